@@ -4,68 +4,1077 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
-	"ratta/internal/app/categoryops"
 	"ratta/internal/app/categoryscan"
+	"ratta/internal/app/diagnostics"
+	"ratta/internal/app/emldraft"
+	"ratta/internal/app/issueexport"
+	"ratta/internal/app/issueimport"
 	"ratta/internal/app/issueops"
+	"ratta/internal/app/migrate"
 	"ratta/internal/app/modedetect"
+	"ratta/internal/app/modesession"
+	"ratta/internal/app/operation"
+	"ratta/internal/app/patchbundle"
+	"ratta/internal/app/pdfreport"
 	"ratta/internal/app/projectroot"
+	"ratta/internal/app/projectsession"
+	"ratta/internal/app/projectsync"
+	"ratta/internal/app/redmine"
+	"ratta/internal/app/sitepublish"
+	"ratta/internal/app/weeklyreport"
+	"ratta/internal/domain/apperr"
 	"ratta/internal/domain/issue"
+	"ratta/internal/domain/timeutil"
+	"ratta/internal/infra/atomicwrite"
+	"ratta/internal/infra/audittrail"
+	"ratta/internal/infra/categorymeta"
 	"ratta/internal/infra/configrepo"
+	"ratta/internal/infra/configwatch"
+	"ratta/internal/infra/fswatch"
+	"ratta/internal/infra/gitcommit"
+	"ratta/internal/infra/journal"
+	"ratta/internal/infra/logging"
+	"ratta/internal/infra/pluginhost"
+	"ratta/internal/infra/projectlock"
+	"ratta/internal/infra/projectmeta"
 	"ratta/internal/infra/schema"
+	"ratta/internal/infra/scripthook"
+	"ratta/internal/infra/tmpresidue"
+	"ratta/internal/infra/webhook"
 	"ratta/internal/present"
+	grpctransport "ratta/internal/transport/grpc"
 
 	mod "ratta/internal/domain/mode"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// 以下は DD-JOURNAL-001 の操作記録に残す操作名を表し、取り消し結果として UI へも返す。
+	journalIssueCreated  = "issue_created"
+	journalIssueUpdated  = "issue_updated"
+	journalCommentAdded  = "comment_added"
+	journalIssueImported = "issue_imported"
+	// 以下は DD-LOG-008 の監査証跡にのみ残す操作名を表す。
+	auditUndone          = "undone"
+	auditCategoryDeleted = "category_deleted"
+	auditCategoryTrashed = "category_trashed"
+	// 以下は DD-GIT-001 の自動コミットのメッセージにのみ残す操作名を表す。
+	gitCategoryCreated     = "category_created"
+	gitCategoryRenamed     = "category_renamed"
+	gitCategoryUpdated     = "category_updated"
+	gitCategoriesReordered = "categories_reordered"
+	gitIssuesImported      = "issues_imported"
+	gitPatchApplied        = "patch_applied"
+	gitProjectMigrated     = "project_migrated"
+	gitProjectSynced       = "project_synced"
+)
+
+const (
+	// projectChangedEvent は DD-WATCH-001 の外部変更を UI へ通知する Wails イベント名を表す。
+	projectChangedEvent = "project:changed"
+	// operationStartedEvent と operationFinishedEvent は DD-CANCEL-001 の処理の開始・終了を通知するイベント名を表す。
+	// UI は開始通知の op_id を CancelOperation に渡して中断できる。
+	operationStartedEvent  = "operation:started"
+	operationFinishedEvent = "operation:finished"
+	// operationProgressEvent は DD-OP-001 の非同期処理の進捗を通知するイベント名を表す。
+	operationProgressEvent = "operation:progress"
+	// warmupProgressEvent は DD-WARMUP-001 の事前読み込みの進捗を通知するイベント名を表す。
+	warmupProgressEvent = "project:warmup"
+
+	// 以下は DD-EVENT-001 のアプリ内の変更を通知するイベント名を表す。
+	// 監視による project:changed は外部の変更も含むが数百ミリ秒遅れるため、開いている他の画面へは操作の完了時に直接通知する。
+	issueCreatedEvent      = "issue:created"
+	issueUpdatedEvent      = "issue:updated"
+	issueCommentedEvent    = "issue:commented"
+	categoryCreatedEvent   = "category:created"
+	categoryRenamedEvent   = "category:renamed"
+	categoryUpdatedEvent   = "category:updated"
+	categoryDeletedEvent   = "category:deleted"
+	categoryReorderedEvent = "category:reordered"
+
+	// filesDroppedEvent は DD-DATA-005 の検査済みのドロップファイルを UI へ通知するイベント名を表す。
+	filesDroppedEvent = "files:dropped"
+	// issueUndoneEvent は DD-JOURNAL-001 の操作の取り消しを通知するイベント名を表す。
+	issueUndoneEvent = "issue:undone"
+	// projectWarningsEvent は DD-PERSIST-004 のプロジェクトを開いた際の警告を UI のエラー一覧へ通知するイベント名を表す。
+	projectWarningsEvent = "project:warnings"
+	// projectLockLostEvent は DD-LOCK-002 の書き込み用ロックを他のインスタンスに引き継がれ、読み取り専用になったことを通知するイベント名を表す。
+	projectLockLostEvent = "project:lock-lost"
+	// modeLockedEvent は DD-MODE-001 の Contractor モードを解除し、再度の認証が必要になったことを通知するイベント名を表す。
+	modeLockedEvent = "mode:locked"
+	// configChangedEvent は DD-CONF-007 の外部で書き換えられた config.json を再読込したことを通知するイベント名を表す。
+	configChangedEvent = "config:changed"
+)
+
+const (
+	// defaultWindowWidth と defaultWindowHeight は DD-BE-002 の保存済みの大きさがない場合のウィンドウの大きさを表す。
+	defaultWindowWidth  = 1280
+	defaultWindowHeight = 768
+	// minRestoredWindowWidth と minRestoredWindowHeight は DD-BE-002 の復元するウィンドウの最小の大きさを表す。
+	// 設定の手編集などで極端に小さい値が保存されていても、操作できない大きさでは開かない。
+	minRestoredWindowWidth  = 640
+	minRestoredWindowHeight = 480
+	// systemSinkSource は DD-LOG-006 の Windows イベントログのソース名・syslog のタグを表す。
+	systemSinkSource = "ratta"
 )
 
+// startupOptions は DD-BE-002 の GUI 起動時の起動引数による指定を表す。
+// Root はすぐに開くプロジェクトルート、ConfigPath は config.json の配置先の上書きを表し、空の場合は指定なしとする。
+// Observer は閲覧のみの Observer モードで起動することを表す。
+type startupOptions struct {
+	Root       string
+	ConfigPath string
+	Observer   bool
+}
+
 // App は DD-BE-002 の Wails バインド対象を表す。
+// startupRoot は起動引数 --root で開いたプロジェクトルートを表し、指定がない場合や開けなかった場合は空とする。
+// modes は DD-MODE-001 の操作モードと照合した Contractor のアカウント名 (DD-CLI-007) を保持し、無操作時間を計測する。
 type App struct {
-	ctx     context.Context
-	exePath string
-	mode    mod.Mode
-	root    string
+	ctx         context.Context
+	exePath     string
+	modes       *modesession.Session
+	startupRoot string
+
+	// projectMu は session・warnings・lock・lockedBy・projectConfig を守る。バインドは Wails から並行に呼び出される。
+	// lockedBy は他のインスタンスが書き込み用に開いている場合の保持者を表し、設定中は読み取り専用とする。
+	// projectConfig は DD-PROJCONF-001 の開いているプロジェクトの設定を表し、読み取れない場合は上書きなしとする。
+	projectMu     sync.RWMutex
+	session       *projectsession.Session
+	warnings      []present.APIErrorDTO
+	lock          *projectlock.Lock
+	lockedBy      *projectlock.Holder
+	projectConfig projectmeta.ProjectConfig
+
+	configRepo      *configrepo.Repository
+	validator       *schema.Validator
+	scanConcurrency int
+	window          *configrepo.Window
+	logger          *logging.Logger
+
+	watchMu    sync.Mutex
+	watcher    *fswatch.Watcher
+	operations *operation.Registry
+
+	warmMu     sync.Mutex
+	warmCancel context.CancelFunc
+
+	// residueInterval は DD-PERSIST-004 の一時ファイル残骸の定期的な検出の間隔を表す。
+	residueMu       sync.Mutex
+	residueScan     *tmpresidue.Scheduler
+	residueInterval time.Duration
 
-	configRepo *configrepo.Repository
-	validator  *schema.Validator
+	// configMu は configWatcher・liveConfig を守る。liveConfig は DD-CONF-007 の直近に UI へ反映した設定を表し、
+	// 値の変わらない再読込 (自身の保存や修復による書き込み) を通知しないために用いる。
+	configMu      sync.Mutex
+	configWatcher *configwatch.Watcher
+	liveConfig    present.ConfigChangedDTO
+
+	// auditLocation は DD-LOG-008 の監査証跡の記録先を表す。auditTrails は記録先のパスごとの監査証跡を表し、auditMu が守る。
+	auditLocation string
+	auditMu       sync.Mutex
+	auditTrails   map[string]*audittrail.Trail
+
+	// gitMu は DD-GIT-001 の自動コミットを直列化し、同じリポジトリのインデックスを同時に書き換えないようにする。
+	gitMu sync.Mutex
+
+	// webhooks は DD-HOOK-001 の課題の変更の通知を送る。notifyCtx は終了時に再試行の待ちや DD-HOOK-003 のフックのスクリプトを中断するために用い、
+	// notifyWG は送信中の通知と実行中のスクリプトを表す。
+	webhooks     *webhook.Sender
+	notifyCtx    context.Context
+	notifyCancel context.CancelFunc
+	notifyWG     sync.WaitGroup
+
+	// plugins は DD-PLUGIN-001 の起動時に実行ファイル隣の plugins/ から発見したプラグインを表す。
+	plugins *pluginhost.Registry
+
+	// grpcAddress は DD-GRPC-001 の gRPC API の待ち受けアドレスを表し、空の場合は待ち受けない。grpcServer は grpcMu が守る。
+	grpcAddress string
+	grpcMu      sync.Mutex
+	grpcServer  *grpctransport.Server
 }
 
 // NewApp は DD-BE-002 の初期化を行う。
 // 目的: Wails 起動時に必要な状態を初期化する。
-// 入力: なし。
+// 入力: options は起動引数による指定。
 // 出力: 初期化済み App。
 // エラー: 返却値で表現しない。実行ファイルパスや設定読み込み失敗時は空文字のまま保持する。
-// 副作用: config.json を読み取る。
+// --root のプロジェクトを開けない場合は警告として起動時情報で返す。
+// 副作用: config.json を読み取り、スキーマに適合しなければ DD-CONF-006 の修復を行う。--root 指定時は last_project_root_path と recent_project_roots を更新する。
 // 並行性: 呼び出し側が単一スレッドで実行する前提。
-// 不変条件: mode は Vendor を初期値とし、root は設定があれば復元する。
-// 関連DD: DD-BE-002
-func NewApp() *App {
+// 不変条件: mode は Vendor (--observer 指定時は Observer) を初期値とし、root・走査並列度・ウィンドウの大きさと位置・ログレベル・DD-PERSIST-003/005 の保存方法は設定があれば復元する。
+// --config 指定時は読み書きとも指定された config.json のみを扱う。
+// 関連DD: DD-BE-002, DD-BE-003
+func NewApp(options startupOptions) *App {
 	exePath, exeErr := os.Executable()
 	if exeErr != nil {
 		exePath = ""
 	}
+	validator := loadValidator(exePath)
 	configRepo := configrepo.NewRepository(exePath)
+	if options.ConfigPath != "" {
+		configRepo = configrepo.NewRepositoryAt(options.ConfigPath)
+	}
+	configRepo.WithValidator(validator)
 	root := ""
+	scanConcurrency := 0
+	idleTimeout := configrepo.DefaultConfig().Auth.ContractorIdleTimeout()
+	residueInterval := configrepo.DefaultConfig().Storage.TmpScanInterval()
+	var window *configrepo.Window
+	logLevel := logging.LevelInfo
+	var logRotation logging.Rotation
+	systemSink := false
+	auditLocation := audittrail.LocationApp
+	displayTimeZone := ""
+	grpcAddress := ""
 	if cfg, hasConfig, err := configRepo.Load(); err == nil && hasConfig {
 		if cfg.LastProjectRootPath != "" {
 			root = cfg.LastProjectRootPath
 		}
+		scanConcurrency = cfg.Scan.Concurrency
+		idleTimeout = cfg.Auth.ContractorIdleTimeout()
+		atomicwrite.SetDurable(cfg.Storage.DurableWrites)
+		atomicwrite.SetBackupGenerations(cfg.Storage.BackupGenerations)
+		timeutil.SetStorageUTC(cfg.Storage.UTCTimestamps)
+		displayTimeZone = cfg.UI.TimeZone
+		present.SetLanguage(cfg.UI.Language)
+		tmpresidue.SetStaleThreshold(cfg.Storage.TmpStaleThreshold())
+		residueInterval = cfg.Storage.TmpScanInterval()
+		window = cfg.UI.Window
+		if level, levelErr := logging.ParseLevel(cfg.Log.Level); levelErr == nil {
+			logLevel = level
+		}
+		logRotation = logging.Rotation{
+			MaxSizeBytes:   int64(cfg.Log.MaxSizeMB) << 20,
+			MaxGenerations: cfg.Log.MaxGenerations,
+		}
+		systemSink = cfg.Log.SystemSink
+		if cfg.Log.AuditTrail != "" {
+			auditLocation = cfg.Log.AuditTrail
+		}
+		if cfg.API != nil {
+			grpcAddress = cfg.API.GRPCAddress
+		}
 	}
-	validator := loadValidator(exePath)
-	return &App{
-		exePath:    exePath,
-		mode:       mod.ModeVendor,
-		root:       root,
-		configRepo: configRepo,
-		validator:  validator,
+	var deliveryLog *webhook.DeliveryLog
+	if exePath != "" {
+		deliveryLog = webhook.OpenDeliveryLog(webhook.AppDeliveryLogPath(exePath))
+	}
+	notifyCtx, notifyCancel := context.WithCancel(context.Background())
+	var plugins *pluginhost.Registry
+	var pluginProblems []error
+	if exePath != "" {
+		plugins, pluginProblems = pluginhost.Discover(pluginhost.AppDir(exePath))
+	}
+	app := &App{
+		exePath:         exePath,
+		configRepo:      configRepo,
+		validator:       validator,
+		scanConcurrency: scanConcurrency,
+		window:          window,
+		logger:          logging.NewLogger(exePath, logLevel, logRotation),
+		operations:      operation.NewRegistry(),
+		residueInterval: residueInterval,
+		auditLocation:   auditLocation,
+		auditTrails:     map[string]*audittrail.Trail{},
+		webhooks:        webhook.NewSender(deliveryLog),
+		notifyCtx:       notifyCtx,
+		notifyCancel:    notifyCancel,
+		plugins:         plugins,
+		grpcAddress:     grpcAddress,
+	}
+	if systemSink {
+		app.openSystemSink()
+	}
+	app.logExtensionProblems()
+	app.logPluginProblems(pluginProblems)
+	app.applyDisplayTimeZone(displayTimeZone)
+	initialMode := mod.ModeVendor
+	if options.Observer {
+		initialMode = mod.ModeObserver
+	}
+	app.modes = modesession.New(initialMode, idleTimeout, func(reason modesession.Reason) {
+		_, _ = app.notifyModeLocked(context.Background(), reason)
+	})
+	if options.Root != "" {
+		app.openStartupRoot(options.Root, root)
+		return app
+	}
+	app.setRoot(root)
+	return app
+}
+
+// openStartupRoot は DD-BE-002 の起動引数 --root で指定されたプロジェクトを検証して開く。
+// 有効でない場合は前回のプロジェクト (fallback) を従来どおり開き、理由を警告に加える。
+// 設定の保存に失敗しても、プロジェクトを開くことは妨げない。
+func (a *App) openStartupRoot(path, fallback string) {
+	service := projectroot.NewService(a.configRepo)
+	result, err := service.ValidateProjectRoot(path)
+	if err == nil && !result.IsValid {
+		err = fmt.Errorf("invalid project root: %s", result.Message)
+	}
+	if err != nil {
+		a.setRoot(fallback)
+		a.projectMu.Lock()
+		a.warnings = append(a.warnings, *present.MapError(fmt.Errorf("--root %s: %w", path, err)))
+		a.projectMu.Unlock()
+		return
+	}
+	if saveErr := service.SaveLastProjectRoot(result.NormalizedPath); saveErr != nil {
+		a.logger.Error("save startup project root failed", map[string]any{"detail": saveErr.Error()})
+	}
+	a.startupRoot = result.NormalizedPath
+	a.setRoot(result.NormalizedPath)
+}
+
+// setRoot は DD-SESSION-001 のプロジェクトルートを切り替え、セッションと監視を作り直す。
+// 操作サービスとロックはセッションが保持するため、ルートを切り替えると前のプロジェクトの状態は引き継がない。
+// 開く際に DD-LOCK-002 の書き込み用ロックを取得し、DD-PERSIST-004 の一時ファイル残骸を処理して警告を UI へ通知する。
+// 他のインスタンスがロックを保持している場合は読み取り専用で開き、書き込み中の一時ファイルを残骸として扱わない。
+// Observer モードでは書き込まないため、ロックを取得せず一時ファイル残骸も処理しない。
+func (a *App) setRoot(root string) {
+	var session *projectsession.Session
+	var lock *projectlock.Lock
+	var lockedBy *projectlock.Holder
+	var projectConfig projectmeta.ProjectConfig
+	warnings := []present.APIErrorDTO{}
+	if root != "" {
+		session = projectsession.New(root, a.validator, a.scanConcurrency)
+		session.Issues().WithRuleChecker(a.checkPluginRules)
+		loaded, configErr := projectmeta.LoadConfig(root)
+		if configErr != nil {
+			warnings = append(warnings, *present.MapError(configErr))
+		}
+		projectConfig = loaded
+	}
+	if root != "" && a.modes.Mode() != mod.ModeObserver {
+		var lockErr error
+		lock, lockedBy, lockErr = acquireProjectLock(root)
+		if lockErr != nil {
+			warnings = append(warnings, *present.MapError(lockErr))
+		}
+		if lockedBy != nil {
+			warnings = append(warnings, *present.MapError(readOnlyError(*lockedBy)))
+		} else {
+			warnings = append(warnings, scanResidue(root)...)
+		}
+	}
+	a.projectMu.Lock()
+	previous := a.lock
+	a.session = session
+	a.warnings = warnings
+	a.lock = lock
+	a.lockedBy = lockedBy
+	a.projectConfig = projectConfig
+	a.projectMu.Unlock()
+	if previous != nil {
+		_ = previous.Release()
+	}
+	a.startLockHeartbeat(lock)
+	a.emitWarnings(warnings)
+	a.restartWatcher()
+	a.restartWarmup()
+	a.restartResidueScan()
+}
+
+// acquireProjectLock は DD-LOCK-002 の書き込み用ロックを取得する。
+// 他のインスタンスが保持している場合は保持者を返す。読み取り専用の共有フォルダなどでロックファイルを作れない場合は、
+// 従来どおりロックなしで開けるようエラーを警告として返す。
+func acquireProjectLock(root string) (*projectlock.Lock, *projectlock.Holder, error) {
+	lock, holder, err := projectlock.Acquire(root)
+	if errors.Is(err, projectlock.ErrLocked) {
+		return nil, &holder, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("acquire project lock: %w", err)
+	}
+	return lock, nil, nil
+}
+
+// startLockHeartbeat は DD-LOCK-002 のロックの更新を開始し、他のインスタンスに引き継がれた場合は読み取り専用へ切り替えて通知する。
+func (a *App) startLockHeartbeat(lock *projectlock.Lock) {
+	if lock == nil {
+		return
+	}
+	lock.Heartbeat(projectlock.HeartbeatInterval, func(holder projectlock.Holder) {
+		a.projectMu.Lock()
+		if a.lock != lock {
+			a.projectMu.Unlock()
+			return
+		}
+		a.lock = nil
+		a.lockedBy = &holder
+		a.projectMu.Unlock()
+		a.stopResidueScan()
+		a.emitEvent(projectLockLostEvent, present.ToProjectLockDTO(holder))
+	})
+}
+
+// projectLockedBy は DD-LOCK-002 の開いているプロジェクトを書き込み用に開いている他のインスタンスを返す。書き込み可能な場合は nil を返す。
+func (a *App) projectLockedBy() *present.ProjectLockDTO {
+	a.projectMu.RLock()
+	defer a.projectMu.RUnlock()
+	if a.lockedBy == nil {
+		return nil
+	}
+	dto := present.ToProjectLockDTO(*a.lockedBy)
+	return &dto
+}
+
+// scanResidue は DD-PERSIST-004 の一時ファイル残骸を処理し、警告をエラー一覧の形式で返す。
+// 走査自体の失敗もプロジェクトを開く妨げにはせず、警告の1件として返す。
+func scanResidue(root string) []present.APIErrorDTO {
+	return residueWarnings(tmpresidue.ScanAndHandle(root))
+}
+
+// residueWarnings は DD-PERSIST-004 の一時ファイル残骸の処理結果を警告の形式へ変換する。
+func residueWarnings(results []tmpresidue.ScanResult, err error) []present.APIErrorDTO {
+	if err != nil {
+		return []present.APIErrorDTO{*present.MapError(fmt.Errorf("scan tmp residue: %w", err))}
+	}
+	return present.ToResidueWarningDTOs(results)
+}
+
+// restartResidueScan は DD-PERSIST-004 の一時ファイル残骸の定期的な検出を現在のプロジェクトで開始し直す。
+// 目的: 開いたままのプロジェクトでも、手動の操作なしに残骸を片付けて残り続けるものを通知する。
+// 入力: なし。
+// 出力: なし。
+// エラー: 返却値で表現しない。走査の失敗は警告として通知する。
+// 副作用: 検出のゴルーチンを起動し、新たな警告を project:warnings で UI へ通知する。
+// 並行性: residueMu で排他制御する。
+// 不変条件: 残骸を削除するため、書き込み用ロックを保持している場合のみ行う。通知済みの警告は再度通知しない。
+// 関連DD: DD-PERSIST-004, DD-LOCK-002
+func (a *App) restartResidueScan() {
+	a.residueMu.Lock()
+	defer a.residueMu.Unlock()
+	if a.residueScan != nil {
+		a.residueScan.Stop()
+		a.residueScan = nil
+	}
+	a.projectMu.RLock()
+	session, lock := a.session, a.lock
+	reported := map[string]bool{}
+	for _, warning := range a.warnings {
+		reported[warningKey(warning)] = true
+	}
+	a.projectMu.RUnlock()
+	if session == nil || lock == nil || a.ctx == nil {
+		return
+	}
+	a.residueScan = tmpresidue.Schedule(session.Root(), a.residueInterval, func(results []tmpresidue.ScanResult, err error) {
+		fresh := []present.APIErrorDTO{}
+		for _, warning := range residueWarnings(results, err) {
+			if key := warningKey(warning); !reported[key] {
+				reported[key] = true
+				fresh = append(fresh, warning)
+			}
+		}
+		a.emitWarnings(fresh)
+	})
+}
+
+// stopResidueScan は DD-PERSIST-004 の一時ファイル残骸の定期的な検出を停止する。
+func (a *App) stopResidueScan() {
+	a.residueMu.Lock()
+	defer a.residueMu.Unlock()
+	if a.residueScan != nil {
+		a.residueScan.Stop()
+		a.residueScan = nil
+	}
+}
+
+// warningKey は DD-PERSIST-004 の同じ警告を重ねて通知しないための識別子を返す。
+func warningKey(warning present.APIErrorDTO) string {
+	return warning.ErrorCode + "\x00" + warning.TargetPath + "\x00" + warning.Message
+}
+
+// projectWarnings は DD-PERSIST-004 の開いているプロジェクトの警告を返す。
+func (a *App) projectWarnings() []present.APIErrorDTO {
+	a.projectMu.RLock()
+	defer a.projectMu.RUnlock()
+	return a.warnings
+}
+
+// bootstrapWarnings は DD-BE-003 の起動時情報で返す警告を、DD-CONF-006 の config.json の修復、プロジェクトの警告の順に返す。
+func (a *App) bootstrapWarnings() []present.APIErrorDTO {
+	warnings := []present.APIErrorDTO{}
+	if repair := a.configRepo.Repaired(); repair != nil {
+		warnings = append(warnings, present.ToConfigRepairWarningDTO(*repair))
+	}
+	return append(warnings, a.projectWarnings()...)
+}
+
+// emitWarnings は DD-PERSIST-004 の警告がある場合に UI へ通知する。
+// 起動時に復元したプロジェクトの警告は画面の準備前で通知できないため、GetAppBootstrap の応答で返す。
+func (a *App) emitWarnings(warnings []present.APIErrorDTO) {
+	if len(warnings) > 0 {
+		a.emitEvent(projectWarningsEvent, warnings)
+	}
+}
+
+// project は DD-SESSION-001 の開いているプロジェクトのセッションを返す。未設定の場合はエラーを返す。
+func (a *App) project() (*projectsession.Session, error) {
+	return a.projectFor(context.Background())
+}
+
+// projectFor は DD-SESSION-001 の ctx の呼び出し元に応じて開いているプロジェクトのセッションを返す。
+// GUI からの呼び出しは DD-MODE-001 の操作として無操作時間の計測をやり直し、DD-GRPC-001 の gRPC からの呼び出しはやり直さない。
+func (a *App) projectFor(ctx context.Context) (*projectsession.Session, error) {
+	if !fromGRPC(ctx) {
+		a.modes.Touch()
+	}
+	a.projectMu.RLock()
+	defer a.projectMu.RUnlock()
+	if a.session == nil {
+		return nil, apperr.New(apperr.ErrValidation, "project root is not set")
+	}
+	return a.session, nil
+}
+
+// writableProject は DD-LOCK-002 の書き込み可能なプロジェクトのセッションを返す。
+// 他のインスタンスが書き込み用に開いている場合は読み取り専用としてエラーを返す。
+func (a *App) writableProject() (*projectsession.Session, error) {
+	return a.writableProjectFor(context.Background())
+}
+
+// writableProjectFor は DD-LOCK-002 の ctx の呼び出し元に応じて書き込み可能なプロジェクトのセッションを返す。
+// 無操作時間の扱いは projectFor と同じとする。
+func (a *App) writableProjectFor(ctx context.Context) (*projectsession.Session, error) {
+	if !fromGRPC(ctx) {
+		a.modes.Touch()
 	}
+	a.projectMu.RLock()
+	defer a.projectMu.RUnlock()
+	if a.session == nil {
+		return nil, apperr.New(apperr.ErrValidation, "project root is not set")
+	}
+	if current, _ := a.callMode(ctx); current == mod.ModeObserver {
+		return nil, errObserverReadOnly
+	}
+	if a.lockedBy != nil {
+		return nil, readOnlyError(*a.lockedBy)
+	}
+	return a.session, nil
+}
+
+// errObserverReadOnly は DD-BE-003 の Observer モードで変更を拒否することを表す。権限不足 (E_PERMISSION) として扱う。
+var errObserverReadOnly = apperr.New(apperr.ErrPermission, "permission denied: observer mode is read-only")
+
+// readOnlyError は DD-LOCK-002 の読み取り専用で開いている理由をエラーとして返す。
+func readOnlyError(holder projectlock.Holder) error {
+	return apperr.Errorf(apperr.ErrReadOnly, "project is read-only: opened for writing by %s (pid %d)", holder.Hostname, holder.PID)
 }
 
-// startup は起動時に context を保存する。
+// startup は起動時に context を保存し、プロジェクトルートが設定済みであれば監視と事前読み込みを開始する。
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	runtime.OnFileDrop(ctx, a.handleFileDrop)
+	a.restoreWindow(ctx)
+	a.restartWatcher()
+	a.restartWarmup()
+	a.restartResidueScan()
+	a.startConfigWatcher()
+	a.startGRPC()
+}
+
+// shutdown は終了時に gRPC API の受け付け、プロジェクトルートと config.json の監視、事前読み込み、一時ファイル残骸の定期的な検出を停止し、
+// 送信中の変更の通知と実行中のフックのスクリプトを待ってから書き込み用ロックを解放する。
+func (a *App) shutdown(_ context.Context) {
+	a.stopGRPC()
+	a.stopWatcher()
+	a.stopConfigWatcher()
+	a.stopWarmup()
+	a.stopResidueScan()
+	a.stopNotifications()
+	if err := a.logger.Close(); err != nil {
+		a.logger.Error("close log sink failed", map[string]any{"detail": err.Error()})
+	}
+	a.projectMu.Lock()
+	lock := a.lock
+	a.lock = nil
+	a.projectMu.Unlock()
+	if lock != nil {
+		_ = lock.Release()
+	}
+}
+
+// startGRPC は DD-GRPC-001 の gRPC API の待ち受けを開始する。アドレスが未設定の場合は何もしない。
+// 待ち受けに失敗しても GUI の利用は妨げず、ログに記録する。
+func (a *App) startGRPC() {
+	if a.grpcAddress == "" {
+		return
+	}
+	listener, err := grpctransport.Listen(a.grpcAddress)
+	if err != nil {
+		a.logger.Error("start grpc api failed", map[string]any{"detail": err.Error()})
+		return
+	}
+	server := grpctransport.New(grpcBackend{app: a})
+	a.grpcMu.Lock()
+	a.grpcServer = server
+	a.grpcMu.Unlock()
+	a.logger.Info("grpc api started", map[string]any{"address": listener.Addr().String()})
+	go func() {
+		if serveErr := server.Serve(listener); serveErr != nil {
+			a.logger.Error("serve grpc api failed", map[string]any{"detail": serveErr.Error()})
+		}
+	}()
+}
+
+// stopGRPC は DD-GRPC-001 の gRPC API の受け付けを止め、処理中の呼び出しの完了を待つ。
+func (a *App) stopGRPC() {
+	a.grpcMu.Lock()
+	server := a.grpcServer
+	a.grpcServer = nil
+	a.grpcMu.Unlock()
+	if server != nil {
+		server.Stop()
+	}
+}
+
+// grpcBackend は DD-GRPC-001 の gRPC API の呼び出しを、GUI と同じ書き込み用ロック・カテゴリ権限の判定で App へ委ねる。
+// 認証を持たない API のため、操作モードと無操作時間は callMode・projectFor に従い GUI の Contractor の権限を引き継がない。
+type grpcBackend struct {
+	app *App
+}
+
+var _ grpctransport.Backend = grpcBackend{}
+
+// ListCategories は DD-GRPC-001 のカテゴリ一覧を返す。
+func (b grpcBackend) ListCategories() present.Response {
+	return b.app.listCategoriesFor(b.app.beginGRPCCall("ListCategories"))
+}
+
+// CreateCategory は DD-GRPC-001 のカテゴリ作成を行う。
+func (b grpcBackend) CreateCategory(name string) present.Response {
+	return b.app.createCategoryFor(b.app.beginGRPCCall("CreateCategory"), name)
+}
+
+// RenameCategory は DD-GRPC-001 のカテゴリ名変更を行う。
+func (b grpcBackend) RenameCategory(oldName, newName string) present.Response {
+	return b.app.renameCategoryFor(b.app.beginGRPCCall("RenameCategory"), oldName, newName)
+}
+
+// DeleteCategory は DD-GRPC-001 の空のカテゴリの削除を行う。
+func (b grpcBackend) DeleteCategory(name string) present.Response {
+	return b.app.deleteCategoryFor(b.app.beginGRPCCall("DeleteCategory"), name)
+}
+
+// ListIssues は DD-GRPC-001 の課題一覧を返す。
+func (b grpcBackend) ListIssues(category string, query present.IssueListQueryDTO) present.Response {
+	return b.app.listIssuesFor(b.app.beginGRPCCall("ListIssues"), category, query)
+}
+
+// GetIssue は DD-GRPC-001 の課題詳細を返す。
+func (b grpcBackend) GetIssue(category, issueID string) present.Response {
+	return b.app.getIssueFor(b.app.beginGRPCCall("GetIssue"), category, issueID)
+}
+
+// CreateIssue は DD-GRPC-001 の課題作成を行う。
+func (b grpcBackend) CreateIssue(category string, dto present.IssueCreateDTO) present.Response {
+	return b.app.createIssueFor(b.app.beginGRPCCall("CreateIssue"), category, dto)
+}
+
+// UpdateIssue は DD-GRPC-001 の課題更新を行う。
+func (b grpcBackend) UpdateIssue(category, issueID string, dto present.IssueUpdateDTO) present.Response {
+	return b.app.updateIssueFor(b.app.beginGRPCCall("UpdateIssue"), category, issueID, dto)
+}
+
+// AddComment は DD-GRPC-001 のコメント追加を行う。
+func (b grpcBackend) AddComment(category, issueID string, dto present.CommentCreateDTO) present.Response {
+	return b.app.addCommentFor(b.app.beginGRPCCall("AddComment"), category, issueID, dto)
+}
+
+// openSystemSink は DD-LOG-006 のエラーのログ行を Windows イベントログ・syslog へも転送する。開けない場合は転送なしで続ける。
+func (a *App) openSystemSink() {
+	sink, err := logging.OpenSystemSink(systemSinkSource)
+	if err != nil {
+		a.logger.Error("open system log sink failed", map[string]any{"detail": err.Error()})
+		return
+	}
+	a.logger.AddSink(sink, logging.LevelError)
+}
+
+// windowSize は DD-BE-002 の起動時のウィンドウの大きさを返す。保存済みの大きさが不正な場合は既定値を用いる。
+func (a *App) windowSize() (int, int) {
+	if a.window == nil || a.window.Width < minRestoredWindowWidth || a.window.Height < minRestoredWindowHeight {
+		return defaultWindowWidth, defaultWindowHeight
+	}
+	return a.window.Width, a.window.Height
+}
+
+// restoreWindow は DD-BE-002 の前回終了時のウィンドウの位置と最大化状態を復元する。
+// 大きさは作成時に windowSize で指定するため、ここでは位置のみを移動する。
+func (a *App) restoreWindow(ctx context.Context) {
+	if a.window == nil {
+		return
+	}
+	runtime.WindowSetPosition(ctx, a.window.X, a.window.Y)
+	if a.window.Maximised {
+		runtime.WindowMaximise(ctx)
+	}
+}
+
+// beforeClose は DD-BE-002 の終了前にウィンドウの大きさと位置を config.json へ保存する。
+// 保存に失敗しても終了は妨げない。
+func (a *App) beforeClose(ctx context.Context) bool {
+	// 最小化中の位置は OS により画面外の値となるため保存しない。
+	if runtime.WindowIsMinimised(ctx) {
+		return false
+	}
+	window := configrepo.Window{Maximised: runtime.WindowIsMaximised(ctx)}
+	if window.Maximised && a.window != nil {
+		// 最大化中の大きさと位置は画面全体となるため、前回保存した最大化前の値を引き継ぐ。
+		window.Width, window.Height, window.X, window.Y = a.window.Width, a.window.Height, a.window.X, a.window.Y
+	} else {
+		window.Width, window.Height = runtime.WindowGetSize(ctx)
+		window.X, window.Y = runtime.WindowGetPosition(ctx)
+	}
+	if err := a.configRepo.SaveWindow(window); err == nil {
+		a.window = &window
+	}
+	return false
+}
+
+// restartWarmup は DD-WARMUP-001 の事前読み込みを現在のプロジェクトルートで開始し直す。
+// 前のプロジェクトの読み込みは不要になるため中断する。UI は CancelOperation で中断することもできる。
+func (a *App) restartWarmup() {
+	a.warmMu.Lock()
+	defer a.warmMu.Unlock()
+	if a.warmCancel != nil {
+		a.warmCancel()
+		a.warmCancel = nil
+	}
+	session, err := a.project()
+	if err != nil || a.ctx == nil {
+		return
+	}
+	parent, cancel := context.WithCancel(a.ctx)
+	a.warmCancel = cancel
+	ctx, done := a.startOperation(parent, "warmup")
+	go func() {
+		defer done()
+		err := session.Warm(ctx, func(completed, total int, category string) {
+			runtime.EventsEmit(a.ctx, warmupProgressEvent, present.WarmupProgressDTO{
+				Root:     session.Root(),
+				Done:     completed,
+				Total:    total,
+				Category: category,
+			})
+		})
+		// 事前読み込みは高速化のためだけに行うため、失敗や中断は通知せず、通常の一覧取得に委ねる。
+		if err == nil {
+			runtime.EventsEmit(a.ctx, warmupProgressEvent, present.WarmupProgressDTO{Root: session.Root(), Finished: true})
+		}
+	}()
+}
+
+// stopWarmup は DD-WARMUP-001 の事前読み込みを中断する。
+func (a *App) stopWarmup() {
+	a.warmMu.Lock()
+	defer a.warmMu.Unlock()
+	if a.warmCancel != nil {
+		a.warmCancel()
+		a.warmCancel = nil
+	}
+}
+
+// restartWatcher は DD-WATCH-001 の監視を現在のプロジェクトルートで開始し直す。
+// 監視は UI の自動更新のためだけに用いるため、開始に失敗してもプロジェクトの操作は継続する。
+func (a *App) restartWatcher() {
+	a.watchMu.Lock()
+	defer a.watchMu.Unlock()
+	if a.watcher != nil {
+		_ = a.watcher.Close()
+		a.watcher = nil
+	}
+	session, err := a.project()
+	if err != nil || a.ctx == nil {
+		return
+	}
+	emit := func(events []fswatch.Event) {
+		// 通知前にキャッシュを破棄し、UI の再読込で最新の内容が返るようにする。
+		session.ApplyChanges(events)
+		a.emitProjectChanged(events)
+	}
+	if watcher, err := fswatch.Start(session.Root(), emit); err == nil {
+		a.watcher = watcher
+	}
+}
+
+// stopWatcher は DD-WATCH-001 の監視を停止する。
+func (a *App) stopWatcher() {
+	a.watchMu.Lock()
+	defer a.watchMu.Unlock()
+	if a.watcher != nil {
+		_ = a.watcher.Close()
+		a.watcher = nil
+	}
+}
+
+// startConfigWatcher は DD-CONF-007 の config.json の監視を開始する。
+// 監視は再起動なしの反映のためだけに用いるため、開始に失敗しても起動は継続する。
+func (a *App) startConfigWatcher() {
+	snapshot, ok := a.loadLiveConfig()
+	a.configMu.Lock()
+	defer a.configMu.Unlock()
+	if ok {
+		a.liveConfig = snapshot
+	}
+	if a.configWatcher != nil {
+		return
+	}
+	watcher, err := configwatch.Start(a.configRepo.Path(), a.reloadConfig)
+	if err != nil {
+		a.logger.Error("start config watch failed", map[string]any{"detail": err.Error()})
+		return
+	}
+	a.configWatcher = watcher
+}
+
+// stopConfigWatcher は DD-CONF-007 の config.json の監視を停止する。
+func (a *App) stopConfigWatcher() {
+	a.configMu.Lock()
+	watcher := a.configWatcher
+	a.configWatcher = nil
+	a.configMu.Unlock()
+	if watcher != nil {
+		_ = watcher.Close()
+	}
+}
+
+// reloadConfig は DD-CONF-007 に従い、外部で書き換えられた config.json を読み直して稼働中の設定へ反映する。
+// 目的: 利用者や配布ツールによる設定の変更を、再起動なしに開いている画面へ反映する。
+// 入力: なし。
+// 出力: なし。
+// エラー: 読み込みに失敗した場合や config.json が削除された場合は、稼働中の設定を保持して何もしない。
+// 副作用: ログレベル・表示用のタイムゾーン・エラーメッセージの表示言語を更新し、変更をログに記録して config:changed イベントを UI へ通知する。
+// 並行性: 監視ゴルーチンから呼ばれる。configMu で直近の設定を排他制御する。
+// 不変条件: 反映対象 (ログレベル・表示件数・アプリ設定) が変わらない場合は通知しない。
+// 関連DD: DD-CONF-007, DD-EVENT-001
+func (a *App) reloadConfig() {
+	dto, ok := a.loadLiveConfig()
+	if !ok {
+		return
+	}
+	a.configMu.Lock()
+	changed := dto != a.liveConfig
+	a.liveConfig = dto
+	a.configMu.Unlock()
+	if !changed {
+		return
+	}
+	if level, err := logging.ParseLevel(dto.LogLevel); err == nil {
+		a.logger.SetLevel(level)
+	}
+	a.applyDisplayTimeZone(dto.Settings.TimeZone)
+	present.SetLanguage(dto.Settings.Language)
+	a.logger.Info("config reloaded", map[string]any{"log_level": dto.LogLevel, "page_size": dto.UIPageSize})
+	a.emitEvent(configChangedEvent, dto)
+}
+
+// loadLiveConfig は DD-CONF-007 の再起動なしに反映する設定を config.json から読み込む。読めない場合は false を返す。
+func (a *App) loadLiveConfig() (present.ConfigChangedDTO, bool) {
+	cfg, hasConfig, err := a.configRepo.Load()
+	if err != nil || !hasConfig {
+		return present.ConfigChangedDTO{}, false
+	}
+	return present.ConfigChangedDTO{
+		LogLevel:   cfg.Log.Level,
+		UIPageSize: a.pageSize(cfg.UI.PageSize),
+		Settings:   present.ToSettingsDTO(cfg.UI.Settings()),
+	}, true
+}
+
+// emitProjectChanged は DD-WATCH-001 の変更通知を Wails イベントとして UI へ送る。
+func (a *App) emitProjectChanged(events []fswatch.Event) {
+	dtos := make([]present.ProjectChangeDTO, 0, len(events))
+	for _, event := range events {
+		dtos = append(dtos, present.ToProjectChangeDTO(event))
+	}
+	runtime.EventsEmit(a.ctx, projectChangedEvent, dtos)
+}
+
+// emitEvent は DD-EVENT-001 の Wails イベントを UI へ送る。起動前 (context 未設定) の場合は何もしない。
+func (a *App) emitEvent(name string, payload any) {
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, name, payload)
+	}
+}
+
+// beginCall は DD-LOG-007 のバインディングの呼び出しにリクエスト ID を割り当てる。
+// 目的: 読み取り・検証・書き込みにまたがる1回の操作のログを、request_id で追跡できるようにする。
+// 入力: method はバインディング名。
+// 出力: リクエスト ID・バインディング名・開始時刻を格納した context。
+// エラー: なし。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 返却した context は endCall へ渡し、処理内のログ出力とユースケースの呼び出しに用いる。
+// 関連DD: DD-LOG-007
+func (a *App) beginCall(method string) context.Context {
+	parent := a.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx := logging.WithRequestID(parent, logging.NewRequestID())
+	return context.WithValue(ctx, callKey{}, callInfo{method: method, started: time.Now()})
+}
+
+// endCall は DD-LOG-007 のバインディングの呼び出しの結果を request_id を付けてログへ記録する。
+// 成功は debug、失敗は info としてエラーコードを残し、入力値や詳細は記録しない。
+func (a *App) endCall(ctx context.Context, resp *present.Response) {
+	info, _ := ctx.Value(callKey{}).(callInfo)
+	fields := map[string]any{
+		"method":      info.method,
+		"duration_ms": time.Since(info.started).Milliseconds(),
+	}
+	if resp.Ok || resp.Error == nil {
+		a.logger.DebugContext(ctx, "api call", fields)
+		return
+	}
+	fields["error_code"] = resp.Error.ErrorCode
+	a.logger.InfoContext(ctx, "api call failed", fields)
+}
+
+// callKey は DD-LOG-007 の context にバインディングの呼び出しの情報を格納するキーを表す。
+type callKey struct{}
+
+// callInfo は DD-LOG-007 のバインディング名と呼び出しの開始時刻を表す。
+// grpc は DD-GRPC-001 の gRPC からの呼び出しであることを表す。
+type callInfo struct {
+	method  string
+	started time.Time
+	grpc    bool
+}
+
+// beginGRPCCall は DD-GRPC-001 の gRPC からの呼び出しとして beginCall を行う。
+func (a *App) beginGRPCCall(method string) context.Context {
+	ctx := a.beginCall(method)
+	info, _ := ctx.Value(callKey{}).(callInfo)
+	info.grpc = true
+	return context.WithValue(ctx, callKey{}, info)
+}
+
+// fromGRPC は ctx が DD-GRPC-001 の gRPC からの呼び出しかを返す。
+func fromGRPC(ctx context.Context) bool {
+	info, _ := ctx.Value(callKey{}).(callInfo)
+	return info.grpc
+}
+
+// callMode は ctx の呼び出しの権限判定と記録に用いる DD-MODE-001 の操作モードと DD-CLI-007 のアカウント名を返す。
+// DD-GRPC-001 の gRPC は認証を持たないため、GUI で照合した Contractor の権限とアカウント名を引き継がず Vendor とする。
+// Observer モードでは書き込み用ロックを保持しないため、gRPC からの呼び出しも Observer として変更を拒否する。
+func (a *App) callMode(ctx context.Context) (mod.Mode, string) {
+	current, user := a.modes.Mode(), a.modes.User()
+	if fromGRPC(ctx) && current == mod.ModeContractor {
+		return mod.ModeVendor, ""
+	}
+	return current, user
+}
+
+// startOperation は DD-CANCEL-001 の親 context を指定して中断可能な処理を登録し、開始を UI へ通知する。
+// 返却した完了関数は処理の終了時に必ず呼び出す。parent はバインディングの呼び出しの context とし、リクエスト ID を処理へ引き継ぐ。
+func (a *App) startOperation(parent context.Context, kind string) (context.Context, func()) {
+	_, ctx, finish := a.registerOperation(parent, kind)
+	return ctx, func() {
+		finish(nil)
+	}
+}
+
+// registerOperation は DD-CANCEL-001 の処理を登録して開始を通知し、結果を添えて終了を通知する関数を返す。
+func (a *App) registerOperation(parent context.Context, kind string) (present.OperationDTO, context.Context, func(*present.Response)) {
+	id, ctx, done := a.operations.Begin(parent)
+	dto := present.OperationDTO{OpID: id, Kind: kind}
+	a.emitEvent(operationStartedEvent, dto)
+	return dto, ctx, func(result *present.Response) {
+		done()
+		finished := dto
+		finished.Result = result
+		a.emitEvent(operationFinishedEvent, finished)
+	}
+}
+
+// asyncTask は DD-OP-001 のバックグラウンドで実行する処理を表す。
+// report で進捗を通知し、成功時は UI へ返す DTO を返す。
+type asyncTask func(ctx context.Context, report func(done, total int, message string)) (any, error)
+
+// startAsync は DD-OP-001 の長時間処理のバックグラウンド実行を行う。
+// 目的: 処理の完了までバインディングの呼び出しを待たせず、UI が操作を続けられるようにする。
+// 入力: parent はバインディングの呼び出しの context、kind は処理の種別、task は実行する処理。
+// 出力: 処理IDを含む OperationDTO の Response。
+// エラー: なし。処理の失敗は operation:finished の result で通知する。
+// 副作用: goroutine で task を実行し、operation:started・operation:progress・operation:finished を通知する。
+// 並行性: task は呼び出し元と並行に実行されるため、必要なロックは task 内で取得する。
+// 不変条件: operation:finished は処理ごとに必ず1回、result を含めて通知する。中断は CancelOperation で行う。
+// 関連DD: DD-OP-001, DD-CANCEL-001
+func (a *App) startAsync(parent context.Context, kind string, task asyncTask) present.Response {
+	dto, ctx, finish := a.registerOperation(parent, kind)
+	report := func(done, total int, message string) {
+		a.emitEvent(operationProgressEvent, present.OperationProgressDTO{
+			OpID:    dto.OpID,
+			Kind:    dto.Kind,
+			Done:    done,
+			Total:   total,
+			Message: message,
+		})
+	}
+	go func() {
+		data, err := task(ctx, report)
+		result := present.Ok(data)
+		if err != nil {
+			result = present.Fail(err)
+			a.logger.InfoContext(ctx, "operation failed", map[string]any{"kind": kind, "error_code": result.Error.ErrorCode})
+		}
+		finish(&result)
+	}()
+	return present.Ok(dto)
+}
+
+// handleFileDrop は DD-DATA-005 のドラッグ&ドロップされたファイルを添付入力へ変換して UI へ通知する。
+// ドロップ先の課題はバックエンドでは分からないため、UI が位置から判断して AddComment の添付に加える。
+// AddComment でもサイズと種類を確認するが、添付できないファイルはドロップした時点で理由を示す。
+func (a *App) handleFileDrop(x, y int, paths []string) {
+	dto := present.FileDropDTO{
+		X:           x,
+		Y:           y,
+		Attachments: make([]present.AttachmentUploadDTO, 0, len(paths)),
+		Rejected:    []present.RejectedFileDTO{},
+	}
+	for _, path := range paths {
+		file, err := issueops.InspectAttachmentFile(path)
+		if err != nil {
+			dto.Rejected = append(dto.Rejected, present.RejectedFileDTO{Path: path, Message: err.Error()})
+			continue
+		}
+		dto.Attachments = append(dto.Attachments, present.AttachmentUploadDTO{
+			SourcePath:       file.Path,
+			OriginalFileName: file.Name,
+			MimeType:         file.MimeType,
+		})
+	}
+	a.emitEvent(filesDroppedEvent, dto)
+}
+
+// CancelOperation は DD-CANCEL-001 の実行中の処理の中断を要求する。
+// 中断された処理は E_CANCELED のエラーで終了する。
+func (a *App) CancelOperation(opID string) (resp present.Response) {
+	ctx := a.beginCall("CancelOperation")
+	defer a.endCall(ctx, &resp)
+	if !a.operations.Cancel(opID) {
+		return present.Fail(apperr.New(apperr.ErrNotFound, "operation not found"))
+	}
+	return present.Ok(nil)
 }
 
 // GetAppBootstrap は DD-BE-003 の起動時情報を返す。
@@ -77,7 +1086,9 @@ func (a *App) startup(ctx context.Context) {
 // 並行性: App はスレッドセーフではないため同時呼び出しは想定しない。
 // 不変条件: 返却する DTO は nil の代わりに空値を使う。
 // 関連DD: DD-BE-003
-func (a *App) GetAppBootstrap() present.Response {
+func (a *App) GetAppBootstrap() (resp present.Response) {
+	ctx := a.beginCall("GetAppBootstrap")
+	defer a.endCall(ctx, &resp)
 	cfg, hasConfig, err := a.configRepo.Load()
 	if err != nil {
 		cfg = configrepo.DefaultConfig()
@@ -89,288 +1100,2170 @@ func (a *App) GetAppBootstrap() present.Response {
 		value := cfg.LastProjectRootPath
 		lastPath = &value
 	}
+	var startupRoot *string
+	if a.startupRoot != "" {
+		value := a.startupRoot
+		startupRoot = &value
+	}
+
+	hasAuth := false
+	usernameRequired := false
+	totpRequired := false
+	if a.exePath != "" {
+		service := modedetect.NewService(a.exePath, a.validator)
+		if _, requiresPassword, detectErr := service.DetectMode(); detectErr == nil {
+			hasAuth = requiresPassword
+		}
+		if required, requiredErr := service.RequiresUsername(); requiredErr == nil {
+			usernameRequired = required
+		}
+		if required, requiredErr := service.RequiresTOTP(""); requiredErr == nil {
+			totpRequired = required
+		}
+	}
+
+	// DD-MODE-003 の記憶による照合はワンタイムコードを求めないため、TOTP を要する場合は記憶を提示しない。
+	dto := present.BootstrapDTO{
+		HasConfig:                   hasConfig,
+		LastProjectRootPath:         lastPath,
+		StartupProjectRoot:          startupRoot,
+		UIPageSize:                  a.pageSize(cfg.UI.PageSize),
+		LogLevel:                    cfg.Log.Level,
+		Mode:                        string(a.modes.Mode()),
+		HasContractorAuthFile:       hasAuth,
+		ContractorUsernameRequired:  usernameRequired,
+		ContractorTOTPRequired:      totpRequired,
+		ContractorRememberSupported: modedetect.RememberSupported() && !totpRequired,
+		RecentProjectRoots:          recentProjectRoots(cfg.RecentProjectRoots),
+		Warnings:                    a.bootstrapWarnings(),
+		LockedBy:                    a.projectLockedBy(),
+		Settings:                    present.ToSettingsDTO(cfg.UI.Settings()),
+	}
+	return present.Ok(dto)
+}
+
+// SetLogLevel は DD-BE-002 のログレベルを再起動なしで変更し、config.json へ保存する。
+// 目的: 問い合わせ対応時に debug ログを有効化し、調査後に戻せるようにする。
+// 入力: level は info または debug。
+// 出力: Response。
+// エラー: 未対応の値、設定の保存失敗時に返す。保存に失敗した場合は稼働中のレベルも変更しない。
+// 副作用: config.json の log.level を更新し、変更をログに記録する。
+// 並行性: Logger の mutex で排他制御する。
+// 不変条件: 稼働中のレベルと保存したレベルを一致させる。
+// 関連DD: DD-BE-002, DD-DATA-001
+func (a *App) SetLogLevel(level string) (resp present.Response) {
+	ctx := a.beginCall("SetLogLevel")
+	defer a.endCall(ctx, &resp)
+	parsed, err := logging.ParseLevel(level)
+	if err != nil {
+		return present.Fail(err)
+	}
+	if err := a.configRepo.SaveLogLevel(level); err != nil {
+		return present.Fail(err)
+	}
+	a.logger.SetLevel(parsed)
+	a.logger.InfoContext(ctx, "log level changed", map[string]any{"log_level": level})
+	return present.Ok(nil)
+}
+
+// SaveSettings は DD-CONF-005 のアプリ設定を config.json へ保存する。
+// 目的: 並び順や作成者名などを起動のたびに入力し直さなくて済むようにする。
+// 入力: dto は保存する設定。
+// 出力: 既定値を補った保存後の SettingsDTO を含む Response。
+// エラー: 値域外の値 (E_VALIDATION)、設定の読み込み・保存失敗時に返す。
+// 副作用: config.json の ui の該当項目を更新し、表示用のタイムゾーンとエラーメッセージの表示言語を切り替えて変更をログに記録する。
+// 並行性: 同時更新は想定しない。
+// 不変条件: 検証に失敗した場合は保存しない。他の設定は保持する。
+// 関連DD: DD-CONF-005, DD-DATA-001, DD-DATA-002
+func (a *App) SaveSettings(dto present.SettingsDTO) (resp present.Response) {
+	ctx := a.beginCall("SaveSettings")
+	defer a.endCall(ctx, &resp)
+	settings := toSettings(dto)
+	if err := a.configRepo.SaveSettings(settings); err != nil {
+		return present.Fail(err)
+	}
+	a.applyDisplayTimeZone(settings.TimeZone)
+	present.SetLanguage(settings.Language)
+	a.logger.InfoContext(ctx, "settings changed", map[string]any{
+		"default_sort_by":    settings.DefaultSort.By,
+		"default_sort_order": settings.DefaultSort.Order,
+		"date_format":        settings.DateFormat,
+		"language":           settings.Language,
+		"time_zone":          settings.TimeZone,
+	})
+	return present.Ok(present.ToSettingsDTO(settings))
+}
+
+// applyDisplayTimeZone は DD-DATA-002 の表示用のタイムゾーンを name へ切り替える。空文字は OS のタイムゾーンを表す。
+// 解決できない名前の場合は OS のタイムゾーンを用い、ログへ記録する。
+func (a *App) applyDisplayTimeZone(name string) {
+	location, err := timeutil.LoadLocation(name)
+	if err != nil {
+		a.logger.Error("time zone not applied", map[string]any{"time_zone": name, "detail": err.Error()})
+		location = nil
+	}
+	timeutil.SetDisplayLocation(location)
+}
+
+// toSettings は DD-CONF-005 のアプリ設定を DTO から変換する。
+func toSettings(dto present.SettingsDTO) configrepo.Settings {
+	return configrepo.Settings{
+		DefaultSort:       configrepo.Sort{By: dto.DefaultSortBy, Order: dto.DefaultSortOrder},
+		DefaultAuthorName: strings.TrimSpace(dto.DefaultAuthorName),
+		DateFormat:        dto.DateFormat,
+		Language:          dto.Language,
+		TimeZone:          strings.TrimSpace(dto.TimeZone),
+		ConfirmOnDelete: configrepo.ConfirmOnDelete{
+			Category:           dto.ConfirmDeleteCategory,
+			CategoryWithIssues: dto.ConfirmDeleteCategoryWithIssues,
+		},
+	}
+}
+
+// GetLogs は DD-LOG-001 のログ (世代ファイルを含む) を絞り込んで返す。
+// 目的: ログファイルを探さずにアプリ内でエラーの記録を確認できるようにする。
+// 入力: query は絞り込み条件。
+// 出力: LogListDTO を含む Response。
+// エラー: 条件の形式不正、ログの読み取り失敗時に返す。
+// 副作用: ログファイルを読み取る。
+// 並行性: ログの書き込みと排他する。
+// 不変条件: 記録は古い順に並べ、条件に合う新しい記録を残す。
+// 関連DD: DD-LOG-001
+func (a *App) GetLogs(query present.LogQueryDTO) (resp present.Response) {
+	ctx := a.beginCall("GetLogs")
+	defer a.endCall(ctx, &resp)
+	parsed, err := toLogQuery(query)
+	if err != nil {
+		return present.Fail(err)
+	}
+	result, err := a.logger.Read(parsed)
+	if err != nil {
+		return present.Fail(err)
+	}
+	return present.Ok(present.ToLogListDTO(result))
+}
+
+// toLogQuery は DD-LOG-001 のログの絞り込み条件を DTO から変換する。
+func toLogQuery(dto present.LogQueryDTO) (logging.Query, error) {
+	query := logging.Query{MinLevel: logging.LevelDebug, Category: dto.Category, Limit: dto.Limit}
+	switch dto.Level {
+	case "", "debug":
+	case "info":
+		query.MinLevel = logging.LevelInfo
+	case "error":
+		query.MinLevel = logging.LevelError
+	default:
+		return logging.Query{}, errors.New("invalid log level")
+	}
+	for _, bound := range []struct {
+		value  string
+		target *time.Time
+	}{{dto.Since, &query.Since}, {dto.Until, &query.Until}} {
+		if bound.value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			return logging.Query{}, errors.New("invalid timestamp")
+		}
+		*bound.target = parsed
+	}
+	return query, nil
+}
+
+// ExportDiagnostics は DD-DIAG-001 の不具合報告用の診断情報 zip を出力する。
+// プロジェクトを開いていない場合はプロジェクトの集計を除いて出力する。中断は CancelOperation で行う。
+func (a *App) ExportDiagnostics(destPath string) (resp present.Response) {
+	ctx := a.beginCall("ExportDiagnostics")
+	defer a.endCall(ctx, &resp)
+	ctx, done := a.startOperation(ctx, "export_diagnostics")
+	defer done()
+	dto, err := a.exportDiagnostics(ctx, destPath)
+	if err != nil {
+		return present.Fail(err)
+	}
+	return present.Ok(dto)
+}
+
+// StartExportDiagnostics は DD-OP-001 の診断情報 zip の出力をバックグラウンドで開始し、処理IDを返す。
+func (a *App) StartExportDiagnostics(destPath string) (resp present.Response) {
+	ctx := a.beginCall("StartExportDiagnostics")
+	defer a.endCall(ctx, &resp)
+	return a.startAsync(ctx, "export_diagnostics", func(ctx context.Context, _ func(int, int, string)) (any, error) {
+		return a.exportDiagnostics(ctx, destPath)
+	})
+}
+
+// exportDiagnostics は DD-DIAG-001 の診断情報 zip を出力する。
+func (a *App) exportDiagnostics(ctx context.Context, destPath string) (present.DiagnosticsExportDTO, error) {
+	input := diagnostics.Input{
+		ExePath:    a.exePath,
+		ConfigPath: a.configRepo.Path(),
+		SchemaDir:  schemaDir(a.exePath),
+		AppVersion: appVersion,
+	}
+	if session, err := a.project(); err == nil {
+		input.ProjectRoot = session.Root()
+		input.Scanner = session.Scanner()
+	}
+	result, err := diagnostics.Export(ctx, destPath, input)
+	if err != nil {
+		return present.DiagnosticsExportDTO{}, err
+	}
+	return present.DiagnosticsExportDTO{Path: result.Path, FileCount: result.FileCount}, nil
+}
+
+// ValidateProjectRoot は DD-BE-003 の Project Root 検証を行う。
+func (a *App) ValidateProjectRoot(path string) (resp present.Response) {
+	ctx := a.beginCall("ValidateProjectRoot")
+	defer a.endCall(ctx, &resp)
+	service := projectroot.NewService(a.configRepo)
+	result, err := service.ValidateProjectRoot(path)
+	if err != nil {
+		return present.Fail(err)
+	}
+	return present.Ok(toValidationResultDTO(result))
+}
+
+// BrowseForProjectRoot は DD-BE-003 のフォルダ選択ダイアログでプロジェクトルートを選ばせ、検証結果を返す。
+// 目的: パスを手入力させず、OS のフォルダ選択でプロジェクトルートを指定できるようにする。
+// 入力: なし。
+// 出力: 選択したパスの ValidationResultDTO を含む Response。選択が取り消された場合は data を null とする。
+// エラー: 起動前の呼び出し、ダイアログ表示失敗、検証失敗時に返す。
+// 副作用: ダイアログを表示する。設定やプロジェクトルートは変更しない。
+// 並行性: Wails のダイアログは同時に1つのみ表示される前提。
+// 不変条件: 開いているプロジェクトがあれば、その場所を初期表示とする。
+// 関連DD: DD-BE-003
+func (a *App) BrowseForProjectRoot() (resp present.Response) {
+	ctx := a.beginCall("BrowseForProjectRoot")
+	defer a.endCall(ctx, &resp)
+	if a.ctx == nil {
+		return present.Fail(errors.New("application is not started"))
+	}
+	options := runtime.OpenDialogOptions{Title: "Select project root", CanCreateDirectories: true}
+	if session, err := a.project(); err == nil {
+		options.DefaultDirectory = session.Root()
+	}
+	path, err := runtime.OpenDirectoryDialog(a.ctx, options)
+	if err != nil {
+		return present.Fail(err)
+	}
+	if path == "" {
+		return present.Ok(nil)
+	}
+	service := projectroot.NewService(a.configRepo)
+	result, err := service.ValidateProjectRoot(path)
+	if err != nil {
+		return present.Fail(err)
+	}
+	return present.Ok(toValidationResultDTO(result))
+}
+
+// CreateProjectRoot は DD-BE-003 の Project Root 作成を行う。
+func (a *App) CreateProjectRoot(path string) (resp present.Response) {
+	ctx := a.beginCall("CreateProjectRoot")
+	defer a.endCall(ctx, &resp)
+	service := projectroot.NewService(a.configRepo)
+	result, err := service.CreateProjectRoot(path)
+	if err != nil {
+		return present.Fail(err)
+	}
+	return present.Ok(toValidationResultDTO(result))
+}
+
+// SaveLastProjectRoot は DD-BE-003 の last_project_root_path 更新を行う。
+func (a *App) SaveLastProjectRoot(path string) (resp present.Response) {
+	ctx := a.beginCall("SaveLastProjectRoot")
+	defer a.endCall(ctx, &resp)
+	service := projectroot.NewService(a.configRepo)
+	if err := service.SaveLastProjectRoot(path); err != nil {
+		return present.Fail(err)
+	}
+	a.setRoot(path)
+	return present.Ok(a.projectOpened(path))
+}
+
+// OpenProjectRoot は DD-BE-003 のプロジェクトルートの切り替えを行う。
+// 目的: 最近開いたプロジェクトなどから、検証・保存・切り替えを1回の呼び出しで行う。
+// 入力: path は開くプロジェクトルート。
+// 出力: ProjectOpenDTO を含む Response。
+// エラー: パスが有効なプロジェクトルートでない場合、設定の保存失敗時に返す。失敗時は開いているプロジェクトを維持する。
+// 副作用: config.json の last_project_root_path と recent_project_roots を更新し、
+// 前のプロジェクトの監視・事前読み込み・キャッシュを破棄して新しいプロジェクトで準備し直す。
+// 並行性: 切り替え中に他のバインドが呼ばれた場合は、切り替え前後いずれかのプロジェクトで処理される。
+// 不変条件: 保存するパスは正規化済みの絶対パスとする。
+// 関連DD: DD-BE-003, DD-DATA-001, DD-SESSION-001
+func (a *App) OpenProjectRoot(path string) (resp present.Response) {
+	ctx := a.beginCall("OpenProjectRoot")
+	defer a.endCall(ctx, &resp)
+	service := projectroot.NewService(a.configRepo)
+	result, err := service.ValidateProjectRoot(path)
+	if err != nil {
+		return present.Fail(err)
+	}
+	if !result.IsValid {
+		return present.Fail(fmt.Errorf("invalid project root: %s", result.Message))
+	}
+	if err := service.SaveLastProjectRoot(result.NormalizedPath); err != nil {
+		return present.Fail(err)
+	}
+	a.setRoot(result.NormalizedPath)
+	return present.Ok(a.projectOpened(result.NormalizedPath))
+}
+
+// projectOpened は DD-BE-003 の開いたプロジェクトの結果を、DD-PROJCONF-001 の上書きを反映した表示件数とともに返す。
+func (a *App) projectOpened(root string) present.ProjectOpenDTO {
+	cfg, _, err := a.configRepo.Load()
+	if err != nil {
+		cfg = configrepo.DefaultConfig()
+	}
+	return present.ProjectOpenDTO{
+		Root:       root,
+		Warnings:   a.projectWarnings(),
+		LockedBy:   a.projectLockedBy(),
+		UIPageSize: a.pageSize(cfg.UI.PageSize),
+	}
+}
+
+// pageSize は DD-PROJCONF-001 の課題一覧の表示件数を、開いているプロジェクトの設定があれば優先して返す。
+func (a *App) pageSize(global int) int {
+	a.projectMu.RLock()
+	defer a.projectMu.RUnlock()
+	if a.projectConfig.PageSize > 0 {
+		return a.projectConfig.PageSize
+	}
+	return global
+}
+
+// TakeOverProjectLock は DD-LOCK-002 の書き込み用ロックの引き継ぎを行う。
+// 目的: 異常終了したインスタンスのロックが残り読み取り専用で開いたプロジェクトを、利用者の判断で書き込み可能にする。
+// 入力: なし。
+// 出力: 成功時は ProjectOpenDTO、失敗時は APIErrorDTO を含む Response。
+// エラー: プロジェクト未選択、ロックが更新され続けている・保持者が変わった場合 (E_CONFLICT)、書き込み失敗時に返す。
+// 副作用: .ratta.lock を置き換え、一時ファイル残骸を処理して警告を UI へ通知する。
+// 並行性: 引き継ぎの間は projectMu を保持せず、完了後に開いているプロジェクトが同じ場合のみ反映する。
+// 不変条件: 更新の途絶えていないロックは引き継がない。書き込み可能な場合は何もせず現在の状態を返す。
+// 関連DD: DD-LOCK-002, DD-PERSIST-004
+func (a *App) TakeOverProjectLock() (resp present.Response) {
+	ctx := a.beginCall("TakeOverProjectLock")
+	defer a.endCall(ctx, &resp)
+	a.projectMu.RLock()
+	session, lockedBy := a.session, a.lockedBy
+	a.projectMu.RUnlock()
+	if session == nil {
+		return present.Fail(apperr.New(apperr.ErrValidation, "project root is not set"))
+	}
+	if a.modes.Mode() == mod.ModeObserver {
+		return present.Fail(errObserverReadOnly)
+	}
+	if lockedBy == nil {
+		return present.Ok(present.ProjectOpenDTO{Root: session.Root(), Warnings: a.projectWarnings()})
+	}
+	lock, err := projectlock.TakeOver(session.Root(), *lockedBy)
+	if err != nil {
+		return present.Fail(err)
+	}
+	warnings := scanResidue(session.Root())
+	a.projectMu.Lock()
+	if a.session != session {
+		a.projectMu.Unlock()
+		_ = lock.Release()
+		return present.Fail(errors.New("project root changed"))
+	}
+	a.lock = lock
+	a.lockedBy = nil
+	a.warnings = append(a.warnings, warnings...)
+	a.projectMu.Unlock()
+	a.startLockHeartbeat(lock)
+	a.emitWarnings(warnings)
+	a.restartResidueScan()
+	return present.Ok(present.ProjectOpenDTO{Root: session.Root(), Warnings: a.projectWarnings()})
+}
+
+// recentProjectRoots は DD-DATA-001 の最近開いたプロジェクトルートを、未設定でも空配列で返す。
+func recentProjectRoots(roots []string) []string {
+	if roots == nil {
+		return []string{}
+	}
+	return roots
+}
+
+// DetectMode は DD-BE-003 のモード判定を行う。
+// 照合済みの Contractor モードや Observer モードでは現在のモードを返し、パスワード入力を求めない。
+// 判定の結果は DD-LOG-005 の監査ログに記録する。
+func (a *App) DetectMode() (resp present.Response) {
+	ctx := a.beginCall("DetectMode")
+	defer a.endCall(ctx, &resp)
+	return a.detectMode(ctx)
+}
+
+// detectMode は DD-BE-003 の現在のモードを判定して監査ログへ記録する。他のバインディングからは ctx を引き継いで呼び出す。
+func (a *App) detectMode(ctx context.Context) present.Response {
+	if current := a.modes.Mode(); current != mod.ModeVendor {
+		dto := present.ModeDTO{Mode: string(current), Username: a.modes.User()}
+		a.auditMode(ctx, "mode_detected", dto)
+		return present.Ok(dto)
+	}
+	dto, err := a.vendorModeDTO()
+	if err != nil {
+		a.logger.AuditContext(ctx, "mode_detected", map[string]any{"detail": err.Error()})
+		return present.Fail(err)
+	}
+	a.auditMode(ctx, "mode_detected", dto)
+	return present.Ok(dto)
+}
+
+// auditMode は DD-LOG-005 のモードに関わる監査イベントを、切り替え後のモードとユーザー名とともに記録する。
+func (a *App) auditMode(ctx context.Context, event string, dto present.ModeDTO) {
+	a.logger.AuditContext(ctx, event, map[string]any{
+		"mode":              dto.Mode,
+		"username":          dto.Username,
+		"requires_password": dto.RequiresPassword,
+	})
+}
+
+// vendorModeDTO は DD-BE-003 の Vendor モードの ModeDTO を、認証ファイルの有無に応じたパスワード要求とともに返す。
+func (a *App) vendorModeDTO() (present.ModeDTO, error) {
+	service := modedetect.NewService(a.exePath, a.validator)
+	modeValue, requiresPassword, err := service.DetectMode()
+	if err != nil {
+		return present.ModeDTO{}, err
+	}
+	requiresUsername, err := service.RequiresUsername()
+	if err != nil {
+		return present.ModeDTO{}, err
+	}
+	requiresTOTP, err := service.RequiresTOTP("")
+	if err != nil {
+		return present.ModeDTO{}, err
+	}
+	return present.ModeDTO{
+		Mode:             string(modeValue),
+		RequiresPassword: requiresPassword,
+		RequiresUsername: requiresUsername,
+		RequiresTOTP:     requiresTOTP,
+	}, nil
+}
+
+// LockMode は DD-MODE-001 の Contractor モードを解除して Vendor モードへ戻す。
+// 目的: 席を離れる際などに、無操作時間の上限を待たずに Contractor の権限を手放せるようにする。
+// 入力: なし。
+// 出力: 解除後の ModeDTO を含む Response。
+// エラー: 認証ファイルの確認に失敗した場合に返す。
+// 副作用: Contractor モードであった場合は modeLockedEvent で再認証が必要になったことを UI へ通知する。
+// 並行性: モードの切り替えは modesession が排他する。
+// 不変条件: Contractor モード以外では何も変更しない。
+// 関連DD: DD-MODE-001, DD-BE-003
+func (a *App) LockMode() (resp present.Response) {
+	ctx := a.beginCall("LockMode")
+	defer a.endCall(ctx, &resp)
+	if !a.modes.Lock() {
+		return a.detectMode(ctx)
+	}
+	dto, err := a.notifyModeLocked(ctx, modesession.ReasonManual)
+	if err != nil {
+		return present.Fail(err)
+	}
+	return present.Ok(dto.Mode)
+}
+
+// notifyModeLocked は DD-MODE-001 の Contractor モードを解除したことを記録し、再認証が必要になったことを UI へ通知する。
+// 無操作時間の上限による解除はバインディングの呼び出しではないため、ctx にリクエスト ID を持たない。
+func (a *App) notifyModeLocked(ctx context.Context, reason modesession.Reason) (present.ModeLockedDTO, error) {
+	a.logger.AuditContext(ctx, "mode_locked", map[string]any{"reason": string(reason)})
+	mode, err := a.vendorModeDTO()
+	if err != nil {
+		// 認証ファイルを確認できない場合も、再認証を求める通知は届ける。
+		mode = present.ModeDTO{Mode: string(mod.ModeVendor), RequiresPassword: true}
+	}
+	dto := present.ModeLockedDTO{Reason: string(reason), Mode: mode}
+	a.emitEvent(modeLockedEvent, dto)
+	return dto, err
+}
+
+// VerifyContractorPassword は DD-BE-003/DD-CLI-007 のパスワード検証を行う。
+// username は users.json のアカウント名を表し、contractor.json の共有パスワードで認証する場合は空とする。
+// code は DD-CLI-008 のワンタイムコードを表し、TOTP を使わない場合は無視する。
+// remember は DD-MODE-003 のこの端末で認証を記憶するかを表し、false の場合は以前の記憶を消去する。
+func (a *App) VerifyContractorPassword(username, password, code string, remember bool) (resp present.Response) {
+	ctx := a.beginCall("VerifyContractorPassword")
+	defer a.endCall(ctx, &resp)
+	if a.modes.Mode() == mod.ModeObserver {
+		// ロックを持たずに開いているため、Observer から書き込み可能なモードへは切り替えない。
+		return present.Fail(errObserverReadOnly)
+	}
+	service := modedetect.NewService(a.exePath, a.validator)
+	modeValue, err := service.VerifyContractorPassword(username, password, code)
+	if err != nil {
+		// DD-MODE-002 の総当たりの兆候を追えるよう、失敗と連続失敗回数を DD-LOG-005 の監査ログに記録する。
+		// パスワードやワンタイムコードは記録しない。
+		a.logger.AuditContext(ctx, "contractor_auth_failed", map[string]any{
+			"username":        username,
+			"failed_attempts": service.FailedAttempts(),
+			"detail":          err.Error(),
+		})
+		return present.Fail(err)
+	}
+	a.modes.Enter(modeValue, username)
+	a.updateRememberedUnlock(ctx, service, username, password, remember)
+	dto := present.ModeDTO{Mode: string(modeValue), RequiresPassword: false, Username: username}
+	a.auditMode(ctx, "contractor_auth_succeeded", dto)
+	return present.Ok(dto)
+}
+
+// updateRememberedUnlock は DD-MODE-003 の照合成功後に、この端末での認証の記憶を保存または消去する。
+// 記憶は利便のためのものであり、失敗してもログに残すのみで照合の結果は変えない。
+func (a *App) updateRememberedUnlock(ctx context.Context, service *modedetect.Service, username, password string, remember bool) {
+	action := "forget"
+	var err error
+	if remember {
+		action = "remember"
+		err = service.Remember(username, password)
+	} else {
+		err = service.Forget()
+	}
+	if err != nil {
+		a.logger.ErrorContext(ctx, "update remembered contractor unlock failed", map[string]any{"action": action, "detail": err.Error()})
+	}
+}
+
+// UnlockRememberedContractor は DD-MODE-003 の記憶した認証で、パスワード入力なしに Contractor モードへ切り替える。
+// 目的: 信頼した端末での起動時にパスワード入力を省く。
+// 入力: なし。
+// 出力: 切り替え後、または切り替えなかった場合は現在の ModeDTO を含む Response。
+// エラー: 返さない。記憶が無い・無効な場合や読み取りに失敗した場合は現在のモードのまま返す。
+// 副作用: 成功時はモードを切り替え、無効になった記憶は消去する。読み取りの失敗はログに残す。
+// 並行性: モードの切り替えは modesession が排他する。
+// 不変条件: Vendor モードでのみ切り替える。UI は起動時にのみ呼び出し、DD-MODE-001 の解除後の再認証には用いない。
+// 関連DD: DD-MODE-003, DD-BE-005
+func (a *App) UnlockRememberedContractor() (resp present.Response) {
+	ctx := a.beginCall("UnlockRememberedContractor")
+	defer a.endCall(ctx, &resp)
+	if a.modes.Mode() != mod.ModeVendor {
+		return a.detectMode(ctx)
+	}
+	service := modedetect.NewService(a.exePath, a.validator)
+	modeValue, username, err := service.UnlockRemembered()
+	if err != nil {
+		if !errors.Is(err, modedetect.ErrNotRemembered) {
+			a.logger.ErrorContext(ctx, "unlock remembered contractor failed", map[string]any{"detail": err.Error()})
+		}
+		return a.detectMode(ctx)
+	}
+	a.modes.Enter(modeValue, username)
+	dto := present.ModeDTO{Mode: string(modeValue), RequiresPassword: false, Username: username}
+	a.auditMode(ctx, "contractor_unlocked_remembered", dto)
+	return present.Ok(dto)
+}
+
+// EnterObserverMode は DD-BE-003 の Observer モードへ切り替える。
+// 目的: 共有フォルダを確認する管理者が、誤って編集しないよう閲覧のみで利用できるようにする。
+// 入力: なし。
+// 出力: ModeDTO を含む Response。
+// エラー: なし。
+// 副作用: DD-PERSIST-004 の一時ファイル残骸の定期的な検出を止め、保持している書き込み用ロックを解放して他のインスタンスが書き込み用に開けるようにする。
+// 並行性: ロックの差し替えは projectMu で保護する。
+// 不変条件: Observer から他のモードへは戻さない (戻すには再起動する)。
+// 関連DD: DD-BE-003, DD-LOCK-002
+func (a *App) EnterObserverMode() (resp present.Response) {
+	ctx := a.beginCall("EnterObserverMode")
+	defer a.endCall(ctx, &resp)
+	a.modes.Enter(mod.ModeObserver, "")
+	a.projectMu.Lock()
+	previous := a.lock
+	a.lock = nil
+	a.projectMu.Unlock()
+	// 残骸の削除は書き込み用ロックを保持している間に限るため、他のインスタンスが開けるようになる前に止める。
+	a.stopResidueScan()
+	if previous != nil {
+		_ = previous.Release()
+	}
+	dto := present.ModeDTO{Mode: string(mod.ModeObserver)}
+	a.auditMode(ctx, "observer_mode_entered", dto)
+	return present.Ok(dto)
+}
+
+// ListCategories は DD-LOAD-002 のカテゴリ一覧を返す。
+func (a *App) ListCategories() present.Response {
+	return a.listCategoriesFor(a.beginCall("ListCategories"))
+}
+
+// listCategoriesFor は DD-LOAD-002 のカテゴリ一覧を返す。GUI からの呼び出しの場合のみ無操作時間の計測をやり直す。
+func (a *App) listCategoriesFor(ctx context.Context) (resp present.Response) {
+	defer a.endCall(ctx, &resp)
+	session, err := a.projectFor(ctx)
+	if err != nil {
+		return present.Fail(err)
+	}
+	ctx, done := a.startOperation(ctx, "list_categories")
+	defer done()
+	result, err := categoryscan.ScanContext(ctx, session.Root())
+	if err != nil {
+		return present.Fail(err)
+	}
+	categories := make([]present.CategoryDTO, 0, len(result.Categories))
+	for _, category := range result.Categories {
+		categories = append(categories, present.ToCategoryDTO(category))
+	}
+	dto := present.CategoryListDTO{
+		Categories: categories,
+		Errors:     result.ErrorCount,
+	}
+	return present.Ok(dto)
+}
+
+// CreateCategory は DD-BE-003 のカテゴリ作成を行う。
+func (a *App) CreateCategory(name string) present.Response {
+	return a.createCategoryFor(a.beginCall("CreateCategory"), name)
+}
+
+// createCategoryFor は DD-BE-003 のカテゴリ作成を、callMode による呼び出し元の操作モードで行う。
+func (a *App) createCategoryFor(ctx context.Context, name string) (resp present.Response) {
+	defer a.endCall(ctx, &resp)
+	session, err := a.writableProjectFor(ctx)
+	if err != nil {
+		return present.Fail(err)
+	}
+	currentMode, _ := a.callMode(ctx)
+	unlock := session.LockCategories(name)
+	defer unlock()
+	category, err := session.Categories().CreateCategory(name, currentMode)
+	if err != nil {
+		return present.Fail(err)
+	}
+	a.autoCommit(ctx, session, gitcommit.Change{Operation: gitCategoryCreated, Category: name})
+	session.InvalidateCategory(name)
+	dto := present.ToManagedCategoryDTO(category)
+	a.emitEvent(categoryCreatedEvent, dto)
+	return present.Ok(dto)
+}
+
+// RenameCategory は DD-BE-003 のカテゴリ名変更を行う。
+func (a *App) RenameCategory(oldName, newName string) present.Response {
+	return a.renameCategoryFor(a.beginCall("RenameCategory"), oldName, newName)
+}
+
+// renameCategoryFor は DD-BE-003 のカテゴリ名変更を、完了まで待って行う。
+func (a *App) renameCategoryFor(ctx context.Context, oldName, newName string) (resp present.Response) {
+	defer a.endCall(ctx, &resp)
+	session, err := a.writableProjectFor(ctx)
+	if err != nil {
+		return present.Fail(err)
+	}
+	dto, err := a.renameCategory(ctx, session, oldName, newName)
+	if err != nil {
+		return present.Fail(err)
+	}
+	return present.Ok(dto)
+}
+
+// StartRenameCategory は DD-OP-001 のカテゴリ名変更をバックグラウンドで開始し、処理IDを返す。
+// 課題の多いカテゴリでは共有フォルダ上の移動に時間がかかるため、UI を待たせない。
+func (a *App) StartRenameCategory(oldName, newName string) (resp present.Response) {
+	ctx := a.beginCall("StartRenameCategory")
+	defer a.endCall(ctx, &resp)
+	session, err := a.writableProject()
+	if err != nil {
+		return present.Fail(err)
+	}
+	return a.startAsync(ctx, "rename_category", func(ctx context.Context, _ func(int, int, string)) (any, error) {
+		return a.renameCategory(ctx, session, oldName, newName)
+	})
+}
+
+// renameCategory は DD-BE-003 のカテゴリ名変更を行い、変更を UI へ通知する。
+func (a *App) renameCategory(ctx context.Context, session *projectsession.Session, oldName, newName string) (present.CategoryDTO, error) {
+	unlock := session.LockCategories(oldName, newName)
+	defer unlock()
+	currentMode, _ := a.callMode(ctx)
+	category, err := session.Categories().RenameCategory(oldName, newName, currentMode)
+	if err != nil {
+		return present.CategoryDTO{}, err
+	}
+	a.autoCommit(ctx, session, gitcommit.Change{Operation: gitCategoryRenamed, Category: newName})
+	session.InvalidateCategory(oldName)
+	session.InvalidateCategory(newName)
+	dto := present.ToManagedCategoryDTO(category)
+	a.emitEvent(categoryRenamedEvent, present.CategoryRenamedDTO{OldName: oldName, Category: dto})
+	return dto, nil
+}
+
+// ListRenameResidues は DD-BE-003 の中断されたカテゴリ名変更の残骸を、完了・取り消しの判断に必要な状態とともに返す。
+func (a *App) ListRenameResidues() (resp present.Response) {
+	ctx := a.beginCall("ListRenameResidues")
+	defer a.endCall(ctx, &resp)
+	session, err := a.project()
+	if err != nil {
+		return present.Fail(err)
+	}
+	residues, err := session.Categories().RenameResidues()
+	if err != nil {
+		return present.Fail(err)
+	}
+	dtos := make([]present.RenameResidueDTO, 0, len(residues))
+	for _, residue := range residues {
+		dtos = append(dtos, present.ToRenameResidueDTO(residue))
+	}
+	return present.Ok(dtos)
+}
+
+// CompleteCategoryRename は DD-BE-003 の中断されたカテゴリ名変更を完了し、読み取り専用でなくなったカテゴリを UI へ通知する。
+func (a *App) CompleteCategoryRename(name string) (resp present.Response) {
+	ctx := a.beginCall("CompleteCategoryRename")
+	defer a.endCall(ctx, &resp)
+	session, err := a.writableProject()
+	if err != nil {
+		return present.Fail(err)
+	}
+	unlock := session.LockCategories(name)
+	defer unlock()
+	category, err := session.Categories().RecoverRename(name, a.modes.Mode())
+	if err != nil {
+		return present.Fail(err)
+	}
+	a.autoCommit(ctx, session, gitcommit.Change{Operation: gitCategoryRenamed, Category: category.Name})
+	session.InvalidateCategory(name)
+	dto := present.ToManagedCategoryDTO(category)
+	a.emitEvent(categoryUpdatedEvent, dto)
+	return present.Ok(dto)
+}
+
+// RollbackCategoryRename は DD-BE-003 の中断されたカテゴリ名変更を取り消し、変更前の名前へ戻したことを UI へ通知する。
+func (a *App) RollbackCategoryRename(name string) (resp present.Response) {
+	ctx := a.beginCall("RollbackCategoryRename")
+	defer a.endCall(ctx, &resp)
+	session, err := a.writableProject()
+	if err != nil {
+		return present.Fail(err)
+	}
+	unlock := session.LockCategories(name)
+	defer unlock()
+	category, err := session.Categories().RollbackRename(name, a.modes.Mode())
+	if err != nil {
+		return present.Fail(err)
+	}
+	a.autoCommit(ctx, session, gitcommit.Change{Operation: gitCategoryRenamed, Category: category.Name})
+	session.InvalidateCategory(name)
+	session.InvalidateCategory(category.Name)
+	dto := present.ToManagedCategoryDTO(category)
+	a.emitEvent(categoryRenamedEvent, present.CategoryRenamedDTO{OldName: name, Category: dto})
+	return present.Ok(dto)
+}
+
+// UpdateCategoryMeta は DD-CATMETA-001 のカテゴリメタデータ更新を行う。
+func (a *App) UpdateCategoryMeta(name string, input present.CategoryMetaDTO) (resp present.Response) {
+	ctx := a.beginCall("UpdateCategoryMeta")
+	defer a.endCall(ctx, &resp)
+	session, err := a.writableProject()
+	if err != nil {
+		return present.Fail(err)
+	}
+	unlock := session.LockCategories(name)
+	defer unlock()
+	category, err := session.Categories().UpdateCategoryMeta(name, categorymeta.Meta{
+		Description:         input.Description,
+		Color:               input.Color,
+		SortWeight:          input.SortWeight,
+		DefaultAssignee:     input.DefaultAssignee,
+		DefaultPriority:     input.DefaultPriority,
+		DescriptionTemplate: input.DescriptionTemplate,
+	}, a.modes.Mode())
+	if err != nil {
+		return present.Fail(err)
+	}
+	a.autoCommit(ctx, session, gitcommit.Change{Operation: gitCategoryUpdated, Category: name})
+	dto := present.ToManagedCategoryDTO(category)
+	a.emitEvent(categoryUpdatedEvent, dto)
+	return present.Ok(dto)
+}
+
+// ArchiveCategory は DD-CATMETA-002 のカテゴリアーカイブを行う。
+func (a *App) ArchiveCategory(name string) (resp present.Response) {
+	ctx := a.beginCall("ArchiveCategory")
+	defer a.endCall(ctx, &resp)
+	return a.setCategoryArchived(ctx, name, true)
+}
+
+// UnarchiveCategory は DD-CATMETA-002 のカテゴリアーカイブ解除を行う。
+func (a *App) UnarchiveCategory(name string) (resp present.Response) {
+	ctx := a.beginCall("UnarchiveCategory")
+	defer a.endCall(ctx, &resp)
+	return a.setCategoryArchived(ctx, name, false)
+}
+
+// setCategoryArchived は DD-CATMETA-002 のアーカイブ切り替えを共通化する。
+func (a *App) setCategoryArchived(ctx context.Context, name string, archived bool) present.Response {
+	session, err := a.writableProject()
+	if err != nil {
+		return present.Fail(err)
+	}
+	unlock := session.LockCategories(name)
+	defer unlock()
+	category, err := session.Categories().SetCategoryArchived(name, archived, a.modes.Mode())
+	if err != nil {
+		return present.Fail(err)
+	}
+	a.autoCommit(ctx, session, gitcommit.Change{Operation: gitCategoryUpdated, Category: name})
+	session.InvalidateCategory(name)
+	dto := present.ToManagedCategoryDTO(category)
+	a.emitEvent(categoryUpdatedEvent, dto)
+	return present.Ok(dto)
+}
+
+// ReorderCategories は DD-PROJMETA-001 のカテゴリ表示順の保存を行う。
+func (a *App) ReorderCategories(names []string) (resp present.Response) {
+	ctx := a.beginCall("ReorderCategories")
+	defer a.endCall(ctx, &resp)
+	session, err := a.writableProject()
+	if err != nil {
+		return present.Fail(err)
+	}
+	unlock := session.LockCategories()
+	defer unlock()
+	if err := session.Categories().ReorderCategories(names, a.modes.Mode()); err != nil {
+		return present.Fail(err)
+	}
+	a.autoCommit(ctx, session, gitcommit.Change{Operation: gitCategoriesReordered})
+	a.emitEvent(categoryReorderedEvent, present.CategoryOrderDTO{Names: names})
+	return present.Ok(nil)
+}
+
+// DeleteCategory は DD-BE-003 のカテゴリ削除を行う。
+func (a *App) DeleteCategory(name string) present.Response {
+	return a.deleteCategoryFor(a.beginCall("DeleteCategory"), name)
+}
+
+// deleteCategoryFor は DD-BE-003 の空のカテゴリの削除を、callMode による呼び出し元の操作モードで行う。
+func (a *App) deleteCategoryFor(ctx context.Context, name string) (resp present.Response) {
+	defer a.endCall(ctx, &resp)
+	session, err := a.writableProjectFor(ctx)
+	if err != nil {
+		return present.Fail(err)
+	}
+	currentMode, _ := a.callMode(ctx)
+	unlock := session.LockCategories(name)
+	defer unlock()
+	if err := session.Categories().DeleteCategory(name, currentMode); err != nil {
+		return present.Fail(err)
+	}
+	a.recordAudit(ctx, session, audittrail.Record{Operation: auditCategoryDeleted, Category: name})
+	session.InvalidateCategory(name)
+	a.emitEvent(categoryDeletedEvent, present.CategoryDeletedDTO{Name: name})
+	return present.Ok(nil)
+}
+
+// ForceDeleteCategory は DD-TRASH-001 の非空カテゴリのゴミ箱への退避を行う。
+func (a *App) ForceDeleteCategory(name string) (resp present.Response) {
+	ctx := a.beginCall("ForceDeleteCategory")
+	defer a.endCall(ctx, &resp)
+	session, err := a.writableProject()
+	if err != nil {
+		return present.Fail(err)
+	}
+	unlock := session.LockCategories(name)
+	defer unlock()
+	entry, err := session.Categories().ForceDeleteCategory(name, a.modes.Mode())
+	if err != nil {
+		return present.Fail(err)
+	}
+	a.recordAudit(ctx, session, audittrail.Record{
+		Operation: auditCategoryTrashed,
+		Category:  name,
+		Changes: []audittrail.Change{
+			{Field: "trash_id", After: entry.TrashID},
+			{Field: "issue_count", After: strconv.Itoa(entry.IssueCount)},
+		},
+	})
+	session.InvalidateCategory(name)
+	a.emitEvent(categoryDeletedEvent, present.CategoryDeletedDTO{Name: name, TrashID: entry.TrashID})
+	return present.Ok(present.CategoryTrashDTO{
+		TrashID:    entry.TrashID,
+		Category:   entry.Category,
+		Path:       entry.Path,
+		IssueCount: entry.IssueCount,
+	})
+}
+
+// GetCategoryStats は DD-STATS-001 のカテゴリ集計を返す。
+func (a *App) GetCategoryStats(category string) (resp present.Response) {
+	ctx := a.beginCall("GetCategoryStats")
+	defer a.endCall(ctx, &resp)
+	session, err := a.project()
+	if err != nil {
+		return present.Fail(err)
+	}
+	ctx, done := a.startOperation(ctx, "category_stats")
+	defer done()
+	stats, err := session.Scanner().CategoryStatsContext(ctx, filepath.Join(session.Root(), category), category)
+	if err != nil {
+		return present.Fail(err)
+	}
+	return present.Ok(present.ToCategoryStatsDTO(stats))
+}
+
+// ListIssues は DD-BE-003 の課題一覧を返す。
+func (a *App) ListIssues(category string, query present.IssueListQueryDTO) present.Response {
+	return a.listIssuesFor(a.beginCall("ListIssues"), category, query)
+}
+
+// listIssuesFor は DD-BE-003 の課題一覧を返す。
+func (a *App) listIssuesFor(ctx context.Context, category string, query present.IssueListQueryDTO) (resp present.Response) {
+	defer a.endCall(ctx, &resp)
+	session, err := a.projectFor(ctx)
+	if err != nil {
+		return present.Fail(err)
+	}
+	result, err := session.ListIssues(category, issueops.IssueListQuery{
+		Page:      query.Page,
+		PageSize:  query.PageSize,
+		SortBy:    query.SortBy,
+		SortOrder: query.SortOrder,
+		Status:    query.Status,
+		Priority:  query.Priority,
+		Cursor:    query.Cursor,
+	})
+	if err != nil {
+		return present.Fail(err)
+	}
+	return present.Ok(present.ToIssueListDTO(result))
+}
+
+// GetChangesSince は DD-CHANGES-001 の timestamp 以降の変更差分を返す。timestamp が空の場合は全件を返す。
+func (a *App) GetChangesSince(timestamp string) (resp present.Response) {
+	ctx := a.beginCall("GetChangesSince")
+	defer a.endCall(ctx, &resp)
+	session, err := a.project()
+	if err != nil {
+		return present.Fail(err)
+	}
+	var since time.Time
+	if timestamp != "" {
+		parsed, err := time.Parse(time.RFC3339, timestamp)
+		if err != nil {
+			return present.Fail(errors.New("invalid timestamp"))
+		}
+		since = parsed
+	}
+	changes, err := session.Issues().ChangesSince(since)
+	if err != nil {
+		return present.Fail(err)
+	}
+	return present.Ok(present.ToChangesDTO(changes))
+}
+
+// RefreshProject は DD-REFRESH-001 の外部変更の確認を行う。
+// 目的: 共有フォルダで作業中に他の利用者が加えた課題・カテゴリの変更を、再読込の際にまとめて示す。
+// 入力: なし。
+// 出力: 成功時は RefreshDTO、失敗時は APIErrorDTO を含む Response。
+// エラー: プロジェクト未選択、カテゴリ走査の失敗、中断された場合に返す。
+// 副作用: 確認済みの状態を更新し、変更のあったカテゴリのキャッシュを破棄する。
+// 並行性: 中断可能な処理として登録し、課題操作と同時に実行してよい。
+// 不変条件: このアプリで行った変更は含めない。プロジェクトを開いた直後の事前読み込みの完了前は、その時点の状態を基準として記録し差分を返さない。
+// 関連DD: DD-REFRESH-001, DD-CANCEL-001
+func (a *App) RefreshProject() (resp present.Response) {
+	ctx := a.beginCall("RefreshProject")
+	defer a.endCall(ctx, &resp)
+	session, err := a.project()
+	if err != nil {
+		return present.Fail(err)
+	}
+	ctx, done := a.startOperation(ctx, "refresh_project")
+	defer done()
+	changes, err := session.Refresh(ctx)
+	if err != nil {
+		return present.Fail(err)
+	}
+	return present.Ok(present.ToRefreshDTO(changes))
+}
+
+// SearchIssues は DD-SEARCH-001 の全文検索を行う。scope が空の場合はプロジェクト全体を対象とする。
+func (a *App) SearchIssues(query string, scope string) (resp present.Response) {
+	ctx := a.beginCall("SearchIssues")
+	defer a.endCall(ctx, &resp)
+	session, err := a.project()
+	if err != nil {
+		return present.Fail(err)
+	}
+	ctx, done := a.startOperation(ctx, "search")
+	defer done()
+	hits, err := session.Issues().SearchIssuesContext(ctx, query, scope, 0)
+	if err != nil {
+		return present.Fail(err)
+	}
+	items := make([]present.SearchHitDTO, 0, len(hits))
+	for _, hit := range hits {
+		items = append(items, present.ToSearchHitDTO(hit))
+	}
+	return present.Ok(present.SearchResultDTO{Query: query, Scope: scope, Hits: items})
+}
+
+// SetSQLiteCacheEnabled は DD-CACHE-001 の SQLite キャッシュの有効・無効を切り替える。
+func (a *App) SetSQLiteCacheEnabled(enabled bool) (resp present.Response) {
+	ctx := a.beginCall("SetSQLiteCacheEnabled")
+	defer a.endCall(ctx, &resp)
+	session, err := a.writableProject()
+	if err != nil {
+		return present.Fail(err)
+	}
+	if err := session.Issues().SetCacheEnabled(enabled, a.modes.Mode()); err != nil {
+		return present.Fail(err)
+	}
+	session.InvalidateAll()
+	return present.Ok(nil)
+}
+
+// GetIssue は DD-BE-003 の課題詳細を取得する。
+func (a *App) GetIssue(category, issueID string) present.Response {
+	return a.getIssueFor(a.beginCall("GetIssue"), category, issueID)
+}
+
+// getIssueFor は DD-BE-003 の課題詳細を返す。
+func (a *App) getIssueFor(ctx context.Context, category, issueID string) (resp present.Response) {
+	defer a.endCall(ctx, &resp)
+	session, err := a.projectFor(ctx)
+	if err != nil {
+		return present.Fail(err)
+	}
+	detail, err := session.GetIssue(category, issueID)
+	if err != nil {
+		return present.Fail(err)
+	}
+	return present.Ok(present.ToIssueDetailDTO(detail))
+}
+
+// GetIssueHistory は DD-GIT-002 のプロジェクトルートを含む git リポジトリのコミット履歴から、課題の各版を新しい順に返す。
+// 目的: 課題がどのように変化してきたかを、コミットごとの作成者・操作名と当時の内容で確認できるようにする。
+// 入力: category はカテゴリ名、issueID は課題ID。
+// 出力: IssueHistoryDTO の Response。
+// エラー: プロジェクト未選択、カテゴリ名・課題IDの不正、プロジェクトルートが git リポジトリに含まれない場合、履歴の読み出しに失敗した場合に返す。
+// 副作用: なし。中断は CancelOperation で行う。
+// 並行性: 読み取りのみで、自動コミットと同時に実行してもよい。
+// 不変条件: 作業ツリーの未コミットの変更は含めない。現在の内容は GetIssue で取得する。
+// 関連DD: DD-GIT-002, DD-GIT-001, DD-CANCEL-001
+func (a *App) GetIssueHistory(category, issueID string) (resp present.Response) {
+	ctx := a.beginCall("GetIssueHistory")
+	defer a.endCall(ctx, &resp)
+	session, err := a.project()
+	if err != nil {
+		return present.Fail(err)
+	}
+	ctx, done := a.startOperation(ctx, "issue_history")
+	defer done()
+	history, err := session.Issues().IssueHistory(ctx, category, issueID, 0)
+	if err != nil {
+		return present.Fail(err)
+	}
+	return present.Ok(present.ToIssueHistoryDTO(category, issueID, history))
+}
+
+// CreateIssue は DD-BE-003 の課題作成を行う。
+func (a *App) CreateIssue(category string, dto present.IssueCreateDTO) present.Response {
+	return a.createIssueFor(a.beginCall("CreateIssue"), category, dto)
+}
+
+// createIssueFor は DD-BE-003 の課題作成を行う。起票元 (origin_company) は呼び出し元の操作モードとする。
+func (a *App) createIssueFor(ctx context.Context, category string, dto present.IssueCreateDTO) (resp present.Response) {
+	defer a.endCall(ctx, &resp)
+	session, err := a.writableProjectFor(ctx)
+	if err != nil {
+		return present.Fail(err)
+	}
+	currentMode, _ := a.callMode(ctx)
+	unlock := session.LockCategoryShared(category)
+	defer unlock()
+	pending := a.beginJournal(ctx, session, journalIssueCreated, category, "")
+	detail, err := session.Issues().CreateIssue(category, currentMode, issueops.IssueCreateInput{
+		Title:       dto.Title,
+		Description: dto.Description,
+		DueDate:     dto.DueDate,
+		Priority:    issue.Priority(dto.Priority),
+		Assignee:    dto.Assignee,
+	})
+	if err != nil {
+		return present.Fail(err)
+	}
+	a.commitJournal(ctx, pending, detail.Issue.IssueID, issueFilePath(category, detail.Issue.IssueID))
+	a.recordAudit(ctx, session, audittrail.Record{
+		Operation: journalIssueCreated,
+		Category:  category,
+		IssueID:   detail.Issue.IssueID,
+		Changes:   audittrail.Created(detail.Issue),
+	})
+	session.InvalidateIssue(category, detail.Issue.IssueID)
+	detailDTO := present.ToIssueDetailDTO(detail)
+	a.emitEvent(issueCreatedEvent, detailDTO)
+	a.notifyWebhooks(ctx, session, projectmeta.WebhookEventIssueCreated, "", detailDTO)
+	a.runPostSave(ctx, session, "", detail.Issue, configrepo.HookEventIssueCreated)
+	return present.Ok(detailDTO)
+}
+
+// UpdateIssue は DD-BE-003 の課題更新を行う。
+func (a *App) UpdateIssue(category, issueID string, dto present.IssueUpdateDTO) present.Response {
+	return a.updateIssueFor(a.beginCall("UpdateIssue"), category, issueID, dto)
+}
+
+// updateIssueFor は DD-BE-003 の課題更新を行う。expected_revision が指定された場合は DD-PERSIST-006 の競合を検出する。
+func (a *App) updateIssueFor(ctx context.Context, category, issueID string, dto present.IssueUpdateDTO) (resp present.Response) {
+	defer a.endCall(ctx, &resp)
+	session, err := a.writableProjectFor(ctx)
+	if err != nil {
+		return present.Fail(err)
+	}
+	currentMode, _ := a.callMode(ctx)
+	unlock := session.LockIssue(category, issueID)
+	defer unlock()
+	pending := a.beginJournal(ctx, session, journalIssueUpdated, category, issueID, issueFilePath(category, issueID))
+	// 監査証跡の変更内容のため更新前の課題を読む。読めない場合は更新で同じエラーとなる。
+	before, beforeErr := session.Issues().GetIssue(category, issueID)
+	detail, err := session.Issues().UpdateIssue(category, issueID, currentMode, issueops.IssueUpdateInput{
+		Title:            dto.Title,
+		Description:      dto.Description,
+		DueDate:          dto.DueDate,
+		Priority:         issue.Priority(dto.Priority),
+		Status:           issue.Status(dto.Status),
+		Assignee:         dto.Assignee,
+		ExpectedRevision: dto.ExpectedRevision,
+	})
+	if err != nil {
+		return present.Fail(err)
+	}
+	a.commitJournal(ctx, pending, "")
+	record := audittrail.Record{Operation: journalIssueUpdated, Category: category, IssueID: issueID}
+	if beforeErr == nil {
+		record.Changes = audittrail.Diff(before.Issue, detail.Issue)
+	}
+	a.recordAudit(ctx, session, record)
+	session.InvalidateIssue(category, detail.Issue.IssueID)
+	detailDTO := present.ToIssueDetailDTO(detail)
+	a.emitEvent(issueUpdatedEvent, detailDTO)
+	a.notifyWebhooks(ctx, session, projectmeta.WebhookEventIssueUpdated, "", detailDTO)
+	hookEvents := []string{configrepo.HookEventIssueUpdated}
+	if beforeErr == nil && before.Issue.Status != issue.StatusClosed && detail.Issue.Status == issue.StatusClosed {
+		hookEvents = append(hookEvents, configrepo.HookEventIssueClosed)
+	}
+	a.runPostSave(ctx, session, "", detail.Issue, hookEvents...)
+	return present.Ok(detailDTO)
+}
+
+// AddComment は DD-BE-003 のコメント追加を行う。
+// 添付の申告された種類が拡張子から判定した種類と異なる場合も申告どおりに保存し、Response の warnings で知らせる。
+func (a *App) AddComment(category, issueID string, dto present.CommentCreateDTO) present.Response {
+	return a.addCommentFor(a.beginCall("AddComment"), category, issueID, dto)
+}
+
+// addCommentFor は DD-BE-003 のコメント追加を行う。作成者名の既定値は呼び出し元が Contractor の場合のみ補う。
+func (a *App) addCommentFor(ctx context.Context, category, issueID string, dto present.CommentCreateDTO) (resp present.Response) {
+	defer a.endCall(ctx, &resp)
+	session, err := a.writableProjectFor(ctx)
+	if err != nil {
+		return present.Fail(err)
+	}
+	attachments := make([]issueops.CommentAttachmentInput, 0, len(dto.Attachments))
+	warnings := []present.APIErrorDTO{}
+	for _, attachment := range dto.Attachments {
+		// 上限を超えるファイルをメモリへ読み込まないよう、読み込む前にサイズと種類を確認する。
+		file, err := issueops.InspectAttachmentFile(attachment.SourcePath)
+		if err != nil {
+			return present.Fail(err)
+		}
+		data, err := os.ReadFile(file.Path)
+		if err != nil {
+			return present.Fail(err)
+		}
+		original := attachment.OriginalFileName
+		if original == "" {
+			original = file.Name
+		}
+		if issueops.IsMimeMismatch(attachment.MimeType, file.MimeType) {
+			warnings = append(warnings, present.ToMimeMismatchWarningDTO(file.Path, attachment.MimeType, file.MimeType))
+		}
+		attachments = append(attachments, issueops.CommentAttachmentInput{
+			OriginalName: original,
+			Data:         data,
+			MimeType:     attachment.MimeType,
+		})
+	}
+	unlock := session.LockIssue(category, issueID)
+	defer unlock()
+	currentMode, user := a.callMode(ctx)
+	authorName := dto.AuthorName
+	if authorName == "" && currentMode == mod.ModeContractor {
+		// 作成者名が未入力の場合は、DD-CLI-007 でログインしたアカウント名を用いる。
+		authorName = user
+	}
+	pending := a.beginJournal(ctx, session, journalCommentAdded, category, issueID, issueFilePath(category, issueID))
+	detail, err := session.Issues().AddComment(category, issueID, currentMode, issueops.CommentCreateInput{
+		Body:             dto.Body,
+		AuthorName:       authorName,
+		Attachments:      attachments,
+		ExpectedRevision: dto.ExpectedRevision,
+	})
+	if err != nil {
+		return present.Fail(err)
+	}
+	var created []string
+	record := audittrail.Record{Operation: journalCommentAdded, Category: category, IssueID: issueID, Author: authorName}
+	if comments := detail.Issue.Comments; len(comments) > 0 {
+		added := comments[len(comments)-1]
+		created = attachmentFilePaths(category, comments[len(comments)-1:])
+		record.Changes = []audittrail.Change{{Field: "comments", After: added.CommentID}}
+		for _, attachment := range added.Attachments {
+			record.Changes = append(record.Changes, audittrail.Change{Field: "attachments", After: attachment.FileName})
+		}
+	}
+	a.commitJournal(ctx, pending, "", created...)
+	a.recordAudit(ctx, session, record)
+	session.InvalidateIssue(category, detail.Issue.IssueID)
+	detailDTO := present.ToIssueDetailDTO(detail)
+	a.emitEvent(issueCommentedEvent, detailDTO)
+	a.notifyWebhooks(ctx, session, projectmeta.WebhookEventIssueCommented, authorName, detailDTO)
+	a.runPostSave(ctx, session, authorName, detail.Issue, configrepo.HookEventIssueCommented)
+	return present.OkWithWarnings(detailDTO, warnings)
+}
+
+// ExportIssueBundle は DD-BUNDLE-001 の課題バンドル出力を行う。
+func (a *App) ExportIssueBundle(category, issueID, destPath string) (resp present.Response) {
+	ctx := a.beginCall("ExportIssueBundle")
+	defer a.endCall(ctx, &resp)
+	session, err := a.project()
+	if err != nil {
+		return present.Fail(err)
+	}
+	ctx, done := a.startOperation(ctx, "export_bundle")
+	defer done()
+	dto, err := exportIssueBundle(ctx, session, category, issueID, destPath)
+	if err != nil {
+		return present.Fail(err)
+	}
+	return present.Ok(dto)
+}
+
+// ExportIssues は DD-EXPORT-001 の課題一覧の CSV/JSON/JSON Lines 出力を行う。
+// 本体に無い形式は DD-PLUGIN-002 のプラグインが追加した形式として出力する。
+// CLI の export と同じ処理で出力し、同じ条件であれば同じ内容のファイルとなる。
+// 解析できず出力しなかった課題JSONがある場合は Response の warnings で知らせる。
+func (a *App) ExportIssues(query present.IssueExportQueryDTO, destPath string) (resp present.Response) {
+	ctx := a.beginCall("ExportIssues")
+	defer a.endCall(ctx, &resp)
+	session, err := a.project()
+	if err != nil {
+		return present.Fail(err)
+	}
+	// 本体の形式を優先し、無い場合のみプラグインの形式を探す。
+	format, err := issueexport.ParseFormat(query.Format)
+	exporter, isPlugin := a.plugins.Exporter(query.Format)
+	if err != nil && !isPlugin {
+		return present.Fail(err)
+	}
+	ctx, done := a.startOperation(ctx, "export_issues")
+	defer done()
+	filter := issueexport.Filter{Categories: query.Categories, Statuses: query.Statuses}
+	var result issueexport.Result
+	if err != nil {
+		result, err = issueexport.ExportPlugin(ctx, session.Root(), exporter, filter, destPath)
+	} else {
+		result, err = issueexport.Export(ctx, session.Root(), format, filter, destPath)
+	}
+	if err != nil {
+		return present.Fail(err)
+	}
+	warnings := []present.APIErrorDTO{}
+	if result.Skipped > 0 {
+		warnings = append(warnings, present.ToExportSkippedWarningDTO(result.Skipped))
+	}
+	return present.OkWithWarnings(present.ToIssueExportDTO(result), warnings)
+}
+
+// ListExportFormats は DD-PLUGIN-002 の課題一覧の出力形式を、本体の形式、プラグインが追加した形式の順に返す。
+func (a *App) ListExportFormats() (resp present.Response) {
+	ctx := a.beginCall("ListExportFormats")
+	defer a.endCall(ctx, &resp)
+	return present.Ok(present.ToExportFormatDTOs(a.plugins.ExportFormats()))
+}
+
+// StartExportIssueBundle は DD-OP-001 の課題バンドル出力をバックグラウンドで開始し、処理IDを返す。
+func (a *App) StartExportIssueBundle(category, issueID, destPath string) (resp present.Response) {
+	ctx := a.beginCall("StartExportIssueBundle")
+	defer a.endCall(ctx, &resp)
+	session, err := a.project()
+	if err != nil {
+		return present.Fail(err)
+	}
+	return a.startAsync(ctx, "export_bundle", func(ctx context.Context, _ func(int, int, string)) (any, error) {
+		return exportIssueBundle(ctx, session, category, issueID, destPath)
+	})
+}
+
+// exportIssueBundle は DD-BUNDLE-001 の課題バンドルを出力する。
+func exportIssueBundle(ctx context.Context, session *projectsession.Session, category, issueID, destPath string) (present.BundleExportDTO, error) {
+	unlock := session.ReadIssue(category, issueID)
+	defer unlock()
+	result, err := session.Issues().ExportIssueBundleContext(ctx, category, issueID, destPath)
+	if err != nil {
+		return present.BundleExportDTO{}, err
+	}
+	return present.BundleExportDTO{
+		Path:      result.Path,
+		IssueID:   result.IssueID,
+		FileCount: result.FileCount,
+	}, nil
+}
+
+// ExportIssuePDF は DD-REPORT-001 の課題の詳細の PDF 出力を行う。
+// CLI の report issue と同じ処理で出力し、埋め込むフォントは config.json の report.pdf_font を用いる。
+func (a *App) ExportIssuePDF(category, issueID, destPath string) (resp present.Response) {
+	ctx := a.beginCall("ExportIssuePDF")
+	defer a.endCall(ctx, &resp)
+	session, err := a.project()
+	if err != nil {
+		return present.Fail(err)
+	}
+	detail, err := session.GetIssue(category, issueID)
+	if err != nil {
+		return present.Fail(err)
+	}
+	result, err := pdfreport.WriteIssue(destPath, detail.Issue, a.reportOptions())
+	if err != nil {
+		return present.Fail(err)
+	}
+	return present.Ok(present.ReportDTO{Path: result.Path, Pages: result.Pages})
+}
 
-	hasAuth := false
-	if a.exePath != "" {
-		if _, statErr := os.Stat(filepath.Join(filepath.Dir(a.exePath), "auth", "contractor.json")); statErr == nil {
-			hasAuth = true
-		}
+// ExportIssueEML は DD-EML-001 の課題の内容と添付をメールの下書き (.eml) として出力する。
+// CLI の draft と同じ処理で出力する。to は宛先 (カンマ区切り) とし、空の場合は宛先を入れない。
+// 見つからず埋め込まなかった添付がある場合は Response の warnings で知らせる。
+func (a *App) ExportIssueEML(category, issueID, to, destPath string) (resp present.Response) {
+	ctx := a.beginCall("ExportIssueEML")
+	defer a.endCall(ctx, &resp)
+	session, err := a.project()
+	if err != nil {
+		return present.Fail(err)
 	}
-
-	dto := present.BootstrapDTO{
-		HasConfig:             hasConfig,
-		LastProjectRootPath:   lastPath,
-		UIPageSize:            cfg.UI.PageSize,
-		LogLevel:              cfg.Log.Level,
-		HasContractorAuthFile: hasAuth,
+	unlock := session.ReadIssue(category, issueID)
+	defer unlock()
+	detail, err := session.GetIssue(category, issueID)
+	if err != nil {
+		return present.Fail(err)
 	}
-	return present.Ok(dto)
+	result, err := emldraft.Write(destPath, filepath.Join(session.Root(), category), detail.Issue, emldraft.Options{To: to})
+	if err != nil {
+		return present.Fail(err)
+	}
+	warnings := []present.APIErrorDTO{}
+	if result.MissingAttachments > 0 {
+		warnings = append(warnings, present.ToMissingAttachmentsWarningDTO(result.MissingAttachments))
+	}
+	return present.OkWithWarnings(present.ToEmlDraftDTO(result), warnings)
 }
 
-// ValidateProjectRoot は DD-BE-003 の Project Root 検証を行う。
-func (a *App) ValidateProjectRoot(path string) present.Response {
-	service := projectroot.NewService(a.configRepo)
-	result, err := service.ValidateProjectRoot(path)
+// ExportSummaryPDF は DD-REPORT-001 のプロジェクトの集計 (ステータス別の件数と期限超過の一覧) の PDF 出力を行う。
+// CLI の report summary と同じ処理で出力し、埋め込むフォントは config.json の report.pdf_font を用いる。
+func (a *App) ExportSummaryPDF(destPath string) (resp present.Response) {
+	ctx := a.beginCall("ExportSummaryPDF")
+	defer a.endCall(ctx, &resp)
+	session, err := a.project()
 	if err != nil {
 		return present.Fail(err)
 	}
-	dto := present.ValidationResultDTO{
-		IsValid:        result.IsValid,
-		NormalizedPath: result.NormalizedPath,
-		Message:        result.Message,
+	ctx, done := a.startOperation(ctx, "export_summary_pdf")
+	defer done()
+	stats, err := session.Scanner().ProjectStatsContext(ctx, session.Root(), 0)
+	if err != nil {
+		return present.Fail(err)
 	}
-	if result.Details != "" {
-		value := result.Details
-		dto.Details = &value
+	result, err := pdfreport.WriteSummary(destPath, filepath.Base(session.Root()), stats, a.reportOptions())
+	if err != nil {
+		return present.Fail(err)
 	}
-	return present.Ok(dto)
+	return present.Ok(present.ReportDTO{Path: result.Path, Pages: result.Pages})
 }
 
-// CreateProjectRoot は DD-BE-003 の Project Root 作成を行う。
-func (a *App) CreateProjectRoot(path string) present.Response {
-	service := projectroot.NewService(a.configRepo)
-	result, err := service.CreateProjectRoot(path)
+// ExportWeeklyReport は DD-REPORT-002 の期間内の新規・対応済み・完了・期限超過の課題の報告書を Markdown/HTML で出力する。
+// CLI の report weekly と同じ処理で出力し、解析できず数えなかった課題JSONがある場合は Response の warnings で知らせる。
+func (a *App) ExportWeeklyReport(query present.WeeklyReportQueryDTO, destPath string) (resp present.Response) {
+	ctx := a.beginCall("ExportWeeklyReport")
+	defer a.endCall(ctx, &resp)
+	session, err := a.project()
 	if err != nil {
 		return present.Fail(err)
 	}
-	dto := present.ValidationResultDTO{
-		IsValid:        result.IsValid,
-		NormalizedPath: result.NormalizedPath,
-		Message:        result.Message,
+	format, err := weeklyreport.ParseFormat(query.Format)
+	if err != nil {
+		return present.Fail(err)
 	}
-	if result.Details != "" {
-		value := result.Details
-		dto.Details = &value
+	period, err := weeklyreport.ParseRange(query.From, query.To)
+	if err != nil {
+		return present.Fail(err)
 	}
-	return present.Ok(dto)
+	ctx, done := a.startOperation(ctx, "export_weekly_report")
+	defer done()
+	result, err := weeklyreport.Export(ctx, session.Root(), format, period, destPath)
+	if err != nil {
+		return present.Fail(err)
+	}
+	warnings := []present.APIErrorDTO{}
+	if result.Skipped > 0 {
+		warnings = append(warnings, present.ToExportSkippedWarningDTO(result.Skipped))
+	}
+	return present.OkWithWarnings(present.ToWeeklyReportDTO(result), warnings)
 }
 
-// SaveLastProjectRoot は DD-BE-003 の last_project_root_path 更新を行う。
-func (a *App) SaveLastProjectRoot(path string) present.Response {
-	service := projectroot.NewService(a.configRepo)
-	if err := service.SaveLastProjectRoot(path); err != nil {
+// reportOptions は DD-REPORT-001 の config.json の report.pdf_font から PDF の出力の設定を返す。設定を読めない場合は PDF 標準フォントを用いる。
+func (a *App) reportOptions() pdfreport.Options {
+	cfg, hasConfig, err := a.configRepo.Load()
+	if err != nil || !hasConfig {
+		return pdfreport.Options{}
+	}
+	return pdfreport.Options{FontPath: cfg.Report.PDFFontPath(filepath.Dir(a.configRepo.Path()))}
+}
+
+// StartPublishSite は DD-OP-001 の静的な HTML サイトの出力をバックグラウンドで開始し、処理IDを返す。
+// CLI の publish と同じ処理で出力し、進捗は出力を終えたカテゴリ数とカテゴリ名を operation:progress で通知する。
+func (a *App) StartPublishSite(destDir string) (resp present.Response) {
+	ctx := a.beginCall("StartPublishSite")
+	defer a.endCall(ctx, &resp)
+	session, err := a.project()
+	if err != nil {
 		return present.Fail(err)
 	}
-	a.root = path
-	return present.Ok(nil)
+	return a.startAsync(ctx, "publish_site", func(ctx context.Context, report func(int, int, string)) (any, error) {
+		result, publishErr := sitepublish.Publish(ctx, session.Root(), destDir, report)
+		if publishErr != nil {
+			return nil, publishErr
+		}
+		return present.ToSitePublishDTO(result), nil
+	})
 }
 
-// DetectMode は DD-BE-003 のモード判定を行う。
-func (a *App) DetectMode() present.Response {
-	service := modedetect.NewService(a.exePath, a.validator)
-	modeValue, requiresPassword, err := service.DetectMode()
+// StartRebuildIndex は DD-OP-001 の全カテゴリの索引の作り直しをバックグラウンドで開始し、処理IDを返す。
+// 進捗は完了したカテゴリ数とカテゴリ名を operation:progress で通知し、UI は operation:finished を受けて一覧を取り直す。
+func (a *App) StartRebuildIndex() (resp present.Response) {
+	ctx := a.beginCall("StartRebuildIndex")
+	defer a.endCall(ctx, &resp)
+	session, err := a.project()
 	if err != nil {
 		return present.Fail(err)
 	}
-	dto := present.ModeDTO{Mode: string(modeValue), RequiresPassword: requiresPassword}
-	return present.Ok(dto)
+	return a.startAsync(ctx, "rebuild_index", func(ctx context.Context, report func(int, int, string)) (any, error) {
+		return nil, session.RebuildIndex(ctx, report)
+	})
 }
 
-// VerifyContractorPassword は DD-BE-003 のパスワード検証を行う。
-func (a *App) VerifyContractorPassword(password string) present.Response {
-	service := modedetect.NewService(a.exePath, a.validator)
-	modeValue, err := service.VerifyContractorPassword(password)
+// StartMigrateProject は DD-MIGRATE-001 のプロジェクトの形式移行をバックグラウンドで開始し、処理IDを返す。
+// 目的: 旧い版の課題JSONなどを現行の版へ一括で書き換える前に、対象と移行できないファイルを確かめられるようにする。
+// 入力: dryRun は書き換えずに対象を報告するかどうか。
+// 出力: 処理IDを含む Response。結果は operation:finished の MigrationResultDTO で通知する。
+// エラー: プロジェクト未設定、dry-run 以外で Contractor モードでない・読み取り専用の場合に返す。
+// 副作用: dry-run 以外では全カテゴリのロックを取得して書き換え、.ratta/backups/migrate-<日時> へ書き換え前の内容を残す。
+// 並行性: 書き換え中は課題操作を待たせる。
+// 不変条件: 現行の版のファイルと移行できないファイルは変更しない。アプリ設定は対象としない。
+// 関連DD: DD-MIGRATE-001, DD-LOCK-001, DD-OP-001
+func (a *App) StartMigrateProject(dryRun bool) (resp present.Response) {
+	ctx := a.beginCall("StartMigrateProject")
+	defer a.endCall(ctx, &resp)
+	var session *projectsession.Session
+	var err error
+	if dryRun {
+		session, err = a.project()
+	} else {
+		session, err = a.writableProject()
+		if err == nil && a.modes.Mode() != mod.ModeContractor {
+			err = errMigrationRequiresContractor
+		}
+	}
 	if err != nil {
 		return present.Fail(err)
 	}
-	a.mode = modeValue
-	dto := present.ModeDTO{Mode: string(modeValue), RequiresPassword: false}
-	return present.Ok(dto)
+	root := session.Root()
+	opts := migrate.Options{DryRun: dryRun}
+	if !dryRun {
+		opts.BackupDir = migrate.NewBackupDir(root, time.Now())
+	}
+	return a.startAsync(ctx, "migrate_project", func(ctx context.Context, _ func(int, int, string)) (any, error) {
+		if !dryRun {
+			scanned, scanErr := categoryscan.ScanContext(ctx, root)
+			if scanErr != nil {
+				return nil, scanErr
+			}
+			names := make([]string, 0, len(scanned.Categories))
+			for _, category := range scanned.Categories {
+				names = append(names, category.Name)
+			}
+			unlock := session.LockCategories(names...)
+			defer unlock()
+			defer session.InvalidateAll()
+		}
+		result, runErr := migrate.Run(ctx, root, opts)
+		if runErr != nil {
+			return nil, runErr
+		}
+		if !dryRun {
+			a.autoCommit(ctx, session, gitcommit.Change{Operation: gitProjectMigrated})
+		}
+		return present.ToMigrationResultDTO(result, opts), nil
+	})
 }
 
-// ListCategories は DD-LOAD-002 のカテゴリ一覧を返す。
-func (a *App) ListCategories() present.Response {
-	if a.root == "" {
-		return present.Fail(errors.New("project root is not set"))
+// errMigrationRequiresContractor は DD-MIGRATE-001 の形式移行を Contractor モード以外で拒否することを表す。権限不足 (E_PERMISSION) として扱う。
+var errMigrationRequiresContractor = apperr.New(apperr.ErrPermission, "permission denied: migration requires contractor mode")
+
+// StartImportIssuesJSONL は DD-IMPORT-001 の JSON Lines 形式の課題の取り込みをバックグラウンドで開始し、処理IDを返す。
+// 目的: 課題一覧の JSON Lines 出力や分析ツールで加工した課題を、検証したうえで一括で登録する。
+// 入力: query は読み込むファイル、取り込み先のカテゴリ、上書きと dry-run の指定。
+// 出力: 処理IDを含む Response。結果は operation:finished の IssueImportDTO で通知する。
+// エラー: プロジェクト未設定、dry-run 以外で読み取り専用の場合に返す。ファイルを読めない場合や行ごとの失敗は operation:finished で通知する。
+// 副作用: dry-run 以外では課題JSONを作成・上書きし、キャッシュを破棄する。操作記録には残さない。
+// 並行性: 取り込み中は取り込み先のカテゴリ (カテゴリを指定しない場合は全カテゴリ) への課題操作を待たせる。dry-run はロックを取得しない。
+// 不変条件: CLI の import jsonl と同じ規則で取り込む。
+// 関連DD: DD-IMPORT-001, DD-LOCK-001, DD-OP-001
+func (a *App) StartImportIssuesJSONL(query present.IssueImportQueryDTO) (resp present.Response) {
+	ctx := a.beginCall("StartImportIssuesJSONL")
+	defer a.endCall(ctx, &resp)
+	var session *projectsession.Session
+	var err error
+	if query.DryRun {
+		session, err = a.project()
+	} else {
+		session, err = a.writableProject()
 	}
-	result, err := categoryscan.Scan(a.root)
 	if err != nil {
 		return present.Fail(err)
 	}
-	categories := make([]present.CategoryDTO, 0, len(result.Categories))
-	for _, category := range result.Categories {
-		categories = append(categories, present.ToCategoryDTO(category))
+	currentMode := a.modes.Mode()
+	opts := issueimport.Options{Category: query.Category, Overwrite: query.Overwrite, DryRun: query.DryRun}
+	return a.startAsync(ctx, "import_jsonl", func(ctx context.Context, _ func(int, int, string)) (any, error) {
+		// #nosec G304 -- 利用者がファイル選択ダイアログで指定した取り込み元のファイルを読む。
+		file, openErr := os.Open(query.Path)
+		if openErr != nil {
+			return nil, apperr.WithPath(fmt.Errorf("open jsonl: %w", openErr), query.Path, "")
+		}
+		defer func() { _ = file.Close() }()
+		if !query.DryRun {
+			names := []string{query.Category}
+			if query.Category == "" {
+				scanned, scanErr := categoryscan.ScanContext(ctx, session.Root())
+				if scanErr != nil {
+					return nil, scanErr
+				}
+				names = names[:0]
+				for _, category := range scanned.Categories {
+					names = append(names, category.Name)
+				}
+			}
+			unlock := session.LockCategories(names...)
+			defer unlock()
+			defer session.InvalidateAll()
+		}
+		result, importErr := issueimport.Import(ctx, session.Issues(), currentMode, file, opts)
+		if importErr != nil {
+			return nil, importErr
+		}
+		if !query.DryRun {
+			a.autoCommit(ctx, session, gitcommit.Change{Operation: gitIssuesImported, Category: query.Category})
+		}
+		return present.ToIssueImportDTO(result), nil
+	})
+}
+
+// ExportRedmineCSV は DD-REDMINE-001 の課題一覧と注記の Redmine 向けの CSV の出力を行う。
+// CLI の redmine export と同じ処理で出力し、journalsPath が空の場合は注記を出力しない。
+// 解析できず出力しなかった課題JSONがある場合は Response の warnings で知らせる。
+func (a *App) ExportRedmineCSV(query present.RedmineExportQueryDTO, issuesPath, journalsPath string) (resp present.Response) {
+	ctx := a.beginCall("ExportRedmineCSV")
+	defer a.endCall(ctx, &resp)
+	session, err := a.project()
+	if err != nil {
+		return present.Fail(err)
 	}
-	dto := present.CategoryListDTO{
-		Categories: categories,
-		Errors:     result.ErrorCount,
+	ctx, done := a.startOperation(ctx, "export_redmine")
+	defer done()
+	result, err := redmine.Export(ctx, session.Root(), issueexport.Filter{
+		Categories: query.Categories,
+		Statuses:   query.Statuses,
+	}, issuesPath, journalsPath)
+	if err != nil {
+		return present.Fail(err)
 	}
-	return present.Ok(dto)
+	warnings := []present.APIErrorDTO{}
+	if result.Skipped > 0 {
+		warnings = append(warnings, present.ToExportSkippedWarningDTO(result.Skipped))
+	}
+	return present.OkWithWarnings(present.ToRedmineExportDTO(result), warnings)
 }
 
-// CreateCategory は DD-BE-003 のカテゴリ作成を行う。
-func (a *App) CreateCategory(name string) present.Response {
-	if a.root == "" {
-		return present.Fail(errors.New("project root is not set"))
+// StartImportRedmineCSV は DD-REDMINE-002 の Redmine の CSV の取り込みをバックグラウンドで開始し、処理IDを返す。
+// 目的: Redmine を使う取引先から受け取った課題一覧と注記を、同じ課題は更新、新しい課題は作成して同期する。
+// 入力: query は取り込み先のカテゴリ、CSV のパス、Redmine 側の会社種別、既定の期日、dry-run の指定。
+// 出力: 処理IDを含む Response。結果は operation:finished の RedmineImportDTO で通知する。
+// エラー: プロジェクト未設定、dry-run 以外で読み取り専用の場合に返す。CSV を読めない場合や行ごとの失敗は operation:finished で通知する。
+// 副作用: dry-run 以外では取り込み先のカテゴリの課題JSONを作成・上書きし、カテゴリのキャッシュを破棄する。操作記録には残さない。
+// 並行性: 取り込み中は取り込み先のカテゴリへの課題操作を待たせる。dry-run はロックを取得しない。
+// 不変条件: CLI の redmine import と同じ規則で課題を対応付ける。
+// 関連DD: DD-REDMINE-002, DD-LOCK-001, DD-OP-001
+func (a *App) StartImportRedmineCSV(query present.RedmineImportQueryDTO) (resp present.Response) {
+	ctx := a.beginCall("StartImportRedmineCSV")
+	defer a.endCall(ctx, &resp)
+	var session *projectsession.Session
+	var err error
+	if query.DryRun {
+		session, err = a.project()
+	} else {
+		session, err = a.writableProject()
 	}
-	service := categoryops.NewService(a.root)
-	category, err := service.CreateCategory(name, a.mode)
 	if err != nil {
 		return present.Fail(err)
 	}
-	dto := present.CategoryDTO{
-		Name:       category.Name,
-		IsReadOnly: category.IsReadOnly,
-		Path:       category.Path,
-		IssueCount: 0,
+	currentMode := a.modes.Mode()
+	opts := redmine.Options{
+		Category:       query.Category,
+		Company:        issue.Company(query.Company),
+		DefaultDueDate: query.DefaultDueDate,
+		DryRun:         query.DryRun,
 	}
-	return present.Ok(dto)
+	return a.startAsync(ctx, "import_redmine", func(ctx context.Context, _ func(int, int, string)) (any, error) {
+		records, readErr := redmine.ReadFiles(query.IssuesPath, query.JournalsPath)
+		if readErr != nil {
+			return nil, readErr
+		}
+		if !query.DryRun {
+			unlock := session.LockCategories(query.Category)
+			defer unlock()
+			defer session.InvalidateCategory(query.Category)
+		}
+		result, importErr := redmine.Import(ctx, session.Issues(), session.Root(), currentMode, records, opts)
+		if importErr != nil {
+			return nil, importErr
+		}
+		if !query.DryRun {
+			a.autoCommit(ctx, session, gitcommit.Change{Operation: gitIssuesImported, Category: query.Category})
+		}
+		return present.ToRedmineImportDTO(result), nil
+	})
 }
 
-// RenameCategory は DD-BE-003 のカテゴリ名変更を行う。
-func (a *App) RenameCategory(oldName, newName string) present.Response {
-	if a.root == "" {
-		return present.Fail(errors.New("project root is not set"))
+// StartSyncProject は DD-SYNC-001 の開いているプロジェクトと別のプロジェクトの同期をバックグラウンドで開始し、処理IDを返す。
+// 目的: Vendor の手元の複製と共有フォルダのように別々に変更した同じプロジェクトを突き合わせ、競合は手作業で解決できるよう報告する。
+// 入力: query は相手のプロジェクトルートと dry-run の指定。
+// 出力: 処理IDを含む Response。結果と競合の報告は operation:finished の SyncDTO で通知する。
+// エラー: プロジェクト未設定、dry-run 以外で読み取り専用の場合に返す。相手のプロジェクトが無い・他のインスタンスが開いている場合や
+// 課題ごとの失敗は operation:finished で通知する。
+// 副作用: dry-run 以外では両方のプロジェクトの課題JSON・添付を更新し、キャッシュを破棄する。操作記録には残さない。
+// 前回の同期の記録は開いているプロジェクトの .ratta/sync 配下に残す。
+// 並行性: 同期中は開いているプロジェクトの全カテゴリへの課題操作を待たせ、相手のプロジェクトの書き込み用ロックを取得する。dry-run はロックを取得しない。
+// 不変条件: CLI の sync と同じ規則で同期する。
+// 関連DD: DD-SYNC-001, DD-SYNC-002, DD-SYNC-003, DD-LOCK-001, DD-LOCK-002, DD-OP-001
+func (a *App) StartSyncProject(query present.SyncQueryDTO) (resp present.Response) {
+	ctx := a.beginCall("StartSyncProject")
+	defer a.endCall(ctx, &resp)
+	var session *projectsession.Session
+	var err error
+	if query.DryRun {
+		session, err = a.project()
+	} else {
+		session, err = a.writableProject()
+	}
+	if err != nil {
+		return present.Fail(err)
 	}
-	service := categoryops.NewService(a.root)
-	category, err := service.RenameCategory(oldName, newName, a.mode)
+	currentMode := a.modes.Mode()
+	opts := projectsync.Options{DryRun: query.DryRun}
+	return a.startAsync(ctx, "sync_project", func(ctx context.Context, _ func(int, int, string)) (any, error) {
+		if !query.DryRun {
+			lock, holder, lockErr := projectlock.Acquire(query.OtherRoot)
+			if errors.Is(lockErr, projectlock.ErrLocked) {
+				return nil, fmt.Errorf("%w (%s, pid %d)", lockErr, holder.Hostname, holder.PID)
+			}
+			if lockErr != nil {
+				return nil, lockErr
+			}
+			defer func() { _ = lock.Release() }()
+			scanned, scanErr := categoryscan.ScanContext(ctx, session.Root())
+			if scanErr != nil {
+				return nil, scanErr
+			}
+			names := make([]string, 0, len(scanned.Categories))
+			for _, category := range scanned.Categories {
+				names = append(names, category.Name)
+			}
+			unlock := session.LockCategories(names...)
+			defer unlock()
+			defer session.InvalidateAll()
+		}
+		result, syncErr := projectsync.Sync(ctx, session.Root(), query.OtherRoot, a.validator, currentMode, opts)
+		if syncErr != nil {
+			return nil, syncErr
+		}
+		if !query.DryRun {
+			a.autoCommit(ctx, session, gitcommit.Change{Operation: gitProjectSynced})
+			a.autoCommitOther(ctx, query.OtherRoot, gitcommit.Change{Operation: gitProjectSynced})
+		}
+		return present.ToSyncDTO(result), nil
+	})
+}
+
+// StartExportPatch は DD-PATCH-001 の基準時刻以降に変わった課題・コメントのパッチの出力をバックグラウンドで開始し、処理IDを返す。
+// 目的: 共有フォルダを使えない相手へ、前回の受け渡し以降の変更だけを署名付きのファイルで渡せるようにする。
+// 入力: query は出力先、基準時刻 (空の場合は全件)、署名用のパスフレーズ。
+// 出力: 処理IDを含む Response。結果は operation:finished の PatchExportDTO で通知する。
+// エラー: プロジェクト未設定、基準時刻を解釈できない場合に返す。パスフレーズが無い場合や出力の失敗は operation:finished で通知する。
+// 副作用: 出力先へパッチを書き込む。プロジェクトは変更しない。
+// 並行性: 読み取りのみでロックを取得しない。
+// 不変条件: CLI の patch export と同じ条件であれば同じ課題・コメントを出力する。
+// 関連DD: DD-PATCH-001, DD-OP-001
+func (a *App) StartExportPatch(query present.PatchExportQueryDTO) (resp present.Response) {
+	ctx := a.beginCall("StartExportPatch")
+	defer a.endCall(ctx, &resp)
+	session, err := a.project()
 	if err != nil {
 		return present.Fail(err)
 	}
-	dto := present.CategoryDTO{
-		Name:       category.Name,
-		IsReadOnly: category.IsReadOnly,
-		Path:       category.Path,
-		IssueCount: 0,
+	since, err := patchbundle.ParseSince(query.Since)
+	if err != nil {
+		return present.Fail(err)
 	}
-	return present.Ok(dto)
+	return a.startAsync(ctx, "export_patch", func(ctx context.Context, _ func(int, int, string)) (any, error) {
+		result, exportErr := patchbundle.Export(ctx, session.Root(), since, query.Passphrase, query.DestPath)
+		if exportErr != nil {
+			return nil, exportErr
+		}
+		return present.ToPatchExportDTO(result), nil
+	})
 }
 
-// DeleteCategory は DD-BE-003 のカテゴリ削除を行う。
-func (a *App) DeleteCategory(name string) present.Response {
-	if a.root == "" {
-		return present.Fail(errors.New("project root is not set"))
+// StartApplyPatch は DD-PATCH-002 のパッチの取り込みをバックグラウンドで開始し、処理IDを返す。
+// 目的: 相手から受け取ったパッチの署名と内容を確かめ、開いているプロジェクトへ変更を反映する。
+// 入力: query はパッチのパス、署名用のパスフレーズ、dry-run の指定。
+// 出力: 処理IDを含む Response。結果と競合の報告は operation:finished の PatchApplyDTO で通知する。
+// エラー: プロジェクト未設定、dry-run 以外で読み取り専用の場合に返す。署名の不一致 (E_CRYPTO)・内容の改ざんや課題ごとの失敗は operation:finished で通知する。
+// 副作用: dry-run 以外では課題JSON・添付を作成・上書きし、キャッシュを破棄する。操作記録には残さない。
+// 並行性: 取り込み中は全カテゴリへの課題操作を待たせる。dry-run はロックを取得しない。
+// 不変条件: CLI の patch apply と同じ規則で取り込む。
+// 関連DD: DD-PATCH-002, DD-PATCH-003, DD-LOCK-001, DD-OP-001
+func (a *App) StartApplyPatch(query present.PatchApplyQueryDTO) (resp present.Response) {
+	ctx := a.beginCall("StartApplyPatch")
+	defer a.endCall(ctx, &resp)
+	var session *projectsession.Session
+	var err error
+	if query.DryRun {
+		session, err = a.project()
+	} else {
+		session, err = a.writableProject()
 	}
-	service := categoryops.NewService(a.root)
-	if err := service.DeleteCategory(name, a.mode); err != nil {
+	if err != nil {
 		return present.Fail(err)
 	}
-	return present.Ok(nil)
+	currentMode := a.modes.Mode()
+	opts := patchbundle.Options{DryRun: query.DryRun}
+	return a.startAsync(ctx, "apply_patch", func(ctx context.Context, _ func(int, int, string)) (any, error) {
+		if !query.DryRun {
+			scanned, scanErr := categoryscan.ScanContext(ctx, session.Root())
+			if scanErr != nil {
+				return nil, scanErr
+			}
+			names := make([]string, 0, len(scanned.Categories))
+			for _, category := range scanned.Categories {
+				names = append(names, category.Name)
+			}
+			unlock := session.LockCategories(names...)
+			defer unlock()
+			defer session.InvalidateAll()
+		}
+		result, applyErr := patchbundle.Apply(ctx, session.Root(), query.Path, query.Passphrase, a.validator, currentMode, opts)
+		if applyErr != nil {
+			return nil, applyErr
+		}
+		if !query.DryRun && result.Created+result.Updated+result.Conflicted > 0 {
+			a.autoCommit(ctx, session, gitcommit.Change{Operation: gitPatchApplied})
+		}
+		return present.ToPatchApplyDTO(result), nil
+	})
 }
 
-// ListIssues は DD-BE-003 の課題一覧を返す。
-func (a *App) ListIssues(category string, query present.IssueListQueryDTO) present.Response {
-	if a.root == "" {
-		return present.Fail(errors.New("project root is not set"))
+// ImportIssueBundle は DD-BUNDLE-002 の課題バンドル取り込みを行う。
+func (a *App) ImportIssueBundle(category, srcPath string) (resp present.Response) {
+	ctx := a.beginCall("ImportIssueBundle")
+	defer a.endCall(ctx, &resp)
+	session, err := a.writableProject()
+	if err != nil {
+		return present.Fail(err)
 	}
-	service := issueops.NewService(a.root, a.validator)
-	result, err := service.ListIssues(category, issueops.IssueListQuery{
-		Page:      query.Page,
-		PageSize:  query.PageSize,
-		SortBy:    query.SortBy,
-		SortOrder: query.SortOrder,
+	unlock := session.LockCategoryShared(category)
+	defer unlock()
+	pending := a.beginJournal(ctx, session, journalIssueImported, category, "")
+	detail, err := session.Issues().ImportIssueBundle(category, srcPath, a.modes.Mode())
+	if err != nil {
+		return present.Fail(err)
+	}
+	created := append([]string{issueFilePath(category, detail.Issue.IssueID)}, attachmentFilePaths(category, detail.Issue.Comments)...)
+	a.commitJournal(ctx, pending, detail.Issue.IssueID, created...)
+	a.recordAudit(ctx, session, audittrail.Record{
+		Operation: journalIssueImported,
+		Category:  category,
+		IssueID:   detail.Issue.IssueID,
+		Changes:   audittrail.Created(detail.Issue),
 	})
+	session.InvalidateIssue(category, detail.Issue.IssueID)
+	detailDTO := present.ToIssueDetailDTO(detail)
+	a.emitEvent(issueCreatedEvent, detailDTO)
+	a.notifyWebhooks(ctx, session, projectmeta.WebhookEventIssueCreated, "", detailDTO)
+	a.runPostSave(ctx, session, "", detail.Issue, configrepo.HookEventIssueCreated)
+	return present.Ok(detailDTO)
+}
+
+// UndoLastOperation は DD-JOURNAL-001 の直前の操作の取り消しを行う。
+// 目的: 誤った状態変更やコメントの追加を、このアプリで行った直前の課題操作の前の状態へ戻す。
+// 入力: なし。
+// 出力: 成功時は UndoDTO、失敗時は APIErrorDTO を含む Response。
+// エラー: プロジェクト未選択、取り消せる操作がない (E_NOT_FOUND)、操作後に課題が変更された (E_CONFLICT) 場合に返す。
+// 現在の操作モードでカテゴリに書き込めない場合、アーカイブ済みカテゴリ、終状態の課題、モードで許されない状態へ戻す場合も返す。
+// 副作用: 課題JSONと添付を書き戻し・削除し、操作記録を削除し、キャッシュを破棄して issue:undone を通知する。
+// 並行性: 対象課題のロックを取得し、同じ課題への更新と直列化する。
+// 不変条件: 記録対象は課題の作成・更新・コメント追加・バンドル取り込みに限り、カテゴリ操作は取り消さない。
+// 書き戻す前に課題の更新と同じ規則を取り消す時点の操作モードで検査する。
+// 関連DD: DD-JOURNAL-001, DD-BE-003, DD-CATMETA-002, DD-PERM-001
+func (a *App) UndoLastOperation() (resp present.Response) {
+	ctx := a.beginCall("UndoLastOperation")
+	defer a.endCall(ctx, &resp)
+	session, err := a.writableProject()
+	if err != nil {
+		return present.Fail(err)
+	}
+	entry, err := session.Journal().Last()
 	if err != nil {
 		return present.Fail(err)
 	}
-	items := make([]present.IssueSummaryDTO, 0, len(result.Issues))
-	for _, item := range result.Issues {
-		items = append(items, present.ToIssueSummaryDTO(item))
+	unlock := session.LockIssue(entry.Category, entry.IssueID)
+	defer unlock()
+	if err := a.checkUndo(session, entry); err != nil {
+		return present.Fail(err)
+	}
+	// ロック待ちの間に記録が進んでいる場合は Undo が最新でないとして失敗させる。
+	undone, err := session.Journal().Undo(entry.ID)
+	if err != nil {
+		return present.Fail(err)
 	}
-	dto := present.IssueListDTO{
-		Category: result.Category,
-		Total:    result.Total,
-		Page:     result.Page,
-		PageSize: result.PageSize,
-		Issues:   items,
+	a.recordAudit(ctx, session, audittrail.Record{
+		Operation: auditUndone,
+		Category:  undone.Category,
+		IssueID:   undone.IssueID,
+		Changes:   []audittrail.Change{{Field: "operation", Before: undone.Operation}},
+	})
+	session.InvalidateIssue(undone.Category, undone.IssueID)
+	dto := present.UndoDTO{
+		Operation: undone.Operation,
+		Category:  undone.Category,
+		IssueID:   undone.IssueID,
 	}
+	a.emitEvent(issueUndoneEvent, dto)
 	return present.Ok(dto)
 }
 
-// GetIssue は DD-BE-003 の課題詳細を取得する。
-func (a *App) GetIssue(category, issueID string) present.Response {
-	if a.root == "" {
-		return present.Fail(errors.New("project root is not set"))
+// checkUndo は DD-JOURNAL-001 の取り消しを、課題の更新と同じ規則 (カテゴリ権限・アーカイブ・終状態・状態遷移) で
+// 取り消す時点の操作モードについて検査する。操作で作成した課題を削除する取り消しは restored を nil として検査する。
+// 呼び出し側は対象課題のロックを保持し、検査から書き戻しまでの間に課題が変更されないようにする。
+func (a *App) checkUndo(session *projectsession.Session, entry journal.Entry) error {
+	data, existed, err := session.Journal().Before(entry, issueFilePath(entry.Category, entry.IssueID))
+	if err != nil {
+		return err
+	}
+	var restored *issue.Issue
+	if existed {
+		restored = &issue.Issue{}
+		if err := json.Unmarshal(data, restored); err != nil {
+			return fmt.Errorf("parse journal issue: %w", err)
+		}
 	}
-	service := issueops.NewService(a.root, a.validator)
-	detail, err := service.GetIssue(category, issueID)
+	return session.Issues().CheckRevert(entry.Category, entry.IssueID, a.modes.Mode(), restored)
+}
+
+// beginJournal は DD-JOURNAL-001 の操作前の状態の記録を開始する。
+// 記録は取り消しのための補助であり、失敗しても操作自体は続けてログへ残す。
+func (a *App) beginJournal(ctx context.Context, session *projectsession.Session, operation, category, issueID string, paths ...string) *journal.Pending {
+	pending, err := session.Journal().Begin(operation, category, issueID, paths...)
 	if err != nil {
-		return present.Fail(err)
+		a.logger.ErrorContext(ctx, "journal begin failed", map[string]any{"operation": operation, "detail": err.Error()})
+		return nil
 	}
-	return present.Ok(present.ToIssueDetailDTO(detail))
+	return pending
 }
 
-// CreateIssue は DD-BE-003 の課題作成を行う。
-func (a *App) CreateIssue(category string, dto present.IssueCreateDTO) present.Response {
-	if a.root == "" {
-		return present.Fail(errors.New("project root is not set"))
+// commitJournal は DD-JOURNAL-001 の操作後の状態を記録する。created は操作で新たに作成したファイルを表す。
+func (a *App) commitJournal(ctx context.Context, pending *journal.Pending, issueID string, created ...string) {
+	if pending == nil {
+		return
 	}
-	service := issueops.NewService(a.root, a.validator)
-	detail, err := service.CreateIssue(category, a.mode, issueops.IssueCreateInput{
-		Title:       dto.Title,
-		Description: dto.Description,
-		DueDate:     dto.DueDate,
-		Priority:    issue.Priority(dto.Priority),
-		Assignee:    dto.Assignee,
+	pending.Created(created...)
+	if _, err := pending.Commit(issueID); err != nil {
+		a.logger.ErrorContext(ctx, "journal commit failed", map[string]any{"detail": err.Error()})
+	}
+}
+
+// recordAudit は DD-LOG-008 の変更操作を監査証跡へ追記する。
+// 目的: 課題の変更を誰がいつ行ったかを、ratta.log とは別に追記専用で残す。
+// 入力: ctx はバインディングの呼び出しの context、session は操作したプロジェクト、record は操作・対象・変更内容。
+// 出力: なし。
+// エラー: 返さない。追記の失敗はログへ記録し、操作自体は成功として扱う。
+// 副作用: 記録先のファイルへ1行を追記する。
+// 並行性: auditMu で記録先ごとの Trail を共有し、追記は Trail が排他する。
+// 不変条件: モード・利用者・リクエスト ID は呼び出し時点の値を補う。モードと利用者は callMode に従い、gRPC からの呼び出しは Vendor とする。
+// 関連DD: DD-LOG-008, DD-LOG-007
+func (a *App) recordAudit(ctx context.Context, session *projectsession.Session, record audittrail.Record) {
+	path := audittrail.AppPath(a.exePath)
+	if a.auditLocation == audittrail.LocationProject {
+		path = audittrail.ProjectPath(session.Root())
+	}
+	a.auditMu.Lock()
+	trail, ok := a.auditTrails[path]
+	if !ok {
+		trail = audittrail.Open(path)
+		a.auditTrails[path] = trail
+	}
+	a.auditMu.Unlock()
+
+	currentMode, user := a.callMode(ctx)
+	record.Mode = string(currentMode)
+	if record.Author == "" {
+		record.Author = user
+	}
+	record.RequestID = logging.RequestID(ctx)
+	if err := trail.Append(record); err != nil {
+		a.logger.ErrorContext(ctx, "append audit trail failed", map[string]any{"operation": record.Operation, "detail": err.Error()})
+	}
+	a.autoCommit(ctx, session, gitcommit.Change{
+		Operation: record.Operation,
+		Category:  record.Category,
+		IssueID:   record.IssueID,
+		Actor:     record.Author,
+		Mode:      record.Mode,
 	})
+}
+
+// autoCommit は DD-GIT-001 の変更操作の自動コミットを行う。
+// 目的: プロジェクトルートが git リポジトリの場合に、操作ごとの差分を課題ID・操作名・操作者を記したコミットとして残す。
+// 入力: ctx はバインディングの呼び出しの context、session は操作したプロジェクト、change は変更操作。
+// 出力: なし。
+// エラー: 返さない。git リポジトリでない場合は何もせず、コミットの失敗はログへ記録して操作自体は成功として扱う。
+// 副作用: project-config.json の git.auto_commit が true の場合に限り、プロジェクトルート配下の変更をステージしてコミットする。
+// 並行性: gitMu で直列化する。並行して行われた別の操作の変更が先のコミットに含まれる場合があり、その場合は後の操作のコミットを作らない。
+// 不変条件: 操作者・操作モードが未指定の場合は呼び出し時点の値を補う。
+// 関連DD: DD-GIT-001, DD-PROJCONF-001
+func (a *App) autoCommit(ctx context.Context, session *projectsession.Session, change gitcommit.Change) {
+	a.projectMu.RLock()
+	settings := a.projectConfig.GitSettings()
+	a.projectMu.RUnlock()
+	a.commitProject(ctx, session.Root(), settings, change)
+}
+
+// autoCommitOther は DD-GIT-001 の開いていないプロジェクト (DD-SYNC-001 の同期の相手) の変更を、そのプロジェクトの設定に従って自動コミットする。
+// 設定を読めない場合はログへ記録してコミットしない。
+func (a *App) autoCommitOther(ctx context.Context, root string, change gitcommit.Change) {
+	config, err := projectmeta.LoadConfig(root)
 	if err != nil {
-		return present.Fail(err)
+		a.logger.ErrorContext(ctx, "git auto-commit failed", map[string]any{"operation": change.Operation, "detail": err.Error()})
+		return
 	}
-	return present.Ok(present.ToIssueDetailDTO(detail))
+	a.commitProject(ctx, root, config.GitSettings(), change)
 }
 
-// UpdateIssue は DD-BE-003 の課題更新を行う。
-func (a *App) UpdateIssue(category, issueID string, dto present.IssueUpdateDTO) present.Response {
-	if a.root == "" {
-		return present.Fail(errors.New("project root is not set"))
+// commitProject は DD-GIT-001 の設定で自動コミットが有効な場合に root 配下の変更をコミットする。
+func (a *App) commitProject(ctx context.Context, root string, settings projectmeta.GitSettings, change gitcommit.Change) {
+	if !settings.AutoCommit {
+		return
+	}
+	if change.Mode == "" {
+		change.Mode = string(a.modes.Mode())
+	}
+	if change.Actor == "" {
+		change.Actor = a.modes.User()
+	}
+	a.gitMu.Lock()
+	defer a.gitMu.Unlock()
+	result, err := gitcommit.Commit(root, change, gitcommit.Author{Name: settings.AuthorName, Email: settings.AuthorEmail}, time.Now())
+	if errors.Is(err, gitcommit.ErrNotRepository) {
+		return
 	}
-	service := issueops.NewService(a.root, a.validator)
-	detail, err := service.UpdateIssue(category, issueID, a.mode, issueops.IssueUpdateInput{
-		Title:       dto.Title,
-		Description: dto.Description,
-		DueDate:     dto.DueDate,
-		Priority:    issue.Priority(dto.Priority),
-		Status:      issue.Status(dto.Status),
-		Assignee:    dto.Assignee,
-	})
 	if err != nil {
-		return present.Fail(err)
+		a.logger.ErrorContext(ctx, "git auto-commit failed", map[string]any{"operation": change.Operation, "detail": err.Error()})
+		return
+	}
+	if result.Hash != "" {
+		a.logger.DebugContext(ctx, "git auto-commit", map[string]any{"operation": change.Operation, "commit": result.Hash, "files": len(result.Files)})
 	}
-	return present.Ok(present.ToIssueDetailDTO(detail))
 }
 
-// AddComment は DD-BE-003 のコメント追加を行う。
-func (a *App) AddComment(category, issueID string, dto present.CommentCreateDTO) present.Response {
-	if a.root == "" {
-		return present.Fail(errors.New("project root is not set"))
+// notifyShutdownWait は DD-HOOK-001 の終了時に送信中の通知と DD-HOOK-003 の実行中のスクリプトを待つ上限を表す。
+// 超えた場合は再試行を中断し、スクリプトを停止する。
+const notifyShutdownWait = 5 * time.Second
+
+// notifyWebhooks は DD-HOOK-001 の開いているプロジェクトの設定に従い、課題の変更 event を通知先へ送る。
+// 再試行を含む送信で操作の応答を待たせないよう別のゴルーチンで送り、届かなかった通知はログへ記録する。
+// actor が空の場合は Contractor のアカウント名を用いる。
+func (a *App) notifyWebhooks(ctx context.Context, session *projectsession.Session, event, actor string, detail present.IssueDetailDTO) {
+	a.projectMu.RLock()
+	hooks := a.projectConfig.Webhooks
+	a.projectMu.RUnlock()
+	if len(hooks) == 0 {
+		return
 	}
-	service := issueops.NewService(a.root, a.validator)
-	attachments := make([]issueops.CommentAttachmentInput, 0, len(dto.Attachments))
-	for _, attachment := range dto.Attachments {
-		data, err := os.ReadFile(attachment.SourcePath)
-		if err != nil {
-			return present.Fail(err)
+	currentMode, user := a.callMode(ctx)
+	if actor == "" {
+		actor = user
+	}
+	payload := webhook.Payload{
+		Event:      event,
+		OccurredAt: timeutil.NowISO8601(),
+		Project:    filepath.Base(session.Root()),
+		Category:   detail.Category,
+		IssueID:    detail.IssueID,
+		Actor:      actor,
+		Mode:       string(currentMode),
+		Issue:      detail,
+	}
+	a.notifyWG.Add(1)
+	go func() {
+		defer a.notifyWG.Done()
+		for _, delivery := range a.webhooks.Send(a.notifyCtx, hooks, payload) {
+			if !delivery.Delivered {
+				a.logger.ErrorContext(ctx, "webhook delivery failed", map[string]any{
+					"event": event, "target": delivery.Target, "attempts": delivery.Attempts, "detail": delivery.Error,
+				})
+			}
 		}
-		original := attachment.OriginalFileName
-		if original == "" {
-			original = filepath.Base(attachment.SourcePath)
+	}()
+}
+
+// stopNotifications は DD-HOOK-001 の送信中の通知と DD-HOOK-003 の実行中のスクリプトを notifyShutdownWait まで待ち、
+// 残りの再試行の中断とスクリプトの停止を行って終わるのを待つ。
+func (a *App) stopNotifications() {
+	done := make(chan struct{})
+	go func() {
+		a.notifyWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(notifyShutdownWait):
+	}
+	a.notifyCancel()
+	<-done
+}
+
+// runPostSave は DD-HOOK-003 の端末ごとの config.json の hooks のスクリプトと DD-PLUGIN-004 の保存後の処理を行うプラグインを、
+// 課題の変更 events ごとに順に実行する。操作の応答を待たせないよう別のゴルーチンで実行し、失敗はログへ記録する。
+// config.json を読めない場合はスクリプトを実行しない。actor が空の場合は Contractor のアカウント名を用いる。
+func (a *App) runPostSave(ctx context.Context, session *projectsession.Session, actor string, value issue.Issue, events ...string) {
+	var hooks *configrepo.Hooks
+	if cfg, hasConfig, err := a.configRepo.Load(); err == nil && hasConfig {
+		hooks = cfg.Hooks
+	}
+	if hooks == nil && len(a.plugins.Plugins()) == 0 {
+		return
+	}
+	currentMode, user := a.callMode(ctx)
+	if actor == "" {
+		actor = user
+	}
+	baseDir := filepath.Dir(a.configRepo.Path())
+	event := pluginhost.PostSaveEvent{
+		ProjectRoot: session.Root(),
+		Category:    value.Category,
+		IssueID:     value.IssueID,
+		Mode:        string(currentMode),
+		Actor:       actor,
+		Issue:       value,
+	}
+	a.notifyWG.Add(1)
+	go func() {
+		defer a.notifyWG.Done()
+		for _, name := range events {
+			event.Event = name
+			if err := scripthook.Run(a.notifyCtx, hooks, baseDir, scripthook.Invocation(event)); err != nil {
+				a.logger.ErrorContext(ctx, "script hook failed", map[string]any{"event": name, "detail": err.Error()})
+			}
+			for _, err := range a.plugins.PostSave(a.notifyCtx, event) {
+				a.logger.ErrorContext(ctx, "plugin post-save action failed", map[string]any{"event": name, "detail": err.Error()})
+			}
+		}
+	}()
+}
+
+// issueFilePath は DD-JOURNAL-001 の課題JSONのプロジェクトルートからの相対パスを返す。
+func issueFilePath(category, issueID string) string {
+	return category + "/" + issueID + ".json"
+}
+
+// attachmentFilePaths は DD-JOURNAL-001 のコメントが参照する添付のプロジェクトルートからの相対パスを返す。
+func attachmentFilePaths(category string, comments []issue.Comment) []string {
+	var paths []string
+	for _, comment := range comments {
+		for _, attachment := range comment.Attachments {
+			paths = append(paths, category+"/"+attachment.RelativePath)
 		}
-		attachments = append(attachments, issueops.CommentAttachmentInput{
-			OriginalName: original,
-			Data:         data,
-			MimeType:     attachment.MimeType,
-		})
 	}
-	detail, err := service.AddComment(category, issueID, a.mode, issueops.CommentCreateInput{
-		Body:        dto.Body,
-		AuthorName:  dto.AuthorName,
-		Attachments: attachments,
-	})
-	if err != nil {
-		return present.Fail(err)
+	return paths
+}
+
+// toValidationResultDTO は DD-BE-003 の検証結果を DTO に変換する。
+func toValidationResultDTO(result projectroot.ValidationResult) present.ValidationResultDTO {
+	dto := present.ValidationResultDTO{
+		IsValid:        result.IsValid,
+		NormalizedPath: result.NormalizedPath,
+		Message:        result.Message,
 	}
-	return present.Ok(present.ToIssueDetailDTO(detail))
+	if result.Details != "" {
+		value := result.Details
+		dto.Details = &value
+	}
+	return dto
 }
 
-func loadValidator(exePath string) *schema.Validator {
+// schemaDir は DD-DIAG-001 の読み込むスキーマのディレクトリを loadValidator と同じ優先順で返す。
+func schemaDir(exePath string) string {
 	if exePath != "" {
 		dir := filepath.Join(filepath.Dir(exePath), "schemas")
-		if validator, err := schema.NewValidatorFromDir(dir); err == nil {
-			return validator
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
 		}
 	}
-	if validator, err := schema.NewValidatorFromDir("schemas"); err == nil {
-		return validator
+	return "schemas"
+}
+
+// logExtensionProblems は DD-BE-002 の読み込めなかった custom_fields の追加スキーマをログへ記録する。
+// 読み込めなかった追加スキーマは検証に用いず、他のスキーマでの検証は続ける。
+func (a *App) logExtensionProblems() {
+	if a.validator == nil {
+		return
+	}
+	for _, problem := range a.validator.ExtensionProblems() {
+		a.logger.Error("schema extension not loaded", map[string]any{
+			"extension": problem.Name,
+			"detail":    problem.Err.Error(),
+		})
+	}
+}
+
+// logPluginProblems は DD-PLUGIN-001 の読み込めず除いたプラグインをログへ記録する。プラグインが無くても起動は続ける。
+func (a *App) logPluginProblems(problems []error) {
+	for _, problem := range problems {
+		a.logger.Error("plugin not loaded", map[string]any{"detail": problem.Error()})
+	}
+}
+
+// checkPluginRules は DD-PLUGIN-003 の検証を行うプラグインで保存前の課題を検証する。
+func (a *App) checkPluginRules(category string, value issue.Issue) error {
+	return a.plugins.Validate(context.Background(), category, value)
+}
+
+// loadValidator は DD-BE-002 のスキーマを実行ファイル隣の schemas、カレントディレクトリの schemas の順に読み込む。
+// いずれも無い場合は実行ファイルへ同梱したスキーマを用いるため、nil を返すのは同梱のスキーマが壊れている場合に限る。
+func loadValidator(exePath string) *schema.Validator {
+	exeDir := ""
+	if exePath != "" {
+		exeDir = filepath.Join(filepath.Dir(exePath), "schemas")
+	}
+	validator, err := schema.NewValidator(exeDir, "schemas")
+	if err != nil {
+		return nil
 	}
-	return nil
+	return validator
 }
@@ -0,0 +1,619 @@
+// app_test.go は GUI のバインド (App) の書き込み用ロック・操作モード・カテゴリ権限の判定、セッションのキャッシュ、
+// 操作記録による取り消し、形式移行・パッチ・同期、ロックの引き継ぎ、変更の通知とフックのテストを行う。
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"ratta/internal/app/modesession"
+	mod "ratta/internal/domain/mode"
+	"ratta/internal/infra/configrepo"
+	"ratta/internal/infra/projectlock"
+	"ratta/internal/infra/projectmeta"
+	"ratta/internal/infra/tmpresidue"
+	"ratta/internal/present"
+	grpctransport "ratta/internal/transport/grpc"
+	"ratta/internal/transport/grpc/rattav1"
+)
+
+// newTestApp は root を開いた App を、テスト用の config.json で作成する。終了時に書き込み用ロックを解放する。
+func newTestApp(t *testing.T, root string, observer bool) *App {
+	t.Helper()
+	app := NewApp(startupOptions{Root: root, ConfigPath: filepath.Join(t.TempDir(), "config.json"), Observer: observer})
+	t.Cleanup(func() { app.shutdown(context.Background()) })
+	return app
+}
+
+// newTestProject は課題を置けるカテゴリ A を持つプロジェクトルートを作成する。
+func newTestProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "A"), 0o750); err != nil {
+		t.Fatalf("Mkdir error: %v", err)
+	}
+	return root
+}
+
+// holdLock は root の書き込み用ロックを、他の端末のインスタンスが保持している状態にする。
+func holdLock(t *testing.T, root string) {
+	t.Helper()
+	updatedAt := time.Now().UTC().Format(time.RFC3339)
+	data, err := json.Marshal(projectlock.Holder{Hostname: "other-host", PID: 1, Instance: "other", AcquiredAt: updatedAt, UpdatedAt: updatedAt})
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, projectlock.FileName), data, 0o600); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+}
+
+// dialApp は app へ委ねる gRPC サーバーをメモリ上の接続で起動し、クライアントを返す。
+func dialApp(t *testing.T, app *App) rattav1.RattaServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpctransport.New(grpcBackend{app: app})
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	conn, err := grpcgo.NewClient("passthrough:///bufnet",
+		grpcgo.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpcgo.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return rattav1.NewRattaServiceClient(conn)
+}
+
+func TestGRPC_CreatesIssueInVendorMode(t *testing.T) {
+	// 書き込み用ロックを保持する Vendor モードでは、GUI と同じく課題を作成・取得できることを確認する。
+	client := dialApp(t, newTestApp(t, newTestProject(t), false))
+	created, err := client.CreateIssue(context.Background(), &rattav1.CreateIssueRequest{Category: "A", Title: "title", Description: "description", Priority: "High", DueDate: "2030-01-01"})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	got, err := client.GetIssue(context.Background(), &rattav1.GetIssueRequest{Category: "A", IssueId: created.GetIssueId()})
+	if err != nil {
+		t.Fatalf("GetIssue error: %v", err)
+	}
+	if got.GetTitle() != "title" || got.GetOriginCompany() != "Vendor" {
+		t.Fatalf("unexpected issue: %v", got)
+	}
+}
+
+func TestGRPC_RejectsContractorOnlyOperationInVendorMode(t *testing.T) {
+	// カテゴリ作成は Contractor 専用のため、gRPC から呼び出しても Vendor モードでは権限不足とすることを確認する。
+	client := dialApp(t, newTestApp(t, newTestProject(t), false))
+	_, err := client.CreateCategory(context.Background(), &rattav1.CreateCategoryRequest{Name: "B"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied, got %v", err)
+	}
+}
+
+func TestGRPC_RejectsWritesInObserverMode(t *testing.T) {
+	// Observer モードでは一覧は返し、変更は権限不足として拒否することを確認する。
+	client := dialApp(t, newTestApp(t, newTestProject(t), true))
+	if _, err := client.ListCategories(context.Background(), &rattav1.ListCategoriesRequest{}); err != nil {
+		t.Fatalf("ListCategories error: %v", err)
+	}
+	_, err := client.CreateIssue(context.Background(), &rattav1.CreateIssueRequest{Category: "A", Title: "title", Description: "description", Priority: "High", DueDate: "2030-01-01"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied, got %v", err)
+	}
+}
+
+func TestGRPC_RejectsWritesWithoutProjectLock(t *testing.T) {
+	// 他のインスタンスが書き込み用に開いているプロジェクトでは、変更を状態の競合として拒否することを確認する。
+	root := newTestProject(t)
+	holdLock(t, root)
+	client := dialApp(t, newTestApp(t, root, false))
+	var trailer metadata.MD
+	_, err := client.CreateIssue(context.Background(), &rattav1.CreateIssueRequest{Category: "A", Title: "title", Description: "description", Priority: "High", DueDate: "2030-01-01"}, grpcgo.Trailer(&trailer))
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition, got %v", err)
+	}
+	if got := trailer.Get(grpctransport.ErrorCodeTrailer); len(got) != 1 || got[0] != "E_CONFLICT" {
+		t.Fatalf("unexpected error code trailer: %v", got)
+	}
+}
+
+func TestGRPC_DoesNotInheritContractorMode(t *testing.T) {
+	// GUI が Contractor モードでも gRPC からの呼び出しは Vendor として判定・記録し、GUI の権限は変わらないことを確認する。
+	app := newTestApp(t, newTestProject(t), false)
+	app.modes.Enter(mod.ModeContractor, "admin")
+	client := dialApp(t, app)
+	if _, err := client.CreateCategory(context.Background(), &rattav1.CreateCategoryRequest{Name: "B"}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied, got %v", err)
+	}
+	created, err := client.CreateIssue(context.Background(), &rattav1.CreateIssueRequest{Category: "A", Title: "title", Description: "description", Priority: "High", DueDate: "2030-01-01"})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	if created.GetOriginCompany() != "Vendor" {
+		t.Fatalf("expected Vendor origin, got %q", created.GetOriginCompany())
+	}
+	commented, err := client.AddComment(context.Background(), &rattav1.AddCommentRequest{Category: "A", IssueId: created.GetIssueId(), Body: "from tool", AuthorName: "tool"})
+	if err != nil {
+		t.Fatalf("AddComment error: %v", err)
+	}
+	if comments := commented.GetComments(); len(comments) != 1 || comments[0].GetAuthorCompany() != "Vendor" {
+		t.Fatalf("unexpected comment: %v", comments)
+	}
+	if app.modes.Mode() != mod.ModeContractor {
+		t.Fatalf("expected GUI to stay Contractor, got %s", app.modes.Mode())
+	}
+	mustOk(t, app.CreateCategory("B"))
+}
+
+func TestGRPC_DoesNotExtendContractorIdleTimeout(t *testing.T) {
+	// gRPC からの呼び出しを続けても Contractor モードの無操作時間の計測をやり直さず、上限で Vendor モードへ戻ることを確認する。
+	app := newTestApp(t, newTestProject(t), false)
+	app.modes = modesession.New(mod.ModeVendor, 200*time.Millisecond, nil)
+	app.modes.Enter(mod.ModeContractor, "admin")
+	client := dialApp(t, app)
+	deadline := time.Now().Add(time.Second)
+	for app.modes.Mode() == mod.ModeContractor {
+		if time.Now().After(deadline) {
+			t.Fatal("expected gRPC polling not to keep Contractor mode")
+		}
+		if _, err := client.ListIssues(context.Background(), &rattav1.ListIssuesRequest{Category: "A", Page: 1, PageSize: 20}); err != nil {
+			t.Fatalf("ListIssues error: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestGRPC_UpdateIssueDetectsStaleRevision(t *testing.T) {
+	// 課題詳細の revision を expected_revision に渡すと、その後に変更された課題の更新を競合として拒否することを確認する。
+	client := dialApp(t, newTestApp(t, newTestProject(t), false))
+	created, err := client.CreateIssue(context.Background(), &rattav1.CreateIssueRequest{Category: "A", Title: "title", Description: "description", Priority: "High", DueDate: "2030-01-01"})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	if created.GetRevision() == "" {
+		t.Fatal("expected revision in issue detail")
+	}
+	update := &rattav1.UpdateIssueRequest{
+		Category: "A", IssueId: created.GetIssueId(), Title: "first", Description: "description",
+		Priority: "High", DueDate: "2030-01-01", Status: created.GetStatus(), ExpectedRevision: created.GetRevision(),
+	}
+	if _, err := client.UpdateIssue(context.Background(), update); err != nil {
+		t.Fatalf("UpdateIssue error: %v", err)
+	}
+	update.Title = "second"
+	var trailer metadata.MD
+	_, err = client.UpdateIssue(context.Background(), update, grpcgo.Trailer(&trailer))
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition, got %v", err)
+	}
+	if got := trailer.Get(grpctransport.ErrorCodeTrailer); len(got) != 1 || got[0] != present.ErrorConflict {
+		t.Fatalf("unexpected error code trailer: %v", got)
+	}
+}
+
+// mustOk は resp が成功であることを確認し、データを返す。
+func mustOk(t *testing.T, resp present.Response) any {
+	t.Helper()
+	if !resp.Ok {
+		t.Fatalf("unexpected failure: %+v", resp.Error)
+	}
+	return resp.Data
+}
+
+// errorCode は resp が失敗であることを確認し、エラーコードを返す。
+func errorCode(t *testing.T, resp present.Response) string {
+	t.Helper()
+	if resp.Ok || resp.Error == nil {
+		t.Fatalf("expected failure, got %+v", resp.Data)
+	}
+	return resp.Error.ErrorCode
+}
+
+// createIssue は category に課題を作成し、課題詳細を返す。
+func createIssue(t *testing.T, app *App, category, title string) present.IssueDetailDTO {
+	t.Helper()
+	return mustOk(t, app.CreateIssue(category, present.IssueCreateDTO{
+		Title: title, Description: "description", Priority: "High", DueDate: "2030-01-01",
+	})).(present.IssueDetailDTO)
+}
+
+// waitOperations はバックグラウンドの処理がすべて終わるまで待つ。
+func waitOperations(t *testing.T, app *App) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for app.operations.Running() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("operations did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWritableProject_RejectsObserverModeAndLockedProject(t *testing.T) {
+	// 変更を伴うバインドは Observer モードでは E_PERMISSION、他のインスタンスが書き込み用に開いている場合は E_CONFLICT とし、
+	// 読み取りは許すことを確認する。
+	locked := newTestProject(t)
+	holdLock(t, locked)
+	apps := map[string]*App{
+		present.ErrorPermission: newTestApp(t, newTestProject(t), true),
+		present.ErrorConflict:   newTestApp(t, locked, false),
+	}
+	for want, app := range apps {
+		calls := map[string]present.Response{
+			"UpdateIssue":       app.UpdateIssue("A", "abc123def", present.IssueUpdateDTO{Title: "t"}),
+			"AddComment":        app.AddComment("A", "abc123def", present.CommentCreateDTO{Body: "body"}),
+			"UndoLastOperation": app.UndoLastOperation(),
+			"ImportIssueBundle": app.ImportIssueBundle("A", "bundle.zip"),
+			"StartApplyPatch":   app.StartApplyPatch(present.PatchApplyQueryDTO{Path: "patch.zip"}),
+			"StartSyncProject":  app.StartSyncProject(present.SyncQueryDTO{OtherRoot: t.TempDir()}),
+		}
+		for name, resp := range calls {
+			if got := errorCode(t, resp); got != want {
+				t.Fatalf("%s: expected %s, got %s", name, want, got)
+			}
+		}
+		mustOk(t, app.ListIssues("A", present.IssueListQueryDTO{Page: 1, PageSize: 20}))
+	}
+}
+
+func TestEnterObserverMode_StopsResidueScan(t *testing.T) {
+	// Observer モードへ切り替えて書き込み用ロックを解放した後は、一時ファイル残骸の定期的な検出 (削除を伴う) を止めることを確認する。
+	app := newTestApp(t, newTestProject(t), false)
+	app.residueScan = tmpresidue.Schedule(t.TempDir(), time.Hour, func([]tmpresidue.ScanResult, error) {})
+	mustOk(t, app.EnterObserverMode())
+	if app.residueScan != nil {
+		t.Fatal("expected residue scan to be stopped")
+	}
+	if app.lock != nil {
+		t.Fatal("expected project lock to be released")
+	}
+}
+
+func TestCreateCategory_RequiresContractorMode(t *testing.T) {
+	// カテゴリ作成は Vendor モードでは権限不足とし、Contractor モードでは作成することを確認する。
+	app := newTestApp(t, newTestProject(t), false)
+	if got := errorCode(t, app.CreateCategory("B")); got != present.ErrorPermission {
+		t.Fatalf("expected E_PERMISSION, got %s", got)
+	}
+	app.modes.Enter(mod.ModeContractor, "admin")
+	mustOk(t, app.CreateCategory("B"))
+}
+
+func TestCreateIssue_FollowsCategoryPermissions(t *testing.T) {
+	// カテゴリ権限で Contractor のみに限ったカテゴリは、Vendor モードでは課題を作成できないことを確認する。
+	root := newTestProject(t)
+	err := projectmeta.SavePermissions(root, projectmeta.Permissions{Categories: map[string]projectmeta.CategoryPermission{
+		"A": {Writers: []mod.Mode{mod.ModeContractor}},
+	}})
+	if err != nil {
+		t.Fatalf("SavePermissions error: %v", err)
+	}
+	app := newTestApp(t, root, false)
+	resp := app.CreateIssue("A", present.IssueCreateDTO{Title: "t", Description: "d", Priority: "High", DueDate: "2030-01-01"})
+	if got := errorCode(t, resp); got != present.ErrorPermission {
+		t.Fatalf("expected E_PERMISSION, got %s", got)
+	}
+	app.modes.Enter(mod.ModeContractor, "admin")
+	createIssue(t, app, "A", "title")
+}
+
+func TestUpdateIssue_InvalidatesSessionCache(t *testing.T) {
+	// 一覧の読み込みでキャッシュした課題を更新した後は、一覧と詳細が更新後の内容を返すことを確認する。
+	app := newTestApp(t, newTestProject(t), false)
+	created := createIssue(t, app, "A", "before")
+	mustOk(t, app.ListIssues("A", present.IssueListQueryDTO{Page: 1, PageSize: 20}))
+	mustOk(t, app.UpdateIssue("A", created.IssueID, present.IssueUpdateDTO{
+		Title: "after", Description: "description", Priority: "High", DueDate: "2030-01-01", Status: string(created.Status),
+	}))
+	list := mustOk(t, app.ListIssues("A", present.IssueListQueryDTO{Page: 1, PageSize: 20})).(present.IssueListDTO)
+	if len(list.Issues) != 1 || list.Issues[0].Title != "after" {
+		t.Fatalf("unexpected list: %+v", list.Issues)
+	}
+	detail := mustOk(t, app.GetIssue("A", created.IssueID)).(present.IssueDetailDTO)
+	if detail.Title != "after" {
+		t.Fatalf("unexpected detail: %+v", detail)
+	}
+}
+
+func TestSetRoot_ReleasesPreviousProjectLock(t *testing.T) {
+	// プロジェクトを切り替えた場合は前のプロジェクトの書き込み用ロックを解放し、新しいプロジェクトのロックを取得することを確認する。
+	first, second := newTestProject(t), newTestProject(t)
+	app := newTestApp(t, first, false)
+	if _, err := os.Stat(filepath.Join(first, projectlock.FileName)); err != nil {
+		t.Fatalf("expected lock on first project: %v", err)
+	}
+	app.setRoot(second)
+	if _, err := os.Stat(filepath.Join(first, projectlock.FileName)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected first lock to be released: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(second, projectlock.FileName)); err != nil {
+		t.Fatalf("expected lock on second project: %v", err)
+	}
+	createIssue(t, app, "A", "title")
+	if entries, _ := os.ReadDir(filepath.Join(first, "A")); len(entries) != 0 {
+		t.Fatalf("issue should be created in the second project: %v", entries)
+	}
+}
+
+func TestAddComment_SerializesConcurrentComments(t *testing.T) {
+	// 同じ課題への並行したコメント追加を課題ごとのロックで直列化し、いずれのコメントも失わないことを確認する。
+	app := newTestApp(t, newTestProject(t), false)
+	created := createIssue(t, app, "A", "title")
+	const writers = 8
+	var wg sync.WaitGroup
+	failures := make(chan *present.APIErrorDTO, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp := app.AddComment("A", created.IssueID, present.CommentCreateDTO{Body: fmt.Sprintf("comment %d", i), AuthorName: "sato"})
+			if !resp.Ok {
+				failures <- resp.Error
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(failures)
+	for failure := range failures {
+		t.Fatalf("AddComment failed: %+v", failure)
+	}
+	detail := mustOk(t, app.GetIssue("A", created.IssueID)).(present.IssueDetailDTO)
+	if len(detail.Comments) != writers {
+		t.Fatalf("expected %d comments, got %d", writers, len(detail.Comments))
+	}
+}
+
+func TestUndoLastOperation_RevertsOperationsInReverseOrder(t *testing.T) {
+	// 取り消しは直前の操作から順に戻し、作成の取り消しで課題を削除し、記録が無くなれば E_NOT_FOUND とすることを確認する。
+	root := newTestProject(t)
+	app := newTestApp(t, root, false)
+	created := createIssue(t, app, "A", "before")
+	mustOk(t, app.UpdateIssue("A", created.IssueID, present.IssueUpdateDTO{
+		Title: "after", Description: "description", Priority: "High", DueDate: "2030-01-01", Status: string(created.Status),
+	}))
+	undone := mustOk(t, app.UndoLastOperation()).(present.UndoDTO)
+	if undone.IssueID != created.IssueID || undone.Operation != journalIssueUpdated {
+		t.Fatalf("unexpected undo: %+v", undone)
+	}
+	if detail := mustOk(t, app.GetIssue("A", created.IssueID)).(present.IssueDetailDTO); detail.Title != "before" {
+		t.Fatalf("expected title to be restored, got %q", detail.Title)
+	}
+	mustOk(t, app.UndoLastOperation())
+	if _, err := os.Stat(filepath.Join(root, "A", created.IssueID+".json")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected created issue to be removed: %v", err)
+	}
+	list := mustOk(t, app.ListIssues("A", present.IssueListQueryDTO{Page: 1, PageSize: 20})).(present.IssueListDTO)
+	if len(list.Issues) != 0 {
+		t.Fatalf("unexpected issues after undo: %+v", list.Issues)
+	}
+	if got := errorCode(t, app.UndoLastOperation()); got != present.ErrorNotFound {
+		t.Fatalf("expected E_NOT_FOUND, got %s", got)
+	}
+}
+
+func TestUndoLastOperation_RejectsExternallyChangedIssue(t *testing.T) {
+	// 操作の後に外部で課題JSONが変更された場合は、その変更を失わないよう取り消しを拒否することを確認する。
+	root := newTestProject(t)
+	app := newTestApp(t, root, false)
+	created := createIssue(t, app, "A", "title")
+	path := filepath.Join(root, "A", created.IssueID+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Replace(string(data), `"title"`, `"edited"`, 1)), 0o600); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+	if got := errorCode(t, app.UndoLastOperation()); got != present.ErrorConflict {
+		t.Fatalf("expected E_CONFLICT, got %s", got)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("issue should be kept: %v", err)
+	}
+}
+
+func TestUndoLastOperation_ChecksCurrentMode(t *testing.T) {
+	// 取り消しは取り消す時点の操作モードでカテゴリ権限を検査し、Contractor 専用のカテゴリの操作を Vendor モードでは戻さないことを確認する。
+	root := newTestProject(t)
+	err := projectmeta.SavePermissions(root, projectmeta.Permissions{Categories: map[string]projectmeta.CategoryPermission{
+		"A": {Writers: []mod.Mode{mod.ModeContractor}},
+	}})
+	if err != nil {
+		t.Fatalf("SavePermissions error: %v", err)
+	}
+	app := newTestApp(t, root, false)
+	app.modes.Enter(mod.ModeContractor, "admin")
+	created := createIssue(t, app, "A", "title")
+	app.modes.Enter(mod.ModeVendor, "")
+	if got := errorCode(t, app.UndoLastOperation()); got != present.ErrorPermission {
+		t.Fatalf("expected E_PERMISSION, got %s", got)
+	}
+	mustOk(t, app.GetIssue("A", created.IssueID))
+}
+
+func TestStartMigrateProject_RequiresContractorModeToRewrite(t *testing.T) {
+	// 形式移行の書き換えは Contractor モードに限り、dry-run は Vendor・Observer モードでも行えることを確認する。
+	vendor := newTestApp(t, newTestProject(t), false)
+	if got := errorCode(t, vendor.StartMigrateProject(false)); got != present.ErrorPermission {
+		t.Fatalf("expected E_PERMISSION, got %s", got)
+	}
+	mustOk(t, vendor.StartMigrateProject(true))
+	waitOperations(t, vendor)
+
+	observer := newTestApp(t, newTestProject(t), true)
+	if got := errorCode(t, observer.StartMigrateProject(false)); got != present.ErrorPermission {
+		t.Fatalf("expected E_PERMISSION, got %s", got)
+	}
+	mustOk(t, observer.StartMigrateProject(true))
+	waitOperations(t, observer)
+
+	vendor.modes.Enter(mod.ModeContractor, "admin")
+	mustOk(t, vendor.StartMigrateProject(false))
+	waitOperations(t, vendor)
+}
+
+func TestPatch_AppliesExportedIssues(t *testing.T) {
+	// 出力したパッチを別のプロジェクトへ取り込み、取り込み先の一覧のキャッシュを破棄して課題を返すことを確認する。
+	source := newTestApp(t, newTestProject(t), false)
+	created := createIssue(t, source, "A", "patched")
+	patchPath := filepath.Join(t.TempDir(), "changes.zip")
+	mustOk(t, source.StartExportPatch(present.PatchExportQueryDTO{DestPath: patchPath, Passphrase: "shared-secret"}))
+	waitOperations(t, source)
+
+	target := newTestApp(t, newTestProject(t), false)
+	mustOk(t, target.ListIssues("A", present.IssueListQueryDTO{Page: 1, PageSize: 20}))
+	mustOk(t, target.StartApplyPatch(present.PatchApplyQueryDTO{Path: patchPath, Passphrase: "shared-secret"}))
+	waitOperations(t, target)
+	list := mustOk(t, target.ListIssues("A", present.IssueListQueryDTO{Page: 1, PageSize: 20})).(present.IssueListDTO)
+	if len(list.Issues) != 1 || list.Issues[0].IssueID != created.IssueID {
+		t.Fatalf("unexpected issues after apply: %+v", list.Issues)
+	}
+}
+
+func TestStartSyncProject_SkipsOtherProjectInUse(t *testing.T) {
+	// 同期の相手を他のインスタンスが書き込み用に開いている場合は相手を変更せず、開いていない場合は課題を同期することを確認する。
+	app := newTestApp(t, newTestProject(t), false)
+	created := createIssue(t, app, "A", "synced")
+	other := newTestProject(t)
+	holdLock(t, other)
+	mustOk(t, app.StartSyncProject(present.SyncQueryDTO{OtherRoot: other}))
+	waitOperations(t, app)
+	if _, err := os.Stat(filepath.Join(other, "A", created.IssueID+".json")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("locked project should not be changed: %v", err)
+	}
+	if err := os.Remove(filepath.Join(other, projectlock.FileName)); err != nil {
+		t.Fatalf("Remove error: %v", err)
+	}
+	mustOk(t, app.StartSyncProject(present.SyncQueryDTO{OtherRoot: other}))
+	waitOperations(t, app)
+	if _, err := os.Stat(filepath.Join(other, "A", created.IssueID+".json")); err != nil {
+		t.Fatalf("expected issue to be synced: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(other, projectlock.FileName)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("lock of the other project should be released: %v", err)
+	}
+}
+
+func TestTakeOverProjectLock_TakesOverOnlyStaleLock(t *testing.T) {
+	// 更新の途絶えたロックは引き継いで書き込み可能にし、更新され続けているロックは E_CONFLICT として引き継がないことを確認する。
+	fresh := newTestProject(t)
+	holdLock(t, fresh)
+	if got := errorCode(t, newTestApp(t, fresh, false).TakeOverProjectLock()); got != present.ErrorConflict {
+		t.Fatalf("expected E_CONFLICT, got %s", got)
+	}
+
+	stale := newTestProject(t)
+	updatedAt := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	data, err := json.Marshal(projectlock.Holder{Hostname: "other-host", PID: 1, Instance: "other", AcquiredAt: updatedAt, UpdatedAt: updatedAt})
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(stale, projectlock.FileName), data, 0o600); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+	app := newTestApp(t, stale, false)
+	if got := errorCode(t, app.UpdateIssue("A", "abc123def", present.IssueUpdateDTO{Title: "t"})); got != present.ErrorConflict {
+		t.Fatalf("expected read-only before take over, got %s", got)
+	}
+	opened := mustOk(t, app.TakeOverProjectLock()).(present.ProjectOpenDTO)
+	if opened.Root != stale {
+		t.Fatalf("unexpected root: %s", opened.Root)
+	}
+	createIssue(t, app, "A", "title")
+
+	observer := newTestApp(t, newTestProject(t), true)
+	if got := errorCode(t, observer.TakeOverProjectLock()); got != present.ErrorPermission {
+		t.Fatalf("expected E_PERMISSION, got %s", got)
+	}
+}
+
+func TestNotifyWebhooks_PostsIssueChanges(t *testing.T) {
+	// プロジェクトの設定の通知先へ、課題の作成とコメントの追加を変更の種類ごとに送ることを確認する。
+	var mu sync.Mutex
+	var events []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Event   string `json:"event"`
+			IssueID string `json:"issue_id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		events = append(events, payload.Event+" "+payload.IssueID)
+		mu.Unlock()
+	}))
+	defer server.Close()
+	root := newTestProject(t)
+	writeJSON(t, projectmeta.ConfigPath(root), projectmeta.ProjectConfig{FormatVersion: 1, Webhooks: []projectmeta.Webhook{{URL: server.URL}}})
+	app := newTestApp(t, root, false)
+	created := createIssue(t, app, "A", "title")
+	mustOk(t, app.AddComment("A", created.IssueID, present.CommentCreateDTO{Body: "body", AuthorName: "sato"}))
+	app.notifyWG.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	slices.Sort(events)
+	want := []string{"issue.commented " + created.IssueID, "issue.created " + created.IssueID}
+	if !slices.Equal(events, want) {
+		t.Fatalf("unexpected events: %v", events)
+	}
+}
+
+func TestRunPostSave_RunsConfiguredHookScript(t *testing.T) {
+	// config.json の hooks に設定したスクリプトを、課題の作成の際にプロジェクトルートで実行することを確認する。
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on windows")
+	}
+	configDir, root := t.TempDir(), newTestProject(t)
+	if err := os.WriteFile(filepath.Join(configDir, "created.sh"), []byte("#!/bin/sh\necho \"$RATTA_EVENT $RATTA_ISSUE_ID\" > hook.txt\n"), 0o755); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+	cfg := configrepo.DefaultConfig()
+	cfg.Hooks = &configrepo.Hooks{OnIssueCreated: "created.sh"}
+	writeJSON(t, filepath.Join(configDir, "config.json"), cfg)
+	app := NewApp(startupOptions{Root: root, ConfigPath: filepath.Join(configDir, "config.json")})
+	t.Cleanup(func() { app.shutdown(context.Background()) })
+	created := createIssue(t, app, "A", "title")
+	app.notifyWG.Wait()
+	output, err := os.ReadFile(filepath.Join(root, "hook.txt"))
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	if got := strings.TrimSpace(string(output)); got != "issue.created "+created.IssueID {
+		t.Fatalf("unexpected hook output: %q", got)
+	}
+}
+
+// writeJSON は value を JSON で path へ書き込む。親ディレクトリが無い場合は作成する。
+func writeJSON(t *testing.T, path string, value any) {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatalf("MkdirAll error: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+}
@@ -1,6 +1,6 @@
 import { describe, expect, it, vi } from 'vitest'
 
-import { ApiError, getAppBootstrap, unwrapResponse } from '../utils/apiClient'
+import { ApiError, getAppBootstrap, onResponseWarnings, unwrapResponse } from '../utils/apiClient'
 
 vi.mock('../../wailsjs/go/main/App.js', () => ({
   GetAppBootstrap: vi.fn()
@@ -22,6 +22,20 @@ describe('api client', () => {
     ).toThrow(ApiError)
   })
 
+  it('passes warnings of ok response to the handler', () => {
+    // ok=true でも warnings があれば通知先へ渡し、data を返すことを確認する。
+    const handler = vi.fn()
+    onResponseWarnings(handler)
+    const warnings = [{ error_code: 'E_MIME_MISMATCH', message: 'mismatch' }]
+
+    expect(unwrapResponse({ ok: true, data: 1, warnings }, 'AddComment')).toBe(1)
+    unwrapResponse({ ok: true, data: 2 }, 'Test')
+
+    expect(handler).toHaveBeenCalledTimes(1)
+    expect(handler).toHaveBeenCalledWith(warnings, 'AddComment')
+    onResponseWarnings(null)
+  })
+
   it('wraps GetAppBootstrap', async () => {
     // Wails バインディングを呼び出して結果を返すことを確認する。
     App.GetAppBootstrap.mockResolvedValue({ ok: true, data: { has_config: true } })
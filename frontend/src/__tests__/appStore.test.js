@@ -9,8 +9,13 @@ vi.mock('../utils/apiClient', () => ({
   getAppBootstrap: vi.fn(),
   validateProjectRoot: vi.fn(),
   saveLastProjectRoot: vi.fn(),
+  saveSettings: vi.fn(),
+  openProjectRoot: vi.fn(),
   createProjectRoot: vi.fn(),
   detectMode: vi.fn(),
+  enterObserverMode: vi.fn(),
+  lockMode: vi.fn(),
+  unlockRememberedContractor: vi.fn(),
   verifyContractorPassword: vi.fn()
 }))
 
@@ -37,6 +42,165 @@ describe('app store', () => {
     expect(store.bootstrapLoaded).toBe(true)
   })
 
+  it('unlocks with the remembered credential on bootstrap', async () => {
+    // この端末で認証を記憶している場合は起動時にパスワード入力なしで Contractor になることを確認する。
+    setActivePinia(createPinia())
+    const store = useAppStore()
+
+    apiClient.getAppBootstrap.mockResolvedValue({
+      has_contractor_auth_file: true,
+      contractor_remember_supported: true
+    })
+    apiClient.unlockRememberedContractor.mockResolvedValue({ mode: 'Contractor', username: 'alice' })
+
+    await store.bootstrap()
+
+    expect(store.contractorRememberSupported).toBe(true)
+    expect(store.mode).toBe('Contractor')
+    expect(store.contractorUser).toBe('alice')
+    expect(store.contractorAuthRequired).toBe(false)
+  })
+
+  it('uses the startup project root from bootstrap', async () => {
+    // 起動引数で開いたプロジェクトがあれば選択ダイアログを経ずに projectRoot へ反映されることを確認する。
+    setActivePinia(createPinia())
+    const store = useAppStore()
+
+    apiClient.getAppBootstrap.mockResolvedValue({
+      last_project_root_path: 'C:/old',
+      startup_project_root: 'C:/pinned'
+    })
+
+    await store.bootstrap()
+
+    expect(store.projectRoot).toBe('C:/pinned')
+    expect(store.lastProjectRootPath).toBe('C:/pinned')
+  })
+
+  it('applies and saves application settings', async () => {
+    // 起動時情報の設定が既定値に重ねて反映され、保存した設定で置き換わることを確認する。
+    setActivePinia(createPinia())
+    const store = useAppStore()
+
+    apiClient.getAppBootstrap.mockResolvedValue({
+      settings: { default_sort_by: 'priority', default_author_name: 'alice' }
+    })
+    await store.bootstrap()
+
+    expect(store.settings.default_sort_by).toBe('priority')
+    expect(store.settings.default_author_name).toBe('alice')
+    expect(store.settings.confirm_delete_category).toBe(true)
+
+    apiClient.saveSettings.mockImplementation(async (settings) => settings)
+    const saved = await store.saveSettings({ confirm_delete_category: false })
+
+    expect(saved).toBe(true)
+    expect(apiClient.saveSettings).toHaveBeenCalledWith(
+      expect.objectContaining({ default_sort_by: 'priority', confirm_delete_category: false })
+    )
+    expect(store.settings.confirm_delete_category).toBe(false)
+  })
+
+  it('opens a recent project root and moves it to the front', async () => {
+    // 最近開いたプロジェクトへ切り替え、一覧の先頭へ移すことを確認する。
+    setActivePinia(createPinia())
+    const store = useAppStore()
+    store.recentProjectRoots = ['C:/a', 'C:/b']
+
+    apiClient.openProjectRoot.mockResolvedValue({ root: 'C:/b', warnings: [] })
+
+    await store.openRecentProjectRoot('C:/b')
+
+    expect(store.projectRoot).toBe('C:/b')
+    expect(store.recentProjectRoots).toEqual(['C:/b', 'C:/a'])
+  })
+
+  it('uses the page size of the opened project', async () => {
+    // 開いたプロジェクトの設定による表示件数に切り替えることを確認する。
+    setActivePinia(createPinia())
+    const store = useAppStore()
+
+    apiClient.openProjectRoot.mockResolvedValue({ root: 'C:/b', warnings: [], ui_page_size: 50 })
+
+    await store.openRecentProjectRoot('C:/b')
+
+    expect(store.pageSize).toBe(50)
+  })
+
+  it('keeps the verified contractor user name', async () => {
+    // users.json のアカウントで認証した場合にアカウント名を保持することを確認する。
+    setActivePinia(createPinia())
+    const store = useAppStore()
+    store.contractorUsernameRequired = true
+
+    apiClient.verifyContractorPassword.mockResolvedValue({
+      mode: 'Contractor',
+      requires_password: false,
+      username: 'alice'
+    })
+
+    await store.verifyContractorPassword('alice', 'secret')
+
+    expect(apiClient.verifyContractorPassword).toHaveBeenCalledWith('alice', 'secret', '', false)
+    expect(store.mode).toBe('Contractor')
+    expect(store.contractorUser).toBe('alice')
+  })
+
+  it('starts in observer mode and enters it on request', async () => {
+    // --observer で起動した場合は Observer となり、切り替え時は Contractor 認証の要求を解除することを確認する。
+    setActivePinia(createPinia())
+    const store = useAppStore()
+
+    apiClient.getAppBootstrap.mockResolvedValue({ mode: 'Observer', has_contractor_auth_file: false })
+    await store.bootstrap()
+    expect(store.mode).toBe('Observer')
+
+    store.mode = 'Vendor'
+    store.contractorAuthRequired = true
+    apiClient.enterObserverMode.mockResolvedValue({ mode: 'Observer', requires_password: false })
+    await store.enterObserverMode()
+
+    expect(store.mode).toBe('Observer')
+    expect(store.contractorAuthRequired).toBe(false)
+  })
+
+  it('requires re-authentication after contractor mode is locked', async () => {
+    // 解除の通知や LockMode の後は Vendor へ戻り、Contractor 認証が再度必要になることを確認する。
+    setActivePinia(createPinia())
+    const store = useAppStore()
+    store.mode = 'Contractor'
+    store.contractorUser = 'alice'
+    store.contractorAuthRequired = false
+
+    store.applyModeLocked({ reason: 'idle', mode: { mode: 'Vendor', requires_password: true, requires_username: true } })
+
+    expect(store.mode).toBe('Vendor')
+    expect(store.contractorUser).toBe('')
+    expect(store.contractorAuthRequired).toBe(true)
+    expect(store.lockReason).toBe('idle')
+
+    store.mode = 'Contractor'
+    store.contractorAuthRequired = false
+    apiClient.lockMode.mockResolvedValue({ mode: 'Vendor', requires_password: true })
+    await store.lockMode()
+
+    expect(store.mode).toBe('Vendor')
+    expect(store.contractorAuthRequired).toBe(true)
+    expect(store.lockReason).toBe('manual')
+  })
+
+  it('applies settings reloaded from config.json', () => {
+    // config:changed の通知で表示件数とアプリ設定が更新され、欠けた項目は既定値で補うことを確認する。
+    setActivePinia(createPinia())
+    const store = useAppStore()
+
+    store.applyConfigChanged({ log_level: 'debug', ui_page_size: 50, settings: { language: 'en' } })
+
+    expect(store.pageSize).toBe(50)
+    expect(store.settings.language).toBe('en')
+    expect(store.settings.date_format).toBe('YYYY-MM-DD')
+  })
+
   it('captures errors on bootstrap failure', async () => {
     // 取得失敗時に errors ストアへ登録されることを確認する。
     setActivePinia(createPinia())
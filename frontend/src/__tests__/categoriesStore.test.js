@@ -1,6 +1,7 @@
 import { createPinia, setActivePinia } from 'pinia'
 import { describe, expect, it, vi } from 'vitest'
 
+import { useAppStore } from '../stores/app'
 import { useCategoriesStore } from '../stores/categories'
 import { useErrorsStore } from '../stores/errors'
 
@@ -8,7 +9,10 @@ vi.mock('../utils/apiClient', () => ({
   listCategories: vi.fn(),
   createCategory: vi.fn(),
   renameCategory: vi.fn(),
-  deleteCategory: vi.fn()
+  deleteCategory: vi.fn(),
+  listRenameResidues: vi.fn(),
+  completeCategoryRename: vi.fn(),
+  rollbackCategoryRename: vi.fn()
 }))
 
 import * as apiClient from '../utils/apiClient'
@@ -36,4 +40,25 @@ describe('categories store', () => {
 
     expect(errors.items.length).toBe(1)
   })
+
+  it('rolls back an interrupted rename and follows the original name', async () => {
+    // 取り消しで選択中のカテゴリが変更前の名前へ移り、一覧を読み直すことを確認する。
+    setActivePinia(createPinia())
+    const store = useCategoriesStore()
+    useAppStore().mode = 'Contractor'
+    store.selectedCategory = 'new'
+
+    apiClient.listRenameResidues.mockResolvedValue([{ name: 'new', original_name: 'old', can_rollback: true }])
+    apiClient.rollbackCategoryRename.mockResolvedValue({ name: 'old' })
+    apiClient.listCategories.mockResolvedValue({ categories: [{ name: 'old' }] })
+
+    const residue = await store.loadRenameResidue('new')
+    const result = await store.recoverRename('new', true)
+
+    expect(residue.original_name).toBe('old')
+    expect(result.name).toBe('old')
+    expect(apiClient.rollbackCategoryRename).toHaveBeenCalledWith('new')
+    expect(store.selectedCategory).toBe('old')
+    expect(store.items[0].name).toBe('old')
+  })
 })
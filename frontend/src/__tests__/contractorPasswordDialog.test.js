@@ -60,4 +60,121 @@ describe('ContractorPasswordDialog', () => {
 
     expect(wrapper.emitted().verified).toBeTruthy()
   })
+
+  it('sends the user name when accounts are configured', async () => {
+    // users.json で認証する場合はユーザー名を入力でき、パスワードと共に検証へ渡すことを確認する。
+    setActivePinia(createPinia())
+    const app = useAppStore()
+    app.contractorUsernameRequired = true
+    app.verifyContractorPassword = vi.fn().mockResolvedValue({ mode: 'Contractor', username: 'alice' })
+
+    const wrapper = mount(ContractorPasswordDialog, {
+      global: {
+        plugins: [vuetify],
+        stubs: {
+          teleport: true,
+          VDialog: { template: '<div><slot /></div>' }
+        }
+      }
+    })
+
+    await wrapper.find('[data-testid="username"] input').setValue(' alice ')
+    await wrapper.find('[data-testid="verify"]').trigger('click')
+    await wrapper.vm.$nextTick()
+
+    expect(app.verifyContractorPassword).toHaveBeenCalledWith('alice', '', '', false)
+    expect(wrapper.emitted().verified).toBeTruthy()
+  })
+
+  it('asks to remember the unlock on this machine', async () => {
+    // 記憶できる端末では「この端末で記憶する」を選べ、その指定を検証へ渡すことを確認する。
+    setActivePinia(createPinia())
+    const app = useAppStore()
+    app.contractorRememberSupported = true
+    app.verifyContractorPassword = vi.fn().mockResolvedValue({ mode: 'Contractor' })
+
+    const wrapper = mount(ContractorPasswordDialog, {
+      global: {
+        plugins: [vuetify],
+        stubs: {
+          teleport: true,
+          VDialog: { template: '<div><slot /></div>' }
+        }
+      }
+    })
+
+    await wrapper.find('[data-testid="remember"] input').setValue(true)
+    await wrapper.find('[data-testid="verify"]').trigger('click')
+    await wrapper.vm.$nextTick()
+
+    expect(app.verifyContractorPassword).toHaveBeenCalledWith('', '', '', true)
+  })
+
+  it('sends the one-time code when TOTP is configured', async () => {
+    // TOTP を設定している場合はワンタイムコードを入力でき、前後の空白を除いて検証へ渡すことを確認する。
+    setActivePinia(createPinia())
+    const app = useAppStore()
+    app.contractorTOTPRequired = true
+    app.verifyContractorPassword = vi.fn().mockResolvedValue({ mode: 'Contractor' })
+
+    const wrapper = mount(ContractorPasswordDialog, {
+      global: {
+        plugins: [vuetify],
+        stubs: {
+          teleport: true,
+          VDialog: { template: '<div><slot /></div>' }
+        }
+      }
+    })
+
+    await wrapper.find('[data-testid="totp"] input').setValue(' 123456 ')
+    await wrapper.find('[data-testid="verify"]').trigger('click')
+    await wrapper.vm.$nextTick()
+
+    expect(app.verifyContractorPassword).toHaveBeenCalledWith('', '', '123456', false)
+  })
+
+  it('opens in observer mode without a password', async () => {
+    // 閲覧のみで開く場合はパスワードを検証せず Observer へ切り替えることを確認する。
+    setActivePinia(createPinia())
+    const app = useAppStore()
+    app.verifyContractorPassword = vi.fn()
+    app.enterObserverMode = vi.fn().mockResolvedValue({ mode: 'Observer' })
+
+    const wrapper = mount(ContractorPasswordDialog, {
+      global: {
+        plugins: [vuetify],
+        stubs: {
+          teleport: true,
+          VDialog: { template: '<div><slot /></div>' }
+        }
+      }
+    })
+
+    await wrapper.find('[data-testid="observe"]').trigger('click')
+    await wrapper.vm.$nextTick()
+
+    expect(app.enterObserverMode).toHaveBeenCalled()
+    expect(app.verifyContractorPassword).not.toHaveBeenCalled()
+    expect(wrapper.emitted().verified).toBeTruthy()
+  })
+
+  it('explains re-authentication after an idle lock', async () => {
+    // 無操作で解除された場合は再認証を求める案内を表示することを確認する。
+    setActivePinia(createPinia())
+    const app = useAppStore()
+    app.lockReason = 'idle'
+
+    const wrapper = mount(ContractorPasswordDialog, {
+      global: {
+        plugins: [vuetify],
+        stubs: {
+          teleport: true,
+          VDialog: { template: '<div><slot /></div>' }
+        }
+      }
+    })
+
+    expect(wrapper.find('[data-testid="idle-notice"]').exists()).toBe(true)
+  })
 })
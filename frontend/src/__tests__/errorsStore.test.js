@@ -27,6 +27,22 @@ describe('errors store', () => {
     expect(store.items[0].api.error_code).toBe('E_VALIDATION')
   })
 
+  it('captures warnings as warn items', () => {
+    // 応答に含まれる警告が warn として API 情報付きで登録されることを確認する。
+    setActivePinia(createPinia())
+    const store = useErrorsStore()
+
+    const count = store.captureWarnings(
+      [{ error_code: 'E_TMP_REMAINING', message: 'tmp', target_path: 'a.json.tmp.1.2' }],
+      { source: 'app', action: 'bootstrap' }
+    )
+
+    expect(count).toBe(1)
+    expect(store.items[0].severity).toBe('warn')
+    expect(store.items[0].api.target_path).toBe('a.json.tmp.1.2')
+    expect(store.captureWarnings(undefined)).toBe(0)
+  })
+
   it('marks items as read', () => {
     // 既読操作が反映されることを確認する。
     setActivePinia(createPinia())
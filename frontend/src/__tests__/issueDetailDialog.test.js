@@ -6,6 +6,7 @@ import { describe, expect, it, vi } from 'vitest'
 import { createVuetify } from 'vuetify'
 
 import IssueDetailDialog from '../components/IssueDetailDialog.vue'
+import { useAppStore } from '../stores/app'
 import { useCategoriesStore } from '../stores/categories'
 import { useIssueDetailStore } from '../stores/issueDetail'
 
@@ -119,6 +120,18 @@ describe('IssueDetailDialog', () => {
     expect(wrapper.emitted()['open-errors']).toBeTruthy()
   })
 
+  it('blocks editing and commenting in observer mode', async () => {
+    // Observer モードでは閲覧のみの案内を表示し、編集とコメントが抑止されることを確認する。
+    setupStores()
+    useAppStore().mode = 'Observer'
+    const wrapper = mountDialog()
+    await wrapper.vm.$nextTick()
+
+    expect(wrapper.find('[data-testid="observer-notice"]').exists()).toBe(true)
+    expect(wrapper.find('[data-testid="edit"]').attributes('disabled')).toBeDefined()
+    expect(wrapper.find('[data-testid="comment-submit"]').attributes('disabled')).toBeDefined()
+  })
+
   it('reloads detail when dialog opens', async () => {
     // ダイアログ表示時に詳細の再読み込みが行われることを確認する。
     const { issueDetail } = setupStores()
@@ -39,4 +39,24 @@ describe('issue detail store', () => {
 
     expect(store.current.issue_id).toBe('1')
   })
+
+  it('sends the loaded revision and keeps an external change conflict', async () => {
+    // 読み込んだ時点の revision を添えて保存し、外部で変更されていた場合は両方の内容を conflict に保持することを確認する。
+    setActivePinia(createPinia())
+    const store = useIssueDetailStore()
+    const errors = useErrorsStore()
+    const conflict = { current: { issue_id: '1', title: 'theirs', revision: 'r2' }, attempted: { issue_id: '1', title: 'mine' } }
+    const error = new apiClient.ApiError('conflict')
+    error.conflict = conflict
+
+    store.current = { issue_id: '1', category: 'Cat', revision: 'r1' }
+    store.currentCategory = 'Cat'
+    apiClient.updateIssue.mockRejectedValue(error)
+
+    await store.saveIssue({ title: 'mine' })
+
+    expect(apiClient.updateIssue).toHaveBeenCalledWith('Cat', '1', { title: 'mine', expected_revision: 'r1' })
+    expect(store.conflict).toEqual(conflict)
+    expect(errors.items.length).toBe(1)
+  })
 })
@@ -11,8 +11,13 @@ vi.mock('../utils/apiClient', () => ({
   getAppBootstrap: vi.fn(),
   validateProjectRoot: vi.fn(),
   saveLastProjectRoot: vi.fn(),
+  saveSettings: vi.fn(),
+  openProjectRoot: vi.fn(),
   createProjectRoot: vi.fn(),
   detectMode: vi.fn(),
+  enterObserverMode: vi.fn(),
+  lockMode: vi.fn(),
+  unlockRememberedContractor: vi.fn(),
   verifyContractorPassword: vi.fn()
 }))
 
@@ -3,11 +3,16 @@ import { createPinia } from 'pinia'
 import { createVuetify } from 'vuetify'
 import 'vuetify/styles'
 import App from './App.vue'
+import { useErrorsStore } from './stores/errors'
+import { onResponseWarnings } from './utils/apiClient'
 import './style.css'
 import '@mdi/font/css/materialdesignicons.css'
 
 const app = createApp(App)
 app.use(createPinia())
+onResponseWarnings((warnings, action) => {
+    useErrorsStore().captureWarnings(warnings, { source: 'backend', action })
+})
 app.use(createVuetify({
     theme: {
         defaultTheme: 'dark',
@@ -5,13 +5,30 @@ import { defineStore } from 'pinia'
 import {
   createProjectRoot,
   detectMode,
+  enterObserverMode,
   getAppBootstrap,
+  lockMode,
+  openProjectRoot,
   saveLastProjectRoot,
+  saveSettings,
+  unlockRememberedContractor,
   validateProjectRoot,
   verifyContractorPassword
 } from '../utils/apiClient'
 import { useErrorsStore } from './errors'
 
+// DEFAULT_SETTINGS は DD-CONF-005 のアプリ設定の既定値を表し、起動時情報を取得するまで用いる。
+const DEFAULT_SETTINGS = {
+  default_sort_by: 'updated_at',
+  default_sort_order: 'desc',
+  default_author_name: '',
+  date_format: 'YYYY-MM-DD',
+  language: 'ja',
+  time_zone: '',
+  confirm_delete_category: true,
+  confirm_delete_category_with_issues: true
+}
+
 // useAppStore は DD-STORE-005/012 のアプリ共通ストアを提供する。
 // 目的: モード・プロジェクトルート・起動状態を管理する。
 // 入力: Pinia の内部状態。
@@ -19,16 +36,23 @@ import { useErrorsStore } from './errors'
 // エラー: なし。
 // 副作用: なし。
 // 並行性: Pinia の更新に従う。
-// 不変条件: mode は "Vendor"/"Contractor" のいずれか。
+// 不変条件: mode は "Vendor"/"Contractor"/"Observer" のいずれか。
 // 関連DD: DD-STORE-005, DD-STORE-012
 export const useAppStore = defineStore('app', {
   state: () => ({
     mode: 'Vendor',
     projectRoot: null,
     lastProjectRootPath: null,
+    recentProjectRoots: [],
     pageSize: 20,
+    settings: { ...DEFAULT_SETTINGS },
     bootstrapLoaded: false,
     contractorAuthRequired: false,
+    contractorUsernameRequired: false,
+    contractorRememberSupported: false,
+    contractorTOTPRequired: false,
+    contractorUser: '',
+    lockReason: null,
     isBusy: false
   }),
   actions: {
@@ -39,7 +63,7 @@ export const useAppStore = defineStore('app', {
     // エラー: 取得失敗時は errors ストアに登録する。
     // 副作用: バックエンド呼び出しを行う。
     // 並行性: 同時実行は想定しない。
-    // 不変条件: 成功時に bootstrapLoaded を true にする。
+    // 不変条件: 成功時に bootstrapLoaded を true にする。startup_project_root がある場合は projectRoot に設定する。
     // 関連DD: DD-STORE-012
     async bootstrap() {
       const errors = useErrorsStore()
@@ -47,8 +71,25 @@ export const useAppStore = defineStore('app', {
       try {
         const data = await getAppBootstrap()
         this.pageSize = data.ui_page_size ?? this.pageSize
+        this.settings = { ...DEFAULT_SETTINGS, ...(data.settings ?? {}) }
+        // 起動引数 --observer で起動した場合は Observer モードになる。
+        this.mode = data.mode ?? this.mode
         this.lastProjectRootPath = data.last_project_root_path ?? null
+        // 起動引数 --root で開いたプロジェクトは選択ダイアログを経ずにそのまま使う。
+        if (data.startup_project_root) {
+          this.projectRoot = data.startup_project_root
+          this.lastProjectRootPath = data.startup_project_root
+        }
+        this.recentProjectRoots = data.recent_project_roots ?? []
         this.contractorAuthRequired = data.has_contractor_auth_file ?? false
+        this.contractorUsernameRequired = data.contractor_username_required ?? false
+        this.contractorRememberSupported = data.contractor_remember_supported ?? false
+        this.contractorTOTPRequired = data.contractor_totp_required ?? false
+        errors.captureWarnings(data.warnings, { source: 'app', action: 'bootstrap' })
+        // 信頼した端末で認証を記憶している場合は、パスワード入力を省いて Contractor モードで始める。
+        if (this.contractorAuthRequired && this.mode === 'Vendor') {
+          await this.unlockRememberedContractor()
+        }
         this.bootstrapLoaded = true
       } catch (e) {
         errors.capture(e, { source: 'app', action: 'bootstrap' })
@@ -56,6 +97,34 @@ export const useAppStore = defineStore('app', {
         this.isBusy = false
       }
     },
+    // saveSettings はアプリ設定を保存して状態へ反映する。
+    // 目的: 利用者が変更した設定を config.json に残し、入力し直さなくて済むようにする。
+    // 入力: changes は変更する設定項目。
+    // 出力: 成功時は true、失敗時は false。
+    // エラー: 失敗時は errors ストアに登録する。
+    // 副作用: バックエンド呼び出しを行う。
+    // 並行性: 同時実行は想定しない。
+    // 不変条件: 保存に失敗した場合は settings を変更しない。
+    // 関連DD: DD-CONF-005, DD-STORE-012
+    async saveSettings(changes) {
+      const errors = useErrorsStore()
+      try {
+        const saved = await saveSettings({ ...this.settings, ...changes })
+        this.settings = { ...DEFAULT_SETTINGS, ...saved }
+        return true
+      } catch (e) {
+        errors.capture(e, { source: 'app', action: 'saveSettings' })
+        return false
+      }
+    },
+    // applyConfigChanged は DD-CONF-007 の config:changed の通知を状態へ反映する。
+    // 表示中の一覧は MainView が pageSize の変更を監視して読み直す。
+    applyConfigChanged(payload) {
+      this.pageSize = payload?.ui_page_size || this.pageSize
+      if (payload?.settings) {
+        this.settings = { ...DEFAULT_SETTINGS, ...payload.settings }
+      }
+    },
     // selectProjectRoot は既存パスを検証し、設定を保存する。
     // 目的: 選択したプロジェクトルートを確定する。
     // 入力: path は選択パス。
@@ -71,7 +140,10 @@ export const useAppStore = defineStore('app', {
       try {
         const result = await validateProjectRoot(path)
         if (result.is_valid) {
-          await saveLastProjectRoot(result.normalized_path ?? path)
+          const opened = await saveLastProjectRoot(result.normalized_path ?? path)
+          errors.captureWarnings(opened?.warnings, { source: 'app', action: 'selectProjectRoot' })
+          // プロジェクト単位の設定 (DD-PROJCONF-001) があれば表示件数を切り替える。
+          this.pageSize = opened?.ui_page_size ?? this.pageSize
           this.projectRoot = result.normalized_path ?? path
           this.lastProjectRootPath = this.projectRoot
         }
@@ -83,6 +155,36 @@ export const useAppStore = defineStore('app', {
         this.isBusy = false
       }
     },
+    // openRecentProjectRoot は最近開いたプロジェクトへ切り替える。
+    // 目的: 最近開いたプロジェクトを一覧から選んで開き直す。
+    // 入力: path は対象パス。
+    // 出力: ProjectOpenDTO。
+    // エラー: 失敗時は errors ストアに登録する。
+    // 副作用: バックエンド呼び出しを行う。
+    // 並行性: 同時実行は想定しない。
+    // 不変条件: 成功時に projectRoot を更新し、recentProjectRoots の先頭へ移す。
+    // 関連DD: DD-STORE-012
+    async openRecentProjectRoot(path) {
+      const errors = useErrorsStore()
+      this.isBusy = true
+      try {
+        const opened = await openProjectRoot(path)
+        errors.captureWarnings(opened.warnings, { source: 'app', action: 'openRecentProjectRoot' })
+        this.pageSize = opened.ui_page_size ?? this.pageSize
+        this.projectRoot = opened.root
+        this.lastProjectRootPath = opened.root
+        this.recentProjectRoots = [
+          opened.root,
+          ...this.recentProjectRoots.filter((root) => root !== opened.root && root !== path)
+        ]
+        return opened
+      } catch (e) {
+        errors.capture(e, { source: 'app', action: 'openRecentProjectRoot' })
+        return null
+      } finally {
+        this.isBusy = false
+      }
+    },
     // createProjectRoot は新規作成後に設定を保存する。
     // 目的: 新規プロジェクトルートを作成して選択状態にする。
     // 入力: path は作成パス。
@@ -98,7 +200,10 @@ export const useAppStore = defineStore('app', {
       try {
         const result = await createProjectRoot(path)
         if (result.is_valid) {
-          await saveLastProjectRoot(result.normalized_path ?? path)
+          const opened = await saveLastProjectRoot(result.normalized_path ?? path)
+          errors.captureWarnings(opened?.warnings, { source: 'app', action: 'createProjectRoot' })
+          // プロジェクト単位の設定 (DD-PROJCONF-001) があれば表示件数を切り替える。
+          this.pageSize = opened?.ui_page_size ?? this.pageSize
           this.projectRoot = result.normalized_path ?? path
           this.lastProjectRootPath = this.projectRoot
         }
@@ -117,7 +222,7 @@ export const useAppStore = defineStore('app', {
     // エラー: 失敗時は errors ストアに登録する。
     // 副作用: バックエンド呼び出しを行う。
     // 並行性: 同時実行は想定しない。
-    // 不変条件: contractorAuthRequired と mode を更新する。
+    // 不変条件: contractorAuthRequired・contractorUsernameRequired と mode を更新する。
     // 関連DD: DD-STORE-012
     async detectMode() {
       const errors = useErrorsStore()
@@ -126,6 +231,8 @@ export const useAppStore = defineStore('app', {
         const result = await detectMode()
         this.mode = result.mode
         this.contractorAuthRequired = result.requires_password ?? false
+        this.contractorUsernameRequired = result.requires_username ?? false
+        this.contractorTOTPRequired = result.requires_totp ?? false
         return result
       } catch (e) {
         errors.capture(e, { source: 'app', action: 'detectMode' })
@@ -134,21 +241,98 @@ export const useAppStore = defineStore('app', {
         this.isBusy = false
       }
     },
+    // enterObserverMode は閲覧のみの Observer モードへ切り替える。
+    // 目的: パスワード入力の代わりに、変更操作を受け付けない閲覧用途で開けるようにする。
+    // 入力: なし。
+    // 出力: ModeDTO。失敗時は null。
+    // エラー: 失敗時は errors ストアに登録する。
+    // 副作用: バックエンド呼び出しを行う。
+    // 並行性: 同時実行は想定しない。
+    // 不変条件: 成功時に mode を Observer とし、Contractor 認証の要求を解除する。
+    // 関連DD: DD-STORE-012, DD-BE-003
+    async enterObserverMode() {
+      const errors = useErrorsStore()
+      this.isBusy = true
+      try {
+        const result = await enterObserverMode()
+        this.mode = result.mode
+        this.contractorAuthRequired = false
+        this.contractorUser = ''
+        return result
+      } catch (e) {
+        errors.capture(e, { source: 'app', action: 'enterObserverMode' })
+        return null
+      } finally {
+        this.isBusy = false
+      }
+    },
+    // lockMode は Contractor モードを解除して Vendor モードへ戻す。
+    // 目的: 席を離れる際などに Contractor の権限を手放す。
+    // 入力: なし。
+    // 出力: ModeDTO。失敗時は null。
+    // エラー: 失敗時は errors ストアに登録する。
+    // 副作用: バックエンド呼び出しを行う。
+    // 並行性: 同時実行は想定しない。
+    // 不変条件: 状態の更新は mode:locked の通知と同じく applyModeLocked で行う。
+    // 関連DD: DD-STORE-012, DD-MODE-001
+    async lockMode() {
+      const errors = useErrorsStore()
+      try {
+        const result = await lockMode()
+        this.applyModeLocked({ reason: 'manual', mode: result })
+        return result
+      } catch (e) {
+        errors.capture(e, { source: 'app', action: 'lockMode' })
+        return null
+      }
+    },
+    // applyModeLocked は DD-MODE-001 の mode:locked の通知を状態へ反映する。
+    // Contractor 認証が必要な状態へ戻すことで、パスワード入力ダイアログを再表示させる。
+    applyModeLocked(payload) {
+      const mode = payload?.mode ?? {}
+      this.mode = mode.mode ?? 'Vendor'
+      this.contractorUser = ''
+      this.contractorAuthRequired = mode.requires_password ?? true
+      this.contractorUsernameRequired = mode.requires_username ?? this.contractorUsernameRequired
+      this.contractorTOTPRequired = mode.requires_totp ?? this.contractorTOTPRequired
+      this.lockReason = payload?.reason ?? null
+    },
+    // unlockRememberedContractor は DD-MODE-003 の記憶した認証で Contractor モードへ切り替える。
+    // 記憶していない場合は何も変えず、パスワード入力ダイアログをそのまま表示させる。
+    async unlockRememberedContractor() {
+      const errors = useErrorsStore()
+      try {
+        const result = await unlockRememberedContractor()
+        if (result?.mode !== 'Contractor') {
+          return null
+        }
+        this.mode = result.mode
+        this.contractorUser = result.username ?? ''
+        this.contractorAuthRequired = false
+        return result
+      } catch (e) {
+        errors.capture(e, { source: 'app', action: 'unlockRememberedContractor' })
+        return null
+      }
+    },
     // verifyContractorPassword は Contractor パスワードを検証する。
     // 目的: Contractor モードへの移行を確定する。
-    // 入力: password は入力パスワード。
+    // 入力: username は users.json のアカウント名 (共有パスワードの場合は空)、password は入力パスワード、
+    // code は TOTP のワンタイムコード (DD-CLI-008)、remember はこの端末で認証を記憶するか (DD-MODE-003)。
     // 出力: ModeDTO。
     // エラー: 失敗時は errors ストアに登録する。
     // 副作用: バックエンド呼び出しを行う。
     // 並行性: 同時実行は想定しない。
-    // 不変条件: 成功時に mode を更新する。
-    // 関連DD: DD-STORE-012
-    async verifyContractorPassword(password) {
+    // 不変条件: 成功時に mode と、コメントの既定の作成者名に用いる contractorUser を更新する。
+    // 関連DD: DD-STORE-012, DD-CLI-007, DD-CLI-008, DD-MODE-003
+    async verifyContractorPassword(username, password, code = '', remember = false) {
       const errors = useErrorsStore()
       this.isBusy = true
       try {
-        const result = await verifyContractorPassword(password)
+        const result = await verifyContractorPassword(username, password, code, remember)
         this.mode = result.mode
+        this.contractorUser = result.username ?? ''
+        this.lockReason = null
         this.contractorAuthRequired = result.requires_password ?? false
         return result
       } catch (e) {
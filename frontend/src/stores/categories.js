@@ -2,7 +2,15 @@
 // 読み込み結果の整形はバックエンドDTOに従う。
 import { defineStore } from 'pinia'
 
-import { createCategory, deleteCategory, listCategories, renameCategory } from '../utils/apiClient'
+import {
+  completeCategoryRename,
+  createCategory,
+  deleteCategory,
+  listCategories,
+  listRenameResidues,
+  renameCategory,
+  rollbackCategoryRename
+} from '../utils/apiClient'
 import { useAppStore } from './app'
 import { useErrorsStore } from './errors'
 import { useIssuesStore } from './issues'
@@ -128,6 +136,63 @@ export const useCategoriesStore = defineStore('categories', {
         return null
       }
     },
+    // loadRenameResidue は読み取り専用カテゴリの中断されたカテゴリ名変更の状態を取得する。
+    // 目的: 完了・取り消しのどちらで復旧できるかを復旧ダイアログに示す。
+    // 入力: name は読み取り専用カテゴリ名。
+    // 出力: RenameResidueDTO。該当が無い・失敗時は null。
+    // エラー: 失敗時は errors ストアに登録する。
+    // 副作用: バックエンド呼び出しを行う。
+    // 並行性: 同時実行は想定しない。
+    // 不変条件: state は変更しない。
+    // 関連DD: DD-BE-003, DD-STORE-013
+    async loadRenameResidue(name) {
+      const errors = useErrorsStore()
+      try {
+        const residues = await listRenameResidues()
+        return (residues ?? []).find((residue) => residue.name === name) ?? null
+      } catch (e) {
+        errors.capture(e, { source: 'categories', action: 'loadRenameResidue', category: name })
+        return null
+      }
+    },
+    // recoverRename は中断されたカテゴリ名変更を完了または取り消し、選択状態とキャッシュを更新する。
+    // 目的: Contractor 操作で読み取り専用カテゴリを復旧する。
+    // 入力: name は読み取り専用カテゴリ名、rollback は変更前の名前へ戻す場合に true。
+    // 出力: 復旧後の CategoryDTO。失敗時は null。
+    // エラー: 失敗時は errors ストアに登録する。
+    // 副作用: バックエンド呼び出しと issues キャッシュの更新を行う。
+    // 並行性: 同時実行は想定しない。
+    // 不変条件: 取り消した場合、選択中であれば selectedCategory を変更前の名前へ更新する。
+    // 関連DD: DD-BE-003, DD-STORE-013
+    async recoverRename(name, rollback) {
+      const errors = useErrorsStore()
+      const app = useAppStore()
+      const action = rollback ? 'rollbackCategoryRename' : 'completeCategoryRename'
+      if (app.mode !== 'Contractor') {
+        errors.captureApiError(
+          new PermissionError('Vendor cannot recover category rename'),
+          { source: 'categories', action }
+        )
+        return null
+      }
+      try {
+        const data = rollback ? await rollbackCategoryRename(name) : await completeCategoryRename(name)
+        const issues = useIssuesStore()
+        if (data.name === name) {
+          issues.invalidateCategory(name)
+        } else {
+          issues.renameCategoryKey(name, data.name)
+        }
+        if (this.selectedCategory === name) {
+          this.selectedCategory = data.name
+        }
+        await this.loadCategories()
+        return data
+      } catch (e) {
+        errors.capture(e, { source: 'categories', action, category: name })
+        return null
+      }
+    },
     // deleteCategory はカテゴリを削除して一覧を更新する。
     // 目的: Contractor 操作でカテゴリを削除する。
     // 入力: name はカテゴリ名。
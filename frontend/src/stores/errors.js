@@ -91,6 +91,33 @@ export const useErrorsStore = defineStore('errors', {
       })
       return list.length
     },
+    // captureWarnings はバックエンドが応答に含めた警告 (APIErrorDTO) をまとめて追加する。
+    // 目的: プロジェクトを開いた際の一時ファイル残骸などの警告をエラー一覧に表示する。
+    // 入力: list は APIErrorDTO 配列、ctx は付帯情報。
+    // 出力: 追加した件数。
+    // エラー: なし。
+    // 副作用: items を更新する。
+    // 並行性: Pinia の更新に従う。
+    // 不変条件: 警告は操作の失敗ではないため severity は warn とする。
+    // 関連DD: DD-STORE-011, DD-PERSIST-004
+    captureWarnings(list, ctx = {}) {
+      if (!Array.isArray(list)) {
+        return 0
+      }
+      list.forEach((api) => {
+        this.items.unshift(
+          buildErrorItem({
+            source: ctx.source ?? 'backend',
+            action: ctx.action ?? 'unknown',
+            severity: 'warn',
+            user_message: api.message ?? 'backend warning',
+            api,
+            raw: api
+          })
+        )
+      })
+      return list.length
+    },
     // markRead は指定IDのエラーを既読にする。
     // 目的: 読み取り状態を更新する。
     // 入力: id は対象ID。
@@ -187,6 +214,7 @@ function severityFromCode(code) {
     case 'E_CONFLICT':
       return 'warn'
     case 'E_PERMISSION':
+    case 'E_CANCELED':
       return 'info'
     default:
       return 'error'
@@ -21,7 +21,8 @@ export const useIssueDetailStore = defineStore('issueDetail', {
     currentCategory: null,
     isLoading: false,
     isDirty: false,
-    lastLoadedAt: null
+    lastLoadedAt: null,
+    conflict: null
   }),
   actions: {
     // openIssue は課題詳細を読み込む。
@@ -42,6 +43,7 @@ export const useIssueDetailStore = defineStore('issueDetail', {
         this.currentCategory = category
         this.isDirty = false
         this.lastLoadedAt = new Date().toISOString()
+        this.conflict = null
         return data
       } catch (e) {
         errors.capture(e, { source: 'issueDetail', action: 'openIssue', category, issue_id: issueId })
@@ -69,11 +71,11 @@ export const useIssueDetailStore = defineStore('issueDetail', {
     // 目的: 課題の更新結果を反映する。
     // 入力: update は IssueUpdateDTO。
     // 出力: IssueDetailDTO。
-    // エラー: 失敗時は errors ストアに登録する。
+    // エラー: 失敗時は errors ストアに登録する。外部で変更されていた場合は conflict に両方の内容を保持する。
     // 副作用: バックエンド呼び出しと issues キャッシュ更新を行う。
     // 並行性: 同時実行は想定しない。
-    // 不変条件: schema invalid の課題は更新しない。
-    // 関連DD: DD-STORE-015
+    // 不変条件: schema invalid の課題は更新しない。読み込んだ時点の revision を添えて保存する。
+    // 関連DD: DD-STORE-015, DD-PERSIST-006
     async saveIssue(update) {
       const errors = useErrorsStore()
       if (!this.current || !this.currentCategory) {
@@ -88,14 +90,19 @@ export const useIssueDetailStore = defineStore('issueDetail', {
       }
       this.isLoading = true
       try {
-        const data = await updateIssue(this.currentCategory, this.current.issue_id, update)
+        const data = await updateIssue(this.currentCategory, this.current.issue_id, {
+          ...update,
+          expected_revision: this.current.revision ?? ''
+        })
         this.current = data
         this.isDirty = false
         this.lastLoadedAt = new Date().toISOString()
+        this.conflict = null
         const issues = useIssuesStore()
         issues.applyIssueUpdatedToCache(data)
         return data
       } catch (e) {
+        this.conflict = e?.conflict ?? null
         errors.capture(e, { source: 'issueDetail', action: 'saveIssue', category: this.currentCategory, issue_id: this.current.issue_id })
         return null
       } finally {
@@ -106,11 +113,11 @@ export const useIssueDetailStore = defineStore('issueDetail', {
     // 目的: コメント追加結果を反映する。
     // 入力: payload は CommentCreateDTO。
     // 出力: IssueDetailDTO。
-    // エラー: 失敗時は errors ストアに登録する。
+    // エラー: 失敗時は errors ストアに登録する。外部で変更されていた場合は conflict に両方の内容を保持する。
     // 副作用: バックエンド呼び出しと issues キャッシュ更新を行う。
     // 並行性: 同時実行は想定しない。
-    // 不変条件: current が null の場合は何もしない。
-    // 関連DD: DD-STORE-015
+    // 不変条件: current が null の場合は何もしない。読み込んだ時点の revision を添えて追加する。
+    // 関連DD: DD-STORE-015, DD-PERSIST-006
     async addComment(payload) {
       const errors = useErrorsStore()
       if (!this.current || !this.currentCategory) {
@@ -118,13 +125,18 @@ export const useIssueDetailStore = defineStore('issueDetail', {
       }
       this.isLoading = true
       try {
-        const data = await addComment(this.currentCategory, this.current.issue_id, payload)
+        const data = await addComment(this.currentCategory, this.current.issue_id, {
+          ...payload,
+          expected_revision: this.current.revision ?? ''
+        })
         this.current = data
         this.lastLoadedAt = new Date().toISOString()
+        this.conflict = null
         const issues = useIssuesStore()
         issues.applyIssueUpdatedToCache(data)
         return data
       } catch (e) {
+        this.conflict = e?.conflict ?? null
         errors.capture(e, { source: 'comments', action: 'addComment', category: this.currentCategory, issue_id: this.current.issue_id })
         return null
       } finally {
@@ -239,11 +239,17 @@ export const useIssuesStore = defineStore('issues', {
     // エラー: なし。
     // 副作用: queryByCategory を更新する。
     // 並行性: Pinia の更新に従う。
-    // 不変条件: 返却値は defaultQuery から生成される。
-    // 関連DD: DD-STORE-014
+    // 不変条件: 返却値は defaultQuery から生成され、並び替えは DD-CONF-005 の既定の並び替えに従う。
+    // 関連DD: DD-STORE-014, DD-CONF-005
     getQuery(category) {
       if (!this.queryByCategory[category]) {
-        this.queryByCategory[category] = cloneQuery(this.defaultQuery)
+        const app = useAppStore()
+        const query = cloneQuery(this.defaultQuery)
+        query.sort = {
+          key: app.settings.default_sort_by ?? query.sort.key,
+          dir: app.settings.default_sort_order ?? query.sort.dir
+        }
+        this.queryByCategory[category] = query
       }
       return this.queryByCategory[category]
     }
@@ -18,15 +18,33 @@ export class ApiError extends Error {
     this.targetPath = payload?.target_path ?? ''
     this.hint = payload?.hint ?? ''
     this.action = payload?.action ?? ''
+    // conflict は DD-PERSIST-006 の外部で変更された課題の現在の内容 (current) と利用者の変更 (attempted) を表す。
+    this.conflict = payload?.conflict ?? null
   }
 }
 
+// warningHandler は DD-BE-003 の成功レスポンスに含まれる警告の通知先を表す。未登録の場合は何もしない。
+let warningHandler = () => {}
+
+// onResponseWarnings は DD-BE-003 の成功レスポンスの warnings の通知先を登録する。
+// 目的: 操作は成功したが報告すべき問題を、呼び出し元によらずエラー一覧へ集める。
+// 入力: handler は (warnings, action) を受け取る関数。
+// 出力: なし。
+// エラー: なし。
+// 副作用: 以前に登録した通知先を置き換える。
+// 並行性: スレッドセーフ。
+// 不変条件: 警告が無い場合は通知しない。
+// 関連DD: DD-BE-003, DD-STORE-011
+export function onResponseWarnings(handler) {
+  warningHandler = handler ?? (() => {})
+}
+
 // unwrapResponse は DD-BE-003 の ResponseDTO を正規化し、成功時は data を返す。
 // 目的: ok/data/error の分岐を統一し、ストアで扱いやすくする。
 // 入力: response はバックエンドのレスポンス、action は呼び出し名。
 // 出力: response.data。
 // エラー: ok=false またはレスポンス不正時に ApiError を送出する。
-// 副作用: なし。
+// 副作用: 成功時に warnings があれば onResponseWarnings の通知先へ渡す。
 // 並行性: スレッドセーフ。
 // 不変条件: ok=true の場合のみ data を返す。
 // 関連DD: DD-BE-003
@@ -39,6 +57,9 @@ export function unwrapResponse(response, action) {
     payload.action = action
     throw new ApiError(payload.message ?? 'backend error', payload)
   }
+  if (Array.isArray(response.warnings) && response.warnings.length > 0) {
+    warningHandler(response.warnings, action)
+  }
   return response.data
 }
 
@@ -56,6 +77,20 @@ export async function getAppBootstrap() {
   return unwrapResponse(response, 'GetAppBootstrap')
 }
 
+// saveSettings は DD-CONF-005 のアプリ設定を保存する。
+// 目的: 並び順や作成者名などの設定を次回起動時にも引き継ぐ。
+// 入力: settings は SettingsDTO。
+// 出力: 既定値を補った保存後の SettingsDTO。
+// エラー: 値域外の値や保存失敗時に ApiError を送出する。
+// 副作用: バックエンド呼び出しを行う。
+// 並行性: スレッドセーフ。
+// 不変条件: ok=true の場合のみ data を返す。
+// 関連DD: DD-CONF-005
+export async function saveSettings(settings) {
+  const response = await App.SaveSettings(settings)
+  return unwrapResponse(response, 'SaveSettings')
+}
+
 // validateProjectRoot は DD-BE-003 の Project Root 検証を行う。
 // 目的: プロジェクトルートの妥当性を検証する。
 // 入力: path は対象パス。
@@ -70,6 +105,34 @@ export async function validateProjectRoot(path) {
   return unwrapResponse(response, 'ValidateProjectRoot')
 }
 
+// browseForProjectRoot は DD-BE-003 のフォルダ選択によるプロジェクトルート指定を行う。
+// 目的: フォルダ選択ダイアログで選んだプロジェクトルートを検証する。
+// 入力: なし。
+// 出力: ValidationResultDTO。選択が取り消された場合は null。
+// エラー: ダイアログ表示・検証失敗時に ApiError を送出する。
+// 副作用: バックエンド呼び出しを行い、ダイアログを表示する。
+// 並行性: スレッドセーフ。
+// 不変条件: ok=true の場合のみ data を返す。
+// 関連DD: DD-BE-003
+export async function browseForProjectRoot() {
+  const response = await App.BrowseForProjectRoot()
+  return unwrapResponse(response, 'BrowseForProjectRoot')
+}
+
+// openProjectRoot は DD-BE-003 のプロジェクトルート切り替えを行う。
+// 目的: 最近開いたプロジェクトなどへ検証・保存を含めて切り替える。
+// 入力: path は対象パス。
+// 出力: ProjectOpenDTO。
+// エラー: 無効なパスや保存失敗時に ApiError を送出する。
+// 副作用: バックエンド呼び出しを行う。
+// 並行性: スレッドセーフ。
+// 不変条件: ok=true の場合のみ data を返す。
+// 関連DD: DD-BE-003
+export async function openProjectRoot(path) {
+  const response = await App.OpenProjectRoot(path)
+  return unwrapResponse(response, 'OpenProjectRoot')
+}
+
 // createProjectRoot は DD-BE-003 の Project Root 作成を行う。
 // 目的: プロジェクトルートを作成する。
 // 入力: path は作成対象パス。
@@ -85,9 +148,9 @@ export async function createProjectRoot(path) {
 }
 
 // saveLastProjectRoot は DD-BE-003 の設定更新を行う。
-// 目的: 最終プロジェクトルートを保存する。
+// 目的: 最終プロジェクトルートを保存し、プロジェクトを開く。
 // 入力: path は保存対象パス。
-// 出力: なし（data は未使用）。
+// 出力: ProjectOpenDTO（一時ファイル残骸の警告を含む）。
 // エラー: 保存失敗時に ApiError を送出する。
 // 副作用: バックエンド呼び出しを行う。
 // 並行性: スレッドセーフ。
@@ -99,7 +162,7 @@ export async function saveLastProjectRoot(path) {
 }
 
 // detectMode は DD-BE-003 の起動時モード判定を行う。
-// 目的: Vendor/Contractor モードとパスワード要求有無を取得する。
+// 目的: Vendor/Contractor/Observer モードとパスワード要求有無を取得する。
 // 入力: なし。
 // 出力: ModeDTO。
 // エラー: 判定失敗時に ApiError を送出する。
@@ -112,20 +175,63 @@ export async function detectMode() {
   return unwrapResponse(response, 'DetectMode')
 }
 
-// verifyContractorPassword は DD-BE-003/DD-CLI-005 のパスワード検証を行う。
+// enterObserverMode は DD-BE-003 の Observer モードへの切り替えを行う。
+// 目的: 閲覧のみで利用するため、変更操作を受け付けないモードへ切り替える。
+// 入力: なし。
+// 出力: ModeDTO。
+// エラー: 失敗時に ApiError を送出する。
+// 副作用: バックエンド呼び出しを行う。
+// 並行性: スレッドセーフ。
+// 不変条件: ok=true の場合のみ data を返す。
+// 関連DD: DD-BE-003
+export async function enterObserverMode() {
+  const response = await App.EnterObserverMode()
+  return unwrapResponse(response, 'EnterObserverMode')
+}
+
+// lockMode は DD-MODE-001 の Contractor モードの解除を行う。
+// 目的: 無操作時間の上限を待たずに Vendor モードへ戻す。
+// 入力: なし。
+// 出力: 解除後の ModeDTO。
+// エラー: 失敗時に ApiError を送出する。
+// 副作用: バックエンド呼び出しを行う。解除した場合はバックエンドが mode:locked を通知する。
+// 並行性: スレッドセーフ。
+// 不変条件: ok=true の場合のみ data を返す。
+// 関連DD: DD-MODE-001
+export async function lockMode() {
+  const response = await App.LockMode()
+  return unwrapResponse(response, 'LockMode')
+}
+
+// verifyContractorPassword は DD-BE-003/DD-CLI-005/DD-CLI-007 のパスワード検証を行う。
 // 目的: Contractor パスワードの検証結果を取得する。
-// 入力: password は入力パスワード。
+// 入力: username は users.json のアカウント名 (共有パスワードの場合は空)、password は入力パスワード、
+// code は TOTP のワンタイムコード (DD-CLI-008、不要な場合は空)、remember はこの端末で認証を記憶するか (DD-MODE-003)。
 // 出力: ModeDTO。
 // エラー: 検証失敗時に ApiError を送出する。
 // 副作用: バックエンド呼び出しを行う。
 // 並行性: スレッドセーフ。
 // 不変条件: ok=true の場合のみ data を返す。
-// 関連DD: DD-BE-003, DD-CLI-005
-export async function verifyContractorPassword(password) {
-  const response = await App.VerifyContractorPassword(password)
+// 関連DD: DD-BE-003, DD-CLI-005, DD-CLI-007, DD-CLI-008, DD-MODE-003
+export async function verifyContractorPassword(username, password, code = '', remember = false) {
+  const response = await App.VerifyContractorPassword(username, password, code, remember)
   return unwrapResponse(response, 'VerifyContractorPassword')
 }
 
+// unlockRememberedContractor は DD-MODE-003 の記憶した認証での Contractor モードへの切り替えを行う。
+// 目的: 信頼した端末での起動時にパスワード入力を省く。
+// 入力: なし。
+// 出力: 切り替え後、または切り替えなかった場合は現在の ModeDTO。
+// エラー: 失敗時に ApiError を送出する。
+// 副作用: バックエンド呼び出しを行う。
+// 並行性: スレッドセーフ。
+// 不変条件: ok=true の場合のみ data を返す。
+// 関連DD: DD-MODE-003
+export async function unlockRememberedContractor() {
+  const response = await App.UnlockRememberedContractor()
+  return unwrapResponse(response, 'UnlockRememberedContractor')
+}
+
 // listCategories は DD-BE-003 のカテゴリ一覧取得を行う。
 // 目的: カテゴリ一覧を取得する。
 // 入力: なし。
@@ -168,6 +274,48 @@ export async function renameCategory(oldName, newName) {
   return unwrapResponse(response, 'RenameCategory')
 }
 
+// listRenameResidues は DD-BE-003 の中断されたカテゴリ名変更の残骸を取得する。
+// 目的: 読み取り専用カテゴリを完了・取り消しのどちらで復旧できるかを確認する。
+// 入力: なし。
+// 出力: RenameResidueDTO の配列。
+// エラー: 取得失敗時に ApiError を送出する。
+// 副作用: バックエンド呼び出しを行う。
+// 並行性: スレッドセーフ。
+// 不変条件: ok=true の場合のみ data を返す。
+// 関連DD: DD-BE-003
+export async function listRenameResidues() {
+  const response = await App.ListRenameResidues()
+  return unwrapResponse(response, 'ListRenameResidues')
+}
+
+// completeCategoryRename は DD-BE-003 の中断されたカテゴリ名変更を完了する。
+// 目的: 読み取り専用カテゴリを変更後の名前のカテゴリとして復旧する。
+// 入力: name は読み取り専用カテゴリ名（変更後の名前）。
+// 出力: CategoryDTO。
+// エラー: 復旧失敗時に ApiError を送出する。
+// 副作用: バックエンド呼び出しを行う。
+// 並行性: スレッドセーフ。
+// 不変条件: ok=true の場合のみ data を返す。
+// 関連DD: DD-BE-003
+export async function completeCategoryRename(name) {
+  const response = await App.CompleteCategoryRename(name)
+  return unwrapResponse(response, 'CompleteCategoryRename')
+}
+
+// rollbackCategoryRename は DD-BE-003 の中断されたカテゴリ名変更を取り消す。
+// 目的: 読み取り専用カテゴリを変更前の名前へ戻して復旧する。
+// 入力: name は読み取り専用カテゴリ名（変更後の名前）。
+// 出力: 変更前の名前の CategoryDTO。
+// エラー: 復旧失敗時に ApiError を送出する。
+// 副作用: バックエンド呼び出しを行う。
+// 並行性: スレッドセーフ。
+// 不変条件: ok=true の場合のみ data を返す。
+// 関連DD: DD-BE-003
+export async function rollbackCategoryRename(name) {
+  const response = await App.RollbackCategoryRename(name)
+  return unwrapResponse(response, 'RollbackCategoryRename')
+}
+
 // deleteCategory は DD-BE-003 のカテゴリ削除を行う。
 // 目的: カテゴリを削除する。
 // 入力: name はカテゴリ名。
@@ -4,6 +4,14 @@ import {present} from '../models';
 
 export function AddComment(arg1:string,arg2:string,arg3:present.CommentCreateDTO):Promise<present.Response>;
 
+export function ArchiveCategory(arg1:string):Promise<present.Response>;
+
+export function BrowseForProjectRoot():Promise<present.Response>;
+
+export function CancelOperation(arg1:string):Promise<present.Response>;
+
+export function CompleteCategoryRename(arg1:string):Promise<present.Response>;
+
 export function CreateCategory(arg1:string):Promise<present.Response>;
 
 export function CreateIssue(arg1:string,arg2:present.IssueCreateDTO):Promise<present.Response>;
@@ -14,20 +22,104 @@ export function DeleteCategory(arg1:string):Promise<present.Response>;
 
 export function DetectMode():Promise<present.Response>;
 
+export function EnterObserverMode():Promise<present.Response>;
+
+export function ExportDiagnostics(arg1:string):Promise<present.Response>;
+
+export function ExportIssueBundle(arg1:string,arg2:string,arg3:string):Promise<present.Response>;
+
+export function ExportIssueEML(arg1:string,arg2:string,arg3:string,arg4:string):Promise<present.Response>;
+
+export function ExportIssuePDF(arg1:string,arg2:string,arg3:string):Promise<present.Response>;
+
+export function ExportIssues(arg1:present.IssueExportQueryDTO,arg2:string):Promise<present.Response>;
+
+export function ExportRedmineCSV(arg1:present.RedmineExportQueryDTO,arg2:string,arg3:string):Promise<present.Response>;
+
+export function ExportSummaryPDF(arg1:string):Promise<present.Response>;
+
+export function ExportWeeklyReport(arg1:present.WeeklyReportQueryDTO,arg2:string):Promise<present.Response>;
+
+export function ForceDeleteCategory(arg1:string):Promise<present.Response>;
+
 export function GetAppBootstrap():Promise<present.Response>;
 
+export function GetCategoryStats(arg1:string):Promise<present.Response>;
+
+export function GetChangesSince(arg1:string):Promise<present.Response>;
+
 export function GetIssue(arg1:string,arg2:string):Promise<present.Response>;
 
+export function GetIssueHistory(arg1:string,arg2:string):Promise<present.Response>;
+
+export function GetLogs(arg1:present.LogQueryDTO):Promise<present.Response>;
+
+export function ImportIssueBundle(arg1:string,arg2:string):Promise<present.Response>;
+
 export function ListCategories():Promise<present.Response>;
 
+export function ListExportFormats():Promise<present.Response>;
+
 export function ListIssues(arg1:string,arg2:present.IssueListQueryDTO):Promise<present.Response>;
 
+export function ListRenameResidues():Promise<present.Response>;
+
+export function LockMode():Promise<present.Response>;
+
+export function OpenProjectRoot(arg1:string):Promise<present.Response>;
+
+export function RefreshProject():Promise<present.Response>;
+
 export function RenameCategory(arg1:string,arg2:string):Promise<present.Response>;
 
+export function ReorderCategories(arg1:Array<string>):Promise<present.Response>;
+
+export function RollbackCategoryRename(arg1:string):Promise<present.Response>;
+
 export function SaveLastProjectRoot(arg1:string):Promise<present.Response>;
 
+export function SaveSettings(arg1:present.SettingsDTO):Promise<present.Response>;
+
+export function SearchIssues(arg1:string,arg2:string):Promise<present.Response>;
+
+export function SetLogLevel(arg1:string):Promise<present.Response>;
+
+export function SetSQLiteCacheEnabled(arg1:boolean):Promise<present.Response>;
+
+export function StartApplyPatch(arg1:present.PatchApplyQueryDTO):Promise<present.Response>;
+
+export function StartExportDiagnostics(arg1:string):Promise<present.Response>;
+
+export function StartExportIssueBundle(arg1:string,arg2:string,arg3:string):Promise<present.Response>;
+
+export function StartExportPatch(arg1:present.PatchExportQueryDTO):Promise<present.Response>;
+
+export function StartImportIssuesJSONL(arg1:present.IssueImportQueryDTO):Promise<present.Response>;
+
+export function StartImportRedmineCSV(arg1:present.RedmineImportQueryDTO):Promise<present.Response>;
+
+export function StartMigrateProject(arg1:boolean):Promise<present.Response>;
+
+export function StartPublishSite(arg1:string):Promise<present.Response>;
+
+export function StartRebuildIndex():Promise<present.Response>;
+
+export function StartRenameCategory(arg1:string,arg2:string):Promise<present.Response>;
+
+export function StartSyncProject(arg1:present.SyncQueryDTO):Promise<present.Response>;
+
+export function TakeOverProjectLock():Promise<present.Response>;
+
+export function UnarchiveCategory(arg1:string):Promise<present.Response>;
+
+export function UndoLastOperation():Promise<present.Response>;
+
+export function UnlockRememberedContractor():Promise<present.Response>;
+
+export function UpdateCategoryMeta(arg1:string,arg2:present.CategoryMetaDTO):Promise<present.Response>;
+
 export function UpdateIssue(arg1:string,arg2:string,arg3:present.IssueUpdateDTO):Promise<present.Response>;
 
 export function ValidateProjectRoot(arg1:string):Promise<present.Response>;
 
-export function VerifyContractorPassword(arg1:string):Promise<present.Response>;
+export function VerifyContractorPassword(arg1:string,arg2:string,arg3:string,arg4:boolean):Promise<present.Response>;
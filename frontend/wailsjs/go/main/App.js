@@ -6,6 +6,22 @@ export function AddComment(arg1, arg2, arg3) {
   return window['go']['main']['App']['AddComment'](arg1, arg2, arg3);
 }
 
+export function ArchiveCategory(arg1) {
+  return window['go']['main']['App']['ArchiveCategory'](arg1);
+}
+
+export function BrowseForProjectRoot() {
+  return window['go']['main']['App']['BrowseForProjectRoot']();
+}
+
+export function CancelOperation(arg1) {
+  return window['go']['main']['App']['CancelOperation'](arg1);
+}
+
+export function CompleteCategoryRename(arg1) {
+  return window['go']['main']['App']['CompleteCategoryRename'](arg1);
+}
+
 export function CreateCategory(arg1) {
   return window['go']['main']['App']['CreateCategory'](arg1);
 }
@@ -26,30 +42,198 @@ export function DetectMode() {
   return window['go']['main']['App']['DetectMode']();
 }
 
+export function EnterObserverMode() {
+  return window['go']['main']['App']['EnterObserverMode']();
+}
+
+export function ExportDiagnostics(arg1) {
+  return window['go']['main']['App']['ExportDiagnostics'](arg1);
+}
+
+export function ExportIssueBundle(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportIssueBundle'](arg1, arg2, arg3);
+}
+
+export function ExportIssueEML(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ExportIssueEML'](arg1, arg2, arg3, arg4);
+}
+
+export function ExportIssuePDF(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportIssuePDF'](arg1, arg2, arg3);
+}
+
+export function ExportIssues(arg1, arg2) {
+  return window['go']['main']['App']['ExportIssues'](arg1, arg2);
+}
+
+export function ExportRedmineCSV(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportRedmineCSV'](arg1, arg2, arg3);
+}
+
+export function ExportSummaryPDF(arg1) {
+  return window['go']['main']['App']['ExportSummaryPDF'](arg1);
+}
+
+export function ExportWeeklyReport(arg1, arg2) {
+  return window['go']['main']['App']['ExportWeeklyReport'](arg1, arg2);
+}
+
+export function ForceDeleteCategory(arg1) {
+  return window['go']['main']['App']['ForceDeleteCategory'](arg1);
+}
+
 export function GetAppBootstrap() {
   return window['go']['main']['App']['GetAppBootstrap']();
 }
 
+export function GetCategoryStats(arg1) {
+  return window['go']['main']['App']['GetCategoryStats'](arg1);
+}
+
+export function GetChangesSince(arg1) {
+  return window['go']['main']['App']['GetChangesSince'](arg1);
+}
+
 export function GetIssue(arg1, arg2) {
   return window['go']['main']['App']['GetIssue'](arg1, arg2);
 }
 
+export function GetIssueHistory(arg1, arg2) {
+  return window['go']['main']['App']['GetIssueHistory'](arg1, arg2);
+}
+
+export function GetLogs(arg1) {
+  return window['go']['main']['App']['GetLogs'](arg1);
+}
+
+export function ImportIssueBundle(arg1, arg2) {
+  return window['go']['main']['App']['ImportIssueBundle'](arg1, arg2);
+}
+
 export function ListCategories() {
   return window['go']['main']['App']['ListCategories']();
 }
 
+export function ListExportFormats() {
+  return window['go']['main']['App']['ListExportFormats']();
+}
+
 export function ListIssues(arg1, arg2) {
   return window['go']['main']['App']['ListIssues'](arg1, arg2);
 }
 
+export function ListRenameResidues() {
+  return window['go']['main']['App']['ListRenameResidues']();
+}
+
+export function LockMode() {
+  return window['go']['main']['App']['LockMode']();
+}
+
+export function OpenProjectRoot(arg1) {
+  return window['go']['main']['App']['OpenProjectRoot'](arg1);
+}
+
+export function RefreshProject() {
+  return window['go']['main']['App']['RefreshProject']();
+}
+
 export function RenameCategory(arg1, arg2) {
   return window['go']['main']['App']['RenameCategory'](arg1, arg2);
 }
 
+export function ReorderCategories(arg1) {
+  return window['go']['main']['App']['ReorderCategories'](arg1);
+}
+
+export function RollbackCategoryRename(arg1) {
+  return window['go']['main']['App']['RollbackCategoryRename'](arg1);
+}
+
 export function SaveLastProjectRoot(arg1) {
   return window['go']['main']['App']['SaveLastProjectRoot'](arg1);
 }
 
+export function SaveSettings(arg1) {
+  return window['go']['main']['App']['SaveSettings'](arg1);
+}
+
+export function SearchIssues(arg1, arg2) {
+  return window['go']['main']['App']['SearchIssues'](arg1, arg2);
+}
+
+export function SetLogLevel(arg1) {
+  return window['go']['main']['App']['SetLogLevel'](arg1);
+}
+
+export function SetSQLiteCacheEnabled(arg1) {
+  return window['go']['main']['App']['SetSQLiteCacheEnabled'](arg1);
+}
+
+export function StartApplyPatch(arg1) {
+  return window['go']['main']['App']['StartApplyPatch'](arg1);
+}
+
+export function StartExportDiagnostics(arg1) {
+  return window['go']['main']['App']['StartExportDiagnostics'](arg1);
+}
+
+export function StartExportIssueBundle(arg1, arg2, arg3) {
+  return window['go']['main']['App']['StartExportIssueBundle'](arg1, arg2, arg3);
+}
+
+export function StartExportPatch(arg1) {
+  return window['go']['main']['App']['StartExportPatch'](arg1);
+}
+
+export function StartImportIssuesJSONL(arg1) {
+  return window['go']['main']['App']['StartImportIssuesJSONL'](arg1);
+}
+
+export function StartImportRedmineCSV(arg1) {
+  return window['go']['main']['App']['StartImportRedmineCSV'](arg1);
+}
+
+export function StartMigrateProject(arg1) {
+  return window['go']['main']['App']['StartMigrateProject'](arg1);
+}
+
+export function StartPublishSite(arg1) {
+  return window['go']['main']['App']['StartPublishSite'](arg1);
+}
+
+export function StartRebuildIndex() {
+  return window['go']['main']['App']['StartRebuildIndex']();
+}
+
+export function StartRenameCategory(arg1, arg2) {
+  return window['go']['main']['App']['StartRenameCategory'](arg1, arg2);
+}
+
+export function StartSyncProject(arg1) {
+  return window['go']['main']['App']['StartSyncProject'](arg1);
+}
+
+export function TakeOverProjectLock() {
+  return window['go']['main']['App']['TakeOverProjectLock']();
+}
+
+export function UnarchiveCategory(arg1) {
+  return window['go']['main']['App']['UnarchiveCategory'](arg1);
+}
+
+export function UndoLastOperation() {
+  return window['go']['main']['App']['UndoLastOperation']();
+}
+
+export function UnlockRememberedContractor() {
+  return window['go']['main']['App']['UnlockRememberedContractor']();
+}
+
+export function UpdateCategoryMeta(arg1, arg2) {
+  return window['go']['main']['App']['UpdateCategoryMeta'](arg1, arg2);
+}
+
 export function UpdateIssue(arg1, arg2, arg3) {
   return window['go']['main']['App']['UpdateIssue'](arg1, arg2, arg3);
 }
@@ -58,6 +242,6 @@ export function ValidateProjectRoot(arg1) {
   return window['go']['main']['App']['ValidateProjectRoot'](arg1);
 }
 
-export function VerifyContractorPassword(arg1) {
-  return window['go']['main']['App']['VerifyContractorPassword'](arg1);
+export function VerifyContractorPassword(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['VerifyContractorPassword'](arg1, arg2, arg3, arg4);
 }
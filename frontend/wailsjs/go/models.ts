@@ -6,6 +6,7 @@ export namespace present {
 	    detail?: string;
 	    target_path?: string;
 	    hint?: string;
+	    conflict?: IssueConflictDTO;
 	
 	    static createFrom(source: any = {}) {
 	        return new APIErrorDTO(source);
@@ -18,6 +19,47 @@ export namespace present {
 	        this.detail = source["detail"];
 	        this.target_path = source["target_path"];
 	        this.hint = source["hint"];
+	        this.conflict = this.convertValues(source["conflict"], IssueConflictDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AttachmentRefDTO {
+	    attachment_id: string;
+	    file_name: string;
+	    stored_name: string;
+	    relative_path: string;
+	    mime_type?: string;
+	    size_bytes?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AttachmentRefDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.attachment_id = source["attachment_id"];
+	        this.file_name = source["file_name"];
+	        this.stored_name = source["stored_name"];
+	        this.relative_path = source["relative_path"];
+	        this.mime_type = source["mime_type"];
+	        this.size_bytes = source["size_bytes"];
 	    }
 	}
 	export class AttachmentUploadDTO {
@@ -36,10 +78,33 @@ export namespace present {
 	        this.mime_type = source["mime_type"];
 	    }
 	}
+	export class CategoryMetaDTO {
+	    description: string;
+	    color: string;
+	    sort_weight: number;
+	    default_assignee: string;
+	    default_priority: string;
+	    description_template: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CategoryMetaDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.description = source["description"];
+	        this.color = source["color"];
+	        this.sort_weight = source["sort_weight"];
+	        this.default_assignee = source["default_assignee"];
+	        this.default_priority = source["default_priority"];
+	        this.description_template = source["description_template"];
+	    }
+	}
 	export class CommentCreateDTO {
 	    body: string;
 	    author_name: string;
 	    attachments: AttachmentUploadDTO[];
+	    expected_revision?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new CommentCreateDTO(source);
@@ -50,6 +115,81 @@ export namespace present {
 	        this.body = source["body"];
 	        this.author_name = source["author_name"];
 	        this.attachments = this.convertValues(source["attachments"], AttachmentUploadDTO);
+	        this.expected_revision = source["expected_revision"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CommentDTO {
+	    comment_id: string;
+	    body: string;
+	    author_name: string;
+	    author_company: string;
+	    created_at: string;
+	    created_at_local: string;
+	    attachments: AttachmentRefDTO[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CommentDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.comment_id = source["comment_id"];
+	        this.body = source["body"];
+	        this.author_name = source["author_name"];
+	        this.author_company = source["author_company"];
+	        this.created_at = source["created_at"];
+	        this.created_at_local = source["created_at_local"];
+	        this.attachments = this.convertValues(source["attachments"], AttachmentRefDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class IssueConflictDTO {
+	    current: IssueDetailDTO;
+	    attempted: IssueDetailDTO;
+	
+	    static createFrom(source: any = {}) {
+	        return new IssueConflictDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.current = this.convertValues(source["current"], IssueDetailDTO);
+	        this.attempted = this.convertValues(source["attempted"], IssueDetailDTO);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -90,11 +230,114 @@ export namespace present {
 	        this.assignee = source["assignee"];
 	    }
 	}
+	export class IssueDetailDTO {
+	    is_schema_invalid: boolean;
+	    version: number;
+	    issue_id: string;
+	    category: string;
+	    title: string;
+	    description: string;
+	    status: string;
+	    priority: string;
+	    origin_company: string;
+	    assignee: string;
+	    created_at: string;
+	    created_at_local: string;
+	    updated_at: string;
+	    updated_at_local: string;
+	    due_date: string;
+	    tags?: string[];
+	    custom_fields?: {[key: string]: any};
+	    comments: CommentDTO[];
+	    revision?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new IssueDetailDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.is_schema_invalid = source["is_schema_invalid"];
+	        this.version = source["version"];
+	        this.issue_id = source["issue_id"];
+	        this.category = source["category"];
+	        this.title = source["title"];
+	        this.description = source["description"];
+	        this.status = source["status"];
+	        this.priority = source["priority"];
+	        this.origin_company = source["origin_company"];
+	        this.assignee = source["assignee"];
+	        this.created_at = source["created_at"];
+	        this.created_at_local = source["created_at_local"];
+	        this.updated_at = source["updated_at"];
+	        this.updated_at_local = source["updated_at_local"];
+	        this.due_date = source["due_date"];
+	        this.tags = source["tags"];
+	        this.custom_fields = source["custom_fields"];
+	        this.comments = this.convertValues(source["comments"], CommentDTO);
+	        this.revision = source["revision"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class IssueExportQueryDTO {
+	    format: string;
+	    categories?: string[];
+	    statuses?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new IssueExportQueryDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.categories = source["categories"];
+	        this.statuses = source["statuses"];
+	    }
+	}
+	export class IssueImportQueryDTO {
+	    path: string;
+	    category?: string;
+	    overwrite: boolean;
+	    dry_run: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new IssueImportQueryDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.category = source["category"];
+	        this.overwrite = source["overwrite"];
+	        this.dry_run = source["dry_run"];
+	    }
+	}
 	export class IssueListQueryDTO {
 	    page: number;
 	    page_size: number;
 	    sort_by: string;
 	    sort_order: string;
+	    status?: string;
+	    priority?: string;
+	    cursor?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new IssueListQueryDTO(source);
@@ -106,6 +349,9 @@ export namespace present {
 	        this.page_size = source["page_size"];
 	        this.sort_by = source["sort_by"];
 	        this.sort_order = source["sort_order"];
+	        this.status = source["status"];
+	        this.priority = source["priority"];
+	        this.cursor = source["cursor"];
 	    }
 	}
 	export class IssueUpdateDTO {
@@ -115,6 +361,7 @@ export namespace present {
 	    priority: string;
 	    status: string;
 	    assignee: string;
+	    expected_revision?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new IssueUpdateDTO(source);
@@ -128,12 +375,102 @@ export namespace present {
 	        this.priority = source["priority"];
 	        this.status = source["status"];
 	        this.assignee = source["assignee"];
+	        this.expected_revision = source["expected_revision"];
+	    }
+	}
+	export class LogQueryDTO {
+	    level?: string;
+	    since?: string;
+	    until?: string;
+	    category?: string;
+	    limit?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new LogQueryDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.level = source["level"];
+	        this.since = source["since"];
+	        this.until = source["until"];
+	        this.category = source["category"];
+	        this.limit = source["limit"];
+	    }
+	}
+	export class PatchApplyQueryDTO {
+	    path: string;
+	    passphrase: string;
+	    dry_run: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new PatchApplyQueryDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.passphrase = source["passphrase"];
+	        this.dry_run = source["dry_run"];
+	    }
+	}
+	export class PatchExportQueryDTO {
+	    dest_path: string;
+	    since?: string;
+	    passphrase: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PatchExportQueryDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dest_path = source["dest_path"];
+	        this.since = source["since"];
+	        this.passphrase = source["passphrase"];
+	    }
+	}
+	export class RedmineExportQueryDTO {
+	    categories?: string[];
+	    statuses?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RedmineExportQueryDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.categories = source["categories"];
+	        this.statuses = source["statuses"];
+	    }
+	}
+	export class RedmineImportQueryDTO {
+	    category: string;
+	    issues_path: string;
+	    journals_path?: string;
+	    company?: string;
+	    default_due_date?: string;
+	    dry_run: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new RedmineImportQueryDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.category = source["category"];
+	        this.issues_path = source["issues_path"];
+	        this.journals_path = source["journals_path"];
+	        this.company = source["company"];
+	        this.default_due_date = source["default_due_date"];
+	        this.dry_run = source["dry_run"];
 	    }
 	}
 	export class Response {
 	    ok: boolean;
 	    data?: any;
 	    error?: APIErrorDTO;
+	    warnings?: APIErrorDTO[];
 	
 	    static createFrom(source: any = {}) {
 	        return new Response(source);
@@ -144,6 +481,7 @@ export namespace present {
 	        this.ok = source["ok"];
 	        this.data = source["data"];
 	        this.error = this.convertValues(source["error"], APIErrorDTO);
+	        this.warnings = this.convertValues(source["warnings"], APIErrorDTO);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -164,6 +502,62 @@ export namespace present {
 		    return a;
 		}
 	}
+	export class SettingsDTO {
+	    default_sort_by: string;
+	    default_sort_order: string;
+	    default_author_name: string;
+	    date_format: string;
+	    language: string;
+	    time_zone: string;
+	    confirm_delete_category: boolean;
+	    confirm_delete_category_with_issues: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SettingsDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.default_sort_by = source["default_sort_by"];
+	        this.default_sort_order = source["default_sort_order"];
+	        this.default_author_name = source["default_author_name"];
+	        this.date_format = source["date_format"];
+	        this.language = source["language"];
+	        this.time_zone = source["time_zone"];
+	        this.confirm_delete_category = source["confirm_delete_category"];
+	        this.confirm_delete_category_with_issues = source["confirm_delete_category_with_issues"];
+	    }
+	}
+	export class SyncQueryDTO {
+	    other_root: string;
+	    dry_run: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SyncQueryDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.other_root = source["other_root"];
+	        this.dry_run = source["dry_run"];
+	    }
+	}
+	export class WeeklyReportQueryDTO {
+	    from?: string;
+	    to?: string;
+	    format: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new WeeklyReportQueryDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.format = source["format"];
+	    }
+	}
 
 }
 
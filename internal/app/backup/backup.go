@@ -0,0 +1,418 @@
+// Package backup はプロジェクトルート全体の zip バックアップの作成と、空のディレクトリへの復元を担い、
+// 保存先の選択や世代管理は扱わない。
+// Git を使わない運用でもプロジェクトの時点の状態を保管・復元できるよう、内容を manifest のハッシュで検証する。
+package backup
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/timeutil"
+	"ratta/internal/infra/jsonfmt"
+	"ratta/internal/infra/projectlock"
+	"ratta/internal/infra/projectmeta"
+	"ratta/internal/infra/tmpresidue"
+)
+
+const (
+	// formatVersion は DD-BACKUP-001 の manifest 形式バージョンを表す。
+	formatVersion = 1
+	// backupKind は DD-BACKUP-001 のバックアップ識別子で、課題バンドルなど他形式の zip との取り違えを防ぐ。
+	backupKind = "ratta-project-backup"
+	// manifestName は DD-BACKUP-001 の manifest エントリ名を表す。プロジェクト内のパスと衝突しないよう先頭をドットにする。
+	manifestName = ".ratta-backup-manifest.json"
+)
+
+// excludedMetaEntries は DD-BACKUP-001 の .ratta 配下で対象外とするエントリを表す。
+// 索引・キャッシュ・全文検索索引は課題JSONから再生成でき、操作記録と DD-SYNC-003 の同期の記録はインスタンス固有のため含めない。
+var excludedMetaEntries = map[string]bool{
+	"index.json":   true,
+	"cache.db":     true,
+	"cache.db-wal": true,
+	"cache.db-shm": true,
+	"search.bleve": true,
+	"journal":      true,
+	"backups":      true,
+	"sync":         true,
+}
+
+var now = time.Now
+
+// Manifest は DD-BACKUP-001 のバックアップの構成情報を表す。
+type Manifest struct {
+	FormatVersion int    `json:"format_version"`
+	Kind          string `json:"kind"`
+	CreatedAt     string `json:"created_at"`
+	Files         []File `json:"files"`
+}
+
+// File は DD-BACKUP-001 のバックアップ内のファイル情報を表す。Path はプロジェクトルートからの相対パス (区切りは /) とする。
+type File struct {
+	Path      string `json:"path"`
+	SizeBytes int64  `json:"size_bytes"`
+	SHA256    string `json:"sha256"`
+}
+
+// Result は DD-BACKUP-001/DD-BACKUP-002 の作成・復元結果を表す。Path は zip または復元先のパスを表す。
+type Result struct {
+	Path       string
+	FileCount  int
+	TotalBytes int64
+}
+
+// DefaultFileName は DD-BACKUP-001 の既定のバックアップファイル名 (ratta-backup-<日時>.zip) を返す。
+func DefaultFileName() string {
+	return "ratta-backup-" + now().Format("20060102T150405") + ".zip"
+}
+
+// Create は DD-BACKUP-001 のプロジェクトのバックアップを作成する。
+// 目的: プロジェクトルート配下の課題・添付・メタデータを1つの zip にまとめ、時点の状態を保管できるようにする。
+// 入力: ctx は中断通知、root はプロジェクトルート、destPath は出力先 zip のパス。
+// 出力: Result とエラー。
+// エラー: 出力先未指定・既存、走査・読み取り・書き込みに失敗した場合、中断された場合に返す。
+// 副作用: destPath に zip を作成する。途中で失敗した場合は作成途中のファイルを残さない。プロジェクト配下は変更しない。
+// 並行性: 読み取りのみのため課題操作と同時に実行してよいが、実行中の変更が含まれるかは保証しない。
+// 不変条件: 一時ファイル残骸、ロックファイル、.git、再生成できる索引・キャッシュ、操作記録、出力先自身は含めない。
+// manifest の files には zip 内の manifest 以外の全エントリがパス順に含まれる。
+// 関連DD: DD-BACKUP-001, DD-PERSIST-002
+func Create(ctx context.Context, root, destPath string) (Result, error) {
+	if destPath == "" {
+		return Result{}, errors.New("backup path is required")
+	}
+	if _, err := os.Stat(destPath); err == nil {
+		return Result{}, fmt.Errorf("backup already exists: %s", destPath)
+	}
+	paths, err := collect(ctx, root, destPath)
+	if err != nil {
+		return Result{}, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(destPath), filepath.Base(destPath)+".tmp.*")
+	if err != nil {
+		return Result{}, fmt.Errorf("create backup: %w", err)
+	}
+	tmpPath := tmp.Name()
+	manifest, writeErr := writeArchive(ctx, tmp, root, paths)
+	if closeErr := tmp.Close(); closeErr != nil && writeErr == nil {
+		writeErr = fmt.Errorf("close backup: %w", closeErr)
+	}
+	if writeErr == nil {
+		writeErr = os.Rename(tmpPath, destPath)
+	}
+	if writeErr != nil {
+		if removeErr := os.Remove(tmpPath); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+			return Result{}, fmt.Errorf("%w; cleanup error: %s", writeErr, removeErr.Error())
+		}
+		return Result{}, writeErr
+	}
+	return summarize(destPath, manifest.Files), nil
+}
+
+// collect は DD-BACKUP-001 のバックアップ対象のファイルをパス順に列挙する。
+func collect(ctx context.Context, root, destPath string) ([]string, error) {
+	absDest, err := filepath.Abs(destPath)
+	if err != nil {
+		return nil, fmt.Errorf("resolve backup path: %w", err)
+	}
+	var paths []string
+	walkErr := filepath.WalkDir(root, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		rel, relErr := filepath.Rel(root, current)
+		if relErr != nil {
+			return fmt.Errorf("resolve backup entry: %w", relErr)
+		}
+		if rel == "." {
+			return nil
+		}
+		if excluded(filepath.ToSlash(rel), entry) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() || !entry.Type().IsRegular() {
+			return nil
+		}
+		if absCurrent, absErr := filepath.Abs(current); absErr == nil && absCurrent == absDest {
+			return nil
+		}
+		paths = append(paths, rel)
+		return nil
+	})
+	if walkErr != nil {
+		return nil, fmt.Errorf("collect backup files: %w", walkErr)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// excluded は DD-BACKUP-001 の対象外判定を行う。rel はプロジェクトルートからの相対パス (区切りは /)。
+func excluded(rel string, entry fs.DirEntry) bool {
+	if rel == ".git" || rel == projectlock.FileName || rel == manifestName {
+		return true
+	}
+	if !entry.IsDir() && tmpresidue.IsArtifact(entry.Name()) {
+		return true
+	}
+	if parent, name := path.Split(rel); parent == projectmeta.DirName+"/" {
+		return excludedMetaEntries[name]
+	}
+	return false
+}
+
+// writeArchive は DD-BACKUP-001 の zip を w へ書き込み、作成した manifest を返す。
+// ファイルは読みながら圧縮し、プロジェクト全体をメモリへ載せない。manifest は最後のエントリとする。
+func writeArchive(ctx context.Context, w io.Writer, root string, paths []string) (Manifest, error) {
+	writer := zip.NewWriter(w)
+	modified := now()
+	manifest := Manifest{
+		FormatVersion: formatVersion,
+		Kind:          backupKind,
+		CreatedAt:     timeutil.FormatISO8601(modified),
+		Files:         make([]File, 0, len(paths)),
+	}
+	for _, rel := range paths {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return Manifest{}, ctxErr
+		}
+		name := filepath.ToSlash(rel)
+		entryWriter, err := writer.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return Manifest{}, fmt.Errorf("create zip entry: %w", err)
+		}
+		file, err := copyFile(entryWriter, filepath.Join(root, rel))
+		if err != nil {
+			return Manifest{}, err
+		}
+		file.Path = name
+		manifest.Files = append(manifest.Files, file)
+	}
+	data, err := jsonfmt.MarshalBackupManifest(manifest)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("marshal manifest: %w", err)
+	}
+	manifestWriter, err := writer.CreateHeader(&zip.FileHeader{Name: manifestName, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return Manifest{}, fmt.Errorf("create zip entry: %w", err)
+	}
+	if _, writeErr := manifestWriter.Write(data); writeErr != nil {
+		return Manifest{}, fmt.Errorf("write zip entry: %w", writeErr)
+	}
+	if closeErr := writer.Close(); closeErr != nil {
+		return Manifest{}, fmt.Errorf("close zip: %w", closeErr)
+	}
+	return manifest, nil
+}
+
+// copyFile は DD-BACKUP-001 のファイル1件を w へ複写し、サイズとハッシュを返す。
+func copyFile(w io.Writer, filePath string) (File, error) {
+	// #nosec G304 -- プロジェクトルート配下の走査結果のみを読む。
+	source, err := os.Open(filePath)
+	if err != nil {
+		return File{}, fmt.Errorf("open backup entry: %w", err)
+	}
+	defer func() { _ = source.Close() }()
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(w, hash), source)
+	if err != nil {
+		return File{}, fmt.Errorf("write zip entry: %w", err)
+	}
+	return File{SizeBytes: size, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+// Restore は DD-BACKUP-002 のバックアップの復元を行う。
+// 目的: Create が作成した zip を検証しながら展開し、バックアップ時点のプロジェクトを再現する。
+// 入力: ctx は中断通知、srcPath はバックアップ zip のパス、destRoot は復元先ディレクトリ。
+// 出力: Result とエラー。
+// エラー: 復元先が空でない場合、zip・manifest の不正、危険なパス、未記載のエントリ、サイズやハッシュの不一致、
+// 書き込みに失敗した場合、中断された場合に返す。
+// 副作用: destRoot を作成し、ファイルを展開する。失敗した場合は展開したファイルを削除し、復元先を空に戻す。
+// 並行性: 同じ復元先への同時復元は想定しない。
+// 不変条件: manifest に記載されたファイルがすべて一致した場合に限り成功を返し、既存のファイルは上書きしない。
+// 関連DD: DD-BACKUP-002, DD-BUNDLE-002
+func Restore(ctx context.Context, srcPath, destRoot string) (Result, error) {
+	if srcPath == "" || destRoot == "" {
+		return Result{}, errors.New("backup path and restore destination are required")
+	}
+	if err := ensureEmptyDir(destRoot); err != nil {
+		return Result{}, err
+	}
+	reader, err := zip.OpenReader(srcPath)
+	if err != nil {
+		return Result{}, fmt.Errorf("open backup: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	manifest, entries, err := readManifest(reader.File)
+	if err != nil {
+		return Result{}, err
+	}
+	if restoreErr := extract(ctx, manifest, entries, destRoot); restoreErr != nil {
+		if cleanupErr := clearDir(destRoot); cleanupErr != nil {
+			return Result{}, fmt.Errorf("%w; cleanup error: %s", restoreErr, cleanupErr.Error())
+		}
+		return Result{}, restoreErr
+	}
+	return summarize(destRoot, manifest.Files), nil
+}
+
+// readManifest は DD-BACKUP-002 の manifest の読み込みと zip のエントリ名の照合を行う。
+// 展開前に、危険なパスや manifest に無いエントリ、記載されたエントリの欠落を検出する。
+func readManifest(files []*zip.File) (Manifest, map[string]*zip.File, error) {
+	entries := make(map[string]*zip.File, len(files))
+	var manifestFile *zip.File
+	for _, file := range files {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		// zip slip 対策として、展開先ディレクトリ外を指し得る名前は一律に拒否する。
+		cleaned := path.Clean(file.Name)
+		if cleaned != file.Name || path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") || strings.Contains(cleaned, "\\") {
+			return Manifest{}, nil, fmt.Errorf("unsafe backup entry: %s", file.Name)
+		}
+		if cleaned == manifestName {
+			manifestFile = file
+			continue
+		}
+		entries[cleaned] = file
+	}
+	if manifestFile == nil {
+		return Manifest{}, nil, apperr.New(apperr.ErrNotFound, "backup manifest not found")
+	}
+	rc, err := manifestFile.Open()
+	if err != nil {
+		return Manifest{}, nil, fmt.Errorf("open backup manifest: %w", err)
+	}
+	var manifest Manifest
+	decodeErr := json.NewDecoder(rc).Decode(&manifest)
+	if closeErr := rc.Close(); closeErr != nil && decodeErr == nil {
+		decodeErr = closeErr
+	}
+	if decodeErr != nil {
+		return Manifest{}, nil, fmt.Errorf("parse backup manifest: %w", decodeErr)
+	}
+	if manifest.Kind != backupKind || manifest.FormatVersion != formatVersion {
+		return Manifest{}, nil, errors.New("unsupported backup format")
+	}
+	listed := make(map[string]bool, len(manifest.Files))
+	for _, file := range manifest.Files {
+		entry, ok := entries[file.Path]
+		if !ok {
+			return Manifest{}, nil, fmt.Errorf("backup entry missing: %s", file.Path)
+		}
+		if int64(entry.UncompressedSize64) != file.SizeBytes {
+			return Manifest{}, nil, fmt.Errorf("backup entry size mismatch: %s", file.Path)
+		}
+		listed[file.Path] = true
+	}
+	// manifest に無いエントリは出所が不明なため、黙って捨てずに復元自体を拒否する。
+	for name := range entries {
+		if !listed[name] {
+			return Manifest{}, nil, fmt.Errorf("backup entry not listed in manifest: %s", name)
+		}
+	}
+	return manifest, entries, nil
+}
+
+// extract は DD-BACKUP-002 の manifest に記載されたファイルを展開し、展開した内容のハッシュを照合する。
+func extract(ctx context.Context, manifest Manifest, entries map[string]*zip.File, destRoot string) error {
+	for _, file := range manifest.Files {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		target := filepath.Join(destRoot, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+			return fmt.Errorf("create restore dir: %w", err)
+		}
+		if err := extractOne(entries[file.Path], target, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractOne は DD-BACKUP-002 のエントリ1件を展開し、サイズとハッシュが manifest と一致することを確認する。
+func extractOne(entry *zip.File, target string, expected File) error {
+	rc, err := entry.Open()
+	if err != nil {
+		return fmt.Errorf("open backup entry: %w", err)
+	}
+	defer func() { _ = rc.Close() }()
+	// #nosec G304 -- 検証済みの相対パスから復元先配下に生成したパスのみを作成する。
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("create restored file: %w", err)
+	}
+	hash := sha256.New()
+	// 展開後のサイズは manifest で確認済みのため、それを超えて読み込まないよう制限する。
+	size, copyErr := io.Copy(io.MultiWriter(out, hash), io.LimitReader(rc, expected.SizeBytes+1))
+	if closeErr := out.Close(); closeErr != nil && copyErr == nil {
+		copyErr = closeErr
+	}
+	if copyErr != nil {
+		return fmt.Errorf("write restored file: %w", copyErr)
+	}
+	if size != expected.SizeBytes || hex.EncodeToString(hash.Sum(nil)) != expected.SHA256 {
+		return fmt.Errorf("backup entry checksum mismatch: %s", expected.Path)
+	}
+	return nil
+}
+
+// ensureEmptyDir は DD-BACKUP-002 の復元先が空のディレクトリであることを確認し、存在しない場合は作成する。
+func ensureEmptyDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		if mkdirErr := os.MkdirAll(dir, 0o750); mkdirErr != nil {
+			return fmt.Errorf("create restore destination: %w", mkdirErr)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read restore destination: %w", err)
+	}
+	if len(entries) > 0 {
+		return apperr.Errorf(apperr.ErrConflict, "restore destination is not empty: %s", dir)
+	}
+	return nil
+}
+
+// clearDir は DD-BACKUP-002 の復元に失敗した場合に復元先の中身を削除する。復元先自体は残す。
+func clearDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, entry := range entries {
+		errs = append(errs, os.RemoveAll(filepath.Join(dir, entry.Name())))
+	}
+	return errors.Join(errs...)
+}
+
+// summarize は DD-BACKUP-001/DD-BACKUP-002 の結果の件数とサイズを集計する。
+func summarize(path string, files []File) Result {
+	result := Result{Path: path, FileCount: len(files)}
+	for _, file := range files {
+		result.TotalBytes += file.SizeBytes
+	}
+	return result
+}
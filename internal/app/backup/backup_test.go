@@ -0,0 +1,131 @@
+// backup_test.go はプロジェクトのバックアップの作成・対象外判定・復元時の検証のテストを行う。
+package backup
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile はテスト用に親ディレクトリを作成してファイルを書き込む。
+func writeFile(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+// newProject はテスト用にバックアップ対象と対象外のファイルを含むプロジェクトを作成する。
+func newProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "cat", "a.json"), `{"issue_id":"a"}`)
+	writeFile(t, filepath.Join(root, "cat", "a.files", "note.txt"), "hello")
+	writeFile(t, filepath.Join(root, ".ratta", "category_order.json"), `{"format_version":1}`)
+	writeFile(t, filepath.Join(root, ".ratta", "index.json"), `{}`)
+	writeFile(t, filepath.Join(root, ".ratta", "journal", "entries", "x.json"), `{}`)
+	writeFile(t, filepath.Join(root, ".ratta.lock"), `{}`)
+	writeFile(t, filepath.Join(root, ".git", "HEAD"), "ref")
+	writeFile(t, filepath.Join(root, "cat", "a.json.tmp.1.2"), "{")
+	return root
+}
+
+func TestCreateAndRestore_RoundTrip(t *testing.T) {
+	// 対象外のファイルを除いてバックアップを作成し、空のディレクトリへ同じ内容で復元できることを確認する。
+	root := newProject(t)
+	dest := filepath.Join(t.TempDir(), "backup.zip")
+	created, err := Create(context.Background(), root, dest)
+	if err != nil {
+		t.Fatalf("Create error: %v", err)
+	}
+	if created.FileCount != 3 {
+		t.Fatalf("unexpected file count: %+v", created)
+	}
+	if _, err := Create(context.Background(), root, dest); err == nil {
+		t.Fatal("expected error for existing backup")
+	}
+
+	restoreRoot := filepath.Join(t.TempDir(), "restored")
+	restored, err := Restore(context.Background(), dest, restoreRoot)
+	if err != nil {
+		t.Fatalf("Restore error: %v", err)
+	}
+	if restored.FileCount != 3 || restored.TotalBytes != created.TotalBytes {
+		t.Fatalf("unexpected restore result: %+v", restored)
+	}
+	for _, rel := range []string{"cat/a.json", "cat/a.files/note.txt", ".ratta/category_order.json"} {
+		want, _ := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+		got, err := os.ReadFile(filepath.Join(restoreRoot, filepath.FromSlash(rel)))
+		if err != nil || string(got) != string(want) {
+			t.Fatalf("unexpected restored %s: %q %v", rel, got, err)
+		}
+	}
+	for _, rel := range []string{".ratta/index.json", ".ratta/journal", ".ratta.lock", ".git", "cat/a.json.tmp.1.2"} {
+		if _, err := os.Stat(filepath.Join(restoreRoot, filepath.FromSlash(rel))); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be excluded, err=%v", rel, err)
+		}
+	}
+
+	if _, err := Restore(context.Background(), dest, restoreRoot); err == nil {
+		t.Fatal("expected error for non-empty destination")
+	}
+}
+
+func TestRestore_RejectsTamperedBackup(t *testing.T) {
+	// manifest と内容が一致しないバックアップは復元せず、展開したファイルを残さないことを確認する。
+	root := newProject(t)
+	dest := filepath.Join(t.TempDir(), "backup.zip")
+	if _, err := Create(context.Background(), root, dest); err != nil {
+		t.Fatalf("Create error: %v", err)
+	}
+	reader, err := zip.OpenReader(dest)
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	tampered := filepath.Join(t.TempDir(), "tampered.zip")
+	out, err := os.Create(tampered)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	writer := zip.NewWriter(out)
+	for _, file := range reader.File {
+		entry, createErr := writer.Create(file.Name)
+		if createErr != nil {
+			t.Fatalf("create entry: %v", createErr)
+		}
+		if file.Name == "cat/a.json" {
+			// 前提: サイズは同じまま内容だけを書き換える。
+			_, createErr = entry.Write([]byte(`{"issue_id":"b"}`))
+		} else {
+			rc, openErr := file.Open()
+			if openErr != nil {
+				t.Fatalf("open entry: %v", openErr)
+			}
+			_, createErr = io.Copy(entry, rc)
+			_ = rc.Close()
+		}
+		if createErr != nil {
+			t.Fatalf("write entry: %v", createErr)
+		}
+	}
+	_ = reader.Close()
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	_ = out.Close()
+
+	restoreRoot := t.TempDir()
+	if _, err := Restore(context.Background(), tampered, restoreRoot); err == nil {
+		t.Fatal("expected checksum error")
+	}
+	entries, err := os.ReadDir(restoreRoot)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected empty destination, got %v %v", entries, err)
+	}
+}
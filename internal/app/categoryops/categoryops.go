@@ -3,6 +3,7 @@
 package categoryops
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,9 +11,12 @@ import (
 	"path/filepath"
 	"strings"
 
+	"ratta/internal/domain/apperr"
 	"ratta/internal/domain/issue"
 	"ratta/internal/infra/atomicwrite"
-	"ratta/internal/infra/jsonfmt"
+	"ratta/internal/infra/categorymeta"
+	"ratta/internal/infra/issueindex"
+	"ratta/internal/infra/projectmeta"
 
 	mod "ratta/internal/domain/mode"
 )
@@ -21,7 +25,9 @@ import (
 type Category struct {
 	Name       string
 	IsReadOnly bool
+	IsArchived bool
 	Path       string
+	Meta       categorymeta.Meta
 }
 
 // Service は DD-BE-003 のカテゴリ操作を担う。
@@ -42,10 +48,10 @@ func NewService(projectRoot string) *Service {
 // 副作用: プロジェクトルート配下にディレクトリを作成する。
 // 並行性: 同一プロジェクトルートへの同時実行は呼び出し側で排他する。
 // 不変条件: 作成後のカテゴリ名は入力 name と一致する。
-// 関連DD: DD-BE-003
+// 関連DD: DD-BE-003, DD-PERM-001
 func (s *Service) CreateCategory(name string, currentMode mod.Mode) (Category, error) {
-	if currentMode != mod.ModeContractor {
-		return Category{}, errors.New("permission denied")
+	if err := s.ensureCanWrite(name, currentMode); err != nil {
+		return Category{}, err
 	}
 	if errs := issue.ValidateCategoryName(name); len(errs) > 0 {
 		return Category{}, errs
@@ -57,7 +63,95 @@ func (s *Service) CreateCategory(name string, currentMode mod.Mode) (Category, e
 	if err := os.MkdirAll(path, 0o750); err != nil {
 		return Category{}, fmt.Errorf("create category: %w", err)
 	}
-	return Category{Name: name, Path: path}, nil
+	meta, _, err := categorymeta.Load(path)
+	if err != nil {
+		return Category{}, err
+	}
+	return Category{Name: name, Path: path, Meta: meta}, nil
+}
+
+// GetCategoryMeta は DD-CATMETA-001 のカテゴリメタデータ取得を行う。
+// 目的: カテゴリ直下の .category.json を読み込み表示用に返す。
+// 入力: name はカテゴリ名。
+// 出力: Meta とエラー。
+// エラー: カテゴリ不在、メタデータの読み取り・パース失敗時に返す。
+// 副作用: なし。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: .category.json が無い場合は既定値を返す。
+// 関連DD: DD-CATMETA-001
+func (s *Service) GetCategoryMeta(name string) (categorymeta.Meta, error) {
+	path, err := s.categoryPath(name)
+	if err != nil {
+		return categorymeta.Meta{}, err
+	}
+	meta, _, loadErr := categorymeta.Load(path)
+	if loadErr != nil {
+		return categorymeta.Meta{}, loadErr
+	}
+	return meta, nil
+}
+
+// UpdateCategoryMeta は DD-CATMETA-001 のカテゴリメタデータ更新を行う。
+// 目的: 説明・表示色・並び順を .category.json に保存する。
+// 入力: name はカテゴリ名、meta は保存内容、currentMode は操作モード。
+// 出力: 保存後の Category とエラー。
+// エラー: 権限不足、読み取り専用、カテゴリ不在、検証失敗、保存失敗時に返す。
+// 副作用: .category.json を作成または置換する。
+// 並行性: 同一カテゴリへの同時更新は想定しない。
+// 不変条件: 課題JSONや添付ディレクトリには触れない。
+// 関連DD: DD-CATMETA-001, DD-BE-003, DD-PERM-001
+func (s *Service) UpdateCategoryMeta(name string, meta categorymeta.Meta, currentMode mod.Mode) (Category, error) {
+	if err := s.ensureCanWrite(name, currentMode); err != nil {
+		return Category{}, err
+	}
+	if s.isReadOnly(name) {
+		return Category{}, apperr.New(apperr.ErrReadOnly, "read-only category")
+	}
+	if s.isArchived(name) {
+		return Category{}, apperr.New(apperr.ErrReadOnly, "archived category is read-only")
+	}
+	path, err := s.categoryPath(name)
+	if err != nil {
+		return Category{}, err
+	}
+	if saveErr := categorymeta.Save(path, meta); saveErr != nil {
+		return Category{}, saveErr
+	}
+	saved, _, loadErr := categorymeta.Load(path)
+	if loadErr != nil {
+		return Category{}, loadErr
+	}
+	return Category{Name: name, Path: path, Meta: saved}, nil
+}
+
+// SetCategoryArchived は DD-CATMETA-002 のカテゴリのアーカイブ・解除を行う。
+// 目的: .archived マーカーを切り替え、カテゴリ配下の課題を読み取り専用にする、または戻す。
+// 入力: name はカテゴリ名、archived は設定後の状態、currentMode は操作モード。
+// 出力: 切り替え後の Category とエラー。
+// エラー: 権限不足、.tmp_rename 由来の読み取り専用、カテゴリ不在、マーカー操作失敗時に返す。
+// 副作用: .archived を作成または削除する。
+// 並行性: 同一カテゴリへの同時切り替えは想定しない。
+// 不変条件: 課題JSON・添付・.category.json には触れない。
+// 関連DD: DD-CATMETA-002, DD-BE-003, DD-PERM-001
+func (s *Service) SetCategoryArchived(name string, archived bool, currentMode mod.Mode) (Category, error) {
+	if err := s.ensureCanWrite(name, currentMode); err != nil {
+		return Category{}, err
+	}
+	if s.isReadOnly(name) {
+		return Category{}, apperr.New(apperr.ErrReadOnly, "read-only category")
+	}
+	path, err := s.categoryPath(name)
+	if err != nil {
+		return Category{}, err
+	}
+	if setErr := categorymeta.SetArchived(path, archived); setErr != nil {
+		return Category{}, setErr
+	}
+	meta, _, loadErr := categorymeta.Load(path)
+	if loadErr != nil {
+		meta = categorymeta.Meta{}
+	}
+	return Category{Name: name, IsReadOnly: archived, IsArchived: archived, Path: path, Meta: meta}, nil
 }
 
 // DeleteCategory は DD-BE-003 のカテゴリ削除を行う。
@@ -67,14 +161,19 @@ func (s *Service) CreateCategory(name string, currentMode mod.Mode) (Category, e
 // エラー: 権限不足、読み取り専用、非空、削除失敗時に返す。
 // 副作用: カテゴリディレクトリを削除する。
 // 並行性: 同時削除は想定しない。
-// 不変条件: 削除対象は .json と .files を含まないことを確認する。
-// 関連DD: DD-BE-003
+// 不変条件: 削除対象は課題JSONと .files 以外のサブディレクトリを含まないことを確認する。
+// .category.json はカテゴリに付随するメタデータのためディレクトリと共に削除する。
+// アーカイブ済みカテゴリは解除するまで削除できない。
+// 関連DD: DD-BE-003, DD-CATMETA-001, DD-CATMETA-002, DD-PERM-001
 func (s *Service) DeleteCategory(name string, currentMode mod.Mode) error {
-	if currentMode != mod.ModeContractor {
-		return errors.New("permission denied")
+	if err := s.ensureCanWrite(name, currentMode); err != nil {
+		return err
 	}
 	if s.isReadOnly(name) {
-		return errors.New("read-only category")
+		return apperr.New(apperr.ErrReadOnly, "read-only category")
+	}
+	if s.isArchived(name) {
+		return apperr.New(apperr.ErrReadOnly, "archived category is read-only")
 	}
 	path := filepath.Join(s.projectRoot, name)
 	entries, err := os.ReadDir(path)
@@ -86,16 +185,18 @@ func (s *Service) DeleteCategory(name string, currentMode mod.Mode) error {
 			continue
 		}
 		if entry.IsDir() {
-			return errors.New("category not empty")
+			return apperr.New(apperr.ErrConflict, "category not empty")
 		}
-		if filepath.Ext(entry.Name()) == ".json" {
-			return errors.New("category not empty")
+		if issue.IsIssueFileName(entry.Name()) {
+			return apperr.New(apperr.ErrConflict, "category not empty")
 		}
 	}
 	removeErr := os.RemoveAll(path)
 	if removeErr != nil {
 		return fmt.Errorf("delete category: %w", removeErr)
 	}
+	// 索引はキャッシュのため、破棄に失敗しても一覧結果には影響しない。
+	_ = issueindex.Open(s.projectRoot).DropCategory(name)
 	return nil
 }
 
@@ -106,11 +207,16 @@ func (s *Service) DeleteCategory(name string, currentMode mod.Mode) error {
 // エラー: 権限不足、検証失敗、衝突、リネーム失敗時に返す。
 // 副作用: ディレクトリ移動と課題JSONの書き換えを行う。
 // 並行性: 同時更新は想定しない。
-// 不変条件: 更新後の課題JSONの Category は newName。
-// 関連DD: DD-BE-003
+// 不変条件: 更新後の課題JSONの Category は newName。.category.json はディレクトリと共に移動する。
+// アーカイブ済みカテゴリは課題JSONを書き換えることになるため拒否する。表示順とカテゴリ権限の旧名は新名へ置き換える。
+// 中断した場合に取り消せるよう、移動の前に旧名を .tmp_rename に記録し、完了または復帰後に削除する。
+// 関連DD: DD-BE-003, DD-CATMETA-001, DD-CATMETA-002, DD-PROJMETA-001, DD-PERM-001
 func (s *Service) RenameCategory(oldName, newName string, currentMode mod.Mode) (Category, error) {
-	if currentMode != mod.ModeContractor {
-		return Category{}, errors.New("permission denied")
+	if err := s.ensureCanWrite(oldName, currentMode); err != nil {
+		return Category{}, err
+	}
+	if err := s.ensureCanWrite(newName, currentMode); err != nil {
+		return Category{}, err
 	}
 	if errs := issue.ValidateCategoryName(newName); len(errs) > 0 {
 		return Category{}, errs
@@ -121,20 +227,28 @@ func (s *Service) RenameCategory(oldName, newName string, currentMode mod.Mode)
 	if s.hasTmpRenameResidue() {
 		return Category{}, errors.New("tmp_rename residue exists")
 	}
+	if s.isArchived(oldName) {
+		return Category{}, apperr.New(apperr.ErrReadOnly, "archived category is read-only")
+	}
 	oldPath := filepath.Join(s.projectRoot, oldName)
 	if _, err := os.Stat(oldPath); err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return Category{}, errors.New("category not found")
+			return Category{}, apperr.New(apperr.ErrNotFound, "category not found")
 		}
 		return Category{}, fmt.Errorf("stat category: %w", err)
 	}
 
-	tmpRoot := filepath.Join(s.projectRoot, ".tmp_rename")
+	tmpRoot := filepath.Join(s.projectRoot, tmpRenameDir)
 	tmpPath := filepath.Join(tmpRoot, newName)
 	if err := os.MkdirAll(tmpRoot, 0o750); err != nil {
 		return Category{}, fmt.Errorf("create tmp_rename: %w", err)
 	}
+	if err := s.writeRenameOrigin(newName, oldName); err != nil {
+		s.cleanupRenameResidue(newName)
+		return Category{}, err
+	}
 	if err := os.Rename(oldPath, tmpPath); err != nil {
+		s.cleanupRenameResidue(newName)
 		return Category{}, fmt.Errorf("rename category: %w", err)
 	}
 
@@ -142,6 +256,7 @@ func (s *Service) RenameCategory(oldName, newName string, currentMode mod.Mode)
 		if renameErr := os.Rename(tmpPath, oldPath); renameErr != nil {
 			return Category{}, fmt.Errorf("rollback rename failed: %w; rollback error: %s", err, renameErr.Error())
 		}
+		s.cleanupRenameResidue(newName)
 		return Category{}, err
 	}
 
@@ -149,7 +264,94 @@ func (s *Service) RenameCategory(oldName, newName string, currentMode mod.Mode)
 	if err := os.Rename(tmpPath, finalPath); err != nil {
 		return Category{}, fmt.Errorf("rename category final: %w", err)
 	}
-	return Category{Name: newName, Path: finalPath}, nil
+	s.cleanupRenameResidue(newName)
+	// リネーム自体は完了しているため、メタデータ破損や表示順の更新失敗は操作を失敗扱いにしない。
+	// 表示順に残った旧名は走査時に無視され、カテゴリは既定順に並ぶだけで済む。
+	meta, _, loadErr := categorymeta.Load(finalPath)
+	if loadErr != nil {
+		meta = categorymeta.Meta{}
+	}
+	_ = s.renameInOrder(oldName, newName)
+	if permErr := s.renameInPermissions(oldName, newName); permErr != nil {
+		// 権限の追従に失敗した場合は、制限が外れたことを呼び出し側へ知らせる。
+		return Category{Name: newName, Path: finalPath, Meta: meta}, fmt.Errorf("category renamed but permissions were not updated: %w", permErr)
+	}
+	_ = issueindex.Open(s.projectRoot).DropCategory(oldName)
+	return Category{Name: newName, Path: finalPath, Meta: meta}, nil
+}
+
+// RecoverRename は DD-BE-003 の中断されたカテゴリ名変更の完了を行う。
+// 目的: .tmp_rename 配下に残った変更途中のカテゴリを新しい名前のカテゴリとして復旧し、読み取り専用状態を解消する。
+// 入力: name は .tmp_rename 配下のディレクトリ名 (変更後のカテゴリ名)、currentMode は操作モード。
+// 出力: 復旧後の Category とエラー。
+// エラー: 権限不足、残骸が無い場合、同名カテゴリとの衝突、課題JSONの更新や移動に失敗した場合に返す。
+// 副作用: 課題JSONの Category を書き換え、ディレクトリをプロジェクトルート直下へ移動する。
+// 並行性: 同時更新は想定しない。
+// 不変条件: 課題JSONの更新は移動より先に行い、移動に失敗した場合も .tmp_rename 配下に残して再実行できるようにする。
+// 変更前の名前が分かる場合は表示順とカテゴリ権限の旧名を新名へ置き換え、分からない場合は走査時の既定の扱いに委ねる。
+// 関連DD: DD-BE-003, DD-LOAD-002, DD-PERM-001
+func (s *Service) RecoverRename(name string, currentMode mod.Mode) (Category, error) {
+	if err := s.ensureCanWrite(name, currentMode); err != nil {
+		return Category{}, err
+	}
+	if errs := issue.ValidateCategoryName(name); len(errs) > 0 {
+		return Category{}, errs
+	}
+	if !s.isReadOnly(name) {
+		return Category{}, apperr.New(apperr.ErrNotFound, "rename residue not found")
+	}
+	if err := s.ensureNoConflict(name); err != nil {
+		return Category{}, err
+	}
+	residue, err := s.inspectRenameResidue(name)
+	if err != nil {
+		return Category{}, err
+	}
+	tmpPath := filepath.Join(s.projectRoot, tmpRenameDir, name)
+	if err := s.updateIssueCategory(tmpPath, name); err != nil {
+		return Category{}, err
+	}
+	finalPath := filepath.Join(s.projectRoot, name)
+	if err := os.Rename(tmpPath, finalPath); err != nil {
+		return Category{}, fmt.Errorf("rename category final: %w", err)
+	}
+	s.cleanupRenameResidue(name)
+	meta, _, loadErr := categorymeta.Load(finalPath)
+	if loadErr != nil {
+		meta = categorymeta.Meta{}
+	}
+	category := Category{Name: name, Path: finalPath, Meta: meta}
+	if original := residue.OriginalName; original != "" {
+		_ = s.renameInOrder(original, name)
+		_ = issueindex.Open(s.projectRoot).DropCategory(original)
+		if permErr := s.renameInPermissions(original, name); permErr != nil {
+			return category, fmt.Errorf("category renamed but permissions were not updated: %w", permErr)
+		}
+	}
+	return category, nil
+}
+
+// categoryPath は DD-CATMETA-001 の操作対象カテゴリのパスを解決する。
+// 読み取り専用カテゴリは .tmp_rename 配下を指す。
+func (s *Service) categoryPath(name string) (string, error) {
+	if errs := issue.ValidateCategoryName(name); len(errs) > 0 {
+		return "", errs
+	}
+	path := filepath.Join(s.projectRoot, name)
+	if s.isReadOnly(name) {
+		path = filepath.Join(s.projectRoot, tmpRenameDir, name)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", apperr.New(apperr.ErrNotFound, "category not found")
+		}
+		return "", fmt.Errorf("stat category: %w", err)
+	}
+	if !info.IsDir() {
+		return "", apperr.New(apperr.ErrNotFound, "category not found")
+	}
+	return path, nil
 }
 
 // ensureNoConflict は DD-BE-003 の大小文字違いを含む重複を防ぐ。
@@ -164,7 +366,7 @@ func (s *Service) ensureNoConflict(name string) error {
 		}
 		other := entry.Name()
 		if strings.EqualFold(other, name) {
-			return errors.New("category name conflict")
+			return apperr.New(apperr.ErrConflict, "category name conflict")
 		}
 	}
 	return nil
@@ -172,7 +374,7 @@ func (s *Service) ensureNoConflict(name string) error {
 
 // hasTmpRenameResidue は DD-BE-003 の .tmp_rename 残骸検出を行う。
 func (s *Service) hasTmpRenameResidue() bool {
-	tmpPath := filepath.Join(s.projectRoot, ".tmp_rename")
+	tmpPath := filepath.Join(s.projectRoot, tmpRenameDir)
 	entries, err := os.ReadDir(tmpPath)
 	if err != nil {
 		return false
@@ -187,7 +389,7 @@ func (s *Service) hasTmpRenameResidue() bool {
 
 // isReadOnly は DD-LOAD-002 の読み取り専用カテゴリ判定を行う。
 func (s *Service) isReadOnly(name string) bool {
-	path := filepath.Join(s.projectRoot, ".tmp_rename", name)
+	path := filepath.Join(s.projectRoot, tmpRenameDir, name)
 	info, err := os.Stat(path)
 	if err != nil {
 		return false
@@ -195,25 +397,35 @@ func (s *Service) isReadOnly(name string) bool {
 	return info.IsDir()
 }
 
+// isArchived は DD-CATMETA-002 のアーカイブ済みカテゴリ判定を行う。
+func (s *Service) isArchived(name string) bool {
+	return categorymeta.IsArchived(filepath.Join(s.projectRoot, name))
+}
+
 // updateIssueCategory は DD-BE-003 のカテゴリ名変更に伴う課題更新を行う。
 // 目的: カテゴリ配下の課題JSONに新カテゴリ名を反映する。
 // 入力: categoryPath は変更対象のカテゴリパス、newName は新カテゴリ名。
 // 出力: 成功時は nil、失敗時はエラー。
 // エラー: 読み取り・パース・書き込み失敗時に返す。
-// 副作用: 課題JSONを書き換える。
+// 副作用: 課題JSONを書き換える。バックアップの世代数を設定した場合は置き換える前の内容を .bak として残す。
 // 並行性: 同時書き込みは想定しない。
-// 不変条件: 対象JSONの Category フィールドは newName に統一する。
-// 関連DD: DD-BE-003
+// 不変条件: 対象JSONの Category フィールドは newName に統一する。改行コードとインデント幅はプロジェクトの設定に従う。
+// 他のツールが追加した未知の項目は保持し、設定した場合はその並びを DD-DATA-007 に従って保つ。
+// 関連DD: DD-BE-003, DD-DATA-006, DD-DATA-007, DD-PERSIST-005
 func (s *Service) updateIssueCategory(categoryPath, newName string) error {
 	entries, err := os.ReadDir(categoryPath)
 	if err != nil {
 		return fmt.Errorf("read category: %w", err)
 	}
+	format, err := projectmeta.LoadFormat(s.projectRoot)
+	if err != nil {
+		return err
+	}
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
-		if filepath.Ext(entry.Name()) != ".json" {
+		if !issue.IsIssueFileName(entry.Name()) {
 			continue
 		}
 		path := filepath.Join(categoryPath, entry.Name())
@@ -222,18 +434,101 @@ func (s *Service) updateIssueCategory(categoryPath, newName string) error {
 		if readErr != nil {
 			return fmt.Errorf("read issue: %w", readErr)
 		}
-		var parsed issue.Issue
-		if unmarshalErr := json.Unmarshal(data, &parsed); unmarshalErr != nil {
-			return fmt.Errorf("parse issue: %w", unmarshalErr)
+		// 未知の項目や数値の表記を保つため、課題の構造体を経由せずに書き換える。
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var parsed map[string]any
+		if decodeErr := decoder.Decode(&parsed); decodeErr != nil {
+			return fmt.Errorf("parse issue: %w", decodeErr)
+		}
+		if parsed == nil {
+			return errors.New("parse issue: not a JSON object")
 		}
-		parsed.Category = newName
-		updated, marshalErr := jsonfmt.MarshalIssue(parsed)
+		parsed["category"] = newName
+		updated, marshalErr := format.RewriteIssue(parsed, data)
 		if marshalErr != nil {
 			return fmt.Errorf("marshal issue: %w", marshalErr)
 		}
-		if writeErr := atomicwrite.WriteFile(path, updated); writeErr != nil {
+		if writeErr := atomicwrite.WriteFileWithBackup(path, updated); writeErr != nil {
 			return fmt.Errorf("write issue: %w", writeErr)
 		}
 	}
 	return nil
 }
+
+// ReorderCategories は DD-PROJMETA-001 のカテゴリ表示順の保存を行う。
+// 目的: 利用者が指定した並びをプロジェクト単位の表示順として保存する。
+// 入力: names は表示順に並べたカテゴリ名、currentMode は操作モード。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: 権限不足、名前の重複、存在しないカテゴリ、保存失敗時に返す。
+// 副作用: .ratta/category_order.json を作成または置換する。
+// 並行性: 同時保存は想定しない。
+// 不変条件: 指定されなかったカテゴリは表示順ファイルに含めず、走査時の既定順に委ねる。
+// 関連DD: DD-PROJMETA-001
+func (s *Service) ReorderCategories(names []string, currentMode mod.Mode) error {
+	if currentMode != mod.ModeContractor {
+		return apperr.New(apperr.ErrPermission, "permission denied")
+	}
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		key := strings.ToLower(name)
+		if _, ok := seen[key]; ok {
+			return errors.New("duplicate category in order")
+		}
+		seen[key] = struct{}{}
+		if _, err := s.categoryPath(name); err != nil {
+			return err
+		}
+	}
+	return projectmeta.SaveCategoryOrder(s.projectRoot, names)
+}
+
+// ensureCanWrite は DD-BE-003/DD-PERM-001 のカテゴリ操作の権限を確認する。
+// カテゴリ操作は Contractor のみ行え、カテゴリ権限で Contractor に書き込みを許していないカテゴリも変更できない。
+func (s *Service) ensureCanWrite(name string, currentMode mod.Mode) error {
+	if currentMode != mod.ModeContractor {
+		return apperr.New(apperr.ErrPermission, "permission denied")
+	}
+	permissions, err := projectmeta.LoadPermissions(s.projectRoot)
+	if err != nil {
+		return err
+	}
+	if writers, _ := permissions.Writers(name); !mod.CanWriteCategory(writers, currentMode) {
+		return apperr.Errorf(apperr.ErrPermission, "permission denied: category %q is not writable in %s mode", name, currentMode)
+	}
+	return nil
+}
+
+// renameInPermissions は DD-PERM-001 のカテゴリ権限に含まれるカテゴリ名を追従させる。
+func (s *Service) renameInPermissions(oldName, newName string) error {
+	permissions, err := projectmeta.LoadPermissions(s.projectRoot)
+	if err != nil {
+		return err
+	}
+	permission, ok := permissions.Categories[oldName]
+	if !ok {
+		return nil
+	}
+	delete(permissions.Categories, oldName)
+	permissions.Categories[newName] = permission
+	return projectmeta.SavePermissions(s.projectRoot, permissions)
+}
+
+// renameInOrder は DD-PROJMETA-001 の表示順に含まれるカテゴリ名を追従させる。
+func (s *Service) renameInOrder(oldName, newName string) error {
+	order, err := projectmeta.LoadCategoryOrder(s.projectRoot)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for i, name := range order {
+		if name == oldName {
+			order[i] = newName
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return projectmeta.SaveCategoryOrder(s.projectRoot, order)
+}
@@ -3,12 +3,16 @@ package categoryops
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"ratta/internal/domain/apperr"
 	"ratta/internal/domain/issue"
+	"ratta/internal/infra/categorymeta"
 	"ratta/internal/infra/jsonfmt"
+	"ratta/internal/infra/projectmeta"
 
 	mod "ratta/internal/domain/mode"
 )
@@ -100,6 +104,34 @@ func TestCreateCategory_PermissionDenied(t *testing.T) {
 	}
 }
 
+func TestObserverMode_RejectsCategoryChanges(t *testing.T) {
+	// Observer ではカテゴリの作成・名前変更・削除・メタデータ更新・並べ替えが権限不足となることを確認する。
+	root := t.TempDir()
+	service := NewService(root)
+	if _, err := service.CreateCategory("cat", mod.ModeContractor); err != nil {
+		t.Fatalf("CreateCategory error: %v", err)
+	}
+
+	if _, err := service.CreateCategory("other", mod.ModeObserver); err == nil {
+		t.Fatal("expected create to be denied")
+	}
+	if _, err := service.RenameCategory("cat", "renamed", mod.ModeObserver); err == nil {
+		t.Fatal("expected rename to be denied")
+	}
+	if err := service.DeleteCategory("cat", mod.ModeObserver); err == nil {
+		t.Fatal("expected delete to be denied")
+	}
+	if _, err := service.UpdateCategoryMeta("cat", categorymeta.Meta{}, mod.ModeObserver); err == nil {
+		t.Fatal("expected meta update to be denied")
+	}
+	if err := service.ReorderCategories([]string{"cat"}, mod.ModeObserver); err == nil {
+		t.Fatal("expected reorder to be denied")
+	}
+	if _, err := os.Stat(filepath.Join(root, "cat")); err != nil {
+		t.Fatalf("expected category to remain: %v", err)
+	}
+}
+
 func TestCreateCategory_InvalidName(t *testing.T) {
 	// 禁止文字を含むカテゴリ名は拒否されることを確認する。
 	root := t.TempDir()
@@ -168,11 +200,11 @@ func TestRenameCategory_TmpResidue(t *testing.T) {
 }
 
 func TestRenameCategory_NotFound(t *testing.T) {
-	// 対象カテゴリが存在しない場合にエラーとなることを確認する。
+	// 対象カテゴリが存在しない場合に ErrNotFound の種別を持つエラーとなることを確認する。
 	root := t.TempDir()
 	service := NewService(root)
 
-	if _, err := service.RenameCategory("missing", "new", mod.ModeContractor); err == nil {
+	if _, err := service.RenameCategory("missing", "new", mod.ModeContractor); !errors.Is(err, apperr.ErrNotFound) {
 		t.Fatal("expected not found error")
 	}
 }
@@ -202,7 +234,7 @@ func TestRenameCategory_RollbackOnParseError(t *testing.T) {
 }
 
 func TestDeleteCategory_NotEmpty(t *testing.T) {
-	// JSONファイルが存在する場合に削除できないことを確認する。
+	// JSONファイルが存在する場合に ErrConflict の種別を持つエラーとなり削除できないことを確認する。
 	root := t.TempDir()
 	category := "cat"
 	if err := os.MkdirAll(filepath.Join(root, category), 0o750); err != nil {
@@ -213,16 +245,16 @@ func TestDeleteCategory_NotEmpty(t *testing.T) {
 	}
 
 	service := NewService(root)
-	if err := service.DeleteCategory(category, mod.ModeContractor); err == nil {
+	if err := service.DeleteCategory(category, mod.ModeContractor); !errors.Is(err, apperr.ErrConflict) {
 		t.Fatal("expected not empty error")
 	}
 }
 
 func TestRenameCategory_PermissionDenied(t *testing.T) {
-	// Vendor モードではリネームできないことを確認する。
+	// Vendor モードではリネームできず、ErrPermission の種別を持つエラーとなることを確認する。
 	root := t.TempDir()
 	service := NewService(root)
-	if _, err := service.RenameCategory("old", "new", mod.ModeVendor); err == nil {
+	if _, err := service.RenameCategory("old", "new", mod.ModeVendor); !errors.Is(err, apperr.ErrPermission) {
 		t.Fatal("expected permission error")
 	}
 }
@@ -241,3 +273,256 @@ func TestRenameCategory_NameConflict(t *testing.T) {
 		t.Fatal("expected name conflict error")
 	}
 }
+
+func TestUpdateCategoryMeta_SavesAndSurvivesRename(t *testing.T) {
+	// メタデータを保存でき、リネーム後も同じ内容が引き継がれることを確認する。
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "old"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	service := NewService(root)
+	updated, err := service.UpdateCategoryMeta("old", categorymeta.Meta{
+		Description: "desc",
+		Color:       "#A0B0C0",
+		SortWeight:  3,
+	}, mod.ModeContractor)
+	if err != nil {
+		t.Fatalf("UpdateCategoryMeta error: %v", err)
+	}
+	if updated.Meta.Description != "desc" || updated.Meta.FormatVersion != 1 {
+		t.Fatalf("unexpected meta: %+v", updated.Meta)
+	}
+
+	renamed, err := service.RenameCategory("old", "new", mod.ModeContractor)
+	if err != nil {
+		t.Fatalf("RenameCategory error: %v", err)
+	}
+	if renamed.Meta.Color != "#A0B0C0" || renamed.Meta.SortWeight != 3 {
+		t.Fatalf("unexpected meta after rename: %+v", renamed.Meta)
+	}
+	meta, err := service.GetCategoryMeta("new")
+	if err != nil {
+		t.Fatalf("GetCategoryMeta error: %v", err)
+	}
+	if meta.Description != "desc" {
+		t.Fatalf("unexpected meta: %+v", meta)
+	}
+}
+
+func TestUpdateCategoryMeta_RejectsVendorAndInvalidColor(t *testing.T) {
+	// Vendor からの更新と不正な色指定が拒否されることを確認する。
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "cat"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	service := NewService(root)
+	if _, err := service.UpdateCategoryMeta("cat", categorymeta.Meta{}, mod.ModeVendor); err == nil {
+		t.Fatal("expected permission error")
+	}
+	if _, err := service.UpdateCategoryMeta("cat", categorymeta.Meta{Color: "red"}, mod.ModeContractor); err == nil {
+		t.Fatal("expected validation error")
+	}
+	if _, statErr := os.Stat(filepath.Join(root, "cat", categorymeta.FileName)); !os.IsNotExist(statErr) {
+		t.Fatalf("expected no meta file, err=%v", statErr)
+	}
+}
+
+func TestDeleteCategory_IgnoresCategoryMeta(t *testing.T) {
+	// .category.json のみが残るカテゴリは空とみなして削除できることを確認する。
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "cat"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "cat", categorymeta.FileName), []byte("{}"), 0o600); err != nil {
+		t.Fatalf("write meta: %v", err)
+	}
+	service := NewService(root)
+	if err := service.DeleteCategory("cat", mod.ModeContractor); err != nil {
+		t.Fatalf("DeleteCategory error: %v", err)
+	}
+}
+
+func TestSetCategoryArchived_BlocksChangesUntilUnarchived(t *testing.T) {
+	// アーカイブ中はリネーム・削除・メタデータ更新を拒否し、解除後は削除できることを確認する。
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "cat"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	service := NewService(root)
+	if _, err := service.SetCategoryArchived("cat", true, mod.ModeVendor); err == nil {
+		t.Fatal("expected permission error")
+	}
+	archived, err := service.SetCategoryArchived("cat", true, mod.ModeContractor)
+	if err != nil {
+		t.Fatalf("SetCategoryArchived error: %v", err)
+	}
+	if !archived.IsArchived || !archived.IsReadOnly {
+		t.Fatalf("unexpected category: %+v", archived)
+	}
+	if _, renameErr := service.RenameCategory("cat", "other", mod.ModeContractor); renameErr == nil {
+		t.Fatal("expected rename to be rejected")
+	}
+	if _, metaErr := service.UpdateCategoryMeta("cat", categorymeta.Meta{}, mod.ModeContractor); metaErr == nil {
+		t.Fatal("expected meta update to be rejected")
+	}
+	if deleteErr := service.DeleteCategory("cat", mod.ModeContractor); deleteErr == nil {
+		t.Fatal("expected delete to be rejected")
+	}
+
+	if _, unarchiveErr := service.SetCategoryArchived("cat", false, mod.ModeContractor); unarchiveErr != nil {
+		t.Fatalf("unarchive error: %v", unarchiveErr)
+	}
+	if deleteErr := service.DeleteCategory("cat", mod.ModeContractor); deleteErr != nil {
+		t.Fatalf("DeleteCategory error: %v", deleteErr)
+	}
+}
+
+func TestReorderCategories_SavesAndFollowsRename(t *testing.T) {
+	// 表示順を保存でき、リネーム時に表示順の名前も追従することを確認する。
+	root := t.TempDir()
+	for _, name := range []string{"a", "b"} {
+		if err := os.MkdirAll(filepath.Join(root, name), 0o750); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	service := NewService(root)
+	if err := service.ReorderCategories([]string{"b", "a"}, mod.ModeVendor); err == nil {
+		t.Fatal("expected permission error")
+	}
+	if err := service.ReorderCategories([]string{"b", "B"}, mod.ModeContractor); err == nil {
+		t.Fatal("expected duplicate error")
+	}
+	if err := service.ReorderCategories([]string{"b", "missing"}, mod.ModeContractor); err == nil {
+		t.Fatal("expected missing category error")
+	}
+	if err := service.ReorderCategories([]string{"b", "a"}, mod.ModeContractor); err != nil {
+		t.Fatalf("ReorderCategories error: %v", err)
+	}
+	if _, err := service.RenameCategory("b", "c", mod.ModeContractor); err != nil {
+		t.Fatalf("RenameCategory error: %v", err)
+	}
+	order, err := projectmeta.LoadCategoryOrder(root)
+	if err != nil {
+		t.Fatalf("LoadCategoryOrder error: %v", err)
+	}
+	if len(order) != 2 || order[0] != "c" || order[1] != "a" {
+		t.Fatalf("unexpected order: %v", order)
+	}
+}
+
+func TestRecoverRename_CompletesInterruptedRename(t *testing.T) {
+	// .tmp_rename 配下の変更途中のカテゴリがルート直下へ移動し、課題の category が更新されることを確認する。
+	root := t.TempDir()
+	tmpPath := filepath.Join(root, ".tmp_rename", "new")
+	if err := os.MkdirAll(tmpPath, 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpPath, "abc123DEF.json"), []byte(`{"issue_id":"abc123DEF","category":"old"}`), 0o600); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+
+	service := NewService(root)
+	if _, err := service.RecoverRename("new", mod.ModeVendor); err == nil {
+		t.Fatal("expected permission error")
+	}
+	category, err := service.RecoverRename("new", mod.ModeContractor)
+	if err != nil {
+		t.Fatalf("RecoverRename error: %v", err)
+	}
+	if category.Path != filepath.Join(root, "new") {
+		t.Fatalf("unexpected path: %s", category.Path)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "new", "abc123DEF.json"))
+	if err != nil {
+		t.Fatalf("read issue: %v", err)
+	}
+	var parsed issue.Issue
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if parsed.Category != "new" {
+		t.Fatalf("unexpected category: %s", parsed.Category)
+	}
+	if _, err := service.RecoverRename("new", mod.ModeContractor); err == nil {
+		t.Fatal("expected not found error after recovery")
+	}
+}
+
+func TestCategoryPermissions_RestrictCategoryChanges(t *testing.T) {
+	// Contractor に書き込みを許していないカテゴリは変更できず、許しているカテゴリの権限は名前変更に追従することを確認する。
+	root := t.TempDir()
+	for _, name := range []string{"vendor-only", "internal"} {
+		if err := os.MkdirAll(filepath.Join(root, name), 0o750); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	permissions := projectmeta.Permissions{Categories: map[string]projectmeta.CategoryPermission{
+		"vendor-only": {Writers: []mod.Mode{mod.ModeVendor}},
+		"internal":    {Writers: []mod.Mode{mod.ModeContractor}},
+	}}
+	if err := projectmeta.SavePermissions(root, permissions); err != nil {
+		t.Fatalf("SavePermissions error: %v", err)
+	}
+	service := NewService(root)
+
+	if err := service.DeleteCategory("vendor-only", mod.ModeContractor); err == nil {
+		t.Fatal("expected permission error for vendor-only category")
+	}
+	if _, err := service.RenameCategory("internal", "vendor-only-2", mod.ModeContractor); err != nil {
+		t.Fatalf("RenameCategory error: %v", err)
+	}
+	loaded, err := projectmeta.LoadPermissions(root)
+	if err != nil {
+		t.Fatalf("LoadPermissions error: %v", err)
+	}
+	if _, ok := loaded.Writers("internal"); ok {
+		t.Fatal("expected old name to be removed from permissions")
+	}
+	if writers, ok := loaded.Writers("vendor-only-2"); !ok || len(writers) != 1 || writers[0] != mod.ModeContractor {
+		t.Fatalf("expected permission to follow rename, got %v ok=%v", writers, ok)
+	}
+	if _, err := service.CreateCategory("vendor-only", mod.ModeContractor); err == nil {
+		t.Fatal("expected permission error before conflict check")
+	}
+}
+
+func TestRenameCategory_PreservesUnknownKeysAndOrder(t *testing.T) {
+	// 設定した場合、リネームに伴う課題の書き換えで未知のキーを保持し、その元の並びを保つことを確認する。
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "old"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.MkdirAll(projectmeta.Dir(root), 0o750); err != nil {
+		t.Fatalf("mkdir meta: %v", err)
+	}
+	settings := `{"format_version": 1, "line_ending": "lf", "indent_width": 2, "preserve_unknown_key_order": true}`
+	if err := os.WriteFile(projectmeta.FormatPath(root), []byte(settings), 0o600); err != nil {
+		t.Fatalf("write format settings: %v", err)
+	}
+	original := `{"version": 1, "issue_id": "abc123DEF", "category": "old", "x_tool": "t", "comments": [], "b_ref": 12345678901234567890}`
+	if err := os.WriteFile(filepath.Join(root, "old", "abc123DEF.json"), []byte(original), 0o600); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+
+	service := NewService(root)
+	if _, err := service.RenameCategory("old", "new", mod.ModeContractor); err != nil {
+		t.Fatalf("RenameCategory error: %v", err)
+	}
+
+	// #nosec G304 -- テスト用一時ディレクトリ配下の固定ファイルを読むため安全。
+	updated, err := os.ReadFile(filepath.Join(root, "new", "abc123DEF.json"))
+	if err != nil {
+		t.Fatalf("read updated issue: %v", err)
+	}
+	expected := "{\n" +
+		"  \"version\": 1,\n" +
+		"  \"issue_id\": \"abc123DEF\",\n" +
+		"  \"category\": \"new\",\n" +
+		"  \"comments\": [],\n" +
+		"  \"x_tool\": \"t\",\n" +
+		"  \"b_ref\": 12345678901234567890\n" +
+		"}\n"
+	if string(updated) != expected {
+		t.Fatalf("unexpected issue JSON:\n%s", updated)
+	}
+}
@@ -0,0 +1,210 @@
+// renameresidue.go は DD-BE-003 の中断されたカテゴリ名変更の残骸 (.tmp_rename) の確認・完了・取り消しを担い、
+// 残骸の検出結果の通知や画面表示は扱わない。
+package categoryops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/atomicwrite"
+	"ratta/internal/infra/categorymeta"
+	"ratta/internal/infra/issueindex"
+
+	mod "ratta/internal/domain/mode"
+)
+
+const (
+	tmpRenameDir = ".tmp_rename"
+	// renameOriginSuffix は DD-BE-003 の変更前の名前を記録するファイルの接尾辞を表す。
+	// .tmp_rename/<変更後の名前>.origin.json に置き、カテゴリのディレクトリには含めない。
+	renameOriginSuffix = ".origin.json"
+)
+
+// renameOrigin は DD-BE-003 のカテゴリ名変更の開始時に記録する変更前の名前を表す。
+type renameOrigin struct {
+	OriginalName string `json:"original_name"`
+}
+
+// RenameResidue は DD-BE-003 の .tmp_rename 配下に残った変更途中のカテゴリを表す。
+// Name は変更後の名前、OriginalName は変更前の名前を表し、記録が無く課題JSONからも推定できない場合は空とする。
+// UpdatedIssues は category が Name に更新済みの課題の件数を表す。
+// CanComplete・CanRollback はそれぞれ Name・OriginalName のカテゴリがルート直下に無く、移動できることを表す。
+type RenameResidue struct {
+	Name          string
+	OriginalName  string
+	Issues        int
+	UpdatedIssues int
+	CanComplete   bool
+	CanRollback   bool
+}
+
+// RenameResidues は DD-BE-003 の中断されたカテゴリ名変更の残骸を確認する。
+// 目的: 完了と取り消しのどちらで復旧するかを利用者が判断できるよう、残骸の状態を示す。
+// 入力: なし。
+// 出力: 名前順の RenameResidue の配列とエラー。残骸が無い場合は空配列。
+// エラー: .tmp_rename や残骸の読み取りに失敗した場合に返す。
+// 副作用: なし。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: 変更前の名前は記録を優先し、記録が無い場合は未更新の課題JSONの category が1種類のときのみ推定する。
+// 関連DD: DD-BE-003, DD-LOAD-002
+func (s *Service) RenameResidues() ([]RenameResidue, error) {
+	entries, err := os.ReadDir(filepath.Join(s.projectRoot, tmpRenameDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return []RenameResidue{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read .tmp_rename: %w", err)
+	}
+	residues := []RenameResidue{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		residue, inspectErr := s.inspectRenameResidue(entry.Name())
+		if inspectErr != nil {
+			return nil, inspectErr
+		}
+		residues = append(residues, residue)
+	}
+	sort.Slice(residues, func(i, j int) bool { return residues[i].Name < residues[j].Name })
+	return residues, nil
+}
+
+// RollbackRename は DD-BE-003 の中断されたカテゴリ名変更の取り消しを行う。
+// 目的: .tmp_rename 配下に残った変更途中のカテゴリを変更前の名前へ戻し、読み取り専用状態を解消する。
+// 入力: name は .tmp_rename 配下のディレクトリ名 (変更後の名前)、currentMode は操作モード。
+// 出力: 戻した後の Category とエラー。
+// エラー: 権限不足、残骸が無い・変更前の名前が分からない場合 (not found)、同名カテゴリとの衝突、課題JSONの更新や移動に失敗した場合に返す。
+// 副作用: 課題JSONの Category を変更前の名前へ書き戻し、ディレクトリをプロジェクトルート直下へ移動して記録と空の .tmp_rename を削除する。
+// 並行性: 同時更新は想定しない。
+// 不変条件: 課題JSONの更新は移動より先に行い、移動に失敗した場合も .tmp_rename 配下に残して再実行できるようにする。
+// 関連DD: DD-BE-003, DD-LOAD-002, DD-PERM-001
+func (s *Service) RollbackRename(name string, currentMode mod.Mode) (Category, error) {
+	if err := s.ensureCanWrite(name, currentMode); err != nil {
+		return Category{}, err
+	}
+	if errs := issue.ValidateCategoryName(name); len(errs) > 0 {
+		return Category{}, errs
+	}
+	if !s.isReadOnly(name) {
+		return Category{}, apperr.New(apperr.ErrNotFound, "rename residue not found")
+	}
+	residue, err := s.inspectRenameResidue(name)
+	if err != nil {
+		return Category{}, err
+	}
+	original := residue.OriginalName
+	if original == "" {
+		return Category{}, apperr.New(apperr.ErrNotFound, "original category name not found")
+	}
+	if err := s.ensureCanWrite(original, currentMode); err != nil {
+		return Category{}, err
+	}
+	if err := s.ensureNoConflict(original); err != nil {
+		return Category{}, err
+	}
+	tmpPath := filepath.Join(s.projectRoot, tmpRenameDir, name)
+	if err := s.updateIssueCategory(tmpPath, original); err != nil {
+		return Category{}, err
+	}
+	finalPath := filepath.Join(s.projectRoot, original)
+	if err := os.Rename(tmpPath, finalPath); err != nil {
+		return Category{}, fmt.Errorf("rename category final: %w", err)
+	}
+	s.cleanupRenameResidue(name)
+	_ = issueindex.Open(s.projectRoot).DropCategory(name)
+	meta, _, loadErr := categorymeta.Load(finalPath)
+	if loadErr != nil {
+		meta = categorymeta.Meta{}
+	}
+	return Category{Name: original, Path: finalPath, Meta: meta}, nil
+}
+
+// inspectRenameResidue は DD-BE-003 の1件の残骸の課題件数と変更前の名前を調べる。
+func (s *Service) inspectRenameResidue(name string) (RenameResidue, error) {
+	tmpPath := filepath.Join(s.projectRoot, tmpRenameDir, name)
+	entries, err := os.ReadDir(tmpPath)
+	if err != nil {
+		return RenameResidue{}, fmt.Errorf("read rename residue: %w", err)
+	}
+	residue := RenameResidue{Name: name}
+	others := map[string]struct{}{}
+	for _, entry := range entries {
+		if entry.IsDir() || !issue.IsIssueFileName(entry.Name()) {
+			continue
+		}
+		residue.Issues++
+		// #nosec G304 -- .tmp_rename 配下の列挙結果のみを利用するため安全。
+		data, readErr := os.ReadFile(filepath.Join(tmpPath, entry.Name()))
+		if readErr != nil {
+			return RenameResidue{}, fmt.Errorf("read issue: %w", readErr)
+		}
+		var parsed struct {
+			Category string `json:"category"`
+		}
+		// 解析できない課題は名前の推定に用いず、完了・取り消しの際の書き換えで報告させる。
+		if json.Unmarshal(data, &parsed) != nil {
+			continue
+		}
+		if parsed.Category == name {
+			residue.UpdatedIssues++
+			continue
+		}
+		others[parsed.Category] = struct{}{}
+	}
+	residue.OriginalName = s.readRenameOrigin(name)
+	if residue.OriginalName == "" && len(others) == 1 {
+		for other := range others {
+			if len(issue.ValidateCategoryName(other)) == 0 {
+				residue.OriginalName = other
+			}
+		}
+	}
+	residue.CanComplete = s.ensureNoConflict(name) == nil
+	residue.CanRollback = residue.OriginalName != "" && s.ensureNoConflict(residue.OriginalName) == nil
+	return residue, nil
+}
+
+// renameOriginPath は DD-BE-003 の変更前の名前を記録するファイルのパスを返す。
+func (s *Service) renameOriginPath(name string) string {
+	return filepath.Join(s.projectRoot, tmpRenameDir, name+renameOriginSuffix)
+}
+
+// writeRenameOrigin は DD-BE-003 のカテゴリ名変更の開始前に変更前の名前を記録する。
+func (s *Service) writeRenameOrigin(newName, oldName string) error {
+	data, err := json.Marshal(renameOrigin{OriginalName: oldName})
+	if err != nil {
+		return fmt.Errorf("marshal rename origin: %w", err)
+	}
+	if err := atomicwrite.WriteFile(s.renameOriginPath(newName), data); err != nil {
+		return fmt.Errorf("write rename origin: %w", err)
+	}
+	return nil
+}
+
+// readRenameOrigin は DD-BE-003 の記録した変更前の名前を返す。記録が無い・読めない場合は空文字を返す。
+func (s *Service) readRenameOrigin(name string) string {
+	// #nosec G304 -- .tmp_rename 配下の固定の接尾辞のファイルのみを読む。
+	data, err := os.ReadFile(s.renameOriginPath(name))
+	if err != nil {
+		return ""
+	}
+	var origin renameOrigin
+	if json.Unmarshal(data, &origin) != nil || len(issue.ValidateCategoryName(origin.OriginalName)) > 0 {
+		return ""
+	}
+	return origin.OriginalName
+}
+
+// cleanupRenameResidue は DD-BE-003 の変更前の名前の記録を削除し、空になった .tmp_rename を削除する。
+// 他の残骸が残っている場合は .tmp_rename を残す。
+func (s *Service) cleanupRenameResidue(name string) {
+	_ = os.Remove(s.renameOriginPath(name))
+	_ = os.Remove(filepath.Join(s.projectRoot, tmpRenameDir))
+}
@@ -0,0 +1,158 @@
+// renameresidue_test.go は中断されたカテゴリ名変更の残骸の確認・完了・取り消しのテストを行い、UI の統合動作は扱わない。
+package categoryops
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/projectmeta"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// writeResidue はテスト用に .tmp_rename/<name> へ category を指定した課題を置き、origin があれば変更前の名前を記録する。
+func writeResidue(t *testing.T, root, name, origin string, categories map[string]string) {
+	t.Helper()
+	tmpPath := filepath.Join(root, ".tmp_rename", name)
+	if err := os.MkdirAll(tmpPath, 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for id, category := range categories {
+		data := []byte(`{"issue_id":"` + id + `","category":"` + category + `"}`)
+		if err := os.WriteFile(filepath.Join(tmpPath, id+".json"), data, 0o600); err != nil {
+			t.Fatalf("write issue: %v", err)
+		}
+	}
+	if origin != "" {
+		data := []byte(`{"original_name":"` + origin + `"}`)
+		if err := os.WriteFile(filepath.Join(root, ".tmp_rename", name+".origin.json"), data, 0o600); err != nil {
+			t.Fatalf("write origin: %v", err)
+		}
+	}
+}
+
+// readCategory はテスト用に課題JSONの category を返す。
+func readCategory(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read issue: %v", err)
+	}
+	var parsed issue.Issue
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return parsed.Category
+}
+
+func TestRenameResidues_ReportsOriginAndProgress(t *testing.T) {
+	// 記録した変更前の名前を優先し、記録が無い場合は未更新の課題から推定して更新済みの件数とともに返すことを確認する。
+	root := t.TempDir()
+	writeResidue(t, root, "new", "old", map[string]string{"abc123DEF": "new", "abc123DEG": "old"})
+	writeResidue(t, root, "other", "", map[string]string{"abc123DEH": "before"})
+
+	residues, err := NewService(root).RenameResidues()
+	if err != nil {
+		t.Fatalf("RenameResidues error: %v", err)
+	}
+	if len(residues) != 2 {
+		t.Fatalf("unexpected residues: %+v", residues)
+	}
+	if got := residues[0]; got.Name != "new" || got.OriginalName != "old" || got.Issues != 2 || got.UpdatedIssues != 1 || !got.CanComplete || !got.CanRollback {
+		t.Fatalf("unexpected residue: %+v", got)
+	}
+	if got := residues[1]; got.Name != "other" || got.OriginalName != "before" || got.UpdatedIssues != 0 {
+		t.Fatalf("unexpected inferred residue: %+v", got)
+	}
+
+	if err := os.MkdirAll(filepath.Join(root, "Old"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	residues, err = NewService(root).RenameResidues()
+	if err != nil || residues[0].CanRollback {
+		t.Fatalf("expected rollback to be blocked by a conflicting category: %+v err=%v", residues, err)
+	}
+}
+
+func TestRollbackRename_RestoresOriginalName(t *testing.T) {
+	// 取り消しで課題の category とディレクトリが変更前の名前へ戻り、表示順は保たれ .tmp_rename が片付くことを確認する。
+	root := t.TempDir()
+	writeResidue(t, root, "new", "old", map[string]string{"abc123DEF": "new", "abc123DEG": "old"})
+	if err := projectmeta.SaveCategoryOrder(root, []string{"old"}); err != nil {
+		t.Fatalf("SaveCategoryOrder error: %v", err)
+	}
+
+	service := NewService(root)
+	if _, err := service.RollbackRename("new", mod.ModeVendor); err == nil {
+		t.Fatal("expected permission error")
+	}
+	category, err := service.RollbackRename("new", mod.ModeContractor)
+	if err != nil {
+		t.Fatalf("RollbackRename error: %v", err)
+	}
+	if category.Name != "old" || category.Path != filepath.Join(root, "old") {
+		t.Fatalf("unexpected category: %+v", category)
+	}
+	for _, id := range []string{"abc123DEF", "abc123DEG"} {
+		if got := readCategory(t, filepath.Join(root, "old", id+".json")); got != "old" {
+			t.Fatalf("unexpected category of %s: %s", id, got)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, ".tmp_rename")); !os.IsNotExist(err) {
+		t.Fatalf("expected .tmp_rename to be removed, err=%v", err)
+	}
+	if order, _ := projectmeta.LoadCategoryOrder(root); len(order) != 1 || order[0] != "old" {
+		t.Fatalf("unexpected order: %v", order)
+	}
+}
+
+func TestRollbackRename_RequiresOriginalName(t *testing.T) {
+	// 変更前の名前が分からない残骸は取り消せず、完了のみ行えることを確認する。
+	root := t.TempDir()
+	writeResidue(t, root, "new", "", map[string]string{"abc123DEF": "new"})
+
+	service := NewService(root)
+	if _, err := service.RollbackRename("new", mod.ModeContractor); err == nil {
+		t.Fatal("expected unknown original name error")
+	}
+	if _, err := service.RecoverRename("new", mod.ModeContractor); err != nil {
+		t.Fatalf("RecoverRename error: %v", err)
+	}
+}
+
+func TestRecoverRename_FollowsRecordedOrigin(t *testing.T) {
+	// 変更前の名前を記録している場合、完了で表示順の旧名が新名へ置き換わり、記録と .tmp_rename が片付くことを確認する。
+	root := t.TempDir()
+	writeResidue(t, root, "new", "old", map[string]string{"abc123DEF": "old"})
+	if err := projectmeta.SaveCategoryOrder(root, []string{"old"}); err != nil {
+		t.Fatalf("SaveCategoryOrder error: %v", err)
+	}
+
+	if _, err := NewService(root).RecoverRename("new", mod.ModeContractor); err != nil {
+		t.Fatalf("RecoverRename error: %v", err)
+	}
+	if order, _ := projectmeta.LoadCategoryOrder(root); len(order) != 1 || order[0] != "new" {
+		t.Fatalf("unexpected order: %v", order)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".tmp_rename")); !os.IsNotExist(err) {
+		t.Fatalf("expected .tmp_rename to be removed, err=%v", err)
+	}
+}
+
+func TestRenameCategory_CleansUpTmpRename(t *testing.T) {
+	// 完了したカテゴリ名変更は変更前の名前の記録と .tmp_rename を残さないことを確認する。
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "old"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	if _, err := NewService(root).RenameCategory("old", "new", mod.ModeContractor); err != nil {
+		t.Fatalf("RenameCategory error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".tmp_rename")); !os.IsNotExist(err) {
+		t.Fatalf("expected .tmp_rename to be removed, err=%v", err)
+	}
+}
@@ -0,0 +1,147 @@
+// trash.go は非空カテゴリの強制削除 (ゴミ箱への退避) を担い、ゴミ箱からの復元や完全削除は扱わない。
+// 退避先はプロジェクトルート直下の .trash で、ドット始まりのためカテゴリ走査の対象外となる。
+package categoryops
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+	"ratta/internal/domain/timeutil"
+	"ratta/internal/infra/atomicwrite"
+	"ratta/internal/infra/issueindex"
+	"ratta/internal/infra/jsonfmt"
+
+	mod "ratta/internal/domain/mode"
+)
+
+const (
+	// trashDirName は DD-TRASH-001 のゴミ箱ディレクトリ名を表す。
+	trashDirName = ".trash"
+	// trashManifestName は DD-TRASH-001 の退避情報ファイル名を表す。
+	trashManifestName = "manifest.json"
+	// trashCategoryDirName は退避したカテゴリ本体を置くディレクトリ名を表す。
+	trashCategoryDirName = "category"
+	// trashFormatVersion は DD-TRASH-001 の manifest 形式バージョンを表す。
+	trashFormatVersion = 1
+)
+
+var trashNow = time.Now
+
+// TrashManifest は DD-TRASH-001 の退避情報を表す。
+type TrashManifest struct {
+	FormatVersion int      `json:"format_version"`
+	TrashID       string   `json:"trash_id"`
+	Category      string   `json:"category"`
+	DeletedAt     string   `json:"deleted_at"`
+	IssueIDs      []string `json:"issue_ids"`
+}
+
+// TrashEntry は DD-TRASH-001 の強制削除結果を表す。
+type TrashEntry struct {
+	TrashID    string
+	Category   string
+	Path       string
+	IssueCount int
+}
+
+// ForceDeleteCategory は DD-TRASH-001 の非空カテゴリの強制削除を行う。
+// 目的: 課題を含むカテゴリを丸ごとゴミ箱へ移動し、退避情報を manifest.json に残す。
+// 入力: name はカテゴリ名、currentMode は操作モード。
+// 出力: 退避結果の TrashEntry とエラー。
+// エラー: 権限不足、読み取り専用・アーカイブ済み、カテゴリ不在、退避先作成・移動失敗時に返す。
+// 副作用: .trash/<trash_id>/ を作成し、カテゴリディレクトリをその配下へ移動する。
+// 並行性: 同一カテゴリへの同時操作は想定しない。
+// 不変条件: 失敗時はカテゴリを元の位置に残し、作成途中の退避先を削除する。
+// 関連DD: DD-TRASH-001, DD-BE-003, DD-PERM-001
+func (s *Service) ForceDeleteCategory(name string, currentMode mod.Mode) (TrashEntry, error) {
+	if err := s.ensureCanWrite(name, currentMode); err != nil {
+		return TrashEntry{}, err
+	}
+	if strings.HasPrefix(name, ".") {
+		return TrashEntry{}, apperr.New(apperr.ErrNotFound, "category not found")
+	}
+	if s.isReadOnly(name) {
+		return TrashEntry{}, apperr.New(apperr.ErrReadOnly, "read-only category")
+	}
+	if s.isArchived(name) {
+		return TrashEntry{}, apperr.New(apperr.ErrReadOnly, "archived category is read-only")
+	}
+	categoryPath, err := s.categoryPath(name)
+	if err != nil {
+		return TrashEntry{}, err
+	}
+	issueIDs, err := listIssueIDs(categoryPath)
+	if err != nil {
+		return TrashEntry{}, err
+	}
+
+	now := trashNow()
+	// 同一カテゴリを繰り返し退避しても衝突しないよう、日時を接頭辞にした ID を用いる。
+	trashID := now.UTC().Format("20060102T150405Z") + "_" + name
+	entryPath := filepath.Join(s.projectRoot, trashDirName, trashID)
+	if _, statErr := os.Stat(entryPath); statErr == nil {
+		return TrashEntry{}, errors.New("trash entry already exists")
+	}
+	if mkdirErr := os.MkdirAll(entryPath, 0o750); mkdirErr != nil {
+		return TrashEntry{}, fmt.Errorf("create trash entry: %w", mkdirErr)
+	}
+
+	manifest := TrashManifest{
+		FormatVersion: trashFormatVersion,
+		TrashID:       trashID,
+		Category:      name,
+		DeletedAt:     timeutil.FormatISO8601(now),
+		IssueIDs:      issueIDs,
+	}
+	data, err := jsonfmt.MarshalTrashManifest(manifest)
+	if err != nil {
+		return TrashEntry{}, cleanupTrashEntry(entryPath, fmt.Errorf("marshal trash manifest: %w", err))
+	}
+	if writeErr := atomicwrite.WriteFile(filepath.Join(entryPath, trashManifestName), data); writeErr != nil {
+		return TrashEntry{}, cleanupTrashEntry(entryPath, fmt.Errorf("write trash manifest: %w", writeErr))
+	}
+	if renameErr := os.Rename(categoryPath, filepath.Join(entryPath, trashCategoryDirName)); renameErr != nil {
+		return TrashEntry{}, cleanupTrashEntry(entryPath, fmt.Errorf("move category to trash: %w", renameErr))
+	}
+	// 索引はキャッシュのため、破棄に失敗しても一覧結果には影響しない。
+	_ = issueindex.Open(s.projectRoot).DropCategory(name)
+
+	return TrashEntry{
+		TrashID:    trashID,
+		Category:   name,
+		Path:       entryPath,
+		IssueCount: len(issueIDs),
+	}, nil
+}
+
+// listIssueIDs は DD-TRASH-001 の manifest 用にカテゴリ直下の課題IDを列挙する。
+func listIssueIDs(categoryPath string) ([]string, error) {
+	entries, err := os.ReadDir(categoryPath)
+	if err != nil {
+		return nil, fmt.Errorf("read category: %w", err)
+	}
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !issue.IsIssueFileName(entry.Name()) {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// cleanupTrashEntry は DD-TRASH-001 の失敗時に作成途中の退避先を削除する。
+func cleanupTrashEntry(entryPath string, cause error) error {
+	if removeErr := os.RemoveAll(entryPath); removeErr != nil {
+		return fmt.Errorf("rollback trash entry failed: %w; rollback error: %s", cause, removeErr.Error())
+	}
+	return cause
+}
@@ -0,0 +1,76 @@
+// trash_test.go は非空カテゴリの強制削除のテストを行い、ゴミ箱からの復元は扱わない。
+package categoryops
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mod "ratta/internal/domain/mode"
+)
+
+func TestForceDeleteCategory_MovesIntoTrashWithManifest(t *testing.T) {
+	// 課題を含むカテゴリがゴミ箱へ移動され、manifest に課題IDが記録されることを確認する。
+	root := t.TempDir()
+	categoryPath := filepath.Join(root, "junk")
+	if err := os.MkdirAll(filepath.Join(categoryPath, "abc.files"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for _, name := range []string{"abc.json", "def.json"} {
+		if err := os.WriteFile(filepath.Join(categoryPath, name), []byte("{}"), 0o600); err != nil {
+			t.Fatalf("write issue: %v", err)
+		}
+	}
+	previous := trashNow
+	trashNow = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+	t.Cleanup(func() { trashNow = previous })
+
+	service := NewService(root)
+	entry, err := service.ForceDeleteCategory("junk", mod.ModeContractor)
+	if err != nil {
+		t.Fatalf("ForceDeleteCategory error: %v", err)
+	}
+	if entry.TrashID != "20240102T030405Z_junk" || entry.IssueCount != 2 {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+	if _, statErr := os.Stat(categoryPath); !os.IsNotExist(statErr) {
+		t.Fatalf("expected category to be moved, err=%v", statErr)
+	}
+	if _, statErr := os.Stat(filepath.Join(entry.Path, "category", "abc.json")); statErr != nil {
+		t.Fatalf("expected issue in trash, err=%v", statErr)
+	}
+	data, err := os.ReadFile(filepath.Join(entry.Path, "manifest.json"))
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	var manifest TrashManifest
+	if unmarshalErr := json.Unmarshal(data, &manifest); unmarshalErr != nil {
+		t.Fatalf("parse manifest: %v", unmarshalErr)
+	}
+	if manifest.Category != "junk" || len(manifest.IssueIDs) != 2 || manifest.IssueIDs[0] != "abc" {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+}
+
+func TestForceDeleteCategory_RejectsVendorAndHiddenNames(t *testing.T) {
+	// Vendor モードとドット始まりの名前 (.trash 自身など) は拒否されることを確認する。
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "cat"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".trash"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	service := NewService(root)
+	if _, err := service.ForceDeleteCategory("cat", mod.ModeVendor); err == nil {
+		t.Fatal("expected permission error")
+	}
+	if _, err := service.ForceDeleteCategory(".trash", mod.ModeContractor); err == nil {
+		t.Fatal("expected hidden name error")
+	}
+	if _, statErr := os.Stat(filepath.Join(root, "cat")); statErr != nil {
+		t.Fatalf("expected category to remain, err=%v", statErr)
+	}
+}
@@ -3,18 +3,24 @@
 package categoryscan
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+
+	"ratta/internal/infra/categorymeta"
+	"ratta/internal/infra/projectmeta"
 )
 
 // Category は DD-LOAD-002 のカテゴリ情報を表す。
 type Category struct {
 	Name       string
 	IsReadOnly bool
+	IsArchived bool
 	Path       string
+	Meta       categorymeta.Meta
 }
 
 // ScanResult は DD-LOAD-002 のカテゴリ一覧結果を表す。
@@ -30,9 +36,17 @@ type ScanResult struct {
 // エラー: 走査対象ディレクトリの読み取りに失敗した場合に返す。
 // 副作用: なし。
 // 並行性: 読み取りのみでスレッドセーフ。
-// 不変条件: 返却するカテゴリ一覧は名前順にソートされる。
-// 関連DD: DD-LOAD-002
+// 不変条件: 明示的な表示順に含まれるカテゴリをその順で先頭に並べ、
+// 残りは sort_weight 昇順、同値は名前順にソートされる。
+// アーカイブ済みカテゴリは IsReadOnly=true として返す。
+// 関連DD: DD-LOAD-002, DD-CATMETA-001, DD-CATMETA-002, DD-PROJMETA-001
 func Scan(root string) (ScanResult, error) {
+	return ScanContext(context.Background(), root)
+}
+
+// ScanContext は DD-LOAD-002/DD-CANCEL-001 の中断可能なカテゴリ走査を行う。
+// カテゴリ毎のメタデータ読み込みの前に ctx を確認し、中断された場合は ctx.Err() を返す。
+func ScanContext(ctx context.Context, root string) (ScanResult, error) {
 	entries, err := os.ReadDir(root)
 	if err != nil {
 		return ScanResult{}, fmt.Errorf("read project root: %w", err)
@@ -78,11 +92,54 @@ func Scan(root string) (ScanResult, error) {
 		})
 	}
 
-	sort.Slice(categories, func(i, j int) bool {
+	// メタデータの破損はカテゴリ一覧の表示を妨げないよう既定値で継続し、件数のみ通知する。
+	errorCount := 0
+	for i := range categories {
+		// 共有フォルダ上ではカテゴリ毎の読み込みが遅いため、ここで中断を受け付ける。
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ScanResult{}, ctxErr
+		}
+		if categorymeta.IsArchived(categories[i].Path) {
+			categories[i].IsArchived = true
+			categories[i].IsReadOnly = true
+		}
+		meta, _, loadErr := categorymeta.Load(categories[i].Path)
+		if loadErr != nil {
+			errorCount++
+			continue
+		}
+		categories[i].Meta = meta
+	}
+
+	// 表示順ファイルの破損もカテゴリ一覧を妨げないよう、既定の並びで継続する。
+	order, orderErr := projectmeta.LoadCategoryOrder(root)
+	if orderErr != nil {
+		errorCount++
+		order = nil
+	}
+	rank := make(map[string]int, len(order))
+	for i, name := range order {
+		if _, ok := rank[name]; !ok {
+			rank[name] = i
+		}
+	}
+
+	sort.SliceStable(categories, func(i, j int) bool {
+		rankI, rankedI := rank[categories[i].Name]
+		rankJ, rankedJ := rank[categories[j].Name]
+		if rankedI || rankedJ {
+			if rankedI && rankedJ {
+				return rankI < rankJ
+			}
+			return rankedI
+		}
+		if categories[i].Meta.SortWeight != categories[j].Meta.SortWeight {
+			return categories[i].Meta.SortWeight < categories[j].Meta.SortWeight
+		}
 		return categories[i].Name < categories[j].Name
 	})
 
-	return ScanResult{Categories: categories}, nil
+	return ScanResult{Categories: categories, ErrorCount: errorCount}, nil
 }
 
 // shouldSkipDir は DD-LOAD-002 の除外ルールを適用する。
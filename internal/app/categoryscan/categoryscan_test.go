@@ -40,3 +40,87 @@ func TestScan_FlatAndReadOnly(t *testing.T) {
 		t.Fatalf("unexpected read-only category: %+v", result.Categories[1])
 	}
 }
+
+func TestScan_SortsByMetaWeightAndCountsBrokenMeta(t *testing.T) {
+	// .category.json の sort_weight で並び替え、破損したメタデータは既定値で継続し件数に計上することを確認する。
+	root := t.TempDir()
+	for _, name := range []string{"alpha", "beta", "gamma"} {
+		if err := os.MkdirAll(filepath.Join(root, name), 0o750); err != nil {
+			t.Fatalf("mkdir %s: %v", name, err)
+		}
+	}
+	metaPath := filepath.Join(root, "gamma", ".category.json")
+	if err := os.WriteFile(metaPath, []byte(`{"format_version":1,"description":"g","color":"#112233","sort_weight":-1}`), 0o600); err != nil {
+		t.Fatalf("write meta: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "beta", ".category.json"), []byte("{"), 0o600); err != nil {
+		t.Fatalf("write broken meta: %v", err)
+	}
+
+	result, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Scan error: %v", err)
+	}
+	if result.ErrorCount != 1 {
+		t.Fatalf("unexpected error count: %d", result.ErrorCount)
+	}
+	if len(result.Categories) != 3 || result.Categories[0].Name != "gamma" {
+		t.Fatalf("unexpected order: %+v", result.Categories)
+	}
+	if result.Categories[0].Meta.Color != "#112233" || result.Categories[0].Meta.Description != "g" {
+		t.Fatalf("unexpected meta: %+v", result.Categories[0].Meta)
+	}
+	if result.Categories[1].Name != "alpha" || result.Categories[2].Name != "beta" {
+		t.Fatalf("unexpected order: %+v", result.Categories)
+	}
+}
+
+func TestScan_ArchivedIsReadOnly(t *testing.T) {
+	// .archived マーカーを持つカテゴリは読み取り専用かつアーカイブ済みとして返ることを確認する。
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "old"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "old", ".archived"), nil, 0o600); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+	result, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Scan error: %v", err)
+	}
+	if len(result.Categories) != 1 || !result.Categories[0].IsReadOnly || !result.Categories[0].IsArchived {
+		t.Fatalf("unexpected categories: %+v", result.Categories)
+	}
+}
+
+func TestScan_RespectsExplicitOrder(t *testing.T) {
+	// 明示的な表示順に含まれるカテゴリが先頭に並び、残りは名前順になることを確認する。
+	root := t.TempDir()
+	for _, name := range []string{"a", "b", "c", "d"} {
+		if err := os.MkdirAll(filepath.Join(root, name), 0o750); err != nil {
+			t.Fatalf("mkdir %s: %v", name, err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".ratta"), 0o750); err != nil {
+		t.Fatalf("mkdir .ratta: %v", err)
+	}
+	order := `{"format_version":1,"categories":["c","missing","a"]}`
+	if err := os.WriteFile(filepath.Join(root, ".ratta", "category_order.json"), []byte(order), 0o600); err != nil {
+		t.Fatalf("write order: %v", err)
+	}
+
+	result, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Scan error: %v", err)
+	}
+	got := make([]string, 0, len(result.Categories))
+	for _, category := range result.Categories {
+		got = append(got, category.Name)
+	}
+	expected := []string{"c", "a", "b", "d"}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("unexpected order: %v", got)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// backup.go はプロジェクト全体のバックアップの作成と復元のサブコマンドを担い、バックアップの世代管理は扱わない。
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ratta/internal/app/backup"
+)
+
+// backupReport は DD-CLI-006 の --json 指定時の backup・restore の出力を表す。
+type backupReport struct {
+	Path       string `json:"path"`
+	FileCount  int    `json:"file_count"`
+	TotalBytes int64  `json:"total_bytes"`
+}
+
+// runBackup は DD-CLI-006 の backup サブコマンドを実行する。
+// 目的: Git を使わない運用でも、プロジェクトの時点の状態を1つの zip として保管できるようにする。
+// 入力: args は `[--output path] <root>`、env は実行環境。--output が無い場合やディレクトリの場合は ratta-backup-<日時>.zip を作成する。
+// 出力: 終了コード。成功時は 0、作成失敗時は 1、引数の不備は 2。
+// エラー: 出力先が既に存在する場合や走査・書き込みに失敗した場合は標準エラーへ書く。
+// 副作用: zip を作成し、標準出力へそのパスを (--json 指定時はパスと件数を JSON で)、標準エラーへ件数の要約を書く。
+// プロジェクト配下は変更しない。
+// 並行性: 読み取りのみのためロックを取得しない。GUI での編集中の変更が含まれるかは保証しない。
+// 不変条件: 一時ファイル残骸や再生成できる索引・キャッシュは含めない。
+// 関連DD: DD-CLI-006, DD-BACKUP-001
+func runBackup(args []string, env Env) int {
+	fs := newFlagSet("backup", env)
+	output := fs.String("output", "", "backup zip path or directory (default: ratta-backup-<timestamp>.zip)")
+	positional, err := parseArgs(fs, args, "root")
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	dest := *output
+	if dest == "" {
+		dest = backup.DefaultFileName()
+	} else if info, statErr := os.Stat(dest); statErr == nil && info.IsDir() {
+		dest = filepath.Join(dest, backup.DefaultFileName())
+	}
+
+	result, err := backup.Create(context.Background(), positional[0], dest)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "backup: %v\n", err)
+		return exitFailure
+	}
+	if env.JSON {
+		err = writeJSON(env.Stdout, backupReport{Path: result.Path, FileCount: result.FileCount, TotalBytes: result.TotalBytes})
+	} else {
+		_, err = fmt.Fprintln(env.Stdout, result.Path)
+	}
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "backup: %v\n", err)
+		return exitFailure
+	}
+	fmt.Fprintf(env.Stderr, "backed up %d files (%d bytes)\n", result.FileCount, result.TotalBytes)
+	return exitOK
+}
+
+// runRestore は DD-CLI-006 の restore サブコマンドを実行する。
+// 目的: backup で作成した zip を検証しながら展開し、バックアップ時点のプロジェクトを再現する。
+// 入力: args は `<backup.zip> <dest>`、env は実行環境。dest は存在しないか空のディレクトリとする。
+// 出力: 終了コード。成功時は 0、検証や展開に失敗した場合は 1、引数の不備は 2。
+// エラー: 復元先が空でない場合、zip の内容が manifest と一致しない場合は標準エラーへ書く。
+// 副作用: dest へファイルを展開し、標準エラーへ件数の要約を書く。--json 指定時は標準出力へ復元先と件数を JSON で書く。
+// 失敗した場合は展開したファイルを残さない。
+// 並行性: 既存のプロジェクトを上書きしないため、ロックを取得しない。
+// 不変条件: 既存のプロジェクトへの上書き復元は行わない。
+// 関連DD: DD-CLI-006, DD-BACKUP-002
+func runRestore(args []string, env Env) int {
+	fs := newFlagSet("restore", env)
+	positional, err := parseArgs(fs, args, "backup.zip", "dest")
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	result, err := backup.Restore(context.Background(), positional[0], positional[1])
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "restore: %v\n", err)
+		return exitFailure
+	}
+	if env.JSON {
+		if writeErr := writeJSON(env.Stdout, backupReport{Path: result.Path, FileCount: result.FileCount, TotalBytes: result.TotalBytes}); writeErr != nil {
+			fmt.Fprintf(env.Stderr, "restore: %v\n", writeErr)
+			return exitFailure
+		}
+	}
+	fmt.Fprintf(env.Stderr, "restored and verified %d files (%d bytes) into %s\n", result.FileCount, result.TotalBytes, result.Path)
+	return exitOK
+}
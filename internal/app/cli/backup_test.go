@@ -0,0 +1,37 @@
+// backup_test.go は backup と restore サブコマンドのテストを行う。
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBackupRestore_RoundTrip(t *testing.T) {
+	// 出力先ディレクトリへ既定名でバックアップを作成し、空のディレクトリへ復元でき、空でない復元先は拒否することを確認する。
+	root, issueID := newProject(t)
+	outDir := t.TempDir()
+	code, stdout, stderr := runCommand(t, "backup", "--output", outDir, root)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	zipPath := strings.TrimSpace(stdout)
+	if filepath.Dir(zipPath) != outDir || !strings.HasPrefix(filepath.Base(zipPath), "ratta-backup-") {
+		t.Fatalf("unexpected backup path: %q", zipPath)
+	}
+
+	dest := filepath.Join(t.TempDir(), "restored")
+	if code, _, stderr := runCommand(t, "restore", zipPath, dest); code != exitOK {
+		t.Fatalf("expected restore success, got %d %q", code, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "cat", issueID+".json")); err != nil {
+		t.Fatalf("expected restored issue: %v", err)
+	}
+	if code, _, _ := runCommand(t, "restore", zipPath, dest); code != exitFailure {
+		t.Fatalf("expected failure for non-empty destination, got %d", code)
+	}
+	if code, _, _ := runCommand(t, "restore", zipPath); code != exitUsage {
+		t.Fatalf("expected usage error, got %d", code)
+	}
+}
@@ -0,0 +1,240 @@
+// Package cli は GUI を起動せずに実行するサブコマンドの振り分けと実行を担い、GUI の起動や画面表示は扱わない。
+// 各サブコマンドは app 層のユースケースを再利用し、結果を標準出力へ、診断を標準エラーへ書き出す。
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"ratta/internal/app/categoryscan"
+	"ratta/internal/app/contractorinit"
+	"ratta/internal/domain/timeutil"
+	"ratta/internal/infra/atomicwrite"
+	"ratta/internal/infra/configrepo"
+	"ratta/internal/infra/jsonfmt"
+	"ratta/internal/infra/schema"
+)
+
+const (
+	// exitOK は DD-CLI-006 の正常終了を表す終了コード。
+	exitOK = 0
+	// exitFailure は DD-CLI-006 の検査失敗や処理失敗を表す終了コード。
+	exitFailure = 1
+	// exitUsage は DD-CLI-006 の引数不備や実行環境の不備を表す終了コード。
+	exitUsage = 2
+)
+
+const (
+	// formatTable は DD-CLI-006 の人が読むための表形式の出力を表す。
+	formatTable = "table"
+	// formatJSON は DD-CLI-006 の他のツールで処理するための JSON 形式の出力を表す。
+	formatJSON = "json"
+)
+
+// Env は DD-CLI-006 のサブコマンドの実行環境を表す。
+// ExePath はスキーマや認証ファイルの配置先の基準とする実行ファイルのパス。
+// Stdin は標準入力を読むサブコマンド (mcp) の入力元を表す。
+// Prompter は Contractor パスワードの端末入力に用い、nil の場合は入力を求めない。
+// JSON はグローバルフラグ --json の指定を表し、各サブコマンドは結果を標準出力へ JSON で書く。
+// Build はビルド時に埋め込まれたバージョン情報を表す。
+type Env struct {
+	ExePath  string
+	Stdin    io.Reader
+	Stdout   io.Writer
+	Stderr   io.Writer
+	Prompter contractorinit.Prompter
+	JSON     bool
+	Build    BuildInfo
+}
+
+// command は DD-CLI-006 のサブコマンドの実装を表す。args はサブコマンド名より後の引数。
+type command func(args []string, env Env) int
+
+// commands は DD-CLI-006 のサブコマンド名と実装の対応を表す。
+var commands = map[string]command{
+	"validate": runValidate,
+	"list":     runList,
+	"show":     runShow,
+	"issue":    group("issue", map[string]command{"create": runIssueCreate}),
+	"comment":  group("comment", map[string]command{"add": runCommentAdd}),
+	"export":   runExport,
+	"import":   group("import", map[string]command{"csv": runImportCSV, "jsonl": runImportJSONL}),
+	"stats":    runStats,
+	"sync":     runSync,
+	"doctor":   runDoctor,
+	"migrate":  runMigrate,
+	"backup":   runBackup,
+	"restore":  runRestore,
+	"publish":  runPublish,
+	"draft":    runDraft,
+	"redmine":  group("redmine", map[string]command{"export": runRedmineExport, "import": runRedmineImport}),
+	"report":   group("report", map[string]command{"issue": runReportIssue, "summary": runReportSummary, "weekly": runReportWeekly}),
+	"patch":    group("patch", map[string]command{"export": runPatchExport, "apply": runPatchApply}),
+	"plugin":   group("plugin", map[string]command{"list": runPluginList}),
+	"passwd":   runPasswd,
+	"version":  runVersion,
+	"mcp":      runMCP,
+}
+
+// Run は DD-CLI-006 のサブコマンドの振り分けを行う。
+// 目的: CI やスクリプトから GUI を起動せずにプロジェクトを扱えるようにする。
+// 入力: args はプログラム名を除いたコマンドライン引数、env は実行環境。
+// 出力: handled はサブコマンドとして処理したか、code は終了コード。
+// エラー: 失敗は終了コードと標準エラーへの出力で示す。
+// 副作用: サブコマンドに応じて標準出力・標準エラーへ書き込み、ファイルを読み書きする。
+// 並行性: 単一ゴルーチンで実行する。
+// 不変条件: 対象外の引数は handled=false を返し、GUI の起動に委ねる。
+// サブコマンドより前の --json はグローバルフラグとして扱い、指定時は対象外のコマンドも引数の不備とする。
+// サブコマンドの実行前に config.json の DD-PERSIST-003/005 の保存方法の設定を反映する。
+// 関連DD: DD-CLI-006, DD-PERSIST-003, DD-PERSIST-005
+func Run(args []string, env Env) (bool, int) {
+	for len(args) > 0 && (args[0] == "--json" || args[0] == "-json") {
+		env.JSON = true
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		if env.JSON {
+			fmt.Fprintln(env.Stderr, "usage: ratta --json <command> [flags]")
+			return true, exitUsage
+		}
+		return false, exitOK
+	}
+	run, ok := commands[args[0]]
+	if !ok {
+		if env.JSON {
+			fmt.Fprintf(env.Stderr, "unknown command: %s\n", args[0])
+			return true, exitUsage
+		}
+		return false, exitOK
+	}
+	applyStorageConfig(env.ExePath)
+	return true, run(args[1:], env)
+}
+
+// applyStorageConfig は DD-PERSIST-003/005 と DD-DATA-002 の保存方法の設定を実行ファイルと同じディレクトリの config.json から反映する。
+// GUI と同じく、設定を読み取れない場合は既定値のまま続行する。
+func applyStorageConfig(exePath string) {
+	cfg, hasConfig, err := configrepo.NewRepository(exePath).Load()
+	if err == nil && hasConfig {
+		atomicwrite.SetDurable(cfg.Storage.DurableWrites)
+		atomicwrite.SetBackupGenerations(cfg.Storage.BackupGenerations)
+		timeutil.SetStorageUTC(cfg.Storage.UTCTimestamps)
+	}
+}
+
+// multiFlag は DD-CLI-006 の繰り返し指定できるフラグの値を指定順に表す。
+type multiFlag []string
+
+// String は flag.Value の表示用文字列を返す。
+func (m *multiFlag) String() string {
+	return strings.Join(*m, ",")
+}
+
+// Set は flag.Value の指定を1件追加する。
+func (m *multiFlag) Set(value string) error {
+	*m = append(*m, value)
+	return nil
+}
+
+// group は DD-CLI-006 の `ratta <対象> <操作>` 形式のサブコマンドを操作名で振り分ける。
+func group(name string, verbs map[string]command) command {
+	return func(args []string, env Env) int {
+		if len(args) == 0 {
+			fmt.Fprintf(env.Stderr, "usage: ratta %s <command> [flags]\n", name)
+			return exitUsage
+		}
+		run, ok := verbs[args[0]]
+		if !ok {
+			fmt.Fprintf(env.Stderr, "unknown command: ratta %s %s\n", name, args[0])
+			return exitUsage
+		}
+		return run(args[1:], env)
+	}
+}
+
+// newFlagSet は DD-CLI-006 のサブコマンド用のフラグ定義を生成する。使い方の出力先は標準エラーとする。
+func newFlagSet(name string, env Env) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	return fs
+}
+
+// parseArgs は DD-CLI-006 のフラグを解析し、names と同数の位置引数を返す。names は使い方の表示に用いる。
+func parseArgs(fs *flag.FlagSet, args []string, names ...string) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != len(names) {
+		return nil, fmt.Errorf("usage: ratta %s [flags] <%s>", fs.Name(), strings.Join(names, "> <"))
+	}
+	return fs.Args(), nil
+}
+
+// loadValidator は DD-CLI-006 のスキーマ検証器を読み込む。
+// dir の指定が無い場合は GUI と同じく実行ファイル隣の schemas、次にカレントディレクトリの schemas を用い、
+// いずれも無い場合は実行ファイルへ同梱したスキーマを用いる。指定した dir を読み込めない場合はフォールバックせずエラーとする。
+func loadValidator(exePath, dir string) (*schema.Validator, error) {
+	if dir != "" {
+		return schema.NewValidatorFromDir(dir)
+	}
+	exeDir := ""
+	if exePath != "" {
+		exeDir = filepath.Join(filepath.Dir(exePath), "schemas")
+	}
+	return schema.NewValidator(exeDir, "schemas")
+}
+
+// optionalValidator は DD-CLI-006 の参照系サブコマンド向けにスキーマ検証器を読み込む。
+// dir を明示しない場合は GUI と同じく、読み込めなくても検証なしで続行する。
+func optionalValidator(env Env, dir string) (*schema.Validator, error) {
+	validator, err := loadValidator(env.ExePath, dir)
+	if err != nil && dir == "" {
+		fmt.Fprintf(env.Stderr, "warning: schemas not loaded, skipping schema checks: %v\n", err)
+		return nil, nil
+	}
+	return validator, err
+}
+
+// findCategory は DD-CLI-006 のプロジェクトルート配下から名前の一致するカテゴリを探す。
+// メタデータ用のディレクトリなどカテゴリとして扱われないディレクトリは見つからないものとする。
+func findCategory(root, name string) (categoryscan.Category, error) {
+	scanned, err := categoryscan.Scan(root)
+	if err != nil {
+		return categoryscan.Category{}, err
+	}
+	for _, category := range scanned.Categories {
+		if category.Name == name {
+			return category, nil
+		}
+	}
+	return categoryscan.Category{}, fmt.Errorf("category not found: %s", name)
+}
+
+// defaultFormat は DD-CLI-006 の --format の既定値を返す。グローバルフラグ --json の指定時は json とする。
+func defaultFormat(env Env) string {
+	if env.JSON {
+		return formatJSON
+	}
+	return formatTable
+}
+
+// checkFormat は DD-CLI-006 の出力形式の指定 (table または json) を検証する。
+func checkFormat(format string) error {
+	if format != formatTable && format != formatJSON {
+		return fmt.Errorf("unsupported format: %s (use %s or %s)", format, formatTable, formatJSON)
+	}
+	return nil
+}
+
+// writeJSON は DD-CLI-006 の JSON 出力を標準出力へ書く。キー順は jsonfmt の正規形式に従う。
+func writeJSON(w io.Writer, value any) error {
+	data, err := jsonfmt.MarshalCanonical(value)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
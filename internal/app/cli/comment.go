@@ -0,0 +1,125 @@
+// comment.go は課題へコメントを追加するサブコマンドを担い、コメントの編集や削除は扱わない。
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/infra/configrepo"
+	"ratta/internal/infra/gitcommit"
+	"ratta/internal/infra/projectmeta"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// runCommentAdd は DD-CLI-006 の comment add サブコマンドを実行する。
+// 目的: スクリプトから GUI と同じ規則で課題へコメントと添付を追加できるようにする。
+// 入力: args は `--body b [--author name] [--attach path]... [--contractor] [--format table|json] [--schemas dir]
+// <root> <category> <issue-id>`、env は実行環境。
+// 出力: 終了コード。成功時は 0、追加失敗時は 1、引数の不備は 2。
+// エラー: 添付の検査、モードの決定、書き込み用ロックの取得、保存に失敗した場合は標準エラーへ書く。
+// 副作用: 添付ファイルの保存と課題JSONの更新を行い、標準出力へコメントIDを (json 形式では課題JSONを) 書く。
+// プロジェクト設定で git の自動コミットが有効な場合は更新をコミットし、通知先がある場合は変更を通知し、
+// config.json のフックと plugins/ の保存後の処理を実行する。
+// 並行性: 書き込み用ロックを取得して実行し、GUI が開いている間は追加しない。
+// 不変条件: 添付は GUI と同じサイズ・種類の制限で検査し、保存に失敗した場合は課題JSONを更新しない。
+// GUI と同じく plugins/ の検証を行うプラグインで保存前に検証する。
+// --author が無い Contractor モードでは、照合したアカウント名 (DD-CLI-007) を作成者名とする。
+// 関連DD: DD-CLI-006, DD-CLI-007, DD-BE-003, DD-DATA-004, DD-DATA-005, DD-LOCK-002, DD-HOOK-003, DD-PLUGIN-003
+func runCommentAdd(args []string, env Env) int {
+	fs := newFlagSet("comment add", env)
+	body := fs.String("body", "", "comment body (required)")
+	author := fs.String("author", "", "author name")
+	var attachments multiFlag
+	fs.Var(&attachments, "attach", "file to attach (repeatable)")
+	contractor := fs.Bool("contractor", false, "operate in contractor mode (password from "+contractorPasswordEnv+" or prompt)")
+	format := fs.String("format", defaultFormat(env), "output format: table or json (default json with --json)")
+	schemasDir := fs.String("schemas", "", "directory containing the JSON schemas")
+	positional, err := parseArgs(fs, args, "root", "category", "issue-id")
+	if err == nil && *body == "" {
+		err = fmt.Errorf("--body is required")
+	}
+	if err == nil {
+		err = checkFormat(*format)
+	}
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	inputs, err := readAttachments(attachments)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "comment add: %v\n", err)
+		return exitFailure
+	}
+	validator, err := optionalValidator(env, *schemasDir)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "load schemas: %v\n", err)
+		return exitUsage
+	}
+	currentMode, err := resolveMode(env, *contractor, validator)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "comment add: %v\n", err)
+		return exitFailure
+	}
+
+	if *author == "" && currentMode == mod.ModeContractor {
+		*author = contractorUser()
+	}
+
+	root := positional[0]
+	plugins := discoverPlugins(env)
+	var updated issueops.IssueDetail
+	err = withWriteLock(root, func() error {
+		category, findErr := findCategory(root, positional[1])
+		if findErr != nil {
+			return findErr
+		}
+		var addErr error
+		updated, addErr = issueops.NewService(root, validator).WithRuleChecker(pluginRules(plugins)).AddComment(category.Name, positional[2], currentMode, issueops.CommentCreateInput{
+			Body:        *body,
+			AuthorName:  *author,
+			Attachments: inputs,
+		})
+		if addErr != nil {
+			return addErr
+		}
+		commitChange(env, root, currentMode, gitcommit.Change{Operation: gitOperationCommentAdded, Category: category.Name, IssueID: positional[2], Actor: *author})
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "comment add: %v\n", err)
+		return exitFailure
+	}
+	notifyWebhooks(env, root, currentMode, projectmeta.WebhookEventIssueCommented, *author, updated)
+	runPostSave(env, root, currentMode, plugins, *author, updated.Issue, configrepo.HookEventIssueCommented)
+
+	if *format == formatJSON {
+		err = writeIssueJSON(env.Stdout, updated.Issue)
+	} else {
+		comments := updated.Issue.Comments
+		_, err = fmt.Fprintln(env.Stdout, comments[len(comments)-1].CommentID)
+	}
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "comment add: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// readAttachments は DD-CLI-006/DD-DATA-005 の添付ファイルを GUI と同じ規則で検査して読み込む。
+func readAttachments(paths []string) ([]issueops.CommentAttachmentInput, error) {
+	inputs := make([]issueops.CommentAttachmentInput, 0, len(paths))
+	for _, path := range paths {
+		file, err := issueops.InspectAttachmentFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		data, err := os.ReadFile(file.Path)
+		if err != nil {
+			return nil, fmt.Errorf("read attachment: %w", err)
+		}
+		inputs = append(inputs, issueops.CommentAttachmentInput{OriginalName: file.Name, Data: data, MimeType: file.MimeType})
+	}
+	return inputs, nil
+}
@@ -0,0 +1,202 @@
+// comment_test.go は comment add サブコマンドのコメントと添付の追加のテストを行う。
+package cli
+
+import (
+	"encoding/base32"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ratta/internal/app/contractorinit"
+	"ratta/internal/app/issueops"
+	"ratta/internal/infra/crypto"
+	"ratta/internal/infra/projectmeta"
+	"ratta/internal/present"
+)
+
+func TestCommentAdd_AddsCommentWithAttachment(t *testing.T) {
+	// コメントと添付を追加してコメントIDを出力し、受け付けない種類の添付は追加しないことを確認する。
+	root, issueID := newProject(t)
+	source := filepath.Join(t.TempDir(), "note.txt")
+	if err := os.WriteFile(source, []byte("hello"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	code, stdout, stderr := runCommand(t, "comment", "add", "--schemas", schemasDir,
+		"--body", "from script", "--author", "bot", "--attach", source, root, "cat", issueID)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	detail, err := issueops.NewService(root, nil).GetIssue("cat", issueID)
+	if err != nil {
+		t.Fatalf("GetIssue error: %v", err)
+	}
+	if len(detail.Issue.Comments) != 1 {
+		t.Fatalf("expected one comment, got %+v", detail.Issue.Comments)
+	}
+	comment := detail.Issue.Comments[0]
+	if comment.CommentID != strings.TrimSpace(stdout) || comment.AuthorName != "bot" || len(comment.Attachments) != 1 {
+		t.Fatalf("unexpected comment: %+v", comment)
+	}
+	if _, err := os.Stat(filepath.Join(root, "cat", filepath.FromSlash(comment.Attachments[0].RelativePath))); err != nil {
+		t.Fatalf("expected stored attachment: %v", err)
+	}
+
+	blocked := filepath.Join(t.TempDir(), "run.exe")
+	if err := os.WriteFile(blocked, []byte("x"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if code, _, _ := runCommand(t, "comment", "add", "--schemas", schemasDir,
+		"--body", "blocked", "--attach", blocked, root, "cat", issueID); code != exitFailure {
+		t.Fatalf("expected failure for blocked attachment, got %d", code)
+	}
+}
+
+func TestCommentAdd_NotifiesWebhooks(t *testing.T) {
+	// 追加したコメントを含む課題を通知先へ送り、届かなかった通知は標準エラーへ書いても成功とすることを確認する。
+	root, issueID := newProject(t)
+	type notification struct {
+		Event string                 `json:"event"`
+		Actor string                 `json:"actor"`
+		Issue present.IssueDetailDTO `json:"issue"`
+	}
+	var received []notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rejected" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var payload notification
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		received = append(received, payload)
+	}))
+	defer server.Close()
+	writeFile(t, projectmeta.ConfigPath(root), `{"format_version": 1, "webhooks": [`+
+		`{"url": "`+server.URL+`/hook", "events": ["issue.commented"]},`+
+		`{"url": "`+server.URL+`/rejected"},`+
+		`{"url": "`+server.URL+`/created", "events": ["issue.created"]}]}`)
+
+	code, _, stderr := runCommand(t, "comment", "add", "--schemas", schemasDir, "--body", "notify", "--author", "bot", root, "cat", issueID)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	if len(received) != 1 || received[0].Event != projectmeta.WebhookEventIssueCommented || received[0].Actor != "bot" ||
+		received[0].Issue.IssueID != issueID || len(received[0].Issue.Comments) != 1 {
+		t.Fatalf("unexpected notifications: %+v", received)
+	}
+	if !strings.Contains(stderr, "webhook issue.commented to "+server.URL+" failed after 1 attempts") || strings.Contains(stderr, "/rejected") {
+		t.Fatalf("unexpected stderr: %q", stderr)
+	}
+}
+
+func TestGroup_RejectsUnknownVerb(t *testing.T) {
+	// 対象に続く操作が無い、または未対応の場合は終了コード 2 となることを確認する。
+	if code, _, _ := runCommand(t, "comment"); code != exitUsage {
+		t.Fatalf("expected usage error, got %d", code)
+	}
+	if code, _, _ := runCommand(t, "comment", "delete"); code != exitUsage {
+		t.Fatalf("expected usage error, got %d", code)
+	}
+}
+
+func TestCommentAdd_ContractorUserIsDefaultAuthor(t *testing.T) {
+	// users.json で照合した Contractor のアカウント名が既定の作成者名となり、アカウント名が無い場合は追加しないことを確認する。
+	root, issueID := newProject(t)
+	exePath := writeUserAuth(t, "", "alice", "Contractor-pass-1")
+	t.Setenv(contractorPasswordEnv, "Contractor-pass-1")
+
+	t.Setenv(contractorUserEnv, "")
+	code, _, stderr := runCommandWith(t, exePath, "comment", "add", "--schemas", schemasDir,
+		"--contractor", "--body", "no user", root, "cat", issueID)
+	if code != exitFailure || !strings.Contains(stderr, contractorUserEnv) {
+		t.Fatalf("expected missing user failure, got %d %q", code, stderr)
+	}
+
+	t.Setenv(contractorUserEnv, "alice")
+	code, _, stderr = runCommandWith(t, exePath, "comment", "add", "--schemas", schemasDir,
+		"--contractor", "--body", "from alice", root, "cat", issueID)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	detail, err := issueops.NewService(root, nil).GetIssue("cat", issueID)
+	if err != nil {
+		t.Fatalf("GetIssue error: %v", err)
+	}
+	if len(detail.Issue.Comments) != 1 || detail.Issue.Comments[0].AuthorName != "alice" {
+		t.Fatalf("expected comment by alice, got %+v", detail.Issue.Comments)
+	}
+}
+
+func TestCommentAdd_ContractorRequiresOneTimeCode(t *testing.T) {
+	// contractor.json に TOTP がある場合はワンタイムコードが無い・一致しないと Contractor にならないことを確認する。
+	root, issueID := newProject(t)
+	exePath := filepath.Join(t.TempDir(), "ratta.exe")
+	kdf := crypto.KDFParams{Name: crypto.KDFArgon2id, Iterations: 2}
+	enrollment, err := contractorinit.RunWithTOTP(exePath, false, kdf, &scriptedPrompter{values: []string{"Contractor-pass-1", "Contractor-pass-1"}})
+	if err != nil {
+		t.Fatalf("RunWithTOTP error: %v", err)
+	}
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	if err != nil {
+		t.Fatalf("decode secret: %v", err)
+	}
+	t.Setenv(contractorPasswordEnv, "Contractor-pass-1")
+	args := []string{"comment", "add", "--schemas", schemasDir, "--contractor", "--body", "with code", "--author", "contractor", root, "cat", issueID}
+
+	t.Setenv(contractorTOTPEnv, "")
+	if code, _, stderr := runCommandWith(t, exePath, args...); code != exitFailure || !strings.Contains(stderr, contractorTOTPEnv) {
+		t.Fatalf("expected missing code failure, got %d %q", code, stderr)
+	}
+	wrong := "000000"
+	if wrong == crypto.TOTPCode(secret, time.Now()) {
+		wrong = "111111"
+	}
+	t.Setenv(contractorTOTPEnv, wrong)
+	if code, _, stderr := runCommandWith(t, exePath, args...); code != exitFailure {
+		t.Fatalf("expected wrong code failure, got %d %q", code, stderr)
+	}
+	t.Setenv(contractorTOTPEnv, crypto.TOTPCode(secret, time.Now()))
+	if code, _, stderr := runCommandWith(t, exePath, args...); code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+}
+
+func TestCommentAdd_UserAddedToTOTPInstallRequiresOneTimeCode(t *testing.T) {
+	// TOTP 設定済みの環境に users.json のアカウントを追加しても、そのアカウントでの認証にワンタイムコードが必要なことを確認する。
+	root, issueID := newProject(t)
+	exePath := filepath.Join(t.TempDir(), "ratta.exe")
+	kdf := crypto.KDFParams{Name: crypto.KDFArgon2id, Iterations: 2}
+	if _, err := contractorinit.RunWithTOTP(exePath, false, kdf, &scriptedPrompter{values: []string{"Contractor-pass-1", "Contractor-pass-1"}}); err != nil {
+		t.Fatalf("RunWithTOTP error: %v", err)
+	}
+	if _, err := contractorinit.AddUser(exePath, "alice", false, false, kdf, &scriptedPrompter{values: []string{"Alice-pass-1", "Alice-pass-1"}}); err == nil {
+		t.Fatal("expected AddUser without TOTP to be refused")
+	}
+	enrollment, err := contractorinit.AddUser(exePath, "alice", false, true, kdf, &scriptedPrompter{values: []string{"Alice-pass-1", "Alice-pass-1"}})
+	if err != nil {
+		t.Fatalf("AddUser error: %v", err)
+	}
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	if err != nil {
+		t.Fatalf("decode secret: %v", err)
+	}
+	t.Setenv(contractorUserEnv, "alice")
+	t.Setenv(contractorPasswordEnv, "Alice-pass-1")
+	args := []string{"comment", "add", "--schemas", schemasDir, "--contractor", "--body", "with code", root, "cat", issueID}
+
+	t.Setenv(contractorTOTPEnv, "")
+	if code, _, stderr := runCommandWith(t, exePath, args...); code != exitFailure || !strings.Contains(stderr, contractorTOTPEnv) {
+		t.Fatalf("expected missing code failure, got %d %q", code, stderr)
+	}
+	t.Setenv(contractorTOTPEnv, crypto.TOTPCode(secret, time.Now()))
+	if code, _, stderr := runCommandWith(t, exePath, args...); code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+}
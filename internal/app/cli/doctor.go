@@ -0,0 +1,353 @@
+// doctor.go はプロジェクトの整合性検査サブコマンドと、データを失わずに行える修復を担い、課題の内容の修正は扱わない。
+// スキーマ検査は validate と同じ規則で行い、修復は --fix を指定した場合に限る。
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ratta/internal/app/categoryops"
+	"ratta/internal/app/categoryscan"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/projectmeta"
+	"ratta/internal/infra/schema"
+	"ratta/internal/infra/tmpresidue"
+
+	mod "ratta/internal/domain/mode"
+)
+
+const (
+	// checkSchema は DD-CLI-006 の課題JSONのスキーマ不整合・読み取り失敗を表す。
+	checkSchema = "schema"
+	// checkMissingAttachment は DD-CLI-006 の課題JSONが参照する添付ファイルの欠落を表す。
+	checkMissingAttachment = "missing_attachment"
+	// checkOrphanAttachment は DD-CLI-006 のどの課題からも参照されない添付ファイルを表す。
+	checkOrphanAttachment = "orphan_attachment"
+	// checkTmpResidue は DD-CLI-006 の一時ファイル残骸を表す。
+	checkTmpResidue = "tmp_residue"
+	// checkRenameResidue は DD-CLI-006 の中断されたカテゴリ名変更の残骸を表す。
+	checkRenameResidue = "rename_residue"
+)
+
+// attachmentDirSuffix は DD-DATA-005 の課題ごとの添付ディレクトリの接尾辞を表す。
+const attachmentDirSuffix = ".files"
+
+// orphanDirName は DD-CLI-006 の参照されない添付ファイルの退避先ディレクトリ名 (.ratta 配下) を表す。
+const orphanDirName = "orphans"
+
+// doctorFinding は DD-CLI-006 の検査で見つかった1件の問題を表す。Path はプロジェクトルートからの相対パス (区切りは /) とする。
+type doctorFinding struct {
+	Check    string `json:"check"`
+	Path     string `json:"path"`
+	Message  string `json:"message"`
+	Fixable  bool   `json:"fixable"`
+	Fixed    bool   `json:"fixed"`
+	FixError string `json:"fix_error,omitempty"`
+
+	fix func() error
+}
+
+// doctorReport は DD-CLI-006 の doctor の json 形式の出力内容を表す。Remaining は修復後も残る問題の数を表す。
+type doctorReport struct {
+	Findings  []doctorFinding `json:"findings"`
+	Fixed     int             `json:"fixed"`
+	Remaining int             `json:"remaining"`
+}
+
+// runDoctor は DD-CLI-006 の doctor サブコマンドを実行する。
+// 目的: スキーマ不整合・添付の欠落と孤立・一時ファイル残骸・カテゴリ名変更の残骸を1回の実行でまとめて確認し、
+// 安全に直せるものは --fix で修復できるようにする。
+// 入力: args は `[--fix] [--contractor] [--format table|json] [--schemas dir] <root>`、env は実行環境。
+// 出力: 終了コード。問題が無い (修復後に残らない) 場合は 0、残る場合や検査に失敗した場合は 1、引数やスキーマの不備は 2。
+// エラー: プロジェクトの走査や書き込み用ロックの取得に失敗した場合は標準エラーへ書く。修復の失敗は結果に含める。
+// 副作用: 標準出力へ問題を1行1件のタブ区切り (検査, パス, 状態, メッセージ) で書き、標準エラーへ件数の要約を書く。
+// --fix では一時ファイル残骸の削除、参照されない添付の .ratta/orphans への退避、中断されたカテゴリ名変更の完了を行う。
+// 並行性: --fix では書き込み用ロックを取得して実行し、GUI が開いている間は修復しない。検査のみの場合はロックを取得しない。
+// 不変条件: 課題JSONの内容と参照されている添付ファイルは変更・削除しない。カテゴリ名変更の完了は Contractor に限る。
+// 関連DD: DD-CLI-006, DD-BE-002, DD-BE-003, DD-DATA-005, DD-PERSIST-004, DD-LOCK-002
+func runDoctor(args []string, env Env) int {
+	fs := newFlagSet("doctor", env)
+	fix := fs.Bool("fix", false, "repair problems that can be fixed without losing data")
+	contractor := fs.Bool("contractor", false, "operate in contractor mode (password from "+contractorPasswordEnv+" or prompt)")
+	format := fs.String("format", defaultFormat(env), "output format: table or json (default json with --json)")
+	schemasDir := fs.String("schemas", "", "directory containing issue.schema.json")
+	positional, err := parseArgs(fs, args, "root")
+	if err == nil {
+		err = checkFormat(*format)
+	}
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	validator, err := loadValidator(env.ExePath, *schemasDir)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "load schemas: %v\n", err)
+		return exitUsage
+	}
+
+	root := positional[0]
+	var findings []doctorFinding
+	if *fix {
+		currentMode, modeErr := resolveMode(env, *contractor, validator)
+		if modeErr != nil {
+			fmt.Fprintf(env.Stderr, "doctor: %v\n", modeErr)
+			return exitFailure
+		}
+		err = withWriteLock(root, func() error {
+			var diagnoseErr error
+			findings, diagnoseErr = diagnoseProject(root, validator, currentMode)
+			if diagnoseErr != nil {
+				return diagnoseErr
+			}
+			applyFixes(findings)
+			return nil
+		})
+	} else {
+		findings, err = diagnoseProject(root, validator, mod.ModeVendor)
+	}
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "doctor: %v\n", err)
+		return exitFailure
+	}
+
+	report := doctorReport{Findings: findings}
+	for _, finding := range findings {
+		if finding.Fixed {
+			report.Fixed++
+		} else {
+			report.Remaining++
+		}
+	}
+	if *format == formatJSON {
+		if report.Findings == nil {
+			report.Findings = []doctorFinding{}
+		}
+		err = writeJSON(env.Stdout, report)
+	} else {
+		err = writeDoctorTable(env.Stdout, findings)
+	}
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "doctor: %v\n", err)
+		return exitFailure
+	}
+	fmt.Fprintf(env.Stderr, "found %d problems, fixed %d, %d remaining\n", len(findings), report.Fixed, report.Remaining)
+	if report.Remaining > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// diagnoseProject は DD-CLI-006 のプロジェクト全体の整合性検査を行う。
+// 目的: 各検査の結果を、修復手順と合わせて1つの一覧にまとめる。
+// 入力: root はプロジェクトルート、validator はスキーマ検証器、currentMode は修復時の操作モード。
+// 出力: 検査順 (スキーマ, 添付, 一時ファイル, カテゴリ名変更) の問題とエラー。
+// エラー: プロジェクトルートの走査に失敗した場合に返す。
+// 副作用: なし。修復は返却値の fix を呼び出した場合に限り行う。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: 読み取り専用カテゴリ (アーカイブ済み・名前変更中) の添付は退避対象としない。
+// 関連DD: DD-CLI-006, DD-BE-002, DD-DATA-005, DD-PERSIST-004
+func diagnoseProject(root string, validator *schema.Validator, currentMode mod.Mode) ([]doctorFinding, error) {
+	failures, _, err := validateProject(root, validator)
+	if err != nil {
+		return nil, err
+	}
+	var findings []doctorFinding
+	for _, failure := range failures {
+		message := failure.Message
+		if failure.InstanceLocation != "" {
+			message = failure.InstanceLocation + ": " + message
+		}
+		findings = append(findings, doctorFinding{Check: checkSchema, Path: failure.Path, Message: message})
+	}
+
+	scanned, err := categoryscan.Scan(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, category := range scanned.Categories {
+		attachmentFindings, checkErr := checkAttachments(root, category)
+		if checkErr != nil {
+			findings = append(findings, doctorFinding{Check: checkOrphanAttachment, Path: category.Name, Message: checkErr.Error()})
+			continue
+		}
+		findings = append(findings, attachmentFindings...)
+	}
+
+	residues, err := tmpresidue.Find(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, residue := range residues {
+		path := residue.Path
+		findings = append(findings, doctorFinding{
+			Check:   checkTmpResidue,
+			Path:    relPath(root, path),
+			Message: "temporary file left by an interrupted write (modified " + residue.ModTime.Format(time.RFC3339) + ")",
+			fix:     func() error { return os.Remove(path) },
+		})
+	}
+
+	service := categoryops.NewService(root)
+	for _, category := range scanned.Categories {
+		if !category.IsReadOnly || category.IsArchived {
+			continue
+		}
+		name := category.Name
+		findings = append(findings, doctorFinding{
+			Check:   checkRenameResidue,
+			Path:    relPath(root, category.Path),
+			Message: "category rename was interrupted; the category is read-only until the rename is completed",
+			fix: func() error {
+				_, recoverErr := service.RecoverRename(name, currentMode)
+				if recoverErr != nil && currentMode != mod.ModeContractor {
+					return fmt.Errorf("%w (completing a rename requires --contractor)", recoverErr)
+				}
+				return recoverErr
+			},
+		})
+	}
+
+	for i := range findings {
+		findings[i].Fixable = findings[i].fix != nil
+	}
+	return findings, nil
+}
+
+// checkAttachments は DD-CLI-006/DD-DATA-005 のカテゴリ内の添付の欠落と、参照されない添付ファイルを検出する。
+// 解析できない課題JSONの添付ディレクトリは参照を判定できないため対象外とする (スキーマ検査で報告される)。
+func checkAttachments(root string, category categoryscan.Category) ([]doctorFinding, error) {
+	entries, err := os.ReadDir(category.Path)
+	if err != nil {
+		return nil, fmt.Errorf("read category: %w", err)
+	}
+	var findings []doctorFinding
+	referenced := make(map[string]bool)
+	issueFiles := make(map[string]bool)
+	unreadable := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() || !issue.IsIssueFileName(entry.Name()) {
+			continue
+		}
+		issueID := strings.TrimSuffix(entry.Name(), ".json")
+		issueFiles[issueID] = true
+		// #nosec G304 -- カテゴリ配下の列挙結果から生成したパスのみを読む。
+		data, readErr := os.ReadFile(filepath.Join(category.Path, entry.Name()))
+		var parsed issue.Issue
+		if readErr != nil || json.Unmarshal(data, &parsed) != nil {
+			unreadable[issueID] = true
+			continue
+		}
+		for _, comment := range parsed.Comments {
+			for _, ref := range comment.Attachments {
+				referenced[ref.RelativePath] = true
+				full := filepath.Join(category.Path, filepath.FromSlash(ref.RelativePath))
+				if _, statErr := os.Stat(full); errors.Is(statErr, os.ErrNotExist) {
+					findings = append(findings, doctorFinding{
+						Check:   checkMissingAttachment,
+						Path:    relPath(root, full),
+						Message: fmt.Sprintf("attachment %q of comment %s in issue %s is missing", ref.FileName, comment.CommentID, issueID),
+					})
+				}
+			}
+		}
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasSuffix(entry.Name(), attachmentDirSuffix) {
+			continue
+		}
+		issueID := strings.TrimSuffix(entry.Name(), attachmentDirSuffix)
+		if unreadable[issueID] {
+			continue
+		}
+		dirPath := filepath.Join(category.Path, entry.Name())
+		files, readErr := os.ReadDir(dirPath)
+		if readErr != nil {
+			return nil, fmt.Errorf("read attachments: %w", readErr)
+		}
+		for _, file := range files {
+			if file.IsDir() || tmpresidue.IsArtifact(file.Name()) {
+				continue
+			}
+			rel := entry.Name() + "/" + file.Name()
+			if referenced[rel] {
+				continue
+			}
+			message := "attachment is not referenced by any comment"
+			if !issueFiles[issueID] {
+				message = "attachment belongs to issue " + issueID + ", which does not exist"
+			}
+			finding := doctorFinding{Check: checkOrphanAttachment, Path: relPath(root, filepath.Join(dirPath, file.Name())), Message: message}
+			if !category.IsReadOnly {
+				source := filepath.Join(dirPath, file.Name())
+				dest := filepath.Join(projectmeta.Dir(root), orphanDirName, category.Name, entry.Name(), file.Name())
+				finding.fix = func() error { return moveOrphan(source, dest) }
+			}
+			findings = append(findings, finding)
+		}
+	}
+	return findings, nil
+}
+
+// moveOrphan は DD-CLI-006 の参照されない添付ファイルを退避先へ移動する。退避先に同名のファイルがある場合は上書きしない。
+func moveOrphan(source, dest string) error {
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("conflict: %s already exists", dest)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o750); err != nil {
+		return fmt.Errorf("create orphan dir: %w", err)
+	}
+	if err := os.Rename(source, dest); err != nil {
+		return fmt.Errorf("move orphan: %w", err)
+	}
+	return nil
+}
+
+// applyFixes は DD-CLI-006 の修復可能な問題を検査順に修復し、結果を各問題に記録する。1件の失敗で残りの修復を止めない。
+func applyFixes(findings []doctorFinding) {
+	for i := range findings {
+		if findings[i].fix == nil {
+			continue
+		}
+		if err := findings[i].fix(); err != nil {
+			findings[i].FixError = err.Error()
+			continue
+		}
+		findings[i].Fixed = true
+	}
+}
+
+// writeDoctorTable は DD-CLI-006 の検査結果を1行1件のタブ区切りで書く。
+// 状態は修復済み (fixed)、修復失敗 (fix-failed)、--fix で修復可能 (fixable)、手作業が必要 (manual) のいずれかとする。
+func writeDoctorTable(w io.Writer, findings []doctorFinding) error {
+	for _, finding := range findings {
+		state, message := "manual", finding.Message
+		switch {
+		case finding.Fixed:
+			state = "fixed"
+		case finding.FixError != "":
+			state, message = "fix-failed", message+": "+finding.FixError
+		case finding.Fixable:
+			state = "fixable"
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", finding.Check, finding.Path, state, oneLine(message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// relPath は DD-CLI-006 の出力用にプロジェクトルートからの相対パス (区切りは /) を返す。
+func relPath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
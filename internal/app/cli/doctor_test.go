@@ -0,0 +1,99 @@
+// doctor_test.go は doctor サブコマンドの検査項目と修復のテストを行う。
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ratta/internal/app/issueops"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// writeFile はテスト用に親ディレクトリを作成してファイルを書き込む。
+func writeFile(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func TestDoctor_ReportsAndFixesProblems(t *testing.T) {
+	// 添付の欠落・孤立と一時ファイル残骸を報告し、--fix では孤立した添付の退避と残骸の削除のみを行うことを確認する。
+	root, issueID := newProject(t)
+	detail, err := issueops.NewService(root, nil).AddComment("cat", issueID, mod.ModeVendor, issueops.CommentCreateInput{
+		Body:        "with file",
+		AuthorName:  "tester",
+		Attachments: []issueops.CommentAttachmentInput{{OriginalName: "note.txt", Data: []byte("hello"), MimeType: "text/plain"}},
+	})
+	if err != nil {
+		t.Fatalf("AddComment error: %v", err)
+	}
+	missing := detail.Issue.Comments[0].Attachments[0].RelativePath
+	if err := os.Remove(filepath.Join(root, "cat", filepath.FromSlash(missing))); err != nil {
+		t.Fatalf("remove attachment: %v", err)
+	}
+	writeFile(t, filepath.Join(root, "cat", issueID+".files", "stray.txt"), "stray")
+	writeFile(t, filepath.Join(root, "cat", "ghost.files", "lost.txt"), "lost")
+	writeFile(t, filepath.Join(root, "cat", issueID+".json.tmp.1.2"), "{")
+
+	code, stdout, stderr := runCommand(t, "doctor", "--schemas", schemasDir, root)
+	if code != exitFailure {
+		t.Fatalf("expected failure, got %d %q", code, stderr)
+	}
+	for _, want := range []string{
+		"missing_attachment\tcat/" + missing + "\tmanual\t",
+		"orphan_attachment\tcat/" + issueID + ".files/stray.txt\tfixable\t",
+		"orphan_attachment\tcat/ghost.files/lost.txt\tfixable\t",
+		"tmp_residue\tcat/" + issueID + ".json.tmp.1.2\tfixable\t",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Fatalf("expected %q in report: %q", want, stdout)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "cat", issueID+".json.tmp.1.2")); err != nil {
+		t.Fatalf("expected check without --fix to keep files: %v", err)
+	}
+
+	code, stdout, _ = runCommand(t, "doctor", "--schemas", schemasDir, "--fix", "--format", "json", root)
+	if code != exitFailure {
+		t.Fatalf("expected failure for remaining missing attachment, got %d", code)
+	}
+	var report doctorReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if report.Fixed != 3 || report.Remaining != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".ratta", "orphans", "cat", issueID+".files", "stray.txt")); err != nil {
+		t.Fatalf("expected orphan to be moved: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "cat", issueID+".json.tmp.1.2")); !os.IsNotExist(err) {
+		t.Fatalf("expected tmp residue to be removed, err=%v", err)
+	}
+}
+
+func TestDoctor_RenameResidueRequiresContractor(t *testing.T) {
+	// 中断されたカテゴリ名変更は Vendor では修復に失敗し、問題が無いプロジェクトは終了コード 0 となることを確認する。
+	root, _ := newProject(t)
+	if code, _, stderr := runCommand(t, "doctor", "--schemas", schemasDir, root); code != exitOK {
+		t.Fatalf("expected success for clean project, got %d %q", code, stderr)
+	}
+
+	writeFile(t, filepath.Join(root, ".tmp_rename", "renamed", ".keep"), "")
+	code, stdout, _ := runCommand(t, "doctor", "--schemas", schemasDir, "--fix", root)
+	if code != exitFailure || !strings.Contains(stdout, "rename_residue\t.tmp_rename/renamed\tfix-failed\t") ||
+		!strings.Contains(stdout, "--contractor") {
+		t.Fatalf("unexpected result %d: %q", code, stdout)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".tmp_rename", "renamed")); err != nil {
+		t.Fatalf("expected residue to remain: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+// draft.go は課題をメールの下書き (.eml) として出力するサブコマンドを担い、メッセージの組み立ては emldraft に委ねる。
+package cli
+
+import (
+	"fmt"
+
+	"ratta/internal/app/emldraft"
+	"ratta/internal/app/issueops"
+	"ratta/internal/present"
+)
+
+// runDraft は DD-CLI-006 の draft サブコマンドを実行する。
+// 目的: 取引先への連絡メールの下書きを、課題の内容と添付からスクリプトで作成できるようにする。
+// 入力: args は `--output path [--to addresses] <root> <category> <issue-id>`、env は実行環境。
+// 出力: 終了コード。成功時は 0、出力失敗時は 1、引数の不備は 2。
+// エラー: カテゴリや課題が存在しない場合、宛先の不備、添付の読み込み・書き込みの失敗を標準エラーへ書く。
+// 副作用: 出力先へ .eml を書き込み、標準エラーへ添付の数の要約を書く。--json 指定時は標準出力へ出力結果を JSON で書く。
+// 並行性: 単一ゴルーチンで実行する。
+// 不変条件: GUI の ExportIssueEML と同じ内容の下書きを出力する。プロジェクト配下は変更しない。
+// 関連DD: DD-CLI-006, DD-EML-001
+func runDraft(args []string, env Env) int {
+	fs := newFlagSet("draft", env)
+	output := fs.String("output", "", "output .eml path (required)")
+	to := fs.String("to", "", "comma-separated recipient addresses (default: none)")
+	positional, err := parseArgs(fs, args, "root", "category", "issue-id")
+	if err == nil && *output == "" {
+		err = fmt.Errorf("--output is required")
+	}
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+
+	root := positional[0]
+	category, err := findCategory(root, positional[1])
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "draft: %v\n", err)
+		return exitFailure
+	}
+	detail, err := issueops.NewService(root, nil).GetIssue(category.Name, positional[2])
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "draft: %v\n", err)
+		return exitFailure
+	}
+	result, err := emldraft.Write(*output, category.Path, detail.Issue, emldraft.Options{To: *to})
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "draft: %v\n", err)
+		return exitFailure
+	}
+	if env.JSON {
+		if writeErr := writeJSON(env.Stdout, present.ToEmlDraftDTO(result)); writeErr != nil {
+			fmt.Fprintf(env.Stderr, "draft: %v\n", writeErr)
+			return exitFailure
+		}
+	}
+	fmt.Fprintf(env.Stderr, "wrote draft with %d attachments to %s", result.Attachments, result.Path)
+	if result.MissingAttachments > 0 {
+		fmt.Fprintf(env.Stderr, " (%d missing attachments)", result.MissingAttachments)
+	}
+	fmt.Fprintln(env.Stderr)
+	return exitOK
+}
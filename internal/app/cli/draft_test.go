@@ -0,0 +1,57 @@
+// draft_test.go は draft サブコマンドの下書きの出力と引数の検査のテストを行う。
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ratta/internal/present"
+)
+
+func TestDraft_WritesEmlWithAttachment(t *testing.T) {
+	// コメントの添付を埋め込んだ下書きを書き、--json 指定時は出力先と添付の数を返すことを確認する。
+	root, issueID := newProject(t)
+	source := filepath.Join(t.TempDir(), "note.txt")
+	if err := os.WriteFile(source, []byte("hello"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if code, _, stderr := runCommand(t, "comment", "add", "--schemas", schemasDir,
+		"--body", "see attached", "--author", "bot", "--attach", source, root, "cat", issueID); code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+
+	output := filepath.Join(t.TempDir(), "draft.eml")
+	code, stdout, stderr := runCommand(t, "--json", "draft", "--output", output, "--to", "vendor@example.com", root, "cat", issueID)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	var draft present.EmlDraftDTO
+	if err := json.Unmarshal([]byte(stdout), &draft); err != nil {
+		t.Fatalf("unmarshal: %v %q", err, stdout)
+	}
+	if draft.Path != output || draft.Attachments != 1 || draft.MissingAttachments != 0 {
+		t.Fatalf("unexpected draft: %+v", draft)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil || !bytes.Contains(data, []byte("Subject: first\r\n")) || !bytes.Contains(data, []byte("To: <vendor@example.com>\r\n")) {
+		t.Fatalf("unexpected draft:\n%s %v", data, err)
+	}
+}
+
+func TestDraft_RejectsInvalidArguments(t *testing.T) {
+	// 出力先の無い指定は終了コード 2、不正な宛先や存在しない課題は終了コード 1 となることを確認する。
+	root, issueID := newProject(t)
+	if code, _, _ := runCommand(t, "draft", root, "cat", issueID); code != exitUsage {
+		t.Fatalf("expected usage error, got %d", code)
+	}
+	output := filepath.Join(t.TempDir(), "draft.eml")
+	if code, _, _ := runCommand(t, "draft", "--output", output, "--to", "not an address", root, "cat", issueID); code != exitFailure {
+		t.Fatalf("expected failure, got %d", code)
+	}
+	if code, _, _ := runCommand(t, "draft", "--output", output, root, "cat", "missing"); code != exitFailure {
+		t.Fatalf("expected failure, got %d", code)
+	}
+}
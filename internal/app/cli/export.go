@@ -0,0 +1,86 @@
+// export.go は課題一覧を CSV/JSON/JSON Lines やプラグインが追加した形式のファイルへ出力するサブコマンドを担い、出力形式の詳細は issueexport に委ねる。
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"ratta/internal/app/issueexport"
+	"ratta/internal/infra/pluginhost"
+	"ratta/internal/present"
+)
+
+// runExport は DD-CLI-006 の export サブコマンドを実行する。
+// 目的: GUI と同じ出力処理で課題一覧をファイルへ書き出し、定期的な集計や他ツールへの受け渡しに用いる。
+// 入力: args は `--format csv|json|jsonl [--category c]... [--status s]... --output path <root>`、env は実行環境。
+// --output に - を指定した場合は標準出力へ書き出す。--format には plugins/ のプラグインが追加した形式 (DD-PLUGIN-002) も指定できる。
+// 出力: 終了コード。成功時は 0、出力失敗時は 1、引数の不備は 2。
+// エラー: 未知のステータスや存在しないカテゴリの指定、走査・書き込みの失敗を標準エラーへ書く。
+// 副作用: 出力先へファイルを書き込み、標準エラーへ件数の要約を書く。--json 指定時は標準出力へ出力結果を JSON で書く。
+// 標準出力へ書き出す場合は --json を指定できない。
+// 並行性: 単一ゴルーチンで実行する。書き込み用ロックは取得せず、GUI が保存中の課題は DD-PERSIST-001 のアトミック更新により保存前か保存後の内容で出力する。
+// 不変条件: GUI の ExportIssues と同じ条件であれば同じ内容のファイルを出力する。
+// 関連DD: DD-CLI-006, DD-EXPORT-001, DD-PLUGIN-002
+func runExport(args []string, env Env) int {
+	fs := newFlagSet("export", env)
+	format := fs.String("format", string(issueexport.FormatCSV), "export format: csv, json, jsonl or a format added by a plugin")
+	output := fs.String("output", "", "output file path, or - for standard output (required)")
+	var categories, statuses multiFlag
+	fs.Var(&categories, "category", "export only this category (repeatable)")
+	fs.Var(&statuses, "status", "export only issues with this status (repeatable)")
+	positional, err := parseArgs(fs, args, "root")
+	if err == nil && *output == "" {
+		err = fmt.Errorf("--output is required")
+	}
+	if err == nil && *output == "-" && env.JSON {
+		err = fmt.Errorf("--json cannot be used with --output -")
+	}
+	var exportFormat issueexport.Format
+	var exporter *pluginhost.Exporter
+	if err == nil {
+		// 本体の形式を優先し、無い場合のみプラグインの形式を探す。
+		exportFormat, err = issueexport.ParseFormat(*format)
+		if err != nil {
+			var found bool
+			if exporter, found = discoverPlugins(env).Exporter(*format); found {
+				err = nil
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+
+	filter := issueexport.Filter{Categories: categories, Statuses: statuses}
+	var result issueexport.Result
+	switch {
+	case exporter != nil && *output == "-":
+		result, err = issueexport.ExportPluginTo(context.Background(), positional[0], exporter, filter, env.Stdout)
+	case exporter != nil:
+		result, err = issueexport.ExportPlugin(context.Background(), positional[0], exporter, filter, *output)
+	case *output == "-":
+		result, err = issueexport.ExportTo(context.Background(), positional[0], exportFormat, filter, env.Stdout)
+	default:
+		result, err = issueexport.Export(context.Background(), positional[0], exportFormat, filter, *output)
+	}
+	if *output == "-" {
+		result.Path = "standard output"
+	}
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "export: %v\n", err)
+		return exitFailure
+	}
+	if env.JSON {
+		if writeErr := writeJSON(env.Stdout, present.ToIssueExportDTO(result)); writeErr != nil {
+			fmt.Fprintf(env.Stderr, "export: %v\n", writeErr)
+			return exitFailure
+		}
+	}
+	fmt.Fprintf(env.Stderr, "exported %d issues to %s", result.Count, result.Path)
+	if result.Skipped > 0 {
+		fmt.Fprintf(env.Stderr, " (%d unreadable issue files skipped)", result.Skipped)
+	}
+	fmt.Fprintln(env.Stderr)
+	return exitOK
+}
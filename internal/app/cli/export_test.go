@@ -0,0 +1,50 @@
+// export_test.go は export サブコマンドの出力と引数の検査のテストを行う。
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ratta/internal/app/issueexport"
+)
+
+func TestExport_WritesSameFileAsSharedExporter(t *testing.T) {
+	// GUI と共通の出力処理と同じ内容のファイルを書くことを確認する。
+	root, _ := newProject(t)
+	dir := t.TempDir()
+	cliPath := filepath.Join(dir, "cli.json")
+	code, _, stderr := runCommand(t, "export", "--format", "json", "--category", "cat", "--output", cliPath, root)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	sharedPath := filepath.Join(dir, "shared.json")
+	if _, err := issueexport.Export(context.Background(), root, issueexport.FormatJSON, issueexport.Filter{Categories: []string{"cat"}}, sharedPath); err != nil {
+		t.Fatalf("Export error: %v", err)
+	}
+	cliData, err := os.ReadFile(cliPath)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	sharedData, err := os.ReadFile(sharedPath)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !bytes.Equal(cliData, sharedData) {
+		t.Fatalf("expected identical files:\n%s\n%s", cliData, sharedData)
+	}
+}
+
+func TestExport_RejectsMissingOutputAndUnknownStatus(t *testing.T) {
+	// 出力先の無い指定は終了コード 2、未知のステータスは終了コード 1 となることを確認する。
+	root, _ := newProject(t)
+	if code, _, _ := runCommand(t, "export", root); code != exitUsage {
+		t.Fatalf("expected usage error, got %d", code)
+	}
+	output := filepath.Join(t.TempDir(), "out.csv")
+	if code, _, _ := runCommand(t, "export", "--status", "Done", "--output", output, root); code != exitFailure {
+		t.Fatalf("expected failure, got %d", code)
+	}
+}
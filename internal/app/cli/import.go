@@ -0,0 +1,241 @@
+// import.go は CSV ファイルから課題を一括登録するサブコマンドを担い、課題の更新やコメントの取り込みは扱わない。
+// 既存の表計算ソフトでの課題管理から移行できるよう、列は見出し名で対応付け、未知の列は無視する。
+package cli
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"ratta/internal/app/categoryscan"
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/gitcommit"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// importColumns は DD-CLI-006 の CSV 取り込みで読み取る列見出しを表す。title 以外は省略できる。
+var importColumns = []string{"title", "description", "due_date", "priority", "assignee", "category"}
+
+// importRow は DD-CLI-006 の CSV の1行分の課題作成入力を表す。Line はファイル上の行番号を表す。
+type importRow struct {
+	Line     int
+	Category string
+	Input    issueops.IssueCreateInput
+}
+
+// importResult は DD-CLI-006 の1行分の取り込み結果を表す。Status は created・ok (dry-run で検証済み)・failed のいずれか。
+type importResult struct {
+	Line    int    `json:"line"`
+	Status  string `json:"status"`
+	IssueID string `json:"issue_id,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// importReport は DD-CLI-006 の --json 指定時の import csv の出力を表す。
+type importReport struct {
+	DryRun    bool           `json:"dry_run"`
+	Succeeded int            `json:"succeeded"`
+	Failed    int            `json:"failed"`
+	Rows      []importResult `json:"rows"`
+}
+
+// runImportCSV は DD-CLI-006 の import csv サブコマンドを実行する。
+// 目的: 表計算ソフトで管理していた課題を GUI と同じ規則でプロジェクトへ一括登録する。
+// 入力: args は `[--category name] [--dry-run] [--contractor] [--schemas dir] <root> <file.csv>`、env は実行環境。
+// 出力: 終了コード。全行を登録 (dry-run では検証) できれば 0、失敗した行がある場合や CSV を読めない場合は 1、引数の不備は 2。
+// エラー: 行ごとの失敗は標準出力の結果に含め、残りの行の処理を続ける。
+// 副作用: 課題JSONを作成し、標準出力へ1行1件のタブ区切り (行番号, 結果, 課題IDまたはメッセージ) を
+// (--json 指定時は件数と行ごとの結果を JSON で)、標準エラーへ件数の要約を書く。dry-run では課題を作成しない。
+// 並行性: 書き込み用ロックを取得して実行し、GUI が開いている間は登録しない。dry-run はロックを取得しない。
+// 不変条件: 行は記載順に1件ずつ登録し、失敗した行があっても登録済みの課題は取り消さない。
+// 関連DD: DD-CLI-006, DD-BE-003, DD-CATMETA-003, DD-LOCK-002
+func runImportCSV(args []string, env Env) int {
+	fs := newFlagSet("import csv", env)
+	defaultCategory := fs.String("category", "", "category for rows without a category column value")
+	dryRun := fs.Bool("dry-run", false, "validate rows without creating issues")
+	contractor := fs.Bool("contractor", false, "operate in contractor mode (password from "+contractorPasswordEnv+" or prompt)")
+	schemasDir := fs.String("schemas", "", "directory containing the JSON schemas")
+	positional, err := parseArgs(fs, args, "root", "file.csv")
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	rows, err := readImportFile(positional[1], *defaultCategory)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "import csv: %v\n", err)
+		return exitFailure
+	}
+	validator, err := optionalValidator(env, *schemasDir)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "load schemas: %v\n", err)
+		return exitUsage
+	}
+	currentMode, err := resolveMode(env, *contractor, validator)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "import csv: %v\n", err)
+		return exitFailure
+	}
+
+	root := positional[0]
+	report := importReport{DryRun: *dryRun, Rows: []importResult{}}
+	err = writeLockUnlessDryRun(*dryRun)(root, func() error {
+		scanned, scanErr := categoryscan.Scan(root)
+		if scanErr != nil {
+			return scanErr
+		}
+		categories := make(map[string]bool, len(scanned.Categories))
+		for _, category := range scanned.Categories {
+			categories[category.Name] = true
+		}
+		service := issueops.NewService(root, validator)
+		for _, row := range rows {
+			result := importOne(service, categories, row, currentMode, *dryRun)
+			if result.Status == "failed" {
+				report.Failed++
+			} else {
+				report.Succeeded++
+			}
+			report.Rows = append(report.Rows, result)
+		}
+		if !*dryRun && report.Succeeded > 0 {
+			commitChange(env, root, currentMode, gitcommit.Change{Operation: gitOperationIssuesImported})
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "import csv: %v\n", err)
+		return exitFailure
+	}
+	if env.JSON {
+		if writeErr := writeJSON(env.Stdout, report); writeErr != nil {
+			fmt.Fprintf(env.Stderr, "import csv: %v\n", writeErr)
+			return exitFailure
+		}
+	} else {
+		for _, result := range report.Rows {
+			value := result.IssueID
+			if result.Status == "failed" {
+				value = oneLine(result.Message)
+			}
+			if value == "" {
+				fmt.Fprintf(env.Stdout, "%d\t%s\n", result.Line, result.Status)
+			} else {
+				fmt.Fprintf(env.Stdout, "%d\t%s\t%s\n", result.Line, result.Status, value)
+			}
+		}
+	}
+	if *dryRun {
+		fmt.Fprintf(env.Stderr, "dry run: %d rows valid, %d rows failed, nothing created\n", report.Succeeded, report.Failed)
+	} else {
+		fmt.Fprintf(env.Stderr, "created %d issues, %d rows failed\n", report.Succeeded, report.Failed)
+	}
+	if report.Failed > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// importOne は DD-CLI-006 の1行分の課題を登録 (dry-run では検証) し、結果を返す。
+func importOne(service *issueops.Service, categories map[string]bool, row importRow, currentMode mod.Mode, dryRun bool) importResult {
+	issueID, err := createRow(service, categories, row, currentMode, dryRun)
+	if err != nil {
+		return importResult{Line: row.Line, Status: "failed", Message: err.Error()}
+	}
+	if dryRun {
+		return importResult{Line: row.Line, Status: "ok"}
+	}
+	return importResult{Line: row.Line, Status: "created", IssueID: issueID}
+}
+
+// createRow は DD-CLI-006 の1行分の課題を登録 (dry-run では検証のみ) し、作成した課題IDを返す。
+func createRow(service *issueops.Service, categories map[string]bool, row importRow, currentMode mod.Mode, dryRun bool) (string, error) {
+	if row.Category == "" {
+		return "", errors.New("category is required")
+	}
+	if !categories[row.Category] {
+		return "", fmt.Errorf("category not found: %s", row.Category)
+	}
+	if dryRun {
+		return "", service.CheckCreateIssue(row.Category, currentMode, row.Input)
+	}
+	created, err := service.CreateIssue(row.Category, currentMode, row.Input)
+	if err != nil {
+		return "", err
+	}
+	return created.Issue.IssueID, nil
+}
+
+// readImportFile は DD-CLI-006 の CSV ファイルを読み、行ごとの課題作成入力を返す。
+// 1行目は見出しとし、BOM 付き UTF-8 (ratta の export の出力や表計算ソフトの保存形式) も受け付ける。
+func readImportFile(path, defaultCategory string) ([]importRow, error) {
+	// #nosec G304 -- 利用者がコマンドラインで指定した取り込み元のファイルを読む。
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open csv: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := index["title"]; !ok {
+		return nil, fmt.Errorf("csv header must contain a title column (supported columns: %s)", strings.Join(importColumns, ", "))
+	}
+	_, hasCategory := index["category"]
+	if !hasCategory && defaultCategory == "" {
+		return nil, errors.New("csv has no category column; specify --category")
+	}
+
+	var rows []importRow
+	for {
+		record, readErr := reader.Read()
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("read csv: %w", readErr)
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			i, ok := index[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			if name == "description" {
+				return record[i]
+			}
+			return strings.TrimSpace(record[i])
+		}
+		category := field("category")
+		if category == "" {
+			category = defaultCategory
+		}
+		rows = append(rows, importRow{
+			Line:     line,
+			Category: category,
+			Input: issueops.IssueCreateInput{
+				Title:       field("title"),
+				Description: field("description"),
+				DueDate:     field("due_date"),
+				Priority:    issue.Priority(field("priority")),
+				Assignee:    field("assignee"),
+			},
+		})
+	}
+	return rows, nil
+}
@@ -0,0 +1,103 @@
+// import_test.go は import csv サブコマンドの行ごとの検証・登録と dry-run のテストを行う。
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeCSV はテスト用の CSV ファイルを書き込み、パスを返す。
+func writeCSV(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "issues.csv")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	return path
+}
+
+// issueFileCount はテスト用にカテゴリ cat の課題JSONの数を返す。
+func issueFileCount(t *testing.T, root string) int {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(root, "cat", "*.json"))
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	return len(matches)
+}
+
+func TestImportCSV_ReportsRowsAndCreatesValidOnes(t *testing.T) {
+	// 有効な行を登録し、無効な行は行番号とメッセージを報告して終了コード 1 とすることを確認する。
+	root, _ := newProject(t)
+	path := writeCSV(t, "\ufeffTitle,description,due_date,priority,assignee,category,extra\n"+
+		"imported,\"multi\nline\",2024-03-01,High,alice,cat,ignored\n"+
+		",no title,2024-03-01,Low,,cat,\n"+
+		"wrong category,desc,2024-03-01,Low,,missing,\n")
+
+	code, stdout, stderr := runCommand(t, "import", "csv", "--schemas", schemasDir, root, path)
+	if code != exitFailure {
+		t.Fatalf("expected failure for invalid rows, got %d %q", code, stderr)
+	}
+	lines := strings.Split(strings.TrimRight(stdout, "\n"), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "2\tcreated\t") ||
+		!strings.HasPrefix(lines[1], "4\tfailed\t") || !strings.HasPrefix(lines[2], "5\tfailed\tcategory not found") {
+		t.Fatalf("unexpected report: %q", stdout)
+	}
+	if !strings.Contains(stderr, "created 1 issues, 2 rows failed") {
+		t.Fatalf("unexpected summary: %q", stderr)
+	}
+	if got := issueFileCount(t, root); got != 2 {
+		t.Fatalf("expected one imported issue, got %d files", got)
+	}
+}
+
+func TestImportCSV_DryRunCreatesNothing(t *testing.T) {
+	// dry-run は既定カテゴリを用いて全行を検証し、課題を作成しないことを確認する。
+	root, _ := newProject(t)
+	path := writeCSV(t, "title,description,due_date,priority\nfirst,desc,2024-03-01,Low\nsecond,desc,2024-03-02,Medium\n")
+
+	code, stdout, stderr := runCommand(t, "import", "csv", "--schemas", schemasDir, "--dry-run", "--category", "cat", root, path)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q %q", code, stdout, stderr)
+	}
+	if stdout != "2\tok\n3\tok\n" || !strings.Contains(stderr, "nothing created") {
+		t.Fatalf("unexpected output: %q %q", stdout, stderr)
+	}
+	if got := issueFileCount(t, root); got != 1 {
+		t.Fatalf("expected no new issues, got %d files", got)
+	}
+}
+
+func TestImportCSV_RequiresTitleAndCategory(t *testing.T) {
+	// title 列の無い CSV と、カテゴリの列も指定も無い CSV は登録せず終了コード 1 となることを確認する。
+	root, _ := newProject(t)
+	for _, content := range []string{"name,category\nx,cat\n", "title\nx\n"} {
+		if code, _, _ := runCommand(t, "import", "csv", root, writeCSV(t, content)); code != exitFailure {
+			t.Fatalf("expected failure for %q, got %d", content, code)
+		}
+	}
+}
+
+func TestImportCSV_JSONReportsRows(t *testing.T) {
+	// --json 指定時は行ごとの結果と件数を JSON で出力することを確認する。
+	root, _ := newProject(t)
+	path := writeCSV(t, "title,description,due_date,priority,category\nok,desc,2024-03-01,Low,cat\nbad,desc,2024-03-01,Low,missing\n")
+
+	code, stdout, _ := runCommand(t, "--json", "import", "csv", "--schemas", schemasDir, "--dry-run", root, path)
+	if code != exitFailure {
+		t.Fatalf("expected failure for the invalid row, got %d", code)
+	}
+	var report importReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("expected JSON output: %v %q", err, stdout)
+	}
+	if !report.DryRun || report.Succeeded != 1 || report.Failed != 1 || len(report.Rows) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if report.Rows[0].Status != "ok" || report.Rows[1].Status != "failed" || !strings.Contains(report.Rows[1].Message, "category not found") {
+		t.Fatalf("unexpected rows: %+v", report.Rows)
+	}
+}
@@ -0,0 +1,99 @@
+// importjsonl.go は JSON Lines 形式の課題を一括で取り込むサブコマンドを担い、行の検証と保存の詳細は issueimport に委ねる。
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"ratta/internal/app/issueimport"
+	"ratta/internal/app/issueops"
+	"ratta/internal/infra/gitcommit"
+	"ratta/internal/present"
+)
+
+// runImportJSONL は DD-CLI-006 の import jsonl サブコマンドを実行する。
+// 目的: export --format jsonl の出力や分析ツールで加工した課題を、個別のスクリプトを書かずに検証して一括で登録する。
+// 入力: args は `[--category name] [--overwrite] [--dry-run] [--contractor] [--schemas dir] <root> <file.jsonl>`、env は実行環境。
+// file.jsonl に - を指定した場合は標準入力から読む。
+// 出力: 終了コード。全行を登録 (dry-run では検証) できれば 0、失敗した行がある場合や読み込めない場合は 1、引数の不備は 2。
+// エラー: 行ごとの失敗は標準出力の結果に含め、残りの行の処理を続ける。
+// 副作用: 課題JSONを作成・上書きし、標準出力へ1行1件のタブ区切り (行番号, 結果, 課題IDまたはメッセージ) を
+// (--json 指定時は件数と行ごとの結果を JSON で)、標準エラーへ件数の要約を書く。dry-run では課題を保存しない。
+// 並行性: 書き込み用ロックを取得して実行し、GUI が開いている間は登録しない。dry-run はロックを取得しない。
+// 不変条件: GUI の StartImportIssuesJSONL と同じ規則で取り込む。行は記載順に1件ずつ登録し、失敗した行があっても登録済みの課題は取り消さない。
+// 関連DD: DD-CLI-006, DD-IMPORT-001, DD-LOCK-002
+func runImportJSONL(args []string, env Env) int {
+	fs := newFlagSet("import jsonl", env)
+	category := fs.String("category", "", "category to import into (default: the category field of each line)")
+	overwrite := fs.Bool("overwrite", false, "overwrite issues whose issue_id already exists")
+	dryRun := fs.Bool("dry-run", false, "validate lines without saving issues")
+	contractor := fs.Bool("contractor", false, "operate in contractor mode (password from "+contractorPasswordEnv+" or prompt)")
+	schemasDir := fs.String("schemas", "", "directory containing the JSON schemas")
+	positional, err := parseArgs(fs, args, "root", "file.jsonl")
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	validator, err := optionalValidator(env, *schemasDir)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "load schemas: %v\n", err)
+		return exitUsage
+	}
+	currentMode, err := resolveMode(env, *contractor, validator)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "import jsonl: %v\n", err)
+		return exitFailure
+	}
+	var input io.Reader = env.Stdin
+	if positional[1] != "-" {
+		// #nosec G304 -- 利用者がコマンドラインで指定した取り込み元のファイルを読む。
+		file, openErr := os.Open(positional[1])
+		if openErr != nil {
+			fmt.Fprintf(env.Stderr, "import jsonl: %v\n", openErr)
+			return exitFailure
+		}
+		defer func() { _ = file.Close() }()
+		input = file
+	}
+
+	root := positional[0]
+	opts := issueimport.Options{Category: *category, Overwrite: *overwrite, DryRun: *dryRun}
+	var result issueimport.Result
+	err = writeLockUnlessDryRun(*dryRun)(root, func() error {
+		var importErr error
+		result, importErr = issueimport.Import(context.Background(), issueops.NewService(root, validator), currentMode, input, opts)
+		if importErr == nil && !*dryRun && result.Created+result.Updated > 0 {
+			commitChange(env, root, currentMode, gitcommit.Change{Operation: gitOperationIssuesImported, Category: *category})
+		}
+		return importErr
+	})
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "import jsonl: %v\n", err)
+		return exitFailure
+	}
+	if env.JSON {
+		if writeErr := writeJSON(env.Stdout, present.ToIssueImportDTO(result)); writeErr != nil {
+			fmt.Fprintf(env.Stderr, "import jsonl: %v\n", writeErr)
+			return exitFailure
+		}
+	} else {
+		for _, row := range result.Rows {
+			value := row.IssueID
+			if row.Status == issueimport.RowFailed {
+				value = oneLine(row.Message)
+			}
+			fmt.Fprintf(env.Stdout, "%d\t%s\t%s\n", row.Line, row.Status, value)
+		}
+	}
+	if *dryRun {
+		fmt.Fprintf(env.Stderr, "dry run: %d lines valid, %d lines failed, nothing saved\n", result.Created+result.Updated, result.Failed)
+	} else {
+		fmt.Fprintf(env.Stderr, "created %d, updated %d issues, %d lines failed\n", result.Created, result.Updated, result.Failed)
+	}
+	if result.Failed > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
@@ -0,0 +1,76 @@
+// importjsonl_test.go は import jsonl サブコマンドの取り込み・標準入力からの読み込み・引数の検査のテストを行う。
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ratta/internal/present"
+)
+
+func TestImportJSONL_ImportsExportedLines(t *testing.T) {
+	// export --format jsonl の標準出力を別カテゴリへ取り込み、同じ課題IDの再取り込みは --overwrite 指定時のみ更新とすることを確認する。
+	root, issueID := newProject(t)
+	if err := os.MkdirAll(filepath.Join(root, "dst"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	code, lines, stderr := runCommand(t, "export", "--format", "jsonl", "--output", "-", root)
+	if code != exitOK || strings.Count(lines, "\n") != 1 || !strings.Contains(stderr, "to standard output") {
+		t.Fatalf("unexpected export: %d %q %q", code, lines, stderr)
+	}
+	source := filepath.Join(t.TempDir(), "issues.jsonl")
+	if err := os.WriteFile(source, []byte(lines), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	code, stdout, stderr := runCommand(t, "import", "jsonl", "--schemas", schemasDir, "--category", "dst", "--dry-run", root, source)
+	if code != exitOK || stdout != "1\tcreated\t"+issueID+"\n" || !strings.Contains(stderr, "nothing saved") {
+		t.Fatalf("unexpected dry run: %d %q %q", code, stdout, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(root, "dst", issueID+".json")); !os.IsNotExist(err) {
+		t.Fatalf("dry run must not save: %v", err)
+	}
+	code, stdout, stderr = runCommand(t, "--json", "import", "jsonl", "--schemas", schemasDir, "--category", "dst", root, source)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	var imported present.IssueImportDTO
+	if err := json.Unmarshal([]byte(stdout), &imported); err != nil {
+		t.Fatalf("unmarshal: %v %q", err, stdout)
+	}
+	if imported.Created != 1 || imported.Rows[0].Category != "dst" || imported.Rows[0].IssueID != issueID {
+		t.Fatalf("unexpected import: %+v", imported)
+	}
+	if code, stdout, _ = runCommand(t, "import", "jsonl", "--schemas", schemasDir, "--category", "dst", root, source); code != exitFailure || !strings.Contains(stdout, "already exists") {
+		t.Fatalf("expected conflict, got %d %q", code, stdout)
+	}
+
+	var out, errOut bytes.Buffer
+	_, code = Run([]string{"import", "jsonl", "--schemas", schemasDir, "--category", "dst", "--overwrite", root, "-"},
+		Env{Stdin: strings.NewReader(lines), Stdout: &out, Stderr: &errOut})
+	if code != exitOK || out.String() != "1\tupdated\t"+issueID+"\n" {
+		t.Fatalf("unexpected stdin import: %d %q %q", code, out.String(), errOut.String())
+	}
+}
+
+func TestImportJSONL_RejectsInvalidArguments(t *testing.T) {
+	// 取り込み元の無い指定や --json と標準出力への出力の併用は終了コード 2、読めない行は終了コード 1 となることを確認する。
+	root, _ := newProject(t)
+	if code, _, _ := runCommand(t, "import", "jsonl", root); code != exitUsage {
+		t.Fatalf("expected usage error, got %d", code)
+	}
+	if code, _, _ := runCommand(t, "--json", "export", "--format", "jsonl", "--output", "-", root); code != exitUsage {
+		t.Fatalf("expected usage error, got %d", code)
+	}
+	source := filepath.Join(t.TempDir(), "broken.jsonl")
+	if err := os.WriteFile(source, []byte("{broken\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if code, stdout, _ := runCommand(t, "import", "jsonl", "--schemas", schemasDir, root, source); code != exitFailure || !strings.HasPrefix(stdout, "1\tfailed\t") {
+		t.Fatalf("expected failed line, got %d %q", code, stdout)
+	}
+}
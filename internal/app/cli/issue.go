@@ -0,0 +1,98 @@
+// issue.go は課題を作成するサブコマンドを担い、課題の更新や状態遷移は扱わない。
+package cli
+
+import (
+	"fmt"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/configrepo"
+	"ratta/internal/infra/gitcommit"
+	"ratta/internal/infra/projectmeta"
+)
+
+// runIssueCreate は DD-CLI-006 の issue create サブコマンドを実行する。
+// 目的: スクリプトから GUI と同じ規則で課題をまとめて登録できるようにする。
+// 入力: args は `--title t [--description d] [--due-date yyyy-mm-dd] [--priority p] [--assignee a]
+// [--contractor] [--format table|json] [--schemas dir] <root> <category>`、env は実行環境。
+// 出力: 終了コード。成功時は 0、作成失敗時は 1、引数の不備は 2。
+// エラー: モードの決定、書き込み用ロックの取得、入力検証、保存に失敗した場合は標準エラーへ書く。
+// 副作用: 課題JSONを作成し、標準出力へ課題IDを (json 形式では課題JSONを) 書く。
+// プロジェクト設定で git の自動コミットが有効な場合は作成した課題をコミットし、通知先がある場合は変更を通知し、
+// config.json のフックと plugins/ の保存後の処理を実行する。
+// 並行性: 書き込み用ロックを取得して実行し、GUI が開いている間は作成しない。
+// 不変条件: 空の入力項目にはカテゴリの既定値を適用する。起票会社は操作モードで決まる。
+// GUI と同じく plugins/ の検証を行うプラグインで保存前に検証する。
+// 関連DD: DD-CLI-006, DD-BE-003, DD-CATMETA-002, DD-LOCK-002, DD-HOOK-003, DD-PLUGIN-003
+func runIssueCreate(args []string, env Env) int {
+	fs := newFlagSet("issue create", env)
+	title := fs.String("title", "", "issue title (required)")
+	description := fs.String("description", "", "issue description")
+	dueDate := fs.String("due-date", "", "due date (YYYY-MM-DD)")
+	priority := fs.String("priority", "", "priority: High, Medium or Low")
+	assignee := fs.String("assignee", "", "assignee name")
+	contractor := fs.Bool("contractor", false, "operate in contractor mode (password from "+contractorPasswordEnv+" or prompt)")
+	format := fs.String("format", defaultFormat(env), "output format: table or json (default json with --json)")
+	schemasDir := fs.String("schemas", "", "directory containing the JSON schemas")
+	positional, err := parseArgs(fs, args, "root", "category")
+	if err == nil && *title == "" {
+		err = fmt.Errorf("--title is required")
+	}
+	if err == nil {
+		err = checkFormat(*format)
+	}
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	validator, err := optionalValidator(env, *schemasDir)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "load schemas: %v\n", err)
+		return exitUsage
+	}
+	currentMode, err := resolveMode(env, *contractor, validator)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "issue create: %v\n", err)
+		return exitFailure
+	}
+
+	root := positional[0]
+	plugins := discoverPlugins(env)
+	var created issueops.IssueDetail
+	err = withWriteLock(root, func() error {
+		category, findErr := findCategory(root, positional[1])
+		if findErr != nil {
+			return findErr
+		}
+		var createErr error
+		created, createErr = issueops.NewService(root, validator).WithRuleChecker(pluginRules(plugins)).CreateIssue(category.Name, currentMode, issueops.IssueCreateInput{
+			Title:       *title,
+			Description: *description,
+			DueDate:     *dueDate,
+			Priority:    issue.Priority(*priority),
+			Assignee:    *assignee,
+		})
+		if createErr != nil {
+			return createErr
+		}
+		commitChange(env, root, currentMode, gitcommit.Change{Operation: gitOperationIssueCreated, Category: category.Name, IssueID: created.Issue.IssueID})
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "issue create: %v\n", err)
+		return exitFailure
+	}
+	notifyWebhooks(env, root, currentMode, projectmeta.WebhookEventIssueCreated, "", created)
+	runPostSave(env, root, currentMode, plugins, "", created.Issue, configrepo.HookEventIssueCreated)
+
+	if *format == formatJSON {
+		err = writeIssueJSON(env.Stdout, created.Issue)
+	} else {
+		_, err = fmt.Fprintln(env.Stdout, created.Issue.IssueID)
+	}
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "issue create: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
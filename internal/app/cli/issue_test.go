@@ -0,0 +1,176 @@
+// issue_test.go は issue create サブコマンドの操作モードの決定と書き込み用ロックのテストを行う。
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/infra/crypto"
+	"ratta/internal/infra/jsonfmt"
+	"ratta/internal/infra/projectlock"
+	"ratta/internal/infra/projectmeta"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// writeContractorAuth はテスト用に実行ファイルのパスと、その隣の auth/contractor.json を用意する。
+func writeContractorAuth(t *testing.T, password string) string {
+	t.Helper()
+	dir := t.TempDir()
+	auth, err := crypto.GenerateContractorAuth(password)
+	if err != nil {
+		t.Fatalf("GenerateContractorAuth error: %v", err)
+	}
+	data, err := jsonfmt.MarshalContractor(auth)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "auth"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "auth", "contractor.json"), data, 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	return filepath.Join(dir, "ratta.exe")
+}
+
+func TestIssueCreate_CreatesIssueAsVendorByDefault(t *testing.T) {
+	// 既定では Vendor として課題を作成し、課題IDを出力してロックファイルを残さないことを確認する。
+	root, _ := newProject(t)
+	code, stdout, stderr := runCommand(t, "issue", "create", "--schemas", schemasDir,
+		"--title", "from script", "--description", "desc", "--due-date", "2024-02-01", "--priority", "High", root, "cat")
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	issueID := strings.TrimSpace(stdout)
+	detail, err := issueops.NewService(root, nil).GetIssue("cat", issueID)
+	if err != nil {
+		t.Fatalf("GetIssue error: %v", err)
+	}
+	if detail.Issue.Title != "from script" || detail.Issue.OriginCompany != "Vendor" {
+		t.Fatalf("unexpected issue: %+v", detail.Issue)
+	}
+	if _, err := os.Stat(filepath.Join(root, projectlock.FileName)); !os.IsNotExist(err) {
+		t.Fatalf("expected lock to be released, got %v", err)
+	}
+}
+
+func TestIssueCreate_CommitsToGitWhenAutoCommitIsEnabled(t *testing.T) {
+	// プロジェクト設定で自動コミットが有効な場合、作成した課題を操作名と課題IDを含むメッセージでコミットすることを確認する。
+	root, _ := newProject(t)
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatalf("PlainInit error: %v", err)
+	}
+	writeFile(t, projectmeta.ConfigPath(root), `{"format_version": 1, "git": {"auto_commit": true, "author_name": "script", "author_email": "script@example.com"}}`)
+	code, stdout, stderr := runCommand(t, "issue", "create", "--schemas", schemasDir, "--title", "committed",
+		"--description", "desc", "--due-date", "2024-02-01", "--priority", "High", root, "cat")
+	if code != exitOK || stderr != "" {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Head error: %v", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatalf("CommitObject error: %v", err)
+	}
+	issueID := strings.TrimSpace(stdout)
+	if !strings.HasPrefix(commit.Message, "ratta: issue_created cat/"+issueID+"\n") || !strings.Contains(commit.Message, "\nMode: Vendor\n") {
+		t.Fatalf("unexpected message:\n%s", commit.Message)
+	}
+	if commit.Author.Email != "script@example.com" {
+		t.Fatalf("unexpected author: %+v", commit.Author)
+	}
+	if _, err := commit.File("cat/" + issueID + ".json"); err != nil {
+		t.Fatalf("expected issue file in the commit: %v", err)
+	}
+}
+
+func TestIssueCreate_ResolvesContractorModeFromPassword(t *testing.T) {
+	// Contractor を要求した場合は環境変数のパスワードを検証し、一致しなければ作成せず、照合の成否を監査ログに残すことを確認する。
+	root, _ := newProject(t)
+	exePath := writeContractorAuth(t, "secret")
+	args := []string{"issue", "create", "--schemas", schemasDir, "--contractor",
+		"--title", "by contractor", "--description", "desc", "--due-date", "2024-02-01", "--priority", "Low", root, "cat"}
+
+	var stdout, stderr bytes.Buffer
+	t.Setenv(contractorPasswordEnv, "wrong")
+	if _, code := Run(args, Env{ExePath: exePath, Stdout: &stdout, Stderr: &stderr}); code != exitFailure {
+		t.Fatalf("expected failure for wrong password, got %d", code)
+	}
+
+	t.Setenv(contractorPasswordEnv, "secret")
+	stdout.Reset()
+	if _, code := Run(args, Env{ExePath: exePath, Stdout: &stdout, Stderr: &stderr}); code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr.String())
+	}
+	detail, err := issueops.NewService(root, nil).GetIssue("cat", strings.TrimSpace(stdout.String()))
+	if err != nil || detail.Issue.OriginCompany != "Contractor" {
+		t.Fatalf("expected contractor issue, got %+v %v", detail.Issue, err)
+	}
+
+	// 失敗と成功は GUI と同じ監査イベントとして記録し、パスワードは記録しない。
+	// #nosec G304 -- テスト用ディレクトリ配下の固定パスを読むため安全。
+	logData, err := os.ReadFile(filepath.Join(filepath.Dir(exePath), "logs", "ratta.log"))
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(logData)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"event":"contractor_auth_failed"`) || !strings.Contains(lines[0], `"failed_attempts":1`) ||
+		!strings.Contains(lines[1], `"event":"contractor_auth_succeeded"`) || !strings.Contains(lines[1], `"mode":"Contractor"`) {
+		t.Fatalf("unexpected audit log:\n%s", logData)
+	}
+	if !strings.Contains(lines[1], `"source":"cli"`) || strings.Contains(string(logData), "secret") {
+		t.Fatalf("unexpected audit fields:\n%s", logData)
+	}
+}
+
+func TestIssueCreate_RefusesWhileProjectIsLocked(t *testing.T) {
+	// 他のインスタンスが書き込み用に開いている場合は作成しないことを確認する。
+	root, _ := newProject(t)
+	holder := `{"hostname":"gui-host","pid":1,"instance":"gui","updated_at":"2999-01-01T00:00:00Z"}`
+	if err := os.WriteFile(filepath.Join(root, projectlock.FileName), []byte(holder), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	code, _, stderr := runCommand(t, "issue", "create", "--schemas", schemasDir,
+		"--title", "blocked", "--description", "desc", "--due-date", "2024-02-01", "--priority", "Low", root, "cat")
+	if code != exitFailure || !strings.Contains(stderr, "gui-host") {
+		t.Fatalf("expected lock conflict, got %d %q", code, stderr)
+	}
+}
+
+func TestIssueCreate_RunsScriptHook(t *testing.T) {
+	// 実行ファイル隣の config.json の on_issue_created のスクリプトを作成した課題JSONを標準入力として実行し、
+	// 失敗したスクリプトは標準エラーへ書いても成功とすることを確認する。
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on windows")
+	}
+	root, _ := newProject(t)
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "created.sh"), "#!/bin/sh\ncat > \"$RATTA_ISSUE_ID.json\"\necho broken >&2\nexit 1\n")
+	if err := os.Chmod(filepath.Join(dir, "created.sh"), 0o755); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "config.json"), `{"format_version": 1, "hooks": {"on_issue_created": "created.sh"}}`)
+
+	code, stdout, stderr := runCommandWith(t, filepath.Join(dir, "ratta.exe"), "issue", "create", "--schemas", schemasDir,
+		"--title", "hooked", "--description", "desc", "--due-date", "2024-02-01", "--priority", "Low", root, "cat")
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	issueID := strings.TrimSpace(stdout)
+	written, err := os.ReadFile(filepath.Join(root, issueID+".json"))
+	if err != nil || !strings.Contains(string(written), `"title": "hooked"`) {
+		t.Fatalf("expected the issue JSON on stdin: %q %v", written, err)
+	}
+	if !strings.Contains(stderr, "hook issue.created exited with code 1: broken") {
+		t.Fatalf("unexpected stderr: %q", stderr)
+	}
+}
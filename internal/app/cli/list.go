@@ -0,0 +1,116 @@
+// list.go はカテゴリ内の課題一覧を表示するサブコマンドを担い、課題の編集は扱わない。
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/app/issuescan"
+	"ratta/internal/present"
+)
+
+// listSortKeys は DD-CLI-006 の list で指定できる並び替え項目を表す。GUI の一覧と同じ項目とする。
+var listSortKeys = map[string]bool{
+	"issue_id":   true,
+	"updated_at": true,
+	"due_date":   true,
+	"priority":   true,
+	"status":     true,
+	"title":      true,
+}
+
+// runList は DD-CLI-006 の list サブコマンドを実行する。
+// 目的: GUI を起動せずにカテゴリ内の課題を絞り込み・並び替えて一覧できるようにする。
+// 入力: args は `[--status s] [--priority p] [--sort key] [--order asc|desc] [--format table|json] [--schemas dir] <root> <category>`、
+// env は実行環境。
+// 出力: 終了コード。成功時は 0、カテゴリの読み取り失敗時は 1、引数の不備は 2。
+// エラー: 読み込めなかった課題JSONは一覧から除き、標準エラーへ警告として書く。
+// 副作用: 標準出力へ一覧を書く。プロジェクト配下のファイル (索引を含む) は変更しない。
+// 並行性: 単一ゴルーチンで実行する。
+// 不変条件: 絞り込みと並び替えは GUI の一覧と同じ規則に従い、ページングは行わない。
+// 関連DD: DD-CLI-006, DD-BE-003, DD-LOAD-003
+func runList(args []string, env Env) int {
+	fs := newFlagSet("list", env)
+	status := fs.String("status", "", "show only issues with this status")
+	priority := fs.String("priority", "", "show only issues with this priority")
+	sortBy := fs.String("sort", "issue_id", "sort key: issue_id, updated_at, due_date, priority, status or title")
+	sortOrder := fs.String("order", "asc", "sort order: asc or desc")
+	format := fs.String("format", defaultFormat(env), "output format: table or json (default json with --json)")
+	schemasDir := fs.String("schemas", "", "directory containing issue.schema.json")
+	positional, err := parseArgs(fs, args, "root", "category")
+	if err == nil && !listSortKeys[*sortBy] {
+		err = fmt.Errorf("unsupported sort key: %s", *sortBy)
+	}
+	if err == nil && *sortOrder != "asc" && *sortOrder != "desc" {
+		err = fmt.Errorf("unsupported sort order: %s", *sortOrder)
+	}
+	if err == nil {
+		err = checkFormat(*format)
+	}
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	validator, err := optionalValidator(env, *schemasDir)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "load schemas: %v\n", err)
+		return exitUsage
+	}
+
+	category, err := findCategory(positional[0], positional[1])
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "list: %v\n", err)
+		return exitFailure
+	}
+	// 索引を更新しないよう、課題JSONを直接走査する。
+	scanned, err := issuescan.NewScanner(validator).ScanCategory(category.Path, category.Name)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "list: %v\n", err)
+		return exitFailure
+	}
+	for _, loadErr := range scanned.LoadErrors {
+		fmt.Fprintf(env.Stderr, "warning: %s: %s\n", loadErr.Path, loadErr.Message)
+	}
+	items := make([]issueops.IssueSummary, 0, len(scanned.Items))
+	for _, item := range scanned.Items {
+		items = append(items, issueops.IssueSummary(item))
+	}
+	list, err := issueops.QueryIssues(category.Name, items, issueops.IssueListQuery{
+		PageSize:  len(items),
+		SortBy:    *sortBy,
+		SortOrder: *sortOrder,
+		Status:    *status,
+		Priority:  *priority,
+	})
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "list: %v\n", err)
+		return exitFailure
+	}
+
+	if *format == formatJSON {
+		err = writeJSON(env.Stdout, present.ToIssueListDTO(list))
+	} else {
+		err = writeIssueTable(env, list.Issues)
+	}
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "list: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// writeIssueTable は DD-CLI-006 の課題一覧を列を揃えた表として書く。スキーマ不整合の課題は SCHEMA 列に示す。
+func writeIssueTable(env Env, items []issueops.IssueSummary) error {
+	w := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ISSUE_ID\tSTATUS\tPRIORITY\tDUE_DATE\tUPDATED_AT\tSCHEMA\tTITLE")
+	for _, item := range items {
+		schemaState := "ok"
+		if item.IsSchemaInvalid {
+			schemaState = "invalid"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			item.IssueID, item.Status, item.Priority, item.DueDate, item.UpdatedAt, schemaState, oneLine(item.Title))
+	}
+	return w.Flush()
+}
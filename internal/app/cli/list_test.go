@@ -0,0 +1,69 @@
+// list_test.go は list サブコマンドの絞り込み・並び替え・出力形式のテストを行う。
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ratta/internal/domain/issue"
+	"ratta/internal/present"
+)
+
+func TestList_FiltersAndSortsIssues(t *testing.T) {
+	// 優先度で並び替えた表を出力し、絞り込み条件に一致しない課題を除くことを確認する。
+	root, lowID := newProject(t)
+	highID := createIssue(t, root, "urgent", issue.PriorityHigh)
+
+	code, stdout, stderr := runCommand(t, "list", "--schemas", schemasDir, "--sort", "priority", root, "cat")
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	lines := strings.Split(strings.TrimRight(stdout, "\n"), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "ISSUE_ID") ||
+		!strings.HasPrefix(lines[1], highID) || !strings.HasPrefix(lines[2], lowID) {
+		t.Fatalf("unexpected table: %q", stdout)
+	}
+
+	code, stdout, _ = runCommand(t, "list", "--schemas", schemasDir, "--priority", "Low", "--format", "json", root, "cat")
+	if code != exitOK {
+		t.Fatalf("expected success, got %d", code)
+	}
+	var list present.IssueListDTO
+	if err := json.Unmarshal([]byte(stdout), &list); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if list.Total != 1 || len(list.Issues) != 1 || list.Issues[0].IssueID != lowID {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+}
+
+func TestList_RejectsUnknownCategoryAndFlags(t *testing.T) {
+	// 存在しないカテゴリは終了コード 1、未対応の並び替え項目や出力形式は終了コード 2 となることを確認する。
+	root, _ := newProject(t)
+	if code, _, _ := runCommand(t, "list", "--schemas", schemasDir, root, "missing"); code != exitFailure {
+		t.Fatalf("expected failure for missing category, got %d", code)
+	}
+	if code, _, _ := runCommand(t, "list", "--sort", "assignee", root, "cat"); code != exitUsage {
+		t.Fatalf("expected usage error for sort key, got %d", code)
+	}
+	if code, _, _ := runCommand(t, "list", "--format", "xml", root, "cat"); code != exitUsage {
+		t.Fatalf("expected usage error for format, got %d", code)
+	}
+}
+
+func TestList_DoesNotWriteIndex(t *testing.T) {
+	// 一覧の表示でプロジェクトメタデータ (索引) を作成しないことを確認する。
+	root, _ := newProject(t)
+	if err := os.RemoveAll(filepath.Join(root, ".ratta")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if code, _, _ := runCommand(t, "list", "--schemas", schemasDir, root, "cat"); code != exitOK {
+		t.Fatalf("expected success, got %d", code)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".ratta")); !os.IsNotExist(err) {
+		t.Fatalf("expected no project metadata, got %v", err)
+	}
+}
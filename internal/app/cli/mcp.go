@@ -0,0 +1,60 @@
+// mcp.go は LLM アシスタント向けの MCP サーバーを標準入出力で起動するサブコマンドを担い、
+// プロトコルの処理やツールの実装は mcpserver に委ねる。
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"ratta/internal/app/mcpserver"
+)
+
+// runMCP は DD-CLI-006 の mcp サブコマンドを実行する。
+// 目的: 社内の LLM アシスタントからローカルのプロジェクトの課題を参照・検索・起票できるようにする。
+// 入力: args は `[--contractor] [--read-only] [--schemas dir] <root>`、env は実行環境。要求は env.Stdin から読む。
+// 出力: 終了コード。入力の終端まで処理できれば 0、入出力の失敗やモードを決定できない場合は 1、引数の不備は 2。
+// エラー: 起動時の失敗は標準エラーへ書く。個々のツールの失敗は MCP の応答として返す。
+// 副作用: 標準出力へ MCP の応答を書く。create_issue の呼び出しで課題JSONを作成する。
+// 並行性: 単一ゴルーチンで実行する。課題作成は呼び出しごとに書き込み用ロックを取得し、GUI が開いている間は作成しない。
+// 不変条件: 標準出力には MCP の応答のみを書く。Contractor パスワードは標準入力がプロトコルに使われるため環境変数からのみ受け取る。
+// 関連DD: DD-CLI-006, DD-MCP-001, DD-LOCK-002
+func runMCP(args []string, env Env) int {
+	fs := newFlagSet("mcp", env)
+	contractor := fs.Bool("contractor", false, "create issues in contractor mode (password from "+contractorPasswordEnv+")")
+	readOnly := fs.Bool("read-only", false, "expose only the read and search tools")
+	schemasDir := fs.String("schemas", "", "directory containing the JSON schemas")
+	positional, err := parseArgs(fs, args, "root")
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	if env.Stdin == nil {
+		fmt.Fprintln(env.Stderr, "mcp requires standard input")
+		return exitUsage
+	}
+	root := positional[0]
+	if info, statErr := os.Stat(root); statErr != nil || !info.IsDir() {
+		fmt.Fprintf(env.Stderr, "mcp: project root is not a directory: %s\n", root)
+		return exitFailure
+	}
+	validator, err := optionalValidator(env, *schemasDir)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "load schemas: %v\n", err)
+		return exitUsage
+	}
+	// 標準入力は MCP のメッセージに使われるため、パスワードの端末入力は行わない。
+	modeEnv := env
+	modeEnv.Prompter = nil
+	currentMode, err := resolveMode(modeEnv, *contractor, validator)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "mcp: %v\n", err)
+		return exitFailure
+	}
+
+	server := mcpserver.New(root, validator, currentMode).WithReadOnly(*readOnly).WithVersion(env.Build.Version)
+	if err := server.Serve(env.Stdin, env.Stdout); err != nil {
+		fmt.Fprintf(env.Stderr, "mcp: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
@@ -0,0 +1,69 @@
+// mcp_test.go は mcp サブコマンドの起動条件と標準入出力での応答のテストを行う。
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// runMCPWith はテスト用に標準入力を与えて mcp サブコマンドを実行し、終了コードと標準出力・標準エラーを返す。
+func runMCPWith(t *testing.T, exePath, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	handled, code := Run(append([]string{"mcp"}, args...), Env{
+		ExePath: exePath,
+		Stdin:   strings.NewReader(stdin),
+		Stdout:  &stdout,
+		Stderr:  &stderr,
+	})
+	if !handled {
+		t.Fatalf("expected mcp to be handled")
+	}
+	return code, stdout.String(), stderr.String()
+}
+
+func TestMCP_ServesToolsOverStdio(t *testing.T) {
+	// 標準入力の要求に標準出力で応答し、--read-only では create_issue を公開しないことを確認する。
+	root, _ := newProject(t)
+	request := `{"jsonrpc":"2.0","id":1,"method":"tools/list"}` + "\n"
+
+	code, stdout, stderr := runMCPWith(t, "", request, "--schemas", schemasDir, root)
+	if code != exitOK || !strings.Contains(stdout, `"create_issue"`) {
+		t.Fatalf("expected tools list, got %d %q %q", code, stdout, stderr)
+	}
+	code, stdout, stderr = runMCPWith(t, "", request, "--read-only", "--schemas", schemasDir, root)
+	if code != exitOK || !strings.Contains(stdout, `"get_issue"`) || strings.Contains(stdout, `"create_issue"`) {
+		t.Fatalf("expected read-only tools list, got %d %q %q", code, stdout, stderr)
+	}
+}
+
+func TestMCP_ContractorRequiresPasswordEnv(t *testing.T) {
+	// --contractor では環境変数のパスワードを検証し、無い・一致しない場合は起動しないことを確認する。
+	root, _ := newProject(t)
+	exePath := writeContractorAuth(t, "secret")
+	request := `{"jsonrpc":"2.0","id":1,"method":"ping"}` + "\n"
+
+	t.Setenv(contractorPasswordEnv, "")
+	code, stdout, stderr := runMCPWith(t, exePath, request, "--contractor", "--schemas", schemasDir, root)
+	if code != exitFailure || stdout != "" || !strings.Contains(stderr, contractorPasswordEnv) {
+		t.Fatalf("expected missing password failure, got %d %q %q", code, stdout, stderr)
+	}
+	t.Setenv(contractorPasswordEnv, "wrong")
+	if code, _, stderr = runMCPWith(t, exePath, request, "--contractor", "--schemas", schemasDir, root); code != exitFailure {
+		t.Fatalf("expected wrong password failure, got %d %q", code, stderr)
+	}
+	t.Setenv(contractorPasswordEnv, "secret")
+	code, stdout, stderr = runMCPWith(t, exePath, request, "--contractor", "--schemas", schemasDir, root)
+	if code != exitOK || !strings.Contains(stdout, `"result":{}`) {
+		t.Fatalf("expected ping reply, got %d %q %q", code, stdout, stderr)
+	}
+}
+
+func TestMCP_RejectsMissingRoot(t *testing.T) {
+	// プロジェクトルートがディレクトリでない場合は起動しないことを確認する。
+	code, _, stderr := runMCPWith(t, "", "", "--schemas", schemasDir, "/nonexistent/ratta-root")
+	if code != exitFailure || !strings.Contains(stderr, "not a directory") {
+		t.Fatalf("expected failure, got %d %q", code, stderr)
+	}
+}
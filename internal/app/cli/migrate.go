@@ -0,0 +1,130 @@
+// migrate.go は旧い形式のファイルを現行形式へ書き換えるサブコマンドを担い、移行手順の定義は扱わない。
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"ratta/internal/app/migrate"
+)
+
+// migrateChange は DD-CLI-006 の --json 指定時の移行したファイル1件を表す。
+type migrateChange struct {
+	Kind string `json:"kind"`
+	Path string `json:"path"`
+	From int    `json:"from"`
+	To   int    `json:"to"`
+}
+
+// migrateProblem は DD-CLI-006 の --json 指定時の移行できないファイル1件を表す。
+type migrateProblem struct {
+	Kind    string `json:"kind"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// migrateReport は DD-CLI-006 の --json 指定時の migrate の出力を表す。BackupDir は書き換え前の内容を残した場合のみ設定する。
+type migrateReport struct {
+	DryRun    bool             `json:"dry_run"`
+	Checked   int              `json:"checked"`
+	Changes   []migrateChange  `json:"changes"`
+	Problems  []migrateProblem `json:"problems"`
+	BackupDir string           `json:"backup_dir,omitempty"`
+}
+
+// runMigrate は DD-CLI-006 の migrate サブコマンドを実行する。
+// 目的: 旧い版の ratta で保存された課題JSONや設定を、新しい版で読めるよう一括で現行形式へ書き換える。
+// 入力: args は `[--dry-run] [--backup] [--config path] <root>`、env は実行環境。
+// 出力: 終了コード。移行できないファイルが無ければ 0、あれば 1、引数の不備は 2。
+// エラー: 走査・バックアップ・書き込みに失敗した場合や書き込み用ロックを取得できない場合は標準エラーへ書く。
+// 副作用: 標準出力へ移行したファイルを1行1件のタブ区切り (種別, パス, 移行前の版, 移行後の版) で書き、
+// 移行できないファイルと件数の要約を標準エラーへ書く。--json 指定時は標準出力へ結果全体を JSON で書く。--backup では .ratta/backups/migrate-<日時> へ書き換え前の内容を残す。
+// 並行性: 書き込み用ロックを取得して実行し、GUI が開いている間は書き換えない。dry-run はロックを取得しない。
+// 不変条件: 現行の版のファイルと移行できないファイルは変更しない。
+// 関連DD: DD-CLI-006, DD-MIGRATE-001, DD-LOCK-002
+func runMigrate(args []string, env Env) int {
+	fs := newFlagSet("migrate", env)
+	dryRun := fs.Bool("dry-run", false, "list files that need migration without rewriting them")
+	backup := fs.Bool("backup", false, "keep the original files under .ratta/"+migrate.BackupDirName)
+	configPath := fs.String("config", "", "also migrate this config.json")
+	positional, err := parseArgs(fs, args, "root")
+	if err == nil && *configPath != "" {
+		if _, statErr := os.Stat(*configPath); statErr != nil {
+			err = fmt.Errorf("config: %w", statErr)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+
+	root := positional[0]
+	opts := migrate.Options{ConfigPath: *configPath, DryRun: *dryRun}
+	if *backup && !*dryRun {
+		opts.BackupDir = migrate.NewBackupDir(root, time.Now())
+	}
+	run := withWriteLock
+	if *dryRun {
+		run = func(_ string, fn func() error) error { return fn() }
+	}
+	var result migrate.Result
+	err = run(root, func() error {
+		var runErr error
+		result, runErr = migrate.Run(context.Background(), root, opts)
+		return runErr
+	})
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "migrate: %v\n", err)
+		return exitFailure
+	}
+
+	if env.JSON {
+		if writeErr := writeJSON(env.Stdout, toMigrateReport(result, opts)); writeErr != nil {
+			fmt.Fprintf(env.Stderr, "migrate: %v\n", writeErr)
+			return exitFailure
+		}
+	} else {
+		for _, change := range result.Changes {
+			fmt.Fprintf(env.Stdout, "%s\t%s\t%d\t%d\n", change.Kind, change.Path, change.From, change.To)
+		}
+	}
+	for _, problem := range result.Problems {
+		fmt.Fprintf(env.Stderr, "error: %s: %s\n", problem.Path, oneLine(problem.Message))
+	}
+	if *dryRun {
+		fmt.Fprintf(env.Stderr, "dry run: %d of %d files need migration, %d cannot be migrated\n",
+			len(result.Changes), result.Checked, len(result.Problems))
+	} else {
+		fmt.Fprintf(env.Stderr, "migrated %d of %d files, %d cannot be migrated\n",
+			len(result.Changes), result.Checked, len(result.Problems))
+		if opts.BackupDir != "" && len(result.Changes) > 0 {
+			fmt.Fprintf(env.Stderr, "originals saved to %s\n", opts.BackupDir)
+		}
+	}
+	if len(result.Problems) > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// toMigrateReport は DD-CLI-006 の移行結果を --json の出力形式へ変換する。
+func toMigrateReport(result migrate.Result, opts migrate.Options) migrateReport {
+	report := migrateReport{
+		DryRun:   opts.DryRun,
+		Checked:  result.Checked,
+		Changes:  make([]migrateChange, 0, len(result.Changes)),
+		Problems: make([]migrateProblem, 0, len(result.Problems)),
+	}
+	for _, change := range result.Changes {
+		report.Changes = append(report.Changes, migrateChange{Kind: string(change.Kind), Path: change.Path, From: change.From, To: change.To})
+	}
+	for _, problem := range result.Problems {
+		report.Problems = append(report.Problems, migrateProblem{Kind: string(problem.Kind), Path: problem.Path, Message: problem.Message})
+	}
+	if len(result.Changes) > 0 {
+		report.BackupDir = opts.BackupDir
+	}
+	return report
+}
@@ -0,0 +1,51 @@
+// migrate_test.go は migrate サブコマンドの dry-run と書き換え・バックアップのテストを行う。
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrate_RewritesOldIssuesWithBackup(t *testing.T) {
+	// dry-run では書き換えずに対象を列挙し、--backup 付きの実行で書き換えと書き換え前の保存を行い、再実行では対象が無いことを確認する。
+	root, _ := newProject(t)
+	oldPath := filepath.Join(root, "cat", "legacy.json")
+	writeFile(t, oldPath, `{"issue_id":"legacy","title":"old"}`)
+
+	code, stdout, stderr := runCommand(t, "migrate", "--dry-run", root)
+	if code != exitOK || stdout != "issue\tcat/legacy.json\t0\t2\n" {
+		t.Fatalf("unexpected dry run %d: %q %q", code, stdout, stderr)
+	}
+	if data, _ := os.ReadFile(oldPath); strings.Contains(string(data), "version") {
+		t.Fatalf("dry run must not rewrite: %s", data)
+	}
+
+	code, stdout, stderr = runCommand(t, "migrate", "--backup", root)
+	if code != exitOK || stdout != "issue\tcat/legacy.json\t0\t2\n" {
+		t.Fatalf("unexpected migrate %d: %q %q", code, stdout, stderr)
+	}
+	if data, _ := os.ReadFile(oldPath); !strings.Contains(string(data), `"version": 2`) {
+		t.Fatalf("expected migrated issue: %s", data)
+	}
+	backups, err := filepath.Glob(filepath.Join(root, ".ratta", "backups", "migrate-*", "cat", "legacy.json"))
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("expected one backup, got %v %v", backups, err)
+	}
+
+	code, stdout, _ = runCommand(t, "migrate", root)
+	if code != exitOK || stdout != "" {
+		t.Fatalf("expected nothing to migrate, got %d %q", code, stdout)
+	}
+}
+
+func TestMigrate_FailsForNewerVersion(t *testing.T) {
+	// 現行より新しい版のファイルは変更せず、終了コード 1 となることを確認する。
+	root, _ := newProject(t)
+	writeFile(t, filepath.Join(root, "cat", "future.json"), `{"version":99}`)
+	code, _, stderr := runCommand(t, "migrate", root)
+	if code != exitFailure || !strings.Contains(stderr, "cat/future.json") {
+		t.Fatalf("unexpected result %d: %q", code, stderr)
+	}
+}
@@ -0,0 +1,299 @@
+// mutate.go は課題を書き換えるサブコマンドに共通する操作モードの決定・書き込み用ロックの取得・git への自動コミット・変更の通知と保存後のスクリプト・プラグインの実行を担い、
+// 課題の内容の組み立ては扱わない。
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/app/modedetect"
+	"ratta/internal/domain/issue"
+	"ratta/internal/domain/timeutil"
+	"ratta/internal/infra/configrepo"
+	"ratta/internal/infra/gitcommit"
+	"ratta/internal/infra/logging"
+	"ratta/internal/infra/pluginhost"
+	"ratta/internal/infra/projectlock"
+	"ratta/internal/infra/projectmeta"
+	"ratta/internal/infra/schema"
+	"ratta/internal/infra/scripthook"
+	"ratta/internal/infra/webhook"
+	"ratta/internal/present"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// contractorPasswordEnv は DD-CLI-006 の Contractor パスワードを渡す環境変数名を表す。
+// スクリプトからの実行では端末入力を行えないため、この環境変数で渡す。
+const contractorPasswordEnv = "RATTA_CONTRACTOR_PASSWORD"
+
+// contractorUserEnv は DD-CLI-007 の auth/users.json のアカウント名を渡す環境変数名を表す。
+const contractorUserEnv = "RATTA_CONTRACTOR_USER"
+
+// contractorTOTPEnv は DD-CLI-008 の contractor.json またはアカウントに TOTP を埋め込んだ場合のワンタイムコードを渡す環境変数名を表す。
+const contractorTOTPEnv = "RATTA_CONTRACTOR_TOTP"
+
+// auditSourceCLI は DD-LOG-005 の監査ログで、CLI (mcp を含む) での認証を GUI と区別するための記録元を表す。
+const auditSourceCLI = "cli"
+
+// gitOperation* は DD-CLI-006 の自動コミットのメッセージに記録する操作名を表し、GUI の監査ログと同じ名前を使う。
+const (
+	gitOperationIssueCreated   = "issue_created"
+	gitOperationCommentAdded   = "comment_added"
+	gitOperationIssuesImported = "issues_imported"
+)
+
+// resolveMode は DD-CLI-006 の書き込みを伴うサブコマンドの操作モードを決定する。
+// 目的: GUI と同じく、既定は Vendor とし、Contractor はパスワードを検証できた場合に限る。
+// auth/users.json がある場合は contractorUserEnv のアカウントで照合し、照合する認証情報に TOTP がある場合はワンタイムコードも照合する。
+// 入力: env は実行環境、contractor は Contractor モードの要求、validator は認証ファイルの検証器。
+// 出力: 操作モードとエラー。
+// エラー: Contractor を要求したがアカウント名・パスワード・ワンタイムコードが無い・一致しない・認証ファイルを読めない場合に返す。
+// 副作用: パスワードやワンタイムコードの環境変数が無い場合は env.Prompter で端末入力を求める。認証ファイルを読み取る。
+// 照合の成否を GUI と同じ DD-LOG-005 の監査イベントとして logs/ratta.log に記録する。
+// 並行性: 単一ゴルーチンで実行する。
+// 不変条件: パスワードを検証できない限り Contractor モードを返さない。
+// 関連DD: DD-CLI-006, DD-CLI-005, DD-CLI-007, DD-CLI-008, DD-BE-003, DD-LOG-005
+func resolveMode(env Env, contractor bool, validator *schema.Validator) (mod.Mode, error) {
+	if !contractor {
+		return mod.ModeVendor, nil
+	}
+	password := os.Getenv(contractorPasswordEnv)
+	if password == "" && env.Prompter != nil {
+		prompted, err := env.Prompter.PromptHidden("Contractor password: ")
+		if err != nil {
+			return mod.ModeVendor, err
+		}
+		password = prompted
+	}
+	if password == "" {
+		return mod.ModeVendor, fmt.Errorf("contractor password is required (set %s)", contractorPasswordEnv)
+	}
+	service := modedetect.NewService(env.ExePath, validator)
+	user := contractorUser()
+	modeValue, err := verifyContractor(env, service, user, password)
+	auditContractorAuth(env.ExePath, service, user, modeValue, err)
+	return modeValue, err
+}
+
+// verifyContractor は DD-CLI-007/DD-CLI-008 のアカウント名の有無を確かめ、ワンタイムコードを受け取ってパスワードを照合する。
+func verifyContractor(env Env, service *modedetect.Service, user, password string) (mod.Mode, error) {
+	if user == "" {
+		required, err := service.RequiresUsername()
+		if err != nil {
+			return mod.ModeVendor, err
+		}
+		if required {
+			return mod.ModeVendor, fmt.Errorf("contractor user is required (set %s)", contractorUserEnv)
+		}
+	}
+	code, err := oneTimeCode(env, service, user)
+	if err != nil {
+		return mod.ModeVendor, err
+	}
+	return service.VerifyContractorPassword(user, password, code)
+}
+
+// auditContractorAuth は DD-LOG-005 の Contractor 認証の成否を、GUI の VerifyContractorPassword と同じイベント名・項目で記録する。
+// CLI には常駐するログが無いため、実行ファイルと同じディレクトリの config.json のローテーション設定で都度ロガーを作る。
+// パスワード・ワンタイムコードは記録しない。
+func auditContractorAuth(exePath string, service *modedetect.Service, username string, modeValue mod.Mode, err error) {
+	var rotation logging.Rotation
+	if cfg, hasConfig, loadErr := configrepo.NewRepository(exePath).Load(); loadErr == nil && hasConfig {
+		rotation = logging.Rotation{
+			MaxSizeBytes:   int64(cfg.Log.MaxSizeMB) << 20,
+			MaxGenerations: cfg.Log.MaxGenerations,
+		}
+	}
+	logger := logging.NewLogger(exePath, logging.LevelInfo, rotation)
+	if err != nil {
+		logger.Audit("contractor_auth_failed", map[string]any{
+			"username":        username,
+			"failed_attempts": service.FailedAttempts(),
+			"detail":          err.Error(),
+			"source":          auditSourceCLI,
+		})
+		return
+	}
+	logger.Audit("contractor_auth_succeeded", map[string]any{
+		"mode":              string(modeValue),
+		"username":          username,
+		"requires_password": false,
+		"source":            auditSourceCLI,
+	})
+}
+
+// oneTimeCode は DD-CLI-008 のワンタイムコードを環境変数または端末入力から受け取る。user のアカウント (users.json が無い場合は
+// contractor.json) が TOTP を使わない場合は空文字を返す。
+func oneTimeCode(env Env, service *modedetect.Service, user string) (string, error) {
+	if code := os.Getenv(contractorTOTPEnv); code != "" {
+		return code, nil
+	}
+	required, err := service.RequiresTOTP(user)
+	if err != nil || !required {
+		return "", err
+	}
+	if env.Prompter == nil {
+		return "", fmt.Errorf("one-time code is required (set %s)", contractorTOTPEnv)
+	}
+	code, err := env.Prompter.PromptHidden("One-time code: ")
+	if err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// contractorUser は DD-CLI-007 の環境変数で指定された Contractor のアカウント名を返す。未指定の場合は空文字を返す。
+func contractorUser() string {
+	return os.Getenv(contractorUserEnv)
+}
+
+// withWriteLock は DD-CLI-006/DD-LOCK-002 のプロジェクトの書き込み用ロックを取得して fn を実行し、終了後に解放する。
+// GUI などの他のインスタンスが書き込み用に開いている場合は、上書きを避けるため実行しない。
+func withWriteLock(root string, fn func() error) error {
+	lock, holder, err := projectlock.Acquire(root)
+	if errors.Is(err, projectlock.ErrLocked) {
+		return fmt.Errorf("%w (%s, pid %d)", err, holder.Hostname, holder.PID)
+	}
+	if err != nil {
+		return err
+	}
+	runErr := fn()
+	return errors.Join(runErr, lock.Release())
+}
+
+// writeLockUnlessDryRun は DD-CLI-006 の取り込み・同期のサブコマンドで fn の実行に用いる関数を返す。
+// dry-run では書き込まないため、GUI が書き込み用に開いている間でも結果を事前に確認できるよう、ロックを取得せずに実行する。
+func writeLockUnlessDryRun(dryRun bool) func(root string, fn func() error) error {
+	if dryRun {
+		return func(_ string, fn func() error) error { return fn() }
+	}
+	return withWriteLock
+}
+
+// commitChange は DD-CLI-006 の書き換えの結果を、プロジェクト設定で git の自動コミットが有効な場合にコミットする。
+// プロジェクトルートがリポジトリ外の場合は何もしない。コミットに失敗しても書き換えは完了しているため、
+// 標準エラーへ警告を書くだけでサブコマンドの結果には影響させない。
+func commitChange(env Env, root string, currentMode mod.Mode, change gitcommit.Change) {
+	config, err := projectmeta.LoadConfig(root)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "git auto-commit: %v\n", err)
+		return
+	}
+	settings := config.GitSettings()
+	if !settings.AutoCommit {
+		return
+	}
+	change.Mode = string(currentMode)
+	if change.Actor == "" && currentMode == mod.ModeContractor {
+		change.Actor = contractorUser()
+	}
+	_, err = gitcommit.Commit(root, change, gitcommit.Author{Name: settings.AuthorName, Email: settings.AuthorEmail}, time.Now())
+	if err != nil && !errors.Is(err, gitcommit.ErrNotRepository) {
+		fmt.Fprintf(env.Stderr, "git auto-commit: %v\n", err)
+	}
+}
+
+// notifyWebhooks は DD-HOOK-001 のプロジェクト単位の設定に従い、課題の変更 event を通知先へ送る。
+// CLI は終了すると送れなくなるため、再試行を含めて送り終えるまで待つ。書き込み用ロックを解放した後に呼び出す。
+// 設定を読めない場合や届かなかった通知は標準エラーへ書き、サブコマンドの終了コードは変えない。
+// actor が空で Contractor モードの場合はアカウント名を用いる。配信の記録は GUI と同じ実行ファイル隣の logs/ に残す。
+func notifyWebhooks(env Env, root string, currentMode mod.Mode, event, actor string, detail issueops.IssueDetail) {
+	config, err := projectmeta.LoadConfig(root)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "webhook: %v\n", err)
+		return
+	}
+	if len(config.Webhooks) == 0 {
+		return
+	}
+	if actor == "" && currentMode == mod.ModeContractor {
+		actor = contractorUser()
+	}
+	if absolute, absErr := filepath.Abs(root); absErr == nil {
+		root = absolute
+	}
+	var deliveryLog *webhook.DeliveryLog
+	if env.ExePath != "" {
+		deliveryLog = webhook.OpenDeliveryLog(webhook.AppDeliveryLogPath(env.ExePath))
+	}
+	payload := webhook.Payload{
+		Event:      event,
+		OccurredAt: timeutil.NowISO8601(),
+		Project:    filepath.Base(root),
+		Category:   detail.Issue.Category,
+		IssueID:    detail.Issue.IssueID,
+		Actor:      actor,
+		Mode:       string(currentMode),
+		Issue:      present.ToIssueDetailDTO(detail),
+	}
+	for _, delivery := range webhook.NewSender(deliveryLog).Send(context.Background(), config.Webhooks, payload) {
+		if !delivery.Delivered {
+			fmt.Fprintf(env.Stderr, "webhook %s to %s failed after %d attempts: %s\n", event, delivery.Target, delivery.Attempts, delivery.Error)
+		}
+	}
+}
+
+// discoverPlugins は DD-PLUGIN-001 の実行ファイルと同じディレクトリの plugins/ からプラグインを発見し、
+// 読み込めず除いたプラグインを標準エラーへ書く。実行ファイルのパスが無い場合は nil (プラグイン無し) を返す。
+func discoverPlugins(env Env) *pluginhost.Registry {
+	if env.ExePath == "" {
+		return nil
+	}
+	registry, problems := pluginhost.Discover(pluginhost.AppDir(env.ExePath))
+	for _, problem := range problems {
+		fmt.Fprintf(env.Stderr, "plugin not loaded: %v\n", problem)
+	}
+	return registry
+}
+
+// pluginRules は DD-PLUGIN-003 の検証を行うプラグインによる保存前の検証を返す。
+func pluginRules(plugins *pluginhost.Registry) issueops.RuleChecker {
+	return func(category string, value issue.Issue) error {
+		return plugins.Validate(context.Background(), category, value)
+	}
+}
+
+// runPostSave は DD-HOOK-003 の実行ファイルと同じディレクトリの config.json の hooks のスクリプトと、
+// DD-PLUGIN-004 の保存後の処理を行うプラグインを、課題の変更 events ごとに順に実行する。
+// CLI は終了すると待てないため、終了するまで待つ。書き込み用ロックを解放した後に呼び出す。
+// 失敗は標準エラーへ書き、サブコマンドの終了コードは変えない。config.json を読めない場合は GUI と同じくスクリプトを実行しない。
+// actor が空で Contractor モードの場合はアカウント名を用いる。
+func runPostSave(env Env, root string, currentMode mod.Mode, plugins *pluginhost.Registry, actor string, value issue.Issue, events ...string) {
+	repo := configrepo.NewRepository(env.ExePath)
+	var hooks *configrepo.Hooks
+	if cfg, hasConfig, err := repo.Load(); err == nil && hasConfig {
+		hooks = cfg.Hooks
+	}
+	if hooks == nil && len(plugins.Plugins()) == 0 {
+		return
+	}
+	if actor == "" && currentMode == mod.ModeContractor {
+		actor = contractorUser()
+	}
+	if absolute, absErr := filepath.Abs(root); absErr == nil {
+		root = absolute
+	}
+	event := pluginhost.PostSaveEvent{
+		ProjectRoot: root,
+		Category:    value.Category,
+		IssueID:     value.IssueID,
+		Mode:        string(currentMode),
+		Actor:       actor,
+		Issue:       value,
+	}
+	for _, name := range events {
+		event.Event = name
+		if err := scripthook.Run(context.Background(), hooks, filepath.Dir(repo.Path()), scripthook.Invocation(event)); err != nil {
+			fmt.Fprintf(env.Stderr, "%v\n", err)
+		}
+		for _, err := range plugins.PostSave(context.Background(), event) {
+			fmt.Fprintf(env.Stderr, "%v\n", err)
+		}
+	}
+}
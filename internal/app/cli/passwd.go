@@ -0,0 +1,60 @@
+// passwd.go は Contractor パスワードを変更するサブコマンドを担い、contractor.json や users.json の新規作成は扱わない。
+// 新規作成は init contractor が担う。
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"ratta/internal/app/contractorinit"
+)
+
+// passwdReport は DD-CLI-006 の --json 指定時の passwd の出力を表す。
+type passwdReport struct {
+	Changed bool `json:"changed"`
+}
+
+// runPasswd は DD-CLI-006 の passwd サブコマンドを実行する。
+// 目的: init contractor --force で作り直さずに、鍵導出設定を保ったまま Contractor パスワードを変更する。
+// 入力: args は `[--user name]`、env は実行環境。パスワードは Prompter で端末から入力する。
+// 出力: 終了コード。成功時は 0、検証や保存の失敗時は 1、引数の不備や端末入力が使えない場合は 2。
+// エラー: 失敗理由を標準エラーへ書く。
+// 副作用: 実行ファイル隣の auth/contractor.json (--user 指定時は auth/users.json の当該アカウント) を原子的に置き換え、
+// 標準エラーへ完了を書く。
+// --json 指定時は標準出力へ変更結果を JSON で書く。
+// 並行性: 単一ゴルーチンで実行する。
+// 不変条件: 現在のパスワードを確認できない場合は認証ファイルを変更しない。
+// 関連DD: DD-CLI-006, DD-CLI-005, DD-CLI-007
+func runPasswd(args []string, env Env) int {
+	fs := newFlagSet("passwd", env)
+	user := fs.String("user", "", "change the password of this account in auth/users.json")
+	err := fs.Parse(args)
+	if err == nil && fs.NArg() != 0 {
+		err = errors.New("usage: ratta passwd [--user name]")
+	}
+	if err == nil && env.Prompter == nil {
+		err = errors.New("passwd requires an interactive terminal")
+	}
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	var changeErr error
+	if *user != "" {
+		changeErr = contractorinit.ChangeUserPassword(env.ExePath, *user, env.Prompter)
+	} else {
+		changeErr = contractorinit.ChangePassword(env.ExePath, env.Prompter)
+	}
+	if changeErr != nil {
+		fmt.Fprintf(env.Stderr, "passwd: %v\n", changeErr)
+		return exitFailure
+	}
+	if env.JSON {
+		if writeErr := writeJSON(env.Stdout, passwdReport{Changed: true}); writeErr != nil {
+			fmt.Fprintf(env.Stderr, "passwd: %v\n", writeErr)
+			return exitFailure
+		}
+	}
+	fmt.Fprintln(env.Stderr, "contractor password changed")
+	return exitOK
+}
@@ -0,0 +1,124 @@
+// passwd_test.go は passwd サブコマンドのテストを行い、contractor.json の新規作成は扱わない。
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ratta/internal/app/contractorinit"
+	"ratta/internal/app/modedetect"
+	"ratta/internal/infra/crypto"
+)
+
+// scriptedPrompter は入力を順に返すテスト用の Prompter。
+type scriptedPrompter struct {
+	values []string
+}
+
+func (p *scriptedPrompter) PromptHidden(_ string) (string, error) {
+	if len(p.values) == 0 {
+		return "", errors.New("no input")
+	}
+	value := p.values[0]
+	p.values = p.values[1:]
+	return value, nil
+}
+
+// writeUserAuth はテスト用に auth/users.json へアカウントを追加し、実行ファイルのパスを返す。
+// テスト時間を抑えるため、鍵導出は軽い Argon2id 設定で行う。
+func writeUserAuth(t *testing.T, exePath, username, password string) string {
+	t.Helper()
+	if exePath == "" {
+		exePath = filepath.Join(t.TempDir(), "ratta.exe")
+	}
+	kdf := crypto.KDFParams{Name: crypto.KDFArgon2id, Iterations: 2}
+	if _, err := contractorinit.AddUser(exePath, username, false, false, kdf, &scriptedPrompter{values: []string{password, password}}); err != nil {
+		t.Fatalf("AddUser error: %v", err)
+	}
+	return exePath
+}
+
+func runPasswdWith(exePath string, inputs ...string) (int, string) {
+	return runPasswdArgs(exePath, nil, inputs...)
+}
+
+// runPasswdArgs はテスト用に引数と入力を与えて passwd サブコマンドを実行し、終了コードと標準エラーを返す。
+func runPasswdArgs(exePath string, args []string, inputs ...string) (int, string) {
+	var stdout, stderr bytes.Buffer
+	_, code := Run(append([]string{"passwd"}, args...), Env{ExePath: exePath, Stdout: &stdout, Stderr: &stderr, Prompter: &scriptedPrompter{values: inputs}})
+	return code, stderr.String()
+}
+
+func TestPasswd_ChangesPassword(t *testing.T) {
+	// 現在のパスワードを確認したうえで新しいパスワードへ置き換わることを確認する。
+	exePath := writeContractorAuth(t, "old-secret")
+	code, stderr := runPasswdWith(exePath, "old-secret", "New-secret-2", "New-secret-2")
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	service := modedetect.NewService(exePath, nil)
+	if _, err := service.VerifyContractorPassword("", "New-secret-2", ""); err != nil {
+		t.Fatalf("expected new password to verify: %v", err)
+	}
+	if _, err := service.VerifyContractorPassword("", "old-secret", ""); err == nil {
+		t.Fatal("expected old password to be rejected")
+	}
+}
+
+func TestPasswd_WrongCurrentPasswordKeepsFile(t *testing.T) {
+	// 現在のパスワードが一致しない場合は失敗し、contractor.json を変更しないことを確認する。
+	exePath := writeContractorAuth(t, "old-secret")
+	authPath := filepath.Join(filepath.Dir(exePath), "auth", "contractor.json")
+	before, err := os.ReadFile(authPath)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	code, stderr := runPasswdWith(exePath, "wrong", "New-secret-2", "New-secret-2")
+	if code != exitFailure || !strings.Contains(stderr, "current password verification failed") {
+		t.Fatalf("expected verification failure, got %d %q", code, stderr)
+	}
+	after, err := os.ReadFile(authPath)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Fatal("expected contractor.json to be unchanged")
+	}
+}
+
+func TestPasswd_RequiresExistingAuthAndPrompter(t *testing.T) {
+	// contractor.json が無い場合は失敗し、端末入力が無い場合は引数の不備として扱うことを確認する。
+	exePath := filepath.Join(t.TempDir(), "ratta.exe")
+	if code, stderr := runPasswdWith(exePath, "a"); code != exitFailure || !strings.Contains(stderr, "init contractor") {
+		t.Fatalf("expected missing auth failure, got %d %q", code, stderr)
+	}
+	var stdout, stderr bytes.Buffer
+	if _, code := Run([]string{"passwd"}, Env{ExePath: exePath, Stdout: &stdout, Stderr: &stderr}); code != exitUsage {
+		t.Fatalf("expected usage error without prompter, got %d", code)
+	}
+}
+
+func TestPasswd_UserChangesOnlyThatAccount(t *testing.T) {
+	// --user では users.json の指定したアカウントのみパスワードを変更し、存在しないアカウントは失敗することを確認する。
+	exePath := writeUserAuth(t, "", "alice", "Alice-old-pw-1")
+	writeUserAuth(t, exePath, "bob", "Bob-password-2")
+
+	code, stderr := runPasswdArgs(exePath, []string{"--user", "alice"}, "Alice-old-pw-1", "Alice-new-pw-2", "Alice-new-pw-2")
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	service := modedetect.NewService(exePath, nil)
+	if _, err := service.VerifyContractorPassword("alice", "Alice-new-pw-2", ""); err != nil {
+		t.Fatalf("expected new password to verify: %v", err)
+	}
+	if _, err := service.VerifyContractorPassword("bob", "Bob-password-2", ""); err != nil {
+		t.Fatalf("expected other account to be unchanged: %v", err)
+	}
+	if code, stderr := runPasswdArgs(exePath, []string{"--user", "carol"}, "x"); code != exitFailure || !strings.Contains(stderr, "user not found") {
+		t.Fatalf("expected unknown user failure, got %d %q", code, stderr)
+	}
+}
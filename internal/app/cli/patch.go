@@ -0,0 +1,173 @@
+// patch.go は共有フォルダを介さずに課題の変更を受け渡すパッチの出力・取り込みのサブコマンドを担い、
+// パッチの形式と取り込みの規則の詳細は patchbundle に委ねる。
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"ratta/internal/app/patchbundle"
+	"ratta/internal/infra/gitcommit"
+	"ratta/internal/present"
+)
+
+// patchPassphraseEnv は DD-CLI-006 のパッチの署名用のパスフレーズを渡す環境変数名を表す。
+// スクリプトからの実行では端末入力を行えないため、この環境変数で渡す。
+const patchPassphraseEnv = "RATTA_PATCH_PASSPHRASE"
+
+// gitOperationPatchApplied は DD-CLI-006 のパッチの取り込みの自動コミットのメッセージに記録する操作名を表す。
+const gitOperationPatchApplied = "patch_applied"
+
+// runPatchExport は DD-CLI-006 の patch export サブコマンドを実行する。
+// 目的: 共有フォルダを使えない相手へ、前回の受け渡し以降に変わった課題・コメントだけを署名付きのファイルで渡せるようにする。
+// 入力: args は `[--since time] --output patch.zip <root>`、env は実行環境。since は RFC 3339 または YYYY-MM-DD で、省略時は全件。
+// 出力: 終了コード。成功時は 0、出力失敗時は 1、引数の不備やパスフレーズが無い場合は 2。
+// エラー: 走査・添付の読み取り・書き込みの失敗を標準エラーへ書く。
+// 副作用: 出力先へパッチを書き込み、標準エラーへ件数と次回の --since に指定する時刻を書く。--json 指定時は標準出力へ出力結果を JSON で書く。
+// パスフレーズは patchPassphraseEnv から、無い場合は env.Prompter で端末入力から受け取る。
+// 並行性: 単一ゴルーチンで実行する。
+// 不変条件: GUI の StartExportPatch と同じ条件であれば同じ課題・コメントを出力する。
+// 関連DD: DD-CLI-006, DD-PATCH-001
+func runPatchExport(args []string, env Env) int {
+	fs := newFlagSet("patch export", env)
+	output := fs.String("output", "", "output path of the patch (required)")
+	sinceFlag := fs.String("since", "", "export changes at or after this time (RFC 3339 or YYYY-MM-DD; default: everything)")
+	positional, err := parseArgs(fs, args, "root")
+	if err == nil && *output == "" {
+		err = fmt.Errorf("--output is required")
+	}
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	since, err := patchbundle.ParseSince(*sinceFlag)
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	passphrase, err := patchPassphrase(env)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "patch export: %v\n", err)
+		return exitUsage
+	}
+
+	result, err := patchbundle.Export(context.Background(), positional[0], since, passphrase, *output)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "patch export: %v\n", err)
+		return exitFailure
+	}
+	if env.JSON {
+		if writeErr := writeJSON(env.Stdout, present.ToPatchExportDTO(result)); writeErr != nil {
+			fmt.Fprintf(env.Stderr, "patch export: %v\n", writeErr)
+			return exitFailure
+		}
+	}
+	fmt.Fprintf(env.Stderr, "exported %d issues, %d comments and %d attachments (next --since %s)\n",
+		result.Issues, result.Comments, result.Attachments, result.ExportedAt)
+	return exitOK
+}
+
+// runPatchApply は DD-CLI-006 の patch apply サブコマンドを実行する。
+// 目的: 相手から受け取ったパッチの署名と内容を確かめ、手元のプロジェクトへ変更を反映する。
+// 入力: args は `[--dry-run] [--contractor] [--schemas dir] <root> <patch.zip>`、env は実行環境。
+// 出力: 終了コード。競合・失敗が無ければ 0、競合や失敗した課題がある場合やパッチを取り込めない場合は 1、引数の不備やパスフレーズが無い場合は 2。
+// エラー: 署名の不一致や内容の改ざんはパッチ全体を取り込まずに標準エラーへ書く。課題ごとの失敗は標準出力の結果に含め、残りの課題の処理を続ける。
+// 副作用: 課題JSON・添付を作成・上書きし、標準出力へ1行1件のタブ区切り (結果, カテゴリ/パッチ上の課題ID, 付け替えた課題ID・競合した項目またはメッセージ) を
+// (--json 指定時は取り込みの結果を JSON で)、標準エラーへ件数の要約を書く。
+// プロジェクト設定で git の自動コミットが有効な場合は変更をコミットする。dry-run では課題を保存しない。
+// 並行性: 書き込み用ロックを取得して実行し、GUI が開いている間は取り込まない。dry-run はロックを取得しない。
+// 不変条件: GUI の StartApplyPatch と同じ規則で取り込む。
+// 関連DD: DD-CLI-006, DD-PATCH-002, DD-PATCH-003, DD-LOCK-002
+func runPatchApply(args []string, env Env) int {
+	fs := newFlagSet("patch apply", env)
+	dryRun := fs.Bool("dry-run", false, "report what would change without saving")
+	contractor := fs.Bool("contractor", false, "operate in contractor mode (password from "+contractorPasswordEnv+" or prompt)")
+	schemasDir := fs.String("schemas", "", "directory containing the JSON schemas")
+	positional, err := parseArgs(fs, args, "root", "patch.zip")
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	validator, err := optionalValidator(env, *schemasDir)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "load schemas: %v\n", err)
+		return exitUsage
+	}
+	passphrase, err := patchPassphrase(env)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "patch apply: %v\n", err)
+		return exitUsage
+	}
+	currentMode, err := resolveMode(env, *contractor, validator)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "patch apply: %v\n", err)
+		return exitFailure
+	}
+
+	root := positional[0]
+	var result patchbundle.ApplyResult
+	err = writeLockUnlessDryRun(*dryRun)(root, func() error {
+		var applyErr error
+		result, applyErr = patchbundle.Apply(context.Background(), root, positional[1], passphrase, validator, currentMode, patchbundle.Options{DryRun: *dryRun})
+		if applyErr == nil && !*dryRun && result.Created+result.Updated+result.Conflicted > 0 {
+			commitChange(env, root, currentMode, gitcommit.Change{Operation: gitOperationPatchApplied})
+		}
+		return applyErr
+	})
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "patch apply: %v\n", err)
+		return exitFailure
+	}
+	if env.JSON {
+		if writeErr := writeJSON(env.Stdout, present.ToPatchApplyDTO(result)); writeErr != nil {
+			fmt.Fprintf(env.Stderr, "patch apply: %v\n", writeErr)
+			return exitFailure
+		}
+	} else {
+		for _, item := range result.Issues {
+			fmt.Fprintf(env.Stdout, "%s\t%s/%s\t%s\n", item.Action, item.Category, item.IssueID, patchDetail(item))
+		}
+	}
+	prefix := ""
+	if *dryRun {
+		prefix = "dry run: "
+	}
+	fmt.Fprintf(env.Stderr, "%screated %d, updated %d, unchanged %d issues, %d conflicts, %d failed\n",
+		prefix, result.Created, result.Updated, result.Unchanged, result.Conflicted, result.Failed)
+	if result.Conflicted > 0 || result.Failed > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// patchPassphrase は DD-CLI-006 のパッチの署名用のパスフレーズを環境変数または端末入力から受け取る。
+func patchPassphrase(env Env) (string, error) {
+	if passphrase := os.Getenv(patchPassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	if env.Prompter != nil {
+		passphrase, err := env.Prompter.PromptHidden("Patch passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if passphrase != "" {
+			return passphrase, nil
+		}
+	}
+	return "", fmt.Errorf("patch passphrase is required (set %s)", patchPassphraseEnv)
+}
+
+// patchDetail は DD-CLI-006 の表形式の出力で、付け替えた課題ID、競合した項目名または失敗の理由を返す。
+func patchDetail(item patchbundle.IssueResult) string {
+	switch {
+	case item.Message != "":
+		return oneLine(item.Message)
+	case len(item.Conflicts) > 0:
+		return strings.Join(item.Conflicts, ",")
+	case item.LocalIssueID != item.IssueID:
+		return item.LocalIssueID
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+// patch_test.go は patch サブコマンドのパッチの出力・取り込みと、パスフレーズの検査のテストを行う。
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPatch_ExportThenApply(t *testing.T) {
+	// 出力したパッチを dry-run では保存せずに確かめ、取り込むと相手のプロジェクトに課題が作成されることを確認する。
+	t.Setenv(patchPassphraseEnv, "shared")
+	root, issueID := newProject(t)
+	other := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(other, "cat"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	patch := filepath.Join(t.TempDir(), "changes.zip")
+
+	code, _, stderr := runCommand(t, "patch", "export", "--since", "2000-01-01", "--output", patch, root)
+	if code != exitOK || !strings.HasPrefix(stderr, "exported 1 issues, 0 comments and 0 attachments (next --since ") {
+		t.Fatalf("unexpected export: %d %q", code, stderr)
+	}
+	code, stdout, stderr := runCommand(t, "patch", "apply", "--schemas", schemasDir, "--dry-run", other, patch)
+	if code != exitOK || stdout != "created\tcat/"+issueID+"\t\n" || !strings.HasPrefix(stderr, "dry run: created 1") {
+		t.Fatalf("unexpected dry run: %d %q %q", code, stdout, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(other, "cat", issueID+".json")); !os.IsNotExist(err) {
+		t.Fatalf("dry run must not save: %v", err)
+	}
+	if code, _, stderr = runCommand(t, "patch", "apply", "--schemas", schemasDir, other, patch); code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(other, "cat", issueID+".json")); err != nil {
+		t.Fatalf("expected issue to be created: %v", err)
+	}
+}
+
+func TestPatch_RejectsMissingOrWrongPassphrase(t *testing.T) {
+	// パスフレーズが無い場合は終了コード 2、異なるパスフレーズのパッチは取り込まずに終了コード 1 となることを確認する。
+	root, _ := newProject(t)
+	patch := filepath.Join(t.TempDir(), "changes.zip")
+	t.Setenv(patchPassphraseEnv, "")
+	if code, _, stderr := runCommand(t, "patch", "export", "--output", patch, root); code != exitUsage || !strings.Contains(stderr, patchPassphraseEnv) {
+		t.Fatalf("expected usage error, got %d %q", code, stderr)
+	}
+	if code, _, _ := runCommand(t, "patch", "export", "--since", "yesterday", "--output", patch, root); code != exitUsage {
+		t.Fatalf("expected usage error for invalid since, got %d", code)
+	}
+
+	t.Setenv(patchPassphraseEnv, "shared")
+	if code, _, stderr := runCommand(t, "patch", "export", "--output", patch, root); code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	t.Setenv(patchPassphraseEnv, "other")
+	code, _, stderr := runCommand(t, "patch", "apply", "--schemas", schemasDir, root, patch)
+	if code != exitFailure || !strings.Contains(stderr, "signature does not match") {
+		t.Fatalf("expected signature error, got %d %q", code, stderr)
+	}
+}
@@ -0,0 +1,106 @@
+// plugin.go は plugins/ から発見したプラグインを一覧するサブコマンドを担い、プラグインの配置や呼び出しは扱わない。
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"ratta/internal/infra/pluginhost"
+)
+
+// pluginListReport は DD-CLI-006 の plugin list の json 形式の出力内容を表す。Problems は読み込めず除いたプラグインの理由を表す。
+type pluginListReport struct {
+	Dir      string         `json:"dir"`
+	Plugins  []pluginReport `json:"plugins"`
+	Problems []string       `json:"problems"`
+}
+
+// pluginReport は DD-PLUGIN-001 の発見したプラグイン1件の出力内容を表す。
+type pluginReport struct {
+	Name        string                    `json:"name"`
+	Description string                    `json:"description,omitempty"`
+	Exporters   []pluginhost.ExportFormat `json:"exporters"`
+	Validate    bool                      `json:"validate"`
+	PostSave    []string                  `json:"post_save"`
+}
+
+// runPluginList は DD-CLI-006 の plugin list サブコマンドを実行する。
+// 目的: 配置したプラグインが読み込まれ、どの出力形式・検証・保存後の処理を追加するかを GUI を起動せずに確かめられるようにする。
+// 入力: args は `[--format table|json]`、env は実行環境。
+// 出力: 終了コード。全プラグインを読み込めた場合は 0、読み込めないプラグインがある場合は 1、引数の不備は 2。
+// エラー: 読み込めないプラグインは標準エラーへ (json 形式では problems へ) 書く。
+// 副作用: 標準出力へプラグインの一覧を書く。プラグインは実行しない。
+// 並行性: 単一ゴルーチンで実行する。
+// 不変条件: GUI と同じ実行ファイル隣の plugins/ を同じ規則で読み込む。
+// 関連DD: DD-CLI-006, DD-PLUGIN-001
+func runPluginList(args []string, env Env) int {
+	fs := newFlagSet("plugin list", env)
+	format := fs.String("format", defaultFormat(env), "output format: table or json (default json with --json)")
+	err := fs.Parse(args)
+	if err == nil && fs.NArg() != 0 {
+		err = fmt.Errorf("usage: ratta plugin list [flags]")
+	}
+	if err == nil {
+		err = checkFormat(*format)
+	}
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+
+	dir := pluginhost.AppDir(env.ExePath)
+	registry, problems := pluginhost.Discover(dir)
+	report := pluginListReport{Dir: dir, Plugins: []pluginReport{}, Problems: []string{}}
+	for _, plugin := range registry.Plugins() {
+		report.Plugins = append(report.Plugins, pluginReport{
+			Name:        plugin.Name,
+			Description: plugin.Manifest.Description,
+			Exporters:   append([]pluginhost.ExportFormat{}, plugin.Manifest.Exporters...),
+			Validate:    plugin.Manifest.Validate,
+			PostSave:    append([]string{}, plugin.Manifest.PostSave...),
+		})
+	}
+	for _, problem := range problems {
+		report.Problems = append(report.Problems, problem.Error())
+	}
+
+	if *format == formatJSON {
+		err = writeJSON(env.Stdout, report)
+	} else {
+		err = writePluginTable(env, report)
+		for _, problem := range report.Problems {
+			fmt.Fprintf(env.Stderr, "plugin not loaded: %s\n", problem)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "plugin list: %v\n", err)
+		return exitFailure
+	}
+	if len(report.Problems) > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// writePluginTable は DD-CLI-006 のプラグインの一覧を列を揃えた表として書く。無い項目は - とする。
+func writePluginTable(env Env, report pluginListReport) error {
+	w := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tEXPORT_FORMATS\tVALIDATE\tPOST_SAVE")
+	for _, plugin := range report.Plugins {
+		formats := make([]string, 0, len(plugin.Exporters))
+		for _, exporter := range plugin.Exporters {
+			formats = append(formats, exporter.Format)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", plugin.Name, orDash(strings.Join(formats, ",")), plugin.Validate, orDash(strings.Join(plugin.PostSave, ",")))
+	}
+	return w.Flush()
+}
+
+// orDash は DD-CLI-006 の表の空の値を - で返す。
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
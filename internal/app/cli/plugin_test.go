@@ -0,0 +1,86 @@
+// plugin_test.go は plugin list サブコマンドの一覧の出力と、プラグインの出力形式・検証の規則の CLI での適用のテストを行う。
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"ratta/internal/infra/pluginhost"
+)
+
+// writeCLIPlugin はテスト用に実行ファイル exePath の隣の plugins/ へ定義と実行可能なシェルスクリプトを作成する。
+func writeCLIPlugin(t *testing.T, exePath, name, manifest, script string) {
+	t.Helper()
+	dir := filepath.Join(pluginhost.AppDir(exePath), name)
+	writeFile(t, filepath.Join(dir, pluginhost.ManifestFileName), manifest)
+	if script == "" {
+		return
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on windows")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "run.sh"), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+}
+
+func TestPluginList_ReportsLoadedAndRejectedPlugins(t *testing.T) {
+	// 読み込めたプラグインを表と json で一覧し、読み込めないプラグインがある場合は理由を示して終了コード 1 となることを確認する。
+	exePath := filepath.Join(t.TempDir(), "ratta")
+	code, stdout, _ := runCommandWith(t, exePath, "plugin", "list")
+	if code != exitOK || !strings.Contains(stdout, "NAME") {
+		t.Fatalf("unexpected empty list: code=%d stdout=%s", code, stdout)
+	}
+
+	writeCLIPlugin(t, exePath, "xlsx", `{"protocol_version": 1, "command": "run.sh", "exporters": [{"format": "xlsx", "extension": ".xlsx"}], "post_save": ["issue.closed"]}`, "")
+	writeCLIPlugin(t, exePath, "broken", `{"protocol_version": 2, "command": "run.sh", "validate": true}`, "")
+
+	code, stdout, stderr := runCommandWith(t, exePath, "plugin", "list")
+	if code != exitFailure || !strings.Contains(stdout, "xlsx") || !strings.Contains(stdout, "issue.closed") {
+		t.Fatalf("unexpected table: code=%d stdout=%s", code, stdout)
+	}
+	if !strings.Contains(stderr, "plugin not loaded: plugin broken") {
+		t.Fatalf("expected broken plugin in stderr: %s", stderr)
+	}
+
+	code, stdout, _ = runCommandWith(t, exePath, "plugin", "list", "--format", "json")
+	var report pluginListReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("unmarshal report: %v (%s)", err, stdout)
+	}
+	if code != exitFailure || len(report.Plugins) != 1 || report.Plugins[0].Exporters[0].Format != "xlsx" || len(report.Problems) != 1 {
+		t.Fatalf("unexpected report: code=%d %+v", code, report)
+	}
+}
+
+func TestPluginFormatsAndRules_AppliedByCLI(t *testing.T) {
+	// export がプラグインの出力形式を受け付けて変換後の内容を書き、検証のプラグインが問題を返した場合は課題を作成しないことを確認する。
+	root, _ := newProject(t)
+	exePath := filepath.Join(t.TempDir(), "ratta")
+	writeCLIPlugin(t, exePath, "rules", `{"protocol_version": 1, "command": "run.sh", "exporters": [{"format": "text", "extension": ".txt"}], "validate": true}`,
+		`case "$(cat)" in
+*'"method":"export"'*) echo '{"content": "Y29udmVydGVk"}' ;;
+*'"title":"forbidden"'*) echo '{"problems": [{"field": "title", "message": "title is reserved"}]}' ;;
+*) echo '{"problems": []}' ;;
+esac
+`)
+
+	output := filepath.Join(t.TempDir(), "out.txt")
+	code, _, stderr := runCommandWith(t, exePath, "export", "--format", "text", "--output", output, root)
+	if code != exitOK {
+		t.Fatalf("export failed: code=%d stderr=%s", code, stderr)
+	}
+	if data, _ := os.ReadFile(output); string(data) != "converted" {
+		t.Fatalf("unexpected export content: %q", data)
+	}
+
+	code, _, stderr = runCommandWith(t, exePath, "issue", "create", "--schemas", schemasDir, "--title", "forbidden",
+		"--description", "desc", "--due-date", "2024-02-01", "--priority", "High", root, "cat")
+	if code == exitOK || !strings.Contains(stderr, "rules: title is reserved") {
+		t.Fatalf("expected plugin rule to reject issue: code=%d stderr=%s", code, stderr)
+	}
+}
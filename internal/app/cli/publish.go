@@ -0,0 +1,49 @@
+// publish.go はプロジェクトを静的な HTML サイトとして出力するサブコマンドを担い、ページ構成の詳細は sitepublish に委ねる。
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"ratta/internal/app/sitepublish"
+	"ratta/internal/present"
+)
+
+// runPublish は DD-CLI-006 の publish サブコマンドを実行する。
+// 目的: ratta を導入していない関係者向けに、課題を閲覧専用のサイトとして共有フォルダや Web サーバーへ置けるようにする。
+// 入力: args は `<root> <outdir>`、env は実行環境。
+// 出力: 終了コード。成功時は 0、出力失敗時は 1、引数の不備は 2。
+// エラー: 出力先が空でない・プロジェクトルート配下にある場合、走査・書き込みの失敗を標準エラーへ書く。
+// 副作用: outdir へサイトを書き込み、標準エラーへ件数の要約を書く。--json 指定時は標準出力へ出力結果を JSON で書く。
+// 並行性: 単一ゴルーチンで実行する。
+// 不変条件: GUI の StartPublishSite と同じ内容のサイトを出力する。プロジェクト配下は変更しない。
+// 関連DD: DD-CLI-006, DD-PUBLISH-001
+func runPublish(args []string, env Env) int {
+	fs := newFlagSet("publish", env)
+	positional, err := parseArgs(fs, args, "root", "outdir")
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+
+	result, err := sitepublish.Publish(context.Background(), positional[0], positional[1], nil)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "publish: %v\n", err)
+		return exitFailure
+	}
+	if env.JSON {
+		if writeErr := writeJSON(env.Stdout, present.ToSitePublishDTO(result)); writeErr != nil {
+			fmt.Fprintf(env.Stderr, "publish: %v\n", writeErr)
+			return exitFailure
+		}
+	}
+	fmt.Fprintf(env.Stderr, "published %d issues in %d categories to %s", result.Issues, result.Categories, result.Path)
+	if result.Skipped > 0 {
+		fmt.Fprintf(env.Stderr, " (%d unreadable issue files skipped)", result.Skipped)
+	}
+	if result.MissingAttachments > 0 {
+		fmt.Fprintf(env.Stderr, " (%d missing attachments)", result.MissingAttachments)
+	}
+	fmt.Fprintln(env.Stderr)
+	return exitOK
+}
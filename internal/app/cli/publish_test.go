@@ -0,0 +1,48 @@
+// publish_test.go は publish サブコマンドの出力と引数の検査のテストを行う。
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ratta/internal/present"
+)
+
+func TestPublish_WritesSiteAndReport(t *testing.T) {
+	// 目次と課題ページを書き、--json 指定時は出力結果を JSON で返すことを確認する。
+	root, issueID := newProject(t)
+	outDir := filepath.Join(t.TempDir(), "site")
+	code, stdout, stderr := runCommand(t, "--json", "publish", root, outDir)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	var report present.SitePublishDTO
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("unmarshal: %v %q", err, stdout)
+	}
+	if report.Path != outDir || report.Categories != 1 || report.Issues != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	for _, name := range []string{"index.html", filepath.Join("cat", "index.html"), filepath.Join("cat", issueID+".html")} {
+		if _, err := os.Stat(filepath.Join(outDir, name)); err != nil {
+			t.Fatalf("expected %s: %v", name, err)
+		}
+	}
+}
+
+func TestPublish_RejectsMissingOutdirAndNonEmptyDestination(t *testing.T) {
+	// 出力先の無い指定は終了コード 2、空でない出力先は終了コード 1 となることを確認する。
+	root, _ := newProject(t)
+	if code, _, _ := runCommand(t, "publish", root); code != exitUsage {
+		t.Fatalf("expected usage error, got %d", code)
+	}
+	outDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outDir, "keep.txt"), []byte("keep"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if code, _, _ := runCommand(t, "publish", root, outDir); code != exitFailure {
+		t.Fatalf("expected failure, got %d", code)
+	}
+}
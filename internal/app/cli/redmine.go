@@ -0,0 +1,150 @@
+// redmine.go は Redmine の CSV との課題の受け渡しのサブコマンドを担い、CSV の形式と課題の対応付けの詳細は redmine に委ねる。
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"ratta/internal/app/issueexport"
+	"ratta/internal/app/issueops"
+	"ratta/internal/app/redmine"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/gitcommit"
+	"ratta/internal/present"
+)
+
+// runRedmineExport は DD-CLI-006 の redmine export サブコマンドを実行する。
+// 目的: Redmine を使う取引先へ、課題とコメントを Redmine の CSV 取り込みで読める形で定期的に渡せるようにする。
+// 入力: args は `[--category c]... [--status s]... --output issues.csv [--journals journals.csv] <root>`、env は実行環境。
+// 出力: 終了コード。成功時は 0、出力失敗時は 1、引数の不備は 2。
+// エラー: 未知のステータスや存在しないカテゴリの指定、走査・書き込みの失敗を標準エラーへ書く。
+// 副作用: 出力先へ CSV を書き込み、標準エラーへ件数の要約を書く。--json 指定時は標準出力へ出力結果を JSON で書く。
+// 並行性: 単一ゴルーチンで実行する。
+// 不変条件: GUI の ExportRedmineCSV と同じ条件であれば同じ内容のファイルを出力する。
+// 関連DD: DD-CLI-006, DD-REDMINE-001
+func runRedmineExport(args []string, env Env) int {
+	fs := newFlagSet("redmine export", env)
+	output := fs.String("output", "", "output path of the issue list CSV (required)")
+	journals := fs.String("journals", "", "output path of the journal (comment) CSV (default: not written)")
+	var categories, statuses multiFlag
+	fs.Var(&categories, "category", "export only this category (repeatable)")
+	fs.Var(&statuses, "status", "export only issues with this status (repeatable)")
+	positional, err := parseArgs(fs, args, "root")
+	if err == nil && *output == "" {
+		err = fmt.Errorf("--output is required")
+	}
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+
+	result, err := redmine.Export(context.Background(), positional[0], issueexport.Filter{
+		Categories: categories,
+		Statuses:   statuses,
+	}, *output, *journals)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "redmine export: %v\n", err)
+		return exitFailure
+	}
+	if env.JSON {
+		if writeErr := writeJSON(env.Stdout, present.ToRedmineExportDTO(result)); writeErr != nil {
+			fmt.Fprintf(env.Stderr, "redmine export: %v\n", writeErr)
+			return exitFailure
+		}
+	}
+	fmt.Fprintf(env.Stderr, "exported %d issues and %d journals", result.Issues, result.Journals)
+	if result.Skipped > 0 {
+		fmt.Fprintf(env.Stderr, " (%d unreadable issue files skipped)", result.Skipped)
+	}
+	fmt.Fprintln(env.Stderr)
+	return exitOK
+}
+
+// runRedmineImport は DD-CLI-006 の redmine import サブコマンドを実行する。
+// 目的: Redmine を使う取引先から受け取った課題一覧と注記の CSV を、同じ課題は更新、新しい課題は作成して同期する。
+// 入力: args は `--category name [--journals journals.csv] [--company Contractor|Vendor] [--default-due date] [--dry-run]
+// [--contractor] [--schemas dir] <root> <issues.csv>`、env は実行環境。
+// 出力: 終了コード。全行を取り込め (dry-run では検証でき) れば 0、失敗した行がある場合や CSV を読めない場合は 1、引数の不備は 2。
+// エラー: 行ごとの失敗は標準出力の結果に含め、残りの行の処理を続ける。
+// 副作用: 課題JSONを作成・上書きし、標準出力へ1行1件のタブ区切り (行番号, 結果, 課題IDまたはメッセージ) を
+// (--json 指定時は件数と行ごとの結果を JSON で)、標準エラーへ件数の要約を書く。dry-run では課題を保存しない。
+// 並行性: 書き込み用ロックを取得して実行し、GUI が開いている間は取り込まない。dry-run はロックを取得しない。
+// 不変条件: GUI の StartImportRedmineCSV と同じ規則で課題を対応付ける。
+// 関連DD: DD-CLI-006, DD-REDMINE-002, DD-LOCK-002
+func runRedmineImport(args []string, env Env) int {
+	fs := newFlagSet("redmine import", env)
+	category := fs.String("category", "", "category to import the issues into (required)")
+	journals := fs.String("journals", "", "journal (comment) CSV to import with the issues")
+	company := fs.String("company", string(issue.CompanyContractor), "company of the Redmine side: Contractor or Vendor")
+	defaultDue := fs.String("default-due", "", "due date (YYYY-MM-DD) for issues without one")
+	dryRun := fs.Bool("dry-run", false, "validate rows without saving issues")
+	contractor := fs.Bool("contractor", false, "operate in contractor mode (password from "+contractorPasswordEnv+" or prompt)")
+	schemasDir := fs.String("schemas", "", "directory containing the JSON schemas")
+	positional, err := parseArgs(fs, args, "root", "issues.csv")
+	if err == nil && *category == "" {
+		err = fmt.Errorf("--category is required")
+	}
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	records, err := redmine.ReadFiles(positional[1], *journals)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "redmine import: %v\n", err)
+		return exitFailure
+	}
+	validator, err := optionalValidator(env, *schemasDir)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "load schemas: %v\n", err)
+		return exitUsage
+	}
+	currentMode, err := resolveMode(env, *contractor, validator)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "redmine import: %v\n", err)
+		return exitFailure
+	}
+
+	root := positional[0]
+	opts := redmine.Options{
+		Category:       *category,
+		Company:        issue.Company(*company),
+		DefaultDueDate: *defaultDue,
+		DryRun:         *dryRun,
+	}
+	var result redmine.ImportResult
+	err = writeLockUnlessDryRun(*dryRun)(root, func() error {
+		var importErr error
+		result, importErr = redmine.Import(context.Background(), issueops.NewService(root, validator), root, currentMode, records, opts)
+		if importErr == nil && !*dryRun && result.Created+result.Updated > 0 {
+			commitChange(env, root, currentMode, gitcommit.Change{Operation: gitOperationIssuesImported, Category: *category})
+		}
+		return importErr
+	})
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "redmine import: %v\n", err)
+		return exitFailure
+	}
+	if env.JSON {
+		if writeErr := writeJSON(env.Stdout, present.ToRedmineImportDTO(result)); writeErr != nil {
+			fmt.Fprintf(env.Stderr, "redmine import: %v\n", writeErr)
+			return exitFailure
+		}
+	} else {
+		for _, row := range result.Rows {
+			value := row.IssueID
+			if row.Status == redmine.RowFailed {
+				value = oneLine(row.Message)
+			}
+			fmt.Fprintf(env.Stdout, "%d\t%s\t%s\n", row.Line, row.Status, value)
+		}
+	}
+	prefix := ""
+	if *dryRun {
+		prefix = "dry run: "
+	}
+	fmt.Fprintf(env.Stderr, "%screated %d, updated %d, unchanged %d issues, %d rows failed\n", prefix, result.Created, result.Updated, result.Unchanged, result.Failed)
+	if result.Failed > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
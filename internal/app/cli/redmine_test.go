@@ -0,0 +1,72 @@
+// redmine_test.go は redmine サブコマンドの Redmine の CSV の取り込み・出力と引数の検査のテストを行う。
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ratta/internal/present"
+)
+
+func TestRedmine_ImportThenExport(t *testing.T) {
+	// dry-run では保存せず、取り込んだ課題を Redmine の課題番号付きで出力し直せることを確認する。
+	root, _ := newProject(t)
+	dir := t.TempDir()
+	issuesCSV := filepath.Join(dir, "issues.csv")
+	journalsCSV := filepath.Join(dir, "journals.csv")
+	if err := os.WriteFile(issuesCSV, []byte("#,Subject,Description,Status,Priority,Due date,Created,Updated\n"+
+		"12,crash,steps,In Progress,Urgent,2024-01-20,2024-01-09 10:00,2024-01-10 11:30\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(journalsCSV, []byte("#,Author,Created,Notes\n12,suzuki,2024-01-10 11:30,looked at logs\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	code, stdout, stderr := runCommand(t, "redmine", "import", "--schemas", schemasDir, "--category", "cat", "--journals", journalsCSV, "--dry-run", root, issuesCSV)
+	if code != exitOK || !strings.HasPrefix(stdout, "2\tcreated\t") || !strings.Contains(stderr, "dry run: created 1") {
+		t.Fatalf("unexpected dry run: %d %q %q", code, stdout, stderr)
+	}
+	code, stdout, stderr = runCommand(t, "--json", "redmine", "import", "--schemas", schemasDir, "--category", "cat", "--journals", journalsCSV, root, issuesCSV)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	var imported present.RedmineImportDTO
+	if err := json.Unmarshal([]byte(stdout), &imported); err != nil {
+		t.Fatalf("unmarshal: %v %q", err, stdout)
+	}
+	if imported.DryRun || imported.Created != 1 || len(imported.Rows) != 1 || imported.Rows[0].IssueID == "" {
+		t.Fatalf("unexpected import: %+v", imported)
+	}
+
+	output := filepath.Join(dir, "out.csv")
+	code, _, stderr = runCommand(t, "redmine", "export", "--output", output, "--journals", filepath.Join(dir, "out-journals.csv"), root)
+	if code != exitOK || !strings.Contains(stderr, "exported 2 issues and 1 journals") {
+		t.Fatalf("unexpected export: %d %q", code, stderr)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil || !strings.Contains(string(data), "\n12,"+imported.Rows[0].IssueID+",crash,steps,In Progress,High,") {
+		t.Fatalf("unexpected csv:\n%s %v", data, err)
+	}
+}
+
+func TestRedmine_RejectsInvalidArguments(t *testing.T) {
+	// 必須の指定の無い場合は終了コード 2、失敗した行がある場合は終了コード 1 となることを確認する。
+	root, _ := newProject(t)
+	if code, _, _ := runCommand(t, "redmine", "export", root); code != exitUsage {
+		t.Fatalf("expected usage error, got %d", code)
+	}
+	issuesCSV := filepath.Join(t.TempDir(), "issues.csv")
+	if err := os.WriteFile(issuesCSV, []byte("#,Subject\n12,no due date\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if code, _, _ := runCommand(t, "redmine", "import", root, issuesCSV); code != exitUsage {
+		t.Fatalf("expected usage error, got %d", code)
+	}
+	code, stdout, _ := runCommand(t, "redmine", "import", "--schemas", schemasDir, "--category", "cat", root, issuesCSV)
+	if code != exitFailure || !strings.Contains(stdout, "2\tfailed\t") {
+		t.Fatalf("expected failed row, got %d %q", code, stdout)
+	}
+}
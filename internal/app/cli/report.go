@@ -0,0 +1,190 @@
+// report.go は課題の詳細とプロジェクトの集計の PDF、期間の報告書を出力するサブコマンドを担い、
+// レイアウトと集計の詳細は pdfreport・weeklyreport に委ねる。
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/app/issuescan"
+	"ratta/internal/app/pdfreport"
+	"ratta/internal/app/weeklyreport"
+	"ratta/internal/infra/configrepo"
+	"ratta/internal/present"
+)
+
+// reportReport は DD-CLI-006 の --json 指定時の report の出力を表す。
+type reportReport struct {
+	Path  string `json:"path"`
+	Pages int    `json:"pages"`
+}
+
+// runReportIssue は DD-CLI-006 の report issue サブコマンドを実行する。
+// 目的: GUI を起動せずに、提出用の課題の詳細の PDF を作成できるようにする。
+// 入力: args は `--output path [--font ttf] <root> <category> <issue-id>`、env は実行環境。
+// 出力: 終了コード。成功時は 0、出力失敗時は 1、引数の不備は 2。
+// エラー: カテゴリや課題が存在しない場合、フォントの不備、書き込みの失敗を標準エラーへ書く。
+// 副作用: 出力先へ PDF を書き込み、標準エラーへページ数の要約を書く。--json 指定時は標準出力へ出力結果を JSON で書く。
+// 並行性: 単一ゴルーチンで実行する。
+// 不変条件: --font が無い場合は config.json の report.pdf_font を用いる。プロジェクト配下は変更しない。
+// 関連DD: DD-CLI-006, DD-REPORT-001
+func runReportIssue(args []string, env Env) int {
+	fs := newFlagSet("report issue", env)
+	output := fs.String("output", "", "output PDF path (required)")
+	font := fs.String("font", "", "TrueType font embedded in the PDF (default: report.pdf_font in config.json)")
+	positional, err := parseArgs(fs, args, "root", "category", "issue-id")
+	if err == nil && *output == "" {
+		err = fmt.Errorf("--output is required")
+	}
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+
+	root := positional[0]
+	category, err := findCategory(root, positional[1])
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "report: %v\n", err)
+		return exitFailure
+	}
+	detail, err := issueops.NewService(root, nil).GetIssue(category.Name, positional[2])
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "report: %v\n", err)
+		return exitFailure
+	}
+	result, err := pdfreport.WriteIssue(*output, detail.Issue, reportOptions(env, *font))
+	return finishReport(env, result, err)
+}
+
+// runReportSummary は DD-CLI-006 の report summary サブコマンドを実行する。
+// 目的: 定例の報告に用いるステータス別の件数と期限超過の一覧の PDF を、GUI を起動せずに作成できるようにする。
+// 入力: args は `--output path [--font ttf] <root>`、env は実行環境。
+// 出力: 終了コード。成功時は 0、出力失敗時は 1、引数の不備は 2。
+// エラー: 走査の失敗、フォントの不備、書き込みの失敗を標準エラーへ書く。
+// 副作用: 出力先へ PDF を書き込み、標準エラーへページ数の要約を書く。--json 指定時は標準出力へ出力結果を JSON で書く。
+// 並行性: 単一ゴルーチンで実行する。
+// 不変条件: 件数・期限超過の判定は stats と同じ規則に従う。プロジェクト配下は変更しない。
+// 関連DD: DD-CLI-006, DD-REPORT-001, DD-STATS-001
+func runReportSummary(args []string, env Env) int {
+	fs := newFlagSet("report summary", env)
+	output := fs.String("output", "", "output PDF path (required)")
+	font := fs.String("font", "", "TrueType font embedded in the PDF (default: report.pdf_font in config.json)")
+	positional, err := parseArgs(fs, args, "root")
+	if err == nil && *output == "" {
+		err = fmt.Errorf("--output is required")
+	}
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+
+	root := positional[0]
+	stats, err := issuescan.NewScanner(nil).ProjectStatsContext(context.Background(), root, 0)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "report: %v\n", err)
+		return exitFailure
+	}
+	project := root
+	if abs, absErr := filepath.Abs(root); absErr == nil {
+		project = abs
+	}
+	result, err := pdfreport.WriteSummary(*output, filepath.Base(project), stats, reportOptions(env, *font))
+	return finishReport(env, result, err)
+}
+
+// runReportWeekly は DD-CLI-006 の report weekly サブコマンドを実行する。
+// 目的: 週次の定例報告に用いる、期間内の新規・対応済み・完了・期限超過の課題の報告書を定期処理から作成できるようにする。
+// 入力: args は `[--from date] [--to date] [--format md|html] [--output path] <root>`、env は実行環境。
+// 期間を省略した場合は今日を末日とする7日間とする。
+// 出力: 終了コード。成功時は 0、出力失敗時は 1、引数の不備は 2。
+// エラー: 日付や形式の不備は終了コード 2、走査・書き込みの失敗は終了コード 1 として標準エラーへ書く。
+// 副作用: --output 指定時は出力先へ報告書を書き込み、標準エラーへ件数の要約を書く。--json 指定時は標準出力へ出力結果を JSON で書く。
+// --output が無い場合は標準出力へ報告書を書く。
+// 並行性: 単一ゴルーチンで実行する。
+// 不変条件: GUI の ExportWeeklyReport と同じ期間であれば同じ内容の報告書を出力する。プロジェクト配下は変更しない。
+// 関連DD: DD-CLI-006, DD-REPORT-002
+func runReportWeekly(args []string, env Env) int {
+	fs := newFlagSet("report weekly", env)
+	from := fs.String("from", "", "first day of the period, YYYY-MM-DD (default: 6 days before --to)")
+	to := fs.String("to", "", "last day of the period, YYYY-MM-DD (default: today)")
+	format := fs.String("format", string(weeklyreport.FormatMarkdown), "report format: md or html")
+	output := fs.String("output", "", "output file path (default: standard output)")
+	positional, err := parseArgs(fs, args, "root")
+	if err == nil && env.JSON && *output == "" {
+		err = fmt.Errorf("--output is required with --json")
+	}
+	var reportFormat weeklyreport.Format
+	if err == nil {
+		reportFormat, err = weeklyreport.ParseFormat(*format)
+	}
+	var period weeklyreport.Range
+	if err == nil {
+		period, err = weeklyreport.ParseRange(*from, *to)
+	}
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+
+	root := positional[0]
+	if *output == "" {
+		report, buildErr := weeklyreport.Build(context.Background(), root, period)
+		var data []byte
+		if buildErr == nil {
+			data, buildErr = weeklyreport.Render(reportFormat, report)
+		}
+		if buildErr == nil {
+			_, buildErr = env.Stdout.Write(data)
+		}
+		if buildErr != nil {
+			fmt.Fprintf(env.Stderr, "report: %v\n", buildErr)
+			return exitFailure
+		}
+		return exitOK
+	}
+	result, err := weeklyreport.Export(context.Background(), root, reportFormat, period, *output)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "report: %v\n", err)
+		return exitFailure
+	}
+	if env.JSON {
+		if writeErr := writeJSON(env.Stdout, present.ToWeeklyReportDTO(result)); writeErr != nil {
+			fmt.Fprintf(env.Stderr, "report: %v\n", writeErr)
+			return exitFailure
+		}
+	}
+	fmt.Fprintf(env.Stderr, "wrote report for %s..%s (opened %d, resolved %d, closed %d, overdue %d) to %s\n",
+		period.From, period.To, result.Totals.Opened, result.Totals.Resolved, result.Totals.Closed, result.Totals.Overdue, result.Path)
+	return exitOK
+}
+
+// reportOptions は DD-REPORT-001 の PDF の出力の設定を返す。font が空の場合は実行ファイルと同じディレクトリの config.json の report.pdf_font を用いる。
+func reportOptions(env Env, font string) pdfreport.Options {
+	if font != "" {
+		return pdfreport.Options{FontPath: font}
+	}
+	repo := configrepo.NewRepository(env.ExePath)
+	cfg, hasConfig, err := repo.Load()
+	if err != nil || !hasConfig {
+		return pdfreport.Options{}
+	}
+	return pdfreport.Options{FontPath: cfg.Report.PDFFontPath(filepath.Dir(repo.Path()))}
+}
+
+// finishReport は DD-CLI-006 の report の出力結果を書き、終了コードを返す。
+func finishReport(env Env, result pdfreport.Result, err error) int {
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "report: %v\n", err)
+		return exitFailure
+	}
+	if env.JSON {
+		if writeErr := writeJSON(env.Stdout, reportReport{Path: result.Path, Pages: result.Pages}); writeErr != nil {
+			fmt.Fprintf(env.Stderr, "report: %v\n", writeErr)
+			return exitFailure
+		}
+	}
+	fmt.Fprintf(env.Stderr, "wrote %d pages to %s\n", result.Pages, result.Path)
+	return exitOK
+}
@@ -0,0 +1,94 @@
+// report_test.go は report サブコマンドの PDF・期間の報告書の出力と引数の検査のテストを行う。
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ratta/internal/present"
+)
+
+func TestReport_WritesIssueAndSummaryPDF(t *testing.T) {
+	// 課題の詳細と集計の PDF を書き、--json 指定時は出力先とページ数を返すことを確認する。
+	root, issueID := newProject(t)
+	dir := t.TempDir()
+	issuePath := filepath.Join(dir, "issue.pdf")
+	if code, _, stderr := runCommand(t, "report", "issue", "--output", issuePath, root, "cat", issueID); code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	summaryPath := filepath.Join(dir, "summary.pdf")
+	code, stdout, stderr := runCommand(t, "--json", "report", "summary", "--output", summaryPath, root)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	var report reportReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("unmarshal: %v %q", err, stdout)
+	}
+	if report.Path != summaryPath || report.Pages != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	for _, path := range []string{issuePath, summaryPath} {
+		data, err := os.ReadFile(path)
+		if err != nil || !bytes.HasPrefix(data, []byte("%PDF-")) {
+			t.Fatalf("expected pdf at %s: %v", path, err)
+		}
+	}
+}
+
+func TestReport_RejectsMissingOutputAndUnknownIssue(t *testing.T) {
+	// 出力先の無い指定は終了コード 2、存在しない課題は終了コード 1 となることを確認する。
+	root, _ := newProject(t)
+	if code, _, _ := runCommand(t, "report", "summary", root); code != exitUsage {
+		t.Fatalf("expected usage error, got %d", code)
+	}
+	output := filepath.Join(t.TempDir(), "issue.pdf")
+	if code, _, _ := runCommand(t, "report", "issue", "--output", output, root, "cat", "missing"); code != exitFailure {
+		t.Fatalf("expected failure, got %d", code)
+	}
+}
+
+func TestReportWeekly_WritesMarkdownAndHTML(t *testing.T) {
+	// 期間を省略すると今日までの報告書を標準出力へ書き、--json 指定時は出力先と件数を返すことを確認する。
+	root, issueID := newProject(t)
+	code, stdout, stderr := runCommand(t, "report", "weekly", root)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	if !strings.Contains(stdout, "| cat | 1 | 0 | 0 | 1 |") || !strings.Contains(stdout, issueID) {
+		t.Fatalf("unexpected markdown:\n%s", stdout)
+	}
+	output := filepath.Join(t.TempDir(), "weekly.html")
+	code, stdout, stderr = runCommand(t, "--json", "report", "weekly", "--format", "html", "--output", output, root)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	var report present.WeeklyReportDTO
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("unmarshal: %v %q", err, stdout)
+	}
+	if report.Path != output || report.Format != "html" || report.Opened != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if data, err := os.ReadFile(output); err != nil || !bytes.Contains(data, []byte("<!DOCTYPE html>")) {
+		t.Fatalf("expected html at %s: %v", output, err)
+	}
+}
+
+func TestReportWeekly_RejectsInvalidArguments(t *testing.T) {
+	// 不正な形式・日付、出力先の無い --json 指定は終了コード 2 となることを確認する。
+	root, _ := newProject(t)
+	for _, args := range [][]string{
+		{"report", "weekly", "--format", "pdf", root},
+		{"report", "weekly", "--from", "2024-02-01", "--to", "2024-01-01", root},
+		{"--json", "report", "weekly", root},
+	} {
+		if code, _, _ := runCommand(t, args...); code != exitUsage {
+			t.Fatalf("expected usage error for %v, got %d", args, code)
+		}
+	}
+}
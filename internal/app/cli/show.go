@@ -0,0 +1,122 @@
+// show.go は課題1件の詳細を表示するサブコマンドを担い、課題の編集は扱わない。
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/jsonfmt"
+)
+
+// runShow は DD-CLI-006 の show サブコマンドを実行する。
+// 目的: GUI を起動せずに課題1件の内容とコメントを確認できるようにする。
+// 入力: args は `[--format table|json] [--schemas dir] <root> <category> <issue-id>`、env は実行環境。
+// 出力: 終了コード。成功時は 0、課題の読み込み失敗時は 1、引数の不備は 2。
+// エラー: カテゴリや課題が存在しない場合、課題JSONを解析できない場合は標準エラーへ書く。
+// 副作用: 標準出力へ課題を書く。json 形式は課題JSONと同じキー順の正規形式とする。
+// 並行性: 単一ゴルーチンで実行する。
+// 不変条件: プロジェクト配下のファイルを変更しない。
+// 関連DD: DD-CLI-006, DD-BE-003, DD-DATA-003, DD-DATA-004
+func runShow(args []string, env Env) int {
+	fs := newFlagSet("show", env)
+	format := fs.String("format", defaultFormat(env), "output format: table or json (default json with --json)")
+	schemasDir := fs.String("schemas", "", "directory containing issue.schema.json")
+	positional, err := parseArgs(fs, args, "root", "category", "issue-id")
+	if err == nil {
+		err = checkFormat(*format)
+	}
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	validator, err := optionalValidator(env, *schemasDir)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "load schemas: %v\n", err)
+		return exitUsage
+	}
+
+	root := positional[0]
+	category, err := findCategory(root, positional[1])
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "show: %v\n", err)
+		return exitFailure
+	}
+	detail, err := issueops.NewService(root, validator).GetIssue(category.Name, positional[2])
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "show: %v\n", err)
+		return exitFailure
+	}
+
+	if *format == formatJSON {
+		err = writeIssueJSON(env.Stdout, detail.Issue)
+	} else {
+		err = writeIssueDetail(env.Stdout, detail)
+	}
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "show: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// writeIssueJSON は DD-CLI-006 の課題を課題JSONと同じキー順の正規形式で書く。
+func writeIssueJSON(w io.Writer, value issue.Issue) error {
+	data, err := jsonfmt.MarshalIssue(value)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// writeIssueDetail は DD-CLI-006 の課題詳細を項目の表、説明、コメントの順に書く。
+func writeIssueDetail(out io.Writer, detail issueops.IssueDetail) error {
+	value := detail.Issue
+	schemaState := "ok"
+	if detail.IsSchemaInvalid {
+		schemaState = "invalid"
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, row := range [][2]string{
+		{"issue_id", value.IssueID},
+		{"category", value.Category},
+		{"title", oneLine(value.Title)},
+		{"status", string(value.Status)},
+		{"priority", string(value.Priority)},
+		{"origin_company", string(value.OriginCompany)},
+		{"assignee", value.Assignee},
+		{"due_date", value.DueDate},
+		{"created_at", value.CreatedAt},
+		{"updated_at", value.UpdatedAt},
+		{"schema", schemaState},
+	} {
+		fmt.Fprintf(w, "%s:\t%s\n", row[0], row[1])
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString("\ndescription:\n")
+	b.WriteString(indent(value.Description))
+	fmt.Fprintf(&b, "\ncomments: %d\n", len(value.Comments))
+	for _, comment := range value.Comments {
+		fmt.Fprintf(&b, "\n[%s] %s (%s) %s\n", comment.CommentID, comment.AuthorName, comment.AuthorCompany, comment.CreatedAt)
+		b.WriteString(indent(comment.Body))
+		for _, attachment := range comment.Attachments {
+			fmt.Fprintf(&b, "  attachment: %s (%s)\n", attachment.FileName, attachment.RelativePath)
+		}
+	}
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// indent は DD-CLI-006 の本文を字下げして書くため、各行の先頭に空白を付け末尾を改行で終える。
+func indent(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	return "  " + strings.Join(lines, "\n  ") + "\n"
+}
@@ -0,0 +1,45 @@
+// show_test.go は show サブコマンドの表形式と JSON 形式の出力のテストを行う。
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"ratta/internal/domain/issue"
+)
+
+func TestShow_PrintsTableAndCanonicalJSON(t *testing.T) {
+	// 表形式では項目と説明を、JSON 形式では課題JSONと同じ内容を出力することを確認する。
+	root, issueID := newProject(t)
+
+	code, stdout, stderr := runCommand(t, "show", "--schemas", schemasDir, root, "cat", issueID)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	for _, want := range []string{"issue_id:", issueID, "title:", "first", "schema:", "ok", "description:\n  desc\n", "comments: 0"} {
+		if !strings.Contains(stdout, want) {
+			t.Fatalf("expected %q in output: %q", want, stdout)
+		}
+	}
+
+	code, stdout, _ = runCommand(t, "show", "--schemas", schemasDir, "--format", "json", root, "cat", issueID)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d", code)
+	}
+	var value issue.Issue
+	if err := json.Unmarshal([]byte(stdout), &value); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if value.IssueID != issueID || value.Title != "first" || !strings.HasPrefix(stdout, "{\n  \"version\"") {
+		t.Fatalf("unexpected json: %q", stdout)
+	}
+}
+
+func TestShow_FailsForMissingIssue(t *testing.T) {
+	// 存在しない課題は終了コード 1 となることを確認する。
+	root, _ := newProject(t)
+	if code, _, _ := runCommand(t, "show", "--schemas", schemasDir, root, "cat", "missing"); code != exitFailure {
+		t.Fatalf("expected failure, got %d", code)
+	}
+}
@@ -0,0 +1,125 @@
+// stats.go はプロジェクト全体の課題集計を表示するサブコマンドを担い、集計結果の保存や課題の編集は扱わない。
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"ratta/internal/app/issuescan"
+	"ratta/internal/domain/issue"
+	"ratta/internal/present"
+)
+
+// statsStatusOrder は DD-CLI-006 の stats の表でステータスを並べる順序を表す。GUI のステータス選択と同じ順とする。
+var statsStatusOrder = []string{
+	string(issue.StatusOpen),
+	string(issue.StatusWorking),
+	string(issue.StatusInquiry),
+	string(issue.StatusHold),
+	string(issue.StatusFeedback),
+	string(issue.StatusResolved),
+	string(issue.StatusClosed),
+	string(issue.StatusRejected),
+}
+
+// statsPriorityOrder は DD-CLI-006 の stats の表で優先度を並べる順序を表す。
+var statsPriorityOrder = []string{
+	string(issue.PriorityHigh),
+	string(issue.PriorityMedium),
+	string(issue.PriorityLow),
+}
+
+// runStats は DD-CLI-006 の stats サブコマンドを実行する。
+// 目的: 週次報告などの定期処理から、GUI を起動せずにプロジェクト全体の課題件数と滞留状況を取得できるようにする。
+// 入力: args は `[--oldest n] [--format table|json] <root>`、env は実行環境。
+// 出力: 終了コード。成功時は 0、集計失敗時は 1、引数の不備は 2。
+// エラー: カテゴリ一覧や課題ディレクトリを読めない場合は標準エラーへ書く。解析できない課題JSONは errors 件数に計上する。
+// 副作用: 標準出力へ集計結果を書く。プロジェクト配下のファイルは変更しない。
+// 並行性: 単一ゴルーチンで実行する。
+// 不変条件: 件数・期限超過の判定は GUI のカテゴリ集計と同じ規則に従う。
+// 関連DD: DD-CLI-006, DD-STATS-001
+func runStats(args []string, env Env) int {
+	fs := newFlagSet("stats", env)
+	oldest := fs.Int("oldest", 5, "number of oldest open issues to list")
+	format := fs.String("format", defaultFormat(env), "output format: table or json (default json with --json)")
+	positional, err := parseArgs(fs, args, "root")
+	if err == nil && *oldest < 0 {
+		err = fmt.Errorf("--oldest must not be negative")
+	}
+	if err == nil {
+		err = checkFormat(*format)
+	}
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+
+	stats, err := issuescan.NewScanner(nil).ProjectStatsContext(context.Background(), positional[0], *oldest)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "stats: %v\n", err)
+		return exitFailure
+	}
+	if *format == formatJSON {
+		err = writeJSON(env.Stdout, present.ToProjectStatsDTO(stats))
+	} else {
+		err = writeStatsTable(env.Stdout, stats)
+	}
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "stats: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// writeStatsTable は DD-CLI-006 の集計結果を、合計・カテゴリ別・ステータス別・優先度別・未完了の古い課題の順に表として書く。
+func writeStatsTable(out io.Writer, stats issuescan.ProjectStats) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "total %d, open %d, overdue %d, errors %d\n", stats.Total, stats.OpenCount, stats.OverdueCount, stats.ErrorCount)
+
+	fmt.Fprintln(w, "\nCATEGORY\tTOTAL\tOPEN\tOVERDUE\tERRORS")
+	for _, category := range stats.Categories {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", category.Category, category.Total, category.OpenCount, category.OverdueCount, category.ErrorCount)
+	}
+
+	fmt.Fprintln(w, "\nSTATUS\tCOUNT")
+	for _, key := range orderedKeys(stats.ByStatus, statsStatusOrder) {
+		fmt.Fprintf(w, "%s\t%d\n", key, stats.ByStatus[key])
+	}
+
+	fmt.Fprintln(w, "\nPRIORITY\tCOUNT")
+	for _, key := range orderedKeys(stats.ByPriority, statsPriorityOrder) {
+		fmt.Fprintf(w, "%s\t%d\n", key, stats.ByPriority[key])
+	}
+
+	if len(stats.OldestOpen) > 0 {
+		fmt.Fprintln(w, "\nCATEGORY\tISSUE_ID\tCREATED_AT\tDUE_DATE\tSTATUS\tTITLE")
+		for _, item := range stats.OldestOpen {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				item.Category, item.IssueID, item.CreatedAt, item.DueDate, item.Status, oneLine(item.Title))
+		}
+	}
+	return w.Flush()
+}
+
+// orderedKeys は DD-CLI-006 の件数表の行順を返す。既知の値は known の順、それ以外 (空値や未知の値) は名前順で後ろに並べる。
+func orderedKeys(counts map[string]int, known []string) []string {
+	keys := make([]string, 0, len(counts))
+	seen := make(map[string]bool, len(known))
+	for _, key := range known {
+		seen[key] = true
+		if counts[key] > 0 {
+			keys = append(keys, key)
+		}
+	}
+	var rest []string
+	for key := range counts {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
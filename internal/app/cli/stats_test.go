@@ -0,0 +1,53 @@
+// stats_test.go は stats サブコマンドの集計結果と出力形式のテストを行う。
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"ratta/internal/domain/issue"
+	"ratta/internal/present"
+)
+
+func TestStats_ReportsProjectTotals(t *testing.T) {
+	// 全カテゴリの件数・期限超過件数と、上限件数までの未完了課題が JSON と表の双方で出力されることを確認する。
+	root, _ := newProject(t)
+	createIssue(t, root, "urgent", issue.PriorityHigh)
+
+	code, stdout, stderr := runCommand(t, "stats", "--oldest", "1", "--format", "json", root)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	var stats present.ProjectStatsDTO
+	if err := json.Unmarshal([]byte(stdout), &stats); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	// 前提: テスト用の課題は期限 2024-01-01 の Open のため、いずれも期限超過となる。
+	if stats.Total != 2 || stats.OpenCount != 2 || stats.OverdueCount != 2 || stats.ByPriority["High"] != 1 {
+		t.Fatalf("unexpected totals: %+v", stats)
+	}
+	if len(stats.Categories) != 1 || stats.Categories[0].Category != "cat" || len(stats.OldestOpen) != 1 {
+		t.Fatalf("unexpected breakdown: %+v", stats)
+	}
+
+	code, stdout, _ = runCommand(t, "stats", root)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d", code)
+	}
+	if !strings.HasPrefix(stdout, "total 2, open 2, overdue 2, errors 0\n") ||
+		!strings.Contains(stdout, "\nOpen    2\n") || !strings.Contains(stdout, "ISSUE_ID") {
+		t.Fatalf("unexpected table: %q", stdout)
+	}
+}
+
+func TestStats_RejectsInvalidFlags(t *testing.T) {
+	// 負の件数や未対応の出力形式は終了コード 2 となることを確認する。
+	root, _ := newProject(t)
+	if code, _, _ := runCommand(t, "stats", "--oldest", "-1", root); code != exitUsage {
+		t.Fatalf("expected usage error for oldest, got %d", code)
+	}
+	if code, _, _ := runCommand(t, "stats", "--format", "csv", root); code != exitUsage {
+		t.Fatalf("expected usage error for format, got %d", code)
+	}
+}
@@ -0,0 +1,121 @@
+// sync.go は2つのプロジェクトの課題を同期するサブコマンドを担い、三方向マージの詳細は projectsync に委ねる。
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ratta/internal/app/projectsync"
+	"ratta/internal/infra/atomicwrite"
+	"ratta/internal/infra/gitcommit"
+	"ratta/internal/infra/jsonfmt"
+	"ratta/internal/present"
+)
+
+// gitOperationProjectSynced は DD-CLI-006 の同期の自動コミットのメッセージに記録する操作名を表す。
+const gitOperationProjectSynced = "project_synced"
+
+// runSync は DD-CLI-006 の sync サブコマンドを実行する。
+// 目的: Vendor の手元の複製と共有フォルダのように別々に変更した同じプロジェクトを、GUI を起動せずに突き合わせて統合する。
+// 入力: args は `[--dry-run] [--report file.json] [--contractor] [--schemas dir] <root> <other-root>`、env は実行環境。
+// 出力: 終了コード。競合・失敗が無ければ 0、競合や失敗した課題がある場合や同期できない場合は 1、引数の不備は 2。
+// エラー: 課題ごとの失敗は標準出力の結果に含め、残りの課題の処理を続ける。
+// 副作用: 両方のプロジェクトの課題JSON・添付を更新し、標準出力へ1行1件のタブ区切り (結果, カテゴリ/課題ID, 競合した項目またはメッセージ) を
+// (--json 指定時は同期の結果を JSON で)、標準エラーへ件数の要約を書く。--report 指定時は競合の報告を JSON で書き出す。
+// プロジェクト設定で git の自動コミットが有効な場合は、それぞれのプロジェクトで変更をコミットする。dry-run では課題を保存しない。
+// 並行性: 両方のプロジェクトの書き込み用ロックを取得して実行し、GUI が開いている間は同期しない。dry-run はロックを取得しない。
+// 不変条件: GUI の StartSyncProject と同じ規則で同期する。前回の同期の記録は root 側に残す。
+// 関連DD: DD-CLI-006, DD-SYNC-001, DD-SYNC-002, DD-SYNC-003, DD-LOCK-002
+func runSync(args []string, env Env) int {
+	fs := newFlagSet("sync", env)
+	dryRun := fs.Bool("dry-run", false, "report what would change without saving")
+	report := fs.String("report", "", "write the synchronization report (including conflicts) as JSON to this file")
+	contractor := fs.Bool("contractor", false, "operate in contractor mode (password from "+contractorPasswordEnv+" or prompt)")
+	schemasDir := fs.String("schemas", "", "directory containing the JSON schemas")
+	positional, err := parseArgs(fs, args, "root", "other-root")
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	validator, err := optionalValidator(env, *schemasDir)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "load schemas: %v\n", err)
+		return exitUsage
+	}
+	currentMode, err := resolveMode(env, *contractor, validator)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "sync: %v\n", err)
+		return exitFailure
+	}
+
+	root, other := positional[0], positional[1]
+	var result projectsync.Result
+	run := func() error {
+		var syncErr error
+		result, syncErr = projectsync.Sync(context.Background(), root, other, validator, currentMode, projectsync.Options{DryRun: *dryRun})
+		if syncErr == nil && !*dryRun {
+			commitChange(env, root, currentMode, gitcommit.Change{Operation: gitOperationProjectSynced})
+			commitChange(env, other, currentMode, gitcommit.Change{Operation: gitOperationProjectSynced})
+		}
+		return syncErr
+	}
+	lock := writeLockUnlessDryRun(*dryRun)
+	err = lock(root, func() error { return lock(other, run) })
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "sync: %v\n", err)
+		return exitFailure
+	}
+
+	dto := present.ToSyncDTO(result)
+	if *report != "" {
+		if writeErr := writeSyncReport(*report, dto); writeErr != nil {
+			fmt.Fprintf(env.Stderr, "sync: %v\n", writeErr)
+			return exitFailure
+		}
+	}
+	if env.JSON {
+		if writeErr := writeJSON(env.Stdout, dto); writeErr != nil {
+			fmt.Fprintf(env.Stderr, "sync: %v\n", writeErr)
+			return exitFailure
+		}
+	} else {
+		for _, item := range result.Issues {
+			fmt.Fprintf(env.Stdout, "%s\t%s/%s\t%s\n", item.Action, item.Category, item.IssueID, syncDetail(item))
+		}
+	}
+	prefix := ""
+	if *dryRun {
+		prefix = "dry run: "
+	}
+	fmt.Fprintf(env.Stderr, "%scopied %d, merged %d, unchanged %d issues, %d conflicts, %d failed\n",
+		prefix, result.Copied, result.Merged, result.Unchanged, result.Conflicted, result.Failed)
+	if result.Conflicted > 0 || result.Failed > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// syncDetail は DD-CLI-006 の表形式の出力で、競合した項目名または失敗の理由を返す。
+func syncDetail(item projectsync.IssueResult) string {
+	if item.Message != "" {
+		return oneLine(item.Message)
+	}
+	fields := make([]string, 0, len(item.Conflicts))
+	for _, conflict := range item.Conflicts {
+		fields = append(fields, conflict.Field)
+	}
+	return strings.Join(fields, ",")
+}
+
+// writeSyncReport は DD-SYNC-001 の同期の結果を、手作業で競合を解決するための報告として JSON で書き出す。
+func writeSyncReport(path string, dto present.SyncDTO) error {
+	data, err := jsonfmt.MarshalCanonical(dto)
+	if err != nil {
+		return fmt.Errorf("marshal report: %w", err)
+	}
+	if err := atomicwrite.WriteFile(path, data); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// sync_test.go は sync サブコマンドの複製・競合の報告・dry-run のテストを行う。
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/infra/schema"
+	"ratta/internal/present"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// setTitle はテスト用にカテゴリ cat の課題の件名を書き換える。
+func setTitle(t *testing.T, root, issueID, title string) {
+	t.Helper()
+	validator, err := schema.NewValidatorFromDir(schemasDir)
+	if err != nil {
+		t.Fatalf("load schemas: %v", err)
+	}
+	service := issueops.NewService(root, validator)
+	detail, err := service.GetIssue("cat", issueID)
+	if err != nil {
+		t.Fatalf("GetIssue error: %v", err)
+	}
+	detail.Issue.Title = title
+	if _, err := service.SaveImportedIssue("cat", mod.ModeVendor, detail.Issue); err != nil {
+		t.Fatalf("SaveImportedIssue error: %v", err)
+	}
+}
+
+func TestSync_CopiesIssuesAndReportsConflicts(t *testing.T) {
+	// 相手に無い課題を複製し、両側で件名を変えた課題は競合として報告ファイルへ書き出して終了コード 1 とすることを確認する。
+	root, issueID := newProject(t)
+	other := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(other, "cat"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	code, stdout, stderr := runCommand(t, "sync", "--schemas", schemasDir, "--dry-run", root, other)
+	if code != exitOK || stdout != "copied_to_remote\tcat/"+issueID+"\t\n" || !strings.HasPrefix(stderr, "dry run: copied 1") {
+		t.Fatalf("unexpected dry run: %d %q %q", code, stdout, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(other, "cat", issueID+".json")); !os.IsNotExist(err) {
+		t.Fatalf("dry run must not save: %v", err)
+	}
+	if code, _, stderr = runCommand(t, "sync", "--schemas", schemasDir, root, other); code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(other, "cat", issueID+".json")); err != nil {
+		t.Fatalf("expected issue to be copied: %v", err)
+	}
+
+	setTitle(t, root, issueID, "local title")
+	setTitle(t, other, issueID, "remote title")
+	report := filepath.Join(t.TempDir(), "report.json")
+	code, stdout, stderr = runCommand(t, "sync", "--schemas", schemasDir, "--report", report, root, other)
+	if code != exitFailure || stdout != "conflict\tcat/"+issueID+"\ttitle\n" || !strings.Contains(stderr, "1 conflicts") {
+		t.Fatalf("unexpected conflict: %d %q %q", code, stdout, stderr)
+	}
+	data, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	var dto present.SyncDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	conflict := dto.Issues[0].Conflicts[0]
+	if dto.Conflicted != 1 || conflict.Base != "first" || conflict.Local != "local title" || conflict.Remote != "remote title" {
+		t.Fatalf("unexpected report: %+v", dto)
+	}
+}
@@ -0,0 +1,142 @@
+// validate.go はプロジェクト全体の課題JSONのスキーマ検査サブコマンドを担い、不整合の修復は扱わない。
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ratta/internal/app/categoryscan"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/schema"
+)
+
+// validationFailure は DD-CLI-006 の検査で見つかった1件の不整合を表す。
+// Path はプロジェクトルートからの相対パス (区切りは /) とする。
+type validationFailure struct {
+	Path             string `json:"path"`
+	InstanceLocation string `json:"instance_location"`
+	Message          string `json:"message"`
+}
+
+// validationReport は DD-CLI-006 の --json 指定時の validate の出力を表す。
+type validationReport struct {
+	Checked  int                 `json:"checked"`
+	Problems []validationFailure `json:"problems"`
+}
+
+// runValidate は DD-CLI-006 の validate サブコマンドを実行する。
+// 目的: 追跡対象のプロジェクトフォルダを CI で検査できるよう、全カテゴリの課題JSONをスキーマ検証する。
+// 入力: args は `[--schemas <dir>] <root>`、env は実行環境。
+// 出力: 終了コード。不整合が無ければ 0、あれば 1、引数やスキーマ (extensions 配下の追加スキーマを含む) の不備は 2。
+// エラー: カテゴリの走査や課題JSONの読み取りに失敗した場合は不整合として報告する。
+// 副作用: 標準出力へ不整合を1行1件のタブ区切り (パス, インスタンス位置, メッセージ) で
+// (--json 指定時は件数と不整合の一覧を JSON で) 書き、標準エラーへ件数の要約を書く。
+// 並行性: 単一ゴルーチンで実行する。
+// 不変条件: 出力はパス順で、同じ内容のプロジェクトに対して常に同じになる。
+// 関連DD: DD-CLI-006, DD-BE-002, DD-LOAD-003
+func runValidate(args []string, env Env) int {
+	fs := newFlagSet("validate", env)
+	schemasDir := fs.String("schemas", "", "directory containing issue.schema.json")
+	positional, err := parseArgs(fs, args, "root")
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	validator, err := loadValidator(env.ExePath, *schemasDir)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "load schemas: %v\n", err)
+		return exitUsage
+	}
+	// CI で規則の記述誤りを見逃さないよう、読み込めない追加スキーマがある場合は検査しない。
+	if problems := validator.ExtensionProblems(); len(problems) > 0 {
+		for _, problem := range problems {
+			fmt.Fprintf(env.Stderr, "load schema extension %s: %v\n", problem.Name, problem.Err)
+		}
+		return exitUsage
+	}
+
+	failures, checked, err := validateProject(positional[0], validator)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "validate: %v\n", err)
+		return exitUsage
+	}
+	if env.JSON {
+		if failures == nil {
+			failures = []validationFailure{}
+		}
+		if writeErr := writeJSON(env.Stdout, validationReport{Checked: checked, Problems: failures}); writeErr != nil {
+			fmt.Fprintf(env.Stderr, "validate: %v\n", writeErr)
+			return exitFailure
+		}
+	} else {
+		for _, failure := range failures {
+			fmt.Fprintf(env.Stdout, "%s\t%s\t%s\n", failure.Path, failure.InstanceLocation, oneLine(failure.Message))
+		}
+	}
+	fmt.Fprintf(env.Stderr, "checked %d issue files, %d problems\n", checked, len(failures))
+	if len(failures) > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// validateProject は DD-CLI-006 のプロジェクト全体の検査を行う。
+// 目的: 全カテゴリ (アーカイブ済みを含む) の課題JSONを検証し、不整合を列挙する。
+// 入力: root はプロジェクトルート、validator はスキーマ検証器。
+// 出力: パス順の不整合、検査した課題ファイル数、エラー。
+// エラー: プロジェクトルートの走査に失敗した場合に返す。
+// 副作用: なし。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: 読み取りや JSON 解析に失敗したファイルはインスタンス位置を空として不整合に含める。
+// 関連DD: DD-CLI-006, DD-BE-002
+func validateProject(root string, validator *schema.Validator) ([]validationFailure, int, error) {
+	scanned, err := categoryscan.Scan(root)
+	if err != nil {
+		return nil, 0, err
+	}
+	var failures []validationFailure
+	checked := 0
+	for _, category := range scanned.Categories {
+		entries, readErr := os.ReadDir(category.Path)
+		if readErr != nil {
+			failures = append(failures, validationFailure{Path: category.Name, Message: fmt.Sprintf("read category: %v", readErr)})
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !issue.IsIssueFileName(entry.Name()) {
+				continue
+			}
+			checked++
+			rel := category.Name + "/" + entry.Name()
+			failures = append(failures, validateIssueFile(filepath.Join(category.Path, entry.Name()), rel, validator)...)
+		}
+	}
+	sort.SliceStable(failures, func(i, j int) bool { return failures[i].Path < failures[j].Path })
+	return failures, checked, nil
+}
+
+// validateIssueFile は DD-CLI-006 の課題JSON1件の検査を行い、不整合を返す。
+func validateIssueFile(path, rel string, validator *schema.Validator) []validationFailure {
+	// #nosec G304 -- カテゴリ配下の列挙結果から生成したパスのみを読む。
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []validationFailure{{Path: rel, Message: fmt.Sprintf("read issue: %v", err)}}
+	}
+	result, err := validator.ValidateIssue(data)
+	if err != nil {
+		return []validationFailure{{Path: rel, Message: err.Error()}}
+	}
+	failures := make([]validationFailure, 0, len(result.Issues))
+	for _, found := range result.Issues {
+		failures = append(failures, validationFailure{Path: rel, InstanceLocation: found.InstanceLocation, Message: found.Message})
+	}
+	return failures
+}
+
+// oneLine は DD-CLI-006 の1行1件の出力を保つため、メッセージ中のタブと改行を空白に置き換える。
+func oneLine(message string) string {
+	return strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ").Replace(message)
+}
@@ -0,0 +1,166 @@
+// validate_test.go は validate サブコマンドの検査結果と終了コードのテストを行う。
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/schema"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// schemasDir はテストで用いるリポジトリの schemas ディレクトリを表す。
+var schemasDir = filepath.Join("..", "..", "..", "schemas")
+
+// newProject はテスト用にカテゴリ cat と課題1件を持つプロジェクトを作成し、ルートと課題IDを返す。
+func newProject(t *testing.T) (string, string) {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "cat"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	return root, createIssue(t, root, "first", issue.PriorityLow)
+}
+
+// createIssue はテスト用にカテゴリ cat へ課題を作成し、課題IDを返す。
+func createIssue(t *testing.T, root, title string, priority issue.Priority) string {
+	t.Helper()
+	validator, err := schema.NewValidatorFromDir(schemasDir)
+	if err != nil {
+		t.Fatalf("load schemas: %v", err)
+	}
+	created, err := issueops.NewService(root, validator).CreateIssue("cat", mod.ModeVendor, issueops.IssueCreateInput{
+		Title:       title,
+		Description: "desc",
+		DueDate:     "2024-01-01",
+		Priority:    priority,
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	return created.Issue.IssueID
+}
+
+// runCommand はテスト用にサブコマンドを実行し、終了コードと標準出力・標準エラーを返す。
+func runCommand(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	return runCommandWith(t, "", args...)
+}
+
+// runCommandWith はテスト用に実行ファイルのパスを与えてサブコマンドを実行し、終了コードと標準出力・標準エラーを返す。
+func runCommandWith(t *testing.T, exePath string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	handled, code := Run(args, Env{ExePath: exePath, Stdout: &stdout, Stderr: &stderr})
+	if !handled {
+		t.Fatalf("expected %v to be handled", args)
+	}
+	return code, stdout.String(), stderr.String()
+}
+
+func TestValidate_PassesForValidProject(t *testing.T) {
+	// 全課題がスキーマに適合する場合は出力が無く終了コード 0 となることを確認する。
+	root, _ := newProject(t)
+	code, stdout, stderr := runCommand(t, "validate", "--schemas", schemasDir, root)
+	if code != exitOK || stdout != "" {
+		t.Fatalf("expected success, got %d %q %q", code, stdout, stderr)
+	}
+	if !strings.Contains(stderr, "checked 1 issue files, 0 problems") {
+		t.Fatalf("unexpected summary: %q", stderr)
+	}
+}
+
+func TestValidate_RejectsBrokenSchemaExtension(t *testing.T) {
+	// extensions 配下に読み込めない追加スキーマがある場合は検査せず、スキーマの不備 (終了コード 2) とすることを確認する。
+	root, _ := newProject(t)
+	dir := t.TempDir()
+	if err := os.CopyFS(dir, os.DirFS(schemasDir)); err != nil {
+		t.Fatalf("copy schemas: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, schema.ExtensionsDirName, "broken.json"), `{"type":5}`)
+
+	code, stdout, stderr := runCommand(t, "validate", "--schemas", dir, root)
+	if code != exitUsage || stdout != "" || !strings.Contains(stderr, "load schema extension broken.json") {
+		t.Fatalf("expected usage error, got %d %q %q", code, stdout, stderr)
+	}
+}
+
+func TestValidate_ReportsInvalidIssuesAndFails(t *testing.T) {
+	// スキーマ不整合と解析できない課題をパス・位置・メッセージで報告し、終了コード 1 となることを確認する。
+	root, issueID := newProject(t)
+	path := filepath.Join(root, "cat", issueID+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if err := os.WriteFile(path, bytes.Replace(data, []byte(`"Low"`), []byte(`"Unknown"`), 1), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "cat", "broken.json"), []byte("{"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	code, stdout, _ := runCommand(t, "validate", "--schemas", schemasDir, root)
+	if code != exitFailure {
+		t.Fatalf("expected failure, got %d", code)
+	}
+	// 課題IDは生成のたびに変わるため、broken.json との前後に依存せずに行を確かめる。
+	lines := strings.Split(strings.TrimRight(stdout, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", stdout)
+	}
+	schemaLine, parseLine := lines[0], lines[1]
+	if strings.HasPrefix(schemaLine, "cat/broken.json") {
+		schemaLine, parseLine = parseLine, schemaLine
+	}
+	if !strings.HasPrefix(schemaLine, "cat/"+issueID+".json\t/priority\t") {
+		t.Fatalf("unexpected schema failure line: %q", schemaLine)
+	}
+	if !strings.HasPrefix(parseLine, "cat/broken.json\t\t") {
+		t.Fatalf("unexpected parse failure line: %q", parseLine)
+	}
+}
+
+func TestRun_LeavesUnknownArgumentsToGUI(t *testing.T) {
+	// サブコマンドでない引数は処理せず、引数不備は終了コード 2 となることを確認する。
+	if handled, _ := Run([]string{"--unknown"}, Env{}); handled {
+		t.Fatalf("expected unknown argument not to be handled")
+	}
+	if code, _, _ := runCommand(t, "validate"); code != exitUsage {
+		t.Fatalf("expected usage error, got %d", code)
+	}
+}
+
+func TestRun_GlobalJSONFlag(t *testing.T) {
+	// サブコマンドより前の --json で結果が JSON になり、--json のみや未知のコマンドは引数の不備となることを確認する。
+	root, _ := newProject(t)
+	code, stdout, stderr := runCommand(t, "--json", "validate", "--schemas", schemasDir, root)
+	if code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr)
+	}
+	var report validationReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("expected JSON output: %v %q", err, stdout)
+	}
+	if report.Checked != 1 || report.Problems == nil || len(report.Problems) != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	code, stdout, _ = runCommand(t, "--json", "list", "--schemas", schemasDir, root, "cat")
+	if code != exitOK || !strings.HasPrefix(stdout, "{") {
+		t.Fatalf("expected list to default to JSON, got %d %q", code, stdout)
+	}
+
+	for _, args := range [][]string{{"--json"}, {"--json", "unknown"}} {
+		if handled, code := Run(args, Env{Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}}); !handled || code != exitUsage {
+			t.Fatalf("expected usage error for %v, got handled=%v code=%d", args, handled, code)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// version.go はバージョン情報を表示するサブコマンドを担い、更新の確認や配布物の検証は扱わない。
+// 現地からの不具合報告の切り分けに用いるため、ビルドを一意に識別できる情報をまとめて出力する。
+package cli
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"ratta/internal/app/migrate"
+)
+
+// BuildInfo は DD-CLI-006 のビルド時に -ldflags で埋め込むバージョン情報を表す。空の項目は不明として扱う。
+type BuildInfo struct {
+	Version   string
+	Commit    string
+	BuildDate string
+}
+
+// versionReport は DD-CLI-006 の version サブコマンドの出力を表す。
+// FormatVersions はファイル種別ごとに読み書きできる最新の版を表す。
+type versionReport struct {
+	Version        string         `json:"version"`
+	Commit         string         `json:"commit"`
+	BuildDate      string         `json:"build_date"`
+	GoVersion      string         `json:"go_version"`
+	OS             string         `json:"os"`
+	Arch           string         `json:"arch"`
+	FormatVersions map[string]int `json:"format_versions"`
+}
+
+// readBuildInfo は DD-CLI-006 の VCS 情報の取得をテストで差し替えるための差し替え点。
+var readBuildInfo = debug.ReadBuildInfo
+
+// runVersion は DD-CLI-006 の version サブコマンドを実行する。
+// 目的: 不具合報告を受けた際に、利用者の環境のビルドと対応するデータ形式の版を特定できるようにする。
+// 入力: args は `[--format table|json]`、env は実行環境。
+// 出力: 終了コード。成功時は 0、引数の不備は 2。
+// エラー: 出力に失敗した場合は標準エラーへ書く。
+// 副作用: 標準出力へバージョン情報を書く。
+// 並行性: 単一ゴルーチンで実行する。
+// 不変条件: コミットやビルド日時が埋め込まれていない場合は Go が記録した VCS 情報で補い、それも無い場合は unknown とする。
+// 関連DD: DD-CLI-006, DD-MIGRATE-001, DD-DIAG-001
+func runVersion(args []string, env Env) int {
+	fs := newFlagSet("version", env)
+	format := fs.String("format", defaultFormat(env), "output format: table or json (default json with --json)")
+	err := fs.Parse(args)
+	if err == nil && fs.NArg() != 0 {
+		err = fmt.Errorf("usage: ratta version [flags]")
+	}
+	if err == nil {
+		err = checkFormat(*format)
+	}
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+
+	report := buildVersionReport(env.Build)
+	if *format == formatJSON {
+		err = writeJSON(env.Stdout, report)
+	} else {
+		err = writeVersionTable(env, report)
+	}
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "version: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// buildVersionReport は DD-CLI-006 の埋め込み値と実行環境からバージョン情報を組み立てる。
+func buildVersionReport(build BuildInfo) versionReport {
+	report := versionReport{
+		Version:        build.Version,
+		Commit:         build.Commit,
+		BuildDate:      build.BuildDate,
+		GoVersion:      runtime.Version(),
+		OS:             runtime.GOOS,
+		Arch:           runtime.GOARCH,
+		FormatVersions: map[string]int{},
+	}
+	if info, ok := readBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && report.Commit == "":
+				report.Commit = setting.Value
+			case setting.Key == "vcs.time" && report.BuildDate == "":
+				report.BuildDate = setting.Value
+			case setting.Key == "vcs.modified" && setting.Value == "true" && report.Commit != "" && build.Commit == "":
+				report.Commit += "-dirty"
+			}
+		}
+	}
+	for _, value := range []*string{&report.Version, &report.Commit, &report.BuildDate} {
+		if *value == "" {
+			*value = "unknown"
+		}
+	}
+	for kind, version := range migrate.CurrentVersions() {
+		report.FormatVersions[string(kind)] = version
+	}
+	return report
+}
+
+// writeVersionTable は DD-CLI-006 のバージョン情報を人が読むための形式で書く。データ形式の版は種別名順とする。
+func writeVersionTable(env Env, report versionReport) error {
+	kinds := make([]string, 0, len(report.FormatVersions))
+	for kind := range report.FormatVersions {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	formats := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		formats = append(formats, fmt.Sprintf("%s %d", kind, report.FormatVersions[kind]))
+	}
+	_, err := fmt.Fprintf(env.Stdout, "ratta %s\ncommit:     %s\nbuild date: %s\ngo:         %s %s/%s\nformats:    %s\n",
+		report.Version, report.Commit, report.BuildDate, report.GoVersion, report.OS, report.Arch, strings.Join(formats, ", "))
+	return err
+}
@@ -0,0 +1,53 @@
+// version_test.go は version サブコマンドの埋め込み値・VCS 情報による補完・出力形式のテストを行う。
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestVersion_PrintsEmbeddedBuildInfo(t *testing.T) {
+	// 埋め込まれたバージョン情報とデータ形式の版を表形式と JSON で出力することを確認する。
+	build := BuildInfo{Version: "1.2.3", Commit: "abc1234", BuildDate: "2024-05-01T00:00:00Z"}
+	var stdout, stderr bytes.Buffer
+	if _, code := Run([]string{"version"}, Env{Stdout: &stdout, Stderr: &stderr, Build: build}); code != exitOK {
+		t.Fatalf("expected success, got %d %q", code, stderr.String())
+	}
+	for _, want := range []string{"ratta 1.2.3\n", "commit:     abc1234\n", "build date: 2024-05-01T00:00:00Z\n", "issue 2"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Fatalf("expected %q in %q", want, stdout.String())
+		}
+	}
+
+	stdout.Reset()
+	if _, code := Run([]string{"--json", "version"}, Env{Stdout: &stdout, Stderr: &stderr, Build: build}); code != exitOK {
+		t.Fatalf("expected success, got %d", code)
+	}
+	var report versionReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("expected JSON output: %v %q", err, stdout.String())
+	}
+	if report.Version != "1.2.3" || report.Commit != "abc1234" || report.FormatVersions["issue"] != 2 || report.FormatVersions["config"] != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
+
+func TestVersion_FallsBackToVCSInfo(t *testing.T) {
+	// コミットとビルド日時が埋め込まれていない場合は VCS 情報で補い、無い項目は unknown とすることを確認する。
+	previous := readBuildInfo
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "deadbeef"},
+			{Key: "vcs.modified", Value: "true"},
+		}}, true
+	}
+	t.Cleanup(func() { readBuildInfo = previous })
+
+	report := buildVersionReport(BuildInfo{Version: "dev"})
+	if report.Commit != "deadbeef-dirty" || report.BuildDate != "unknown" || report.Version != "dev" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
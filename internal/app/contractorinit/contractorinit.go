@@ -1,82 +1,202 @@
-// Package contractorinit は contractor.json 生成のユースケースを提供し、UIや通信は扱わない。
+// Package contractorinit は contractor.json と users.json の生成とパスワード変更のユースケースを提供し、UIや通信は扱わない。
 // 暗号化の詳細実装は infra 層に委ねる。
 package contractorinit
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
+	"ratta/internal/domain/apperr"
 	"ratta/internal/infra/atomicwrite"
 	"ratta/internal/infra/crypto"
 	"ratta/internal/infra/jsonfmt"
 )
 
 var (
-	generateAuth = crypto.GenerateContractorAuth
+	generateAuth = crypto.GenerateContractorAuthWithKDF
 	marshalAuth  = jsonfmt.MarshalContractor
 	writeFile    = atomicwrite.WriteFile
 	statFile     = os.Stat
+	readFile     = os.ReadFile
 	mkdirAll     = os.MkdirAll
 )
 
+const (
+	// totpIssuer は DD-CLI-008 の認証アプリに表示する発行者名を表す。
+	totpIssuer = "ratta"
+	// totpAccount は DD-CLI-008 の認証アプリに表示するアカウント名を表す。
+	totpAccount = "contractor"
+)
+
 // Prompter は DD-CLI-003 のパスワード入力を抽象化する。
 type Prompter interface {
 	PromptHidden(label string) (string, error)
 }
 
-// Run は DD-CLI-002/003/004 に従い contractor.json を生成する。
+// Run は DD-CLI-002/003/004 に従い既定の鍵導出設定で contractor.json を生成する。
+func Run(exePath string, force bool, prompter Prompter) error {
+	return RunWithKDF(exePath, force, crypto.DefaultKDFParams(), prompter)
+}
+
+// TOTPEnrollment は DD-CLI-008 の認証アプリへ登録する TOTP の情報を表す。
+// Secret は手入力用の Base32 表記、URI は QR コード化して読み取らせる otpauth URI を表す。
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// RunWithKDF は DD-CLI-002/003/004/005 に従い指定した鍵導出設定で contractor.json を生成する。
+func RunWithKDF(exePath string, force bool, kdf crypto.KDFParams, prompter Prompter) error {
+	_, err := generateContractor(exePath, force, kdf, false, prompter)
+	return err
+}
+
+// RunWithTOTP は DD-CLI-008 に従い、TOTP の秘密鍵を埋め込んだ contractor.json を生成し、認証アプリへの登録情報を返す。
+func RunWithTOTP(exePath string, force bool, kdf crypto.KDFParams, prompter Prompter) (TOTPEnrollment, error) {
+	return generateContractor(exePath, force, kdf, true, prompter)
+}
+
+// generateContractor は DD-CLI-002/003/004/005/008 に従い指定した鍵導出設定で contractor.json を生成する。
 // 目的: Contractor 認証情報ファイルを生成し所定の配置に保存する。
-// 入力: exePath は実行ファイルのパス、force は上書き許可、prompter は入力手段。
-// 出力: 成功時は nil、失敗時はエラー。
-// エラー: 入力不備、既存ファイル衝突、暗号化や保存失敗時に返す。
-// 副作用: auth ディレクトリ作成と contractor.json 書き込みを行う。
+// 入力: exePath は実行ファイルのパス、force は上書き許可、kdf は鍵導出設定、withTOTP は TOTP の有効化、prompter は入力手段。
+// 出力: withTOTP の場合は認証アプリへの登録情報、失敗時はエラー。
+// エラー: 入力不備、強度の規則を満たさないパスワード、未対応の鍵導出設定、既存ファイル衝突、暗号化や保存失敗時に返す。
+// 副作用: config.json を読み取り、auth ディレクトリ作成と contractor.json 書き込みを行う。
 // 並行性: 同一パスへの同時実行は想定しない。
-// 不変条件: 保存する JSON は暗号化済みパスワードを含む。
-// 関連DD: DD-CLI-002, DD-CLI-003, DD-CLI-004
-func Run(exePath string, force bool, prompter Prompter) error {
+// 不変条件: 保存する JSON は暗号化済みパスワードを含む。鍵導出設定と強度の規則はパスワード入力前に読み込む。
+// TOTP の秘密鍵はパスワードからの導出鍵で暗号化して保存し、平文では保存しない。
+// 関連DD: DD-CLI-002, DD-CLI-003, DD-CLI-004, DD-CLI-005, DD-CLI-008, DD-CLI-009
+func generateContractor(exePath string, force bool, kdf crypto.KDFParams, withTOTP bool, prompter Prompter) (TOTPEnrollment, error) {
 	if prompter == nil {
-		return errors.New("prompter is required")
+		return TOTPEnrollment{}, errors.New("prompter is required")
 	}
-
-	password, err := prompter.PromptHidden("Password: ")
+	kdf, err := crypto.ResolveKDFParams(kdf.Name, kdf.Iterations)
 	if err != nil {
-		return fmt.Errorf("prompt password: %w", err)
+		return TOTPEnrollment{}, err
 	}
-	confirm, err := prompter.PromptHidden("Confirm: ")
+	policy, err := loadPasswordPolicy(exePath)
 	if err != nil {
-		return fmt.Errorf("prompt confirm: %w", err)
+		return TOTPEnrollment{}, err
 	}
-	if password == "" {
-		return errors.New("password is required")
-	}
-	if password != confirm {
-		return errors.New("password confirmation does not match")
+
+	password, err := promptNewPassword(prompter, "Password: ", policy)
+	if err != nil {
+		return TOTPEnrollment{}, err
 	}
 
 	authDir := filepath.Join(filepath.Dir(exePath), "auth")
 	targetPath := filepath.Join(authDir, "contractor.json")
 
 	if exists, existsErr := fileExists(targetPath); existsErr != nil {
-		return existsErr
+		return TOTPEnrollment{}, existsErr
 	} else if exists && !force {
-		return errors.New("contractor.json already exists")
+		return TOTPEnrollment{}, errors.New("contractor.json already exists")
 	}
 
 	if mkdirErr := mkdirAll(authDir, 0o750); mkdirErr != nil {
-		return fmt.Errorf("create auth dir: %w", mkdirErr)
+		return TOTPEnrollment{}, fmt.Errorf("create auth dir: %w", mkdirErr)
 	}
 
-	auth, err := generateAuth(password)
+	auth, err := generateAuth(password, kdf)
 	if err != nil {
-		return fmt.Errorf("generate contractor auth: %w", err)
+		return TOTPEnrollment{}, fmt.Errorf("generate contractor auth: %w", err)
+	}
+	enrollment := TOTPEnrollment{}
+	if withTOTP {
+		secret, secretErr := crypto.GenerateTOTPSecret()
+		if secretErr != nil {
+			return TOTPEnrollment{}, fmt.Errorf("generate totp secret: %w", secretErr)
+		}
+		if auth, err = crypto.AttachTOTPSecret(auth, password, secret); err != nil {
+			return TOTPEnrollment{}, fmt.Errorf("attach totp secret: %w", err)
+		}
+		enrollment = TOTPEnrollment{
+			Secret: crypto.EncodeTOTPSecret(secret),
+			URI:    crypto.TOTPKeyURI(totpIssuer, totpAccount, secret),
+		}
 	}
 	data, err := marshalAuth(auth)
 	if err != nil {
-		return fmt.Errorf("marshal contractor auth: %w", err)
+		return TOTPEnrollment{}, fmt.Errorf("marshal contractor auth: %w", err)
 	}
 	if writeErr := writeFile(targetPath, data); writeErr != nil {
+		return TOTPEnrollment{}, fmt.Errorf("write contractor auth: %w", writeErr)
+	}
+	return enrollment, nil
+}
+
+// ChangePassword は DD-CLI-005 に従い contractor.json のパスワードを変更する。
+// 目的: 鍵導出設定を保ったまま、現在のパスワードを確認したうえで新しいパスワードの認証情報へ置き換える。
+// 入力: exePath は実行ファイルのパス、prompter は入力手段。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: contractor.json が無い・読めない場合、現在のパスワードが一致しない場合、
+// 新しいパスワードが空・確認と不一致・強度の規則を満たさない場合、暗号化や保存に失敗した場合に返す。
+// 副作用: salt・nonce・暗号文を再生成し、contractor.json を原子的に置き換える。
+// DD-CLI-008 の TOTP がある場合は同じ秘密鍵を新しいパスワードからの導出鍵で暗号化し直す (認証アプリの再登録は不要)。
+// 並行性: 同一パスへの同時実行は想定しない。
+// 不変条件: 現在のパスワードを確認できない場合はファイルを変更しない。kdf は変更せず、kdf_iterations は
+// 現在の生成時の下限を下回る場合のみその方式の既定値へ引き上げる。
+// 関連DD: DD-CLI-005, DD-CLI-008, DD-CLI-009, DD-PERSIST-002
+func ChangePassword(exePath string, prompter Prompter) error {
+	if prompter == nil {
+		return errors.New("prompter is required")
+	}
+	targetPath := filepath.Join(filepath.Dir(exePath), "auth", "contractor.json")
+	data, err := readFile(targetPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return apperr.New(apperr.ErrNotFound, "contractor.json not found (run init contractor first)")
+	}
+	if err != nil {
+		return fmt.Errorf("read contractor auth: %w", err)
+	}
+	var current crypto.ContractorAuth
+	if unmarshalErr := json.Unmarshal(data, &current); unmarshalErr != nil {
+		return fmt.Errorf("parse contractor auth: %w", unmarshalErr)
+	}
+	policy, err := loadPasswordPolicy(exePath)
+	if err != nil {
+		return err
+	}
+
+	password, err := prompter.PromptHidden("Current password: ")
+	if err != nil {
+		return fmt.Errorf("prompt current password: %w", err)
+	}
+	key, verifyErr := crypto.DeriveUnlockKey(current, password)
+	if verifyErr != nil {
+		if errors.Is(verifyErr, crypto.ErrPasswordMismatch) {
+			return apperr.New(apperr.ErrCrypto, "current password verification failed")
+		}
+		return fmt.Errorf("verify current password: %w", verifyErr)
+	}
+
+	newPassword, err := promptNewPassword(prompter, "New password: ", policy)
+	if err != nil {
+		return err
+	}
+
+	params := crypto.UpgradeKDFParams(crypto.KDFParams{Name: current.KDF, Iterations: current.KDFIterations})
+	auth, err := generateAuth(newPassword, params)
+	if err != nil {
+		return fmt.Errorf("generate contractor auth: %w", err)
+	}
+	if current.HasTOTP() {
+		secret, openErr := crypto.OpenTOTPSecret(current, key)
+		if openErr != nil {
+			return fmt.Errorf("open totp secret: %w", openErr)
+		}
+		if auth, err = crypto.AttachTOTPSecret(auth, newPassword, secret); err != nil {
+			return fmt.Errorf("attach totp secret: %w", err)
+		}
+	}
+	updated, err := marshalAuth(auth)
+	if err != nil {
+		return fmt.Errorf("marshal contractor auth: %w", err)
+	}
+	if writeErr := writeFile(targetPath, updated); writeErr != nil {
 		return fmt.Errorf("write contractor auth: %w", writeErr)
 	}
 	return nil
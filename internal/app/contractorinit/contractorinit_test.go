@@ -2,9 +2,11 @@
 package contractorinit
 
 import (
+	"encoding/json"
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"ratta/internal/infra/crypto"
@@ -40,7 +42,7 @@ func TestRun_CreatesAuthFile(t *testing.T) {
 	previousGenerate := generateAuth
 	previousMarshal := marshalAuth
 	previousWrite := writeFile
-	generateAuth = func(string) (crypto.ContractorAuth, error) {
+	generateAuth = func(string, crypto.KDFParams) (crypto.ContractorAuth, error) {
 		return crypto.ContractorAuth{FormatVersion: 1}, nil
 	}
 	marshalAuth = func(any) ([]byte, error) { return []byte("{\"ok\":true}\n"), nil }
@@ -53,7 +55,7 @@ func TestRun_CreatesAuthFile(t *testing.T) {
 		writeFile = previousWrite
 	})
 
-	prompter := &stubPrompter{values: []string{"secret", "secret"}}
+	prompter := &stubPrompter{values: []string{"Contractor-pass-1", "Contractor-pass-1"}}
 	if err := Run(exePath, false, prompter); err != nil {
 		t.Fatalf("Run error: %v", err)
 	}
@@ -75,7 +77,7 @@ func TestRun_RejectsOverwriteWithoutForce(t *testing.T) {
 		t.Fatalf("write existing: %v", err)
 	}
 
-	prompter := &stubPrompter{values: []string{"secret", "secret"}}
+	prompter := &stubPrompter{values: []string{"Contractor-pass-1", "Contractor-pass-1"}}
 	if err := Run(exePath, false, prompter); err == nil {
 		t.Fatal("expected overwrite to be rejected")
 	}
@@ -96,7 +98,7 @@ func TestRun_AllowsOverwriteWithForce(t *testing.T) {
 	previousGenerate := generateAuth
 	previousMarshal := marshalAuth
 	previousWrite := writeFile
-	generateAuth = func(string) (crypto.ContractorAuth, error) {
+	generateAuth = func(string, crypto.KDFParams) (crypto.ContractorAuth, error) {
 		return crypto.ContractorAuth{FormatVersion: 1}, nil
 	}
 	marshalAuth = func(any) ([]byte, error) { return []byte("{\"ok\":true}\n"), nil }
@@ -109,7 +111,7 @@ func TestRun_AllowsOverwriteWithForce(t *testing.T) {
 		writeFile = previousWrite
 	})
 
-	prompter := &stubPrompter{values: []string{"secret", "secret"}}
+	prompter := &stubPrompter{values: []string{"Contractor-pass-1", "Contractor-pass-1"}}
 	if err := Run(exePath, true, prompter); err != nil {
 		t.Fatalf("Run error: %v", err)
 	}
@@ -141,7 +143,7 @@ func TestRun_PromptError(t *testing.T) {
 
 func TestRun_PasswordMismatch(t *testing.T) {
 	// パスワード確認が一致しない場合に失敗することを確認する。
-	prompter := &stubPrompter{values: []string{"secret", "other"}}
+	prompter := &stubPrompter{values: []string{"Contractor-pass-1", "Other-password-3"}}
 	if err := Run("path", false, prompter); err == nil {
 		t.Fatal("expected mismatch error")
 	}
@@ -171,12 +173,12 @@ func TestFileExists_UnexpectedError(t *testing.T) {
 func TestRun_GenerateAuthError(t *testing.T) {
 	// 認証情報生成が失敗した場合にエラーとなることを確認する。
 	previousGenerate := generateAuth
-	generateAuth = func(string) (crypto.ContractorAuth, error) {
+	generateAuth = func(string, crypto.KDFParams) (crypto.ContractorAuth, error) {
 		return crypto.ContractorAuth{}, errors.New("generate failed")
 	}
 	t.Cleanup(func() { generateAuth = previousGenerate })
 
-	prompter := &stubPrompter{values: []string{"secret", "secret"}}
+	prompter := &stubPrompter{values: []string{"Contractor-pass-1", "Contractor-pass-1"}}
 	if err := Run("path", true, prompter); err == nil {
 		t.Fatal("expected generate error")
 	}
@@ -186,7 +188,7 @@ func TestRun_MarshalAuthError(t *testing.T) {
 	// JSON整形が失敗した場合にエラーとなることを確認する。
 	previousGenerate := generateAuth
 	previousMarshal := marshalAuth
-	generateAuth = func(string) (crypto.ContractorAuth, error) {
+	generateAuth = func(string, crypto.KDFParams) (crypto.ContractorAuth, error) {
 		return crypto.ContractorAuth{FormatVersion: 1}, nil
 	}
 	marshalAuth = func(any) ([]byte, error) {
@@ -197,7 +199,7 @@ func TestRun_MarshalAuthError(t *testing.T) {
 		marshalAuth = previousMarshal
 	})
 
-	prompter := &stubPrompter{values: []string{"secret", "secret"}}
+	prompter := &stubPrompter{values: []string{"Contractor-pass-1", "Contractor-pass-1"}}
 	if err := Run("path", true, prompter); err == nil {
 		t.Fatal("expected marshal error")
 	}
@@ -208,7 +210,7 @@ func TestRun_WriteFileError(t *testing.T) {
 	previousGenerate := generateAuth
 	previousMarshal := marshalAuth
 	previousWrite := writeFile
-	generateAuth = func(string) (crypto.ContractorAuth, error) {
+	generateAuth = func(string, crypto.KDFParams) (crypto.ContractorAuth, error) {
 		return crypto.ContractorAuth{FormatVersion: 1}, nil
 	}
 	marshalAuth = func(any) ([]byte, error) { return []byte("{}"), nil }
@@ -221,7 +223,7 @@ func TestRun_WriteFileError(t *testing.T) {
 		writeFile = previousWrite
 	})
 
-	prompter := &stubPrompter{values: []string{"secret", "secret"}}
+	prompter := &stubPrompter{values: []string{"Contractor-pass-1", "Contractor-pass-1"}}
 	if err := Run("path", true, prompter); err == nil {
 		t.Fatal("expected write error")
 	}
@@ -235,8 +237,142 @@ func TestRun_FileExistsError(t *testing.T) {
 	}
 	t.Cleanup(func() { statFile = previousStat })
 
-	prompter := &stubPrompter{values: []string{"secret", "secret"}}
+	prompter := &stubPrompter{values: []string{"Contractor-pass-1", "Contractor-pass-1"}}
 	if err := Run("path", false, prompter); err == nil {
 		t.Fatal("expected file exists error")
 	}
 }
+
+func TestRunWithKDF_PassesParamsToGenerator(t *testing.T) {
+	// 指定した鍵導出設定が既定値で補われて生成処理へ渡されることを確認する。
+	dir := t.TempDir()
+	previousGenerate := generateAuth
+	previousWrite := writeFile
+	var received crypto.KDFParams
+	generateAuth = func(_ string, kdf crypto.KDFParams) (crypto.ContractorAuth, error) {
+		received = kdf
+		return crypto.ContractorAuth{FormatVersion: 1}, nil
+	}
+	writeFile = func(path string, data []byte) error {
+		return os.WriteFile(path, data, 0o600)
+	}
+	t.Cleanup(func() {
+		generateAuth = previousGenerate
+		writeFile = previousWrite
+	})
+
+	prompter := &stubPrompter{values: []string{"Contractor-pass-1", "Contractor-pass-1"}}
+	if err := RunWithKDF(filepath.Join(dir, "ratta.exe"), false, crypto.KDFParams{Name: crypto.KDFArgon2id}, prompter); err != nil {
+		t.Fatalf("RunWithKDF error: %v", err)
+	}
+	if received.Name != crypto.KDFArgon2id || received.Iterations == 0 {
+		t.Fatalf("unexpected kdf params: %+v", received)
+	}
+}
+
+func TestRunWithKDF_RejectsUnsupportedBeforePrompt(t *testing.T) {
+	// 未対応の鍵導出設定ではパスワード入力を求めずにエラーとなることを確認する。
+	prompter := &stubPrompter{values: []string{"Contractor-pass-1", "Contractor-pass-1"}}
+	err := RunWithKDF("path", false, crypto.KDFParams{Name: crypto.KDFPBKDF2SHA256, Iterations: 1}, prompter)
+	if !errors.Is(err, crypto.ErrUnsupportedKDF) {
+		t.Fatalf("expected unsupported kdf error, got: %v", err)
+	}
+	if prompter.index != 0 {
+		t.Fatalf("expected no prompts, got %d", prompter.index)
+	}
+}
+
+func TestChangePassword_KeepsKDFSettings(t *testing.T) {
+	// パスワード変更後も鍵導出設定が保たれ、salt が再生成されることを確認する。
+	dir := t.TempDir()
+	exePath := filepath.Join(dir, "ratta.exe")
+	kdf := crypto.KDFParams{Name: crypto.KDFArgon2id, Iterations: 2}
+	if err := RunWithKDF(exePath, false, kdf, &stubPrompter{values: []string{"Old-password-1", "Old-password-1"}}); err != nil {
+		t.Fatalf("RunWithKDF error: %v", err)
+	}
+	before := readAuth(t, exePath)
+
+	if err := ChangePassword(exePath, &stubPrompter{values: []string{"Old-password-1", "New-password-2", "New-password-2"}}); err != nil {
+		t.Fatalf("ChangePassword error: %v", err)
+	}
+	after := readAuth(t, exePath)
+	if after.KDF != kdf.Name || after.KDFIterations != kdf.Iterations {
+		t.Fatalf("expected kdf settings to be kept: %+v", after)
+	}
+	if after.SaltB64 == before.SaltB64 {
+		t.Fatal("expected salt to be regenerated")
+	}
+	if ok, err := crypto.VerifyPassword(after, "New-password-2"); err != nil || !ok {
+		t.Fatalf("expected new password to verify, ok=%v err=%v", ok, err)
+	}
+}
+
+func TestChangePassword_RejectsMismatchedConfirmation(t *testing.T) {
+	// 新しいパスワードと確認入力が一致しない場合に書き込まないことを確認する。
+	dir := t.TempDir()
+	exePath := filepath.Join(dir, "ratta.exe")
+	if err := Run(exePath, false, &stubPrompter{values: []string{"Old-password-1", "Old-password-1"}}); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	previousWrite := writeFile
+	writeFile = func(string, []byte) error {
+		t.Fatal("unexpected write")
+		return nil
+	}
+	t.Cleanup(func() { writeFile = previousWrite })
+
+	if err := ChangePassword(exePath, &stubPrompter{values: []string{"Old-password-1", "New-password-2", "Other-password-3"}}); err == nil {
+		t.Fatal("expected confirmation mismatch error")
+	}
+}
+
+func TestRunWithTOTP_EmbedsSecretKeptAcrossPasswordChange(t *testing.T) {
+	// TOTP の秘密鍵を暗号化して埋め込み、パスワードを変更しても同じ秘密鍵を新しいパスワードで取り出せることを確認する。
+	dir := t.TempDir()
+	exePath := filepath.Join(dir, "ratta.exe")
+	kdf := crypto.KDFParams{Name: crypto.KDFArgon2id, Iterations: 2}
+	enrollment, err := RunWithTOTP(exePath, false, kdf, &stubPrompter{values: []string{"Old-password-1", "Old-password-1"}})
+	if err != nil {
+		t.Fatalf("RunWithTOTP error: %v", err)
+	}
+	if enrollment.Secret == "" || !strings.Contains(enrollment.URI, "secret="+enrollment.Secret) {
+		t.Fatalf("unexpected enrollment: %+v", enrollment)
+	}
+	openSecret := func(password string) string {
+		t.Helper()
+		auth := readAuth(t, exePath)
+		key, deriveErr := crypto.DeriveUnlockKey(auth, password)
+		if deriveErr != nil {
+			t.Fatalf("DeriveUnlockKey error: %v", deriveErr)
+		}
+		secret, openErr := crypto.OpenTOTPSecret(auth, key)
+		if openErr != nil {
+			t.Fatalf("OpenTOTPSecret error: %v", openErr)
+		}
+		return crypto.EncodeTOTPSecret(secret)
+	}
+	if got := openSecret("Old-password-1"); got != enrollment.Secret {
+		t.Fatalf("expected embedded secret %s, got %s", enrollment.Secret, got)
+	}
+
+	if err := ChangePassword(exePath, &stubPrompter{values: []string{"Old-password-1", "New-password-2", "New-password-2"}}); err != nil {
+		t.Fatalf("ChangePassword error: %v", err)
+	}
+	if got := openSecret("New-password-2"); got != enrollment.Secret {
+		t.Fatalf("expected secret to be kept, got %s", got)
+	}
+}
+
+func readAuth(t *testing.T, exePath string) crypto.ContractorAuth {
+	t.Helper()
+	// #nosec G304 -- テスト用ディレクトリ配下の固定パスを読むため安全。
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(exePath), "auth", "contractor.json"))
+	if err != nil {
+		t.Fatalf("read contractor.json: %v", err)
+	}
+	var auth crypto.ContractorAuth
+	if err := json.Unmarshal(data, &auth); err != nil {
+		t.Fatalf("parse contractor.json: %v", err)
+	}
+	return auth
+}
@@ -0,0 +1,98 @@
+// policy.go は init contractor・passwd で設定する Contractor パスワードの強度の検査を担い、
+// 規則の保存や読み取った設定の検証は扱わない。規則は実行ファイル隣の config.json の auth.password_policy から読む。
+package contractorinit
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"ratta/internal/infra/configrepo"
+)
+
+// commonPasswordList は DD-CLI-009 のよく使われるパスワードの一覧を表す。
+//
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// commonPasswords は DD-CLI-009 のよく使われるパスワードを小文字で引けるようにした集合を表す。
+var commonPasswords = parseCommonPasswords(commonPasswordList)
+
+var loadConfig = func(exePath string) (configrepo.Config, error) {
+	cfg, _, err := configrepo.NewRepository(exePath).Load()
+	return cfg, err
+}
+
+// loadPasswordPolicy は DD-CLI-009 の実行ファイル隣の config.json からパスワードの強度の規則を読む。
+// config.json が無い場合は既定の規則を返す。
+func loadPasswordPolicy(exePath string) (configrepo.PasswordPolicy, error) {
+	cfg, err := loadConfig(exePath)
+	if err != nil {
+		return configrepo.PasswordPolicy{}, fmt.Errorf("load password policy: %w", err)
+	}
+	return cfg.Auth.ContractorPasswordPolicy(), nil
+}
+
+// checkPasswordStrength は DD-CLI-009 に従い、新しいパスワードが強度の規則を満たすかを検査する。
+// 目的: 推測されやすいパスワードで Contractor 認証情報を生成しないようにする。
+// 入力: password は新しいパスワード、policy は既定値を補った規則。
+// 出力: 規則を満たす場合は nil、満たさない場合は満たしていない規則を示すエラー。
+// エラー: 文字数の不足、文字種の不足、よく使われるパスワードとの一致の順に最初に見つかったものを返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 文字数は UTF-8 の文字単位で数える。よく使われるパスワードとの照合は大文字小文字を区別しない。
+// 関連DD: DD-CLI-009
+func checkPasswordStrength(password string, policy configrepo.PasswordPolicy) error {
+	if length := utf8.RuneCountInString(password); length < policy.MinLength {
+		return fmt.Errorf("password is too short: %d characters, at least %d required", length, policy.MinLength)
+	}
+	if classes := countCharClasses(password); classes < policy.MinCharClasses {
+		return fmt.Errorf("password is too simple: uses %d of lowercase, uppercase, digits and symbols, at least %d required",
+			classes, policy.MinCharClasses)
+	}
+	if !policy.AllowCommon {
+		if _, found := commonPasswords[strings.ToLower(password)]; found {
+			return fmt.Errorf("password is too common: choose one that is not in the list of commonly used passwords")
+		}
+	}
+	return nil
+}
+
+// countCharClasses は DD-CLI-009 のパスワードに含まれる文字種 (英小文字・英大文字・数字・記号など) の数を返す。
+func countCharClasses(password string) int {
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+	count := 0
+	for _, present := range []bool{lower, upper, digit, other} {
+		if present {
+			count++
+		}
+	}
+	return count
+}
+
+// parseCommonPasswords は DD-CLI-009 の一覧から空行と注釈を除いて集合にする。
+func parseCommonPasswords(list string) map[string]struct{} {
+	passwords := map[string]struct{}{}
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords[strings.ToLower(line)] = struct{}{}
+	}
+	return passwords
+}
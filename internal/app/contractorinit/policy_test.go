@@ -0,0 +1,82 @@
+// policy_test.go は Contractor パスワードの強度の検査のテストを行い、config.json の読み書き自体は扱わない。
+package contractorinit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ratta/internal/infra/configrepo"
+)
+
+func TestCheckPasswordStrength_DefaultPolicy(t *testing.T) {
+	// 既定の規則では短い・文字種の少ない・よく使われるパスワードを理由付きで拒否することを確認する。
+	policy := configrepo.Auth{}.ContractorPasswordPolicy()
+	cases := map[string]string{
+		"Sh0rt-pw":              "too short",
+		"alllowercaseletters":   "too simple",
+		"Password1234":          "too common",
+		"P@ssw0rd123":           "too short",
+		"correct-horse-battery": "",
+		"パスワードは十二文字以上です1": "",
+	}
+	for password, expected := range cases {
+		err := checkPasswordStrength(password, policy)
+		if expected == "" {
+			if err != nil {
+				t.Fatalf("%q: expected accepted, got %v", password, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("%q: expected %q, got %v", password, expected, err)
+		}
+	}
+}
+
+func TestCheckPasswordStrength_ConfiguredPolicy(t *testing.T) {
+	// 設定した規則で文字数・文字種の下限を変え、よく使われるパスワードの拒否を外せることを確認する。
+	policy := configrepo.Auth{PasswordPolicy: &configrepo.PasswordPolicy{MinLength: 6, MinCharClasses: 3, AllowCommon: true}}.ContractorPasswordPolicy()
+	if err := checkPasswordStrength("Secret1", policy); err != nil {
+		t.Fatalf("expected accepted, got %v", err)
+	}
+	if err := checkPasswordStrength("secret12", policy); err == nil || !strings.Contains(err.Error(), "at least 3") {
+		t.Fatalf("expected character class failure, got %v", err)
+	}
+}
+
+func TestRun_RejectsWeakPasswordFromConfig(t *testing.T) {
+	// 実行ファイル隣の config.json の規則を満たさないパスワードでは contractor.json を作成しないことを確認する。
+	dir := t.TempDir()
+	exePath := filepath.Join(dir, "ratta.exe")
+	config := `{"format_version":1,"last_project_root_path":"","log":{"level":"info"},"ui":{"page_size":20},` +
+		`"auth":{"contractor_idle_timeout_minutes":0,"password_policy":{"min_length":20,"min_char_classes":0,"allow_common":false}}}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	err := Run(exePath, false, &stubPrompter{values: []string{"Contractor-pass-1", "Contractor-pass-1"}})
+	if err == nil || !strings.Contains(err.Error(), "at least 20") {
+		t.Fatalf("expected length failure, got %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(dir, "auth", "contractor.json")); !errors.Is(statErr, os.ErrNotExist) {
+		t.Fatalf("expected no contractor.json, got %v", statErr)
+	}
+}
+
+func TestRun_ConfigErrorBeforePrompt(t *testing.T) {
+	// config.json を読めない場合はパスワードの入力を求めずに失敗することを確認する。
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte("{broken"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	prompter := &stubPrompter{values: []string{"Contractor-pass-1", "Contractor-pass-1"}}
+	if err := Run(filepath.Join(dir, "ratta.exe"), false, prompter); err == nil || !strings.Contains(err.Error(), "password policy") {
+		t.Fatalf("expected policy load failure, got %v", err)
+	}
+	if prompter.index != 0 {
+		t.Fatalf("expected no prompt, got %d inputs read", prompter.index)
+	}
+}
@@ -0,0 +1,231 @@
+// users.go は users.json の Contractor アカウントの追加とパスワード変更のユースケースを担い、ログイン時の照合は扱わない。
+// 照合は modedetect が担う。
+package contractorinit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/infra/configrepo"
+	"ratta/internal/infra/crypto"
+	"ratta/internal/infra/jsonfmt"
+)
+
+var marshalUsers = jsonfmt.MarshalUsers
+
+// AddUser は DD-CLI-007 に従い users.json へ Contractor アカウントを追加する。
+// 目的: 共有パスワードの contractor.json に代えて、担当者ごとの名前付きアカウントで Contractor 認証できるようにする。
+// 入力: exePath は実行ファイルのパス、username はユーザー名、force は同名アカウントの置き換え許可、
+// kdf は鍵導出設定、withTOTP は DD-CLI-008 の TOTP の有効化、prompter は入力手段。
+// 出力: withTOTP の場合は認証アプリへの登録情報、失敗時はエラー。
+// エラー: ユーザー名の不備、未対応の鍵導出設定、同名アカウントの衝突、強度の規則を満たさないパスワード、
+// contractor.json か他のアカウントに TOTP があるのに withTOTP でない場合、users.json の読み取り・暗号化・保存の失敗時に返す。
+// 副作用: auth ディレクトリを作成し、users.json を原子的に書き換える。
+// 並行性: 同一パスへの同時実行は想定しない。
+// 不変条件: 他のアカウントは変更しない。ユーザー名と鍵導出設定はパスワード入力前に検証する。
+// users.json があると contractor.json は照合に使われないため、TOTP を設定済みの環境では TOTP の無いアカウントを追加せず、
+// 二要素認証が外れることを防ぐ。
+// 関連DD: DD-CLI-007, DD-CLI-005, DD-CLI-008, DD-CLI-009, DD-PERSIST-002
+func AddUser(exePath, username string, force, withTOTP bool, kdf crypto.KDFParams, prompter Prompter) (TOTPEnrollment, error) {
+	if prompter == nil {
+		return TOTPEnrollment{}, errors.New("prompter is required")
+	}
+	if err := crypto.ValidateUsername(username); err != nil {
+		return TOTPEnrollment{}, err
+	}
+	kdf, err := crypto.ResolveKDFParams(kdf.Name, kdf.Iterations)
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+	authDir := filepath.Join(filepath.Dir(exePath), "auth")
+	targetPath := filepath.Join(authDir, "users.json")
+	store, _, err := loadUsers(targetPath)
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+	if _, found := store.Find(username); found && !force {
+		return TOTPEnrollment{}, fmt.Errorf("user %q already exists (use --force to replace)", username)
+	}
+	if !withTOTP {
+		required, requiredErr := totpConfigured(filepath.Join(authDir, "contractor.json"), store)
+		if requiredErr != nil {
+			return TOTPEnrollment{}, requiredErr
+		}
+		if required {
+			return TOTPEnrollment{}, errors.New("a one-time code is already required; add the account with --totp to keep two-factor authentication")
+		}
+	}
+	policy, err := loadPasswordPolicy(exePath)
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+
+	password, err := promptNewPassword(prompter, "Password: ", policy)
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+	if mkdirErr := mkdirAll(authDir, 0o750); mkdirErr != nil {
+		return TOTPEnrollment{}, fmt.Errorf("create auth dir: %w", mkdirErr)
+	}
+	auth, err := generateAuth(password, kdf)
+	if err != nil {
+		return TOTPEnrollment{}, fmt.Errorf("generate contractor auth: %w", err)
+	}
+	enrollment := TOTPEnrollment{}
+	if withTOTP {
+		secret, secretErr := crypto.GenerateTOTPSecret()
+		if secretErr != nil {
+			return TOTPEnrollment{}, fmt.Errorf("generate totp secret: %w", secretErr)
+		}
+		if auth, err = crypto.AttachTOTPSecret(auth, password, secret); err != nil {
+			return TOTPEnrollment{}, fmt.Errorf("attach totp secret: %w", err)
+		}
+		enrollment = TOTPEnrollment{
+			Secret: crypto.EncodeTOTPSecret(secret),
+			URI:    crypto.TOTPKeyURI(totpIssuer, username, secret),
+		}
+	}
+	if saveErr := saveUsers(targetPath, store.Put(crypto.NewUserAccount(username, auth))); saveErr != nil {
+		return TOTPEnrollment{}, saveErr
+	}
+	return enrollment, nil
+}
+
+// totpConfigured は DD-CLI-008 の TOTP が contractor.json か users.json のいずれかのアカウントに設定済みかを返す。
+func totpConfigured(contractorPath string, store crypto.UserStore) (bool, error) {
+	if store.HasTOTP() {
+		return true, nil
+	}
+	data, err := readFile(contractorPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read contractor auth: %w", err)
+	}
+	var auth crypto.ContractorAuth
+	if unmarshalErr := json.Unmarshal(data, &auth); unmarshalErr != nil {
+		return false, fmt.Errorf("parse contractor auth: %w", unmarshalErr)
+	}
+	return auth.HasTOTP(), nil
+}
+
+// ChangeUserPassword は DD-CLI-007 に従い users.json のアカウントのパスワードを変更する。
+// 目的: 現在のパスワードを確認したうえで、指定したアカウントの認証情報のみを置き換える。
+// 入力: exePath は実行ファイルのパス、username はユーザー名、prompter は入力手段。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: users.json やアカウントが無い場合、現在のパスワードが一致しない場合、
+// 新しいパスワードが空・確認と不一致・強度の規則を満たさない場合、暗号化や保存に失敗した場合に返す。
+// 副作用: 対象アカウントの salt・nonce・暗号文を再生成し、users.json を原子的に置き換える。
+// DD-CLI-008 の TOTP がある場合は同じ秘密鍵を新しいパスワードからの導出鍵で暗号化し直す (認証アプリの再登録は不要)。
+// 並行性: 同一パスへの同時実行は想定しない。
+// 不変条件: 現在のパスワードを確認できない場合はファイルを変更しない。鍵導出設定は ChangePassword と同じ規則で引き継ぐ。
+// 関連DD: DD-CLI-007, DD-CLI-005, DD-CLI-008, DD-CLI-009, DD-PERSIST-002
+func ChangeUserPassword(exePath, username string, prompter Prompter) error {
+	if prompter == nil {
+		return errors.New("prompter is required")
+	}
+	targetPath := filepath.Join(filepath.Dir(exePath), "auth", "users.json")
+	store, exists, err := loadUsers(targetPath)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return apperr.New(apperr.ErrNotFound, "users.json not found (run init contractor --user first)")
+	}
+	account, found := store.Find(username)
+	if !found {
+		return apperr.Errorf(apperr.ErrNotFound, "user not found: %s", username)
+	}
+	policy, err := loadPasswordPolicy(exePath)
+	if err != nil {
+		return err
+	}
+
+	password, err := prompter.PromptHidden("Current password: ")
+	if err != nil {
+		return fmt.Errorf("prompt current password: %w", err)
+	}
+	current := account.Auth()
+	key, verifyErr := crypto.DeriveUnlockKey(current, password)
+	if verifyErr != nil {
+		if errors.Is(verifyErr, crypto.ErrPasswordMismatch) {
+			return apperr.New(apperr.ErrCrypto, "current password verification failed")
+		}
+		return fmt.Errorf("verify current password: %w", verifyErr)
+	}
+
+	newPassword, err := promptNewPassword(prompter, "New password: ", policy)
+	if err != nil {
+		return err
+	}
+	params := crypto.UpgradeKDFParams(crypto.KDFParams{Name: account.KDF, Iterations: account.KDFIterations})
+	auth, err := generateAuth(newPassword, params)
+	if err != nil {
+		return fmt.Errorf("generate contractor auth: %w", err)
+	}
+	if current.HasTOTP() {
+		secret, openErr := crypto.OpenTOTPSecret(current, key)
+		if openErr != nil {
+			return fmt.Errorf("open totp secret: %w", openErr)
+		}
+		if auth, err = crypto.AttachTOTPSecret(auth, newPassword, secret); err != nil {
+			return fmt.Errorf("attach totp secret: %w", err)
+		}
+	}
+	return saveUsers(targetPath, store.Put(crypto.NewUserAccount(username, auth)))
+}
+
+// promptNewPassword は DD-CLI-003 の新しいパスワードを確認入力付きで受け取り、DD-CLI-009 の強度の規則で検査する。
+func promptNewPassword(prompter Prompter, label string, policy configrepo.PasswordPolicy) (string, error) {
+	password, err := prompter.PromptHidden(label)
+	if err != nil {
+		return "", fmt.Errorf("prompt password: %w", err)
+	}
+	confirm, err := prompter.PromptHidden("Confirm: ")
+	if err != nil {
+		return "", fmt.Errorf("prompt confirm: %w", err)
+	}
+	if password == "" {
+		return "", errors.New("password is required")
+	}
+	if password != confirm {
+		return "", errors.New("password confirmation does not match")
+	}
+	if err := checkPasswordStrength(password, policy); err != nil {
+		return "", err
+	}
+	return password, nil
+}
+
+// loadUsers は DD-CLI-007 の users.json を読み込む。ファイルが無い場合はアカウントの無い内容と exists=false を返す。
+func loadUsers(path string) (crypto.UserStore, bool, error) {
+	data, err := readFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return crypto.NewUserStore(), false, nil
+	}
+	if err != nil {
+		return crypto.UserStore{}, false, fmt.Errorf("read users: %w", err)
+	}
+	var store crypto.UserStore
+	if unmarshalErr := json.Unmarshal(data, &store); unmarshalErr != nil {
+		return crypto.UserStore{}, false, fmt.Errorf("parse users: %w", unmarshalErr)
+	}
+	return store, true, nil
+}
+
+// saveUsers は DD-CLI-007/DD-PERSIST-002 の users.json を正規のキー順で原子的に書き込む。
+func saveUsers(path string, store crypto.UserStore) error {
+	data, err := marshalUsers(store)
+	if err != nil {
+		return fmt.Errorf("marshal users: %w", err)
+	}
+	if writeErr := writeFile(path, data); writeErr != nil {
+		return fmt.Errorf("write users: %w", writeErr)
+	}
+	return nil
+}
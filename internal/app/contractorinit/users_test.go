@@ -0,0 +1,172 @@
+// users_test.go は users.json のアカウント追加とパスワード変更のテストを行い、UI統合は扱わない。
+package contractorinit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ratta/internal/infra/crypto"
+)
+
+// fastKDF はテスト時間を抑えるための軽い鍵導出設定を表す。
+var fastKDF = crypto.KDFParams{Name: crypto.KDFArgon2id, Iterations: 2}
+
+func TestAddUser_AddsAccountsAndKeepsOthers(t *testing.T) {
+	// アカウントを追加しても他のアカウントを変更せず、同名は --force なしで拒否することを確認する。
+	exePath := filepath.Join(t.TempDir(), "ratta.exe")
+	if _, err := AddUser(exePath, "alice", false, false, fastKDF, &stubPrompter{values: []string{"Alice-password-1", "Alice-password-1"}}); err != nil {
+		t.Fatalf("AddUser alice error: %v", err)
+	}
+	if _, err := AddUser(exePath, "bob", false, false, crypto.KDFParams{}, &stubPrompter{values: []string{"Bob-password-2", "Bob-password-2"}}); err != nil {
+		t.Fatalf("AddUser bob error: %v", err)
+	}
+	store := readUsers(t, exePath)
+	if len(store.Users) != 2 || store.Users[0].Username != "alice" || store.Users[1].KDF != crypto.KDFPBKDF2SHA256 {
+		t.Fatalf("unexpected users: %+v", store.Users)
+	}
+	if ok, err := crypto.VerifyUserPassword(store, "alice", "Alice-password-1"); err != nil || !ok {
+		t.Fatalf("expected alice to verify, ok=%v err=%v", ok, err)
+	}
+
+	_, err := AddUser(exePath, "alice", false, false, fastKDF, &stubPrompter{values: []string{"x", "x"}})
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected duplicate error, got: %v", err)
+	}
+	if _, err := AddUser(exePath, "alice", true, false, fastKDF, &stubPrompter{values: []string{"Alice-password-9", "Alice-password-9"}}); err != nil {
+		t.Fatalf("AddUser --force error: %v", err)
+	}
+	store = readUsers(t, exePath)
+	if ok, verifyErr := crypto.VerifyUserPassword(store, "alice", "Alice-password-9"); verifyErr != nil || !ok {
+		t.Fatalf("expected replaced password to verify, ok=%v err=%v", ok, verifyErr)
+	}
+	if ok, verifyErr := crypto.VerifyUserPassword(store, "bob", "Bob-password-2"); verifyErr != nil || !ok {
+		t.Fatalf("expected bob to be kept, ok=%v err=%v", ok, verifyErr)
+	}
+}
+
+func TestAddUser_RejectsInvalidUsernameBeforePrompt(t *testing.T) {
+	// ユーザー名が不正な場合はパスワード入力前に失敗することを確認する。
+	exePath := filepath.Join(t.TempDir(), "ratta.exe")
+	prompter := &stubPrompter{values: []string{"pw", "pw"}}
+	if _, err := AddUser(exePath, " alice", false, false, fastKDF, prompter); err == nil {
+		t.Fatal("expected invalid username error")
+	}
+	if prompter.index != 0 {
+		t.Fatalf("expected no prompt, got %d", prompter.index)
+	}
+}
+
+func TestAddUser_RequiresTOTPWhenAlreadyConfigured(t *testing.T) {
+	// contractor.json に TOTP がある場合は --totp なしの追加をパスワード入力前に拒否することを確認する。
+	exePath := filepath.Join(t.TempDir(), "ratta.exe")
+	if _, err := RunWithTOTP(exePath, false, fastKDF, &stubPrompter{values: []string{"Shared-password-1", "Shared-password-1"}}); err != nil {
+		t.Fatalf("RunWithTOTP error: %v", err)
+	}
+	prompter := &stubPrompter{values: []string{"Alice-password-1", "Alice-password-1"}}
+	_, err := AddUser(exePath, "alice", false, false, fastKDF, prompter)
+	if err == nil || !strings.Contains(err.Error(), "--totp") {
+		t.Fatalf("expected --totp required error, got: %v", err)
+	}
+	if prompter.index != 0 {
+		t.Fatalf("expected no prompt, got %d", prompter.index)
+	}
+	if _, statErr := os.Stat(filepath.Join(filepath.Dir(exePath), "auth", "users.json")); !os.IsNotExist(statErr) {
+		t.Fatalf("expected users.json not to be written, got: %v", statErr)
+	}
+}
+
+func TestAddUser_WithTOTPKeepsSecretAcrossPasswordChange(t *testing.T) {
+	// --totp で追加したアカウントに TOTP の秘密鍵を埋め込み、パスワードを変更しても同じ秘密鍵を取り出せることを確認する。
+	exePath := filepath.Join(t.TempDir(), "ratta.exe")
+	enrollment, err := AddUser(exePath, "alice", false, true, fastKDF, &stubPrompter{values: []string{"Alice-password-1", "Alice-password-1"}})
+	if err != nil {
+		t.Fatalf("AddUser error: %v", err)
+	}
+	if enrollment.Secret == "" || !strings.Contains(enrollment.URI, "alice") {
+		t.Fatalf("unexpected enrollment: %+v", enrollment)
+	}
+	openSecret := func(password string) string {
+		t.Helper()
+		account, found := readUsers(t, exePath).Find("alice")
+		if !found {
+			t.Fatal("expected alice to exist")
+		}
+		key, deriveErr := crypto.DeriveUnlockKey(account.Auth(), password)
+		if deriveErr != nil {
+			t.Fatalf("DeriveUnlockKey error: %v", deriveErr)
+		}
+		secret, openErr := crypto.OpenTOTPSecret(account.Auth(), key)
+		if openErr != nil {
+			t.Fatalf("OpenTOTPSecret error: %v", openErr)
+		}
+		return crypto.EncodeTOTPSecret(secret)
+	}
+	if got := openSecret("Alice-password-1"); got != enrollment.Secret {
+		t.Fatalf("expected embedded secret %s, got %s", enrollment.Secret, got)
+	}
+
+	if err := ChangeUserPassword(exePath, "alice", &stubPrompter{values: []string{"Alice-password-1", "New-password-2", "New-password-2"}}); err != nil {
+		t.Fatalf("ChangeUserPassword error: %v", err)
+	}
+	if got := openSecret("New-password-2"); got != enrollment.Secret {
+		t.Fatalf("expected secret to be kept, got %s", got)
+	}
+	if _, err := AddUser(exePath, "bob", false, false, fastKDF, &stubPrompter{values: []string{"Bob-password-2", "Bob-password-2"}}); err == nil {
+		t.Fatal("expected bob without --totp to be refused")
+	}
+}
+
+func TestChangeUserPassword_ChangesOnlyTargetAccount(t *testing.T) {
+	// 現在のパスワードを確認したうえで対象アカウントのみを変更し、不一致の場合は変更しないことを確認する。
+	exePath := filepath.Join(t.TempDir(), "ratta.exe")
+	for _, name := range []string{"alice", "bob"} {
+		if _, err := AddUser(exePath, name, false, false, fastKDF, &stubPrompter{values: []string{name + "-Password-1", name + "-Password-1"}}); err != nil {
+			t.Fatalf("AddUser error: %v", err)
+		}
+	}
+	before := readUsers(t, exePath)
+
+	err := ChangeUserPassword(exePath, "alice", &stubPrompter{values: []string{"wrong", "n", "n"}})
+	if err == nil || !strings.Contains(err.Error(), "verification failed") {
+		t.Fatalf("expected verification failure, got: %v", err)
+	}
+	if err := ChangeUserPassword(exePath, "alice", &stubPrompter{values: []string{"alice-Password-1", "New-password-2", "New-password-2"}}); err != nil {
+		t.Fatalf("ChangeUserPassword error: %v", err)
+	}
+	after := readUsers(t, exePath)
+	if ok, verifyErr := crypto.VerifyUserPassword(after, "alice", "New-password-2"); verifyErr != nil || !ok {
+		t.Fatalf("expected new password to verify, ok=%v err=%v", ok, verifyErr)
+	}
+	if after.Users[1] != before.Users[1] {
+		t.Fatalf("expected bob to be unchanged: %+v", after.Users[1])
+	}
+	if err := ChangeUserPassword(exePath, "carol", &stubPrompter{}); err == nil || !strings.Contains(err.Error(), "user not found") {
+		t.Fatalf("expected user not found, got: %v", err)
+	}
+}
+
+func TestChangeUserPassword_RequiresUsersFile(t *testing.T) {
+	// users.json が無い場合は作成を促すエラーを返すことを確認する。
+	exePath := filepath.Join(t.TempDir(), "ratta.exe")
+	err := ChangeUserPassword(exePath, "alice", &stubPrompter{})
+	if err == nil || !strings.Contains(err.Error(), "init contractor --user") {
+		t.Fatalf("expected missing users.json error, got: %v", err)
+	}
+}
+
+func readUsers(t *testing.T, exePath string) crypto.UserStore {
+	t.Helper()
+	// #nosec G304 -- テスト用ディレクトリ配下の固定パスを読むため安全。
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(exePath), "auth", "users.json"))
+	if err != nil {
+		t.Fatalf("read users.json: %v", err)
+	}
+	var store crypto.UserStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		t.Fatalf("parse users.json: %v", err)
+	}
+	return store
+}
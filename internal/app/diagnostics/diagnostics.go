@@ -0,0 +1,351 @@
+// Package diagnostics は不具合報告に添付する診断情報 zip の出力を担い、保存先の選択や UI 表示は扱わない。
+// 秘密情報を含む contractor.json・users.json は出力せず、config.json も秘密情報らしき値を伏せてから含める。
+package diagnostics
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"ratta/internal/app/categoryscan"
+	"ratta/internal/app/issuescan"
+	"ratta/internal/domain/timeutil"
+	"ratta/internal/infra/atomicwrite"
+)
+
+const (
+	// formatVersion は DD-DIAG-001 の manifest 形式バージョンを表す。
+	formatVersion = 1
+	// kind は DD-DIAG-001 の診断情報 zip の識別子を表す。
+	kind = "ratta-diagnostics"
+	// redacted は DD-DIAG-001 の伏せた値の表記を表す。
+	redacted = "[REDACTED]"
+	// maxLogGenerations は DD-DIAG-001 の含めるログの世代数を表す。logging のローテーション世代数と合わせる。
+	maxLogGenerations = 3
+)
+
+// secretKeyMarkers は DD-DIAG-001 の値を伏せるキー名の部分文字列を表す。
+// 現在の config.json に秘密情報はないが、将来の設定追加で漏れないよう名前で判定する。
+var secretKeyMarkers = []string{"password", "secret", "token", "salt", "hash", "credential"}
+
+// now は DD-DIAG-001 の作成時刻をテストで固定するための差し替え点。
+var now = time.Now
+
+// Input は DD-DIAG-001 の診断情報の収集元を表す。ProjectRoot が空の場合はプロジェクトの集計を含めない。
+// ConfigPath が空の場合は実行ファイルと同じディレクトリの config.json を含める。
+type Input struct {
+	ExePath     string
+	ConfigPath  string
+	SchemaDir   string
+	ProjectRoot string
+	AppVersion  string
+	Scanner     *issuescan.Scanner
+}
+
+// Manifest は DD-DIAG-001 の診断情報の概要を表す。
+type Manifest struct {
+	FormatVersion int             `json:"format_version"`
+	Kind          string          `json:"kind"`
+	CreatedAt     string          `json:"created_at"`
+	AppVersion    string          `json:"app_version"`
+	GoVersion     string          `json:"go_version"`
+	OS            string          `json:"os"`
+	Arch          string          `json:"arch"`
+	Schemas       []SchemaInfo    `json:"schemas"`
+	Project       *ProjectSummary `json:"project"`
+	Files         []string        `json:"files"`
+}
+
+// SchemaInfo は DD-DIAG-001 の同梱スキーマの識別情報を表す。
+type SchemaInfo struct {
+	File   string `json:"file"`
+	ID     string `json:"id"`
+	SHA256 string `json:"sha256"`
+}
+
+// ProjectSummary は DD-DIAG-001 のプロジェクトの整合性の集計を表す。課題の内容は含めない。
+type ProjectSummary struct {
+	Root               string      `json:"root"`
+	Categories         int         `json:"categories"`
+	ReadOnlyCategories int         `json:"read_only_categories"`
+	CategoryErrors     int         `json:"category_errors"`
+	Issues             int         `json:"issues"`
+	SchemaInvalid      int         `json:"schema_invalid"`
+	LoadErrors         []LoadError `json:"load_errors"`
+	ScanError          string      `json:"scan_error,omitempty"`
+}
+
+// LoadError は DD-DIAG-001 の読み込めなかった課題ファイルを表す。Path はプロジェクトルートからの相対パスとする。
+type LoadError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Result は DD-DIAG-001 の出力結果を表す。
+type Result struct {
+	Path      string
+	FileCount int
+}
+
+// entry は DD-DIAG-001 の zip エントリ1件を表す。
+type entry struct {
+	name string
+	data []byte
+}
+
+// Export は DD-DIAG-001 の診断情報 zip の出力を行う。
+// 目的: 現地からの不具合報告に、ログ・設定・スキーマ・プロジェクトの整合性をまとめて添付できるようにする。
+// 入力: ctx は中断通知、destPath は出力先、input は収集元。
+// 出力: Result とエラー。
+// エラー: 出力先未指定、ログ・設定の読み取り失敗、中断、書き込み失敗時に返す。
+// プロジェクトの走査失敗は報告の妨げにしないよう manifest に記録して続行する。
+// 副作用: destPath へ zip を atomic write で書き込む。
+// 並行性: 読み取りのみのため、他の操作と並行に実行できる。
+// 不変条件: contractor.json・users.json は含めない。config.json の秘密情報らしき値は伏せる。
+// 関連DD: DD-DIAG-001, DD-LOG-001, DD-DATA-001
+func Export(ctx context.Context, destPath string, input Input) (Result, error) {
+	if destPath == "" {
+		return Result{}, errors.New("destination path is required")
+	}
+	exeDir := filepath.Dir(input.ExePath)
+	var entries []entry
+
+	configPath := input.ConfigPath
+	if configPath == "" {
+		configPath = filepath.Join(exeDir, "config.json")
+	}
+	configData, err := readRedactedConfig(configPath)
+	if err != nil {
+		return Result{}, err
+	}
+	if configData != nil {
+		entries = append(entries, entry{name: "config.json", data: configData})
+	}
+
+	logEntries, err := readLogs(filepath.Join(exeDir, "logs", "ratta.log"))
+	if err != nil {
+		return Result{}, err
+	}
+	entries = append(entries, logEntries...)
+
+	manifest := Manifest{
+		FormatVersion: formatVersion,
+		Kind:          kind,
+		CreatedAt:     timeutil.FormatISO8601(now()),
+		AppVersion:    input.AppVersion,
+		GoVersion:     runtime.Version(),
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		Schemas:       readSchemas(input.SchemaDir),
+		Files:         make([]string, 0, len(entries)),
+	}
+	if input.ProjectRoot != "" {
+		summary, summaryErr := summarizeProject(ctx, input.ProjectRoot, input.Scanner)
+		if summaryErr != nil {
+			return Result{}, summaryErr
+		}
+		manifest.Project = &summary
+	}
+	for _, item := range entries {
+		manifest.Files = append(manifest.Files, item.name)
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return Result{}, fmt.Errorf("marshal manifest: %w", err)
+	}
+	entries = append([]entry{{name: "manifest.json", data: append(manifestData, '\n')}}, entries...)
+
+	archive, err := buildZip(entries)
+	if err != nil {
+		return Result{}, err
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return Result{}, ctxErr
+	}
+	if writeErr := atomicwrite.WriteFile(destPath, archive); writeErr != nil {
+		return Result{}, fmt.Errorf("write diagnostics: %w", writeErr)
+	}
+	return Result{Path: destPath, FileCount: len(entries)}, nil
+}
+
+// readRedactedConfig は DD-DIAG-001 の config.json を秘密情報を伏せて読み込む。存在しない場合は nil を返す。
+// 解析できない config.json は内容を判断できず伏せられないため、含めずに破損していることのみを残す。
+func readRedactedConfig(path string) ([]byte, error) {
+	// #nosec G304 -- 実行ファイルと同じディレクトリ、または起動引数で指定された config.json のみを読むため安全。
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	var value any
+	if unmarshalErr := json.Unmarshal(data, &value); unmarshalErr != nil {
+		return []byte(`{"error":"config.json could not be parsed"}` + "\n"), nil
+	}
+	output, err := json.MarshalIndent(redact(value), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	return append(output, '\n'), nil
+}
+
+// redact は DD-DIAG-001 の秘密情報らしきキーの値を再帰的に伏せる。
+func redact(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, child := range typed {
+			if isSecretKey(key) {
+				typed[key] = redacted
+				continue
+			}
+			typed[key] = redact(child)
+		}
+		return typed
+	case []any:
+		for i, child := range typed {
+			typed[i] = redact(child)
+		}
+		return typed
+	default:
+		return value
+	}
+}
+
+// isSecretKey は DD-DIAG-001 のキー名が秘密情報を表すかを判定する。
+func isSecretKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, marker := range secretKeyMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// readLogs は DD-DIAG-001 のログと世代ファイルを読み込む。存在しない世代は含めない。
+func readLogs(path string) ([]entry, error) {
+	var entries []entry
+	for generation := 0; generation <= maxLogGenerations; generation++ {
+		current := path
+		if generation > 0 {
+			current = fmt.Sprintf("%s.%d", path, generation)
+		}
+		// #nosec G304 -- 実行ファイル配下の logs ディレクトリのログのみを読むため安全。
+		data, err := os.ReadFile(current)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read log: %w", err)
+		}
+		entries = append(entries, entry{name: "logs/" + filepath.Base(current), data: data})
+	}
+	return entries, nil
+}
+
+// readSchemas は DD-DIAG-001 のスキーマの $id とハッシュを読み取る。読めないファイルは識別情報を空にして残す。
+func readSchemas(dir string) []SchemaInfo {
+	schemas := []SchemaInfo{}
+	if dir == "" {
+		return schemas
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return schemas
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		info := SchemaInfo{File: filepath.Base(path)}
+		// #nosec G304 -- 同梱スキーマディレクトリ配下のみを読むため安全。
+		if data, readErr := os.ReadFile(path); readErr == nil {
+			sum := sha256.Sum256(data)
+			info.SHA256 = hex.EncodeToString(sum[:])
+			var header struct {
+				ID string `json:"$id"`
+			}
+			if json.Unmarshal(data, &header) == nil {
+				info.ID = header.ID
+			}
+		}
+		schemas = append(schemas, info)
+	}
+	return schemas
+}
+
+// summarizeProject は DD-DIAG-001 のプロジェクトのカテゴリ・課題の件数と読み込めない課題を集計する。
+// 中断以外の走査失敗は ScanError に記録して返す。
+func summarizeProject(ctx context.Context, root string, scanner *issuescan.Scanner) (ProjectSummary, error) {
+	summary := ProjectSummary{Root: root, LoadErrors: []LoadError{}}
+	scanned, err := categoryscan.ScanContext(ctx, root)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ProjectSummary{}, ctxErr
+		}
+		summary.ScanError = err.Error()
+		return summary, nil
+	}
+	if scanner == nil {
+		scanner = issuescan.NewScanner(nil)
+	}
+	summary.Categories = len(scanned.Categories)
+	summary.CategoryErrors = scanned.ErrorCount
+	for _, category := range scanned.Categories {
+		if category.IsReadOnly {
+			summary.ReadOnlyCategories++
+		}
+		result, scanErr := scanner.ScanCategoryContext(ctx, category.Path, category.Name)
+		if scanErr != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ProjectSummary{}, ctxErr
+			}
+			summary.LoadErrors = append(summary.LoadErrors, LoadError{Path: category.Name, Message: scanErr.Error()})
+			continue
+		}
+		summary.Issues += len(result.Items)
+		for _, item := range result.Items {
+			if item.IsSchemaInvalid {
+				summary.SchemaInvalid++
+			}
+		}
+		for _, loadErr := range result.LoadErrors {
+			path := loadErr.Path
+			if rel, relErr := filepath.Rel(root, loadErr.Path); relErr == nil {
+				path = filepath.ToSlash(rel)
+			}
+			summary.LoadErrors = append(summary.LoadErrors, LoadError{Path: path, Message: loadErr.Message})
+		}
+	}
+	return summary, nil
+}
+
+// buildZip は DD-DIAG-001 のエントリ群から zip を生成する。
+func buildZip(entries []entry) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	modified := now()
+	for _, item := range entries {
+		fileWriter, err := writer.CreateHeader(&zip.FileHeader{Name: item.name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return nil, fmt.Errorf("create zip entry: %w", err)
+		}
+		if _, writeErr := fileWriter.Write(item.data); writeErr != nil {
+			return nil, fmt.Errorf("write zip entry: %w", writeErr)
+		}
+	}
+	if closeErr := writer.Close(); closeErr != nil {
+		return nil, fmt.Errorf("close zip: %w", closeErr)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,87 @@
+// diagnostics_test.go は診断情報 zip の内容と秘密情報の伏せ字のテストを行う。
+package diagnostics
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestFile はテスト用ファイルを親ディレクトリごと作成する。
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func TestExport_IncludesRedactedConfigLogsAndProjectSummary(t *testing.T) {
+	// 設定の秘密情報を伏せ、ログ・スキーマ・プロジェクト集計を含め、contractor.json は含めないことを確認する。
+	exeDir := t.TempDir()
+	writeTestFile(t, filepath.Join(exeDir, "config.json"), `{"log":{"level":"info"},"proxy":{"password":"p@ss"}}`)
+	writeTestFile(t, filepath.Join(exeDir, "contractor.json"), `{"password_hash":"x"}`)
+	writeTestFile(t, filepath.Join(exeDir, "logs", "ratta.log"), "current\n")
+	writeTestFile(t, filepath.Join(exeDir, "logs", "ratta.log.1"), "previous\n")
+	writeTestFile(t, filepath.Join(exeDir, "schemas", "issue.schema.json"), `{"$id":"issue.schema.json"}`)
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "cat", "broken.json"), "{")
+
+	dest := filepath.Join(t.TempDir(), "diag.zip")
+	result, err := Export(context.Background(), dest, Input{
+		ExePath:     filepath.Join(exeDir, "ratta.exe"),
+		SchemaDir:   filepath.Join(exeDir, "schemas"),
+		ProjectRoot: root,
+		AppVersion:  "1.2.3",
+	})
+	if err != nil {
+		t.Fatalf("Export error: %v", err)
+	}
+
+	reader, err := zip.OpenReader(dest)
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	defer func() { _ = reader.Close() }()
+	files := map[string]string{}
+	for _, file := range reader.File {
+		handle, openErr := file.Open()
+		if openErr != nil {
+			t.Fatalf("open entry: %v", openErr)
+		}
+		data, readErr := io.ReadAll(handle)
+		_ = handle.Close()
+		if readErr != nil {
+			t.Fatalf("read entry: %v", readErr)
+		}
+		files[file.Name] = string(data)
+	}
+	if result.FileCount != len(files) || len(files) != 4 {
+		t.Fatalf("unexpected files: %v", files)
+	}
+	if strings.Contains(files["config.json"], "p@ss") || !strings.Contains(files["config.json"], redacted) {
+		t.Fatalf("config must be redacted: %s", files["config.json"])
+	}
+	if files["logs/ratta.log.1"] != "previous\n" {
+		t.Fatalf("unexpected rotated log: %q", files["logs/ratta.log.1"])
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal([]byte(files["manifest.json"]), &manifest); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	if manifest.AppVersion != "1.2.3" || len(manifest.Schemas) != 1 || manifest.Schemas[0].ID != "issue.schema.json" {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+	if manifest.Project == nil || manifest.Project.Categories != 1 || len(manifest.Project.LoadErrors) != 1 ||
+		manifest.Project.LoadErrors[0].Path != "cat/broken.json" {
+		t.Fatalf("unexpected project summary: %+v", manifest.Project)
+	}
+}
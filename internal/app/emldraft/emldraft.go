@@ -0,0 +1,285 @@
+// Package emldraft は課題1件をメールクライアントで開ける下書き (.eml) として出力する処理を担い、課題の読み込みや送信は扱わない。
+package emldraft
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+	"ratta/internal/domain/timeutil"
+	"ratta/internal/infra/atomicwrite"
+)
+
+// base64LineLength は DD-EML-001 の base64 の1行の文字数を表す (RFC 2045 の上限 76 文字)。
+const base64LineLength = 76
+
+// now は DD-EML-001 の Date ヘッダに用いる現在時刻を返す。テストで差し替える。
+var now = time.Now
+
+// Options は DD-EML-001 の下書きの設定を表す。
+// To は RFC 5322 のアドレスの並び (カンマ区切り) とし、空の場合は宛先を入れずにメールクライアントで指定させる。
+type Options struct {
+	To string
+}
+
+// Result は DD-EML-001 の下書きの出力結果を表す。
+// Attachments は埋め込んだ添付の数、MissingAttachments は見つからず埋め込まなかった添付の数を表す。
+type Result struct {
+	Path               string
+	Attachments        int
+	MissingAttachments int
+}
+
+// attachmentPart は DD-EML-001 の埋め込む添付1件を表す。
+type attachmentPart struct {
+	name     string
+	mimeType string
+	data     []byte
+}
+
+// Write は DD-EML-001 の課題の下書きを出力する。
+// 目的: 課題の内容と添付を、取引先への連絡メールの下書きとしてメールクライアントでそのまま開けるようにする。
+// 入力: destPath は出力先、categoryDir は課題のカテゴリのディレクトリ (添付の基点)、item は課題、opts は下書きの設定。
+// 出力: Result とエラー。
+// エラー: 出力先が空の場合、宛先を解析できない場合、添付を読み込めない場合、書き込みに失敗した場合に返す。
+// 副作用: destPath へ .eml を書き込む。添付は読み取りのみ行う。
+// 並行性: 呼び出しごとに独立した下書きを作るためスレッドセーフ。課題の排他は呼び出し側で行う。
+// 不変条件: 件名は課題のタイトル、本文は課題の詳細の UTF-8 のテキストとする。X-Unsent を付け、メールクライアントでは未送信の下書きとして開く。
+// 見つからない添付やカテゴリの外を指す添付は埋め込まず、本文にその旨を記す。
+// 関連DD: DD-EML-001, DD-DATA-003, DD-DATA-004, DD-DATA-005
+func Write(destPath, categoryDir string, item issue.Issue, opts Options) (Result, error) {
+	if destPath == "" {
+		return Result{}, apperr.New(apperr.ErrValidation, "draft path is required")
+	}
+	to, err := parseRecipients(opts.To)
+	if err != nil {
+		return Result{}, err
+	}
+	var attachments []attachmentPart
+	missing := map[string]bool{}
+	for _, comment := range item.Comments {
+		for _, ref := range comment.Attachments {
+			part, ok, readErr := readAttachment(categoryDir, ref)
+			if readErr != nil {
+				return Result{}, readErr
+			}
+			if !ok {
+				missing[ref.RelativePath] = true
+				continue
+			}
+			attachments = append(attachments, part)
+		}
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "MIME-Version", "1.0")
+	writeHeader(&buf, "Date", now().Format(time.RFC1123Z))
+	if to != "" {
+		writeHeader(&buf, "To", to)
+	}
+	writeHeader(&buf, "Subject", encodeHeader(item.Title))
+	writeHeader(&buf, "X-Unsent", "1")
+	writer := multipart.NewWriter(&buf)
+	writeHeader(&buf, "Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": writer.Boundary()}))
+	buf.WriteString("\r\n")
+
+	if err := writeBody(writer, renderBody(item, missing)); err != nil {
+		return Result{}, err
+	}
+	for _, attachment := range attachments {
+		if err := writeAttachment(writer, attachment); err != nil {
+			return Result{}, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return Result{}, fmt.Errorf("close draft: %w", err)
+	}
+	if err := atomicwrite.WriteFile(destPath, buf.Bytes()); err != nil {
+		return Result{}, fmt.Errorf("write draft: %w", err)
+	}
+	return Result{Path: destPath, Attachments: len(attachments), MissingAttachments: len(missing)}, nil
+}
+
+// parseRecipients は DD-EML-001 の宛先を解析し、To ヘッダの値を返す。空の場合は空文字を返す。
+func parseRecipients(value string) (string, error) {
+	if strings.TrimSpace(value) == "" {
+		return "", nil
+	}
+	addresses, err := mail.ParseAddressList(value)
+	if err != nil {
+		return "", apperr.Errorf(apperr.ErrValidation, "invalid recipient address: %v", err)
+	}
+	formatted := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		formatted = append(formatted, address.String())
+	}
+	return strings.Join(formatted, ",\r\n "), nil
+}
+
+// readAttachment は DD-EML-001 の添付をカテゴリからの相対パスで読み込む。
+// 添付が見つからない場合や相対パスがカテゴリの外を指す場合は false を返す。
+func readAttachment(categoryDir string, ref issue.AttachmentRef) (attachmentPart, bool, error) {
+	relative := filepath.FromSlash(ref.RelativePath)
+	if ref.RelativePath == "" || !filepath.IsLocal(relative) {
+		return attachmentPart{}, false, nil
+	}
+	path := filepath.Join(categoryDir, relative)
+	// #nosec G304 -- カテゴリ配下を指すことを確認した相対パスのみを読む。
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return attachmentPart{}, false, nil
+	}
+	if err != nil {
+		return attachmentPart{}, false, apperr.WithPath(fmt.Errorf("read attachment: %w", err), path, "")
+	}
+	mimeType := ref.MimeType
+	if mimeType == "" {
+		mimeType = mime.TypeByExtension(filepath.Ext(ref.FileName))
+	}
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return attachmentPart{name: ref.FileName, mimeType: mimeType, data: data}, true, nil
+}
+
+// renderBody は DD-EML-001 の本文 (課題の項目・説明・コメント・添付の一覧) を返す。missing は埋め込めなかった添付の相対パスを表す。
+func renderBody(item issue.Issue, missing map[string]bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", item.Title)
+	for _, field := range [][2]string{
+		{"Category", item.Category},
+		{"Issue ID", item.IssueID},
+		{"Status", string(item.Status)},
+		{"Priority", string(item.Priority)},
+		{"Origin", string(item.OriginCompany)},
+		{"Assignee", item.Assignee},
+		{"Due date", item.DueDate},
+		{"Created", displayTime(item.CreatedAt)},
+		{"Updated", displayTime(item.UpdatedAt)},
+		{"Tags", strings.Join(item.Tags, ", ")},
+	} {
+		value := field[1]
+		if value == "" {
+			value = "-"
+		}
+		fmt.Fprintf(&b, "%-9s %s\n", field[0]+":", value)
+	}
+	b.WriteString("\nDescription\n-----------\n")
+	b.WriteString(strings.TrimRight(item.Description, "\n"))
+	fmt.Fprintf(&b, "\n\nComments (%d)\n------------\n", len(item.Comments))
+	for i, comment := range item.Comments {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "#%d  %s (%s)  %s\n", i+1, comment.AuthorName, comment.AuthorCompany, displayTime(comment.CreatedAt))
+		b.WriteString(strings.TrimRight(comment.Body, "\n"))
+		b.WriteString("\n")
+		for _, attachment := range comment.Attachments {
+			line := "Attachment: " + attachment.FileName
+			if size := formatSize(attachment.SizeBytes); size != "" {
+				line += " (" + size + ")"
+			}
+			if missing[attachment.RelativePath] {
+				line += " [not attached: file not found]"
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	if len(item.Comments) == 0 {
+		b.WriteString("No comments.\n")
+	}
+	return b.String()
+}
+
+// writeBody は DD-EML-001 の本文のパートを quoted-printable で書き込む。
+func writeBody(writer *multipart.Writer, body string) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", "text/plain; charset=utf-8")
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("create body part: %w", err)
+	}
+	encoder := quotedprintable.NewWriter(part)
+	if _, err := io.WriteString(encoder, body); err != nil {
+		return fmt.Errorf("write body part: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("write body part: %w", err)
+	}
+	return nil
+}
+
+// writeAttachment は DD-EML-001 の添付のパートを base64 で書き込む。ファイル名は RFC 2231 の形式で符号化する。
+func writeAttachment(writer *multipart.Writer, attachment attachmentPart) error {
+	header := textproto.MIMEHeader{}
+	contentType := mime.FormatMediaType(attachment.mimeType, map[string]string{"name": attachment.name})
+	if contentType == "" {
+		// 課題JSONの mime_type が不正な場合は種別を特定しない。
+		contentType = mime.FormatMediaType("application/octet-stream", map[string]string{"name": attachment.name})
+	}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.name}))
+	header.Set("Content-Transfer-Encoding", "base64")
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("create attachment part: %w", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(attachment.data)
+	for len(encoded) > 0 {
+		n := min(len(encoded), base64LineLength)
+		if _, err := io.WriteString(part, encoded[:n]+"\r\n"); err != nil {
+			return fmt.Errorf("write attachment part: %w", err)
+		}
+		encoded = encoded[n:]
+	}
+	return nil
+}
+
+// writeHeader は DD-EML-001 のメッセージのヘッダ1行を CRLF 区切りで書き込む。
+func writeHeader(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name + ": " + value + "\r\n")
+}
+
+// encodeHeader は DD-EML-001 のヘッダの値を RFC 2047 の UTF-8 の encoded-word で符号化し、長い値は encoded-word ごとに折り返す。
+// ASCII のみの値はそのまま返す。
+func encodeHeader(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	return strings.ReplaceAll(mime.BEncoding.Encode("utf-8", value), "?= =?", "?=\r\n =?")
+}
+
+// displayTime は DD-DATA-002 の保存された日時を表示用のタイムゾーンの分精度で返す。解析できない場合はそのまま返す。
+func displayTime(value string) string {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return parsed.In(timeutil.DisplayLocation()).Format("2006-01-02 15:04")
+}
+
+// formatSize は DD-EML-001 の添付のサイズを読みやすい単位で返す。不明な場合は空文字を返す。
+func formatSize(size int64) string {
+	switch {
+	case size <= 0:
+		return ""
+	case size < 1024:
+		return fmt.Sprintf("%d B", size)
+	case size < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	}
+}
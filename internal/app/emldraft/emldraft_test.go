@@ -0,0 +1,116 @@
+// emldraft_test.go は課題の下書き (.eml) の件名・本文・添付の出力と宛先の検査のテストを行う。
+package emldraft
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+)
+
+func TestWrite_EmbedsBodyAndAttachments(t *testing.T) {
+	// 件名をタイトルの encoded-word とし、本文に課題の詳細を、添付を実体ごと埋め込み、見つからない添付は本文に記すことを確認する。
+	categoryDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(categoryDir, "i1.files"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(categoryDir, "i1.files", "a1_log.txt"), []byte("log body"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	item := issue.Issue{
+		IssueID:     "i1",
+		Category:    "cat",
+		Title:       "画面が固まる",
+		Description: "再現手順\n1. 起動する",
+		Status:      issue.StatusOpen,
+		Priority:    issue.PriorityHigh,
+		Comments: []issue.Comment{{
+			Body:       "ログを添付します",
+			AuthorName: "tanaka",
+			Attachments: []issue.AttachmentRef{
+				{AttachmentID: "a1", FileName: "ログ.txt", RelativePath: "i1.files/a1_log.txt", SizeBytes: 8},
+				{AttachmentID: "a2", FileName: "gone.png", RelativePath: "i1.files/a2_gone.png"},
+				{AttachmentID: "a3", FileName: "escape.txt", RelativePath: "../escape.txt"},
+			},
+		}},
+	}
+	destPath := filepath.Join(t.TempDir(), "draft.eml")
+	result, err := Write(destPath, categoryDir, item, Options{To: "Vendor <vendor@example.com>"})
+	if err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	if result.Attachments != 1 || result.MissingAttachments != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	file, err := os.Open(destPath)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+	message, err := mail.ReadMessage(file)
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	if err != nil || subject != item.Title {
+		t.Fatalf("unexpected subject: %q %v", subject, err)
+	}
+	if message.Header.Get("X-Unsent") != "1" || message.Header.Get("To") != "\"Vendor\" <vendor@example.com>" {
+		t.Fatalf("unexpected header: %v", message.Header)
+	}
+	_, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("ParseMediaType: %v", err)
+	}
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	body := readPart(t, reader)
+	if !strings.Contains(body, "1. 起動する") || !strings.Contains(body, "gone.png [not attached: file not found]") {
+		t.Fatalf("unexpected body:\n%s", body)
+	}
+	part, err := reader.NextPart()
+	if err != nil {
+		t.Fatalf("NextPart: %v", err)
+	}
+	data, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
+	if err != nil || part.FileName() != "ログ.txt" || string(data) != "log body" {
+		t.Fatalf("unexpected attachment: %q %q %v", part.FileName(), data, err)
+	}
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Fatalf("expected no more parts, got %v", err)
+	}
+}
+
+func TestWrite_RejectsInvalidRecipient(t *testing.T) {
+	// 解析できない宛先は入力の不備とし、出力先を作成しないことを確認する。
+	destPath := filepath.Join(t.TempDir(), "draft.eml")
+	if _, err := Write(destPath, t.TempDir(), issue.Issue{Title: "t"}, Options{To: "not an address"}); !errors.Is(err, apperr.ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if _, err := os.Stat(destPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no draft, got %v", err)
+	}
+}
+
+// readPart はテスト用に次のパートを読み、内容を文字列で返す。quoted-printable は multipart.Reader が復号する。
+func readPart(t *testing.T, reader *multipart.Reader) string {
+	t.Helper()
+	part, err := reader.NextPart()
+	if err != nil {
+		t.Fatalf("NextPart: %v", err)
+	}
+	data, err := io.ReadAll(part)
+	if err != nil {
+		t.Fatalf("read part: %v", err)
+	}
+	return string(data)
+}
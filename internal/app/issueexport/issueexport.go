@@ -0,0 +1,353 @@
+// Package issueexport は課題の一覧を CSV・JSON・JSON Lines のファイルへ出力する処理を担い、保存先の選択や UI 表示は扱わない。
+// GUI と CLI の双方から用い、同じ条件であれば同じ内容のファイルを出力する。
+package issueexport
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"ratta/internal/app/categoryscan"
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/atomicwrite"
+	"ratta/internal/infra/jsonfmt"
+	"ratta/internal/infra/pluginhost"
+)
+
+// Format は DD-EXPORT-001 の出力形式を表す。
+type Format string
+
+const (
+	// FormatCSV は DD-EXPORT-001 の表計算ソフト向けの CSV 形式を表す。
+	FormatCSV Format = "csv"
+	// FormatJSON は DD-EXPORT-001 のコメントを含む JSON 形式を表す。
+	FormatJSON Format = "json"
+	// FormatJSONL は DD-EXPORT-002 の1行1課題の JSON Lines 形式を表し、分析ツールや一括移行での行単位の処理に用いる。
+	FormatJSONL Format = "jsonl"
+)
+
+// BuiltinFormats は DD-EXPORT-001 の本体が備える出力形式を表す。DD-PLUGIN-002 のプラグインが同じ名前の形式を追加しても本体の形式を用いる。
+var BuiltinFormats = []Format{FormatCSV, FormatJSON, FormatJSONL}
+
+// exportFormatVersion は DD-EXPORT-001 の JSON 形式の形式バージョンを表す。
+const exportFormatVersion = 1
+
+// utf8BOM は DD-EXPORT-001 の CSV 先頭に付ける BOM で、表計算ソフトが UTF-8 と判定できるようにする。
+const utf8BOM = "\ufeff"
+
+// CSVColumns は DD-EXPORT-001 の CSV の列見出しを出力順に表す。
+var CSVColumns = []string{
+	"category",
+	"issue_id",
+	"title",
+	"status",
+	"priority",
+	"origin_company",
+	"assignee",
+	"due_date",
+	"created_at",
+	"updated_at",
+	"comment_count",
+	"description",
+}
+
+// Filter は DD-EXPORT-001 の出力対象の条件を表す。空の項目は絞り込みを行わない。
+type Filter struct {
+	Categories []string
+	Statuses   []string
+}
+
+// Result は DD-EXPORT-001 の出力結果を表す。Skipped は解析できず出力しなかった課題JSONの数を表す。
+type Result struct {
+	Path    string
+	Format  Format
+	Count   int
+	Skipped int
+}
+
+// document は DD-EXPORT-001 の JSON 形式の出力内容を表す。
+type document struct {
+	FormatVersion int           `json:"format_version"`
+	Issues        []issue.Issue `json:"issues"`
+}
+
+// ParseFormat は DD-EXPORT-001 の出力形式の指定を検証する。
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case FormatCSV, FormatJSON, FormatJSONL:
+		return Format(value), nil
+	default:
+		return "", fmt.Errorf("unsupported export format: %s", value)
+	}
+}
+
+// Export は DD-EXPORT-001 の課題一覧の出力を行う。
+// 目的: 条件に一致する課題を表計算ソフトや他のツールで扱える形式で1ファイルにまとめる。
+// 入力: ctx は中断通知、root はプロジェクトルート、format は出力形式、filter は出力対象の条件、destPath は出力先。
+// 出力: Result とエラー。
+// エラー: 条件が不正な場合、カテゴリが存在しない場合、走査・出力に失敗した場合、中断された場合に返す。
+// 副作用: destPath へファイルを書き込む。プロジェクト配下のファイルは変更しない。
+// 並行性: 読み取りのみのため課題操作と同時に実行してよいが、実行中の変更が含まれるかは保証しない。
+// 不変条件: 課題はカテゴリの表示順、同一カテゴリ内は課題ID順に並べ、同じ内容のプロジェクトに対して同じファイルを出力する。
+// 関連DD: DD-EXPORT-001, DD-LOAD-002, DD-DATA-003
+func Export(ctx context.Context, root string, format Format, filter Filter, destPath string) (Result, error) {
+	if _, err := ParseFormat(string(format)); err != nil {
+		return Result{}, err
+	}
+	if destPath == "" {
+		return Result{}, fmt.Errorf("export path is required")
+	}
+	issues, skipped, err := Collect(ctx, root, filter)
+	if err != nil {
+		return Result{}, err
+	}
+	data, err := Encode(format, issues)
+	if err != nil {
+		return Result{}, err
+	}
+	if writeErr := atomicwrite.WriteFile(destPath, data); writeErr != nil {
+		return Result{}, fmt.Errorf("write export: %w", writeErr)
+	}
+	return Result{Path: destPath, Format: format, Count: len(issues), Skipped: skipped}, nil
+}
+
+// ExportTo は DD-EXPORT-002 の課題一覧をファイルではなく w へ出力する。
+// 目的: 標準出力などへ書き出し、分析ツールへパイプで受け渡せるようにする。
+// 入力・エラーは Export と同じとし、出力先の代わりに w を受け取る。Result.Path は空とする。
+// JSON Lines は1課題ずつ書き出し、書き込みに失敗した場合はそれまでの行を書き出したままエラーを返す。
+func ExportTo(ctx context.Context, root string, format Format, filter Filter, w io.Writer) (Result, error) {
+	if _, err := ParseFormat(string(format)); err != nil {
+		return Result{}, err
+	}
+	issues, skipped, err := Collect(ctx, root, filter)
+	if err != nil {
+		return Result{}, err
+	}
+	if format == FormatJSONL {
+		err = WriteJSONL(w, issues)
+	} else {
+		var data []byte
+		if data, err = Encode(format, issues); err == nil {
+			_, err = w.Write(data)
+		}
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("write export: %w", err)
+	}
+	return Result{Format: format, Count: len(issues), Skipped: skipped}, nil
+}
+
+// ExportPlugin は DD-PLUGIN-002 のプラグインが追加した出力形式で課題一覧を出力する。
+// 対象の課題は Export と同じ条件・順序で集め、変換をプラグインへ依頼した内容を destPath へアトミックに書き込む。
+// エラーは Export と同じとし、プラグインの失敗を加える。
+func ExportPlugin(ctx context.Context, root string, exporter *pluginhost.Exporter, filter Filter, destPath string) (Result, error) {
+	if destPath == "" {
+		return Result{}, fmt.Errorf("export path is required")
+	}
+	data, result, err := pluginContent(ctx, root, exporter, filter)
+	if err != nil {
+		return Result{}, err
+	}
+	if writeErr := atomicwrite.WriteFile(destPath, data); writeErr != nil {
+		return Result{}, fmt.Errorf("write export: %w", writeErr)
+	}
+	result.Path = destPath
+	return result, nil
+}
+
+// ExportPluginTo は DD-PLUGIN-002 のプラグインが追加した出力形式の課題一覧をファイルではなく w へ出力する。Result.Path は空とする。
+func ExportPluginTo(ctx context.Context, root string, exporter *pluginhost.Exporter, filter Filter, w io.Writer) (Result, error) {
+	data, result, err := pluginContent(ctx, root, exporter, filter)
+	if err != nil {
+		return Result{}, err
+	}
+	if _, writeErr := w.Write(data); writeErr != nil {
+		return Result{}, fmt.Errorf("write export: %w", writeErr)
+	}
+	return result, nil
+}
+
+// pluginContent は DD-PLUGIN-002 の出力対象の課題を集め、プラグインが変換した内容を返す。
+func pluginContent(ctx context.Context, root string, exporter *pluginhost.Exporter, filter Filter) ([]byte, Result, error) {
+	issues, skipped, err := Collect(ctx, root, filter)
+	if err != nil {
+		return nil, Result{}, err
+	}
+	data, err := exporter.Export(ctx, filepath.Base(root), issues)
+	if err != nil {
+		return nil, Result{}, err
+	}
+	return data, Result{Format: Format(exporter.Format), Count: len(issues), Skipped: skipped}, nil
+}
+
+// Collect は DD-EXPORT-001 の条件に一致する課題を読み込む。
+// 目的: 出力形式に依存せず、出力対象の課題を決まった順序で集める。
+// 入力: ctx は中断通知、root はプロジェクトルート、filter は出力対象の条件。
+// 出力: 課題、解析できず除いた課題JSONの数、エラー。
+// エラー: 未知のステータスや存在しないカテゴリを指定した場合、走査に失敗した場合、中断された場合に返す。
+// 副作用: なし。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: アーカイブ済みカテゴリも対象とし、名前変更中のカテゴリは対象としない。
+// 各課題の Category はディレクトリ名で上書きする。
+// 関連DD: DD-EXPORT-001, DD-LOAD-002, DD-LOAD-003
+func Collect(ctx context.Context, root string, filter Filter) ([]issue.Issue, int, error) {
+	statuses := make(map[issue.Status]bool, len(filter.Statuses))
+	for _, value := range filter.Statuses {
+		status := issue.Status(value)
+		if !status.IsValid() {
+			return nil, 0, fmt.Errorf("unknown status: %s", value)
+		}
+		statuses[status] = true
+	}
+	scanned, err := categoryscan.ScanContext(ctx, root)
+	if err != nil {
+		return nil, 0, err
+	}
+	wanted := make(map[string]bool, len(filter.Categories))
+	for _, name := range filter.Categories {
+		wanted[name] = true
+	}
+	found := make(map[string]bool, len(wanted))
+
+	issues := []issue.Issue{}
+	skipped := 0
+	for _, category := range scanned.Categories {
+		if len(wanted) > 0 && !wanted[category.Name] {
+			continue
+		}
+		found[category.Name] = true
+		if category.IsReadOnly && !category.IsArchived {
+			continue
+		}
+		items, categorySkipped, readErr := readCategory(ctx, category)
+		if readErr != nil {
+			return nil, 0, readErr
+		}
+		skipped += categorySkipped
+		for _, item := range items {
+			if len(statuses) == 0 || statuses[item.Status] {
+				issues = append(issues, item)
+			}
+		}
+	}
+	for _, name := range filter.Categories {
+		if !found[name] {
+			return nil, 0, apperr.Errorf(apperr.ErrNotFound, "category not found: %s", name)
+		}
+	}
+	return issues, skipped, nil
+}
+
+// readCategory は DD-EXPORT-001 のカテゴリ直下の課題JSONを課題ID順に読み込み、解析できないファイルの数を返す。
+func readCategory(ctx context.Context, category categoryscan.Category) ([]issue.Issue, int, error) {
+	entries, err := os.ReadDir(category.Path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("read category: %w", err)
+	}
+	var issues []issue.Issue
+	skipped := 0
+	for _, entry := range entries {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, 0, ctxErr
+		}
+		if entry.IsDir() || !issue.IsIssueFileName(entry.Name()) {
+			continue
+		}
+		// #nosec G304 -- カテゴリ配下の列挙結果から生成したパスのみを読む。
+		data, readErr := os.ReadFile(filepath.Join(category.Path, entry.Name()))
+		if readErr != nil {
+			skipped++
+			continue
+		}
+		var item issue.Issue
+		if json.Unmarshal(data, &item) != nil {
+			skipped++
+			continue
+		}
+		item.Category = category.Name
+		issues = append(issues, item)
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].IssueID < issues[j].IssueID })
+	return issues, skipped, nil
+}
+
+// Encode は DD-EXPORT-001 の課題を出力形式に従って符号化する。
+// CSV は BOM 付き UTF-8 で1課題1行とし、コメントは件数のみを出力する。
+// JSON は課題JSONと同じキー順でコメントと添付の参照を含めて出力する。JSON Lines は WriteJSONL と同じ内容とする。
+func Encode(format Format, issues []issue.Issue) ([]byte, error) {
+	switch format {
+	case FormatCSV:
+		return encodeCSV(issues)
+	case FormatJSON:
+		if issues == nil {
+			issues = []issue.Issue{}
+		}
+		return jsonfmt.MarshalIssueExport(document{FormatVersion: exportFormatVersion, Issues: issues})
+	case FormatJSONL:
+		var buf bytes.Buffer
+		if err := WriteJSONL(&buf, issues); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// WriteJSONL は DD-EXPORT-002 の JSON Lines 形式で課題を1件ずつ w へ書き出す。
+// 各行は課題JSONと同じキー順の1課題とし、category にカテゴリ名を含める。形式バージョンの行は置かず、
+// 各課題の version で課題JSONの版を表す。書き込みに失敗した時点で中断し、それまでの行は書き出したままとする。
+func WriteJSONL(w io.Writer, issues []issue.Issue) error {
+	for _, item := range issues {
+		line, err := jsonfmt.MarshalIssueLine(item)
+		if err != nil {
+			return fmt.Errorf("encode issue %s: %w", item.IssueID, err)
+		}
+		if _, err := w.Write(line); err != nil {
+			return fmt.Errorf("write jsonl: %w", err)
+		}
+	}
+	return nil
+}
+
+// encodeCSV は DD-EXPORT-001 の CSV 形式の符号化を行う。
+func encodeCSV(issues []issue.Issue) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(utf8BOM)
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(CSVColumns); err != nil {
+		return nil, fmt.Errorf("write csv: %w", err)
+	}
+	for _, item := range issues {
+		record := []string{
+			item.Category,
+			item.IssueID,
+			item.Title,
+			string(item.Status),
+			string(item.Priority),
+			string(item.OriginCompany),
+			item.Assignee,
+			item.DueDate,
+			item.CreatedAt,
+			item.UpdatedAt,
+			strconv.Itoa(len(item.Comments)),
+			item.Description,
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, fmt.Errorf("write csv: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("write csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,223 @@
+// issueexport_test.go は課題一覧の CSV/JSON 出力とプラグインによる出力の絞り込みと形式のテストを行う。
+package issueexport
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/pluginhost"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// newProject はテスト用に2カテゴリと課題を持つプロジェクトを作成し、ルートを返す。
+// cat-a には Open の課題2件、cat-b には Open の課題1件を作成し、cat-a の1件目を Working にする。
+func newProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	service := issueops.NewService(root, nil)
+	for _, spec := range []struct{ category, title string }{
+		{"cat-a", "first, with comma"},
+		{"cat-a", "second"},
+		{"cat-b", "third"},
+	} {
+		if err := os.MkdirAll(filepath.Join(root, spec.category), 0o750); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		created, err := service.CreateIssue(spec.category, mod.ModeVendor, issueops.IssueCreateInput{
+			Title:       spec.title,
+			Description: "line1\nline2",
+			DueDate:     "2024-01-01",
+			Priority:    issue.PriorityLow,
+		})
+		if err != nil {
+			t.Fatalf("CreateIssue error: %v", err)
+		}
+		if spec.title == "first, with comma" {
+			if _, err := service.UpdateIssue(spec.category, created.Issue.IssueID, mod.ModeContractor, issueops.IssueUpdateInput{
+				Title:       spec.title,
+				Description: "line1\nline2",
+				DueDate:     "2024-01-01",
+				Priority:    issue.PriorityLow,
+				Status:      issue.StatusWorking,
+			}); err != nil {
+				t.Fatalf("UpdateIssue error: %v", err)
+			}
+		}
+	}
+	return root
+}
+
+func TestExport_WritesCSVWithFilters(t *testing.T) {
+	// BOM 付きの CSV に見出しと条件に一致する課題を出力し、カンマや改行を含む値を引用することを確認する。
+	root := newProject(t)
+	dest := filepath.Join(t.TempDir(), "issues.csv")
+	result, err := Export(context.Background(), root, FormatCSV, Filter{Categories: []string{"cat-a"}, Statuses: []string{"Working"}}, dest)
+	if err != nil {
+		t.Fatalf("Export error: %v", err)
+	}
+	if result.Count != 1 || result.Skipped != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !strings.HasPrefix(string(data), utf8BOM) {
+		t.Fatalf("expected BOM")
+	}
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(data), utf8BOM))).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(records) != 2 || strings.Join(records[0], ",") != strings.Join(CSVColumns, ",") {
+		t.Fatalf("unexpected records: %q", records)
+	}
+	row := records[1]
+	if row[0] != "cat-a" || row[2] != "first, with comma" || row[3] != "Working" || row[11] != "line1\nline2" {
+		t.Fatalf("unexpected row: %q", row)
+	}
+}
+
+func TestExport_WritesJSONForAllCategories(t *testing.T) {
+	// 条件を指定しない場合は全カテゴリの課題をカテゴリ順・課題ID順の JSON で出力することを確認する。
+	root := newProject(t)
+	dest := filepath.Join(t.TempDir(), "issues.json")
+	if _, err := os.Create(filepath.Join(root, "cat-b", "broken.json")); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	result, err := Export(context.Background(), root, FormatJSON, Filter{}, dest)
+	if err != nil {
+		t.Fatalf("Export error: %v", err)
+	}
+	if result.Count != 3 || result.Skipped != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var got document
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.FormatVersion != exportFormatVersion || len(got.Issues) != 3 {
+		t.Fatalf("unexpected document: %+v", got)
+	}
+	if got.Issues[0].Category != "cat-a" || got.Issues[2].Category != "cat-b" || got.Issues[0].IssueID > got.Issues[1].IssueID {
+		t.Fatalf("unexpected order: %+v", got.Issues)
+	}
+}
+
+func TestExport_WritesJSONLOneIssuePerLine(t *testing.T) {
+	// JSON Lines では1行に1課題を課題JSONのキー順で出力し、説明中の改行で行が分かれないことを確認する。
+	root := newProject(t)
+	dest := filepath.Join(t.TempDir(), "issues.jsonl")
+	result, err := Export(context.Background(), root, FormatJSONL, Filter{Statuses: []string{"Open"}}, dest)
+	if err != nil || result.Count != 2 || result.Format != FormatJSONL {
+		t.Fatalf("unexpected result: %+v %v", result, err)
+	}
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], `{"version":`) {
+		t.Fatalf("unexpected lines:\n%s", data)
+	}
+	var first issue.Issue
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if first.Category != "cat-a" || first.Title != "second" || first.Description != "line1\nline2" {
+		t.Fatalf("unexpected issue: %+v", first)
+	}
+}
+
+func TestExportTo_WritesToWriter(t *testing.T) {
+	// ファイルの代わりに渡した書き込み先へ出力し、出力先のパスは空とすることを確認する。
+	root := newProject(t)
+	var buf strings.Builder
+	result, err := ExportTo(context.Background(), root, FormatJSONL, Filter{Categories: []string{"cat-b"}}, &buf)
+	if err != nil || result.Count != 1 || result.Path != "" {
+		t.Fatalf("unexpected result: %+v %v", result, err)
+	}
+	if strings.Count(buf.String(), "\n") != 1 || !strings.Contains(buf.String(), `"title":"third"`) {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+	if _, err := ExportTo(context.Background(), root, Format("xml"), Filter{}, &buf); err == nil {
+		t.Fatal("expected format error")
+	}
+}
+
+func TestExport_RejectsUnknownConditions(t *testing.T) {
+	// 未知の形式・ステータス、存在しないカテゴリの指定はエラーとし、ファイルを作成しないことを確認する。
+	root := newProject(t)
+	dest := filepath.Join(t.TempDir(), "issues.csv")
+	cases := []struct {
+		format Format
+		filter Filter
+	}{
+		{Format("xml"), Filter{}},
+		{FormatCSV, Filter{Statuses: []string{"Done"}}},
+		{FormatCSV, Filter{Categories: []string{"missing"}}},
+	}
+	for _, tc := range cases {
+		if _, err := Export(context.Background(), root, tc.format, tc.filter, dest); err == nil {
+			t.Fatalf("expected error for %+v", tc)
+		}
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Fatalf("expected no output, got %v", err)
+	}
+}
+
+func TestExportPlugin_WritesConvertedContent(t *testing.T) {
+	// 条件に一致する課題をプラグインへ渡し、変換された内容をファイルと w へ書き出すことを確認する。
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on windows")
+	}
+	root := newProject(t)
+	pluginsDir := t.TempDir()
+	pluginDir := filepath.Join(pluginsDir, "xlsx")
+	if err := os.MkdirAll(pluginDir, 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	manifest := `{"protocol_version": 1, "command": "run.sh", "exporters": [{"format": "xlsx", "extension": ".xlsx"}]}`
+	if err := os.WriteFile(filepath.Join(pluginDir, pluginhost.ManifestFileName), []byte(manifest), 0o600); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+	script := "#!/bin/sh\ncat > request.json\nprintf '{\"content\": \"eGxzeA==\"}'\n"
+	if err := os.WriteFile(filepath.Join(pluginDir, "run.sh"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	registry, _ := pluginhost.Discover(pluginsDir)
+	exporter, _ := registry.Exporter("xlsx")
+
+	dest := filepath.Join(t.TempDir(), "issues.xlsx")
+	result, err := ExportPlugin(context.Background(), root, exporter, Filter{Categories: []string{"cat-a"}}, dest)
+	if err != nil || result.Count != 2 || result.Format != "xlsx" || result.Path != dest {
+		t.Fatalf("unexpected result: %+v %v", result, err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "xlsx" {
+		t.Fatalf("unexpected content: %q", data)
+	}
+	request, _ := os.ReadFile(filepath.Join(pluginDir, "request.json"))
+	if strings.Contains(string(request), "third") || !strings.Contains(string(request), "second") {
+		t.Fatalf("expected only filtered issues in the request: %s", request)
+	}
+
+	var out bytes.Buffer
+	if result, err := ExportPluginTo(context.Background(), root, exporter, Filter{}, &out); err != nil || result.Count != 3 || out.String() != "xlsx" {
+		t.Fatalf("unexpected stream export: %+v %q %v", result, out.String(), err)
+	}
+}
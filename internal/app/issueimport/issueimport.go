@@ -0,0 +1,147 @@
+// Package issueimport は JSON Lines 形式の課題を1行ずつ読み込んで検証・保存する処理を担い、読み込み元の選択や UI 表示は扱わない。
+// issueexport の JSON Lines 出力を読み戻せる形式とし、GUI と CLI の双方から用いる。
+package issueimport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/apperr"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// 行の結果は DD-IMPORT-001 の1行分の取り込みの結果を表す。dry-run では保存した場合の結果を表す。
+const (
+	RowCreated = "created"
+	RowUpdated = "updated"
+	RowFailed  = "failed"
+)
+
+// Options は DD-IMPORT-001 の取り込みの設定を表す。
+// Category は取り込み先のカテゴリで、空の場合は各行の category を用いる。
+// Overwrite は取り込み先に同じ課題IDの課題がある場合に上書きすることを表し、false の場合はその行を失敗とする。
+// DryRun は検証のみを行い保存しないことを表す。
+type Options struct {
+	Category  string
+	Overwrite bool
+	DryRun    bool
+}
+
+// RowResult は DD-IMPORT-001 の1行分の取り込みの結果を表す。Line は空行を含めたファイル上の行番号を表す。
+type RowResult struct {
+	Line     int
+	Status   string
+	Category string
+	IssueID  string
+	Message  string
+}
+
+// Result は DD-IMPORT-001 の取り込みの結果を表す。
+type Result struct {
+	DryRun  bool
+	Created int
+	Updated int
+	Failed  int
+	Rows    []RowResult
+}
+
+// Import は DD-IMPORT-001 の JSON Lines 形式の課題の取り込みを行う。
+// 目的: 分析ツールや他のプロジェクトから出力した課題を、個別のスクリプトを書かずに検証して一括で登録する。
+// 入力: ctx は中断通知、service は課題の検証・保存に用いるサービス、currentMode は操作モード、r は読み込み元、opts は取り込みの設定。
+// 出力: Result とエラー。
+// エラー: 読み込み元の読み取りに失敗した場合、中断された場合に返す。
+// 行ごとの失敗 (JSON・スキーマ・課題の項目の不備、カテゴリ不存在、課題IDの重複) は Result.Rows に含め、残りの行の処理を続ける。
+// 副作用: 課題JSONを作成・上書きする。dry-run では保存しない。
+// 並行性: 取り込み先のカテゴリへの同時書き込みは呼び出し側で排他する。
+// 不変条件: r は1行ずつ読み、全体を保持しない。空行は読み飛ばす。課題ID・作成日時・ステータス・コメントは行の値のまま保存し、
+// 課題IDの無い行は新たに採番する。添付は参照のみを取り込み、添付ファイルは複製しない。
+// 関連DD: DD-IMPORT-001, DD-EXPORT-002, DD-DATA-003
+func Import(ctx context.Context, service *issueops.Service, currentMode mod.Mode, r io.Reader, opts Options) (Result, error) {
+	reader := bufio.NewReader(r)
+	seen := map[string]bool{}
+	result := Result{DryRun: opts.DryRun, Rows: []RowResult{}}
+	for line := 1; ; line++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return Result{}, ctxErr
+		}
+		data, readErr := reader.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return Result{}, fmt.Errorf("read jsonl: %w", readErr)
+		}
+		if line == 1 {
+			data = bytes.TrimPrefix(data, []byte("\ufeff"))
+		}
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			row := apply(service, currentMode, opts, seen, line, data)
+			switch row.Status {
+			case RowCreated:
+				result.Created++
+			case RowUpdated:
+				result.Updated++
+			default:
+				result.Failed++
+			}
+			result.Rows = append(result.Rows, row)
+		}
+		if readErr != nil {
+			return result, nil
+		}
+	}
+}
+
+// apply は DD-IMPORT-001 の1行分の課題を作成・上書き (dry-run では検証) し、結果を返す。
+// seen は既に処理した行のカテゴリと課題IDを表し、同じファイル内の重複を失敗とするために用いる。
+func apply(service *issueops.Service, currentMode mod.Mode, opts Options, seen map[string]bool, line int, data []byte) RowResult {
+	row := RowResult{Line: line}
+	fail := func(err error) RowResult {
+		row.Status = RowFailed
+		row.Message = err.Error()
+		return row
+	}
+	item, err := service.ParseImportedIssue(data)
+	if err != nil {
+		return fail(err)
+	}
+	category := opts.Category
+	if category == "" {
+		category = item.Category
+	}
+	if category == "" {
+		return fail(apperr.New(apperr.ErrValidation, "category is required"))
+	}
+	row.Category = category
+	row.IssueID = item.IssueID
+	row.Status = RowCreated
+	if item.IssueID != "" {
+		key := category + "/" + item.IssueID
+		if seen[key] {
+			return fail(apperr.Errorf(apperr.ErrConflict, "issue %s appears more than once", item.IssueID))
+		}
+		seen[key] = true
+		if service.HasIssue(category, item.IssueID) {
+			if !opts.Overwrite {
+				return fail(apperr.Errorf(apperr.ErrConflict, "issue %s already exists in %s", item.IssueID, category))
+			}
+			row.Status = RowUpdated
+		}
+	}
+
+	if opts.DryRun {
+		if err := service.CheckImportedIssue(category, currentMode, item); err != nil {
+			return fail(err)
+		}
+		return row
+	}
+	saved, err := service.SaveImportedIssue(category, currentMode, item)
+	if err != nil {
+		return fail(err)
+	}
+	row.IssueID = saved.Issue.IssueID
+	return row
+}
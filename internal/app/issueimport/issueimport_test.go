@@ -0,0 +1,104 @@
+// issueimport_test.go は JSON Lines 形式の課題の取り込みの検証・上書き・dry-run のテストを行う。
+package issueimport
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ratta/internal/app/issueexport"
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/schema"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// newService はテスト用にカテゴリ src・dst を持つプロジェクトとスキーマ検証付きのサービスを作成する。
+func newService(t *testing.T) *issueops.Service {
+	t.Helper()
+	root := t.TempDir()
+	for _, name := range []string{"src", "dst"} {
+		if err := os.MkdirAll(filepath.Join(root, name), 0o750); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	validator, err := schema.NewValidatorFromDir(filepath.Join("..", "..", "..", "schemas"))
+	if err != nil {
+		t.Fatalf("NewValidatorFromDir error: %v", err)
+	}
+	return issueops.NewService(root, validator)
+}
+
+// exportLines はテスト用に src の課題を作成して JSON Lines で出力した内容を返す。
+func exportLines(t *testing.T, service *issueops.Service, titles ...string) []byte {
+	t.Helper()
+	issues := make([]issue.Issue, 0, len(titles))
+	for _, title := range titles {
+		created, err := service.CreateIssue("src", mod.ModeVendor, issueops.IssueCreateInput{
+			Title: title, Description: "d", DueDate: "2024-01-01", Priority: issue.PriorityLow,
+		})
+		if err != nil {
+			t.Fatalf("CreateIssue error: %v", err)
+		}
+		issues = append(issues, created.Issue)
+	}
+	var buf bytes.Buffer
+	if err := issueexport.WriteJSONL(&buf, issues); err != nil {
+		t.Fatalf("WriteJSONL error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestImport_RoundTripsExportedLines(t *testing.T) {
+	// 出力した JSON Lines を別カテゴリへ課題IDのまま取り込み、再度の取り込みは上書きを指定した場合のみ更新とすることを確認する。
+	service := newService(t)
+	data := exportLines(t, service, "first", "second")
+
+	result, err := Import(context.Background(), service, mod.ModeVendor, bytes.NewReader(data), Options{Category: "dst"})
+	if err != nil || result.Created != 2 || result.Failed != 0 {
+		t.Fatalf("unexpected result: %+v %v", result, err)
+	}
+	detail, err := service.GetIssue("dst", result.Rows[0].IssueID)
+	if err != nil || detail.Issue.Title != "first" || detail.Issue.Category != "dst" {
+		t.Fatalf("unexpected imported issue: %+v %v", detail.Issue, err)
+	}
+
+	result, err = Import(context.Background(), service, mod.ModeVendor, bytes.NewReader(data), Options{Category: "dst"})
+	if err != nil || result.Failed != 2 || !strings.Contains(result.Rows[0].Message, "already exists") {
+		t.Fatalf("expected conflicts, got %+v %v", result, err)
+	}
+	result, err = Import(context.Background(), service, mod.ModeVendor, bytes.NewReader(data), Options{Category: "dst", Overwrite: true})
+	if err != nil || result.Updated != 2 {
+		t.Fatalf("expected updates, got %+v %v", result, err)
+	}
+}
+
+func TestImport_ReportsInvalidLinesAndDryRun(t *testing.T) {
+	// 不正な行は行番号付きで失敗とし、空行は読み飛ばし、dry-run では保存しないことを確認する。
+	service := newService(t)
+	valid := exportLines(t, service, "first")
+	input := "\n{broken\n" + `{"version":2,"title":"no id"}` + "\n" + string(valid) + string(valid)
+
+	result, err := Import(context.Background(), service, mod.ModeVendor, strings.NewReader(input), Options{Category: "dst", DryRun: true})
+	if err != nil {
+		t.Fatalf("Import error: %v", err)
+	}
+	if !result.DryRun || result.Created != 1 || result.Failed != 3 || len(result.Rows) != 4 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.Rows[0].Line != 2 || result.Rows[1].Status != RowFailed || result.Rows[2].Line != 4 || !strings.Contains(result.Rows[3].Message, "more than once") {
+		t.Fatalf("unexpected rows: %+v", result.Rows)
+	}
+	if summaries, err := service.ListSummaries("dst"); err != nil || len(summaries) != 0 {
+		t.Fatalf("dry run must not save: %+v %v", summaries, err)
+	}
+
+	result, err = Import(context.Background(), service, mod.ModeVendor, bytes.NewReader(valid), Options{})
+	if err != nil || result.Failed != 1 || result.Rows[0].Category != "src" {
+		t.Fatalf("expected conflict in source category, got %+v %v", result, err)
+	}
+}
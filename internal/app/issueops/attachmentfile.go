@@ -0,0 +1,141 @@
+// attachmentfile.go は添付候補のローカルファイルの検査を担い、添付の保存やコメントの更新は扱わない。
+// ドラッグ&ドロップなど UI から渡されたパスを信用せず、サイズと種類の制限をバックエンドで確認する。
+package issueops
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ratta/internal/infra/projectmeta"
+)
+
+// maxAttachmentBytes は DD-DATA-005 の添付1件あたりの上限サイズを表す。
+// 共有フォルダ上の課題ディレクトリを肥大化させないため、資料の添付に十分な大きさに留める。
+const maxAttachmentBytes = 20 << 20
+
+// blockedAttachmentExtensions は DD-DATA-005 の添付を受け付けない実行形式の拡張子を表す。
+// 共有フォルダ経由で相手側の環境で誤って実行されることを防ぐ。
+var blockedAttachmentExtensions = map[string]bool{
+	".exe": true,
+	".com": true,
+	".bat": true,
+	".cmd": true,
+	".msi": true,
+	".scr": true,
+	".ps1": true,
+	".vbs": true,
+	".lnk": true,
+}
+
+// AttachmentFile は DD-DATA-005 の添付可能と確認したローカルファイルを表す。
+type AttachmentFile struct {
+	Path      string
+	Name      string
+	MimeType  string
+	SizeBytes int64
+}
+
+// InspectAttachmentFile は DD-DATA-005 の添付候補ファイルの検査を行う。
+// 目的: UI から渡されたパスが添付可能な通常ファイルであることを確認し、添付入力に必要な情報を返す。
+// 入力: path は添付候補のパス。
+// 出力: AttachmentFile とエラー。
+// エラー: パスが空・存在しない・通常ファイルでない・サイズ超過・受け付けない種類の場合に返す。
+// 副作用: ファイル情報を読み取る。内容は読み込まない。
+// 並行性: スレッドセーフ。
+// 不変条件: 返却する Path は絶対パスとする。
+// 関連DD: DD-DATA-005
+func InspectAttachmentFile(path string) (AttachmentFile, error) {
+	if strings.TrimSpace(path) == "" {
+		return AttachmentFile{}, errors.New("attachment path is empty")
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return AttachmentFile{}, fmt.Errorf("resolve attachment path: %w", err)
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return AttachmentFile{}, fmt.Errorf("stat attachment: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return AttachmentFile{}, errors.New("attachment is not a regular file")
+	}
+	if err := checkAttachmentSize(info.Size()); err != nil {
+		return AttachmentFile{}, err
+	}
+	name := filepath.Base(absPath)
+	if err := checkAttachmentName(name); err != nil {
+		return AttachmentFile{}, err
+	}
+	return AttachmentFile{
+		Path:      absPath,
+		Name:      name,
+		MimeType:  mime.TypeByExtension(strings.ToLower(filepath.Ext(name))),
+		SizeBytes: info.Size(),
+	}, nil
+}
+
+// checkAttachmentSize は DD-DATA-005 の添付サイズの上限を確認する。
+func checkAttachmentSize(size int64) error {
+	return checkAttachmentSizeWithin(size, maxAttachmentBytes)
+}
+
+// checkAttachmentSizeWithin は DD-DATA-005 の添付サイズが limit 以下であることを確認する。
+func checkAttachmentSizeWithin(size, limit int64) error {
+	if size > limit {
+		return fmt.Errorf("attachment exceeds %d bytes", limit)
+	}
+	return nil
+}
+
+// checkAttachmentLimits は DD-PROJCONF-001 のプロジェクト単位の制限を加えた添付の数とサイズの上限を確認する。
+// プロジェクト単位の制限は組み込みの上限より厳しい場合のみ用いる。
+func (s *Service) checkAttachmentLimits(attachments []CommentAttachmentInput) error {
+	cfg, err := projectmeta.LoadConfig(s.projectRoot)
+	if err != nil {
+		return err
+	}
+	limits := cfg.AttachmentLimit()
+	maxCount := maxCommentAttachments
+	if limits.MaxPerComment > 0 && limits.MaxPerComment < maxCount {
+		maxCount = limits.MaxPerComment
+	}
+	maxBytes := int64(maxAttachmentBytes)
+	if limits.MaxBytes > 0 && limits.MaxBytes < maxBytes {
+		maxBytes = limits.MaxBytes
+	}
+	if len(attachments) > maxCount {
+		return errors.New("too many attachments")
+	}
+	for _, attachment := range attachments {
+		if err := checkAttachmentSizeWithin(int64(len(attachment.Data)), maxBytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsMimeMismatch は DD-DATA-005 の UI が申告した種類 declared と拡張子から判定した種類 detected が異なるかを判定する。
+// いずれかが空・解析できない場合は比較できないため、異ならないものとする。パラメータ (charset など) は比較しない。
+func IsMimeMismatch(declared, detected string) bool {
+	declaredType, _, err := mime.ParseMediaType(declared)
+	if err != nil {
+		return false
+	}
+	detectedType, _, err := mime.ParseMediaType(detected)
+	if err != nil {
+		return false
+	}
+	return declaredType != detectedType
+}
+
+// checkAttachmentName は DD-DATA-005 の添付の種類を拡張子で確認する。
+func checkAttachmentName(name string) error {
+	if blockedAttachmentExtensions[strings.ToLower(filepath.Ext(name))] {
+		return errors.New("attachment type is not allowed")
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+// attachmentfile_test.go は添付候補ファイルの検査のテストを行い、添付の保存は扱わない。
+package issueops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ratta/internal/infra/projectmeta"
+)
+
+func TestInspectAttachmentFile_AcceptsRegularFileAndRejectsOthers(t *testing.T) {
+	// 通常ファイルは名前とサイズを返し、ディレクトリ・実行形式・上限超過は拒否することを確認する。
+	dir := t.TempDir()
+	writeFile := func(name string, size int) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, make([]byte, size), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		return path
+	}
+
+	file, err := InspectAttachmentFile(writeFile("memo.txt", 3))
+	if err != nil {
+		t.Fatalf("InspectAttachmentFile error: %v", err)
+	}
+	if file.Name != "memo.txt" || file.SizeBytes != 3 || !filepath.IsAbs(file.Path) {
+		t.Fatalf("unexpected file: %+v", file)
+	}
+
+	for name, path := range map[string]string{
+		"directory":  dir,
+		"executable": writeFile("tool.EXE", 1),
+		"too large":  writeFile("big.bin", maxAttachmentBytes+1),
+		"missing":    filepath.Join(dir, "missing.txt"),
+		"empty":      "",
+	} {
+		if _, err := InspectAttachmentFile(path); err == nil {
+			t.Fatalf("expected %s to be rejected", name)
+		}
+	}
+}
+
+func TestCheckAttachmentLimits_FollowsProjectConfig(t *testing.T) {
+	// プロジェクト単位の設定で添付の数とサイズを組み込みの上限より厳しくでき、緩める指定は無視することを確認する。
+	root := t.TempDir()
+	service := NewService(root, nil)
+	three := []CommentAttachmentInput{{Data: []byte("a")}, {Data: []byte("b")}, {Data: []byte("c")}}
+	if err := service.checkAttachmentLimits(three); err != nil {
+		t.Fatalf("expected built-in limits without project config, got %v", err)
+	}
+
+	if err := os.MkdirAll(projectmeta.Dir(root), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeConfig := func(contents string) {
+		t.Helper()
+		if err := os.WriteFile(projectmeta.ConfigPath(root), []byte(contents), 0o600); err != nil {
+			t.Fatalf("write project config: %v", err)
+		}
+	}
+	writeConfig(`{"format_version": 1, "attachments": {"max_bytes": 4, "max_per_comment": 2}}`)
+	if err := service.checkAttachmentLimits(three); err == nil {
+		t.Fatal("expected too many attachments")
+	}
+	if err := service.checkAttachmentLimits([]CommentAttachmentInput{{Data: []byte("12345")}}); err == nil {
+		t.Fatal("expected size limit from project config")
+	}
+	if err := service.checkAttachmentLimits(three[:2]); err != nil {
+		t.Fatalf("expected attachments within limits, got %v", err)
+	}
+
+	writeConfig(`{"format_version": 1, "attachments": {"max_per_comment": 100}}`)
+	if err := service.checkAttachmentLimits(make([]CommentAttachmentInput, maxCommentAttachments+1)); err == nil {
+		t.Fatal("expected built-in limit to win over a looser project config")
+	}
+}
+
+func TestIsMimeMismatch_ComparesMediaTypes(t *testing.T) {
+	// 申告された種類と拡張子から判定した種類はパラメータを除いて比較し、いずれかが空の場合は不一致としないことを確認する。
+	cases := []struct {
+		declared, detected string
+		want               bool
+	}{
+		{"image/png", "image/png", false},
+		{"text/plain", "text/plain; charset=utf-8", false},
+		{"image/png", "application/pdf", true},
+		{"", "image/png", false},
+		{"image/png", "", false},
+	}
+	for _, tc := range cases {
+		if got := IsMimeMismatch(tc.declared, tc.detected); got != tc.want {
+			t.Fatalf("IsMimeMismatch(%q, %q) = %v, want %v", tc.declared, tc.detected, got, tc.want)
+		}
+	}
+}
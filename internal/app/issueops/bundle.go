@@ -0,0 +1,470 @@
+// bundle.go は課題単位のバンドル(zip)の出力と取り込みを担い、保存先の選択や UI 表示は扱わない。
+// バンドルは課題JSON・添付ディレクトリ・manifest.json の3要素で構成する。
+package issueops
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/atomicwrite"
+	"ratta/internal/infra/jsonfmt"
+	"ratta/internal/infra/safezip"
+
+	mod "ratta/internal/domain/mode"
+)
+
+const (
+	// bundleFormatVersion は DD-BUNDLE-001 の manifest 形式バージョンを表す。
+	bundleFormatVersion = 1
+	// bundleKind は DD-BUNDLE-001 のバンドル識別子で、他形式の zip との取り違えを防ぐ。
+	bundleKind = "ratta-issue-bundle"
+	// bundleManifestName は DD-BUNDLE-001 の manifest エントリ名を表す。
+	bundleManifestName = "manifest.json"
+)
+
+// bundleZipLimits は DD-BUNDLE-002 の取り込むバンドルの展開後の大きさの上限を表す。
+// エントリ1件は添付1件の上限 (課題JSON・manifest もこれに収まる)、合計はメモリへ読み込んでよい量として 512 MiB とする。
+var bundleZipLimits = safezip.Limits{MaxEntryBytes: maxAttachmentBytes, MaxTotalBytes: 512 << 20}
+
+// BundleManifest は DD-BUNDLE-001 のバンドル構成情報を表す。
+type BundleManifest struct {
+	FormatVersion int          `json:"format_version"`
+	Kind          string       `json:"kind"`
+	ExportedAt    string       `json:"exported_at"`
+	IssueID       string       `json:"issue_id"`
+	Category      string       `json:"category"`
+	IssueFile     string       `json:"issue_file"`
+	Files         []BundleFile `json:"files"`
+}
+
+// BundleFile は DD-BUNDLE-001 のバンドル内ファイル情報を表す。
+type BundleFile struct {
+	Path      string `json:"path"`
+	SizeBytes int64  `json:"size_bytes"`
+	SHA256    string `json:"sha256"`
+}
+
+// BundleExportResult は DD-BUNDLE-001 のバンドル出力結果を表す。
+type BundleExportResult struct {
+	Path      string
+	IssueID   string
+	FileCount int
+}
+
+// bundleEntry は zip へ書き込む1ファイル分の内容を表す。
+type bundleEntry struct {
+	name string
+	data []byte
+}
+
+// ExportIssueBundle は DD-BUNDLE-001 の課題バンドル出力を行う。
+// 目的: 課題JSONと添付ファイルを manifest 付きの zip にまとめ、メール添付や個別保管に使えるようにする。
+// 入力: category と issueID は対象識別子、destPath は出力先 zip のパス。
+// 出力: BundleExportResult とエラー。
+// エラー: 出力先未指定、課題読み込み失敗、スキーマ不整合、添付読み取り失敗、書き込み失敗時に返す。
+// 副作用: destPath に zip を atomic write で作成する。プロジェクトルート配下は変更しない。
+// 並行性: 読み取り中に同一課題が更新されることは想定しない。
+// 不変条件: manifest の files には zip 内の manifest 以外の全エントリが含まれる。
+// 関連DD: DD-BUNDLE-001, DD-PERSIST-002
+func (s *Service) ExportIssueBundle(category, issueID, destPath string) (BundleExportResult, error) {
+	return s.ExportIssueBundleContext(context.Background(), category, issueID, destPath)
+}
+
+// ExportIssueBundleContext は DD-BUNDLE-001/DD-CANCEL-001 の中断可能な課題バンドル出力を行う。
+// 添付の読み込み中や書き込み前に中断された場合は出力先を作成せず ctx.Err() を返す。
+func (s *Service) ExportIssueBundleContext(ctx context.Context, category, issueID, destPath string) (BundleExportResult, error) {
+	if destPath == "" {
+		return BundleExportResult{}, errors.New("destination path is required")
+	}
+	issuePath := filepath.Join(s.projectRoot, category, issueID+".json")
+	detail, err := s.readIssue(issuePath, category)
+	if err != nil {
+		return BundleExportResult{}, err
+	}
+	if detail.IsSchemaInvalid {
+		return BundleExportResult{}, apperr.New(apperr.ErrSchemaInvalid, "schema invalid issue cannot be exported")
+	}
+
+	issueData, err := jsonfmt.MarshalIssue(detail.Issue)
+	if err != nil {
+		return BundleExportResult{}, fmt.Errorf("marshal issue: %w", err)
+	}
+	issueFile := issueID + ".json"
+	entries := []bundleEntry{{name: issueFile, data: issueData}}
+
+	attachments, err := collectAttachmentEntries(ctx, filepath.Join(s.projectRoot, category), issueID)
+	if err != nil {
+		return BundleExportResult{}, err
+	}
+	entries = append(entries, attachments...)
+
+	manifest := BundleManifest{
+		FormatVersion: bundleFormatVersion,
+		Kind:          bundleKind,
+		ExportedAt:    nowISO(),
+		IssueID:       issueID,
+		Category:      category,
+		IssueFile:     issueFile,
+		Files:         make([]BundleFile, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		sum := sha256.Sum256(entry.data)
+		manifest.Files = append(manifest.Files, BundleFile{
+			Path:      entry.name,
+			SizeBytes: int64(len(entry.data)),
+			SHA256:    hex.EncodeToString(sum[:]),
+		})
+	}
+	manifestData, err := jsonfmt.MarshalBundleManifest(manifest)
+	if err != nil {
+		return BundleExportResult{}, fmt.Errorf("marshal manifest: %w", err)
+	}
+
+	archive, err := buildZip(append([]bundleEntry{{name: bundleManifestName, data: manifestData}}, entries...))
+	if err != nil {
+		return BundleExportResult{}, err
+	}
+	// 書き込み後の中断は出力済みの zip と矛盾するため、中断の受け付けは書き込み前までとする。
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return BundleExportResult{}, ctxErr
+	}
+	if writeErr := atomicwrite.WriteFile(destPath, archive); writeErr != nil {
+		return BundleExportResult{}, fmt.Errorf("write bundle: %w", writeErr)
+	}
+
+	return BundleExportResult{
+		Path:      destPath,
+		IssueID:   issueID,
+		FileCount: len(entries),
+	}, nil
+}
+
+// collectAttachmentEntries は DD-BUNDLE-001 の添付収集を行う。
+// 目的: <issue_id>.files 配下の通常ファイルを zip エントリとして読み込む。
+// 入力: ctx は中断通知、categoryPath はカテゴリパス、issueID は課題ID。
+// 出力: zip 内パス順に並んだエントリ一覧とエラー。
+// エラー: 走査・読み取りに失敗した場合、中断された場合に返す。添付ディレクトリが無い場合は空を返す。
+// 副作用: 添付ファイルを読み取る。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: 一時ファイル残骸 (*.tmp.*) は含めない。zip 内パスは "/" 区切り。
+// 関連DD: DD-BUNDLE-001, DD-DATA-005
+func collectAttachmentEntries(ctx context.Context, categoryPath, issueID string) ([]bundleEntry, error) {
+	dirName := issueID + ".files"
+	attachDir := filepath.Join(categoryPath, dirName)
+	if _, err := os.Stat(attachDir); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("stat attachments: %w", err)
+	}
+
+	var entries []bundleEntry
+	walkErr := filepath.WalkDir(attachDir, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if !entry.Type().IsRegular() || isTmpArtifact(entry.Name()) {
+			return nil
+		}
+		rel, relErr := filepath.Rel(attachDir, current)
+		if relErr != nil {
+			return fmt.Errorf("resolve attachment path: %w", relErr)
+		}
+		// #nosec G304 -- 添付ディレクトリの走査結果のみを読む。
+		data, readErr := os.ReadFile(current)
+		if readErr != nil {
+			return fmt.Errorf("read attachment: %w", readErr)
+		}
+		entries = append(entries, bundleEntry{
+			name: path.Join(dirName, filepath.ToSlash(rel)),
+			data: data,
+		})
+		return nil
+	})
+	if walkErr != nil {
+		return nil, fmt.Errorf("collect attachments: %w", walkErr)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	return entries, nil
+}
+
+// buildZip は DD-BUNDLE-001 の zip を生成する。
+// 目的: エントリを与えられた順序で deflate 圧縮した zip バイト列にする。
+// 入力: entries は書き込むエントリ一覧。
+// 出力: zip バイト列とエラー。
+// エラー: zip 書き込みに失敗した場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: エントリの更新日時は出力時刻で揃える。
+// 関連DD: DD-BUNDLE-001
+func buildZip(entries []bundleEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	modified := bundleNow()
+	for _, entry := range entries {
+		header := &zip.FileHeader{
+			Name:     entry.name,
+			Method:   zip.Deflate,
+			Modified: modified,
+		}
+		fileWriter, err := writer.CreateHeader(header)
+		if err != nil {
+			return nil, fmt.Errorf("create zip entry: %w", err)
+		}
+		if _, writeErr := fileWriter.Write(entry.data); writeErr != nil {
+			return nil, fmt.Errorf("write zip entry: %w", writeErr)
+		}
+	}
+	if closeErr := writer.Close(); closeErr != nil {
+		return nil, fmt.Errorf("close zip: %w", closeErr)
+	}
+	return buf.Bytes(), nil
+}
+
+// ImportIssueBundle は DD-BUNDLE-002 の課題バンドル取り込みを行う。
+// 目的: ExportIssueBundle が生成した zip を検証し、指定カテゴリへ課題と添付を復元する。
+// 入力: category は取り込み先カテゴリ名、srcPath はバンドル zip のパス、currentMode は操作モード。
+// 出力: 作成した IssueDetail とエラー。
+// エラー: 閲覧専用モード、カテゴリ権限で許されないモード、カテゴリ不存在、zip 読み取り失敗、manifest 不整合、ハッシュ不一致、スキーマ不整合、保存失敗時に返す。
+// 副作用: <issue_id>.files の作成と課題JSONの新規作成を行う。失敗時は作成した添付ディレクトリを削除する。
+// 並行性: 同一カテゴリへの同時取り込みは呼び出し側で排他する。
+// 不変条件: 既存課題と issue_id が衝突する場合は新しい issue_id を採番し、relative_path も追従させる。
+// 関連DD: DD-BUNDLE-002, DD-DATA-003, DD-DATA-005, DD-PERM-001
+func (s *Service) ImportIssueBundle(category, srcPath string, currentMode mod.Mode) (IssueDetail, error) {
+	if err := s.ensureCanWrite(category, currentMode); err != nil {
+		return IssueDetail{}, err
+	}
+	if err := s.ensureCategoryDir(category); err != nil {
+		return IssueDetail{}, err
+	}
+	if err := s.ensureNotArchived(category); err != nil {
+		return IssueDetail{}, err
+	}
+	manifest, files, err := readBundle(srcPath)
+	if err != nil {
+		return IssueDetail{}, err
+	}
+
+	issueData := files[manifest.IssueFile]
+	if s.validator != nil {
+		result, validateErr := s.validator.ValidateIssue(issueData)
+		if validateErr != nil {
+			return IssueDetail{}, fmt.Errorf("validate bundle issue: %w", validateErr)
+		}
+		if len(result.Issues) > 0 {
+			return IssueDetail{}, apperr.Errorf(apperr.ErrSchemaInvalid, "bundle issue schema invalid: %s", result.Detail())
+		}
+	}
+	var imported issue.Issue
+	if unmarshalErr := json.Unmarshal(issueData, &imported); unmarshalErr != nil {
+		return IssueDetail{}, fmt.Errorf("parse bundle issue: %w", unmarshalErr)
+	}
+	if !issue.IsSupportedVersion(imported.Version) || imported.IssueID != manifest.IssueID {
+		return IssueDetail{}, errors.New("bundle issue does not match manifest")
+	}
+
+	oldPrefix := manifest.IssueID + ".files/"
+	issueID, err := s.resolveImportIssueID(category, manifest.IssueID)
+	if err != nil {
+		return IssueDetail{}, err
+	}
+	newPrefix := issueID + ".files/"
+	imported.IssueID = issueID
+	imported.Category = category
+	for i := range imported.Comments {
+		for j := range imported.Comments[i].Attachments {
+			ref := &imported.Comments[i].Attachments[j]
+			ref.RelativePath = newPrefix + strings.TrimPrefix(ref.RelativePath, oldPrefix)
+		}
+	}
+	if errs := issue.ValidateIssue(imported); len(errs) > 0 {
+		return IssueDetail{}, errs
+	}
+
+	categoryPath := filepath.Join(s.projectRoot, category)
+	attachDir := filepath.Join(categoryPath, issueID+".files")
+	if restoreErr := restoreBundleAttachments(attachDir, oldPrefix, files); restoreErr != nil {
+		return IssueDetail{}, restoreErr
+	}
+
+	issuePath := filepath.Join(categoryPath, issueID+".json")
+	revision, writeErr := writeIssueFunc(s, issuePath, imported)
+	if writeErr != nil {
+		if removeErr := os.RemoveAll(attachDir); removeErr != nil {
+			return IssueDetail{}, fmt.Errorf("rollback attachments failed: %w; rollback error: %s", writeErr, removeErr.Error())
+		}
+		return IssueDetail{}, writeErr
+	}
+	return IssueDetail{Issue: imported, Path: issuePath, Revision: revision}, nil
+}
+
+// readBundle は DD-BUNDLE-002 の zip 読み込みと manifest 照合を行う。
+// 目的: zip 内容を manifest と突き合わせ、改ざんや欠落の無いファイル群だけを返す。
+// 入力: srcPath はバンドル zip のパス。
+// 出力: manifest、zip 内パスをキーとする内容マップ、エラー。
+// エラー: zip 読み取り失敗、manifest 不在・不正、未知エントリ、サイズやハッシュ不一致時に返す。
+// 副作用: zip ファイルを読み取る。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: 返却マップのキーは manifest.files と一致し、manifest 自身は含まない。
+// 関連DD: DD-BUNDLE-002
+func readBundle(srcPath string) (BundleManifest, map[string][]byte, error) {
+	if srcPath == "" {
+		return BundleManifest{}, nil, errors.New("bundle path is required")
+	}
+	reader, err := zip.OpenReader(srcPath)
+	if err != nil {
+		return BundleManifest{}, nil, fmt.Errorf("open bundle: %w", err)
+	}
+	entries, readErr := safezip.ReadFiles(reader.File, bundleZipLimits)
+	if closeErr := reader.Close(); closeErr != nil && readErr == nil {
+		readErr = fmt.Errorf("close bundle: %w", closeErr)
+	}
+	if readErr != nil {
+		return BundleManifest{}, nil, readErr
+	}
+
+	manifestData, ok := entries[bundleManifestName]
+	if !ok {
+		return BundleManifest{}, nil, apperr.New(apperr.ErrNotFound, "bundle manifest not found")
+	}
+	delete(entries, bundleManifestName)
+	var manifest BundleManifest
+	if unmarshalErr := json.Unmarshal(manifestData, &manifest); unmarshalErr != nil {
+		return BundleManifest{}, nil, fmt.Errorf("parse bundle manifest: %w", unmarshalErr)
+	}
+	if manifest.Kind != bundleKind || manifest.FormatVersion != bundleFormatVersion {
+		return BundleManifest{}, nil, errors.New("unsupported bundle format")
+	}
+	if manifest.IssueFile != manifest.IssueID+".json" {
+		return BundleManifest{}, nil, errors.New("bundle manifest issue_file mismatch")
+	}
+
+	listed := make(map[string]struct{}, len(manifest.Files))
+	for _, file := range manifest.Files {
+		data, exists := entries[file.Path]
+		if !exists {
+			return BundleManifest{}, nil, fmt.Errorf("bundle entry missing: %s", file.Path)
+		}
+		sum := sha256.Sum256(data)
+		if int64(len(data)) != file.SizeBytes || hex.EncodeToString(sum[:]) != file.SHA256 {
+			return BundleManifest{}, nil, fmt.Errorf("bundle entry checksum mismatch: %s", file.Path)
+		}
+		listed[file.Path] = struct{}{}
+	}
+	if _, exists := listed[manifest.IssueFile]; !exists {
+		return BundleManifest{}, nil, errors.New("bundle issue file not listed")
+	}
+	// manifest に無いエントリは出所が不明なため、黙って捨てずに取り込み自体を拒否する。
+	for name := range entries {
+		if _, exists := listed[name]; !exists {
+			return BundleManifest{}, nil, fmt.Errorf("bundle entry not listed in manifest: %s", name)
+		}
+	}
+	return manifest, entries, nil
+}
+
+// resolveImportIssueID は DD-BUNDLE-002 の issue_id 衝突回避を行う。
+// 目的: 取り込み先カテゴリで未使用の issue_id を決定する。
+// 入力: category はカテゴリ名、original はバンドル内の issue_id。
+// 出力: 採用する issue_id とエラー。
+// エラー: プロジェクト単位の設定の読み取り失敗、ID生成失敗や衝突回避の上限到達時に返す。
+// 副作用: なし。
+// 並行性: 判定から作成までの間に同名が作られることは想定しない。
+// 不変条件: 返却IDの <id>.json と <id>.files はいずれも存在しない。
+// 関連DD: DD-BUNDLE-002, DD-DATA-003
+func (s *Service) resolveImportIssueID(category, original string) (string, error) {
+	candidate := original
+	idFormat, err := s.idFormat()
+	if err != nil {
+		return "", err
+	}
+	// nanoid の衝突は極めて稀なため、再採番は少数回で打ち切る。
+	for attempt := 0; attempt < 10; attempt++ {
+		if !s.issueIDInUse(category, candidate) {
+			return candidate, nil
+		}
+		generated, err := newIssueID(idFormat)
+		if err != nil {
+			return "", fmt.Errorf("generate issue id: %w", err)
+		}
+		candidate = generated
+	}
+	return "", apperr.New(apperr.ErrConflict, "issue id conflict could not be resolved")
+}
+
+// issueIDInUse は DD-BUNDLE-002 の衝突判定を行う。
+func (s *Service) issueIDInUse(category, issueID string) bool {
+	base := filepath.Join(s.projectRoot, category, issueID)
+	if _, err := os.Stat(base + ".json"); err == nil {
+		return true
+	}
+	_, err := os.Stat(base + ".files")
+	return err == nil
+}
+
+// restoreBundleAttachments は DD-BUNDLE-002 の添付復元を行う。
+// 目的: バンドル内の <old_id>.files 配下を取り込み先の添付ディレクトリへ書き出す。
+// 入力: attachDir は作成する添付ディレクトリ、oldPrefix はバンドル内の添付パス接頭辞、files はバンドル内容。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: 入れ子パス、ディレクトリ作成、書き込みに失敗した場合に返す。
+// 副作用: 添付ディレクトリとファイルを作成する。失敗時は attachDir を削除する。
+// 並行性: 同一ディレクトリへの同時書き込みは想定しない。
+// 不変条件: 添付が無い場合はディレクトリを作成しない。
+// 関連DD: DD-BUNDLE-002, DD-DATA-005
+func restoreBundleAttachments(attachDir, oldPrefix string, files map[string][]byte) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if strings.HasPrefix(name, oldPrefix) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	if err := os.MkdirAll(attachDir, 0o750); err != nil {
+		return fmt.Errorf("create attachment dir: %w", err)
+	}
+	for _, name := range names {
+		storedName := strings.TrimPrefix(name, oldPrefix)
+		// DD-DATA-005 の relative_path は <issue_id>.files/<stored_name> の1階層のみを許容する。
+		if strings.Contains(storedName, "/") {
+			return cleanupAttachDir(attachDir, fmt.Errorf("nested attachment path is not allowed: %s", name))
+		}
+		if writeErr := atomicwrite.WriteFile(filepath.Join(attachDir, storedName), files[name]); writeErr != nil {
+			return cleanupAttachDir(attachDir, fmt.Errorf("write attachment: %w", writeErr))
+		}
+	}
+	return nil
+}
+
+// cleanupAttachDir は DD-BUNDLE-002 の失敗時ロールバックとして添付ディレクトリを削除する。
+func cleanupAttachDir(attachDir string, cause error) error {
+	if removeErr := os.RemoveAll(attachDir); removeErr != nil {
+		return fmt.Errorf("rollback attachments failed: %w; rollback error: %s", cause, removeErr.Error())
+	}
+	return cause
+}
+
+// isTmpArtifact は DD-PERSIST-004 の *.tmp.* 判定を行う。
+func isTmpArtifact(name string) bool {
+	matched, err := filepath.Match("*.tmp.*", name)
+	return err == nil && matched
+}
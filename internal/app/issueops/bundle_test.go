@@ -0,0 +1,307 @@
+// bundle_test.go は課題バンドル入出力のテストを行い、UI 統合は扱わない。
+package issueops
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/id"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/schema"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// newBundleTestService はバンドルテスト用にカテゴリ付きのサービスを準備する。
+func newBundleTestService(t *testing.T, category string) (*Service, string) {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, category), 0o750); err != nil {
+		t.Fatalf("mkdir category: %v", err)
+	}
+	validator, err := schema.NewValidatorFromDir(filepath.Join("..", "..", "..", "schemas"))
+	if err != nil {
+		t.Fatalf("NewValidatorFromDir error: %v", err)
+	}
+	return NewService(root, validator), root
+}
+
+// readZipEntries は zip の全エントリを名前→内容のマップで返す。
+func readZipEntries(t *testing.T, path string) map[string][]byte {
+	t.Helper()
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	defer func() {
+		if closeErr := reader.Close(); closeErr != nil {
+			t.Fatalf("close zip: %v", closeErr)
+		}
+	}()
+	entries := make(map[string][]byte)
+	for _, file := range reader.File {
+		rc, openErr := file.Open()
+		if openErr != nil {
+			t.Fatalf("open entry: %v", openErr)
+		}
+		data, readErr := io.ReadAll(rc)
+		if closeErr := rc.Close(); closeErr != nil {
+			t.Fatalf("close entry: %v", closeErr)
+		}
+		if readErr != nil {
+			t.Fatalf("read entry: %v", readErr)
+		}
+		entries[file.Name] = data
+	}
+	return entries
+}
+
+func TestExportIssueBundle_IncludesIssueAttachmentsAndManifest(t *testing.T) {
+	// 課題JSON・添付・manifest がすべて zip に含まれ、manifest が全ファイルを列挙することを確認する。
+	category := "cat"
+	service, root := newBundleTestService(t, category)
+	created, err := service.CreateIssue(category, mod.ModeVendor, IssueCreateInput{
+		Title:       "title",
+		Description: "desc",
+		DueDate:     "2024-01-01",
+		Priority:    issue.PriorityHigh,
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	issueID := created.Issue.IssueID
+	if _, commentErr := service.AddComment(category, issueID, mod.ModeVendor, CommentCreateInput{
+		Body:       "body",
+		AuthorName: "author",
+		Attachments: []CommentAttachmentInput{
+			{OriginalName: "note.txt", Data: []byte("hello")},
+		},
+	}); commentErr != nil {
+		t.Fatalf("AddComment error: %v", commentErr)
+	}
+	// 一時ファイル残骸はバンドルに含めないことも同時に確認する。
+	residue := filepath.Join(root, category, issueID+".files", "x.txt.tmp.1.1")
+	if writeErr := os.WriteFile(residue, []byte("tmp"), 0o600); writeErr != nil {
+		t.Fatalf("write residue: %v", writeErr)
+	}
+
+	dest := filepath.Join(t.TempDir(), "bundle.zip")
+	result, err := service.ExportIssueBundle(category, issueID, dest)
+	if err != nil {
+		t.Fatalf("ExportIssueBundle error: %v", err)
+	}
+	// 前提: 課題JSON 1件 + 添付 1件。
+	if result.FileCount != 2 {
+		t.Fatalf("unexpected file count: %d", result.FileCount)
+	}
+
+	entries := readZipEntries(t, dest)
+	if _, ok := entries[issueID+".json"]; !ok {
+		t.Fatal("expected issue json entry")
+	}
+	var manifest BundleManifest
+	if unmarshalErr := json.Unmarshal(entries[bundleManifestName], &manifest); unmarshalErr != nil {
+		t.Fatalf("parse manifest: %v", unmarshalErr)
+	}
+	if manifest.Kind != bundleKind || manifest.IssueID != issueID || manifest.Category != category {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+	if len(manifest.Files) != 2 {
+		t.Fatalf("unexpected manifest files: %+v", manifest.Files)
+	}
+	for _, file := range manifest.Files {
+		data, ok := entries[file.Path]
+		if !ok {
+			t.Fatalf("manifest entry missing in zip: %s", file.Path)
+		}
+		if int64(len(data)) != file.SizeBytes {
+			t.Fatalf("size mismatch for %s", file.Path)
+		}
+	}
+}
+
+func TestExportIssueBundle_RejectsMissingDestination(t *testing.T) {
+	// 出力先が未指定の場合は課題を読む前にエラーとなることを確認する。
+	service, _ := newBundleTestService(t, "cat")
+	if _, err := service.ExportIssueBundle("cat", "abc123DEF", ""); err == nil {
+		t.Fatal("expected destination error")
+	}
+}
+
+func TestExportIssueBundle_RejectsSchemaInvalid(t *testing.T) {
+	// スキーマ不整合の課題は取り込み側で検証に失敗するため、出力段階で拒否することを確認する。
+	service, root := newBundleTestService(t, "cat")
+	path := filepath.Join(root, "cat", "abc123DEF.json")
+	if err := os.WriteFile(path, []byte(`{"issue_id":"abc123DEF"}`), 0o600); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	dest := filepath.Join(t.TempDir(), "bundle.zip")
+	if _, err := service.ExportIssueBundle("cat", "abc123DEF", dest); err == nil {
+		t.Fatal("expected schema invalid error")
+	}
+	if _, statErr := os.Stat(dest); !os.IsNotExist(statErr) {
+		t.Fatalf("expected no bundle output, err=%v", statErr)
+	}
+}
+
+// exportTestBundle は添付付き課題を作成してバンドルを出力し、課題IDと zip パスを返す。
+func exportTestBundle(t *testing.T, service *Service, category string) (string, string) {
+	t.Helper()
+	created, err := service.CreateIssue(category, mod.ModeContractor, IssueCreateInput{
+		Title:       "title",
+		Description: "desc",
+		DueDate:     "2024-01-01",
+		Priority:    issue.PriorityMedium,
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	issueID := created.Issue.IssueID
+	if _, commentErr := service.AddComment(category, issueID, mod.ModeContractor, CommentCreateInput{
+		Body:       "body",
+		AuthorName: "author",
+		Attachments: []CommentAttachmentInput{
+			{OriginalName: "note.txt", Data: []byte("hello")},
+		},
+	}); commentErr != nil {
+		t.Fatalf("AddComment error: %v", commentErr)
+	}
+	dest := filepath.Join(t.TempDir(), "bundle.zip")
+	if _, exportErr := service.ExportIssueBundle(category, issueID, dest); exportErr != nil {
+		t.Fatalf("ExportIssueBundle error: %v", exportErr)
+	}
+	return issueID, dest
+}
+
+func TestImportIssueBundle_RestoresIntoOtherCategory(t *testing.T) {
+	// 別カテゴリへ取り込むと ID を維持したまま課題と添付が復元され、category が取り込み先になることを確認する。
+	service, root := newBundleTestService(t, "src")
+	if err := os.MkdirAll(filepath.Join(root, "dst"), 0o750); err != nil {
+		t.Fatalf("mkdir dst: %v", err)
+	}
+	issueID, bundlePath := exportTestBundle(t, service, "src")
+
+	detail, err := service.ImportIssueBundle("dst", bundlePath, mod.ModeVendor)
+	if err != nil {
+		t.Fatalf("ImportIssueBundle error: %v", err)
+	}
+	if detail.Issue.IssueID != issueID {
+		t.Fatalf("expected issue id to be kept, got %s", detail.Issue.IssueID)
+	}
+	if detail.Issue.Category != "dst" {
+		t.Fatalf("unexpected category: %s", detail.Issue.Category)
+	}
+	ref := detail.Issue.Comments[0].Attachments[0]
+	data, err := os.ReadFile(filepath.Join(root, "dst", filepath.FromSlash(ref.RelativePath)))
+	if err != nil {
+		t.Fatalf("read restored attachment: %v", err)
+	}
+	if string(data) != "hello" {
+		t.Fatalf("unexpected attachment content: %s", string(data))
+	}
+	reloaded, err := service.GetIssue("dst", issueID)
+	if err != nil {
+		t.Fatalf("GetIssue error: %v", err)
+	}
+	if reloaded.IsSchemaInvalid {
+		t.Fatal("expected imported issue to be schema valid")
+	}
+}
+
+func TestImportIssueBundle_RegeneratesIDOnCollision(t *testing.T) {
+	// 同一カテゴリに同じ issue_id がある場合は再採番し、relative_path も新IDへ追従することを確認する。
+	service, root := newBundleTestService(t, "cat")
+	issueID, bundlePath := exportTestBundle(t, service, "cat")
+
+	previous := newIssueID
+	newIssueID = func(id.Format) (string, error) { return "NEWid_123", nil }
+	t.Cleanup(func() { newIssueID = previous })
+
+	detail, err := service.ImportIssueBundle("cat", bundlePath, mod.ModeVendor)
+	if err != nil {
+		t.Fatalf("ImportIssueBundle error: %v", err)
+	}
+	if detail.Issue.IssueID != "NEWid_123" || detail.Issue.IssueID == issueID {
+		t.Fatalf("expected regenerated id, got %s", detail.Issue.IssueID)
+	}
+	ref := detail.Issue.Comments[0].Attachments[0]
+	if filepath.Dir(filepath.FromSlash(ref.RelativePath)) != "NEWid_123.files" {
+		t.Fatalf("unexpected relative path: %s", ref.RelativePath)
+	}
+	if _, statErr := os.Stat(filepath.Join(root, "cat", filepath.FromSlash(ref.RelativePath))); statErr != nil {
+		t.Fatalf("expected restored attachment, err=%v", statErr)
+	}
+}
+
+func TestImportIssueBundle_RejectsTamperedEntry(t *testing.T) {
+	// manifest のハッシュと内容が一致しない zip は取り込まず、課題も作成しないことを確認する。
+	service, root := newBundleTestService(t, "src")
+	if err := os.MkdirAll(filepath.Join(root, "dst"), 0o750); err != nil {
+		t.Fatalf("mkdir dst: %v", err)
+	}
+	issueID, bundlePath := exportTestBundle(t, service, "src")
+	entries := readZipEntries(t, bundlePath)
+	attachmentName := ""
+	for name := range entries {
+		if filepath.Dir(filepath.FromSlash(name)) == issueID+".files" {
+			attachmentName = name
+		}
+	}
+	entries[attachmentName] = []byte("tampered")
+
+	tampered := filepath.Join(t.TempDir(), "tampered.zip")
+	ordered := make([]bundleEntry, 0, len(entries))
+	for name, data := range entries {
+		ordered = append(ordered, bundleEntry{name: name, data: data})
+	}
+	archive, err := buildZip(ordered)
+	if err != nil {
+		t.Fatalf("buildZip error: %v", err)
+	}
+	if writeErr := os.WriteFile(tampered, archive, 0o600); writeErr != nil {
+		t.Fatalf("write tampered: %v", writeErr)
+	}
+
+	if _, importErr := service.ImportIssueBundle("dst", tampered, mod.ModeVendor); importErr == nil {
+		t.Fatal("expected checksum error")
+	}
+	if _, statErr := os.Stat(filepath.Join(root, "dst", issueID+".json")); !os.IsNotExist(statErr) {
+		t.Fatalf("expected no issue to be created, err=%v", statErr)
+	}
+}
+
+func TestReadBundle_RejectsUnsafePath(t *testing.T) {
+	// 親ディレクトリ参照を含むエントリ (zip slip) を拒否することを確認する。
+	archive, err := buildZip([]bundleEntry{{name: "../evil.txt", data: []byte("x")}})
+	if err != nil {
+		t.Fatalf("buildZip error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "evil.zip")
+	if writeErr := os.WriteFile(path, archive, 0o600); writeErr != nil {
+		t.Fatalf("write zip: %v", writeErr)
+	}
+	if _, _, readErr := readBundle(path); readErr == nil {
+		t.Fatal("expected unsafe path error")
+	}
+}
+
+func TestReadBundle_RejectsOversizedEntry(t *testing.T) {
+	// 添付1件の上限を超えるエントリは manifest の照合より前に、上限超過の入力エラーとして拒否することを確認する。
+	archive, err := buildZip([]bundleEntry{{name: "a.json", data: make([]byte, maxAttachmentBytes+1)}})
+	if err != nil {
+		t.Fatalf("buildZip error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "big.zip")
+	if writeErr := os.WriteFile(path, archive, 0o600); writeErr != nil {
+		t.Fatalf("write zip: %v", writeErr)
+	}
+	if _, _, readErr := readBundle(path); !errors.Is(readErr, apperr.ErrValidation) {
+		t.Fatalf("expected validation error, got %v", readErr)
+	}
+}
@@ -0,0 +1,186 @@
+// changes.go は指定時刻以降の課題・カテゴリの変更差分の取得を担い、UI の再描画や監視は扱わない。
+// 作成・更新は mtime で、削除は索引に記録した削除の検知時刻で判定する。
+package issueops
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"ratta/internal/app/categoryscan"
+	"ratta/internal/infra/issueindex"
+)
+
+// ChangeOp は DD-CHANGES-001 の変更内容を表す。
+type ChangeOp string
+
+const (
+	ChangeCreated ChangeOp = "created"
+	ChangeUpdated ChangeOp = "updated"
+	ChangeRemoved ChangeOp = "removed"
+)
+
+// changesMargin は DD-CHANGES-001 の Until を現在時刻より遡らせる幅を表す。
+// ファイルシステムの mtime は時計より粗い粒度で記録され、共有フォルダではサーバとの時計のずれもあるため、
+// 取得直後に書かれた課題の mtime が Until より前になり次回に取りこぼすことを防ぐ。
+const changesMargin = 2 * time.Second
+
+// changesNow は DD-CHANGES-001 の取得時刻をテストで固定するための差し替え点。
+var changesNow = time.Now
+
+// CategoryChange は DD-CHANGES-001 のカテゴリの変更1件を表す。
+type CategoryChange struct {
+	Name string
+	Op   ChangeOp
+}
+
+// IssueChange は DD-CHANGES-001 の課題の変更1件を表す。削除の場合 Summary は nil とする。
+type IssueChange struct {
+	Op       ChangeOp
+	Category string
+	IssueID  string
+	Summary  *IssueSummary
+}
+
+// Changes は DD-CHANGES-001 の変更差分を表す。Until は次回の取得で since に渡す時刻とする。
+type Changes struct {
+	Since      time.Time
+	Until      time.Time
+	Categories []CategoryChange
+	Issues     []IssueChange
+}
+
+// ChangesSince は DD-CHANGES-001 の変更差分の取得を行う。
+// 目的: 定期的な再読込で全件を読み直さず、since 以降に変わった課題とカテゴリだけを返す。
+// 入力: since は前回取得時の Until。ゼロ値の場合は現存する全件を変更として返す。
+// 出力: Changes とエラー。
+// エラー: プロジェクトルートの走査失敗時に返す。個別カテゴリの読み取り失敗は結果から除く。
+// 副作用: 索引 (.ratta/index.json) を最新化し、検知した削除を記録する。
+// 並行性: 索引の同時更新は後勝ちとなるが、次回参照時の鮮度判定で自己修復する。
+// 不変条件: 取得中の変更を取りこぼさないよう、Until は走査開始前の時刻から changesMargin だけ遡らせる。
+// このため同じ変更が次回も返ることがあり、呼び出し側は重複を許容する。
+// 関連DD: DD-CHANGES-001, DD-INDEX-001, DD-LOAD-002
+func (s *Service) ChangesSince(since time.Time) (Changes, error) {
+	until := changesNow().Add(-changesMargin)
+	scanned, err := categoryscan.Scan(s.projectRoot)
+	if err != nil {
+		return Changes{}, err
+	}
+
+	index := issueindex.Open(s.projectRoot).WithConcurrency(s.concurrency)
+	known := make(map[string]bool)
+	for _, name := range index.Categories() {
+		known[name] = true
+	}
+	changes := Changes{Since: since, Until: until, Categories: []CategoryChange{}, Issues: []IssueChange{}}
+	existing := make(map[string]bool, len(scanned.Categories))
+	changedIssues := make(map[string]bool)
+	for _, category := range scanned.Categories {
+		existing[category.Name] = true
+		if info, statErr := os.Stat(category.Path); statErr == nil && info.ModTime().After(since) {
+			op := ChangeUpdated
+			if !known[category.Name] {
+				op = ChangeCreated
+			}
+			changes.Categories = append(changes.Categories, CategoryChange{Name: category.Name, Op: op})
+		}
+
+		entries, categoryErr := index.Category(category.Path, category.Name, s.indexLoader())
+		if categoryErr != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.ModTime <= since.UnixNano() {
+				continue
+			}
+			summary := toIssueSummary(category.Path, category.Name, entry)
+			changes.Issues = append(changes.Issues, IssueChange{
+				Op:       issueChangeOp(summary.Path, since),
+				Category: category.Name,
+				IssueID:  summary.IssueID,
+				Summary:  &summary,
+			})
+			changedIssues[issueKey(category.Name, summary.IssueID)] = true
+		}
+	}
+
+	// 外部で削除されたカテゴリは索引に残り続けるため、ここで破棄して削除として記録する。
+	for name := range known {
+		if !existing[name] {
+			_ = index.DropCategory(name)
+		}
+	}
+	for _, removal := range index.RemovedSince(since.UnixNano()) {
+		if removal.FileName == "" {
+			// 名前変更で元に戻った場合など、現存するカテゴリは削除として返さない。
+			if !existing[removal.Category] {
+				changes.Categories = appendCategoryRemoval(changes.Categories, removal.Category)
+			}
+			continue
+		}
+		issueID := strings.TrimSuffix(removal.FileName, ".json")
+		key := issueKey(removal.Category, issueID)
+		if changedIssues[key] {
+			continue
+		}
+		changedIssues[key] = true
+		changes.Issues = append(changes.Issues, IssueChange{Op: ChangeRemoved, Category: removal.Category, IssueID: issueID})
+	}
+
+	sort.Slice(changes.Categories, func(i, j int) bool { return changes.Categories[i].Name < changes.Categories[j].Name })
+	sort.SliceStable(changes.Issues, func(i, j int) bool {
+		if changes.Issues[i].Category != changes.Issues[j].Category {
+			return changes.Issues[i].Category < changes.Issues[j].Category
+		}
+		return changes.Issues[i].IssueID < changes.Issues[j].IssueID
+	})
+	return changes, nil
+}
+
+// appendCategoryRemoval は DD-CHANGES-001 のカテゴリ削除を重複なく追加する。
+func appendCategoryRemoval(changes []CategoryChange, name string) []CategoryChange {
+	for _, change := range changes {
+		if change.Name == name && change.Op == ChangeRemoved {
+			return changes
+		}
+	}
+	return append(changes, CategoryChange{Name: name, Op: ChangeRemoved})
+}
+
+// issueChangeOp は DD-CHANGES-001 の課題の変更が作成か更新かを created_at で判定する。
+// created_at は秒精度のため、since を秒単位に切り捨てて比較し、作成を更新と取り違えないようにする。
+// 読み取れない場合は更新として扱う。
+func issueChangeOp(path string, since time.Time) ChangeOp {
+	createdAt, err := readCreatedAt(path)
+	if err != nil || createdAt.Before(since.Truncate(time.Second)) {
+		return ChangeUpdated
+	}
+	return ChangeCreated
+}
+
+// readCreatedAt は DD-CHANGES-001 の課題JSONから created_at のみを読み取る。
+func readCreatedAt(path string) (time.Time, error) {
+	data, _, err := readIssueFile(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var header struct {
+		CreatedAt string `json:"created_at"`
+	}
+	if unmarshalErr := json.Unmarshal(data, &header); unmarshalErr != nil {
+		return time.Time{}, fmt.Errorf("parse issue: %w", unmarshalErr)
+	}
+	createdAt, err := time.Parse(time.RFC3339, header.CreatedAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse created_at: %w", err)
+	}
+	return createdAt, nil
+}
+
+// issueKey は DD-CHANGES-001 のカテゴリと課題IDの組を一意なキーにする。
+func issueKey(category, issueID string) string {
+	return category + "/" + issueID
+}
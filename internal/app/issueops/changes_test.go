@@ -0,0 +1,124 @@
+// changes_test.go は変更差分の取得のテストを行い、索引の保存形式は扱わない。
+package issueops
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ratta/internal/domain/issue"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// backdateIssue は課題JSONの created_at と mtime を指定時刻へ書き換える。
+func backdateIssue(t *testing.T, path string, at time.Time) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var value map[string]any
+	if unmarshalErr := json.Unmarshal(data, &value); unmarshalErr != nil {
+		t.Fatalf("unmarshal: %v", unmarshalErr)
+	}
+	value["created_at"] = at.Format(time.RFC3339)
+	data, err = json.Marshal(value)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if writeErr := os.WriteFile(path, data, 0o600); writeErr != nil {
+		t.Fatalf("write: %v", writeErr)
+	}
+	if chtimesErr := os.Chtimes(path, at, at); chtimesErr != nil {
+		t.Fatalf("chtimes: %v", chtimesErr)
+	}
+}
+
+func TestChangesSince_ReportsCreatedUpdatedAndRemoved(t *testing.T) {
+	// 前回の取得以降に作成・更新・削除された課題と、作成・削除されたカテゴリのみが返ることを確認する。
+	root := t.TempDir()
+	for _, category := range []string{"keep", "gone"} {
+		if err := os.MkdirAll(filepath.Join(root, category), 0o750); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	service := NewService(root, nil)
+	create := func(category, title string) string {
+		t.Helper()
+		created, err := service.CreateIssue(category, mod.ModeContractor, IssueCreateInput{
+			Title:       title,
+			Description: "desc",
+			DueDate:     "2024-01-01",
+			Priority:    issue.PriorityHigh,
+		})
+		if err != nil {
+			t.Fatalf("CreateIssue error: %v", err)
+		}
+		return created.Issue.IssueID
+	}
+	updatedID := create("keep", "updated")
+	removedID := create("keep", "removed")
+	untouchedID := create("keep", "untouched")
+	// 前回の取得より十分前に作成された状態にするため、created_at と mtime を1時間前へ戻す。
+	past := time.Now().Add(-time.Hour)
+	for _, issueID := range []string{updatedID, removedID, untouchedID} {
+		backdateIssue(t, filepath.Join(root, "keep", issueID+".json"), past)
+	}
+
+	first, err := service.ChangesSince(time.Time{})
+	if err != nil {
+		t.Fatalf("ChangesSince error: %v", err)
+	}
+	if len(first.Issues) != 3 || len(first.Categories) != 2 {
+		t.Fatalf("expected full listing on first call, got %+v", first)
+	}
+
+	since := first.Until
+	if _, err := service.UpdateIssue("keep", updatedID, mod.ModeContractor, IssueUpdateInput{
+		Title:       "updated2",
+		Description: "desc",
+		DueDate:     "2024-01-01",
+		Priority:    issue.PriorityHigh,
+		Status:      issue.StatusOpen,
+	}); err != nil {
+		t.Fatalf("UpdateIssue error: %v", err)
+	}
+	if err := os.Remove(filepath.Join(root, "keep", removedID+".json")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	createdID := create("keep", "created")
+	if err := os.RemoveAll(filepath.Join(root, "gone")); err != nil {
+		t.Fatalf("remove category: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "fresh"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	second, err := service.ChangesSince(since)
+	if err != nil {
+		t.Fatalf("ChangesSince error: %v", err)
+	}
+	ops := map[string]ChangeOp{}
+	for _, change := range second.Issues {
+		ops[change.IssueID] = change.Op
+		if change.Op == ChangeRemoved && change.Summary != nil {
+			t.Fatalf("removed change must not carry a summary: %+v", change)
+		}
+	}
+	if ops[updatedID] != ChangeUpdated || ops[removedID] != ChangeRemoved || ops[createdID] != ChangeCreated {
+		t.Fatalf("unexpected issue changes: %+v", second.Issues)
+	}
+	if _, ok := ops[untouchedID]; ok {
+		t.Fatalf("untouched issue must not be reported: %+v", second.Issues)
+	}
+	categories := map[string]ChangeOp{}
+	for _, change := range second.Categories {
+		categories[change.Name] = change.Op
+	}
+	if categories["gone"] != ChangeRemoved || categories["fresh"] != ChangeCreated || categories["keep"] != ChangeUpdated {
+		t.Fatalf("unexpected category changes: %+v", second.Categories)
+	}
+}
@@ -0,0 +1,103 @@
+// cursor.go は課題一覧のカーソル方式のページングを担い、絞り込みや並び替えの規則そのものは扱わない。
+// ページ番号方式は取得の間に課題が増減すると重複・欠落が起きるため、直前の項目の位置から続きを返す。
+package issueops
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// errInvalidCursor は DD-BE-003 の解釈できないカーソルを表す。
+var errInvalidCursor = errors.New("invalid cursor")
+
+// listCursor は DD-BE-003 の一覧カーソルの内容を表す。
+// 並び順が異なる一覧へ流用されないよう、発行時の sort_by/sort_order も保持する。
+type listCursor struct {
+	SortBy    string `json:"s"`
+	SortOrder string `json:"o"`
+	Key       string `json:"k"`
+	IssueID   string `json:"i"`
+}
+
+// encodeCursor は DD-BE-003 の一覧項目の位置を表す不透明なカーソル文字列を生成する。
+func encodeCursor(sortBy, sortOrder string, item IssueSummary) string {
+	data, err := json.Marshal(listCursor{
+		SortBy:    sortBy,
+		SortOrder: normalizeSortOrder(sortOrder),
+		Key:       sortKey(item, sortBy),
+		IssueID:   item.IssueID,
+	})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor は DD-BE-003 のカーソル文字列を解釈し、一覧条件の並び順と一致することを確認する。
+func decodeCursor(value string, query IssueListQuery) (listCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return listCursor{}, errInvalidCursor
+	}
+	var cursor listCursor
+	if unmarshalErr := json.Unmarshal(data, &cursor); unmarshalErr != nil || cursor.IssueID == "" {
+		return listCursor{}, errInvalidCursor
+	}
+	if cursor.SortBy != query.SortBy || cursor.SortOrder != normalizeSortOrder(query.SortOrder) {
+		return listCursor{}, errors.New("cursor does not match sort order")
+	}
+	return cursor, nil
+}
+
+// isAfterCursor は DD-BE-003 の並び順で item がカーソル位置より後ろにあるかを判定する。
+// 同値の場合は applySort と同じく sort_order に関わらず issue_id 昇順で比較する。
+func isAfterCursor(item IssueSummary, cursor listCursor) bool {
+	compared := compareSortKey(cursor.SortBy, sortKey(item, cursor.SortBy), cursor.Key)
+	if cursor.SortOrder == "desc" {
+		compared = -compared
+	}
+	if compared != 0 {
+		return compared > 0
+	}
+	return item.IssueID > cursor.IssueID
+}
+
+// sortKey は DD-BE-003 の sort_by に対応する項目の値を返す。
+func sortKey(item IssueSummary, sortBy string) string {
+	switch sortBy {
+	case "updated_at":
+		return item.UpdatedAt
+	case "due_date":
+		return item.DueDate
+	case "priority":
+		return item.Priority
+	case "status":
+		return item.Status
+	case "title":
+		return item.Title
+	default:
+		return item.IssueID
+	}
+}
+
+// compareSortKey は DD-BE-003 の sort_by に従って2つの値を比較する。優先度とステータスは定義順で比較する。
+func compareSortKey(sortBy, left, right string) int {
+	switch sortBy {
+	case "priority":
+		return priorityRank(left) - priorityRank(right)
+	case "status":
+		return statusRank(left) - statusRank(right)
+	default:
+		return strings.Compare(left, right)
+	}
+}
+
+// normalizeSortOrder は DD-BE-003 の sort_order を asc/desc のいずれかに揃える。
+func normalizeSortOrder(sortOrder string) string {
+	if sortOrder == "desc" {
+		return "desc"
+	}
+	return "asc"
+}
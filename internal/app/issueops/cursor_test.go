@@ -0,0 +1,115 @@
+// cursor_test.go は課題一覧のカーソル方式のページングのテストを行い、並び替え規則そのものは扱わない。
+package issueops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ratta/internal/domain/issue"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// collectPages はカーソルを辿って一覧の全ページを取得し、課題IDを順に返す。
+// 各ページの取得後に between を呼び、ページ間の変更を再現できるようにする。
+func collectPages(t *testing.T, service *Service, query IssueListQuery, between func(page int)) []string {
+	t.Helper()
+	var ids []string
+	for page := 0; ; page++ {
+		list, err := service.ListIssues("cat", query)
+		if err != nil {
+			t.Fatalf("ListIssues error: %v", err)
+		}
+		for _, item := range list.Issues {
+			ids = append(ids, item.IssueID)
+		}
+		if list.NextCursor == "" {
+			return ids
+		}
+		if page > 10 {
+			t.Fatalf("cursor did not terminate: %v", ids)
+		}
+		between(page)
+		query.Cursor = list.NextCursor
+	}
+}
+
+func TestListIssues_CursorPagesStablyAcrossInsertions(t *testing.T) {
+	// ページ間で前方に課題が追加されても、カーソルで辿ると取得済みの課題を重複・欠落なく返すことを確認する。
+	// SQLite キャッシュ経由でも同じ結果になることも確認する。
+	for _, useCache := range []bool{false, true} {
+		root := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(root, "cat"), 0o750); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		service := NewService(root, nil)
+		if useCache {
+			if err := service.SetCacheEnabled(true, mod.ModeContractor); err != nil {
+				t.Fatalf("SetCacheEnabled error: %v", err)
+			}
+		}
+		priorities := []issue.Priority{issue.PriorityLow, issue.PriorityHigh, issue.PriorityMedium, issue.PriorityHigh, issue.PriorityLow}
+		for _, priority := range priorities {
+			if _, err := service.CreateIssue("cat", mod.ModeContractor, IssueCreateInput{
+				Title:       "t",
+				Description: "desc",
+				DueDate:     "2024-01-01",
+				Priority:    priority,
+			}); err != nil {
+				t.Fatalf("CreateIssue error: %v", err)
+			}
+		}
+		query := IssueListQuery{PageSize: 2, SortBy: "priority", SortOrder: "asc"}
+		before, err := service.ListIssues("cat", IssueListQuery{PageSize: 100, SortBy: "priority", SortOrder: "asc"})
+		if err != nil {
+			t.Fatalf("ListIssues error: %v", err)
+		}
+
+		ids := collectPages(t, service, query, func(int) {
+			// 先頭ページに入る High の課題を追加し、ページ番号方式であればずれが生じる状況を作る。
+			if _, err := service.CreateIssue("cat", mod.ModeContractor, IssueCreateInput{
+				Title:       "late",
+				Description: "desc",
+				DueDate:     "2024-01-01",
+				Priority:    issue.PriorityHigh,
+			}); err != nil {
+				t.Fatalf("CreateIssue error: %v", err)
+			}
+		})
+		seen := map[string]int{}
+		for _, id := range ids {
+			seen[id]++
+		}
+		for _, item := range before.Issues {
+			if seen[item.IssueID] != 1 {
+				t.Fatalf("cache=%v: issue %s seen %d times in %v", useCache, item.IssueID, seen[item.IssueID], ids)
+			}
+		}
+	}
+}
+
+func TestQueryIssues_RejectsInvalidOrMismatchedCursor(t *testing.T) {
+	// 解釈できないカーソルや、発行時と並び順が異なるカーソルはエラーになることを確認する。
+	items := []IssueSummary{{IssueID: "a", Title: "x"}, {IssueID: "b", Title: "y"}}
+	first, err := QueryIssues("cat", items, IssueListQuery{PageSize: 1, SortBy: "title"})
+	if err != nil {
+		t.Fatalf("QueryIssues error: %v", err)
+	}
+	if first.NextCursor == "" {
+		t.Fatalf("expected next cursor")
+	}
+	if _, err := QueryIssues("cat", items, IssueListQuery{SortBy: "title", Cursor: "%%%"}); err == nil {
+		t.Fatalf("expected invalid cursor error")
+	}
+	if _, err := QueryIssues("cat", items, IssueListQuery{SortBy: "title", SortOrder: "desc", Cursor: first.NextCursor}); err == nil {
+		t.Fatalf("expected mismatched cursor error")
+	}
+	next, err := QueryIssues("cat", items, IssueListQuery{PageSize: 1, SortBy: "title", Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("QueryIssues error: %v", err)
+	}
+	if len(next.Issues) != 1 || next.Issues[0].IssueID != "b" || next.NextCursor != "" || next.Page != 0 {
+		t.Fatalf("unexpected next page: %+v", next)
+	}
+}
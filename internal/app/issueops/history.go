@@ -0,0 +1,104 @@
+// history.go は git リポジトリのコミット履歴から課題JSONの各版を読み出して課題へ変換する処理を担い、
+// 履歴のコミットは扱わない。
+package issueops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/gitcommit"
+)
+
+// IssueRevision は DD-GIT-002 の課題JSONを変更した1つのコミットと、その時点の課題を表す。
+// Operation・Actor・Mode は DD-GIT-001 の自動コミットのメッセージから復元した値で、手作業のコミットでは空とする。
+// Deleted はそのコミットで課題JSONが削除されたことを表し、その場合 Snapshot は空とする。
+// ParseError は課題JSONとして解析できなかった場合の理由を表し、その場合 Snapshot は空とする。
+type IssueRevision struct {
+	Commit      string
+	AuthorName  string
+	AuthorEmail string
+	CommittedAt time.Time
+	Subject     string
+	Operation   string
+	Actor       string
+	Mode        string
+	Deleted     bool
+	ParseError  string
+	Snapshot    *IssueDetail
+}
+
+// IssueHistory は DD-GIT-002 の課題JSONのコミット履歴を新しい順に返す。
+// 目的: プロジェクトルートが git リポジトリに含まれる場合に、課題がどのように変化してきたかを確認できるようにする。
+// 入力: ctx は中断用、category はカテゴリ名、issueID は課題ID、limit は返す件数の上限 (0 以下は無制限)。
+// 出力: IssueRevision の一覧とエラー。
+// エラー: カテゴリ名・課題IDが不正な場合は ErrValidation、プロジェクトルートが git リポジトリに含まれない場合は ErrNotFound、
+// 履歴の読み出しに失敗した場合と中断した場合に返す。
+// 副作用: なし。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: 各版はスキーマで検証して IsSchemaInvalid を付与し、解析できない版も一覧から除かず ParseError を設定する。
+// Snapshot の Category は入力 category に上書きする。
+// 関連DD: DD-GIT-002, DD-LOAD-004
+func (s *Service) IssueHistory(ctx context.Context, category, issueID string, limit int) ([]IssueRevision, error) {
+	if errs := issue.ValidateCategoryName(category); len(errs) > 0 {
+		return nil, errs
+	}
+	if issueID == "" || strings.ContainsAny(issueID, `/\`) || strings.Contains(issueID, "..") {
+		return nil, apperr.Errorf(apperr.ErrValidation, "invalid issue id: %q", issueID)
+	}
+	revisions, err := gitcommit.FileHistory(ctx, s.projectRoot, category+"/"+issueID+".json", limit)
+	if errors.Is(err, gitcommit.ErrNotRepository) {
+		return nil, apperr.New(apperr.ErrNotFound, "project root is not in a git repository")
+	}
+	if err != nil {
+		return nil, err
+	}
+	history := make([]IssueRevision, 0, len(revisions))
+	for _, revision := range revisions {
+		item := IssueRevision{
+			Commit:      revision.Hash,
+			AuthorName:  revision.AuthorName,
+			AuthorEmail: revision.AuthorEmail,
+			CommittedAt: revision.When,
+			Subject:     revision.Subject,
+			Operation:   revision.Change.Operation,
+			Actor:       revision.Change.Actor,
+			Mode:        revision.Change.Mode,
+			Deleted:     revision.Deleted,
+		}
+		if !revision.Deleted {
+			snapshot, parseErr := s.parseSnapshot(revision.Data, category)
+			if parseErr != nil {
+				item.ParseError = parseErr.Error()
+			} else {
+				item.Snapshot = &snapshot
+			}
+		}
+		history = append(history, item)
+	}
+	return history, nil
+}
+
+// parseSnapshot は DD-GIT-002 の過去の版の課題JSONを解析し、readIssue と同じ規則で IsSchemaInvalid を付与する。
+// 過去の版はファイルとして存在しないため、検証結果の再利用は行わない。
+func (s *Service) parseSnapshot(data []byte, category string) (IssueDetail, error) {
+	var parsed issue.Issue
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return IssueDetail{}, fmt.Errorf("parse issue: %w", err)
+	}
+	parsed.Category = category
+	schemaInvalid := !issue.IsSupportedVersion(parsed.Version)
+	if s.validator != nil {
+		result, err := s.validator.ValidateIssue(data)
+		if err != nil {
+			return IssueDetail{}, fmt.Errorf("validate issue: %w", err)
+		}
+		schemaInvalid = schemaInvalid || len(result.Issues) > 0
+	}
+	return IssueDetail{IsSchemaInvalid: schemaInvalid, Issue: parsed}, nil
+}
@@ -0,0 +1,79 @@
+// history_test.go は git のコミット履歴から課題JSONの各版を読み出すテストを行う。
+package issueops
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/infra/gitcommit"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// commitIssue はテスト用にプロジェクトルート配下の変更をコミットする。
+func commitIssue(t *testing.T, root string, change gitcommit.Change) {
+	t.Helper()
+	if _, err := gitcommit.Commit(root, change, gitcommit.Author{Name: "tester", Email: "tester@example.com"}, time.Now()); err != nil {
+		t.Fatalf("Commit error: %v", err)
+	}
+}
+
+func TestIssueHistory_ReturnsParsedSnapshots(t *testing.T) {
+	// コミットごとの課題を新しい順に返し、解析できない版は ParseError を設定して一覧に残すことを確認する。
+	service, root := newBundleTestService(t, "cat")
+	if _, err := git.PlainInit(root, false); err != nil {
+		t.Fatalf("PlainInit error: %v", err)
+	}
+	detail, err := service.SaveImportedIssue("cat", mod.ModeVendor, importedIssue())
+	if err != nil {
+		t.Fatalf("SaveImportedIssue error: %v", err)
+	}
+	issueID := detail.Issue.IssueID
+	commitIssue(t, root, gitcommit.Change{Operation: "issue_created", Category: "cat", IssueID: issueID, Actor: "suzuki", Mode: "Vendor"})
+	updated := detail.Issue
+	updated.Title = "renamed"
+	if _, err := service.SaveImportedIssue("cat", mod.ModeVendor, updated); err != nil {
+		t.Fatalf("SaveImportedIssue error: %v", err)
+	}
+	commitIssue(t, root, gitcommit.Change{Operation: "issue_updated", Category: "cat", IssueID: issueID})
+	if err := os.WriteFile(filepath.Join(root, "cat", issueID+".json"), []byte("{broken"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	commitIssue(t, root, gitcommit.Change{Operation: "manual_edit"})
+
+	history, err := service.IssueHistory(context.Background(), "cat", issueID, 0)
+	if err != nil {
+		t.Fatalf("IssueHistory error: %v", err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("expected 3 revisions, got %+v", history)
+	}
+	if history[0].ParseError == "" || history[0].Snapshot != nil || history[0].Operation != "manual_edit" {
+		t.Fatalf("unexpected broken revision: %+v", history[0])
+	}
+	if snapshot := history[1].Snapshot; snapshot == nil || snapshot.Issue.Title != "renamed" || snapshot.IsSchemaInvalid || snapshot.Issue.Category != "cat" {
+		t.Fatalf("unexpected updated revision: %+v", history[1])
+	}
+	created := history[2]
+	if created.Snapshot == nil || created.Snapshot.Issue.Title != "imported" || created.Actor != "suzuki" || created.Mode != "Vendor" || created.AuthorName != "tester" {
+		t.Fatalf("unexpected created revision: %+v", created)
+	}
+}
+
+func TestIssueHistory_RejectsInvalidTargets(t *testing.T) {
+	// リポジトリ外のプロジェクトは ErrNotFound、パスを含む課題IDは ErrValidation とすることを確認する。
+	service, _ := newBundleTestService(t, "cat")
+	if _, err := service.IssueHistory(context.Background(), "cat", "abc", 0); !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := service.IssueHistory(context.Background(), "cat", "../abc", 0); !errors.Is(err, apperr.ErrValidation) {
+		t.Fatalf("expected ErrValidation, got %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+// importissue.go は外部の課題管理から取り込んだ課題をそのままの内容で保存する処理を担い、取り込み元の形式の解釈は扱わない。
+package issueops
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// SaveImportedIssue は DD-REDMINE-002 の外部の課題管理から取り込んだ課題の作成・上書きを行う。
+// 目的: 作成日時・更新日時・ステータス・コメントの作成者を取り込み元のまま保持し、定期的な同期で同じ課題を更新できるようにする。
+// 入力: category はカテゴリ名、currentMode は操作モード、item は課題。IssueID が空の場合は新たに採番して作成する。
+// 出力: 保存した IssueDetail とエラー。
+// エラー: 閲覧専用モード、カテゴリ権限で許されないモード、カテゴリ不存在、アーカイブ済みカテゴリ、ID生成失敗、検証失敗、保存失敗時に返す。
+// 副作用: 課題JSONの作成または上書きを行う。
+// 並行性: 同一カテゴリへの同時取り込みは呼び出し側で排他する。
+// 不変条件: ステータスの遷移規則は適用せず、取り込み元の状態をそのまま写す。Version が 0 の場合は issue.CurrentVersion とし、
+// 空のコメントIDは採番する。添付は扱わない。
+// 関連DD: DD-REDMINE-002, DD-IMPORT-001, DD-BE-003, DD-CATMETA-002, DD-PERM-001
+func (s *Service) SaveImportedIssue(category string, currentMode mod.Mode, item issue.Issue) (IssueDetail, error) {
+	prepared, err := s.prepareImportedIssue(category, currentMode, item)
+	if err != nil {
+		return IssueDetail{}, err
+	}
+	path := filepath.Join(s.projectRoot, category, prepared.IssueID+".json")
+	revision, writeErr := writeIssueFunc(s, path, prepared)
+	if writeErr != nil {
+		return IssueDetail{}, writeErr
+	}
+	return IssueDetail{Issue: prepared, Path: path, Revision: revision}, nil
+}
+
+// CheckImportedIssue は DD-REDMINE-002 の取り込む課題を保存せずに検証する。
+// SaveImportedIssue と同じ規則で保存できるかを判定し、一括取り込みの事前確認に用いる。
+func (s *Service) CheckImportedIssue(category string, currentMode mod.Mode, item issue.Issue) error {
+	_, err := s.prepareImportedIssue(category, currentMode, item)
+	return err
+}
+
+// ParseImportedIssue は DD-IMPORT-001 の課題JSONの内容を issue スキーマで検証して課題へ変換する。
+// スキーマを読み込んでいない場合は JSON として解析できるかのみを確かめ、課題の項目の検証は SaveImportedIssue に委ねる。
+func (s *Service) ParseImportedIssue(data []byte) (issue.Issue, error) {
+	if s.validator != nil {
+		result, err := s.validator.ValidateIssue(data)
+		if err != nil {
+			return issue.Issue{}, fmt.Errorf("validate issue: %w", err)
+		}
+		if len(result.Issues) > 0 {
+			return issue.Issue{}, apperr.Errorf(apperr.ErrSchemaInvalid, "issue schema invalid: %s", result.Detail())
+		}
+	}
+	var item issue.Issue
+	if err := json.Unmarshal(data, &item); err != nil {
+		return issue.Issue{}, apperr.Errorf(apperr.ErrValidation, "parse issue: %v", err)
+	}
+	return item, nil
+}
+
+// HasIssue は DD-IMPORT-001 のカテゴリに課題ID の課題JSONまたは添付ディレクトリが既にあるかを返す。
+func (s *Service) HasIssue(category, issueID string) bool {
+	return s.issueIDInUse(category, issueID)
+}
+
+// ResolveImportIssueID は DD-PATCH-003 の取り込み先カテゴリで original が使われていなければそのまま返し、
+// 使われていれば別の課題と衝突しない課題IDを新たに採番して返す。
+func (s *Service) ResolveImportIssueID(category, original string) (string, error) {
+	return s.resolveImportIssueID(category, original)
+}
+
+// prepareImportedIssue は DD-REDMINE-002 の取り込む課題へ課題ID・コメントIDを補って検証する。ファイルは書き込まない。
+func (s *Service) prepareImportedIssue(category string, currentMode mod.Mode, item issue.Issue) (issue.Issue, error) {
+	if err := s.ensureCanWrite(category, currentMode); err != nil {
+		return issue.Issue{}, err
+	}
+	if err := s.ensureCategoryDir(category); err != nil {
+		return issue.Issue{}, err
+	}
+	if err := s.ensureNotArchived(category); err != nil {
+		return issue.Issue{}, err
+	}
+	if item.IssueID == "" {
+		idFormat, err := s.idFormat()
+		if err != nil {
+			return issue.Issue{}, err
+		}
+		generated, err := newIssueID(idFormat)
+		if err != nil {
+			return issue.Issue{}, fmt.Errorf("generate issue id: %w", err)
+		}
+		if item.IssueID, err = s.resolveImportIssueID(category, generated); err != nil {
+			return issue.Issue{}, err
+		}
+	}
+	if item.Version == 0 {
+		item.Version = issue.CurrentVersion
+	}
+	item.Category = category
+	comments := make([]issue.Comment, len(item.Comments))
+	for i, comment := range item.Comments {
+		if comment.CommentID == "" {
+			commentID, err := newCommentID()
+			if err != nil {
+				return issue.Issue{}, fmt.Errorf("generate comment id: %w", err)
+			}
+			comment.CommentID = commentID
+		}
+		if comment.Attachments == nil {
+			comment.Attachments = []issue.AttachmentRef{}
+		}
+		comments[i] = comment
+	}
+	item.Comments = comments
+	if errs := issue.ValidateIssue(item); len(errs) > 0 {
+		return issue.Issue{}, errs
+	}
+	return item, nil
+}
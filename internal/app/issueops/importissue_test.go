@@ -0,0 +1,95 @@
+// importissue_test.go は外部の課題管理から取り込んだ課題の保存のテストを行い、取り込み元の形式の解釈は扱わない。
+package issueops
+
+import (
+	"errors"
+	"testing"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// importedIssue はテスト用に取り込み元の日時とステータスを持つ課題を返す。
+func importedIssue() issue.Issue {
+	return issue.Issue{
+		Title:         "imported",
+		Description:   "from redmine",
+		Status:        issue.StatusClosed,
+		Priority:      issue.PriorityHigh,
+		OriginCompany: issue.CompanyContractor,
+		CreatedAt:     "2024-01-09T10:00:00+09:00",
+		UpdatedAt:     "2024-01-10T10:00:00+09:00",
+		DueDate:       "2024-01-20",
+		CustomFields:  map[string]any{"redmine_id": "12"},
+		Comments: []issue.Comment{{
+			Body:          "note",
+			AuthorName:    "suzuki",
+			AuthorCompany: issue.CompanyContractor,
+			CreatedAt:     "2024-01-10T10:00:00+09:00",
+		}},
+	}
+}
+
+func TestSaveImportedIssue_KeepsSourceValuesAndOverwrites(t *testing.T) {
+	// 課題ID・コメントIDを採番して取り込み元の日時・ステータスのまま保存し、同じ課題IDでは上書きすることを確認する。
+	service, _ := newBundleTestService(t, "cat")
+	detail, err := service.SaveImportedIssue("cat", mod.ModeVendor, importedIssue())
+	if err != nil {
+		t.Fatalf("SaveImportedIssue error: %v", err)
+	}
+	saved := detail.Issue
+	if saved.IssueID == "" || saved.Version != issue.CurrentVersion || saved.Category != "cat" || saved.Comments[0].CommentID == "" {
+		t.Fatalf("unexpected saved issue: %+v", saved)
+	}
+	reloaded, err := service.GetIssue("cat", saved.IssueID)
+	if err != nil || reloaded.IsSchemaInvalid {
+		t.Fatalf("GetIssue error: %v %+v", err, reloaded)
+	}
+	if reloaded.Issue.Status != issue.StatusClosed || reloaded.Issue.CreatedAt != "2024-01-09T10:00:00+09:00" {
+		t.Fatalf("unexpected reloaded issue: %+v", reloaded.Issue)
+	}
+
+	saved.Status = issue.StatusWorking
+	saved.Title = "reopened"
+	if _, err := service.SaveImportedIssue("cat", mod.ModeVendor, saved); err != nil {
+		t.Fatalf("SaveImportedIssue error: %v", err)
+	}
+	reloaded, err = service.GetIssue("cat", saved.IssueID)
+	if err != nil || reloaded.Issue.Status != issue.StatusWorking || reloaded.Issue.Title != "reopened" {
+		t.Fatalf("expected overwritten issue: %+v %v", reloaded.Issue, err)
+	}
+}
+
+func TestCheckImportedIssue_RejectsInvalidAndReadOnly(t *testing.T) {
+	// 必須項目の無い課題は検証エラー、閲覧専用モードは権限不足とし、いずれもファイルを作成しないことを確認する。
+	service, _ := newBundleTestService(t, "cat")
+	invalid := importedIssue()
+	invalid.DueDate = ""
+	var validationErrs issue.ValidationErrors
+	if err := service.CheckImportedIssue("cat", mod.ModeVendor, invalid); !errors.As(err, &validationErrs) {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+	if err := service.CheckImportedIssue("cat", mod.ModeObserver, importedIssue()); !errors.Is(err, apperr.ErrPermission) {
+		t.Fatalf("expected permission error, got %v", err)
+	}
+	summaries, err := service.ListSummaries("cat")
+	if err != nil || len(summaries) != 0 {
+		t.Fatalf("expected no issues, got %+v %v", summaries, err)
+	}
+}
+
+func TestParseImportedIssue_ValidatesSchema(t *testing.T) {
+	// スキーマに沿わない課題JSONはスキーマ不整合、JSON として読めない内容は検証エラーとすることを確認する。
+	service, _ := newBundleTestService(t, "cat")
+	if _, err := service.ParseImportedIssue([]byte(`{"version":2,"issue_id":"x"}`)); !errors.Is(err, apperr.ErrSchemaInvalid) {
+		t.Fatalf("expected schema error, got %v", err)
+	}
+	if _, err := service.ParseImportedIssue([]byte(`{`)); err == nil {
+		t.Fatal("expected parse error")
+	}
+	if service.HasIssue("cat", "missing") {
+		t.Fatal("expected no issue")
+	}
+}
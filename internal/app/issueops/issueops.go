@@ -9,23 +9,31 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+	"time"
 
+	"ratta/internal/domain/apperr"
 	"ratta/internal/domain/id"
 	"ratta/internal/domain/issue"
 	"ratta/internal/domain/timeutil"
 	"ratta/internal/infra/atomicwrite"
 	"ratta/internal/infra/attachmentstore"
-	"ratta/internal/infra/jsonfmt"
+	"ratta/internal/infra/categorymeta"
+	"ratta/internal/infra/issueindex"
+	"ratta/internal/infra/projectmeta"
 	"ratta/internal/infra/schema"
+	"ratta/internal/infra/sqlitecache"
 
 	mod "ratta/internal/domain/mode"
 )
 
 // IssueDetail は DD-LOAD-004/DD-DATA-003 の課題詳細を表す。
+// Revision は読み込んだ・書き込んだ課題JSONの内容から求めた DD-PERSIST-006 の版を表し、内容を持たない場合は空とする。
 type IssueDetail struct {
 	IsSchemaInvalid bool
 	Issue           issue.Issue
 	Path            string
+	Revision        string
 }
 
 // IssueCreateInput は DD-DATA-003 の課題作成入力を表す。
@@ -45,6 +53,8 @@ type IssueUpdateInput struct {
 	Priority    issue.Priority
 	Status      issue.Status
 	Assignee    string
+	// ExpectedRevision は DD-PERSIST-006 の利用者が編集を始めた時点の課題の版を表す。空の場合は外部での変更を確かめない。
+	ExpectedRevision string
 }
 
 // CommentCreateInput は DD-DATA-004 のコメント作成入力を表す。
@@ -52,6 +62,8 @@ type CommentCreateInput struct {
 	Body        string
 	AuthorName  string
 	Attachments []CommentAttachmentInput
+	// ExpectedRevision は DD-PERSIST-006 の利用者がコメントを書き始めた時点の課題の版を表す。空の場合は外部での変更を確かめない。
+	ExpectedRevision string
 }
 
 // CommentAttachmentInput は DD-DATA-005 の添付入力を表す。
@@ -62,20 +74,27 @@ type CommentAttachmentInput struct {
 }
 
 // IssueListQuery は DD-BE-003 の IssueListQueryDTO に合わせた条件を表す。
+// Status と Priority は空の場合に絞り込みを行わない。
+// Cursor を指定した場合は Page を用いず、カーソル位置の続きから PageSize 件を返す。
 type IssueListQuery struct {
 	Page      int
 	PageSize  int
 	SortBy    string
 	SortOrder string
+	Status    string
+	Priority  string
+	Cursor    string
 }
 
 // IssueList は DD-BE-003 の IssueListDTO を表す。
+// カーソル指定時の Page は 0 とし、NextCursor は続きが無い場合に空とする。
 type IssueList struct {
-	Category string
-	Total    int
-	Page     int
-	PageSize int
-	Issues   []IssueSummary
+	Category   string
+	Total      int
+	Page       int
+	PageSize   int
+	Issues     []IssueSummary
+	NextCursor string
 }
 
 // IssueSummary は DD-LOAD-004 の課題一覧項目を表す。
@@ -96,16 +115,24 @@ type IssueSummary struct {
 type Service struct {
 	projectRoot string
 	validator   *schema.Validator
+	concurrency int
+	ruleChecker RuleChecker
 }
 
+// RuleChecker は DD-PLUGIN-003 の保存前に追加の規則で課題を検証する関数を表す。
+// 規則に反する場合は issue.ValidationErrors を、検証できない場合はその他のエラーを返す。
+type RuleChecker func(category string, value issue.Issue) error
+
 // maxCommentAttachments は DD-DATA-004 の添付上限数を表す。
 const maxCommentAttachments = 5
 
 var (
 	saveAttachments = attachmentstore.SaveAll
 	newCommentID    = id.NewCommentID
+	newIssueID      = id.NewIssueID
 	nowISO          = timeutil.NowISO8601
-	writeIssueFunc  = func(s *Service, path string, value issue.Issue) error { return s.writeIssue(path, value) }
+	bundleNow       = time.Now
+	writeIssueFunc  = func(s *Service, path string, value issue.Issue) (string, error) { return s.writeIssue(path, value) }
 )
 
 // NewService は DD-BE-003 の課題操作に必要な設定を受け取って生成する。
@@ -116,6 +143,18 @@ func NewService(projectRoot string, validator *schema.Validator) *Service {
 	}
 }
 
+// WithConcurrency は DD-SCAN-001 の一覧取得時の読み込み並列度を設定する。0 以下は既定値を用いる。
+func (s *Service) WithConcurrency(concurrency int) *Service {
+	s.concurrency = concurrency
+	return s
+}
+
+// WithRuleChecker は DD-PLUGIN-003 の課題の作成・更新・コメントの追加の保存前に行う追加の検証を設定する。nil の場合は行わない。
+func (s *Service) WithRuleChecker(check RuleChecker) *Service {
+	s.ruleChecker = check
+	return s
+}
+
 // GetIssue は DD-BE-003 の課題詳細読み込みを行う。
 func (s *Service) GetIssue(category, issueID string) (IssueDetail, error) {
 	path := filepath.Join(s.projectRoot, category, issueID+".json")
@@ -126,24 +165,81 @@ func (s *Service) GetIssue(category, issueID string) (IssueDetail, error) {
 // 目的: 入力内容から新規課題を生成し永続化する。
 // 入力: category はカテゴリ名、currentMode は操作モード、input は課題入力。
 // 出力: 作成した IssueDetail とエラー。
-// エラー: 入力検証失敗、ID生成失敗、保存失敗時に返す。
+// エラー: 閲覧専用モード、カテゴリ権限で許されないモード、アーカイブ済みカテゴリ、カテゴリメタデータ読み取り失敗、入力検証失敗 (DD-PLUGIN-003 の追加の規則を含む)、ID生成失敗、保存失敗時に返す。
 // 副作用: 課題JSONの新規作成を行う。
 // 並行性: 同一カテゴリへの同時作成は呼び出し側で排他する。
-// 不変条件: 作成後の Issue は検証済みで Version=1。
-// 関連DD: DD-BE-003
+// 不変条件: 作成後の Issue は検証済みで Version=issue.CurrentVersion。空の入力項目にはカテゴリの既定値を適用する。
+// 関連DD: DD-BE-003, DD-CATMETA-002, DD-CATMETA-003, DD-PERM-001, DD-PLUGIN-003
 func (s *Service) CreateIssue(category string, currentMode mod.Mode, input IssueCreateInput) (IssueDetail, error) {
-	if err := s.ensureCategoryDir(category); err != nil {
+	newIssue, err := s.buildIssue(category, currentMode, input)
+	if err != nil {
 		return IssueDetail{}, err
 	}
 
-	issueID, err := id.NewIssueID()
+	path := filepath.Join(s.projectRoot, category, newIssue.IssueID+".json")
+	revision, writeErr := s.writeIssue(path, newIssue)
+	if writeErr != nil {
+		return IssueDetail{}, writeErr
+	}
+
+	return IssueDetail{Issue: newIssue, Path: path, Revision: revision}, nil
+}
+
+// CheckCreateIssue は DD-BE-003 の課題作成を保存せずに検証する。
+// CreateIssue と同じ規則 (カテゴリの状態・既定値の適用・入力検証) で作成できるかを判定し、一括登録の事前確認に用いる。
+func (s *Service) CheckCreateIssue(category string, currentMode mod.Mode, input IssueCreateInput) error {
+	_, err := s.buildIssue(category, currentMode, input)
+	return err
+}
+
+// validateIssue は DD-DATA-003 の課題の検証と、設定されている場合は DD-PLUGIN-003 の追加の規則による検証を行う。
+func (s *Service) validateIssue(value issue.Issue) error {
+	if errs := issue.ValidateIssue(value); len(errs) > 0 {
+		return errs
+	}
+	if s.ruleChecker != nil {
+		return s.ruleChecker(value.Category, value)
+	}
+	return nil
+}
+
+// idFormat は DD-PROJCONF-001 のプロジェクト単位の設定から課題ID・添付IDの文字種と長さを返す。
+func (s *Service) idFormat() (id.Format, error) {
+	cfg, err := projectmeta.LoadConfig(s.projectRoot)
+	if err != nil {
+		return id.Format{}, err
+	}
+	return cfg.IDFormat(), nil
+}
+
+// buildIssue は DD-BE-003/DD-CATMETA-003 の新規課題を組み立てて検証する。ファイルは書き込まない。
+func (s *Service) buildIssue(category string, currentMode mod.Mode, input IssueCreateInput) (issue.Issue, error) {
+	if err := s.ensureCanWrite(category, currentMode); err != nil {
+		return issue.Issue{}, err
+	}
+	if err := s.ensureCategoryDir(category); err != nil {
+		return issue.Issue{}, err
+	}
+	if err := s.ensureNotArchived(category); err != nil {
+		return issue.Issue{}, err
+	}
+	input, err := s.applyCategoryDefaults(category, input)
+	if err != nil {
+		return issue.Issue{}, err
+	}
+
+	idFormat, err := s.idFormat()
 	if err != nil {
-		return IssueDetail{}, fmt.Errorf("generate issue id: %w", err)
+		return issue.Issue{}, err
+	}
+	issueID, err := newIssueID(idFormat)
+	if err != nil {
+		return issue.Issue{}, fmt.Errorf("generate issue id: %w", err)
 	}
 
 	now := timeutil.NowISO8601()
 	newIssue := issue.Issue{
-		Version:       1,
+		Version:       issue.CurrentVersion,
 		IssueID:       issueID,
 		Category:      category,
 		Title:         input.Title,
@@ -158,41 +254,36 @@ func (s *Service) CreateIssue(category string, currentMode mod.Mode, input Issue
 		Comments:      []issue.Comment{},
 	}
 
-	if errs := issue.ValidateIssue(newIssue); len(errs) > 0 {
-		return IssueDetail{}, errs
+	if err := s.validateIssue(newIssue); err != nil {
+		return issue.Issue{}, err
 	}
-
-	path := filepath.Join(s.projectRoot, category, issueID+".json")
-	if writeErr := s.writeIssue(path, newIssue); writeErr != nil {
-		return IssueDetail{}, writeErr
-	}
-
-	return IssueDetail{Issue: newIssue, Path: path}, nil
+	return newIssue, nil
 }
 
 // UpdateIssue は DD-BE-003 の課題更新を行う。
 // 目的: 既存課題を更新し状態遷移を適用する。
 // 入力: category と issueID は対象識別子、currentMode は操作モード、input は更新内容。
 // 出力: 更新後の IssueDetail とエラー。
-// エラー: 読み込み失敗、禁止状態、検証失敗、保存失敗時に返す。
+// エラー: 閲覧専用モード、カテゴリ権限で許されないモード、アーカイブ済みカテゴリ、読み込み失敗、禁止状態、検証失敗 (DD-PLUGIN-003 の追加の規則を含む)、保存失敗時に返す。
+// input.ExpectedRevision と現在の課題JSONの版が異なる場合は *ExternalChangeError を返す。
 // 副作用: 既存課題JSONを上書きする。
-// 並行性: 同一課題への同時更新は想定しない。
-// 不変条件: 更新後の課題は検証済みで UpdatedAt が更新される。
-// 関連DD: DD-BE-003
+// 並行性: 同一プロセス内の同時更新は呼び出し側で排他する。共有フォルダ上の他の利用者による変更は版の比較で検出する。
+// 不変条件: 更新後の課題は検証済みで UpdatedAt が更新される。外部での変更を検出した場合は上書きしない。
+// 関連DD: DD-BE-003, DD-PERSIST-006, DD-CATMETA-002, DD-PERM-001, DD-PLUGIN-003
 func (s *Service) UpdateIssue(category, issueID string, currentMode mod.Mode, input IssueUpdateInput) (IssueDetail, error) {
+	if err := s.ensureCanWrite(category, currentMode); err != nil {
+		return IssueDetail{}, err
+	}
+	if err := s.ensureNotArchived(category); err != nil {
+		return IssueDetail{}, err
+	}
 	path := filepath.Join(s.projectRoot, category, issueID+".json")
 	current, err := s.readIssue(path, category)
 	if err != nil {
 		return IssueDetail{}, err
 	}
 	if current.IsSchemaInvalid {
-		return IssueDetail{}, errors.New("schema invalid issue is read-only")
-	}
-	if current.Issue.Status.IsEndState() {
-		return IssueDetail{}, errors.New("closed or rejected issue cannot be updated")
-	}
-	if !mod.CanTransitionStatus(current.Issue.Status, input.Status, currentMode) {
-		return IssueDetail{}, errors.New("status transition not allowed")
+		return IssueDetail{}, apperr.New(apperr.ErrReadOnly, "schema invalid issue is read-only")
 	}
 
 	updated := current.Issue
@@ -204,41 +295,70 @@ func (s *Service) UpdateIssue(category, issueID string, currentMode mod.Mode, in
 	updated.Assignee = input.Assignee
 	updated.UpdatedAt = timeutil.NowISO8601()
 
-	if errs := issue.ValidateIssue(updated); len(errs) > 0 {
-		return IssueDetail{}, errs
+	// 外部で終了状態へ変えられた場合も、状態の規則より先に競合として両方の内容を返す。
+	if err := checkRevision(current, input.ExpectedRevision, updated); err != nil {
+		return IssueDetail{}, err
+	}
+	if current.Issue.Status.IsEndState() {
+		return IssueDetail{}, errors.New("closed or rejected issue cannot be updated")
+	}
+	if !mod.CanTransitionStatus(current.Issue.Status, input.Status, currentMode) {
+		return IssueDetail{}, errors.New("status transition not allowed")
+	}
+
+	if err := s.validateIssue(updated); err != nil {
+		return IssueDetail{}, err
 	}
 
-	if writeErr := s.writeIssue(path, updated); writeErr != nil {
+	revision, writeErr := s.writeIssue(path, updated)
+	if writeErr != nil {
 		return IssueDetail{}, writeErr
 	}
 
-	return IssueDetail{Issue: updated, Path: path}, nil
+	return IssueDetail{Issue: updated, Path: path, Revision: revision}, nil
 }
 
 // AddComment は DD-BE-003/DD-DATA-004 のコメント追加を行う。
 // 目的: 課題にコメントと添付情報を追加する。
 // 入力: category と issueID は対象識別子、currentMode は操作モード、input はコメント入力。
 // 出力: 更新後の IssueDetail とエラー。
-// エラー: 読み込み失敗、添付保存失敗、検証失敗、保存失敗時に返す。
+// エラー: 閲覧専用モード、カテゴリ権限で許されないモード、アーカイブ済みカテゴリ、読み込み失敗、
+// DD-PROJCONF-001 の添付の制限を超えた場合、添付保存失敗、検証失敗 (DD-PLUGIN-003 の追加の規則を含む)、保存失敗時に返す。
+// input.ExpectedRevision と現在の課題JSONの版が異なる場合は *ExternalChangeError を返す。
 // 副作用: 添付ファイルの保存と課題JSONの更新を行う。
-// 並行性: 同一課題への同時更新は想定しない。
-// 不変条件: 添付保存に失敗した場合は課題JSONを更新しない。
-// 関連DD: DD-BE-003, DD-DATA-004
+// 並行性: 同一プロセス内の同時更新は呼び出し側で排他する。共有フォルダ上の他の利用者による変更は版の比較で検出する。
+// 不変条件: 添付保存に失敗した場合や外部での変更を検出した場合は課題JSONを更新しない。
+// 関連DD: DD-BE-003, DD-PERSIST-006, DD-DATA-004, DD-CATMETA-002, DD-PERM-001, DD-PROJCONF-001, DD-PLUGIN-003
 func (s *Service) AddComment(category, issueID string, currentMode mod.Mode, input CommentCreateInput) (IssueDetail, error) {
+	if err := s.ensureCanWrite(category, currentMode); err != nil {
+		return IssueDetail{}, err
+	}
+	if err := s.ensureNotArchived(category); err != nil {
+		return IssueDetail{}, err
+	}
 	path := filepath.Join(s.projectRoot, category, issueID+".json")
 	current, err := s.readIssue(path, category)
 	if err != nil {
 		return IssueDetail{}, err
 	}
 	if current.IsSchemaInvalid {
-		return IssueDetail{}, errors.New("schema invalid issue is read-only")
+		return IssueDetail{}, apperr.New(apperr.ErrReadOnly, "schema invalid issue is read-only")
+	}
+	// 添付を保存する前に確かめ、競合時に書き込んだ添付が残らないようにする。
+	if err := checkRevision(current, input.ExpectedRevision, pendingComment(current.Issue, currentMode, input)); err != nil {
+		return IssueDetail{}, err
 	}
 	if current.Issue.Status.IsEndState() {
 		return IssueDetail{}, errors.New("closed or rejected issue cannot be updated")
 	}
 
-	if len(input.Attachments) > maxCommentAttachments {
-		return IssueDetail{}, errors.New("too many attachments")
+	if err := s.checkAttachmentLimits(input.Attachments); err != nil {
+		return IssueDetail{}, err
+	}
+	for _, attachment := range input.Attachments {
+		if err := checkAttachmentName(attachment.OriginalName); err != nil {
+			return IssueDetail{}, err
+		}
 	}
 
 	commentID, err := newCommentID()
@@ -254,7 +374,11 @@ func (s *Service) AddComment(category, issueID string, currentMode mod.Mode, inp
 			Data:         attachment.Data,
 		})
 	}
-	saved, rollback, err := saveAttachments(issueDir, issueID, storeInputs)
+	idFormat, err := s.idFormat()
+	if err != nil {
+		return IssueDetail{}, err
+	}
+	saved, rollback, err := saveAttachments(issueDir, issueID, storeInputs, idFormat)
 	if err != nil {
 		return IssueDetail{}, err
 	}
@@ -284,16 +408,17 @@ func (s *Service) AddComment(category, issueID string, currentMode mod.Mode, inp
 	updated.Comments = append(updated.Comments, comment)
 	updated.UpdatedAt = nowISO()
 
-	if errs := issue.ValidateIssue(updated); len(errs) > 0 {
+	if validateErr := s.validateIssue(updated); validateErr != nil {
 		if rollback != nil {
 			if rollbackErr := rollback(); rollbackErr != nil {
-				return IssueDetail{}, fmt.Errorf("rollback attachments failed: %w; rollback error: %s", errs, rollbackErr.Error())
+				return IssueDetail{}, fmt.Errorf("rollback attachments failed: %w; rollback error: %s", validateErr, rollbackErr.Error())
 			}
 		}
-		return IssueDetail{}, errs
+		return IssueDetail{}, validateErr
 	}
 
-	if writeErr := writeIssueFunc(s, path, updated); writeErr != nil {
+	revision, writeErr := writeIssueFunc(s, path, updated)
+	if writeErr != nil {
 		if rollback != nil {
 			if rollbackErr := rollback(); rollbackErr != nil {
 				return IssueDetail{}, fmt.Errorf("rollback attachments failed: %w; rollback error: %s", writeErr, rollbackErr.Error())
@@ -302,65 +427,143 @@ func (s *Service) AddComment(category, issueID string, currentMode mod.Mode, inp
 		return IssueDetail{}, writeErr
 	}
 
-	return IssueDetail{Issue: updated, Path: path}, nil
+	return IssueDetail{Issue: updated, Path: path, Revision: revision}, nil
+}
+
+// CheckRevert は DD-JOURNAL-001 の取り消しで課題を操作前の内容へ戻してよいかを判定する。
+// 目的: 取り消しが課題JSONを直接書き戻す前に、課題の更新と同じ規則を現在の操作モードで適用する。
+// 入力: category と issueID は対象識別子、currentMode は取り消しを行う時点の操作モード、
+// restored は戻す先の課題 (操作で作成した課題を削除する場合は nil)。
+// 出力: 戻してよい場合は nil。
+// エラー: 閲覧専用モード、カテゴリ権限で許されないモード、アーカイブ済みカテゴリ、スキーマ不整合の課題、
+// 現在の課題が終状態 (Closed/Rejected) の場合、現在の状態から restored の状態への遷移が操作モードで許されない場合に返す。
+// 副作用: 現在の課題JSONとカテゴリ権限を読み込むのみで、書き込まない。
+// 並行性: 判定から書き戻しまでの同一課題への書き込みとの排他は呼び出し側で行う。
+// 不変条件: 終状態の課題は取り消しでも変更せず、Vendor は取り消しで Closed/Rejected へ戻さない。
+// 関連DD: DD-JOURNAL-001, DD-BE-003, DD-CATMETA-002, DD-PERM-001
+func (s *Service) CheckRevert(category, issueID string, currentMode mod.Mode, restored *issue.Issue) error {
+	if err := s.ensureCanWrite(category, currentMode); err != nil {
+		return err
+	}
+	if err := s.ensureNotArchived(category); err != nil {
+		return err
+	}
+	current, err := s.readIssue(filepath.Join(s.projectRoot, category, issueID+".json"), category)
+	if err != nil {
+		// 課題JSONが既に無い場合は状態の規則を適用する対象が無いため、ファイルの照合を取り消し側に任せる。
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if current.IsSchemaInvalid {
+		return apperr.New(apperr.ErrReadOnly, "schema invalid issue is read-only")
+	}
+	if current.Issue.Status.IsEndState() {
+		return apperr.New(apperr.ErrReadOnly, "closed or rejected issue cannot be reverted")
+	}
+	if restored != nil {
+		return CheckMerge(current.Issue, *restored, currentMode)
+	}
+	return nil
 }
 
 // ListIssues は DD-BE-003/DD-LOAD-003 の一覧取得を行う。
-// 目的: 指定カテゴリの課題一覧を読み込みページングする。
+// 目的: 指定カテゴリの課題一覧を索引経由で取得しページングする。
+// 変更の無い課題JSONは索引の要約を再利用し、読み込みとスキーマ検証を省く。
 // 入力: category はカテゴリ名、query はページング条件。
 // 出力: IssueList とエラー。
-// エラー: カテゴリ読み取り失敗時に返す。
-// 副作用: なし。
-// 並行性: 読み取りのみでスレッドセーフ。
+// エラー: カテゴリ読み取り失敗時、カーソルが不正な場合に返す。
+// SQLite キャッシュが有効な場合はキャッシュ経由で取得し、失敗時は索引へ切り替える。
+// 副作用: 索引が古い場合は .ratta/index.json を、キャッシュ有効時は .ratta/cache.db を更新する。
+// 並行性: 索引の同時更新は後勝ちとなるが、次回の鮮度判定で自己修復する。
 // 不変条件: 返却する一覧は sort_by/sort_order に従う。
-// 関連DD: DD-BE-003, DD-LOAD-003
+// 関連DD: DD-BE-003, DD-LOAD-003, DD-INDEX-001, DD-CACHE-001
 func (s *Service) ListIssues(category string, query IssueListQuery) (IssueList, error) {
-	categoryPath := filepath.Join(s.projectRoot, category)
-	entries, err := os.ReadDir(categoryPath)
+	if query.Cursor != "" {
+		if _, err := decodeCursor(query.Cursor, query); err != nil {
+			return IssueList{}, err
+		}
+	}
+	if sqlitecache.Enabled(s.projectRoot) {
+		// キャッシュは高速化のためだけに用いるため、失敗時は索引による一覧取得へ切り替える。
+		if list, cacheErr := s.listIssuesFromCache(category, query); cacheErr == nil {
+			return list, nil
+		}
+	}
+	items, err := s.ListSummaries(category)
 	if err != nil {
-		return IssueList{}, fmt.Errorf("read category: %w", err)
+		return IssueList{}, err
 	}
+	return QueryIssues(category, items, query)
+}
 
+// ListSummaries は DD-LOAD-003/DD-INDEX-001 のカテゴリ内の全課題の一覧項目を取得する。
+// 絞り込み・並び替え・ページングは行わず、索引のファイル名順で返す。
+func (s *Service) ListSummaries(category string) ([]IssueSummary, error) {
+	categoryPath := filepath.Join(s.projectRoot, category)
+	entries, err := issueindex.Open(s.projectRoot).WithConcurrency(s.concurrency).Category(categoryPath, category, s.indexLoader())
+	if err != nil {
+		return nil, err
+	}
 	items := make([]IssueSummary, 0, len(entries))
 	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		if filepath.Ext(entry.Name()) != ".json" {
+		items = append(items, toIssueSummary(categoryPath, category, entry))
+	}
+	return items, nil
+}
+
+// RebuildSummaries は DD-INDEX-001 のカテゴリの索引を作り直し、全課題の一覧項目を取得する。
+// 外部ツールによる mtime を保った書き換えなど、鮮度判定で検知できない索引の不整合を解消するために用いる。
+func (s *Service) RebuildSummaries(category string) ([]IssueSummary, error) {
+	if err := issueindex.Open(s.projectRoot).Reset(category); err != nil {
+		return nil, err
+	}
+	return s.ListSummaries(category)
+}
+
+// QueryIssues は DD-BE-003 の一覧条件 (絞り込み・並び替え・ページング) を一覧項目に適用する。
+// 入力の items は並べ替えずに複製して扱うため、呼び出し側で保持している一覧を渡してよい。
+// カーソルが不正な場合はエラーを返す。
+func QueryIssues(category string, items []IssueSummary, query IssueListQuery) (IssueList, error) {
+	filtered := make([]IssueSummary, 0, len(items))
+	for _, item := range items {
+		if query.Status != "" && item.Status != query.Status {
 			continue
 		}
-		path := filepath.Join(categoryPath, entry.Name())
-		item, readErr := s.readIssue(path, category)
-		if readErr != nil {
+		if query.Priority != "" && item.Priority != query.Priority {
 			continue
 		}
-		items = append(items, IssueSummary{
-			IssueID:         item.Issue.IssueID,
-			Title:           item.Issue.Title,
-			Status:          string(item.Issue.Status),
-			Priority:        string(item.Issue.Priority),
-			OriginCompany:   string(item.Issue.OriginCompany),
-			UpdatedAt:       item.Issue.UpdatedAt,
-			DueDate:         item.Issue.DueDate,
-			Category:        category,
-			IsSchemaInvalid: item.IsSchemaInvalid,
-			Path:            item.Path,
-		})
+		filtered = append(filtered, item)
 	}
 
-	applySort(items, query.SortBy, query.SortOrder)
-	total := len(items)
+	applySort(filtered, query.SortBy, query.SortOrder)
+	total := len(filtered)
 	pageSize := normalizePageSize(query.PageSize)
 	page := normalizePage(query.Page)
-	paged := paginate(items, page, pageSize)
+	start := (page - 1) * pageSize
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor, query)
+		if err != nil {
+			return IssueList{}, err
+		}
+		// カーソルの課題が削除されていても位置は並び順の値で決まるため、続きの先頭を探せる。
+		page = 0
+		start = sort.Search(len(filtered), func(i int) bool { return isAfterCursor(filtered[i], cursor) })
+	}
+	paged := sliceFrom(filtered, start, pageSize)
 
-	return IssueList{
+	list := IssueList{
 		Category: category,
 		Total:    total,
 		Page:     page,
 		PageSize: pageSize,
 		Issues:   paged,
-	}, nil
+	}
+	if start+len(paged) < total && len(paged) > 0 {
+		list.NextCursor = encodeCursor(query.SortBy, query.SortOrder, paged[len(paged)-1])
+	}
+	return list, nil
 }
 
 // readIssue は DD-LOAD-004 の課題JSON読み込みを行う。
@@ -373,54 +576,238 @@ func (s *Service) ListIssues(category string, query IssueListQuery) (IssueList,
 // 不変条件: Category は入力 category に上書きする。
 // 関連DD: DD-LOAD-004
 func (s *Service) readIssue(path, category string) (IssueDetail, error) {
-	// #nosec G304 -- カテゴリ配下の列挙結果から生成したパスのみを読む。
-	data, readErr := os.ReadFile(path)
+	data, stamp, readErr := readIssueFile(path)
 	if readErr != nil {
-		return IssueDetail{}, fmt.Errorf("read issue: %w", readErr)
+		return IssueDetail{}, readErr
 	}
 
 	var parsed issue.Issue
 	if unmarshalErr := json.Unmarshal(data, &parsed); unmarshalErr != nil {
-		return IssueDetail{}, fmt.Errorf("parse issue: %w", unmarshalErr)
+		return IssueDetail{}, apperr.WithPath(fmt.Errorf("parse issue: %w", unmarshalErr), path, apperr.HintCheckJSON)
 	}
 	parsed.Category = category
 
-	schemaInvalid := false
-	if s.validator != nil {
-		result, validateErr := s.validator.ValidateIssue(data)
-		if validateErr != nil {
-			return IssueDetail{}, fmt.Errorf("validate issue: %w", validateErr)
-		}
-		schemaInvalid = len(result.Issues) > 0 || parsed.Version != 1
-	} else if parsed.Version != 1 {
-		schemaInvalid = true
+	schemaInvalid, err := s.isSchemaInvalid(path, stamp, data, parsed.Version)
+	if err != nil {
+		return IssueDetail{}, err
 	}
 
 	return IssueDetail{
 		IsSchemaInvalid: schemaInvalid,
 		Issue:           parsed,
 		Path:            path,
+		Revision:        revisionOf(data),
+	}, nil
+}
+
+// issueHeader は DD-LOAD-004 の一覧表示に必要な項目のみを表す。
+// comments を持たないため、コメントや添付の量に応じた割り当てが発生しない。
+type issueHeader struct {
+	Version       int    `json:"version"`
+	IssueID       string `json:"issue_id"`
+	Title         string `json:"title"`
+	Status        string `json:"status"`
+	Priority      string `json:"priority"`
+	OriginCompany string `json:"origin_company"`
+	UpdatedAt     string `json:"updated_at"`
+	DueDate       string `json:"due_date"`
+}
+
+// readIssueHeader は DD-LOAD-004 の一覧向けの部分読み込みを行う。
+// 目的: 課題JSONから一覧表示に必要な項目のみを取り出し、索引エントリとして返す。
+// 入力: path は課題JSONパス。
+// 出力: 索引エントリとエラー。
+// エラー: 読み込み・パース・スキーマ検証失敗時に返す。
+// 副作用: なし。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: IsSchemaInvalid の判定は readIssue と同じ規則に従う。
+// 関連DD: DD-LOAD-004, DD-INDEX-001
+func (s *Service) readIssueHeader(path string) (issueindex.Entry, error) {
+	data, stamp, readErr := readIssueFile(path)
+	if readErr != nil {
+		return issueindex.Entry{}, readErr
+	}
+
+	var header issueHeader
+	if unmarshalErr := json.Unmarshal(data, &header); unmarshalErr != nil {
+		return issueindex.Entry{}, apperr.WithPath(fmt.Errorf("parse issue: %w", unmarshalErr), path, apperr.HintCheckJSON)
+	}
+	schemaInvalid, err := s.isSchemaInvalid(path, stamp, data, header.Version)
+	if err != nil {
+		return issueindex.Entry{}, err
+	}
+
+	return issueindex.Entry{
+		IssueID:         header.IssueID,
+		Title:           header.Title,
+		Status:          header.Status,
+		Priority:        header.Priority,
+		OriginCompany:   header.OriginCompany,
+		UpdatedAt:       header.UpdatedAt,
+		DueDate:         header.DueDate,
+		IsSchemaInvalid: schemaInvalid,
 	}, nil
 }
 
+// readIssueFile は DD-VALCACHE-001 の検証結果の再利用判定に用いるファイル状態とともに課題JSONを読み込む。
+// 状態は読み込み前に取得し、読み込み中に更新された場合は次回の判定で再検証されるようにする。
+func readIssueFile(path string) ([]byte, schema.FileStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, schema.FileStamp{}, apperr.WithPath(fmt.Errorf("read issue: %w", err), path, "")
+	}
+	// #nosec G304 -- カテゴリ配下の列挙結果から生成したパスのみを読む。
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, schema.FileStamp{}, apperr.WithPath(fmt.Errorf("read issue: %w", err), path, "")
+	}
+	return data, schema.StampOf(info), nil
+}
+
+// isSchemaInvalid は DD-LOAD-004 のスキーマ不整合判定を行う。
+// 検証器が無い場合も version が 1 以外であれば不整合として扱う。
+// 変更の無い課題JSONは DD-VALCACHE-001 の検証結果キャッシュを再利用する。
+func (s *Service) isSchemaInvalid(path string, stamp schema.FileStamp, data []byte, version int) (bool, error) {
+	if s.validator == nil {
+		return !issue.IsSupportedVersion(version), nil
+	}
+	result, err := s.validator.ValidateIssueFile(path, stamp, data)
+	if err != nil {
+		return false, fmt.Errorf("validate issue: %w", err)
+	}
+	return len(result.Issues) > 0 || !issue.IsSupportedVersion(version), nil
+}
+
 // writeIssue は DD-PERSIST-002 に従い課題 JSON を保存する。
 // 目的: 検証済み課題をJSONに整形し原子的に保存する。
 // 入力: path は保存先、value は課題モデル。
-// 出力: 成功時は nil、失敗時はエラー。
+// 出力: 書き込んだ内容の DD-PERSIST-006 の版とエラー。
 // エラー: JSON生成失敗または保存失敗時に返す。
-// 副作用: 課題JSONを書き換える。
+// 副作用: 課題JSONを書き換え、課題索引を更新する。バックアップの世代数を設定した場合は置き換える前の内容を .bak として残す。
 // 並行性: 同一ファイルへの同時書き込みは想定しない。
-// 不変条件: JSONキー順序と整形は jsonfmt に従う。
-// 関連DD: DD-PERSIST-002
-func (s *Service) writeIssue(path string, value issue.Issue) error {
-	data, err := jsonfmt.MarshalIssue(value)
+// 不変条件: JSONキー順序と整形は jsonfmt に従い、改行コードとインデント幅はプロジェクトの設定に従う。
+// 関連DD: DD-PERSIST-002, DD-INDEX-001, DD-DATA-006, DD-PERSIST-006, DD-PERSIST-005
+func (s *Service) writeIssue(path string, value issue.Issue) (string, error) {
+	format, err := projectmeta.LoadFormat(s.projectRoot)
 	if err != nil {
-		return fmt.Errorf("marshal issue: %w", err)
+		return "", err
 	}
-	if writeErr := atomicwrite.WriteFile(path, data); writeErr != nil {
-		return fmt.Errorf("write issue: %w", writeErr)
+	data, err := format.MarshalIssue(value)
+	if err != nil {
+		return "", fmt.Errorf("marshal issue: %w", err)
+	}
+	if writeErr := atomicwrite.WriteFileWithBackup(path, data); writeErr != nil {
+		return "", fmt.Errorf("write issue: %w", writeErr)
+	}
+	// 索引はキャッシュのため、更新に失敗しても次回の一覧取得で再構築される。
+	_ = issueindex.Open(s.projectRoot).Put(value.Category, path, toIndexEntry(IssueDetail{Issue: value, Path: path}))
+	return revisionOf(data), nil
+}
+
+// listIssuesFromCache は DD-CACHE-001 の SQLite キャッシュによる一覧取得を行う。
+// 目的: 同期後に絞り込み・並び替え・ページングをDB側で行い、該当ページのみを返す。
+// 入力: category はカテゴリ名、query は一覧条件。
+// 出力: IssueList とエラー。
+// エラー: キャッシュの接続・同期・参照失敗時に返す。
+// 副作用: キャッシュDBを更新する。
+// 並行性: 同期は1トランザクションで行う。
+// 不変条件: 返却内容は索引経由の ListIssues と同じ並び順・ページ仕様に従う。
+// 関連DD: DD-CACHE-001, DD-BE-003
+func (s *Service) listIssuesFromCache(category string, query IssueListQuery) (result IssueList, err error) {
+	cache, err := sqlitecache.Open(s.projectRoot)
+	if err != nil {
+		return IssueList{}, err
+	}
+	defer func() {
+		if closeErr := cache.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	categoryPath := filepath.Join(s.projectRoot, category)
+	if syncErr := cache.WithConcurrency(s.concurrency).SyncCategory(categoryPath, category, s.indexLoader()); syncErr != nil {
+		return IssueList{}, syncErr
+	}
+	pageSize := normalizePageSize(query.PageSize)
+	page := normalizePage(query.Page)
+	// 続きの有無を判定するため、1件多く取得する。
+	cacheQuery := sqlitecache.Query{
+		Status:    query.Status,
+		Priority:  query.Priority,
+		SortBy:    query.SortBy,
+		SortOrder: query.SortOrder,
+		Limit:     pageSize + 1,
+		Offset:    (page - 1) * pageSize,
+	}
+	if query.Cursor != "" {
+		cursor, cursorErr := decodeCursor(query.Cursor, query)
+		if cursorErr != nil {
+			return IssueList{}, cursorErr
+		}
+		page = 0
+		cacheQuery.Offset = 0
+		cacheQuery.AfterKey = cursor.Key
+		cacheQuery.AfterIssueID = cursor.IssueID
+	}
+	entries, total, err := cache.List(category, cacheQuery)
+	if err != nil {
+		return IssueList{}, err
+	}
+	hasMore := len(entries) > pageSize
+	if hasMore {
+		entries = entries[:pageSize]
+	}
+	items := make([]IssueSummary, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, toIssueSummary(categoryPath, category, entry))
+	}
+	list := IssueList{
+		Category: category,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+		Issues:   items,
+	}
+	if hasMore {
+		list.NextCursor = encodeCursor(query.SortBy, query.SortOrder, items[len(items)-1])
+	}
+	return list, nil
+}
+
+// indexLoader は DD-INDEX-001 の索引・キャッシュ向けに課題JSONを読み込む関数を返す。
+// 一覧にはコメントが不要なため、課題全体ではなく見出し項目のみを解析する。
+func (s *Service) indexLoader() issueindex.LoadFunc {
+	return s.readIssueHeader
+}
+
+// toIssueSummary は DD-LOAD-004 の一覧項目へ索引エントリを変換する。
+func toIssueSummary(categoryPath, category string, entry issueindex.Entry) IssueSummary {
+	return IssueSummary{
+		IssueID:         entry.IssueID,
+		Title:           entry.Title,
+		Status:          entry.Status,
+		Priority:        entry.Priority,
+		OriginCompany:   entry.OriginCompany,
+		UpdatedAt:       entry.UpdatedAt,
+		DueDate:         entry.DueDate,
+		Category:        category,
+		IsSchemaInvalid: entry.IsSchemaInvalid,
+		Path:            filepath.Join(categoryPath, entry.FileName),
+	}
+}
+
+// toIndexEntry は DD-INDEX-001 の索引エントリへ課題の要約を変換する。
+func toIndexEntry(detail IssueDetail) issueindex.Entry {
+	return issueindex.Entry{
+		IssueID:         detail.Issue.IssueID,
+		Title:           detail.Issue.Title,
+		Status:          string(detail.Issue.Status),
+		Priority:        string(detail.Issue.Priority),
+		OriginCompany:   string(detail.Issue.OriginCompany),
+		UpdatedAt:       detail.Issue.UpdatedAt,
+		DueDate:         detail.Issue.DueDate,
+		IsSchemaInvalid: detail.IsSchemaInvalid,
 	}
-	return nil
 }
 
 // ensureCategoryDir は DD-LOAD-002 のカテゴリディレクトリ存在を確認する。
@@ -444,6 +831,67 @@ func (s *Service) ensureCategoryDir(category string) error {
 	return nil
 }
 
+// applyCategoryDefaults は DD-CATMETA-003 のカテゴリ既定値を新規課題の入力に適用する。
+// 利用者が入力した値は常に優先し、空の項目だけを補う。
+func (s *Service) applyCategoryDefaults(category string, input IssueCreateInput) (IssueCreateInput, error) {
+	meta, _, err := categorymeta.Load(filepath.Join(s.projectRoot, category))
+	if err != nil {
+		return IssueCreateInput{}, err
+	}
+	if input.Assignee == "" {
+		input.Assignee = meta.DefaultAssignee
+	}
+	if input.Priority == "" {
+		input.Priority = issue.Priority(meta.DefaultPriority)
+	}
+	if input.Description == "" {
+		input.Description = meta.DescriptionTemplate
+	}
+	return input, nil
+}
+
+// ensureNotArchived は DD-CATMETA-002 のアーカイブ済みカテゴリへの書き込みを拒否する。
+func (s *Service) ensureNotArchived(category string) error {
+	if categorymeta.IsArchived(filepath.Join(s.projectRoot, category)) {
+		return apperr.New(apperr.ErrReadOnly, "archived category is read-only")
+	}
+	return nil
+}
+
+// ensureCanMutate は DD-BE-003 の閲覧専用モード (Observer) による変更を権限不足として拒否する。
+func ensureCanMutate(currentMode mod.Mode) error {
+	if !mod.CanMutate(currentMode) {
+		return apperr.New(apperr.ErrPermission, "permission denied")
+	}
+	return nil
+}
+
+// ensureCanWrite は DD-BE-003/DD-PERM-001 の閲覧専用モードと、カテゴリ権限で許されていないモードによる変更を拒否する。
+// カテゴリ権限ファイルを読めない場合は、制限を外して書き込むことを避けるため変更を拒否する。
+func (s *Service) ensureCanWrite(category string, currentMode mod.Mode) error {
+	if err := ensureCanMutate(currentMode); err != nil {
+		return err
+	}
+	permissions, err := projectmeta.LoadPermissions(s.projectRoot)
+	if err != nil {
+		return err
+	}
+	writers, _ := permissions.Writers(category)
+	if !mod.CanWriteCategory(writers, currentMode) {
+		return apperr.Errorf(apperr.ErrPermission, "permission denied: category %q is writable only in %s mode", category, joinModes(writers))
+	}
+	return nil
+}
+
+// joinModes は DD-PERM-001 のエラーメッセージ用にモードを "/" 区切りで連結する。
+func joinModes(modes []mod.Mode) string {
+	names := make([]string, 0, len(modes))
+	for _, m := range modes {
+		names = append(names, string(m))
+	}
+	return strings.Join(names, "/")
+}
+
 // originCompany は DD-DATA-003 の origin_company を決定する。
 func originCompany(current mod.Mode) issue.Company {
 	if current == mod.ModeContractor {
@@ -470,7 +918,11 @@ func normalizePage(page int) int {
 
 // paginate は DD-BE-003 のページングを適用する。
 func paginate(items []IssueSummary, page, pageSize int) []IssueSummary {
-	start := (page - 1) * pageSize
+	return sliceFrom(items, (page-1)*pageSize, pageSize)
+}
+
+// sliceFrom は DD-BE-003 の start 番目から pageSize 件を返す。
+func sliceFrom(items []IssueSummary, start, pageSize int) []IssueSummary {
 	if start >= len(items) {
 		return []IssueSummary{}
 	}
@@ -550,3 +1002,22 @@ func statusRank(value string) int {
 		return 8
 	}
 }
+
+// SetCacheEnabled は DD-CACHE-001 の SQLite キャッシュの有効・無効を切り替える。
+// 目的: 大規模プロジェクトで一覧取得をキャッシュ経由に切り替える、または元に戻す。
+// 入力: enabled は設定後の状態、currentMode は操作モード。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: 権限不足、キャッシュDBの作成・削除失敗時に返す。
+// 副作用: .ratta/cache.db を作成または削除する。課題JSONには触れない。
+// 並行性: 同時切り替えは想定しない。
+// 不変条件: 無効化後の一覧取得は索引経由に戻る。
+// 関連DD: DD-CACHE-001
+func (s *Service) SetCacheEnabled(enabled bool, currentMode mod.Mode) error {
+	if currentMode != mod.ModeContractor {
+		return apperr.New(apperr.ErrPermission, "permission denied")
+	}
+	if enabled {
+		return sqlitecache.Enable(s.projectRoot)
+	}
+	return sqlitecache.Disable(s.projectRoot)
+}
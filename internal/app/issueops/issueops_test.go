@@ -8,9 +8,12 @@ import (
 	"strings"
 	"testing"
 
+	"ratta/internal/domain/id"
 	"ratta/internal/domain/issue"
+	"ratta/internal/infra/atomicwrite"
 	"ratta/internal/infra/attachmentstore"
 	"ratta/internal/infra/jsonfmt"
+	"ratta/internal/infra/projectmeta"
 	"ratta/internal/infra/schema"
 
 	mod "ratta/internal/domain/mode"
@@ -345,7 +348,7 @@ func TestAddComment_RollbackOnWriteFailure(t *testing.T) {
 	previousSave := saveAttachments
 	previousWrite := writeIssueFunc
 	rolledBack := false
-	saveAttachments = func(string, string, []attachmentstore.Input) ([]attachmentstore.SavedAttachment, func() error, error) {
+	saveAttachments = func(string, string, []attachmentstore.Input, id.Format) ([]attachmentstore.SavedAttachment, func() error, error) {
 		return []attachmentstore.SavedAttachment{
 				{
 					AttachmentID: "att123",
@@ -359,8 +362,8 @@ func TestAddComment_RollbackOnWriteFailure(t *testing.T) {
 				return nil
 			}, nil
 	}
-	writeIssueFunc = func(*Service, string, issue.Issue) error {
-		return errors.New("write failed")
+	writeIssueFunc = func(*Service, string, issue.Issue) (string, error) {
+		return "", errors.New("write failed")
 	}
 	t.Cleanup(func() {
 		saveAttachments = previousSave
@@ -487,6 +490,40 @@ func TestCreateIssue_ValidationError(t *testing.T) {
 	}
 }
 
+func TestCheckCreateIssue_ValidatesWithoutWriting(t *testing.T) {
+	// 作成できる入力でもファイルを作成せず、作成できない入力は CreateIssue と同じくエラーとなることを確認する。
+	root := t.TempDir()
+	category := "cat"
+	if err := os.MkdirAll(filepath.Join(root, category), 0o750); err != nil {
+		t.Fatalf("mkdir category: %v", err)
+	}
+	service := NewService(root, nil)
+	input := IssueCreateInput{
+		Title:       "title",
+		Description: "desc",
+		DueDate:     "2024-01-01",
+		Priority:    issue.PriorityHigh,
+	}
+
+	if err := service.CheckCreateIssue(category, mod.ModeVendor, input); err != nil {
+		t.Fatalf("CheckCreateIssue error: %v", err)
+	}
+	entries, err := os.ReadDir(filepath.Join(root, category))
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected no files, got %d", len(entries))
+	}
+	input.Title = ""
+	if err := service.CheckCreateIssue(category, mod.ModeVendor, input); err == nil {
+		t.Fatal("expected validation error")
+	}
+	if err := service.CheckCreateIssue("missing", mod.ModeVendor, IssueCreateInput{Title: "title"}); err == nil {
+		t.Fatal("expected missing category error")
+	}
+}
+
 func TestEnsureCategoryDir_NotDirectory(t *testing.T) {
 	// カテゴリパスがファイルの場合にエラーとなることを確認する。
 	root := t.TempDir()
@@ -630,7 +667,7 @@ func TestReadIssue_SchemaInvalidVersion(t *testing.T) {
 		t.Fatalf("mkdir category: %v", err)
 	}
 	path := filepath.Join(root, category, "issue.json")
-	if writeErr := os.WriteFile(path, []byte(`{"version":2,"issue_id":"id","category":"cat"}`), 0o600); writeErr != nil {
+	if writeErr := os.WriteFile(path, []byte(`{"version":3,"issue_id":"id","category":"cat"}`), 0o600); writeErr != nil {
 		t.Fatalf("write issue: %v", writeErr)
 	}
 
@@ -644,10 +681,65 @@ func TestReadIssue_SchemaInvalidVersion(t *testing.T) {
 	}
 }
 
+func TestReadIssueHeader_MatchesFullRead(t *testing.T) {
+	// 見出しのみの読み込みが、コメント付きの課題でも全体読み込みと同じ一覧項目とスキーマ判定を返すことを確認する。
+	root := t.TempDir()
+	category := "cat"
+	if err := os.MkdirAll(filepath.Join(root, category), 0o750); err != nil {
+		t.Fatalf("mkdir category: %v", err)
+	}
+	validator, err := schema.NewValidatorFromDir(filepath.Join("..", "..", "..", "schemas"))
+	if err != nil {
+		t.Fatalf("NewValidatorFromDir error: %v", err)
+	}
+	service := NewService(root, validator)
+	created, err := service.CreateIssue(category, mod.ModeContractor, IssueCreateInput{
+		Title:       "title",
+		Description: "desc",
+		DueDate:     "2024-01-01",
+		Priority:    issue.PriorityHigh,
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := service.AddComment(category, created.Issue.IssueID, mod.ModeContractor, CommentCreateInput{
+			Body:       strings.Repeat("body ", 100),
+			AuthorName: "tester",
+		}); err != nil {
+			t.Fatalf("AddComment error: %v", err)
+		}
+	}
+
+	full, err := service.readIssue(created.Path, category)
+	if err != nil {
+		t.Fatalf("readIssue error: %v", err)
+	}
+	header, err := service.readIssueHeader(created.Path)
+	if err != nil {
+		t.Fatalf("readIssueHeader error: %v", err)
+	}
+	if header != toIndexEntry(full) {
+		t.Fatalf("header mismatch: %+v vs %+v", header, toIndexEntry(full))
+	}
+
+	invalidPath := filepath.Join(root, category, "invalid.json")
+	if writeErr := os.WriteFile(invalidPath, []byte(`{"version":3,"issue_id":"id","comments":[{"body":"x"}]}`), 0o600); writeErr != nil {
+		t.Fatalf("write issue: %v", writeErr)
+	}
+	invalid, err := NewService(root, nil).readIssueHeader(invalidPath)
+	if err != nil {
+		t.Fatalf("readIssueHeader error: %v", err)
+	}
+	if !invalid.IsSchemaInvalid || invalid.IssueID != "id" {
+		t.Fatalf("expected schema invalid header, got %+v", invalid)
+	}
+}
+
 func TestWriteIssue_InvalidPath(t *testing.T) {
 	// 保存先ディレクトリが存在しない場合にエラーとなることを確認する。
 	service := NewService("missing", nil)
-	err := service.writeIssue(filepath.Join("missing", "cat", "issue.json"), issue.Issue{
+	_, err := service.writeIssue(filepath.Join("missing", "cat", "issue.json"), issue.Issue{
 		Version:       1,
 		IssueID:       "id",
 		Category:      "cat",
@@ -665,3 +757,491 @@ func TestWriteIssue_InvalidPath(t *testing.T) {
 		t.Fatal("expected write error")
 	}
 }
+
+func TestArchivedCategory_RejectsWrites(t *testing.T) {
+	// アーカイブ済みカテゴリでは課題作成・更新・コメント追加が拒否されることを確認する。
+	root := t.TempDir()
+	category := "cat"
+	if err := os.MkdirAll(filepath.Join(root, category), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	service := NewService(root, nil)
+	created, err := service.CreateIssue(category, mod.ModeContractor, IssueCreateInput{
+		Title:       "title",
+		Description: "desc",
+		DueDate:     "2024-01-01",
+		Priority:    issue.PriorityHigh,
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, category, ".archived"), nil, 0o600); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	if _, createErr := service.CreateIssue(category, mod.ModeContractor, IssueCreateInput{
+		Title:       "title",
+		Description: "desc",
+		DueDate:     "2024-01-01",
+		Priority:    issue.PriorityHigh,
+	}); createErr == nil {
+		t.Fatal("expected create to be rejected")
+	}
+	if _, updateErr := service.UpdateIssue(category, created.Issue.IssueID, mod.ModeContractor, IssueUpdateInput{
+		Title:       "updated",
+		Description: "desc",
+		DueDate:     "2024-01-01",
+		Priority:    issue.PriorityHigh,
+		Status:      issue.StatusOpen,
+	}); updateErr == nil {
+		t.Fatal("expected update to be rejected")
+	}
+	if _, commentErr := service.AddComment(category, created.Issue.IssueID, mod.ModeContractor, CommentCreateInput{
+		Body:       "body",
+		AuthorName: "author",
+	}); commentErr == nil {
+		t.Fatal("expected comment to be rejected")
+	}
+	if _, getErr := service.GetIssue(category, created.Issue.IssueID); getErr != nil {
+		t.Fatalf("expected read to succeed, got %v", getErr)
+	}
+}
+
+func TestObserverMode_RejectsWrites(t *testing.T) {
+	// Observer では課題作成・事前検証・更新・コメント追加・バンドル取り込みが権限不足となり、閲覧はできることを確認する。
+	root := t.TempDir()
+	category := "cat"
+	if err := os.MkdirAll(filepath.Join(root, category), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	service := NewService(root, nil)
+	input := IssueCreateInput{Title: "title", Description: "desc", DueDate: "2024-01-01", Priority: issue.PriorityHigh}
+	created, err := service.CreateIssue(category, mod.ModeVendor, input)
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	before, err := os.ReadFile(created.Path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	if _, createErr := service.CreateIssue(category, mod.ModeObserver, input); createErr == nil || !strings.Contains(createErr.Error(), "permission denied") {
+		t.Fatalf("expected create to be denied, got %v", createErr)
+	}
+	if checkErr := service.CheckCreateIssue(category, mod.ModeObserver, input); checkErr == nil {
+		t.Fatal("expected check to be denied")
+	}
+	if _, updateErr := service.UpdateIssue(category, created.Issue.IssueID, mod.ModeObserver, IssueUpdateInput{
+		Title:       "updated",
+		Description: "desc",
+		DueDate:     "2024-01-01",
+		Priority:    issue.PriorityHigh,
+		Status:      issue.StatusOpen,
+	}); updateErr == nil {
+		t.Fatal("expected update to be denied")
+	}
+	if _, commentErr := service.AddComment(category, created.Issue.IssueID, mod.ModeObserver, CommentCreateInput{
+		Body:       "body",
+		AuthorName: "author",
+	}); commentErr == nil {
+		t.Fatal("expected comment to be denied")
+	}
+	if _, importErr := service.ImportIssueBundle(category, filepath.Join(root, "missing.zip"), mod.ModeObserver); importErr == nil || !strings.Contains(importErr.Error(), "permission denied") {
+		t.Fatalf("expected import to be denied, got %v", importErr)
+	}
+
+	after, err := os.ReadFile(created.Path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(before) != string(after) {
+		t.Fatal("expected issue file to be unchanged")
+	}
+	entries, err := os.ReadDir(filepath.Join(root, category))
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	issueFiles := 0
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".json") {
+			issueFiles++
+		}
+	}
+	if issueFiles != 1 {
+		t.Fatalf("expected only the vendor issue, got %d files", issueFiles)
+	}
+}
+
+func TestCreateIssue_AppliesCategoryDefaults(t *testing.T) {
+	// 空の担当者・優先度・説明にはカテゴリの既定値が入り、入力済みの値は維持されることを確認する。
+	root := t.TempDir()
+	category := "cat"
+	if err := os.MkdirAll(filepath.Join(root, category), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	meta := `{"format_version":1,"default_assignee":"alice","default_priority":"Low","description_template":"## 手順"}`
+	if err := os.WriteFile(filepath.Join(root, category, ".category.json"), []byte(meta), 0o600); err != nil {
+		t.Fatalf("write meta: %v", err)
+	}
+	service := NewService(root, nil)
+
+	created, err := service.CreateIssue(category, mod.ModeContractor, IssueCreateInput{
+		Title:   "title",
+		DueDate: "2024-01-01",
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	if created.Issue.Assignee != "alice" || created.Issue.Priority != issue.PriorityLow || created.Issue.Description != "## 手順" {
+		t.Fatalf("expected defaults to be applied: %+v", created.Issue)
+	}
+
+	explicit, err := service.CreateIssue(category, mod.ModeContractor, IssueCreateInput{
+		Title:       "title",
+		Description: "desc",
+		DueDate:     "2024-01-01",
+		Priority:    issue.PriorityHigh,
+		Assignee:    "bob",
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	if explicit.Issue.Assignee != "bob" || explicit.Issue.Priority != issue.PriorityHigh || explicit.Issue.Description != "desc" {
+		t.Fatalf("expected explicit values to win: %+v", explicit.Issue)
+	}
+}
+
+func TestListIssues_UsesIndexAndDetectsExternalEdit(t *testing.T) {
+	// 課題保存で索引が作成され、外部で書き換えられた課題は一覧取得時に再読込されることを確認する。
+	root := t.TempDir()
+	category := "cat"
+	if err := os.MkdirAll(filepath.Join(root, category), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	service := NewService(root, nil)
+	created, err := service.CreateIssue(category, mod.ModeContractor, IssueCreateInput{
+		Title:       "before",
+		Description: "desc",
+		DueDate:     "2024-01-01",
+		Priority:    issue.PriorityHigh,
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(root, ".ratta", "index.json")); statErr != nil {
+		t.Fatalf("expected index to be written, err=%v", statErr)
+	}
+
+	edited := created.Issue
+	edited.Title = "after external edit"
+	data, err := jsonfmt.MarshalIssue(edited)
+	if err != nil {
+		t.Fatalf("MarshalIssue error: %v", err)
+	}
+	if writeErr := os.WriteFile(created.Path, data, 0o600); writeErr != nil {
+		t.Fatalf("write issue: %v", writeErr)
+	}
+
+	list, err := service.ListIssues(category, IssueListQuery{})
+	if err != nil {
+		t.Fatalf("ListIssues error: %v", err)
+	}
+	if list.Total != 1 || list.Issues[0].Title != "after external edit" {
+		t.Fatalf("unexpected list: %+v", list.Issues)
+	}
+}
+
+func TestListIssues_SQLiteCacheMatchesIndex(t *testing.T) {
+	// SQLite キャッシュ有効時も索引経由と同じ絞り込み・並び順の結果になることを確認する。
+	root := t.TempDir()
+	category := "cat"
+	if err := os.MkdirAll(filepath.Join(root, category), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	service := NewService(root, nil)
+	for _, priority := range []issue.Priority{issue.PriorityLow, issue.PriorityHigh, issue.PriorityMedium} {
+		if _, err := service.CreateIssue(category, mod.ModeContractor, IssueCreateInput{
+			Title:       string(priority),
+			Description: "desc",
+			DueDate:     "2024-01-01",
+			Priority:    priority,
+		}); err != nil {
+			t.Fatalf("CreateIssue error: %v", err)
+		}
+	}
+	query := IssueListQuery{SortBy: "priority", SortOrder: "desc"}
+	fromIndex, err := service.ListIssues(category, query)
+	if err != nil {
+		t.Fatalf("ListIssues error: %v", err)
+	}
+
+	if enableErr := service.SetCacheEnabled(true, mod.ModeVendor); enableErr == nil {
+		t.Fatal("expected permission error")
+	}
+	if enableErr := service.SetCacheEnabled(true, mod.ModeContractor); enableErr != nil {
+		t.Fatalf("SetCacheEnabled error: %v", enableErr)
+	}
+	fromCache, err := service.ListIssues(category, query)
+	if err != nil {
+		t.Fatalf("ListIssues error: %v", err)
+	}
+	if fromCache.Total != fromIndex.Total || len(fromCache.Issues) != len(fromIndex.Issues) {
+		t.Fatalf("unexpected totals: cache=%d index=%d", fromCache.Total, fromIndex.Total)
+	}
+	for i := range fromIndex.Issues {
+		if fromCache.Issues[i].IssueID != fromIndex.Issues[i].IssueID {
+			t.Fatalf("order mismatch at %d: cache=%+v index=%+v", i, fromCache.Issues, fromIndex.Issues)
+		}
+	}
+	if fromCache.Issues[0].Priority != string(issue.PriorityLow) {
+		t.Fatalf("unexpected first item: %+v", fromCache.Issues[0])
+	}
+
+	filtered, err := service.ListIssues(category, IssueListQuery{Priority: string(issue.PriorityHigh)})
+	if err != nil {
+		t.Fatalf("ListIssues error: %v", err)
+	}
+	if filtered.Total != 1 {
+		t.Fatalf("unexpected filtered total: %d", filtered.Total)
+	}
+}
+
+func TestCategoryPermissions_RestrictIssueChanges(t *testing.T) {
+	// カテゴリ権限で Contractor のみに制限したカテゴリでは Vendor の作成・更新・コメントを拒否し、
+	// Contractor は変更できることを確認する。
+	root := t.TempDir()
+	category := "internal"
+	if err := os.MkdirAll(filepath.Join(root, category), 0o750); err != nil {
+		t.Fatalf("mkdir category: %v", err)
+	}
+	permissions := projectmeta.Permissions{Categories: map[string]projectmeta.CategoryPermission{
+		category: {Writers: []mod.Mode{mod.ModeContractor}},
+	}}
+	if err := projectmeta.SavePermissions(root, permissions); err != nil {
+		t.Fatalf("SavePermissions error: %v", err)
+	}
+	validator, err := schema.NewValidatorFromDir(filepath.Join("..", "..", "..", "schemas"))
+	if err != nil {
+		t.Fatalf("NewValidatorFromDir error: %v", err)
+	}
+	service := NewService(root, validator)
+	input := IssueCreateInput{Title: "title", Description: "desc", DueDate: "2024-01-01", Priority: issue.PriorityHigh}
+
+	if _, err := service.CreateIssue(category, mod.ModeVendor, input); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Fatalf("expected permission error for vendor, got %v", err)
+	}
+	created, err := service.CreateIssue(category, mod.ModeContractor, input)
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	update := IssueUpdateInput{Title: "changed", Description: "desc", DueDate: "2024-01-01", Priority: issue.PriorityHigh, Status: issue.StatusWorking}
+	if _, err := service.UpdateIssue(category, created.Issue.IssueID, mod.ModeVendor, update); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Fatalf("expected permission error for vendor update, got %v", err)
+	}
+	comment := CommentCreateInput{Body: "body", AuthorName: "vendor"}
+	if _, err := service.AddComment(category, created.Issue.IssueID, mod.ModeVendor, comment); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Fatalf("expected permission error for vendor comment, got %v", err)
+	}
+	if _, err := service.UpdateIssue(category, created.Issue.IssueID, mod.ModeContractor, update); err != nil {
+		t.Fatalf("UpdateIssue error: %v", err)
+	}
+}
+
+func TestCreateIssue_FollowsProjectFormat(t *testing.T) {
+	// プロジェクトの整形の設定に従い、課題 JSON を CRLF と指定のインデント幅で保存し、そのまま読み戻せることを確認する。
+	root := t.TempDir()
+	category := "cat"
+	if err := os.MkdirAll(filepath.Join(root, category), 0o750); err != nil {
+		t.Fatalf("mkdir category: %v", err)
+	}
+	if err := os.MkdirAll(projectmeta.Dir(root), 0o750); err != nil {
+		t.Fatalf("mkdir meta: %v", err)
+	}
+	settings := `{"format_version": 1, "line_ending": "crlf", "indent_width": 4}`
+	if err := os.WriteFile(projectmeta.FormatPath(root), []byte(settings), 0o600); err != nil {
+		t.Fatalf("write format settings: %v", err)
+	}
+	validator, err := schema.NewValidatorFromDir(filepath.Join("..", "..", "..", "schemas"))
+	if err != nil {
+		t.Fatalf("NewValidatorFromDir error: %v", err)
+	}
+	service := NewService(root, validator)
+
+	detail, err := service.CreateIssue(category, mod.ModeVendor, IssueCreateInput{
+		Title:       "title",
+		Description: "desc",
+		DueDate:     "2024-01-01",
+		Priority:    issue.PriorityHigh,
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	// #nosec G304 -- テスト用の一時ディレクトリ配下を読むため安全。
+	data, err := os.ReadFile(detail.Path)
+	if err != nil {
+		t.Fatalf("read issue: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "{\r\n    \"version\": 2,\r\n") || strings.Contains(strings.ReplaceAll(string(data), "\r\n", ""), "\n") {
+		t.Fatalf("unexpected issue JSON:\n%q", string(data))
+	}
+	if _, err := service.GetIssue(category, detail.Issue.IssueID); err != nil {
+		t.Fatalf("GetIssue error: %v", err)
+	}
+}
+
+func TestRuleChecker_RejectsBeforeSaving(t *testing.T) {
+	// 追加の規則に反する作成・更新・コメントの追加は保存せずに拒み、コメントの添付も残さないことを確認する。
+	service, root := newBundleTestService(t, "cat")
+	var checked []string
+	service.WithRuleChecker(func(category string, value issue.Issue) error {
+		checked = append(checked, category)
+		if strings.Contains(value.Title, "vague") || (len(value.Comments) > 0 && value.Comments[len(value.Comments)-1].Body == "vague") {
+			return issue.ValidationErrors{{Field: "title", Message: "rules: too vague"}}
+		}
+		return nil
+	})
+	input := IssueCreateInput{Title: "vague", Description: "desc", DueDate: "2024-02-01", Priority: issue.PriorityMedium}
+	var errs issue.ValidationErrors
+	if _, err := service.CreateIssue("cat", mod.ModeVendor, input); !errors.As(err, &errs) {
+		t.Fatalf("expected rule violation on create, got %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(root, "cat")); len(entries) != 0 {
+		t.Fatalf("expected no issue file, got %d entries", len(entries))
+	}
+
+	input.Title = "clear"
+	created, err := service.CreateIssue("cat", mod.ModeVendor, input)
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	if _, err := service.UpdateIssue("cat", created.Issue.IssueID, mod.ModeVendor, updateInput(created, "still vague", "")); !errors.As(err, &errs) {
+		t.Fatalf("expected rule violation on update, got %v", err)
+	}
+	_, err = service.AddComment("cat", created.Issue.IssueID, mod.ModeVendor, CommentCreateInput{
+		Body:        "vague",
+		AuthorName:  "sato",
+		Attachments: []CommentAttachmentInput{{OriginalName: "log.txt", Data: []byte("log"), MimeType: "text/plain"}},
+	})
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected rule violation on comment, got %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(root, "cat", created.Issue.IssueID+".files")); len(entries) != 0 {
+		t.Fatalf("expected attachments to be rolled back, got %d entries", len(entries))
+	}
+	if current, _ := service.GetIssue("cat", created.Issue.IssueID); current.Issue.Title != "clear" || len(current.Issue.Comments) != 0 {
+		t.Fatalf("expected the issue to be unchanged: %+v", current.Issue)
+	}
+	if len(checked) != 4 || checked[0] != "cat" {
+		t.Fatalf("unexpected checks: %v", checked)
+	}
+}
+
+func TestCheckRevert_AppliesUpdateRules(t *testing.T) {
+	// 取り消しは課題JSONを直接書き戻すため、課題の更新と同じ規則を取り消す時点の操作モードで適用することを確認する。
+	root := t.TempDir()
+	for _, category := range []string{"cat", "internal", "old"} {
+		if err := os.MkdirAll(filepath.Join(root, category), 0o750); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	service := NewService(root, nil)
+	input := IssueCreateInput{Title: "title", Description: "desc", DueDate: "2024-01-01", Priority: issue.PriorityHigh}
+	create := func(category string) IssueDetail {
+		t.Helper()
+		created, err := service.CreateIssue(category, mod.ModeContractor, input)
+		if err != nil {
+			t.Fatalf("CreateIssue error: %v", err)
+		}
+		return created
+	}
+	open, restricted, archived, closed := create("cat"), create("internal"), create("old"), create("cat")
+	closing := updateInput(closed, closed.Issue.Title, "")
+	closing.Status = issue.StatusClosed
+	if _, err := service.UpdateIssue("cat", closed.Issue.IssueID, mod.ModeContractor, closing); err != nil {
+		t.Fatalf("UpdateIssue error: %v", err)
+	}
+	permissions := projectmeta.Permissions{Categories: map[string]projectmeta.CategoryPermission{
+		"internal": {Writers: []mod.Mode{mod.ModeContractor}},
+	}}
+	if err := projectmeta.SavePermissions(root, permissions); err != nil {
+		t.Fatalf("SavePermissions error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "old", ".archived"), nil, 0o600); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+	working := open.Issue
+	working.Status = issue.StatusWorking
+	reopened := closed.Issue
+	reopened.Status = issue.StatusOpen
+	toClosed := open.Issue
+	toClosed.Status = issue.StatusClosed
+
+	cases := []struct {
+		name     string
+		category string
+		issueID  string
+		mode     mod.Mode
+		restored *issue.Issue
+		wantErr  string
+	}{
+		// 状態の規則に反しない取り消しは許す。作成の取り消し (restored が nil) も同じ検査を通る。
+		{name: "vendor restores open status", category: "cat", issueID: open.Issue.IssueID, mode: mod.ModeVendor, restored: &working},
+		{name: "contractor removes created issue", category: "cat", issueID: open.Issue.IssueID, mode: mod.ModeContractor},
+		// 閲覧専用モードは取り消しでも変更できない。
+		{name: "observer", category: "cat", issueID: open.Issue.IssueID, mode: mod.ModeObserver, restored: &working, wantErr: "permission denied"},
+		// Contractor のみが書き込めるカテゴリは、操作したのが Contractor でも Vendor へ戻った後は取り消せない。
+		{name: "vendor in contractor-only category", category: "internal", issueID: restricted.Issue.IssueID, mode: mod.ModeVendor, wantErr: "permission denied"},
+		// アーカイブ済みカテゴリは読み取り専用のため取り消せない。
+		{name: "archived category", category: "old", issueID: archived.Issue.IssueID, mode: mod.ModeContractor, wantErr: "archived"},
+		// 終状態の課題はモードによらず変更できないため、Contractor が閉じた課題を開き直す取り消しも拒否する。
+		{name: "vendor undoes close", category: "cat", issueID: closed.Issue.IssueID, mode: mod.ModeVendor, restored: &reopened, wantErr: "cannot be reverted"},
+		{name: "contractor undoes close", category: "cat", issueID: closed.Issue.IssueID, mode: mod.ModeContractor, restored: &reopened, wantErr: "cannot be reverted"},
+		// Vendor は Closed/Rejected へ遷移できないため、取り消しでも Closed へ戻さない。
+		{name: "vendor restores closed status", category: "cat", issueID: open.Issue.IssueID, mode: mod.ModeVendor, restored: &toClosed, wantErr: "status transition"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := service.CheckRevert(tc.category, tc.issueID, tc.mode, tc.restored)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("CheckRevert error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected %q error, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestUpdateIssue_KeepsBackupOfIssueFile(t *testing.T) {
+	// バックアップの世代数を設定した場合、課題JSONの更新では置き換える前の内容を .bak として残すことを確認する。
+	atomicwrite.SetBackupGenerations(1)
+	t.Cleanup(func() { atomicwrite.SetBackupGenerations(0) })
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "cat"), 0o750); err != nil {
+		t.Fatalf("mkdir category: %v", err)
+	}
+	validator, err := schema.NewValidatorFromDir(filepath.Join("..", "..", "..", "schemas"))
+	if err != nil {
+		t.Fatalf("NewValidatorFromDir error: %v", err)
+	}
+	service := NewService(root, validator)
+	created, err := service.CreateIssue("cat", mod.ModeVendor, IssueCreateInput{
+		Title: "before", Description: "desc", DueDate: "2024-01-01", Priority: issue.PriorityHigh,
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	if _, err := service.UpdateIssue("cat", created.Issue.IssueID, mod.ModeVendor, IssueUpdateInput{
+		Title: "after", Description: "desc", DueDate: "2024-01-01", Priority: issue.PriorityHigh, Status: issue.StatusOpen,
+	}); err != nil {
+		t.Fatalf("UpdateIssue error: %v", err)
+	}
+	// #nosec G304 -- テスト用の一時ディレクトリ配下を読むため安全。
+	backup, err := os.ReadFile(atomicwrite.BackupPath(created.Path, 1))
+	if err != nil || !strings.Contains(string(backup), `"before"`) {
+		t.Fatalf("expected backup of previous issue, got %q err=%v", string(backup), err)
+	}
+}
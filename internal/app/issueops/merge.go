@@ -0,0 +1,51 @@
+// merge.go は同期・パッチの取り込みで別の版の課題から項目を取り込む際の項目の一覧と状態の規則の検査を担い、
+// どちらの版の値を採用するかの判定は扱わない。判定は projectsync・patchbundle が担う。
+package issueops
+
+import (
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// MergeField は DD-SYNC-002/DD-PATCH-002 の別の版から取り込む課題の項目1件を表す。Name は課題JSONの項目名を表す。
+type MergeField struct {
+	Name string
+	Get  func(issue.Issue) any
+	Set  func(dst *issue.Issue, value any)
+}
+
+// MergeFields は DD-SYNC-002/DD-PATCH-002 の別の版と比べて取り込む課題の項目を表す。
+// 課題ID・起票会社・作成日時は変更されないため対象としない。custom_fields は同期ではキーごと、パッチでは全体で扱うため、
+// 呼び出し側で扱う。コメントは和集合として呼び出し側で扱う。
+var MergeFields = []MergeField{
+	{"title", func(i issue.Issue) any { return i.Title }, func(i *issue.Issue, v any) { i.Title = v.(string) }},
+	{"description", func(i issue.Issue) any { return i.Description }, func(i *issue.Issue, v any) { i.Description = v.(string) }},
+	{"status", func(i issue.Issue) any { return i.Status }, func(i *issue.Issue, v any) { i.Status = v.(issue.Status) }},
+	{"priority", func(i issue.Issue) any { return i.Priority }, func(i *issue.Issue, v any) { i.Priority = v.(issue.Priority) }},
+	{"assignee", func(i issue.Issue) any { return i.Assignee }, func(i *issue.Issue, v any) { i.Assignee = v.(string) }},
+	{"due_date", func(i issue.Issue) any { return i.DueDate }, func(i *issue.Issue, v any) { i.DueDate = v.(string) }},
+	{"tags", func(i issue.Issue) any { return i.Tags }, func(i *issue.Issue, v any) { i.Tags = v.([]string) }},
+}
+
+// CheckMerge は DD-SYNC-002/DD-PATCH-002 の別の版から取り込んだ内容 merged で、保存済みの課題 current を上書きしてよいかを判定する。
+// 目的: 同期・パッチの取り込みが SaveImportedIssue で状態の規則を通らずに保存するため、課題の更新・コメントの追加と同じ規則を適用する。
+// 入力: current は上書きする側の保存済みの課題、merged は取り込んだ後の課題、currentMode は取り込みを行う操作モード。
+// merged が current と異なる場合のみ呼び出す。
+// 出力: 上書きしてよい場合は nil。
+// エラー: current が終状態 (Closed/Rejected) の場合は E_READ_ONLY、current の状態から merged の状態への遷移が操作モードで
+// 許されない場合は E_PERMISSION を返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 終状態の課題は項目もコメントも変更せず、Vendor は取り込みでも課題を Closed/Rejected にしない。
+// 関連DD: DD-SYNC-002, DD-PATCH-002, DD-BE-003
+func CheckMerge(current, merged issue.Issue, currentMode mod.Mode) error {
+	if current.Status.IsEndState() {
+		return apperr.Errorf(apperr.ErrReadOnly, "closed or rejected issue cannot be updated: %s", current.IssueID)
+	}
+	if merged.Status != current.Status && !mod.CanTransitionStatus(current.Status, merged.Status, currentMode) {
+		return apperr.Errorf(apperr.ErrPermission, "permission denied: status transition %s -> %s is not allowed in %s mode", current.Status, merged.Status, currentMode)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// merge_test.go は同期・パッチの取り込みで共通に用いる項目の一覧と状態の規則の検査のテストを行う。
+package issueops
+
+import (
+	"errors"
+	"testing"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+
+	mod "ratta/internal/domain/mode"
+)
+
+func TestMergeFields_CopyEachField(t *testing.T) {
+	// 各項目の Get で取り出した値を Set で別の課題へ設定すると、その項目のみが同じ値になることを確認する。
+	src := issue.Issue{
+		Title: "t", Description: "d", Status: issue.StatusWorking, Priority: issue.PriorityHigh,
+		Assignee: "sato", DueDate: "2024-02-01", Tags: []string{"ui"},
+	}
+	dst := issue.Issue{}
+	for _, f := range MergeFields {
+		f.Set(&dst, f.Get(src))
+	}
+	if dst.Title != "t" || dst.Description != "d" || dst.Status != issue.StatusWorking || dst.Priority != issue.PriorityHigh ||
+		dst.Assignee != "sato" || dst.DueDate != "2024-02-01" || len(dst.Tags) != 1 {
+		t.Fatalf("unexpected copied issue: %+v", dst)
+	}
+	if dst.IssueID != "" || dst.CreatedAt != "" {
+		t.Fatalf("expected identity fields not to be copied: %+v", dst)
+	}
+}
+
+func TestCheckMerge_AppliesStatusRules(t *testing.T) {
+	// 取り込みで上書きする場合も、課題の更新・コメントの追加と同じ終状態と遷移の規則を操作モードで適用することを確認する。
+	open := issue.Issue{IssueID: "a", Status: issue.StatusOpen}
+	closed := issue.Issue{IssueID: "a", Status: issue.StatusClosed}
+	working := issue.Issue{IssueID: "a", Status: issue.StatusWorking}
+	commented := closed
+	commented.Comments = []issue.Comment{{CommentID: "c"}}
+
+	cases := []struct {
+		name    string
+		current issue.Issue
+		merged  issue.Issue
+		mode    mod.Mode
+		want    error
+	}{
+		{name: "vendor moves to working", current: open, merged: working, mode: mod.ModeVendor},
+		{name: "contractor closes", current: open, merged: closed, mode: mod.ModeContractor},
+		// Vendor は Closed/Rejected へ遷移できないため、相手の版が閉じていても取り込まない。
+		{name: "vendor closes", current: open, merged: closed, mode: mod.ModeVendor, want: apperr.ErrPermission},
+		// 終状態の課題はモードによらず、状態を変えないコメントの追加も含めて変更しない。
+		{name: "contractor reopens", current: closed, merged: open, mode: mod.ModeContractor, want: apperr.ErrReadOnly},
+		{name: "comment on closed", current: closed, merged: commented, mode: mod.ModeContractor, want: apperr.ErrReadOnly},
+		// Observer は状態を変えられない。
+		{name: "observer moves to working", current: open, merged: working, mode: mod.ModeObserver, want: apperr.ErrPermission},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckMerge(tc.current, tc.merged, tc.mode)
+			if tc.want == nil {
+				if err != nil {
+					t.Fatalf("CheckMerge error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// revision.go は課題JSONの版の算出と、読み込み後に共有フォルダ上で他の利用者が課題を変更した場合の検出を担い、
+// 変更の統合は UI に委ねる。
+package issueops
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// ExternalChangeError は DD-PERSIST-006 の保存しようとした課題JSONが、利用者が読み込んだ後に外部で変更されていたことを表す。
+// Current は現在のファイルの内容、Attempted は利用者の変更を現在の内容へ適用した課題を表し、UI は両者を比べて統合を促す。
+// 種別は apperr.ErrConflict とする。
+type ExternalChangeError struct {
+	Path             string
+	ExpectedRevision string
+	Current          IssueDetail
+	Attempted        issue.Issue
+}
+
+// Error はエラーメッセージを返す。
+func (e *ExternalChangeError) Error() string {
+	return fmt.Sprintf("issue was modified externally after it was loaded: %s/%s", e.Current.Issue.Category, e.Current.Issue.IssueID)
+}
+
+// Unwrap は種別を返し、apperr.Kind で ErrConflict と判定できるようにする。
+func (e *ExternalChangeError) Unwrap() error {
+	return apperr.ErrConflict
+}
+
+// revisionOf は DD-PERSIST-006 の課題JSONの内容 data から版を求める。
+// 共有フォルダでは更新日時の精度や複製時の扱いが環境により異なるため、更新日時は用いず内容のハッシュとする。
+func revisionOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// checkRevision は DD-PERSIST-006 の現在の課題JSON current が利用者の読み込んだ版 expected から変わっていないかを確かめる。
+// expected が空の場合は確かめない。変わっている場合は attempted を添えた *ExternalChangeError を対象のパスと統合の指針とともに返す。
+func checkRevision(current IssueDetail, expected string, attempted issue.Issue) error {
+	if expected == "" || expected == current.Revision {
+		return nil
+	}
+	return apperr.WithPath(&ExternalChangeError{
+		Path:             current.Path,
+		ExpectedRevision: expected,
+		Current:          current,
+		Attempted:        attempted,
+	}, current.Path, apperr.HintMergeChanges)
+}
+
+// pendingComment は DD-PERSIST-006 の競合の報告用に、追加しようとしたコメントを現在の課題 current へ加えた課題を返す。
+// 添付はまだ保存していないため、ファイル名のみを持つ。
+func pendingComment(current issue.Issue, currentMode mod.Mode, input CommentCreateInput) issue.Issue {
+	comment := issue.Comment{
+		Body:          input.Body,
+		AuthorName:    input.AuthorName,
+		AuthorCompany: originCompany(currentMode),
+		CreatedAt:     nowISO(),
+		Attachments:   []issue.AttachmentRef{},
+	}
+	for _, attachment := range input.Attachments {
+		comment.Attachments = append(comment.Attachments, issue.AttachmentRef{FileName: attachment.OriginalName, MimeType: attachment.MimeType})
+	}
+	attempted := current
+	attempted.Comments = append(append([]issue.Comment{}, current.Comments...), comment)
+	return attempted
+}
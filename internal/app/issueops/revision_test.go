@@ -0,0 +1,124 @@
+// revision_test.go は課題JSONの版の算出と、読み込み後の外部での変更を検出して保存を拒むことのテストを行う。
+package issueops
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// createRevisionIssue はテスト用に課題を作成し、読み込んだ課題詳細を返す。
+func createRevisionIssue(t *testing.T, service *Service) IssueDetail {
+	t.Helper()
+	created, err := service.CreateIssue("cat", mod.ModeVendor, IssueCreateInput{
+		Title:       "title",
+		Description: "desc",
+		DueDate:     "2024-02-01",
+		Priority:    issue.PriorityMedium,
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	loaded, err := service.GetIssue("cat", created.Issue.IssueID)
+	if err != nil {
+		t.Fatalf("GetIssue error: %v", err)
+	}
+	if loaded.Revision == "" || loaded.Revision != created.Revision {
+		t.Fatalf("expected the written revision to match the loaded one: %q %q", created.Revision, loaded.Revision)
+	}
+	return loaded
+}
+
+// changeExternally はテスト用に他の利用者による課題JSONの変更を再現する。
+func changeExternally(t *testing.T, service *Service, detail IssueDetail, title string) {
+	t.Helper()
+	changed := detail.Issue
+	changed.Title = title
+	if _, err := service.writeIssue(detail.Path, changed); err != nil {
+		t.Fatalf("writeIssue error: %v", err)
+	}
+}
+
+// updateInput はテスト用に課題の内容を保ったまま題名と版を指定した更新入力を返す。
+func updateInput(detail IssueDetail, title, expected string) IssueUpdateInput {
+	return IssueUpdateInput{
+		Title:            title,
+		Description:      detail.Issue.Description,
+		DueDate:          detail.Issue.DueDate,
+		Priority:         detail.Issue.Priority,
+		Status:           detail.Issue.Status,
+		Assignee:         detail.Issue.Assignee,
+		ExpectedRevision: expected,
+	}
+}
+
+func TestUpdateIssue_RejectsExternalChange(t *testing.T) {
+	// 読み込んだ版のままであれば保存して新しい版を返し、外部で変更された後は上書きせずに両方の内容を返すことを確認する。
+	service, _ := newBundleTestService(t, "cat")
+	loaded := createRevisionIssue(t, service)
+
+	saved, err := service.UpdateIssue("cat", loaded.Issue.IssueID, mod.ModeVendor, updateInput(loaded, "mine", loaded.Revision))
+	if err != nil || saved.Revision == loaded.Revision {
+		t.Fatalf("expected update with a new revision: %+v %v", saved, err)
+	}
+	changeExternally(t, service, saved, "theirs")
+
+	_, err = service.UpdateIssue("cat", loaded.Issue.IssueID, mod.ModeVendor, updateInput(saved, "mine again", saved.Revision))
+	var changed *ExternalChangeError
+	if !errors.As(err, &changed) || apperr.Kind(err) != apperr.ErrConflict {
+		t.Fatalf("expected external change error, got %v", err)
+	}
+	var pathErr *apperr.PathError
+	if !errors.As(err, &pathErr) || pathErr.Path != saved.Path || pathErr.Hint != apperr.HintMergeChanges {
+		t.Fatalf("expected path and hint, got %+v", pathErr)
+	}
+	if changed.Current.Issue.Title != "theirs" || changed.Attempted.Title != "mine again" || changed.ExpectedRevision != saved.Revision {
+		t.Fatalf("unexpected conflict: %+v", changed)
+	}
+	if current, _ := service.GetIssue("cat", loaded.Issue.IssueID); current.Issue.Title != "theirs" || current.Revision != changed.Current.Revision {
+		t.Fatalf("expected the external change to be kept: %+v", current)
+	}
+
+	if _, err := service.UpdateIssue("cat", loaded.Issue.IssueID, mod.ModeVendor, updateInput(saved, "forced", "")); err != nil {
+		t.Fatalf("expected an empty revision to skip the check: %v", err)
+	}
+}
+
+func TestAddComment_RejectsExternalChangeBeforeSavingAttachments(t *testing.T) {
+	// 外部で変更された後のコメント追加は添付を保存せずに拒み、追加しようとしたコメントを現在の内容へ加えて返すことを確認する。
+	service, root := newBundleTestService(t, "cat")
+	loaded := createRevisionIssue(t, service)
+	changeExternally(t, service, loaded, "theirs")
+
+	_, err := service.AddComment("cat", loaded.Issue.IssueID, mod.ModeVendor, CommentCreateInput{
+		Body:             "reply",
+		AuthorName:       "sato",
+		Attachments:      []CommentAttachmentInput{{OriginalName: "log.txt", Data: []byte("log"), MimeType: "text/plain"}},
+		ExpectedRevision: loaded.Revision,
+	})
+	var changed *ExternalChangeError
+	if !errors.As(err, &changed) {
+		t.Fatalf("expected external change error, got %v", err)
+	}
+	comments := changed.Attempted.Comments
+	if changed.Attempted.Title != "theirs" || len(comments) != 1 || comments[0].Body != "reply" || comments[0].Attachments[0].FileName != "log.txt" {
+		t.Fatalf("unexpected attempted issue: %+v", changed.Attempted)
+	}
+	if _, statErr := os.Stat(filepath.Join(root, "cat", loaded.Issue.IssueID+".files")); !os.IsNotExist(statErr) {
+		t.Fatalf("expected no attachments to be saved: %v", statErr)
+	}
+
+	if _, err := service.AddComment("cat", loaded.Issue.IssueID, mod.ModeVendor, CommentCreateInput{
+		Body:             "reply",
+		AuthorName:       "sato",
+		ExpectedRevision: changed.Current.Revision,
+	}); err != nil {
+		t.Fatalf("expected comment with the current revision to be saved: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+// search.go は課題の全文検索ユースケースを担い、索引の構築方式や形態素解析の詳細は fulltext に委ねる。
+package issueops
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"ratta/internal/app/categoryscan"
+	"ratta/internal/domain/apperr"
+	"ratta/internal/infra/fulltext"
+)
+
+// SearchHit は DD-SEARCH-001 の検索結果1件を表す。
+type SearchHit struct {
+	Summary   IssueSummary
+	Score     float64
+	Locations []fulltext.Location
+}
+
+// SearchIssues は DD-SEARCH-001 の全文検索を行う。
+// 目的: タイトル・説明・コメントを対象に課題を検索し、スコア順の要約と一致箇所を返す。
+// 入力: text は検索文字列、scope は対象カテゴリ名 (空ならプロジェクト全体)、limit は最大件数。
+// 出力: スコア降順の SearchHit 一覧とエラー。
+// エラー: カテゴリ走査失敗、対象カテゴリ不在、索引の更新・検索失敗時に返す。
+// 副作用: 検索前に変更された課題のみを .ratta/search.bleve へ再索引化する。
+// 並行性: 索引は同時に1つしか開けないため、同時検索は呼び出し側で排他する。
+// 不変条件: 検索後に削除・破損した課題は結果に含めない。
+// 関連DD: DD-SEARCH-001, DD-LOAD-004
+func (s *Service) SearchIssues(text, scope string, limit int) ([]SearchHit, error) {
+	return s.SearchIssuesContext(context.Background(), text, scope, limit)
+}
+
+// SearchIssuesContext は DD-SEARCH-001/DD-CANCEL-001 の中断可能な全文検索を行う。
+// 索引の差分更新の途中で中断された場合、更新済みのカテゴリは索引に反映されたまま ctx.Err() を返す。
+func (s *Service) SearchIssuesContext(ctx context.Context, text, scope string, limit int) (hits []SearchHit, err error) {
+	if strings.TrimSpace(text) == "" {
+		return []SearchHit{}, nil
+	}
+	scanned, err := categoryscan.ScanContext(ctx, s.projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	paths := make(map[string]string, len(scanned.Categories))
+	for _, category := range scanned.Categories {
+		if scope == "" || category.Name == scope {
+			paths[category.Name] = category.Path
+		}
+	}
+	if scope != "" && len(paths) == 0 {
+		return nil, apperr.New(apperr.ErrNotFound, "category not found")
+	}
+
+	index, err := fulltext.Open(s.projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := index.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	for name, path := range paths {
+		if syncErr := index.SyncCategoryContext(ctx, path, name, s.searchLoader(name)); syncErr != nil {
+			return nil, syncErr
+		}
+	}
+	var categories []string
+	if scope != "" {
+		categories = []string{scope}
+	}
+	found, err := index.Search(text, categories, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	hits = make([]SearchHit, 0, len(found))
+	for _, hit := range found {
+		categoryPath, ok := paths[hit.Category]
+		if !ok {
+			continue
+		}
+		detail, readErr := s.readIssue(filepath.Join(categoryPath, hit.IssueID+".json"), hit.Category)
+		if readErr != nil {
+			continue
+		}
+		entry := toIndexEntry(detail)
+		entry.FileName = filepath.Base(detail.Path)
+		summary := toIssueSummary(categoryPath, hit.Category, entry)
+		hits = append(hits, SearchHit{Summary: summary, Score: hit.Score, Locations: hit.Locations})
+	}
+	return hits, nil
+}
+
+// searchLoader は DD-SEARCH-001 の索引向けに課題JSONを読み込む関数を返す。
+func (s *Service) searchLoader(category string) fulltext.LoadFunc {
+	return func(path string) (fulltext.Document, error) {
+		detail, readErr := s.readIssue(path, category)
+		if readErr != nil {
+			return fulltext.Document{}, readErr
+		}
+		comments := make([]string, 0, len(detail.Issue.Comments))
+		for _, comment := range detail.Issue.Comments {
+			comments = append(comments, comment.Body)
+		}
+		return fulltext.Document{
+			IssueID:     strings.TrimSuffix(filepath.Base(path), ".json"),
+			Category:    category,
+			Title:       detail.Issue.Title,
+			Description: detail.Issue.Description,
+			Comments:    comments,
+		}, nil
+	}
+}
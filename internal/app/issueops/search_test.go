@@ -0,0 +1,61 @@
+// search_test.go は課題の全文検索ユースケースのテストを行い、索引の内部構造は扱わない。
+package issueops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ratta/internal/domain/issue"
+
+	mod "ratta/internal/domain/mode"
+)
+
+func TestSearchIssues_FindsCommentsAndRespectsScope(t *testing.T) {
+	// コメント本文も検索対象となり、scope 指定時は他カテゴリを返さず、存在しない scope はエラーになることを確認する。
+	root := t.TempDir()
+	service := NewService(root, nil)
+	for _, category := range []string{"alpha", "beta"} {
+		if err := os.MkdirAll(filepath.Join(root, category), 0o750); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		created, err := service.CreateIssue(category, mod.ModeContractor, IssueCreateInput{
+			Title:       "画面崩れ",
+			Description: "desc",
+			DueDate:     "2024-01-01",
+			Priority:    issue.PriorityHigh,
+		})
+		if err != nil {
+			t.Fatalf("CreateIssue error: %v", err)
+		}
+		if _, err := service.AddComment(category, created.Issue.IssueID, mod.ModeContractor, CommentCreateInput{
+			Body:       "再現手順を追記しました",
+			AuthorName: "tester",
+		}); err != nil {
+			t.Fatalf("AddComment error: %v", err)
+		}
+	}
+
+	all, err := service.SearchIssues("再現手順", "", 0)
+	if err != nil {
+		t.Fatalf("SearchIssues error: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected 2 hits, got %+v", all)
+	}
+	if all[0].Locations[0].Field != "comments" || all[0].Summary.Title != "画面崩れ" {
+		t.Fatalf("unexpected hit: %+v", all[0])
+	}
+
+	scoped, err := service.SearchIssues("再現手順", "beta", 0)
+	if err != nil {
+		t.Fatalf("SearchIssues error: %v", err)
+	}
+	if len(scoped) != 1 || scoped[0].Summary.Category != "beta" {
+		t.Fatalf("expected beta only, got %+v", scoped)
+	}
+
+	if _, err := service.SearchIssues("再現手順", "missing", 0); err == nil {
+		t.Fatalf("expected error for missing scope")
+	}
+}
@@ -3,12 +3,15 @@
 package issuescan
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 
+	"ratta/internal/domain/issue"
 	"ratta/internal/infra/schema"
+	"ratta/internal/infra/workerpool"
 )
 
 // IssueSummary は DD-LOAD-003/004 の課題一覧向け最小情報を表す。
@@ -39,7 +42,8 @@ type ScanResult struct {
 
 // Scanner は DD-LOAD-003 の課題走査を行う。
 type Scanner struct {
-	validator *schema.Validator
+	validator   *schema.Validator
+	concurrency int
 }
 
 // NewScanner は DD-LOAD-003 のスキーマ検証を受け取って生成する。
@@ -47,46 +51,76 @@ func NewScanner(validator *schema.Validator) *Scanner {
 	return &Scanner{validator: validator}
 }
 
+// WithConcurrency は DD-SCAN-001 の読み込み並列度を設定する。0 以下は既定値を用いる。
+func (s *Scanner) WithConcurrency(concurrency int) *Scanner {
+	s.concurrency = concurrency
+	return s
+}
+
 // ScanCategory は DD-LOAD-003/004 のルールでカテゴリ配下を走査する。
 // 目的: カテゴリ配下の課題JSONを読み込み一覧項目を収集する。
 // 入力: categoryPath はカテゴリパス、categoryName はカテゴリ名。
 // 出力: ScanResult とエラー。
 // エラー: カテゴリディレクトリの読み取り失敗時に返す。
 // 副作用: なし。
-// 並行性: 読み取りのみでスレッドセーフ。
+// 並行性: 読み取りのみでスレッドセーフ。課題JSONの読み込みと検証は上限付きで並列に行う。
 // 不変条件: スキーマ不整合の課題は LoadErrors ではなく IsSchemaInvalid で表現する。
+// 結果の並びは並列度に関係なくファイル名順とする。
 // 関連DD: DD-LOAD-003, DD-LOAD-004
 func (s *Scanner) ScanCategory(categoryPath, categoryName string) (ScanResult, error) {
-	entries, err := os.ReadDir(categoryPath)
+	return s.ScanCategoryContext(context.Background(), categoryPath, categoryName)
+}
+
+// ScanCategoryContext は DD-LOAD-003/DD-CANCEL-001 の中断可能なカテゴリ走査を行う。
+// 中断された場合は途中までの結果を返さず ctx.Err() を返す。
+func (s *Scanner) ScanCategoryContext(ctx context.Context, categoryPath, categoryName string) (ScanResult, error) {
+	paths, err := issueFilePaths(categoryPath)
 	if err != nil {
-		return ScanResult{}, fmt.Errorf("read category: %w", err)
+		return ScanResult{}, err
+	}
+
+	// 読み込みと検証は並列に行い、結果はファイル名順を保つためインデックス単位で受け取る。
+	items := make([]*IssueSummary, len(paths))
+	errs := make([]error, len(paths))
+	if runErr := workerpool.RunContext(ctx, s.concurrency, len(paths), func(i int) {
+		items[i], errs[i] = s.readIssue(paths[i], categoryName)
+	}); runErr != nil {
+		return ScanResult{}, runErr
 	}
 
 	var result ScanResult
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		if filepath.Ext(entry.Name()) != ".json" {
-			continue
-		}
-		path := filepath.Join(categoryPath, entry.Name())
-		item, readErr := s.readIssue(path, categoryName)
-		if readErr != nil {
+	for i, path := range paths {
+		if errs[i] != nil {
 			result.LoadErrors = append(result.LoadErrors, LoadError{
 				Path:    path,
-				Message: readErr.Error(),
+				Message: errs[i].Error(),
 			})
 			continue
 		}
-		if item != nil {
-			result.Items = append(result.Items, *item)
+		if items[i] != nil {
+			result.Items = append(result.Items, *items[i])
 		}
 	}
 
 	return result, nil
 }
 
+// issueFilePaths は DD-LOAD-003 のカテゴリ直下の課題JSONパスをファイル名順に列挙する。
+func issueFilePaths(categoryPath string) ([]string, error) {
+	entries, err := os.ReadDir(categoryPath)
+	if err != nil {
+		return nil, fmt.Errorf("read category: %w", err)
+	}
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !issue.IsIssueFileName(entry.Name()) {
+			continue
+		}
+		paths = append(paths, filepath.Join(categoryPath, entry.Name()))
+	}
+	return paths, nil
+}
+
 // readIssue は DD-LOAD-004 の課題JSONを読み込み一覧向け情報を抽出する。
 // 目的: JSONを解析しスキーマ検証結果を付与して返す。
 // 入力: path は課題JSONのパス、categoryName はカテゴリ名。
@@ -97,6 +131,10 @@ func (s *Scanner) ScanCategory(categoryPath, categoryName string) (ScanResult, e
 // 不変条件: スキーマ不整合時は schemaInvalid を true にする。
 // 関連DD: DD-LOAD-004
 func (s *Scanner) readIssue(path, categoryName string) (*IssueSummary, error) {
+	info, statErr := os.Stat(path)
+	if statErr != nil {
+		return nil, fmt.Errorf("read issue: %w", statErr)
+	}
 	// #nosec G304 -- カテゴリ配下の列挙結果から生成したパスのみを読む。
 	data, readErr := os.ReadFile(path)
 	if readErr != nil {
@@ -109,7 +147,8 @@ func (s *Scanner) readIssue(path, categoryName string) (*IssueSummary, error) {
 	}
 
 	if s.validator != nil {
-		result, validateErr := s.validator.ValidateIssue(data)
+		// 状態は読み込み前に取得し、読み込み中の更新は次回の状態不一致で再検証させる。
+		result, validateErr := s.validator.ValidateIssueFile(path, schema.StampOf(info), data)
 		if validateErr != nil {
 			return nil, fmt.Errorf("validate issue: %w", validateErr)
 		}
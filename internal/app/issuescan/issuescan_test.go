@@ -2,6 +2,9 @@
 package issuescan
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -87,3 +90,51 @@ func TestScanCategory_ClassifiesIssues(t *testing.T) {
 		t.Fatal("expected schema invalid item")
 	}
 }
+
+func TestScanCategory_ParallelKeepsFileOrder(t *testing.T) {
+	// 並列に読み込んでも結果がファイル名順に並び、読み込みエラーも漏れなく集約されることを確認する。
+	dir := t.TempDir()
+	for i := 0; i < 40; i++ {
+		name := fmt.Sprintf("issue%02d.json", i)
+		body := fmt.Sprintf(`{"issue_id":"id%02d","title":"t"}`, i)
+		if i%10 == 0 {
+			body = "{"
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatalf("write issue: %v", err)
+		}
+	}
+
+	result, err := NewScanner(nil).WithConcurrency(4).ScanCategory(dir, "cat")
+	if err != nil {
+		t.Fatalf("ScanCategory error: %v", err)
+	}
+	if len(result.Items) != 36 || len(result.LoadErrors) != 4 {
+		t.Fatalf("unexpected result: items=%d errors=%d", len(result.Items), len(result.LoadErrors))
+	}
+	for i := 1; i < len(result.Items); i++ {
+		if result.Items[i-1].IssueID >= result.Items[i].IssueID {
+			t.Fatalf("items not ordered: %s >= %s", result.Items[i-1].IssueID, result.Items[i].IssueID)
+		}
+	}
+	if filepath.Base(result.LoadErrors[0].Path) != "issue00.json" || filepath.Base(result.LoadErrors[3].Path) != "issue30.json" {
+		t.Fatalf("load errors not ordered: %+v", result.LoadErrors)
+	}
+}
+
+func TestScanCategoryContext_ReturnsCanceled(t *testing.T) {
+	// 中断済みの context では課題を読み込まず、途中までの結果ではなく中断理由を返すことを確認する。
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "issue.json"), []byte(`{"issue_id":"id","title":"t"}`), 0o600); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := NewScanner(nil).ScanCategoryContext(ctx, dir, "cat")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled, got %v", err)
+	}
+	if len(result.Items) != 0 {
+		t.Fatalf("expected no items, got %+v", result.Items)
+	}
+}
@@ -0,0 +1,248 @@
+// stats.go はカテゴリ単位とプロジェクト全体の課題集計 (ステータス別・優先度別・期限超過・未完了の古い課題) を担い、
+// 一覧の並び替えやページングは扱わない。
+package issuescan
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+
+	"ratta/internal/app/categoryscan"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/workerpool"
+)
+
+// dueDateLayout は DD-DATA-003 の due_date 書式を表す。
+const dueDateLayout = "2006-01-02"
+
+var statsNow = time.Now
+
+// CategoryStats は DD-STATS-001 のカテゴリ集計結果を表す。
+type CategoryStats struct {
+	Category     string
+	Total        int
+	ByStatus     map[string]int
+	ByPriority   map[string]int
+	OpenCount    int
+	OverdueCount int
+	ErrorCount   int
+}
+
+// OpenIssue は DD-STATS-001 の未完了課題の要約を表す。
+type OpenIssue struct {
+	Category  string
+	IssueID   string
+	Title     string
+	Status    string
+	Priority  string
+	DueDate   string
+	CreatedAt string
+}
+
+// ProjectStats は DD-STATS-001 のプロジェクト全体の集計結果を表す。
+// OldestOpen は作成日時の古い順に並べた未完了課題、Overdue は期限の古い順に並べた期限超過の課題を表す。
+type ProjectStats struct {
+	Categories   []CategoryStats
+	Total        int
+	ByStatus     map[string]int
+	ByPriority   map[string]int
+	OpenCount    int
+	OverdueCount int
+	ErrorCount   int
+	OldestOpen   []OpenIssue
+	Overdue      []OpenIssue
+}
+
+// statsFields は集計に必要な項目だけを取り出すための最小構造を表す。
+type statsFields struct {
+	IssueID   string `json:"issue_id"`
+	Title     string `json:"title"`
+	Status    string `json:"status"`
+	Priority  string `json:"priority"`
+	DueDate   string `json:"due_date"`
+	CreatedAt string `json:"created_at"`
+}
+
+// CategoryStats は DD-STATS-001 のカテゴリ集計を行う。
+// 目的: 一覧をページングせずにステータス別・優先度別件数と期限超過件数を求める。
+// 入力: categoryPath はカテゴリパス、categoryName はカテゴリ名。
+// 出力: CategoryStats とエラー。
+// エラー: カテゴリディレクトリの読み取り失敗時に返す。
+// 副作用: なし。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: 集計に必要な項目のみを解析し、スキーマ検証は行わない。
+// 読み取り・解析できない課題は ErrorCount に計上し Total には含めない。
+// 関連DD: DD-STATS-001, DD-LOAD-003
+func (s *Scanner) CategoryStats(categoryPath, categoryName string) (CategoryStats, error) {
+	return s.CategoryStatsContext(context.Background(), categoryPath, categoryName)
+}
+
+// CategoryStatsContext は DD-STATS-001/DD-CANCEL-001 の中断可能なカテゴリ集計を行う。
+// 中断された場合は途中までの集計を返さず ctx.Err() を返す。
+func (s *Scanner) CategoryStatsContext(ctx context.Context, categoryPath, categoryName string) (CategoryStats, error) {
+	stats, _, err := s.categoryStats(ctx, categoryPath, categoryName)
+	return stats, err
+}
+
+// ProjectStatsContext は DD-STATS-001 のプロジェクト全体の集計を行う。
+// 目的: 定期報告向けに、全カテゴリの件数と期限超過の課題、対応が滞っている未完了課題を1回の走査で求める。
+// 入力: ctx は中断通知、root はプロジェクトルート、oldestLimit は OldestOpen に含める件数の上限 (0 以下は含めない)。
+// 出力: ProjectStats とエラー。
+// エラー: カテゴリ一覧または課題ディレクトリの読み取りに失敗した場合、中断された場合に返す。
+// 副作用: なし。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: アーカイブ済みカテゴリも集計し、名前変更中のカテゴリは集計しない。
+// カテゴリはカテゴリ一覧の表示順に並べ、未完了の判定は期限超過と同じく Resolved と終了状態を除く。
+// 関連DD: DD-STATS-001, DD-LOAD-002, DD-LOAD-003
+func (s *Scanner) ProjectStatsContext(ctx context.Context, root string, oldestLimit int) (ProjectStats, error) {
+	scanned, err := categoryscan.ScanContext(ctx, root)
+	if err != nil {
+		return ProjectStats{}, err
+	}
+	project := ProjectStats{
+		Categories: []CategoryStats{},
+		ByStatus:   make(map[string]int),
+		ByPriority: make(map[string]int),
+		OldestOpen: []OpenIssue{},
+		Overdue:    []OpenIssue{},
+	}
+	var open []OpenIssue
+	for _, category := range scanned.Categories {
+		if category.IsReadOnly && !category.IsArchived {
+			continue
+		}
+		stats, categoryOpen, statsErr := s.categoryStats(ctx, category.Path, category.Name)
+		if statsErr != nil {
+			return ProjectStats{}, statsErr
+		}
+		project.Categories = append(project.Categories, stats)
+		project.Total += stats.Total
+		project.OpenCount += stats.OpenCount
+		project.OverdueCount += stats.OverdueCount
+		project.ErrorCount += stats.ErrorCount
+		for key, count := range stats.ByStatus {
+			project.ByStatus[key] += count
+		}
+		for key, count := range stats.ByPriority {
+			project.ByPriority[key] += count
+		}
+		open = append(open, categoryOpen...)
+	}
+	today := statsNow().Format(dueDateLayout)
+	for _, item := range open {
+		if isOverdue(statsFields{Status: item.Status, DueDate: item.DueDate}, today) {
+			project.Overdue = append(project.Overdue, item)
+		}
+	}
+	// 期限が同じ課題はカテゴリ・課題IDで順序を固定する。
+	sort.SliceStable(project.Overdue, func(i, j int) bool {
+		if project.Overdue[i].DueDate != project.Overdue[j].DueDate {
+			return project.Overdue[i].DueDate < project.Overdue[j].DueDate
+		}
+		if project.Overdue[i].Category != project.Overdue[j].Category {
+			return project.Overdue[i].Category < project.Overdue[j].Category
+		}
+		return project.Overdue[i].IssueID < project.Overdue[j].IssueID
+	})
+	// created_at は RFC3339 の固定書式のため文字列比較で前後を判定できる。同時刻はカテゴリ・課題IDで順序を固定する。
+	sort.SliceStable(open, func(i, j int) bool {
+		if open[i].CreatedAt != open[j].CreatedAt {
+			return open[i].CreatedAt < open[j].CreatedAt
+		}
+		if open[i].Category != open[j].Category {
+			return open[i].Category < open[j].Category
+		}
+		return open[i].IssueID < open[j].IssueID
+	})
+	if oldestLimit > 0 {
+		if len(open) > oldestLimit {
+			open = open[:oldestLimit]
+		}
+		project.OldestOpen = append(project.OldestOpen, open...)
+	}
+	return project, nil
+}
+
+// categoryStats は DD-STATS-001 のカテゴリ集計を行い、集計結果と未完了課題の要約を返す。
+func (s *Scanner) categoryStats(ctx context.Context, categoryPath, categoryName string) (CategoryStats, []OpenIssue, error) {
+	paths, err := issueFilePaths(categoryPath)
+	if err != nil {
+		return CategoryStats{}, nil, err
+	}
+
+	fields := make([]statsFields, len(paths))
+	failed := make([]bool, len(paths))
+	if runErr := workerpool.RunContext(ctx, s.concurrency, len(paths), func(i int) {
+		fields[i], failed[i] = readStatsFields(paths[i])
+	}); runErr != nil {
+		return CategoryStats{}, nil, runErr
+	}
+
+	today := statsNow().Format(dueDateLayout)
+	stats := CategoryStats{
+		Category:   categoryName,
+		ByStatus:   make(map[string]int),
+		ByPriority: make(map[string]int),
+	}
+	var open []OpenIssue
+	for i := range paths {
+		if failed[i] {
+			stats.ErrorCount++
+			continue
+		}
+		stats.Total++
+		stats.ByStatus[fields[i].Status]++
+		stats.ByPriority[fields[i].Priority]++
+		if isOverdue(fields[i], today) {
+			stats.OverdueCount++
+		}
+		if isOpen(fields[i]) {
+			stats.OpenCount++
+			open = append(open, OpenIssue{
+				Category:  categoryName,
+				IssueID:   fields[i].IssueID,
+				Title:     fields[i].Title,
+				Status:    fields[i].Status,
+				Priority:  fields[i].Priority,
+				DueDate:   fields[i].DueDate,
+				CreatedAt: fields[i].CreatedAt,
+			})
+		}
+	}
+	return stats, open, nil
+}
+
+// readStatsFields は DD-STATS-001 の集計に必要な項目のみを読み取る。読み取り・解析に失敗した場合は true を返す。
+func readStatsFields(path string) (statsFields, bool) {
+	// #nosec G304 -- カテゴリ配下の列挙結果から生成したパスのみを読む。
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return statsFields{}, true
+	}
+	var fields statsFields
+	if unmarshalErr := json.Unmarshal(data, &fields); unmarshalErr != nil {
+		return statsFields{}, true
+	}
+	return fields, false
+}
+
+// isOverdue は DD-STATS-001 の期限超過判定を行う。
+// 対応済み (Resolved) と終了状態は期限を過ぎていても超過として扱わない。
+// due_date は YYYY-MM-DD 固定長のため文字列比較で日付の前後を判定できる。
+func isOverdue(fields statsFields, today string) bool {
+	if !isOpen(fields) {
+		return false
+	}
+	if _, err := time.Parse(dueDateLayout, fields.DueDate); err != nil {
+		return false
+	}
+	return fields.DueDate < today
+}
+
+// isOpen は DD-STATS-001 の未完了判定を行う。対応済み (Resolved) と終了状態は未完了として扱わない。
+func isOpen(fields statsFields) bool {
+	status := issue.Status(fields.Status)
+	return !status.IsEndState() && status != issue.StatusResolved
+}
@@ -0,0 +1,90 @@
+// stats_test.go はカテゴリ集計とプロジェクト集計のテストを行い、一覧取得は扱わない。
+package issuescan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCategoryStats_CountsStatusPriorityAndOverdue(t *testing.T) {
+	// ステータス別・優先度別件数と期限超過件数が集計され、破損JSONはエラー件数に計上されることを確認する。
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.json":         `{"status":"Open","priority":"High","due_date":"2024-01-01"}`,
+		"b.json":         `{"status":"Open","priority":"Low","due_date":"2024-02-01"}`,
+		"c.json":         `{"status":"Closed","priority":"High","due_date":"2023-12-01"}`,
+		"d.json":         `{"status":"Resolved","priority":"Medium","due_date":"2023-12-01"}`,
+		"broken.json":    `{`,
+		".category.json": `{"format_version":1}`,
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	previous := statsNow
+	statsNow = func() time.Time { return time.Date(2024, 1, 15, 12, 0, 0, 0, time.Local) }
+	t.Cleanup(func() { statsNow = previous })
+
+	stats, err := NewScanner(nil).CategoryStats(dir, "cat")
+	if err != nil {
+		t.Fatalf("CategoryStats error: %v", err)
+	}
+	if stats.Total != 4 || stats.ErrorCount != 1 {
+		t.Fatalf("unexpected totals: %+v", stats)
+	}
+	if stats.ByStatus["Open"] != 2 || stats.ByStatus["Closed"] != 1 || stats.ByPriority["High"] != 2 {
+		t.Fatalf("unexpected breakdown: %+v", stats)
+	}
+	// 前提: a.json のみが未完了かつ期限超過。
+	if stats.OverdueCount != 1 {
+		t.Fatalf("unexpected overdue count: %d", stats.OverdueCount)
+	}
+}
+
+func TestProjectStats_AggregatesCategoriesAndOldestOpen(t *testing.T) {
+	// 全カテゴリの件数が合算され、未完了課題が作成日時の古い順に上限件数まで、期限超過の課題が全件返り、名前変更中のカテゴリは除かれることを確認する。
+	root := t.TempDir()
+	files := map[string]string{
+		"alpha/a1.json":             `{"issue_id":"a1","status":"Open","priority":"High","due_date":"2024-01-01","created_at":"2023-05-01T00:00:00+09:00"}`,
+		"alpha/a2.json":             `{"issue_id":"a2","status":"Closed","priority":"Low","created_at":"2022-01-01T00:00:00+09:00"}`,
+		"beta/b1.json":              `{"issue_id":"b1","status":"Working","priority":"High","created_at":"2023-01-01T00:00:00+09:00"}`,
+		"beta/b2.json":              `{"issue_id":"b2","status":"Open","priority":"Medium","created_at":"2023-09-01T00:00:00+09:00"}`,
+		".tmp_rename/gamma/g1.json": `{"issue_id":"g1","status":"Open","priority":"High","created_at":"2020-01-01T00:00:00+09:00"}`,
+	}
+	for name, body := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatalf("mkdir %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	previous := statsNow
+	statsNow = func() time.Time { return time.Date(2024, 1, 15, 12, 0, 0, 0, time.Local) }
+	t.Cleanup(func() { statsNow = previous })
+
+	stats, err := NewScanner(nil).ProjectStatsContext(context.Background(), root, 2)
+	if err != nil {
+		t.Fatalf("ProjectStatsContext error: %v", err)
+	}
+	if len(stats.Categories) != 2 || stats.Total != 4 || stats.OpenCount != 3 || stats.OverdueCount != 1 {
+		t.Fatalf("unexpected totals: %+v", stats)
+	}
+	if stats.ByStatus["Open"] != 2 || stats.ByPriority["High"] != 2 {
+		t.Fatalf("unexpected breakdown: %+v", stats)
+	}
+	if len(stats.OldestOpen) != 2 || stats.OldestOpen[0].IssueID != "b1" || stats.OldestOpen[1].IssueID != "a1" {
+		t.Fatalf("unexpected oldest open: %+v", stats.OldestOpen)
+	}
+	if stats.OldestOpen[1].Category != "alpha" {
+		t.Fatalf("unexpected category: %+v", stats.OldestOpen[1])
+	}
+	if len(stats.Overdue) != 1 || stats.Overdue[0].IssueID != "a1" || stats.Overdue[0].DueDate != "2024-01-01" {
+		t.Fatalf("unexpected overdue: %+v", stats.Overdue)
+	}
+}
@@ -0,0 +1,233 @@
+// Package mcpserver は LLM アシスタント向けの MCP (Model Context Protocol) サーバーを標準入出力上で提供し、
+// 課題の読み込み・検索・作成の規則は issueops に委ねる。ネットワーク経由の公開や GUI の起動は扱わない。
+// 通信は1行1メッセージの JSON-RPC 2.0 とし、要求は受け取った順に1件ずつ処理する。
+package mcpserver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/infra/schema"
+
+	mod "ratta/internal/domain/mode"
+)
+
+const (
+	// protocolVersion は DD-MCP-001 で応答する MCP のプロトコルバージョンを表す。
+	protocolVersion = "2024-11-05"
+	// jsonRPCVersion は DD-MCP-001 のメッセージに付ける JSON-RPC のバージョンを表す。
+	jsonRPCVersion = "2.0"
+	// serverName は DD-MCP-001 の initialize で通知するサーバー名を表す。
+	serverName = "ratta"
+	// maxMessageBytes は DD-MCP-001 で受け付ける1メッセージの上限を表す。課題作成の入力を十分に収める大きさとする。
+	maxMessageBytes = 16 << 20
+)
+
+const (
+	// codeParseError は JSON として解析できないメッセージを表す JSON-RPC のエラーコード。
+	codeParseError = -32700
+	// codeInvalidRequest は JSON-RPC の要求の形式を満たさないメッセージを表すエラーコード。
+	codeInvalidRequest = -32600
+	// codeMethodNotFound は未対応のメソッドを表す JSON-RPC のエラーコード。
+	codeMethodNotFound = -32601
+	// codeInvalidParams はメソッドの引数の不備 (未知のツール名を含む) を表す JSON-RPC のエラーコード。
+	codeInvalidParams = -32602
+)
+
+// Server は DD-MCP-001 の MCP サーバーを表す。
+// mode は課題作成時の操作モード、readOnly は書き込みを伴うツールを公開しないことを表す。
+type Server struct {
+	root      string
+	validator *schema.Validator
+	mode      mod.Mode
+	readOnly  bool
+	version   string
+	issues    *issueops.Service
+}
+
+// request は DD-MCP-001 の JSON-RPC の要求または通知を表す。ID が無いものは通知として応答しない。
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response は DD-MCP-001 の JSON-RPC の応答を表す。Result と Error はどちらか一方のみ設定する。
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError は DD-MCP-001 の JSON-RPC のエラーを表す。
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// New は DD-MCP-001 の MCP サーバーを生成する。validator が nil の場合はスキーマ検証を省略する。
+func New(root string, validator *schema.Validator, currentMode mod.Mode) *Server {
+	return &Server{
+		root:      root,
+		validator: validator,
+		mode:      currentMode,
+		version:   "dev",
+		issues:    issueops.NewService(root, validator),
+	}
+}
+
+// WithReadOnly は DD-MCP-001 の読み取り専用での公開を設定する。true の場合は課題作成のツールを公開しない。
+func (s *Server) WithReadOnly(readOnly bool) *Server {
+	s.readOnly = readOnly
+	return s
+}
+
+// WithVersion は DD-MCP-001 の initialize で通知するサーバーのバージョンを設定する。空の場合は変更しない。
+func (s *Server) WithVersion(version string) *Server {
+	if version != "" {
+		s.version = version
+	}
+	return s
+}
+
+// Serve は DD-MCP-001 の MCP セッションを処理する。
+// 目的: LLM アシスタントから GUI と同じ規則で課題を参照・検索・起票できるようにする。
+// 入力: r は1行1メッセージの JSON-RPC の要求、w は応答の書き込み先。
+// 出力: r が終端に達した場合は nil、読み書きに失敗した場合はエラー。
+// エラー: 個々の要求の不備やツールの失敗は応答として返し、セッションは継続する。
+// 副作用: ツールに応じて課題JSONの読み込み・作成、検索索引 (.ratta/search.bleve) の更新を行う。
+// 並行性: 単一ゴルーチンで要求を受け取った順に処理する。課題作成はツール呼び出しごとに書き込み用ロックを取得する。
+// 不変条件: 通知 (id の無いメッセージ) には応答しない。読み取り専用では課題を作成しない。
+// 関連DD: DD-MCP-001, DD-BE-003, DD-LOCK-002
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageBytes)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		reply, ok := s.handle(line)
+		if !ok {
+			continue
+		}
+		if err := encoder.Encode(reply); err != nil {
+			return fmt.Errorf("write response: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read request: %w", err)
+	}
+	return nil
+}
+
+// handle は DD-MCP-001 の1メッセージを処理し、応答を返す。通知の場合は ok=false を返す。
+func (s *Server) handle(line []byte) (response, bool) {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		if !json.Valid(line) {
+			return errorResponse(nil, codeParseError, "parse error"), true
+		}
+		return errorResponse(nil, codeInvalidRequest, "invalid request"), true
+	}
+	if len(req.ID) == 0 {
+		// 通知は initialized などの状態通知のみを想定し、処理も応答もしない。
+		return response{}, false
+	}
+	if req.JSONRPC != jsonRPCVersion || req.Method == "" {
+		return errorResponse(req.ID, codeInvalidRequest, "invalid request"), true
+	}
+	result, rpcErr := s.dispatch(req.Method, req.Params)
+	if rpcErr != nil {
+		return response{JSONRPC: jsonRPCVersion, ID: req.ID, Error: rpcErr}, true
+	}
+	return response{JSONRPC: jsonRPCVersion, ID: req.ID, Result: result}, true
+}
+
+// dispatch は DD-MCP-001 のメソッド名に応じて処理を振り分ける。
+func (s *Server) dispatch(method string, params json.RawMessage) (any, *rpcError) {
+	switch method {
+	case "initialize":
+		return map[string]any{
+			"protocolVersion": protocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": serverName, "version": s.version},
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		return map[string]any{"tools": s.tools()}, nil
+	case "tools/call":
+		return s.callTool(params)
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + method}
+	}
+}
+
+// callParams は DD-MCP-001 の tools/call の引数を表す。
+type callParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// textContent は DD-MCP-001 のツール結果のテキスト要素を表す。
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// toolResult は DD-MCP-001 のツール呼び出しの結果を表す。IsError はツールの実行失敗を表す。
+type toolResult struct {
+	Content []textContent `json:"content"`
+	IsError bool          `json:"isError"`
+}
+
+// callTool は DD-MCP-001 のツールを呼び出す。ツールの失敗はアシスタントが読めるよう isError の結果として返す。
+func (s *Server) callTool(params json.RawMessage) (any, *rpcError) {
+	var call callParams
+	if err := json.Unmarshal(params, &call); err != nil || call.Name == "" {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "tools/call requires a tool name"}
+	}
+	found, ok := s.findTool(call.Name)
+	if !ok {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown tool: " + call.Name}
+	}
+	value, err := found.run(s, call.Arguments)
+	if err != nil {
+		return toolResult{Content: []textContent{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return toolResult{Content: []textContent{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	return toolResult{Content: []textContent{{Type: "text", Text: string(data)}}}, nil
+}
+
+// decodeArguments は DD-MCP-001 のツール引数を dst へ読み込む。未知の項目は指定誤りとして拒否する。
+func decodeArguments(raw json.RawMessage, dst any) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		raw = json.RawMessage("{}")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+// errorResponse は DD-MCP-001 のエラー応答を組み立てる。id が不明な場合は null とする。
+func errorResponse(id json.RawMessage, code int, message string) response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return response{JSONRPC: jsonRPCVersion, ID: id, Error: &rpcError{Code: code, Message: message}}
+}
@@ -0,0 +1,244 @@
+// mcpserver_test.go は MCP サーバーのメッセージ処理とツールの実行結果のテストを行う。
+package mcpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/projectlock"
+	"ratta/internal/infra/schema"
+	"ratta/internal/present"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// schemasDir はテストで用いるリポジトリの schemas ディレクトリを表す。
+var schemasDir = filepath.Join("..", "..", "..", "schemas")
+
+// newProject はテスト用にカテゴリ cat と課題1件を持つプロジェクトを作成し、ルート・課題ID・検証器を返す。
+func newProject(t *testing.T) (string, string, *schema.Validator) {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "cat"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	validator, err := schema.NewValidatorFromDir(schemasDir)
+	if err != nil {
+		t.Fatalf("load schemas: %v", err)
+	}
+	created, err := issueops.NewService(root, validator).CreateIssue("cat", mod.ModeVendor, issueops.IssueCreateInput{
+		Title:       "printer jam",
+		Description: "paper stuck in tray",
+		DueDate:     "2024-01-01",
+		Priority:    issue.PriorityLow,
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	return root, created.Issue.IssueID, validator
+}
+
+// serve はテスト用にメッセージを1行ずつ送り、応答を行ごとに解析して返す。
+func serve(t *testing.T, server *Server, messages ...string) []map[string]any {
+	t.Helper()
+	var out bytes.Buffer
+	if err := server.Serve(strings.NewReader(strings.Join(messages, "\n")+"\n"), &out); err != nil {
+		t.Fatalf("Serve error: %v", err)
+	}
+	var replies []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var reply map[string]any
+		if err := json.Unmarshal([]byte(line), &reply); err != nil {
+			t.Fatalf("unmarshal %q: %v", line, err)
+		}
+		replies = append(replies, reply)
+	}
+	return replies
+}
+
+// callTool はテスト用にツールを1回呼び出し、結果のテキストと失敗の有無を返す。
+func callTool(t *testing.T, server *Server, name string, args any) (string, bool) {
+	t.Helper()
+	params, err := json.Marshal(map[string]any{"name": name, "arguments": args})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	replies := serve(t, server, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":`+string(params)+`}`)
+	if len(replies) != 1 {
+		t.Fatalf("expected one reply, got %v", replies)
+	}
+	result, ok := replies[0]["result"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected result, got %v", replies[0])
+	}
+	content := result["content"].([]any)[0].(map[string]any)
+	return content["text"].(string), result["isError"].(bool)
+}
+
+// toolNames はテスト用に tools/list の応答からツール名を取り出す。
+func toolNames(t *testing.T, reply map[string]any) []string {
+	t.Helper()
+	tools := reply["result"].(map[string]any)["tools"].([]any)
+	names := make([]string, 0, len(tools))
+	for _, value := range tools {
+		names = append(names, value.(map[string]any)["name"].(string))
+	}
+	return names
+}
+
+func TestServe_InitializeListsToolsAndIgnoresNotifications(t *testing.T) {
+	// initialize にサーバー情報を返し、通知には応答せず、tools/list で全ツールを公開することを確認する。
+	root, _, validator := newProject(t)
+	replies := serve(t, New(root, validator, mod.ModeVendor).WithVersion("1.2.3"),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":"two","method":"tools/list"}`,
+	)
+	if len(replies) != 2 {
+		t.Fatalf("expected 2 replies, got %v", replies)
+	}
+	info := replies[0]["result"].(map[string]any)["serverInfo"].(map[string]any)
+	if info["name"] != "ratta" || info["version"] != "1.2.3" {
+		t.Fatalf("unexpected server info: %v", info)
+	}
+	if replies[1]["id"] != "two" {
+		t.Fatalf("expected id to be echoed, got %v", replies[1]["id"])
+	}
+	want := "list_categories,list_issues,get_issue,search_issues,create_issue"
+	if got := strings.Join(toolNames(t, replies[1]), ","); got != want {
+		t.Fatalf("unexpected tools: %s", got)
+	}
+}
+
+func TestServe_ReportsProtocolErrors(t *testing.T) {
+	// 解析できない行・未対応のメソッド・未知のツールを JSON-RPC のエラーとして返し、処理を続けることを確認する。
+	root, _, validator := newProject(t)
+	replies := serve(t, New(root, validator, mod.ModeVendor),
+		`{not json`,
+		`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"delete_issue"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"ping"}`,
+	)
+	if len(replies) != 4 {
+		t.Fatalf("expected 4 replies, got %v", replies)
+	}
+	for i, code := range []float64{codeParseError, codeMethodNotFound, codeInvalidParams} {
+		rpcErr, ok := replies[i]["error"].(map[string]any)
+		if !ok || rpcErr["code"] != code {
+			t.Fatalf("reply %d: expected error %v, got %v", i, code, replies[i])
+		}
+	}
+	if _, ok := replies[3]["result"]; !ok {
+		t.Fatalf("expected ping result, got %v", replies[3])
+	}
+}
+
+func TestTools_ReadIssues(t *testing.T) {
+	// list_categories・list_issues・get_issue・search_issues が GUI と同じ DTO で課題を返すことを確認する。
+	root, issueID, validator := newProject(t)
+	server := New(root, validator, mod.ModeVendor)
+
+	text, isError := callTool(t, server, "list_categories", map[string]any{})
+	var categories present.CategoryListDTO
+	if isError || json.Unmarshal([]byte(text), &categories) != nil || len(categories.Categories) != 1 || categories.Categories[0].Name != "cat" {
+		t.Fatalf("unexpected list_categories: %s", text)
+	}
+
+	text, isError = callTool(t, server, "list_issues", map[string]any{"category": "cat", "priority": "Low"})
+	var list present.IssueListDTO
+	if isError || json.Unmarshal([]byte(text), &list) != nil || len(list.Issues) != 1 || list.Issues[0].IssueID != issueID {
+		t.Fatalf("unexpected list_issues: %s", text)
+	}
+
+	text, isError = callTool(t, server, "get_issue", map[string]any{"category": "cat", "issue_id": issueID})
+	var detail present.IssueDetailDTO
+	if isError || json.Unmarshal([]byte(text), &detail) != nil || detail.Title != "printer jam" {
+		t.Fatalf("unexpected get_issue: %s", text)
+	}
+
+	text, isError = callTool(t, server, "search_issues", map[string]any{"query": "paper"})
+	var hits []present.SearchHitDTO
+	if isError || json.Unmarshal([]byte(text), &hits) != nil || len(hits) != 1 || hits[0].Issue.IssueID != issueID {
+		t.Fatalf("unexpected search_issues: %s", text)
+	}
+}
+
+func TestTools_RejectInvalidArguments(t *testing.T) {
+	// 未知の引数・存在しないカテゴリ・カテゴリ外を指す課題IDをツールの失敗として返すことを確認する。
+	root, _, validator := newProject(t)
+	server := New(root, validator, mod.ModeVendor)
+	cases := []struct {
+		name string
+		tool string
+		args map[string]any
+		want string
+	}{
+		{"unknown argument", "list_issues", map[string]any{"category": "cat", "sort": "title"}, "invalid arguments"},
+		{"missing category", "list_issues", map[string]any{"category": "nope"}, "category not found"},
+		{"path traversal", "get_issue", map[string]any{"category": "cat", "issue_id": "../../secret"}, "invalid issue_id"},
+		{"empty query", "search_issues", map[string]any{"query": " "}, "query is required"},
+	}
+	for _, tc := range cases {
+		text, isError := callTool(t, server, tc.tool, tc.args)
+		if !isError || !strings.Contains(text, tc.want) {
+			t.Fatalf("%s: expected error containing %q, got %v %q", tc.name, tc.want, isError, text)
+		}
+	}
+}
+
+func TestTools_CreateIssueUsesServerMode(t *testing.T) {
+	// create_issue がサーバー起動時の操作モードで課題を作成し、起票元の会社に反映することを確認する。
+	root, _, validator := newProject(t)
+	text, isError := callTool(t, New(root, validator, mod.ModeContractor), "create_issue", map[string]any{
+		"category":    "cat",
+		"title":       "from assistant",
+		"description": "filed via MCP",
+		"due_date":    "2024-02-01",
+		"priority":    "High",
+	})
+	var detail present.IssueDetailDTO
+	if isError || json.Unmarshal([]byte(text), &detail) != nil {
+		t.Fatalf("unexpected create_issue: %v %s", isError, text)
+	}
+	if detail.OriginCompany != string(issue.CompanyContractor) {
+		t.Fatalf("expected contractor origin, got %q", detail.OriginCompany)
+	}
+	if _, err := os.Stat(filepath.Join(root, "cat", detail.IssueID+".json")); err != nil {
+		t.Fatalf("expected issue file: %v", err)
+	}
+}
+
+func TestTools_CreateIssueRespectsReadOnlyAndLock(t *testing.T) {
+	// 読み取り専用では create_issue を公開せず、他のインスタンスが書き込み用に開いている間は作成しないことを確認する。
+	root, _, validator := newProject(t)
+	args := map[string]any{"category": "cat", "title": "t", "description": "d", "due_date": "2024-02-01", "priority": "Low"}
+
+	readOnly := New(root, validator, mod.ModeVendor).WithReadOnly(true)
+	replies := serve(t, readOnly, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	if strings.Contains(strings.Join(toolNames(t, replies[0]), ","), "create_issue") {
+		t.Fatalf("read-only server must not list create_issue")
+	}
+	params, _ := json.Marshal(map[string]any{"name": "create_issue", "arguments": args})
+	replies = serve(t, readOnly, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":`+string(params)+`}`)
+	if rpcErr, ok := replies[0]["error"].(map[string]any); !ok || rpcErr["code"] != float64(codeInvalidParams) {
+		t.Fatalf("expected unknown tool error, got %v", replies[0])
+	}
+
+	holder := `{"hostname":"gui-host","pid":1,"instance":"gui","updated_at":"2999-01-01T00:00:00Z"}`
+	if err := os.WriteFile(filepath.Join(root, projectlock.FileName), []byte(holder), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	text, isError := callTool(t, New(root, validator, mod.ModeVendor), "create_issue", args)
+	if !isError || !strings.Contains(text, "gui-host") {
+		t.Fatalf("expected lock error, got %v %q", isError, text)
+	}
+}
@@ -0,0 +1,339 @@
+// tools.go は MCP サーバーが公開するツールの定義と実行を担い、JSON-RPC のメッセージ処理は扱わない。
+// ツールの結果は GUI と同じ present の DTO を JSON にしたものとする。
+package mcpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"ratta/internal/app/categoryscan"
+	"ratta/internal/app/issueops"
+	"ratta/internal/app/issuescan"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/projectlock"
+	"ratta/internal/present"
+)
+
+const (
+	// defaultSearchLimit は DD-MCP-001 の search_issues で件数の指定が無い場合の最大件数を表す。
+	defaultSearchLimit = 20
+	// maxSearchLimit は DD-MCP-001 の search_issues で指定できる最大件数を表す。
+	maxSearchLimit = 100
+)
+
+// tool は DD-MCP-001 の公開ツールを表す。writes は課題を書き換えるため読み取り専用では公開しないことを表す。
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+	writes      bool
+	run         func(s *Server, args json.RawMessage) (any, error)
+}
+
+// allTools は DD-MCP-001 で公開するツールの一覧を表す。
+var allTools = []tool{
+	{
+		Name:        "list_categories",
+		Description: "List the issue categories in the project.",
+		InputSchema: objectSchema(nil, nil),
+		run:         (*Server).listCategories,
+	},
+	{
+		Name:        "list_issues",
+		Description: "List issues in a category with optional status/priority filters, sorting and paging.",
+		InputSchema: objectSchema([]string{"category"}, map[string]any{
+			"category":   stringProperty("category name"),
+			"status":     stringProperty("show only issues with this status"),
+			"priority":   stringProperty("show only issues with this priority"),
+			"sort_by":    stringProperty("issue_id, updated_at, due_date, priority, status or title"),
+			"sort_order": stringProperty("asc or desc"),
+			"page":       integerProperty("1-based page number"),
+			"page_size":  integerProperty("issues per page (default 20)"),
+		}),
+		run: (*Server).listIssues,
+	},
+	{
+		Name:        "get_issue",
+		Description: "Get an issue with its comments and attachments.",
+		InputSchema: objectSchema([]string{"category", "issue_id"}, map[string]any{
+			"category": stringProperty("category name"),
+			"issue_id": stringProperty("issue ID"),
+		}),
+		run: (*Server).getIssue,
+	},
+	{
+		Name:        "search_issues",
+		Description: "Full-text search over issue titles, descriptions and comments, ordered by relevance.",
+		InputSchema: objectSchema([]string{"query"}, map[string]any{
+			"query":    stringProperty("search text"),
+			"category": stringProperty("restrict the search to this category"),
+			"limit":    integerProperty(fmt.Sprintf("maximum number of hits (default %d, max %d)", defaultSearchLimit, maxSearchLimit)),
+		}),
+		run: (*Server).searchIssues,
+	},
+	{
+		Name:        "create_issue",
+		Description: "Create a new issue in a category. Empty fields take the category defaults.",
+		InputSchema: objectSchema([]string{"category", "title"}, map[string]any{
+			"category":    stringProperty("category name"),
+			"title":       stringProperty("issue title"),
+			"description": stringProperty("issue description"),
+			"due_date":    stringProperty("due date (YYYY-MM-DD)"),
+			"priority":    stringProperty("High, Medium or Low"),
+			"assignee":    stringProperty("assignee name"),
+		}),
+		writes: true,
+		run:    (*Server).createIssue,
+	},
+}
+
+// tools は DD-MCP-001 のこのサーバーで公開するツールを返す。読み取り専用では書き込みを伴うツールを除く。
+func (s *Server) tools() []tool {
+	tools := make([]tool, 0, len(allTools))
+	for _, candidate := range allTools {
+		if candidate.writes && s.readOnly {
+			continue
+		}
+		tools = append(tools, candidate)
+	}
+	return tools
+}
+
+// findTool は DD-MCP-001 の公開しているツールを名前で探す。
+func (s *Server) findTool(name string) (tool, bool) {
+	for _, candidate := range s.tools() {
+		if candidate.Name == name {
+			return candidate, true
+		}
+	}
+	return tool{}, false
+}
+
+// listCategories は DD-MCP-001 の list_categories を実行する。
+func (s *Server) listCategories(args json.RawMessage) (any, error) {
+	if err := decodeArguments(args, &struct{}{}); err != nil {
+		return nil, err
+	}
+	scanned, err := categoryscan.Scan(s.root)
+	if err != nil {
+		return nil, err
+	}
+	categories := make([]present.CategoryDTO, 0, len(scanned.Categories))
+	for _, category := range scanned.Categories {
+		categories = append(categories, present.ToCategoryDTO(category))
+	}
+	return present.CategoryListDTO{Categories: categories, Errors: scanned.ErrorCount}, nil
+}
+
+// listIssuesArgs は DD-MCP-001 の list_issues の引数を表す。
+type listIssuesArgs struct {
+	Category  string `json:"category"`
+	Status    string `json:"status"`
+	Priority  string `json:"priority"`
+	SortBy    string `json:"sort_by"`
+	SortOrder string `json:"sort_order"`
+	Page      int    `json:"page"`
+	PageSize  int    `json:"page_size"`
+}
+
+// listIssues は DD-MCP-001 の list_issues を実行する。
+// GUI での編集と同時に使われるため、CLI の list と同じく索引を更新せずに課題JSONを直接走査する。
+func (s *Server) listIssues(args json.RawMessage) (any, error) {
+	var input listIssuesArgs
+	if err := decodeArguments(args, &input); err != nil {
+		return nil, err
+	}
+	category, err := s.findCategory(input.Category)
+	if err != nil {
+		return nil, err
+	}
+	scanned, err := issuescan.NewScanner(s.validator).ScanCategory(category.Path, category.Name)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]issueops.IssueSummary, 0, len(scanned.Items))
+	for _, item := range scanned.Items {
+		items = append(items, issueops.IssueSummary(item))
+	}
+	list, err := issueops.QueryIssues(category.Name, items, issueops.IssueListQuery{
+		Page:      input.Page,
+		PageSize:  input.PageSize,
+		SortBy:    input.SortBy,
+		SortOrder: input.SortOrder,
+		Status:    input.Status,
+		Priority:  input.Priority,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return present.ToIssueListDTO(list), nil
+}
+
+// getIssueArgs は DD-MCP-001 の get_issue の引数を表す。
+type getIssueArgs struct {
+	Category string `json:"category"`
+	IssueID  string `json:"issue_id"`
+}
+
+// getIssue は DD-MCP-001 の get_issue を実行する。
+func (s *Server) getIssue(args json.RawMessage) (any, error) {
+	var input getIssueArgs
+	if err := decodeArguments(args, &input); err != nil {
+		return nil, err
+	}
+	category, err := s.findCategory(input.Category)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkIssueID(input.IssueID); err != nil {
+		return nil, err
+	}
+	detail, err := s.issues.GetIssue(category.Name, input.IssueID)
+	if err != nil {
+		return nil, err
+	}
+	return present.ToIssueDetailDTO(detail), nil
+}
+
+// searchIssuesArgs は DD-MCP-001 の search_issues の引数を表す。
+type searchIssuesArgs struct {
+	Query    string `json:"query"`
+	Category string `json:"category"`
+	Limit    int    `json:"limit"`
+}
+
+// searchIssues は DD-MCP-001 の search_issues を実行する。件数は既定値と上限の範囲に収める。
+func (s *Server) searchIssues(args json.RawMessage) (any, error) {
+	var input searchIssuesArgs
+	if err := decodeArguments(args, &input); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(input.Query) == "" {
+		return nil, errors.New("query is required")
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	limit = min(limit, maxSearchLimit)
+	hits, err := s.issues.SearchIssues(input.Query, input.Category, limit)
+	if err != nil {
+		return nil, err
+	}
+	dtos := make([]present.SearchHitDTO, 0, len(hits))
+	for _, hit := range hits {
+		dtos = append(dtos, present.ToSearchHitDTO(hit))
+	}
+	return dtos, nil
+}
+
+// createIssueArgs は DD-MCP-001 の create_issue の引数を表す。
+type createIssueArgs struct {
+	Category    string `json:"category"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	DueDate     string `json:"due_date"`
+	Priority    string `json:"priority"`
+	Assignee    string `json:"assignee"`
+}
+
+// createIssue は DD-MCP-001 の create_issue を実行する。
+// サーバー起動時に決めた操作モードで GUI と同じ規則により作成し、呼び出しごとに書き込み用ロックを取得する。
+func (s *Server) createIssue(args json.RawMessage) (any, error) {
+	var input createIssueArgs
+	if err := decodeArguments(args, &input); err != nil {
+		return nil, err
+	}
+	var created issueops.IssueDetail
+	err := s.withWriteLock(func() error {
+		category, findErr := s.findCategory(input.Category)
+		if findErr != nil {
+			return findErr
+		}
+		var createErr error
+		created, createErr = s.issues.CreateIssue(category.Name, s.mode, issueops.IssueCreateInput{
+			Title:       input.Title,
+			Description: input.Description,
+			DueDate:     input.DueDate,
+			Priority:    issue.Priority(input.Priority),
+			Assignee:    input.Assignee,
+		})
+		return createErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return present.ToIssueDetailDTO(created), nil
+}
+
+// findCategory は DD-MCP-001 のプロジェクトルート配下から名前の一致するカテゴリを探す。
+// アシスタントが指定した名前でルート外のパスを参照しないよう、走査で見つかったカテゴリのみを対象とする。
+func (s *Server) findCategory(name string) (categoryscan.Category, error) {
+	if name == "" {
+		return categoryscan.Category{}, errors.New("category is required")
+	}
+	scanned, err := categoryscan.Scan(s.root)
+	if err != nil {
+		return categoryscan.Category{}, err
+	}
+	for _, category := range scanned.Categories {
+		if category.Name == name {
+			return category, nil
+		}
+	}
+	return categoryscan.Category{}, fmt.Errorf("category not found: %s", name)
+}
+
+// checkIssueID は DD-MCP-001 の課題IDがカテゴリ直下の課題JSONを指すことを確認する。
+func checkIssueID(issueID string) error {
+	if issueID == "" {
+		return errors.New("issue_id is required")
+	}
+	if filepath.Base(issueID) != issueID || !issue.IsIssueFileName(issueID+".json") {
+		return fmt.Errorf("invalid issue_id: %s", issueID)
+	}
+	return nil
+}
+
+// withWriteLock は DD-MCP-001/DD-LOCK-002 のプロジェクトの書き込み用ロックを取得して fn を実行し、終了後に解放する。
+// GUI などの他のインスタンスが書き込み用に開いている場合は、上書きを避けるため実行しない。
+func (s *Server) withWriteLock(fn func() error) error {
+	lock, holder, err := projectlock.Acquire(s.root)
+	if errors.Is(err, projectlock.ErrLocked) {
+		return fmt.Errorf("%w (%s, pid %d)", err, holder.Hostname, holder.PID)
+	}
+	if err != nil {
+		return err
+	}
+	runErr := fn()
+	return errors.Join(runErr, lock.Release())
+}
+
+// objectSchema は DD-MCP-001 のツール引数の JSON Schema を組み立てる。
+func objectSchema(required []string, properties map[string]any) map[string]any {
+	if properties == nil {
+		properties = map[string]any{}
+	}
+	value := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		value["required"] = required
+	}
+	return value
+}
+
+// stringProperty は DD-MCP-001 の文字列型の引数の JSON Schema を返す。
+func stringProperty(description string) map[string]any {
+	return map[string]any{"type": "string", "description": description}
+}
+
+// integerProperty は DD-MCP-001 の整数型の引数の JSON Schema を返す。
+func integerProperty(description string) map[string]any {
+	return map[string]any{"type": "integer", "description": description}
+}
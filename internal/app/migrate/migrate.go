@@ -0,0 +1,377 @@
+// Package migrate は課題JSON・カテゴリメタデータ・カテゴリ表示順・アプリ設定を旧い形式から現行形式へ書き換える処理を担い、
+// 書き込み用ロックの取得や結果の表示は扱わない。
+// 形式の変更は版ごとの移行手順として登録し、旧い版のファイルには手順を順に適用する。
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"ratta/internal/app/categoryscan"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/atomicwrite"
+	"ratta/internal/infra/categorymeta"
+	"ratta/internal/infra/jsonfmt"
+	"ratta/internal/infra/projectmeta"
+)
+
+// Kind は DD-MIGRATE-001 の移行対象のファイル種別を表す。
+type Kind string
+
+const (
+	// KindIssue は DD-MIGRATE-001 の課題JSON (version) を表す。
+	KindIssue Kind = "issue"
+	// KindCategoryMeta は DD-MIGRATE-001 の .category.json (format_version) を表す。
+	KindCategoryMeta Kind = "category_meta"
+	// KindCategoryOrder は DD-MIGRATE-001 の .ratta/category_order.json (format_version) を表す。
+	KindCategoryOrder Kind = "category_order"
+	// KindConfig は DD-MIGRATE-001 のアプリ設定 config.json (format_version) を表す。
+	KindConfig Kind = "config"
+)
+
+const (
+	// configBackupDirName は DD-MIGRATE-001 のバックアップ先でプロジェクト外の config.json を置くディレクトリ名を表す。
+	configBackupDirName = "_app"
+	// BackupDirName は DD-MIGRATE-001 の書き換え前の内容を置くディレクトリ名 (.ratta 配下) を表す。
+	BackupDirName = "backups"
+)
+
+// NewBackupDir は DD-MIGRATE-001 の移行1回分のバックアップ先 (.ratta/backups/migrate-<日時>) を返す。ディレクトリは作成しない。
+func NewBackupDir(root string, at time.Time) string {
+	return filepath.Join(projectmeta.Dir(root), BackupDirName, "migrate-"+at.Format("20060102T150405"))
+}
+
+// step は DD-MIGRATE-001 の1版分の移行手順を表す。Apply は From 版の内容を From+1 版の内容へ書き換える。
+// 版の値の更新は呼び出し側が行うため、Apply は版以外の項目のみを扱う。
+type step struct {
+	Kind        Kind
+	From        int
+	Description string
+	Apply       func(doc map[string]any) error
+}
+
+// format は DD-MIGRATE-001 のファイル種別ごとの版の項目名・現行の版・整形方法を表す。
+// marshal は DD-DATA-006 のプロジェクトの整形の設定と書き換え前の内容を受け取り、従うファイル種別のみがそれを用いる。
+type format struct {
+	versionKey string
+	current    int
+	marshal    func(projectFormat jsonfmt.Format, value any, original []byte) ([]byte, error)
+}
+
+// canonical は DD-MIGRATE-001 のプロジェクトの整形の設定に従わないファイル種別の整形方法を表す。
+func canonical(marshal func(any) ([]byte, error)) func(jsonfmt.Format, any, []byte) ([]byte, error) {
+	return func(_ jsonfmt.Format, value any, _ []byte) ([]byte, error) {
+		return marshal(value)
+	}
+}
+
+// categoryMeta は DD-MIGRATE-001 の .category.json の整形方法を表し、書き換え前の内容は用いない。
+func categoryMeta(projectFormat jsonfmt.Format, value any, _ []byte) ([]byte, error) {
+	return projectFormat.MarshalCategoryMeta(value)
+}
+
+// formats は DD-MIGRATE-001 のファイル種別ごとの形式を表す。current は各パッケージが保存時に書き込む版と一致させる。
+var formats = map[Kind]format{
+	KindIssue:         {versionKey: "version", current: issue.CurrentVersion, marshal: jsonfmt.Format.RewriteIssue},
+	KindCategoryMeta:  {versionKey: "format_version", current: 1, marshal: categoryMeta},
+	KindCategoryOrder: {versionKey: "format_version", current: 1, marshal: canonical(jsonfmt.MarshalCategoryOrder)},
+	KindConfig:        {versionKey: "format_version", current: 1, marshal: canonical(jsonfmt.MarshalConfig)},
+}
+
+// CurrentVersions は DD-MIGRATE-001 のファイル種別ごとの現行の版 (読み書きできる最新の版) を返す。
+func CurrentVersions() map[Kind]int {
+	versions := make(map[Kind]int, len(formats))
+	for kind, spec := range formats {
+		versions[kind] = spec.current
+	}
+	return versions
+}
+
+// steps は DD-MIGRATE-001 の登録済みの移行手順を表す。版の項目が無いファイルは版 0 として扱う。
+var steps = []step{
+	{
+		Kind:        KindIssue,
+		From:        0,
+		Description: "add version and empty comment/attachment lists",
+		Apply: func(doc map[string]any) error {
+			comments, _ := doc["comments"].([]any)
+			if comments == nil {
+				comments = []any{}
+			}
+			for _, value := range comments {
+				comment, ok := value.(map[string]any)
+				if !ok {
+					return errors.New("comments must contain objects")
+				}
+				if _, isList := comment["attachments"].([]any); !isList {
+					comment["attachments"] = []any{}
+				}
+			}
+			doc["comments"] = comments
+			return nil
+		},
+	},
+	{
+		Kind:        KindIssue,
+		From:        1,
+		Description: "allow tags and custom_fields",
+		Apply: func(doc map[string]any) error {
+			// 版 2 の任意項目は空の場合に保存しないため追加せず、既に置かれている場合の型のみを確かめる。
+			if tags, ok := doc["tags"]; ok {
+				if _, isList := tags.([]any); !isList {
+					return errors.New("tags must be a list")
+				}
+			}
+			if fields, ok := doc["custom_fields"]; ok {
+				if _, isObject := fields.(map[string]any); !isObject {
+					return errors.New("custom_fields must be an object")
+				}
+			}
+			return nil
+		},
+	},
+	{Kind: KindCategoryMeta, From: 0, Description: "add format_version", Apply: func(map[string]any) error { return nil }},
+	{
+		Kind:        KindCategoryOrder,
+		From:        0,
+		Description: "add format_version and empty category list",
+		Apply: func(doc map[string]any) error {
+			if _, isList := doc["categories"].([]any); !isList {
+				doc["categories"] = []any{}
+			}
+			return nil
+		},
+	},
+	{Kind: KindConfig, From: 0, Description: "add format_version", Apply: func(map[string]any) error { return nil }},
+}
+
+// Options は DD-MIGRATE-001 の移行の実行条件を表す。
+// ConfigPath が空の場合はアプリ設定を対象としない。BackupDir が空の場合はバックアップを作成しない。
+type Options struct {
+	ConfigPath string
+	DryRun     bool
+	BackupDir  string
+}
+
+// Change は DD-MIGRATE-001 の移行が必要 (dry-run 以外では移行済み) なファイル1件を表す。
+// Path はプロジェクト内のファイルではプロジェクトルートからの相対パス (区切りは /)、アプリ設定では指定されたパスとする。
+type Change struct {
+	Kind Kind
+	Path string
+	From int
+	To   int
+}
+
+// Problem は DD-MIGRATE-001 の移行できないファイル1件を表す。
+type Problem struct {
+	Kind    Kind
+	Path    string
+	Message string
+}
+
+// Result は DD-MIGRATE-001 の移行結果を表す。Checked は版を確認したファイルの数を表す。
+type Result struct {
+	Checked  int
+	Changes  []Change
+	Problems []Problem
+}
+
+// file は DD-MIGRATE-001 の移行対象のファイル1件を表す。
+type file struct {
+	kind   Kind
+	path   string
+	label  string
+	backup string
+}
+
+// Run は DD-MIGRATE-001 のプロジェクトの形式移行を行う。
+// 目的: 旧い版で保存されたファイルを、登録済みの手順で現行の版へ書き換える。
+// 入力: ctx は中断通知、root はプロジェクトルート、opts は実行条件。
+// 出力: Result とエラー。
+// エラー: カテゴリの走査、整形の設定の読み取り、バックアップや書き込みに失敗した場合、中断された場合に返す。
+// 解析できないファイル、現行より新しい版、手順が登録されていない版は Problems に含め、そのファイルは変更しない。
+// 副作用: dry-run 以外では対象ファイルを原子的に書き換え、BackupDir 指定時は書き換え前の内容を相対パスを保って複製する。
+// 並行性: 同時実行や課題操作との並行は想定しない。呼び出し側で書き込み用ロックを取得する。
+// 不変条件: 現行の版のファイルは読み取りのみで変更しない。未知の項目は保持し、キー順は各ファイルの整形規則に従う。
+// 課題 JSON と .category.json の改行コードとインデント幅は DD-DATA-006 のプロジェクトの設定に従い、
+// 設定した場合は課題 JSON の未知のキーの並びを DD-DATA-007 に従って保つ。
+// 名前変更中のカテゴリは対象としない。
+// 関連DD: DD-MIGRATE-001, DD-DATA-001, DD-DATA-003, DD-CATMETA-001, DD-PROJMETA-001, DD-PERSIST-002
+func Run(ctx context.Context, root string, opts Options) (Result, error) {
+	files, err := collect(root, opts.ConfigPath)
+	if err != nil {
+		return Result{}, err
+	}
+	projectFormat, err := projectmeta.LoadFormat(root)
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{Changes: []Change{}, Problems: []Problem{}}
+	for _, target := range files {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return Result{}, ctxErr
+		}
+		// #nosec G304 -- プロジェクト配下の列挙結果と利用者が指定した設定ファイルのみを読む。
+		data, readErr := os.ReadFile(target.path)
+		if readErr != nil {
+			return Result{}, fmt.Errorf("read %s: %w", target.label, readErr)
+		}
+		result.Checked++
+		migrated, change, migrateErr := migrate(target, data, projectFormat)
+		if migrateErr != nil {
+			result.Problems = append(result.Problems, Problem{Kind: target.kind, Path: target.label, Message: migrateErr.Error()})
+			continue
+		}
+		if migrated == nil {
+			continue
+		}
+		result.Changes = append(result.Changes, change)
+		if opts.DryRun {
+			continue
+		}
+		if opts.BackupDir != "" {
+			if backupErr := writeBackup(filepath.Join(opts.BackupDir, target.backup), data); backupErr != nil {
+				return Result{}, backupErr
+			}
+		}
+		if writeErr := atomicwrite.WriteFile(target.path, migrated); writeErr != nil {
+			return Result{}, fmt.Errorf("write %s: %w", target.label, writeErr)
+		}
+	}
+	return result, nil
+}
+
+// collect は DD-MIGRATE-001 の移行対象のファイルを列挙する。存在しないメタデータや設定ファイルは対象に含めない。
+func collect(root, configPath string) ([]file, error) {
+	scanned, err := categoryscan.Scan(root)
+	if err != nil {
+		return nil, err
+	}
+	var files []file
+	add := func(kind Kind, path string) {
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			rel = path
+		}
+		files = append(files, file{kind: kind, path: path, label: filepath.ToSlash(rel), backup: rel})
+	}
+	for _, category := range scanned.Categories {
+		if category.IsReadOnly && !category.IsArchived {
+			continue
+		}
+		metaPath := filepath.Join(category.Path, categorymeta.FileName)
+		if isFile(metaPath) {
+			add(KindCategoryMeta, metaPath)
+		}
+		entries, readErr := os.ReadDir(category.Path)
+		if readErr != nil {
+			return nil, fmt.Errorf("read category: %w", readErr)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !issue.IsIssueFileName(entry.Name()) {
+				continue
+			}
+			add(KindIssue, filepath.Join(category.Path, entry.Name()))
+		}
+	}
+	orderPath := projectmeta.CategoryOrderPath(root)
+	if isFile(orderPath) {
+		add(KindCategoryOrder, orderPath)
+	}
+	if configPath != "" && isFile(configPath) {
+		files = append(files, file{
+			kind:   KindConfig,
+			path:   configPath,
+			label:  configPath,
+			backup: filepath.Join(configBackupDirName, filepath.Base(configPath)),
+		})
+	}
+	return files, nil
+}
+
+// migrate は DD-MIGRATE-001 のファイル1件に移行手順を適用し、projectFormat に従って整形する。現行の版の場合は nil を返す。
+func migrate(target file, data []byte, projectFormat jsonfmt.Format) ([]byte, Change, error) {
+	spec := formats[target.kind]
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc map[string]any
+	if err := decoder.Decode(&doc); err != nil || doc == nil {
+		return nil, Change{}, errors.New("not a JSON object")
+	}
+	version, err := readVersion(doc, spec.versionKey)
+	if err != nil {
+		return nil, Change{}, err
+	}
+	if version > spec.current {
+		return nil, Change{}, fmt.Errorf("%s %d is newer than supported version %d", spec.versionKey, version, spec.current)
+	}
+	if version == spec.current {
+		return nil, Change{}, nil
+	}
+	for from := version; from < spec.current; from++ {
+		registered, ok := findStep(target.kind, from)
+		if !ok {
+			return nil, Change{}, fmt.Errorf("no migration registered from %s %d", spec.versionKey, from)
+		}
+		if applyErr := registered.Apply(doc); applyErr != nil {
+			return nil, Change{}, fmt.Errorf("migrate from %s %d: %w", spec.versionKey, from, applyErr)
+		}
+		doc[spec.versionKey] = from + 1
+	}
+	migrated, err := spec.marshal(projectFormat, doc, data)
+	if err != nil {
+		return nil, Change{}, err
+	}
+	return migrated, Change{Kind: target.kind, Path: target.label, From: version, To: spec.current}, nil
+}
+
+// readVersion は DD-MIGRATE-001 の版の値を読み取る。項目が無い場合は版 0 とする。
+func readVersion(doc map[string]any, key string) (int, error) {
+	value, ok := doc[key]
+	if !ok || value == nil {
+		return 0, nil
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("%s must be an integer", key)
+	}
+	version, err := strconv.Atoi(number.String())
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", key)
+	}
+	return version, nil
+}
+
+// findStep は DD-MIGRATE-001 の種別と版に対応する移行手順を返す。
+func findStep(kind Kind, from int) (step, bool) {
+	for _, candidate := range steps {
+		if candidate.Kind == kind && candidate.From == from {
+			return candidate, true
+		}
+	}
+	return step{}, false
+}
+
+// writeBackup は DD-MIGRATE-001 の書き換え前の内容をバックアップ先へ書き込む。
+func writeBackup(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("create backup dir: %w", err)
+	}
+	if err := atomicwrite.WriteFile(path, data); err != nil {
+		return fmt.Errorf("write backup: %w", err)
+	}
+	return nil
+}
+
+// isFile は DD-MIGRATE-001 の対象ファイルの存在確認を行う。
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
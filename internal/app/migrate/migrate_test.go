@@ -0,0 +1,130 @@
+// migrate_test.go は形式移行の対象判定・書き換え・バックアップのテストを行う。
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ratta/internal/infra/jsonfmt"
+)
+
+// writeFile はテスト用に親ディレクトリを作成してファイルを書き込む。
+func writeFile(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+// readJSON はテスト用に JSON ファイルを読み込む。
+func readJSON(t *testing.T, path string) map[string]any {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return doc
+}
+
+func TestRun_MigratesOldFilesAndReportsUnsupported(t *testing.T) {
+	// 版の無いファイルを現行の版へ書き換えてバックアップを残し、現行の版は変更せず、新しい版は問題として報告することを確認する。
+	root := t.TempDir()
+	oldIssue := `{"issue_id":"old","title":"t","comments":[{"comment_id":"c1","attachments":null}],"custom":"kept"}`
+	currentIssue := `{"version":2,"issue_id":"cur","tags":["a"]}`
+	writeFile(t, filepath.Join(root, "cat", "old.json"), oldIssue)
+	writeFile(t, filepath.Join(root, "cat", "cur.json"), currentIssue)
+	writeFile(t, filepath.Join(root, "cat", "new.json"), `{"version":3,"issue_id":"new"}`)
+	writeFile(t, filepath.Join(root, "cat", ".category.json"), `{"description":"d"}`)
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, configPath, `{"log":{"level":"info"}}`)
+
+	dryRun, err := Run(context.Background(), root, Options{ConfigPath: configPath, DryRun: true})
+	if err != nil {
+		t.Fatalf("Run dry-run error: %v", err)
+	}
+	if dryRun.Checked != 5 || len(dryRun.Changes) != 3 || len(dryRun.Problems) != 1 || dryRun.Problems[0].Path != "cat/new.json" {
+		t.Fatalf("unexpected dry-run result: %+v", dryRun)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "cat", "old.json")); string(data) != oldIssue {
+		t.Fatalf("dry-run must not rewrite files: %s", data)
+	}
+
+	backupDir := filepath.Join(t.TempDir(), "backup")
+	result, err := Run(context.Background(), root, Options{ConfigPath: configPath, BackupDir: backupDir})
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if len(result.Changes) != 3 {
+		t.Fatalf("unexpected changes: %+v", result.Changes)
+	}
+	migrated := readJSON(t, filepath.Join(root, "cat", "old.json"))
+	comment := migrated["comments"].([]any)[0].(map[string]any)
+	if migrated["version"] != float64(2) || migrated["custom"] != "kept" || comment["attachments"] == nil {
+		t.Fatalf("unexpected migrated issue: %+v", migrated)
+	}
+	if readJSON(t, filepath.Join(root, "cat", ".category.json"))["format_version"] != float64(1) {
+		t.Fatal("expected category meta to be migrated")
+	}
+	if readJSON(t, configPath)["format_version"] != float64(1) {
+		t.Fatal("expected config to be migrated")
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "cat", "cur.json")); string(data) != currentIssue {
+		t.Fatalf("current version must not be rewritten: %s", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(backupDir, "cat", "old.json")); string(data) != oldIssue {
+		t.Fatalf("unexpected backup: %s", data)
+	}
+	if _, err := os.Stat(filepath.Join(backupDir, configBackupDirName, "config.json")); err != nil {
+		t.Fatalf("expected config backup: %v", err)
+	}
+}
+
+func TestMigrate_RejectsInvalidVersion(t *testing.T) {
+	// 負の版や整数でない版、オブジェクトでない JSON は移行せずエラーとすることを確認する。
+	target := file{kind: KindIssue, path: "x.json", label: "x.json"}
+	if _, _, err := migrate(target, []byte(`{"version":-1}`), jsonfmt.DefaultFormat()); err == nil {
+		t.Fatal("expected error for negative version")
+	}
+	if _, _, err := migrate(target, []byte(`{"version":"1"}`), jsonfmt.DefaultFormat()); err == nil {
+		t.Fatal("expected error for string version")
+	}
+	if _, _, err := migrate(target, []byte(`[]`), jsonfmt.DefaultFormat()); err == nil {
+		t.Fatal("expected error for non-object")
+	}
+}
+
+func TestRun_MigratesIssueVersion1ToVersion2(t *testing.T) {
+	// 版 1 の課題を版 2 へ上げ、空の tags・custom_fields を追加せず、型の誤った任意項目を持つ課題は問題として変更しないことを確認する。
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "cat", "v1.json"), `{"version":1,"issue_id":"v1","comments":[]}`)
+	broken := `{"version":1,"issue_id":"bad","tags":"x","comments":[]}`
+	writeFile(t, filepath.Join(root, "cat", "bad.json"), broken)
+
+	result, err := Run(context.Background(), root, Options{})
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if len(result.Changes) != 1 || result.Changes[0].From != 1 || result.Changes[0].To != 2 {
+		t.Fatalf("unexpected changes: %+v", result.Changes)
+	}
+	if len(result.Problems) != 1 || result.Problems[0].Path != "cat/bad.json" {
+		t.Fatalf("unexpected problems: %+v", result.Problems)
+	}
+	migrated := readJSON(t, filepath.Join(root, "cat", "v1.json"))
+	if _, ok := migrated["tags"]; ok || migrated["version"] != float64(2) {
+		t.Fatalf("unexpected migrated issue: %+v", migrated)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "cat", "bad.json")); string(data) != broken {
+		t.Fatalf("broken issue must not be rewritten: %s", data)
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	"ratta/internal/domain/apperr"
 	"ratta/internal/domain/mode"
 	"ratta/internal/infra/crypto"
 	"ratta/internal/infra/schema"
@@ -17,77 +19,235 @@ import (
 var (
 	readFile = os.ReadFile
 	statFile = os.Stat
+	totpNow  = time.Now
 )
 
+// errPasswordMismatch は DD-CLI-005 のパスワード不一致を表し、DD-MODE-002 の失敗回数に数える。
+var errPasswordMismatch = apperr.New(apperr.ErrCrypto, "password verification failed")
+
 // Service は DD-BE-003 のモード判定と検証を担う。
+// 認証情報は auth/users.json (DD-CLI-007 の名前付きアカウント) を優先し、無い場合は auth/contractor.json (共有パスワード) を用いる。
+// 照合の失敗回数は auth/attempts.json (DD-MODE-002) に、記憶した認証の照合情報は auth/remembered.json (DD-MODE-003) に記録する。
 type Service struct {
-	authPath  string
-	validator *schema.Validator
+	authPath       string
+	usersPath      string
+	attemptsPath   string
+	rememberedPath string
+	validator      *schema.Validator
 }
 
-// NewService は DD-BE-003 に従い auth/contractor.json を対象にする。
+// NewService は DD-BE-003 に従い実行ファイル隣の auth/users.json と auth/contractor.json を対象にする。
 func NewService(exePath string, validator *schema.Validator) *Service {
+	authDir := filepath.Join(filepath.Dir(exePath), "auth")
 	return &Service{
-		authPath:  filepath.Join(filepath.Dir(exePath), "auth", "contractor.json"),
-		validator: validator,
+		authPath:       filepath.Join(authDir, "contractor.json"),
+		usersPath:      filepath.Join(authDir, "users.json"),
+		attemptsPath:   filepath.Join(authDir, "attempts.json"),
+		rememberedPath: filepath.Join(authDir, "remembered.json"),
+		validator:      validator,
 	}
 }
 
-// DetectMode は DD-BE-003 の起動時モード判定を行う。
+// DetectMode は DD-BE-003 の起動時モード判定を行う。users.json か contractor.json のいずれかがあればパスワードを要求する。
 func (s *Service) DetectMode() (mode.Mode, bool, error) {
-	exists, err := s.fileExists()
+	usersExist, err := fileExists(s.usersPath)
+	if err != nil {
+		return mode.ModeVendor, false, err
+	}
+	authExists, err := fileExists(s.authPath)
 	if err != nil {
 		return mode.ModeVendor, false, err
 	}
-	if !exists {
-		return mode.ModeVendor, false, nil
+	return mode.ModeVendor, usersExist || authExists, nil
+}
+
+// RequiresUsername は DD-CLI-007 の名前付きアカウント (users.json) で認証するかを返す。
+func (s *Service) RequiresUsername() (bool, error) {
+	return fileExists(s.usersPath)
+}
+
+// RequiresTOTP は DD-CLI-008 の認証でワンタイムコードを要するかを返す。
+// users.json で認証する場合は username のアカウントに TOTP があるかを返し、username が空か不在の場合は
+// TOTP を設定したアカウントが1件でもあれば true とする (入力欄を表示する段階ではユーザー名が分からないため)。
+// users.json が無い場合は contractor.json の TOTP の有無を返し、認証ファイルが無い場合は false とする。
+func (s *Service) RequiresTOTP(username string) (bool, error) {
+	usersExist, err := fileExists(s.usersPath)
+	if err != nil {
+		return false, err
+	}
+	if usersExist {
+		store, readErr := readUserStore(s.usersPath, s.validator)
+		if readErr != nil {
+			return false, readErr
+		}
+		if account, found := store.Find(username); found {
+			return account.Auth().HasTOTP(), nil
+		}
+		return store.HasTOTP(), nil
+	}
+	authExists, err := fileExists(s.authPath)
+	if err != nil || !authExists {
+		return false, err
+	}
+	auth, err := s.loadAuth("")
+	if err != nil {
+		return false, err
 	}
-	return mode.ModeVendor, true, nil
+	return auth.HasTOTP(), nil
 }
 
-// VerifyContractorPassword は DD-BE-003/DD-CLI-005 に従いパスワードを検証する。
-// 目的: contractor.json の内容に基づきパスワード一致を判定する。
-// 入力: password は入力された平文パスワード。
+// VerifyContractorPassword は DD-BE-003/DD-CLI-005/DD-CLI-007 に従いユーザー名とパスワードを検証する。
+// 目的: users.json があればアカウントの認証情報で、無ければ contractor.json の共有パスワードで一致を判定する。
+// 照合に用いる認証情報 (contractor.json か users.json のアカウント) に DD-CLI-008 の TOTP が埋め込まれている場合はワンタイムコードも照合する。
+// 連続した失敗には DD-MODE-002 の待ち時間を課し、総当たりでの推測を遅らせる。
+// 入力: username はユーザー名 (contractor.json で認証する場合は空)、password は入力された平文パスワード、
+// code はワンタイムコード (TOTP を使わない場合は無視する)。
 // 出力: 成功時は ModeContractor、失敗時は ModeVendor とエラー。
-// エラー: 読み取り・検証・復号失敗、パスワード不一致時に返す。
-// 副作用: contractor.json を読み取る。
-// 並行性: 読み取りのみでスレッドセーフ。
-// 不変条件: 認証情報が不正な場合は Contractor モードにしない。
-// 関連DD: DD-BE-003, DD-CLI-005
-func (s *Service) VerifyContractorPassword(password string) (mode.Mode, error) {
+// エラー: 読み取り・検証・復号失敗、ユーザー名の過不足、ワンタイムコードの未入力、ユーザー不在やパスワード・コード不一致時に返す。
+// 待ち時間中は照合せずに ErrTooManyAttempts を返す。
+// 副作用: users.json または contractor.json を読み取る。不一致時は attempts.json の失敗回数を増やし、成功時は削除する。
+// 並行性: 同一 auth ディレクトリへの同時実行は想定しない。
+// 不変条件: 認証情報が不正な場合は Contractor モードにしない。ユーザー不在、パスワード不一致、コード不一致は同じエラーで返す。
+// 失敗回数はユーザー名を問わず数える。
+// 関連DD: DD-BE-003, DD-CLI-005, DD-CLI-007, DD-CLI-008, DD-MODE-002
+func (s *Service) VerifyContractorPassword(username, password, code string) (mode.Mode, error) {
+	record := loadAttempts(s.attemptsPath)
+	if wait := record.remainingWait(throttleNow()); wait > 0 {
+		return mode.ModeVendor, fmt.Errorf("%w (retry in %s)", ErrTooManyAttempts, wait.Truncate(time.Second)+time.Second)
+	}
+	modeValue, err := s.verify(username, password, code)
+	if errors.Is(err, errPasswordMismatch) {
+		if _, recordErr := recordFailure(s.attemptsPath, record); recordErr != nil {
+			return mode.ModeVendor, fmt.Errorf("%w; %s", err, recordErr.Error())
+		}
+		return mode.ModeVendor, err
+	}
+	if err != nil {
+		return mode.ModeVendor, err
+	}
+	// 削除できなくても照合は成功しているため、Contractor モードへの切り替えは妨げない。
+	_ = resetAttempts(s.attemptsPath)
+	return modeValue, nil
+}
+
+// FailedAttempts は DD-MODE-002 の直近の照合成功以降に続いた失敗回数を返す。
+func (s *Service) FailedAttempts() int {
+	return loadAttempts(s.attemptsPath).FailedAttempts
+}
+
+// verify は DD-CLI-005/DD-CLI-007/DD-CLI-008 の認証ファイルでユーザー名・パスワード・ワンタイムコードを照合する。失敗回数は扱わない。
+func (s *Service) verify(username, password, code string) (mode.Mode, error) {
+	auth, err := s.loadAuth(username)
+	if err != nil {
+		return mode.ModeVendor, err
+	}
+	if !auth.HasTOTP() {
+		ok, verifyErr := crypto.VerifyPassword(auth, password)
+		return verifyResult(ok, verifyErr)
+	}
+	if code == "" {
+		// 入力漏れは推測の試行ではないため、パスワードを照合せずに返す。
+		return mode.ModeVendor, errors.New("one-time code is required")
+	}
+	key, err := crypto.DeriveUnlockKey(auth, password)
+	if err != nil {
+		return verifyResult(false, err)
+	}
+	secret, err := crypto.OpenTOTPSecret(auth, key)
+	if err != nil {
+		return mode.ModeVendor, fmt.Errorf("verify contractor password: %w", err)
+	}
+	if !crypto.VerifyTOTPCode(secret, code, totpNow()) {
+		// パスワードが一致したことを推測されないよう、パスワード不一致と同じエラーにする。
+		return mode.ModeVendor, errPasswordMismatch
+	}
+	return mode.ModeContractor, nil
+}
+
+// loadAuth は DD-CLI-005/DD-CLI-007 の照合に用いる認証情報を返す。
+// users.json があればそのアカウントの、無ければ contractor.json の認証情報とする。
+// ユーザー名の存在を推測されないよう、ユーザー不在はパスワード不一致と同じエラーにする。
+func (s *Service) loadAuth(username string) (crypto.ContractorAuth, error) {
+	usersExist, err := fileExists(s.usersPath)
+	if err != nil {
+		return crypto.ContractorAuth{}, err
+	}
+	if usersExist {
+		if username == "" {
+			return crypto.ContractorAuth{}, errors.New("username is required")
+		}
+		store, readErr := readUserStore(s.usersPath, s.validator)
+		if readErr != nil {
+			return crypto.ContractorAuth{}, readErr
+		}
+		account, found := store.Find(username)
+		if !found {
+			return crypto.ContractorAuth{}, errPasswordMismatch
+		}
+		return account.Auth(), nil
+	}
+	if username != "" {
+		return crypto.ContractorAuth{}, apperr.New(apperr.ErrNotFound, "user accounts are not configured (auth/users.json not found)")
+	}
+
 	data, err := readFile(s.authPath)
 	if err != nil {
-		return mode.ModeVendor, fmt.Errorf("read contractor auth: %w", err)
+		return crypto.ContractorAuth{}, fmt.Errorf("read contractor auth: %w", err)
 	}
 	if s.validator != nil {
 		result, validateErr := s.validator.ValidateContractor(data)
 		if validateErr != nil {
-			return mode.ModeVendor, fmt.Errorf("validate contractor auth: %w", validateErr)
+			return crypto.ContractorAuth{}, fmt.Errorf("validate contractor auth: %w", validateErr)
 		}
 		if len(result.Issues) > 0 {
-			return mode.ModeVendor, fmt.Errorf("contractor auth schema invalid: %s", result.Detail())
+			return crypto.ContractorAuth{}, apperr.Errorf(apperr.ErrSchemaInvalid, "contractor auth schema invalid: %s", result.Detail())
 		}
 	}
-
 	var auth crypto.ContractorAuth
 	if unmarshalErr := json.Unmarshal(data, &auth); unmarshalErr != nil {
-		return mode.ModeVendor, fmt.Errorf("parse contractor auth: %w", unmarshalErr)
+		return crypto.ContractorAuth{}, fmt.Errorf("parse contractor auth: %w", unmarshalErr)
 	}
-	ok, err := crypto.VerifyPassword(auth, password)
+	return auth, nil
+}
+
+// verifyResult は DD-CLI-005 の照合結果をモードとエラーへ変換する。
+func verifyResult(ok bool, err error) (mode.Mode, error) {
 	if err != nil {
 		if errors.Is(err, crypto.ErrPasswordMismatch) {
-			return mode.ModeVendor, errors.New("password verification failed")
+			return mode.ModeVendor, errPasswordMismatch
 		}
 		return mode.ModeVendor, fmt.Errorf("verify contractor password: %w", err)
 	}
 	if !ok {
-		return mode.ModeVendor, errors.New("password verification failed")
+		return mode.ModeVendor, errPasswordMismatch
 	}
 	return mode.ModeContractor, nil
 }
 
-func (s *Service) fileExists() (bool, error) {
-	_, err := statFile(s.authPath)
+// readUserStore は DD-CLI-007 の users.json を読み込み、validator が nil でなければスキーマ検証する。
+func readUserStore(path string, validator *schema.Validator) (crypto.UserStore, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return crypto.UserStore{}, fmt.Errorf("read users: %w", err)
+	}
+	if validator != nil {
+		result, validateErr := validator.ValidateUsers(data)
+		if validateErr != nil {
+			return crypto.UserStore{}, fmt.Errorf("validate users: %w", validateErr)
+		}
+		if len(result.Issues) > 0 {
+			return crypto.UserStore{}, apperr.Errorf(apperr.ErrSchemaInvalid, "users schema invalid: %s", result.Detail())
+		}
+	}
+	var store crypto.UserStore
+	if unmarshalErr := json.Unmarshal(data, &store); unmarshalErr != nil {
+		return crypto.UserStore{}, fmt.Errorf("parse users: %w", unmarshalErr)
+	}
+	return store, nil
+}
+
+func fileExists(path string) (bool, error) {
+	_, err := statFile(path)
 	if err == nil {
 		return true, nil
 	}
@@ -83,7 +83,7 @@ func TestVerifyContractorPassword_Success(t *testing.T) {
 		t.Fatalf("NewValidatorFromDir error: %v", err)
 	}
 	service := NewService(filepath.Join(dir, "ratta.exe"), validator)
-	gotMode, err := service.VerifyContractorPassword("secret")
+	gotMode, err := service.VerifyContractorPassword("", "secret", "")
 	if err != nil {
 		t.Fatalf("VerifyContractorPassword error: %v", err)
 	}
@@ -92,6 +92,39 @@ func TestVerifyContractorPassword_Success(t *testing.T) {
 	}
 }
 
+func TestVerifyContractorPassword_Argon2id(t *testing.T) {
+	// argon2id で作成した認証ファイルがスキーマ検証を通り、正しいパスワードで Contractor に切り替わることを確認する。
+	dir := t.TempDir()
+	authPath := filepath.Join(dir, "auth", "contractor.json")
+	if err := os.MkdirAll(filepath.Dir(authPath), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	auth, err := crypto.GenerateContractorAuthWithKDF("secret", crypto.KDFParams{Name: crypto.KDFArgon2id, Iterations: 2})
+	if err != nil {
+		t.Fatalf("GenerateContractorAuthWithKDF error: %v", err)
+	}
+	data, err := jsonfmt.MarshalContractor(auth)
+	if err != nil {
+		t.Fatalf("MarshalContractor error: %v", err)
+	}
+	if writeErr := os.WriteFile(authPath, data, 0o600); writeErr != nil {
+		t.Fatalf("write auth: %v", writeErr)
+	}
+
+	validator, err := schema.NewValidatorFromDir(filepath.Join("..", "..", "..", "schemas"))
+	if err != nil {
+		t.Fatalf("NewValidatorFromDir error: %v", err)
+	}
+	service := NewService(filepath.Join(dir, "ratta.exe"), validator)
+	gotMode, err := service.VerifyContractorPassword("", "secret", "")
+	if err != nil || gotMode != mode.ModeContractor {
+		t.Fatalf("expected contractor mode, got %s err=%v", gotMode, err)
+	}
+	if _, err := service.VerifyContractorPassword("", "wrong", ""); err == nil {
+		t.Fatal("expected wrong password to be rejected")
+	}
+}
+
 func TestVerifyContractorPassword_WrongPassword(t *testing.T) {
 	// 誤ったパスワードでは Contractor にならないことを確認する。
 	dir := t.TempDir()
@@ -116,7 +149,7 @@ func TestVerifyContractorPassword_WrongPassword(t *testing.T) {
 	}
 
 	service := NewService(filepath.Join(dir, "ratta.exe"), nil)
-	if _, verifyErr := service.VerifyContractorPassword("wrong"); verifyErr == nil {
+	if _, verifyErr := service.VerifyContractorPassword("", "wrong", ""); verifyErr == nil {
 		t.Fatal("expected verification error")
 	}
 }
@@ -137,7 +170,7 @@ func TestVerifyContractorPassword_SchemaInvalid(t *testing.T) {
 		t.Fatalf("NewValidatorFromDir error: %v", err)
 	}
 	service := NewService(filepath.Join(dir, "ratta.exe"), validator)
-	if _, err := service.VerifyContractorPassword("secret"); err == nil {
+	if _, err := service.VerifyContractorPassword("", "secret", ""); err == nil {
 		t.Fatal("expected schema invalid error")
 	}
 }
@@ -157,3 +190,109 @@ func TestDetectMode_ReadError(t *testing.T) {
 		t.Fatal("expected detect mode error")
 	}
 }
+
+// writeUsers はテスト用に実行ファイル隣の auth/users.json を作成し、実行ファイルのパスを返す。
+func writeUsers(t *testing.T, dir string, passwords map[string]string) string {
+	t.Helper()
+	store := crypto.NewUserStore()
+	for username, password := range passwords {
+		auth, err := crypto.GenerateContractorAuthWithKDF(password, crypto.KDFParams{Name: crypto.KDFArgon2id, Iterations: 2})
+		if err != nil {
+			t.Fatalf("GenerateContractorAuthWithKDF error: %v", err)
+		}
+		store = store.Put(crypto.NewUserAccount(username, auth))
+	}
+	data, err := jsonfmt.MarshalUsers(store)
+	if err != nil {
+		t.Fatalf("MarshalUsers error: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "auth"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "auth", "users.json"), data, 0o600); err != nil {
+		t.Fatalf("write users: %v", err)
+	}
+	return filepath.Join(dir, "ratta.exe")
+}
+
+func TestVerifyContractorPassword_UserAccounts(t *testing.T) {
+	// users.json がある場合はユーザー名とパスワードで照合し、ユーザー不在と不一致を同じエラーで返すことを確認する。
+	exePath := writeUsers(t, t.TempDir(), map[string]string{"alice": "alice-pw", "bob": "bob-pw"})
+	validator, err := schema.NewValidatorFromDir(filepath.Join("..", "..", "..", "schemas"))
+	if err != nil {
+		t.Fatalf("NewValidatorFromDir error: %v", err)
+	}
+	service := NewService(exePath, validator)
+
+	_, requiresPassword, err := service.DetectMode()
+	if err != nil || !requiresPassword {
+		t.Fatalf("expected password to be required, got %v err=%v", requiresPassword, err)
+	}
+	if requiresUsername, usernameErr := service.RequiresUsername(); usernameErr != nil || !requiresUsername {
+		t.Fatalf("expected username to be required, got %v err=%v", requiresUsername, usernameErr)
+	}
+	if gotMode, verifyErr := service.VerifyContractorPassword("bob", "bob-pw", ""); verifyErr != nil || gotMode != mode.ModeContractor {
+		t.Fatalf("expected contractor mode, got %s err=%v", gotMode, verifyErr)
+	}
+	_, wrongErr := service.VerifyContractorPassword("alice", "bob-pw", "")
+	_, unknownErr := service.VerifyContractorPassword("carol", "bob-pw", "")
+	if wrongErr == nil || unknownErr == nil || wrongErr.Error() != unknownErr.Error() {
+		t.Fatalf("expected identical failures, got %v / %v", wrongErr, unknownErr)
+	}
+	if _, emptyErr := service.VerifyContractorPassword("", "alice-pw", ""); emptyErr == nil {
+		t.Fatal("expected username to be required")
+	}
+}
+
+func TestVerifyContractorPassword_UsernameWithoutUserStore(t *testing.T) {
+	// users.json が無い場合はユーザー名付きの認証を拒否し、共有パスワードの contractor.json と区別することを確認する。
+	dir := t.TempDir()
+	service := NewService(filepath.Join(dir, "ratta.exe"), nil)
+	if requiresUsername, err := service.RequiresUsername(); err != nil || requiresUsername {
+		t.Fatalf("expected no username requirement, got %v err=%v", requiresUsername, err)
+	}
+	if _, err := service.VerifyContractorPassword("alice", "secret", ""); err == nil {
+		t.Fatal("expected error without users.json")
+	}
+}
+
+func TestRequiresTOTP_PerUserAccount(t *testing.T) {
+	// users.json では TOTP を設定したアカウントのみコードを要し、ユーザー名が空か不在なら設定済みアカウントの有無で判定することを確認する。
+	dir := t.TempDir()
+	exePath := writeUsers(t, dir, map[string]string{"alice": "alice-pw"})
+	service := NewService(exePath, nil)
+	if required, err := service.RequiresTOTP("alice"); err != nil || required {
+		t.Fatalf("expected no TOTP, got %v err=%v", required, err)
+	}
+
+	auth, err := crypto.GenerateContractorAuthWithKDF("bob-pw", crypto.KDFParams{Name: crypto.KDFArgon2id, Iterations: 2})
+	if err != nil {
+		t.Fatalf("GenerateContractorAuthWithKDF error: %v", err)
+	}
+	secret, err := crypto.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret error: %v", err)
+	}
+	if auth, err = crypto.AttachTOTPSecret(auth, "bob-pw", secret); err != nil {
+		t.Fatalf("AttachTOTPSecret error: %v", err)
+	}
+	store, err := readUserStore(filepath.Join(dir, "auth", "users.json"), nil)
+	if err != nil {
+		t.Fatalf("readUserStore error: %v", err)
+	}
+	data, err := jsonfmt.MarshalUsers(store.Put(crypto.NewUserAccount("bob", auth)))
+	if err != nil {
+		t.Fatalf("MarshalUsers error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "auth", "users.json"), data, 0o600); err != nil {
+		t.Fatalf("write users: %v", err)
+	}
+	for username, expected := range map[string]bool{"alice": false, "bob": true, "": true, "carol": true} {
+		if required, err := service.RequiresTOTP(username); err != nil || required != expected {
+			t.Fatalf("RequiresTOTP(%q) = %v err=%v, expected %v", username, required, err, expected)
+		}
+	}
+	if _, err := service.VerifyContractorPassword("bob", "bob-pw", ""); err == nil {
+		t.Fatal("expected bob to require a one-time code")
+	}
+}
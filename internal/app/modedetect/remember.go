@@ -0,0 +1,319 @@
+// remember.go は信頼した端末で Contractor 認証を記憶し、次回起動時のパスワード入力を省く処理を担い、
+// 資格情報ストアへの読み書き自体は扱わない。読み書きは infra/keychain が担う。
+// 記憶するのはパスワードや認証情報を復号できる導出鍵ではなく、この端末の記憶を表す乱数のトークンとする。
+// 認証ファイルと同じ auth ディレクトリの remembered.json にはトークンのハッシュと、記憶した時点の認証情報の指紋のみを保存する。
+package modedetect
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ratta/internal/domain/mode"
+	"ratta/internal/domain/timeutil"
+	"ratta/internal/infra/atomicwrite"
+	"ratta/internal/infra/crypto"
+	"ratta/internal/infra/jsonfmt"
+	"ratta/internal/infra/keychain"
+)
+
+const (
+	// keychainService は DD-MODE-003 の資格情報ストアに保存する項目のサービス名を表す。
+	keychainService = "ratta"
+	// rememberFormatVersion は DD-MODE-003 の資格情報ストアに保存する内容の形式バージョンを表す。
+	// 1 は導出鍵を保存していた形式で、読み取った場合は無効として消去する。
+	rememberFormatVersion = 2
+	// rememberedFileFormatVersion は DD-MODE-003 の remembered.json の形式バージョンを表す。
+	rememberedFileFormatVersion = 1
+	// rememberTokenBytes は DD-MODE-003 のトークンのバイト数を表す。
+	rememberTokenBytes = 32
+	// rememberIDBytes は DD-MODE-003 の記憶を識別する ID のバイト数を表す。
+	rememberIDBytes = 16
+)
+
+var (
+	keychainGet       = keychain.Get
+	keychainSet       = keychain.Set
+	keychainDelete    = keychain.Delete
+	keychainSupported = keychain.Supported
+	rememberRand      = rand.Read
+	writeRemembered   = atomicwrite.WriteFile
+)
+
+// ErrNotRemembered は DD-MODE-003 のこの端末で Contractor 認証を記憶していないことを表す。
+var ErrNotRemembered = errors.New("contractor unlock is not remembered")
+
+// ErrRememberRequiresCode は DD-MODE-003 の DD-CLI-008 のワンタイムコードを要する認証は記憶できないことを表す。
+// 記憶による照合はコードを求めないため、記憶できると二要素認証を迂回できてしまう。
+var ErrRememberRequiresCode = errors.New("contractor unlock cannot be remembered while a one-time code is required")
+
+// errRememberedInvalid は DD-MODE-003 の記憶が現在の認証情報で使えなくなったことを表す。
+var errRememberedInvalid = errors.New("remembered contractor unlock is no longer valid")
+
+// rememberedUnlock は DD-MODE-003 の資格情報ストアに保存する内容を表す。
+type rememberedUnlock struct {
+	FormatVersion int    `json:"format_version"`
+	ID            string `json:"id"`
+	Username      string `json:"username"`
+	TokenB64      string `json:"token_b64"`
+}
+
+// rememberedFile は DD-MODE-003 の remembered.json の形式を表す。端末・OS ユーザーごとの記憶を1件ずつ持つ。
+type rememberedFile struct {
+	FormatVersion int               `json:"format_version"`
+	Tokens        []rememberedToken `json:"tokens"`
+}
+
+// rememberedToken は DD-MODE-003 の1件の記憶を照合するための情報を表す。トークンそのものは持たない。
+type rememberedToken struct {
+	ID                  string `json:"id"`
+	Username            string `json:"username"`
+	TokenSHA256B64      string `json:"token_sha256_b64"`
+	CredentialSHA256B64 string `json:"credential_sha256_b64"`
+	CreatedAt           string `json:"created_at"`
+}
+
+// RememberSupported は DD-MODE-003 のこの端末で Contractor 認証を記憶できるかを返す。
+func RememberSupported() bool {
+	return keychainSupported()
+}
+
+// Remember は DD-MODE-003 に従い、この端末で Contractor 認証を記憶する。
+// 目的: 信頼した端末では次回起動時にパスワード入力を省けるようにする。
+// 入力: username はユーザー名 (contractor.json で認証する場合は空)、password は照合済みの平文パスワード。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: 認証情報の読み取り・照合の失敗、ワンタイムコードを要する場合は ErrRememberRequiresCode、
+// 資格情報ストアが使えない・保存に失敗した場合に返す。
+// 副作用: remembered.json に記憶を追加し、OS の資格情報ストアの項目を置き換える。以前の記憶は remembered.json から除く。
+// 並行性: 同一 auth ディレクトリへの同時実行は想定しない。
+// 不変条件: パスワードや導出鍵は保存しない。資格情報ストアの項目は auth ディレクトリごとに1件のみとする。
+// 関連DD: DD-MODE-003, DD-CLI-005, DD-CLI-007, DD-CLI-008
+func (s *Service) Remember(username, password string) error {
+	auth, err := s.loadAuth(username)
+	if err != nil {
+		return err
+	}
+	if auth.HasTOTP() {
+		return ErrRememberRequiresCode
+	}
+	if _, verifyErr := verifyResult(crypto.VerifyPassword(auth, password)); verifyErr != nil {
+		return verifyErr
+	}
+	token := make([]byte, rememberTokenBytes)
+	id := make([]byte, rememberIDBytes)
+	if _, randErr := rememberRand(token); randErr != nil {
+		return fmt.Errorf("generate remember token: %w", randErr)
+	}
+	if _, randErr := rememberRand(id); randErr != nil {
+		return fmt.Errorf("generate remember id: %w", randErr)
+	}
+	remembered := rememberedUnlock{
+		FormatVersion: rememberFormatVersion,
+		ID:            hex.EncodeToString(id),
+		Username:      username,
+		TokenB64:      base64.StdEncoding.EncodeToString(token),
+	}
+	data, err := json.Marshal(remembered)
+	if err != nil {
+		return fmt.Errorf("marshal remembered unlock: %w", err)
+	}
+	file := loadRememberedFile(s.rememberedPath)
+	if previous, readErr := s.readRemembered(); readErr == nil {
+		file = file.without(previous.ID)
+	}
+	file.Tokens = append(file.Tokens, rememberedToken{
+		ID:                  remembered.ID,
+		Username:            username,
+		TokenSHA256B64:      tokenDigest(token),
+		CredentialSHA256B64: credentialDigest(auth),
+		CreatedAt:           timeutil.NowISO8601(),
+	})
+	if saveErr := saveRememberedFile(s.rememberedPath, file); saveErr != nil {
+		return saveErr
+	}
+	// 資格情報ストアのコマンド入力で扱いやすいよう、引用符を含まない形にして保存する。
+	if setErr := keychainSet(keychainService, s.keychainAccount(), base64.StdEncoding.EncodeToString(data)); setErr != nil {
+		_ = saveRememberedFile(s.rememberedPath, file.without(remembered.ID))
+		return fmt.Errorf("remember contractor unlock: %w", setErr)
+	}
+	return nil
+}
+
+// Forget は DD-MODE-003 のこの端末で記憶した Contractor 認証を消去する。記憶していない場合も成功とする。
+func (s *Service) Forget() error {
+	if !keychainSupported() {
+		return nil
+	}
+	if remembered, err := s.readRemembered(); err == nil {
+		if saveErr := saveRememberedFile(s.rememberedPath, loadRememberedFile(s.rememberedPath).without(remembered.ID)); saveErr != nil {
+			return fmt.Errorf("forget contractor unlock: %w", saveErr)
+		}
+	}
+	if err := keychainDelete(keychainService, s.keychainAccount()); err != nil {
+		return fmt.Errorf("forget contractor unlock: %w", err)
+	}
+	return nil
+}
+
+// UnlockRemembered は DD-MODE-003 に従い、記憶した Contractor 認証でパスワード入力なしに照合する。
+// 目的: 信頼した端末での起動時にパスワード入力を省く。
+// 入力: なし。
+// 出力: 成功時は ModeContractor と記憶したユーザー名、失敗時は ModeVendor とエラー。
+// エラー: 記憶していない・資格情報ストアが使えない場合は ErrNotRemembered、
+// 記憶が現在の認証情報で使えない場合や読み取りに失敗した場合はそのエラーを返す。
+// 副作用: 資格情報ストアと remembered.json を読み取る。使えなくなった記憶は消去する。
+// 並行性: 同一 auth ディレクトリへの同時実行は想定しない。
+// 不変条件: トークンが一致し、記憶した時点から認証情報 (パスワード・鍵導出設定・TOTP) が変わっていない場合のみ Contractor モードにする。
+// ワンタイムコードを要する認証には用いない。DD-MODE-002 の失敗回数には数えない。
+// 関連DD: DD-MODE-003, DD-CLI-005, DD-CLI-007, DD-CLI-008
+func (s *Service) UnlockRemembered() (mode.Mode, string, error) {
+	if !keychainSupported() {
+		return mode.ModeVendor, "", ErrNotRemembered
+	}
+	secret, err := keychainGet(keychainService, s.keychainAccount())
+	if errors.Is(err, keychain.ErrNotFound) {
+		return mode.ModeVendor, "", ErrNotRemembered
+	}
+	if err != nil {
+		return mode.ModeVendor, "", fmt.Errorf("read remembered unlock: %w", err)
+	}
+	remembered, token, err := decodeRemembered(secret)
+	if err != nil {
+		_ = s.Forget()
+		return mode.ModeVendor, "", err
+	}
+	entry, found := loadRememberedFile(s.rememberedPath).find(remembered.ID)
+	if !found || entry.Username != remembered.Username || !digestMatches(entry.TokenSHA256B64, tokenDigest(token)) {
+		_ = s.Forget()
+		return mode.ModeVendor, "", errRememberedInvalid
+	}
+	auth, err := s.loadAuth(remembered.Username)
+	if errors.Is(err, errPasswordMismatch) || (err == nil && (auth.HasTOTP() || !digestMatches(entry.CredentialSHA256B64, credentialDigest(auth)))) {
+		// パスワード・鍵導出設定の変更、TOTP の設定、アカウントの削除で使えなくなった記憶は残さない。
+		_ = s.Forget()
+		return mode.ModeVendor, "", errRememberedInvalid
+	}
+	if err != nil {
+		return mode.ModeVendor, "", err
+	}
+	return mode.ModeContractor, remembered.Username, nil
+}
+
+// keychainAccount は DD-MODE-003 の資格情報ストアの項目名を、認証ファイルを置く auth ディレクトリから決める。
+func (s *Service) keychainAccount() string {
+	return "contractor:" + filepath.Dir(s.authPath)
+}
+
+// readRemembered は DD-MODE-003 の資格情報ストアの項目を読み取り、記憶した内容に戻す。
+func (s *Service) readRemembered() (rememberedUnlock, error) {
+	secret, err := keychainGet(keychainService, s.keychainAccount())
+	if err != nil {
+		return rememberedUnlock{}, err
+	}
+	remembered, _, err := decodeRemembered(secret)
+	return remembered, err
+}
+
+// decodeRemembered は DD-MODE-003 の資格情報ストアから読み取った内容を記憶した内容とトークンに戻す。
+func decodeRemembered(secret string) (rememberedUnlock, []byte, error) {
+	data, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return rememberedUnlock{}, nil, fmt.Errorf("decode remembered unlock: %w", err)
+	}
+	var remembered rememberedUnlock
+	if unmarshalErr := json.Unmarshal(data, &remembered); unmarshalErr != nil {
+		return rememberedUnlock{}, nil, fmt.Errorf("parse remembered unlock: %w", unmarshalErr)
+	}
+	if remembered.FormatVersion != rememberFormatVersion {
+		return rememberedUnlock{}, nil, fmt.Errorf("unsupported remembered unlock version: %d", remembered.FormatVersion)
+	}
+	token, err := base64.StdEncoding.DecodeString(remembered.TokenB64)
+	if err != nil {
+		return rememberedUnlock{}, nil, fmt.Errorf("decode remember token: %w", err)
+	}
+	return remembered, token, nil
+}
+
+// tokenDigest は DD-MODE-003 のトークンの SHA-256 を base64 で返す。トークンは乱数のため鍵導出を行わない。
+func tokenDigest(token []byte) string {
+	sum := sha256.Sum256(token)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// credentialDigest は DD-MODE-003 の認証情報の指紋を返す。
+// パスワードの変更・鍵導出設定の変更・TOTP の設定のいずれでも salt・暗号文などの値が変わるため、指紋も変わる。
+func credentialDigest(auth crypto.ContractorAuth) string {
+	// 構造体のフィールドの順で変換するため、同じ認証情報からは常に同じ値となる。
+	data, err := json.Marshal(auth)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// digestMatches は DD-MODE-003 の2つのハッシュが一致するかを一定時間で比較する。空の場合は一致としない。
+func digestMatches(stored, actual string) bool {
+	return stored != "" && subtle.ConstantTimeCompare([]byte(stored), []byte(actual)) == 1
+}
+
+// loadRememberedFile は DD-MODE-003 の remembered.json を読み込む。
+// ファイルが無い場合や壊れている場合は記憶の無い内容を返す (記憶による照合は行わず、パスワード入力に戻す)。
+func loadRememberedFile(path string) rememberedFile {
+	data, err := readFile(path)
+	if err != nil {
+		return rememberedFile{FormatVersion: rememberedFileFormatVersion}
+	}
+	var file rememberedFile
+	if unmarshalErr := json.Unmarshal(data, &file); unmarshalErr != nil || file.FormatVersion != rememberedFileFormatVersion {
+		return rememberedFile{FormatVersion: rememberedFileFormatVersion}
+	}
+	return file
+}
+
+// saveRememberedFile は DD-MODE-003 の remembered.json を保存する。記憶が無くなった場合はファイルを削除する。
+func saveRememberedFile(path string, file rememberedFile) error {
+	if len(file.Tokens) == 0 {
+		if err := removeFile(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove remembered unlocks: %w", err)
+		}
+		return nil
+	}
+	file.FormatVersion = rememberedFileFormatVersion
+	data, err := jsonfmt.MarshalRemembered(file)
+	if err != nil {
+		return fmt.Errorf("marshal remembered unlocks: %w", err)
+	}
+	if err := writeRemembered(path, data); err != nil {
+		return fmt.Errorf("write remembered unlocks: %w", err)
+	}
+	return nil
+}
+
+// find は DD-MODE-003 の id の記憶を返す。
+func (f rememberedFile) find(id string) (rememberedToken, bool) {
+	for _, token := range f.Tokens {
+		if token.ID == id {
+			return token, true
+		}
+	}
+	return rememberedToken{}, false
+}
+
+// without は DD-MODE-003 の id の記憶を除いた内容を返す。元の内容は変更しない。
+func (f rememberedFile) without(id string) rememberedFile {
+	next := rememberedFile{FormatVersion: f.FormatVersion, Tokens: make([]rememberedToken, 0, len(f.Tokens))}
+	for _, token := range f.Tokens {
+		if token.ID != id {
+			next.Tokens = append(next.Tokens, token)
+		}
+	}
+	return next
+}
@@ -0,0 +1,210 @@
+// remember_test.go は信頼した端末での Contractor 認証の記憶と解除のテストを行い、OS の資格情報ストアは扱わない。
+package modedetect
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ratta/internal/domain/mode"
+	"ratta/internal/infra/crypto"
+	"ratta/internal/infra/jsonfmt"
+	"ratta/internal/infra/keychain"
+)
+
+// fakeKeychain はテスト用に資格情報ストアをメモリ上の項目へ差し替え、保存内容を返す。
+func fakeKeychain(t *testing.T) map[string]string {
+	t.Helper()
+	items := map[string]string{}
+	originalGet, originalSet, originalDelete, originalSupported := keychainGet, keychainSet, keychainDelete, keychainSupported
+	keychainGet = func(service, account string) (string, error) {
+		secret, ok := items[service+"|"+account]
+		if !ok {
+			return "", keychain.ErrNotFound
+		}
+		return secret, nil
+	}
+	keychainSet = func(service, account, secret string) error {
+		items[service+"|"+account] = secret
+		return nil
+	}
+	keychainDelete = func(service, account string) error {
+		delete(items, service+"|"+account)
+		return nil
+	}
+	keychainSupported = func() bool { return true }
+	t.Cleanup(func() {
+		keychainGet, keychainSet, keychainDelete, keychainSupported = originalGet, originalSet, originalDelete, originalSupported
+	})
+	return items
+}
+
+func TestRemember_UnlocksWithoutPassword(t *testing.T) {
+	// 記憶した認証でパスワードなしに Contractor となり、パスワードも導出鍵も保存しないことを確認する。
+	items := fakeKeychain(t)
+	dir := t.TempDir()
+	service := NewService(writeUsers(t, dir, map[string]string{"alice": "alice-pw"}), nil)
+
+	if _, _, err := service.UnlockRemembered(); !errors.Is(err, ErrNotRemembered) {
+		t.Fatalf("expected not remembered, got %v", err)
+	}
+	if err := service.Remember("alice", "wrong"); err == nil {
+		t.Fatal("expected wrong password not to be remembered")
+	}
+	if err := service.Remember("alice", "alice-pw"); err != nil {
+		t.Fatalf("Remember error: %v", err)
+	}
+	auth, err := service.loadAuth("alice")
+	if err != nil {
+		t.Fatalf("loadAuth error: %v", err)
+	}
+	key, err := crypto.DeriveUnlockKey(auth, "alice-pw")
+	if err != nil {
+		t.Fatalf("DeriveUnlockKey error: %v", err)
+	}
+	// #nosec G304 -- テスト用ディレクトリ配下の固定パスを読むため安全。
+	file, err := os.ReadFile(filepath.Join(dir, "auth", "remembered.json"))
+	if err != nil {
+		t.Fatalf("read remembered.json: %v", err)
+	}
+	for _, secret := range items {
+		data, decodeErr := base64.StdEncoding.DecodeString(secret)
+		if decodeErr != nil {
+			t.Fatalf("decode secret: %v", decodeErr)
+		}
+		stored := string(data)
+		if strings.Contains(stored, "alice-pw") || strings.Contains(stored, base64.StdEncoding.EncodeToString(key)) {
+			t.Fatalf("expected neither password nor derived key to be stored: %s", stored)
+		}
+		var remembered rememberedUnlock
+		if unmarshalErr := json.Unmarshal(data, &remembered); unmarshalErr != nil {
+			t.Fatalf("parse secret: %v", unmarshalErr)
+		}
+		if strings.Contains(string(file), remembered.TokenB64) {
+			t.Fatal("expected remembered.json not to contain the token")
+		}
+	}
+
+	gotMode, username, err := service.UnlockRemembered()
+	if err != nil || gotMode != mode.ModeContractor || username != "alice" {
+		t.Fatalf("expected contractor alice, got %s %q err=%v", gotMode, username, err)
+	}
+	if err := service.Forget(); err != nil {
+		t.Fatalf("Forget error: %v", err)
+	}
+	if _, _, err := service.UnlockRemembered(); !errors.Is(err, ErrNotRemembered) {
+		t.Fatalf("expected not remembered after forget, got %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(dir, "auth", "remembered.json")); !os.IsNotExist(statErr) {
+		t.Fatalf("expected remembered.json to be removed, got %v", statErr)
+	}
+}
+
+func TestRemember_RefusesWhenOneTimeCodeRequired(t *testing.T) {
+	// DD-CLI-008 の TOTP を設定したアカウントは記憶できず、資格情報ストアに何も保存しないことを確認する。
+	items := fakeKeychain(t)
+	dir := t.TempDir()
+	exePath := writeUsers(t, dir, map[string]string{})
+	auth, err := crypto.GenerateContractorAuthWithKDF("alice-pw", crypto.KDFParams{Name: crypto.KDFArgon2id, Iterations: 2})
+	if err != nil {
+		t.Fatalf("GenerateContractorAuthWithKDF error: %v", err)
+	}
+	secret, err := crypto.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret error: %v", err)
+	}
+	if auth, err = crypto.AttachTOTPSecret(auth, "alice-pw", secret); err != nil {
+		t.Fatalf("AttachTOTPSecret error: %v", err)
+	}
+	data, err := jsonfmt.MarshalUsers(crypto.NewUserStore().Put(crypto.NewUserAccount("alice", auth)))
+	if err != nil {
+		t.Fatalf("MarshalUsers error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "auth", "users.json"), data, 0o600); err != nil {
+		t.Fatalf("write users: %v", err)
+	}
+
+	service := NewService(exePath, nil)
+	if err := service.Remember("alice", "alice-pw"); !errors.Is(err, ErrRememberRequiresCode) {
+		t.Fatalf("expected ErrRememberRequiresCode, got %v", err)
+	}
+	if len(items) != 0 {
+		t.Fatalf("expected nothing to be stored, got %d items", len(items))
+	}
+}
+
+func TestUnlockRemembered_ForgetsAfterKDFChange(t *testing.T) {
+	// 同じパスワードでも鍵導出設定を変更すると記憶した認証では照合できず、記憶を消去することを確認する。
+	items := fakeKeychain(t)
+	dir := t.TempDir()
+	service := NewService(writeUsers(t, dir, map[string]string{"alice": "alice-pw"}), nil)
+	if err := service.Remember("alice", "alice-pw"); err != nil {
+		t.Fatalf("Remember error: %v", err)
+	}
+
+	auth, err := crypto.GenerateContractorAuthWithKDF("alice-pw", crypto.KDFParams{Name: crypto.KDFArgon2id, Iterations: 3})
+	if err != nil {
+		t.Fatalf("GenerateContractorAuthWithKDF error: %v", err)
+	}
+	data, err := jsonfmt.MarshalUsers(crypto.NewUserStore().Put(crypto.NewUserAccount("alice", auth)))
+	if err != nil {
+		t.Fatalf("MarshalUsers error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "auth", "users.json"), data, 0o600); err != nil {
+		t.Fatalf("write users: %v", err)
+	}
+	if gotMode, _, err := service.UnlockRemembered(); err == nil || gotMode != mode.ModeVendor {
+		t.Fatalf("expected unlock to be rejected after KDF change, got %s err=%v", gotMode, err)
+	}
+	if len(items) != 0 {
+		t.Fatalf("expected stale unlock to be forgotten, got %d items", len(items))
+	}
+	if _, statErr := os.Stat(filepath.Join(dir, "auth", "remembered.json")); !os.IsNotExist(statErr) {
+		t.Fatalf("expected remembered.json entry to be removed, got %v", statErr)
+	}
+}
+
+func TestUnlockRemembered_ForgetsUnknownToken(t *testing.T) {
+	// 以前の形式 (導出鍵) の項目や remembered.json に無いトークンでは照合せず、その項目を消去することを確認する。
+	items := fakeKeychain(t)
+	service := NewService(writeUsers(t, t.TempDir(), map[string]string{"alice": "alice-pw"}), nil)
+	legacy, err := json.Marshal(map[string]any{"format_version": 1, "username": "alice", "key_b64": "AAAA"})
+	if err != nil {
+		t.Fatalf("marshal legacy: %v", err)
+	}
+	forged, err := json.Marshal(rememberedUnlock{FormatVersion: rememberFormatVersion, ID: "00", Username: "alice", TokenB64: "AAAA"})
+	if err != nil {
+		t.Fatalf("marshal forged: %v", err)
+	}
+	for _, data := range [][]byte{legacy, forged} {
+		items[keychainService+"|"+service.keychainAccount()] = base64.StdEncoding.EncodeToString(data)
+		if gotMode, _, err := service.UnlockRemembered(); err == nil || gotMode != mode.ModeVendor {
+			t.Fatalf("expected unlock to be rejected, got %s err=%v", gotMode, err)
+		}
+		if len(items) != 0 {
+			t.Fatalf("expected item to be forgotten, got %d items", len(items))
+		}
+	}
+}
+
+func TestUnlockRemembered_ForgetsAfterPasswordChange(t *testing.T) {
+	// パスワードを変更すると記憶した認証では照合できず、その記憶を消去することを確認する。
+	items := fakeKeychain(t)
+	dir := t.TempDir()
+	service := NewService(writeUsers(t, dir, map[string]string{"alice": "alice-pw"}), nil)
+	if err := service.Remember("alice", "alice-pw"); err != nil {
+		t.Fatalf("Remember error: %v", err)
+	}
+
+	writeUsers(t, dir, map[string]string{"alice": "new-pw"})
+	if gotMode, _, err := service.UnlockRemembered(); err == nil || gotMode != mode.ModeVendor {
+		t.Fatalf("expected stale unlock to be rejected, got %s err=%v", gotMode, err)
+	}
+	if len(items) != 0 {
+		t.Fatalf("expected stale unlock to be forgotten, got %d items", len(items))
+	}
+}
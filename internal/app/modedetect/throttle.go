@@ -0,0 +1,117 @@
+// throttle.go はパスワード照合の失敗回数の記録と再試行までの待ち時間の判定を担い、パスワードの照合自体は扱わない。
+// 失敗回数は認証ファイルと同じ auth ディレクトリの attempts.json に保存し、CLI と GUI で共有する。
+package modedetect
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/timeutil"
+	"ratta/internal/infra/atomicwrite"
+	"ratta/internal/infra/jsonfmt"
+)
+
+const (
+	// attemptsFormatVersion は DD-MODE-002 の attempts.json の形式バージョンを表す。
+	attemptsFormatVersion = 1
+	// freeAttempts は DD-MODE-002 の待ち時間なしで続けて失敗できる回数を表す。
+	freeAttempts = 3
+	// baseBackoff は DD-MODE-002 の freeAttempts 回目の失敗後に課す待ち時間を表し、以降は失敗のたびに倍にする。
+	baseBackoff = 2 * time.Second
+	// maxBackoff は DD-MODE-002 の待ち時間の上限を表す。
+	maxBackoff = 15 * time.Minute
+)
+
+var (
+	throttleNow   = time.Now
+	writeAttempts = atomicwrite.WriteFile
+	removeFile    = os.Remove
+)
+
+// ErrTooManyAttempts は DD-MODE-002 の失敗が続いたため、待ち時間が過ぎるまで照合しないことを示す。
+var ErrTooManyAttempts = apperr.New(apperr.ErrPermission, "permission denied: too many failed password attempts")
+
+// attemptRecord は DD-MODE-002 の attempts.json の形式を表す。
+type attemptRecord struct {
+	FormatVersion  int    `json:"format_version"`
+	FailedAttempts int    `json:"failed_attempts"`
+	LastFailureAt  string `json:"last_failure_at"`
+}
+
+// backoff は DD-MODE-002 の連続 failures 回の失敗後に課す待ち時間を返す。
+func backoff(failures int) time.Duration {
+	if failures < freeAttempts {
+		return 0
+	}
+	shift := failures - freeAttempts
+	if shift >= 16 {
+		return maxBackoff
+	}
+	delay := baseBackoff << shift
+	if delay > maxBackoff {
+		return maxBackoff
+	}
+	return delay
+}
+
+// remainingWait は DD-MODE-002 の記録から、次の照合を受け付けるまでの残り時間を返す。
+func (r attemptRecord) remainingWait(now time.Time) time.Duration {
+	delay := backoff(r.FailedAttempts)
+	if delay == 0 {
+		return 0
+	}
+	last, err := time.Parse(time.RFC3339, r.LastFailureAt)
+	if err != nil {
+		// 時刻を読めない場合は直前に失敗したものとして扱う。
+		return delay
+	}
+	wait := delay - now.Sub(last)
+	if wait > delay {
+		// 時計が戻された場合も待ち時間を延ばさない。
+		return delay
+	}
+	return wait
+}
+
+// loadAttempts は DD-MODE-002 の attempts.json を読み込む。
+// ファイルが無い場合や壊れている場合は失敗の無い記録を返す (照合を妨げないことを優先する)。
+func loadAttempts(path string) attemptRecord {
+	data, err := readFile(path)
+	if err != nil {
+		return attemptRecord{}
+	}
+	var record attemptRecord
+	if unmarshalErr := json.Unmarshal(data, &record); unmarshalErr != nil {
+		return attemptRecord{}
+	}
+	return record
+}
+
+// recordFailure は DD-MODE-002 の失敗回数を1件増やして attempts.json に保存し、更新後の記録を返す。
+func recordFailure(path string, record attemptRecord) (attemptRecord, error) {
+	next := attemptRecord{
+		FormatVersion:  attemptsFormatVersion,
+		FailedAttempts: record.FailedAttempts + 1,
+		LastFailureAt:  timeutil.FormatISO8601(throttleNow()),
+	}
+	data, err := jsonfmt.MarshalAttempts(next)
+	if err != nil {
+		return next, fmt.Errorf("marshal attempts: %w", err)
+	}
+	if writeErr := writeAttempts(path, data); writeErr != nil {
+		return next, fmt.Errorf("write attempts: %w", writeErr)
+	}
+	return next, nil
+}
+
+// resetAttempts は DD-MODE-002 の照合成功時に attempts.json を削除し、失敗回数を 0 に戻す。
+func resetAttempts(path string) error {
+	if err := removeFile(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove attempts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// throttle_test.go はパスワード照合の失敗回数の記録と待ち時間のテストを行う。
+package modedetect
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ratta/internal/domain/mode"
+)
+
+func TestBackoff_DoublesUpToLimit(t *testing.T) {
+	// 待ち時間なしで失敗できる回数を超えると待ち時間が倍々に伸び、上限で止まることを確認する。
+	cases := map[int]time.Duration{
+		0:   0,
+		2:   0,
+		3:   2 * time.Second,
+		4:   4 * time.Second,
+		6:   16 * time.Second,
+		12:  maxBackoff,
+		100: maxBackoff,
+	}
+	for failures, expected := range cases {
+		if got := backoff(failures); got != expected {
+			t.Fatalf("backoff(%d) = %s, expected %s", failures, got, expected)
+		}
+	}
+}
+
+func TestVerifyContractorPassword_ThrottlesRepeatedFailures(t *testing.T) {
+	// 失敗が続くと待ち時間中は正しいパスワードでも照合せず、待ち時間の経過後に成功すると失敗回数を消すことを確認する。
+	exePath := writeUsers(t, t.TempDir(), map[string]string{"alice": "alice-pw"})
+	current := time.Date(2026, 1, 2, 3, 4, 5, 0, time.Local)
+	original := throttleNow
+	throttleNow = func() time.Time { return current }
+	t.Cleanup(func() { throttleNow = original })
+	service := NewService(exePath, nil)
+
+	for i := 0; i < freeAttempts; i++ {
+		if _, err := service.VerifyContractorPassword("alice", "wrong", ""); !errors.Is(err, errPasswordMismatch) {
+			t.Fatalf("attempt %d: expected mismatch, got %v", i+1, err)
+		}
+	}
+	if got := service.FailedAttempts(); got != freeAttempts {
+		t.Fatalf("expected %d failed attempts, got %d", freeAttempts, got)
+	}
+	if _, err := service.VerifyContractorPassword("alice", "alice-pw", ""); !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("expected throttling, got %v", err)
+	}
+
+	current = current.Add(backoff(freeAttempts))
+	gotMode, err := service.VerifyContractorPassword("alice", "alice-pw", "")
+	if err != nil || gotMode != mode.ModeContractor {
+		t.Fatalf("expected contractor after backoff, got %s err=%v", gotMode, err)
+	}
+	attemptsPath := filepath.Join(filepath.Dir(exePath), "auth", "attempts.json")
+	if _, statErr := os.Stat(attemptsPath); !errors.Is(statErr, os.ErrNotExist) {
+		t.Fatalf("expected attempts.json to be removed, got %v", statErr)
+	}
+}
+
+func TestVerifyContractorPassword_IgnoresCorruptAttempts(t *testing.T) {
+	// 壊れた attempts.json は失敗の無い記録として扱い、照合を妨げないことを確認する。
+	exePath := writeUsers(t, t.TempDir(), map[string]string{"alice": "alice-pw"})
+	attemptsPath := filepath.Join(filepath.Dir(exePath), "auth", "attempts.json")
+	if err := os.WriteFile(attemptsPath, []byte("{broken"), 0o600); err != nil {
+		t.Fatalf("write attempts: %v", err)
+	}
+
+	service := NewService(exePath, nil)
+	if gotMode, err := service.VerifyContractorPassword("alice", "alice-pw", ""); err != nil || gotMode != mode.ModeContractor {
+		t.Fatalf("expected contractor mode, got %s err=%v", gotMode, err)
+	}
+}
@@ -0,0 +1,129 @@
+// Package modesession は起動中の操作モードと Contractor の無操作時間の計測を担い、
+// パスワードの照合や UI への通知は扱わない。照合は modedetect が、通知は呼び出し側が担う。
+package modesession
+
+import (
+	"sync"
+	"time"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// Reason は DD-MODE-001 の Contractor モードを解除した理由を表す。
+type Reason string
+
+const (
+	// ReasonIdle は無操作時間が上限に達したことによる解除を表す。
+	ReasonIdle Reason = "idle"
+	// ReasonManual は利用者の操作 (LockMode) による解除を表す。
+	ReasonManual Reason = "manual"
+)
+
+// Session は DD-MODE-001 の操作モードと Contractor のアカウント名を保持する。
+// Contractor モードの間は無操作時間を計測し、上限に達すると Vendor モードへ戻す。
+type Session struct {
+	mu       sync.Mutex
+	mode     mod.Mode
+	user     string
+	timeout  time.Duration
+	timer    *time.Timer
+	gen      uint64
+	onExpire func(Reason)
+}
+
+// New は DD-MODE-001 の initial モードで始まるセッションを生成する。
+// timeout が 0 以下の場合は無操作で Contractor モードを解除しない。
+// onExpire は無操作で解除した後に呼ばれ、nil の場合は呼ばない。
+func New(initial mod.Mode, timeout time.Duration, onExpire func(Reason)) *Session {
+	return &Session{mode: initial, timeout: timeout, onExpire: onExpire}
+}
+
+// Mode は DD-MODE-001 の現在の操作モードを返す。
+func (s *Session) Mode() mod.Mode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mode
+}
+
+// User は DD-CLI-007 の照合した Contractor のアカウント名を返す。Contractor モードでない場合や共有パスワードの場合は空とする。
+func (s *Session) User() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.user
+}
+
+// Enter は DD-MODE-001 の操作モードを切り替える。
+// 目的: パスワード照合の成功や Observer への切り替えを反映し、Contractor モードでは無操作時間の計測を始める。
+// 入力: next は切り替え後のモード、user は照合したアカウント名 (Contractor 以外では無視する)。
+// 出力: なし。
+// エラー: なし。
+// 副作用: Contractor モードでは計測用のタイマーを開始し、それ以外では停止する。
+// 並行性: スレッドセーフ。
+// 不変条件: Contractor 以外のモードではアカウント名を保持しない。
+// 関連DD: DD-MODE-001, DD-BE-005
+func (s *Session) Enter(next mod.Mode, user string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mode = next
+	s.user = ""
+	if next == mod.ModeContractor {
+		s.user = user
+	}
+	s.restartLocked()
+}
+
+// Lock は DD-MODE-001 の Contractor モードを解除して Vendor モードへ戻す。
+// Contractor モードでない場合は何もせず false を返す。
+func (s *Session) Lock() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mode != mod.ModeContractor {
+		return false
+	}
+	s.mode = mod.ModeVendor
+	s.user = ""
+	s.restartLocked()
+	return true
+}
+
+// Touch は DD-MODE-001 の操作があったことを記録し、Contractor モードの無操作時間の計測をやり直す。
+func (s *Session) Touch() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mode == mod.ModeContractor {
+		s.restartLocked()
+	}
+}
+
+// restartLocked は DD-MODE-001 のタイマーを止め、Contractor モードであれば計測を始め直す。s.mu を保持して呼ぶ。
+// 止めたタイマーが既に発火していた場合に備え、世代番号で古い発火を無視する。
+func (s *Session) restartLocked() {
+	s.gen++
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.mode != mod.ModeContractor || s.timeout <= 0 {
+		return
+	}
+	gen := s.gen
+	s.timer = time.AfterFunc(s.timeout, func() { s.expire(gen) })
+}
+
+// expire は DD-MODE-001 の無操作時間の上限に達した際に Vendor モードへ戻し、onExpire を呼ぶ。
+func (s *Session) expire(gen uint64) {
+	s.mu.Lock()
+	if gen != s.gen || s.mode != mod.ModeContractor {
+		s.mu.Unlock()
+		return
+	}
+	s.mode = mod.ModeVendor
+	s.user = ""
+	s.timer = nil
+	s.gen++
+	onExpire := s.onExpire
+	s.mu.Unlock()
+	if onExpire != nil {
+		onExpire(ReasonIdle)
+	}
+}
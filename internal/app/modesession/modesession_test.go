@@ -0,0 +1,94 @@
+// modesession_test.go は操作モードの切り替えと無操作による Contractor モードの解除のテストを行う。
+package modesession
+
+import (
+	"testing"
+	"time"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// waitReason はテスト用に解除の通知を待ち、期限内に届かなければ失敗する。
+func waitReason(t *testing.T, reasons <-chan Reason) Reason {
+	t.Helper()
+	select {
+	case reason := <-reasons:
+		return reason
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected expiry notification")
+		return ""
+	}
+}
+
+func TestSession_IdleTimeoutDropsToVendor(t *testing.T) {
+	// Contractor モードで操作が無いまま上限に達すると Vendor へ戻り、アカウント名を消して通知することを確認する。
+	reasons := make(chan Reason, 1)
+	session := New(mod.ModeVendor, 20*time.Millisecond, func(reason Reason) { reasons <- reason })
+	session.Enter(mod.ModeContractor, "alice")
+	if session.Mode() != mod.ModeContractor || session.User() != "alice" {
+		t.Fatalf("expected contractor alice, got %s %q", session.Mode(), session.User())
+	}
+
+	if reason := waitReason(t, reasons); reason != ReasonIdle {
+		t.Fatalf("expected idle reason, got %s", reason)
+	}
+	if session.Mode() != mod.ModeVendor || session.User() != "" {
+		t.Fatalf("expected vendor without user, got %s %q", session.Mode(), session.User())
+	}
+}
+
+func TestSession_TouchPostponesExpiry(t *testing.T) {
+	// 操作があるたびに計測をやり直し、上限より長く使い続けても解除しないことを確認する。
+	reasons := make(chan Reason, 1)
+	session := New(mod.ModeVendor, 80*time.Millisecond, func(reason Reason) { reasons <- reason })
+	session.Enter(mod.ModeContractor, "")
+	for i := 0; i < 5; i++ {
+		time.Sleep(30 * time.Millisecond)
+		session.Touch()
+	}
+	if session.Mode() != mod.ModeContractor {
+		t.Fatalf("expected contractor while active, got %s", session.Mode())
+	}
+	waitReason(t, reasons)
+}
+
+func TestSession_LockStopsTimer(t *testing.T) {
+	// 手動で解除した場合は Vendor へ戻り、その後に無操作の通知が届かないことを確認する。
+	reasons := make(chan Reason, 1)
+	session := New(mod.ModeVendor, 20*time.Millisecond, func(reason Reason) { reasons <- reason })
+	session.Enter(mod.ModeContractor, "alice")
+	if !session.Lock() {
+		t.Fatal("expected lock to drop contractor mode")
+	}
+	if session.Mode() != mod.ModeVendor || session.User() != "" {
+		t.Fatalf("expected vendor without user, got %s %q", session.Mode(), session.User())
+	}
+	if session.Lock() {
+		t.Fatal("expected second lock to be a no-op")
+	}
+	select {
+	case reason := <-reasons:
+		t.Fatalf("unexpected expiry after lock: %s", reason)
+	case <-time.After(60 * time.Millisecond):
+	}
+}
+
+func TestSession_NoTimeoutOrOtherModes(t *testing.T) {
+	// 上限が 0 の場合や Contractor 以外のモードでは解除しないことを確認する。
+	reasons := make(chan Reason, 1)
+	disabled := New(mod.ModeVendor, 0, func(reason Reason) { reasons <- reason })
+	disabled.Enter(mod.ModeContractor, "alice")
+	observer := New(mod.ModeVendor, 10*time.Millisecond, func(reason Reason) { reasons <- reason })
+	observer.Enter(mod.ModeObserver, "alice")
+	select {
+	case reason := <-reasons:
+		t.Fatalf("unexpected expiry: %s", reason)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if disabled.Mode() != mod.ModeContractor {
+		t.Fatalf("expected contractor without timeout, got %s", disabled.Mode())
+	}
+	if observer.Mode() != mod.ModeObserver || observer.User() != "" {
+		t.Fatalf("expected observer without user, got %s %q", observer.Mode(), observer.User())
+	}
+}
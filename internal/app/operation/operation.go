@@ -0,0 +1,66 @@
+// Package operation は実行中の長時間処理を識別子で管理し、利用者からの中断要求を処理へ伝えることを担う。
+// 処理内容や UI への通知は扱わない。
+package operation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Registry は DD-CANCEL-001 の実行中の処理の一覧を表す。
+type Registry struct {
+	mu      sync.Mutex
+	seq     uint64
+	running map[string]context.CancelFunc
+}
+
+// NewRegistry は DD-CANCEL-001 の空の処理一覧を生成する。
+func NewRegistry() *Registry {
+	return &Registry{running: make(map[string]context.CancelFunc)}
+}
+
+// Begin は DD-CANCEL-001 の処理の開始を登録する。
+// 目的: 処理に識別子と中断可能な context を割り当てる。
+// 入力: parent は親の context。
+// 出力: 処理ID、処理に渡す context、完了時に呼ぶ関数。
+// エラー: なし。
+// 副作用: 処理一覧に登録する。完了関数は登録を解除し、context を解放する。
+// 並行性: スレッドセーフ。
+// 不変条件: 処理IDはレジストリ内で再利用しない。
+// 関連DD: DD-CANCEL-001
+func (r *Registry) Begin(parent context.Context) (string, context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+	r.mu.Lock()
+	r.seq++
+	id := fmt.Sprintf("op-%d", r.seq)
+	r.running[id] = cancel
+	r.mu.Unlock()
+
+	done := func() {
+		r.mu.Lock()
+		delete(r.running, id)
+		r.mu.Unlock()
+		cancel()
+	}
+	return id, ctx, done
+}
+
+// Cancel は DD-CANCEL-001 の処理の中断を要求する。処理が見つからない場合は false を返す。
+// 処理は中断を検知した時点で ctx.Err() を返して終わるため、呼び出し直後に停止しているとは限らない。
+func (r *Registry) Cancel(id string) bool {
+	r.mu.Lock()
+	cancel, ok := r.running[id]
+	r.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// Running は DD-CANCEL-001 の実行中の処理数を返す。
+func (r *Registry) Running() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.running)
+}
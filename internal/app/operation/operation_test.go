@@ -0,0 +1,40 @@
+// operation_test.go は処理一覧の登録・中断・解除のテストを行う。
+package operation
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRegistry_CancelAndDone(t *testing.T) {
+	// 中断要求が該当処理の context にのみ伝わり、完了後の処理IDは中断できないことを確認する。
+	registry := NewRegistry()
+	firstID, firstCtx, firstDone := registry.Begin(context.Background())
+	secondID, secondCtx, secondDone := registry.Begin(context.Background())
+	defer secondDone()
+	if firstID == secondID {
+		t.Fatalf("expected distinct ids, got %s", firstID)
+	}
+
+	if !registry.Cancel(firstID) {
+		t.Fatalf("expected cancel to find %s", firstID)
+	}
+	if !errors.Is(firstCtx.Err(), context.Canceled) {
+		t.Fatalf("expected first context canceled, got %v", firstCtx.Err())
+	}
+	if secondCtx.Err() != nil {
+		t.Fatalf("second context must stay active: %v", secondCtx.Err())
+	}
+
+	firstDone()
+	if registry.Cancel(firstID) {
+		t.Fatalf("finished operation must not be cancelable")
+	}
+	if registry.Running() != 1 {
+		t.Fatalf("expected 1 running operation, got %d", registry.Running())
+	}
+	if registry.Cancel("missing") {
+		t.Fatalf("unknown id must not be cancelable")
+	}
+}
@@ -0,0 +1,407 @@
+// apply.go はパッチの課題をプロジェクトへ取り込む処理を担い、パッチの読み込みと照合は patchbundle.go に委ねる。
+package patchbundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ratta/internal/app/categoryops"
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/atomicwrite"
+	"ratta/internal/infra/schema"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// Action* は DD-PATCH-002 の課題ごとの取り込みの結果を表す。
+// ActionRenamed は別の課題と課題IDが衝突したため、新たな課題IDで作成したことを表す。
+const (
+	ActionCreated  = "created"
+	ActionRenamed  = "renamed"
+	ActionUpdated  = "updated"
+	ActionConflict = "conflict"
+	ActionFailed   = "failed"
+)
+
+// Options は DD-PATCH-002 の取り込みの設定を表す。DryRun は保存せずに結果のみを求めることを表す。
+type Options struct {
+	DryRun bool
+}
+
+// IssueResult は DD-PATCH-002 のパッチの課題1件の取り込みの結果を表す。
+// IssueID はパッチ上の課題ID、LocalIssueID はこのプロジェクトの課題IDを表す。Comments は加えたコメントの数、
+// Attachments は書き込んだ添付ファイルの数、Conflicts は両側で変更されたためこのプロジェクトの値のまま残した項目名を表す。
+// Message は失敗した場合の理由を表す。
+type IssueResult struct {
+	Category     string
+	IssueID      string
+	LocalIssueID string
+	Action       string
+	Comments     int
+	Attachments  int
+	Conflicts    []string
+	Message      string
+}
+
+// ApplyResult は DD-PATCH-002 のパッチの取り込みの結果を表す。Issues には変更・競合・失敗のあった課題のみを含め、
+// Unchanged はそれ以外の課題の数を表す。ExportedAt・Since はパッチの出力時刻と基準時刻を表す。
+type ApplyResult struct {
+	DryRun     bool
+	ExportedAt string
+	Since      string
+	Created    int
+	Updated    int
+	Conflicted int
+	Failed     int
+	Unchanged  int
+	Issues     []IssueResult
+}
+
+// patchFields は DD-PATCH-002 の取り込みで比較し、パッチの値を採用する課題の項目を表す。
+// 同期と共通の issueops.MergeFields に加え、custom_fields はキーごとではなく全体を1つの項目として扱う。
+var patchFields = append(append([]issueops.MergeField{}, issueops.MergeFields...), issueops.MergeField{
+	Name: "custom_fields",
+	Get:  func(i issue.Issue) any { return i.CustomFields },
+	Set:  func(i *issue.Issue, v any) { i.CustomFields = v.(map[string]any) },
+})
+
+// applier は DD-PATCH-002 の取り込み中の状態を表す。
+type applier struct {
+	root       string
+	issues     *issueops.Service
+	categories *categoryops.Service
+	created    map[string]bool
+	ids        idMap
+	files      map[string][]byte
+	since      time.Time
+	mode       mod.Mode
+	opts       Options
+}
+
+// Apply は DD-PATCH-002 のパッチを検証してプロジェクトへ取り込む。
+// 目的: 共有フォルダを使えない相手から受け取った変更を、署名と内容を確かめてから手元の課題へ反映する。
+// 入力: ctx は中断通知、root はプロジェクトルート、srcPath はパッチのパス、passphrase は相手と共有する署名用のパスフレーズ、
+// validator は課題の検証器、currentMode は操作モード、opts は取り込みの設定。
+// 出力: ApplyResult とエラー。
+// エラー: パッチを読めない場合、署名が一致しない場合 (ErrCrypto)、manifest と内容が一致しない場合 (ErrValidation)、中断された場合に返す。
+// 課題ごとの検証・保存の失敗、終状態の課題の変更や操作モードで許されない状態の遷移 (DD-BE-003) は ApplyResult.Issues に含め、
+// 残りの課題の処理を続ける。
+// 副作用: 課題JSON・添付ファイルを作成・上書きし、カテゴリが無ければ作成する。課題IDを付け替えた場合は .ratta/patch_ids.json に対応を記録する。
+// dry-run ではいずれも行わない。
+// 並行性: プロジェクトへの同時書き込みは呼び出し側で排他する。
+// 不変条件: パッチ上の課題は課題IDと作成日時の組で識別する。同じ課題はコメントを和集合とし、項目はこのプロジェクトで基準時刻以降に
+// 変更されていなければパッチの値を採用し、変更されていて値が異なる場合は競合としてこのプロジェクトの値を残す。
+// 課題IDが同じで作成日時の異なる課題は別の課題として新たな課題IDで作成し、以後のパッチでも同じ課題へ取り込む。削除は伝播しない。
+// 関連DD: DD-PATCH-002, DD-PATCH-003, DD-BUNDLE-002, DD-REDMINE-002
+func Apply(ctx context.Context, root, srcPath, passphrase string, validator *schema.Validator, currentMode mod.Mode, opts Options) (ApplyResult, error) {
+	manifest, files, err := readPatch(srcPath, passphrase)
+	if err != nil {
+		return ApplyResult{}, err
+	}
+	var since time.Time
+	if manifest.Since != "" {
+		if since, err = time.Parse(time.RFC3339, manifest.Since); err != nil {
+			return ApplyResult{}, apperr.Errorf(apperr.ErrValidation, "invalid patch since: %v", err)
+		}
+	}
+	ids, err := loadIDMap(root)
+	if err != nil {
+		return ApplyResult{}, err
+	}
+	a := applier{
+		root:       root,
+		issues:     issueops.NewService(root, validator),
+		categories: categoryops.NewService(root),
+		created:    map[string]bool{},
+		ids:        ids,
+		files:      files,
+		since:      since,
+		mode:       currentMode,
+		opts:       opts,
+	}
+
+	result := ApplyResult{DryRun: opts.DryRun, ExportedAt: manifest.ExportedAt, Since: manifest.Since, Issues: []IssueResult{}}
+	for _, entry := range manifest.Issues {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ApplyResult{}, ctxErr
+		}
+		item := a.apply(entry)
+		switch item.Action {
+		case "":
+			result.Unchanged++
+			continue
+		case ActionCreated, ActionRenamed:
+			result.Created++
+		case ActionUpdated:
+			result.Updated++
+		case ActionConflict:
+			result.Conflicted++
+		default:
+			result.Failed++
+		}
+		result.Issues = append(result.Issues, item)
+	}
+	return result, nil
+}
+
+// apply は DD-PATCH-002 のパッチの課題1件を作成・統合 (dry-run では判定のみ) し、結果を返す。
+func (a *applier) apply(entry ManifestIssue) IssueResult {
+	result := IssueResult{Category: entry.Category, IssueID: entry.IssueID, LocalIssueID: entry.IssueID}
+	if errs := issue.ValidateCategoryName(entry.Category); len(errs) > 0 {
+		return failed(result, errs)
+	}
+	if !isSingleElement(entry.IssueID) {
+		return failed(result, apperr.Errorf(apperr.ErrValidation, "invalid issue id: %q", entry.IssueID))
+	}
+	item, err := a.issues.ParseImportedIssue(a.files[entry.file()])
+	if err != nil {
+		return failed(result, err)
+	}
+	if item.IssueID != entry.IssueID {
+		return failed(result, apperr.New(apperr.ErrValidation, "patch issue does not match manifest"))
+	}
+	item.Category = entry.Category
+	if err := a.ensureCategory(entry.Category); err != nil {
+		return failed(result, err)
+	}
+
+	if mapped, ok := a.ids.local(entry.Category, entry.IssueID, item.CreatedAt); ok {
+		result.LocalIssueID = mapped
+	}
+	if !a.issues.HasIssue(entry.Category, result.LocalIssueID) {
+		return a.create(entry.Dir, result, item, ActionCreated)
+	}
+	detail, err := a.issues.GetIssue(entry.Category, result.LocalIssueID)
+	if err != nil {
+		return failed(result, err)
+	}
+	if detail.IsSchemaInvalid {
+		return failed(result, apperr.New(apperr.ErrSchemaInvalid, "local issue schema invalid"))
+	}
+	if detail.Issue.CreatedAt != item.CreatedAt {
+		// 課題IDは同じでも作成日時が異なれば別々に起票された課題のため、上書きせずに新たな課題IDで作成する。
+		renamed, err := a.issues.ResolveImportIssueID(entry.Category, result.LocalIssueID)
+		if err != nil {
+			return failed(result, err)
+		}
+		result.LocalIssueID = renamed
+		return a.create(entry.Dir, result, item, ActionRenamed)
+	}
+	return a.merge(entry.Dir, result, item, detail.Issue)
+}
+
+// create は DD-PATCH-002 のパッチの dir 配下の課題を、添付ファイルとともに result.LocalIssueID の課題として作成する。
+// action が ActionRenamed の場合は課題IDの対応を記録する。
+func (a *applier) create(dir string, result IssueResult, item issue.Issue, action string) IssueResult {
+	result.Action = action
+	result.Comments = len(item.Comments)
+	target := renameIssue(item, result.LocalIssueID)
+	if a.opts.DryRun {
+		if a.created[item.Category] {
+			return result
+		}
+		if err := a.issues.CheckImportedIssue(item.Category, a.mode, target); err != nil {
+			return failed(result, err)
+		}
+		return result
+	}
+	attachments, err := a.writeAttachments(dir, item.IssueID, target, target.Comments)
+	result.Attachments = attachments
+	if err == nil {
+		_, err = a.issues.SaveImportedIssue(item.Category, a.mode, target)
+	}
+	if err != nil {
+		// 新たに作成した課題の添付ディレクトリのみを取り除き、既存の添付には触れない。
+		if removeErr := os.RemoveAll(filepath.Join(a.root, item.Category, result.LocalIssueID+".files")); removeErr != nil {
+			err = fmt.Errorf("%w; rollback error: %s", err, removeErr.Error())
+		}
+		return failed(result, err)
+	}
+	if action == ActionRenamed {
+		ids := a.ids.add(idMapping{Category: item.Category, IssueID: item.IssueID, CreatedAt: item.CreatedAt, LocalIssueID: result.LocalIssueID})
+		if err := ids.save(a.root); err != nil {
+			return failed(result, err)
+		}
+		a.ids = ids
+	}
+	return result
+}
+
+// merge は DD-PATCH-002 のパッチの dir 配下の課題を、同じ課題であるこのプロジェクトの課題 local へ統合する。
+// local が終状態の場合や、パッチの状態への遷移が操作モードで許されない場合は issueops.CheckMerge に従い失敗とし、保存しない。
+func (a *applier) merge(dir string, result IssueResult, item, local issue.Issue) IssueResult {
+	patch := renameIssue(item, result.LocalIssueID)
+	merged := local
+	fieldsChanged := false
+	patchChanged := atOrAfter(patch.UpdatedAt, a.since)
+	localChanged := atOrAfter(local.UpdatedAt, a.since)
+	for _, f := range patchFields {
+		if sameValue(f.Get(local), f.Get(patch)) {
+			continue
+		}
+		switch {
+		case !patchChanged:
+			// パッチ側は基準時刻以降に項目を変更していないため、このプロジェクトの値が新しい。
+		case localChanged:
+			result.Conflicts = append(result.Conflicts, f.Name)
+		default:
+			f.Set(&merged, f.Get(patch))
+			fieldsChanged = true
+		}
+	}
+
+	known := make(map[string]bool, len(local.Comments))
+	for _, comment := range local.Comments {
+		known[comment.CommentID] = true
+	}
+	var added []issue.Comment
+	for _, comment := range patch.Comments {
+		if !known[comment.CommentID] {
+			added = append(added, comment)
+		}
+	}
+	result.Comments = len(added)
+	if len(added) > 0 {
+		merged.Comments = append(append([]issue.Comment{}, local.Comments...), added...)
+		sort.SliceStable(merged.Comments, func(i, j int) bool { return isLater(merged.Comments[j].CreatedAt, merged.Comments[i].CreatedAt) })
+	}
+
+	switch {
+	case len(result.Conflicts) > 0:
+		result.Action = ActionConflict
+	case fieldsChanged || len(added) > 0:
+		result.Action = ActionUpdated
+	default:
+		return result
+	}
+	if !fieldsChanged && len(added) == 0 {
+		return result
+	}
+	if isLater(patch.UpdatedAt, merged.UpdatedAt) {
+		merged.UpdatedAt = patch.UpdatedAt
+	}
+	if patch.Version > merged.Version {
+		merged.Version = patch.Version
+	}
+	// SaveImportedIssue は状態の規則を適用しないため、課題の更新と同じ終状態と遷移の規則を保存 (dry-run では報告) の前に確かめる。
+	if err := issueops.CheckMerge(local, merged, a.mode); err != nil {
+		return failed(result, err)
+	}
+	if a.opts.DryRun {
+		if err := a.issues.CheckImportedIssue(local.Category, a.mode, merged); err != nil {
+			return failed(result, err)
+		}
+		return result
+	}
+	attachments, err := a.writeAttachments(dir, item.IssueID, merged, added)
+	result.Attachments = attachments
+	if err == nil {
+		_, err = a.issues.SaveImportedIssue(local.Category, a.mode, merged)
+	}
+	if err != nil {
+		return failed(result, err)
+	}
+	return result
+}
+
+// writeAttachments は DD-PATCH-002 のコメントが参照する添付ファイルのうち、プロジェクトに無くパッチにあるものを書き込み、書き込んだ数を返す。
+// dir はパッチ上の課題のディレクトリ、patchID はパッチ上の課題ID、target は保存する課題で、添付の relative_path は target の課題IDの添付ディレクトリを指す。
+func (a *applier) writeAttachments(dir, patchID string, target issue.Issue, comments []issue.Comment) (int, error) {
+	written := 0
+	prefix := target.IssueID + ".files/"
+	for _, comment := range comments {
+		for _, attachment := range comment.Attachments {
+			storedName := strings.TrimPrefix(attachment.RelativePath, prefix)
+			if storedName == attachment.RelativePath || !isSingleElement(storedName) {
+				return written, apperr.Errorf(apperr.ErrValidation, "invalid attachment path: %s", attachment.RelativePath)
+			}
+			destPath := filepath.Join(a.root, target.Category, target.IssueID+".files", storedName)
+			if _, err := os.Stat(destPath); err == nil {
+				continue
+			}
+			data, ok := a.files[dir+"/"+patchID+".files/"+storedName]
+			if !ok {
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(destPath), 0o750); err != nil {
+				return written, fmt.Errorf("create attachment dir: %w", err)
+			}
+			if err := atomicwrite.WriteFile(destPath, data); err != nil {
+				return written, fmt.Errorf("write attachment: %w", err)
+			}
+			written++
+		}
+	}
+	return written, nil
+}
+
+// ensureCategory は DD-PATCH-002 のカテゴリが無ければ作成する。dry-run では作成せず、作成するものとして扱う。
+// カテゴリの作成は GUI と同じく Contractor モードに限るため、Vendor モードでは無いカテゴリの課題は失敗として報告する。
+func (a *applier) ensureCategory(category string) error {
+	if a.created[category] {
+		return nil
+	}
+	info, err := os.Stat(filepath.Join(a.root, category))
+	if err == nil && info.IsDir() {
+		return nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("stat category: %w", err)
+	}
+	if a.opts.DryRun {
+		if a.mode != mod.ModeContractor {
+			return apperr.Errorf(apperr.ErrPermission, "create category %s: contractor mode is required", category)
+		}
+		a.created[category] = true
+		return nil
+	}
+	if _, err := a.categories.CreateCategory(category, a.mode); err != nil {
+		return fmt.Errorf("create category %s: %w", category, err)
+	}
+	return nil
+}
+
+// sameValue は DD-PATCH-002 の2つの項目の値が同じかを JSON 表現で比べる。空のタグ・custom_fields は無い場合と同じとみなす。
+func sameValue(a, b any) bool {
+	left, leftErr := json.Marshal(a)
+	right, rightErr := json.Marshal(b)
+	if leftErr != nil || rightErr != nil {
+		return false
+	}
+	return bytes.Equal(normalizeEmpty(left), normalizeEmpty(right))
+}
+
+// normalizeEmpty は DD-PATCH-002 の比較のため、空の配列・オブジェクトを null とそろえる。
+func normalizeEmpty(data []byte) []byte {
+	if string(data) == "[]" || string(data) == "{}" {
+		return []byte("null")
+	}
+	return data
+}
+
+// isLater は DD-PATCH-002 の日時 a が b より後かを返す。解析できない場合は文字列として比べる。
+func isLater(a, b string) bool {
+	at, aErr := time.Parse(time.RFC3339, a)
+	bt, bErr := time.Parse(time.RFC3339, b)
+	if aErr != nil || bErr != nil {
+		return a > b
+	}
+	return at.After(bt)
+}
+
+// failed は DD-PATCH-002 の課題の取り込みの失敗を結果へ記録する。
+func failed(result IssueResult, err error) IssueResult {
+	result.Action = ActionFailed
+	result.Message = err.Error()
+	return result
+}
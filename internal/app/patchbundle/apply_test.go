@@ -0,0 +1,203 @@
+// apply_test.go はパッチの取り込みによる課題の作成・統合・競合の報告と、課題IDの衝突の扱いのテストを行う。
+package patchbundle
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/issue"
+	"ratta/internal/domain/timeutil"
+	"ratta/internal/infra/schema"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// apply はテスト用にパッチを取り込む。
+func apply(t *testing.T, validator *schema.Validator, root, path string, dryRun bool) ApplyResult {
+	t.Helper()
+	result, err := Apply(context.Background(), root, path, "shared", validator, mod.ModeVendor, Options{DryRun: dryRun})
+	if err != nil {
+		t.Fatalf("Apply error: %v", err)
+	}
+	return result
+}
+
+// baseline はテスト用に前回のパッチの出力時刻を次回の基準時刻へ変換する。
+func baseline(t *testing.T, exportedAt string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, exportedAt)
+	if err != nil {
+		t.Fatalf("parse exported_at: %v", err)
+	}
+	return parsed
+}
+
+func TestApply_CreatesMergesAndReportsConflicts(t *testing.T) {
+	// 相手に無い課題は添付とともに作成し、以後のパッチはコメントを加えて項目を更新し、両側で変えた項目は競合として残すことを確認する。
+	validator, sender := newProject(t)
+	_, receiver := newProject(t)
+	senderService := issueops.NewService(sender, validator)
+	receiverService := issueops.NewService(receiver, validator)
+	issueID := createIssue(t, senderService, "exchange", "log.txt")
+	// 日時は秒単位で記録するため、出力と同じ秒の変更が次回も含まれないよう作成済みの課題を古い日時へ戻す。
+	age(t, senderService, issueID)
+
+	first, dest := export(t, sender, time.Time{})
+	if dry := apply(t, validator, receiver, dest, true); !dry.DryRun || dry.Created != 1 {
+		t.Fatalf("unexpected dry run: %+v", dry)
+	}
+	if receiverService.HasIssue("cat", issueID) {
+		t.Fatal("expected dry run not to write")
+	}
+	created := apply(t, validator, receiver, dest, false)
+	if created.Created != 1 || created.Issues[0].Action != ActionCreated || created.Issues[0].Attachments != 1 {
+		t.Fatalf("unexpected apply: %+v", created)
+	}
+	detail, err := receiverService.GetIssue("cat", issueID)
+	if err != nil || detail.IsSchemaInvalid {
+		t.Fatalf("unexpected created issue: %+v %v", detail, err)
+	}
+	data, err := os.ReadFile(filepath.Join(receiver, "cat", filepath.FromSlash(detail.Issue.Comments[0].Attachments[0].RelativePath)))
+	if err != nil || string(data) != "log.txt" {
+		t.Fatalf("expected attachment to be written: %q %v", data, err)
+	}
+	if again := apply(t, validator, receiver, dest, false); again.Unchanged != 1 || len(again.Issues) != 0 {
+		t.Fatalf("expected reapplying to change nothing: %+v", again)
+	}
+
+	editIssue(t, senderService, issueID, func(item *issue.Issue) {
+		item.Title = "renamed by sender"
+		item.UpdatedAt = timeutil.NowISO8601()
+	})
+	if _, err := senderService.AddComment("cat", issueID, mod.ModeVendor, issueops.CommentCreateInput{Body: "reply", AuthorName: "sato"}); err != nil {
+		t.Fatalf("AddComment error: %v", err)
+	}
+	second, dest := export(t, sender, baseline(t, first.ExportedAt))
+	if second.Comments != 1 {
+		t.Fatalf("expected only the new comment: %+v", second)
+	}
+	updated := apply(t, validator, receiver, dest, false)
+	if updated.Updated != 1 || updated.Issues[0].Comments != 1 {
+		t.Fatalf("unexpected update: %+v", updated)
+	}
+	detail, _ = receiverService.GetIssue("cat", issueID)
+	if detail.Issue.Title != "renamed by sender" || len(detail.Issue.Comments) != 2 {
+		t.Fatalf("unexpected merged issue: %+v", detail.Issue)
+	}
+
+	editIssue(t, senderService, issueID, func(item *issue.Issue) {
+		item.Priority = issue.PriorityHigh
+		item.UpdatedAt = timeutil.NowISO8601()
+	})
+	editIssue(t, receiverService, issueID, func(item *issue.Issue) {
+		item.Priority = issue.PriorityLow
+		item.UpdatedAt = timeutil.NowISO8601()
+	})
+	_, dest = export(t, sender, baseline(t, second.ExportedAt))
+	conflict := apply(t, validator, receiver, dest, false)
+	if conflict.Conflicted != 1 || len(conflict.Issues[0].Conflicts) != 1 || conflict.Issues[0].Conflicts[0] != "priority" {
+		t.Fatalf("unexpected conflict: %+v", conflict)
+	}
+	if detail, _ = receiverService.GetIssue("cat", issueID); detail.Issue.Priority != issue.PriorityLow {
+		t.Fatalf("expected receiver to keep its value, got %s", detail.Issue.Priority)
+	}
+}
+
+func TestApply_AppliesStatusRulesAsVendor(t *testing.T) {
+	// パッチの値は SaveImportedIssue で保存するため、Vendor モードの取り込みでは相手が Closed にした課題を Closed にせず、
+	// 受け取った側で閉じた課題へはコメントも加えずに失敗として報告することを確認する (dry-run でも同じく報告する)。
+	validator, sender := newProject(t)
+	_, receiver := newProject(t)
+	senderService := issueops.NewService(sender, validator)
+	receiverService := issueops.NewService(receiver, validator)
+	closedBySender := createIssue(t, senderService, "closed by sender", "a.txt")
+	closedByReceiver := createIssue(t, senderService, "closed by receiver", "b.txt")
+	age(t, senderService, closedBySender)
+	age(t, senderService, closedByReceiver)
+	first, dest := export(t, sender, time.Time{})
+	if created := apply(t, validator, receiver, dest, false); created.Created != 2 {
+		t.Fatalf("unexpected apply: %+v", created)
+	}
+
+	editIssue(t, senderService, closedBySender, func(item *issue.Issue) {
+		item.Status = issue.StatusClosed
+		item.UpdatedAt = timeutil.NowISO8601()
+	})
+	if _, err := senderService.AddComment("cat", closedByReceiver, mod.ModeVendor, issueops.CommentCreateInput{Body: "reply", AuthorName: "sato"}); err != nil {
+		t.Fatalf("AddComment error: %v", err)
+	}
+	editIssue(t, receiverService, closedByReceiver, func(item *issue.Issue) { item.Status = issue.StatusClosed })
+	_, dest = export(t, sender, baseline(t, first.ExportedAt))
+
+	for _, dryRun := range []bool{true, false} {
+		result := apply(t, validator, receiver, dest, dryRun)
+		if result.Failed != 2 || result.Updated != 0 {
+			t.Fatalf("expected status rule failures (dry run %v): %+v", dryRun, result)
+		}
+	}
+	if detail, _ := receiverService.GetIssue("cat", closedBySender); detail.Issue.Status != issue.StatusOpen {
+		t.Fatalf("expected vendor not to close the issue, got %s", detail.Issue.Status)
+	}
+	if detail, _ := receiverService.GetIssue("cat", closedByReceiver); len(detail.Issue.Comments) != 1 {
+		t.Fatalf("expected no comment on the closed issue, got %d", len(detail.Issue.Comments))
+	}
+}
+
+func TestApply_RenamesCollidingIssueAndKeepsMapping(t *testing.T) {
+	// 課題IDが同じ別の課題は新たな課題IDで作成し、以後のパッチは同じ課題へ取り込み、送り返す際は元の課題IDで出力することを確認する。
+	validator, sender := newProject(t)
+	_, receiver := newProject(t)
+	senderService := issueops.NewService(sender, validator)
+	receiverService := issueops.NewService(receiver, validator)
+	issueID := createIssue(t, senderService, "from sender", "log.txt")
+	age(t, senderService, issueID)
+	own := createIssue(t, receiverService, "receiver's own", "own.txt")
+	for _, suffix := range []string{".json", ".files"} {
+		if err := os.Rename(filepath.Join(receiver, "cat", own+suffix), filepath.Join(receiver, "cat", issueID+suffix)); err != nil {
+			t.Fatalf("rename: %v", err)
+		}
+	}
+	editIssue(t, receiverService, issueID, func(item *issue.Issue) {
+		item.IssueID = own
+		*item = renameIssue(*item, issueID)
+	})
+
+	first, dest := export(t, sender, time.Time{})
+	renamed := apply(t, validator, receiver, dest, false)
+	if renamed.Created != 1 || renamed.Issues[0].Action != ActionRenamed || renamed.Issues[0].LocalIssueID == issueID {
+		t.Fatalf("unexpected rename: %+v", renamed)
+	}
+	localID := renamed.Issues[0].LocalIssueID
+	detail, err := receiverService.GetIssue("cat", localID)
+	if err != nil || detail.Issue.Title != "from sender" || detail.IsSchemaInvalid {
+		t.Fatalf("unexpected renamed issue: %+v %v", detail, err)
+	}
+	if _, err := os.Stat(filepath.Join(receiver, "cat", filepath.FromSlash(detail.Issue.Comments[0].Attachments[0].RelativePath))); err != nil {
+		t.Fatalf("expected attachment under the new issue id: %v", err)
+	}
+	if ownDetail, _ := receiverService.GetIssue("cat", issueID); ownDetail.Issue.Title != "receiver's own" {
+		t.Fatalf("expected receiver's issue to be kept: %+v", ownDetail.Issue)
+	}
+
+	if _, err := senderService.AddComment("cat", issueID, mod.ModeVendor, issueops.CommentCreateInput{Body: "follow up", AuthorName: "sato"}); err != nil {
+		t.Fatalf("AddComment error: %v", err)
+	}
+	_, dest = export(t, sender, baseline(t, first.ExportedAt))
+	followUp := apply(t, validator, receiver, dest, false)
+	if followUp.Updated != 1 || followUp.Issues[0].LocalIssueID != localID {
+		t.Fatalf("expected follow-up to reach the renamed issue: %+v", followUp)
+	}
+
+	_, back := export(t, receiver, time.Time{})
+	returned := apply(t, validator, sender, back, false)
+	if returned.Failed != 0 || returned.Created != 1 || returned.Issues[0].Action != ActionRenamed {
+		t.Fatalf("unexpected result on the sender: %+v", returned)
+	}
+	if names, _ := filepath.Glob(filepath.Join(sender, "cat", "*.json")); len(names) != 2 {
+		t.Fatalf("expected the sender to hold its issue and the receiver's issue, got %v", names)
+	}
+}
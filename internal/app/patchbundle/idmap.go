@@ -0,0 +1,102 @@
+// idmap.go はパッチの取り込みで課題IDを付け替えた課題の対応を、プロジェクトの .ratta 配下へ記録する処理を担い、付け替えの判断は扱わない。
+package patchbundle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ratta/internal/infra/atomicwrite"
+	"ratta/internal/infra/jsonfmt"
+	"ratta/internal/infra/projectmeta"
+)
+
+// idMapName は DD-PATCH-003 の課題IDの対応を記録する .ratta 配下のファイル名を表す。
+const idMapName = "patch_ids.json"
+
+// idMapping は DD-PATCH-003 の付け替えた課題1件の対応を表す。
+// パッチ上の課題は課題IDと作成日時の組で識別し、LocalIssueID はこのプロジェクトで採番した課題IDを表す。
+type idMapping struct {
+	Category     string `json:"category"`
+	IssueID      string `json:"issue_id"`
+	CreatedAt    string `json:"created_at"`
+	LocalIssueID string `json:"local_issue_id"`
+}
+
+// idMap は DD-PATCH-003 のプロジェクトの課題IDの対応を表す。
+type idMap struct {
+	Mappings []idMapping `json:"mappings"`
+}
+
+// idMapPath は DD-PATCH-003 の課題IDの対応を記録するファイルのパスを返す。
+func idMapPath(root string) string {
+	return filepath.Join(projectmeta.Dir(root), idMapName)
+}
+
+// loadIDMap は DD-PATCH-003 の課題IDの対応を読み込む。ファイルが無い場合は空の対応を返す。
+func loadIDMap(root string) (idMap, error) {
+	// #nosec G304 -- プロジェクトの .ratta 配下の固定名のファイルのみを読む。
+	data, err := os.ReadFile(idMapPath(root))
+	if errors.Is(err, os.ErrNotExist) {
+		return idMap{Mappings: []idMapping{}}, nil
+	}
+	if err != nil {
+		return idMap{}, fmt.Errorf("read patch id map: %w", err)
+	}
+	var ids idMap
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return idMap{}, fmt.Errorf("parse patch id map: %w", err)
+	}
+	if ids.Mappings == nil {
+		ids.Mappings = []idMapping{}
+	}
+	return ids, nil
+}
+
+// save は DD-PATCH-003 の課題IDの対応を書き込む。
+func (m idMap) save(root string) error {
+	data, err := jsonfmt.MarshalCanonical(m)
+	if err != nil {
+		return fmt.Errorf("marshal patch id map: %w", err)
+	}
+	if err := os.MkdirAll(projectmeta.Dir(root), 0o750); err != nil {
+		return fmt.Errorf("create project meta dir: %w", err)
+	}
+	if err := atomicwrite.WriteFile(idMapPath(root), data); err != nil {
+		return fmt.Errorf("write patch id map: %w", err)
+	}
+	return nil
+}
+
+// local は DD-PATCH-003 のパッチ上の課題に対応する、このプロジェクトの課題IDを返す。
+func (m idMap) local(category, issueID, createdAt string) (string, bool) {
+	for _, mapping := range m.Mappings {
+		if mapping.Category == category && mapping.IssueID == issueID && mapping.CreatedAt == createdAt {
+			return mapping.LocalIssueID, true
+		}
+	}
+	return "", false
+}
+
+// original は DD-PATCH-003 のこのプロジェクトの課題に対応する、パッチ上の課題IDを返す。
+func (m idMap) original(category, localIssueID, createdAt string) (string, bool) {
+	for _, mapping := range m.Mappings {
+		if mapping.Category == category && mapping.LocalIssueID == localIssueID && mapping.CreatedAt == createdAt {
+			return mapping.IssueID, true
+		}
+	}
+	return "", false
+}
+
+// add は DD-PATCH-003 の付け替えた課題の対応を加えた対応を返す。同じパッチ上の課題の対応が既にあれば置き換える。
+func (m idMap) add(mapping idMapping) idMap {
+	mappings := make([]idMapping, 0, len(m.Mappings)+1)
+	for _, existing := range m.Mappings {
+		if existing.Category != mapping.Category || existing.IssueID != mapping.IssueID || existing.CreatedAt != mapping.CreatedAt {
+			mappings = append(mappings, existing)
+		}
+	}
+	return idMap{Mappings: append(mappings, mapping)}
+}
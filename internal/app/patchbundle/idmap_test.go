@@ -0,0 +1,31 @@
+// idmap_test.go は課題IDの対応の記録と参照のテストを行う。
+package patchbundle
+
+import "testing"
+
+func TestIDMap_SaveLoadAndReplace(t *testing.T) {
+	// 記録した対応を両方向に引け、同じパッチ上の課題の対応は置き換えることを確認する。
+	root := t.TempDir()
+	empty, err := loadIDMap(root)
+	if err != nil || len(empty.Mappings) != 0 {
+		t.Fatalf("expected empty map, got %+v %v", empty, err)
+	}
+	ids := empty.add(idMapping{Category: "cat", IssueID: "a", CreatedAt: oldTime, LocalIssueID: "b"})
+	ids = ids.add(idMapping{Category: "cat", IssueID: "a", CreatedAt: oldTime, LocalIssueID: "c"})
+	if err := ids.save(root); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+	loaded, err := loadIDMap(root)
+	if err != nil || len(loaded.Mappings) != 1 {
+		t.Fatalf("unexpected loaded map: %+v %v", loaded, err)
+	}
+	if local, ok := loaded.local("cat", "a", oldTime); !ok || local != "c" {
+		t.Fatalf("unexpected local id: %q %v", local, ok)
+	}
+	if original, ok := loaded.original("cat", "c", oldTime); !ok || original != "a" {
+		t.Fatalf("unexpected original id: %q %v", original, ok)
+	}
+	if _, ok := loaded.local("cat", "a", "2025-01-01T00:00:00Z"); ok {
+		t.Fatal("expected a different created_at not to match")
+	}
+}
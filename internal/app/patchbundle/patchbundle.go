@@ -0,0 +1,371 @@
+// Package patchbundle は基準時刻以降に変わった課題・コメントだけを署名付きの zip (パッチ) にまとめる出力と、その取り込みを担い、
+// 受け渡しの手段や出力先の選択は扱わない。共有フォルダを使えない環境で、Vendor と Contractor がパッチをやり取りして課題をそろえる。
+// パッチは manifest.json・signature.json と、課題ごとのディレクトリ「issues/<連番>/」配下の課題JSONと添付で構成する。
+// 課題ごとにディレクトリを分けるため、課題IDが同じ別々の課題も1つのパッチに含められる。
+package patchbundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ratta/internal/app/issueexport"
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+	"ratta/internal/domain/timeutil"
+	"ratta/internal/infra/atomicwrite"
+	"ratta/internal/infra/crypto"
+	"ratta/internal/infra/jsonfmt"
+	"ratta/internal/infra/safezip"
+)
+
+const (
+	// formatVersion は DD-PATCH-001 の manifest 形式バージョンを表す。
+	formatVersion = 1
+	// patchKind は DD-PATCH-001 のパッチ識別子で、課題バンドルなど他形式の zip との取り違えを防ぐ。
+	patchKind = "ratta-patch"
+	// manifestName は DD-PATCH-001 の manifest エントリ名を表す。
+	manifestName = "manifest.json"
+	// signatureName は DD-PATCH-001 の manifest の署名のエントリ名を表す。
+	signatureName = "signature.json"
+)
+
+// patchZipLimits は DD-PATCH-002 の取り込むパッチの展開後の大きさの上限を表す。
+// エントリ1件は DD-DATA-005 の添付1件の上限 (20 MiB、課題JSON・manifest もこれに収まる)、合計は複数の課題を含むため 1 GiB とする。
+var patchZipLimits = safezip.Limits{MaxEntryBytes: 20 << 20, MaxTotalBytes: 1 << 30}
+
+// patchNow は DD-PATCH-001 の出力時刻をテストで固定するための差し替え点。
+var patchNow = time.Now
+
+// Manifest は DD-PATCH-001 のパッチの構成情報を表す。
+// Since は基準時刻で、全件を出力した場合は空とする。ExportedAt は次回のパッチの基準時刻に用いる。
+// Files には manifest・署名以外の全エントリを含め、署名は manifest のバイト列に対して行うため、添付を含む全体の改ざんを検出できる。
+type Manifest struct {
+	FormatVersion int                   `json:"format_version"`
+	Kind          string                `json:"kind"`
+	ExportedAt    string                `json:"exported_at"`
+	Since         string                `json:"since"`
+	Issues        []ManifestIssue       `json:"issues"`
+	Files         []issueops.BundleFile `json:"files"`
+}
+
+// ManifestIssue は DD-PATCH-001 のパッチに含める課題1件を表す。
+// Dir は課題のエントリを置くディレクトリで、課題JSONは「Dir/課題ID.json」、添付は「Dir/relative_path」に置く。
+// Comments はパッチに含めたコメントの数を表す。
+type ManifestIssue struct {
+	Category string `json:"category"`
+	IssueID  string `json:"issue_id"`
+	Dir      string `json:"dir"`
+	Comments int    `json:"comments"`
+}
+
+// file は DD-PATCH-001 の課題JSONのエントリ名を返す。
+func (m ManifestIssue) file() string {
+	return m.Dir + "/" + m.IssueID + ".json"
+}
+
+// ExportResult は DD-PATCH-001 のパッチの出力結果を表す。
+type ExportResult struct {
+	Path        string
+	ExportedAt  string
+	Issues      int
+	Comments    int
+	Attachments int
+}
+
+// entry は DD-PATCH-001 の zip へ書き込む1ファイル分の内容を表す。
+type entry struct {
+	name string
+	data []byte
+}
+
+// Export は DD-PATCH-001 の基準時刻以降に変わった課題・コメントをパッチとして出力する。
+// 目的: 共有フォルダを使えない環境で、前回の受け渡し以降の変更だけを小さなファイルにまとめて相手へ渡せるようにする。
+// 入力: ctx は中断通知、root はプロジェクトルート、since は基準時刻 (ゼロ値の場合は全件)、passphrase は相手と共有する署名用のパスフレーズ、
+// destPath は出力先 zip のパス。
+// 出力: ExportResult とエラー。
+// エラー: 出力先・パスフレーズが未指定の場合は ErrValidation、課題の走査・添付の読み取り・書き込みに失敗した場合、中断された場合に返す。
+// 副作用: destPath に zip を atomic write で作成する。変更された課題が無い場合も課題を含まないパッチを作成する。プロジェクトルート配下は変更しない。
+// 並行性: 読み取りのみで、出力中に課題が更新されることは想定しない。
+// 不変条件: 課題は updated_at が基準時刻以降の場合、または基準時刻以降に作成されたコメントを持つ場合に含め、課題JSONのコメントは
+// 基準時刻以降に作成されたものに限る。添付は含めたコメントが参照するもののみとし、存在しない添付は含めない。
+// パッチの取り込みで課題IDを付け替えた課題は、相手が同じ課題と判断できるよう取り込み元の課題IDで出力する。
+// 関連DD: DD-PATCH-001, DD-PATCH-003, DD-EXPORT-001
+func Export(ctx context.Context, root string, since time.Time, passphrase, destPath string) (ExportResult, error) {
+	if destPath == "" {
+		return ExportResult{}, apperr.New(apperr.ErrValidation, "destination path is required")
+	}
+	if passphrase == "" {
+		return ExportResult{}, apperr.New(apperr.ErrValidation, "passphrase is required")
+	}
+	items, _, err := issueexport.Collect(ctx, root, issueexport.Filter{})
+	if err != nil {
+		return ExportResult{}, err
+	}
+	ids, err := loadIDMap(root)
+	if err != nil {
+		return ExportResult{}, err
+	}
+
+	exportedAt := timeutil.FormatISO8601(patchNow())
+	manifest := Manifest{
+		FormatVersion: formatVersion,
+		Kind:          patchKind,
+		ExportedAt:    exportedAt,
+		Issues:        []ManifestIssue{},
+		Files:         []issueops.BundleFile{},
+	}
+	if !since.IsZero() {
+		manifest.Since = timeutil.FormatISO8601(since)
+	}
+	result := ExportResult{Path: destPath, ExportedAt: exportedAt}
+	var entries []entry
+	for _, item := range items {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ExportResult{}, ctxErr
+		}
+		comments := make([]issue.Comment, 0, len(item.Comments))
+		for _, comment := range item.Comments {
+			if atOrAfter(comment.CreatedAt, since) {
+				comments = append(comments, comment)
+			}
+		}
+		if len(comments) == 0 && !atOrAfter(item.UpdatedAt, since) {
+			continue
+		}
+		localID := item.IssueID
+		item.Comments = comments
+		if original, ok := ids.original(item.Category, localID, item.CreatedAt); ok {
+			item = renameIssue(item, original)
+		}
+		listed := ManifestIssue{Category: item.Category, IssueID: item.IssueID, Dir: fmt.Sprintf("issues/%d", len(manifest.Issues)+1), Comments: len(comments)}
+		issueEntries, attachments, err := issueEntries(root, listed.Dir, item, localID)
+		if err != nil {
+			return ExportResult{}, err
+		}
+		entries = append(entries, issueEntries...)
+		manifest.Issues = append(manifest.Issues, listed)
+		result.Issues++
+		result.Comments += len(comments)
+		result.Attachments += attachments
+	}
+	for _, e := range entries {
+		sum := sha256.Sum256(e.data)
+		manifest.Files = append(manifest.Files, issueops.BundleFile{Path: e.name, SizeBytes: int64(len(e.data)), SHA256: hex.EncodeToString(sum[:])})
+	}
+
+	manifestData, err := jsonfmt.MarshalCanonical(manifest)
+	if err != nil {
+		return ExportResult{}, fmt.Errorf("marshal manifest: %w", err)
+	}
+	signature, err := crypto.Sign(passphrase, manifestData)
+	if err != nil {
+		return ExportResult{}, fmt.Errorf("sign patch: %w", err)
+	}
+	signatureData, err := jsonfmt.MarshalCanonical(signature)
+	if err != nil {
+		return ExportResult{}, fmt.Errorf("marshal signature: %w", err)
+	}
+	archive, err := buildZip(append([]entry{{name: manifestName, data: manifestData}, {name: signatureName, data: signatureData}}, entries...))
+	if err != nil {
+		return ExportResult{}, err
+	}
+	// 書き込み後の中断は出力済みの zip と矛盾するため、中断の受け付けは書き込み前までとする。
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ExportResult{}, ctxErr
+	}
+	if err := atomicwrite.WriteFile(destPath, archive); err != nil {
+		return ExportResult{}, fmt.Errorf("write patch: %w", err)
+	}
+	return result, nil
+}
+
+// ParseSince は DD-PATCH-001 の基準時刻の指定を解釈する。RFC 3339 の日時か、表示用のタイムゾーンでの日付 (YYYY-MM-DD) の
+// 0時を受け付ける。空の場合はゼロ値 (全件) を返し、解釈できない場合は ErrValidation を返す。
+func ParseSince(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	parsed, err := time.ParseInLocation(time.DateOnly, value, timeutil.DisplayLocation())
+	if err != nil {
+		return time.Time{}, apperr.Errorf(apperr.ErrValidation, "invalid since %q: use RFC 3339 or YYYY-MM-DD", value)
+	}
+	return parsed, nil
+}
+
+// issueEntries は DD-PATCH-001 の dir 配下に置く課題JSONと、コメントが参照する添付のエントリを返す。
+// item の課題IDと添付の relative_path は出力する値、localID はプロジェクト内の課題IDを表し、添付は localID の添付ディレクトリから読む。
+// 返す添付の数は読み込んだ添付ファイルの数を表す。
+func issueEntries(root, dir string, item issue.Issue, localID string) ([]entry, int, error) {
+	data, err := jsonfmt.MarshalIssue(item)
+	if err != nil {
+		return nil, 0, fmt.Errorf("marshal issue: %w", err)
+	}
+	entries := []entry{{name: dir + "/" + item.IssueID + ".json", data: data}}
+	prefix := item.IssueID + ".files/"
+	for _, comment := range item.Comments {
+		for _, attachment := range comment.Attachments {
+			storedName := strings.TrimPrefix(attachment.RelativePath, prefix)
+			if storedName == attachment.RelativePath || !isSingleElement(storedName) {
+				return nil, 0, apperr.Errorf(apperr.ErrValidation, "invalid attachment path: %s", attachment.RelativePath)
+			}
+			// #nosec G304 -- カテゴリ配下の、検証済みの1階層の添付名のみを読む。
+			attachmentData, readErr := os.ReadFile(filepath.Join(root, item.Category, localID+".files", storedName))
+			if errors.Is(readErr, os.ErrNotExist) {
+				continue
+			}
+			if readErr != nil {
+				return nil, 0, fmt.Errorf("read attachment: %w", readErr)
+			}
+			entries = append(entries, entry{name: dir + "/" + attachment.RelativePath, data: attachmentData})
+		}
+	}
+	return entries, len(entries) - 1, nil
+}
+
+// renameIssue は DD-PATCH-003 の課題IDを付け替え、添付の relative_path も新しい課題IDの添付ディレクトリへ追従させた課題を返す。
+func renameIssue(item issue.Issue, issueID string) issue.Issue {
+	oldPrefix, newPrefix := item.IssueID+".files/", issueID+".files/"
+	item.IssueID = issueID
+	comments := make([]issue.Comment, len(item.Comments))
+	for i, comment := range item.Comments {
+		attachments := make([]issue.AttachmentRef, len(comment.Attachments))
+		for j, attachment := range comment.Attachments {
+			if strings.HasPrefix(attachment.RelativePath, oldPrefix) {
+				attachment.RelativePath = newPrefix + strings.TrimPrefix(attachment.RelativePath, oldPrefix)
+			}
+			attachments[j] = attachment
+		}
+		comment.Attachments = attachments
+		comments[i] = comment
+	}
+	item.Comments = comments
+	return item
+}
+
+// atOrAfter は DD-PATCH-001 の日時 value が基準時刻 since 以降かを返す。since がゼロ値の場合と value を解析できない場合は true とする。
+func atOrAfter(value string, since time.Time) bool {
+	if since.IsZero() {
+		return true
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return true
+	}
+	return !parsed.Before(since)
+}
+
+// isSingleElement は DD-PATCH-002 の名前がディレクトリ区切りや親参照を含まない1階層のパス要素かを返す。
+func isSingleElement(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// buildZip は DD-PATCH-001 のエントリを与えられた順序で deflate 圧縮した zip バイト列にする。
+func buildZip(entries []entry) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	modified := patchNow()
+	for _, e := range entries {
+		fileWriter, err := writer.CreateHeader(&zip.FileHeader{Name: e.name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return nil, fmt.Errorf("create zip entry: %w", err)
+		}
+		if _, err := fileWriter.Write(e.data); err != nil {
+			return nil, fmt.Errorf("write zip entry: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("close zip: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// readPatch は DD-PATCH-002 のパッチを読み込み、署名と manifest の照合を行う。
+// 目的: パスフレーズを知る相手が作成し、改ざん・欠落の無いパッチだけを取り込めるようにする。
+// 入力: srcPath はパッチのパス、passphrase は署名用のパスフレーズ。
+// 出力: manifest、zip 内パスをキーとする内容 (manifest・署名を除く)、エラー。
+// エラー: zip の読み取り失敗、危険なエントリ名、manifest・署名の不在や不正、形式の不一致の場合に返す。
+// 署名が一致しない場合は ErrCrypto、manifest に無いエントリやサイズ・ハッシュの不一致は ErrValidation とする。
+// 副作用: パッチを読み取る。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: 署名を検証してから manifest を解釈する。
+// 関連DD: DD-PATCH-002, DD-BUNDLE-002
+func readPatch(srcPath, passphrase string) (Manifest, map[string][]byte, error) {
+	if srcPath == "" {
+		return Manifest{}, nil, apperr.New(apperr.ErrValidation, "patch path is required")
+	}
+	if passphrase == "" {
+		return Manifest{}, nil, apperr.New(apperr.ErrValidation, "passphrase is required")
+	}
+	reader, err := zip.OpenReader(srcPath)
+	if err != nil {
+		return Manifest{}, nil, apperr.WithPath(fmt.Errorf("open patch: %w", err), srcPath, apperr.IOHint(err, ""))
+	}
+	files, readErr := safezip.ReadFiles(reader.File, patchZipLimits)
+	if closeErr := reader.Close(); closeErr != nil && readErr == nil {
+		readErr = fmt.Errorf("close patch: %w", closeErr)
+	}
+	if readErr != nil {
+		return Manifest{}, nil, readErr
+	}
+
+	manifestData, hasManifest := files[manifestName]
+	signatureData, hasSignature := files[signatureName]
+	if !hasManifest || !hasSignature {
+		return Manifest{}, nil, apperr.New(apperr.ErrValidation, "patch manifest or signature not found")
+	}
+	delete(files, manifestName)
+	delete(files, signatureName)
+	var signature crypto.Signature
+	if err := json.Unmarshal(signatureData, &signature); err != nil {
+		return Manifest{}, nil, apperr.Errorf(apperr.ErrValidation, "parse patch signature: %v", err)
+	}
+	if err := crypto.Verify(signature, passphrase, manifestData); err != nil {
+		return Manifest{}, nil, apperr.Errorf(apperr.ErrCrypto, "patch signature does not match (wrong passphrase or modified patch): %w", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return Manifest{}, nil, apperr.Errorf(apperr.ErrValidation, "parse patch manifest: %v", err)
+	}
+	if manifest.Kind != patchKind || manifest.FormatVersion != formatVersion {
+		return Manifest{}, nil, apperr.New(apperr.ErrValidation, "unsupported patch format")
+	}
+
+	listed := make(map[string]bool, len(manifest.Files))
+	for _, file := range manifest.Files {
+		data, ok := files[file.Path]
+		if !ok {
+			return Manifest{}, nil, apperr.Errorf(apperr.ErrValidation, "patch entry missing: %s", file.Path)
+		}
+		sum := sha256.Sum256(data)
+		if int64(len(data)) != file.SizeBytes || hex.EncodeToString(sum[:]) != file.SHA256 {
+			return Manifest{}, nil, apperr.Errorf(apperr.ErrValidation, "patch entry checksum mismatch: %s", file.Path)
+		}
+		listed[file.Path] = true
+	}
+	// manifest に無いエントリは署名の対象外で出所が不明なため、黙って捨てずに取り込み自体を拒否する。
+	for name := range files {
+		if !listed[name] {
+			return Manifest{}, nil, apperr.Errorf(apperr.ErrValidation, "patch entry not listed in manifest: %s", name)
+		}
+	}
+	for _, item := range manifest.Issues {
+		if !listed[item.file()] {
+			return Manifest{}, nil, apperr.Errorf(apperr.ErrValidation, "patch issue file not listed: %s", item.file())
+		}
+	}
+	return manifest, files, nil
+}
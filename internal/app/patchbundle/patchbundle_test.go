@@ -0,0 +1,214 @@
+// patchbundle_test.go はパッチの出力対象の選別と、署名・内容の照合のテストを行う。
+package patchbundle
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/schema"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// oldTime はテスト用の基準時刻より前の日時を表す。
+const oldTime = "2024-01-01T09:00:00+09:00"
+
+// newProject はテスト用に検証器と、カテゴリ "cat" を持つプロジェクトを用意する。
+func newProject(t *testing.T) (*schema.Validator, string) {
+	t.Helper()
+	validator, err := schema.NewValidatorFromDir(filepath.Join("..", "..", "..", "schemas"))
+	if err != nil {
+		t.Fatalf("NewValidatorFromDir error: %v", err)
+	}
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "cat"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	return validator, root
+}
+
+// createIssue はテスト用に添付付きのコメントを1件持つ課題を作成し、課題IDを返す。
+func createIssue(t *testing.T, service *issueops.Service, title, attachment string) string {
+	t.Helper()
+	created, err := service.CreateIssue("cat", mod.ModeVendor, issueops.IssueCreateInput{
+		Title: title, Description: "desc", DueDate: "2024-02-01", Priority: issue.PriorityMedium,
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	if _, err := service.AddComment("cat", created.Issue.IssueID, mod.ModeVendor, issueops.CommentCreateInput{
+		Body: "first", AuthorName: "suzuki",
+		Attachments: []issueops.CommentAttachmentInput{{OriginalName: attachment, Data: []byte(attachment), MimeType: "text/plain"}},
+	}); err != nil {
+		t.Fatalf("AddComment error: %v", err)
+	}
+	return created.Issue.IssueID
+}
+
+// editIssue はテスト用に課題を読み込んで変更し、そのまま保存する。
+func editIssue(t *testing.T, service *issueops.Service, issueID string, edit func(*issue.Issue)) {
+	t.Helper()
+	detail, err := service.GetIssue("cat", issueID)
+	if err != nil {
+		t.Fatalf("GetIssue error: %v", err)
+	}
+	edit(&detail.Issue)
+	if _, err := service.SaveImportedIssue("cat", mod.ModeVendor, detail.Issue); err != nil {
+		t.Fatalf("SaveImportedIssue error: %v", err)
+	}
+}
+
+// age はテスト用に課題とそのコメントの日時を oldTime へ戻す。
+func age(t *testing.T, service *issueops.Service, issueID string) {
+	t.Helper()
+	editIssue(t, service, issueID, func(item *issue.Issue) {
+		item.CreatedAt, item.UpdatedAt = oldTime, oldTime
+		for i := range item.Comments {
+			item.Comments[i].CreatedAt = oldTime
+		}
+	})
+}
+
+// export はテスト用にパッチを出力し、結果とパッチのパスを返す。
+func export(t *testing.T, root string, since time.Time) (ExportResult, string) {
+	t.Helper()
+	dest := filepath.Join(t.TempDir(), "changes.zip")
+	result, err := Export(context.Background(), root, since, "shared", dest)
+	if err != nil {
+		t.Fatalf("Export error: %v", err)
+	}
+	return result, dest
+}
+
+// readEntries はテスト用にパッチの全エントリを読み込む。
+func readEntries(t *testing.T, path string) map[string][]byte {
+	t.Helper()
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	defer func() { _ = reader.Close() }()
+	entries := map[string][]byte{}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("open entry: %v", err)
+		}
+		data, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatalf("read entry: %v", err)
+		}
+		entries[file.Name] = data
+	}
+	return entries
+}
+
+func TestExport_IncludesOnlyChangesSinceBaseline(t *testing.T) {
+	// 基準時刻以降に変わった課題のみを含め、課題JSONのコメントと添付は基準時刻以降のものに限ることを確認する。
+	validator, root := newProject(t)
+	service := issueops.NewService(root, validator)
+	changed := createIssue(t, service, "changed", "old.txt")
+	untouched := createIssue(t, service, "untouched", "other.txt")
+	age(t, service, changed)
+	age(t, service, untouched)
+	if _, err := service.AddComment("cat", changed, mod.ModeVendor, issueops.CommentCreateInput{
+		Body: "second", AuthorName: "sato",
+		Attachments: []issueops.CommentAttachmentInput{{OriginalName: "new.txt", Data: []byte("new"), MimeType: "text/plain"}},
+	}); err != nil {
+		t.Fatalf("AddComment error: %v", err)
+	}
+
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	result, dest := export(t, root, since)
+	if result.Issues != 1 || result.Comments != 1 || result.Attachments != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	entries := readEntries(t, dest)
+	var manifest Manifest
+	if err := json.Unmarshal(entries[manifestName], &manifest); err != nil {
+		t.Fatalf("unmarshal manifest: %v", err)
+	}
+	if manifest.Since == "" || len(manifest.Issues) != 1 || manifest.Issues[0].IssueID != changed || len(manifest.Files) != 2 {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+	var item issue.Issue
+	if err := json.Unmarshal(entries[manifest.Issues[0].file()], &item); err != nil {
+		t.Fatalf("unmarshal issue: %v", err)
+	}
+	if len(item.Comments) != 1 || item.Comments[0].Body != "second" {
+		t.Fatalf("expected only the new comment: %+v", item.Comments)
+	}
+	if data := entries[manifest.Issues[0].Dir+"/"+item.Comments[0].Attachments[0].RelativePath]; string(data) != "new" {
+		t.Fatalf("expected new attachment, got %q", data)
+	}
+
+	if all, _ := export(t, root, time.Time{}); all.Issues != 2 || all.Comments != 3 || all.Attachments != 3 {
+		t.Fatalf("expected everything without a baseline: %+v", all)
+	}
+}
+
+func TestApply_RejectsWrongPassphraseAndTamperedPatch(t *testing.T) {
+	// パスフレーズが異なる場合は ErrCrypto、manifest と内容が一致しない場合は ErrValidation とし、何も書き込まないことを確認する。
+	validator, root := newProject(t)
+	createIssue(t, issueops.NewService(root, validator), "signed", "log.txt")
+	_, dest := export(t, root, time.Time{})
+	_, target := newProject(t)
+
+	if _, err := Apply(context.Background(), target, dest, "other", validator, mod.ModeVendor, Options{}); !errors.Is(err, apperr.ErrCrypto) {
+		t.Fatalf("expected ErrCrypto, got %v", err)
+	}
+
+	entries := readEntries(t, dest)
+	var manifest Manifest
+	if err := json.Unmarshal(entries[manifestName], &manifest); err != nil {
+		t.Fatalf("unmarshal manifest: %v", err)
+	}
+	rebuilt := []entry{{name: manifestName, data: entries[manifestName]}, {name: signatureName, data: entries[signatureName]}}
+	for _, file := range manifest.Files {
+		data := entries[file.Path]
+		if file.Path == manifest.Issues[0].file() {
+			data = append(append([]byte{}, data...), ' ')
+		}
+		rebuilt = append(rebuilt, entry{name: file.Path, data: data})
+	}
+	archive, err := buildZip(rebuilt)
+	if err != nil {
+		t.Fatalf("buildZip error: %v", err)
+	}
+	tampered := filepath.Join(t.TempDir(), "tampered.zip")
+	if err := os.WriteFile(tampered, archive, 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := Apply(context.Background(), target, tampered, "shared", validator, mod.ModeVendor, Options{}); !errors.Is(err, apperr.ErrValidation) {
+		t.Fatalf("expected ErrValidation, got %v", err)
+	}
+	if names, _ := os.ReadDir(filepath.Join(target, "cat")); len(names) != 0 {
+		t.Fatalf("expected nothing to be written, got %d entries", len(names))
+	}
+}
+
+func TestReadPatch_RejectsOversizedEntry(t *testing.T) {
+	// 添付1件の上限を超えるエントリは署名の検証より前に、上限超過の入力エラーとして拒否することを確認する。
+	archive, err := buildZip([]entry{{name: manifestName, data: make([]byte, patchZipLimits.MaxEntryBytes+1)}})
+	if err != nil {
+		t.Fatalf("buildZip error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "big.zip")
+	if err := os.WriteFile(path, archive, 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, _, err := readPatch(path, "shared"); !errors.Is(err, apperr.ErrValidation) {
+		t.Fatalf("expected ErrValidation, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+// issue.go は課題1件の詳細 (項目・説明・コメント・添付の一覧) の PDF を担い、課題の読み込みは扱わない。
+package pdfreport
+
+import (
+	"fmt"
+	"strings"
+
+	"ratta/internal/domain/issue"
+)
+
+// WriteIssue は DD-REPORT-001 の課題の詳細の PDF を出力する。
+// 目的: Contractor への正式な提出物として、課題の内容と経緯を印刷できる形で渡せるようにする。
+// 入力: destPath は出力先、item は課題、opts は出力の設定。
+// 出力: Result とエラー。
+// エラー: 出力先が空の場合、フォントを読み込めない場合、PDF 標準フォントで表せない文字を含む場合、書き込みに失敗した場合に返す。
+// 副作用: destPath へ PDF を書き込む。
+// 並行性: 呼び出しごとに独立した文書を作るためスレッドセーフ。
+// 不変条件: コメントは課題JSONの順に並べ、添付は実体を含めずファイル名とサイズのみを載せる。
+// 関連DD: DD-REPORT-001, DD-DATA-003, DD-DATA-004, DD-DATA-005
+func WriteIssue(destPath string, item issue.Issue, opts Options) (Result, error) {
+	if destPath == "" {
+		return Result{}, fmt.Errorf("report path is required")
+	}
+	doc, err := newDocument(fmt.Sprintf("%s %s", item.IssueID, item.Title), opts)
+	if err != nil {
+		return Result{}, err
+	}
+	doc.heading(item.Title, 16)
+	doc.fields([][2]string{
+		{"Category", item.Category},
+		{"Issue ID", item.IssueID},
+		{"Status", string(item.Status)},
+		{"Priority", string(item.Priority)},
+		{"Origin", string(item.OriginCompany)},
+		{"Assignee", item.Assignee},
+		{"Due date", item.DueDate},
+		{"Created", displayTime(item.CreatedAt)},
+		{"Updated", displayTime(item.UpdatedAt)},
+		{"Tags", strings.Join(item.Tags, ", ")},
+	})
+	doc.pdf.Ln(4)
+	doc.heading("Description", 12)
+	doc.paragraph(item.Description)
+	doc.pdf.Ln(4)
+	doc.heading(fmt.Sprintf("Comments (%d)", len(item.Comments)), 12)
+	for i, comment := range item.Comments {
+		if i > 0 {
+			doc.pdf.Ln(2)
+		}
+		doc.font("B", 9)
+		doc.pdf.MultiCell(0, lineHeight, doc.text(fmt.Sprintf("#%d  %s (%s)  %s", i+1, comment.AuthorName, comment.AuthorCompany, displayTime(comment.CreatedAt))), "B", "L", false)
+		doc.paragraph(comment.Body)
+		for _, attachment := range comment.Attachments {
+			line := "Attachment: " + attachment.FileName
+			if size := formatSize(attachment.SizeBytes); size != "" {
+				line += " (" + size + ")"
+			}
+			doc.font("", 8)
+			doc.pdf.MultiCell(0, lineHeight, doc.text(line), "", "L", false)
+		}
+	}
+	if len(item.Comments) == 0 {
+		doc.paragraph("No comments.")
+	}
+	return doc.save(destPath)
+}
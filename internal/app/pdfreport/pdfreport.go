@@ -0,0 +1,229 @@
+// Package pdfreport は課題の詳細とプロジェクトの集計を印刷用の PDF として出力する処理を担い、課題の読み込みや集計そのものは扱わない。
+// GUI と CLI の双方から用い、同じ内容であれば同じレイアウトの PDF を出力する。
+package pdfreport
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/text/encoding/charmap"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+	"ratta/internal/domain/timeutil"
+	"ratta/internal/infra/atomicwrite"
+)
+
+const (
+	// fontFamily は DD-REPORT-001 の埋め込むフォントを登録する名前を表す。
+	fontFamily = "report"
+	// coreFamily は DD-REPORT-001 のフォントを設定していない場合に用いる PDF 標準フォントを表す。
+	coreFamily = "Helvetica"
+	// lineHeight は DD-REPORT-001 の本文1行の高さ (mm) を表す。
+	lineHeight = 5.5
+)
+
+// statusOrder は DD-REPORT-001 の集計表でステータスを並べる順を表す。GUI のステータス選択と同じ順とする。
+var statusOrder = []issue.Status{
+	issue.StatusOpen,
+	issue.StatusWorking,
+	issue.StatusInquiry,
+	issue.StatusHold,
+	issue.StatusFeedback,
+	issue.StatusResolved,
+	issue.StatusClosed,
+	issue.StatusRejected,
+}
+
+// Options は DD-REPORT-001 の出力の設定を表す。
+// FontPath は埋め込む TrueType フォントのパスを表し、空の場合は PDF 標準フォントで Latin-1 の範囲の文字のみを出力する。
+type Options struct {
+	FontPath string
+}
+
+// Result は DD-REPORT-001 の出力結果を表す。
+type Result struct {
+	Path  string
+	Pages int
+}
+
+// document は DD-REPORT-001 の作成中の PDF と文字の変換方法を表す。
+type document struct {
+	pdf    *gofpdf.Fpdf
+	family string
+	// embedded はフォントを埋め込む場合に true とし、文字を変換せずに UTF-8 のまま渡す。
+	embedded bool
+	// unsupported は PDF 標準フォントで表せない文字を含んでいた場合に true とする。
+	unsupported bool
+}
+
+// newDocument は DD-REPORT-001 の A4 縦の文書を作成し、フォントとページ番号付きのフッターを設定する。
+func newDocument(title string, opts Options) (*document, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 18)
+	pdf.AliasNbPages("")
+	doc := &document{pdf: pdf, family: coreFamily}
+	if opts.FontPath != "" {
+		// #nosec G304 -- 利用者が設定したフォントのパスのみを読む。
+		data, err := os.ReadFile(opts.FontPath)
+		if err != nil {
+			return nil, apperr.WithPath(fmt.Errorf("read pdf font: %w", err), opts.FontPath, "")
+		}
+		// 太字も同じフォントで描画し、フォントごとに太字の書体を用意しなくてよいようにする。
+		pdf.AddUTF8FontFromBytes(fontFamily, "", data)
+		pdf.AddUTF8FontFromBytes(fontFamily, "B", data)
+		if pdf.Err() {
+			return nil, apperr.WithPath(apperr.Errorf(apperr.ErrValidation, "load pdf font: %v", pdf.Error()), opts.FontPath, "")
+		}
+		doc.family = fontFamily
+		doc.embedded = true
+	}
+	pdf.SetTitle(title, true)
+	pdf.SetCreator("ratta", true)
+	generatedAt := displayTime(timeutil.NowISO8601())
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-13)
+		doc.font("", 8)
+		pdf.SetTextColor(110, 110, 110)
+		pdf.CellFormat(0, 5, doc.text(fmt.Sprintf("%s  %s", title, generatedAt)), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 5, fmt.Sprintf("%d / {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+	pdf.AddPage()
+	return doc, nil
+}
+
+// save は DD-REPORT-001 の作成した PDF を destPath へ書き込む。
+// PDF 標準フォントで表せない文字を含んでいた場合は、文字化けした PDF を書かずにエラーを返す。
+func (d *document) save(destPath string) (Result, error) {
+	var buf bytes.Buffer
+	if err := d.pdf.Output(&buf); err != nil {
+		return Result{}, fmt.Errorf("render pdf: %w", err)
+	}
+	// 最終ページのフッターは Output で描画されるため、その後に判定する。
+	if d.unsupported {
+		return Result{}, apperr.New(apperr.ErrValidation, "report contains characters that the built-in PDF font cannot render; set report.pdf_font in config.json to a TrueType font that supports them")
+	}
+	if err := atomicwrite.WriteFile(destPath, buf.Bytes()); err != nil {
+		return Result{}, fmt.Errorf("write pdf: %w", err)
+	}
+	return Result{Path: destPath, Pages: d.pdf.PageCount()}, nil
+}
+
+// font は DD-REPORT-001 の文字の太さと大きさ (pt) を切り替える。
+func (d *document) font(style string, size float64) {
+	d.pdf.SetFont(d.family, style, size)
+}
+
+// text は DD-REPORT-001 の文字列を現在のフォントで描画できる形へ変換する。
+// PDF 標準フォントの場合は Windows-1252 へ変換し、表せない文字は "?" とし unsupported を記録する。
+func (d *document) text(value string) string {
+	if d.embedded {
+		return value
+	}
+	var b strings.Builder
+	for _, r := range value {
+		if r < 0x80 {
+			b.WriteRune(r)
+			continue
+		}
+		encoded, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok {
+			d.unsupported = true
+			encoded = '?'
+		}
+		b.WriteByte(encoded)
+	}
+	return b.String()
+}
+
+// heading は DD-REPORT-001 の見出しを書く。
+func (d *document) heading(value string, size float64) {
+	d.font("B", size)
+	d.pdf.MultiCell(0, size*0.5, d.text(value), "", "L", false)
+	d.pdf.Ln(2)
+}
+
+// paragraph は DD-REPORT-001 の改行を含む本文を折り返して書く。
+func (d *document) paragraph(value string) {
+	d.font("", 10)
+	d.pdf.MultiCell(0, lineHeight, d.text(value), "", "L", false)
+}
+
+// fields は DD-REPORT-001 の項目名と値の組を2列の表として書く。値が空の項目は "-" とする。
+func (d *document) fields(rows [][2]string) {
+	d.pdf.SetFillColor(235, 235, 235)
+	for _, row := range rows {
+		value := row[1]
+		if value == "" {
+			value = "-"
+		}
+		d.font("B", 9)
+		d.pdf.CellFormat(35, 6, d.text(row[0]), "1", 0, "L", true, 0, "")
+		d.font("", 9)
+		d.pdf.CellFormat(0, 6, d.fit(value, 145), "1", 1, "L", false, 0, "")
+	}
+}
+
+// table は DD-REPORT-001 の見出し行と本文行を列幅 widths の表として書く。aligns は列ごとの寄せ ("L"・"R"・"C") を表す。
+func (d *document) table(header []string, rows [][]string, widths []float64, aligns []string) {
+	d.font("B", 8)
+	d.pdf.SetFillColor(235, 235, 235)
+	for i, label := range header {
+		d.pdf.CellFormat(widths[i], 6, d.fit(label, widths[i]), "1", 0, "C", true, 0, "")
+	}
+	d.pdf.Ln(-1)
+	d.font("", 8)
+	for _, row := range rows {
+		for i, value := range row {
+			d.pdf.CellFormat(widths[i], 6, d.fit(value, widths[i]), "1", 0, aligns[i], false, 0, "")
+		}
+		d.pdf.Ln(-1)
+	}
+}
+
+// fit は DD-REPORT-001 のセルの幅 width (mm) に収まるよう文字列を末尾で切り詰め、省略したことを "..." で示す。
+func (d *document) fit(value string, width float64) string {
+	converted := d.text(value)
+	limit := width - 2
+	if d.pdf.GetStringWidth(converted) <= limit {
+		return converted
+	}
+	runes := []rune(value)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := d.text(string(runes) + "...")
+		if d.pdf.GetStringWidth(candidate) <= limit {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// displayTime は DD-DATA-002 の保存された日時を表示用のタイムゾーンの分精度で返す。解析できない場合はそのまま返す。
+func displayTime(value string) string {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return parsed.In(timeutil.DisplayLocation()).Format("2006-01-02 15:04")
+}
+
+// formatSize は DD-REPORT-001 の添付のサイズを読みやすい単位で返す。不明な場合は空文字を返す。
+func formatSize(size int64) string {
+	switch {
+	case size <= 0:
+		return ""
+	case size < 1024:
+		return fmt.Sprintf("%d B", size)
+	case size < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	}
+}
@@ -0,0 +1,93 @@
+// pdfreport_test.go は課題の詳細と集計の PDF の出力とフォントの扱いのテストを行う。
+package pdfreport
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ratta/internal/app/issuescan"
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+)
+
+// sampleIssue はテスト用にコメントと添付を持つ課題を返す。
+func sampleIssue(title string) issue.Issue {
+	return issue.Issue{
+		IssueID:       "abc123",
+		Category:      "cat",
+		Title:         title,
+		Description:   "line1\nline2",
+		Status:        issue.StatusOpen,
+		Priority:      issue.PriorityHigh,
+		OriginCompany: issue.CompanyVendor,
+		DueDate:       "2024-01-01",
+		CreatedAt:     "2024-01-01T09:00:00+09:00",
+		UpdatedAt:     "2024-01-02T09:00:00+09:00",
+		Comments: []issue.Comment{{
+			Body:          strings.Repeat("long comment ", 400),
+			AuthorName:    "vendor",
+			AuthorCompany: issue.CompanyVendor,
+			CreatedAt:     "2024-01-02T09:00:00+09:00",
+			Attachments:   []issue.AttachmentRef{{FileName: "note.txt", SizeBytes: 2048}},
+		}},
+	}
+}
+
+func TestWriteIssue_WritesPDF(t *testing.T) {
+	// 課題の詳細を PDF として書き、長いコメントは複数ページへ送ることを確認する。
+	dest := filepath.Join(t.TempDir(), "issue.pdf")
+	result, err := WriteIssue(dest, sampleIssue("Café menu"), Options{})
+	if err != nil {
+		t.Fatalf("WriteIssue error: %v", err)
+	}
+	if result.Path != dest || result.Pages < 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Fatalf("expected pdf header: %q", data[:8])
+	}
+}
+
+func TestWriteIssue_RejectsTextOutsideBuiltInFont(t *testing.T) {
+	// フォントを設定せずに PDF 標準フォントで表せない文字を含む場合は入力の不備とし、ファイルを書かないことを確認する。
+	dest := filepath.Join(t.TempDir(), "issue.pdf")
+	if _, err := WriteIssue(dest, sampleIssue("不具合"), Options{}); !errors.Is(err, apperr.ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if _, err := os.Stat(dest); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("pdf must not be written: %v", err)
+	}
+	missing := filepath.Join(t.TempDir(), "missing.ttf")
+	_, err := WriteIssue(dest, sampleIssue("title"), Options{FontPath: missing})
+	var pathErr *apperr.PathError
+	if !errors.As(err, &pathErr) || pathErr.Path != missing {
+		t.Fatalf("expected font path error, got %v", err)
+	}
+}
+
+func TestWriteSummary_WritesPDF(t *testing.T) {
+	// ステータス別の件数と期限超過の一覧を PDF として書くことを確認する。
+	dest := filepath.Join(t.TempDir(), "summary.pdf")
+	stats := issuescan.ProjectStats{
+		Categories: []issuescan.CategoryStats{{Category: "cat", Total: 2, ByStatus: map[string]int{"Open": 1, "Closed": 1}}},
+		Total:      2,
+		ByStatus:   map[string]int{"Open": 1, "Closed": 1},
+		OpenCount:  1,
+		Overdue:    []issuescan.OpenIssue{{Category: "cat", IssueID: "abc123", Title: strings.Repeat("very long title ", 10), Status: "Open", Priority: "High", DueDate: "2024-01-01"}},
+	}
+	result, err := WriteSummary(dest, "project", stats, Options{})
+	if err != nil {
+		t.Fatalf("WriteSummary error: %v", err)
+	}
+	if result.Pages != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
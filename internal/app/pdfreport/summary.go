@@ -0,0 +1,74 @@
+// summary.go はプロジェクトの集計 (ステータス別の件数と期限超過の一覧) の PDF を担い、集計そのものは issuescan に委ねる。
+package pdfreport
+
+import (
+	"fmt"
+	"strconv"
+
+	"ratta/internal/app/issuescan"
+)
+
+// WriteSummary は DD-REPORT-001 のプロジェクトの集計の PDF を出力する。
+// 目的: 定例の報告に用いる状況の一覧と期限超過の課題を、印刷できる1つの文書にまとめる。
+// 入力: destPath は出力先、project は表題に用いるプロジェクト名、stats は issuescan.ProjectStatsContext の集計結果、opts は出力の設定。
+// 出力: Result とエラー。
+// エラー: 出力先が空の場合、フォントを読み込めない場合、PDF 標準フォントで表せない文字を含む場合、書き込みに失敗した場合に返す。
+// 副作用: destPath へ PDF を書き込む。
+// 並行性: 呼び出しごとに独立した文書を作るためスレッドセーフ。
+// 不変条件: カテゴリは集計結果の順 (カテゴリ一覧の表示順)、期限超過の課題は期限の古い順に並べる。
+// 関連DD: DD-REPORT-001, DD-STATS-001
+func WriteSummary(destPath, project string, stats issuescan.ProjectStats, opts Options) (Result, error) {
+	if destPath == "" {
+		return Result{}, fmt.Errorf("report path is required")
+	}
+	doc, err := newDocument("Status overview - "+project, opts)
+	if err != nil {
+		return Result{}, err
+	}
+	doc.heading("Status overview: "+project, 16)
+	doc.paragraph(fmt.Sprintf("Total %d, open %d, overdue %d, unreadable %d", stats.Total, stats.OpenCount, stats.OverdueCount, stats.ErrorCount))
+	doc.pdf.Ln(4)
+
+	// カテゴリ名の列を除いた幅をステータスごとに等分する。
+	header := []string{"Category", "Total"}
+	widths := []float64{38, 14}
+	aligns := []string{"L", "R"}
+	statusWidth := (180 - 38 - 14) / float64(len(statusOrder))
+	for _, status := range statusOrder {
+		header = append(header, string(status))
+		widths = append(widths, statusWidth)
+		aligns = append(aligns, "R")
+	}
+	rows := make([][]string, 0, len(stats.Categories)+1)
+	for _, category := range stats.Categories {
+		row := []string{category.Category, strconv.Itoa(category.Total)}
+		for _, status := range statusOrder {
+			row = append(row, strconv.Itoa(category.ByStatus[string(status)]))
+		}
+		rows = append(rows, row)
+	}
+	total := []string{"Total", strconv.Itoa(stats.Total)}
+	for _, status := range statusOrder {
+		total = append(total, strconv.Itoa(stats.ByStatus[string(status)]))
+	}
+	rows = append(rows, total)
+	doc.table(header, rows, widths, aligns)
+	doc.pdf.Ln(6)
+
+	doc.heading(fmt.Sprintf("Overdue issues (%d)", len(stats.Overdue)), 12)
+	if len(stats.Overdue) == 0 {
+		doc.paragraph("No overdue issues.")
+		return doc.save(destPath)
+	}
+	overdue := make([][]string, 0, len(stats.Overdue))
+	for _, item := range stats.Overdue {
+		overdue = append(overdue, []string{item.DueDate, item.Category, item.IssueID, item.Title, item.Status, item.Priority})
+	}
+	doc.table(
+		[]string{"Due date", "Category", "Issue ID", "Title", "Status", "Priority"},
+		overdue,
+		[]float64{20, 30, 22, 70, 20, 18},
+		[]string{"L", "L", "L", "L", "L", "L"},
+	)
+	return doc.save(destPath)
+}
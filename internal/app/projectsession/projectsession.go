@@ -0,0 +1,384 @@
+// Package projectsession は開いているプロジェクト単位の操作サービス・キー単位のロック・課題一覧と詳細のメモリキャッシュを担い、
+// 永続化や UI 通知は扱わない。
+// キャッシュは監視による変更通知とローカルの書き込みで破棄し、加えてファイル状態の照合で外部変更の取りこぼしを防ぐ。
+package projectsession
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ratta/internal/app/categoryops"
+	"ratta/internal/app/categoryscan"
+	"ratta/internal/app/issueops"
+	"ratta/internal/app/issuescan"
+	"ratta/internal/infra/fswatch"
+	"ratta/internal/infra/journal"
+	"ratta/internal/infra/keylock"
+	"ratta/internal/infra/schema"
+	"ratta/internal/infra/sqlitecache"
+)
+
+// dirStamp は DD-SESSION-001 のキャッシュの鮮度判定に用いるファイル・ディレクトリの状態を表す。
+type dirStamp struct {
+	modTime   int64
+	sizeBytes int64
+}
+
+// summaryCache は DD-SESSION-001 のカテゴリ単位の一覧キャッシュを表す。
+type summaryCache struct {
+	stamp dirStamp
+	items []issueops.IssueSummary
+}
+
+// detailCache は DD-SESSION-001 の課題単位の詳細キャッシュを表す。
+type detailCache struct {
+	stamp  dirStamp
+	detail issueops.IssueDetail
+}
+
+// instanceOwner は DD-JOURNAL-001 の操作記録の所有者を表す。共有フォルダ上で他の端末・プロセスの操作を取り消さないよう、
+// ホスト名・PID・起動時刻で識別する。
+var instanceOwner = func() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s/%d/%d", host, os.Getpid(), time.Now().UnixNano())
+}()
+
+// projectMetaLockKey は DD-LOCK-001 のカテゴリ表示順など .ratta 配下のプロジェクト設定を守るロックのキーを表す。
+const projectMetaLockKey = "project-meta"
+
+// Session は DD-SESSION-001 の開いているプロジェクトの状態を表す。
+// 操作サービスは状態を持たないため、プロジェクトを開いている間は同じものを使い回す。
+type Session struct {
+	root       string
+	issues     *issueops.Service
+	categories *categoryops.Service
+	scanner    *issuescan.Scanner
+	journal    *journal.Journal
+	locks      *keylock.Map
+
+	mu        sync.Mutex
+	summaries map[string]summaryCache
+	details   map[string]detailCache
+	// generation は破棄の度に進め、読み込み中に破棄された結果を保持しないために用いる。
+	generation uint64
+	// seen は Refresh で突き合わせる確認済みの課題一覧、acked は次回の Refresh で除くアプリ自身の変更を表す。
+	seen  map[string]map[string]issueops.IssueSummary
+	acked map[string]bool
+}
+
+// New は DD-SESSION-001 のプロジェクトルートに対するセッションを生成する。
+func New(root string, validator *schema.Validator, concurrency int) *Session {
+	return &Session{
+		root:       root,
+		issues:     issueops.NewService(root, validator).WithConcurrency(concurrency),
+		categories: categoryops.NewService(root),
+		scanner:    issuescan.NewScanner(validator).WithConcurrency(concurrency),
+		journal:    journal.Open(root, instanceOwner),
+		locks:      keylock.New(),
+		summaries:  make(map[string]summaryCache),
+		details:    make(map[string]detailCache),
+	}
+}
+
+// Root は DD-SESSION-001 のプロジェクトルートパスを返す。
+func (s *Session) Root() string {
+	return s.root
+}
+
+// Issues は DD-SESSION-001 のセッション設定を反映した課題操作サービスを返す。
+func (s *Session) Issues() *issueops.Service {
+	return s.issues
+}
+
+// Categories は DD-SESSION-001 のカテゴリ操作サービスを返す。
+func (s *Session) Categories() *categoryops.Service {
+	return s.categories
+}
+
+// Scanner は DD-SESSION-001 のセッション設定を反映した課題走査を返す。
+func (s *Session) Scanner() *issuescan.Scanner {
+	return s.scanner
+}
+
+// Journal は DD-JOURNAL-001 のこのインスタンスの操作記録を返す。
+func (s *Session) Journal() *journal.Journal {
+	return s.journal
+}
+
+// LockIssue は DD-LOCK-001 の課題の読み込みから保存までを他の操作と直列化するロックを取得する。
+// 同じ課題への更新同士と、課題を含むカテゴリへの操作を待たせる。返却した関数で解放する。
+// ロックはカテゴリ、課題の順に取得し、カテゴリ操作 (カテゴリのみを取得) とデッドロックしない。
+func (s *Session) LockIssue(category, issueID string) func() {
+	unlockCategory := s.locks.RLock(categoryLockKey(category))
+	unlockIssue := s.locks.Lock("issue:" + issueKey(category, issueID))
+	return func() {
+		unlockIssue()
+		unlockCategory()
+	}
+}
+
+// ReadIssue は DD-LOCK-001 の課題を読み込む間、更新と名前変更を待たせる共有ロックを取得する。返却した関数で解放する。
+func (s *Session) ReadIssue(category, issueID string) func() {
+	unlockCategory := s.locks.RLock(categoryLockKey(category))
+	unlockIssue := s.locks.RLock("issue:" + issueKey(category, issueID))
+	return func() {
+		unlockIssue()
+		unlockCategory()
+	}
+}
+
+// LockCategoryShared は DD-LOCK-001 のカテゴリへ課題を追加する間、カテゴリ全体に及ぶ操作を待たせる共有ロックを取得する。
+// 追加する課題の課題IDは保存時に採番するため、課題のロックは取得しない。返却した関数で解放する。
+func (s *Session) LockCategoryShared(category string) func() {
+	return s.locks.RLock(categoryLockKey(category))
+}
+
+// LockCategories は DD-LOCK-001 のカテゴリ全体に及ぶ操作のロックを取得する。
+// カテゴリ内の課題の更新が終わるまで待ち、操作中は新たな更新を待たせる。
+// 名前変更のように表示順も更新する操作に備え、カテゴリの後にプロジェクト設定のロックも取得する。
+// 複数のカテゴリは名前順に取得し、同時に呼ばれてもデッドロックしない。
+func (s *Session) LockCategories(names ...string) func() {
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+	unlocks := make([]func(), 0, len(sorted)+1)
+	for i, name := range sorted {
+		if i > 0 && sorted[i-1] == name {
+			continue
+		}
+		unlocks = append(unlocks, s.locks.Lock(categoryLockKey(name)))
+	}
+	unlocks = append(unlocks, s.locks.Lock(projectMetaLockKey))
+	return func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+}
+
+// categoryLockKey は DD-LOCK-001 のカテゴリのロックのキーを生成する。
+func categoryLockKey(category string) string {
+	return "category:" + category
+}
+
+// ListIssues は DD-SESSION-001 のキャッシュ付き一覧取得を行う。
+// 目的: カテゴリ間の行き来や並び替えの度に課題JSONを走査しないよう、カテゴリ内の一覧項目を保持する。
+// 入力: category はカテゴリ名、query は一覧条件。
+// 出力: IssueList とエラー。
+// エラー: カテゴリ読み取り失敗時に返す。
+// 副作用: 一覧項目をメモリに保持する。
+// 並行性: スレッドセーフ。
+// 不変条件: カテゴリディレクトリの状態が変わった場合はキャッシュを用いない。
+// 課題の保存は atomic write の rename で行われるため、作成・更新・削除はディレクトリの mtime に現れる。
+// SQLite キャッシュが有効な場合は DB 側で絞り込むため、メモリには保持しない。
+// 関連DD: DD-SESSION-001, DD-BE-003, DD-CACHE-001
+func (s *Session) ListIssues(category string, query issueops.IssueListQuery) (issueops.IssueList, error) {
+	service := s.issues
+	if sqlitecache.Enabled(s.root) {
+		return service.ListIssues(category, query)
+	}
+
+	stamp, stampErr := statStamp(filepath.Join(s.root, category))
+	s.mu.Lock()
+	cached, ok := s.summaries[category]
+	generation := s.generation
+	s.mu.Unlock()
+	if stampErr == nil && ok && cached.stamp == stamp {
+		return issueops.QueryIssues(category, cached.items, query)
+	}
+
+	items, err := service.ListSummaries(category)
+	if err != nil {
+		return issueops.IssueList{}, err
+	}
+	if stampErr == nil {
+		s.store(generation, func() { s.summaries[category] = summaryCache{stamp: stamp, items: items} })
+	}
+	return issueops.QueryIssues(category, items, query)
+}
+
+// ProgressFunc は DD-WARMUP-001 の事前読み込みの進捗を受け取る関数を表す。
+// done は完了したカテゴリ数、total は全カテゴリ数、category は直前に完了したカテゴリ名とする。
+type ProgressFunc func(done, total int, category string)
+
+// Warm は DD-WARMUP-001 のプロジェクトを開いた直後の事前読み込みを行う。
+// 目的: 全カテゴリの一覧を読み込んで索引とキャッシュを作り、最初の一覧表示を待たせないようにする。
+// 入力: ctx は中断通知、progress は進捗の通知先 (nil 可)。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: カテゴリ走査の失敗時、中断された場合に返す。個別カテゴリの読み込み失敗は無視する。
+// 副作用: 索引 (.ratta/index.json) または SQLite キャッシュを更新し、一覧と RefreshProject の基準をメモリに保持する。
+// 並行性: 通常の一覧取得と同時に実行してよい。中断はカテゴリ単位で受け付ける。
+// 不変条件: 全文検索の索引は作らない。索引は同時に1つしか開けず、検索と競合するため。
+// 関連DD: DD-WARMUP-001, DD-SESSION-001, DD-INDEX-001
+func (s *Session) Warm(ctx context.Context, progress ProgressFunc) error {
+	scanned, err := categoryscan.ScanContext(ctx, s.root)
+	if err != nil {
+		return err
+	}
+	total := len(scanned.Categories)
+	for i, category := range scanned.Categories {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		// 名前変更中のカテゴリは一時ディレクトリ配下にあり一覧の対象外のため、読み込まない。
+		if !category.IsReadOnly || category.IsArchived {
+			// 読めないカテゴリは利用者が開いた時点で改めてエラーとして表示されるため、ここでは無視する。
+			_, _ = s.ListIssues(category.Name, issueops.IssueListQuery{})
+			// 開いた時点の状態を RefreshProject の突き合わせの基準とする。索引を使う場合は直前に作られているため読み直しは生じない。
+			if items, err := s.issues.ListSummaries(category.Name); err == nil {
+				s.remember(category.Name, items)
+			}
+		}
+		if progress != nil {
+			progress(i+1, total, category.Name)
+		}
+	}
+	return nil
+}
+
+// RebuildIndex は DD-INDEX-001 の全カテゴリの索引の作り直しを行う。
+// 目的: 鮮度判定で検知できない索引の不整合を、利用者の操作で解消できるようにする。
+// 入力: ctx は中断通知、progress は進捗の通知先 (nil 可)。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: カテゴリ走査の失敗時、中断された場合に返す。個別カテゴリの読み込み失敗は無視する。
+// 副作用: .ratta/index.json を作り直し、一覧と詳細のキャッシュを破棄する。SQLite キャッシュと全文検索の索引は作り直さない。
+// 並行性: 通常の一覧取得と同時に実行してよい。中断はカテゴリ単位で受け付け、作り直し済みのカテゴリはそのまま残す。
+// 不変条件: 名前変更中のカテゴリは対象外とする。
+// 関連DD: DD-INDEX-001, DD-SESSION-001
+func (s *Session) RebuildIndex(ctx context.Context, progress ProgressFunc) error {
+	scanned, err := categoryscan.ScanContext(ctx, s.root)
+	if err != nil {
+		return err
+	}
+	total := len(scanned.Categories)
+	for i, category := range scanned.Categories {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if !category.IsReadOnly || category.IsArchived {
+			_, _ = s.issues.RebuildSummaries(category.Name)
+			s.invalidateCategory(category.Name)
+		}
+		if progress != nil {
+			progress(i+1, total, category.Name)
+		}
+	}
+	return nil
+}
+
+// GetIssue は DD-SESSION-001 のキャッシュ付き詳細取得を行う。
+// 課題JSONの mtime とサイズが一致する間は、読み込みとスキーマ検証を省いて保持している詳細を返す。
+func (s *Session) GetIssue(category, issueID string) (issueops.IssueDetail, error) {
+	key := issueKey(category, issueID)
+	stamp, stampErr := statStamp(filepath.Join(s.root, category, issueID+".json"))
+	s.mu.Lock()
+	cached, ok := s.details[key]
+	generation := s.generation
+	s.mu.Unlock()
+	if stampErr == nil && ok && cached.stamp == stamp {
+		return cached.detail, nil
+	}
+
+	detail, err := s.issues.GetIssue(category, issueID)
+	if err != nil {
+		s.InvalidateIssue(category, issueID)
+		return issueops.IssueDetail{}, err
+	}
+	if stampErr == nil {
+		s.store(generation, func() { s.details[key] = detailCache{stamp: stamp, detail: detail} })
+	}
+	return detail, nil
+}
+
+// InvalidateCategory は DD-SESSION-001 のカテゴリ単位のキャッシュを破棄する。
+// カテゴリ名変更・削除・アーカイブなど、カテゴリ全体に及ぶ操作の後に呼び出す。
+// アプリ自身の変更として扱い、RefreshProject の外部変更の差分から除く。
+func (s *Session) InvalidateCategory(category string) {
+	s.acknowledge(issueKey(category, ""))
+	s.invalidateCategory(category)
+}
+
+// invalidateCategory は DD-SESSION-001 のカテゴリ単位のキャッシュを破棄する。
+func (s *Session) invalidateCategory(category string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generation++
+	delete(s.summaries, category)
+	prefix := issueKey(category, "")
+	for key := range s.details {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.details, key)
+		}
+	}
+}
+
+// InvalidateIssue は DD-SESSION-001 の課題単位のキャッシュと、その課題を含むカテゴリの一覧を破棄する。
+// 課題の保存後に呼び出し、アプリ自身の変更として RefreshProject の外部変更の差分から除く。
+func (s *Session) InvalidateIssue(category, issueID string) {
+	s.acknowledge(issueKey(category, issueID))
+	s.invalidateIssue(category, issueID)
+}
+
+// invalidateIssue は DD-SESSION-001 の課題単位のキャッシュと、その課題を含むカテゴリの一覧を破棄する。
+func (s *Session) invalidateIssue(category, issueID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generation++
+	delete(s.summaries, category)
+	delete(s.details, issueKey(category, issueID))
+}
+
+// InvalidateAll は DD-SESSION-001 の全キャッシュを破棄する。
+func (s *Session) InvalidateAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generation++
+	s.summaries = make(map[string]summaryCache)
+	s.details = make(map[string]detailCache)
+}
+
+// ApplyChanges は DD-SESSION-001 の監視による変更通知をキャッシュへ反映する。
+// 課題・添付の変更は該当課題とカテゴリ一覧を、カテゴリの変更はカテゴリ全体を破棄する。
+func (s *Session) ApplyChanges(events []fswatch.Event) {
+	for _, event := range events {
+		switch event.Kind {
+		case fswatch.KindCategory:
+			s.invalidateCategory(event.Category)
+		case fswatch.KindIssue, fswatch.KindAttachment:
+			s.invalidateIssue(event.Category, event.IssueID)
+		}
+	}
+}
+
+// store は DD-SESSION-001 の読み込み結果を保持する。読み込み開始後に破棄が行われていれば保持しない。
+func (s *Session) store(generation uint64, put func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generation != generation {
+		return
+	}
+	put()
+}
+
+// issueKey は DD-SESSION-001 の詳細キャッシュのキーを生成する。
+// カテゴリ名にパス区切りは含まれないため、区切りに "/" を用いても衝突しない。
+func issueKey(category, issueID string) string {
+	return category + "/" + issueID
+}
+
+// statStamp は DD-SESSION-001 の鮮度判定に用いる状態を取得する。
+func statStamp(path string) (dirStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return dirStamp{}, err
+	}
+	return dirStamp{modTime: info.ModTime().UnixNano(), sizeBytes: info.Size()}, nil
+}
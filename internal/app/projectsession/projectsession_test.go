@@ -0,0 +1,240 @@
+// projectsession_test.go はセッションのキャッシュ利用と破棄、キー単位のロックのテストを行い、課題操作そのものは扱わない。
+package projectsession
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/fswatch"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// createIssue はテスト用の課題を作成し、その課題IDを返す。
+func createIssue(t *testing.T, session *Session, category, title string) string {
+	t.Helper()
+	created, err := session.Issues().CreateIssue(category, mod.ModeContractor, issueops.IssueCreateInput{
+		Title:       title,
+		Description: "desc",
+		DueDate:     "2024-01-01",
+		Priority:    issue.PriorityHigh,
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	return created.Issue.IssueID
+}
+
+// rewriteTitle はディレクトリの mtime を変えないよう、課題JSONをその場で書き換えてタイトルを置き換える。
+func rewriteTitle(t *testing.T, path, from, to string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Replace(string(data), from, to, 1)), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func TestListIssues_ReusesCacheUntilDirectoryOrInvalidationChanges(t *testing.T) {
+	// ディレクトリの状態が同じ間は保持した一覧を返し、課題の追加や明示的な破棄の後は読み直すことを確認する。
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "cat"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	session := New(root, nil, 0)
+	firstID := createIssue(t, session, "cat", "first")
+
+	list, err := session.ListIssues("cat", issueops.IssueListQuery{})
+	if err != nil {
+		t.Fatalf("ListIssues error: %v", err)
+	}
+	if len(list.Issues) != 1 || list.Issues[0].Title != "first" {
+		t.Fatalf("unexpected list: %+v", list.Issues)
+	}
+
+	rewriteTitle(t, filepath.Join(root, "cat", firstID+".json"), `"first"`, `"edited"`)
+	cached, err := session.ListIssues("cat", issueops.IssueListQuery{})
+	if err != nil {
+		t.Fatalf("ListIssues error: %v", err)
+	}
+	if cached.Issues[0].Title != "first" {
+		t.Fatalf("expected cached title, got %+v", cached.Issues)
+	}
+
+	session.ApplyChanges([]fswatch.Event{{Kind: fswatch.KindIssue, Op: fswatch.OpModified, Category: "cat", IssueID: firstID}})
+	reloaded, err := session.ListIssues("cat", issueops.IssueListQuery{})
+	if err != nil {
+		t.Fatalf("ListIssues error: %v", err)
+	}
+	if reloaded.Issues[0].Title != "edited" {
+		t.Fatalf("expected reloaded title, got %+v", reloaded.Issues)
+	}
+
+	createIssue(t, session, "cat", "second")
+	added, err := session.ListIssues("cat", issueops.IssueListQuery{})
+	if err != nil {
+		t.Fatalf("ListIssues error: %v", err)
+	}
+	if len(added.Issues) != 2 {
+		t.Fatalf("expected new issue to appear, got %+v", added.Issues)
+	}
+}
+
+func TestGetIssue_ReloadsWhenFileChangesAndSkipsStaleStore(t *testing.T) {
+	// 課題JSONの状態が変わると詳細を読み直し、読み込み中に破棄された結果は保持しないことを確認する。
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "cat"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	session := New(root, nil, 0)
+	issueID := createIssue(t, session, "cat", "first")
+
+	detail, err := session.GetIssue("cat", issueID)
+	if err != nil {
+		t.Fatalf("GetIssue error: %v", err)
+	}
+	if detail.Issue.Title != "first" {
+		t.Fatalf("unexpected detail: %+v", detail.Issue)
+	}
+	rewriteTitle(t, filepath.Join(root, "cat", issueID+".json"), `"first"`, `"changed title"`)
+	changed, err := session.GetIssue("cat", issueID)
+	if err != nil {
+		t.Fatalf("GetIssue error: %v", err)
+	}
+	if changed.Issue.Title != "changed title" {
+		t.Fatalf("expected reload after size change, got %+v", changed.Issue)
+	}
+
+	session.mu.Lock()
+	generation := session.generation
+	session.mu.Unlock()
+	session.InvalidateCategory("cat")
+	session.store(generation, func() { session.summaries["cat"] = summaryCache{} })
+	session.mu.Lock()
+	_, stored := session.summaries["cat"]
+	_, detailKept := session.details[issueKey("cat", issueID)]
+	session.mu.Unlock()
+	if stored || detailKept {
+		t.Fatalf("expected stale store to be skipped and details dropped")
+	}
+}
+
+func TestWarm_CachesEveryCategoryAndStopsOnCancel(t *testing.T) {
+	// 全カテゴリの一覧を保持して進捗を通知し、中断済みの context では読み込まないことを確認する。
+	root := t.TempDir()
+	for _, category := range []string{"alpha", "beta"} {
+		if err := os.MkdirAll(filepath.Join(root, category), 0o750); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	session := New(root, nil, 0)
+	createIssue(t, session, "alpha", "first")
+
+	var seen []string
+	if err := session.Warm(context.Background(), func(done, total int, category string) {
+		if total != 2 || done != len(seen)+1 {
+			t.Fatalf("unexpected progress: %d/%d", done, total)
+		}
+		seen = append(seen, category)
+	}); err != nil {
+		t.Fatalf("Warm error: %v", err)
+	}
+	session.mu.Lock()
+	cached := len(session.summaries)
+	session.mu.Unlock()
+	if len(seen) != 2 || cached != 2 {
+		t.Fatalf("expected both categories warmed, seen=%v cached=%d", seen, cached)
+	}
+
+	session.InvalidateAll()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := session.Warm(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled, got %v", err)
+	}
+	session.mu.Lock()
+	cached = len(session.summaries)
+	session.mu.Unlock()
+	if cached != 0 {
+		t.Fatalf("expected nothing cached after cancel, got %d", cached)
+	}
+}
+
+func TestRebuildIndex_ReloadsIssuesTheIndexConsidersFresh(t *testing.T) {
+	// mtime とサイズを保った書き換えは通常の一覧に反映されず、索引の作り直し後に反映されることを確認する。
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "cat"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	session := New(root, nil, 0)
+	issueID := createIssue(t, session, "cat", "first")
+	if _, err := session.ListIssues("cat", issueops.IssueListQuery{}); err != nil {
+		t.Fatalf("ListIssues error: %v", err)
+	}
+	path := filepath.Join(root, "cat", issueID+".json")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	rewriteTitle(t, path, `"first"`, `"fixed"`)
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	session.InvalidateAll()
+	stale, err := session.ListIssues("cat", issueops.IssueListQuery{})
+	if err != nil {
+		t.Fatalf("ListIssues error: %v", err)
+	}
+	if stale.Issues[0].Title != "first" {
+		t.Fatalf("expected index entry to be reused, got %+v", stale.Issues)
+	}
+
+	calls := 0
+	if err := session.RebuildIndex(context.Background(), func(done, total int, category string) {
+		calls++
+	}); err != nil {
+		t.Fatalf("RebuildIndex error: %v", err)
+	}
+	rebuilt, err := session.ListIssues("cat", issueops.IssueListQuery{})
+	if err != nil {
+		t.Fatalf("ListIssues error: %v", err)
+	}
+	if calls != 1 || rebuilt.Issues[0].Title != "fixed" {
+		t.Fatalf("expected rebuilt title, calls=%d issues=%+v", calls, rebuilt.Issues)
+	}
+}
+
+func TestLockCategories_WaitsForIssueLocks(t *testing.T) {
+	// カテゴリ全体の操作は課題の更新が終わるまで待ち、別カテゴリの課題の更新は待たないことを確認する。
+	session := New(t.TempDir(), nil, 0)
+	unlockIssue := session.LockIssue("cat", "A")
+	acquired := make(chan struct{})
+	go func() {
+		unlock := session.LockCategories("other", "cat", "cat")
+		close(acquired)
+		unlock()
+	}()
+
+	unlockOther := session.LockIssue("third", "B")
+	unlockOther()
+	select {
+	case <-acquired:
+		t.Fatalf("category lock must wait for the issue lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlockIssue()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatalf("category lock was not acquired after release")
+	}
+}
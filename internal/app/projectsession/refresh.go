@@ -0,0 +1,134 @@
+// refresh.go は前回確認した状態と現在の課題一覧の突き合わせを担い、UI への通知や変更の取り込みは扱わない。
+// 共有フォルダを複数人で編集する際、作業中に他の利用者が加えた変更を利用者が確認できるようにする。
+package projectsession
+
+import (
+	"context"
+	"sort"
+
+	"ratta/internal/app/categoryscan"
+	"ratta/internal/app/issueops"
+)
+
+// remember は DD-REFRESH-001 のカテゴリの課題一覧を確認済みの状態として記録する。
+func (s *Session) remember(category string, items []issueops.IssueSummary) {
+	known := make(map[string]issueops.IssueSummary, len(items))
+	for _, item := range items {
+		known[item.IssueID] = item
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen == nil {
+		s.seen = make(map[string]map[string]issueops.IssueSummary)
+	}
+	s.seen[category] = known
+}
+
+// acknowledge は DD-REFRESH-001 のアプリ自身の変更を記録し、次回の Refresh で外部の変更として返さないようにする。
+// key は課題の場合 issueKey(category, issueID)、カテゴリ全体の場合 issueKey(category, "") とする。
+func (s *Session) acknowledge(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.acked == nil {
+		s.acked = make(map[string]bool)
+	}
+	s.acked[key] = true
+}
+
+// Refresh は DD-REFRESH-001 の外部変更の突き合わせを行う。
+// 目的: 編集中に他の利用者が共有フォルダへ加えた課題とカテゴリの追加・変更・削除を一覧で示す。
+// 入力: ctx は中断通知。
+// 出力: 前回の確認以降の変更差分 (Since/Until は設定しない) とエラー。
+// エラー: カテゴリ走査の失敗時、中断された場合に返す。個別カテゴリの読み込み失敗は差分から除く。
+// 副作用: 確認済みの状態を現在の状態へ更新し、変更のあったカテゴリのキャッシュを破棄する。
+// 並行性: 通常の一覧取得や課題操作と同時に実行してよい。実行中の課題操作は次回の Refresh で除外される。
+// 不変条件: 事前読み込みか Refresh で状態を記録するまでは差分を返さない。
+// InvalidateIssue/InvalidateCategory で通知されたアプリ自身の変更は差分に含めない。
+// 関連DD: DD-REFRESH-001, DD-SESSION-001, DD-INDEX-001
+func (s *Session) Refresh(ctx context.Context) (issueops.Changes, error) {
+	scanned, err := categoryscan.ScanContext(ctx, s.root)
+	if err != nil {
+		return issueops.Changes{}, err
+	}
+	s.mu.Lock()
+	previous := s.seen
+	acked := s.acked
+	s.acked = nil
+	s.mu.Unlock()
+
+	changes := issueops.Changes{Categories: []issueops.CategoryChange{}, Issues: []issueops.IssueChange{}}
+	current := make(map[string]map[string]issueops.IssueSummary, len(scanned.Categories))
+	changed := make(map[string]bool)
+	for _, category := range scanned.Categories {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return issueops.Changes{}, ctxErr
+		}
+		// 名前変更中のカテゴリは一覧の対象外のため、前回の状態を引き継いで削除として返さない。
+		if category.IsReadOnly && !category.IsArchived {
+			if known, ok := previous[category.Name]; ok {
+				current[category.Name] = known
+			}
+			continue
+		}
+		items, listErr := s.issues.ListSummaries(category.Name)
+		if listErr != nil {
+			if known, ok := previous[category.Name]; ok {
+				current[category.Name] = known
+			}
+			continue
+		}
+		known, wasKnown := previous[category.Name]
+		categoryAcked := acked[issueKey(category.Name, "")]
+		if previous != nil && !wasKnown && !categoryAcked {
+			changes.Categories = append(changes.Categories, issueops.CategoryChange{Name: category.Name, Op: issueops.ChangeCreated})
+		}
+		now := make(map[string]issueops.IssueSummary, len(items))
+		for _, item := range items {
+			now[item.IssueID] = item
+			if previous == nil || categoryAcked || acked[issueKey(category.Name, item.IssueID)] {
+				continue
+			}
+			before, existed := known[item.IssueID]
+			if existed && before == item {
+				continue
+			}
+			op := issueops.ChangeUpdated
+			if !existed {
+				op = issueops.ChangeCreated
+			}
+			summary := item
+			changes.Issues = append(changes.Issues, issueops.IssueChange{Op: op, Category: category.Name, IssueID: item.IssueID, Summary: &summary})
+			changed[category.Name] = true
+		}
+		for issueID := range known {
+			if _, exists := now[issueID]; exists || categoryAcked || acked[issueKey(category.Name, issueID)] {
+				continue
+			}
+			changes.Issues = append(changes.Issues, issueops.IssueChange{Op: issueops.ChangeRemoved, Category: category.Name, IssueID: issueID})
+			changed[category.Name] = true
+		}
+		current[category.Name] = now
+	}
+	for name := range previous {
+		if _, exists := current[name]; !exists && !acked[issueKey(name, "")] {
+			changes.Categories = append(changes.Categories, issueops.CategoryChange{Name: name, Op: issueops.ChangeRemoved})
+		}
+	}
+
+	s.mu.Lock()
+	s.seen = current
+	s.mu.Unlock()
+	// 一覧のキャッシュはディレクトリの状態で鮮度を判定するため、その場での書き換えによる変更を読み直させる。
+	for name := range changed {
+		s.invalidateCategory(name)
+	}
+
+	sort.Slice(changes.Categories, func(i, j int) bool { return changes.Categories[i].Name < changes.Categories[j].Name })
+	sort.SliceStable(changes.Issues, func(i, j int) bool {
+		if changes.Issues[i].Category != changes.Issues[j].Category {
+			return changes.Issues[i].Category < changes.Issues[j].Category
+		}
+		return changes.Issues[i].IssueID < changes.Issues[j].IssueID
+	})
+	return changes, nil
+}
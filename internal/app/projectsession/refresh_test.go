@@ -0,0 +1,117 @@
+// refresh_test.go は確認済みの状態と現在の課題一覧の突き合わせのテストを行い、課題操作そのものは扱わない。
+package projectsession
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/issue"
+
+	mod "ratta/internal/domain/mode"
+)
+
+func TestRefresh_ReportsExternalChangesButNotOwnChanges(t *testing.T) {
+	// 外部での課題の追加・変更・削除とカテゴリの追加を返し、アプリ自身の変更と確認済みの変更は返さないことを確認する。
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "cat"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	session := New(root, nil, 0)
+	editedID := createIssue(t, session, "cat", "first")
+	ownID := createIssue(t, session, "cat", "own")
+	removedID := createIssue(t, session, "cat", "removed")
+	if err := session.Warm(context.Background(), nil); err != nil {
+		t.Fatalf("Warm error: %v", err)
+	}
+
+	rewriteTitle(t, filepath.Join(root, "cat", editedID+".json"), `"first"`, `"edited elsewhere"`)
+	if err := os.Remove(filepath.Join(root, "cat", removedID+".json")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	other := issueops.NewService(root, nil)
+	added, err := other.CreateIssue("cat", mod.ModeVendor, issueops.IssueCreateInput{
+		Title:       "added elsewhere",
+		Description: "desc",
+		DueDate:     "2024-01-01",
+		Priority:    issue.PriorityLow,
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "newcat"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if _, err := session.Issues().UpdateIssue("cat", ownID, mod.ModeContractor, issueops.IssueUpdateInput{
+		Title:       "own edit",
+		Description: "desc",
+		DueDate:     "2024-01-01",
+		Priority:    issue.PriorityHigh,
+		Status:      issue.StatusOpen,
+	}); err != nil {
+		t.Fatalf("UpdateIssue error: %v", err)
+	}
+	session.InvalidateIssue("cat", ownID)
+
+	changes, err := session.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("Refresh error: %v", err)
+	}
+	if len(changes.Categories) != 1 || changes.Categories[0].Name != "newcat" || changes.Categories[0].Op != issueops.ChangeCreated {
+		t.Fatalf("unexpected category changes: %+v", changes.Categories)
+	}
+	got := make(map[string]issueops.ChangeOp)
+	for _, change := range changes.Issues {
+		got[change.IssueID] = change.Op
+	}
+	want := map[string]issueops.ChangeOp{
+		editedID:            issueops.ChangeUpdated,
+		removedID:           issueops.ChangeRemoved,
+		added.Issue.IssueID: issueops.ChangeCreated,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected issue changes: %+v", changes.Issues)
+	}
+	for issueID, op := range want {
+		if got[issueID] != op {
+			t.Fatalf("expected %s to be %s, got %+v", issueID, op, changes.Issues)
+		}
+	}
+
+	again, err := session.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("Refresh error: %v", err)
+	}
+	if len(again.Categories) != 0 || len(again.Issues) != 0 {
+		t.Fatalf("expected no changes after refresh, got %+v", again)
+	}
+}
+
+func TestRefresh_RecordsBaselineBeforeReporting(t *testing.T) {
+	// 基準となる状態が無い場合は差分を返さず、その時点の状態を基準として記録することを確認する。
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "cat"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	session := New(root, nil, 0)
+	createIssue(t, session, "cat", "first")
+	changes, err := session.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("Refresh error: %v", err)
+	}
+	if len(changes.Categories) != 0 || len(changes.Issues) != 0 {
+		t.Fatalf("expected no changes without baseline, got %+v", changes)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "later"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	next, err := session.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("Refresh error: %v", err)
+	}
+	if len(next.Categories) != 1 || next.Categories[0].Name != "later" {
+		t.Fatalf("expected new category after baseline, got %+v", next.Categories)
+	}
+}
@@ -0,0 +1,73 @@
+// base.go は三方向マージの基準とする前回の同期時点の課題の版を、同期元のプロジェクトの .ratta 配下へ記録する処理を担い、
+// マージの判断は扱わない。
+package projectsync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/atomicwrite"
+	"ratta/internal/infra/jsonfmt"
+	"ratta/internal/infra/projectmeta"
+)
+
+// baseDirName は DD-SYNC-003 の同期の記録を置く .ratta 配下のディレクトリ名を表す。
+const baseDirName = "sync"
+
+// BaseDir は DD-SYNC-003 の同期の相手ごとに前回の同期時点の課題を記録するディレクトリを返す。
+// 相手は解決済みの絶対パスのハッシュで区別するため、相手のプロジェクトを別のパスで開いた場合は別の相手として扱う。
+func BaseDir(root, otherRoot string) string {
+	sum := sha256.Sum256([]byte(otherRoot))
+	return filepath.Join(projectmeta.Dir(root), baseDirName, hex.EncodeToString(sum[:8]))
+}
+
+// baseStore は DD-SYNC-003 の1つの相手との前回の同期時点の課題を表す。dir は BaseDir の値を表す。
+type baseStore struct {
+	dir string
+}
+
+// path は DD-SYNC-003 の課題の記録のパスを返す。
+func (b baseStore) path(category, issueID string) string {
+	return filepath.Join(b.dir, category, issueID+".json")
+}
+
+// load は DD-SYNC-003 の前回の同期時点の課題を返す。記録が無い場合は nil を返す。
+func (b baseStore) load(category, issueID string) (*issue.Issue, error) {
+	path := b.path(category, issueID)
+	// #nosec G304 -- 同期の記録のディレクトリ配下の、検証済みのカテゴリ名と課題IDから組み立てたパスのみを読む。
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read sync base: %w", err)
+	}
+	var item issue.Issue
+	if err := json.Unmarshal(data, &item); err != nil {
+		// 記録は同期のたびに書き直すため、壊れている場合は記録が無いものとして扱い、差分を競合として報告させる。
+		return nil, nil
+	}
+	return &item, nil
+}
+
+// save は DD-SYNC-003 の同期後の課題を次回の同期の基準として記録する。
+func (b baseStore) save(item issue.Issue) error {
+	data, err := jsonfmt.MarshalIssue(item)
+	if err != nil {
+		return fmt.Errorf("marshal sync base: %w", err)
+	}
+	path := b.path(item.Category, item.IssueID)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("create sync base dir: %w", err)
+	}
+	if err := atomicwrite.WriteFile(path, data); err != nil {
+		return fmt.Errorf("write sync base: %w", err)
+	}
+	return nil
+}
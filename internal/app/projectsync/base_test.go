@@ -0,0 +1,45 @@
+// base_test.go は前回の同期時点の課題の記録のテストを行う。
+package projectsync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBaseStore_SavesAndLoadsPerPeer(t *testing.T) {
+	// 相手ごとに別のディレクトリへ記録し、記録した課題を読み戻せることを確認する。
+	root := t.TempDir()
+	if BaseDir(root, "/shared/a") == BaseDir(root, "/shared/b") {
+		t.Fatal("expected a separate directory per peer")
+	}
+	store := baseStore{dir: BaseDir(root, "/shared/a")}
+	item := baseIssue()
+	if err := store.save(item); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+	loaded, err := store.load("cat", item.IssueID)
+	if err != nil || loaded == nil || !sameIssue(*loaded, item) {
+		t.Fatalf("unexpected loaded issue: %+v %v", loaded, err)
+	}
+	other, err := baseStore{dir: BaseDir(root, "/shared/b")}.load("cat", item.IssueID)
+	if err != nil || other != nil {
+		t.Fatalf("expected no record for another peer, got %+v %v", other, err)
+	}
+}
+
+func TestBaseStore_IgnoresBrokenRecord(t *testing.T) {
+	// 壊れた記録は記録が無いものとして扱うことを確認する。
+	store := baseStore{dir: t.TempDir()}
+	path := store.path("cat", "abcdefgh")
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("{broken"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	loaded, err := store.load("cat", "abcdefgh")
+	if err != nil || loaded != nil {
+		t.Fatalf("expected no record, got %+v %v", loaded, err)
+	}
+}
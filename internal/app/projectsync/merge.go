@@ -0,0 +1,252 @@
+// merge.go は同じ課題の2つの版を前回の同期時点の版と比べて統合する三方向マージを担い、ファイルの読み書きは扱わない。
+package projectsync
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/issue"
+)
+
+// customFieldPrefix は DD-SYNC-002 の custom_fields のキーごとの競合を表す項目名の接頭辞を表す。
+const customFieldPrefix = "custom_fields."
+
+// Conflict は DD-SYNC-002 の両方のプロジェクトで異なる値に変更された項目を表す。
+// Base は前回の同期時点の値で、前回の同期の記録が無い場合や項目が無かった場合は nil とする。
+type Conflict struct {
+	Field  string
+	Base   any
+	Local  any
+	Remote any
+}
+
+// mergeResult は DD-SYNC-002 の三方向マージの結果を表す。
+// Local・Remote はそれぞれのプロジェクトへ保存する課題で、競合した項目はそれぞれの値のまま残す。
+// CommentsToLocal・CommentsToRemote は相手側から加わるコメントの数を表す。
+type mergeResult struct {
+	Local            issue.Issue
+	Remote           issue.Issue
+	Conflicts        []Conflict
+	CommentsToLocal  int
+	CommentsToRemote int
+}
+
+// mergeIssue は DD-SYNC-002 の同じ課題の2つの版を三方向マージする。
+// 目的: 片側のみで変更された項目は変更を取り込み、両側で異なる値に変更された項目を競合として報告する。
+// 入力: base は前回の同期時点の版で、記録が無い場合は nil。local・remote はそれぞれのプロジェクトの現在の版。
+// 出力: mergeResult。
+// エラー: なし。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 前回の同期の記録が無い場合、両側で値が異なる項目はすべて競合とする。
+// コメントは和集合とし、前回の同期時点にあって片側で削除されたコメントのみを除く。updated_at は変更する側で新しい方の値とする。
+// 関連DD: DD-SYNC-002
+func mergeIssue(base *issue.Issue, local, remote issue.Issue) mergeResult {
+	result := mergeResult{Local: local, Remote: remote}
+	// 課題ID・起票会社・作成日時は変更されないため issueops.MergeFields の項目のみを比べ、custom_fields はキーごとに扱う。
+	for _, f := range issueops.MergeFields {
+		var baseValue any
+		if base != nil {
+			baseValue = f.Get(*base)
+		}
+		value, conflict := mergeValue(baseValue, base != nil, f.Get(local), f.Get(remote))
+		if conflict {
+			result.Conflicts = append(result.Conflicts, Conflict{Field: f.Name, Base: baseValue, Local: f.Get(local), Remote: f.Get(remote)})
+			continue
+		}
+		f.Set(&result.Local, value)
+		f.Set(&result.Remote, value)
+	}
+	mergeCustomFields(base, local, remote, &result)
+
+	comments, toLocal, toRemote := mergeComments(base, local.Comments, remote.Comments)
+	result.Local.Comments, result.Remote.Comments = comments, comments
+	result.CommentsToLocal, result.CommentsToRemote = toLocal, toRemote
+
+	if local.Version > remote.Version {
+		result.Remote.Version = local.Version
+	} else {
+		result.Local.Version = remote.Version
+	}
+	updatedAt := local.UpdatedAt
+	if isLater(remote.UpdatedAt, local.UpdatedAt) {
+		updatedAt = remote.UpdatedAt
+	}
+	if !sameIssue(result.Local, local) {
+		result.Local.UpdatedAt = updatedAt
+	}
+	if !sameIssue(result.Remote, remote) {
+		result.Remote.UpdatedAt = updatedAt
+	}
+	return result
+}
+
+// mergeValue は DD-SYNC-002 の1つの項目の三方向マージを行い、統合後の値と競合の有無を返す。
+func mergeValue(base any, hasBase bool, local, remote any) (any, bool) {
+	if sameValue(local, remote) {
+		return local, false
+	}
+	if hasBase {
+		if sameValue(local, base) {
+			return remote, false
+		}
+		if sameValue(remote, base) {
+			return local, false
+		}
+	}
+	return nil, true
+}
+
+// mergeCustomFields は DD-SYNC-002 の custom_fields をキーごとに三方向マージし、result へ反映する。
+// 無いキーは nil として比べ、統合後に nil となったキーは削除する。
+func mergeCustomFields(base *issue.Issue, local, remote issue.Issue, result *mergeResult) {
+	var baseFields map[string]any
+	if base != nil {
+		baseFields = base.CustomFields
+	}
+	keys := map[string]bool{}
+	for _, fields := range []map[string]any{baseFields, local.CustomFields, remote.CustomFields} {
+		for key := range fields {
+			keys[key] = true
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+	names := make([]string, 0, len(keys))
+	for key := range keys {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	localFields := map[string]any{}
+	remoteFields := map[string]any{}
+	for _, key := range names {
+		value, conflict := mergeValue(baseFields[key], base != nil, local.CustomFields[key], remote.CustomFields[key])
+		if conflict {
+			result.Conflicts = append(result.Conflicts, Conflict{
+				Field:  customFieldPrefix + key,
+				Base:   baseFields[key],
+				Local:  local.CustomFields[key],
+				Remote: remote.CustomFields[key],
+			})
+			setField(localFields, key, local.CustomFields[key])
+			setField(remoteFields, key, remote.CustomFields[key])
+			continue
+		}
+		setField(localFields, key, value)
+		setField(remoteFields, key, value)
+	}
+	result.Local.CustomFields = nilIfEmpty(localFields)
+	result.Remote.CustomFields = nilIfEmpty(remoteFields)
+}
+
+// setField は DD-SYNC-002 の custom_fields へ値を設定する。nil は項目が無いことを表すため設定しない。
+func setField(fields map[string]any, key string, value any) {
+	if value != nil {
+		fields[key] = value
+	}
+}
+
+// nilIfEmpty は DD-SYNC-002 の空の custom_fields を nil とし、保存時に項目を省略させる。
+func nilIfEmpty(fields map[string]any) map[string]any {
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// mergeComments は DD-SYNC-002 のコメントを和集合として統合し、作成日時の順に並べる。
+// 前回の同期時点にあり片側で削除されたコメントは除く。相手側から加わるコメントの数もあわせて返す。
+func mergeComments(base *issue.Issue, local, remote []issue.Comment) ([]issue.Comment, int, int) {
+	inBase := map[string]bool{}
+	if base != nil {
+		for _, comment := range base.Comments {
+			inBase[comment.CommentID] = true
+		}
+	}
+	inLocal := commentIDs(local)
+	inRemote := commentIDs(remote)
+	merged := make([]issue.Comment, 0, len(local)+len(remote))
+	toLocal, toRemote := 0, 0
+	for _, comment := range local {
+		if inRemote[comment.CommentID] {
+			merged = append(merged, comment)
+			continue
+		}
+		if inBase[comment.CommentID] {
+			continue
+		}
+		merged = append(merged, comment)
+		toRemote++
+	}
+	for _, comment := range remote {
+		if inLocal[comment.CommentID] || inBase[comment.CommentID] {
+			continue
+		}
+		merged = append(merged, comment)
+		toLocal++
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return isLater(merged[j].CreatedAt, merged[i].CreatedAt)
+	})
+	return merged, toLocal, toRemote
+}
+
+// commentIDs は DD-SYNC-002 のコメントIDの集合を返す。
+func commentIDs(comments []issue.Comment) map[string]bool {
+	ids := make(map[string]bool, len(comments))
+	for _, comment := range comments {
+		ids[comment.CommentID] = true
+	}
+	return ids
+}
+
+// isLater は DD-SYNC-002 の日時 a が b より後かを返す。DD-DATA-002 の日時表記として解析できない場合は文字列として比べる。
+func isLater(a, b string) bool {
+	at, aErr := time.Parse(time.RFC3339, a)
+	bt, bErr := time.Parse(time.RFC3339, b)
+	if aErr != nil || bErr != nil {
+		return a > b
+	}
+	return at.After(bt)
+}
+
+// sameIssue は DD-SYNC-002 の2つの課題の内容が同じかを返す。空のタグ・custom_fields・コメントは無い場合と同じとみなす。
+func sameIssue(a, b issue.Issue) bool {
+	return sameValue(normalizeIssue(a), normalizeIssue(b))
+}
+
+// normalizeIssue は DD-SYNC-002 の比較のため、空の一覧・オブジェクトの表し方をそろえた課題を返す。
+func normalizeIssue(item issue.Issue) issue.Issue {
+	if len(item.Tags) == 0 {
+		item.Tags = nil
+	}
+	if len(item.CustomFields) == 0 {
+		item.CustomFields = nil
+	}
+	if item.Comments == nil {
+		item.Comments = []issue.Comment{}
+	}
+	return item
+}
+
+// sameValue は DD-SYNC-002 の2つの値が JSON として同じかを返す。nil と空の一覧は同じとみなす。
+func sameValue(a, b any) bool {
+	aData, aErr := json.Marshal(a)
+	bData, bErr := json.Marshal(b)
+	if aErr != nil || bErr != nil {
+		return false
+	}
+	return bytes.Equal(normalizeEmpty(aData), normalizeEmpty(bData))
+}
+
+// normalizeEmpty は DD-SYNC-002 の比較で空の一覧・空のオブジェクトを null として扱うよう置き換える。
+func normalizeEmpty(data []byte) []byte {
+	if string(data) == "[]" || string(data) == "{}" {
+		return []byte("null")
+	}
+	return data
+}
@@ -0,0 +1,142 @@
+// merge_test.go は同じ課題の2つの版の三方向マージのテストを行う。
+package projectsync
+
+import (
+	"testing"
+
+	"ratta/internal/domain/issue"
+)
+
+// baseIssue はテスト用の前回の同期時点の課題を返す。
+func baseIssue() issue.Issue {
+	return issue.Issue{
+		Version:      2,
+		IssueID:      "abcdefgh",
+		Category:     "cat",
+		Title:        "title",
+		Description:  "desc",
+		Status:       issue.StatusOpen,
+		Priority:     issue.PriorityMedium,
+		UpdatedAt:    "2024-01-10T09:00:00+09:00",
+		DueDate:      "2024-02-01",
+		CustomFields: map[string]any{"team": "a", "rank": "1"},
+		Comments: []issue.Comment{
+			{CommentID: "c1", Body: "first", CreatedAt: "2024-01-10T09:00:00+09:00"},
+			{CommentID: "c2", Body: "second", CreatedAt: "2024-01-10T10:00:00+09:00"},
+		},
+	}
+}
+
+// cloneIssue はテスト用に一覧・マップを複製した課題を返す。
+func cloneIssue(item issue.Issue) issue.Issue {
+	item.Comments = append([]issue.Comment(nil), item.Comments...)
+	fields := make(map[string]any, len(item.CustomFields))
+	for key, value := range item.CustomFields {
+		fields[key] = value
+	}
+	item.CustomFields = fields
+	return item
+}
+
+func TestMergeIssue_TakesOneSidedChanges(t *testing.T) {
+	// 片側のみで変更された項目とコメントを取り込み、変更した側の updated_at を新しい方にそろえることを確認する。
+	base := baseIssue()
+	local := cloneIssue(base)
+	local.Title = "local title"
+	local.CustomFields["team"] = "b"
+	local.Comments = append(local.Comments, issue.Comment{CommentID: "c3", Body: "local", CreatedAt: "2024-01-11T09:00:00+09:00"})
+	local.UpdatedAt = "2024-01-11T09:00:00+09:00"
+	remote := cloneIssue(base)
+	remote.Status = issue.StatusWorking
+	delete(remote.CustomFields, "rank")
+	remote.Comments = append(remote.Comments[1:], issue.Comment{CommentID: "c4", Body: "remote", CreatedAt: "2024-01-10T12:00:00+09:00"})
+	remote.UpdatedAt = "2024-01-12T09:00:00+09:00"
+
+	merged := mergeIssue(&base, local, remote)
+
+	if len(merged.Conflicts) != 0 {
+		t.Fatalf("unexpected conflicts: %+v", merged.Conflicts)
+	}
+	for _, item := range []issue.Issue{merged.Local, merged.Remote} {
+		if item.Title != "local title" || item.Status != issue.StatusWorking || item.UpdatedAt != "2024-01-12T09:00:00+09:00" {
+			t.Fatalf("unexpected merged issue: %+v", item)
+		}
+		if len(item.CustomFields) != 1 || item.CustomFields["team"] != "b" {
+			t.Fatalf("unexpected custom fields: %+v", item.CustomFields)
+		}
+		ids := ""
+		for _, comment := range item.Comments {
+			ids += comment.CommentID + ","
+		}
+		if ids != "c2,c4,c3," {
+			t.Fatalf("unexpected comments: %s", ids)
+		}
+	}
+	if merged.CommentsToLocal != 1 || merged.CommentsToRemote != 1 {
+		t.Fatalf("unexpected comment counts: %d %d", merged.CommentsToLocal, merged.CommentsToRemote)
+	}
+}
+
+func TestMergeIssue_ReportsConflictingEdits(t *testing.T) {
+	// 両側で異なる値に変更した項目は競合とし、それぞれの値のまま残すことを確認する。
+	base := baseIssue()
+	local := cloneIssue(base)
+	local.Priority = issue.PriorityHigh
+	local.CustomFields["team"] = "b"
+	remote := cloneIssue(base)
+	remote.Priority = issue.PriorityLow
+	remote.CustomFields["team"] = "c"
+	remote.Description = "remote desc"
+
+	merged := mergeIssue(&base, local, remote)
+
+	if len(merged.Conflicts) != 2 {
+		t.Fatalf("expected 2 conflicts, got %+v", merged.Conflicts)
+	}
+	priority := merged.Conflicts[0]
+	if priority.Field != "priority" || priority.Base != issue.PriorityMedium || priority.Local != issue.PriorityHigh || priority.Remote != issue.PriorityLow {
+		t.Fatalf("unexpected conflict: %+v", priority)
+	}
+	if merged.Conflicts[1].Field != "custom_fields.team" {
+		t.Fatalf("unexpected conflict: %+v", merged.Conflicts[1])
+	}
+	if merged.Local.Priority != issue.PriorityHigh || merged.Remote.Priority != issue.PriorityLow {
+		t.Fatalf("expected each side to keep its value: %s %s", merged.Local.Priority, merged.Remote.Priority)
+	}
+	if merged.Local.Description != "remote desc" || merged.Local.CustomFields["team"] != "b" || merged.Remote.CustomFields["team"] != "c" {
+		t.Fatalf("unexpected merged issues: %+v %+v", merged.Local, merged.Remote)
+	}
+}
+
+func TestMergeIssue_WithoutBaseTreatsDifferencesAsConflicts(t *testing.T) {
+	// 前回の同期の記録が無い場合は、値の異なる項目をすべて競合とし、コメントは和集合とすることを確認する。
+	local := baseIssue()
+	remote := cloneIssue(local)
+	remote.Title = "other"
+	remote.Comments = remote.Comments[:1]
+
+	merged := mergeIssue(nil, local, remote)
+
+	if len(merged.Conflicts) != 1 || merged.Conflicts[0].Field != "title" || merged.Conflicts[0].Base != nil {
+		t.Fatalf("unexpected conflicts: %+v", merged.Conflicts)
+	}
+	if len(merged.Remote.Comments) != 2 || merged.CommentsToRemote != 1 {
+		t.Fatalf("expected comment union, got %+v", merged.Remote.Comments)
+	}
+	if !sameIssue(merged.Local, local) {
+		t.Fatalf("expected local to be unchanged: %+v", merged.Local)
+	}
+}
+
+func TestSameIssue_TreatsEmptyAsMissing(t *testing.T) {
+	// 空のタグ・custom_fields・コメントは無い場合と同じとみなすことを確認する。
+	a := issue.Issue{IssueID: "abcdefgh"}
+	b := issue.Issue{IssueID: "abcdefgh", Tags: []string{}, CustomFields: map[string]any{}, Comments: []issue.Comment{}}
+	if !sameIssue(a, b) {
+		t.Fatal("expected empty collections to compare equal")
+	}
+	b.Tags = []string{"x"}
+	if sameIssue(a, b) {
+		t.Fatal("expected different tags to differ")
+	}
+}
@@ -0,0 +1,364 @@
+// Package projectsync は同じプロジェクトの2つの複製 (例えば Vendor の手元の複製と共有フォルダ) を比べて課題を同期する処理を担い、
+// 競合した項目の解決は扱わない。競合は報告に含め、利用者がどちらかのプロジェクトで値をそろえてから再び同期する。
+package projectsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"ratta/internal/app/categoryops"
+	"ratta/internal/app/issueexport"
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/atomicwrite"
+	"ratta/internal/infra/schema"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// Action* は DD-SYNC-001 の課題ごとの同期の結果を表す。
+const (
+	ActionCopiedToLocal  = "copied_to_local"
+	ActionCopiedToRemote = "copied_to_remote"
+	ActionMerged         = "merged"
+	ActionConflict       = "conflict"
+	ActionFailed         = "failed"
+)
+
+// Options は DD-SYNC-001 の同期の設定を表す。DryRun は保存せずに結果のみを求めることを表す。
+type Options struct {
+	DryRun bool
+}
+
+// IssueResult は DD-SYNC-001 の1つの課題の同期の結果を表す。
+// LocalUpdated・RemoteUpdated はそれぞれのプロジェクトの課題JSONを書き換えた (dry-run では書き換える) ことを表す。
+// CommentsToLocal・CommentsToRemote は相手側から加えたコメントの数、Attachments は複製した添付ファイルの数を表す。
+// Message は失敗した場合の理由を表す。
+type IssueResult struct {
+	Category         string
+	IssueID          string
+	Action           string
+	LocalUpdated     bool
+	RemoteUpdated    bool
+	CommentsToLocal  int
+	CommentsToRemote int
+	Attachments      int
+	Conflicts        []Conflict
+	Message          string
+}
+
+// Result は DD-SYNC-001 の同期の結果を表す。Issues には変更・競合・失敗のあった課題のみを含め、Unchanged はそれ以外の課題の数を表す。
+type Result struct {
+	DryRun     bool
+	LocalRoot  string
+	RemoteRoot string
+	Copied     int
+	Merged     int
+	Conflicted int
+	Failed     int
+	Unchanged  int
+	Issues     []IssueResult
+}
+
+// side は DD-SYNC-001 の同期する一方のプロジェクトを表す。
+type side struct {
+	root       string
+	issues     *issueops.Service
+	categories *categoryops.Service
+	created    map[string]bool
+}
+
+// syncer は DD-SYNC-001 の同期中の状態を表す。
+type syncer struct {
+	local  side
+	remote side
+	base   baseStore
+	mode   mod.Mode
+	opts   Options
+}
+
+// Sync は DD-SYNC-001 の2つのプロジェクトの課題を同期する。
+// 目的: オフラインで作業した複製と共有フォルダのように、別々に変更された同じプロジェクトの課題を突き合わせて統合する。
+// 入力: ctx は中断通知、localRoot は同期を実行するプロジェクト、remoteRoot は相手のプロジェクト、validator は課題の検証器、
+// currentMode は操作モード、opts は同期の設定。
+// 出力: Result とエラー。
+// エラー: 同じプロジェクトを指定した場合、相手のプロジェクトが無い場合、課題の走査に失敗した場合、中断された場合に返す。
+// 課題ごとの保存の失敗、終状態の課題の変更や操作モードで許されない状態の遷移 (DD-BE-003) は Result.Issues に含め、残りの課題の処理を続ける。
+// 副作用: 両方のプロジェクトの課題JSON・添付ファイルを作成・上書きし、カテゴリが無ければ作成する。
+// localRoot の .ratta/sync 配下へ次回の同期の基準とする課題を記録する。dry-run ではいずれも行わない。
+// 並行性: 両方のプロジェクトへの同時書き込みは呼び出し側で書き込み用ロックを取得して排他する。
+// 不変条件: 片側にのみある課題は相手へ複製し、削除は伝播しない。両側にある課題は前回の同期時点の版を基準に三方向マージし、
+// コメントは和集合とする。競合した項目はそれぞれの値のまま残し、その課題の基準は更新しないため、値をそろえるまで競合として報告する。
+// 関連DD: DD-SYNC-001, DD-SYNC-002, DD-SYNC-003, DD-REDMINE-002
+func Sync(ctx context.Context, localRoot, remoteRoot string, validator *schema.Validator, currentMode mod.Mode, opts Options) (Result, error) {
+	local, err := resolveRoot(localRoot)
+	if err != nil {
+		return Result{}, err
+	}
+	remote, err := resolveRoot(remoteRoot)
+	if err != nil {
+		return Result{}, err
+	}
+	if local == remote {
+		return Result{}, apperr.New(apperr.ErrValidation, "cannot synchronize a project with itself")
+	}
+	localIssues, err := collect(ctx, localRoot)
+	if err != nil {
+		return Result{}, err
+	}
+	remoteIssues, err := collect(ctx, remoteRoot)
+	if err != nil {
+		return Result{}, err
+	}
+	s := syncer{
+		local:  newSide(localRoot, validator),
+		remote: newSide(remoteRoot, validator),
+		base:   baseStore{dir: BaseDir(localRoot, remote)},
+		mode:   currentMode,
+		opts:   opts,
+	}
+
+	keys := make([]string, 0, len(localIssues)+len(remoteIssues))
+	for key := range localIssues {
+		keys = append(keys, key)
+	}
+	for key := range remoteIssues {
+		if _, ok := localIssues[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	result := Result{DryRun: opts.DryRun, LocalRoot: localRoot, RemoteRoot: remoteRoot, Issues: []IssueResult{}}
+	for _, key := range keys {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return Result{}, ctxErr
+		}
+		localItem, inLocal := localIssues[key]
+		remoteItem, inRemote := remoteIssues[key]
+		var item IssueResult
+		switch {
+		case !inRemote:
+			item = s.copyIssue(localItem, s.local, s.remote, ActionCopiedToRemote)
+		case !inLocal:
+			item = s.copyIssue(remoteItem, s.remote, s.local, ActionCopiedToLocal)
+		default:
+			item = s.mergeIssue(localItem, remoteItem)
+		}
+		switch item.Action {
+		case "":
+			result.Unchanged++
+			continue
+		case ActionCopiedToLocal, ActionCopiedToRemote:
+			result.Copied++
+		case ActionMerged:
+			result.Merged++
+		case ActionConflict:
+			result.Conflicted++
+		default:
+			result.Failed++
+		}
+		result.Issues = append(result.Issues, item)
+	}
+	return result, nil
+}
+
+// resolveRoot は DD-SYNC-001 のプロジェクトルートをシンボリックリンクを解決した絶対パスへ変換する。
+func resolveRoot(root string) (string, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("resolve project root: %w", err)
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", apperr.WithPath(fmt.Errorf("resolve project root: %w", err), root, apperr.IOHint(err, ""))
+	}
+	return resolved, nil
+}
+
+// collect は DD-SYNC-001 のプロジェクトのすべての課題を「カテゴリ名/課題ID」ごとに読み込む。
+func collect(ctx context.Context, root string) (map[string]issue.Issue, error) {
+	items, _, err := issueexport.Collect(ctx, root, issueexport.Filter{})
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]issue.Issue, len(items))
+	for _, item := range items {
+		byKey[item.Category+"/"+item.IssueID] = item
+	}
+	return byKey, nil
+}
+
+// newSide は DD-SYNC-001 の同期する一方のプロジェクトのサービスを用意する。
+func newSide(root string, validator *schema.Validator) side {
+	return side{
+		root:       root,
+		issues:     issueops.NewService(root, validator),
+		categories: categoryops.NewService(root),
+		created:    map[string]bool{},
+	}
+}
+
+// copyIssue は DD-SYNC-001 の片側にのみある課題を、添付ファイルとともに相手へ複製する。
+func (s *syncer) copyIssue(item issue.Issue, from, to side, action string) IssueResult {
+	result := IssueResult{Category: item.Category, IssueID: item.IssueID, Action: action}
+	if action == ActionCopiedToLocal {
+		result.LocalUpdated = true
+		result.CommentsToLocal = len(item.Comments)
+	} else {
+		result.RemoteUpdated = true
+		result.CommentsToRemote = len(item.Comments)
+	}
+	if s.opts.DryRun {
+		return result
+	}
+	copied, err := s.save(item, from, to)
+	result.Attachments = copied
+	if err == nil {
+		err = s.base.save(item)
+	}
+	if err != nil {
+		return failed(result, err)
+	}
+	return result
+}
+
+// mergeIssue は DD-SYNC-002 の両側にある課題を三方向マージし、変わった側へ保存する。
+// 変わる側の課題が終状態の場合や、操作モードで許されない状態へ変わる場合は issueops.CheckMerge に従い、どちらの側も保存せず失敗とする。
+func (s *syncer) mergeIssue(local, remote issue.Issue) IssueResult {
+	result := IssueResult{Category: local.Category, IssueID: local.IssueID}
+	base, err := s.base.load(local.Category, local.IssueID)
+	if err != nil {
+		return failed(result, err)
+	}
+	merged := mergeIssue(base, local, remote)
+	result.Conflicts = merged.Conflicts
+	result.CommentsToLocal, result.CommentsToRemote = merged.CommentsToLocal, merged.CommentsToRemote
+	result.LocalUpdated = !sameIssue(merged.Local, local)
+	result.RemoteUpdated = !sameIssue(merged.Remote, remote)
+	switch {
+	case len(merged.Conflicts) > 0:
+		result.Action = ActionConflict
+	case result.LocalUpdated || result.RemoteUpdated:
+		result.Action = ActionMerged
+	}
+	// SaveImportedIssue は状態の規則を適用しないため、上書きする側ごとに課題の更新と同じ規則を保存 (dry-run では報告) の前に確かめる。
+	if result.LocalUpdated {
+		if err := issueops.CheckMerge(local, merged.Local, s.mode); err != nil {
+			return failed(result, err)
+		}
+	}
+	if result.RemoteUpdated {
+		if err := issueops.CheckMerge(remote, merged.Remote, s.mode); err != nil {
+			return failed(result, err)
+		}
+	}
+	if s.opts.DryRun {
+		return result
+	}
+	if result.LocalUpdated {
+		copied, saveErr := s.save(merged.Local, s.remote, s.local)
+		result.Attachments += copied
+		if saveErr != nil {
+			return failed(result, saveErr)
+		}
+	}
+	if result.RemoteUpdated {
+		copied, saveErr := s.save(merged.Remote, s.local, s.remote)
+		result.Attachments += copied
+		if saveErr != nil {
+			return failed(result, saveErr)
+		}
+	}
+	// 競合した課題は基準を更新しない。更新すると次回の同期で片側の値が変更として扱われ、競合が失われる。
+	if len(merged.Conflicts) == 0 && (base == nil || !sameIssue(*base, merged.Local)) {
+		if saveErr := s.base.save(merged.Local); saveErr != nil {
+			return failed(result, saveErr)
+		}
+	}
+	return result
+}
+
+// save は DD-SYNC-001 の課題を to のプロジェクトへ保存する。
+// カテゴリが無ければ作成し、コメントが参照する添付ファイルのうち to に無いものを from から複製してから課題JSONを書き込む。
+// 複製した添付ファイルの数を返す。
+func (s *syncer) save(item issue.Issue, from, to side) (int, error) {
+	if err := s.ensureCategory(to, item.Category); err != nil {
+		return 0, err
+	}
+	copied, err := copyAttachments(item, from.root, to.root)
+	if err != nil {
+		return copied, err
+	}
+	if _, err := to.issues.SaveImportedIssue(item.Category, s.mode, item); err != nil {
+		return copied, err
+	}
+	return copied, nil
+}
+
+// ensureCategory は DD-SYNC-001 の to のプロジェクトにカテゴリが無ければ作成する。
+// カテゴリの作成は GUI と同じく Contractor モードに限るため、Vendor モードでは相手に無いカテゴリの課題は失敗として報告する。
+func (s *syncer) ensureCategory(to side, category string) error {
+	if to.created[category] {
+		return nil
+	}
+	info, err := os.Stat(filepath.Join(to.root, category))
+	if err == nil && info.IsDir() {
+		to.created[category] = true
+		return nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("stat category: %w", err)
+	}
+	if _, err := to.categories.CreateCategory(category, s.mode); err != nil {
+		return fmt.Errorf("create category %s: %w", category, err)
+	}
+	to.created[category] = true
+	return nil
+}
+
+// copyAttachments は DD-SYNC-001 のコメントが参照する添付ファイルのうち toRoot に無いものを fromRoot から複製し、複製した数を返す。
+// fromRoot にも無い添付ファイルは複製しない。
+func copyAttachments(item issue.Issue, fromRoot, toRoot string) (int, error) {
+	copied := 0
+	for _, comment := range item.Comments {
+		for _, attachment := range comment.Attachments {
+			rel := filepath.FromSlash(attachment.RelativePath)
+			if !filepath.IsLocal(rel) {
+				return copied, apperr.Errorf(apperr.ErrValidation, "invalid attachment path: %s", attachment.RelativePath)
+			}
+			target := filepath.Join(toRoot, item.Category, rel)
+			if _, err := os.Stat(target); err == nil {
+				continue
+			}
+			// #nosec G304 -- カテゴリ配下に限定した添付の相対パスのみを読む。
+			data, err := os.ReadFile(filepath.Join(fromRoot, item.Category, rel))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return copied, fmt.Errorf("read attachment: %w", err)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+				return copied, fmt.Errorf("create attachment dir: %w", err)
+			}
+			if err := atomicwrite.WriteFile(target, data); err != nil {
+				return copied, fmt.Errorf("write attachment: %w", err)
+			}
+			copied++
+		}
+	}
+	return copied, nil
+}
+
+// failed は DD-SYNC-001 の課題の同期の失敗を結果へ記録する。
+func failed(result IssueResult, err error) IssueResult {
+	result.Action = ActionFailed
+	result.Message = err.Error()
+	return result
+}
@@ -0,0 +1,187 @@
+// projectsync_test.go は2つのプロジェクトの課題の同期と、添付の複製・競合の報告・dry-run のテストを行う。
+package projectsync
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/schema"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// newRoots はテスト用に検証器と、それぞれカテゴリ "cat" を持つ同期元と相手のプロジェクトを用意する。
+func newRoots(t *testing.T) (*schema.Validator, string, string) {
+	t.Helper()
+	validator, err := schema.NewValidatorFromDir(filepath.Join("..", "..", "..", "schemas"))
+	if err != nil {
+		t.Fatalf("NewValidatorFromDir error: %v", err)
+	}
+	local, remote := t.TempDir(), t.TempDir()
+	for _, root := range []string{local, remote} {
+		if err := os.MkdirAll(filepath.Join(root, "cat"), 0o750); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	return validator, local, remote
+}
+
+// editIssue はテスト用に課題を読み込んで変更し、そのまま保存する。
+func editIssue(t *testing.T, service *issueops.Service, issueID string, edit func(*issue.Issue)) {
+	t.Helper()
+	detail, err := service.GetIssue("cat", issueID)
+	if err != nil {
+		t.Fatalf("GetIssue error: %v", err)
+	}
+	edit(&detail.Issue)
+	if _, err := service.SaveImportedIssue("cat", mod.ModeVendor, detail.Issue); err != nil {
+		t.Fatalf("SaveImportedIssue error: %v", err)
+	}
+}
+
+// runSync はテスト用に同期を実行する。
+func runSync(t *testing.T, validator *schema.Validator, local, remote string, dryRun bool) Result {
+	t.Helper()
+	result, err := Sync(context.Background(), local, remote, validator, mod.ModeVendor, Options{DryRun: dryRun})
+	if err != nil {
+		t.Fatalf("Sync error: %v", err)
+	}
+	return result
+}
+
+func TestSync_CopiesMergesAndReportsConflicts(t *testing.T) {
+	// 片側のみの課題は添付とともに複製し、以後の変更は統合し、両側で異なる変更は競合として値を残し続けることを確認する。
+	validator, local, remote := newRoots(t)
+	localService := issueops.NewService(local, validator)
+	remoteService := issueops.NewService(remote, validator)
+	created, err := localService.CreateIssue("cat", mod.ModeVendor, issueops.IssueCreateInput{
+		Title: "sync me", Description: "desc", DueDate: "2024-02-01", Priority: issue.PriorityMedium,
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	issueID := created.Issue.IssueID
+	withAttachment, err := localService.AddComment("cat", issueID, mod.ModeVendor, issueops.CommentCreateInput{
+		Body: "see log", AuthorName: "suzuki",
+		Attachments: []issueops.CommentAttachmentInput{{OriginalName: "log.txt", Data: []byte("log"), MimeType: "text/plain"}},
+	})
+	if err != nil {
+		t.Fatalf("AddComment error: %v", err)
+	}
+	attachment := withAttachment.Issue.Comments[0].Attachments[0]
+
+	dry := runSync(t, validator, local, remote, true)
+	if !dry.DryRun || dry.Copied != 1 || dry.Issues[0].Action != ActionCopiedToRemote {
+		t.Fatalf("unexpected dry run: %+v", dry)
+	}
+	if _, err := remoteService.GetIssue("cat", issueID); err == nil {
+		t.Fatal("expected dry run not to write")
+	}
+
+	first := runSync(t, validator, local, remote, false)
+	if first.Copied != 1 || first.Issues[0].Attachments != 1 || first.Issues[0].Message != "" {
+		t.Fatalf("unexpected first sync: %+v", first)
+	}
+	data, err := os.ReadFile(filepath.Join(remote, "cat", filepath.FromSlash(attachment.RelativePath)))
+	if err != nil || string(data) != "log" {
+		t.Fatalf("expected attachment to be copied: %q %v", data, err)
+	}
+
+	editIssue(t, remoteService, issueID, func(item *issue.Issue) { item.Title = "renamed remotely" })
+	if _, err := localService.AddComment("cat", issueID, mod.ModeVendor, issueops.CommentCreateInput{Body: "local note", AuthorName: "sato"}); err != nil {
+		t.Fatalf("AddComment error: %v", err)
+	}
+	second := runSync(t, validator, local, remote, false)
+	if second.Merged != 1 || !second.Issues[0].LocalUpdated || !second.Issues[0].RemoteUpdated || second.Issues[0].CommentsToRemote != 1 {
+		t.Fatalf("unexpected second sync: %+v", second)
+	}
+	for _, service := range []*issueops.Service{localService, remoteService} {
+		detail, err := service.GetIssue("cat", issueID)
+		if err != nil || detail.Issue.Title != "renamed remotely" || len(detail.Issue.Comments) != 2 || detail.IsSchemaInvalid {
+			t.Fatalf("unexpected merged issue: %+v %v", detail, err)
+		}
+	}
+	if unchanged := runSync(t, validator, local, remote, false); unchanged.Unchanged != 1 || len(unchanged.Issues) != 0 {
+		t.Fatalf("expected nothing to change, got %+v", unchanged)
+	}
+
+	editIssue(t, localService, issueID, func(item *issue.Issue) { item.Priority = issue.PriorityHigh })
+	editIssue(t, remoteService, issueID, func(item *issue.Issue) { item.Priority = issue.PriorityLow })
+	for range 2 {
+		conflict := runSync(t, validator, local, remote, false)
+		if conflict.Conflicted != 1 || len(conflict.Issues[0].Conflicts) != 1 || conflict.Issues[0].Conflicts[0].Field != "priority" {
+			t.Fatalf("unexpected conflict sync: %+v", conflict)
+		}
+	}
+	localDetail, _ := localService.GetIssue("cat", issueID)
+	remoteDetail, _ := remoteService.GetIssue("cat", issueID)
+	if localDetail.Issue.Priority != issue.PriorityHigh || remoteDetail.Issue.Priority != issue.PriorityLow {
+		t.Fatalf("expected each side to keep its value: %s %s", localDetail.Issue.Priority, remoteDetail.Issue.Priority)
+	}
+}
+
+func TestSync_ReportsMissingCategoryAsVendor(t *testing.T) {
+	// Vendor モードでは相手に無いカテゴリを作成できないため、その課題を失敗として報告し、他の課題の同期は続けることを確認する。
+	validator, local, remote := newRoots(t)
+	if err := os.MkdirAll(filepath.Join(local, "extra"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	service := issueops.NewService(local, validator)
+	for _, category := range []string{"cat", "extra"} {
+		if _, err := service.CreateIssue(category, mod.ModeVendor, issueops.IssueCreateInput{
+			Title: category, Description: "desc", DueDate: "2024-02-01", Priority: issue.PriorityLow,
+		}); err != nil {
+			t.Fatalf("CreateIssue error: %v", err)
+		}
+	}
+	result := runSync(t, validator, local, remote, false)
+	if result.Copied != 1 || result.Failed != 1 || result.Issues[1].Category != "extra" || result.Issues[1].Action != ActionFailed {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestSync_AppliesStatusRulesAsVendor(t *testing.T) {
+	// 統合した状態は SaveImportedIssue で保存するため、相手が Closed にした課題を Vendor モードの同期で Closed にせず、
+	// 終状態の課題へコメントも加えずに失敗として報告することを確認する (dry-run でも同じく報告する)。
+	validator, local, remote := newRoots(t)
+	localService := issueops.NewService(local, validator)
+	remoteService := issueops.NewService(remote, validator)
+	created, err := localService.CreateIssue("cat", mod.ModeVendor, issueops.IssueCreateInput{
+		Title: "close me", Description: "desc", DueDate: "2024-02-01", Priority: issue.PriorityMedium,
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	issueID := created.Issue.IssueID
+	runSync(t, validator, local, remote, false)
+	editIssue(t, remoteService, issueID, func(item *issue.Issue) { item.Status = issue.StatusClosed })
+	if _, err := localService.AddComment("cat", issueID, mod.ModeVendor, issueops.CommentCreateInput{Body: "local note", AuthorName: "sato"}); err != nil {
+		t.Fatalf("AddComment error: %v", err)
+	}
+
+	for _, dryRun := range []bool{true, false} {
+		result := runSync(t, validator, local, remote, dryRun)
+		if result.Failed != 1 || result.Issues[0].Action != ActionFailed {
+			t.Fatalf("expected status rule failure (dry run %v): %+v", dryRun, result)
+		}
+	}
+	localDetail, _ := localService.GetIssue("cat", issueID)
+	remoteDetail, _ := remoteService.GetIssue("cat", issueID)
+	if localDetail.Issue.Status != issue.StatusOpen || len(remoteDetail.Issue.Comments) != 0 {
+		t.Fatalf("expected neither side to change: %s %d", localDetail.Issue.Status, len(remoteDetail.Issue.Comments))
+	}
+}
+
+func TestSync_RejectsSameProject(t *testing.T) {
+	// 同じプロジェクトどうしの同期は ErrValidation とすることを確認する。
+	validator, local, _ := newRoots(t)
+	if _, err := Sync(context.Background(), local, local+string(filepath.Separator)+".", validator, mod.ModeVendor, Options{}); !errors.Is(err, apperr.ErrValidation) {
+		t.Fatalf("expected ErrValidation, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+// export.go は課題を Redmine の CSV 取り込みで読める課題一覧と注記の CSV へ出力する処理を担う。
+package redmine
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"ratta/internal/app/issueexport"
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/atomicwrite"
+)
+
+// IssueColumns は DD-REDMINE-001 の出力する課題一覧の CSV の列見出しを出力順に表す。見出しは Redmine の英語の列名に合わせる。
+var IssueColumns = []string{"#", "ratta ID", "Subject", "Description", "Status", "Priority", "Assignee", "Category", "Due date", "Created", "Updated"}
+
+// JournalColumns は DD-REDMINE-001 の出力する注記の CSV の列見出しを出力順に表す。
+var JournalColumns = []string{"#", "ratta ID", "Author", "Created", "Notes"}
+
+// statusToRedmine は DD-REDMINE-001 の ratta のステータスと Redmine の既定のステータス名の対応を表す。
+// Redmine の既定に無いステータスは ratta の名前のまま出力する。
+var statusToRedmine = map[issue.Status]string{
+	issue.StatusOpen:    "New",
+	issue.StatusWorking: "In Progress",
+}
+
+// priorityToRedmine は DD-REDMINE-001 の ratta の優先度と Redmine の既定の優先度名の対応を表す。
+var priorityToRedmine = map[issue.Priority]string{
+	issue.PriorityLow:    "Low",
+	issue.PriorityMedium: "Normal",
+	issue.PriorityHigh:   "High",
+}
+
+// ExportResult は DD-REDMINE-001 の出力結果を表す。
+// Issues は出力した課題の数、Journals は出力した注記の数、Skipped は解析できず出力しなかった課題JSONの数を表す。
+type ExportResult struct {
+	IssuesPath   string
+	JournalsPath string
+	Issues       int
+	Journals     int
+	Skipped      int
+}
+
+// Export は DD-REDMINE-001 の課題一覧と注記の Redmine 向けの CSV の出力を行う。
+// 目的: Redmine を使う取引先が、課題とコメントを Redmine の CSV 取り込みで読み込めるようにする。
+// 入力: ctx は中断通知、root はプロジェクトルート、filter は出力対象の条件、issuesPath は課題一覧の出力先、
+// journalsPath は注記の出力先 (空の場合は出力しない)。
+// 出力: ExportResult とエラー。
+// エラー: 出力先が空の場合、条件が不正な場合、カテゴリが存在しない場合、走査・書き込みに失敗した場合、中断された場合に返す。
+// 副作用: issuesPath・journalsPath へ UTF-8 (BOM 無し) の CSV を書き込む。プロジェクト配下のファイルは変更しない。
+// 並行性: 読み取りのみのため課題操作と同時に実行してよいが、実行中の変更が含まれるかは保証しない。
+// 不変条件: 課題の並びは DD-EXPORT-001 と同じとする。Redmine から取り込んだ課題は "#" に Redmine の課題番号を出力し、
+// それ以外は空とする。注記はコメントごとに1行とし、添付はファイル名のみを本文の末尾に記す。
+// 関連DD: DD-REDMINE-001, DD-EXPORT-001
+func Export(ctx context.Context, root string, filter issueexport.Filter, issuesPath, journalsPath string) (ExportResult, error) {
+	if issuesPath == "" {
+		return ExportResult{}, apperr.New(apperr.ErrValidation, "export path is required")
+	}
+	issues, skipped, err := issueexport.Collect(ctx, root, filter)
+	if err != nil {
+		return ExportResult{}, err
+	}
+	issueRows := make([][]string, 0, len(issues))
+	var journalRows [][]string
+	for _, item := range issues {
+		number := redmineID(item)
+		issueRows = append(issueRows, []string{
+			number,
+			item.IssueID,
+			item.Title,
+			item.Description,
+			exportStatus(item.Status),
+			priorityToRedmine[item.Priority],
+			item.Assignee,
+			item.Category,
+			item.DueDate,
+			displayTime(item.CreatedAt),
+			displayTime(item.UpdatedAt),
+		})
+		for _, comment := range item.Comments {
+			journalRows = append(journalRows, []string{number, item.IssueID, comment.AuthorName, displayTime(comment.CreatedAt), journalNotes(comment)})
+		}
+	}
+	data, err := encodeCSV(IssueColumns, issueRows)
+	if err != nil {
+		return ExportResult{}, err
+	}
+	if writeErr := atomicwrite.WriteFile(issuesPath, data); writeErr != nil {
+		return ExportResult{}, fmt.Errorf("write redmine issues: %w", writeErr)
+	}
+	result := ExportResult{IssuesPath: issuesPath, Issues: len(issueRows), Skipped: skipped}
+	if journalsPath == "" {
+		return result, nil
+	}
+	data, err = encodeCSV(JournalColumns, journalRows)
+	if err != nil {
+		return ExportResult{}, err
+	}
+	if writeErr := atomicwrite.WriteFile(journalsPath, data); writeErr != nil {
+		return ExportResult{}, fmt.Errorf("write redmine journals: %w", writeErr)
+	}
+	result.JournalsPath = journalsPath
+	result.Journals = len(journalRows)
+	return result, nil
+}
+
+// exportStatus は DD-REDMINE-001 の ratta のステータスを Redmine のステータス名へ変換する。
+func exportStatus(status issue.Status) string {
+	if name, ok := statusToRedmine[status]; ok {
+		return name
+	}
+	return string(status)
+}
+
+// journalNotes は DD-REDMINE-001 のコメントの本文と添付のファイル名から注記の本文を返す。
+func journalNotes(comment issue.Comment) string {
+	if len(comment.Attachments) == 0 {
+		return comment.Body
+	}
+	names := make([]string, 0, len(comment.Attachments))
+	for _, attachment := range comment.Attachments {
+		names = append(names, attachment.FileName)
+	}
+	return fmt.Sprintf("%s\n\n(Attachments: %s)", comment.Body, strings.Join(names, ", "))
+}
+
+// encodeCSV は DD-REDMINE-001 の見出しと行を CSV へ符号化する。
+func encodeCSV(header []string, rows [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(header); err != nil {
+		return nil, fmt.Errorf("write csv: %w", err)
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("write csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,308 @@
+// import.go は Redmine の CSV から読み込んだ課題を、Redmine の課題番号で既存の課題と対応付けて作成・更新する処理を担う。
+package redmine
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+	"unicode/utf8"
+
+	"ratta/internal/app/issueexport"
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+	"ratta/internal/domain/timeutil"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// maxDescriptionLength は DD-DATA-003 の説明の最大文字数を表す。超える説明は切り詰め、全文をコメントとして残す。
+const maxDescriptionLength = 255
+
+// defaultAuthor は DD-REDMINE-002 の作成者の無い注記に用いる作成者名を表す。
+const defaultAuthor = "Redmine"
+
+// 行の結果は DD-REDMINE-002 の1行分の取り込みの結果を表す。dry-run では保存した場合の結果を表す。
+const (
+	RowCreated   = "created"
+	RowUpdated   = "updated"
+	RowUnchanged = "unchanged"
+	RowFailed    = "failed"
+)
+
+// Options は DD-REDMINE-002 の取り込みの設定を表す。
+// Category は取り込み先のカテゴリ、Company は Redmine を使う取引先の会社種別 (起票元とコメントの作成者の会社) を表す。
+// DefaultDueDate は期日の無い課題に用いる期日 (YYYY-MM-DD) を表し、空の場合は期日の無い課題を失敗とする。
+// DryRun は検証のみを行い保存しないことを表す。
+type Options struct {
+	Category       string
+	Company        issue.Company
+	DefaultDueDate string
+	DryRun         bool
+}
+
+// RowResult は DD-REDMINE-002 の1行分の取り込みの結果を表す。Status は RowCreated などのいずれか。
+type RowResult struct {
+	Line      int
+	RedmineID string
+	Status    string
+	IssueID   string
+	Message   string
+}
+
+// ImportResult は DD-REDMINE-002 の取り込みの結果を表す。
+type ImportResult struct {
+	DryRun    bool
+	Created   int
+	Updated   int
+	Unchanged int
+	Failed    int
+	Rows      []RowResult
+}
+
+// importer は DD-REDMINE-002 の取り込み中の状態を表す。
+// byRedmine は Redmine の課題番号ごとの既存の課題、byRatta は Redmine の課題番号を持たない既存の課題を課題IDごとに表す。
+type importer struct {
+	service   *issueops.Service
+	mode      mod.Mode
+	opts      Options
+	byRedmine map[string]issue.Issue
+	byRatta   map[string]issue.Issue
+	seen      map[string]bool
+}
+
+// Import は DD-REDMINE-002 の Redmine の課題の取り込みを行う。
+// 目的: Redmine を使う取引先から定期的に受け取る課題一覧を、同じ課題は更新、新しい課題は作成して同期する。
+// 入力: ctx は中断通知、service は課題の保存に用いるサービス、root はプロジェクトルート、currentMode は操作モード、
+// records は ReadFiles で読み込んだ行、opts は取り込みの設定。
+// 出力: ImportResult とエラー。
+// エラー: 設定が不正な場合、カテゴリが存在しない場合、既存の課題の走査に失敗した場合、中断された場合に返す。
+// 行ごとの失敗は ImportResult.Rows に含め、残りの行の処理を続ける。
+// 副作用: 課題JSONを作成・上書きする。dry-run では保存しない。
+// 並行性: 取り込み先のカテゴリへの同時書き込みは呼び出し側で排他する。
+// 不変条件: 既存の課題は custom_fields の redmine_id、無ければ Redmine のカスタムフィールド "ratta ID" で対応付ける。
+// Redmine の更新日時が前回の取り込み以前の課題は変更しない。更新では課題の項目を Redmine の値で上書きし、
+// コメントは作成者・作成日時・本文が一致しない注記のみを追加する。ステータスの遷移規則は適用しない。
+// 関連DD: DD-REDMINE-002, DD-REDMINE-001, DD-BE-003
+func Import(ctx context.Context, service *issueops.Service, root string, currentMode mod.Mode, records []Record, opts Options) (ImportResult, error) {
+	if opts.Category == "" {
+		return ImportResult{}, apperr.New(apperr.ErrValidation, "category is required")
+	}
+	if opts.Company == "" {
+		opts.Company = issue.CompanyContractor
+	}
+	if !opts.Company.IsValid() {
+		return ImportResult{}, apperr.Errorf(apperr.ErrValidation, "unknown company: %s", opts.Company)
+	}
+	if opts.DefaultDueDate != "" {
+		if _, err := time.Parse("2006-01-02", opts.DefaultDueDate); err != nil {
+			return ImportResult{}, apperr.Errorf(apperr.ErrValidation, "invalid default due date: %s", opts.DefaultDueDate)
+		}
+	}
+	existing, _, err := issueexport.Collect(ctx, root, issueexport.Filter{Categories: []string{opts.Category}})
+	if err != nil {
+		return ImportResult{}, err
+	}
+	im := importer{
+		service:   service,
+		mode:      currentMode,
+		opts:      opts,
+		byRedmine: make(map[string]issue.Issue, len(existing)),
+		byRatta:   make(map[string]issue.Issue, len(existing)),
+		seen:      make(map[string]bool, len(records)),
+	}
+	for _, item := range existing {
+		if number := redmineID(item); number != "" {
+			im.byRedmine[number] = item
+		} else {
+			im.byRatta[item.IssueID] = item
+		}
+	}
+
+	result := ImportResult{DryRun: opts.DryRun, Rows: make([]RowResult, 0, len(records))}
+	for _, record := range records {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ImportResult{}, ctxErr
+		}
+		row := im.apply(record)
+		switch row.Status {
+		case RowCreated:
+			result.Created++
+		case RowUpdated:
+			result.Updated++
+		case RowUnchanged:
+			result.Unchanged++
+		default:
+			result.Failed++
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, nil
+}
+
+// apply は DD-REDMINE-002 の1行分の課題を作成・更新 (dry-run では検証) し、結果を返す。
+func (im *importer) apply(record Record) RowResult {
+	row := RowResult{Line: record.Line, RedmineID: record.RedmineID}
+	fail := func(err error) RowResult {
+		row.Status = RowFailed
+		row.Message = err.Error()
+		return row
+	}
+	if record.Err != nil {
+		return fail(record.Err)
+	}
+	if im.seen[record.RedmineID] {
+		return fail(fmt.Errorf("issue #%s appears more than once", record.RedmineID))
+	}
+	im.seen[record.RedmineID] = true
+
+	current, exists := im.byRedmine[record.RedmineID]
+	if !exists && record.RattaID != "" {
+		current, exists = im.byRatta[record.RattaID]
+	}
+	var item issue.Issue
+	if exists {
+		row.IssueID = current.IssueID
+		if !isNewer(record.UpdatedAt, customString(current, FieldRedmineUpdatedOn)) && redmineID(current) != "" {
+			row.Status = RowUnchanged
+			return row
+		}
+		if current.Version < 2 {
+			return fail(fmt.Errorf("issue %s is version %d; migrate the project before importing", current.IssueID, current.Version))
+		}
+		item = im.merge(current, record)
+		row.Status = RowUpdated
+	} else {
+		var err error
+		if item, err = im.create(record); err != nil {
+			return fail(err)
+		}
+		row.Status = RowCreated
+	}
+
+	if im.opts.DryRun {
+		if err := im.service.CheckImportedIssue(im.opts.Category, im.mode, item); err != nil {
+			return fail(err)
+		}
+		return row
+	}
+	saved, err := im.service.SaveImportedIssue(im.opts.Category, im.mode, item)
+	if err != nil {
+		return fail(err)
+	}
+	row.IssueID = saved.Issue.IssueID
+	im.byRedmine[record.RedmineID] = saved.Issue
+	delete(im.byRatta, saved.Issue.IssueID)
+	return row
+}
+
+// create は DD-REDMINE-002 の Redmine の課題から新しい課題を組み立てる。空のステータス・優先度は Open・Medium とする。
+func (im *importer) create(record Record) (issue.Issue, error) {
+	dueDate := record.DueDate
+	if dueDate == "" {
+		dueDate = im.opts.DefaultDueDate
+	}
+	if dueDate == "" {
+		return issue.Issue{}, fmt.Errorf("issue #%s has no due date; specify a default due date", record.RedmineID)
+	}
+	createdAt := record.CreatedAt
+	if createdAt == "" {
+		createdAt = timeutil.NowISO8601()
+	}
+	item := issue.Issue{
+		Version:       issue.CurrentVersion,
+		Title:         record.Subject,
+		Status:        issue.StatusOpen,
+		Priority:      issue.PriorityMedium,
+		OriginCompany: im.opts.Company,
+		CreatedAt:     createdAt,
+		DueDate:       dueDate,
+		CustomFields:  map[string]any{},
+		Comments:      []issue.Comment{},
+	}
+	return im.merge(item, record), nil
+}
+
+// merge は DD-REDMINE-002 の課題の項目を Redmine の値で上書きし、未取り込みの注記をコメントとして追加した課題を返す。
+// Redmine 側で空のステータス・優先度・期日は元の値のままとする。
+func (im *importer) merge(current issue.Issue, record Record) issue.Issue {
+	item := current
+	item.Title = record.Subject
+	item.Assignee = record.Assignee
+	if record.Status != "" {
+		item.Status = record.Status
+	}
+	if record.Priority != "" {
+		item.Priority = record.Priority
+	}
+	if record.DueDate != "" {
+		item.DueDate = record.DueDate
+	}
+	item.UpdatedAt = record.UpdatedAt
+	if item.UpdatedAt == "" {
+		item.UpdatedAt = timeutil.NowISO8601()
+	}
+	item.CustomFields = maps.Clone(current.CustomFields)
+	if item.CustomFields == nil {
+		item.CustomFields = map[string]any{}
+	}
+	item.CustomFields[FieldRedmineID] = record.RedmineID
+	if record.UpdatedAt != "" {
+		item.CustomFields[FieldRedmineUpdatedOn] = record.UpdatedAt
+	}
+
+	item.Comments = append([]issue.Comment{}, current.Comments...)
+	known := make(map[string]bool, len(item.Comments))
+	for _, comment := range item.Comments {
+		known[commentKey(comment.AuthorName, comment.CreatedAt, comment.Body)] = true
+	}
+	addComment := func(author, createdAt, body string) {
+		if author == "" {
+			author = defaultAuthor
+		}
+		if createdAt == "" {
+			createdAt = item.CreatedAt
+		}
+		key := commentKey(author, createdAt, body)
+		if known[key] {
+			return
+		}
+		known[key] = true
+		item.Comments = append(item.Comments, issue.Comment{
+			Body:          body,
+			AuthorName:    author,
+			AuthorCompany: im.opts.Company,
+			CreatedAt:     createdAt,
+		})
+	}
+
+	item.Description = record.Description
+	if item.Description == "" {
+		item.Description = record.Subject
+	}
+	if utf8.RuneCountInString(item.Description) > maxDescriptionLength {
+		// 説明の全文は課題の作成日時のコメントとして残し、説明が変わらない限り再度の取り込みで重複しないようにする。
+		addComment(record.Author, item.CreatedAt, "Description (imported from Redmine):\n\n"+item.Description)
+		item.Description = string([]rune(item.Description)[:maxDescriptionLength-1]) + "…"
+	}
+	for _, journal := range record.Journals {
+		addComment(journal.Author, journal.CreatedAt, journal.Notes)
+	}
+	return item
+}
+
+// isNewer は DD-REDMINE-002 の Redmine の更新日時が前回の取り込みより新しいかを判定する。いずれかが不明な場合は新しいものとする。
+func isNewer(incoming, stored string) bool {
+	incomingTime, incomingErr := time.Parse(time.RFC3339, incoming)
+	storedTime, storedErr := time.Parse(time.RFC3339, stored)
+	if incomingErr != nil || storedErr != nil {
+		return true
+	}
+	return incomingTime.After(storedTime)
+}
+
+// commentKey は DD-REDMINE-002 の取り込み済みの注記を判定するための、作成者・作成日時・本文の組を返す。
+func commentKey(author, createdAt, body string) string {
+	return author + "\x00" + createdAt + "\x00" + body
+}
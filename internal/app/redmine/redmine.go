@@ -0,0 +1,388 @@
+// Package redmine は Redmine の課題一覧の CSV と注記 (journal) の CSV の読み書きを担い、Redmine への接続や UI 表示は扱わない。
+// Redmine を使う取引先と課題の一覧を定期的に受け渡せるよう、Redmine の課題番号を課題の custom_fields に保持して同じ課題を対応付ける。
+package redmine
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/japanese"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+	"ratta/internal/domain/timeutil"
+)
+
+const (
+	// FieldRedmineID は DD-REDMINE-001 の Redmine の課題番号を保持する custom_fields のキーを表す。
+	FieldRedmineID = "redmine_id"
+	// FieldRedmineUpdatedOn は DD-REDMINE-002 の最後に取り込んだ Redmine の更新日時 (RFC3339) を保持する custom_fields のキーを表す。
+	FieldRedmineUpdatedOn = "redmine_updated_on"
+)
+
+// 列のキーは DD-REDMINE-001 の Redmine の列見出し (英語・日本語) に対応する、このパッケージ内の列の識別子を表す。
+const (
+	columnID          = "id"
+	columnRattaID     = "ratta_id"
+	columnSubject     = "subject"
+	columnDescription = "description"
+	columnStatus      = "status"
+	columnPriority    = "priority"
+	columnAssignee    = "assignee"
+	columnAuthor      = "author"
+	columnDueDate     = "due_date"
+	columnCreated     = "created"
+	columnUpdated     = "updated"
+	columnNotes       = "notes"
+)
+
+// columnAliases は DD-REDMINE-001 の Redmine が出力する列見出しと列のキーの対応を表す。見出しは大文字・小文字を区別しない。
+var columnAliases = map[string]string{
+	"#":           columnID,
+	"id":          columnID,
+	"ratta id":    columnRattaID,
+	"subject":     columnSubject,
+	"題名":          columnSubject,
+	"description": columnDescription,
+	"説明":          columnDescription,
+	"status":      columnStatus,
+	"ステータス":       columnStatus,
+	"priority":    columnPriority,
+	"優先度":         columnPriority,
+	"assignee":    columnAssignee,
+	"assigned to": columnAssignee,
+	"担当者":         columnAssignee,
+	"author":      columnAuthor,
+	"作成者":         columnAuthor,
+	"due date":    columnDueDate,
+	"期日":          columnDueDate,
+	"created":     columnCreated,
+	"作成日":         columnCreated,
+	"updated":     columnUpdated,
+	"更新日":         columnUpdated,
+	"notes":       columnNotes,
+	"注記":          columnNotes,
+}
+
+// statusFromRedmine は DD-REDMINE-001 の Redmine の既定のステータス名 (英語・日本語) と ratta のステータスの対応を表す。
+// ratta のステータス名もそのまま受け付ける。
+var statusFromRedmine = map[string]issue.Status{
+	"new":         issue.StatusOpen,
+	"新規":          issue.StatusOpen,
+	"open":        issue.StatusOpen,
+	"in progress": issue.StatusWorking,
+	"assigned":    issue.StatusWorking,
+	"進行中":         issue.StatusWorking,
+	"working":     issue.StatusWorking,
+	"inquiry":     issue.StatusInquiry,
+	"hold":        issue.StatusHold,
+	"feedback":    issue.StatusFeedback,
+	"フィードバック":     issue.StatusFeedback,
+	"resolved":    issue.StatusResolved,
+	"解決":          issue.StatusResolved,
+	"closed":      issue.StatusClosed,
+	"終了":          issue.StatusClosed,
+	"rejected":    issue.StatusRejected,
+	"却下":          issue.StatusRejected,
+}
+
+// priorityFromRedmine は DD-REDMINE-001 の Redmine の既定の優先度名 (英語・日本語) と ratta の優先度の対応を表す。
+// ratta は3段階のため、Redmine の Urgent・Immediate は High とする。
+var priorityFromRedmine = map[string]issue.Priority{
+	"low":       issue.PriorityLow,
+	"低め":        issue.PriorityLow,
+	"normal":    issue.PriorityMedium,
+	"medium":    issue.PriorityMedium,
+	"通常":        issue.PriorityMedium,
+	"high":      issue.PriorityHigh,
+	"高め":        issue.PriorityHigh,
+	"urgent":    issue.PriorityHigh,
+	"急いで":       issue.PriorityHigh,
+	"immediate": issue.PriorityHigh,
+	"今すぐ":       issue.PriorityHigh,
+}
+
+// timeLayouts は DD-REDMINE-001 の Redmine の CSV の日時の書式を表す。Redmine は利用者の設定の書式で出力する。
+var timeLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04",
+	"2006/01/02 15:04:05",
+	"01/02/2006 03:04 PM",
+	"01/02/2006 15:04",
+}
+
+// dateLayouts は DD-REDMINE-001 の Redmine の CSV の日付の書式を表す。
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006/01/02",
+	"01/02/2006",
+}
+
+// displayTimeLayout は DD-REDMINE-001 の出力する CSV の日時の書式を表す。Redmine の既定の書式に合わせる。
+const displayTimeLayout = "2006-01-02 15:04"
+
+// Journal は DD-REDMINE-001 の注記1件を表す。CreatedAt は RFC3339 とする。
+type Journal struct {
+	Author    string
+	CreatedAt string
+	Notes     string
+}
+
+// Record は DD-REDMINE-001 の課題一覧の CSV の1行を表す。
+// RattaID は ratta から出力した課題を Redmine へ取り込んだ場合の、Redmine のカスタムフィールド "ratta ID" の値を表す。
+// 日時は RFC3339、期日は YYYY-MM-DD に変換済みとする。Err は行の値を解釈できなかった理由を表し、取り込み時にその行を失敗とする。
+type Record struct {
+	Line        int
+	RedmineID   string
+	RattaID     string
+	Subject     string
+	Description string
+	Status      issue.Status
+	Priority    issue.Priority
+	Assignee    string
+	Author      string
+	DueDate     string
+	CreatedAt   string
+	UpdatedAt   string
+	Journals    []Journal
+	Err         error
+}
+
+// table は DD-REDMINE-001 の CSV の見出しと行を表す。lines は各行のファイル上の行番号を表す。
+type table struct {
+	columns map[string]int
+	rows    [][]string
+	lines   []int
+}
+
+// ReadFiles は DD-REDMINE-001 の Redmine の課題一覧の CSV と注記の CSV を読み込む。
+// 目的: Redmine の CSV 出力 (英語・日本語の見出し、UTF-8・Shift_JIS) をそのまま取り込めるようにする。
+// 入力: issuesPath は課題一覧の CSV、journalsPath は注記の CSV (空の場合は読み込まない)。
+// 出力: 課題一覧の行の順の Record とエラー。
+// エラー: ファイルを読めない場合、CSV として解析できない場合、課題番号・題名の列が無い場合に返す。
+// 値を解釈できない行は Record.Err に理由を設定して返す。
+// 副作用: ファイルを読み取る。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: 注記は課題番号で課題へ対応付け、ファイル上の順に並べる。本文の無い注記 (項目の変更のみの記録) と、
+// 課題一覧に無い課題番号の注記は読み込まない。
+// 関連DD: DD-REDMINE-001
+func ReadFiles(issuesPath, journalsPath string) ([]Record, error) {
+	issues, err := readTable(issuesPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := issues.require(columnID, columnSubject); err != nil {
+		return nil, fmt.Errorf("%s: %w", issuesPath, err)
+	}
+	records := make([]Record, 0, len(issues.rows))
+	byID := make(map[string]int, len(issues.rows))
+	for i, row := range issues.rows {
+		record := parseRecord(issues, row)
+		record.Line = issues.lines[i]
+		if _, exists := byID[record.RedmineID]; !exists && record.Err == nil {
+			byID[record.RedmineID] = len(records)
+		}
+		records = append(records, record)
+	}
+	if journalsPath == "" {
+		return records, nil
+	}
+	journals, err := readTable(journalsPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := journals.require(columnID, columnNotes); err != nil {
+		return nil, fmt.Errorf("%s: %w", journalsPath, err)
+	}
+	for i, row := range journals.rows {
+		index, ok := byID[strings.TrimPrefix(journals.value(row, columnID), "#")]
+		notes := journals.raw(row, columnNotes)
+		if !ok || strings.TrimSpace(notes) == "" {
+			continue
+		}
+		createdAt, parseErr := parseTime(journals.value(row, columnCreated))
+		if parseErr != nil {
+			records[index].Err = fmt.Errorf("%s line %d: %w", journalsPath, journals.lines[i], parseErr)
+			continue
+		}
+		records[index].Journals = append(records[index].Journals, Journal{
+			Author:    journals.value(row, columnAuthor),
+			CreatedAt: createdAt,
+			Notes:     notes,
+		})
+	}
+	return records, nil
+}
+
+// parseRecord は DD-REDMINE-001 の課題一覧の1行を Record へ変換する。解釈できない値は Record.Err に設定する。
+func parseRecord(t table, row []string) Record {
+	record := Record{
+		RedmineID:   strings.TrimPrefix(t.value(row, columnID), "#"),
+		RattaID:     t.value(row, columnRattaID),
+		Subject:     t.value(row, columnSubject),
+		Description: t.raw(row, columnDescription),
+		Assignee:    t.value(row, columnAssignee),
+		Author:      t.value(row, columnAuthor),
+	}
+	var errs []error
+	if record.RedmineID == "" {
+		errs = append(errs, errors.New("issue number (#) is required"))
+	}
+	if value := t.value(row, columnStatus); value != "" {
+		status, ok := statusFromRedmine[strings.ToLower(value)]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown status: %s", value))
+		}
+		record.Status = status
+	}
+	if value := t.value(row, columnPriority); value != "" {
+		priority, ok := priorityFromRedmine[strings.ToLower(value)]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown priority: %s", value))
+		}
+		record.Priority = priority
+	}
+	var err error
+	if record.DueDate, err = parseDate(t.value(row, columnDueDate)); err != nil {
+		errs = append(errs, err)
+	}
+	if record.CreatedAt, err = parseTime(t.value(row, columnCreated)); err != nil {
+		errs = append(errs, err)
+	}
+	if record.UpdatedAt, err = parseTime(t.value(row, columnUpdated)); err != nil {
+		errs = append(errs, err)
+	}
+	record.Err = errors.Join(errs...)
+	return record
+}
+
+// readTable は DD-REDMINE-001 の CSV を読み込む。BOM 付き UTF-8 と、UTF-8 として不正な場合は Shift_JIS (Redmine の日本語環境の既定) として扱う。
+func readTable(path string) (table, error) {
+	// #nosec G304 -- 利用者が取り込み元として指定したファイルを読む。
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return table{}, apperr.WithPath(fmt.Errorf("read redmine csv: %w", err), path, "")
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	if !utf8.Valid(data) {
+		decoded, decodeErr := japanese.ShiftJIS.NewDecoder().Bytes(data)
+		if decodeErr != nil {
+			return table{}, apperr.Errorf(apperr.ErrValidation, "%s: csv is neither UTF-8 nor Shift_JIS", path)
+		}
+		data = decoded
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return table{}, apperr.Errorf(apperr.ErrValidation, "%s: read csv header: %v", path, err)
+	}
+	result := table{columns: make(map[string]int, len(header))}
+	for i, name := range header {
+		if key, ok := columnAliases[strings.ToLower(strings.TrimSpace(name))]; ok {
+			if _, exists := result.columns[key]; !exists {
+				result.columns[key] = i
+			}
+		}
+	}
+	for {
+		record, readErr := reader.Read()
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return table{}, apperr.Errorf(apperr.ErrValidation, "%s: read csv: %v", path, readErr)
+		}
+		line, _ := reader.FieldPos(0)
+		result.rows = append(result.rows, record)
+		result.lines = append(result.lines, line)
+	}
+	return result, nil
+}
+
+// require は DD-REDMINE-001 の必須の列があるかを検証する。
+func (t table) require(keys ...string) error {
+	for _, key := range keys {
+		if _, ok := t.columns[key]; !ok {
+			return apperr.Errorf(apperr.ErrValidation, "csv header has no %s column", key)
+		}
+	}
+	return nil
+}
+
+// raw は DD-REDMINE-001 の行の列の値をそのまま返す。列が無い場合は空文字を返す。
+func (t table) raw(row []string, key string) string {
+	i, ok := t.columns[key]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return row[i]
+}
+
+// value は DD-REDMINE-001 の行の列の値を前後の空白を除いて返す。
+func (t table) value(row []string, key string) string {
+	return strings.TrimSpace(t.raw(row, key))
+}
+
+// parseTime は DD-REDMINE-001 の CSV の日時を表示用のタイムゾーンの日時として解釈し、RFC3339 で返す。空の場合は空文字を返す。
+func parseTime(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed.Format(time.RFC3339), nil
+	}
+	for _, layout := range timeLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, timeutil.DisplayLocation()); err == nil {
+			return parsed.Format(time.RFC3339), nil
+		}
+	}
+	return "", fmt.Errorf("invalid date time: %s", value)
+}
+
+// parseDate は DD-REDMINE-001 の CSV の日付を YYYY-MM-DD で返す。空の場合は空文字を返す。
+func parseDate(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	for _, layout := range dateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("invalid date: %s", value)
+}
+
+// displayTime は DD-REDMINE-001 の保存された日時を表示用のタイムゾーンの Redmine の既定の書式で返す。解析できない場合はそのまま返す。
+func displayTime(value string) string {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return parsed.In(timeutil.DisplayLocation()).Format(displayTimeLayout)
+}
+
+// redmineID は DD-REDMINE-001 の課題の custom_fields に保持した Redmine の課題番号を返す。無い場合は空文字を返す。
+func redmineID(item issue.Issue) string {
+	return customString(item, FieldRedmineID)
+}
+
+// customString は DD-REDMINE-001 の custom_fields の値を文字列で返す。数値で保存された課題番号も受け付ける。
+func customString(item issue.Issue, key string) string {
+	switch value := item.CustomFields[key].(type) {
+	case string:
+		return value
+	case float64:
+		return fmt.Sprintf("%.0f", value)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,208 @@
+// redmine_test.go は Redmine の CSV の読み込み、課題番号による取り込み・更新、Redmine 向けの CSV の出力のテストを行う。
+package redmine
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+
+	"ratta/internal/app/issueexport"
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// writeFile はテスト用に内容をファイルへ書き、パスを返す。
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	return path
+}
+
+// newProject はテスト用にカテゴリ cat のみを持つプロジェクトを作成し、ルートを返す。
+func newProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "cat"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	return root
+}
+
+func TestReadFiles_ParsesJapaneseShiftJIS(t *testing.T) {
+	// 日本語環境の Redmine が出力する Shift_JIS の CSV を読み、ステータス・優先度・日時を変換して注記を対応付けることを確認する。
+	dir := t.TempDir()
+	issuesCSV := "#,題名,説明,ステータス,優先度,担当者,期日,作成日,更新日\n" +
+		"12,画面が固まる,再現手順,進行中,急いで,佐藤,2024/01/20,2024/01/09 10:00,2024/01/10 11:30\n" +
+		"13,不明な状態,,保留中,通常,,,2024/01/09 10:00,2024/01/09 10:00\n"
+	journalsCSV := "#,作成者,作成日,注記\n12,鈴木,2024/01/10 11:30,ログを確認しました\n12,鈴木,2024/01/10 11:31,\n99,鈴木,2024/01/10 11:32,対象外\n"
+	encode := func(value string) []byte {
+		data, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte(value))
+		if err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		return data
+	}
+	records, err := ReadFiles(writeFile(t, dir, "issues.csv", encode(issuesCSV)), writeFile(t, dir, "journals.csv", encode(journalsCSV)))
+	if err != nil {
+		t.Fatalf("ReadFiles error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+	first := records[0]
+	if first.Err != nil || first.Line != 2 || first.Subject != "画面が固まる" || first.Status != issue.StatusWorking || first.Priority != issue.PriorityHigh {
+		t.Fatalf("unexpected first record: %+v", first)
+	}
+	if first.DueDate != "2024-01-20" || !strings.HasPrefix(first.UpdatedAt, "2024-01-10T11:30:00") {
+		t.Fatalf("unexpected dates: %+v", first)
+	}
+	if len(first.Journals) != 1 || first.Journals[0].Author != "鈴木" || first.Journals[0].Notes != "ログを確認しました" {
+		t.Fatalf("unexpected journals: %+v", first.Journals)
+	}
+	if records[1].Err == nil || !strings.Contains(records[1].Err.Error(), "unknown status") {
+		t.Fatalf("expected unknown status error, got %v", records[1].Err)
+	}
+
+	missing := writeFile(t, dir, "bad.csv", []byte("Subject\nx\n"))
+	if _, err := ReadFiles(missing, ""); !errors.Is(err, apperr.ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestImport_CreatesThenUpdatesByIssueNumber(t *testing.T) {
+	// 課題番号で同じ課題を対応付け、更新日時が変わらない行は変更せず、新しい注記のみをコメントとして追加することを確認する。
+	root := newProject(t)
+	service := issueops.NewService(root, nil)
+	record := Record{
+		Line: 2, RedmineID: "12", Subject: "crash", Description: strings.Repeat("x", 300),
+		Status: issue.StatusOpen, Priority: issue.PriorityLow, Author: "suzuki", DueDate: "2024-01-20",
+		CreatedAt: "2024-01-09T10:00:00+09:00", UpdatedAt: "2024-01-10T10:00:00+09:00",
+		Journals: []Journal{{Author: "suzuki", CreatedAt: "2024-01-10T10:00:00+09:00", Notes: "first note"}},
+	}
+	noDue := Record{Line: 3, RedmineID: "13", Subject: "no due", CreatedAt: "2024-01-09T10:00:00+09:00"}
+	opts := Options{Category: "cat"}
+
+	result, err := Import(context.Background(), service, root, mod.ModeVendor, []Record{record, noDue}, opts)
+	if err != nil {
+		t.Fatalf("Import error: %v", err)
+	}
+	if result.Created != 1 || result.Failed != 1 || !strings.Contains(result.Rows[1].Message, "no due date") {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	issueID := result.Rows[0].IssueID
+	detail, err := service.GetIssue("cat", issueID)
+	if err != nil {
+		t.Fatalf("GetIssue error: %v", err)
+	}
+	created := detail.Issue
+	if created.OriginCompany != issue.CompanyContractor || created.CustomFields[FieldRedmineID] != "12" || len([]rune(created.Description)) != maxDescriptionLength {
+		t.Fatalf("unexpected created issue: %+v", created)
+	}
+	if len(created.Comments) != 2 || !strings.Contains(created.Comments[0].Body, strings.Repeat("x", 300)) {
+		t.Fatalf("unexpected comments: %+v", created.Comments)
+	}
+
+	result, err = Import(context.Background(), service, root, mod.ModeVendor, []Record{record}, opts)
+	if err != nil || result.Unchanged != 1 {
+		t.Fatalf("expected unchanged, got %+v %v", result, err)
+	}
+
+	record.Status = issue.StatusResolved
+	record.UpdatedAt = "2024-01-11T10:00:00+09:00"
+	record.Journals = append(record.Journals, Journal{Author: "suzuki", CreatedAt: "2024-01-11T10:00:00+09:00", Notes: "fixed"})
+	result, err = Import(context.Background(), service, root, mod.ModeVendor, []Record{record}, opts)
+	if err != nil || result.Updated != 1 || result.Rows[0].IssueID != issueID {
+		t.Fatalf("expected updated, got %+v %v", result, err)
+	}
+	detail, err = service.GetIssue("cat", issueID)
+	if err != nil || detail.Issue.Status != issue.StatusResolved || len(detail.Issue.Comments) != 3 {
+		t.Fatalf("unexpected updated issue: %+v %v", detail.Issue, err)
+	}
+}
+
+func TestImport_DryRunAndRattaIDLink(t *testing.T) {
+	// Redmine のカスタムフィールド "ratta ID" で ratta の課題へ課題番号を対応付け、dry-run では保存しないことを確認する。
+	root := newProject(t)
+	service := issueops.NewService(root, nil)
+	created, err := service.CreateIssue("cat", mod.ModeVendor, issueops.IssueCreateInput{Title: "ours", Description: "d", DueDate: "2024-02-01", Priority: issue.PriorityHigh})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	record := Record{Line: 2, RedmineID: "40", RattaID: created.Issue.IssueID, Subject: "ours", Status: issue.StatusWorking, UpdatedAt: "2024-01-12T10:00:00+09:00"}
+
+	dry, err := Import(context.Background(), service, root, mod.ModeVendor, []Record{record}, Options{Category: "cat", DryRun: true})
+	if err != nil || dry.Updated != 1 || !dry.DryRun {
+		t.Fatalf("unexpected dry run: %+v %v", dry, err)
+	}
+	if detail, _ := service.GetIssue("cat", created.Issue.IssueID); detail.Issue.Status != issue.StatusOpen {
+		t.Fatalf("dry run must not save: %+v", detail.Issue)
+	}
+	if _, err := Import(context.Background(), service, root, mod.ModeVendor, []Record{record}, Options{Category: "cat"}); err != nil {
+		t.Fatalf("Import error: %v", err)
+	}
+	detail, err := service.GetIssue("cat", created.Issue.IssueID)
+	if err != nil || detail.Issue.Status != issue.StatusWorking || detail.Issue.CustomFields[FieldRedmineID] != "40" || detail.Issue.DueDate != "2024-02-01" {
+		t.Fatalf("unexpected linked issue: %+v %v", detail.Issue, err)
+	}
+	if _, err := Import(context.Background(), service, root, mod.ModeVendor, nil, Options{Category: "cat", Company: "Other"}); !errors.Is(err, apperr.ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestExport_WritesIssuesAndJournals(t *testing.T) {
+	// Redmine の列名とステータス名・優先度名で課題一覧を、コメントごとに注記を出力することを確認する。
+	root := newProject(t)
+	service := issueops.NewService(root, nil)
+	record := Record{
+		Line: 2, RedmineID: "12", Subject: "crash", Description: "d", Priority: issue.PriorityMedium, DueDate: "2024-01-20",
+		CreatedAt: "2024-01-09T10:00:00+09:00", UpdatedAt: "2024-01-10T10:00:00+09:00",
+		Journals: []Journal{{Author: "suzuki", CreatedAt: "2024-01-10T10:00:00+09:00", Notes: "note"}},
+	}
+	if _, err := Import(context.Background(), service, root, mod.ModeVendor, []Record{record}, Options{Category: "cat"}); err != nil {
+		t.Fatalf("Import error: %v", err)
+	}
+	dir := t.TempDir()
+	issuesPath := filepath.Join(dir, "issues.csv")
+	journalsPath := filepath.Join(dir, "journals.csv")
+	result, err := Export(context.Background(), root, issueexport.Filter{}, issuesPath, journalsPath)
+	if err != nil || result.Issues != 1 || result.Journals != 1 {
+		t.Fatalf("unexpected result: %+v %v", result, err)
+	}
+	issues := readCSV(t, issuesPath)
+	if strings.Join(issues[0], ",") != strings.Join(IssueColumns, ",") {
+		t.Fatalf("unexpected header: %v", issues[0])
+	}
+	if row := issues[1]; row[0] != "12" || row[2] != "crash" || row[4] != "New" || row[5] != "Normal" || row[9] != displayTime(record.CreatedAt) {
+		t.Fatalf("unexpected row: %v", row)
+	}
+	if journals := readCSV(t, journalsPath); len(journals) != 2 || journals[1][0] != "12" || journals[1][4] != "note" {
+		t.Fatalf("unexpected journals: %v", journals)
+	}
+}
+
+// readCSV はテスト用に CSV の全行を読む。
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	return rows
+}
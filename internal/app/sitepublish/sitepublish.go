@@ -0,0 +1,452 @@
+// Package sitepublish はプロジェクトを閲覧専用の静的な HTML サイトとして出力する処理を担い、出力先の選択や公開先への配置は扱わない。
+// GUI と CLI の双方から用い、出力したサイトは ratta やサーバーが無くてもブラウザで閲覧できる。
+package sitepublish
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ratta/internal/app/categoryscan"
+	"ratta/internal/app/issueexport"
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+	"ratta/internal/domain/timeutil"
+	"ratta/internal/infra/atomicwrite"
+)
+
+// templateFiles は DD-PUBLISH-001 のページのテンプレートとスタイルシートを表す。
+//
+//go:embed templates
+var templateFiles embed.FS
+
+// pages は DD-PUBLISH-001 のページのテンプレートを表す。
+var pages = template.Must(template.ParseFS(templateFiles, "templates/*.html"))
+
+// statuses は DD-PUBLISH-001 の目次に件数を並べるステータスの順を表す。
+var statuses = []issue.Status{
+	issue.StatusOpen,
+	issue.StatusWorking,
+	issue.StatusInquiry,
+	issue.StatusHold,
+	issue.StatusFeedback,
+	issue.StatusResolved,
+	issue.StatusClosed,
+	issue.StatusRejected,
+}
+
+// ProgressFunc は DD-PUBLISH-001 の出力を終えたカテゴリ数と全体のカテゴリ数、カテゴリ名を通知する。
+type ProgressFunc func(done, total int, message string)
+
+// Result は DD-PUBLISH-001 の出力結果を表す。
+// Skipped は解析できず出力しなかった課題JSONの数、MissingAttachments は見つからず複写しなかった添付の数を表す。
+type Result struct {
+	Path               string
+	Categories         int
+	Issues             int
+	Attachments        int
+	MissingAttachments int
+	Skipped            int
+}
+
+// link は DD-PUBLISH-001 のページ上部の階層リンク1件を表す。
+type link struct {
+	Label string
+	Href  string
+}
+
+// page は DD-PUBLISH-001 の全ページに共通する表示内容を表す。Root はサイトの最上位への相対パスを表す。
+type page struct {
+	Title       string
+	Heading     string
+	Project     string
+	Root        string
+	Trail       []link
+	GeneratedAt string
+}
+
+// indexPage は DD-PUBLISH-001 のカテゴリの目次ページを表す。
+type indexPage struct {
+	page
+	Statuses   []issue.Status
+	Columns    int
+	Categories []categoryRow
+}
+
+// categoryRow は DD-PUBLISH-001 の目次のカテゴリ1件とステータスごとの件数を表す。
+type categoryRow struct {
+	Name     string
+	Href     string
+	Archived bool
+	Total    int
+	Counts   []int
+}
+
+// categoryPage は DD-PUBLISH-001 のカテゴリの課題一覧ページを表す。
+type categoryPage struct {
+	page
+	Archived bool
+	Issues   []issueRow
+}
+
+// issueRow は DD-PUBLISH-001 の課題一覧の課題1件を表す。
+type issueRow struct {
+	ID        string
+	Href      string
+	Title     string
+	Status    issue.Status
+	Priority  issue.Priority
+	Assignee  string
+	DueDate   string
+	UpdatedAt string
+}
+
+// issuePage は DD-PUBLISH-001 の課題の詳細ページを表す。
+type issuePage struct {
+	page
+	Issue     issue.Issue
+	CreatedAt string
+	UpdatedAt string
+	Comments  []commentView
+}
+
+// commentView は DD-PUBLISH-001 の詳細ページのコメント1件を表す。
+type commentView struct {
+	AuthorName    string
+	AuthorCompany issue.Company
+	CreatedAt     string
+	Body          string
+	Attachments   []attachmentView
+}
+
+// attachmentView は DD-PUBLISH-001 のコメントの添付1件を表す。複写できなかった添付は Href を空とする。
+type attachmentView struct {
+	FileName string
+	Href     string
+	Size     string
+}
+
+// Publish は DD-PUBLISH-001 のプロジェクトの静的な HTML サイトの出力を行う。
+// 目的: ratta を導入していない関係者にも、課題とコメント・添付をブラウザで閲覧できる形で渡せるようにする。
+// 入力: ctx は中断通知、root はプロジェクトルート、outDir は出力先、progress は進捗の通知先 (nil 可)。
+// 出力: Result とエラー。
+// エラー: 出力先が空でない・プロジェクトルート配下にある場合、走査・書き込みに失敗した場合、中断された場合に返す。
+// 副作用: outDir へ index.html・style.css と、カテゴリごとの index.html・課題ごとの <課題ID>.html・添付の複写を書き込む。
+// 失敗した場合は outDir の中身を削除する。プロジェクト配下のファイルは変更しない。
+// 並行性: 読み取りのみのため課題操作と同時に実行してよいが、実行中の変更が含まれるかは保証しない。
+// 不変条件: 対象は issueexport.Collect と同じとし、ページ間のリンクと添付は相対パスで参照する。
+// 関連DD: DD-PUBLISH-001, DD-EXPORT-001, DD-LOAD-002, DD-DATA-005
+func Publish(ctx context.Context, root, outDir string, progress ProgressFunc) (Result, error) {
+	if outDir == "" {
+		return Result{}, apperr.New(apperr.ErrValidation, "publish destination is required")
+	}
+	if err := checkOutside(root, outDir); err != nil {
+		return Result{}, err
+	}
+	scanned, err := categoryscan.ScanContext(ctx, root)
+	if err != nil {
+		return Result{}, err
+	}
+	issues, skipped, err := issueexport.Collect(ctx, root, issueexport.Filter{})
+	if err != nil {
+		return Result{}, err
+	}
+	if err := ensureEmptyDir(outDir); err != nil {
+		return Result{}, err
+	}
+	result, err := publish(ctx, root, outDir, scanned.Categories, issues, progress)
+	if err != nil {
+		_ = clearDir(outDir)
+		return Result{}, err
+	}
+	result.Skipped = skipped
+	return result, nil
+}
+
+// publish は DD-PUBLISH-001 の走査済みのカテゴリと課題から各ページと添付を書き込む。
+func publish(ctx context.Context, root, outDir string, categories []categoryscan.Category, issues []issue.Issue, progress ProgressFunc) (Result, error) {
+	byCategory := make(map[string][]issue.Issue)
+	for _, item := range issues {
+		byCategory[item.Category] = append(byCategory[item.Category], item)
+	}
+	base := page{
+		Project:     projectName(root),
+		GeneratedAt: displayTime(timeutil.NowISO8601()),
+	}
+	result := Result{Path: outDir}
+	index := indexPage{Statuses: statuses, Columns: len(statuses) + 2}
+	index.page = base
+	index.Title, index.Heading = base.Project, base.Project
+
+	published := make([]categoryscan.Category, 0, len(categories))
+	for _, category := range categories {
+		// 名前変更中のカテゴリは Collect と同じく対象としない。
+		if !category.IsReadOnly || category.IsArchived {
+			published = append(published, category)
+		}
+	}
+	for i, category := range published {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return Result{}, ctxErr
+		}
+		items := byCategory[category.Name]
+		attachments, missing, err := writeCategory(category, items, filepath.Join(outDir, category.Name), base)
+		if err != nil {
+			return Result{}, err
+		}
+		result.Categories++
+		result.Issues += len(items)
+		result.Attachments += attachments
+		result.MissingAttachments += missing
+		index.Categories = append(index.Categories, toCategoryRow(category, items))
+		if progress != nil {
+			progress(i+1, len(published), category.Name)
+		}
+	}
+	if err := writePage(filepath.Join(outDir, "index.html"), "index", index); err != nil {
+		return Result{}, err
+	}
+	style, err := templateFiles.ReadFile("templates/style.css")
+	if err != nil {
+		return Result{}, fmt.Errorf("read stylesheet: %w", err)
+	}
+	if err := atomicwrite.WriteFile(filepath.Join(outDir, "style.css"), style); err != nil {
+		return Result{}, fmt.Errorf("write stylesheet: %w", err)
+	}
+	return result, nil
+}
+
+// writeCategory は DD-PUBLISH-001 のカテゴリの課題一覧ページと課題ごとの詳細ページを書き込み、添付を複写する。
+// 複写した添付の数と見つからなかった添付の数を返す。
+func writeCategory(category categoryscan.Category, items []issue.Issue, dir string, base page) (int, int, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return 0, 0, fmt.Errorf("create category directory: %w", err)
+	}
+	categoryLink := link{Label: category.Name, Href: "index.html"}
+	list := categoryPage{Archived: category.IsArchived}
+	list.page = base
+	list.Root = "../"
+	list.Title = category.Name + " - " + base.Project
+	list.Heading = category.Name
+
+	copied, missing := 0, 0
+	for _, item := range items {
+		detail := issuePage{
+			Issue:     item,
+			CreatedAt: displayTime(item.CreatedAt),
+			UpdatedAt: displayTime(item.UpdatedAt),
+		}
+		detail.page = base
+		detail.Root = "../"
+		detail.Trail = []link{categoryLink}
+		detail.Title = item.Title + " - " + base.Project
+		detail.Heading = item.Title
+		for _, comment := range item.Comments {
+			view := commentView{
+				AuthorName:    comment.AuthorName,
+				AuthorCompany: comment.AuthorCompany,
+				CreatedAt:     displayTime(comment.CreatedAt),
+				Body:          comment.Body,
+			}
+			for _, ref := range comment.Attachments {
+				attachment := attachmentView{FileName: ref.FileName, Size: formatSize(ref.SizeBytes)}
+				ok, err := copyAttachment(category.Path, dir, ref.RelativePath)
+				if err != nil {
+					return 0, 0, err
+				}
+				if ok {
+					attachment.Href = escapePath(ref.RelativePath)
+					copied++
+				} else {
+					missing++
+				}
+				view.Attachments = append(view.Attachments, attachment)
+			}
+			detail.Comments = append(detail.Comments, view)
+		}
+		href := url.PathEscape(item.IssueID) + ".html"
+		if err := writePage(filepath.Join(dir, item.IssueID+".html"), "issue", detail); err != nil {
+			return 0, 0, err
+		}
+		list.Issues = append(list.Issues, issueRow{
+			ID:        item.IssueID,
+			Href:      href,
+			Title:     item.Title,
+			Status:    item.Status,
+			Priority:  item.Priority,
+			Assignee:  item.Assignee,
+			DueDate:   item.DueDate,
+			UpdatedAt: displayTime(item.UpdatedAt),
+		})
+	}
+	if err := writePage(filepath.Join(dir, "index.html"), "category", list); err != nil {
+		return 0, 0, err
+	}
+	return copied, missing, nil
+}
+
+// toCategoryRow は DD-PUBLISH-001 の目次のカテゴリ1件をステータスごとの件数とともに作る。
+func toCategoryRow(category categoryscan.Category, items []issue.Issue) categoryRow {
+	counts := make(map[issue.Status]int, len(statuses))
+	for _, item := range items {
+		counts[item.Status]++
+	}
+	row := categoryRow{
+		Name:     category.Name,
+		Href:     url.PathEscape(category.Name) + "/index.html",
+		Archived: category.IsArchived,
+		Total:    len(items),
+	}
+	for _, status := range statuses {
+		row.Counts = append(row.Counts, counts[status])
+	}
+	return row
+}
+
+// copyAttachment は DD-PUBLISH-001 の添付をカテゴリからの相対パスのまま出力先のカテゴリへ複写する。
+// 添付が見つからない場合や相対パスがカテゴリの外を指す場合は複写せず false を返す。
+func copyAttachment(categoryDir, destDir, relativePath string) (bool, error) {
+	relative := filepath.FromSlash(relativePath)
+	if relativePath == "" || !filepath.IsLocal(relative) {
+		return false, nil
+	}
+	// #nosec G304 -- カテゴリ配下を指すことを確認した相対パスのみを読む。
+	source, err := os.Open(filepath.Join(categoryDir, relative))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, apperr.WithPath(fmt.Errorf("open attachment: %w", err), filepath.Join(categoryDir, relative), "")
+	}
+	defer func() { _ = source.Close() }()
+	target := filepath.Join(destDir, relative)
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return false, fmt.Errorf("create attachment directory: %w", err)
+	}
+	// #nosec G304 -- 空であることを確認した出力先配下に生成したパスのみを作成する。
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return false, apperr.WithPath(fmt.Errorf("create attachment copy: %w", err), target, "")
+	}
+	_, copyErr := io.Copy(out, source)
+	closeErr := out.Close()
+	if copyErr != nil {
+		return false, apperr.WithPath(fmt.Errorf("copy attachment: %w", copyErr), target, apperr.IOHint(copyErr, apperr.HintRetryWrite))
+	}
+	if closeErr != nil {
+		return false, apperr.WithPath(fmt.Errorf("copy attachment: %w", closeErr), target, apperr.IOHint(closeErr, apperr.HintRetryWrite))
+	}
+	return true, nil
+}
+
+// writePage は DD-PUBLISH-001 のテンプレート name で data を描画して path へ書き込む。
+func writePage(path, name string, data any) error {
+	var buf bytes.Buffer
+	if err := pages.ExecuteTemplate(&buf, name, data); err != nil {
+		return fmt.Errorf("render %s page: %w", name, err)
+	}
+	if err := atomicwrite.WriteFile(path, buf.Bytes()); err != nil {
+		return fmt.Errorf("write %s page: %w", name, err)
+	}
+	return nil
+}
+
+// checkOutside は DD-PUBLISH-001 の出力先がプロジェクトルート配下でないことを確認する。
+// 配下へ出力すると、出力したページや添付の複写がカテゴリとして走査されるため拒否する。
+func checkOutside(root, outDir string) error {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("resolve project root: %w", err)
+	}
+	absOut, err := filepath.Abs(outDir)
+	if err != nil {
+		return fmt.Errorf("resolve publish destination: %w", err)
+	}
+	rel, err := filepath.Rel(absRoot, absOut)
+	if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return apperr.Errorf(apperr.ErrValidation, "publish destination must be outside the project root: %s", outDir)
+	}
+	return nil
+}
+
+// ensureEmptyDir は DD-PUBLISH-001 の出力先が空のディレクトリであることを確認し、存在しない場合は作成する。
+func ensureEmptyDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		if mkdirErr := os.MkdirAll(dir, 0o750); mkdirErr != nil {
+			return fmt.Errorf("create publish destination: %w", mkdirErr)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read publish destination: %w", err)
+	}
+	if len(entries) > 0 {
+		return apperr.Errorf(apperr.ErrConflict, "publish destination is not empty: %s", dir)
+	}
+	return nil
+}
+
+// clearDir は DD-PUBLISH-001 の出力に失敗した場合に出力先の中身を削除する。出力先自体は残す。
+func clearDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if removeErr := os.RemoveAll(filepath.Join(dir, entry.Name())); removeErr != nil {
+			return removeErr
+		}
+	}
+	return nil
+}
+
+// projectName は DD-PUBLISH-001 のサイトの見出しに用いるプロジェクトルートのフォルダ名を返す。
+func projectName(root string) string {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	return filepath.Base(root)
+}
+
+// escapePath は DD-PUBLISH-001 の "/" 区切りの相対パスを区切りごとに URL エスケープする。
+func escapePath(relativePath string) string {
+	segments := strings.Split(path.Clean(relativePath), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// displayTime は DD-DATA-002 の保存された日時を表示用のタイムゾーンの分精度で返す。解析できない場合はそのまま返す。
+func displayTime(value string) string {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return parsed.In(timeutil.DisplayLocation()).Format("2006-01-02 15:04")
+}
+
+// formatSize は DD-PUBLISH-001 の添付のサイズを読みやすい単位で返す。不明な場合は空文字を返す。
+func formatSize(size int64) string {
+	switch {
+	case size <= 0:
+		return ""
+	case size < 1024:
+		return fmt.Sprintf("%d B", size)
+	case size < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	}
+}
@@ -0,0 +1,140 @@
+// sitepublish_test.go は静的な HTML サイトのページ構成・添付の複写・出力先の検査のテストを行う。
+package sitepublish
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+
+	mod "ratta/internal/domain/mode"
+)
+
+// newProject はテスト用にカテゴリ cat へ添付付きのコメントを持つ課題を1件作成し、ルートと課題IDを返す。
+func newProject(t *testing.T) (string, string) {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "cat"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	service := issueops.NewService(root, nil)
+	created, err := service.CreateIssue("cat", mod.ModeVendor, issueops.IssueCreateInput{
+		Title:       "<b>title</b>",
+		Description: "line1\nline2",
+		DueDate:     "2024-01-01",
+		Priority:    issue.PriorityLow,
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue error: %v", err)
+	}
+	if _, err := service.AddComment("cat", created.Issue.IssueID, mod.ModeVendor, issueops.CommentCreateInput{
+		Body:       "see attached",
+		AuthorName: "vendor",
+		Attachments: []issueops.CommentAttachmentInput{
+			{OriginalName: "note.txt", Data: []byte("hello"), MimeType: "text/plain"},
+		},
+	}); err != nil {
+		t.Fatalf("AddComment error: %v", err)
+	}
+	return root, created.Issue.IssueID
+}
+
+func TestPublish_WritesPagesAndCopiesAttachments(t *testing.T) {
+	// 目次・カテゴリ・課題のページを書き、添付をリンクとともに複写し、本文を HTML エスケープすることを確認する。
+	root, issueID := newProject(t)
+	outDir := filepath.Join(t.TempDir(), "site")
+	var reported []string
+	result, err := Publish(context.Background(), root, outDir, func(done, total int, message string) {
+		reported = append(reported, message)
+	})
+	if err != nil {
+		t.Fatalf("Publish error: %v", err)
+	}
+	if result.Categories != 1 || result.Issues != 1 || result.Attachments != 1 || result.MissingAttachments != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(reported) != 1 || reported[0] != "cat" {
+		t.Fatalf("unexpected progress: %v", reported)
+	}
+	for _, name := range []string{"index.html", "style.css", filepath.Join("cat", "index.html")} {
+		if _, statErr := os.Stat(filepath.Join(outDir, name)); statErr != nil {
+			t.Fatalf("expected %s: %v", name, statErr)
+		}
+	}
+	index := readFile(t, filepath.Join(outDir, "index.html"))
+	if !strings.Contains(index, `href="cat/index.html"`) {
+		t.Fatalf("expected category link:\n%s", index)
+	}
+	detail := readFile(t, filepath.Join(outDir, "cat", issueID+".html"))
+	if strings.Contains(detail, "<b>title</b>") || !strings.Contains(detail, "&lt;b&gt;title&lt;/b&gt;") {
+		t.Fatalf("expected escaped title:\n%s", detail)
+	}
+	href := issueID + ".files/"
+	if !strings.Contains(detail, `href="`+href) {
+		t.Fatalf("expected attachment link:\n%s", detail)
+	}
+	entries, err := os.ReadDir(filepath.Join(outDir, "cat", issueID+".files"))
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected copied attachment: %v %v", entries, err)
+	}
+	if copied := readFile(t, filepath.Join(outDir, "cat", issueID+".files", entries[0].Name())); copied != "hello" {
+		t.Fatalf("unexpected attachment content: %q", copied)
+	}
+}
+
+func TestPublish_CountsMissingAttachments(t *testing.T) {
+	// 添付の実体が無い場合はリンクを作らず、見つからなかった数として数えることを確認する。
+	root, issueID := newProject(t)
+	if err := os.RemoveAll(filepath.Join(root, "cat", issueID+".files")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	outDir := filepath.Join(t.TempDir(), "site")
+	result, err := Publish(context.Background(), root, outDir, nil)
+	if err != nil {
+		t.Fatalf("Publish error: %v", err)
+	}
+	if result.Attachments != 0 || result.MissingAttachments != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if detail := readFile(t, filepath.Join(outDir, "cat", issueID+".html")); strings.Contains(detail, issueID+".files/") {
+		t.Fatalf("unexpected attachment link:\n%s", detail)
+	}
+}
+
+func TestPublish_RejectsUnsafeDestination(t *testing.T) {
+	// 空でない出力先は衝突、プロジェクトルート配下の出力先は入力の不備として拒否し、既存のファイルを変更しないことを確認する。
+	root, _ := newProject(t)
+	outDir := t.TempDir()
+	keep := filepath.Join(outDir, "keep.txt")
+	if err := os.WriteFile(keep, []byte("keep"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := Publish(context.Background(), root, outDir, nil); !errors.Is(err, apperr.ErrConflict) {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+	if readFile(t, keep) != "keep" {
+		t.Fatal("destination must not be changed")
+	}
+	if _, err := Publish(context.Background(), root, filepath.Join(root, "site"), nil); !errors.Is(err, apperr.ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "site")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("destination inside root must not be created: %v", err)
+	}
+}
+
+// readFile はテスト用にファイルの内容を文字列で返す。
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return string(data)
+}
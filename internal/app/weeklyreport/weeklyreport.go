@@ -0,0 +1,372 @@
+// Package weeklyreport は期間を指定した課題の動き (新規・対応済み・完了・期限超過) の報告書を Markdown/HTML で作成する処理を担い、
+// 報告書の配布や定期実行は扱わない。GUI と CLI の双方から用い、同じ期間とプロジェクトであれば同じ内容の報告書を作成する。
+package weeklyreport
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"path/filepath"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"ratta/internal/app/categoryscan"
+	"ratta/internal/app/issueexport"
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+	"ratta/internal/domain/timeutil"
+	"ratta/internal/infra/atomicwrite"
+)
+
+// Format は DD-REPORT-002 の報告書の形式を表す。
+type Format string
+
+const (
+	// FormatMarkdown は DD-REPORT-002 のチャットや Wiki へ貼り付ける Markdown 形式を表す。
+	FormatMarkdown Format = "md"
+	// FormatHTML は DD-REPORT-002 のブラウザやメールで閲覧する HTML 形式を表す。
+	FormatHTML Format = "html"
+)
+
+const (
+	// dateLayout は DD-REPORT-002 の期間の指定と due_date の書式を表す。
+	dateLayout = "2006-01-02"
+	// defaultDays は DD-REPORT-002 の期間を指定しない場合の日数を表す。
+	defaultDays = 7
+	// highlightLimit は DD-REPORT-002 の注目する課題として並べる件数の上限を表す。
+	highlightLimit = 5
+)
+
+// templateFiles は DD-REPORT-002 の報告書のテンプレートを表す。
+//
+//go:embed templates
+var templateFiles embed.FS
+
+// markdownTemplate・htmlTemplate は DD-REPORT-002 の形式ごとの報告書のテンプレートを表す。
+var markdownTemplate = texttemplate.Must(texttemplate.New("weekly.md.tmpl").
+	Funcs(texttemplate.FuncMap{"md": escapeMarkdown, "section": newSection}).
+	ParseFS(templateFiles, "templates/weekly.md.tmpl"))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("weekly.html.tmpl").
+	Funcs(htmltemplate.FuncMap{"section": newSection}).
+	ParseFS(templateFiles, "templates/weekly.html.tmpl"))
+
+// section は DD-REPORT-002 の詳細に載せる区分1件の見出しと課題を表す。
+type section struct {
+	Label   string
+	Entries []Entry
+}
+
+// newSection は DD-REPORT-002 のテンプレートから区分を組み立てる。
+func newSection(label string, entries []Entry) section {
+	return section{Label: label, Entries: entries}
+}
+
+var now = time.Now
+
+// Range は DD-REPORT-002 の報告の期間を表す。From・To は表示用のタイムゾーンの日付 (YYYY-MM-DD) で、いずれの日も含む。
+type Range struct {
+	From string
+	To   string
+}
+
+// Entry は DD-REPORT-002 の報告書に載せる課題1件を表す。Date は期間内に該当した日 (作成日・最終更新日) を表す。
+type Entry struct {
+	Category string
+	IssueID  string
+	Title    string
+	Status   issue.Status
+	Priority issue.Priority
+	DueDate  string
+	Date     string
+}
+
+// CategorySummary は DD-REPORT-002 のカテゴリ1件の期間内の動きを表す。
+type CategorySummary struct {
+	Name     string
+	Archived bool
+	Opened   []Entry
+	Resolved []Entry
+	Closed   []Entry
+	Overdue  []Entry
+}
+
+// HasActivity は DD-REPORT-002 のカテゴリに報告する課題があるかを返す。
+func (c CategorySummary) HasActivity() bool {
+	return len(c.Opened)+len(c.Resolved)+len(c.Closed)+len(c.Overdue) > 0
+}
+
+// Counts は DD-REPORT-002 の区分ごとの件数を表す。
+type Counts struct {
+	Opened   int
+	Resolved int
+	Closed   int
+	Overdue  int
+}
+
+// Report は DD-REPORT-002 の報告書の内容を表す。
+// HighPriorityOpened は期間内に作成した優先度 High の課題、LongestOverdue は期限の古い順の期限超過の課題を上限件数まで表す。
+// Skipped は解析できず集計しなかった課題JSONの数を表す。
+type Report struct {
+	Project            string
+	Range              Range
+	GeneratedAt        string
+	Categories         []CategorySummary
+	Totals             Counts
+	HighPriorityOpened []Entry
+	LongestOverdue     []Entry
+	Skipped            int
+}
+
+// Result は DD-REPORT-002 の報告書の出力結果を表す。
+type Result struct {
+	Path    string
+	Format  Format
+	Range   Range
+	Totals  Counts
+	Skipped int
+}
+
+// ParseFormat は DD-REPORT-002 の報告書の形式の指定を検証する。
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case FormatMarkdown, FormatHTML:
+		return Format(value), nil
+	default:
+		return "", apperr.Errorf(apperr.ErrValidation, "unsupported report format: %s", value)
+	}
+}
+
+// ParseRange は DD-REPORT-002 の期間の指定を検証する。
+// 目的: 期間の省略を補い、週次の報告を日付を指定せずに作成できるようにする。
+// 入力: from・to は YYYY-MM-DD の日付または空文字。
+// 出力: Range とエラー。to が空の場合は今日、from が空の場合は to を含む7日間の初日とする。
+// エラー: 日付の書式が不正な場合、from が to より後の場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 今日は表示用のタイムゾーンで判定する。
+// 関連DD: DD-REPORT-002, DD-DATA-002
+func ParseRange(from, to string) (Range, error) {
+	if to == "" {
+		to = now().In(timeutil.DisplayLocation()).Format(dateLayout)
+	}
+	end, err := time.Parse(dateLayout, to)
+	if err != nil {
+		return Range{}, apperr.Errorf(apperr.ErrValidation, "invalid end date: %s", to)
+	}
+	if from == "" {
+		from = end.AddDate(0, 0, -(defaultDays - 1)).Format(dateLayout)
+	}
+	if _, parseErr := time.Parse(dateLayout, from); parseErr != nil {
+		return Range{}, apperr.Errorf(apperr.ErrValidation, "invalid start date: %s", from)
+	}
+	if from > to {
+		return Range{}, apperr.Errorf(apperr.ErrValidation, "start date %s is after end date %s", from, to)
+	}
+	return Range{From: from, To: to}, nil
+}
+
+// Build は DD-REPORT-002 の期間内の課題の動きを集計する。
+// 目的: 定例の報告に用いる、カテゴリごとの新規・対応済み・完了・期限超過の課題を1回の走査で求める。
+// 入力: ctx は中断通知、root はプロジェクトルート、period は期間。
+// 出力: Report とエラー。
+// エラー: 走査に失敗した場合、中断された場合に返す。
+// 副作用: なし。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: ステータスの変更履歴は持たないため、対応済み・完了は現在のステータスが該当し最終更新日が期間内の課題とする。
+// 新規は作成日が期間内の課題、期限超過は期間の末日の時点で期限を過ぎた未完了 (Resolved と終了状態を除く) の課題とする。
+// 完了は終了状態 (Closed・Rejected) を表す。カテゴリはカテゴリ一覧の表示順とし、課題の無いカテゴリも含める。
+// 関連DD: DD-REPORT-002, DD-STATS-001, DD-EXPORT-001
+func Build(ctx context.Context, root string, period Range) (Report, error) {
+	scanned, err := categoryscan.ScanContext(ctx, root)
+	if err != nil {
+		return Report{}, err
+	}
+	issues, skipped, err := issueexport.Collect(ctx, root, issueexport.Filter{})
+	if err != nil {
+		return Report{}, err
+	}
+	report := Report{
+		Project:     projectName(root),
+		Range:       period,
+		GeneratedAt: displayDate(timeutil.NowISO8601(), "2006-01-02 15:04"),
+		Categories:  []CategorySummary{},
+		Skipped:     skipped,
+	}
+	index := make(map[string]int)
+	for _, category := range scanned.Categories {
+		// 名前変更中のカテゴリは Collect と同じく対象としない。
+		if category.IsReadOnly && !category.IsArchived {
+			continue
+		}
+		index[category.Name] = len(report.Categories)
+		report.Categories = append(report.Categories, CategorySummary{Name: category.Name, Archived: category.IsArchived})
+	}
+	var overdue []Entry
+	for _, item := range issues {
+		i, ok := index[item.Category]
+		if !ok {
+			continue
+		}
+		summary := &report.Categories[i]
+		created := displayDate(item.CreatedAt, dateLayout)
+		updated := displayDate(item.UpdatedAt, dateLayout)
+		if period.contains(created) {
+			entry := toEntry(item, created)
+			summary.Opened = append(summary.Opened, entry)
+			if item.Priority == issue.PriorityHigh {
+				report.HighPriorityOpened = append(report.HighPriorityOpened, entry)
+			}
+		}
+		switch {
+		case item.Status == issue.StatusResolved && period.contains(updated):
+			summary.Resolved = append(summary.Resolved, toEntry(item, updated))
+		case item.Status.IsEndState() && period.contains(updated):
+			summary.Closed = append(summary.Closed, toEntry(item, updated))
+		}
+		if isOverdue(item, period.To) {
+			entry := toEntry(item, item.DueDate)
+			summary.Overdue = append(summary.Overdue, entry)
+			overdue = append(overdue, entry)
+		}
+	}
+	for i := range report.Categories {
+		summary := &report.Categories[i]
+		sortByDueDate(summary.Overdue)
+		report.Totals.Opened += len(summary.Opened)
+		report.Totals.Resolved += len(summary.Resolved)
+		report.Totals.Closed += len(summary.Closed)
+		report.Totals.Overdue += len(summary.Overdue)
+	}
+	sortByDueDate(overdue)
+	if len(overdue) > highlightLimit {
+		overdue = overdue[:highlightLimit]
+	}
+	report.LongestOverdue = overdue
+	if len(report.HighPriorityOpened) > highlightLimit {
+		report.HighPriorityOpened = report.HighPriorityOpened[:highlightLimit]
+	}
+	return report, nil
+}
+
+// Render は DD-REPORT-002 の報告書を形式に従って文書にする。
+func Render(format Format, report Report) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case FormatMarkdown:
+		err = markdownTemplate.Execute(&buf, report)
+	case FormatHTML:
+		err = htmlTemplate.Execute(&buf, report)
+	default:
+		return nil, apperr.Errorf(apperr.ErrValidation, "unsupported report format: %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("render report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Export は DD-REPORT-002 の報告書の出力を行う。
+// 目的: 集計と文書化をまとめて行い、GUI と CLI から同じ内容の報告書を書き出せるようにする。
+// 入力: ctx は中断通知、root はプロジェクトルート、format は形式、period は期間、destPath は出力先。
+// 出力: Result とエラー。
+// エラー: 形式が不正な場合、出力先が空の場合、走査・書き込みに失敗した場合、中断された場合に返す。
+// 副作用: destPath へファイルを書き込む。プロジェクト配下のファイルは変更しない。
+// 並行性: 読み取りのみのため課題操作と同時に実行してよいが、実行中の変更が含まれるかは保証しない。
+// 不変条件: 集計は Build に従う。
+// 関連DD: DD-REPORT-002
+func Export(ctx context.Context, root string, format Format, period Range, destPath string) (Result, error) {
+	if _, err := ParseFormat(string(format)); err != nil {
+		return Result{}, err
+	}
+	if destPath == "" {
+		return Result{}, apperr.New(apperr.ErrValidation, "report path is required")
+	}
+	report, err := Build(ctx, root, period)
+	if err != nil {
+		return Result{}, err
+	}
+	data, err := Render(format, report)
+	if err != nil {
+		return Result{}, err
+	}
+	if writeErr := atomicwrite.WriteFile(destPath, data); writeErr != nil {
+		return Result{}, fmt.Errorf("write report: %w", writeErr)
+	}
+	return Result{Path: destPath, Format: format, Range: period, Totals: report.Totals, Skipped: report.Skipped}, nil
+}
+
+// contains は DD-REPORT-002 の日付 (YYYY-MM-DD) が期間内かを判定する。日付は固定長のため文字列比較で前後を判定できる。
+func (r Range) contains(date string) bool {
+	return date != "" && date >= r.From && date <= r.To
+}
+
+// isOverdue は DD-REPORT-002 の期間の末日 asOf の時点の期限超過を判定する。判定の規則は DD-STATS-001 と同じとする。
+func isOverdue(item issue.Issue, asOf string) bool {
+	if item.Status.IsEndState() || item.Status == issue.StatusResolved {
+		return false
+	}
+	if _, err := time.Parse(dateLayout, item.DueDate); err != nil {
+		return false
+	}
+	return item.DueDate < asOf
+}
+
+// toEntry は DD-REPORT-002 の課題を報告書に載せる1件へ変換する。
+func toEntry(item issue.Issue, date string) Entry {
+	return Entry{
+		Category: item.Category,
+		IssueID:  item.IssueID,
+		Title:    item.Title,
+		Status:   item.Status,
+		Priority: item.Priority,
+		DueDate:  item.DueDate,
+		Date:     date,
+	}
+}
+
+// sortByDueDate は DD-REPORT-002 の課題を期限の古い順に並べる。同じ期限はカテゴリ・課題IDで順序を固定する。
+func sortByDueDate(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].DueDate != entries[j].DueDate {
+			return entries[i].DueDate < entries[j].DueDate
+		}
+		if entries[i].Category != entries[j].Category {
+			return entries[i].Category < entries[j].Category
+		}
+		return entries[i].IssueID < entries[j].IssueID
+	})
+}
+
+// displayDate は DD-DATA-002 の保存された日時を表示用のタイムゾーンで layout に従って返す。解析できない場合は空文字を返す。
+func displayDate(value, layout string) string {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return ""
+	}
+	return parsed.In(timeutil.DisplayLocation()).Format(layout)
+}
+
+// projectName は DD-REPORT-002 の報告書の表題に用いるプロジェクトルートのフォルダ名を返す。
+func projectName(root string) string {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	return filepath.Base(root)
+}
+
+// markdownEscaper は DD-REPORT-002 の Markdown で書式として解釈される記号を表す。
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`, "\r\n", " ", "\n", " ",
+)
+
+// escapeMarkdown は DD-REPORT-002 の課題のタイトルなどを Markdown の表や箇条書きにそのまま載せられるよう記号をエスケープし、改行を空白にする。
+func escapeMarkdown(value string) string {
+	return markdownEscaper.Replace(value)
+}
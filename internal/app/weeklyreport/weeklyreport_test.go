@@ -0,0 +1,119 @@
+// weeklyreport_test.go は期間の指定、課題の動きの集計、Markdown/HTML の報告書の出力のテストを行う。
+package weeklyreport
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ratta/internal/domain/apperr"
+)
+
+// newProject はテスト用に日時を固定した課題JSONを2カテゴリへ配置し、ルートを返す。
+func newProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"alpha/a1.json": `{"issue_id":"a1","title":"new | high","status":"Open","priority":"High","due_date":"2024-01-20","created_at":"2024-01-09T10:00:00+09:00","updated_at":"2024-01-09T10:00:00+09:00"}`,
+		"alpha/a2.json": `{"issue_id":"a2","title":"fixed","status":"Resolved","priority":"Low","due_date":"2024-01-01","created_at":"2023-12-01T10:00:00+09:00","updated_at":"2024-01-10T10:00:00+09:00"}`,
+		"alpha/a3.json": `{"issue_id":"a3","title":"late","status":"Working","priority":"Medium","due_date":"2024-01-05","created_at":"2023-12-01T10:00:00+09:00","updated_at":"2023-12-02T10:00:00+09:00"}`,
+		"beta/b1.json":  `{"issue_id":"b1","title":"<rejected>","status":"Rejected","priority":"Low","due_date":"2024-01-01","created_at":"2023-11-01T10:00:00+09:00","updated_at":"2024-01-14T23:00:00+09:00"}`,
+		"beta/b2.json":  `{"issue_id":"b2","title":"closed before","status":"Closed","priority":"Low","due_date":"2024-01-01","created_at":"2023-11-01T10:00:00+09:00","updated_at":"2024-01-07T10:00:00+09:00"}`,
+		"gamma/.keep":   ``,
+	}
+	for name, body := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	return root
+}
+
+func TestParseRange_DefaultsToLastSevenDays(t *testing.T) {
+	// 期間を省略すると今日を末日とする7日間とし、逆転した期間や不正な日付は入力の不備とすることを確認する。
+	previous := now
+	now = func() time.Time { return time.Date(2024, 1, 14, 12, 0, 0, 0, time.Local) }
+	t.Cleanup(func() { now = previous })
+
+	period, err := ParseRange("", "")
+	if err != nil || period != (Range{From: "2024-01-08", To: "2024-01-14"}) {
+		t.Fatalf("unexpected range: %+v %v", period, err)
+	}
+	for _, spec := range [][2]string{{"2024-01-15", "2024-01-14"}, {"2024/01/01", ""}} {
+		if _, err := ParseRange(spec[0], spec[1]); !errors.Is(err, apperr.ErrValidation) {
+			t.Fatalf("expected validation error for %v, got %v", spec, err)
+		}
+	}
+}
+
+func TestBuild_ClassifiesIssuesPerCategory(t *testing.T) {
+	// 作成日・最終更新日・期限から新規・対応済み・完了・期限超過を振り分け、課題の無いカテゴリも含めることを確認する。
+	root := newProject(t)
+	report, err := Build(context.Background(), root, Range{From: "2024-01-08", To: "2024-01-14"})
+	if err != nil {
+		t.Fatalf("Build error: %v", err)
+	}
+	if len(report.Categories) != 3 || report.Categories[2].Name != "gamma" || report.Categories[2].HasActivity() {
+		t.Fatalf("unexpected categories: %+v", report.Categories)
+	}
+	if report.Totals != (Counts{Opened: 1, Resolved: 1, Closed: 1, Overdue: 1}) {
+		t.Fatalf("unexpected totals: %+v", report.Totals)
+	}
+	alpha := report.Categories[0]
+	if alpha.Opened[0].IssueID != "a1" || alpha.Resolved[0].IssueID != "a2" || alpha.Overdue[0].IssueID != "a3" {
+		t.Fatalf("unexpected alpha: %+v", alpha)
+	}
+	if report.Categories[1].Closed[0].IssueID != "b1" {
+		t.Fatalf("unexpected beta: %+v", report.Categories[1])
+	}
+	if len(report.HighPriorityOpened) != 1 || len(report.LongestOverdue) != 1 {
+		t.Fatalf("unexpected highlights: %+v %+v", report.HighPriorityOpened, report.LongestOverdue)
+	}
+}
+
+func TestExport_WritesMarkdownAndHTML(t *testing.T) {
+	// Markdown は表の区切り記号をエスケープし、HTML はタイトルを HTML エスケープして出力することを確認する。
+	root := newProject(t)
+	period := Range{From: "2024-01-08", To: "2024-01-14"}
+	dir := t.TempDir()
+	mdPath := filepath.Join(dir, "weekly.md")
+	result, err := Export(context.Background(), root, FormatMarkdown, period, mdPath)
+	if err != nil {
+		t.Fatalf("Export error: %v", err)
+	}
+	if result.Totals.Opened != 1 || result.Range != period {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	markdown := readFile(t, mdPath)
+	if !strings.Contains(markdown, "| alpha | 1 | 1 | 0 | 1 |") || !strings.Contains(markdown, `new \| high`) {
+		t.Fatalf("unexpected markdown:\n%s", markdown)
+	}
+	htmlPath := filepath.Join(dir, "weekly.html")
+	if _, err := Export(context.Background(), root, FormatHTML, period, htmlPath); err != nil {
+		t.Fatalf("Export error: %v", err)
+	}
+	if html := readFile(t, htmlPath); strings.Contains(html, "<rejected>") || !strings.Contains(html, "&lt;rejected&gt;") {
+		t.Fatalf("unexpected html:\n%s", html)
+	}
+	if _, err := Export(context.Background(), root, Format("pdf"), period, htmlPath); !errors.Is(err, apperr.ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+// readFile はテスト用にファイルの内容を文字列で返す。
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return string(data)
+}
@@ -0,0 +1,130 @@
+// Package apperr は DD-BE-003 のエラーの種別と DD-BE-004 の対象のパス・復旧の指針を提供し、UI 向けのエラーコードやメッセージへの変換は扱わない。
+// 種別は errors.Is で判定し、メッセージの文言に依存しない。
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"syscall"
+)
+
+// エラーの種別を表す。present.MapError が ApiErrorDTO.error_code へ対応付ける。
+var (
+	// ErrValidation は入力や前提条件の不備を表す (E_VALIDATION)。
+	ErrValidation = errors.New("invalid input")
+	// ErrPermission は操作モードや権限の不足を表す (E_PERMISSION)。
+	ErrPermission = errors.New("permission denied")
+	// ErrNotFound は対象が存在しないことを表す (E_NOT_FOUND)。
+	ErrNotFound = errors.New("not found")
+	// ErrConflict は既存のデータや他の操作との衝突を表す (E_CONFLICT)。
+	ErrConflict = errors.New("conflict")
+	// ErrReadOnly は対象が読み取り専用で変更できないことを表す (E_CONFLICT)。
+	ErrReadOnly = errors.New("read-only")
+	// ErrSchemaInvalid は対象のファイルがスキーマに適合しないことを表す (E_CONFLICT)。
+	ErrSchemaInvalid = errors.New("schema invalid")
+	// ErrCrypto はパスワードの照合や認証情報の扱いの失敗を表す (E_CRYPTO)。
+	ErrCrypto = errors.New("password verification failed")
+)
+
+// kindError は DD-BE-003 の種別を持つエラーを表す。メッセージは err のものをそのまま返す。
+type kindError struct {
+	kind error
+	err  error
+}
+
+// Error はエラーメッセージを返す。種別の文言は含めない。
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap は種別と元のエラーを返し、errors.Is/As でいずれも判定できるようにする。
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// New は DD-BE-003 の種別 kind を持つ message のエラーを作る。
+func New(kind error, message string) error {
+	return &kindError{kind: kind, err: errors.New(message)}
+}
+
+// Errorf は DD-BE-003 の種別 kind を持つエラーを fmt.Errorf と同じ書式で作る。%w で包んだエラーも errors.Is/As で辿れる。
+func Errorf(kind error, format string, args ...any) error {
+	return &kindError{kind: kind, err: fmt.Errorf(format, args...)}
+}
+
+// Kind は DD-BE-003 の err が持つ種別を返す。種別を持たない場合は nil を返す。
+// 複数の種別を持つ場合は、ErrValidation・ErrPermission・ErrNotFound・ErrConflict・ErrReadOnly・ErrSchemaInvalid・ErrCrypto の順で先に一致したものを返す。
+func Kind(err error) error {
+	for _, kind := range kinds {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+	return nil
+}
+
+// kinds は DD-BE-003 の Kind で判定する順の種別を表す。
+var kinds = []error{ErrValidation, ErrPermission, ErrNotFound, ErrConflict, ErrReadOnly, ErrSchemaInvalid, ErrCrypto}
+
+// Hint は DD-BE-004 の復旧の指針の種類を表す。利用者向けの文言は present が表示言語に応じて選ぶ。
+type Hint string
+
+const (
+	// HintCheckPermission はファイルやフォルダのアクセス権限の確認を促す。
+	HintCheckPermission Hint = "check_permission"
+	// HintCheckExists はファイルの移動・削除の確認と再読み込みを促す。
+	HintCheckExists Hint = "check_exists"
+	// HintCheckDiskSpace はディスクの空き容量の確認を促す。
+	HintCheckDiskSpace Hint = "check_disk_space"
+	// HintRetryWrite は他のプログラムによる使用の確認と再実行を促す。
+	HintRetryWrite Hint = "retry_write"
+	// HintCheckJSON は JSON の内容 (git のマージ結果など) の確認を促す。
+	HintCheckJSON Hint = "check_json"
+	// HintMergeChanges は他の利用者が変更した最新の内容との比較と変更の統合を促す。
+	HintMergeChanges Hint = "merge_changes"
+)
+
+// PathError は DD-BE-004 の対象のパスと復旧の指針を持つエラーを表す。メッセージは Err のものをそのまま返す。
+type PathError struct {
+	Path string
+	Hint Hint
+	Err  error
+}
+
+// Error はエラーメッセージを返す。パスと指針は含めない。
+func (e *PathError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap は元のエラーを返し、種別や fs のエラーを errors.Is/As で判定できるようにする。
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// WithPath は DD-BE-004 の err に対象のパス path と復旧の指針 hint を加える。err が nil の場合は nil を返す。
+// hint が空の場合は IOHint で err から判断する。
+func WithPath(err error, path string, hint Hint) error {
+	if err == nil {
+		return nil
+	}
+	if hint == "" {
+		hint = IOHint(err, "")
+	}
+	return &PathError{Path: path, Hint: hint, Err: err}
+}
+
+// IOHint は DD-BE-004 のファイル操作のエラー err に応じた復旧の指針を返す。
+// アクセス権限の不足・対象の不在・空き容量の不足のいずれでもない場合は fallback を返す。
+func IOHint(err error, fallback Hint) Hint {
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		return HintCheckPermission
+	case errors.Is(err, fs.ErrNotExist):
+		return HintCheckExists
+	case errors.Is(err, syscall.ENOSPC):
+		return HintCheckDiskSpace
+	default:
+		return fallback
+	}
+}
@@ -0,0 +1,54 @@
+// apperr_test.go はエラーの種別と対象のパス・復旧の指針の判定のテストを行い、UI 向けの変換は扱わない。
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"testing"
+)
+
+func TestErrorf_KeepsMessageAndChain(t *testing.T) {
+	// 種別を持つエラーはメッセージを変えず、種別と包んだエラーのいずれも errors.Is で辿れることを確認する。
+	err := fmt.Errorf("rename: %w", Errorf(ErrNotFound, "category not found: %w", os.ErrNotExist))
+	if err.Error() != "rename: category not found: file does not exist" {
+		t.Fatalf("unexpected message: %s", err.Error())
+	}
+	if !errors.Is(err, ErrNotFound) || !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected kind and wrapped error: %v", err)
+	}
+	if errors.Is(err, ErrPermission) {
+		t.Fatal("unexpected kind")
+	}
+}
+
+func TestKind_ReturnsFirstMatchingKind(t *testing.T) {
+	// 種別を持たないエラーは nil、複数の種別を持つ場合は判定順で先のものを返すことを確認する。
+	if kind := Kind(errors.New("unexpected")); kind != nil {
+		t.Fatalf("unexpected kind: %v", kind)
+	}
+	err := Errorf(ErrReadOnly, "save: %w", New(ErrPermission, "observer mode"))
+	if kind := Kind(err); kind != ErrPermission {
+		t.Fatalf("unexpected kind: %v", kind)
+	}
+}
+
+func TestWithPath_KeepsMessageAndChoosesHint(t *testing.T) {
+	// 対象のパスを加えてもメッセージと種別は変わらず、指針を省略した場合は元のエラーから判断することを確認する。
+	if WithPath(nil, "a.json", HintCheckJSON) != nil {
+		t.Fatal("nil error must stay nil")
+	}
+	cause := &fs.PathError{Op: "open", Path: "a.json", Err: fs.ErrPermission}
+	err := WithPath(fmt.Errorf("read issue: %w", cause), "a.json", "")
+	var pathErr *PathError
+	if !errors.As(err, &pathErr) || pathErr.Path != "a.json" || pathErr.Hint != HintCheckPermission {
+		t.Fatalf("unexpected path error: %+v", pathErr)
+	}
+	if err.Error() != "read issue: open a.json: permission denied" || !errors.Is(err, fs.ErrPermission) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hint := IOHint(errors.New("busy"), HintRetryWrite); hint != HintRetryWrite {
+		t.Fatalf("unexpected fallback hint: %s", hint)
+	}
+}
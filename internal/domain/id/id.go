@@ -3,7 +3,10 @@
 package id
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"strings"
 
 	"github.com/google/uuid"
 	gonanoid "github.com/matoous/go-nanoid/v2"
@@ -12,6 +15,18 @@ import (
 const (
 	nanoAlphabet = "_-0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
 	nanoIDLength = 9
+
+	// MaxLength は DD-DATA-003/005 の nanoid に指定できる長さの上限を表す。
+	MaxLength = 32
+	// MinEntropyBits は DD-DATA-003/005 の nanoid に求める情報量 (長さ × log2(文字種の数)) の下限を表す。
+	// 既定の 64 文字種・9 文字 (54 ビット) から文字種を減らしても、衝突しにくさを大きく損なわない値とする。
+	MinEntropyBits = 48
+
+	// SchemeNanoID は DD-DATA-003/005 の nanoid による採番 (既定) を表す。
+	SchemeNanoID = "nanoid"
+	// SchemeULID は DD-DATA-003/005 の ULID による採番を表す。
+	// 先頭が作成時刻のため、ファイル名の並びが作成順になる。
+	SchemeULID = "ulid"
 )
 
 var (
@@ -19,14 +34,98 @@ var (
 	nanoidGenerate  = gonanoid.Generate
 )
 
-// NewIssueID は DD-DATA-003 の issue_id 仕様に従い nanoid (9 文字) を生成する。
-func NewIssueID() (string, error) {
-	return newNanoID()
+// Format は DD-DATA-003/005 の ID の採番方式と、nanoid の文字種と長さを表す。
+// Scheme が空の場合は nanoid、Alphabet が空の場合は既定の 64 文字種、Length が 0 の場合は既定の 9 文字を用いる。
+// ULID では文字種と長さは固定で、指定できない。
+type Format struct {
+	Scheme   string
+	Alphabet string
+	Length   int
+}
+
+// withDefaults は DD-DATA-003/005 の未指定の項目を既定値で補った Format を返す。
+func (f Format) withDefaults() Format {
+	if f.Scheme == "" {
+		f.Scheme = SchemeNanoID
+	}
+	if f.Scheme == SchemeULID {
+		return f
+	}
+	if f.Alphabet == "" {
+		f.Alphabet = nanoAlphabet
+	}
+	if f.Length == 0 {
+		f.Length = nanoIDLength
+	}
+	return f
+}
+
+// EntropyBits は DD-DATA-003/005 の1つの ID が持つ情報量 (ビット) を返す。ULID では乱数部分の情報量を返す。
+func (f Format) EntropyBits() float64 {
+	resolved := f.withDefaults()
+	if resolved.Scheme == SchemeULID {
+		return ulidRandomBytes * 8
+	}
+	return float64(resolved.Length) * math.Log2(float64(len(resolved.Alphabet)))
+}
+
+// Validate は DD-DATA-003/005 の文字種と長さを確認する。
+// 目的: ファイル名やパスに使えない文字、衝突しやすい短い ID を設定で選べないようにする。
+// 入力: なし。
+// 出力: 扱えない場合のエラー。
+// エラー: 未知の採番方式、ULID で文字種や長さを指定した場合、文字種に英数字・`_`・`-` 以外や重複がある場合、
+// 長さが 1〜MaxLength でない場合、情報量が MinEntropyBits に満たない場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 未指定の項目は既定値として確認する。
+// 関連DD: DD-DATA-003, DD-DATA-005, DD-PROJCONF-001
+func (f Format) Validate() error {
+	resolved := f.withDefaults()
+	switch resolved.Scheme {
+	case SchemeNanoID:
+	case SchemeULID:
+		if f.Alphabet != "" || f.Length != 0 {
+			return errors.New("id alphabet and length cannot be set for ulid")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported id scheme: %s", f.Scheme)
+	}
+	if resolved.Length < 0 || resolved.Length > MaxLength {
+		return fmt.Errorf("id length must be between 1 and %d", MaxLength)
+	}
+	seen := make(map[rune]struct{}, len(resolved.Alphabet))
+	for _, char := range resolved.Alphabet {
+		if !isIDChar(char) {
+			return fmt.Errorf("id alphabet must contain only letters, digits, '_' and '-': %q", char)
+		}
+		if _, dup := seen[char]; dup {
+			return fmt.Errorf("id alphabet contains duplicated character: %q", char)
+		}
+		seen[char] = struct{}{}
+	}
+	if len(seen) < 2 {
+		return errors.New("id alphabet must contain at least 2 characters")
+	}
+	if bits := resolved.EntropyBits(); bits < MinEntropyBits {
+		return fmt.Errorf("id entropy %.1f bits is below the minimum of %d bits; use a longer length or more characters", bits, MinEntropyBits)
+	}
+	return nil
+}
+
+// isIDChar は DD-DATA-003/005 の ID に使える文字 (英数字・`_`・`-`) かを判定する。
+func isIDChar(char rune) bool {
+	return char < 0x80 && (char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= '0' && char <= '9' || strings.ContainsRune("_-", char))
+}
+
+// NewIssueID は DD-DATA-003 の issue_id 仕様に従い format の採番方式 (nanoid・ULID) で生成する。
+func NewIssueID(format Format) (string, error) {
+	return newID(format)
 }
 
-// NewAttachmentID は DD-DATA-005 の attachment_id 仕様に従い nanoid (9 文字) を生成する。
-func NewAttachmentID() (string, error) {
-	return newNanoID()
+// NewAttachmentID は DD-DATA-005 の attachment_id 仕様に従い format の採番方式 (nanoid・ULID) で生成する。
+func NewAttachmentID(format Format) (string, error) {
+	return newID(format)
 }
 
 // NewCommentID は DD-DATA-004 の comment_id 仕様に従い UUID v7 を生成する。
@@ -38,9 +137,16 @@ func NewCommentID() (string, error) {
 	return value.String(), nil
 }
 
-// newNanoID は DD-DATA-003/DD-DATA-005 の ID 仕様に従い nanoid (9 文字) を生成する。
-func newNanoID() (string, error) {
-	value, err := nanoidGenerate(nanoAlphabet, nanoIDLength)
+// newID は DD-DATA-003/DD-DATA-005 の ID 仕様に従い format の採番方式で ID を生成する。扱えない format はエラーとする。
+func newID(format Format) (string, error) {
+	if err := format.Validate(); err != nil {
+		return "", err
+	}
+	resolved := format.withDefaults()
+	if resolved.Scheme == SchemeULID {
+		return newULID()
+	}
+	value, err := nanoidGenerate(resolved.Alphabet, resolved.Length)
 	if err != nil {
 		return "", fmt.Errorf("nanoid: %w", err)
 	}
@@ -1,9 +1,12 @@
 package id
 
 import (
+	"bytes"
 	"errors"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -38,7 +41,7 @@ func TestNanoIDs_FormatAndUniqueness(t *testing.T) {
 	seen := make(map[string]struct{})
 
 	for i := 0; i < 100; i++ {
-		value, err := NewIssueID()
+		value, err := NewIssueID(Format{})
 		if err != nil {
 			t.Fatalf("NewIssueID error: %v", err)
 		}
@@ -52,7 +55,7 @@ func TestNanoIDs_FormatAndUniqueness(t *testing.T) {
 	}
 
 	// attachment_id も同じフォーマット要件を満たすことを確認する。
-	attachmentID, err := NewAttachmentID()
+	attachmentID, err := NewAttachmentID(Format{})
 	if err != nil {
 		t.Fatalf("NewAttachmentID error: %v", err)
 	}
@@ -96,3 +99,66 @@ func TestCommentID_FormatAndUniqueness(t *testing.T) {
 		t.Fatalf("unexpected comment id format: %s", second)
 	}
 }
+
+func TestFormat_CustomAlphabetAndLength(t *testing.T) {
+	// 指定した文字種と長さで生成し、使えない文字・重複・情報量の不足を拒否することを確認する。
+	withDeterministicNanoGenerator(t)
+	format := Format{Alphabet: "23456789abcdefghjkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ", Length: 12}
+	value, err := NewIssueID(format)
+	if err != nil {
+		t.Fatalf("NewIssueID error: %v", err)
+	}
+	if len(value) != 12 || strings.Trim(value, format.Alphabet) != "" {
+		t.Fatalf("unexpected issue id: %s", value)
+	}
+
+	for _, invalid := range []Format{
+		{Alphabet: "abc/def"},
+		{Alphabet: "aabcdefgh", Length: 30},
+		{Alphabet: "0123456789", Length: 9},
+		{Length: MaxLength + 1},
+		{Length: -1},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("expected %+v to be rejected", invalid)
+		}
+		if _, err := NewAttachmentID(invalid); err == nil {
+			t.Fatalf("expected generation with %+v to fail", invalid)
+		}
+	}
+	if err := (Format{}).Validate(); err != nil {
+		t.Fatalf("default format must be valid: %v", err)
+	}
+}
+
+func TestULID_SortsByCreationTime(t *testing.T) {
+	// ULID は時刻を先頭に Crockford Base32 で表し、作成順に辞書順で並び、文字種や長さの指定を拒否することを確認する。
+	previousNow, previousEntropy := ulidNow, ulidEntropy
+	t.Cleanup(func() { ulidNow, ulidEntropy = previousNow, previousEntropy })
+	ulidEntropy = bytes.NewReader(make([]byte, 2*ulidRandomBytes))
+	ulidNow = func() time.Time { return time.UnixMilli(1469918176385) }
+
+	format := Format{Scheme: SchemeULID}
+	first, err := NewIssueID(format)
+	if err != nil {
+		t.Fatalf("NewIssueID error: %v", err)
+	}
+	if first != "01ARYZ6S410000000000000000" {
+		t.Fatalf("unexpected ulid: %s", first)
+	}
+	ulidNow = func() time.Time { return time.UnixMilli(1469918176386) }
+	second, err := NewAttachmentID(format)
+	if err != nil {
+		t.Fatalf("NewAttachmentID error: %v", err)
+	}
+	if second <= first {
+		t.Fatalf("expected %s to sort after %s", second, first)
+	}
+
+	if err := (Format{Scheme: SchemeULID, Length: 30}).Validate(); err == nil {
+		t.Fatal("expected length with ulid to be rejected")
+	}
+	if err := (Format{Scheme: "uuid"}).Validate(); err == nil {
+		t.Fatal("expected unknown scheme to be rejected")
+	}
+}
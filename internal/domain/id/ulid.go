@@ -0,0 +1,66 @@
+// ulid.go は DD-DATA-003/005 の ULID の生成を担い、採番方式の選択は扱わない。
+package id
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// ulidAlphabet は ULID の Crockford Base32 の文字種を表す。I・L・O・U を含まない。
+	ulidAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	// ulidLength は ULID の文字数 (時刻 10 文字 + 乱数 16 文字) を表す。
+	ulidLength = 26
+	// ulidRandomBytes は ULID の乱数部分のバイト数 (80 ビット) を表す。
+	ulidRandomBytes = 10
+)
+
+var (
+	ulidNow     = time.Now
+	ulidEntropy = rand.Reader
+)
+
+// newULID は DD-DATA-003/005 の ULID を生成する。
+// 目的: ファイル名の辞書順が作成順と一致する ID を、外部ライブラリなしで生成する。
+// 入力: なし。
+// 出力: 26 文字の ULID とエラー。
+// エラー: 乱数の取得に失敗した場合に返す。
+// 副作用: 乱数を読み取る。
+// 並行性: スレッドセーフ。
+// 不変条件: 先頭 48 ビットは UNIX 時刻のミリ秒、残り 80 ビットは乱数とする。同じミリ秒内の順序は保証しない。
+// 関連DD: DD-DATA-003, DD-DATA-005
+func newULID() (string, error) {
+	var data [16]byte
+	millis := uint64(ulidNow().UnixMilli())
+	for i := 5; i >= 0; i-- {
+		data[i] = byte(millis)
+		millis >>= 8
+	}
+	if _, err := io.ReadFull(ulidEntropy, data[6:]); err != nil {
+		return "", fmt.Errorf("ulid entropy: %w", err)
+	}
+	return encodeULID(data), nil
+}
+
+// encodeULID は DD-DATA-003/005 の 128 ビットを先頭から 5 ビットずつ Crockford Base32 で表す。
+// 128 ビットは 5 の倍数でないため、先頭の文字は上位 3 ビットのみを表す。
+func encodeULID(data [16]byte) string {
+	out := make([]byte, ulidLength)
+	var acc uint64
+	bits := 0
+	pos := ulidLength - 1
+	for i := len(data) - 1; i >= 0; i-- {
+		acc |= uint64(data[i]) << bits
+		bits += 8
+		for bits >= 5 {
+			out[pos] = ulidAlphabet[acc&0x1f]
+			pos--
+			acc >>= 5
+			bits -= 5
+		}
+	}
+	out[pos] = ulidAlphabet[acc&0x1f]
+	return string(out)
+}
@@ -0,0 +1,23 @@
+// filename.go はカテゴリ配下のファイル名から課題JSONを判別する規則を提供し、ファイルI/Oは扱わない。
+package issue
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// IsIssueFileName は DD-LOAD-003 の課題JSON判定を行う。
+// 目的: カテゴリ直下のファイル名が課題JSONとして扱う対象かを判定する。
+// 入力: name はディレクトリ要素を含まないファイル名。
+// 出力: 課題JSONであれば true。
+// エラー: なし。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: ドット始まりのファイル (.category.json などのメタデータ) は課題として扱わない。
+// 関連DD: DD-LOAD-003, DD-CATMETA-001
+func IsIssueFileName(name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	return filepath.Ext(name) == ".json"
+}
@@ -61,21 +61,37 @@ func (c Company) IsValid() bool {
 	return c == CompanyContractor || c == CompanyVendor
 }
 
+const (
+	// CurrentVersion は DD-DATA-003 の新たに作成する課題JSONの版を表す。
+	// 版 2 では任意の tags・custom_fields を持てる。版 1 の課題は DD-MIGRATE-001 の移行まで版 1 のまま読み書きする。
+	CurrentVersion = 2
+	// minSupportedVersion は DD-DATA-003 の読み書きできる最も古い版を表す。
+	minSupportedVersion = 1
+)
+
+// IsSupportedVersion は DD-DATA-003 の読み書きできる課題JSONの版かを判定する。
+func IsSupportedVersion(version int) bool {
+	return version >= minSupportedVersion && version <= CurrentVersion
+}
+
 // Issue は DD-DATA-003 の課題データを表す。
+// Tags・CustomFields は版 2 以降の任意項目を表し、空の場合は保存しない。
 type Issue struct {
-	Version       int       `json:"version"`
-	IssueID       string    `json:"issue_id"`
-	Category      string    `json:"category"`
-	Title         string    `json:"title"`
-	Description   string    `json:"description"`
-	Status        Status    `json:"status"`
-	Priority      Priority  `json:"priority"`
-	OriginCompany Company   `json:"origin_company"`
-	Assignee      string    `json:"assignee,omitempty"`
-	CreatedAt     string    `json:"created_at"`
-	UpdatedAt     string    `json:"updated_at"`
-	DueDate       string    `json:"due_date"`
-	Comments      []Comment `json:"comments"`
+	Version       int            `json:"version"`
+	IssueID       string         `json:"issue_id"`
+	Category      string         `json:"category"`
+	Title         string         `json:"title"`
+	Description   string         `json:"description"`
+	Status        Status         `json:"status"`
+	Priority      Priority       `json:"priority"`
+	OriginCompany Company        `json:"origin_company"`
+	Assignee      string         `json:"assignee,omitempty"`
+	CreatedAt     string         `json:"created_at"`
+	UpdatedAt     string         `json:"updated_at"`
+	DueDate       string         `json:"due_date"`
+	Tags          []string       `json:"tags,omitempty"`
+	CustomFields  map[string]any `json:"custom_fields,omitempty"`
+	Comments      []Comment      `json:"comments"`
 }
 
 // Comment は DD-DATA-004 のコメントデータを表す。
@@ -13,6 +13,8 @@ const (
 	maxNameLength       = 255
 	maxCommentBodyBytes = 100 * 1024
 	maxAttachments      = 5
+	maxTags             = 20
+	maxTagLength        = 50
 )
 
 // ValidationError は DD-DATA-003/004 の入力不整合を表す。
@@ -101,6 +103,7 @@ func ValidateIssue(issue Issue) ValidationErrors {
 	} else if !isValidDate(issue.DueDate) {
 		errs = append(errs, ValidationError{Field: "due_date", Message: "invalid format"})
 	}
+	errs = append(errs, validateExtensions(issue)...)
 	if issue.Comments == nil {
 		errs = append(errs, ValidationError{Field: "comments", Message: "required"})
 	} else {
@@ -112,6 +115,34 @@ func ValidateIssue(issue Issue) ValidationErrors {
 	return errs
 }
 
+// validateExtensions は DD-DATA-003 の版 2 の任意項目 (tags・custom_fields) を検証する。版 1 の課題は持てない。
+func validateExtensions(issue Issue) ValidationErrors {
+	var errs ValidationErrors
+	if issue.Version < 2 {
+		if len(issue.Tags) > 0 {
+			errs = append(errs, ValidationError{Field: "tags", Message: "requires version 2"})
+		}
+		if len(issue.CustomFields) > 0 {
+			errs = append(errs, ValidationError{Field: "custom_fields", Message: "requires version 2"})
+		}
+		return errs
+	}
+	if len(issue.Tags) > maxTags {
+		errs = append(errs, ValidationError{Field: "tags", Message: "too many"})
+	}
+	seen := make(map[string]struct{}, len(issue.Tags))
+	for i, tag := range issue.Tags {
+		if err := validateRequiredLength(fmt.Sprintf("tags[%d]", i), tag, maxTagLength); err != nil {
+			errs = append(errs, *err)
+		}
+		if _, dup := seen[tag]; dup {
+			errs = append(errs, ValidationError{Field: fmt.Sprintf("tags[%d]", i), Message: "duplicated"})
+		}
+		seen[tag] = struct{}{}
+	}
+	return errs
+}
+
 // ValidateComment は DD-DATA-004 のコメント必須項目を検証する。
 func ValidateComment(comment Comment) ValidationErrors {
 	var errs ValidationErrors
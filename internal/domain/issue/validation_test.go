@@ -69,6 +69,37 @@ func TestValidateIssue_DueDateFormat(t *testing.T) {
 	}
 }
 
+func TestValidateIssue_TagsAndCustomFieldsRequireVersion2(t *testing.T) {
+	// 版 1 の課題は tags・custom_fields を持てず、版 2 では重複した tag のみがエラーになることを確認する。
+	base := Issue{
+		Version:       1,
+		IssueID:       "abc",
+		Category:      "cat",
+		Title:         "t",
+		Description:   "d",
+		Status:        StatusOpen,
+		Priority:      PriorityHigh,
+		OriginCompany: CompanyVendor,
+		CreatedAt:     "2024-01-01T00:00:00Z",
+		UpdatedAt:     "2024-01-01T00:00:00Z",
+		DueDate:       "2024-01-01",
+		Tags:          []string{"ui", "db"},
+		CustomFields:  map[string]any{"build": "1.0"},
+		Comments:      []Comment{},
+	}
+	if errs := ValidateIssue(base); len(errs) != 2 || errs[0].Field != "tags" || errs[1].Field != "custom_fields" {
+		t.Fatalf("unexpected version 1 errors: %v", errs)
+	}
+	base.Version = CurrentVersion
+	if errs := ValidateIssue(base); len(errs) != 0 {
+		t.Fatalf("unexpected version 2 errors: %v", errs)
+	}
+	base.Tags = []string{"ui", "ui"}
+	if errs := ValidateIssue(base); len(errs) != 1 || errs[0].Field != "tags[1]" {
+		t.Fatalf("expected duplicated tag error: %v", errs)
+	}
+}
+
 func TestValidateComment_BodySizeAndAttachments(t *testing.T) {
 	// コメント本文のサイズ制限と添付数上限を確認する。
 	comment := Comment{
@@ -117,3 +148,18 @@ func TestPrefixErrors_AddsPrefix(t *testing.T) {
 		t.Fatalf("unexpected field: %s", prefixed[0].Field)
 	}
 }
+
+func TestIsIssueFileName_SkipsDotFiles(t *testing.T) {
+	// カテゴリメタデータ (.category.json) を課題JSONと誤認しないことを確認する。
+	cases := map[string]bool{
+		"abc123DEF.json": true,
+		".category.json": false,
+		"note.txt":       false,
+		"abc.json.tmp.1": false,
+	}
+	for name, expected := range cases {
+		if got := IsIssueFileName(name); got != expected {
+			t.Fatalf("IsIssueFileName(%q) = %v, want %v", name, got, expected)
+		}
+	}
+}
@@ -7,4 +7,6 @@ type Mode string
 const (
 	ModeContractor Mode = "Contractor"
 	ModeVendor     Mode = "Vendor"
+	// ModeObserver は閲覧のみを許すモードを表す。課題・コメント・カテゴリを変更しない。
+	ModeObserver Mode = "Observer"
 )
@@ -2,6 +2,28 @@ package mode
 
 import "ratta/internal/domain/issue"
 
+// CanMutate は DD-BE-003 の操作モードで課題・コメント・カテゴリを変更できるかを判定する。Observer は変更できない。
+func CanMutate(mode Mode) bool {
+	return mode == ModeContractor || mode == ModeVendor
+}
+
+// CanWriteCategory は DD-PERM-001 のカテゴリの書き込みを許すモード writers に current が含まれるかを判定する。
+// writers が空の場合はカテゴリに制限が無いものとして CanMutate に従う。Observer はいずれの場合も変更できない。
+func CanWriteCategory(writers []Mode, current Mode) bool {
+	if !CanMutate(current) {
+		return false
+	}
+	if len(writers) == 0 {
+		return true
+	}
+	for _, writer := range writers {
+		if writer == current {
+			return true
+		}
+	}
+	return false
+}
+
 // CanTransitionStatus は DD-DATA-003/F-004 の遷移許可を判定する。
 func CanTransitionStatus(current issue.Status, next issue.Status, mode Mode) bool {
 	if !current.IsValid() || !next.IsValid() {
@@ -46,3 +46,33 @@ func TestCanTransitionStatus_EndStateIsLocked(t *testing.T) {
 		t.Fatal("expected rejected to be locked")
 	}
 }
+
+func TestCanMutate_ObserverIsReadOnly(t *testing.T) {
+	// Contractor と Vendor は変更でき、Observer と未知のモードは変更できないことを確認する。
+	if !CanMutate(ModeContractor) || !CanMutate(ModeVendor) {
+		t.Fatal("expected contractor and vendor to mutate")
+	}
+	if CanMutate(ModeObserver) || CanMutate(Mode("")) {
+		t.Fatal("expected observer and unknown mode to be read-only")
+	}
+	if CanTransitionStatus(issue.StatusOpen, issue.StatusWorking, ModeObserver) {
+		t.Fatal("expected observer to reject transitions")
+	}
+}
+
+func TestCanWriteCategory_RestrictsToWriters(t *testing.T) {
+	// 制限の無いカテゴリは閲覧専用以外で変更でき、制限のあるカテゴリは writers に含まれるモードのみ変更できることを確認する。
+	if !CanWriteCategory(nil, ModeVendor) || CanWriteCategory(nil, ModeObserver) {
+		t.Fatal("expected unrestricted category to follow CanMutate")
+	}
+	contractorOnly := []Mode{ModeContractor}
+	if !CanWriteCategory(contractorOnly, ModeContractor) {
+		t.Fatal("expected contractor to write contractor-only category")
+	}
+	if CanWriteCategory(contractorOnly, ModeVendor) {
+		t.Fatal("expected vendor to be rejected")
+	}
+	if CanWriteCategory([]Mode{ModeObserver}, ModeObserver) {
+		t.Fatal("expected observer to be rejected even when listed")
+	}
+}
@@ -1,17 +1,92 @@
 // Package timeutil は時刻表現の共通処理を提供し、永続化I/Oは扱わない。
+// 保存用の表記 (OS のタイムゾーンまたは UTC) と、表示用のタイムゾーンへの変換を分けて扱う。
 package timeutil
 
-import "time"
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	// Windows など IANA のタイムゾーン情報を持たない環境でも表示用のタイムゾーンを解決できるよう同梱する。
+	_ "time/tzdata"
+)
 
 // now は DD-DATA-002 の時刻仕様をテストで固定するための差し替え点。
 var now = time.Now
 
-// FormatISO8601 は DD-DATA-002 の日時表記に従い、TZ 付き秒精度で整形する。
+var (
+	// storageUTC は DD-DATA-002 の保存する日時を UTC で表記するかを表す。既定は OS のタイムゾーン。
+	storageUTC atomic.Bool
+	// displayLocation は DD-DATA-002 の表示用のタイムゾーンを表す。nil の場合は OS のタイムゾーンを用いる。
+	displayLocation atomic.Pointer[time.Location]
+)
+
+// SetStorageUTC は DD-DATA-002 の保存する日時を UTC で表記するかを切り替える。既存の日時は書き換えない。
+func SetStorageUTC(enabled bool) {
+	storageUTC.Store(enabled)
+}
+
+// storageLocation は DD-DATA-002 の保存する日時のタイムゾーンを返す。
+func storageLocation() *time.Location {
+	if storageUTC.Load() {
+		return time.UTC
+	}
+	return time.Local
+}
+
+// FormatISO8601 は DD-DATA-002 の日時表記に従い、保存用のタイムゾーンの TZ 付き秒精度で整形する。
 func FormatISO8601(value time.Time) string {
-	return value.In(time.Local).Truncate(time.Second).Format(time.RFC3339)
+	return value.In(storageLocation()).Truncate(time.Second).Format(time.RFC3339)
 }
 
 // NowISO8601 は DD-DATA-002 の日時表記で現在時刻を返す。
 func NowISO8601() string {
 	return FormatISO8601(now())
 }
+
+// FormatUTC は DD-DATA-002 の日時表記に従い、保存用の設定にかかわらず UTC の秒精度で整形する。
+func FormatUTC(value time.Time) string {
+	return value.UTC().Truncate(time.Second).Format(time.RFC3339)
+}
+
+// LoadLocation は DD-CONF-005 の表示用のタイムゾーン名を解決する。空文字は OS のタイムゾーンを表す。
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("load time zone %s: %w", name, err)
+	}
+	return location, nil
+}
+
+// SetDisplayLocation は DD-DATA-002 の表示用のタイムゾーンを切り替える。nil は OS のタイムゾーンを表す。
+func SetDisplayLocation(location *time.Location) {
+	displayLocation.Store(location)
+}
+
+// DisplayLocation は DD-DATA-002 の表示用のタイムゾーンを返す。
+func DisplayLocation() *time.Location {
+	if location := displayLocation.Load(); location != nil {
+		return location
+	}
+	return time.Local
+}
+
+// ToDisplay は DD-DATA-002 の保存された日時を表示用のタイムゾーンの TZ 付き秒精度へ変換する。
+// 目的: 保存時のタイムゾーンが混在していても、画面は設定したタイムゾーンで日時を並べられるようにする。
+// 入力: value は RFC 3339 の日時。
+// 出力: 表示用のタイムゾーンへ変換した RFC 3339 の日時。解析できない場合は空文字。
+// エラー: なし。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 表す時点は変えない。
+// 関連DD: DD-DATA-002, DD-CONF-005
+func ToDisplay(value string) string {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return ""
+	}
+	return parsed.In(DisplayLocation()).Format(time.RFC3339)
+}
@@ -29,3 +29,43 @@ func TestNowISO8601_UsesCurrentTimeFormat(t *testing.T) {
 		t.Fatalf("unexpected format: %s", got)
 	}
 }
+
+func TestFormatISO8601_StorageUTC(t *testing.T) {
+	// UTC で保存する設定では Z の表記となり、UTC 用の整形は設定にかかわらず UTC となることを確認する。
+	value := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("JST", 9*60*60))
+	SetStorageUTC(true)
+	t.Cleanup(func() { SetStorageUTC(false) })
+
+	if got := FormatISO8601(value); got != "2024-01-01T18:04:05Z" {
+		t.Fatalf("unexpected storage format: %s", got)
+	}
+	SetStorageUTC(false)
+	if got := FormatUTC(value); got != "2024-01-01T18:04:05Z" {
+		t.Fatalf("unexpected utc format: %s", got)
+	}
+}
+
+func TestToDisplay_ConvertsToDisplayLocation(t *testing.T) {
+	// 保存時のタイムゾーンにかかわらず表示用のタイムゾーンへ変換し、解析できない値は空文字とすることを確認する。
+	location, err := LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("LoadLocation error: %v", err)
+	}
+	SetDisplayLocation(location)
+	t.Cleanup(func() { SetDisplayLocation(nil) })
+
+	for _, stored := range []string{"2024-01-01T18:04:05Z", "2024-01-01T13:04:05-05:00"} {
+		if got := ToDisplay(stored); got != "2024-01-02T03:04:05+09:00" {
+			t.Fatalf("unexpected display of %s: %s", stored, got)
+		}
+	}
+	if got := ToDisplay("2024-01-01"); got != "" {
+		t.Fatalf("expected empty display for invalid value, got %s", got)
+	}
+	if _, err := LoadLocation("No/Such_Zone"); err == nil {
+		t.Fatal("expected unknown time zone error")
+	}
+	if location, err := LoadLocation(""); err != nil || location != time.Local {
+		t.Fatalf("expected local time zone, got %v err=%v", location, err)
+	}
+}
@@ -1,5 +1,6 @@
 // Package atomicwrite は原子的なファイル書き込みを提供し、上位の整形や検証は扱わない。
-// fsync や同期保証の強化は対象外とする。
+// fsync による同期は DD-PERSIST-003 の durable モードを有効にした場合のみ行う。
+// 置き換える前の内容は WriteFileWithBackup で書き込み、DD-PERSIST-005 の世代数を設定した場合のみ .bak として残す。
 package atomicwrite
 
 import (
@@ -7,15 +8,37 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"time"
+
+	"ratta/internal/domain/apperr"
 )
 
 var (
 	now        = time.Now
 	renameFile = os.Rename
 	removeFile = os.Remove
+	syncDir    = syncDirectory
 )
 
+// durable は DD-PERSIST-003 の durable モードが有効かを表す。
+var durable atomic.Bool
+
+// syncer は DD-PERSIST-003 の fsync できる一時ファイルを表す。
+type syncer interface {
+	Sync() error
+}
+
+// SetDurable は DD-PERSIST-003 の durable モードを切り替える。起動時に config.json の設定から呼び出す。
+func SetDurable(enabled bool) {
+	durable.Store(enabled)
+}
+
+// Durable は DD-PERSIST-003 の durable モードが有効かを返す。
+func Durable() bool {
+	return durable.Load()
+}
+
 type tempFileCreator func(dir, base string) (io.WriteCloser, string, error)
 
 // createTempFile は DD-PERSIST-002 の命名規則で一時ファイルを作成する。
@@ -36,11 +59,41 @@ var createTempFile tempFileCreator = func(dir, base string) (io.WriteCloser, str
 // 入力: targetPath は保存先、data は書き込むバイト列。
 // 出力: 成功時は nil、失敗時はエラー。
 // エラー: 一時ファイル作成、書き込み、リネーム失敗時に返す。
+// durable モードでは rename 前に一時ファイルを、rename 後に親ディレクトリを fsync する。
 // 副作用: 一時ファイル作成・削除とターゲットファイル更新を行う。
+// バックアップは残さない。利用者が手作業で戻す対象の書き込みには WriteFileWithBackup を用いる。
 // 並行性: 同一ファイルへの同時書き込みは想定しない。
-// 不変条件: 書き込み失敗時はターゲットファイルを変更しない。
-// 関連DD: DD-PERSIST-002, DD-PERSIST-003
+// 不変条件: rename 前の失敗時はターゲットファイルを変更しない。ターゲットファイルが存在しない時間は作らない。
+// 親ディレクトリの fsync の失敗時はターゲットファイルを置き換えたうえでエラーを返す。
+// エラーは DD-BE-004 の対象のパス (targetPath) と復旧の指針を持つ。
+// 関連DD: DD-PERSIST-002, DD-PERSIST-003, DD-PERSIST-005, DD-BE-004
 func WriteFile(targetPath string, data []byte) error {
+	if err := writeFile(targetPath, data, false); err != nil {
+		return apperr.WithPath(err, targetPath, apperr.IOHint(err, apperr.HintRetryWrite))
+	}
+	return nil
+}
+
+// WriteFileWithBackup は DD-PERSIST-005 に従い、WriteFile と同じ手順で書き込んだうえで、置き換える前の内容を .bak として残す。
+// 目的: 課題JSON・カテゴリメタデータなど利用者が編集する内容のみを、誤った編集から手作業で戻せるようにする。
+// ロックの更新やキャッシュなど頻繁に書き換える管理用のファイルは WriteFile を用い、.bak を残さない。
+// 入力: targetPath は保存先、data は書き込むバイト列。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: WriteFile の失敗に加え、バックアップの書き込みに失敗した場合に返す。
+// 副作用: WriteFile の副作用に加え、バックアップの世代数を設定した場合は rename 前にバックアップの世代を繰り下げる。
+// 並行性: 同一ファイルへの同時書き込みは想定しない。
+// 不変条件: 世代数が 0 の場合やターゲットファイルが存在しない場合は WriteFile と同じ結果となる。
+// エラーは DD-BE-004 の対象のパス (targetPath) と復旧の指針を持つ。
+// 関連DD: DD-PERSIST-002, DD-PERSIST-005, DD-BE-004
+func WriteFileWithBackup(targetPath string, data []byte) error {
+	if err := writeFile(targetPath, data, true); err != nil {
+		return apperr.WithPath(err, targetPath, apperr.IOHint(err, apperr.HintRetryWrite))
+	}
+	return nil
+}
+
+// writeFile は DD-PERSIST-002 の一時ファイルへの書き出しと rename を行う。backup が真の場合は DD-PERSIST-005 のバックアップも残す。
+func writeFile(targetPath string, data []byte, backup bool) error {
 	dir := filepath.Dir(targetPath)
 	base := filepath.Base(targetPath)
 
@@ -61,6 +114,16 @@ func WriteFile(targetPath string, data []byte) error {
 		return fmt.Errorf("write temp file: %w", writeErr)
 	}
 
+	if file, ok := writer.(syncer); ok && Durable() {
+		if syncErr := file.Sync(); syncErr != nil {
+			_ = writer.Close()
+			if removeErr := removeFile(tmpPath); removeErr != nil {
+				return fmt.Errorf("sync temp file failed: %w; cleanup error: %s", syncErr, removeErr.Error())
+			}
+			return fmt.Errorf("sync temp file: %w", syncErr)
+		}
+	}
+
 	if closeErr := writer.Close(); closeErr != nil {
 		removeErr := removeFile(tmpPath)
 		if removeErr != nil {
@@ -69,6 +132,16 @@ func WriteFile(targetPath string, data []byte) error {
 		return fmt.Errorf("close temp file: %w", closeErr)
 	}
 
+	if generations := BackupGenerations(); backup && generations > 0 {
+		if backupErr := keepBackup(targetPath, generations); backupErr != nil {
+			removeErr := removeFile(tmpPath)
+			if removeErr != nil {
+				return fmt.Errorf("keep backup failed: %w; cleanup error: %s", backupErr, removeErr.Error())
+			}
+			return fmt.Errorf("keep backup: %w", backupErr)
+		}
+	}
+
 	if renameErr := renameFile(tmpPath, targetPath); renameErr != nil {
 		removeErr := removeFile(tmpPath)
 		if removeErr != nil {
@@ -77,5 +150,10 @@ func WriteFile(targetPath string, data []byte) error {
 		return fmt.Errorf("rename temp file: %w", renameErr)
 	}
 
+	if Durable() {
+		if syncErr := syncDir(dir); syncErr != nil {
+			return fmt.Errorf("sync directory: %w", syncErr)
+		}
+	}
 	return nil
 }
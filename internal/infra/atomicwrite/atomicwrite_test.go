@@ -10,6 +10,8 @@ import (
 	"strconv"
 	"testing"
 	"time"
+
+	"ratta/internal/domain/apperr"
 )
 
 type failingWriter struct {
@@ -81,7 +83,7 @@ func TestWriteFile_Success(t *testing.T) {
 }
 
 func TestWriteFile_RenameFailureCleansTemp(t *testing.T) {
-	// rename 失敗時に元データ保持と一時ファイル削除を行うことを確認する。
+	// rename 失敗時に元データ保持と一時ファイル削除を行い、エラーが保存先のパスと再実行の指針を持つことを確認する。
 	dir := t.TempDir()
 	targetPath := filepath.Join(dir, "issue.json")
 	if err := os.WriteFile(targetPath, []byte("old"), 0o600); err != nil {
@@ -96,8 +98,10 @@ func TestWriteFile_RenameFailureCleansTemp(t *testing.T) {
 	renameFile = func(_, _ string) error { return errors.New("rename failed") }
 	t.Cleanup(func() { renameFile = previousRename })
 
-	if err := WriteFile(targetPath, []byte("new")); err == nil {
-		t.Fatal("expected rename error")
+	err := WriteFile(targetPath, []byte("new"))
+	var pathErr *apperr.PathError
+	if !errors.As(err, &pathErr) || pathErr.Path != targetPath || pathErr.Hint != apperr.HintRetryWrite {
+		t.Fatalf("expected rename error with target path, got %v", err)
 	}
 
 	// #nosec G304 -- テスト用の一時ディレクトリ配下を読むため安全。
@@ -214,3 +218,87 @@ func TestWriteFile_CloseFailureCleanup(t *testing.T) {
 func itoa(value int) string {
 	return strconv.Itoa(value)
 }
+
+type syncFailWriter struct {
+	closeFailWriter
+}
+
+func (w *syncFailWriter) Close() error {
+	return w.file.Close()
+}
+
+func (w *syncFailWriter) Sync() error {
+	return errors.New("sync failed")
+}
+
+// enableDurable はテストの間だけ DD-PERSIST-003 の durable モードを有効にする。
+func enableDurable(t *testing.T) {
+	t.Helper()
+	SetDurable(true)
+	t.Cleanup(func() { SetDurable(false) })
+}
+
+func TestWriteFile_DurableSyncsDirectory(t *testing.T) {
+	// durable モードでは置き換えたうえで親ディレクトリを fsync し、その失敗をエラーとして返すことを確認する。
+	enableDurable(t)
+	dir := t.TempDir()
+	targetPath := filepath.Join(dir, "issue.json")
+
+	var synced []string
+	previousSyncDir := syncDir
+	syncDir = func(path string) error {
+		synced = append(synced, path)
+		return syncDirectory(path)
+	}
+	t.Cleanup(func() { syncDir = previousSyncDir })
+
+	if err := WriteFile(targetPath, []byte("first")); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+	if len(synced) != 1 || synced[0] != dir {
+		t.Fatalf("expected parent directory to be synced, got %v", synced)
+	}
+
+	syncDir = func(_ string) error { return errors.New("fsync failed") }
+	if err := WriteFile(targetPath, []byte("second")); err == nil {
+		t.Fatal("expected directory sync error")
+	}
+	// #nosec G304 -- テスト用の一時ディレクトリ配下を読むため安全。
+	contents, readErr := os.ReadFile(targetPath)
+	if readErr != nil || string(contents) != "second" {
+		t.Fatalf("expected target to be replaced, got %q err=%v", string(contents), readErr)
+	}
+}
+
+func TestWriteFile_DurableSyncFailureKeepsTarget(t *testing.T) {
+	// durable モードで一時ファイルの fsync に失敗した場合は書き込み先を変更せず、一時ファイルを削除することを確認する。
+	enableDurable(t)
+	dir := t.TempDir()
+	targetPath := filepath.Join(dir, "issue.json")
+	if err := os.WriteFile(targetPath, []byte("old"), 0o600); err != nil {
+		t.Fatalf("write original: %v", err)
+	}
+	tmpPath := filepath.Join(dir, "issue.json.tmp")
+	previousCreate := createTempFile
+	createTempFile = func(_, _ string) (io.WriteCloser, string, error) {
+		// #nosec G304 -- テスト用の一時ディレクトリ配下のみを作成するため安全。
+		file, err := os.Create(tmpPath)
+		if err != nil {
+			return nil, "", err
+		}
+		return &syncFailWriter{closeFailWriter{file: file}}, tmpPath, nil
+	}
+	t.Cleanup(func() { createTempFile = previousCreate })
+
+	if err := WriteFile(targetPath, []byte("new")); err == nil {
+		t.Fatal("expected sync error")
+	}
+	// #nosec G304 -- テスト用の一時ディレクトリ配下を読むため安全。
+	contents, readErr := os.ReadFile(targetPath)
+	if readErr != nil || string(contents) != "old" {
+		t.Fatalf("expected target to be kept, got %q err=%v", string(contents), readErr)
+	}
+	if _, statErr := os.Stat(tmpPath); !os.IsNotExist(statErr) {
+		t.Fatalf("expected temp file cleanup, got err=%v", statErr)
+	}
+}
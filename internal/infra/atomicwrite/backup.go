@@ -0,0 +1,75 @@
+// backup.go は DD-PERSIST-005 の置き換える前の内容を .bak として世代管理する処理を担い、
+// バックアップからの復元は扱わない。復元は利用者が .bak を手作業で戻して行う。
+package atomicwrite
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// maxBackupGenerations は DD-PERSIST-005 の残すバックアップの世代数の上限を表す。
+const maxBackupGenerations = 10
+
+// backupGenerations は DD-PERSIST-005 の残すバックアップの世代数を表す。0 の場合は残さない。
+var backupGenerations atomic.Int32
+
+// SetBackupGenerations は DD-PERSIST-005 の残すバックアップの世代数を設定する。起動時に config.json の設定から呼び出す。
+// 0 以下はバックアップを残さない設定とし、上限を超える値は上限に切り詰める。
+func SetBackupGenerations(generations int) {
+	switch {
+	case generations < 0:
+		generations = 0
+	case generations > maxBackupGenerations:
+		generations = maxBackupGenerations
+	}
+	// #nosec G115 -- 上限で切り詰めているため int32 に収まる。
+	backupGenerations.Store(int32(generations))
+}
+
+// BackupGenerations は DD-PERSIST-005 の残すバックアップの世代数を返す。
+func BackupGenerations() int {
+	return int(backupGenerations.Load())
+}
+
+// BackupPath は DD-PERSIST-005 の generation 世代前のバックアップのパスを返す。
+// 1 世代前は `<name>.bak`、それより古い世代は `<name>.bak.<世代>` とする。
+func BackupPath(targetPath string, generation int) string {
+	if generation <= 1 {
+		return targetPath + ".bak"
+	}
+	return fmt.Sprintf("%s.bak.%d", targetPath, generation)
+}
+
+// keepBackup は DD-PERSIST-005 に従い、置き換える前の内容を `<name>.bak` に残す。
+// 目的: 共有フォルダでの誤った編集を、利用者が1つ前の内容へ手作業で戻せるようにする。
+// 入力: targetPath は置き換えるファイル、generations は残す世代数 (1 以上)。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: 現在の内容の読み取り、世代の繰り下げ、バックアップの書き込みに失敗した場合に返す。
+// 副作用: 既存のバックアップを1世代ずつ繰り下げ、最も古い世代を上書きする。ターゲットファイルは変更しない。
+// 並行性: 同一ファイルへの同時書き込みは想定しない。
+// 不変条件: ターゲットファイルが存在しない場合は何もしない。
+// 世代数を減らした場合に残った古い世代は削除しない。
+// 関連DD: DD-PERSIST-005
+func keepBackup(targetPath string, generations int) error {
+	// #nosec G304 -- 上位が検証済みの書き込み先のみを読むため安全。
+	data, err := os.ReadFile(targetPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read current file: %w", err)
+	}
+	for generation := generations; generation > 1; generation-- {
+		older := BackupPath(targetPath, generation-1)
+		if renameErr := renameFile(older, BackupPath(targetPath, generation)); renameErr != nil && !errors.Is(renameErr, os.ErrNotExist) {
+			return fmt.Errorf("rotate backup: %w", renameErr)
+		}
+	}
+	// rename で退避するとターゲットファイルが存在しない時間ができるため、内容を複製して残す。
+	if writeErr := os.WriteFile(BackupPath(targetPath, 1), data, 0o600); writeErr != nil {
+		return fmt.Errorf("write backup: %w", writeErr)
+	}
+	return nil
+}
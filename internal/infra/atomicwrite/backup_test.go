@@ -0,0 +1,94 @@
+// backup_test.go は置き換える前の内容のバックアップのテストを行い、fsync は扱わない。
+package atomicwrite
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// withBackupGenerations はテストの間だけ DD-PERSIST-005 のバックアップの世代数を設定する。
+func withBackupGenerations(t *testing.T, generations int) {
+	t.Helper()
+	SetBackupGenerations(generations)
+	t.Cleanup(func() { SetBackupGenerations(0) })
+}
+
+func TestWriteFileWithBackup_KeepsBackupGenerations(t *testing.T) {
+	// 置き換える前の内容を .bak に残し、設定した世代数を超える分は上書きすることを確認する。
+	withBackupGenerations(t, 2)
+	targetPath := filepath.Join(t.TempDir(), "issue.json")
+
+	for _, contents := range []string{"v1", "v2", "v3", "v4"} {
+		if err := WriteFileWithBackup(targetPath, []byte(contents)); err != nil {
+			t.Fatalf("WriteFileWithBackup %s error: %v", contents, err)
+		}
+	}
+
+	expected := map[string]string{
+		targetPath:                "v4",
+		BackupPath(targetPath, 1): "v3",
+		BackupPath(targetPath, 2): "v2",
+	}
+	for path, want := range expected {
+		// #nosec G304 -- テスト用の一時ディレクトリ配下を読むため安全。
+		got, err := os.ReadFile(path)
+		if err != nil || string(got) != want {
+			t.Fatalf("%s: expected %q, got %q err=%v", filepath.Base(path), want, string(got), err)
+		}
+	}
+	if _, err := os.Stat(BackupPath(targetPath, 3)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no third generation, got %v", err)
+	}
+}
+
+func TestWriteFileWithBackup_NoBackupWhenDisabledOrNew(t *testing.T) {
+	// 世代数が 0 の場合や新規作成の場合はバックアップを作らないことを確認する。
+	targetPath := filepath.Join(t.TempDir(), "issue.json")
+	if err := WriteFileWithBackup(targetPath, []byte("v1")); err != nil {
+		t.Fatalf("WriteFileWithBackup error: %v", err)
+	}
+	if err := WriteFileWithBackup(targetPath, []byte("v2")); err != nil {
+		t.Fatalf("WriteFileWithBackup error: %v", err)
+	}
+	if _, err := os.Stat(BackupPath(targetPath, 1)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no backup when disabled, got %v", err)
+	}
+
+	withBackupGenerations(t, 1)
+	newPath := filepath.Join(filepath.Dir(targetPath), "new.json")
+	if err := WriteFileWithBackup(newPath, []byte("v1")); err != nil {
+		t.Fatalf("WriteFileWithBackup error: %v", err)
+	}
+	if _, err := os.Stat(BackupPath(newPath, 1)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no backup for new file, got %v", err)
+	}
+}
+
+func TestWriteFile_NeverKeepsBackup(t *testing.T) {
+	// 世代数を設定していても、WriteFile による管理用のファイルの書き込みではバックアップを作らないことを確認する。
+	withBackupGenerations(t, 2)
+	targetPath := filepath.Join(t.TempDir(), "lock.json")
+	for _, contents := range []string{"v1", "v2"} {
+		if err := WriteFile(targetPath, []byte(contents)); err != nil {
+			t.Fatalf("WriteFile error: %v", err)
+		}
+	}
+	if _, err := os.Stat(BackupPath(targetPath, 1)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no backup, got %v", err)
+	}
+}
+
+func TestSetBackupGenerations_ClampsRange(t *testing.T) {
+	// 世代数は 0 から上限の範囲に切り詰めることを確認する。
+	t.Cleanup(func() { SetBackupGenerations(0) })
+	SetBackupGenerations(-1)
+	if got := BackupGenerations(); got != 0 {
+		t.Fatalf("expected 0, got %d", got)
+	}
+	SetBackupGenerations(maxBackupGenerations + 5)
+	if got := BackupGenerations(); got != maxBackupGenerations {
+		t.Fatalf("expected %d, got %d", maxBackupGenerations, got)
+	}
+}
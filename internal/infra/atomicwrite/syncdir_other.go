@@ -0,0 +1,27 @@
+//go:build !windows
+
+// syncdir_other.go は DD-PERSIST-003 の親ディレクトリの fsync を Windows 以外で行う実装を担う。
+package atomicwrite
+
+import (
+	"fmt"
+	"os"
+)
+
+// syncDirectory は DD-PERSIST-003 の rename の結果をディスクへ反映するため、ディレクトリを fsync する。
+func syncDirectory(dir string) error {
+	// #nosec G304 -- 書き込み先の親ディレクトリのみを開くため安全。
+	handle, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("open directory: %w", err)
+	}
+	syncErr := handle.Sync()
+	closeErr := handle.Close()
+	if syncErr != nil {
+		return fmt.Errorf("fsync directory: %w", syncErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("close directory: %w", closeErr)
+	}
+	return nil
+}
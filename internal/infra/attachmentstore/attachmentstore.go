@@ -56,14 +56,14 @@ type SavedAttachment struct {
 
 // SaveAll は DD-DATA-005 の格納ルールに従い、添付ファイルを保存する。
 // 目的: 複数添付を保存し、ロールバック関数を返却する。
-// 入力: issueDir は課題ディレクトリ、issueID は課題ID、inputs は添付入力群。
+// 入力: issueDir は課題ディレクトリ、issueID は課題ID、inputs は添付入力群、idFormat は添付IDの文字種と長さ (DD-PROJCONF-001)。
 // 出力: 保存済み添付一覧、ロールバック関数、エラー。
 // エラー: 保存失敗やロールバック失敗時に返す。
 // 副作用: 添付ディレクトリ作成とファイル書き込みを行う。
 // 並行性: 同一課題への同時保存は想定しない。
 // 不変条件: 保存に失敗した場合は保存済み添付を削除する。
 // 関連DD: DD-DATA-005
-func SaveAll(issueDir, issueID string, inputs []Input) ([]SavedAttachment, func() error, error) {
+func SaveAll(issueDir, issueID string, inputs []Input, idFormat id.Format) ([]SavedAttachment, func() error, error) {
 	if len(inputs) == 0 {
 		return nil, func() error { return nil }, nil
 	}
@@ -75,7 +75,7 @@ func SaveAll(issueDir, issueID string, inputs []Input) ([]SavedAttachment, func(
 
 	saved := make([]SavedAttachment, 0, len(inputs))
 	for _, input := range inputs {
-		record, err := saveOne(attachDir, issueID, input)
+		record, err := saveOne(attachDir, issueID, input, idFormat)
 		if err != nil {
 			if cleanupErr := removeAll(saved); cleanupErr != nil {
 				return nil, nil, fmt.Errorf("cleanup attachments failed: %w; cleanup error: %s", err, cleanupErr.Error())
@@ -97,8 +97,8 @@ func SaveAll(issueDir, issueID string, inputs []Input) ([]SavedAttachment, func(
 // 並行性: 同一ディレクトリへの同時保存は想定しない。
 // 不変条件: StoredName は sanitize と衝突回避に従う。
 // 関連DD: DD-DATA-005
-func saveOne(attachDir, issueID string, input Input) (SavedAttachment, error) {
-	attachmentID, err := newAttachmentID()
+func saveOne(attachDir, issueID string, input Input, idFormat id.Format) (SavedAttachment, error) {
+	attachmentID, err := newAttachmentID(idFormat)
 	if err != nil {
 		return SavedAttachment{}, fmt.Errorf("generate attachment id: %w", err)
 	}
@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"ratta/internal/domain/id"
 )
 
 type failingWriter struct {
@@ -55,7 +57,7 @@ func TestSaveAll_CollisionAddsSuffix(t *testing.T) {
 	}
 
 	previousID := newAttachmentID
-	newAttachmentID = func() (string, error) { return "ATTACH123", nil }
+	newAttachmentID = func(id.Format) (string, error) { return "ATTACH123", nil }
 	t.Cleanup(func() { newAttachmentID = previousID })
 
 	existing := filepath.Join(attachDir, "ATTACH123_report.txt")
@@ -63,7 +65,7 @@ func TestSaveAll_CollisionAddsSuffix(t *testing.T) {
 		t.Fatalf("write existing: %v", err)
 	}
 
-	records, rollback, err := SaveAll(dir, issueID, []Input{{OriginalName: "report.txt", Data: []byte("new")}})
+	records, rollback, err := SaveAll(dir, issueID, []Input{{OriginalName: "report.txt", Data: []byte("new")}}, id.Format{})
 	if err != nil {
 		t.Fatalf("SaveAll error: %v", err)
 	}
@@ -91,7 +93,7 @@ func TestSaveAll_RollbackOnFailure(t *testing.T) {
 
 	previousID := newAttachmentID
 	counter := 0
-	newAttachmentID = func() (string, error) {
+	newAttachmentID = func(id.Format) (string, error) {
 		counter++
 		if counter == 1 {
 			return "ATTACHAAA", nil
@@ -124,7 +126,7 @@ func TestSaveAll_RollbackOnFailure(t *testing.T) {
 	_, _, err := SaveAll(dir, issueID, []Input{
 		{OriginalName: "a.txt", Data: []byte("ok")},
 		{OriginalName: "b.txt", Data: []byte("ng")},
-	})
+	}, id.Format{})
 	if err == nil {
 		t.Fatal("expected save error")
 	}
@@ -137,7 +139,7 @@ func TestSaveAll_RollbackOnFailure(t *testing.T) {
 
 func TestSaveAll_EmptyInputs(t *testing.T) {
 	// 入力が空の場合に空結果とロールバック関数が返ることを確認する。
-	records, rollback, err := SaveAll("dir", "issue", nil)
+	records, rollback, err := SaveAll("dir", "issue", nil, id.Format{})
 	if err != nil {
 		t.Fatalf("SaveAll error: %v", err)
 	}
@@ -0,0 +1,140 @@
+// Package audittrail は課題の変更操作の追記専用の記録 (監査証跡) を担い、操作の実行や記録の閲覧は扱わない。
+// 記録は ratta.log とは別のファイルへ1行1JSONで追記し、書き換え・削除・ローテーションは行わない。
+package audittrail
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/projectmeta"
+)
+
+const (
+	// FileName は DD-LOG-008 の監査証跡のファイル名を表す。
+	FileName = "audit.jsonl"
+
+	// LocationApp と LocationProject は DD-LOG-008 の config.json の log.audit_trail で指定する記録先を表す。
+	// LocationApp は実行ファイルと同じディレクトリの logs/、LocationProject はプロジェクトルートの .ratta/ に置く。
+	LocationApp     = "app"
+	LocationProject = "project"
+)
+
+// now は DD-LOG-008 の記録時刻をテストで固定するための差し替え点。
+var now = time.Now
+
+// Change は DD-LOG-008 の項目1件の変更内容を表す。
+// 長文の項目は変更したことのみを記録し、Before・After を空とする。
+type Change struct {
+	Field  string `json:"field"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// Record は DD-LOG-008 の変更操作1件の記録を表す。
+// Mode・Author は操作したモードと利用者 (Contractor のユーザー名、コメントの作成者名) を表す。
+type Record struct {
+	Timestamp string   `json:"timestamp"`
+	Operation string   `json:"operation"`
+	Category  string   `json:"category"`
+	IssueID   string   `json:"issue_id,omitempty"`
+	Mode      string   `json:"mode"`
+	Author    string   `json:"author,omitempty"`
+	RequestID string   `json:"request_id,omitempty"`
+	Changes   []Change `json:"changes,omitempty"`
+}
+
+// Trail は DD-LOG-008 の監査証跡のファイルを表す。
+type Trail struct {
+	mu   sync.Mutex
+	path string
+}
+
+// Open は DD-LOG-008 の path へ追記する監査証跡を返す。ファイルは最初の追記で作成する。
+func Open(path string) *Trail {
+	return &Trail{path: path}
+}
+
+// AppPath は DD-LOG-008 の実行ファイルと同じディレクトリの logs/audit.jsonl のパスを返す。
+func AppPath(exePath string) string {
+	return filepath.Join(filepath.Dir(exePath), "logs", FileName)
+}
+
+// ProjectPath は DD-LOG-008 のプロジェクトルートの .ratta/audit.jsonl のパスを返す。
+func ProjectPath(root string) string {
+	return filepath.Join(projectmeta.Dir(root), FileName)
+}
+
+// Path は DD-LOG-008 の記録先のパスを返す。
+func (t *Trail) Path() string {
+	return t.path
+}
+
+// Append は DD-LOG-008 の変更操作の記録を追記する。
+// 目的: 誰がいつどの課題のどの項目を変えたかを、ログのローテーションに左右されずに後から確認できるようにする。
+// 入力: record は記録内容。Timestamp が空の場合は現在時刻を補う。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: 記録先のディレクトリの作成、ファイルのオープン・書き込みに失敗した場合に返す。
+// 副作用: 記録先のファイルへ1行を追記する。
+// 並行性: 同じ Trail への追記は mutex で排他する。他のインスタンスとの追記は O_APPEND による1回の書き込みに委ねる。
+// 不変条件: 既存の行を書き換えず、末尾にのみ追記する。
+// 関連DD: DD-LOG-008
+func (t *Trail) Append(record Record) error {
+	if record.Timestamp == "" {
+		record.Timestamp = now().Format(time.RFC3339)
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o750); err != nil {
+		return fmt.Errorf("create audit dir: %w", err)
+	}
+	file, err := os.OpenFile(t.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open audit trail: %w", err)
+	}
+	if _, err := file.Write(line); err != nil {
+		if closeErr := file.Close(); closeErr != nil {
+			return fmt.Errorf("write audit trail failed: %w; close error: %s", err, closeErr.Error())
+		}
+		return fmt.Errorf("write audit trail: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close audit trail: %w", err)
+	}
+	return nil
+}
+
+// Diff は DD-LOG-008 の課題の更新前後で値の変わった項目を返す。
+// 説明は長文となるため値を記録せず、変更したことのみを返す。コメントはコメント追加の記録で扱う。
+func Diff(before, after issue.Issue) []Change {
+	changes := []Change{}
+	add := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, Change{Field: field, Before: oldValue, After: newValue})
+		}
+	}
+	add("title", before.Title, after.Title)
+	add("status", string(before.Status), string(after.Status))
+	add("priority", string(before.Priority), string(after.Priority))
+	add("assignee", before.Assignee, after.Assignee)
+	add("due_date", before.DueDate, after.DueDate)
+	if before.Description != after.Description {
+		changes = append(changes, Change{Field: "description"})
+	}
+	return changes
+}
+
+// Created は DD-LOG-008 の作成・取り込んだ課題の初期値を変更内容として返す。説明は値を記録しない。
+func Created(created issue.Issue) []Change {
+	return Diff(issue.Issue{}, created)
+}
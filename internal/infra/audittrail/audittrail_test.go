@@ -0,0 +1,68 @@
+// audittrail_test.go は監査証跡の追記と変更内容の算出のテストを行い、バインディングからの記録は扱わない。
+package audittrail
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ratta/internal/domain/issue"
+)
+
+func TestAppend_AppendsLines(t *testing.T) {
+	// 記録が1行1JSONで末尾へ追記され、時刻が補われることを確認する。
+	original := now
+	now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	t.Cleanup(func() { now = original })
+
+	path := ProjectPath(t.TempDir())
+	trail := Open(path)
+	if err := trail.Append(Record{Operation: "issue_created", Category: "cat", IssueID: "abc123DEF", Mode: "Vendor"}); err != nil {
+		t.Fatalf("Append error: %v", err)
+	}
+	if err := trail.Append(Record{Operation: "issue_updated", Category: "cat", IssueID: "abc123DEF", Mode: "Contractor", Author: "alice"}); err != nil {
+		t.Fatalf("Append error: %v", err)
+	}
+
+	// #nosec G304 -- テスト用ディレクトリ配下の記録のみを読むため安全。
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read audit trail: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("unexpected lines: %q", lines)
+	}
+	var second Record
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if second.Operation != "issue_updated" || second.Author != "alice" || second.Timestamp != "2026-01-02T03:04:05Z" {
+		t.Fatalf("unexpected record: %+v", second)
+	}
+	if filepath.Base(filepath.Dir(path)) != ".ratta" {
+		t.Fatalf("unexpected project path: %s", path)
+	}
+}
+
+func TestDiff_ReportsChangedFields(t *testing.T) {
+	// 値の変わった項目のみを返し、説明は値を含めないことを確認する。
+	before := issue.Issue{Title: "old", Description: "long text", Status: issue.StatusOpen, Priority: issue.PriorityHigh}
+	after := before
+	after.Title = "new"
+	after.Description = "longer text"
+
+	changes := Diff(before, after)
+	if len(changes) != 2 {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+	if changes[0] != (Change{Field: "title", Before: "old", After: "new"}) {
+		t.Fatalf("unexpected title change: %+v", changes[0])
+	}
+	if changes[1] != (Change{Field: "description"}) {
+		t.Fatalf("unexpected description change: %+v", changes[1])
+	}
+}
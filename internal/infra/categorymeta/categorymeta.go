@@ -0,0 +1,174 @@
+// Package categorymeta はカテゴリ直下の .category.json と .archived マーカーの読み書きを担い、権限判定やカテゴリ走査は扱わない。
+// ファイルが存在しない場合は既定値として扱い、作成は明示的な保存時のみ行う。
+package categorymeta
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"unicode/utf8"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/atomicwrite"
+	"ratta/internal/infra/projectmeta"
+)
+
+// FileName は DD-CATMETA-001 のカテゴリメタデータファイル名を表す。
+// ドット始まりとすることで課題JSONやカテゴリ走査の対象から外れる。
+const FileName = ".category.json"
+
+const (
+	formatVersion        = 1
+	maxDescriptionLength = 255
+)
+
+// colorPattern は DD-CATMETA-001 の表示色 (#RRGGBB) を表す。
+var colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// writeFile は DD-PERSIST-005 の利用者が編集するメタデータとして、置き換える前の内容を .bak として残して書き込む。
+var writeFile = atomicwrite.WriteFileWithBackup
+
+// Meta は DD-CATMETA-001 のカテゴリメタデータを表す。
+type Meta struct {
+	FormatVersion int    `json:"format_version"`
+	Description   string `json:"description"`
+	Color         string `json:"color"`
+	SortWeight    int    `json:"sort_weight"`
+	// 以下は DD-CATMETA-003 の新規課題の既定値で、空の場合は適用しない。
+	DefaultAssignee     string `json:"default_assignee"`
+	DefaultPriority     string `json:"default_priority"`
+	DescriptionTemplate string `json:"description_template"`
+}
+
+// Load は DD-CATMETA-001 のメタデータ読み込みを行う。
+// 目的: カテゴリディレクトリ直下の .category.json を読み込む。
+// 入力: categoryPath はカテゴリディレクトリのパス。
+// 出力: Meta、ファイル存在フラグ、エラー。
+// エラー: 読み取り・パース失敗時に返す。ファイルが無い場合はエラーにしない。
+// 副作用: ファイルを読み取る。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: ファイルが無い場合は FormatVersion のみ設定したゼロ値を返す。
+// 関連DD: DD-CATMETA-001
+func Load(categoryPath string) (Meta, bool, error) {
+	// #nosec G304 -- カテゴリディレクトリ直下の固定ファイル名のみを読む。
+	data, err := os.ReadFile(filepath.Join(categoryPath, FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return Meta{FormatVersion: formatVersion}, false, nil
+	}
+	if err != nil {
+		return Meta{}, false, apperr.WithPath(fmt.Errorf("read category meta: %w", err), filepath.Join(categoryPath, FileName), "")
+	}
+	var meta Meta
+	if unmarshalErr := json.Unmarshal(data, &meta); unmarshalErr != nil {
+		return Meta{}, false, apperr.WithPath(fmt.Errorf("parse category meta: %w", unmarshalErr), filepath.Join(categoryPath, FileName), apperr.HintCheckJSON)
+	}
+	return meta, true, nil
+}
+
+// Save は DD-CATMETA-001 のメタデータ保存を行う。
+// 目的: 検証済みのメタデータを .category.json に atomic write で保存する。
+// 入力: categoryPath はカテゴリディレクトリのパス、meta は保存内容。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: 検証失敗時は issue.ValidationErrors、整形・書き込み失敗時はラップしたエラーを返す。
+// 副作用: .category.json を作成または置換する。バックアップの世代数を設定した場合は置き換える前の内容を .bak として残す。
+// 並行性: 同一カテゴリへの同時保存は想定しない。
+// 不変条件: 保存される format_version は常に現行値。改行コードとインデント幅はカテゴリの親であるプロジェクトの設定に従う。
+// 関連DD: DD-CATMETA-001, DD-PERSIST-002, DD-DATA-006, DD-PERSIST-005
+func Save(categoryPath string, meta Meta) error {
+	if errs := Validate(meta); len(errs) > 0 {
+		return errs
+	}
+	meta.FormatVersion = formatVersion
+	format, err := projectmeta.LoadFormat(filepath.Dir(categoryPath))
+	if err != nil {
+		return err
+	}
+	data, err := format.MarshalCategoryMeta(meta)
+	if err != nil {
+		return fmt.Errorf("marshal category meta: %w", err)
+	}
+	if writeErr := writeFile(filepath.Join(categoryPath, FileName), data); writeErr != nil {
+		return fmt.Errorf("write category meta: %w", writeErr)
+	}
+	return nil
+}
+
+// Validate は DD-CATMETA-001 のメタデータ制約を検証する。
+// 目的: 説明文の長さ、表示色の書式、新規課題の既定値を検証する。
+// 入力: meta は検証対象。
+// 出力: 検証エラー一覧。問題が無ければ空。
+// エラー: なし。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: color は空文字 (既定色) か #RRGGBB のいずれか。既定値は課題の入力制約と同じ長さ・値域に収まる。
+// 関連DD: DD-CATMETA-001, DD-CATMETA-003
+func Validate(meta Meta) issue.ValidationErrors {
+	var errs issue.ValidationErrors
+	if utf8.RuneCountInString(meta.Description) > maxDescriptionLength {
+		errs = append(errs, issue.ValidationError{Field: "description", Message: "too long"})
+	}
+	if meta.Color != "" && !colorPattern.MatchString(meta.Color) {
+		errs = append(errs, issue.ValidationError{Field: "color", Message: "invalid format"})
+	}
+	if utf8.RuneCountInString(meta.DefaultAssignee) > maxDescriptionLength {
+		errs = append(errs, issue.ValidationError{Field: "default_assignee", Message: "too long"})
+	}
+	if meta.DefaultPriority != "" && !issue.Priority(meta.DefaultPriority).IsValid() {
+		errs = append(errs, issue.ValidationError{Field: "default_priority", Message: "invalid value"})
+	}
+	if utf8.RuneCountInString(meta.DescriptionTemplate) > maxDescriptionLength {
+		errs = append(errs, issue.ValidationError{Field: "description_template", Message: "too long"})
+	}
+	return errs
+}
+
+// ArchivedMarker は DD-CATMETA-002 のアーカイブ状態を示すマーカーファイル名を表す。
+// 内容は持たず、存在のみでアーカイブ済みと判定する。
+const ArchivedMarker = ".archived"
+
+// IsArchived は DD-CATMETA-002 のアーカイブ判定を行う。
+// 目的: カテゴリ直下にアーカイブマーカーが存在するかを判定する。
+// 入力: categoryPath はカテゴリディレクトリのパス。
+// 出力: マーカーが通常ファイルとして存在すれば true。
+// エラー: なし。判定できない場合は false とする。
+// 副作用: なし。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: マーカーの内容は判定に用いない。
+// 関連DD: DD-CATMETA-002
+func IsArchived(categoryPath string) bool {
+	info, err := os.Stat(filepath.Join(categoryPath, ArchivedMarker))
+	if err != nil {
+		return false
+	}
+	return info.Mode().IsRegular()
+}
+
+// SetArchived は DD-CATMETA-002 のアーカイブ状態の切り替えを行う。
+// 目的: アーカイブマーカーを作成または削除する。
+// 入力: categoryPath はカテゴリディレクトリのパス、archived は設定後の状態。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: マーカーの書き込み・削除失敗時に返す。
+// 副作用: .archived を作成または削除する。
+// 並行性: 同一カテゴリへの同時切り替えは想定しない。
+// 不変条件: 既に目的の状態であれば何もしない。
+// 関連DD: DD-CATMETA-002
+func SetArchived(categoryPath string, archived bool) error {
+	path := filepath.Join(categoryPath, ArchivedMarker)
+	if !archived {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove archived marker: %w", err)
+		}
+		return nil
+	}
+	if IsArchived(categoryPath) {
+		return nil
+	}
+	if err := writeFile(path, []byte{}); err != nil {
+		return fmt.Errorf("write archived marker: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// categorymeta_test.go はカテゴリメタデータの読み書きのテストを行い、カテゴリ操作の統合は扱わない。
+package categorymeta
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad_MissingFileReturnsDefault(t *testing.T) {
+	// .category.json が無い場合はエラーにせず既定値を返すことを確認する。
+	meta, exists, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if exists || meta.FormatVersion != formatVersion || meta.Color != "" {
+		t.Fatalf("unexpected default: %+v exists=%v", meta, exists)
+	}
+}
+
+func TestSave_RoundTripWithKeyOrder(t *testing.T) {
+	// 保存内容が読み戻せ、キー順が固定されていることを確認する。
+	dir := t.TempDir()
+	if err := Save(dir, Meta{Description: "説明", Color: "#00ff00", SortWeight: 2}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		t.Fatalf("read meta: %v", err)
+	}
+	text := string(data)
+	if strings.Index(text, "format_version") > strings.Index(text, "sort_weight") {
+		t.Fatalf("unexpected key order: %s", text)
+	}
+	meta, exists, err := Load(dir)
+	if err != nil || !exists {
+		t.Fatalf("Load error: %v exists=%v", err, exists)
+	}
+	if meta.Description != "説明" || meta.Color != "#00ff00" || meta.SortWeight != 2 {
+		t.Fatalf("unexpected meta: %+v", meta)
+	}
+}
+
+func TestValidate_RejectsLongDescriptionAndBadColor(t *testing.T) {
+	// 255 文字を超える説明と #RRGGBB 以外の色が拒否されることを確認する。
+	errs := Validate(Meta{Description: strings.Repeat("a", 256), Color: "#12345"})
+	if len(errs) != 2 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+}
+
+func TestSetArchived_TogglesMarker(t *testing.T) {
+	// アーカイブマーカーの作成と削除が IsArchived に反映され、重複操作もエラーにならないことを確認する。
+	dir := t.TempDir()
+	if IsArchived(dir) {
+		t.Fatal("expected not archived")
+	}
+	for i := 0; i < 2; i++ {
+		if err := SetArchived(dir, true); err != nil {
+			t.Fatalf("SetArchived(true) error: %v", err)
+		}
+	}
+	if !IsArchived(dir) {
+		t.Fatal("expected archived")
+	}
+	for i := 0; i < 2; i++ {
+		if err := SetArchived(dir, false); err != nil {
+			t.Fatalf("SetArchived(false) error: %v", err)
+		}
+	}
+	if IsArchived(dir) {
+		t.Fatal("expected unarchived")
+	}
+}
+
+func TestValidate_RejectsUnknownDefaultPriority(t *testing.T) {
+	// 既定優先度は課題の優先度と同じ値域のみ受け付けることを確認する。
+	if errs := Validate(Meta{DefaultPriority: "Urgent"}); len(errs) != 1 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if errs := Validate(Meta{DefaultPriority: "High"}); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+}
@@ -8,32 +8,232 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
 
+	"ratta/internal/domain/apperr"
 	"ratta/internal/infra/atomicwrite"
 	"ratta/internal/infra/jsonfmt"
+	"ratta/internal/infra/schema"
 )
 
 const (
 	formatVersion   = 1
 	defaultPageSize = 20
+	// maxRecentProjectRoots は DD-DATA-001 の最近開いたプロジェクトルートの保持件数を表す。
+	maxRecentProjectRoots = 10
+	// defaultContractorIdleTimeout は DD-MODE-001 の Contractor モードを解除するまでの無操作時間の既定値を表す。
+	defaultContractorIdleTimeout = 30 * time.Minute
+	// defaultTmpScanInterval は DD-PERSIST-004 の一時ファイル残骸の定期的な検出の間隔の既定値を表す。
+	defaultTmpScanInterval = time.Hour
+	// defaultPasswordMinLength は DD-CLI-009 の Contractor パスワードの最小文字数の既定値を表す。
+	defaultPasswordMinLength = 12
+	// defaultPasswordMinCharClasses は DD-CLI-009 の Contractor パスワードに含める文字種の数の既定値を表す。
+	defaultPasswordMinCharClasses = 2
+	// defaultHookTimeout は DD-HOOK-003 のフックのスクリプトの終了を待つ上限の既定値を表す。
+	defaultHookTimeout = 30 * time.Second
+)
+
+// HookEventIssueCreated などは DD-HOOK-003 のフックのスクリプトを実行する課題の変更の種類を表す。
+// 作成・更新・コメントは DD-HOOK-001 の通知と同じ名前とし、HookEventIssueClosed は状態が Closed へ変わった更新を表す。
+const (
+	HookEventIssueCreated   = "issue.created"
+	HookEventIssueUpdated   = "issue.updated"
+	HookEventIssueCommented = "issue.commented"
+	HookEventIssueClosed    = "issue.closed"
 )
 
 // Config は DD-DATA-001 の config.json 仕様を表す。
+// RecentProjectRoots は最近開いたプロジェクトルートを新しい順に最大 maxRecentProjectRoots 件保持する。
 type Config struct {
-	FormatVersion       int    `json:"format_version"`
-	LastProjectRootPath string `json:"last_project_root_path"`
-	Log                 Log    `json:"log"`
-	UI                  UI     `json:"ui"`
+	FormatVersion       int      `json:"format_version"`
+	LastProjectRootPath string   `json:"last_project_root_path"`
+	RecentProjectRoots  []string `json:"recent_project_roots,omitempty"`
+	Log                 Log      `json:"log"`
+	UI                  UI       `json:"ui"`
+	Scan                Scan     `json:"scan"`
+	Auth                Auth     `json:"auth"`
+	Storage             Storage  `json:"storage"`
+	Report              *Report  `json:"report,omitempty"`
+	Hooks               *Hooks   `json:"hooks,omitempty"`
+	API                 *API     `json:"api,omitempty"`
 }
 
 // Log は DD-DATA-001 の log 設定を表す。
+// MaxSizeMB・MaxGenerations は DD-LOG-003 のローテーションの1ファイルの上限 (MB) と残す世代数を表し、0 の場合は既定値 (1MB、3世代) を用いる。
+// SystemSink は DD-LOG-006 のエラーを Windows イベントログ・syslog へも転送するかを表す。
+// AuditTrail は DD-LOG-008 の監査証跡の記録先 ("app" または "project") を表し、空の場合は "app" とする。
 type Log struct {
-	Level string `json:"level"`
+	Level          string `json:"level"`
+	MaxSizeMB      int    `json:"max_size_mb,omitempty"`
+	MaxGenerations int    `json:"max_generations,omitempty"`
+	SystemSink     bool   `json:"system_sink,omitempty"`
+	AuditTrail     string `json:"audit_trail,omitempty"`
 }
 
-// UI は DD-DATA-001 の UI 設定を表す。
+// UI は DD-DATA-001 の UI 設定を表す。Window は一度も保存していない場合 nil とする。
+// DefaultSort 以降は DD-CONF-005 のアプリ設定を表し、未設定の項目は Settings で既定値を補う。
 type UI struct {
-	PageSize int `json:"page_size"`
+	PageSize          int              `json:"page_size"`
+	Window            *Window          `json:"window,omitempty"`
+	DefaultSort       *Sort            `json:"default_sort,omitempty"`
+	DefaultAuthorName string           `json:"default_author_name,omitempty"`
+	DateFormat        string           `json:"date_format,omitempty"`
+	Language          string           `json:"language,omitempty"`
+	TimeZone          string           `json:"time_zone,omitempty"`
+	ConfirmOnDelete   *ConfirmOnDelete `json:"confirm_on_delete,omitempty"`
+}
+
+// Window は DD-DATA-001 の前回終了時のウィンドウの大きさと位置を表す。
+// 最大化していた場合も Width/Height は最大化前の大きさを保持し、解除時に戻せるようにする。
+type Window struct {
+	Width     int  `json:"width"`
+	Height    int  `json:"height"`
+	X         int  `json:"x"`
+	Y         int  `json:"y"`
+	Maximised bool `json:"maximised"`
+}
+
+// Scan は DD-SCAN-001 の走査設定を表す。Concurrency が 0 の場合は CPU 数に応じた既定値を用いる。
+type Scan struct {
+	Concurrency int `json:"concurrency"`
+}
+
+// Storage は DD-PERSIST-003 の保存方法の設定を表す。
+// DurableWrites が true の場合はアトミック更新の際に一時ファイルと親ディレクトリを fsync する。
+// BackupGenerations は DD-PERSIST-005 の置き換える前の内容を .bak として残す世代数を表し、0 の場合は残さない。
+// TmpStaleHours は DD-PERSIST-004 の一時ファイル残骸を削除せず警告とする経過時間、TmpScanIntervalMinutes は定期的な検出の間隔を表し、
+// 0 の場合は既定値 (24時間、60分) を用いる。
+type Storage struct {
+	DurableWrites          bool `json:"durable_writes"`
+	BackupGenerations      int  `json:"backup_generations,omitempty"`
+	TmpStaleHours          int  `json:"tmp_stale_hours,omitempty"`
+	TmpScanIntervalMinutes int  `json:"tmp_scan_interval_minutes,omitempty"`
+	UTCTimestamps          bool `json:"utc_timestamps,omitempty"`
+}
+
+// Report は DD-REPORT-001 の帳票出力の設定を表し、設定していない場合 nil とする。
+// PDFFont は PDF に埋め込む TrueType フォントのパスを表す。相対パスは config.json のあるディレクトリを基準とする。
+type Report struct {
+	PDFFont string `json:"pdf_font,omitempty"`
+}
+
+// Hooks は DD-HOOK-003 の課題の変更の際に実行するスクリプトの設定を表し、設定していない場合 nil とする。
+// 各項目は実行するファイルのパスを表し、空の場合は実行しない。相対パスは config.json のあるディレクトリを基準とする。
+// TimeoutSeconds はスクリプトの終了を待つ上限 (秒) を表し、0 の場合は既定値 (30秒) を用いる。
+// 共有フォルダのプロジェクト設定に置くと書き込める利用者が全員の端末で任意のコマンドを実行できるため、端末ごとの config.json にのみ置く。
+type Hooks struct {
+	OnIssueCreated   string `json:"on_issue_created,omitempty"`
+	OnIssueUpdated   string `json:"on_issue_updated,omitempty"`
+	OnIssueCommented string `json:"on_issue_commented,omitempty"`
+	OnIssueClosed    string `json:"on_issue_closed,omitempty"`
+	TimeoutSeconds   int    `json:"timeout_seconds,omitempty"`
+}
+
+// API は DD-GRPC-001 の他ツール向けの API の設定を表し、設定していない場合 nil とする。
+// GRPCAddress は GUI の起動中に gRPC サーバーが待ち受けるアドレス (host:port) を表し、空の場合は起動しない。
+// 認証を持たない API のため、待ち受けは同じ端末のループバックアドレスに限り、端末ごとの config.json にのみ置く。
+type API struct {
+	GRPCAddress string `json:"grpc_address,omitempty"`
+}
+
+// Auth は DD-MODE-001 の Contractor モードの設定を表す。
+// ContractorIdleTimeoutMinutes が 0 の場合は既定値 (30分) を用いる。
+// PasswordPolicy は DD-CLI-009 のパスワードの強度の規則を表し、設定していない場合 nil とする。
+type Auth struct {
+	ContractorIdleTimeoutMinutes int             `json:"contractor_idle_timeout_minutes"`
+	PasswordPolicy               *PasswordPolicy `json:"password_policy,omitempty"`
+}
+
+// PasswordPolicy は DD-CLI-009 の init contractor・passwd で設定する Contractor パスワードの強度の規則を表す。
+// MinLength・MinCharClasses が 0 の場合は既定値を用いる。AllowCommon が true の場合はよく使われるパスワードも受け付ける。
+type PasswordPolicy struct {
+	MinLength      int  `json:"min_length"`
+	MinCharClasses int  `json:"min_char_classes"`
+	AllowCommon    bool `json:"allow_common"`
+}
+
+// PDFFontPath は DD-REPORT-001 の PDF に埋め込むフォントのパスを返す。相対パスは baseDir (config.json のあるディレクトリ) を基準とし、未設定の場合は空文字を返す。
+func (r *Report) PDFFontPath(baseDir string) string {
+	if r == nil || r.PDFFont == "" {
+		return ""
+	}
+	if filepath.IsAbs(r.PDFFont) {
+		return r.PDFFont
+	}
+	return filepath.Join(baseDir, r.PDFFont)
+}
+
+// Command は DD-HOOK-003 の変更の種類 event で実行するスクリプトのパスを返す。相対パスは baseDir (config.json のあるディレクトリ) を基準とし、
+// 設定していない場合や未知の種類の場合は空文字を返す。
+func (h *Hooks) Command(event, baseDir string) string {
+	if h == nil {
+		return ""
+	}
+	var path string
+	switch event {
+	case HookEventIssueCreated:
+		path = h.OnIssueCreated
+	case HookEventIssueUpdated:
+		path = h.OnIssueUpdated
+	case HookEventIssueCommented:
+		path = h.OnIssueCommented
+	case HookEventIssueClosed:
+		path = h.OnIssueClosed
+	}
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// Timeout は DD-HOOK-003 のフックのスクリプトの終了を待つ上限を返す。
+func (h *Hooks) Timeout() time.Duration {
+	if h == nil || h.TimeoutSeconds <= 0 {
+		return defaultHookTimeout
+	}
+	return time.Duration(h.TimeoutSeconds) * time.Second
+}
+
+// TmpStaleThreshold は DD-PERSIST-004 の一時ファイル残骸を削除せず警告とする経過時間を返す。未設定の場合は 0 を返し、tmpresidue の既定値を用いる。
+func (s Storage) TmpStaleThreshold() time.Duration {
+	if s.TmpStaleHours <= 0 {
+		return 0
+	}
+	return time.Duration(s.TmpStaleHours) * time.Hour
+}
+
+// TmpScanInterval は DD-PERSIST-004 の一時ファイル残骸の定期的な検出の間隔を返す。
+func (s Storage) TmpScanInterval() time.Duration {
+	if s.TmpScanIntervalMinutes <= 0 {
+		return defaultTmpScanInterval
+	}
+	return time.Duration(s.TmpScanIntervalMinutes) * time.Minute
+}
+
+// ContractorIdleTimeout は DD-MODE-001 の Contractor モードを解除するまでの無操作時間を返す。
+func (a Auth) ContractorIdleTimeout() time.Duration {
+	if a.ContractorIdleTimeoutMinutes <= 0 {
+		return defaultContractorIdleTimeout
+	}
+	return time.Duration(a.ContractorIdleTimeoutMinutes) * time.Minute
+}
+
+// ContractorPasswordPolicy は DD-CLI-009 の既定値を補ったパスワードの強度の規則を返す。
+func (a Auth) ContractorPasswordPolicy() PasswordPolicy {
+	policy := PasswordPolicy{}
+	if a.PasswordPolicy != nil {
+		policy = *a.PasswordPolicy
+	}
+	if policy.MinLength <= 0 {
+		policy.MinLength = defaultPasswordMinLength
+	}
+	if policy.MinCharClasses <= 0 {
+		policy.MinCharClasses = defaultPasswordMinCharClasses
+	}
+	return policy
 }
 
 // DefaultConfig は DD-DATA-001 の既定値に従う。
@@ -47,12 +247,26 @@ func DefaultConfig() Config {
 		UI: UI{
 			PageSize: defaultPageSize,
 		},
+		Scan: Scan{
+			Concurrency: 0,
+		},
+		Auth: Auth{
+			ContractorIdleTimeoutMinutes: 0,
+		},
+		Storage: Storage{
+			DurableWrites: false,
+		},
 	}
 }
 
 // Repository は DD-BE-002 の config.json 読み書きを担う。
+// validator を設定した場合は DD-CONF-006 に従い読み込み時にスキーマ検証と修復を行い、repair に結果を記録する。
 type Repository struct {
-	path string
+	path      string
+	validator *schema.Validator
+
+	mu     sync.Mutex
+	repair *Repair
 }
 
 var writeFile = atomicwrite.WriteFile
@@ -64,27 +278,51 @@ func NewRepository(exePath string) *Repository {
 	}
 }
 
+// NewRepositoryAt は DD-BE-002 の起動引数 --config で指定された config.json を扱う。
+func NewRepositoryAt(path string) *Repository {
+	return &Repository{path: path}
+}
+
+// Path は DD-BE-002 の扱う config.json のパスを返す。
+func (r *Repository) Path() string {
+	return r.path
+}
+
 // Load は DD-BE-002 に従い config.json を読み込み、存在しなければ既定値を返す。
 // 目的: 設定を読み取り、存在しない場合は既定値で続行する。
 // 入力: なし。
 // 出力: Config、存在フラグ、エラー。
-// エラー: 読み取り・パース失敗時に返す。
-// 副作用: config.json を読み取る。
-// 並行性: 読み取りのみでスレッドセーフ。
+// エラー: 読み取り・パース失敗時に返す。validator を設定した場合は修復に失敗した場合に返す。
+// 副作用: config.json を読み取る。validator を設定した場合、スキーマに適合しなければ DD-CONF-006 の修復を行う。
+// 並行性: 修復を行わない場合は読み取りのみでスレッドセーフ。
 // 不変条件: 返却する Config は format_version を含む。
-// 関連DD: DD-BE-002
+// 関連DD: DD-BE-002, DD-CONF-006
 func (r *Repository) Load() (Config, bool, error) {
 	data, err := os.ReadFile(r.path)
 	if errors.Is(err, os.ErrNotExist) {
 		return DefaultConfig(), false, nil
 	}
 	if err != nil {
-		return DefaultConfig(), false, fmt.Errorf("read config: %w", err)
+		return DefaultConfig(), false, apperr.WithPath(fmt.Errorf("read config: %w", err), r.path, "")
+	}
+
+	if r.validator != nil {
+		detail, validateErr := r.invalidDetail(data)
+		if validateErr != nil {
+			return DefaultConfig(), false, validateErr
+		}
+		if detail != "" {
+			cfg, repairErr := r.repairConfig(data, detail)
+			if repairErr != nil {
+				return DefaultConfig(), false, repairErr
+			}
+			return cfg, true, nil
+		}
 	}
 
 	var cfg Config
 	if unmarshalErr := json.Unmarshal(data, &cfg); unmarshalErr != nil {
-		return DefaultConfig(), false, fmt.Errorf("parse config: %w", unmarshalErr)
+		return DefaultConfig(), false, apperr.WithPath(fmt.Errorf("parse config: %w", unmarshalErr), r.path, apperr.HintCheckJSON)
 	}
 
 	return cfg, true, nil
@@ -110,14 +348,70 @@ func (r *Repository) Save(cfg Config) error {
 // エラー: 読み込みや保存失敗時に返す。
 // 副作用: config.json を更新する。
 // 並行性: 同時更新は想定しない。
-// 不変条件: last_project_root_path のみ変更し他の設定は保持する。
-// 関連DD: DD-BE-003
+// 不変条件: last_project_root_path と recent_project_roots のみ変更し他の設定は保持する。
+// 空文字は最近開いたプロジェクトルートに加えない。
+// 関連DD: DD-BE-003, DD-DATA-001
 func (r *Repository) SaveLastProjectRoot(path string) error {
 	cfg, _, err := r.Load()
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
 	cfg.LastProjectRootPath = path
+	if path != "" {
+		cfg.RecentProjectRoots = pushRecent(cfg.RecentProjectRoots, path)
+	}
+	if saveErr := r.Save(cfg); saveErr != nil {
+		return fmt.Errorf("save config: %w", saveErr)
+	}
+	return nil
+}
+
+// pushRecent は DD-DATA-001 の最近開いたプロジェクトルートの先頭に path を加え、重複を除いて上限件数に収める。
+func pushRecent(roots []string, path string) []string {
+	updated := make([]string, 0, maxRecentProjectRoots)
+	updated = append(updated, path)
+	for _, root := range roots {
+		if len(updated) == maxRecentProjectRoots {
+			break
+		}
+		if !sameRoot(root, path) {
+			updated = append(updated, root)
+		}
+	}
+	return updated
+}
+
+// sameRoot は DD-DATA-001 の2つのプロジェクトルートが同じ場所を指すかを表記の揺れを除いて判定する。
+// Windows のパスは大文字小文字を区別しないため、同じフォルダを別表記で重複して保持しない。
+func sameRoot(a, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// SaveLogLevel は DD-DATA-001 に従い log.level を更新して保存する。値の検証は呼び出し側で行う。
+func (r *Repository) SaveLogLevel(level string) error {
+	cfg, _, err := r.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	cfg.Log.Level = level
+	if saveErr := r.Save(cfg); saveErr != nil {
+		return fmt.Errorf("save config: %w", saveErr)
+	}
+	return nil
+}
+
+// SaveWindow は DD-DATA-001 に従い ui.window を更新して保存する。
+// 他の設定は保持する。設定が読み取れない場合は既定値を壊さないよう保存しない。
+func (r *Repository) SaveWindow(window Window) error {
+	cfg, _, err := r.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	cfg.UI.Window = &window
 	if saveErr := r.Save(cfg); saveErr != nil {
 		return fmt.Errorf("save config: %w", saveErr)
 	}
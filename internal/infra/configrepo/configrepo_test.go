@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoad_MissingUsesDefaults(t *testing.T) {
@@ -72,6 +73,41 @@ func TestSaveLastProjectRoot_UpdatesPath(t *testing.T) {
 	}
 }
 
+func TestSaveLastProjectRoot_KeepsBoundedRecentList(t *testing.T) {
+	// 最近開いたプロジェクトルートが新しい順に重複なく上限件数まで保持されることを確認する。
+	dir := t.TempDir()
+	repo := NewRepository(filepath.Join(dir, "ratta.exe"))
+
+	for i := 0; i < maxRecentProjectRoots+2; i++ {
+		if err := repo.SaveLastProjectRoot(filepath.Join(dir, "proj", string(rune('a'+i)))); err != nil {
+			t.Fatalf("SaveLastProjectRoot error: %v", err)
+		}
+	}
+	reopened := filepath.Join(dir, "proj", "c")
+	if err := repo.SaveLastProjectRoot(reopened + string(filepath.Separator)); err != nil {
+		t.Fatalf("SaveLastProjectRoot error: %v", err)
+	}
+	if err := repo.SaveLastProjectRoot(""); err != nil {
+		t.Fatalf("SaveLastProjectRoot error: %v", err)
+	}
+
+	cfg, _, err := repo.Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if len(cfg.RecentProjectRoots) != maxRecentProjectRoots {
+		t.Fatalf("unexpected recent roots: %v", cfg.RecentProjectRoots)
+	}
+	if filepath.Clean(cfg.RecentProjectRoots[0]) != reopened {
+		t.Fatalf("expected reopened root first: %v", cfg.RecentProjectRoots)
+	}
+	for _, root := range cfg.RecentProjectRoots[1:] {
+		if filepath.Clean(root) == reopened {
+			t.Fatalf("duplicate root kept: %v", cfg.RecentProjectRoots)
+		}
+	}
+}
+
 func TestSaveLastProjectRoot_LoadError(t *testing.T) {
 	// 既存設定が破損している場合に保存が失敗することを確認する。
 	dir := t.TempDir()
@@ -85,6 +121,31 @@ func TestSaveLastProjectRoot_LoadError(t *testing.T) {
 	}
 }
 
+func TestSaveWindow_KeepsOtherSettings(t *testing.T) {
+	// ui.window を保存しても他の設定が保持され、読み込みで復元できることを確認する。
+	dir := t.TempDir()
+	repo := NewRepository(filepath.Join(dir, "ratta.exe"))
+	if err := repo.SaveLastProjectRoot("C:/proj"); err != nil {
+		t.Fatalf("SaveLastProjectRoot error: %v", err)
+	}
+
+	window := Window{Width: 1024, Height: 700, X: -1200, Y: 40, Maximised: true}
+	if err := repo.SaveWindow(window); err != nil {
+		t.Fatalf("SaveWindow error: %v", err)
+	}
+
+	cfg, _, err := repo.Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if cfg.LastProjectRootPath != "C:/proj" || cfg.UI.PageSize != defaultPageSize {
+		t.Fatalf("other settings must be kept: %+v", cfg)
+	}
+	if cfg.UI.Window == nil || *cfg.UI.Window != window {
+		t.Fatalf("unexpected window: %+v", cfg.UI.Window)
+	}
+}
+
 func TestSave_AtomicWriteFailure(t *testing.T) {
 	// atomic write に失敗した場合にエラーが返ることを確認する。
 	dir := t.TempDir()
@@ -100,3 +161,110 @@ func TestSave_AtomicWriteFailure(t *testing.T) {
 		t.Fatal("expected save error")
 	}
 }
+
+func TestNewRepositoryAt_UsesGivenPath(t *testing.T) {
+	// 指定したパスの config.json を読み書きし、実行ファイル隣の config.json を扱わないことを確認する。
+	dir := t.TempDir()
+	path := filepath.Join(dir, "profiles", "a.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	repo := NewRepositoryAt(path)
+	if repo.Path() != path {
+		t.Fatalf("unexpected path: %s", repo.Path())
+	}
+	if err := repo.SaveLastProjectRoot(filepath.Join(dir, "project")); err != nil {
+		t.Fatalf("SaveLastProjectRoot error: %v", err)
+	}
+	cfg, ok, err := repo.Load()
+	if err != nil || !ok {
+		t.Fatalf("expected saved config, ok=%v err=%v", ok, err)
+	}
+	if cfg.LastProjectRootPath != filepath.Join(dir, "project") {
+		t.Fatalf("unexpected last project root: %s", cfg.LastProjectRootPath)
+	}
+	if _, statErr := os.Stat(filepath.Join(dir, "config.json")); !errors.Is(statErr, os.ErrNotExist) {
+		t.Fatalf("expected no config.json next to the directory, err=%v", statErr)
+	}
+}
+
+func TestAuth_ContractorIdleTimeout(t *testing.T) {
+	// 未設定 (0) では既定の30分、設定時は指定した分数を無操作時間の上限とし、保存後も保持することを確認する。
+	if got := (Auth{}).ContractorIdleTimeout(); got != 30*time.Minute {
+		t.Fatalf("unexpected default timeout: %v", got)
+	}
+	repo := NewRepository(filepath.Join(t.TempDir(), "ratta.exe"))
+	cfg := DefaultConfig()
+	cfg.Auth.ContractorIdleTimeoutMinutes = 5
+	if err := repo.Save(cfg); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	if err := repo.SaveLogLevel("debug"); err != nil {
+		t.Fatalf("SaveLogLevel error: %v", err)
+	}
+	loaded, _, err := repo.Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if got := loaded.Auth.ContractorIdleTimeout(); got != 5*time.Minute {
+		t.Fatalf("unexpected timeout: %v", got)
+	}
+}
+
+func TestStorage_TmpResidueSettings(t *testing.T) {
+	// 未設定 (0) では残骸の経過時間を tmpresidue の既定値に委ね、検出間隔は既定の60分とすることを確認する。
+	if got := (Storage{}).TmpStaleThreshold(); got != 0 {
+		t.Fatalf("unexpected default threshold: %v", got)
+	}
+	if got := (Storage{}).TmpScanInterval(); got != time.Hour {
+		t.Fatalf("unexpected default interval: %v", got)
+	}
+	storage := Storage{TmpStaleHours: 48, TmpScanIntervalMinutes: 15}
+	if storage.TmpStaleThreshold() != 48*time.Hour || storage.TmpScanInterval() != 15*time.Minute {
+		t.Fatalf("unexpected configured values: %v %v", storage.TmpStaleThreshold(), storage.TmpScanInterval())
+	}
+}
+
+func TestAuth_ContractorPasswordPolicy(t *testing.T) {
+	// 未設定や 0 の項目は既定の規則で補い、設定した値は保持することを確認する。
+	got := (Auth{}).ContractorPasswordPolicy()
+	if got != (PasswordPolicy{MinLength: 12, MinCharClasses: 2}) {
+		t.Fatalf("unexpected default policy: %+v", got)
+	}
+	got = Auth{PasswordPolicy: &PasswordPolicy{MinCharClasses: 3, AllowCommon: true}}.ContractorPasswordPolicy()
+	if got != (PasswordPolicy{MinLength: 12, MinCharClasses: 3, AllowCommon: true}) {
+		t.Fatalf("unexpected configured policy: %+v", got)
+	}
+}
+
+func TestHooks_CommandAndTimeout(t *testing.T) {
+	// 未設定では実行せず既定の30秒とし、相対パスは config.json のディレクトリを基準に、絶対パスはそのまま返すことを確認する。
+	var missing *Hooks
+	if missing.Command(HookEventIssueClosed, "base") != "" || missing.Timeout() != 30*time.Second {
+		t.Fatalf("unexpected defaults: %q %v", missing.Command(HookEventIssueClosed, "base"), missing.Timeout())
+	}
+	absolute, _ := filepath.Abs(filepath.Join("opt", "notify"))
+	hooks := &Hooks{OnIssueClosed: filepath.Join("hooks", "closed"), OnIssueCreated: absolute, TimeoutSeconds: 5}
+	if got := hooks.Command(HookEventIssueClosed, "base"); got != filepath.Join("base", "hooks", "closed") {
+		t.Fatalf("unexpected relative command: %q", got)
+	}
+	if got := hooks.Command(HookEventIssueCreated, "base"); got != absolute {
+		t.Fatalf("unexpected absolute command: %q", got)
+	}
+	if hooks.Command(HookEventIssueUpdated, "base") != "" || hooks.Command("issue.deleted", "base") != "" {
+		t.Fatal("expected no command for unset or unknown events")
+	}
+	if hooks.Timeout() != 5*time.Second {
+		t.Fatalf("unexpected timeout: %v", hooks.Timeout())
+	}
+	repo := NewRepository(filepath.Join(t.TempDir(), "ratta.exe"))
+	cfg := DefaultConfig()
+	cfg.Hooks = hooks
+	if err := repo.Save(cfg); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	loaded, _, err := repo.Load()
+	if err != nil || loaded.Hooks == nil || *loaded.Hooks != *hooks {
+		t.Fatalf("expected hooks to be kept: %+v %v", loaded.Hooks, err)
+	}
+}
@@ -0,0 +1,149 @@
+// repair.go は DD-CONF-006 のスキーマに適合しない config.json の退避と修復を担い、
+// 修復の通知は扱わない。通知は上位層が Repaired の結果を起動時情報の警告に変換して行う。
+package configrepo
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"ratta/internal/infra/schema"
+)
+
+// backupTimeLayout は DD-CONF-006 の修復前の内容を退避するファイル名に付ける日時の書式を表す。
+const backupTimeLayout = "20060102-150405"
+
+var now = time.Now
+
+// Repair は DD-CONF-006 の config.json を修復した結果を表す。
+// BackupPath は修復前の内容の退避先、Detail はスキーマ不整合または JSON の構文エラーの内容を表す。
+type Repair struct {
+	Path       string
+	BackupPath string
+	Detail     string
+}
+
+// WithValidator は DD-CONF-006 の読み込み時のスキーマ検証に用いる Validator を設定する。nil の場合は検証しない。
+func (r *Repository) WithValidator(validator *schema.Validator) *Repository {
+	r.validator = validator
+	return r
+}
+
+// Repaired は DD-CONF-006 のこの Repository で最後に行った修復の結果を返す。修復していない場合は nil を返す。
+func (r *Repository) Repaired() *Repair {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.repair
+}
+
+// invalidDetail は DD-CONF-006 の config.json の内容がスキーマに適合しない場合にその内容を返す。適合する場合は空文字を返す。
+func (r *Repository) invalidDetail(data []byte) (string, error) {
+	result, err := r.validator.ValidateConfig(data)
+	if err != nil {
+		// 構文エラーは修復の対象とし、スキーマが読み込めていない場合のみ検証の失敗とする。
+		var syntaxTarget any
+		if json.Unmarshal(data, &syntaxTarget) != nil {
+			return err.Error(), nil
+		}
+		return "", fmt.Errorf("validate config: %w", err)
+	}
+	return result.Detail(), nil
+}
+
+// repairConfig は DD-CONF-006 に従い、スキーマに適合しない config.json を退避し、復元できる項目を既定値に重ねて保存する。
+// 目的: 壊れた設定で起動のたびに既定値へ黙って戻るのを避け、残せる設定は引き継ぐ。
+// 入力: data は読み込んだ config.json の内容、detail は不整合の内容。
+// 出力: 修復した Config とエラー。
+// エラー: 退避・保存に失敗した場合に返す。
+// 副作用: 修復前の内容を config.json.invalid-<日時> に書き出し、config.json を置き換える。修復結果を記録する。
+// 並行性: 同時更新は想定しない。
+// 不変条件: 保存する内容はスキーマに適合する。退避に失敗した場合は config.json を置き換えない。
+// 関連DD: DD-CONF-006, DD-PERSIST-002
+func (r *Repository) repairConfig(data []byte, detail string) (Config, error) {
+	backupPath := r.path + ".invalid-" + now().Format(backupTimeLayout)
+	if err := writeFile(backupPath, data); err != nil {
+		return DefaultConfig(), fmt.Errorf("back up invalid config: %w", err)
+	}
+	cfg := r.recoverConfig(data)
+	if err := r.Save(cfg); err != nil {
+		return DefaultConfig(), fmt.Errorf("save repaired config: %w", err)
+	}
+	r.mu.Lock()
+	r.repair = &Repair{Path: r.path, BackupPath: backupPath, Detail: detail}
+	r.mu.Unlock()
+	return cfg, nil
+}
+
+// recoverConfig は DD-CONF-006 の既定値に、元の内容のうちスキーマに適合する項目を1件ずつ重ねた Config を返す。
+// 既定値にある設定群は項目ごとに、既定値に無い設定群 (ui.window など) はまとめて採否を判定する。
+func (r *Repository) recoverConfig(data []byte) Config {
+	doc, err := toDocument(DefaultConfig())
+	if err != nil {
+		return DefaultConfig()
+	}
+	var original map[string]any
+	if json.Unmarshal(data, &original) == nil {
+		r.mergeValid(doc, doc, original)
+	}
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		return DefaultConfig()
+	}
+	cfg := DefaultConfig()
+	if err := json.Unmarshal(encoded, &cfg); err != nil {
+		return DefaultConfig()
+	}
+	return cfg
+}
+
+// mergeValid は DD-CONF-006 の original の項目を target に重ね、root 全体がスキーマに適合しなくなる項目は元に戻す。
+func (r *Repository) mergeValid(root, target, original map[string]any) {
+	keys := make([]string, 0, len(original))
+	for key := range original {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := original[key]
+		current, exists := target[key]
+		if nested, ok := value.(map[string]any); ok {
+			if section, ok := current.(map[string]any); ok {
+				r.mergeValid(root, section, nested)
+				continue
+			}
+		}
+		target[key] = value
+		if r.conforms(root) {
+			continue
+		}
+		if exists {
+			target[key] = current
+		} else {
+			delete(target, key)
+		}
+	}
+}
+
+// conforms は DD-CONF-006 の doc がスキーマに適合するかを返す。
+func (r *Repository) conforms(doc map[string]any) bool {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return false
+	}
+	result, err := r.validator.ValidateConfig(data)
+	return err == nil && len(result.Issues) == 0
+}
+
+// toDocument は DD-CONF-006 の Config を項目ごとに扱える JSON オブジェクトに変換する。
+func toDocument(cfg Config) (map[string]any, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -0,0 +1,113 @@
+package configrepo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ratta/internal/infra/schema"
+)
+
+// newValidatedRepository はテスト用にリポジトリ同梱のスキーマで検証する Repository と config.json のパスを返す。
+func newValidatedRepository(t *testing.T) (*Repository, string) {
+	t.Helper()
+	validator, err := schema.NewValidatorFromDir(filepath.Join("..", "..", "..", "schemas"))
+	if err != nil {
+		t.Fatalf("NewValidatorFromDir error: %v", err)
+	}
+	original := now
+	now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.Local) }
+	t.Cleanup(func() { now = original })
+	dir := t.TempDir()
+	return NewRepository(filepath.Join(dir, "ratta.exe")).WithValidator(validator), filepath.Join(dir, "config.json")
+}
+
+func TestLoad_RepairsInvalidConfig(t *testing.T) {
+	// スキーマに適合しない config.json は退避し、適合する項目だけを既定値に重ねて保存し直すことを確認する。
+	repo, path := newValidatedRepository(t)
+	original := []byte(`{
+  "format_version": 1,
+  "last_project_root_path": "C:/proj",
+  "log": { "level": "verbose" },
+  "ui": { "page_size": 20, "language": "en", "unknown": true },
+  "scan": { "concurrency": 4 },
+  "storage": { "durable_writes": "yes", "backup_generations": 3 },
+  "extra": 1
+}`)
+	if err := os.WriteFile(path, original, 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, ok, err := repo.Load()
+	if err != nil || !ok {
+		t.Fatalf("expected repaired config, got ok=%v err=%v", ok, err)
+	}
+	if cfg.LastProjectRootPath != "C:/proj" || cfg.UI.Language != "en" || cfg.Scan.Concurrency != 4 || cfg.Storage.BackupGenerations != 3 {
+		t.Fatalf("valid fields must be recovered: %+v", cfg)
+	}
+	if cfg.Log.Level != "info" || cfg.Storage.DurableWrites {
+		t.Fatalf("invalid fields must fall back to defaults: %+v", cfg)
+	}
+
+	repair := repo.Repaired()
+	if repair == nil || repair.Detail == "" || repair.Path != path {
+		t.Fatalf("unexpected repair: %+v", repair)
+	}
+	if repair.BackupPath != path+".invalid-20260102-030405" {
+		t.Fatalf("unexpected backup path: %s", repair.BackupPath)
+	}
+	backup, err := os.ReadFile(repair.BackupPath)
+	if err != nil || string(backup) != string(original) {
+		t.Fatalf("expected original to be backed up, err=%v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if result, err := repo.validator.ValidateConfig(data); err != nil || len(result.Issues) != 0 {
+		t.Fatalf("expected repaired config to conform, got %v err=%v", result.Issues, err)
+	}
+}
+
+func TestLoad_RepairsCorruptConfigWithDefaults(t *testing.T) {
+	// JSON として読めない config.json は退避して既定値で置き換え、以後の保存を妨げないことを確認する。
+	repo, path := newValidatedRepository(t)
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, ok, err := repo.Load()
+	if err != nil || !ok {
+		t.Fatalf("expected repaired config, got ok=%v err=%v", ok, err)
+	}
+	if cfg.UI.PageSize != defaultPageSize || repo.Repaired() == nil {
+		t.Fatalf("expected defaults and a repair record: %+v", cfg)
+	}
+	if err := repo.SaveLastProjectRoot("C:/proj"); err != nil {
+		t.Fatalf("SaveLastProjectRoot error: %v", err)
+	}
+}
+
+func TestLoad_ValidConfigIsNotRepaired(t *testing.T) {
+	// スキーマに適合する config.json は書き換えず、修復の記録も残さないことを確認する。
+	repo, path := newValidatedRepository(t)
+	if err := repo.SaveLastProjectRoot("C:/proj"); err != nil {
+		t.Fatalf("SaveLastProjectRoot error: %v", err)
+	}
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+
+	if _, _, err := repo.Load(); err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	after, err := os.ReadFile(path)
+	if err != nil || string(after) != string(before) {
+		t.Fatalf("expected config to be kept, err=%v", err)
+	}
+	if repo.Repaired() != nil {
+		t.Fatal("expected no repair")
+	}
+}
@@ -0,0 +1,165 @@
+// settings.go は DD-CONF-005 の利用者が画面から変更するアプリ設定の既定値補完・検証・保存を担い、
+// 設定を画面へ反映する処理は扱わない。反映はフロントエンドが起動時情報をもとに行う。
+package configrepo
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"ratta/internal/domain/issue"
+	"ratta/internal/domain/timeutil"
+)
+
+const (
+	// defaultSortBy は DD-CONF-005 の課題一覧の既定の並び替え項目を表す。
+	defaultSortBy = "updated_at"
+	// defaultSortOrder は DD-CONF-005 の課題一覧の既定の並び順を表す。
+	defaultSortOrder = "desc"
+	// defaultDateFormat は DD-CONF-005 の日付の既定の表示形式を表す。
+	defaultDateFormat = "YYYY-MM-DD"
+	// defaultLanguage は DD-CONF-005 の既定の表示言語を表す。
+	defaultLanguage = "ja"
+	// maxAuthorNameLength は DD-CONF-005 の既定の作成者名の最大文字数を表し、コメントの作成者名の制約と揃える。
+	maxAuthorNameLength = 255
+	// maxTimeZoneLength は DD-CONF-005 の表示用のタイムゾーン名の最大長を表し、config.schema.json と揃える。
+	maxTimeZoneLength = 64
+)
+
+var (
+	// sortByValues は DD-CONF-005 の既定の並び替え項目として受け付ける DD-BE-003 の sort_by を表す。
+	sortByValues = []string{"updated_at", "due_date", "priority", "status", "title"}
+	// sortOrderValues は DD-CONF-005 の既定の並び順として受け付ける値を表す。
+	sortOrderValues = []string{"asc", "desc"}
+	// dateFormatValues は DD-CONF-005 の日付の表示形式として受け付ける値を表す。
+	dateFormatValues = []string{"YYYY-MM-DD", "YYYY/MM/DD", "YYYY年MM月DD日"}
+	// languageValues は DD-CONF-005 の表示言語として受け付ける値を表す。
+	languageValues = []string{"ja", "en"}
+)
+
+// Sort は DD-CONF-005 の課題一覧の既定の並び替えを表す。
+type Sort struct {
+	By    string `json:"sort_by"`
+	Order string `json:"sort_order"`
+}
+
+// ConfirmOnDelete は DD-CONF-005 の削除前に確認ダイアログを表示するかを操作ごとに表す。
+// Category は空のカテゴリの削除、CategoryWithIssues は課題を含むカテゴリのゴミ箱への退避を表す。
+type ConfirmOnDelete struct {
+	Category           bool `json:"category"`
+	CategoryWithIssues bool `json:"category_with_issues"`
+}
+
+// Settings は DD-CONF-005 の既定値を補ったアプリ設定を表す。TimeZone は表示用のタイムゾーン名で、空の場合は OS のタイムゾーンを表す。
+type Settings struct {
+	DefaultSort       Sort
+	DefaultAuthorName string
+	DateFormat        string
+	Language          string
+	TimeZone          string
+	ConfirmOnDelete   ConfirmOnDelete
+}
+
+// Settings は DD-CONF-005 の未設定の項目に既定値を補ったアプリ設定を返す。
+func (u UI) Settings() Settings {
+	settings := DefaultSettings()
+	if u.DefaultSort != nil {
+		settings.DefaultSort = *u.DefaultSort
+	}
+	settings.DefaultAuthorName = u.DefaultAuthorName
+	if u.DateFormat != "" {
+		settings.DateFormat = u.DateFormat
+	}
+	if u.Language != "" {
+		settings.Language = u.Language
+	}
+	settings.TimeZone = u.TimeZone
+	if u.ConfirmOnDelete != nil {
+		settings.ConfirmOnDelete = *u.ConfirmOnDelete
+	}
+	return settings
+}
+
+// DefaultSettings は DD-CONF-005 のアプリ設定の既定値を返す。削除前の確認はすべて行う。
+func DefaultSettings() Settings {
+	return Settings{
+		DefaultSort:     Sort{By: defaultSortBy, Order: defaultSortOrder},
+		DateFormat:      defaultDateFormat,
+		Language:        defaultLanguage,
+		ConfirmOnDelete: ConfirmOnDelete{Category: true, CategoryWithIssues: true},
+	}
+}
+
+// ValidateSettings は DD-CONF-005 のアプリ設定の値域を検証する。
+// 目的: config.json のスキーマに反する値を保存しないようにする。
+// 入力: settings は検証対象。
+// 出力: 検証エラー一覧。問題が無ければ空。
+// エラー: なし。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 列挙値は config.schema.json の enum と一致させる。
+// 関連DD: DD-CONF-005, DD-CONF-003
+func ValidateSettings(settings Settings) issue.ValidationErrors {
+	var errs issue.ValidationErrors
+	if !contains(sortByValues, settings.DefaultSort.By) {
+		errs = append(errs, issue.ValidationError{Field: "default_sort_by", Message: "invalid value"})
+	}
+	if !contains(sortOrderValues, settings.DefaultSort.Order) {
+		errs = append(errs, issue.ValidationError{Field: "default_sort_order", Message: "invalid value"})
+	}
+	if utf8.RuneCountInString(settings.DefaultAuthorName) > maxAuthorNameLength {
+		errs = append(errs, issue.ValidationError{Field: "default_author_name", Message: "too long"})
+	}
+	if !contains(dateFormatValues, settings.DateFormat) {
+		errs = append(errs, issue.ValidationError{Field: "date_format", Message: "invalid value"})
+	}
+	if !contains(languageValues, settings.Language) {
+		errs = append(errs, issue.ValidationError{Field: "language", Message: "invalid value"})
+	}
+	if len(settings.TimeZone) > maxTimeZoneLength {
+		errs = append(errs, issue.ValidationError{Field: "time_zone", Message: "too long"})
+	} else if _, err := timeutil.LoadLocation(settings.TimeZone); err != nil {
+		errs = append(errs, issue.ValidationError{Field: "time_zone", Message: "unknown time zone"})
+	}
+	return errs
+}
+
+// SaveSettings は DD-CONF-005 に従いアプリ設定を検証し、ui の該当項目を更新して保存する。
+// 目的: 利用者が画面で変更した設定を次回起動時にも引き継ぐ。
+// 入力: settings は保存する設定。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: 検証失敗時は issue.ValidationErrors、読み込みや保存の失敗時はラップしたエラーを返す。
+// 副作用: config.json を更新する。
+// 並行性: 同時更新は想定しない。
+// 不変条件: ui.page_size・ui.window など他の設定は保持する。検証に失敗した場合は保存しない。
+// 関連DD: DD-CONF-005, DD-DATA-001
+func (r *Repository) SaveSettings(settings Settings) error {
+	if errs := ValidateSettings(settings); len(errs) > 0 {
+		return errs
+	}
+	cfg, _, err := r.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	sort := settings.DefaultSort
+	confirm := settings.ConfirmOnDelete
+	cfg.UI.DefaultSort = &sort
+	cfg.UI.DefaultAuthorName = settings.DefaultAuthorName
+	cfg.UI.DateFormat = settings.DateFormat
+	cfg.UI.Language = settings.Language
+	cfg.UI.TimeZone = settings.TimeZone
+	cfg.UI.ConfirmOnDelete = &confirm
+	if saveErr := r.Save(cfg); saveErr != nil {
+		return fmt.Errorf("save config: %w", saveErr)
+	}
+	return nil
+}
+
+// contains は DD-CONF-005 の値が列挙値のいずれかに一致するかを返す。
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
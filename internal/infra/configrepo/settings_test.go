@@ -0,0 +1,75 @@
+package configrepo
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"ratta/internal/domain/issue"
+)
+
+func TestUI_SettingsFillsDefaults(t *testing.T) {
+	// 未設定の項目は既定値で補い、削除前の確認は既定ですべて行うことを確認する。
+	got := (UI{}).Settings()
+	if got != DefaultSettings() {
+		t.Fatalf("unexpected default settings: %+v", got)
+	}
+	if !got.ConfirmOnDelete.Category || !got.ConfirmOnDelete.CategoryWithIssues {
+		t.Fatalf("expected confirmations by default: %+v", got.ConfirmOnDelete)
+	}
+	got = UI{Language: "en", ConfirmOnDelete: &ConfirmOnDelete{}}.Settings()
+	if got.Language != "en" || got.DateFormat != defaultDateFormat || got.ConfirmOnDelete.Category {
+		t.Fatalf("unexpected configured settings: %+v", got)
+	}
+}
+
+func TestSaveSettings_KeepsOtherSettings(t *testing.T) {
+	// アプリ設定を保存しても他の設定が保持され、読み込みで復元できることを確認する。
+	dir := t.TempDir()
+	repo := NewRepository(filepath.Join(dir, "ratta.exe"))
+	if err := repo.SaveWindow(Window{Width: 800, Height: 600}); err != nil {
+		t.Fatalf("SaveWindow error: %v", err)
+	}
+
+	settings := Settings{
+		DefaultSort:       Sort{By: "priority", Order: "asc"},
+		DefaultAuthorName: "alice",
+		DateFormat:        "YYYY/MM/DD",
+		Language:          "en",
+		TimeZone:          "UTC",
+		ConfirmOnDelete:   ConfirmOnDelete{CategoryWithIssues: true},
+	}
+	if err := repo.SaveSettings(settings); err != nil {
+		t.Fatalf("SaveSettings error: %v", err)
+	}
+
+	cfg, _, err := repo.Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if cfg.UI.Window == nil || cfg.UI.Window.Width != 800 {
+		t.Fatalf("window must be kept: %+v", cfg.UI.Window)
+	}
+	if got := cfg.UI.Settings(); got != settings {
+		t.Fatalf("unexpected settings: %+v", got)
+	}
+}
+
+func TestSaveSettings_RejectsInvalidValues(t *testing.T) {
+	// 値域外の設定は項目ごとの検証エラーとし、config.json を作らないことを確認する。
+	dir := t.TempDir()
+	repo := NewRepository(filepath.Join(dir, "ratta.exe"))
+	settings := DefaultSettings()
+	settings.DefaultSort.By = "assignee"
+	settings.Language = "fr"
+	settings.TimeZone = "Mars/Olympus"
+
+	err := repo.SaveSettings(settings)
+	var errs issue.ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Fatalf("expected three validation errors, got %v", err)
+	}
+	if _, hasConfig, _ := repo.Load(); hasConfig {
+		t.Fatal("config must not be saved")
+	}
+}
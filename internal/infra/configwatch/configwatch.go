@@ -0,0 +1,138 @@
+// Package configwatch は config.json の外部変更を検知して通知することを担い、設定の読み込みや反映は扱わない。
+// atomic write による置き換えは rename で届くため、ファイルではなく配置先のディレクトリを監視してファイル名で絞り込む。
+package configwatch
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// defaultDebounce は DD-CONF-007 の通知をまとめる待ち時間を表す。
+// 一時ファイルの作成と rename、エディタの複数回の書き込みを1回の通知へまとめる。
+const defaultDebounce = 300 * time.Millisecond
+
+// Watcher は DD-CONF-007 の config.json の監視を表す。
+type Watcher struct {
+	path     string
+	notify   *fsnotify.Watcher
+	onChange func()
+	debounce time.Duration
+
+	emitMu sync.Mutex
+	mu     sync.Mutex
+	timer  *time.Timer
+	closed bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Start は DD-CONF-007 の config.json の監視を開始する。
+// 目的: 利用者や配布ツールが config.json を書き換えたことを検知し、再起動なしに設定を反映できるようにする。
+// 入力: path は config.json のパス、onChange は変更時の通知先。
+// 出力: Watcher とエラー。
+// エラー: 監視の初期化、配置先ディレクトリの監視登録失敗時に返す。
+// 副作用: 配置先ディレクトリを監視対象に登録し、監視ゴルーチンを起動する。
+// 並行性: onChange は監視ゴルーチンから呼ばれる。Close まで同時に複数回呼ばれることはない。
+// 不変条件: 同じディレクトリの他のファイルや一時ファイルの変更は通知しない。
+// 関連DD: DD-CONF-007
+func Start(path string, onChange func()) (*Watcher, error) {
+	return start(path, onChange, defaultDebounce)
+}
+
+// start は DD-CONF-007 の待ち時間を指定して監視を開始する。
+func start(path string, onChange func(), debounce time.Duration) (*Watcher, error) {
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("create config watcher: %w", err)
+	}
+	w := &Watcher{
+		path:     filepath.Clean(path),
+		notify:   notify,
+		onChange: onChange,
+		debounce: debounce,
+		done:     make(chan struct{}),
+	}
+	if addErr := notify.Add(filepath.Dir(w.path)); addErr != nil {
+		if closeErr := notify.Close(); closeErr != nil {
+			return nil, fmt.Errorf("watch config dir failed: %w; close error: %s", addErr, closeErr.Error())
+		}
+		return nil, fmt.Errorf("watch config dir: %w", addErr)
+	}
+	w.wg.Add(1)
+	go w.loop()
+	return w, nil
+}
+
+// Close は DD-CONF-007 の監視を停止する。未通知の変更は破棄する。
+func (w *Watcher) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mu.Unlock()
+
+	close(w.done)
+	err := w.notify.Close()
+	w.wg.Wait()
+	if err != nil {
+		return fmt.Errorf("close config watcher: %w", err)
+	}
+	return nil
+}
+
+// loop は DD-CONF-007 の fsnotify イベントを受け取り、config.json への変更のみ通知を予約する。
+func (w *Watcher) loop() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.done:
+			return
+		case raw, ok := <-w.notify.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(raw.Name) == w.path && (raw.Has(fsnotify.Create) || raw.Has(fsnotify.Write)) {
+				w.schedule()
+			}
+		case _, ok := <-w.notify.Errors:
+			// 監視エラーは通知の取りこぼしに留まり、次回の起動時に設定を読み直すことで補われる。
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// schedule は DD-CONF-007 の待ち時間経過後の通知を予約する。待ち時間中の変更は1回にまとめる。
+func (w *Watcher) schedule() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed || w.timer != nil {
+		return
+	}
+	w.timer = time.AfterFunc(w.debounce, w.flush)
+}
+
+// flush は DD-CONF-007 の予約した通知を行う。
+func (w *Watcher) flush() {
+	w.mu.Lock()
+	w.timer = nil
+	closed := w.closed
+	w.mu.Unlock()
+	if closed {
+		return
+	}
+	w.emitMu.Lock()
+	defer w.emitMu.Unlock()
+	w.onChange()
+}
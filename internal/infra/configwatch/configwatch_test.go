@@ -0,0 +1,71 @@
+// configwatch_test.go は config.json の変更検知のテストを行い、設定の反映は扱わない。
+package configwatch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ratta/internal/infra/atomicwrite"
+)
+
+func TestWatcher_NotifiesOnlyConfigChanges(t *testing.T) {
+	// 同じディレクトリの他のファイルの変更は通知せず、atomic write による config.json の置き換えを1回にまとめて通知することを確認する。
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	received := make(chan struct{}, 16)
+	watcher, err := start(path, func() { received <- struct{}{} }, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("start error: %v", err)
+	}
+	t.Cleanup(func() { _ = watcher.Close() })
+
+	if err := os.WriteFile(filepath.Join(dir, "other.json"), []byte(`{}`), 0o600); err != nil {
+		t.Fatalf("write other: %v", err)
+	}
+	select {
+	case <-received:
+		t.Fatal("unexpected notification for another file")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	if err := atomicwrite.WriteFile(path, []byte(`{"format_version":1}`)); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	select {
+	case <-received:
+	case <-time.After(3 * time.Second):
+		t.Fatal("config change not notified")
+	}
+	select {
+	case <-received:
+		t.Fatal("expected a single notification for one write")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestWatcher_CloseStopsNotifications(t *testing.T) {
+	// 停止後の変更は通知せず、重ねて停止してもエラーにならないことを確認する。
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	received := make(chan struct{}, 16)
+	watcher, err := start(path, func() { received <- struct{}{} }, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("start error: %v", err)
+	}
+	if err := watcher.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	if err := watcher.Close(); err != nil {
+		t.Fatalf("second Close error: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(`{}`), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	select {
+	case <-received:
+		t.Fatal("unexpected notification after close")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
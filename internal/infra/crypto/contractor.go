@@ -1,4 +1,4 @@
-// Package crypto は contractor.json の暗号化と検証を担い、UI表示は扱わない。
+// Package crypto は contractor.json の暗号化と検証、受け渡すファイルの署名を担い、UI表示は扱わない。
 // 暗号アルゴリズムの選定は詳細設計に従う。
 package crypto
 
@@ -12,16 +12,28 @@ import (
 	"fmt"
 	"io"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/pbkdf2"
 )
 
 const (
-	formatVersion    = 1
-	kdfName          = "pbkdf2-hmac-sha256"
-	kdfIterations    = 200000
-	saltSizeBytes    = 16
-	nonceSizeBytes   = 16
-	derivedKeyLength = 32
+	// KDFPBKDF2SHA256 は DD-CLI-005 の既定の鍵導出方式 PBKDF2-HMAC-SHA256 を表す。
+	KDFPBKDF2SHA256 = "pbkdf2-hmac-sha256"
+	// KDFArgon2id は DD-CLI-005 の鍵導出方式 Argon2id を表す。kdf_iterations は時間コストとして扱う。
+	KDFArgon2id = "argon2id"
+)
+
+const (
+	formatVersion = 1
+	kdfName       = KDFPBKDF2SHA256
+	kdfIterations = 200000
+	// pbkdf2VerifyFloor は既定値を引き上げた後も検証を受け付ける PBKDF2 の反復回数の下限 (最初の既定値) を表す。
+	pbkdf2VerifyFloor = 200000
+	argon2MemoryKiB   = 64 * 1024
+	argon2Threads     = 4
+	saltSizeBytes     = 16
+	nonceSizeBytes    = 16
+	derivedKeyLength  = 32
 )
 
 const fixedPlaintext = "contractor-mode"
@@ -35,22 +47,105 @@ var ErrPasswordMismatch = errors.New("password mismatch")
 // randReader は DD-CLI-005 のランダム生成をテストで固定するための差し替え点。
 var randReader io.Reader = rand.Reader
 
+// KDFParams は DD-CLI-005 の鍵導出方式と反復回数を表す。
+type KDFParams struct {
+	Name       string
+	Iterations int
+}
+
+// kdfSpec は DD-CLI-005 の鍵導出方式ごとの反復回数の既定値と許容範囲を表す。
+// minIterations は新規生成時の下限、verifyMinIterations は保存済みの contractor.json を検証する際の下限を表す。
+type kdfSpec struct {
+	defaultIterations   int
+	minIterations       int
+	verifyMinIterations int
+	maxIterations       int
+}
+
+// kdfSpecs は DD-CLI-005 の対応済みの鍵導出方式を表す。
+// 生成時の下限は既定値の強度を下回らない値、上限は起動時の検証が実用的な時間で終わる値とする。
+// 既定値を引き上げても以前の既定値で作成した contractor.json を使い続けられるよう、検証時の下限は生成時と分けて据え置く。
+var kdfSpecs = map[string]kdfSpec{
+	KDFPBKDF2SHA256: {defaultIterations: kdfIterations, minIterations: kdfIterations, verifyMinIterations: pbkdf2VerifyFloor, maxIterations: 10000000},
+	KDFArgon2id:     {defaultIterations: 3, minIterations: 2, verifyMinIterations: 2, maxIterations: 100},
+}
+
+// SupportedKDFs は DD-CLI-005 の対応済みの鍵導出方式の名前を既定の方式から順に返す。
+func SupportedKDFs() []string {
+	return []string{KDFPBKDF2SHA256, KDFArgon2id}
+}
+
+// DefaultKDFParams は DD-CLI-005 の既定の鍵導出設定を返す。
+func DefaultKDFParams() KDFParams {
+	return KDFParams{Name: kdfName, Iterations: kdfIterations}
+}
+
+// ResolveKDFParams は DD-CLI-005 の鍵導出設定を検証し、反復回数が 0 の場合は方式ごとの既定値で補う。
+// 目的: init contractor の指定値を対応済みの方式と許容範囲に照らして確定する。
+// 入力: name は方式名 (空の場合は既定の方式)、iterations は反復回数 (0 の場合は既定値)。
+// 出力: 確定した KDFParams とエラー。
+// エラー: 未対応の方式、許容範囲外の反復回数の場合に ErrUnsupportedKDF を包んで返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 成功時の値は GenerateContractorAuthWithKDF と VerifyPassword が受け付ける。
+// 関連DD: DD-CLI-005
+func ResolveKDFParams(name string, iterations int) (KDFParams, error) {
+	if name == "" {
+		name = kdfName
+	}
+	spec, ok := kdfSpecs[name]
+	if !ok {
+		return KDFParams{}, fmt.Errorf("%w: kdf %q (supported: %v)", ErrUnsupportedKDF, name, SupportedKDFs())
+	}
+	if iterations == 0 {
+		iterations = spec.defaultIterations
+	}
+	if iterations < spec.minIterations || iterations > spec.maxIterations {
+		return KDFParams{}, fmt.Errorf("%w: %s iterations must be between %d and %d", ErrUnsupportedKDF, name, spec.minIterations, spec.maxIterations)
+	}
+	return KDFParams{Name: name, Iterations: iterations}, nil
+}
+
+// UpgradeKDFParams は DD-CLI-005 の保存済みの鍵導出設定を、新規生成時の許容範囲に収まるよう補正する。
+// 方式は維持し、反復回数が現在の生成時の下限を下回る場合はその方式の既定値へ引き上げる。
+// パスワード変更時に、既定値の引き上げ前に作成した contractor.json を現在の強度へ移行するために用いる。
+func UpgradeKDFParams(params KDFParams) KDFParams {
+	spec, ok := kdfSpecs[params.Name]
+	if !ok || params.Iterations >= spec.minIterations {
+		return params
+	}
+	return KDFParams{Name: params.Name, Iterations: spec.defaultIterations}
+}
+
 // ContractorAuth は DD-CLI-005 の contractor.json フォーマットを表す。
+// totp_nonce_b64・totp_ciphertext_b64 は DD-CLI-008 の TOTP を有効にした場合のみ持つ。
 type ContractorAuth struct {
-	FormatVersion int    `json:"format_version"`
-	KDF           string `json:"kdf"`
-	KDFIterations int    `json:"kdf_iterations"`
-	SaltB64       string `json:"salt_b64"`
-	NonceB64      string `json:"nonce_b64"`
-	CiphertextB64 string `json:"ciphertext_b64"`
-	Mode          string `json:"mode"`
+	FormatVersion     int    `json:"format_version"`
+	KDF               string `json:"kdf"`
+	KDFIterations     int    `json:"kdf_iterations"`
+	SaltB64           string `json:"salt_b64"`
+	NonceB64          string `json:"nonce_b64"`
+	CiphertextB64     string `json:"ciphertext_b64"`
+	TOTPNonceB64      string `json:"totp_nonce_b64,omitempty"`
+	TOTPCiphertextB64 string `json:"totp_ciphertext_b64,omitempty"`
+	Mode              string `json:"mode"`
 }
 
-// GenerateContractorAuth は DD-CLI-005 の方式で contractor.json を生成する。
+// GenerateContractorAuth は DD-CLI-005 の既定の鍵導出設定で contractor.json を生成する。
 func GenerateContractorAuth(password string) (ContractorAuth, error) {
+	return GenerateContractorAuthWithKDF(password, DefaultKDFParams())
+}
+
+// GenerateContractorAuthWithKDF は DD-CLI-005 の方式で、指定した鍵導出設定の contractor.json を生成する。
+// 設定は ResolveKDFParams と同じ規則で検証・補完する。
+func GenerateContractorAuthWithKDF(password string, requested KDFParams) (ContractorAuth, error) {
 	if password == "" {
 		return ContractorAuth{}, errors.New("password is required")
 	}
+	params, err := ResolveKDFParams(requested.Name, requested.Iterations)
+	if err != nil {
+		return ContractorAuth{}, err
+	}
 
 	salt := make([]byte, saltSizeBytes)
 	if _, err := io.ReadFull(randReader, salt); err != nil {
@@ -62,7 +157,7 @@ func GenerateContractorAuth(password string) (ContractorAuth, error) {
 		return ContractorAuth{}, fmt.Errorf("nonce read: %w", err)
 	}
 
-	key := deriveKey(password, salt)
+	key := deriveKey(password, salt, params)
 	ciphertext, err := encryptFixed(key, nonce)
 	if err != nil {
 		return ContractorAuth{}, err
@@ -70,8 +165,8 @@ func GenerateContractorAuth(password string) (ContractorAuth, error) {
 
 	return ContractorAuth{
 		FormatVersion: formatVersion,
-		KDF:           kdfName,
-		KDFIterations: kdfIterations,
+		KDF:           params.Name,
+		KDFIterations: params.Iterations,
 		SaltB64:       base64.StdEncoding.EncodeToString(salt),
 		NonceB64:      base64.StdEncoding.EncodeToString(nonce),
 		CiphertextB64: base64.StdEncoding.EncodeToString(ciphertext),
@@ -86,41 +181,77 @@ func GenerateContractorAuth(password string) (ContractorAuth, error) {
 // エラー: 設定不一致や復号失敗時に返す。
 // 副作用: なし。
 // 並行性: スレッドセーフ。
-// 不変条件: 未対応KDFは一致判定を行わない。
+// 不変条件: 未対応KDFや検証時の許容範囲外の反復回数は一致判定を行わない。鍵は保存された設定で導出し、
+// 現在の既定値と異なる反復回数でも検証時の下限以上であれば受け付ける。
 // 関連DD: DD-CLI-005
 func VerifyPassword(auth ContractorAuth, password string) (bool, error) {
-	if auth.KDF != kdfName || auth.KDFIterations != kdfIterations {
-		return false, ErrUnsupportedKDF
+	if _, err := DeriveUnlockKey(auth, password); err != nil {
+		return false, err
 	}
+	return true, nil
+}
 
+// DeriveUnlockKey は DD-CLI-008 のパスワードを検証し、一致した場合は認証情報を復号できる導出鍵を返す。
+// 導出鍵はパスワードと同等に扱い、TOTP の秘密鍵の暗号化・復号にのみ用いて保存しない。
+func DeriveUnlockKey(auth ContractorAuth, password string) ([]byte, error) {
+	params := KDFParams{Name: auth.KDF, Iterations: auth.KDFIterations}
+	if err := checkStoredKDFParams(params); err != nil {
+		return nil, err
+	}
+	salt, nonce, ciphertext, err := decodeAuth(auth)
+	if err != nil {
+		return nil, err
+	}
+	key := deriveKey(password, salt, params)
+	if err := checkFixed(key, nonce, ciphertext); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// decodeAuth は DD-CLI-005 の認証情報の salt・nonce・暗号文を復号前のバイト列に戻す。
+func decodeAuth(auth ContractorAuth) ([]byte, []byte, []byte, error) {
 	salt, err := base64.StdEncoding.DecodeString(auth.SaltB64)
 	if err != nil {
-		return false, fmt.Errorf("decode salt: %w", err)
+		return nil, nil, nil, fmt.Errorf("decode salt: %w", err)
 	}
 	nonce, err := base64.StdEncoding.DecodeString(auth.NonceB64)
 	if err != nil {
-		return false, fmt.Errorf("decode nonce: %w", err)
+		return nil, nil, nil, fmt.Errorf("decode nonce: %w", err)
 	}
 	ciphertext, err := base64.StdEncoding.DecodeString(auth.CiphertextB64)
 	if err != nil {
-		return false, fmt.Errorf("decode ciphertext: %w", err)
+		return nil, nil, nil, fmt.Errorf("decode ciphertext: %w", err)
 	}
+	return salt, nonce, ciphertext, nil
+}
 
-	key := deriveKey(password, salt)
+// checkFixed は DD-CLI-005 の暗号文が key で固定平文に復号できるかを確認し、できなければ ErrPasswordMismatch を返す。
+func checkFixed(key, nonce, ciphertext []byte) error {
 	plaintext, err := decryptFixed(key, nonce, ciphertext)
-	if err != nil {
-		return false, ErrPasswordMismatch
+	if err != nil || string(plaintext) != fixedPlaintext {
+		return ErrPasswordMismatch
 	}
+	return nil
+}
 
-	if string(plaintext) != fixedPlaintext {
-		return false, ErrPasswordMismatch
+// checkStoredKDFParams は DD-CLI-005 の保存済みの鍵導出設定が検証時の許容範囲にあるかを確認する。
+// 空や 0 は既定値で補わず、保存内容の不備として扱う。
+func checkStoredKDFParams(params KDFParams) error {
+	spec, ok := kdfSpecs[params.Name]
+	if !ok || params.Iterations < spec.verifyMinIterations || params.Iterations > spec.maxIterations {
+		return ErrUnsupportedKDF
 	}
-	return true, nil
+	return nil
 }
 
-// deriveKey は DD-CLI-005 の PBKDF2-HMAC-SHA256 で鍵を導出する。
-func deriveKey(password string, salt []byte) []byte {
-	return pbkdf2.Key([]byte(password), salt, kdfIterations, derivedKeyLength, sha256.New)
+// deriveKey は DD-CLI-005 の指定された鍵導出方式 (検証済み) で鍵を導出する。
+func deriveKey(password string, salt []byte, params KDFParams) []byte {
+	if params.Name == KDFArgon2id {
+		// #nosec G115 -- 反復回数は ResolveKDFParams または checkStoredKDFParams で上限を検証済み。
+		return argon2.IDKey([]byte(password), salt, uint32(params.Iterations), argon2MemoryKiB, argon2Threads, derivedKeyLength)
+	}
+	return pbkdf2.Key([]byte(password), salt, params.Iterations, derivedKeyLength, sha256.New)
 }
 
 // encryptFixed は DD-CLI-005 の固定平文を AES-256-GCM で暗号化する。
@@ -120,3 +120,166 @@ func TestVerifyPassword_DecodeError(t *testing.T) {
 		t.Fatal("expected decode error")
 	}
 }
+
+func TestGenerateContractorAuthWithKDF_StoresParamsAndVerifies(t *testing.T) {
+	// 指定した鍵導出設定が保存され、保存された設定で検証できることを確認する。
+	cases := []KDFParams{
+		{Name: KDFPBKDF2SHA256, Iterations: kdfIterations + 1},
+		{Name: KDFArgon2id, Iterations: 2},
+	}
+	for _, params := range cases {
+		t.Run(params.Name, func(t *testing.T) {
+			auth, err := GenerateContractorAuthWithKDF("secret", params)
+			if err != nil {
+				t.Fatalf("GenerateContractorAuthWithKDF error: %v", err)
+			}
+			if auth.KDF != params.Name || auth.KDFIterations != params.Iterations {
+				t.Fatalf("unexpected kdf settings: %s %d", auth.KDF, auth.KDFIterations)
+			}
+			if ok, verifyErr := VerifyPassword(auth, "secret"); verifyErr != nil || !ok {
+				t.Fatalf("expected password to verify, ok=%v err=%v", ok, verifyErr)
+			}
+			if _, verifyErr := VerifyPassword(auth, "wrong"); !errors.Is(verifyErr, ErrPasswordMismatch) {
+				t.Fatalf("expected password mismatch, got: %v", verifyErr)
+			}
+		})
+	}
+}
+
+func TestResolveKDFParams(t *testing.T) {
+	// 既定値の補完と、未対応の方式・範囲外の反復回数の拒否を確認する。
+	defaults, err := ResolveKDFParams("", 0)
+	if err != nil || defaults != DefaultKDFParams() {
+		t.Fatalf("unexpected defaults: %+v err=%v", defaults, err)
+	}
+	argon, err := ResolveKDFParams(KDFArgon2id, 0)
+	if err != nil || argon.Iterations != 3 {
+		t.Fatalf("unexpected argon2id defaults: %+v err=%v", argon, err)
+	}
+	invalid := []KDFParams{
+		{Name: "scrypt", Iterations: 0},
+		{Name: KDFPBKDF2SHA256, Iterations: kdfIterations - 1},
+		{Name: KDFPBKDF2SHA256, Iterations: -1},
+		{Name: KDFArgon2id, Iterations: 101},
+	}
+	for _, params := range invalid {
+		if _, resolveErr := ResolveKDFParams(params.Name, params.Iterations); !errors.Is(resolveErr, ErrUnsupportedKDF) {
+			t.Fatalf("expected unsupported kdf error for %+v, got: %v", params, resolveErr)
+		}
+	}
+}
+
+func TestVerifyPassword_RejectsOutOfRangeIterations(t *testing.T) {
+	// 保存された反復回数が許容範囲外や 0 の場合に検証しないことを確認する。
+	for _, iterations := range []int{0, 1000, 10000001} {
+		auth := ContractorAuth{
+			KDF:           kdfName,
+			KDFIterations: iterations,
+			SaltB64:       "AA==",
+			NonceB64:      "AA==",
+			CiphertextB64: "AA==",
+		}
+		if _, err := VerifyPassword(auth, "secret"); !errors.Is(err, ErrUnsupportedKDF) {
+			t.Fatalf("expected unsupported kdf error for %d, got: %v", iterations, err)
+		}
+	}
+}
+
+func TestVerifyPassword_DispatchesOnStoredKDF(t *testing.T) {
+	// 検証時の鍵導出が保存された kdf に従い、方式を書き換えた認証情報では一致しないことを確認する。
+	auth, err := GenerateContractorAuthWithKDF("secret", KDFParams{Name: KDFArgon2id, Iterations: 2})
+	if err != nil {
+		t.Fatalf("GenerateContractorAuthWithKDF error: %v", err)
+	}
+	relabeled := auth
+	relabeled.KDF = KDFPBKDF2SHA256
+	relabeled.KDFIterations = kdfIterations
+	if _, verifyErr := VerifyPassword(relabeled, "secret"); !errors.Is(verifyErr, ErrPasswordMismatch) {
+		t.Fatalf("expected password mismatch for relabeled kdf, got: %v", verifyErr)
+	}
+	if ok, verifyErr := VerifyPassword(auth, "secret"); verifyErr != nil || !ok {
+		t.Fatalf("expected original auth to verify, ok=%v err=%v", ok, verifyErr)
+	}
+}
+
+// raisePBKDF2Default はテスト用に PBKDF2 の既定値と生成時の下限を引き上げ、テスト終了時に元へ戻す。
+func raisePBKDF2Default(t *testing.T, iterations int) {
+	t.Helper()
+	previous := kdfSpecs[KDFPBKDF2SHA256]
+	raised := previous
+	raised.defaultIterations = iterations
+	raised.minIterations = iterations
+	kdfSpecs[KDFPBKDF2SHA256] = raised
+	t.Cleanup(func() { kdfSpecs[KDFPBKDF2SHA256] = previous })
+}
+
+func TestVerifyPassword_AcceptsOlderIterationsAfterDefaultRaised(t *testing.T) {
+	// 既定値を引き上げた後も、以前の既定値で作成した認証情報を保存された反復回数で検証できることを確認する。
+	old, err := GenerateContractorAuth("secret")
+	if err != nil {
+		t.Fatalf("GenerateContractorAuth error: %v", err)
+	}
+	raisePBKDF2Default(t, kdfIterations*2)
+
+	if ok, verifyErr := VerifyPassword(old, "secret"); verifyErr != nil || !ok {
+		t.Fatalf("expected older auth to verify, ok=%v err=%v", ok, verifyErr)
+	}
+	if _, genErr := GenerateContractorAuthWithKDF("secret", KDFParams{Name: KDFPBKDF2SHA256, Iterations: kdfIterations}); !errors.Is(genErr, ErrUnsupportedKDF) {
+		t.Fatalf("expected new auth below the raised minimum to be rejected, got: %v", genErr)
+	}
+	below := old
+	below.KDFIterations = pbkdf2VerifyFloor - 1
+	if _, verifyErr := VerifyPassword(below, "secret"); !errors.Is(verifyErr, ErrUnsupportedKDF) {
+		t.Fatalf("expected iterations below the verify floor to be rejected, got: %v", verifyErr)
+	}
+}
+
+func TestUpgradeKDFParams(t *testing.T) {
+	// 生成時の下限を下回る反復回数のみ既定値へ引き上げ、方式と十分な反復回数は維持することを確認する。
+	raisePBKDF2Default(t, kdfIterations*2)
+	cases := []struct {
+		in   KDFParams
+		want KDFParams
+	}{
+		{KDFParams{Name: KDFPBKDF2SHA256, Iterations: kdfIterations}, KDFParams{Name: KDFPBKDF2SHA256, Iterations: kdfIterations * 2}},
+		{KDFParams{Name: KDFPBKDF2SHA256, Iterations: kdfIterations * 3}, KDFParams{Name: KDFPBKDF2SHA256, Iterations: kdfIterations * 3}},
+		{KDFParams{Name: KDFArgon2id, Iterations: 5}, KDFParams{Name: KDFArgon2id, Iterations: 5}},
+	}
+	for _, tc := range cases {
+		if got := UpgradeKDFParams(tc.in); got != tc.want {
+			t.Fatalf("UpgradeKDFParams(%+v) = %+v, want %+v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestDeriveUnlockKey_ChangesWithSalt(t *testing.T) {
+	// 不一致のパスワードでは導出鍵を返さず、同じパスワードでも salt が変わると異なる導出鍵となることを確認する。
+	previousReader := randReader
+	randReader = bytes.NewReader(bytes.Repeat([]byte{0x03}, 2*(saltSizeBytes+nonceSizeBytes)))
+	t.Cleanup(func() { randReader = previousReader })
+
+	auth, err := GenerateContractorAuth("secret")
+	if err != nil {
+		t.Fatalf("GenerateContractorAuth error: %v", err)
+	}
+	if _, wrongErr := DeriveUnlockKey(auth, "wrong"); !errors.Is(wrongErr, ErrPasswordMismatch) {
+		t.Fatalf("expected mismatch, got %v", wrongErr)
+	}
+	key, err := DeriveUnlockKey(auth, "secret")
+	if err != nil {
+		t.Fatalf("DeriveUnlockKey error: %v", err)
+	}
+
+	randReader = bytes.NewReader(bytes.Repeat([]byte{0x04}, saltSizeBytes+nonceSizeBytes))
+	changed, err := GenerateContractorAuth("secret")
+	if err != nil {
+		t.Fatalf("GenerateContractorAuth error: %v", err)
+	}
+	changedKey, err := DeriveUnlockKey(changed, "secret")
+	if err != nil {
+		t.Fatalf("DeriveUnlockKey error: %v", err)
+	}
+	if bytes.Equal(key, changedKey) {
+		t.Fatal("expected derived key to change with salt")
+	}
+}
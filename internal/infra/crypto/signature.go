@@ -0,0 +1,85 @@
+// signature.go は共有パスフレーズによるデータの署名と検証を担い、署名対象の組み立てやファイルI/Oは扱わない。
+// 鍵は contractor.json と同じ鍵導出方式 (DD-CLI-005) でパスフレーズから導出し、HMAC-SHA256 で署名する。
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrSignatureMismatch は DD-PATCH-002 の署名がデータと一致しない (改ざん・パスフレーズ違い) ことを示す。
+var ErrSignatureMismatch = errors.New("signature mismatch")
+
+// Signature は DD-PATCH-001 の共有パスフレーズによる署名を表す。
+type Signature struct {
+	Algorithm     string `json:"algorithm"`
+	KDF           string `json:"kdf"`
+	KDFIterations int    `json:"kdf_iterations"`
+	SaltB64       string `json:"salt_b64"`
+	MACB64        string `json:"mac_b64"`
+}
+
+// signatureAlgorithm は DD-PATCH-001 の署名方式を表す。
+const signatureAlgorithm = "hmac-sha256"
+
+// Sign は DD-PATCH-001 の共有パスフレーズでデータに署名する。
+// 目的: 共有フォルダを介さずに受け渡すファイルが、パスフレーズを知る相手から改ざんされずに届いたことを確かめられるようにする。
+// 入力: passphrase は送り手と受け手で共有するパスフレーズ、data は署名対象。
+// 出力: Signature とエラー。
+// エラー: パスフレーズが空の場合、乱数の取得に失敗した場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 鍵は署名ごとに新しい salt と既定の鍵導出設定で導出する。
+// 関連DD: DD-PATCH-001, DD-CLI-005
+func Sign(passphrase string, data []byte) (Signature, error) {
+	if passphrase == "" {
+		return Signature{}, errors.New("passphrase is required")
+	}
+	salt := make([]byte, saltSizeBytes)
+	if _, err := io.ReadFull(randReader, salt); err != nil {
+		return Signature{}, fmt.Errorf("salt read: %w", err)
+	}
+	params := DefaultKDFParams()
+	return Signature{
+		Algorithm:     signatureAlgorithm,
+		KDF:           params.Name,
+		KDFIterations: params.Iterations,
+		SaltB64:       base64.StdEncoding.EncodeToString(salt),
+		MACB64:        base64.StdEncoding.EncodeToString(mac(deriveKey(passphrase, salt, params), data)),
+	}, nil
+}
+
+// Verify は DD-PATCH-002 の共有パスフレーズでデータの署名を検証する。
+// 一致しない場合は ErrSignatureMismatch、署名方式や鍵導出設定が未対応の場合は ErrUnsupportedKDF を返す。
+func Verify(signature Signature, passphrase string, data []byte) error {
+	if signature.Algorithm != signatureAlgorithm {
+		return ErrUnsupportedKDF
+	}
+	params := KDFParams{Name: signature.KDF, Iterations: signature.KDFIterations}
+	if err := checkStoredKDFParams(params); err != nil {
+		return err
+	}
+	salt, err := base64.StdEncoding.DecodeString(signature.SaltB64)
+	if err != nil {
+		return fmt.Errorf("decode salt: %w", err)
+	}
+	expected, err := base64.StdEncoding.DecodeString(signature.MACB64)
+	if err != nil {
+		return fmt.Errorf("decode mac: %w", err)
+	}
+	if !hmac.Equal(mac(deriveKey(passphrase, salt, params), data), expected) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+// mac は DD-PATCH-001 の HMAC-SHA256 を計算する。
+func mac(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
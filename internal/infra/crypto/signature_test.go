@@ -0,0 +1,47 @@
+package crypto
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	// 同じパスフレーズ・同じデータでのみ検証できることを確認する。
+	signature, err := Sign("shared", []byte("payload"))
+	if err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+	if signature.Algorithm != signatureAlgorithm || signature.KDF != kdfName || signature.KDFIterations != kdfIterations {
+		t.Fatalf("unexpected signature: %+v", signature)
+	}
+	if err := Verify(signature, "shared", []byte("payload")); err != nil {
+		t.Fatalf("Verify error: %v", err)
+	}
+	if err := Verify(signature, "other", []byte("payload")); !errors.Is(err, ErrSignatureMismatch) {
+		t.Fatalf("expected mismatch for wrong passphrase, got %v", err)
+	}
+	if err := Verify(signature, "shared", []byte("tampered")); !errors.Is(err, ErrSignatureMismatch) {
+		t.Fatalf("expected mismatch for tampered data, got %v", err)
+	}
+}
+
+func TestVerify_RejectsWeakOrUnknownSettings(t *testing.T) {
+	// 未対応の署名方式や下限を下回る反復回数の署名は一致判定を行わないことを確認する。
+	signature, err := Sign("shared", []byte("payload"))
+	if err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+	weak := signature
+	weak.KDFIterations = 1
+	if err := Verify(weak, "shared", []byte("payload")); !errors.Is(err, ErrUnsupportedKDF) {
+		t.Fatalf("expected ErrUnsupportedKDF, got %v", err)
+	}
+	unknown := signature
+	unknown.Algorithm = "none"
+	if err := Verify(unknown, "shared", []byte("payload")); !errors.Is(err, ErrUnsupportedKDF) {
+		t.Fatalf("expected ErrUnsupportedKDF, got %v", err)
+	}
+	if _, err := Sign("", []byte("payload")); err == nil {
+		t.Fatal("expected error for empty passphrase")
+	}
+}
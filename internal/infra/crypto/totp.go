@@ -0,0 +1,167 @@
+// totp.go は contractor.json に埋め込む TOTP (RFC 6238) の秘密鍵の暗号化とワンタイムコードの検証を担い、
+// 認証アプリへの登録手順や入力 UI は扱わない。秘密鍵はパスワードからの導出鍵で暗号化して保存する。
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha1" // #nosec G505 -- RFC 6238 の既定かつ認証アプリが広く対応する HMAC-SHA1 を用いる。
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+)
+
+const (
+	// totpSecretBytes は DD-CLI-008 の TOTP 秘密鍵の長さ (RFC 4226 の推奨 160 bit) を表す。
+	totpSecretBytes = 20
+	// totpDigits は DD-CLI-008 のワンタイムコードの桁数を表す。
+	totpDigits = 6
+	// totpPeriod は DD-CLI-008 のワンタイムコードの有効間隔を表す。
+	totpPeriod = 30 * time.Second
+	// totpSkew は DD-CLI-008 の時計のずれを許容する前後の間隔数を表す。
+	totpSkew = 1
+)
+
+// totpEncoding は DD-CLI-008 の認証アプリへ登録する秘密鍵の表記 (パディングなしの Base32) を表す。
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// HasTOTP は DD-CLI-008 の認証情報に TOTP の秘密鍵が埋め込まれているかを返す。
+func (a ContractorAuth) HasTOTP() bool {
+	return a.TOTPCiphertextB64 != ""
+}
+
+// GenerateTOTPSecret は DD-CLI-008 の TOTP 秘密鍵を乱数から生成する。
+func GenerateTOTPSecret() ([]byte, error) {
+	secret := make([]byte, totpSecretBytes)
+	if _, err := io.ReadFull(randReader, secret); err != nil {
+		return nil, fmt.Errorf("totp secret read: %w", err)
+	}
+	return secret, nil
+}
+
+// AttachTOTPSecret は DD-CLI-008 に従い、TOTP の秘密鍵をパスワードからの導出鍵で暗号化して認証情報に埋め込む。
+// 目的: パスワードを知る者だけが秘密鍵を取り出せる形で contractor.json に保存する。
+// 入力: auth は生成済みの認証情報、password はその平文パスワード、secret は TOTP の秘密鍵。
+// 出力: 秘密鍵を埋め込んだ認証情報とエラー。
+// エラー: パスワードが一致しない場合、乱数の取得や暗号化に失敗した場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 固定平文とは別の nonce で暗号化する。元の認証情報の他の項目は変更しない。
+// 関連DD: DD-CLI-008, DD-CLI-005
+func AttachTOTPSecret(auth ContractorAuth, password string, secret []byte) (ContractorAuth, error) {
+	if len(secret) == 0 {
+		return ContractorAuth{}, errors.New("totp secret is required")
+	}
+	key, err := DeriveUnlockKey(auth, password)
+	if err != nil {
+		return ContractorAuth{}, err
+	}
+	nonce := make([]byte, nonceSizeBytes)
+	if _, readErr := io.ReadFull(randReader, nonce); readErr != nil {
+		return ContractorAuth{}, fmt.Errorf("totp nonce read: %w", readErr)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return ContractorAuth{}, err
+	}
+	auth.TOTPNonceB64 = base64.StdEncoding.EncodeToString(nonce)
+	auth.TOTPCiphertextB64 = base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, secret, nil))
+	return auth, nil
+}
+
+// OpenTOTPSecret は DD-CLI-008 の埋め込まれた TOTP の秘密鍵を、DeriveUnlockKey で得た導出鍵で復号する。
+func OpenTOTPSecret(auth ContractorAuth, key []byte) ([]byte, error) {
+	if !auth.HasTOTP() {
+		return nil, errors.New("totp is not configured")
+	}
+	nonce, err := base64.StdEncoding.DecodeString(auth.TOTPNonceB64)
+	if err != nil {
+		return nil, fmt.Errorf("decode totp nonce: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(auth.TOTPCiphertextB64)
+	if err != nil {
+		return nil, fmt.Errorf("decode totp ciphertext: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt totp secret: %w", err)
+	}
+	return secret, nil
+}
+
+// TOTPCode は DD-CLI-008 の時刻 at におけるワンタイムコードを返す。
+func TOTPCode(secret []byte, at time.Time) string {
+	return hotp(secret, totpCounter(at))
+}
+
+// VerifyTOTPCode は DD-CLI-008 のワンタイムコードが時刻 now の前後 totpSkew 間隔のいずれかと一致するかを返す。
+func VerifyTOTPCode(secret []byte, code string, now time.Time) bool {
+	if len(code) != totpDigits {
+		return false
+	}
+	counter := totpCounter(now)
+	matched := 0
+	for offset := -totpSkew; offset <= totpSkew; offset++ {
+		// #nosec G115 -- 現在時刻から求めたカウンタに ±1 しても符号は変わらない。
+		candidate := hotp(secret, uint64(int64(counter)+int64(offset)))
+		matched |= subtle.ConstantTimeCompare([]byte(candidate), []byte(code))
+	}
+	return matched == 1
+}
+
+// EncodeTOTPSecret は DD-CLI-008 の秘密鍵を認証アプリへ手入力できる Base32 表記で返す。
+func EncodeTOTPSecret(secret []byte) string {
+	return totpEncoding.EncodeToString(secret)
+}
+
+// TOTPKeyURI は DD-CLI-008 の認証アプリへ登録する otpauth URI を返す。
+func TOTPKeyURI(issuer, account string, secret []byte) string {
+	query := url.Values{}
+	query.Set("secret", EncodeTOTPSecret(secret))
+	query.Set("issuer", issuer)
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(int(totpPeriod/time.Second)))
+	return "otpauth://totp/" + url.PathEscape(issuer+":"+account) + "?" + query.Encode()
+}
+
+// totpCounter は DD-CLI-008 の時刻から RFC 6238 のカウンタを求める。
+func totpCounter(at time.Time) uint64 {
+	// #nosec G115 -- Unix 時刻は 1970 年以降のため負にならない。
+	return uint64(at.Unix() / int64(totpPeriod/time.Second))
+}
+
+// hotp は DD-CLI-008 の RFC 4226 の HMAC-SHA1 によるワンタイムコードを返す。
+func hotp(secret []byte, counter uint64) string {
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], counter)
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(message[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// newGCM は DD-CLI-005 の導出鍵から AES-256-GCM を生成する。
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("aes cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, nonceSizeBytes)
+	if err != nil {
+		return nil, fmt.Errorf("gcm: %w", err)
+	}
+	return gcm, nil
+}
@@ -0,0 +1,95 @@
+// totp_test.go は TOTP の秘密鍵の暗号化とワンタイムコードの検証のテストを行う。
+package crypto
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	// RFC 6238 の SHA1 のテストベクタの下位 6 桁と一致することを確認する。
+	secret := []byte("12345678901234567890")
+	cases := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, expected := range cases {
+		if got := TOTPCode(secret, time.Unix(unix, 0)); got != expected {
+			t.Fatalf("TOTPCode(%d) = %s, expected %s", unix, got, expected)
+		}
+	}
+}
+
+func TestVerifyTOTPCode_AllowsOneStepOfSkew(t *testing.T) {
+	// 前後1間隔のコードは受け付け、それより離れたコードや桁数の異なるコードは拒否することを確認する。
+	secret := []byte("12345678901234567890")
+	now := time.Unix(1111111109, 0)
+	if !VerifyTOTPCode(secret, TOTPCode(secret, now.Add(-totpPeriod)), now) {
+		t.Fatal("expected previous code to be accepted")
+	}
+	if !VerifyTOTPCode(secret, TOTPCode(secret, now.Add(totpPeriod)), now) {
+		t.Fatal("expected next code to be accepted")
+	}
+	if VerifyTOTPCode(secret, TOTPCode(secret, now.Add(-3*totpPeriod)), now) {
+		t.Fatal("expected old code to be rejected")
+	}
+	if VerifyTOTPCode(secret, "81804", now) {
+		t.Fatal("expected short code to be rejected")
+	}
+}
+
+func TestAttachTOTPSecret_OpensWithDerivedKey(t *testing.T) {
+	// 秘密鍵はパスワードからの導出鍵で暗号化して埋め込み、同じ導出鍵でのみ取り出せることを確認する。
+	previousReader := randReader
+	randReader = bytes.NewReader(bytes.Repeat([]byte{0x05}, 2*(saltSizeBytes+nonceSizeBytes)+totpSecretBytes))
+	t.Cleanup(func() { randReader = previousReader })
+
+	auth, err := GenerateContractorAuthWithKDF("secret", KDFParams{Name: KDFArgon2id, Iterations: 2})
+	if err != nil {
+		t.Fatalf("GenerateContractorAuthWithKDF error: %v", err)
+	}
+	totpSecret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret error: %v", err)
+	}
+	if _, attachErr := AttachTOTPSecret(auth, "wrong", totpSecret); attachErr == nil {
+		t.Fatal("expected wrong password to be rejected")
+	}
+	withTOTP, err := AttachTOTPSecret(auth, "secret", totpSecret)
+	if err != nil {
+		t.Fatalf("AttachTOTPSecret error: %v", err)
+	}
+	if !withTOTP.HasTOTP() || auth.HasTOTP() {
+		t.Fatal("expected only the returned auth to carry the totp secret")
+	}
+	if strings.Contains(withTOTP.TOTPCiphertextB64, EncodeTOTPSecret(totpSecret)) {
+		t.Fatal("expected totp secret to be encrypted")
+	}
+
+	key, err := DeriveUnlockKey(withTOTP, "secret")
+	if err != nil {
+		t.Fatalf("DeriveUnlockKey error: %v", err)
+	}
+	opened, err := OpenTOTPSecret(withTOTP, key)
+	if err != nil || !bytes.Equal(opened, totpSecret) {
+		t.Fatalf("expected totp secret to open, got %x err=%v", opened, err)
+	}
+	if _, openErr := OpenTOTPSecret(withTOTP, bytes.Repeat([]byte{0x01}, derivedKeyLength)); openErr == nil {
+		t.Fatal("expected other key to fail")
+	}
+}
+
+func TestTOTPKeyURI_ContainsSecretAndIssuer(t *testing.T) {
+	// 認証アプリへ登録する URI に Base32 の秘密鍵と発行者名を含むことを確認する。
+	uri := TOTPKeyURI("ratta", "contractor", []byte("12345678901234567890"))
+	if !strings.HasPrefix(uri, "otpauth://totp/ratta:contractor?") {
+		t.Fatalf("unexpected uri: %s", uri)
+	}
+	if !strings.Contains(uri, "secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ") || !strings.Contains(uri, "issuer=ratta") {
+		t.Fatalf("unexpected uri: %s", uri)
+	}
+}
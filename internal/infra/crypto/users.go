@@ -0,0 +1,148 @@
+// users.go は複数の Contractor アカウントを保持する users.json の形式と照合を担い、ファイルI/Oは扱わない。
+// 各アカウントの認証情報は contractor.json と同じ方式 (DD-CLI-005) で保護する。
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// usersFormatVersion は DD-CLI-007 の users.json の形式バージョンを表す。
+const usersFormatVersion = 1
+
+// maxUsernameLength は DD-CLI-007 のユーザー名の最大文字数を表す。コメントの作成者名の上限に収まる長さとする。
+const maxUsernameLength = 64
+
+// ErrUnknownUser は DD-CLI-007 の users.json に指定されたユーザーが存在しないことを示す。
+var ErrUnknownUser = errors.New("unknown user")
+
+// UserStore は DD-CLI-007 の users.json の形式を表す。
+type UserStore struct {
+	FormatVersion int           `json:"format_version"`
+	Users         []UserAccount `json:"users"`
+}
+
+// UserAccount は DD-CLI-007 の Contractor アカウント1件を表す。鍵導出設定はアカウントごとに保持する。
+// totp_nonce_b64・totp_ciphertext_b64 は DD-CLI-008 の TOTP をアカウントに設定した場合のみ持つ。
+type UserAccount struct {
+	Username          string `json:"username"`
+	KDF               string `json:"kdf"`
+	KDFIterations     int    `json:"kdf_iterations"`
+	SaltB64           string `json:"salt_b64"`
+	NonceB64          string `json:"nonce_b64"`
+	CiphertextB64     string `json:"ciphertext_b64"`
+	TOTPNonceB64      string `json:"totp_nonce_b64,omitempty"`
+	TOTPCiphertextB64 string `json:"totp_ciphertext_b64,omitempty"`
+}
+
+// NewUserStore は DD-CLI-007 のアカウントを持たない users.json を返す。
+func NewUserStore() UserStore {
+	return UserStore{FormatVersion: usersFormatVersion, Users: []UserAccount{}}
+}
+
+// NewUserAccount は DD-CLI-007 の生成済みの認証情報からアカウントを組み立てる。DD-CLI-008 の TOTP の秘密鍵も引き継ぐ。
+func NewUserAccount(username string, auth ContractorAuth) UserAccount {
+	return UserAccount{
+		Username:          username,
+		KDF:               auth.KDF,
+		KDFIterations:     auth.KDFIterations,
+		SaltB64:           auth.SaltB64,
+		NonceB64:          auth.NonceB64,
+		CiphertextB64:     auth.CiphertextB64,
+		TOTPNonceB64:      auth.TOTPNonceB64,
+		TOTPCiphertextB64: auth.TOTPCiphertextB64,
+	}
+}
+
+// Auth は DD-CLI-007 のアカウントの認証情報を VerifyPassword で検証できる形で返す。
+// TOTP の秘密鍵も含めるため、HasTOTP と OpenTOTPSecret は contractor.json と同じに扱える。
+func (u UserAccount) Auth() ContractorAuth {
+	return ContractorAuth{
+		FormatVersion:     formatVersion,
+		KDF:               u.KDF,
+		KDFIterations:     u.KDFIterations,
+		SaltB64:           u.SaltB64,
+		NonceB64:          u.NonceB64,
+		CiphertextB64:     u.CiphertextB64,
+		TOTPNonceB64:      u.TOTPNonceB64,
+		TOTPCiphertextB64: u.TOTPCiphertextB64,
+		Mode:              "contractor",
+	}
+}
+
+// HasTOTP は DD-CLI-008 の TOTP を設定したアカウントが1件でもあるかを返す。
+func (s UserStore) HasTOTP() bool {
+	for _, account := range s.Users {
+		if account.TOTPCiphertextB64 != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Find は DD-CLI-007 のユーザー名が一致するアカウントを返す。ユーザー名は大文字小文字を区別する。
+func (s UserStore) Find(username string) (UserAccount, bool) {
+	for _, account := range s.Users {
+		if account.Username == username {
+			return account, true
+		}
+	}
+	return UserAccount{}, false
+}
+
+// Put は DD-CLI-007 のアカウントを追加し、同名のアカウントがあれば置き換えた users.json を返す。
+// 追加したアカウントは末尾に置き、既存のアカウントの並びは変えない。
+func (s UserStore) Put(account UserAccount) UserStore {
+	users := make([]UserAccount, 0, len(s.Users)+1)
+	replaced := false
+	for _, existing := range s.Users {
+		if existing.Username == account.Username {
+			users = append(users, account)
+			replaced = true
+			continue
+		}
+		users = append(users, existing)
+	}
+	if !replaced {
+		users = append(users, account)
+	}
+	return UserStore{FormatVersion: usersFormatVersion, Users: users}
+}
+
+// VerifyUserPassword は DD-CLI-007 のユーザー名とパスワードを照合する。
+// 目的: users.json のアカウントの認証情報でパスワード一致を判定する。
+// 入力: store は users.json の内容、username はユーザー名、password は平文パスワード。
+// 出力: 一致時は true、未一致時は false とエラー。
+// エラー: ユーザーが存在しない場合は ErrUnknownUser、それ以外は VerifyPassword と同じエラーを返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 鍵はアカウントごとに保存された設定で導出する。
+// 関連DD: DD-CLI-007, DD-CLI-005
+func VerifyUserPassword(store UserStore, username, password string) (bool, error) {
+	account, ok := store.Find(username)
+	if !ok {
+		return false, ErrUnknownUser
+	}
+	return VerifyPassword(account.Auth(), password)
+}
+
+// ValidateUsername は DD-CLI-007 のユーザー名の規則 (1〜64文字、前後の空白・制御文字なし) を検証する。
+func ValidateUsername(username string) error {
+	if username == "" {
+		return errors.New("username is required")
+	}
+	if strings.TrimSpace(username) != username {
+		return errors.New("username must not start or end with whitespace")
+	}
+	if len([]rune(username)) > maxUsernameLength {
+		return fmt.Errorf("username must be at most %d characters", maxUsernameLength)
+	}
+	for _, r := range username {
+		if unicode.IsControl(r) {
+			return errors.New("username must not contain control characters")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// users_test.go は users.json のアカウント照合とユーザー名検証のテストを行う。
+package crypto
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVerifyUserPassword_UsesEachAccountsKDF(t *testing.T) {
+	// アカウントごとに保存された鍵導出設定で照合し、未登録のユーザーを区別して返すことを確認する。
+	aliceAuth, err := GenerateContractorAuth("alice-pw")
+	if err != nil {
+		t.Fatalf("GenerateContractorAuth error: %v", err)
+	}
+	bobAuth, err := GenerateContractorAuthWithKDF("bob-pw", KDFParams{Name: KDFArgon2id, Iterations: 2})
+	if err != nil {
+		t.Fatalf("GenerateContractorAuthWithKDF error: %v", err)
+	}
+	store := NewUserStore().Put(NewUserAccount("alice", aliceAuth)).Put(NewUserAccount("bob", bobAuth))
+
+	if ok, verifyErr := VerifyUserPassword(store, "alice", "alice-pw"); verifyErr != nil || !ok {
+		t.Fatalf("expected alice to verify, ok=%v err=%v", ok, verifyErr)
+	}
+	if ok, verifyErr := VerifyUserPassword(store, "bob", "bob-pw"); verifyErr != nil || !ok {
+		t.Fatalf("expected bob to verify, ok=%v err=%v", ok, verifyErr)
+	}
+	if _, verifyErr := VerifyUserPassword(store, "bob", "alice-pw"); !errors.Is(verifyErr, ErrPasswordMismatch) {
+		t.Fatalf("expected password mismatch, got: %v", verifyErr)
+	}
+	if _, verifyErr := VerifyUserPassword(store, "Alice", "alice-pw"); !errors.Is(verifyErr, ErrUnknownUser) {
+		t.Fatalf("expected unknown user, got: %v", verifyErr)
+	}
+}
+
+func TestUserStorePut_ReplacesSameUsername(t *testing.T) {
+	// 同名のアカウントは位置を保ったまま置き換え、新しいアカウントは末尾へ追加することを確認する。
+	store := NewUserStore().
+		Put(UserAccount{Username: "alice", SaltB64: "a1"}).
+		Put(UserAccount{Username: "bob", SaltB64: "b1"}).
+		Put(UserAccount{Username: "alice", SaltB64: "a2"})
+	if len(store.Users) != 2 || store.Users[0].Username != "alice" || store.Users[0].SaltB64 != "a2" || store.Users[1].Username != "bob" {
+		t.Fatalf("unexpected users: %+v", store.Users)
+	}
+}
+
+func TestValidateUsername(t *testing.T) {
+	// 空・前後の空白・制御文字・長すぎる名前を拒否することを確認する。
+	if err := ValidateUsername("山田 太郎"); err != nil {
+		t.Fatalf("expected valid username, got: %v", err)
+	}
+	for _, name := range []string{"", " alice", "alice ", "al\tice", strings.Repeat("a", maxUsernameLength+1)} {
+		if err := ValidateUsername(name); err == nil {
+			t.Fatalf("expected %q to be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,340 @@
+// Package fswatch はプロジェクトルート配下のカテゴリ・課題・添付の外部変更を検知して通知することを担い、UI への配信や一覧の再読込は扱わない。
+// fsnotify は再帰監視を持たないため、カテゴリと添付ディレクトリを個別に監視対象へ加える。
+package fswatch
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"ratta/internal/domain/issue"
+)
+
+// Kind は DD-WATCH-001 の変更対象の種別を表す。
+type Kind string
+
+// Op は DD-WATCH-001 の変更内容を表す。
+type Op string
+
+const (
+	KindCategory   Kind = "category"
+	KindIssue      Kind = "issue"
+	KindAttachment Kind = "attachment"
+
+	OpCreated  Op = "created"
+	OpModified Op = "modified"
+	OpDeleted  Op = "deleted"
+)
+
+const (
+	// attachmentDirExt は DD-DATA-005 の添付ディレクトリの拡張子を表す。
+	attachmentDirExt = ".files"
+	// defaultDebounce は DD-WATCH-001 の通知をまとめる待ち時間を表す。
+	// atomic write は一時ファイル作成と rename の複数イベントを生むため、短時間の変更を1回の通知へまとめる。
+	defaultDebounce = 300 * time.Millisecond
+)
+
+// Event は DD-WATCH-001 の変更通知1件を表す。IssueID は課題・添付の場合のみ設定する。
+type Event struct {
+	Kind     Kind
+	Op       Op
+	Category string
+	IssueID  string
+	Path     string
+}
+
+// EmitFunc は DD-WATCH-001 のまとめた変更通知を受け取る関数を表す。
+type EmitFunc func(events []Event)
+
+// Watcher は DD-WATCH-001 のプロジェクトルートの監視を表す。
+type Watcher struct {
+	root     string
+	notify   *fsnotify.Watcher
+	emit     EmitFunc
+	debounce time.Duration
+
+	emitMu  sync.Mutex
+	mu      sync.Mutex
+	known   map[string]struct{}
+	pending []Event
+	timer   *time.Timer
+	closed  bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Start は DD-WATCH-001 のプロジェクトルートの監視を開始する。
+// 目的: 他の利用者による共有フォルダ上の変更を検知し、まとめて emit へ通知する。
+// 入力: root はプロジェクトルートパス、emit は通知先。
+// 出力: Watcher とエラー。
+// エラー: 監視の初期化、ルートの読み取り・監視登録失敗時に返す。
+// 副作用: ルート・カテゴリ・添付ディレクトリを監視対象に登録し、監視ゴルーチンを起動する。
+// 並行性: emit は監視ゴルーチンから呼ばれる。Close まで同時に複数回呼ばれることはない。
+// 不変条件: ドット始まりの名前と一時ファイルは通知しない。
+// 関連DD: DD-WATCH-001, DD-LOAD-002
+func Start(root string, emit EmitFunc) (*Watcher, error) {
+	return start(root, emit, defaultDebounce)
+}
+
+// start は DD-WATCH-001 の待ち時間を指定して監視を開始する。
+func start(root string, emit EmitFunc, debounce time.Duration) (*Watcher, error) {
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("create watcher: %w", err)
+	}
+	w := &Watcher{
+		root:     filepath.Clean(root),
+		notify:   notify,
+		emit:     emit,
+		debounce: debounce,
+		known:    make(map[string]struct{}),
+		done:     make(chan struct{}),
+	}
+	if addErr := w.addTree(w.root); addErr != nil {
+		if closeErr := notify.Close(); closeErr != nil {
+			return nil, fmt.Errorf("start watcher failed: %w; close error: %s", addErr, closeErr.Error())
+		}
+		return nil, addErr
+	}
+	w.wg.Add(1)
+	go w.loop()
+	return w, nil
+}
+
+// Close は DD-WATCH-001 の監視を停止する。未通知の変更は破棄する。
+func (w *Watcher) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mu.Unlock()
+
+	close(w.done)
+	err := w.notify.Close()
+	w.wg.Wait()
+	if err != nil {
+		return fmt.Errorf("close watcher: %w", err)
+	}
+	return nil
+}
+
+// loop は DD-WATCH-001 の fsnotify イベントを受け取り分類する。
+func (w *Watcher) loop() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.done:
+			return
+		case raw, ok := <-w.notify.Events:
+			if !ok {
+				return
+			}
+			w.handle(raw)
+		case _, ok := <-w.notify.Errors:
+			// 監視エラー (イベント溢れ等) は通知の取りこぼしに留まり、次回の一覧取得の鮮度判定で補われる。
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// handle は DD-WATCH-001 の1イベントを分類し、必要に応じて監視対象を追加する。
+func (w *Watcher) handle(raw fsnotify.Event) {
+	path := filepath.Clean(raw.Name)
+	kind, category, issueID, ok := w.classify(path)
+	if !ok {
+		return
+	}
+
+	var op Op
+	switch {
+	case raw.Has(fsnotify.Remove) || raw.Has(fsnotify.Rename):
+		op = OpDeleted
+		w.forget(path)
+		if kind != KindIssue {
+			// 名前変更されたディレクトリの監視は移動先の inode に残り旧パスで通知されるため、明示的に解除する。
+			_ = w.notify.Remove(path)
+		}
+	case raw.Has(fsnotify.Create):
+		op = OpCreated
+		if w.remember(path) {
+			// atomic write による上書きは rename で Create として届くため、既知のパスは更新として扱う。
+			op = OpModified
+		}
+		if kind != KindIssue {
+			if info, err := os.Stat(path); err == nil && info.IsDir() {
+				// 監視開始前に作られた中身も拾うため、追加したディレクトリ配下を改めて登録する。
+				_ = w.addTree(path)
+			}
+		}
+	case raw.Has(fsnotify.Write):
+		op = OpModified
+		w.remember(path)
+	default:
+		return
+	}
+
+	// 添付ディレクトリ自体の作成・削除は、課題に対する添付の変更として通知する。
+	if kind == KindAttachment && category != "" && filepath.Dir(path) == filepath.Join(w.root, category) {
+		op = OpModified
+	}
+	w.enqueue(Event{Kind: kind, Op: op, Category: category, IssueID: issueID, Path: path})
+}
+
+// classify は DD-WATCH-001 のパスから変更対象の種別を判定する。
+// ルート直下のディレクトリはカテゴリ、カテゴリ直下の課題JSONは課題、
+// <issue_id>.files 配下とそのディレクトリ自体は添付として扱う。
+func (w *Watcher) classify(path string) (Kind, string, string, bool) {
+	rel, err := filepath.Rel(w.root, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", "", "", false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for _, part := range parts {
+		if strings.HasPrefix(part, ".") || strings.Contains(part, ".tmp.") {
+			return "", "", "", false
+		}
+	}
+	switch len(parts) {
+	case 1:
+		// ルート直下のファイルはカテゴリではないため通知しない。削除済みのパスは既知のカテゴリの場合のみ通知する。
+		info, statErr := os.Stat(path)
+		if statErr == nil && !info.IsDir() {
+			return "", "", "", false
+		}
+		if statErr != nil && !w.isKnown(path) {
+			return "", "", "", false
+		}
+		return KindCategory, parts[0], "", true
+	case 2:
+		if issue.IsIssueFileName(parts[1]) {
+			return KindIssue, parts[0], strings.TrimSuffix(parts[1], ".json"), true
+		}
+		if strings.HasSuffix(parts[1], attachmentDirExt) {
+			return KindAttachment, parts[0], strings.TrimSuffix(parts[1], attachmentDirExt), true
+		}
+	case 3:
+		if strings.HasSuffix(parts[1], attachmentDirExt) {
+			return KindAttachment, parts[0], strings.TrimSuffix(parts[1], attachmentDirExt), true
+		}
+	}
+	return "", "", "", false
+}
+
+// enqueue は DD-WATCH-001 の変更を待機列に加え、待ち時間経過後の通知を予約する。
+// 同じパスへの連続した変更は1件にまとめ、作成直後の更新は作成のまま、削除後の再作成は更新として扱う。
+func (w *Watcher) enqueue(event Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	merged := false
+	for i := range w.pending {
+		if w.pending[i].Path != event.Path || w.pending[i].Kind != event.Kind {
+			continue
+		}
+		w.pending[i].Op = mergeOp(w.pending[i].Op, event.Op)
+		merged = true
+		break
+	}
+	if !merged {
+		w.pending = append(w.pending, event)
+	}
+	if w.timer == nil {
+		w.timer = time.AfterFunc(w.debounce, w.flush)
+	}
+}
+
+// mergeOp は DD-WATCH-001 の同一パスに対する連続した変更を1つにまとめる。
+func mergeOp(previous, next Op) Op {
+	switch {
+	case previous == OpCreated && next == OpModified:
+		return OpCreated
+	case previous == OpDeleted && next != OpDeleted:
+		return OpModified
+	default:
+		return next
+	}
+}
+
+// flush は DD-WATCH-001 の待機中の変更をまとめて通知する。
+func (w *Watcher) flush() {
+	w.mu.Lock()
+	events := w.pending
+	w.pending = nil
+	w.timer = nil
+	closed := w.closed
+	w.mu.Unlock()
+	if closed || len(events) == 0 {
+		return
+	}
+	// 通知処理が長引いて次の通知予約が発火しても、emit が同時に呼ばれないよう直列化する。
+	w.emitMu.Lock()
+	defer w.emitMu.Unlock()
+	w.emit(events)
+}
+
+// addTree は DD-WATCH-001 のディレクトリとその配下のカテゴリ・添付ディレクトリを監視対象に加える。
+func (w *Watcher) addTree(dir string) error {
+	if err := w.notify.Add(dir); err != nil {
+		return fmt.Errorf("watch %s: %w", dir, err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("read dir: %w", err)
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if _, _, _, ok := w.classify(path); !ok {
+			continue
+		}
+		w.remember(path)
+		if !entry.IsDir() {
+			continue
+		}
+		// 監視中に削除されたディレクトリは登録できないが、削除通知は親の監視で届くため無視する。
+		_ = w.addTree(path)
+	}
+	return nil
+}
+
+// remember は DD-WATCH-001 の既知パスとして記録し、既に記録済みであれば true を返す。
+func (w *Watcher) remember(path string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, ok := w.known[path]
+	w.known[path] = struct{}{}
+	return ok
+}
+
+// isKnown は DD-WATCH-001 の既知パスかどうかを返す。
+func (w *Watcher) isKnown(path string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, ok := w.known[path]
+	return ok
+}
+
+// forget は DD-WATCH-001 の既知パスから取り除く。
+func (w *Watcher) forget(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.known, path)
+}
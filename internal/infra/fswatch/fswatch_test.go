@@ -0,0 +1,123 @@
+// fswatch_test.go は外部変更の検知と通知内容のテストを行い、UI への配信は扱わない。
+package fswatch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ratta/internal/infra/atomicwrite"
+)
+
+// startForTest は短い待ち時間で監視を開始し、通知をチャネルで受け取れるようにする。
+func startForTest(t *testing.T, root string) <-chan []Event {
+	t.Helper()
+	received := make(chan []Event, 16)
+	watcher, err := start(root, func(events []Event) { received <- events }, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("start error: %v", err)
+	}
+	t.Cleanup(func() { _ = watcher.Close() })
+	return received
+}
+
+// waitEvent は条件に一致する通知が届くまで待ち、届かなければ失敗させる。
+func waitEvent(t *testing.T, received <-chan []Event, want Event) {
+	t.Helper()
+	deadline := time.After(3 * time.Second)
+	var seen []Event
+	for {
+		select {
+		case events := <-received:
+			for _, event := range events {
+				if event == want {
+					return
+				}
+			}
+			seen = append(seen, events...)
+		case <-deadline:
+			t.Fatalf("event %+v not received; got %+v", want, seen)
+		}
+	}
+}
+
+func TestWatcher_ReportsIssueLifecycle(t *testing.T) {
+	// 既存カテゴリでの課題の作成・atomic write による更新・削除が、それぞれ種別付きで通知されることを確認する。
+	root := t.TempDir()
+	categoryPath := filepath.Join(root, "cat")
+	if err := os.MkdirAll(categoryPath, 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	received := startForTest(t, root)
+	issuePath := filepath.Join(categoryPath, "abc.json")
+
+	if err := atomicwrite.WriteFile(issuePath, []byte(`{}`)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	waitEvent(t, received, Event{Kind: KindIssue, Op: OpCreated, Category: "cat", IssueID: "abc", Path: issuePath})
+
+	if err := atomicwrite.WriteFile(issuePath, []byte(`{"a":1}`)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	waitEvent(t, received, Event{Kind: KindIssue, Op: OpModified, Category: "cat", IssueID: "abc", Path: issuePath})
+
+	if err := os.Remove(issuePath); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	waitEvent(t, received, Event{Kind: KindIssue, Op: OpDeleted, Category: "cat", IssueID: "abc", Path: issuePath})
+}
+
+func TestWatcher_FollowsNewCategoriesAndAttachments(t *testing.T) {
+	// 監視開始後に作成したカテゴリと添付ディレクトリも監視対象となり、ドット始まりの変更は通知されないことを確認する。
+	root := t.TempDir()
+	received := startForTest(t, root)
+
+	if err := os.MkdirAll(filepath.Join(root, ".ratta"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	categoryPath := filepath.Join(root, "new")
+	if err := os.MkdirAll(categoryPath, 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	waitEvent(t, received, Event{Kind: KindCategory, Op: OpCreated, Category: "new", Path: categoryPath})
+
+	attachDir := filepath.Join(categoryPath, "abc.files")
+	if err := os.MkdirAll(attachDir, 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	waitEvent(t, received, Event{Kind: KindAttachment, Op: OpModified, Category: "new", IssueID: "abc", Path: attachDir})
+
+	// 監視登録が反映されるまでの取りこぼしを避けるため、少し待ってから添付を書き込む。
+	time.Sleep(100 * time.Millisecond)
+	attachPath := filepath.Join(attachDir, "file.txt")
+	if err := os.WriteFile(attachPath, []byte("x"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	waitEvent(t, received, Event{Kind: KindAttachment, Op: OpCreated, Category: "new", IssueID: "abc", Path: attachPath})
+
+	if err := os.WriteFile(filepath.Join(root, ".ratta", "index.json"), []byte("{}"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.RemoveAll(categoryPath); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	waitEvent(t, received, Event{Kind: KindCategory, Op: OpDeleted, Category: "new", Path: categoryPath})
+}
+
+func TestMergeOp(t *testing.T) {
+	// 作成直後の更新は作成、削除後の再作成は更新、それ以外は後の変更として扱うことを確認する。
+	cases := []struct {
+		previous, next, want Op
+	}{
+		{OpCreated, OpModified, OpCreated},
+		{OpDeleted, OpCreated, OpModified},
+		{OpModified, OpDeleted, OpDeleted},
+		{OpCreated, OpDeleted, OpDeleted},
+	}
+	for _, tc := range cases {
+		if got := mergeOp(tc.previous, tc.next); got != tc.want {
+			t.Fatalf("mergeOp(%s, %s) = %s, want %s", tc.previous, tc.next, got, tc.want)
+		}
+	}
+}
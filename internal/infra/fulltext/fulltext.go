@@ -0,0 +1,335 @@
+// Package fulltext は課題のタイトル・説明・コメントに対する全文検索索引 (bleve) の構築と検索を担う。
+// 課題JSONの読み込みや検証は呼び出し側に委ね、索引は再構築可能なキャッシュとして .ratta/search.bleve に置く。
+package fulltext
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/lang/cjk"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/projectmeta"
+)
+
+const (
+	// dirName は DD-SEARCH-001 の索引ディレクトリ名を表す。
+	dirName = "search.bleve"
+	// stampKeyPrefix は DD-SEARCH-001 のカテゴリ単位の鮮度情報を保存する内部キーの接頭辞を表す。
+	stampKeyPrefix = "stamps/"
+	// defaultLimit は DD-SEARCH-001 の既定の最大取得件数を表す。
+	defaultLimit = 50
+)
+
+// 検索対象フィールドの名前を表す。
+const (
+	FieldTitle       = "title"
+	FieldDescription = "description"
+	FieldComments    = "comments"
+	fieldCategory    = "category"
+	fieldIssueID     = "issue_id"
+)
+
+// Document は DD-SEARCH-001 の索引対象となる課題1件分の内容を表す。
+type Document struct {
+	IssueID     string
+	Category    string
+	Title       string
+	Description string
+	Comments    []string
+}
+
+// LoadFunc は索引に無い、または古くなった課題JSONを読み込んで索引対象の内容を返す。
+type LoadFunc func(path string) (Document, error)
+
+// Location は DD-SEARCH-001 の一致箇所を表す。Start と End はフィールド値の UTF-8 バイト位置。
+type Location struct {
+	Field string
+	Start int
+	End   int
+}
+
+// Hit は DD-SEARCH-001 の検索結果1件を表す。
+type Hit struct {
+	Category  string
+	IssueID   string
+	Score     float64
+	Locations []Location
+}
+
+// Index は DD-SEARCH-001 のプロジェクト単位の全文検索索引を表す。
+type Index struct {
+	index bleve.Index
+}
+
+// fileStamp は鮮度判定に用いる mtime とサイズの組を表す。
+type fileStamp struct {
+	ModTime   int64 `json:"mtime"`
+	SizeBytes int64 `json:"size_bytes"`
+}
+
+// Open は DD-SEARCH-001 の索引を開き、存在しなければ作成する。
+// 目的: 検索・更新のために索引へ接続する。
+// 入力: root はプロジェクトルートパス。
+// 出力: Index とエラー。
+// エラー: 索引の作成・オープン失敗時に返す。
+// 副作用: 初回は .ratta/search.bleve を作成する。
+// 並行性: bleve の索引は同時に1プロセスのみ開けるため、呼び出し側で Close する。
+// 不変条件: 日本語を含む本文は CJK bigram で分割して索引化する。
+// 関連DD: DD-SEARCH-001
+func Open(root string) (*Index, error) {
+	path := filepath.Join(projectmeta.Dir(root), dirName)
+	opened, err := bleve.Open(path)
+	if err == nil {
+		return &Index{index: opened}, nil
+	}
+	if !errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		return nil, fmt.Errorf("open search index: %w", err)
+	}
+	if mkdirErr := os.MkdirAll(projectmeta.Dir(root), 0o750); mkdirErr != nil {
+		return nil, fmt.Errorf("create project meta dir: %w", mkdirErr)
+	}
+	created, err := bleve.New(path, newMapping())
+	if err != nil {
+		return nil, fmt.Errorf("create search index: %w", err)
+	}
+	return &Index{index: created}, nil
+}
+
+// Close は DD-SEARCH-001 の索引を閉じる。
+func (x *Index) Close() error {
+	if err := x.index.Close(); err != nil {
+		return fmt.Errorf("close search index: %w", err)
+	}
+	return nil
+}
+
+// newMapping は DD-SEARCH-001 の索引定義を生成する。
+// 本文は CJK アナライザで bigram 化し、分かち書きの無い日本語でも部分一致できるようにする。
+func newMapping() mapping.IndexMapping {
+	text := bleve.NewTextFieldMapping()
+	text.Analyzer = cjk.AnalyzerName
+	text.Store = false
+	text.IncludeTermVectors = true
+
+	exact := bleve.NewTextFieldMapping()
+	exact.Analyzer = keyword.Name
+	exact.Store = true
+	exact.IncludeInAll = false
+
+	doc := bleve.NewDocumentMapping()
+	doc.AddFieldMappingsAt(FieldTitle, text)
+	doc.AddFieldMappingsAt(FieldDescription, text)
+	doc.AddFieldMappingsAt(FieldComments, text)
+	doc.AddFieldMappingsAt(fieldCategory, exact)
+	doc.AddFieldMappingsAt(fieldIssueID, exact)
+
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultMapping = doc
+	indexMapping.DefaultAnalyzer = cjk.AnalyzerName
+	return indexMapping
+}
+
+// SyncCategory は DD-SEARCH-001 のカテゴリ単位の差分更新を行う。
+// 目的: 変更・追加された課題のみ再索引化し、削除された課題を索引から除く。
+// 入力: categoryPath はカテゴリパス、category はカテゴリ名、load は再読込関数。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: カテゴリ読み取り、索引更新失敗時に返す。
+// 副作用: 索引と鮮度情報を更新する。
+// 並行性: 同一索引への同時更新は呼び出し側で排他する。
+// 不変条件: 読み込みに失敗した課題は索引に残さない。
+// 関連DD: DD-SEARCH-001
+func (x *Index) SyncCategory(categoryPath, category string, load LoadFunc) error {
+	return x.SyncCategoryContext(context.Background(), categoryPath, category, load)
+}
+
+// SyncCategoryContext は DD-SEARCH-001/DD-CANCEL-001 の中断可能な差分更新を行う。
+// 中断された場合は索引と鮮度情報を更新せずに ctx.Err() を返し、次回の更新でやり直す。
+func (x *Index) SyncCategoryContext(ctx context.Context, categoryPath, category string, load LoadFunc) error {
+	dirEntries, err := os.ReadDir(categoryPath)
+	if err != nil {
+		return fmt.Errorf("read category: %w", err)
+	}
+	known, err := x.loadStamps(category)
+	if err != nil {
+		return err
+	}
+
+	next := make(map[string]fileStamp, len(dirEntries))
+	batch := x.index.NewBatch()
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if dirEntry.IsDir() || !issue.IsIssueFileName(name) {
+			continue
+		}
+		info, infoErr := dirEntry.Info()
+		if infoErr != nil {
+			continue
+		}
+		stamp := fileStamp{ModTime: info.ModTime().UnixNano(), SizeBytes: info.Size()}
+		previous, ok := known[name]
+		delete(known, name)
+		if ok && previous == stamp {
+			next[name] = stamp
+			continue
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		docID := documentID(category, strings.TrimSuffix(name, ".json"))
+		doc, loadErr := load(filepath.Join(categoryPath, name))
+		if loadErr != nil {
+			batch.Delete(docID)
+			continue
+		}
+		if indexErr := batch.Index(docID, toIndexed(category, doc)); indexErr != nil {
+			return fmt.Errorf("index issue: %w", indexErr)
+		}
+		next[name] = stamp
+	}
+	for name := range known {
+		batch.Delete(documentID(category, strings.TrimSuffix(name, ".json")))
+	}
+	if batch.Size() == 0 && len(known) == 0 {
+		return nil
+	}
+	if batchErr := x.index.Batch(batch); batchErr != nil {
+		return fmt.Errorf("update search index: %w", batchErr)
+	}
+	return x.saveStamps(category, next)
+}
+
+// Search は DD-SEARCH-001 の全文検索を行う。
+// 目的: タイトル・説明・コメントを対象にスコア順の検索結果と一致箇所を返す。
+// 入力: text は検索文字列、categories は対象カテゴリ (空なら全体)、limit は最大件数。
+// 出力: スコア降順の Hit 一覧とエラー。
+// エラー: 検索実行失敗時に返す。
+// 副作用: なし。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: タイトルの一致は説明・コメントより高く評価する。
+// 関連DD: DD-SEARCH-001
+func (x *Index) Search(text string, categories []string, limit int) ([]Hit, error) {
+	if strings.TrimSpace(text) == "" {
+		return []Hit{}, nil
+	}
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	fields := query.NewDisjunctionQuery(nil)
+	for field, boost := range map[string]float64{FieldTitle: 3, FieldDescription: 1, FieldComments: 1} {
+		match := bleve.NewMatchQuery(text)
+		match.SetField(field)
+		match.SetBoost(boost)
+		fields.AddQuery(match)
+	}
+	var searchQuery query.Query = fields
+	if len(categories) > 0 {
+		scope := query.NewDisjunctionQuery(nil)
+		for _, category := range categories {
+			term := bleve.NewTermQuery(category)
+			term.SetField(fieldCategory)
+			scope.AddQuery(term)
+		}
+		searchQuery = bleve.NewConjunctionQuery(fields, scope)
+	}
+
+	request := bleve.NewSearchRequestOptions(searchQuery, limit, 0, false)
+	request.Fields = []string{fieldCategory, fieldIssueID}
+	request.IncludeLocations = true
+	result, err := x.index.Search(request)
+	if err != nil {
+		return nil, fmt.Errorf("search index: %w", err)
+	}
+
+	hits := make([]Hit, 0, len(result.Hits))
+	for _, match := range result.Hits {
+		category, _ := match.Fields[fieldCategory].(string)
+		issueID, _ := match.Fields[fieldIssueID].(string)
+		hit := Hit{Category: category, IssueID: issueID, Score: match.Score}
+		for field, terms := range match.Locations {
+			for _, locations := range terms {
+				for _, location := range locations {
+					hit.Locations = append(hit.Locations, Location{
+						Field: field,
+						Start: int(location.Start),
+						End:   int(location.End),
+					})
+				}
+			}
+		}
+		sort.Slice(hit.Locations, func(i, j int) bool {
+			if hit.Locations[i].Field != hit.Locations[j].Field {
+				return hit.Locations[i].Field < hit.Locations[j].Field
+			}
+			return hit.Locations[i].Start < hit.Locations[j].Start
+		})
+		hits = append(hits, hit)
+	}
+	return hits, nil
+}
+
+// indexedDocument は bleve に渡す索引用の表現を表す。
+type indexedDocument struct {
+	Category    string `json:"category"`
+	IssueID     string `json:"issue_id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Comments    string `json:"comments"`
+}
+
+// toIndexed は DD-SEARCH-001 の索引用表現へ変換する。
+// コメントは1フィールドに連結し、コメント境界をまたぐ bigram を避けるため改行で区切る。
+func toIndexed(category string, doc Document) indexedDocument {
+	return indexedDocument{
+		Category:    category,
+		IssueID:     doc.IssueID,
+		Title:       doc.Title,
+		Description: doc.Description,
+		Comments:    strings.Join(doc.Comments, "\n"),
+	}
+}
+
+// documentID は DD-SEARCH-001 の索引内文書IDを生成する。
+func documentID(category, issueID string) string {
+	return category + "/" + issueID
+}
+
+// loadStamps は DD-SEARCH-001 のカテゴリ単位の鮮度情報を読み込む。
+func (x *Index) loadStamps(category string) (map[string]fileStamp, error) {
+	data, err := x.index.GetInternal([]byte(stampKeyPrefix + category))
+	if err != nil {
+		return nil, fmt.Errorf("read search stamps: %w", err)
+	}
+	stamps := make(map[string]fileStamp)
+	if len(data) == 0 {
+		return stamps, nil
+	}
+	// 鮮度情報が壊れている場合は全件を再索引化すれば回復できるため、空として扱う。
+	if unmarshalErr := json.Unmarshal(data, &stamps); unmarshalErr != nil {
+		return make(map[string]fileStamp), nil
+	}
+	return stamps, nil
+}
+
+// saveStamps は DD-SEARCH-001 のカテゴリ単位の鮮度情報を保存する。
+func (x *Index) saveStamps(category string, stamps map[string]fileStamp) error {
+	data, err := json.Marshal(stamps)
+	if err != nil {
+		return fmt.Errorf("marshal search stamps: %w", err)
+	}
+	if setErr := x.index.SetInternal([]byte(stampKeyPrefix+category), data); setErr != nil {
+		return fmt.Errorf("write search stamps: %w", setErr)
+	}
+	return nil
+}
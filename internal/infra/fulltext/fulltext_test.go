@@ -0,0 +1,172 @@
+// fulltext_test.go は全文検索索引の差分更新と検索結果のテストを行い、課題JSONの読み込みは扱わない。
+package fulltext
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeIssueFile はテスト用の課題JSONを書き込み、mtime を指定時刻に揃える。
+func writeIssueFile(t *testing.T, path, body string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+}
+
+// fakeLoader はファイル内容を「タイトル|説明|コメント」として解釈する読み込み関数を返す。
+func fakeLoader(loads map[string]int) LoadFunc {
+	return func(path string) (Document, error) {
+		name := filepath.Base(path)
+		loads[name]++
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Document{}, err
+		}
+		parts := strings.SplitN(string(data), "|", 3)
+		for len(parts) < 3 {
+			parts = append(parts, "")
+		}
+		return Document{
+			IssueID:     strings.TrimSuffix(name, ".json"),
+			Title:       parts[0],
+			Description: parts[1],
+			Comments:    []string{parts[2]},
+		}, nil
+	}
+}
+
+func TestSearch_JapaneseAcrossFieldsRankedByTitle(t *testing.T) {
+	// 分かち書きの無い日本語でも部分一致し、タイトル一致が上位に並び、一致箇所が返ることを確認する。
+	root := t.TempDir()
+	categoryPath := filepath.Join(root, "cat")
+	if err := os.MkdirAll(categoryPath, 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	writeIssueFile(t, filepath.Join(categoryPath, "a.json"), "画面の表示|ログイン画面で不具合が発生する|", base)
+	writeIssueFile(t, filepath.Join(categoryPath, "b.json"), "ログイン不具合|詳細なし|", base)
+	writeIssueFile(t, filepath.Join(categoryPath, "c.json"), "無関係|説明|コメントで不具合を報告", base)
+	writeIssueFile(t, filepath.Join(categoryPath, "d.json"), "無関係|説明|対応済み", base)
+
+	index, err := Open(root)
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	defer func() { _ = index.Close() }()
+	if err := index.SyncCategory(categoryPath, "cat", fakeLoader(map[string]int{})); err != nil {
+		t.Fatalf("SyncCategory error: %v", err)
+	}
+
+	hits, err := index.Search("不具合", nil, 0)
+	if err != nil {
+		t.Fatalf("Search error: %v", err)
+	}
+	if len(hits) != 3 {
+		t.Fatalf("expected 3 hits, got %+v", hits)
+	}
+	if hits[0].IssueID != "b" || hits[0].Category != "cat" {
+		t.Fatalf("expected title match first, got %+v", hits[0])
+	}
+	found := false
+	for _, location := range hits[0].Locations {
+		if location.Field == FieldTitle && location.Start == len("ログイン") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected title location, got %+v", hits[0].Locations)
+	}
+}
+
+func TestSyncCategory_ReindexesOnlyChangedAndDropsRemoved(t *testing.T) {
+	// 変更の無い課題は再読込せず、更新された課題は新しい内容で、削除された課題は結果から消えることを確認する。
+	root := t.TempDir()
+	categoryPath := filepath.Join(root, "cat")
+	if err := os.MkdirAll(categoryPath, 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	writeIssueFile(t, filepath.Join(categoryPath, "a.json"), "仕様確認", base)
+	writeIssueFile(t, filepath.Join(categoryPath, "b.json"), "仕様変更", base)
+
+	loads := map[string]int{}
+	index, err := Open(root)
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	if err := index.SyncCategory(categoryPath, "cat", fakeLoader(loads)); err != nil {
+		t.Fatalf("SyncCategory error: %v", err)
+	}
+	if err := index.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+
+	writeIssueFile(t, filepath.Join(categoryPath, "a.json"), "性能改善", base.Add(time.Minute))
+	if err := os.Remove(filepath.Join(categoryPath, "b.json")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	writeIssueFile(t, filepath.Join(categoryPath, "c.json"), "仕様追加", base)
+
+	index, err = Open(root)
+	if err != nil {
+		t.Fatalf("reopen error: %v", err)
+	}
+	defer func() { _ = index.Close() }()
+	if err := index.SyncCategory(categoryPath, "cat", fakeLoader(loads)); err != nil {
+		t.Fatalf("SyncCategory error: %v", err)
+	}
+	if loads["a.json"] != 2 || loads["b.json"] != 1 || loads["c.json"] != 1 {
+		t.Fatalf("unexpected loads: %v", loads)
+	}
+
+	hits, err := index.Search("仕様", nil, 0)
+	if err != nil {
+		t.Fatalf("Search error: %v", err)
+	}
+	if len(hits) != 1 || hits[0].IssueID != "c" {
+		t.Fatalf("expected only c, got %+v", hits)
+	}
+}
+
+func TestSearch_ScopeAndBlankQuery(t *testing.T) {
+	// カテゴリ指定時は他カテゴリの課題を返さず、空の検索文字列では何も返さないことを確認する。
+	root := t.TempDir()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	index, err := Open(root)
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	defer func() { _ = index.Close() }()
+	for _, name := range []string{"alpha", "beta"} {
+		categoryPath := filepath.Join(root, name)
+		if err := os.MkdirAll(categoryPath, 0o750); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		writeIssueFile(t, filepath.Join(categoryPath, "x.json"), "timeout error", base)
+		if err := index.SyncCategory(categoryPath, name, fakeLoader(map[string]int{})); err != nil {
+			t.Fatalf("SyncCategory error: %v", err)
+		}
+	}
+
+	hits, err := index.Search("TIMEOUT", []string{"beta"}, 0)
+	if err != nil {
+		t.Fatalf("Search error: %v", err)
+	}
+	if len(hits) != 1 || hits[0].Category != "beta" {
+		t.Fatalf("expected beta only, got %+v", hits)
+	}
+	blank, err := index.Search("  ", nil, 0)
+	if err != nil {
+		t.Fatalf("Search error: %v", err)
+	}
+	if len(blank) != 0 {
+		t.Fatalf("expected no hits, got %+v", blank)
+	}
+}
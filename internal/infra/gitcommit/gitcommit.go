@@ -0,0 +1,218 @@
+// Package gitcommit はプロジェクトルートを含む git リポジトリへ、変更操作ごとの差分をコミットする処理と、
+// ファイルごとのコミット履歴を読み出す処理を担う。
+// 自動コミットを行うかどうかの判断や設定の読み込み、履歴の内容の解釈は扱わず、呼び出し側が DD-PROJCONF-001 の設定に従って呼び出す。
+package gitcommit
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"ratta/internal/infra/projectlock"
+	"ratta/internal/infra/projectmeta"
+	"ratta/internal/infra/tmpresidue"
+)
+
+// defaultAuthorName と defaultAuthorEmail は DD-GIT-001 の作成者を決められない場合に用いる名前とアドレスを表す。
+const (
+	defaultAuthorName  = "ratta"
+	defaultAuthorEmail = "ratta@localhost"
+)
+
+// ErrNotRepository は DD-GIT-001 のプロジェクトルートが git リポジトリに含まれないことを表す。
+var ErrNotRepository = errors.New("project root is not in a git repository")
+
+// excludedMetaEntries は DD-GIT-001 の .ratta 配下でコミットしないエントリを表す。
+// DD-BACKUP-001 と同じく、課題JSONから再生成できる索引・キャッシュと、インスタンス固有の操作記録・同期の記録・移行時のバックアップを除く。
+var excludedMetaEntries = map[string]bool{
+	"index.json":   true,
+	"cache.db":     true,
+	"cache.db-wal": true,
+	"cache.db-shm": true,
+	"search.bleve": true,
+	"journal":      true,
+	"backups":      true,
+	"sync":         true,
+}
+
+// Change は DD-GIT-001 のコミットする変更操作を表す。Operation は操作名、Actor は操作者、Mode は操作モードを表す。
+// IssueID は課題を対象としない操作では空とする。
+type Change struct {
+	Operation string
+	Category  string
+	IssueID   string
+	Actor     string
+	Mode      string
+}
+
+// Author は DD-GIT-001 のコミットの作成者を表す。空の項目はリポジトリの user.name・user.email を用いる。
+type Author struct {
+	Name  string
+	Email string
+}
+
+// Result は DD-GIT-001 のコミットの結果を表す。コミットする差分が無かった場合、Hash は空とする。
+type Result struct {
+	Hash  string
+	Files []string
+}
+
+// Message は DD-GIT-001 のコミットメッセージを返す。
+// 1行目は操作名と対象、本文は git の trailer 形式で操作名・カテゴリ・課題ID・操作者・操作モードを記す。
+func Message(change Change) string {
+	target := change.Category
+	if change.IssueID != "" {
+		target += "/" + change.IssueID
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "ratta: %s %s\n\n", change.Operation, target)
+	fmt.Fprintf(&b, "Operation: %s\n", change.Operation)
+	if change.Category != "" {
+		fmt.Fprintf(&b, "Category: %s\n", change.Category)
+	}
+	if change.IssueID != "" {
+		fmt.Fprintf(&b, "Issue-ID: %s\n", change.IssueID)
+	}
+	if change.Actor != "" {
+		fmt.Fprintf(&b, "Actor: %s\n", change.Actor)
+	}
+	if change.Mode != "" {
+		fmt.Fprintf(&b, "Mode: %s\n", change.Mode)
+	}
+	return b.String()
+}
+
+// Commit は DD-GIT-001 のプロジェクトルート配下の変更をステージしてコミットする。
+// 目的: 課題やカテゴリの変更を操作ごとの履歴として git に残し、差分の確認や取り消しを git で行えるようにする。
+// 入力: root はプロジェクトルート、change はコミットする変更操作、author はコミットの作成者、when はコミット日時。
+// 出力: Result とエラー。プロジェクトルート配下に変更が無い場合は Hash を空として返す。
+// エラー: プロジェクトルートが git リポジトリに含まれない場合は ErrNotRepository、状態の取得・ステージ・コミットに失敗した場合に返す。
+// 副作用: リポジトリのインデックスを更新し、HEAD のブランチへコミットを追加する。
+// 並行性: 同じリポジトリへの同時呼び出しは呼び出し側で直列化する。
+// 不変条件: プロジェクトルートの外、.gitignore で除外したファイル、ロックファイル・一時ファイル、.ratta 配下の索引・キャッシュ・操作記録はステージしない。
+// インデックスに既にステージされていた変更はコミットに含まれる。
+// 関連DD: DD-GIT-001, DD-PROJCONF-001
+func Commit(root string, change Change, author Author, when time.Time) (Result, error) {
+	repo, err := git.PlainOpenWithOptions(root, &git.PlainOpenOptions{DetectDotGit: true})
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return Result{}, ErrNotRepository
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("open git repository: %w", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return Result{}, fmt.Errorf("open git worktree: %w", err)
+	}
+	prefix, err := projectPrefix(worktree.Filesystem.Root(), root)
+	if err != nil {
+		return Result{}, err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return Result{}, fmt.Errorf("git status: %w", err)
+	}
+	files := make([]string, 0, len(status))
+	for name, fileStatus := range status {
+		if fileStatus.Worktree == git.Unmodified {
+			continue
+		}
+		rel, ok := strings.CutPrefix(name, prefix)
+		if !ok || excluded(rel) {
+			continue
+		}
+		files = append(files, name)
+	}
+	if len(files) == 0 {
+		return Result{}, nil
+	}
+	sort.Strings(files)
+	for _, name := range files {
+		if _, addErr := worktree.Add(name); addErr != nil {
+			return Result{}, fmt.Errorf("git add %s: %w", name, addErr)
+		}
+	}
+	signature, err := resolveAuthor(repo, author, change.Actor)
+	if err != nil {
+		return Result{}, err
+	}
+	signature.When = when
+	hash, err := worktree.Commit(Message(change), &git.CommitOptions{Author: &signature})
+	if err != nil {
+		return Result{}, fmt.Errorf("git commit: %w", err)
+	}
+	return Result{Hash: hash.String(), Files: files}, nil
+}
+
+// projectPrefix は DD-GIT-001 のリポジトリのルートからプロジェクトルートまでの相対パスを、末尾に / を付けて返す。
+// プロジェクトルートがリポジトリのルートの場合は空文字を返す。シンボリックリンクは解決してから比べる。
+func projectPrefix(top, root string) (string, error) {
+	resolvedTop, err := filepath.EvalSymlinks(top)
+	if err != nil {
+		return "", fmt.Errorf("resolve git worktree: %w", err)
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("resolve project root: %w", err)
+	}
+	resolvedRoot, err := filepath.EvalSymlinks(absRoot)
+	if err != nil {
+		return "", fmt.Errorf("resolve project root: %w", err)
+	}
+	rel, err := filepath.Rel(resolvedTop, resolvedRoot)
+	if err != nil {
+		return "", fmt.Errorf("resolve project root: %w", err)
+	}
+	if rel == "." {
+		return "", nil
+	}
+	return filepath.ToSlash(rel) + "/", nil
+}
+
+// excluded は DD-GIT-001 のコミットしないファイルの判定を行う。rel はプロジェクトルートからの相対パス (区切りは /)。
+func excluded(rel string) bool {
+	if rel == projectlock.FileName || tmpresidue.IsArtifact(path.Base(rel)) {
+		return true
+	}
+	if name, ok := strings.CutPrefix(rel, projectmeta.DirName+"/"); ok {
+		first, _, _ := strings.Cut(name, "/")
+		return excludedMetaEntries[first]
+	}
+	return false
+}
+
+// resolveAuthor は DD-GIT-001 のコミットの作成者を決める。
+// author の値、リポジトリ (とグローバル) の user.name・user.email、操作者名と既定のアドレスの順に用いる。
+func resolveAuthor(repo *git.Repository, author Author, actor string) (object.Signature, error) {
+	name, email := author.Name, author.Email
+	if name == "" || email == "" {
+		cfg, err := repo.ConfigScoped(config.GlobalScope)
+		if err != nil {
+			return object.Signature{}, fmt.Errorf("read git config: %w", err)
+		}
+		if name == "" {
+			name = cfg.User.Name
+		}
+		if email == "" {
+			email = cfg.User.Email
+		}
+	}
+	if name == "" {
+		name = actor
+	}
+	if name == "" {
+		name = defaultAuthorName
+	}
+	if email == "" {
+		email = defaultAuthorEmail
+	}
+	return object.Signature{Name: name, Email: email}, nil
+}
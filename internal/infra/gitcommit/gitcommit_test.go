@@ -0,0 +1,115 @@
+// gitcommit_test.go はプロジェクトルート配下の変更のコミットと対象外のファイル、コミットメッセージのテストを行う。
+package gitcommit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// writeFile はテスト用に親ディレクトリを作成してファイルを書き込む。
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+// headCommit はテスト用に HEAD のコミットを返す。
+func headCommit(t *testing.T, repoDir string) *object.Commit {
+	t.Helper()
+	repo, err := git.PlainOpen(repoDir)
+	if err != nil {
+		t.Fatalf("PlainOpen error: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Head error: %v", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatalf("CommitObject error: %v", err)
+	}
+	return commit
+}
+
+func TestCommit_CommitsProjectChangesWithStructuredMessage(t *testing.T) {
+	// リポジトリの一部であるプロジェクトルート配下の変更のみをコミットし、索引やロックファイルは含めないことを確認する。
+	repoDir := t.TempDir()
+	if _, err := git.PlainInit(repoDir, false); err != nil {
+		t.Fatalf("PlainInit error: %v", err)
+	}
+	root := filepath.Join(repoDir, "project")
+	writeFile(t, filepath.Join(root, "cat", "abc.json"), "{}")
+	writeFile(t, filepath.Join(root, ".ratta", "index.json"), "{}")
+	writeFile(t, filepath.Join(root, ".ratta", "journal", "entries", "1.json"), "{}")
+	writeFile(t, filepath.Join(root, ".ratta", "category_order.json"), "{}")
+	writeFile(t, filepath.Join(root, ".ratta.lock"), "{}")
+	writeFile(t, filepath.Join(repoDir, "outside.txt"), "x")
+
+	change := Change{Operation: "issue_created", Category: "cat", IssueID: "abc", Actor: "suzuki", Mode: "Vendor"}
+	when := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	result, err := Commit(root, change, Author{Name: "Suzuki", Email: "suzuki@example.com"}, when)
+	if err != nil {
+		t.Fatalf("Commit error: %v", err)
+	}
+	if result.Hash == "" || strings.Join(result.Files, ",") != "project/.ratta/category_order.json,project/cat/abc.json" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	commit := headCommit(t, repoDir)
+	if commit.Author.Email != "suzuki@example.com" || !commit.Author.When.Equal(when) {
+		t.Fatalf("unexpected author: %+v", commit.Author)
+	}
+	if !strings.HasPrefix(commit.Message, "ratta: issue_created cat/abc\n\n") || !strings.Contains(commit.Message, "\nIssue-ID: abc\nActor: suzuki\nMode: Vendor\n") {
+		t.Fatalf("unexpected message:\n%s", commit.Message)
+	}
+
+	result, err = Commit(root, change, Author{}, when)
+	if err != nil || result.Hash != "" {
+		t.Fatalf("expected no commit, got %+v %v", result, err)
+	}
+}
+
+func TestCommit_StagesDeletionAndFallsBackToActor(t *testing.T) {
+	// 削除したファイルもコミットし、作成者を決められない場合は操作者名と既定のアドレスを用いることを確認する。
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	if _, err := git.PlainInit(root, false); err != nil {
+		t.Fatalf("PlainInit error: %v", err)
+	}
+	writeFile(t, filepath.Join(root, "cat", "abc.json"), "{}")
+	if _, err := Commit(root, Change{Operation: "issue_created", Category: "cat", IssueID: "abc"}, Author{}, time.Now()); err != nil {
+		t.Fatalf("Commit error: %v", err)
+	}
+	if err := os.RemoveAll(filepath.Join(root, "cat")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	result, err := Commit(root, Change{Operation: "category_deleted", Category: "cat", Actor: "sato"}, Author{}, time.Now())
+	if err != nil || len(result.Files) != 1 {
+		t.Fatalf("unexpected result: %+v %v", result, err)
+	}
+	commit := headCommit(t, root)
+	if commit.Author.Name != "sato" || commit.Author.Email != defaultAuthorEmail || !strings.HasPrefix(commit.Message, "ratta: category_deleted cat\n") {
+		t.Fatalf("unexpected commit: %+v %q", commit.Author, commit.Message)
+	}
+	if _, err := commit.File("cat/abc.json"); err == nil {
+		t.Fatal("expected deleted file to be removed from the commit")
+	}
+}
+
+func TestCommit_ReportsNonRepository(t *testing.T) {
+	// git リポジトリに含まれないプロジェクトルートは ErrNotRepository とすることを確認する。
+	if _, err := Commit(t.TempDir(), Change{Operation: "issue_created"}, Author{}, time.Now()); !errors.Is(err, ErrNotRepository) {
+		t.Fatalf("expected ErrNotRepository, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+// history.go はプロジェクトルートを含む git リポジトリから、ファイルごとのコミット履歴と各版の内容を読み出す処理を担い、
+// 内容の解釈は扱わない。
+package gitcommit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Revision は DD-GIT-002 のファイルを変更したコミットと、そのコミット時点のファイルの内容を表す。
+// Change はコミットメッセージの trailer から復元した変更操作で、ratta 以外が作成したコミットでは空の項目となる。
+// Deleted はそのコミットでファイルが削除されたことを表し、その場合 Data は空とする。
+type Revision struct {
+	Hash        string
+	AuthorName  string
+	AuthorEmail string
+	When        time.Time
+	Subject     string
+	Change      Change
+	Deleted     bool
+	Data        []byte
+}
+
+// trailerFields は DD-GIT-002 の Message が書く trailer のキーと、Change の項目への代入を表す。
+var trailerFields = map[string]func(*Change, string){
+	"Operation": func(c *Change, v string) { c.Operation = v },
+	"Category":  func(c *Change, v string) { c.Category = v },
+	"Issue-ID":  func(c *Change, v string) { c.IssueID = v },
+	"Actor":     func(c *Change, v string) { c.Actor = v },
+	"Mode":      func(c *Change, v string) { c.Mode = v },
+}
+
+// ParseMessage は DD-GIT-002 の Message が書いたコミットメッセージの trailer から変更操作を復元する。
+// 1行目は対象としない。知らないキーの行や trailer の無いメッセージは無視し、該当する項目を空とする。
+func ParseMessage(message string) Change {
+	var change Change
+	_, body, _ := strings.Cut(message, "\n")
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ": ")
+		if !ok {
+			continue
+		}
+		if assign, known := trailerFields[key]; known {
+			assign(&change, strings.TrimSpace(value))
+		}
+	}
+	return change
+}
+
+// FileHistory は DD-GIT-002 のプロジェクトルートからの相対パスのファイルを変更したコミットを新しい順に返す。
+// 目的: 課題JSONが各コミットでどのような内容だったかをたどり、課題の変遷を確認できるようにする。
+// 入力: ctx は中断用、root はプロジェクトルート、rel はプロジェクトルートからの相対パス (区切りは /)、limit は返す件数の上限 (0 以下は無制限)。
+// 出力: Revision の一覧とエラー。ファイルを変更したコミットが無い場合は空の一覧を返す。
+// エラー: プロジェクトルートが git リポジトリに含まれない場合は ErrNotRepository、
+// HEAD が無い (コミットが1つも無い) 場合は空の一覧、履歴や内容の読み出しに失敗した場合と中断した場合に返す。
+// 副作用: なし。
+// 並行性: 読み取りのみで、Commit と同時に呼び出してもよい。
+// 不変条件: HEAD から親をたどった履歴のみを対象とし、作業ツリーの未コミットの変更は含めない。改名は追跡しない。
+// 関連DD: DD-GIT-002, DD-GIT-001
+func FileHistory(ctx context.Context, root, rel string, limit int) ([]Revision, error) {
+	repo, err := git.PlainOpenWithOptions(root, &git.PlainOpenOptions{DetectDotGit: true})
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return nil, ErrNotRepository
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open git repository: %w", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("open git worktree: %w", err)
+	}
+	prefix, err := projectPrefix(worktree.Filesystem.Root(), root)
+	if err != nil {
+		return nil, err
+	}
+	name := prefix + rel
+	revisions := []Revision{}
+	if _, headErr := repo.Head(); errors.Is(headErr, plumbing.ErrReferenceNotFound) {
+		return revisions, nil
+	}
+	commits, err := repo.Log(&git.LogOptions{FileName: &name})
+	if err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+	defer commits.Close()
+	for limit <= 0 || len(revisions) < limit {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		commit, nextErr := commits.Next()
+		if errors.Is(nextErr, io.EOF) {
+			break
+		}
+		if nextErr != nil {
+			return nil, fmt.Errorf("git log: %w", nextErr)
+		}
+		revision, revErr := toRevision(commit, name)
+		if revErr != nil {
+			return nil, revErr
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, nil
+}
+
+// toRevision は DD-GIT-002 のコミットと、そのコミット時点のファイルの内容を Revision に変換する。
+func toRevision(commit *object.Commit, name string) (Revision, error) {
+	subject, _, _ := strings.Cut(commit.Message, "\n")
+	revision := Revision{
+		Hash:        commit.Hash.String(),
+		AuthorName:  commit.Author.Name,
+		AuthorEmail: commit.Author.Email,
+		When:        commit.Author.When,
+		Subject:     subject,
+		Change:      ParseMessage(commit.Message),
+	}
+	file, err := commit.File(name)
+	if errors.Is(err, object.ErrFileNotFound) {
+		revision.Deleted = true
+		return revision, nil
+	}
+	if err != nil {
+		return Revision{}, fmt.Errorf("read %s at %s: %w", name, revision.Hash, err)
+	}
+	contents, err := file.Contents()
+	if err != nil {
+		return Revision{}, fmt.Errorf("read %s at %s: %w", name, revision.Hash, err)
+	}
+	revision.Data = []byte(contents)
+	return revision, nil
+}
@@ -0,0 +1,103 @@
+// history_test.go はファイルごとのコミット履歴の読み出しとコミットメッセージの trailer の復元のテストを行う。
+package gitcommit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// commitChange はテスト用に変更をコミットし、コミットが作られたことを確かめる。
+func commitChange(t *testing.T, root string, change Change, when time.Time) {
+	t.Helper()
+	result, err := Commit(root, change, Author{Name: "tester", Email: "tester@example.com"}, when)
+	if err != nil || result.Hash == "" {
+		t.Fatalf("Commit error: %+v %v", result, err)
+	}
+}
+
+func TestFileHistory_ReturnsRevisionsNewestFirst(t *testing.T) {
+	// ファイルを変更したコミットのみを新しい順に返し、各版の内容・削除・trailer を復元することを確認する。
+	repoDir := t.TempDir()
+	if _, err := git.PlainInit(repoDir, false); err != nil {
+		t.Fatalf("PlainInit error: %v", err)
+	}
+	root := filepath.Join(repoDir, "project")
+	base := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	writeFile(t, filepath.Join(root, "cat", "abc.json"), `{"title":"v1"}`)
+	commitChange(t, root, Change{Operation: "issue_created", Category: "cat", IssueID: "abc", Actor: "suzuki", Mode: "Vendor"}, base)
+	writeFile(t, filepath.Join(root, "cat", "other.json"), `{}`)
+	commitChange(t, root, Change{Operation: "issue_created", Category: "cat", IssueID: "other"}, base.Add(time.Hour))
+	writeFile(t, filepath.Join(root, "cat", "abc.json"), `{"title":"v2"}`)
+	commitChange(t, root, Change{Operation: "issue_updated", Category: "cat", IssueID: "abc", Actor: "sato", Mode: "Contractor"}, base.Add(2*time.Hour))
+	if err := os.Remove(filepath.Join(root, "cat", "abc.json")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	commitChange(t, root, Change{Operation: "issue_deleted", Category: "cat", IssueID: "abc"}, base.Add(3*time.Hour))
+
+	revisions, err := FileHistory(context.Background(), root, "cat/abc.json", 0)
+	if err != nil {
+		t.Fatalf("FileHistory error: %v", err)
+	}
+	if len(revisions) != 3 {
+		t.Fatalf("expected 3 revisions, got %+v", revisions)
+	}
+	if !revisions[0].Deleted || revisions[0].Data != nil || revisions[0].Change.Operation != "issue_deleted" {
+		t.Fatalf("unexpected deleted revision: %+v", revisions[0])
+	}
+	updated := revisions[1]
+	if string(updated.Data) != `{"title":"v2"}` || updated.Subject != "ratta: issue_updated cat/abc" || !updated.When.Equal(base.Add(2*time.Hour)) {
+		t.Fatalf("unexpected updated revision: %+v", updated)
+	}
+	if updated.Change != (Change{Operation: "issue_updated", Category: "cat", IssueID: "abc", Actor: "sato", Mode: "Contractor"}) || updated.AuthorEmail != "tester@example.com" {
+		t.Fatalf("unexpected change: %+v", updated)
+	}
+	if string(revisions[2].Data) != `{"title":"v1"}` || revisions[2].Change.Actor != "suzuki" {
+		t.Fatalf("unexpected created revision: %+v", revisions[2])
+	}
+
+	limited, err := FileHistory(context.Background(), root, "cat/abc.json", 1)
+	if err != nil || len(limited) != 1 || limited[0].Hash != revisions[0].Hash {
+		t.Fatalf("unexpected limited history: %+v %v", limited, err)
+	}
+}
+
+func TestFileHistory_EmptyRepositoryAndErrors(t *testing.T) {
+	// コミットの無いリポジトリでは空の一覧、リポジトリ外では ErrNotRepository、中断時は context のエラーとすることを確認する。
+	root := t.TempDir()
+	if _, err := git.PlainInit(root, false); err != nil {
+		t.Fatalf("PlainInit error: %v", err)
+	}
+	revisions, err := FileHistory(context.Background(), root, "cat/abc.json", 0)
+	if err != nil || len(revisions) != 0 {
+		t.Fatalf("expected empty history, got %+v %v", revisions, err)
+	}
+
+	writeFile(t, filepath.Join(root, "cat", "abc.json"), `{}`)
+	commitChange(t, root, Change{Operation: "issue_created", Category: "cat", IssueID: "abc"}, time.Now())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := FileHistory(ctx, root, "cat/abc.json", 0); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if _, err := FileHistory(context.Background(), t.TempDir(), "cat/abc.json", 0); !errors.Is(err, ErrNotRepository) {
+		t.Fatalf("expected ErrNotRepository, got %v", err)
+	}
+}
+
+func TestParseMessage_IgnoresSubjectAndUnknownLines(t *testing.T) {
+	// 1行目や知らないキーの行は無視し、Message が書いた trailer のみを復元することを確認する。
+	change := Change{Operation: "comment_added", Category: "cat", IssueID: "abc", Actor: "suzuki", Mode: "Vendor"}
+	if got := ParseMessage(Message(change) + "Signed-off-by: someone\n"); got != change {
+		t.Fatalf("unexpected change: %+v", got)
+	}
+	if got := ParseMessage("Operation: not a trailer\n\nmanual edit\n"); got != (Change{}) {
+		t.Fatalf("expected empty change, got %+v", got)
+	}
+}
@@ -0,0 +1,337 @@
+// Package issueindex は .ratta/index.json に課題一覧向けの要約を保持する索引を担い、課題JSONの検証や解釈は扱わない。
+// 索引はあくまでキャッシュであり、正本は常にカテゴリ配下の課題JSONとする。
+package issueindex
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/atomicwrite"
+	"ratta/internal/infra/jsonfmt"
+	"ratta/internal/infra/projectmeta"
+	"ratta/internal/infra/workerpool"
+)
+
+const (
+	// fileName は DD-INDEX-001 の索引ファイル名を表す。
+	fileName = "index.json"
+	// formatVersion は DD-INDEX-001 の索引形式バージョンを表す。
+	// 形式が変わった場合は値を上げ、古い索引を破棄して再構築させる。
+	formatVersion = 1
+	// removalRetention は DD-INDEX-001 の削除記録の保持期間を表す。
+	// 変更差分の取得は定期的な再読込を想定するため、それより十分長い期間だけ残せばよい。
+	removalRetention = 7 * 24 * time.Hour
+	// maxRemovals は DD-INDEX-001 の削除記録の上限件数を表す。
+	maxRemovals = 1000
+)
+
+var (
+	writeFile = atomicwrite.WriteFile
+	now       = time.Now
+)
+
+// Entry は DD-INDEX-001 の課題1件分の索引情報を表す。
+type Entry struct {
+	FileName        string `json:"file_name"`
+	IssueID         string `json:"issue_id"`
+	Title           string `json:"title"`
+	Status          string `json:"status"`
+	Priority        string `json:"priority"`
+	OriginCompany   string `json:"origin_company"`
+	UpdatedAt       string `json:"updated_at"`
+	DueDate         string `json:"due_date"`
+	IsSchemaInvalid bool   `json:"is_schema_invalid"`
+	ModTime         int64  `json:"mtime"`
+	SizeBytes       int64  `json:"size_bytes"`
+}
+
+// Removal は DD-INDEX-001 の削除を検知した記録を表す。FileName が空の場合はカテゴリ全体の削除を表す。
+// RemovedAt は削除そのものではなく検知した時刻 (UnixNano) のため、実際の削除より遅れることがある。
+type Removal struct {
+	Category  string `json:"category"`
+	FileName  string `json:"file_name,omitempty"`
+	RemovedAt int64  `json:"removed_at"`
+}
+
+// document は DD-INDEX-001 の索引ファイル全体を表す。
+type document struct {
+	FormatVersion int             `json:"format_version"`
+	Categories    []categoryIndex `json:"categories"`
+	Removed       []Removal       `json:"removed,omitempty"`
+}
+
+// snapshot は DD-INDEX-001 の読み込んだ索引の内容を表す。
+type snapshot struct {
+	categories map[string][]Entry
+	removed    []Removal
+}
+
+// categoryIndex は DD-INDEX-001 のカテゴリ単位の索引を表す。
+// キー順を固定して出力するため、マップではなく名前付きの配列要素として保存する。
+type categoryIndex struct {
+	Name    string  `json:"name"`
+	Entries []Entry `json:"entries"`
+}
+
+// LoadFunc は索引に無い、または古くなった課題JSONを読み込んで要約を返す。
+// ModTime・SizeBytes・FileName は索引側で設定するため、呼び出し側は設定しなくてよい。
+// 複数ゴルーチンから同時に呼ばれるため、スレッドセーフである必要がある。
+type LoadFunc func(path string) (Entry, error)
+
+// staleFile は再読込が必要な課題JSONを表す。
+type staleFile struct {
+	name     string
+	info     os.FileInfo
+	hadCache bool
+}
+
+// Index は DD-INDEX-001 のプロジェクト単位の課題索引を表す。
+type Index struct {
+	root        string
+	concurrency int
+}
+
+// Open は DD-INDEX-001 の索引をプロジェクトルートに対して生成する。
+// 索引ファイルが無い場合も生成でき、初回の参照時に構築される。
+func Open(root string) *Index {
+	return &Index{root: root}
+}
+
+// WithConcurrency は DD-SCAN-001 の再読込の並列度を設定する。0 以下は既定値を用いる。
+func (x *Index) WithConcurrency(concurrency int) *Index {
+	x.concurrency = concurrency
+	return x
+}
+
+// Category は DD-INDEX-001 のカテゴリ単位の索引参照を行う。
+// 目的: 変更の無い課題JSONは索引の要約を再利用し、一覧取得時の読み込みと検証を省く。
+// 入力: categoryPath はカテゴリパス、category はカテゴリ名、load は再読込関数。
+// 出力: ファイル名順の索引エントリとエラー。
+// エラー: カテゴリディレクトリの読み取り失敗時に返す。索引の破損や保存失敗はエラーにしない。
+// 副作用: 変更を検知した場合は index.json を更新する。
+// 並行性: 同時更新では後勝ちとなるが、次回参照時の鮮度判定で自己修復する。再読込は上限付きで並列に行う。
+// 不変条件: 返却するエントリはカテゴリ直下に現存する課題JSONのみで、load が失敗したものは含まない。
+// 関連DD: DD-INDEX-001, DD-LOAD-003
+func (x *Index) Category(categoryPath, category string, load LoadFunc) ([]Entry, error) {
+	dirEntries, err := os.ReadDir(categoryPath)
+	if err != nil {
+		return nil, fmt.Errorf("read category: %w", err)
+	}
+
+	state := x.load()
+	cached := make(map[string]Entry, len(state.categories[category]))
+	for _, entry := range state.categories[category] {
+		cached[entry.FileName] = entry
+	}
+
+	// 課題の無いカテゴリも索引に載せ、外部で削除された場合に削除として記録できるようにする。
+	_, indexed := state.categories[category]
+	changed := !indexed
+	entries := make([]Entry, 0, len(dirEntries))
+	var stale []staleFile
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if dirEntry.IsDir() || !issue.IsIssueFileName(name) {
+			continue
+		}
+		info, infoErr := dirEntry.Info()
+		if infoErr != nil {
+			continue
+		}
+		// mtime とサイズが一致する場合のみ再利用し、外部編集や同期ツールによる更新を取りこぼさない。
+		if entry, ok := cached[name]; ok && entry.ModTime == info.ModTime().UnixNano() && entry.SizeBytes == info.Size() {
+			entries = append(entries, entry)
+			delete(cached, name)
+			continue
+		}
+		_, hadCache := cached[name]
+		delete(cached, name)
+		stale = append(stale, staleFile{name: name, info: info, hadCache: hadCache})
+	}
+
+	loaded := make([]Entry, len(stale))
+	loadErrs := make([]error, len(stale))
+	workerpool.Run(x.concurrency, len(stale), func(i int) {
+		loaded[i], loadErrs[i] = load(filepath.Join(categoryPath, stale[i].name))
+	})
+	for i, file := range stale {
+		if loadErrs[i] != nil {
+			// 読めない課題は索引に載せないため、既存エントリが無ければ索引の更新も不要。
+			changed = changed || file.hadCache
+			continue
+		}
+		changed = true
+		entry := loaded[i]
+		entry.FileName = file.name
+		entry.ModTime = file.info.ModTime().UnixNano()
+		entry.SizeBytes = file.info.Size()
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].FileName < entries[j].FileName })
+	// 索引にだけ残っているエントリは削除済みの課題のため破棄し、変更差分の取得向けに削除を記録する。
+	if len(cached) > 0 {
+		changed = true
+		removedAt := now().UnixNano()
+		for name := range cached {
+			state.removed = append(state.removed, Removal{Category: category, FileName: name, RemovedAt: removedAt})
+		}
+	}
+
+	if changed {
+		state.categories[category] = entries
+		// 索引の保存に失敗しても一覧結果は正しいため、次回の再構築に委ねる。
+		_ = x.save(state)
+	}
+	return entries, nil
+}
+
+// Put は DD-INDEX-001 の課題保存直後の索引更新を行う。
+// 目的: 書き込んだ課題の要約を索引へ反映し、次回の一覧取得で再読込を不要にする。
+// 入力: category はカテゴリ名、path は保存済みの課題JSONパス、entry は要約。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: 課題JSONの stat 失敗や索引の保存失敗時に返す。
+// 副作用: index.json を更新する。
+// 並行性: 同時更新では後勝ちとなるが、次回参照時の鮮度判定で自己修復する。
+// 不変条件: エントリの mtime とサイズは保存後の課題JSONに一致する。
+// 関連DD: DD-INDEX-001, DD-PERSIST-002
+func (x *Index) Put(category, path string, entry Entry) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("stat issue: %w", err)
+	}
+	entry.FileName = filepath.Base(path)
+	entry.ModTime = info.ModTime().UnixNano()
+	entry.SizeBytes = info.Size()
+
+	state := x.load()
+	entries := state.categories[category]
+	replaced := false
+	for i := range entries {
+		if entries[i].FileName == entry.FileName {
+			entries[i] = entry
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		entries = append(entries, entry)
+		sort.Slice(entries, func(i, j int) bool { return entries[i].FileName < entries[j].FileName })
+	}
+	state.categories[category] = entries
+	return x.save(state)
+}
+
+// DropCategory は DD-INDEX-001 のカテゴリ単位の索引破棄を行う。
+// カテゴリ名変更や削除の後に呼び出し、旧名のエントリが残り続けないようにする。
+// 一覧を一度も取得していないカテゴリでも変更差分に現れるよう、削除は常に記録する。
+func (x *Index) DropCategory(category string) error {
+	state := x.load()
+	delete(state.categories, category)
+	state.removed = append(state.removed, Removal{Category: category, RemovedAt: now().UnixNano()})
+	return x.save(state)
+}
+
+// Reset は DD-INDEX-001 のカテゴリ単位の索引の再構築準備を行う。
+// DropCategory と異なり削除として記録せず、次回の参照でカテゴリ内の全課題を読み直させる。
+func (x *Index) Reset(category string) error {
+	state := x.load()
+	if _, ok := state.categories[category]; !ok {
+		return nil
+	}
+	state.categories[category] = []Entry{}
+	return x.save(state)
+}
+
+// Categories は DD-INDEX-001 の索引に記録されているカテゴリ名を名前順で返す。
+func (x *Index) Categories() []string {
+	state := x.load()
+	names := make([]string, 0, len(state.categories))
+	for name := range state.categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RemovedSince は DD-INDEX-001 の since (UnixNano) より後に検知した削除の記録を検知順で返す。
+func (x *Index) RemovedSince(since int64) []Removal {
+	var removals []Removal
+	for _, removal := range x.load().removed {
+		if removal.RemovedAt > since {
+			removals = append(removals, removal)
+		}
+	}
+	return removals
+}
+
+// load は DD-INDEX-001 の索引ファイルを読み込む。
+// 不在・破損・形式違いの索引は空として扱い、参照時に再構築させる。
+func (x *Index) load() snapshot {
+	state := snapshot{categories: make(map[string][]Entry)}
+	// #nosec G304 -- プロジェクトルート配下の固定ファイル名のみを読む。
+	data, err := os.ReadFile(filepath.Join(projectmeta.Dir(x.root), fileName))
+	if err != nil {
+		return state
+	}
+	var doc document
+	if unmarshalErr := json.Unmarshal(data, &doc); unmarshalErr != nil {
+		return state
+	}
+	if doc.FormatVersion != formatVersion {
+		return state
+	}
+	for _, category := range doc.Categories {
+		state.categories[category.Name] = category.Entries
+	}
+	state.removed = doc.Removed
+	return state
+}
+
+// save は DD-INDEX-001 の索引ファイルを atomic write で保存する。
+func (x *Index) save(state snapshot) error {
+	dir := projectmeta.Dir(x.root)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("create project meta dir: %w", err)
+	}
+	doc := document{
+		FormatVersion: formatVersion,
+		Categories:    make([]categoryIndex, 0, len(state.categories)),
+		Removed:       pruneRemovals(state.removed),
+	}
+	for name, entries := range state.categories {
+		if entries == nil {
+			entries = []Entry{}
+		}
+		doc.Categories = append(doc.Categories, categoryIndex{Name: name, Entries: entries})
+	}
+	sort.Slice(doc.Categories, func(i, j int) bool { return doc.Categories[i].Name < doc.Categories[j].Name })
+	data, err := jsonfmt.MarshalIssueIndex(doc)
+	if err != nil {
+		return fmt.Errorf("marshal issue index: %w", err)
+	}
+	if writeErr := writeFile(filepath.Join(dir, fileName), data); writeErr != nil {
+		return fmt.Errorf("write issue index: %w", writeErr)
+	}
+	return nil
+}
+
+// pruneRemovals は DD-INDEX-001 の保持期間を過ぎた削除記録を除き、上限件数を超える場合は古いものから捨てる。
+func pruneRemovals(removals []Removal) []Removal {
+	threshold := now().Add(-removalRetention).UnixNano()
+	kept := make([]Removal, 0, len(removals))
+	for _, removal := range removals {
+		if removal.RemovedAt >= threshold {
+			kept = append(kept, removal)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].RemovedAt < kept[j].RemovedAt })
+	if len(kept) > maxRemovals {
+		kept = kept[len(kept)-maxRemovals:]
+	}
+	return kept
+}
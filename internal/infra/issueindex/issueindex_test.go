@@ -0,0 +1,217 @@
+// issueindex_test.go は課題索引の鮮度判定と更新のテストを行い、課題JSONの検証は扱わない。
+package issueindex
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeIssueFile はテスト用の課題JSONを書き込み、mtime を指定時刻に揃える。
+func writeIssueFile(t *testing.T, path, body string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+}
+
+func TestCategory_ReusesFreshEntriesAndReloadsStale(t *testing.T) {
+	// 変更の無い課題は再読込せず、mtime が変わった課題と新規課題のみ読み込み、削除済みは除外することを確認する。
+	root := t.TempDir()
+	categoryPath := filepath.Join(root, "cat")
+	if err := os.MkdirAll(categoryPath, 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	writeIssueFile(t, filepath.Join(categoryPath, "a.json"), "a", base)
+	writeIssueFile(t, filepath.Join(categoryPath, "b.json"), "b", base)
+	writeIssueFile(t, filepath.Join(categoryPath, ".category.json"), "{}", base)
+
+	loads := map[string]int{}
+	load := func(path string) (Entry, error) {
+		name := filepath.Base(path)
+		loads[name]++
+		return Entry{IssueID: name, Title: "v" + string(rune('0'+loads[name]))}, nil
+	}
+
+	index := Open(root)
+	first, err := index.Category(categoryPath, "cat", load)
+	if err != nil {
+		t.Fatalf("Category error: %v", err)
+	}
+	if len(first) != 2 || loads["a.json"] != 1 || loads["b.json"] != 1 {
+		t.Fatalf("unexpected first pass: %+v loads=%v", first, loads)
+	}
+
+	writeIssueFile(t, filepath.Join(categoryPath, "a.json"), "a2", base.Add(time.Minute))
+	if err := os.Remove(filepath.Join(categoryPath, "b.json")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	writeIssueFile(t, filepath.Join(categoryPath, "c.json"), "c", base)
+
+	second, err := Open(root).Category(categoryPath, "cat", load)
+	if err != nil {
+		t.Fatalf("Category error: %v", err)
+	}
+	if len(second) != 2 || second[0].FileName != "a.json" || second[1].FileName != "c.json" {
+		t.Fatalf("unexpected second pass: %+v", second)
+	}
+	if loads["a.json"] != 2 || loads["c.json"] != 1 || loads["b.json"] != 1 {
+		t.Fatalf("unexpected loads: %v", loads)
+	}
+
+	third, err := Open(root).Category(categoryPath, "cat", load)
+	if err != nil {
+		t.Fatalf("Category error: %v", err)
+	}
+	if len(third) != 2 || loads["a.json"] != 2 || loads["c.json"] != 1 {
+		t.Fatalf("expected cached entries to be reused, loads=%v", loads)
+	}
+}
+
+func TestCategory_SkipsUnreadableAndRebuildsCorruptIndex(t *testing.T) {
+	// 読めない課題は索引に載せず、破損した索引ファイルは空として再構築することを確認する。
+	root := t.TempDir()
+	categoryPath := filepath.Join(root, "cat")
+	if err := os.MkdirAll(filepath.Join(root, ".ratta"), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.MkdirAll(categoryPath, 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".ratta", "index.json"), []byte("{"), 0o600); err != nil {
+		t.Fatalf("write index: %v", err)
+	}
+	writeIssueFile(t, filepath.Join(categoryPath, "ok.json"), "ok", time.Now())
+	writeIssueFile(t, filepath.Join(categoryPath, "broken.json"), "{", time.Now())
+
+	entries, err := Open(root).Category(categoryPath, "cat", func(path string) (Entry, error) {
+		if filepath.Base(path) == "broken.json" {
+			return Entry{}, errors.New("parse error")
+		}
+		return Entry{IssueID: "ok"}, nil
+	})
+	if err != nil {
+		t.Fatalf("Category error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].IssueID != "ok" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestPutAndDropCategory(t *testing.T) {
+	// 保存直後の Put で索引が更新され、DropCategory でカテゴリ単位に破棄されることを確認する。
+	root := t.TempDir()
+	categoryPath := filepath.Join(root, "cat")
+	if err := os.MkdirAll(categoryPath, 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	path := filepath.Join(categoryPath, "a.json")
+	writeIssueFile(t, path, "a", time.Now())
+	index := Open(root)
+	if err := index.Put("cat", path, Entry{IssueID: "a", Title: "title"}); err != nil {
+		t.Fatalf("Put error: %v", err)
+	}
+	entries, err := index.Category(categoryPath, "cat", func(string) (Entry, error) {
+		t.Fatal("expected entry from Put to be reused")
+		return Entry{}, nil
+	})
+	if err != nil {
+		t.Fatalf("Category error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Title != "title" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if dropErr := index.DropCategory("cat"); dropErr != nil {
+		t.Fatalf("DropCategory error: %v", dropErr)
+	}
+	if _, ok := index.load().categories["cat"]; ok {
+		t.Fatal("expected category to be dropped")
+	}
+}
+
+func TestReset_ReloadsEveryIssueWithoutRecordingRemoval(t *testing.T) {
+	// Reset 後の参照では mtime が同じ課題も読み直し、カテゴリの削除としては記録しないことを確認する。
+	root := t.TempDir()
+	categoryPath := filepath.Join(root, "cat")
+	if err := os.MkdirAll(categoryPath, 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeIssueFile(t, filepath.Join(categoryPath, "a.json"), "a", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	loads := 0
+	load := func(string) (Entry, error) {
+		loads++
+		return Entry{IssueID: "a"}, nil
+	}
+	index := Open(root)
+	if _, err := index.Category(categoryPath, "cat", load); err != nil {
+		t.Fatalf("Category error: %v", err)
+	}
+	if err := index.Reset("cat"); err != nil {
+		t.Fatalf("Reset error: %v", err)
+	}
+	entries, err := index.Category(categoryPath, "cat", load)
+	if err != nil {
+		t.Fatalf("Category error: %v", err)
+	}
+	if len(entries) != 1 || loads != 2 {
+		t.Fatalf("expected issue to be reloaded, entries=%+v loads=%d", entries, loads)
+	}
+	if removed := index.RemovedSince(0); len(removed) != 0 {
+		t.Fatalf("expected no removal record, got %+v", removed)
+	}
+}
+
+func TestRemovedSince_RecordsAndPrunesRemovals(t *testing.T) {
+	// 索引から外れた課題とカテゴリの削除が記録され、指定時刻以降のみ返り、保持期間を過ぎた記録は破棄されることを確認する。
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	previousNow := now
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = previousNow })
+
+	root := t.TempDir()
+	categoryPath := filepath.Join(root, "cat")
+	if err := os.MkdirAll(categoryPath, 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeIssueFile(t, filepath.Join(categoryPath, "a.json"), "a", current)
+	load := func(path string) (Entry, error) { return Entry{IssueID: filepath.Base(path)}, nil }
+	index := Open(root)
+	if _, err := index.Category(categoryPath, "cat", load); err != nil {
+		t.Fatalf("Category error: %v", err)
+	}
+
+	current = current.Add(time.Hour)
+	if err := os.Remove(filepath.Join(categoryPath, "a.json")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if _, err := index.Category(categoryPath, "cat", load); err != nil {
+		t.Fatalf("Category error: %v", err)
+	}
+	current = current.Add(time.Hour)
+	if err := index.DropCategory("other"); err != nil {
+		t.Fatalf("DropCategory error: %v", err)
+	}
+
+	all := index.RemovedSince(0)
+	if len(all) != 2 || all[0].FileName != "a.json" || all[1].Category != "other" || all[1].FileName != "" {
+		t.Fatalf("unexpected removals: %+v", all)
+	}
+	recent := index.RemovedSince(current.Add(-time.Minute).UnixNano())
+	if len(recent) != 1 || recent[0].Category != "other" {
+		t.Fatalf("unexpected recent removals: %+v", recent)
+	}
+
+	current = current.Add(removalRetention + time.Minute)
+	if err := index.DropCategory("last"); err != nil {
+		t.Fatalf("DropCategory error: %v", err)
+	}
+	if kept := index.RemovedSince(0); len(kept) != 1 || kept[0].Category != "last" {
+		t.Fatalf("expected old removals to be pruned, got %+v", kept)
+	}
+}
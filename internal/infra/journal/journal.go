@@ -0,0 +1,400 @@
+// Package journal は変更操作の前後のファイル内容の記録と、直前の操作の取り消しを担い、操作の内容の検証や UI 通知は扱わない。
+// 記録は <project_root>/.ratta/journal/ に置き、ファイル内容は SHA-256 をキーとした objects/ 配下に重複なく保存する。
+package journal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/infra/atomicwrite"
+	"ratta/internal/infra/projectmeta"
+)
+
+const (
+	// formatVersion は DD-JOURNAL-001 の記録の形式バージョンを表す。
+	formatVersion = 1
+	// maxEntries は DD-JOURNAL-001 の保持する記録の件数を表す。古い記録から破棄し、共有フォルダの肥大化を防ぐ。
+	maxEntries = 100
+	// entriesDirName と objectsDirName は DD-JOURNAL-001 の記録とファイル内容の保存先を表す。
+	entriesDirName = "entries"
+	objectsDirName = "objects"
+)
+
+var (
+	// ErrNothingToUndo は DD-JOURNAL-001 の取り消せる記録がないことを表す。
+	ErrNothingToUndo = apperr.New(apperr.ErrNotFound, "undo entry not found")
+	// ErrConflict は DD-JOURNAL-001 の記録後にファイルが変更され、取り消すと変更を失うことを表す。
+	ErrConflict = apperr.New(apperr.ErrConflict, "undo conflict: files changed after the operation")
+)
+
+// now は DD-JOURNAL-001 の記録時刻をテストで固定するための差し替え点。
+var now = time.Now
+
+// FileChange は DD-JOURNAL-001 のファイル1件の操作前後の状態を表す。
+// ハッシュは内容の参照を表し、存在しなかった場合は空とする。Path はプロジェクトルートからの "/" 区切りの相対パスとする。
+type FileChange struct {
+	Path   string `json:"path"`
+	Before string `json:"before_sha256"`
+	After  string `json:"after_sha256"`
+}
+
+// Entry は DD-JOURNAL-001 の操作1件の記録を表す。
+// Owner は記録したアプリのインスタンスを表し、共有フォルダで他の利用者の操作を取り消さないために用いる。
+type Entry struct {
+	FormatVersion int          `json:"format_version"`
+	ID            string       `json:"id"`
+	Owner         string       `json:"owner"`
+	Operation     string       `json:"operation"`
+	Category      string       `json:"category"`
+	IssueID       string       `json:"issue_id"`
+	RecordedAt    string       `json:"recorded_at"`
+	Files         []FileChange `json:"files"`
+}
+
+// Journal は DD-JOURNAL-001 のプロジェクトの操作記録を表す。
+type Journal struct {
+	root  string
+	dir   string
+	owner string
+}
+
+// Open は DD-JOURNAL-001 のプロジェクトの操作記録を開く。owner は記録・取り消しの対象とするインスタンスの識別子。
+func Open(root, owner string) *Journal {
+	return &Journal{root: root, dir: filepath.Join(projectmeta.Dir(root), "journal"), owner: owner}
+}
+
+// Pending は DD-JOURNAL-001 の記録中の操作を表す。Begin で操作前の内容を読み、Commit で操作後の内容と合わせて記録する。
+type Pending struct {
+	journal   *Journal
+	entry     Entry
+	paths     []string
+	before    map[string][]byte
+	beforeHit map[string]bool
+}
+
+// Begin は DD-JOURNAL-001 の操作前の状態の読み取りを行う。
+// 目的: 操作で変更されうるファイルの内容を、変更前に保持する。
+// 入力: operation は操作名、category と issueID は対象の課題、paths はプロジェクトルートからの相対パス。
+// 出力: Pending とエラー。
+// エラー: 存在するファイルの読み取りに失敗した場合に返す。
+// 副作用: ファイルを読み取る。
+// 並行性: 対象ファイルへの書き込みとの排他は呼び出し側で行う。
+// 不変条件: 存在しないファイルは操作前に存在しなかったものとして扱う。
+// 関連DD: DD-JOURNAL-001
+func (j *Journal) Begin(operation, category, issueID string, paths ...string) (*Pending, error) {
+	pending := &Pending{
+		journal:   j,
+		entry:     Entry{FormatVersion: formatVersion, Owner: j.owner, Operation: operation, Category: category, IssueID: issueID},
+		before:    make(map[string][]byte),
+		beforeHit: make(map[string]bool),
+	}
+	for _, path := range paths {
+		if err := pending.capture(path); err != nil {
+			return nil, err
+		}
+	}
+	return pending, nil
+}
+
+// capture は DD-JOURNAL-001 の操作前の内容を1件読み取る。
+func (p *Pending) capture(path string) error {
+	path = filepath.ToSlash(path)
+	if _, seen := p.before[path]; seen {
+		return nil
+	}
+	data, exists, err := p.journal.read(path)
+	if err != nil {
+		return err
+	}
+	p.paths = append(p.paths, path)
+	p.before[path] = data
+	p.beforeHit[path] = exists
+	return nil
+}
+
+// Created は DD-JOURNAL-001 の操作で新たに作成したファイルを加える。課題IDの採番などで操作前にパスが分からない場合に用いる。
+func (p *Pending) Created(paths ...string) {
+	for _, path := range paths {
+		path = filepath.ToSlash(path)
+		if _, seen := p.before[path]; seen {
+			continue
+		}
+		p.paths = append(p.paths, path)
+		p.before[path] = nil
+		p.beforeHit[path] = false
+	}
+}
+
+// Commit は DD-JOURNAL-001 の操作後の状態を読み取り、記録を保存する。
+// 目的: 取り消しに必要な操作前の内容と、取り消し時の衝突検出に用いる操作後の内容を保存する。
+// 入力: issueID は操作対象の課題ID。Begin 時に未確定だった場合に設定し、空の場合は Begin の値を用いる。
+// 出力: 保存した Entry とエラー。
+// エラー: ファイルの読み取り、記録の保存に失敗した場合に返す。
+// 副作用: objects/ と entries/ へ書き込み、保持件数を超えた古い記録と参照されない内容を削除する。
+// 並行性: 同じプロジェクトへの複数インスタンスからの記録は、ファイル名の時刻と PID で区別する。
+// 不変条件: 内容は記録より先に保存し、記録が参照する内容が欠けないようにする。
+// 関連DD: DD-JOURNAL-001
+func (p *Pending) Commit(issueID string) (Entry, error) {
+	entry := p.entry
+	if issueID != "" {
+		entry.IssueID = issueID
+	}
+	recordedAt := now()
+	entry.ID = fmt.Sprintf("%020d-%d", recordedAt.UnixNano(), os.Getpid())
+	entry.RecordedAt = recordedAt.UTC().Format(time.RFC3339)
+	for _, path := range p.paths {
+		change := FileChange{Path: path}
+		if p.beforeHit[path] {
+			hash, err := p.journal.storeObject(p.before[path])
+			if err != nil {
+				return Entry{}, err
+			}
+			change.Before = hash
+		}
+		after, exists, err := p.journal.read(path)
+		if err != nil {
+			return Entry{}, err
+		}
+		if exists {
+			change.After = hashOf(after)
+		}
+		if change.Before == change.After {
+			continue
+		}
+		entry.Files = append(entry.Files, change)
+	}
+	if len(entry.Files) == 0 {
+		return entry, nil
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return Entry{}, fmt.Errorf("marshal journal entry: %w", err)
+	}
+	entriesDir := filepath.Join(p.journal.dir, entriesDirName)
+	if err := os.MkdirAll(entriesDir, 0o750); err != nil {
+		return Entry{}, fmt.Errorf("create journal dir: %w", err)
+	}
+	if err := atomicwrite.WriteFile(filepath.Join(entriesDir, entry.ID+".json"), append(data, '\n')); err != nil {
+		return Entry{}, fmt.Errorf("write journal entry: %w", err)
+	}
+	// 記録の破棄に失敗しても操作自体は完了しているため、次回の記録時に再試行する。
+	_ = p.journal.prune()
+	return entry, nil
+}
+
+// Last は DD-JOURNAL-001 のこのインスタンスが記録した最新の操作を返す。記録がない場合は ErrNothingToUndo を返す。
+func (j *Journal) Last() (Entry, error) {
+	entries, err := j.entries()
+	if err != nil {
+		return Entry{}, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Owner == j.owner {
+			return entries[i], nil
+		}
+	}
+	return Entry{}, ErrNothingToUndo
+}
+
+// Before は DD-JOURNAL-001 の entry が記録したファイル path の操作前の内容を返す。
+// 目的: 取り消しの前に、呼び出し側が戻す先の内容を課題の規則で検査できるようにする。
+// 入力: entry は Last で取得した記録、path はプロジェクトルートからの相対パス。
+// 出力: 操作前の内容と、操作前に存在したか。記録に含まれないパス、操作で作成されたファイルは exists=false とする。
+// エラー: 記録した内容を読み取れない場合に返す。
+// 副作用: 記録した内容を読み取るのみ。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: entry と記録を変更しない。
+// 関連DD: DD-JOURNAL-001
+func (j *Journal) Before(entry Entry, path string) ([]byte, bool, error) {
+	path = filepath.ToSlash(path)
+	for _, change := range entry.Files {
+		if change.Path != path || change.Before == "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(j.dir, objectsDirName, change.Before))
+		if err != nil {
+			return nil, false, fmt.Errorf("read journal object: %w", err)
+		}
+		return data, true, nil
+	}
+	return nil, false, nil
+}
+
+// Undo は DD-JOURNAL-001 の操作の取り消しを行う。
+// 目的: 誤った状態変更やコメントの追加を、操作前の内容へ戻す。
+// 入力: id は取り消す記録の ID。Last で取得した最新の記録であること。
+// 出力: 取り消した Entry とエラー。
+// エラー: 記録が最新でない場合は ErrNothingToUndo、記録後にファイルが変更されている場合は ErrConflict、
+// 復元・削除に失敗した場合に返す。
+// 副作用: 記録したファイルを操作前の内容へ書き戻し、操作で作成されたファイルを削除し、記録を削除する。
+// 並行性: 対象ファイルへの書き込みとの排他は呼び出し側で行う。
+// 不変条件: 全ファイルが操作後の状態のままであることを確認してから書き戻す。各ファイルは atomic write で置き換える。
+// 関連DD: DD-JOURNAL-001, DD-PERSIST-002
+func (j *Journal) Undo(id string) (Entry, error) {
+	entry, err := j.Last()
+	if err != nil {
+		return Entry{}, err
+	}
+	if entry.ID != id {
+		return Entry{}, ErrNothingToUndo
+	}
+	for _, change := range entry.Files {
+		current, exists, readErr := j.read(change.Path)
+		if readErr != nil {
+			return Entry{}, readErr
+		}
+		currentHash := ""
+		if exists {
+			currentHash = hashOf(current)
+		}
+		if currentHash != change.After {
+			return Entry{}, fmt.Errorf("%w: %s", ErrConflict, change.Path)
+		}
+	}
+	restores := make(map[string][]byte, len(entry.Files))
+	for _, change := range entry.Files {
+		if change.Before == "" {
+			continue
+		}
+		data, readErr := os.ReadFile(filepath.Join(j.dir, objectsDirName, change.Before))
+		if readErr != nil {
+			return Entry{}, fmt.Errorf("read journal object: %w", readErr)
+		}
+		restores[change.Path] = data
+	}
+
+	// 記録順 (課題JSON を先頭) に戻し、途中で失敗しても課題JSONが削除済みの添付を参照し続けないようにする。
+	for _, change := range entry.Files {
+		target := filepath.Join(j.root, filepath.FromSlash(change.Path))
+		if change.Before == "" {
+			if removeErr := os.Remove(target); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+				return Entry{}, fmt.Errorf("remove file: %w", removeErr)
+			}
+			continue
+		}
+		if mkdirErr := os.MkdirAll(filepath.Dir(target), 0o750); mkdirErr != nil {
+			return Entry{}, fmt.Errorf("create dir: %w", mkdirErr)
+		}
+		if writeErr := atomicwrite.WriteFile(target, restores[change.Path]); writeErr != nil {
+			return Entry{}, fmt.Errorf("restore file: %w", writeErr)
+		}
+	}
+	if removeErr := os.Remove(filepath.Join(j.dir, entriesDirName, entry.ID+".json")); removeErr != nil {
+		return Entry{}, fmt.Errorf("remove journal entry: %w", removeErr)
+	}
+	_ = j.prune()
+	return entry, nil
+}
+
+// read は DD-JOURNAL-001 の相対パスのファイルを読む。存在しない場合は exists=false を返す。
+func (j *Journal) read(path string) ([]byte, bool, error) {
+	// #nosec G304 -- プロジェクトルート配下の記録対象ファイルのみを読むため安全。
+	data, err := os.ReadFile(filepath.Join(j.root, filepath.FromSlash(path)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("read journaled file: %w", err)
+	}
+	return data, true, nil
+}
+
+// storeObject は DD-JOURNAL-001 のファイル内容を保存し、参照となるハッシュを返す。同じ内容は1度だけ保存する。
+func (j *Journal) storeObject(data []byte) (string, error) {
+	hash := hashOf(data)
+	dir := filepath.Join(j.dir, objectsDirName)
+	path := filepath.Join(dir, hash)
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", fmt.Errorf("create journal objects dir: %w", err)
+	}
+	if err := atomicwrite.WriteFile(path, data); err != nil {
+		return "", fmt.Errorf("write journal object: %w", err)
+	}
+	return hash, nil
+}
+
+// entries は DD-JOURNAL-001 の記録を古い順に読み込む。読めない記録は取り消しの対象にできないため除く。
+func (j *Journal) entries() ([]Entry, error) {
+	dirEntries, err := os.ReadDir(filepath.Join(j.dir, entriesDirName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read journal dir: %w", err)
+	}
+	names := make([]string, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.IsDir() && strings.HasSuffix(dirEntry.Name(), ".json") {
+			names = append(names, dirEntry.Name())
+		}
+	}
+	sort.Strings(names)
+	entries := make([]Entry, 0, len(names))
+	for _, name := range names {
+		// #nosec G304 -- 記録ディレクトリ配下のファイルのみを読むため安全。
+		data, readErr := os.ReadFile(filepath.Join(j.dir, entriesDirName, name))
+		if readErr != nil {
+			continue
+		}
+		var entry Entry
+		if json.Unmarshal(data, &entry) != nil || entry.FormatVersion != formatVersion {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// prune は DD-JOURNAL-001 の保持件数を超えた古い記録と、どの記録からも参照されない内容を削除する。
+func (j *Journal) prune() error {
+	entries, err := j.entries()
+	if err != nil {
+		return err
+	}
+	for len(entries) > maxEntries {
+		if removeErr := os.Remove(filepath.Join(j.dir, entriesDirName, entries[0].ID+".json")); removeErr != nil {
+			return fmt.Errorf("remove journal entry: %w", removeErr)
+		}
+		entries = entries[1:]
+	}
+	referenced := make(map[string]bool)
+	for _, entry := range entries {
+		for _, change := range entry.Files {
+			referenced[change.Before] = true
+		}
+	}
+	objects, err := os.ReadDir(filepath.Join(j.dir, objectsDirName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("read journal objects dir: %w", err)
+	}
+	for _, object := range objects {
+		// 書き込み途中の一時ファイルは他のインスタンスのものである可能性があるため残す。
+		if object.IsDir() || referenced[object.Name()] || strings.Contains(object.Name(), ".tmp.") {
+			continue
+		}
+		_ = os.Remove(filepath.Join(j.dir, objectsDirName, object.Name()))
+	}
+	return nil
+}
+
+// hashOf は DD-JOURNAL-001 の内容の参照となる SHA-256 を返す。
+func hashOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
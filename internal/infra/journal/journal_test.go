@@ -0,0 +1,158 @@
+// journal_test.go は操作の記録と取り消し、記録の破棄のテストを行い、課題操作そのものは扱わない。
+package journal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeFile はテスト用のファイルをプロジェクトルートからの相対パスへ書き込む。
+func writeFile(t *testing.T, root, path, content string) {
+	t.Helper()
+	full := filepath.Join(root, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(full), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+// readFile はテスト用のファイルを読み、存在しない場合は空文字列を返す。
+func readFile(t *testing.T, root, path string) (string, bool) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(path)))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false
+	}
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return string(data), true
+}
+
+func TestUndo_RestoresUpdatedAndRemovesCreatedFiles(t *testing.T) {
+	// 更新したファイルは操作前の内容へ戻し、操作で作成したファイルは削除して記録を消すことを確認する。
+	root := t.TempDir()
+	writeFile(t, root, "cat/A.json", "before")
+	journal := Open(root, "me")
+
+	pending, err := journal.Begin("comment_added", "cat", "A", "cat/A.json")
+	if err != nil {
+		t.Fatalf("Begin error: %v", err)
+	}
+	writeFile(t, root, "cat/A.json", "after")
+	writeFile(t, root, "cat/A.files/x.txt", "attachment")
+	pending.Created("cat/A.files/x.txt")
+	entry, err := pending.Commit("")
+	if err != nil {
+		t.Fatalf("Commit error: %v", err)
+	}
+	if len(entry.Files) != 2 || entry.IssueID != "A" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+
+	last, err := journal.Last()
+	if err != nil || last.ID != entry.ID {
+		t.Fatalf("unexpected last: %+v %v", last, err)
+	}
+	// 取り消しの前に戻す先の内容を検査できるよう、更新したファイルは操作前の内容を、作成したファイルは存在しなかったことを返す。
+	if data, existed, beforeErr := journal.Before(last, "cat/A.json"); beforeErr != nil || !existed || string(data) != "before" {
+		t.Fatalf("unexpected before content: %q %v %v", data, existed, beforeErr)
+	}
+	if _, existed, beforeErr := journal.Before(last, "cat/A.files/x.txt"); beforeErr != nil || existed {
+		t.Fatalf("expected created file to have no before content: %v %v", existed, beforeErr)
+	}
+	undone, err := journal.Undo(last.ID)
+	if err != nil {
+		t.Fatalf("Undo error: %v", err)
+	}
+	if undone.Operation != "comment_added" {
+		t.Fatalf("unexpected undone: %+v", undone)
+	}
+	if content, _ := readFile(t, root, "cat/A.json"); content != "before" {
+		t.Fatalf("expected restored content, got %q", content)
+	}
+	if _, exists := readFile(t, root, "cat/A.files/x.txt"); exists {
+		t.Fatalf("expected created attachment to be removed")
+	}
+	if _, err := journal.Last(); !errors.Is(err, ErrNothingToUndo) {
+		t.Fatalf("expected nothing to undo, got %v", err)
+	}
+}
+
+func TestUndo_RejectsChangedFilesAndOtherOwners(t *testing.T) {
+	// 記録後に変更されたファイルは取り消さず、他のインスタンスの記録は取り消し対象にしないことを確認する。
+	root := t.TempDir()
+	writeFile(t, root, "cat/A.json", "before")
+	journal := Open(root, "me")
+	pending, err := journal.Begin("issue_updated", "cat", "A", "cat/A.json")
+	if err != nil {
+		t.Fatalf("Begin error: %v", err)
+	}
+	writeFile(t, root, "cat/A.json", "after")
+	entry, err := pending.Commit("")
+	if err != nil {
+		t.Fatalf("Commit error: %v", err)
+	}
+
+	if _, err := Open(root, "other").Last(); !errors.Is(err, ErrNothingToUndo) {
+		t.Fatalf("expected other owner to see nothing, got %v", err)
+	}
+	writeFile(t, root, "cat/A.json", "edited elsewhere")
+	if _, err := journal.Undo(entry.ID); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+	if content, _ := readFile(t, root, "cat/A.json"); content != "edited elsewhere" {
+		t.Fatalf("expected file untouched, got %q", content)
+	}
+}
+
+func TestCommit_SkipsUnchangedAndPrunesOldEntries(t *testing.T) {
+	// 内容が変わらない操作は記録せず、保持件数を超えた古い記録と参照されない内容を破棄することを確認する。
+	root := t.TempDir()
+	writeFile(t, root, "cat/A.json", "v0")
+	journal := Open(root, "me")
+	pending, err := journal.Begin("issue_updated", "cat", "A", "cat/A.json")
+	if err != nil {
+		t.Fatalf("Begin error: %v", err)
+	}
+	if entry, err := pending.Commit(""); err != nil || len(entry.Files) != 0 {
+		t.Fatalf("expected no-op commit, got %+v %v", entry, err)
+	}
+	if _, err := journal.Last(); !errors.Is(err, ErrNothingToUndo) {
+		t.Fatalf("expected nothing recorded, got %v", err)
+	}
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	original := now
+	t.Cleanup(func() { now = original })
+	for i := 1; i <= maxEntries+2; i++ {
+		now = func() time.Time { return base.Add(time.Duration(i) * time.Second) }
+		pending, err := journal.Begin("issue_updated", "cat", "A", "cat/A.json")
+		if err != nil {
+			t.Fatalf("Begin error: %v", err)
+		}
+		writeFile(t, root, "cat/A.json", "v"+time.Duration(i).String())
+		if _, err := pending.Commit(""); err != nil {
+			t.Fatalf("Commit error: %v", err)
+		}
+	}
+	entries, err := journal.entries()
+	if err != nil {
+		t.Fatalf("entries error: %v", err)
+	}
+	if len(entries) != maxEntries {
+		t.Fatalf("expected %d entries, got %d", maxEntries, len(entries))
+	}
+	objects, err := os.ReadDir(filepath.Join(root, ".ratta", "journal", objectsDirName))
+	if err != nil {
+		t.Fatalf("read objects: %v", err)
+	}
+	if len(objects) != maxEntries {
+		t.Fatalf("expected unreferenced objects to be pruned, got %d", len(objects))
+	}
+}
@@ -0,0 +1,492 @@
+// encode.go は DD-DATA-001 の canonical 出力を、値を JSON へ変換し直さずに1回の走査で書き出す処理を担い、
+// キー順の定義は扱わない。キー順の定義は jsonfmt.go が担う。
+// 型ごとの出力は encoding/json と同じ規則 (json タグ、omitempty、Marshaler) に従う。
+package jsonfmt
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var (
+	marshalerType     = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	numberType        = reflect.TypeFor[json.Number]()
+)
+
+// field は DD-DATA-001 の構造体のフィールドのうち JSON に出力するものを表す。
+type field struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+// structFields は DD-DATA-001 の構造体の型ごとの出力するフィールドの一覧を表す。
+type structFields struct {
+	fields []field
+	// direct は埋め込みフィールドなど encoding/json の規則を再現しない構造を含まない場合に true とする。
+	direct bool
+}
+
+// fieldCache は DD-DATA-001 の型ごとの structFields を保持する。
+var fieldCache sync.Map
+
+// marshalWithOrder は DD-DATA-001 の canonical 出力ルールに従って整形する。
+// 目的: 値を1回の走査で順序付きの JSON として出力し、汎用構造への変換を省く。
+// 入力: value はJSON化対象、order はキー順序定義。
+// 出力: 整形済みJSONバイト列とエラー。
+// エラー: JSON変換や整形処理に失敗した場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 出力の末尾に改行を付与する。出力は encoding/json で変換して読み直した値を整形した場合と一致する。
+// 関連DD: DD-DATA-001
+func marshalWithOrder(value any, order *keyOrder) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeValue(&buf, reflect.ValueOf(value), order, 0); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// writeValue は DD-DATA-001 の JSON ルールに従い値を出力する。
+// 目的: 値の型に応じて正しい表現で書き出す。
+// 入力: buf は出力先、value は対象値、order はキー順序定義、level はインデント階層。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: JSON変換に失敗した場合に返す。
+// 副作用: buf に書き込む。
+// 並行性: buf は呼び出し側で排他する。
+// 不変条件: 文字列は JSON エスケープ済みで出力する。Marshaler を実装する値はその出力を整形し直す。
+// 関連DD: DD-DATA-001
+func writeValue(buf *bytes.Buffer, value reflect.Value, order *keyOrder, level int) error {
+	if !value.IsValid() {
+		buf.WriteString("null")
+		return nil
+	}
+	if value.Type() == numberType || implements(value, textMarshalerType) && !implements(value, marshalerType) {
+		return writeScalar(buf, value)
+	}
+	if implements(value, marshalerType) {
+		return writeReparsed(buf, value, order, level)
+	}
+	switch value.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if value.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeValue(buf, value.Elem(), order, level)
+	case reflect.Struct:
+		cached := fieldsOf(value.Type())
+		if !cached.direct {
+			return writeReparsed(buf, value, order, level)
+		}
+		return writeStruct(buf, value, cached.fields, order, level)
+	case reflect.Map:
+		if value.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		if value.Type().Key().Kind() != reflect.String || value.Type().Key().Implements(textMarshalerType) {
+			return writeReparsed(buf, value, order, level)
+		}
+		return writeMap(buf, value, order, level)
+	case reflect.Slice:
+		if value.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return writeScalar(buf, value)
+		}
+		return writeArray(buf, value, order, level)
+	case reflect.Array:
+		return writeArray(buf, value, order, level)
+	default:
+		return writeScalar(buf, value)
+	}
+}
+
+// writeScalar は DD-DATA-001 の文字列・数値などの値を encoding/json の表記で出力する。
+// 目的: 課題JSONの大半を占める文字列・数値を、値ごとに json.Marshal を呼ばずに直接書き出す。
+// 入力: buf は出力先、value は対象値。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: NaN・無限大の浮動小数点数、数値として不正な json.Number、JSON に変換できない値の場合に返す。
+// 副作用: buf に書き込む。
+// 並行性: buf は呼び出し側で排他する。
+// 不変条件: 出力は json.Marshal の出力と一致する。[]byte・TextMarshaler など直接扱わない値は json.Marshal に委ねる。
+// 関連DD: DD-DATA-001
+func writeScalar(buf *bytes.Buffer, value reflect.Value) error {
+	if value.Type() == numberType {
+		return writeNumber(buf, value.String())
+	}
+	if !implements(value, textMarshalerType) {
+		switch value.Kind() {
+		case reflect.String:
+			writeString(buf, value.String())
+			return nil
+		case reflect.Bool:
+			buf.WriteString(strconv.FormatBool(value.Bool()))
+			return nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			buf.Write(strconv.AppendInt(buf.AvailableBuffer(), value.Int(), 10))
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			buf.Write(strconv.AppendUint(buf.AvailableBuffer(), value.Uint(), 10))
+			return nil
+		case reflect.Float32:
+			return writeFloat(buf, value.Float(), 32)
+		case reflect.Float64:
+			return writeFloat(buf, value.Float(), 64)
+		}
+	}
+	encoded, err := json.Marshal(value.Interface())
+	if err != nil {
+		return fmt.Errorf("marshal value: %w", err)
+	}
+	buf.Write(encoded)
+	return nil
+}
+
+// writeString は DD-DATA-001 の文字列を encoding/json と同じエスケープ (HTML の <>&、U+2028/U+2029、不正な UTF-8 を含む) で出力する。
+// 文字列の出力は失敗しないため、json.Marshal のエラーは無い。
+func writeString(buf *bytes.Buffer, text string) {
+	const hexDigits = "0123456789abcdef"
+	mark := buf.Len()
+	buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(text); {
+		if b := text[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			buf.WriteString(text[start:i])
+			switch b {
+			case '\\', '"':
+				buf.WriteByte('\\')
+				buf.WriteByte(b)
+			case '\b':
+				buf.WriteString(`\b`)
+			case '\f':
+				buf.WriteString(`\f`)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[b>>4])
+				buf.WriteByte(hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if r == utf8.RuneError && size == 1 {
+			// 不正な UTF-8 の表記は Go のバージョンで異なるため、この文字列のみ encoding/json に委ねる。
+			buf.Truncate(mark)
+			encoded, _ := json.Marshal(text)
+			buf.Write(encoded)
+			return
+		}
+		if r == '\u2028' || r == '\u2029' {
+			buf.WriteString(text[start:i])
+			buf.WriteString(`\u202`)
+			buf.WriteByte(hexDigits[r&0xF])
+			start = i + size
+		}
+		i += size
+	}
+	buf.WriteString(text[start:])
+	buf.WriteByte('"')
+}
+
+// writeNumber は DD-DATA-001 の json.Number を元の表記のまま出力する。空の場合は encoding/json と同じく 0 とする。
+// 2^53 を超える整数の精度を保つため、float64 へ変換しない。
+func writeNumber(buf *bytes.Buffer, number string) error {
+	if number == "" {
+		buf.WriteByte('0')
+		return nil
+	}
+	first, last := number[0], number[len(number)-1]
+	if first != '-' && (first < '0' || first > '9') || last < '0' || last > '9' || !json.Valid([]byte(number)) {
+		return fmt.Errorf("marshal value: invalid number literal %q", number)
+	}
+	buf.WriteString(number)
+	return nil
+}
+
+// writeFloat は DD-DATA-001 の浮動小数点数を encoding/json と同じ表記で出力する。
+// 絶対値が 1e-6 未満または 1e21 以上の場合は指数表記とし、指数の先頭の 0 を除く (1e-07 → 1e-7)。
+func writeFloat(buf *bytes.Buffer, f float64, bits int) error {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return fmt.Errorf("marshal value: unsupported value: %s", strconv.FormatFloat(f, 'g', -1, bits))
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	encoded := strconv.AppendFloat(buf.AvailableBuffer(), f, format, -1, bits)
+	if format == 'e' {
+		if n := len(encoded); n >= 4 && encoded[n-4] == 'e' && encoded[n-3] == '-' && encoded[n-2] == '0' {
+			encoded[n-2] = encoded[n-1]
+			encoded = encoded[:n-1]
+		}
+	}
+	buf.Write(encoded)
+	return nil
+}
+
+// writeReparsed は DD-DATA-001 の Marshaler の出力や埋め込みフィールドを持つ構造体を、
+// encoding/json で変換して読み直したうえで整形する。オブジェクト・配列以外はそのまま出力する。
+func writeReparsed(buf *bytes.Buffer, value reflect.Value, order *keyOrder, level int) error {
+	target := value.Interface()
+	if value.CanAddr() {
+		target = value.Addr().Interface()
+	}
+	encoded, err := json.Marshal(target)
+	if err != nil {
+		return fmt.Errorf("marshal value: %w", err)
+	}
+	if len(encoded) == 0 || encoded[0] != '{' && encoded[0] != '[' {
+		buf.Write(encoded)
+		return nil
+	}
+	// float64 を経由すると 2^53 を超える整数 (ナノ秒の mtime など) の精度が落ちるため、数値は元の表記のまま扱う。
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var data any
+	if decodeErr := decoder.Decode(&data); decodeErr != nil {
+		return fmt.Errorf("unmarshal json: %w", decodeErr)
+	}
+	return writeValue(buf, reflect.ValueOf(data), order, level)
+}
+
+// writeStruct は DD-DATA-001 のキー順で構造体のフィールドを出力する。omitempty の空の値は出力しない。
+func writeStruct(buf *bytes.Buffer, value reflect.Value, fields []field, order *keyOrder, level int) error {
+	values := make(map[string]reflect.Value, len(fields))
+	keys := make([]string, 0, len(fields))
+	for _, f := range fields {
+		fieldValue := value.Field(f.index)
+		if f.omitEmpty && isEmptyValue(fieldValue) {
+			continue
+		}
+		values[f.name] = fieldValue
+		keys = append(keys, f.name)
+	}
+	return writeObject(buf, keys, values, order, level)
+}
+
+// writeMap は DD-DATA-001 のキー順でマップを出力する。
+func writeMap(buf *bytes.Buffer, value reflect.Value, order *keyOrder, level int) error {
+	values := make(map[string]reflect.Value, value.Len())
+	keys := make([]string, 0, value.Len())
+	iter := value.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+		values[key] = iter.Value()
+		keys = append(keys, key)
+	}
+	return writeObject(buf, keys, values, order, level)
+}
+
+// writeObject は DD-DATA-001 のキー順でオブジェクトを出力する。
+// 目的: キー順序定義に従いオブジェクトを整形出力する。
+// 入力: buf は出力先、keys は出力するキー、values はキーごとの値、order はキー順序定義、level はインデント階層。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: 値の出力に失敗した場合に返す。
+// 副作用: buf に書き込む。
+// 並行性: buf は呼び出し側で排他する。
+// 不変条件: 既知キーは order の順序で出力する。
+// 関連DD: DD-DATA-001
+func writeObject(buf *bytes.Buffer, keys []string, values map[string]reflect.Value, order *keyOrder, level int) error {
+	if len(keys) == 0 {
+		buf.WriteString("{}")
+		return nil
+	}
+
+	buf.WriteString("{\n")
+	keys = orderedKeys(keys, order)
+	for i, key := range keys {
+		buf.WriteString(strings.Repeat(indent, level+1))
+		fmt.Fprintf(buf, "%q", key)
+		buf.WriteString(": ")
+		childOrder := orderChild(order, key)
+		if writeErr := writeValue(buf, values[key], childOrder, level+1); writeErr != nil {
+			return writeErr
+		}
+		if i < len(keys)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString(strings.Repeat(indent, level))
+	buf.WriteString("}")
+	return nil
+}
+
+// writeArray は DD-DATA-001 の配列表記で出力する。
+// 目的: 配列要素を正しいインデントで出力する。
+// 入力: buf は出力先、value は配列またはスライス、order は子要素順序、level はインデント階層。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: 要素出力に失敗した場合に返す。
+// 副作用: buf に書き込む。
+// 並行性: buf は呼び出し側で排他する。
+// 不変条件: 要素間はカンマ区切りで出力する。
+// 関連DD: DD-DATA-001
+func writeArray(buf *bytes.Buffer, value reflect.Value, order *keyOrder, level int) error {
+	if value.Len() == 0 {
+		buf.WriteString("[]")
+		return nil
+	}
+	buf.WriteString("[\n")
+	for i := 0; i < value.Len(); i++ {
+		buf.WriteString(strings.Repeat(indent, level+1))
+		if writeErr := writeValue(buf, value.Index(i), order, level+1); writeErr != nil {
+			return writeErr
+		}
+		if i < value.Len()-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString(strings.Repeat(indent, level))
+	buf.WriteString("]")
+	return nil
+}
+
+// orderedKeys は DD-DATA-001 のキー順と未知キーのソートを適用する。
+// 目的: 定義済みキー順序と未定義キーの辞書順を統合する。
+// 入力: keys は出力するキー、order はキー順序定義。
+// 出力: 反映済みのキー配列。
+// エラー: なし。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 未定義キーは昇順で追加される。
+// 関連DD: DD-DATA-001
+func orderedKeys(keys []string, order *keyOrder) []string {
+	present := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		present[key] = true
+	}
+	ordered := make([]string, 0, len(keys))
+	if order != nil {
+		for _, key := range order.Order {
+			if present[key] {
+				ordered = append(ordered, key)
+				delete(present, key)
+			}
+		}
+	}
+	remaining := make([]string, 0, len(present))
+	for _, key := range keys {
+		if present[key] {
+			remaining = append(remaining, key)
+		}
+	}
+	sort.Strings(remaining)
+	return append(ordered, remaining...)
+}
+
+// orderChild は DD-DATA-001 のネスト順序定義を取得する。
+func orderChild(order *keyOrder, key string) *keyOrder {
+	if order == nil {
+		return nil
+	}
+	return order.Children[key]
+}
+
+// implements は DD-DATA-001 の値が encoding/json から iface のメソッドを呼ばれるかを返す。
+// encoding/json と同じく、ポインタのメソッドはアドレスを取れる値の場合のみ対象とする。
+func implements(value reflect.Value, iface reflect.Type) bool {
+	if value.Type().Implements(iface) {
+		return true
+	}
+	return value.Kind() != reflect.Pointer && value.CanAddr() && reflect.PointerTo(value.Type()).Implements(iface)
+}
+
+// fieldsOf は DD-DATA-001 の構造体の出力するフィールドの一覧を、型ごとに一度だけ求めて返す。
+// 目的: encoding/json の json タグの規則に従い、出力名と omitempty を決める。
+// 入力: typ は構造体の型。
+// 出力: structFields。埋め込みフィールド、string・omitzero オプション、出力名の重複を含む場合は direct=false。
+// エラー: なし。
+// 副作用: fieldCache に結果を保持する。
+// 並行性: スレッドセーフ。
+// 不変条件: 非公開フィールドと json:"-" のフィールドは含めない。
+// 関連DD: DD-DATA-001
+func fieldsOf(typ reflect.Type) structFields {
+	if cached, ok := fieldCache.Load(typ); ok {
+		return cached.(structFields)
+	}
+	result := structFields{direct: true}
+	seen := map[string]bool{}
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if sf.Anonymous {
+			result.direct = false
+			break
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+		f := field{name: name, index: i}
+		for _, option := range strings.Split(options, ",") {
+			switch option {
+			case "omitempty":
+				f.omitEmpty = true
+			case "string", "omitzero":
+				result.direct = false
+			}
+		}
+		if seen[name] {
+			result.direct = false
+		}
+		seen[name] = true
+		result.fields = append(result.fields, f)
+	}
+	fieldCache.Store(typ, result)
+	return result
+}
+
+// isEmptyValue は DD-DATA-001 の omitempty で省略する空の値かを encoding/json と同じ規則で判定する。
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	case reflect.Bool:
+		return !value.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return value.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return value.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return value.IsNil()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,234 @@
+// encode_test.go は1回の走査による整形が encoding/json で変換して読み直した値の整形と一致することのテストを行う。
+package jsonfmt
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type encodeAttachment struct {
+	ID   string `json:"attachment_id"`
+	Size int64  `json:"size_bytes"`
+}
+
+type encodeComment struct {
+	Body        string             `json:"body"`
+	CommentID   string             `json:"comment_id"`
+	Attachments []encodeAttachment `json:"attachments"`
+}
+
+type encodeBase struct {
+	Shared string `json:"shared"`
+}
+
+type encodeLevel string
+
+// MarshalText は encoding.TextMarshaler を実装するフィールドの出力を確認するため、接頭辞を付けて返す。
+func (l encodeLevel) MarshalText() ([]byte, error) {
+	return []byte("L-" + string(l)), nil
+}
+
+type encodeIssue struct {
+	Version   int               `json:"version"`
+	Title     string            `json:"title"`
+	Assignee  string            `json:"assignee,omitempty"`
+	Due       *string           `json:"due_date,omitempty"`
+	Comments  []encodeComment   `json:"comments"`
+	Labels    map[string]int    `json:"labels"`
+	Extra     any               `json:"extra"`
+	Raw       json.RawMessage   `json:"raw"`
+	When      time.Time         `json:"created_at"`
+	Data      []byte            `json:"data"`
+	Level     encodeLevel       `json:"level"`
+	Nested    *encodeIssue      `json:"nested,omitempty"`
+	Skipped   string            `json:"-"`
+	Untagged  float64           // タグの無いフィールドはフィールド名で出力する。
+	hidden    string            // 非公開フィールドは出力しない。
+	Nothing   map[string]string `json:"nothing"`
+	Fixed     [2]uint8          `json:"fixed"`
+	Embedding encodeEmbedded    `json:"embedding"`
+}
+
+type encodeEmbedded struct {
+	encodeBase
+	Own string `json:"own"`
+}
+
+func TestMarshalWithOrder_MatchesReparsedOutput(t *testing.T) {
+	// 構造体を直接走査した整形が、encoding/json で変換して読み直したマップの整形と一致することを確認する。
+	due := "2024-01-01"
+	value := encodeIssue{
+		Version: 1,
+		Title:   "<a & b>\n\"quoted\"",
+		Due:     &due,
+		Comments: []encodeComment{
+			{Body: "x", CommentID: "c1", Attachments: []encodeAttachment{{ID: "a1", Size: 1704067200123456789}}},
+			{Body: "y", CommentID: "c2", Attachments: []encodeAttachment{}},
+		},
+		Labels:    map[string]int{"b": 2, "a": 1},
+		Extra:     map[string]any{"z": []any{1.5, nil, true}, "y": json.Number("12345678901234567890")},
+		Raw:       json.RawMessage(`{"k":[1,2]}`),
+		When:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Data:      []byte("bytes"),
+		Level:     "info",
+		Nested:    &encodeIssue{Title: "inner"},
+		Skipped:   "skip",
+		Untagged:  1e21,
+		hidden:    "hidden",
+		Fixed:     [2]uint8{1, 2},
+		Embedding: encodeEmbedded{encodeBase: encodeBase{Shared: "s"}, Own: "o"},
+	}
+
+	for _, input := range []any{value, &value, []any{value, nil}} {
+		got, err := MarshalIssue(input)
+		if err != nil {
+			t.Fatalf("MarshalIssue error: %v", err)
+		}
+		encoded, err := json.Marshal(input)
+		if err != nil {
+			t.Fatalf("json.Marshal error: %v", err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(encoded))
+		decoder.UseNumber()
+		var reparsed any
+		if err := decoder.Decode(&reparsed); err != nil {
+			t.Fatalf("decode error: %v", err)
+		}
+		expected, err := MarshalIssue(reparsed)
+		if err != nil {
+			t.Fatalf("MarshalIssue reparsed error: %v", err)
+		}
+		if string(got) != string(expected) {
+			t.Fatalf("direct output differs from reparsed output:\n%s\n---\n%s", got, expected)
+		}
+	}
+}
+
+func TestMarshalWithOrder_ReturnsUnsupportedValueError(t *testing.T) {
+	// JSON に変換できない値を含む場合はエラーを返すことを確認する。
+	if _, err := MarshalCanonical(map[string]any{"f": func() {}}); err == nil {
+		t.Fatal("expected error for unsupported value")
+	}
+}
+
+func TestWriteScalar_MatchesEncodingJSON(t *testing.T) {
+	// 文字列・数値を直接書き出した結果が、エスケープや指数表記を含めて json.Marshal の出力と一致することを確認する。
+	values := []any{
+		"", "plain", "<a & b>", "quote\" back\\slash", "\b\f\n\r\t\x00\x1f\x7f", "日本語", "  ", "bad\xffutf8",
+		true, false,
+		0, -1, int8(-128), int64(math.MinInt64), int64(math.MaxInt64), uint8(255), uint64(math.MaxUint64), uintptr(7),
+		0.0, -0.0, 1.5, 1e20, 1e21, 1e-6, 1e-7, -1.25e-10, math.MaxFloat64, math.SmallestNonzeroFloat64,
+		float32(0.1), float32(1e21), float32(1e-7), float32(3.4e38),
+		json.Number("12345678901234567890"), json.Number("-1.5e-300"), json.Number(""),
+		encodeLevel("text"), []byte("bytes"),
+	}
+	for _, value := range values {
+		var buf bytes.Buffer
+		if err := writeScalar(&buf, reflect.ValueOf(value)); err != nil {
+			t.Fatalf("writeScalar(%#v) error: %v", value, err)
+		}
+		expected, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("json.Marshal(%#v) error: %v", value, err)
+		}
+		if buf.String() != string(expected) {
+			t.Fatalf("writeScalar(%#v) = %s, expected %s", value, buf.String(), expected)
+		}
+	}
+	for _, value := range []any{math.NaN(), math.Inf(1), json.Number("1x"), json.Number("01"), json.Number(" 1")} {
+		if err := writeScalar(&bytes.Buffer{}, reflect.ValueOf(value)); err == nil {
+			t.Fatalf("expected error for %#v", value)
+		}
+	}
+}
+
+func TestMarshalCanonical_RoundTripsLargeIntegers(t *testing.T) {
+	// 2^53 を超える整数を整形して UseNumber で読み直しても、元の値と同じ表記のまま精度が落ちないことを確認する。
+	value := map[string]any{
+		"int64":  int64(math.MaxInt64),
+		"uint64": uint64(math.MaxUint64),
+		"number": json.Number("123456789012345678901234567890"),
+		"mtime":  int64(1704067200123456789),
+	}
+	data, err := MarshalCanonical(value)
+	if err != nil {
+		t.Fatalf("MarshalCanonical error: %v", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded map[string]json.Number
+	if err := decoder.Decode(&decoded); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	expected := map[string]string{
+		"int64":  "9223372036854775807",
+		"uint64": "18446744073709551615",
+		"number": "123456789012345678901234567890",
+		"mtime":  "1704067200123456789",
+	}
+	for key, want := range expected {
+		if string(decoded[key]) != want {
+			t.Fatalf("%s: expected %s, got %s", key, want, decoded[key])
+		}
+	}
+	again, err := MarshalCanonical(decoded)
+	if err != nil {
+		t.Fatalf("MarshalCanonical decoded error: %v", err)
+	}
+	if string(again) != string(data) {
+		t.Fatalf("expected identical output after round trip:\n%s\n---\n%s", again, data)
+	}
+}
+
+// benchmarkIssue はベンチマーク用にコメントと添付を多く持つ課題を返す。
+func benchmarkIssue() encodeIssue {
+	comments := make([]encodeComment, 0, 50)
+	for i := 0; i < 50; i++ {
+		comments = append(comments, encodeComment{
+			Body:        "コメント本文 <b>detail</b> & more\nsecond line",
+			CommentID:   "c" + strconv.Itoa(i),
+			Attachments: []encodeAttachment{{ID: "a" + strconv.Itoa(i), Size: 1704067200123456789}},
+		})
+	}
+	return encodeIssue{
+		Version: 1, Title: "タイトル", Comments: comments, Labels: map[string]int{"b": 2, "a": 1},
+		When: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Level: "info",
+	}
+}
+
+// BenchmarkMarshalIssue_Direct は構造体を1回の走査で整形する現在の経路を計測する。
+func BenchmarkMarshalIssue_Direct(b *testing.B) {
+	value := benchmarkIssue()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := MarshalIssue(value); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkMarshalIssue_Reparsed は以前の経路 (encoding/json で変換し、UseNumber で読み直したマップを整形する) を計測する。
+func BenchmarkMarshalIssue_Reparsed(b *testing.B) {
+	value := benchmarkIssue()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			b.Fatal(err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(encoded))
+		decoder.UseNumber()
+		var reparsed any
+		if err := decoder.Decode(&reparsed); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := MarshalIssue(reparsed); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
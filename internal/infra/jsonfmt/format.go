@@ -0,0 +1,116 @@
+// format.go は DD-DATA-006 のプロジェクト単位の改行コードとインデント幅の設定に従った整形を担い、
+// 設定ファイルの読み書きは扱わない。読み書きは projectmeta が担う。
+package jsonfmt
+
+import (
+	"bytes"
+	"errors"
+)
+
+const (
+	// LineEndingLF は DD-DATA-006 の改行コード LF を表す。
+	LineEndingLF = "lf"
+	// LineEndingCRLF は DD-DATA-006 の改行コード CRLF を表す。
+	LineEndingCRLF = "crlf"
+	// DefaultIndentWidth は DD-DATA-002 の既定のインデント幅を表す。
+	DefaultIndentWidth = 2
+	// MaxIndentWidth は DD-DATA-006 のインデント幅の上限を表す。
+	MaxIndentWidth = 8
+)
+
+// Format は DD-DATA-006 のプロジェクト単位の整形の設定を表す。
+// LineEnding が空の場合は LF、IndentWidth が 0 の場合は既定のインデント幅を用いる。
+// PreserveUnknownKeyOrder は DD-DATA-007 の既存ファイルを書き換える際に未知のキーの元の並びを保つかを表す。
+type Format struct {
+	LineEnding              string
+	IndentWidth             int
+	PreserveUnknownKeyOrder bool
+}
+
+// DefaultFormat は DD-DATA-002 の既定の整形 (LF、2 スペース) を返す。
+func DefaultFormat() Format {
+	return Format{LineEnding: LineEndingLF, IndentWidth: DefaultIndentWidth}
+}
+
+// Validate は DD-DATA-006 の整形の設定が扱える値かを検証する。
+func (f Format) Validate() error {
+	switch f.LineEnding {
+	case "", LineEndingLF, LineEndingCRLF:
+	default:
+		return errors.New("line_ending must be lf or crlf")
+	}
+	if f.IndentWidth < 0 || f.IndentWidth > MaxIndentWidth {
+		return errors.New("indent_width must be between 0 and 8")
+	}
+	return nil
+}
+
+// MarshalIssue は DD-DATA-006 の整形の設定に従い、MarshalIssue と同じキー順で issue JSON を整形する。
+func (f Format) MarshalIssue(value any) ([]byte, error) {
+	return f.marshal(value, issueKeyOrder)
+}
+
+// RewriteIssue は DD-DATA-007 に従い、既存の課題 JSON original を value で書き換える内容を整形する。
+// 目的: 他のツールが追加した未知のキーを、書き換えのたびに辞書順へ並べ替えて差分を生じさせないようにする。
+// 入力: value は書き換え後の課題、original は書き換え前のファイルの内容。
+// 出力: 整形済みJSONバイト列とエラー。
+// エラー: JSON変換に失敗した場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 既知のキーは MarshalIssue と同じ順とし、未知のキーは既知のキーの後に original での出現順で並べる。
+// original に無い未知のキーはその後に辞書順で並べる。PreserveUnknownKeyOrder が false の場合や
+// original が JSON として読めない場合は MarshalIssue と同じ出力とする。
+// 関連DD: DD-DATA-007, DD-DATA-006
+func (f Format) RewriteIssue(value any, original []byte) ([]byte, error) {
+	if !f.PreserveUnknownKeyOrder {
+		return f.MarshalIssue(value)
+	}
+	recorded, err := recordOrder(original)
+	if err != nil {
+		return f.MarshalIssue(value)
+	}
+	return f.marshal(value, preserving(issueKeyOrder, recorded))
+}
+
+// MarshalCategoryMeta は DD-DATA-006 の整形の設定に従い、MarshalCategoryMeta と同じキー順で .category.json を整形する。
+func (f Format) MarshalCategoryMeta(value any) ([]byte, error) {
+	return f.marshal(value, categoryMetaKeyOrder)
+}
+
+// marshal は DD-DATA-006 の標準の整形で出力した JSON を、設定した改行コードとインデント幅へ置き換える。
+// 目的: キー順や値の表記は標準の整形と揃えたまま、改行コードとインデント幅のみを変える。
+// 入力: value はJSON化対象、order はキー順序定義。
+// 出力: 整形済みJSONバイト列とエラー。
+// エラー: JSON変換に失敗した場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 既定の設定では標準の整形と同じバイト列を返す。
+// 文字列中の改行は標準の整形でエスケープ済みのため、置き換えるのは行頭のインデントと行末の改行のみとなる。
+// 関連DD: DD-DATA-006, DD-DATA-002
+func (f Format) marshal(value any, order *keyOrder) ([]byte, error) {
+	data, err := marshalWithOrder(value, order)
+	if err != nil {
+		return nil, err
+	}
+	width := f.IndentWidth
+	if width == 0 {
+		width = DefaultIndentWidth
+	}
+	newline := "\n"
+	if f.LineEnding == LineEndingCRLF {
+		newline = "\r\n"
+	}
+	if width == DefaultIndentWidth && newline == "\n" {
+		return data, nil
+	}
+	unit := bytes.Repeat([]byte(" "), width)
+	var buf bytes.Buffer
+	for _, line := range bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) {
+		content := bytes.TrimLeft(line, " ")
+		depth := (len(line) - len(content)) / len(indent)
+		buf.Write(bytes.Repeat(unit, depth))
+		buf.Write(content)
+		buf.WriteString(newline)
+	}
+	return buf.Bytes(), nil
+}
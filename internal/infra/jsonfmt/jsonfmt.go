@@ -5,9 +5,6 @@ package jsonfmt
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
-	"sort"
-	"strings"
 )
 
 const indent = "  "
@@ -65,6 +62,171 @@ func MarshalContractor(value any) ([]byte, error) {
 	return marshalWithOrder(value, contractorKeyOrder)
 }
 
+// MarshalUsers は DD-CLI-007 のキー順に従って users.json を整形する。
+// 目的: 複数の Contractor アカウントの暗号化済み資格情報のキー順を固定し、アカウントの追加・削除の差分を安定化する。
+// 入力: value は利用者一覧の構造体またはマップ。
+// 出力: 整形済みJSONバイト列とエラー。
+// エラー: JSON変換に失敗した場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 仕様定義のキー順序を維持し、各アカウントの項目は contractor.json と同じ順とする。
+// 関連DD: DD-CLI-007, DD-DATA-001
+func MarshalUsers(value any) ([]byte, error) {
+	return marshalWithOrder(value, usersKeyOrder)
+}
+
+// MarshalAttempts は DD-MODE-002 のキー順に従って attempts.json を整形する。
+// 目的: Contractor 認証の失敗回数と最後の失敗時刻の記録のキー順を固定し、手作業での確認や解除を容易にする。
+// 入力: value は試行記録の構造体またはマップ。
+// 出力: 整形済みJSONバイト列とエラー。
+// エラー: JSON変換に失敗した場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 仕様定義のキー順序を維持する。
+// 関連DD: DD-MODE-002, DD-DATA-001
+func MarshalAttempts(value any) ([]byte, error) {
+	return marshalWithOrder(value, attemptsKeyOrder)
+}
+
+// MarshalRemembered は DD-MODE-003 のキー順に従って remembered.json を整形する。
+// 目的: この端末で記憶した Contractor 認証の照合情報のキー順を固定し、手作業での確認や削除を容易にする。
+// 入力: value は記憶の一覧の構造体またはマップ。
+// 出力: 整形済みJSONバイト列とエラー。
+// エラー: JSON変換に失敗した場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 仕様定義のキー順序を維持する。
+// 関連DD: DD-MODE-003, DD-DATA-001
+func MarshalRemembered(value any) ([]byte, error) {
+	return marshalWithOrder(value, rememberedKeyOrder)
+}
+
+// MarshalBundleManifest は DD-BUNDLE-001 のキー順に従ってバンドル manifest を整形する。
+// 目的: manifest.json のキー順を固定し、同一課題の再出力で差分が出ないようにする。
+// 入力: value は manifest 構造体またはマップ。
+// 出力: 整形済みJSONバイト列とエラー。
+// エラー: JSON変換に失敗した場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 仕様定義のキー順序を維持する。
+// 関連DD: DD-BUNDLE-001
+func MarshalBundleManifest(value any) ([]byte, error) {
+	return marshalWithOrder(value, bundleManifestKeyOrder)
+}
+
+// MarshalCategoryMeta は DD-CATMETA-001 のキー順に従って .category.json を整形する。
+// 目的: カテゴリメタデータのキー順を固定し差分を安定化する。
+// 入力: value はメタデータ構造体またはマップ。
+// 出力: 整形済みJSONバイト列とエラー。
+// エラー: JSON変換に失敗した場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 仕様定義のキー順序を維持する。
+// 関連DD: DD-CATMETA-001
+func MarshalCategoryMeta(value any) ([]byte, error) {
+	return marshalWithOrder(value, categoryMetaKeyOrder)
+}
+
+// MarshalTrashManifest は DD-TRASH-001 のキー順に従ってゴミ箱の manifest を整形する。
+// 目的: 退避情報のキー順を固定し、手作業での確認や復元を容易にする。
+// 入力: value は manifest 構造体またはマップ。
+// 出力: 整形済みJSONバイト列とエラー。
+// エラー: JSON変換に失敗した場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 仕様定義のキー順序を維持する。
+// 関連DD: DD-TRASH-001
+func MarshalTrashManifest(value any) ([]byte, error) {
+	return marshalWithOrder(value, trashManifestKeyOrder)
+}
+
+// MarshalCategoryOrder は DD-PROJMETA-001 のキー順に従ってカテゴリ表示順を整形する。
+// 目的: category_order.json のキー順を固定し差分を安定化する。
+// 入力: value は表示順構造体またはマップ。
+// 出力: 整形済みJSONバイト列とエラー。
+// エラー: JSON変換に失敗した場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 仕様定義のキー順序を維持する。
+// 関連DD: DD-PROJMETA-001
+func MarshalCategoryOrder(value any) ([]byte, error) {
+	return marshalWithOrder(value, categoryOrderKeyOrder)
+}
+
+// MarshalPermissions は DD-PERM-001 のキー順に従ってカテゴリ権限を整形する。
+// 目的: permissions.json のキー順を固定し、カテゴリごとの権限の変更を差分で確認しやすくする。
+// 入力: value は権限設定の構造体またはマップ。
+// 出力: 整形済みJSONバイト列とエラー。
+// エラー: JSON変換に失敗した場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 仕様定義のキー順序を維持し、カテゴリ名のキーは名前順に並べる。
+// 関連DD: DD-PERM-001, DD-DATA-001
+func MarshalPermissions(value any) ([]byte, error) {
+	return marshalWithOrder(value, permissionsKeyOrder)
+}
+
+// MarshalIssueIndex は DD-INDEX-001 のキー順に従って課題索引を整形する。
+// 目的: index.json のキー順を固定し、索引の中身を人手で確認しやすくする。
+// 入力: value は索引構造体またはマップ。
+// 出力: 整形済みJSONバイト列とエラー。
+// エラー: JSON変換に失敗した場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 仕様定義のキー順序を維持する。
+// 関連DD: DD-INDEX-001
+func MarshalIssueIndex(value any) ([]byte, error) {
+	return marshalWithOrder(value, issueIndexKeyOrder)
+}
+
+// MarshalIssueExport は DD-EXPORT-001 のキー順に従って課題一覧の出力を整形する。
+// 目的: 出力ファイルのキー順を固定し、各課題を課題JSONと同じキー順で並べる。
+// 入力: value は出力内容の構造体またはマップ。
+// 出力: 整形済みJSONバイト列とエラー。
+// エラー: JSON変換に失敗した場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 仕様定義のキー順序を維持する。
+// 関連DD: DD-EXPORT-001, DD-DATA-003
+func MarshalIssueExport(value any) ([]byte, error) {
+	return marshalWithOrder(value, issueExportKeyOrder)
+}
+
+// MarshalIssueLine は DD-EXPORT-002 の課題JSONと同じキー順で、課題を改行を含まない1行の JSON として出力する。
+// 目的: JSON Lines の1行を、課題JSONとキー順を揃えたまま行単位の処理ツールで扱える形にする。
+// 入力: value は課題の構造体またはマップ。
+// 出力: 末尾に LF を1つ付けた JSON バイト列とエラー。
+// エラー: JSON変換に失敗した場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: キー順は MarshalIssue と同じとし、値の途中に改行や空白を含めない。
+// 関連DD: DD-EXPORT-002, DD-DATA-003
+func MarshalIssueLine(value any) ([]byte, error) {
+	data, err := marshalWithOrder(value, issueKeyOrder)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if compactErr := json.Compact(&buf, data); compactErr != nil {
+		return nil, compactErr
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// MarshalBackupManifest は DD-BACKUP-001 のキー順に従ってプロジェクトのバックアップの manifest を整形する。
+// 目的: manifest.json のキー順を固定し、手作業での確認を容易にする。
+// 入力: value は manifest 構造体またはマップ。
+// 出力: 整形済みJSONバイト列とエラー。
+// エラー: JSON変換に失敗した場合に返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 仕様定義のキー順序を維持する。
+// 関連DD: DD-BACKUP-001
+func MarshalBackupManifest(value any) ([]byte, error) {
+	return marshalWithOrder(value, backupManifestKeyOrder)
+}
+
 type keyOrder struct {
 	Order    []string
 	Children map[string]*keyOrder
@@ -85,6 +247,8 @@ var issueKeyOrder = &keyOrder{
 		"created_at",
 		"updated_at",
 		"due_date",
+		"tags",
+		"custom_fields",
 		"comments",
 	},
 	Children: map[string]*keyOrder{
@@ -118,15 +282,52 @@ var configKeyOrder = &keyOrder{
 	Order: []string{
 		"format_version",
 		"last_project_root_path",
+		"recent_project_roots",
 		"log",
 		"ui",
+		"scan",
+		"auth",
+		"storage",
+		"report",
+		"hooks",
 	},
 	Children: map[string]*keyOrder{
-		"log": {Order: []string{"level"}},
-		"ui":  {Order: []string{"page_size"}},
+		"log": {Order: []string{"level", "max_size_mb", "max_generations", "system_sink", "audit_trail"}},
+		"ui": {
+			Order: []string{
+				"page_size",
+				"window",
+				"default_sort",
+				"default_author_name",
+				"date_format",
+				"language",
+				"time_zone",
+				"confirm_on_delete",
+			},
+			Children: map[string]*keyOrder{
+				"window":            {Order: []string{"width", "height", "x", "y", "maximised"}},
+				"default_sort":      {Order: []string{"sort_by", "sort_order"}},
+				"confirm_on_delete": {Order: []string{"category", "category_with_issues"}},
+			},
+		},
+		"scan": {Order: []string{"concurrency"}},
+		"auth": {
+			Order: []string{"contractor_idle_timeout_minutes", "password_policy"},
+			Children: map[string]*keyOrder{
+				"password_policy": {Order: []string{"min_length", "min_char_classes", "allow_common"}},
+			},
+		},
+		"storage": {Order: []string{"durable_writes", "backup_generations", "tmp_stale_hours", "tmp_scan_interval_minutes", "utc_timestamps"}},
+		"report":  {Order: []string{"pdf_font"}},
+		"hooks":   {Order: []string{"on_issue_created", "on_issue_updated", "on_issue_commented", "on_issue_closed", "timeout_seconds"}},
 	},
 }
 
+// permissionsKeyOrder は DD-PERM-001 のキー順を定義する。
+var permissionsKeyOrder = &keyOrder{
+	Order: []string{"format_version", "categories"},
+}
+
 // contractorKeyOrder は DD-DATA-001 のキー順を定義する。
 var contractorKeyOrder = &keyOrder{
 	Order: []string{
@@ -136,164 +337,118 @@ var contractorKeyOrder = &keyOrder{
 		"salt_b64",
 		"nonce_b64",
 		"ciphertext_b64",
+		"totp_nonce_b64",
+		"totp_ciphertext_b64",
 		"mode",
 	},
 }
 
-// marshalWithOrder は DD-DATA-001 の canonical 出力ルールに従って整形する。
-// 目的: JSONを一度汎用構造に変換し、順序付きで再出力する。
-// 入力: value はJSON化対象、order はキー順序定義。
-// 出力: 整形済みJSONバイト列とエラー。
-// エラー: JSON変換や整形処理に失敗した場合に返す。
-// 副作用: なし。
-// 並行性: スレッドセーフ。
-// 不変条件: 出力の末尾に改行を付与する。
-// 関連DD: DD-DATA-001
-func marshalWithOrder(value any, order *keyOrder) ([]byte, error) {
-	raw, err := json.Marshal(value)
-	if err != nil {
-		return nil, fmt.Errorf("marshal json: %w", err)
-	}
+// usersKeyOrder は DD-CLI-007 の users.json のキー順を定義する。アカウントの項目は contractor.json と同じ順とする。
+var usersKeyOrder = &keyOrder{
+	Order: []string{"format_version", "users"},
+	Children: map[string]*keyOrder{
+		"users": {Order: []string{"username", "kdf", "kdf_iterations", "salt_b64", "nonce_b64", "ciphertext_b64", "totp_nonce_b64", "totp_ciphertext_b64"}},
+	},
+}
 
-	var data any
-	if unmarshalErr := json.Unmarshal(raw, &data); unmarshalErr != nil {
-		return nil, fmt.Errorf("unmarshal json: %w", unmarshalErr)
-	}
+// attemptsKeyOrder は DD-MODE-002 の attempts.json のキー順を定義する。
+var attemptsKeyOrder = &keyOrder{
+	Order: []string{"format_version", "failed_attempts", "last_failure_at"},
+}
 
-	var buf bytes.Buffer
-	if writeErr := writeValue(&buf, data, order, 0); writeErr != nil {
-		return nil, writeErr
-	}
-	buf.WriteByte('\n')
-	return buf.Bytes(), nil
+// rememberedKeyOrder は DD-MODE-003 の remembered.json のキー順を定義する。
+var rememberedKeyOrder = &keyOrder{
+	Order: []string{"format_version", "tokens"},
+	Children: map[string]*keyOrder{
+		"tokens": {Order: []string{"id", "username", "token_sha256_b64", "credential_sha256_b64", "created_at"}},
+	},
 }
 
-// writeValue は DD-DATA-001 の JSON ルールに従い値を出力する。
-// 目的: 値の型に応じて正しい表現で書き出す。
-// 入力: buf は出力先、value は対象値、order はキー順序定義、level はインデント階層。
-// 出力: 成功時は nil、失敗時はエラー。
-// エラー: JSON変換に失敗した場合に返す。
-// 副作用: buf に書き込む。
-// 並行性: buf は呼び出し側で排他する。
-// 不変条件: 文字列は JSON エスケープ済みで出力する。
-// 関連DD: DD-DATA-001
-func writeValue(buf *bytes.Buffer, value any, order *keyOrder, level int) error {
-	switch typed := value.(type) {
-	case map[string]any:
-		return writeObject(buf, typed, order, level)
-	case []any:
-		return writeArray(buf, typed, order, level)
-	default:
-		encoded, err := json.Marshal(typed)
-		if err != nil {
-			return fmt.Errorf("marshal value: %w", err)
-		}
-		buf.Write(encoded)
-		return nil
-	}
+// bundleManifestKeyOrder は DD-BUNDLE-001 のキー順を定義する。
+var bundleManifestKeyOrder = &keyOrder{
+	Order: []string{
+		"format_version",
+		"kind",
+		"exported_at",
+		"issue_id",
+		"category",
+		"issue_file",
+		"files",
+	},
+	Children: map[string]*keyOrder{
+		"files": {Order: []string{"path", "size_bytes", "sha256"}},
+	},
 }
 
-// writeObject は DD-DATA-001 のキー順でオブジェクトを出力する。
-// 目的: キー順序定義に従いオブジェクトを整形出力する。
-// 入力: buf は出力先、value はマップ、order はキー順序定義、level はインデント階層。
-// 出力: 成功時は nil、失敗時はエラー。
-// エラー: 値の出力に失敗した場合に返す。
-// 副作用: buf に書き込む。
-// 並行性: buf は呼び出し側で排他する。
-// 不変条件: 既知キーは order の順序で出力する。
-// 関連DD: DD-DATA-001
-func writeObject(buf *bytes.Buffer, value map[string]any, order *keyOrder, level int) error {
-	if len(value) == 0 {
-		buf.WriteString("{}")
-		return nil
-	}
+// categoryMetaKeyOrder は DD-CATMETA-001 のキー順を定義する。
+var categoryMetaKeyOrder = &keyOrder{
+	Order: []string{
+		"format_version",
+		"description",
+		"color",
+		"sort_weight",
+		"default_assignee",
+		"default_priority",
+		"description_template",
+	},
+}
 
-	buf.WriteString("{\n")
-	keys := orderedKeys(value, order)
-	for i, key := range keys {
-		buf.WriteString(strings.Repeat(indent, level+1))
-		fmt.Fprintf(buf, "%q", key)
-		buf.WriteString(": ")
-		childOrder := orderChild(order, key)
-		if writeErr := writeValue(buf, value[key], childOrder, level+1); writeErr != nil {
-			return writeErr
-		}
-		if i < len(keys)-1 {
-			buf.WriteString(",")
-		}
-		buf.WriteString("\n")
-	}
-	buf.WriteString(strings.Repeat(indent, level))
-	buf.WriteString("}")
-	return nil
+// trashManifestKeyOrder は DD-TRASH-001 のキー順を定義する。
+var trashManifestKeyOrder = &keyOrder{
+	Order: []string{
+		"format_version",
+		"trash_id",
+		"category",
+		"deleted_at",
+		"issue_ids",
+	},
 }
 
-// writeArray は DD-DATA-001 の配列表記で出力する。
-// 目的: 配列要素を正しいインデントで出力する。
-// 入力: buf は出力先、value は配列、order は子要素順序、level はインデント階層。
-// 出力: 成功時は nil、失敗時はエラー。
-// エラー: 要素出力に失敗した場合に返す。
-// 副作用: buf に書き込む。
-// 並行性: buf は呼び出し側で排他する。
-// 不変条件: 要素間はカンマ区切りで出力する。
-// 関連DD: DD-DATA-001
-func writeArray(buf *bytes.Buffer, value []any, order *keyOrder, level int) error {
-	if len(value) == 0 {
-		buf.WriteString("[]")
-		return nil
-	}
-	buf.WriteString("[\n")
-	for i, item := range value {
-		buf.WriteString(strings.Repeat(indent, level+1))
-		if writeErr := writeValue(buf, item, order, level+1); writeErr != nil {
-			return writeErr
-		}
-		if i < len(value)-1 {
-			buf.WriteString(",")
-		}
-		buf.WriteString("\n")
-	}
-	buf.WriteString(strings.Repeat(indent, level))
-	buf.WriteString("]")
-	return nil
+// categoryOrderKeyOrder は DD-PROJMETA-001 のキー順を定義する。
+var categoryOrderKeyOrder = &keyOrder{
+	Order: []string{"format_version", "categories"},
 }
 
-// orderedKeys は DD-DATA-001 のキー順と未知キーのソートを適用する。
-// 目的: 定義済みキー順序と未定義キーの辞書順を統合する。
-// 入力: value は対象マップ、order はキー順序定義。
-// 出力: 反映済みのキー配列。
-// エラー: なし。
-// 副作用: なし。
-// 並行性: スレッドセーフ。
-// 不変条件: 未定義キーは昇順で追加される。
-// 関連DD: DD-DATA-001
-func orderedKeys(value map[string]any, order *keyOrder) []string {
-	seen := make(map[string]struct{}, len(value))
-	keys := make([]string, 0, len(value))
-	if order != nil {
-		for _, key := range order.Order {
-			if _, ok := value[key]; ok {
-				keys = append(keys, key)
-				seen[key] = struct{}{}
-			}
-		}
-	}
-	remaining := make([]string, 0, len(value))
-	for key := range value {
-		if _, ok := seen[key]; ok {
-			continue
-		}
-		remaining = append(remaining, key)
-	}
-	sort.Strings(remaining)
-	keys = append(keys, remaining...)
-	return keys
+// issueIndexKeyOrder は DD-INDEX-001 のキー順を定義する。
+var issueIndexKeyOrder = &keyOrder{
+	Order: []string{"format_version", "categories", "removed"},
+	Children: map[string]*keyOrder{
+		"removed": {
+			Order: []string{"category", "file_name", "removed_at"},
+		},
+		"categories": {
+			Order: []string{"name", "entries"},
+			Children: map[string]*keyOrder{
+				"entries": {
+					Order: []string{
+						"file_name",
+						"issue_id",
+						"title",
+						"status",
+						"priority",
+						"origin_company",
+						"updated_at",
+						"due_date",
+						"is_schema_invalid",
+						"mtime",
+						"size_bytes",
+					},
+				},
+			},
+		},
+	},
 }
 
-// orderChild は DD-DATA-001 のネスト順序定義を取得する。
-func orderChild(order *keyOrder, key string) *keyOrder {
-	if order == nil {
-		return nil
-	}
-	return order.Children[key]
+// issueExportKeyOrder は DD-EXPORT-001 のキー順を定義する。課題は issueKeyOrder に従う。
+var issueExportKeyOrder = &keyOrder{
+	Order:    []string{"format_version", "issues"},
+	Children: map[string]*keyOrder{"issues": issueKeyOrder},
+}
+
+// backupManifestKeyOrder は DD-BACKUP-001 のキー順を定義する。
+var backupManifestKeyOrder = &keyOrder{
+	Order: []string{"format_version", "kind", "created_at", "files"},
+	Children: map[string]*keyOrder{
+		"files": {Order: []string{"path", "size_bytes", "sha256"}},
+	},
 }
@@ -104,6 +104,14 @@ func TestMarshalConfig_KeyOrder(t *testing.T) {
 	// config JSON のキー順が DD-DATA-001 に沿っていることを確認する。
 	input := map[string]any{
 		"ui": map[string]any{
+			"confirm_on_delete": map[string]any{
+				"category_with_issues": true,
+				"category":             false,
+			},
+			"default_sort": map[string]any{
+				"sort_order": "desc",
+				"sort_by":    "title",
+			},
 			"page_size": 20,
 		},
 		"format_version":         1,
@@ -111,6 +119,18 @@ func TestMarshalConfig_KeyOrder(t *testing.T) {
 		"log": map[string]any{
 			"level": "info",
 		},
+		"auth": map[string]any{
+			"password_policy": map[string]any{
+				"allow_common":     false,
+				"min_char_classes": 2,
+				"min_length":       12,
+			},
+			"contractor_idle_timeout_minutes": 30,
+		},
+		"storage": map[string]any{
+			"backup_generations": 3,
+			"durable_writes":     true,
+		},
 	}
 
 	got, err := MarshalConfig(input)
@@ -125,7 +145,27 @@ func TestMarshalConfig_KeyOrder(t *testing.T) {
 		"    \"level\": \"info\"\n" +
 		"  },\n" +
 		"  \"ui\": {\n" +
-		"    \"page_size\": 20\n" +
+		"    \"page_size\": 20,\n" +
+		"    \"default_sort\": {\n" +
+		"      \"sort_by\": \"title\",\n" +
+		"      \"sort_order\": \"desc\"\n" +
+		"    },\n" +
+		"    \"confirm_on_delete\": {\n" +
+		"      \"category\": false,\n" +
+		"      \"category_with_issues\": true\n" +
+		"    }\n" +
+		"  },\n" +
+		"  \"auth\": {\n" +
+		"    \"contractor_idle_timeout_minutes\": 30,\n" +
+		"    \"password_policy\": {\n" +
+		"      \"min_length\": 12,\n" +
+		"      \"min_char_classes\": 2,\n" +
+		"      \"allow_common\": false\n" +
+		"    }\n" +
+		"  },\n" +
+		"  \"storage\": {\n" +
+		"    \"durable_writes\": true,\n" +
+		"    \"backup_generations\": 3\n" +
 		"  }\n" +
 		"}\n"
 	if string(got) != expected {
@@ -136,13 +176,15 @@ func TestMarshalConfig_KeyOrder(t *testing.T) {
 func TestMarshalContractor_KeyOrder(t *testing.T) {
 	// contractor JSON のキー順が DD-DATA-001 に沿っていることを確認する。
 	input := map[string]any{
-		"mode":           "contractor",
-		"ciphertext_b64": "cc",
-		"salt_b64":       "aa",
-		"nonce_b64":      "bb",
-		"kdf":            "pbkdf2-hmac-sha256",
-		"kdf_iterations": 200000,
-		"format_version": 1,
+		"mode":                "contractor",
+		"ciphertext_b64":      "cc",
+		"salt_b64":            "aa",
+		"nonce_b64":           "bb",
+		"kdf":                 "pbkdf2-hmac-sha256",
+		"kdf_iterations":      200000,
+		"format_version":      1,
+		"totp_ciphertext_b64": "ee",
+		"totp_nonce_b64":      "dd",
 	}
 
 	got, err := MarshalContractor(input)
@@ -157,9 +199,378 @@ func TestMarshalContractor_KeyOrder(t *testing.T) {
 		"  \"salt_b64\": \"aa\",\n" +
 		"  \"nonce_b64\": \"bb\",\n" +
 		"  \"ciphertext_b64\": \"cc\",\n" +
+		"  \"totp_nonce_b64\": \"dd\",\n" +
+		"  \"totp_ciphertext_b64\": \"ee\",\n" +
 		"  \"mode\": \"contractor\"\n" +
 		"}\n"
 	if string(got) != expected {
 		t.Fatalf("unexpected contractor JSON:\n%s", string(got))
 	}
 }
+
+func TestMarshalBundleManifest_KeyOrder(t *testing.T) {
+	// バンドル manifest のキー順が DD-BUNDLE-001 に沿っていることを確認する。
+	input := map[string]any{
+		"files": []any{
+			map[string]any{"sha256": "ff", "path": "a.json", "size_bytes": 2},
+		},
+		"issue_file":     "a.json",
+		"kind":           "ratta-issue-bundle",
+		"format_version": 1,
+	}
+
+	got, err := MarshalBundleManifest(input)
+	if err != nil {
+		t.Fatalf("MarshalBundleManifest error: %v", err)
+	}
+
+	expected := "{\n" +
+		"  \"format_version\": 1,\n" +
+		"  \"kind\": \"ratta-issue-bundle\",\n" +
+		"  \"issue_file\": \"a.json\",\n" +
+		"  \"files\": [\n" +
+		"    {\n" +
+		"      \"path\": \"a.json\",\n" +
+		"      \"size_bytes\": 2,\n" +
+		"      \"sha256\": \"ff\"\n" +
+		"    }\n" +
+		"  ]\n" +
+		"}\n"
+	if string(got) != expected {
+		t.Fatalf("unexpected manifest JSON:\n%s", string(got))
+	}
+}
+
+func TestMarshalCategoryMeta_KeyOrder(t *testing.T) {
+	// カテゴリメタデータのキー順が DD-CATMETA-001 に沿っていることを確認する。
+	input := map[string]any{
+		"sort_weight":    1,
+		"color":          "#000000",
+		"description":    "d",
+		"format_version": 1,
+	}
+
+	got, err := MarshalCategoryMeta(input)
+	if err != nil {
+		t.Fatalf("MarshalCategoryMeta error: %v", err)
+	}
+
+	expected := "{\n" +
+		"  \"format_version\": 1,\n" +
+		"  \"description\": \"d\",\n" +
+		"  \"color\": \"#000000\",\n" +
+		"  \"sort_weight\": 1\n" +
+		"}\n"
+	if string(got) != expected {
+		t.Fatalf("unexpected category meta JSON:\n%s", string(got))
+	}
+}
+
+func TestMarshalTrashManifest_KeyOrder(t *testing.T) {
+	// ゴミ箱 manifest のキー順が DD-TRASH-001 に沿っていることを確認する。
+	input := map[string]any{
+		"issue_ids":      []any{"a"},
+		"deleted_at":     "2024-01-01T00:00:00+09:00",
+		"category":       "cat",
+		"trash_id":       "t",
+		"format_version": 1,
+	}
+
+	got, err := MarshalTrashManifest(input)
+	if err != nil {
+		t.Fatalf("MarshalTrashManifest error: %v", err)
+	}
+
+	expected := "{\n" +
+		"  \"format_version\": 1,\n" +
+		"  \"trash_id\": \"t\",\n" +
+		"  \"category\": \"cat\",\n" +
+		"  \"deleted_at\": \"2024-01-01T00:00:00+09:00\",\n" +
+		"  \"issue_ids\": [\n" +
+		"    \"a\"\n" +
+		"  ]\n" +
+		"}\n"
+	if string(got) != expected {
+		t.Fatalf("unexpected trash manifest JSON:\n%s", string(got))
+	}
+}
+
+func TestMarshalCategoryOrder_KeyOrder(t *testing.T) {
+	// カテゴリ表示順のキー順が DD-PROJMETA-001 に沿っていることを確認する。
+	got, err := MarshalCategoryOrder(map[string]any{
+		"categories":     []any{"b", "a"},
+		"format_version": 1,
+	})
+	if err != nil {
+		t.Fatalf("MarshalCategoryOrder error: %v", err)
+	}
+
+	expected := "{\n" +
+		"  \"format_version\": 1,\n" +
+		"  \"categories\": [\n" +
+		"    \"b\",\n" +
+		"    \"a\"\n" +
+		"  ]\n" +
+		"}\n"
+	if string(got) != expected {
+		t.Fatalf("unexpected category order JSON:\n%s", string(got))
+	}
+}
+
+func TestMarshalIssueIndex_KeyOrder(t *testing.T) {
+	// 課題索引のキー順が DD-INDEX-001 に沿っていることを確認する。
+	input := map[string]any{
+		"categories": []any{
+			map[string]any{
+				"entries": []any{
+					map[string]any{"mtime": 1, "issue_id": "a", "file_name": "a.json"},
+				},
+				"name": "cat",
+			},
+		},
+		"format_version": 1,
+	}
+
+	got, err := MarshalIssueIndex(input)
+	if err != nil {
+		t.Fatalf("MarshalIssueIndex error: %v", err)
+	}
+
+	expected := "{\n" +
+		"  \"format_version\": 1,\n" +
+		"  \"categories\": [\n" +
+		"    {\n" +
+		"      \"name\": \"cat\",\n" +
+		"      \"entries\": [\n" +
+		"        {\n" +
+		"          \"file_name\": \"a.json\",\n" +
+		"          \"issue_id\": \"a\",\n" +
+		"          \"mtime\": 1\n" +
+		"        }\n" +
+		"      ]\n" +
+		"    }\n" +
+		"  ]\n" +
+		"}\n"
+	if string(got) != expected {
+		t.Fatalf("unexpected index JSON:\n%s", string(got))
+	}
+}
+
+func TestMarshalIssueExport_KeyOrder(t *testing.T) {
+	// 課題一覧の出力のキー順が DD-EXPORT-001 に沿い、各課題が課題JSONのキー順になることを確認する。
+	got, err := MarshalIssueExport(map[string]any{
+		"issues": []any{
+			map[string]any{"title": "t", "issue_id": "a", "version": 1},
+		},
+		"format_version": 1,
+	})
+	if err != nil {
+		t.Fatalf("MarshalIssueExport error: %v", err)
+	}
+
+	expected := "{\n" +
+		"  \"format_version\": 1,\n" +
+		"  \"issues\": [\n" +
+		"    {\n" +
+		"      \"version\": 1,\n" +
+		"      \"issue_id\": \"a\",\n" +
+		"      \"title\": \"t\"\n" +
+		"    }\n" +
+		"  ]\n" +
+		"}\n"
+	if string(got) != expected {
+		t.Fatalf("unexpected export JSON:\n%s", string(got))
+	}
+}
+
+func TestMarshalIssueLine_SingleLineInIssueKeyOrder(t *testing.T) {
+	// 課題JSONのキー順のまま1行に詰め、文字列中の改行はエスケープして行末にのみ改行を付けることを確認する。
+	got, err := MarshalIssueLine(map[string]any{
+		"title":       "t",
+		"description": "line1\nline2",
+		"issue_id":    "a",
+		"version":     1,
+		"comments":    []any{map[string]any{"body": "b", "comment_id": "c"}},
+	})
+	if err != nil {
+		t.Fatalf("MarshalIssueLine error: %v", err)
+	}
+	expected := `{"version":1,"issue_id":"a","title":"t","description":"line1\nline2","comments":[{"comment_id":"c","body":"b"}]}` + "\n"
+	if string(got) != expected {
+		t.Fatalf("unexpected issue line:\n%s", string(got))
+	}
+}
+
+func TestMarshalCanonical_PreservesLargeIntegers(t *testing.T) {
+	// 2^53 を超える整数も丸めずに出力されることを確認する。
+	got, err := MarshalCanonical(map[string]any{"mtime": int64(1704067200123456789)})
+	if err != nil {
+		t.Fatalf("MarshalCanonical error: %v", err)
+	}
+	if string(got) != "{\n  \"mtime\": 1704067200123456789\n}\n" {
+		t.Fatalf("unexpected JSON:\n%s", string(got))
+	}
+}
+
+func TestMarshalBackupManifest_KeyOrder(t *testing.T) {
+	// バックアップの manifest のキー順が DD-BACKUP-001 に沿うことを確認する。
+	got, err := MarshalBackupManifest(map[string]any{
+		"files":          []any{map[string]any{"sha256": "h", "path": "p", "size_bytes": 1}},
+		"created_at":     "t",
+		"kind":           "k",
+		"format_version": 1,
+	})
+	if err != nil {
+		t.Fatalf("MarshalBackupManifest error: %v", err)
+	}
+
+	expected := "{\n" +
+		"  \"format_version\": 1,\n" +
+		"  \"kind\": \"k\",\n" +
+		"  \"created_at\": \"t\",\n" +
+		"  \"files\": [\n" +
+		"    {\n" +
+		"      \"path\": \"p\",\n" +
+		"      \"size_bytes\": 1,\n" +
+		"      \"sha256\": \"h\"\n" +
+		"    }\n" +
+		"  ]\n" +
+		"}\n"
+	if string(got) != expected {
+		t.Fatalf("unexpected manifest JSON:\n%s", string(got))
+	}
+}
+
+func TestMarshalUsers_KeyOrder(t *testing.T) {
+	// users.json のキー順が DD-CLI-007 に沿うことを確認する。
+	got, err := MarshalUsers(map[string]any{
+		"users": []any{map[string]any{
+			"ciphertext_b64": "cc", "nonce_b64": "bb", "salt_b64": "aa",
+			"kdf_iterations": 3, "kdf": "argon2id", "username": "alice",
+		}},
+		"format_version": 1,
+	})
+	if err != nil {
+		t.Fatalf("MarshalUsers error: %v", err)
+	}
+
+	expected := "{\n" +
+		"  \"format_version\": 1,\n" +
+		"  \"users\": [\n" +
+		"    {\n" +
+		"      \"username\": \"alice\",\n" +
+		"      \"kdf\": \"argon2id\",\n" +
+		"      \"kdf_iterations\": 3,\n" +
+		"      \"salt_b64\": \"aa\",\n" +
+		"      \"nonce_b64\": \"bb\",\n" +
+		"      \"ciphertext_b64\": \"cc\"\n" +
+		"    }\n" +
+		"  ]\n" +
+		"}\n"
+	if string(got) != expected {
+		t.Fatalf("unexpected users JSON:\n%s", string(got))
+	}
+}
+
+func TestMarshalAttempts_KeyOrder(t *testing.T) {
+	// attempts.json のキー順が DD-MODE-002 に沿うことを確認する。
+	got, err := MarshalAttempts(map[string]any{
+		"last_failure_at": "2026-01-02T03:04:05+09:00",
+		"failed_attempts": 4,
+		"format_version":  1,
+	})
+	if err != nil {
+		t.Fatalf("MarshalAttempts error: %v", err)
+	}
+
+	expected := "{\n" +
+		"  \"format_version\": 1,\n" +
+		"  \"failed_attempts\": 4,\n" +
+		"  \"last_failure_at\": \"2026-01-02T03:04:05+09:00\"\n" +
+		"}\n"
+	if string(got) != expected {
+		t.Fatalf("unexpected attempts JSON:\n%s", string(got))
+	}
+}
+
+func TestMarshalRemembered_KeyOrder(t *testing.T) {
+	// remembered.json のキー順が DD-MODE-003 に沿うことを確認する。
+	got, err := MarshalRemembered(map[string]any{
+		"tokens": []any{map[string]any{
+			"created_at":            "2026-01-02T03:04:05+09:00",
+			"credential_sha256_b64": "Yw==",
+			"token_sha256_b64":      "dA==",
+			"username":              "alice",
+			"id":                    "0a1b",
+		}},
+		"format_version": 1,
+	})
+	if err != nil {
+		t.Fatalf("MarshalRemembered error: %v", err)
+	}
+
+	expected := "{\n" +
+		"  \"format_version\": 1,\n" +
+		"  \"tokens\": [\n" +
+		"    {\n" +
+		"      \"id\": \"0a1b\",\n" +
+		"      \"username\": \"alice\",\n" +
+		"      \"token_sha256_b64\": \"dA==\",\n" +
+		"      \"credential_sha256_b64\": \"Yw==\",\n" +
+		"      \"created_at\": \"2026-01-02T03:04:05+09:00\"\n" +
+		"    }\n" +
+		"  ]\n" +
+		"}\n"
+	if string(got) != expected {
+		t.Fatalf("unexpected remembered JSON:\n%s", string(got))
+	}
+}
+
+func TestFormat_MarshalIssueAppliesLineEndingAndIndent(t *testing.T) {
+	// 整形の設定に従い改行コードとインデント幅のみを置き換え、文字列中の改行や空白は変えないことを確認する。
+	input := map[string]any{
+		"title":    "a\n  b",
+		"comments": []any{map[string]any{"body": "x"}},
+	}
+
+	got, err := Format{LineEnding: LineEndingCRLF, IndentWidth: 4}.MarshalIssue(input)
+	if err != nil {
+		t.Fatalf("MarshalIssue error: %v", err)
+	}
+	expected := "{\r\n" +
+		"    \"title\": \"a\\n  b\",\r\n" +
+		"    \"comments\": [\r\n" +
+		"        {\r\n" +
+		"            \"body\": \"x\"\r\n" +
+		"        }\r\n" +
+		"    ]\r\n" +
+		"}\r\n"
+	if string(got) != expected {
+		t.Fatalf("unexpected formatted JSON:\n%q", string(got))
+	}
+
+	canonical, err := MarshalIssue(input)
+	if err != nil {
+		t.Fatalf("MarshalIssue error: %v", err)
+	}
+	for _, format := range []Format{{}, DefaultFormat()} {
+		defaulted, defaultErr := format.MarshalIssue(input)
+		if defaultErr != nil || string(defaulted) != string(canonical) {
+			t.Fatalf("expected default format %+v to match canonical output, got %q err=%v", format, string(defaulted), defaultErr)
+		}
+	}
+}
+
+func TestFormat_Validate(t *testing.T) {
+	// 改行コードとインデント幅の範囲外の値を拒否することを確認する。
+	for _, format := range []Format{{}, DefaultFormat(), {LineEnding: LineEndingCRLF, IndentWidth: MaxIndentWidth}} {
+		if err := format.Validate(); err != nil {
+			t.Fatalf("expected %+v to be valid, got %v", format, err)
+		}
+	}
+	for _, format := range []Format{{LineEnding: "cr"}, {IndentWidth: -1}, {IndentWidth: MaxIndentWidth + 1}} {
+		if err := format.Validate(); err == nil {
+			t.Fatalf("expected %+v to be invalid", format)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// preserve.go は DD-DATA-007 の既存の JSON に現れたキーの並びの記録と、固定のキー順への合成を担い、
+// 整形そのものは扱わない。
+package jsonfmt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// recordOrder は DD-DATA-007 の JSON に現れたオブジェクトのキーの並びを、keyOrder の木として記録する。
+// 配列の要素はまとめて1つの並びとし、先に現れたキーを前に置く。
+func recordOrder(data []byte) (*keyOrder, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	recorded := &keyOrder{}
+	if err := recordValue(decoder, recorded); err != nil {
+		return nil, err
+	}
+	return recorded, nil
+}
+
+// recordValue は DD-DATA-007 の値1つを読み進め、オブジェクトのキーの並びを order に追記する。
+// 目的: 値を汎用構造へ変換せずにキーの出現順のみを取り出す。
+// 入力: decoder は読み取り位置が値の先頭にあるデコーダ、order は追記先。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: JSON として読めない場合に返す。
+// 副作用: decoder を値の末尾まで読み進め、order を更新する。
+// 並行性: 単一ゴルーチンでの利用を前提とする。
+// 不変条件: 同じキーは order に1度だけ記録する。
+// 関連DD: DD-DATA-007
+func recordValue(decoder *json.Decoder, order *keyOrder) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("read json: %w", err)
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return nil
+	}
+	switch delim {
+	case '{':
+		for decoder.More() {
+			keyToken, keyErr := decoder.Token()
+			if keyErr != nil {
+				return fmt.Errorf("read json: %w", keyErr)
+			}
+			key, _ := keyToken.(string)
+			if valueErr := recordValue(decoder, order.record(key)); valueErr != nil {
+				return valueErr
+			}
+		}
+	case '[':
+		for decoder.More() {
+			if valueErr := recordValue(decoder, order); valueErr != nil {
+				return valueErr
+			}
+		}
+	}
+	if _, closeErr := decoder.Token(); closeErr != nil {
+		return fmt.Errorf("read json: %w", closeErr)
+	}
+	return nil
+}
+
+// record は DD-DATA-007 のキーを初出であれば並びの末尾に加え、その値の並びを記録する keyOrder を返す。
+func (o *keyOrder) record(key string) *keyOrder {
+	child, ok := o.Children[key]
+	if ok {
+		return child
+	}
+	if o.Children == nil {
+		o.Children = map[string]*keyOrder{}
+	}
+	child = &keyOrder{}
+	o.Children[key] = child
+	o.Order = append(o.Order, key)
+	return child
+}
+
+// preserving は DD-DATA-007 の固定のキー順 fixed に、記録したキーの並び recorded のうち未知のキーを後ろに加えた順を返す。
+// ネストしたオブジェクトにも同じ規則を適用する。
+func preserving(fixed, recorded *keyOrder) *keyOrder {
+	if recorded == nil {
+		return fixed
+	}
+	merged := &keyOrder{Children: map[string]*keyOrder{}}
+	known := map[string]bool{}
+	if fixed != nil {
+		merged.Order = append(merged.Order, fixed.Order...)
+		for _, key := range fixed.Order {
+			known[key] = true
+		}
+		for key, child := range fixed.Children {
+			merged.Children[key] = preserving(child, recorded.Children[key])
+		}
+	}
+	for _, key := range recorded.Order {
+		if !known[key] {
+			merged.Order = append(merged.Order, key)
+		}
+		if _, ok := merged.Children[key]; !ok {
+			merged.Children[key] = preserving(orderChild(fixed, key), recorded.Children[key])
+		}
+	}
+	return merged
+}
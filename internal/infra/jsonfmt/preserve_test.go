@@ -0,0 +1,67 @@
+// preserve_test.go は書き換えの際に未知のキーの元の並びを保つ整形のテストを行う。
+package jsonfmt
+
+import "testing"
+
+func TestRewriteIssue_PreservesUnknownKeyOrder(t *testing.T) {
+	// 既知のキーは固定の順とし、未知のキーは元の出現順、元に無いキーは辞書順で後ろに並べることを確認する。
+	original := []byte(`{
+  "zeta": 1,
+  "version": 1,
+  "alpha": {"y": 1, "x": 2},
+  "comments": [{"body": "a", "z_note": "n", "comment_id": "c1"}, {"a_note": "m"}]
+}`)
+	value := map[string]any{
+		"version":  1,
+		"zeta":     1,
+		"alpha":    map[string]any{"x": 2, "y": 1},
+		"added":    true,
+		"comments": []any{map[string]any{"comment_id": "c1", "body": "a", "z_note": "n", "a_note": "m"}},
+	}
+
+	got, err := Format{PreserveUnknownKeyOrder: true}.RewriteIssue(value, original)
+	if err != nil {
+		t.Fatalf("RewriteIssue error: %v", err)
+	}
+	expected := "{\n" +
+		"  \"version\": 1,\n" +
+		"  \"comments\": [\n" +
+		"    {\n" +
+		"      \"comment_id\": \"c1\",\n" +
+		"      \"body\": \"a\",\n" +
+		"      \"z_note\": \"n\",\n" +
+		"      \"a_note\": \"m\"\n" +
+		"    }\n" +
+		"  ],\n" +
+		"  \"zeta\": 1,\n" +
+		"  \"alpha\": {\n" +
+		"    \"y\": 1,\n" +
+		"    \"x\": 2\n" +
+		"  },\n" +
+		"  \"added\": true\n" +
+		"}\n"
+	if string(got) != expected {
+		t.Fatalf("unexpected JSON:\n%s", got)
+	}
+}
+
+func TestRewriteIssue_FallsBackToFixedOrder(t *testing.T) {
+	// 設定していない場合や元の内容が読めない場合は、未知のキーを辞書順に並べることを確認する。
+	value := map[string]any{"version": 1, "zeta": 1, "alpha": 2}
+	expected, err := MarshalIssue(value)
+	if err != nil {
+		t.Fatalf("MarshalIssue error: %v", err)
+	}
+	for _, tc := range []struct {
+		format   Format
+		original string
+	}{
+		{Format{}, `{"zeta": 1, "alpha": 2}`},
+		{Format{PreserveUnknownKeyOrder: true}, `{broken`},
+	} {
+		got, rewriteErr := tc.format.RewriteIssue(value, []byte(tc.original))
+		if rewriteErr != nil || string(got) != string(expected) {
+			t.Fatalf("expected fixed order for %+v, got %s err=%v", tc.format, got, rewriteErr)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// Package keychain は OS の資格情報ストア (Windows の資格情報マネージャー、macOS のキーチェーン) への
+// 秘密情報の保存・取得・削除を担い、保存する内容の意味や検証は扱わない。
+// 対応していない OS では ErrUnsupported を返す。
+package keychain
+
+import (
+	"errors"
+
+	"ratta/internal/domain/apperr"
+)
+
+var (
+	// ErrNotFound は DD-MODE-003 の指定した項目が資格情報ストアに無いことを表す。
+	ErrNotFound = apperr.New(apperr.ErrNotFound, "keychain item not found")
+	// ErrUnsupported は DD-MODE-003 の実行中の OS で資格情報ストアを利用できないことを表す。
+	ErrUnsupported = errors.New("keychain is not supported on this platform")
+)
+
+// Supported は DD-MODE-003 の実行中の OS で資格情報ストアを利用できるかを返す。
+func Supported() bool {
+	return supported
+}
+
+// Get は DD-MODE-003 の service と account で識別する項目の秘密情報を返す。項目が無い場合は ErrNotFound を返す。
+func Get(service, account string) (string, error) {
+	return getItem(service, account)
+}
+
+// Set は DD-MODE-003 の service と account で識別する項目に秘密情報を保存する。既存の項目は置き換える。
+func Set(service, account, secret string) error {
+	return setItem(service, account, secret)
+}
+
+// Delete は DD-MODE-003 の service と account で識別する項目を削除する。項目が無い場合も成功とする。
+func Delete(service, account string) error {
+	return deleteItem(service, account)
+}
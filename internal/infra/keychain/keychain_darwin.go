@@ -0,0 +1,103 @@
+//go:build darwin
+
+// keychain_darwin.go は macOS のキーチェーンへの保存を security コマンドで行う実装を担う。
+// 秘密情報はプロセス一覧から見えないよう、引数ではなく対話モードの標準入力で渡す。
+package keychain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const (
+	// supported は DD-MODE-003 の資格情報ストアを利用できるかを表す。
+	supported = true
+	// securityPath は DD-MODE-003 の macOS に標準で含まれる security コマンドのパスを表す。
+	securityPath = "/usr/bin/security"
+	// exitItemNotFound は DD-MODE-003 の security コマンドが項目を見つけられなかった場合の終了コードを表す。
+	exitItemNotFound = 44
+)
+
+// getItem は DD-MODE-003 の macOS のキーチェーンから汎用パスワードを読み取る。
+// 目的: service と account で識別する項目の秘密情報を security find-generic-password で取得する。
+// 入力: service はサービス名、account はアカウント名。
+// 出力: 秘密情報 (末尾の改行を除く) とエラー。
+// エラー: 項目が無い場合は ErrNotFound、security コマンドの失敗時はその標準エラーを含むエラーを返す。
+// 副作用: security コマンドを実行する。キーチェーンがロックされている場合は OS が解除を求めることがある。
+// 並行性: 呼び出しごとに別プロセスを起動するためスレッドセーフ。
+// 不変条件: キーチェーンの内容は変更しない。
+// 関連DD: DD-MODE-003
+func getItem(service, account string) (string, error) {
+	// #nosec G204 -- 固定のコマンドに引数を個別に渡し、シェルを経由しない。
+	cmd := exec.Command(securityPath, "find-generic-password", "-s", service, "-a", account, "-w")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", securityError("find-generic-password", err, stderr.String())
+	}
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
+
+// setItem は DD-MODE-003 の macOS のキーチェーンへ汎用パスワードを保存する。
+// 目的: service と account で識別する項目に秘密情報を保存し、既存の項目は -U で置き換える。
+// 入力: service はサービス名、account はアカウント名、secret は保存する秘密情報。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: security コマンドの失敗時にその標準エラーを含むエラーを返す。
+// 副作用: security コマンドを対話モードで実行し、キーチェーンの項目を作成または置換する。
+// 並行性: 同一項目への同時保存は後に完了した内容が残る。
+// 不変条件: 秘密情報はコマンドの引数に含めず、プロセス一覧から見えない標準入力で渡す。
+// 関連DD: DD-MODE-003
+func setItem(service, account, secret string) error {
+	script := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", quote(service), quote(account), quote(secret))
+	cmd := exec.Command(securityPath, "-i")
+	cmd.Stdin = strings.NewReader(script)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return securityError("add-generic-password", err, stderr.String())
+	}
+	return nil
+}
+
+// deleteItem は DD-MODE-003 の macOS のキーチェーンから汎用パスワードを削除する。
+// 目的: service と account で識別する項目を security delete-generic-password で削除する。
+// 入力: service はサービス名、account はアカウント名。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: 項目が無い場合を除く security コマンドの失敗時に、その標準エラーを含むエラーを返す。
+// 副作用: security コマンドを実行し、キーチェーンの項目を削除する。
+// 並行性: 呼び出しごとに別プロセスを起動するためスレッドセーフ。
+// 不変条件: 項目が無い場合も成功とし、削除を繰り返しても結果は変わらない。
+// 関連DD: DD-MODE-003
+func deleteItem(service, account string) error {
+	// #nosec G204 -- 固定のコマンドに引数を個別に渡し、シェルを経由しない。
+	cmd := exec.Command(securityPath, "delete-generic-password", "-s", service, "-a", account)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+	if mapped := securityError("delete-generic-password", err, stderr.String()); !errors.Is(mapped, ErrNotFound) {
+		return mapped
+	}
+	return nil
+}
+
+// securityError は DD-MODE-003 の security コマンドの失敗を、項目が無い場合は ErrNotFound に変換する。
+func securityError(action string, err error, stderr string) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == exitItemNotFound {
+		return ErrNotFound
+	}
+	return fmt.Errorf("security %s: %w: %s", action, err, strings.TrimSpace(stderr))
+}
+
+// quote は DD-MODE-003 の security コマンドの対話モードで1つの引数として扱われるよう、二重引用符で囲む。
+func quote(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + replacer.Replace(value) + `"`
+}
@@ -0,0 +1,46 @@
+//go:build !windows && !darwin
+
+// keychain_other.go は資格情報ストアに対応していない OS の実装を担い、常に ErrUnsupported を返す。
+package keychain
+
+// supported は DD-MODE-003 の資格情報ストアを利用できるかを表し、この OS では利用できない。
+const supported = false
+
+// getItem は DD-MODE-003 の秘密情報の取得を行う。
+// 目的: 資格情報ストアの無い OS で、呼び出し側が保存済みの秘密情報を使わない経路へ切り替えられるようにする。
+// 入力: service と account は使用しない。
+// 出力: 空の秘密情報と ErrUnsupported。
+// エラー: 常に ErrUnsupported を返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: ErrNotFound は返さず、項目が無いことと対応していないことを区別できるようにする。
+// 関連DD: DD-MODE-003
+func getItem(_, _ string) (string, error) {
+	return "", ErrUnsupported
+}
+
+// setItem は DD-MODE-003 の秘密情報の保存を行う。
+// 目的: 資格情報ストアの無い OS で、秘密情報を平文のファイルなど別の場所へ黙って保存しないようにする。
+// 入力: service、account、secret は使用しない。
+// 出力: ErrUnsupported。
+// エラー: 常に ErrUnsupported を返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 秘密情報をどこにも書き込まない。
+// 関連DD: DD-MODE-003
+func setItem(_, _, _ string) error {
+	return ErrUnsupported
+}
+
+// deleteItem は DD-MODE-003 の秘密情報の削除を行う。
+// 目的: 資格情報ストアの無い OS で、削除できたと誤って扱わないようにする。
+// 入力: service と account は使用しない。
+// 出力: ErrUnsupported。
+// エラー: 常に ErrUnsupported を返す。
+// 副作用: なし。
+// 並行性: スレッドセーフ。
+// 不変条件: 他の OS と異なり、項目が無い場合の成功は返さない。
+// 関連DD: DD-MODE-003
+func deleteItem(_, _ string) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,25 @@
+//go:build !windows && !darwin
+
+// keychain_other_test.go は資格情報ストアに対応していない OS での動作のテストを行う。
+package keychain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnsupportedPlatform(t *testing.T) {
+	// 対応していない OS では利用不可を返し、読み書きとも ErrUnsupported になることを確認する。
+	if Supported() {
+		t.Fatal("expected keychain to be unsupported")
+	}
+	if _, err := Get("ratta", "account"); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected unsupported on get, got %v", err)
+	}
+	if err := Set("ratta", "account", "secret"); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected unsupported on set, got %v", err)
+	}
+	if err := Delete("ratta", "account"); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected unsupported on delete, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+//go:build windows
+
+// keychain_windows.go は Windows の資格情報マネージャーへの保存を advapi32 の Cred* API で行う実装を担う。
+// 項目は汎用資格情報として、"service:account" を対象名に保存する。
+package keychain
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	// supported は DD-MODE-003 の資格情報ストアを利用できるかを表す。
+	supported = true
+	// credTypeGeneric は DD-MODE-003 の CRED_TYPE_GENERIC を表す。
+	credTypeGeneric = 1
+	// credPersistLocalMachine は DD-MODE-003 の CRED_PERSIST_LOCAL_MACHINE を表し、この端末のこのユーザーに限って保存する。
+	credPersistLocalMachine = 2
+)
+
+var (
+	advapi32        = windows.NewLazySystemDLL("advapi32.dll")
+	procCredReadW   = advapi32.NewProc("CredReadW")
+	procCredWriteW  = advapi32.NewProc("CredWriteW")
+	procCredDeleteW = advapi32.NewProc("CredDeleteW")
+	procCredFree    = advapi32.NewProc("CredFree")
+)
+
+// credential は DD-MODE-003 の Win32 の CREDENTIALW 構造体を表す。
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// getItem は DD-MODE-003 の資格情報マネージャーから汎用資格情報を読み取る。
+// 目的: service と account で識別する項目の秘密情報を CredReadW で取得する。
+// 入力: service はサービス名、account はアカウント名。
+// 出力: 秘密情報とエラー。保存した秘密情報が空の場合は空文字列を返す。
+// エラー: 対象名の変換失敗時、項目が無い場合は ErrNotFound、その他の API の失敗時はラップしたエラーを返す。
+// 副作用: advapi32.dll を読み込み、API が確保した領域を CredFree で解放する。
+// 並行性: API 呼び出しのみでスレッドセーフ。
+// 不変条件: 資格情報マネージャーの内容は変更しない。
+// 関連DD: DD-MODE-003
+func getItem(service, account string) (string, error) {
+	target, err := windows.UTF16PtrFromString(targetName(service, account))
+	if err != nil {
+		return "", fmt.Errorf("encode target: %w", err)
+	}
+	var cred *credential
+	result, _, callErr := procCredReadW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if result == 0 {
+		return "", credError("read", callErr)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+	if cred.CredentialBlobSize == 0 {
+		return "", nil
+	}
+	return string(unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)), nil
+}
+
+// setItem は DD-MODE-003 の資格情報マネージャーへ汎用資格情報を保存する。
+// 目的: service と account で識別する項目に秘密情報を CredWriteW で保存し、既存の項目は置き換える。
+// 入力: service はサービス名、account はアカウント名 (資格情報のユーザー名にも用いる)、secret は保存する秘密情報。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: 対象名・アカウント名の変換失敗時や API の失敗時に返す。
+// 副作用: 資格情報マネージャーの項目を作成または置換する。
+// 並行性: 同一項目への同時保存は後に完了した内容が残る。
+// 不変条件: 保存範囲はこの端末のこのユーザーに限り (CRED_PERSIST_LOCAL_MACHINE)、ローミングしない。
+// 関連DD: DD-MODE-003
+func setItem(service, account, secret string) error {
+	target, err := windows.UTF16PtrFromString(targetName(service, account))
+	if err != nil {
+		return fmt.Errorf("encode target: %w", err)
+	}
+	userName, err := windows.UTF16PtrFromString(account)
+	if err != nil {
+		return fmt.Errorf("encode account: %w", err)
+	}
+	blob := []byte(secret)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)), // #nosec G115 -- 保存する秘密情報は数百バイト程度に限られる。
+		Persist:            credPersistLocalMachine,
+		UserName:           userName,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+	result, _, callErr := procCredWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0)
+	if result == 0 {
+		return credError("write", callErr)
+	}
+	return nil
+}
+
+// deleteItem は DD-MODE-003 の資格情報マネージャーから汎用資格情報を削除する。
+// 目的: service と account で識別する項目を CredDeleteW で削除する。
+// 入力: service はサービス名、account はアカウント名。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: 対象名の変換失敗時や、項目が無い場合を除く API の失敗時に返す。
+// 副作用: 資格情報マネージャーの項目を削除する。
+// 並行性: API 呼び出しのみでスレッドセーフ。
+// 不変条件: 項目が無い場合も成功とし、削除を繰り返しても結果は変わらない。
+// 関連DD: DD-MODE-003
+func deleteItem(service, account string) error {
+	target, err := windows.UTF16PtrFromString(targetName(service, account))
+	if err != nil {
+		return fmt.Errorf("encode target: %w", err)
+	}
+	result, _, callErr := procCredDeleteW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0)
+	if result == 0 {
+		if mapped := credError("delete", callErr); !errors.Is(mapped, ErrNotFound) {
+			return mapped
+		}
+	}
+	return nil
+}
+
+// targetName は DD-MODE-003 の資格情報マネージャーの対象名を service と account から組み立てる。
+func targetName(service, account string) string {
+	return service + ":" + account
+}
+
+// credError は DD-MODE-003 の Cred* API の失敗を、項目が無い場合は ErrNotFound に変換する。
+func credError(action string, err error) error {
+	if errors.Is(err, windows.ERROR_NOT_FOUND) {
+		return ErrNotFound
+	}
+	return fmt.Errorf("%s credential: %w", action, err)
+}
@@ -0,0 +1,74 @@
+// Package keylock はキー単位の読み書きロックを提供し、キーの命名や取得順序の規則は利用側に委ねる。
+// 使われなくなったキーのロックは解放し、課題数に比例してメモリが増え続けないようにする。
+package keylock
+
+import "sync"
+
+// Map は DD-LOCK-001 のキー単位のロックの集合を表す。ゼロ値は使用できないため New で生成する。
+type Map struct {
+	mu    sync.Mutex
+	locks map[string]*entry
+}
+
+// entry は DD-LOCK-001 の1キー分のロックと参照数を表す。
+type entry struct {
+	rw   sync.RWMutex
+	refs int
+}
+
+// New は DD-LOCK-001 の空のロック集合を生成する。
+func New() *Map {
+	return &Map{locks: make(map[string]*entry)}
+}
+
+// Lock は DD-LOCK-001 の key の排他ロックを取得し、解放する関数を返す。
+// 解放関数は1回だけ呼び出す。
+func (m *Map) Lock(key string) func() {
+	e := m.acquire(key)
+	e.rw.Lock()
+	return func() {
+		e.rw.Unlock()
+		m.release(key, e)
+	}
+}
+
+// RLock は DD-LOCK-001 の key の共有ロックを取得し、解放する関数を返す。
+// 解放関数は1回だけ呼び出す。
+func (m *Map) RLock(key string) func() {
+	e := m.acquire(key)
+	e.rw.RLock()
+	return func() {
+		e.rw.RUnlock()
+		m.release(key, e)
+	}
+}
+
+// acquire は DD-LOCK-001 の key のロックを参照数を増やして返す。
+func (m *Map) acquire(key string) *entry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.locks[key]
+	if !ok {
+		e = &entry{}
+		m.locks[key] = e
+	}
+	e.refs++
+	return e
+}
+
+// release は DD-LOCK-001 の参照数を減らし、誰も参照しなくなったロックを破棄する。
+func (m *Map) release(key string, e *entry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e.refs--
+	if e.refs == 0 {
+		delete(m.locks, key)
+	}
+}
+
+// size は DD-LOCK-001 の保持しているロック数を返す。
+func (m *Map) size() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.locks)
+}
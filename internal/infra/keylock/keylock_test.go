@@ -0,0 +1,80 @@
+// keylock_test.go はキー単位のロックの排他と解放のテストを行う。
+package keylock
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLock_SerializesSameKeyOnly(t *testing.T) {
+	// 同じキーの排他ロックは直列化され、異なるキーは互いに待たないことを確認する。
+	m := New()
+	var mu sync.Mutex
+	inside := 0
+	peak := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := m.Lock("issue")
+			defer unlock()
+			mu.Lock()
+			inside++
+			peak = max(peak, inside)
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			inside--
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if peak != 1 {
+		t.Fatalf("expected exclusive access, peak=%d", peak)
+	}
+
+	unlockA := m.Lock("a")
+	done := make(chan struct{})
+	go func() {
+		unlockB := m.Lock("b")
+		unlockB()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("different keys must not block each other")
+	}
+	unlockA()
+	if m.size() != 0 {
+		t.Fatalf("expected released locks to be dropped, got %d", m.size())
+	}
+}
+
+func TestRLock_SharedUntilExclusive(t *testing.T) {
+	// 共有ロック同士は同時に取得でき、排他ロックは共有ロックの解放を待つことを確認する。
+	m := New()
+	unlockFirst := m.RLock("category")
+	unlockSecond := m.RLock("category")
+
+	acquired := make(chan struct{})
+	go func() {
+		unlock := m.Lock("category")
+		close(acquired)
+		unlock()
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("exclusive lock must wait for readers")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unlockFirst()
+	unlockSecond()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("exclusive lock not acquired after readers released")
+	}
+}
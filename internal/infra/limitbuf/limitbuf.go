@@ -0,0 +1,52 @@
+// Package limitbuf は DD-HOOK-003/DD-PLUGIN-001 の外部プロセスの出力を上限のバイト数まで保持することを担い、
+// プロセスの起動や出力の解釈は扱わない。起動と解釈はフックスクリプト・プラグインの各パッケージが担う。
+package limitbuf
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Buffer は DD-HOOK-003/DD-PLUGIN-001 の外部プロセスの出力を上限まで保持し、残りを読み捨てる io.Writer を表す。
+// プロセスが書き込みで止まらないよう、読み捨てた分も書き込めたものとして返す。
+type Buffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// New は DD-HOOK-003/DD-PLUGIN-001 の limit バイトまで保持する Buffer を返す。
+func New(limit int) *Buffer {
+	return &Buffer{limit: limit}
+}
+
+// Write は io.Writer の書き込みを行う。exec は Stdout と Stderr が同じ値の場合も含め、1つの出力先へ同時には書き込まない。
+func (b *Buffer) Write(p []byte) (int, error) {
+	remaining := b.limit - b.buf.Len()
+	if len(p) > remaining {
+		b.truncated = true
+		b.buf.Write(p[:max(remaining, 0)])
+		return len(p), nil
+	}
+	b.buf.Write(p)
+	return len(p), nil
+}
+
+// Bytes は保持した出力をそのまま返す。
+func (b *Buffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+// Truncated は上限を超えて読み捨てた出力があるかを返す。
+func (b *Buffer) Truncated() bool {
+	return b.truncated
+}
+
+// String は保持した出力の前後の空白を除いて返す。読み捨てた場合は末尾に省略の印を付ける。
+func (b *Buffer) String() string {
+	text := strings.TrimSpace(b.buf.String())
+	if b.truncated {
+		text += " ..."
+	}
+	return text
+}
@@ -0,0 +1,32 @@
+// limitbuf_test.go は外部プロセスの出力を上限まで保持するバッファのテストを行う。
+package limitbuf
+
+import "testing"
+
+func TestBuffer_TruncatesOutput(t *testing.T) {
+	// 上限を超えた出力は読み捨てても書き込めたものとして返し、省略の印を付けることを確認する。
+	buffer := New(4)
+	if n, err := buffer.Write([]byte("abcdef")); n != 6 || err != nil {
+		t.Fatalf("unexpected write result: %d %v", n, err)
+	}
+	if n, _ := buffer.Write([]byte("gh")); n != 2 {
+		t.Fatalf("unexpected write after limit: %d", n)
+	}
+	if got := buffer.String(); got != "abcd ..." {
+		t.Fatalf("unexpected output: %q", got)
+	}
+	if !buffer.Truncated() || string(buffer.Bytes()) != "abcd" {
+		t.Fatalf("unexpected buffer state: %v %q", buffer.Truncated(), buffer.Bytes())
+	}
+}
+
+func TestBuffer_KeepsOutputWithinLimit(t *testing.T) {
+	// 上限内の出力は省略の印を付けず、前後の空白を除いて返すことを確認する。
+	buffer := New(8)
+	if _, err := buffer.Write([]byte(" ok\n")); err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	if buffer.Truncated() || buffer.String() != "ok" {
+		t.Fatalf("unexpected buffer state: %v %q", buffer.Truncated(), buffer.String())
+	}
+}
@@ -13,10 +13,16 @@ import (
 )
 
 const (
-	maxSizeBytes   = 1 << 20
-	maxGenerations = 3
+	// defaultMaxSizeBytes と defaultMaxGenerations は DD-LOG-003 のローテーションの既定値を表す。
+	defaultMaxSizeBytes   = 1 << 20
+	defaultMaxGenerations = 3
 )
 
+// CategoryAudit は DD-LOG-005 の監査ログの記録に付ける category の値を表す。
+const CategoryAudit = "audit"
+
+var hostname = os.Hostname
+
 type Level int
 
 const (
@@ -25,18 +31,53 @@ const (
 	LevelError
 )
 
+// Rotation は DD-LOG-003 のローテーションの設定を表す。
+// MaxSizeBytes は1ファイルの上限、MaxGenerations は残す世代数を表し、0 以下の場合は既定値 (1MB、3世代) を用いる。
+type Rotation struct {
+	MaxSizeBytes   int64
+	MaxGenerations int
+}
+
+// withDefaults は DD-LOG-003 の未設定の項目を既定値で補ったローテーションの設定を返す。
+func (r Rotation) withDefaults() Rotation {
+	if r.MaxSizeBytes <= 0 {
+		r.MaxSizeBytes = defaultMaxSizeBytes
+	}
+	if r.MaxGenerations <= 0 {
+		r.MaxGenerations = defaultMaxGenerations
+	}
+	return r
+}
+
 // Logger は BD-FILES-003 に従った構造化ログを提供する。
 type Logger struct {
-	mu   sync.Mutex
-	path string
-	lvl  Level
+	mu       sync.Mutex
+	path     string
+	lvl      Level
+	rotation Rotation
+	sinks    []sinkEntry
 }
 
 // NewLogger は DD-BE-002 に従い実行ファイルと同じディレクトリの logs/ratta.log を使う。
-func NewLogger(exePath string, level Level) *Logger {
+// rotation は DD-LOG-003 の config.json の log.max_size_mb・log.max_generations から渡し、未設定の項目は既定値を用いる。
+func NewLogger(exePath string, level Level, rotation Rotation) *Logger {
 	return &Logger{
-		path: filepath.Join(filepath.Dir(exePath), "logs", "ratta.log"),
-		lvl:  level,
+		path:     filepath.Join(filepath.Dir(exePath), "logs", "ratta.log"),
+		lvl:      level,
+		rotation: rotation.withDefaults(),
+	}
+}
+
+// ParseLevel は DD-DATA-001 の config.json の log.level をログレベルに変換する。
+// 設定で指定できる info と debug のみを受け付ける。
+func ParseLevel(value string) (Level, error) {
+	switch value {
+	case "info":
+		return LevelInfo, nil
+	case "debug":
+		return LevelDebug, nil
+	default:
+		return LevelInfo, fmt.Errorf("unsupported log level: %q", value)
 	}
 }
 
@@ -62,6 +103,33 @@ func (l *Logger) Error(message string, fields map[string]any) {
 	l.write(LevelError, message, fields)
 }
 
+// Audit は DD-LOG-005 の監査ログを記録する。
+// 目的: モードの切り替えや認証の成否を、後から第三者が確認できる形で残す。
+// 入力: event は監査イベント名、fields は追加フィールド。
+// 出力: なし。
+// エラー: 内部でエラーが発生した場合は出力を中断する。
+// 副作用: ログファイルへの追記とローテーションを行う。
+// 並行性: Logger の mutex で排他制御する。
+// 不変条件: ログレベルの設定にかかわらず info として記録し、category・event・machine を付ける。
+// 関連DD: DD-LOG-005, DD-BE-002
+func (l *Logger) Audit(event string, fields map[string]any) {
+	record := make(map[string]any, len(fields)+3)
+	for key, value := range fields {
+		record[key] = value
+	}
+	record["category"] = CategoryAudit
+	record["event"] = event
+	machine, err := hostname()
+	if err != nil {
+		machine = ""
+	}
+	record["machine"] = machine
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.appendRecord(LevelInfo, "audit: "+event, record)
+}
+
 // write は DD-BE-002/BD-FILES-003 のフォーマットでログ行を出力する。
 // 目的: 指定レベル以上のログを構造化形式で追記する。
 // 入力: level はログレベル、message は本文、fields は追加フィールド。
@@ -78,15 +146,12 @@ func (l *Logger) write(level Level, message string, fields map[string]any) {
 	if level < l.lvl {
 		return
 	}
+	l.appendRecord(level, message, fields)
+}
 
-	if err := ensureDir(filepath.Dir(l.path)); err != nil {
-		return
-	}
-
-	if err := rotateIfNeeded(l.path); err != nil {
-		return
-	}
-
+// appendRecord は DD-BE-002/BD-FILES-003 のログ行をレベルの判定なしに追記する。呼び出し側で mutex を保持する。
+// シンクへの転送は ratta.log へ書き込めない場合も行う。
+func (l *Logger) appendRecord(level Level, message string, fields map[string]any) {
 	record := map[string]any{
 		"timestamp": time.Now().Format(time.RFC3339),
 		"level":     levelString(level),
@@ -100,6 +165,14 @@ func (l *Logger) write(level Level, message string, fields map[string]any) {
 	if err != nil {
 		return
 	}
+	l.forward(level, line)
+
+	if err := ensureDir(filepath.Dir(l.path)); err != nil {
+		return
+	}
+	if err := rotateIfNeeded(l.path, l.rotation); err != nil {
+		return
+	}
 	line = append(line, '\n')
 
 	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
@@ -157,14 +230,14 @@ func ensureDir(dir string) error {
 
 // rotateIfNeeded は BD-FILES-003 のローテーション仕様に従う。
 // 目的: サイズ上限を超えたログの世代管理を行う。
-// 入力: path はログファイルのパス。
+// 入力: path はログファイルのパス、rotation は既定値を補ったローテーションの設定。
 // 出力: 成功時は nil、失敗時はエラー。
 // エラー: 取得・リネーム・削除に失敗した場合に返す。
 // 副作用: ログファイルの移動・削除を行う。
 // 並行性: 同時ローテーションは想定しない。
-// 不変条件: 世代数は maxGenerations 以内に収める。
-// 関連DD: BD-FILES-003
-func rotateIfNeeded(path string) error {
+// 不変条件: 世代数は rotation.MaxGenerations 以内に収める。世代数を減らした場合、それを超える古い世代は削除しない。
+// 関連DD: BD-FILES-003, DD-LOG-003
+func rotateIfNeeded(path string, rotation Rotation) error {
 	info, err := os.Stat(path)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -172,10 +245,11 @@ func rotateIfNeeded(path string) error {
 		}
 		return fmt.Errorf("stat log: %w", err)
 	}
-	if info.Size() < maxSizeBytes {
+	if info.Size() < rotation.MaxSizeBytes {
 		return nil
 	}
 
+	maxGenerations := rotation.MaxGenerations
 	for i := maxGenerations; i >= 1; i-- {
 		if i == maxGenerations {
 			removeErr := os.Remove(fmt.Sprintf("%s.%d", path, i))
@@ -13,7 +13,7 @@ func TestRotateIfNeeded_RotatesAndKeepsGenerations(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "ratta.log")
 
-	if err := os.WriteFile(path, make([]byte, maxSizeBytes+1), 0o600); err != nil {
+	if err := os.WriteFile(path, make([]byte, defaultMaxSizeBytes+1), 0o600); err != nil {
 		t.Fatalf("write base log: %v", err)
 	}
 	if err := os.WriteFile(path+".1", []byte("gen1"), 0o600); err != nil {
@@ -26,7 +26,7 @@ func TestRotateIfNeeded_RotatesAndKeepsGenerations(t *testing.T) {
 		t.Fatalf("write gen3: %v", err)
 	}
 
-	if err := rotateIfNeeded(path); err != nil {
+	if err := rotateIfNeeded(path, Rotation{}.withDefaults()); err != nil {
 		t.Fatalf("rotateIfNeeded error: %v", err)
 	}
 
@@ -44,10 +44,43 @@ func TestRotateIfNeeded_RotatesAndKeepsGenerations(t *testing.T) {
 	}
 }
 
+func TestRotateIfNeeded_UsesConfiguredRotation(t *testing.T) {
+	// 指定したサイズでローテーションが行われ、指定した世代数を超える世代は残らないことを確認する。
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ratta.log")
+	rotation := Rotation{MaxSizeBytes: 16, MaxGenerations: 2}.withDefaults()
+
+	if err := os.WriteFile(path, make([]byte, 16), 0o600); err != nil {
+		t.Fatalf("write base log: %v", err)
+	}
+	if err := os.WriteFile(path+".1", []byte("gen1"), 0o600); err != nil {
+		t.Fatalf("write gen1: %v", err)
+	}
+	if err := os.WriteFile(path+".2", []byte("gen2"), 0o600); err != nil {
+		t.Fatalf("write gen2: %v", err)
+	}
+
+	if err := rotateIfNeeded(path, rotation); err != nil {
+		t.Fatalf("rotateIfNeeded error: %v", err)
+	}
+
+	if _, statErr := os.Stat(path + ".3"); !os.IsNotExist(statErr) {
+		t.Fatalf("expected no generation beyond max, err=%v", statErr)
+	}
+	// #nosec G304 -- テスト用ディレクトリ配下のログのみを読むため安全。
+	data, readErr := os.ReadFile(path + ".2")
+	if readErr != nil || string(data) != "gen1" {
+		t.Fatalf("expected generation 1 to shift to 2, data=%q err=%v", data, readErr)
+	}
+	if _, statErr := os.Stat(path); !os.IsNotExist(statErr) {
+		t.Fatalf("expected base log to be rotated, err=%v", statErr)
+	}
+}
+
 func TestLogger_WritesStructuredLog(t *testing.T) {
 	// JSON 形式でログが追記されることを確認する。
 	dir := t.TempDir()
-	logger := NewLogger(filepath.Join(dir, "ratta.exe"), LevelInfo)
+	logger := NewLogger(filepath.Join(dir, "ratta.exe"), LevelInfo, Rotation{})
 
 	logger.Info("hello", map[string]any{
 		"detail": "value",
@@ -76,7 +109,7 @@ func TestLogger_WritesStructuredLog(t *testing.T) {
 func TestLogger_RespectsLevel(t *testing.T) {
 	// ログレベルで出力が制御されることを確認する。
 	dir := t.TempDir()
-	logger := NewLogger(filepath.Join(dir, "ratta.exe"), LevelError)
+	logger := NewLogger(filepath.Join(dir, "ratta.exe"), LevelError, Rotation{})
 
 	logger.Info("skip", nil)
 
@@ -88,7 +121,7 @@ func TestLogger_RespectsLevel(t *testing.T) {
 func TestLogger_DebugAndError(t *testing.T) {
 	// Debug と Error が出力されることを確認する。
 	dir := t.TempDir()
-	logger := NewLogger(filepath.Join(dir, "ratta.exe"), LevelDebug)
+	logger := NewLogger(filepath.Join(dir, "ratta.exe"), LevelDebug, Rotation{})
 
 	logger.Debug("debug", map[string]any{"k": "v"})
 	logger.Error("error", map[string]any{"k": "v"})
@@ -110,9 +143,24 @@ func TestLevelString_Default(t *testing.T) {
 	}
 }
 
+func TestParseLevel_AcceptsConfigValues(t *testing.T) {
+	// 設定で指定できる info と debug のみを受け付けることを確認する。
+	if level, err := ParseLevel("debug"); err != nil || level != LevelDebug {
+		t.Fatalf("unexpected debug result: %v, %v", level, err)
+	}
+	if level, err := ParseLevel("info"); err != nil || level != LevelInfo {
+		t.Fatalf("unexpected info result: %v, %v", level, err)
+	}
+	for _, value := range []string{"", "error", "DEBUG"} {
+		if _, err := ParseLevel(value); err == nil {
+			t.Fatalf("expected %q to be rejected", value)
+		}
+	}
+}
+
 func TestSetLevel_ChangesLevel(t *testing.T) {
 	// SetLevel がログレベルを更新することを確認する。
-	logger := NewLogger("ratta.exe", LevelInfo)
+	logger := NewLogger("ratta.exe", LevelInfo, Rotation{})
 	logger.SetLevel(LevelError)
 	if logger.lvl != LevelError {
 		t.Fatalf("unexpected level: %v", logger.lvl)
@@ -134,7 +182,7 @@ func TestEnsureDir_Error(t *testing.T) {
 func TestLogger_DebugBelowLevel(t *testing.T) {
 	// 出力レベル未満のログが出力されないことを確認する。
 	dir := t.TempDir()
-	logger := NewLogger(filepath.Join(dir, "ratta.exe"), LevelError)
+	logger := NewLogger(filepath.Join(dir, "ratta.exe"), LevelError, Rotation{})
 
 	logger.Debug("debug", nil)
 
@@ -142,3 +190,30 @@ func TestLogger_DebugBelowLevel(t *testing.T) {
 		t.Fatalf("expected no log output, err=%v", statErr)
 	}
 }
+
+func TestLogger_AuditIgnoresLevelAndFiltersByCategory(t *testing.T) {
+	// 監査ログはログレベルにかかわらず端末名とともに記録され、category で絞り込めることを確認する。
+	original := hostname
+	hostname = func() (string, error) { return "pc-01", nil }
+	t.Cleanup(func() { hostname = original })
+	logger := NewLogger(filepath.Join(t.TempDir(), "ratta.exe"), LevelInfo, Rotation{})
+	logger.Info("noise", nil)
+	logger.SetLevel(LevelError)
+
+	logger.Audit("mode_locked", map[string]any{"reason": "idle", "category": "spoofed"})
+
+	result, err := logger.Read(Query{Category: CategoryAudit})
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if len(result.Entries) != 1 {
+		t.Fatalf("expected one audit entry, got %+v", result.Entries)
+	}
+	entry := result.Entries[0]
+	if entry.Level != "info" || entry.Fields["event"] != "mode_locked" || entry.Fields["machine"] != "pc-01" || entry.Fields["reason"] != "idle" {
+		t.Fatalf("unexpected audit entry: %+v", entry)
+	}
+	if entry.Timestamp.IsZero() {
+		t.Fatal("expected audit entry to have timestamp")
+	}
+}
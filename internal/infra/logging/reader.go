@@ -0,0 +1,175 @@
+// reader.go はログファイルと世代ファイルの読み取り・絞り込みを担い、画面表示や書き込みは扱わない。
+// 解析できない行は手編集や書き込み途中の行とみなして読み飛ばす。
+package logging
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// defaultReadLimit は DD-LOG-001 の件数指定がない場合に返す件数を表す。
+	defaultReadLimit = 200
+	// maxReadLimit は DD-LOG-001 の1回で返す件数の上限を表す。世代を含めても数万行に収まるが、UI の描画量を抑える。
+	maxReadLimit = 5000
+	// maxLineBytes は DD-LOG-001 の1行として読み取る上限を表す。大きなフィールドを含む行も読み飛ばさないよう既定より広げる。
+	maxLineBytes = 1 << 20
+)
+
+// Query は DD-LOG-001 のログの絞り込み条件を表す。
+// MinLevel 以上のレベル、[Since, Until] の範囲、Category が指定された場合はその category の記録のうち、
+// 新しい方から Limit 件を返す。ゼロ値の条件は絞り込まない。
+type Query struct {
+	MinLevel Level
+	Since    time.Time
+	Until    time.Time
+	Category string
+	Limit    int
+}
+
+// Entry は DD-LOG-001 のログ1件を表す。Fields は timestamp/level/message 以外のフィールドを保持する。
+type Entry struct {
+	Timestamp time.Time
+	Level     string
+	Message   string
+	Fields    map[string]any
+}
+
+// Result は DD-LOG-001 のログの読み取り結果を表す。Truncated は条件に合う記録が Limit を超えたことを表す。
+type Result struct {
+	Entries   []Entry
+	Truncated bool
+}
+
+// Read は DD-LOG-001 のログの読み取りを行う。
+// 目的: ログファイルを探さずに UI からエラーの記録を確認できるようにする。
+// 入力: query は絞り込み条件。
+// 出力: 古い順に並べた記録とエラー。
+// エラー: 存在するログファイルの読み取りに失敗した場合に返す。ログが未作成の場合は空の結果を返す。
+// 副作用: ログファイルを読み取る。
+// 並行性: 書き込み・ローテーションと同じ mutex で排他し、世代の移動途中の読み取りを防ぐ。
+// 不変条件: 世代ファイル (.N が大きいほど古い) から現行ファイルの順に読み、tail として新しい記録を残す。
+// 関連DD: DD-LOG-001, BD-FILES-003
+func (l *Logger) Read(query Query) (Result, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultReadLimit
+	}
+	if limit > maxReadLimit {
+		limit = maxReadLimit
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var matched []Entry
+	total := 0
+	for generation := l.rotation.MaxGenerations; generation >= 0; generation-- {
+		path := l.path
+		if generation > 0 {
+			path = fmt.Sprintf("%s.%d", l.path, generation)
+		}
+		err := readEntries(path, func(entry Entry) {
+			if !query.matches(entry) {
+				return
+			}
+			total++
+			matched = append(matched, entry)
+			// 全件を保持しないよう、上限の2倍に達したら古い方を捨てる。
+			if len(matched) >= limit*2 {
+				matched = append(matched[:0], matched[len(matched)-limit:]...)
+			}
+		})
+		if err != nil {
+			return Result{}, err
+		}
+	}
+	if len(matched) > limit {
+		matched = matched[len(matched)-limit:]
+	}
+	if matched == nil {
+		matched = []Entry{}
+	}
+	return Result{Entries: matched, Truncated: total > limit}, nil
+}
+
+// matches は DD-LOG-001 の記録が絞り込み条件に合うかを判定する。
+func (q Query) matches(entry Entry) bool {
+	if parseLevelString(entry.Level) < q.MinLevel {
+		return false
+	}
+	if !q.Since.IsZero() && entry.Timestamp.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && entry.Timestamp.After(q.Until) {
+		return false
+	}
+	if q.Category != "" {
+		if category, _ := entry.Fields["category"].(string); category != q.Category {
+			return false
+		}
+	}
+	return true
+}
+
+// readEntries は DD-LOG-001 のログファイル1つを先頭から読み、解析できた記録を visit に渡す。
+func readEntries(path string, visit func(Entry)) error {
+	// #nosec G304 -- 実行ファイル配下の logs ディレクトリのログのみを読むため安全。
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open log: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	for scanner.Scan() {
+		if entry, ok := parseEntry(scanner.Bytes()); ok {
+			visit(entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read log: %w", err)
+	}
+	return nil
+}
+
+// parseEntry は DD-LOG-001 のログ1行を解析する。timestamp が読めない行は記録として扱わない。
+func parseEntry(line []byte) (Entry, bool) {
+	var record map[string]any
+	if err := json.Unmarshal(line, &record); err != nil {
+		return Entry{}, false
+	}
+	rawTimestamp, _ := record["timestamp"].(string)
+	timestamp, err := time.Parse(time.RFC3339, rawTimestamp)
+	if err != nil {
+		return Entry{}, false
+	}
+	level, _ := record["level"].(string)
+	message, _ := record["message"].(string)
+	delete(record, "timestamp")
+	delete(record, "level")
+	delete(record, "message")
+	return Entry{Timestamp: timestamp, Level: level, Message: message, Fields: record}, true
+}
+
+// parseLevelString は DD-LOG-001 のログ行のレベル表記をログレベルに変換する。未知の表記は error とみなす。
+func parseLevelString(value string) Level {
+	switch value {
+	case "debug":
+		return LevelDebug
+	case "info":
+		return LevelInfo
+	default:
+		return LevelError
+	}
+}
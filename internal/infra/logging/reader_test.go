@@ -0,0 +1,67 @@
+// reader_test.go はログの読み取りと絞り込みのテストを行い、書き込みは扱わない。
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRead_FiltersAcrossGenerationsAndKeepsTail(t *testing.T) {
+	// 世代ファイルを古い順に読み、レベル・期間で絞り込んだうえで新しい記録を上限件数だけ返すことを確認する。
+	dir := t.TempDir()
+	logger := NewLogger(filepath.Join(dir, "ratta.exe"), LevelDebug, Rotation{})
+	logDir := filepath.Join(dir, "logs")
+	if err := os.MkdirAll(logDir, 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	line := func(ts, level, message string) string {
+		return `{"timestamp":"` + ts + `","level":"` + level + `","message":"` + message + `","op":"x"}`
+	}
+	files := map[string][]string{
+		"ratta.log.2": {line("2024-01-01T00:00:00Z", "error", "oldest")},
+		"ratta.log.1": {line("2024-01-02T00:00:00Z", "debug", "noise"), "not json"},
+		"ratta.log": {
+			line("2024-01-03T00:00:00Z", "info", "third"),
+			line("2024-01-04T00:00:00Z", "error", "fourth"),
+		},
+	}
+	for name, lines := range files {
+		if err := os.WriteFile(filepath.Join(logDir, name), []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	all, err := logger.Read(Query{MinLevel: LevelInfo})
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if len(all.Entries) != 3 || all.Entries[0].Message != "oldest" || all.Entries[2].Message != "fourth" || all.Truncated {
+		t.Fatalf("unexpected entries: %+v", all)
+	}
+	if all.Entries[0].Fields["op"] != "x" || all.Entries[0].Fields["message"] != nil {
+		t.Fatalf("unexpected fields: %+v", all.Entries[0].Fields)
+	}
+
+	tail, err := logger.Read(Query{Since: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Limit: 1})
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if len(tail.Entries) != 1 || tail.Entries[0].Message != "fourth" || !tail.Truncated {
+		t.Fatalf("unexpected tail: %+v", tail)
+	}
+}
+
+func TestRead_MissingLogReturnsEmpty(t *testing.T) {
+	// ログが未作成の場合は空の結果を返すことを確認する。
+	logger := NewLogger(filepath.Join(t.TempDir(), "ratta.exe"), LevelInfo, Rotation{})
+	result, err := logger.Read(Query{})
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if len(result.Entries) != 0 || result.Truncated {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
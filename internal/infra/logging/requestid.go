@@ -0,0 +1,72 @@
+// requestid.go は DD-LOG-007 のバインディング呼び出しごとのリクエスト ID の受け渡しとログ行への付与を担い、
+// ID を発行する契機の判断は呼び出し側に委ねる。
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// requestIDKey は DD-LOG-007 の context にリクエスト ID を格納するキーを表す。
+type requestIDKey struct{}
+
+// requestIDField は DD-LOG-007 のログ行のリクエスト ID のフィールド名を表す。
+const requestIDField = "request_id"
+
+// NewRequestID は DD-LOG-007 のリクエスト ID (16 桁の16進数) を発行する。乱数を取得できない場合は空文字を返す。
+func NewRequestID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
+
+// WithRequestID は DD-LOG-007 のリクエスト ID を格納した context を返す。
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID は DD-LOG-007 の context に格納したリクエスト ID を返す。格納していない場合は空文字を返す。
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// DebugContext は ctx のリクエスト ID を付けてデバッグログを記録する。
+func (l *Logger) DebugContext(ctx context.Context, message string, fields map[string]any) {
+	l.write(LevelDebug, message, withRequestID(ctx, fields))
+}
+
+// InfoContext は ctx のリクエスト ID を付けて情報ログを記録する。
+func (l *Logger) InfoContext(ctx context.Context, message string, fields map[string]any) {
+	l.write(LevelInfo, message, withRequestID(ctx, fields))
+}
+
+// ErrorContext は ctx のリクエスト ID を付けてエラーログを記録する。
+func (l *Logger) ErrorContext(ctx context.Context, message string, fields map[string]any) {
+	l.write(LevelError, message, withRequestID(ctx, fields))
+}
+
+// AuditContext は ctx のリクエスト ID を付けて DD-LOG-005 の監査ログを記録する。
+func (l *Logger) AuditContext(ctx context.Context, event string, fields map[string]any) {
+	l.Audit(event, withRequestID(ctx, fields))
+}
+
+// withRequestID は DD-LOG-007 の fields を複製してリクエスト ID を加える。ID が無い場合は fields をそのまま返す。
+func withRequestID(ctx context.Context, fields map[string]any) map[string]any {
+	id := RequestID(ctx)
+	if id == "" {
+		return fields
+	}
+	merged := make(map[string]any, len(fields)+1)
+	for key, value := range fields {
+		merged[key] = value
+	}
+	merged[requestIDField] = id
+	return merged
+}
@@ -0,0 +1,80 @@
+// requestid_test.go はリクエスト ID の受け渡しとログ行への付与のテストを行い、バインディングでの発行は扱わない。
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readRecords はテスト用にログファイルの全行を解析して返す。
+func readRecords(t *testing.T, path string) []map[string]any {
+	t.Helper()
+	// #nosec G304 -- テスト用ディレクトリ配下のログのみを読むため安全。
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestContextLogging_AddsRequestID(t *testing.T) {
+	// context のリクエスト ID が各レベルと監査ログの行に request_id として付くことを確認する。
+	dir := t.TempDir()
+	logger := NewLogger(filepath.Join(dir, "ratta.exe"), LevelDebug, Rotation{})
+	ctx := WithRequestID(context.Background(), "0123456789abcdef")
+	fields := map[string]any{"detail": "value"}
+
+	logger.DebugContext(ctx, "debug", fields)
+	logger.InfoContext(ctx, "info", fields)
+	logger.ErrorContext(ctx, "error", fields)
+	logger.AuditContext(ctx, "mode_locked", fields)
+
+	records := readRecords(t, filepath.Join(dir, "logs", "ratta.log"))
+	if len(records) != 4 {
+		t.Fatalf("unexpected records: %v", records)
+	}
+	for _, record := range records {
+		if record["request_id"] != "0123456789abcdef" || record["detail"] != "value" {
+			t.Fatalf("unexpected record: %v", record)
+		}
+	}
+	if _, ok := fields["request_id"]; ok {
+		t.Fatal("expected caller fields not to be modified")
+	}
+}
+
+func TestContextLogging_WithoutRequestID(t *testing.T) {
+	// リクエスト ID の無い context では request_id を付けないことを確認する。
+	dir := t.TempDir()
+	logger := NewLogger(filepath.Join(dir, "ratta.exe"), LevelInfo, Rotation{})
+
+	logger.InfoContext(context.Background(), "info", nil)
+
+	records := readRecords(t, filepath.Join(dir, "logs", "ratta.log"))
+	if _, ok := records[0]["request_id"]; ok {
+		t.Fatalf("unexpected request_id: %v", records[0])
+	}
+}
+
+func TestNewRequestID_IsUnique(t *testing.T) {
+	// リクエスト ID が 16 桁で呼び出しごとに異なることを確認する。
+	first, second := NewRequestID(), NewRequestID()
+	if len(first) != 16 || first == second {
+		t.Fatalf("unexpected request ids: %q %q", first, second)
+	}
+	if RequestID(WithRequestID(context.Background(), first)) != first {
+		t.Fatal("expected request id to round-trip through context")
+	}
+}
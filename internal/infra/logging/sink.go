@@ -0,0 +1,55 @@
+// sink.go は DD-LOG-006 のログ行の追加の出力先 (シンク) への転送を担い、出力先ごとの書式や送信は各シンクに委ねる。
+package logging
+
+// Sink は DD-LOG-006 の ratta.log に加えてログ行を転送する出力先を表す。
+// Emit は1行分の JSON (改行なし) を受け取り、Close は出力先を解放する。
+type Sink interface {
+	Emit(level Level, line []byte) error
+	Close() error
+}
+
+// sinkEntry は DD-LOG-006 の登録したシンクと転送する最小のログレベルを表す。
+type sinkEntry struct {
+	sink     Sink
+	minLevel Level
+}
+
+// AddSink は DD-LOG-006 のログ行の追加の出力先を登録する。
+// 目的: ratta.log に加えて、社内で集中監視されている Windows イベントログや syslog などへログを送れるようにする。
+// 入力: sink は出力先、minLevel は転送する最小のログレベル。
+// 出力: なし。
+// エラー: なし。
+// 副作用: 以降のログ行のうち minLevel 以上のものを sink へ転送する。
+// 並行性: Logger の mutex で排他制御する。Emit は mutex を保持したまま呼ばれる。
+// 不変条件: ログレベルの設定で出力しない行は転送しない。転送の失敗は ratta.log への出力に影響させない。
+// 関連DD: DD-LOG-006
+func (l *Logger) AddSink(sink Sink, minLevel Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sinks = append(l.sinks, sinkEntry{sink: sink, minLevel: minLevel})
+}
+
+// Close は DD-LOG-006 の登録したシンクをすべて解放して登録を解除する。ratta.log への出力は続ける。
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var firstErr error
+	for _, entry := range l.sinks {
+		if err := entry.sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	l.sinks = nil
+	return firstErr
+}
+
+// forward は DD-LOG-006 のログ行を minLevel を満たすシンクへ転送する。呼び出し側で mutex を保持する。
+func (l *Logger) forward(level Level, line []byte) {
+	for _, entry := range l.sinks {
+		if level < entry.minLevel {
+			continue
+		}
+		// 転送の失敗をログへ記録すると同じシンクへの転送を繰り返すため、破棄する。
+		_ = entry.sink.Emit(level, line)
+	}
+}
@@ -0,0 +1,83 @@
+// sink_test.go はログ行の追加の出力先への転送のテストを行い、OS のログへの実際の出力は扱わない。
+package logging
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// recordingSink はテスト用に転送されたログ行を記録する Sink。
+type recordingSink struct {
+	lines  []string
+	levels []Level
+	closed bool
+	err    error
+}
+
+func (s *recordingSink) Emit(level Level, line []byte) error {
+	s.levels = append(s.levels, level)
+	s.lines = append(s.lines, string(line))
+	return s.err
+}
+
+func (s *recordingSink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestAddSink_ForwardsFromMinLevel(t *testing.T) {
+	// 最小のログレベル以上の行のみが ratta.log と同じ JSON でシンクへ転送されることを確認する。
+	dir := t.TempDir()
+	logger := NewLogger(filepath.Join(dir, "ratta.exe"), LevelDebug, Rotation{})
+	sink := &recordingSink{}
+	logger.AddSink(sink, LevelError)
+
+	logger.Info("ignored", nil)
+	logger.Error("failed", map[string]any{"detail": "boom"})
+
+	if len(sink.lines) != 1 || sink.levels[0] != LevelError {
+		t.Fatalf("unexpected forwarded lines: %v", sink.lines)
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(sink.lines[0]), &record); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if record["message"] != "failed" || record["detail"] != "boom" || record["level"] != "error" {
+		t.Fatalf("unexpected record: %v", record)
+	}
+}
+
+func TestAddSink_FailureDoesNotAffectFile(t *testing.T) {
+	// シンクへの転送に失敗しても ratta.log への出力が続くことを確認する。
+	dir := t.TempDir()
+	logger := NewLogger(filepath.Join(dir, "ratta.exe"), LevelInfo, Rotation{})
+	logger.AddSink(&recordingSink{err: errors.New("unavailable")}, LevelInfo)
+
+	logger.Error("failed", nil)
+
+	// #nosec G304 -- テスト用ディレクトリ配下のログのみを読むため安全。
+	data, err := os.ReadFile(filepath.Join(dir, "logs", "ratta.log"))
+	if err != nil || len(data) == 0 {
+		t.Fatalf("expected log file to be written, err=%v", err)
+	}
+}
+
+func TestClose_ClosesAndRemovesSinks(t *testing.T) {
+	// Close でシンクが解放され、以降の行は転送されないことを確認する。
+	dir := t.TempDir()
+	logger := NewLogger(filepath.Join(dir, "ratta.exe"), LevelInfo, Rotation{})
+	sink := &recordingSink{}
+	logger.AddSink(sink, LevelInfo)
+
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	logger.Error("after close", nil)
+
+	if !sink.closed || len(sink.lines) != 0 {
+		t.Fatalf("unexpected sink state: closed=%v lines=%v", sink.closed, sink.lines)
+	}
+}
@@ -0,0 +1,56 @@
+//go:build !windows
+
+// syssink_other.go は DD-LOG-006 のシステムのログへの出力を Windows 以外で syslog により行う実装を担う。
+package logging
+
+import (
+	"fmt"
+	"log/syslog"
+)
+
+// syslogSink は DD-LOG-006 の syslog への出力先を表す。
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+// OpenSystemSink は DD-LOG-006 のローカルの syslog への出力先を開く。
+// 目的: 社内で集中監視されている syslog へ ratta のエラーを届ける。
+// 入力: source は syslog のタグ。
+// 出力: Sink とエラー。
+// エラー: syslog へ接続できない場合に返す。
+// 副作用: syslog への接続を開く。
+// 並行性: Logger の mutex の下で使用する。
+// 不変条件: ファシリティは user とする。
+// 関連DD: DD-LOG-006
+func OpenSystemSink(source string) (Sink, error) {
+	writer, err := syslog.New(syslog.LOG_USER|syslog.LOG_INFO, source)
+	if err != nil {
+		return nil, fmt.Errorf("open syslog: %w", err)
+	}
+	return &syslogSink{writer: writer}, nil
+}
+
+// Emit は DD-LOG-006 のログ行をレベルに応じた重要度で記録する。
+func (s *syslogSink) Emit(level Level, line []byte) error {
+	var err error
+	switch level {
+	case LevelError:
+		err = s.writer.Err(string(line))
+	case LevelDebug:
+		err = s.writer.Debug(string(line))
+	default:
+		err = s.writer.Info(string(line))
+	}
+	if err != nil {
+		return fmt.Errorf("write syslog: %w", err)
+	}
+	return nil
+}
+
+// Close は DD-LOG-006 の syslog への接続を閉じる。
+func (s *syslogSink) Close() error {
+	if err := s.writer.Close(); err != nil {
+		return fmt.Errorf("close syslog: %w", err)
+	}
+	return nil
+}
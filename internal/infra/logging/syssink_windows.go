@@ -0,0 +1,58 @@
+//go:build windows
+
+// syssink_windows.go は DD-LOG-006 のシステムのログへの出力を Windows イベントログ (アプリケーション) で行う実装を担う。
+package logging
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// eventIDRatta は DD-LOG-006 のイベントログに記録するイベント ID を表す。
+const eventIDRatta = 1
+
+// eventLogSink は DD-LOG-006 の Windows イベントログへの出力先を表す。
+type eventLogSink struct {
+	log *eventlog.Log
+}
+
+// OpenSystemSink は DD-LOG-006 の Windows イベントログへの出力先を開く。
+// 目的: 社内で集中監視されているイベントログへ ratta のエラーを届ける。
+// 入力: source はイベントのソース名。
+// 出力: Sink とエラー。
+// エラー: イベントログを開けない場合に返す。
+// 副作用: イベントログのハンドルを開く。
+// 並行性: Logger の mutex の下で使用する。
+// 不変条件: ソースの登録は配布時のインストーラーに委ね、未登録でもイベントは記録される。
+// 関連DD: DD-LOG-006
+func OpenSystemSink(source string) (Sink, error) {
+	handle, err := eventlog.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("open event log: %w", err)
+	}
+	return &eventLogSink{log: handle}, nil
+}
+
+// Emit は DD-LOG-006 のログ行をレベルに応じたイベントの種類で記録する。
+func (s *eventLogSink) Emit(level Level, line []byte) error {
+	var err error
+	switch level {
+	case LevelError:
+		err = s.log.Error(eventIDRatta, string(line))
+	default:
+		err = s.log.Info(eventIDRatta, string(line))
+	}
+	if err != nil {
+		return fmt.Errorf("write event log: %w", err)
+	}
+	return nil
+}
+
+// Close は DD-LOG-006 のイベントログのハンドルを閉じる。
+func (s *eventLogSink) Close() error {
+	if err := s.log.Close(); err != nil {
+		return fmt.Errorf("close event log: %w", err)
+	}
+	return nil
+}
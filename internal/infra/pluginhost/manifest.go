@@ -0,0 +1,207 @@
+// manifest.go は DD-PLUGIN-001 の plugins/ ディレクトリからのプラグインの発見と plugin.json の検証を担い、
+// プラグインの呼び出しは扱わない。呼び出しは pluginhost.go が担う。
+package pluginhost
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"ratta/internal/infra/configrepo"
+)
+
+const (
+	// DirName は DD-PLUGIN-001 の実行ファイルと同じディレクトリに置くプラグインのディレクトリ名を表す。
+	DirName = "plugins"
+	// ManifestFileName は DD-PLUGIN-001 の各プラグインのディレクトリに置く定義ファイルの名前を表す。
+	ManifestFileName = "plugin.json"
+	// ProtocolVersion は DD-PLUGIN-001 の本版が扱うプラグインとのやり取りの形式のバージョンを表す。
+	ProtocolVersion = 1
+
+	// defaultTimeout と maxTimeoutSeconds は DD-PLUGIN-001 の1回の呼び出しの終了を待つ上限の既定値と最大値を表す。
+	defaultTimeout    = 30 * time.Second
+	maxTimeoutSeconds = 600
+)
+
+// formatPattern は DD-PLUGIN-002 のプラグインが追加する出力形式の名前の規則を表す。
+var formatPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// Manifest は DD-PLUGIN-001 の plugin.json の内容を表す。
+// Command はプラグインのディレクトリを基準とする実行ファイルのパス、Validate は DD-PLUGIN-003 の保存前の検証を行うか、
+// PostSave は DD-PLUGIN-004 の保存後の処理を行う変更の種類 (DD-HOOK-003 と同じ名前) を表す。
+// TimeoutSeconds は1回の呼び出しの終了を待つ上限 (秒) を表し、0 の場合は既定値 (30秒) を用いる。
+type Manifest struct {
+	ProtocolVersion int            `json:"protocol_version"`
+	Command         string         `json:"command"`
+	Description     string         `json:"description,omitempty"`
+	Exporters       []ExportFormat `json:"exporters,omitempty"`
+	Validate        bool           `json:"validate,omitempty"`
+	PostSave        []string       `json:"post_save,omitempty"`
+	TimeoutSeconds  int            `json:"timeout_seconds,omitempty"`
+}
+
+// ExportFormat は DD-PLUGIN-002 のプラグインが追加する出力形式を表す。Plugin は発見時にプラグインの名前を設定する。
+type ExportFormat struct {
+	Format      string `json:"format"`
+	Extension   string `json:"extension"`
+	Description string `json:"description,omitempty"`
+	Plugin      string `json:"-"`
+}
+
+// Plugin は DD-PLUGIN-001 の発見したプラグイン1件を表す。Name はプラグインのディレクトリ名、Dir はそのパスを表す。
+type Plugin struct {
+	Name     string
+	Dir      string
+	Manifest Manifest
+}
+
+// AppDir は DD-PLUGIN-001 の実行ファイルと同じディレクトリの plugins/ のパスを返す。
+// プラグインは任意のコマンドを実行するため、共有フォルダではなく端末ごとに置く。
+func AppDir(exePath string) string {
+	return filepath.Join(filepath.Dir(exePath), DirName)
+}
+
+// Discover は DD-PLUGIN-001 の dir 直下の各ディレクトリの plugin.json からプラグインを発見する。
+// 目的: コードを変えずに、拠点ごとの出力形式・検証の規則・保存後の処理を追加できるようにする。
+// 入力: dir はプラグインのディレクトリ。
+// 出力: 発見したプラグインの登録簿と、読み込めず除いたプラグインごとの警告。dir が存在しない場合は空の登録簿を返す。
+// エラー: 返さない。読み込めない plugin.json、不正な定義、他のプラグインと重複する出力形式は警告とし、そのプラグインを除く。
+// 副作用: ファイルを読み込むのみで、プラグインは実行しない。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: プラグインはディレクトリ名の順に並べ、同じ出力形式は名前順で先のプラグインを用いる。
+// 関連DD: DD-PLUGIN-001, DD-PLUGIN-002
+func Discover(dir string) (*Registry, []error) {
+	registry := &Registry{}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return registry, nil
+		}
+		return registry, []error{fmt.Errorf("read plugins dir: %w", err)}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	warnings := []error{}
+	formats := map[string]string{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		pluginDir := filepath.Join(dir, entry.Name())
+		manifest, loadErr := loadManifest(filepath.Join(pluginDir, ManifestFileName))
+		if loadErr != nil {
+			warnings = append(warnings, fmt.Errorf("plugin %s: %w", entry.Name(), loadErr))
+			continue
+		}
+		if duplicate := duplicateFormat(manifest, formats); duplicate != "" {
+			warnings = append(warnings, fmt.Errorf("plugin %s: export format %s is already provided by plugin %s", entry.Name(), duplicate, formats[duplicate]))
+			continue
+		}
+		for i := range manifest.Exporters {
+			manifest.Exporters[i].Plugin = entry.Name()
+			formats[manifest.Exporters[i].Format] = entry.Name()
+		}
+		registry.plugins = append(registry.plugins, &Plugin{Name: entry.Name(), Dir: pluginDir, Manifest: manifest})
+	}
+	return registry, warnings
+}
+
+// loadManifest は DD-PLUGIN-001 の plugin.json を読み込んで検証する。綴りの誤りに気付けるよう未知の項目は不正とする。
+func loadManifest(path string) (Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("read %s: %w", ManifestFileName, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var manifest Manifest
+	if err := decoder.Decode(&manifest); err != nil {
+		return Manifest{}, fmt.Errorf("parse %s: %w", ManifestFileName, err)
+	}
+	if err := decoder.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return Manifest{}, fmt.Errorf("parse %s: trailing data", ManifestFileName)
+	}
+	if err := manifest.validate(); err != nil {
+		return Manifest{}, err
+	}
+	return manifest, nil
+}
+
+// validate は DD-PLUGIN-001 の plugin.json の値域を検証する。
+func (m Manifest) validate() error {
+	if m.ProtocolVersion != ProtocolVersion {
+		return fmt.Errorf("unsupported protocol_version: %d", m.ProtocolVersion)
+	}
+	if m.Command == "" {
+		return errors.New("command is required")
+	}
+	if m.TimeoutSeconds < 0 || m.TimeoutSeconds > maxTimeoutSeconds {
+		return fmt.Errorf("timeout_seconds must be between 0 and %d", maxTimeoutSeconds)
+	}
+	if len(m.Exporters) == 0 && !m.Validate && len(m.PostSave) == 0 {
+		return errors.New("plugin provides no exporters, validation or post_save actions")
+	}
+	seen := map[string]bool{}
+	for _, exporter := range m.Exporters {
+		if !formatPattern.MatchString(exporter.Format) {
+			return fmt.Errorf("invalid export format: %q", exporter.Format)
+		}
+		if seen[exporter.Format] {
+			return fmt.Errorf("duplicate export format: %s", exporter.Format)
+		}
+		seen[exporter.Format] = true
+		if len(exporter.Extension) < 2 || exporter.Extension[0] != '.' {
+			return fmt.Errorf("export format %s: extension must start with a dot", exporter.Format)
+		}
+	}
+	for _, event := range m.PostSave {
+		switch event {
+		case configrepo.HookEventIssueCreated, configrepo.HookEventIssueUpdated, configrepo.HookEventIssueCommented, configrepo.HookEventIssueClosed:
+		default:
+			return fmt.Errorf("unknown post_save event: %s", event)
+		}
+	}
+	return nil
+}
+
+// duplicateFormat は DD-PLUGIN-002 の manifest の出力形式のうち、先に発見したプラグインが追加済みのものを返す。無い場合は空文字を返す。
+func duplicateFormat(manifest Manifest, formats map[string]string) string {
+	for _, exporter := range manifest.Exporters {
+		if _, ok := formats[exporter.Format]; ok {
+			return exporter.Format
+		}
+	}
+	return ""
+}
+
+// command は DD-PLUGIN-001 の実行するファイルのパスを返す。相対パスはプラグインのディレクトリを基準とする。
+func (p *Plugin) command() string {
+	if filepath.IsAbs(p.Manifest.Command) {
+		return p.Manifest.Command
+	}
+	return filepath.Join(p.Dir, p.Manifest.Command)
+}
+
+// timeout は DD-PLUGIN-001 の1回の呼び出しの終了を待つ上限を返す。
+func (p *Plugin) timeout() time.Duration {
+	if p.Manifest.TimeoutSeconds <= 0 {
+		return defaultTimeout
+	}
+	return time.Duration(p.Manifest.TimeoutSeconds) * time.Second
+}
+
+// accepts は DD-PLUGIN-004 のプラグインが変更の種類 event の保存後の処理を行うかを返す。
+func (p *Plugin) accepts(event string) bool {
+	for _, candidate := range p.Manifest.PostSave {
+		if candidate == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+// manifest_test.go は plugins/ からのプラグインの発見と plugin.json の検証のテストを行う。
+package pluginhost
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writePlugin はテスト用に dir/name へ plugin.json と実行可能なシェルスクリプト run.sh を作成する。script が空の場合は作成しない。
+func writePlugin(t *testing.T, dir, name, manifest, script string) {
+	t.Helper()
+	pluginDir := filepath.Join(dir, name)
+	if err := os.MkdirAll(pluginDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(pluginDir, ManifestFileName), []byte(manifest), 0o600); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+	if script == "" {
+		return
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on windows")
+	}
+	if err := os.WriteFile(filepath.Join(pluginDir, "run.sh"), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+}
+
+func TestDiscover_LoadsValidPluginsAndWarnsOnInvalid(t *testing.T) {
+	// 正しい定義のプラグインを名前順に登録し、未知の項目・未知の変更の種類・重複する出力形式の定義は警告として除くことを確認する。
+	dir := t.TempDir()
+	writePlugin(t, dir, "a-xlsx", `{"protocol_version": 1, "command": "run.sh", "exporters": [{"format": "xlsx", "extension": ".xlsx"}], "timeout_seconds": 5}`, "")
+	writePlugin(t, dir, "b-rules", `{"protocol_version": 1, "command": "run.sh", "validate": true, "post_save": ["issue.closed"]}`, "")
+	writePlugin(t, dir, "c-duplicate", `{"protocol_version": 1, "command": "run.sh", "exporters": [{"format": "xlsx", "extension": ".xlsx"}]}`, "")
+	writePlugin(t, dir, "d-typo", `{"protocol_version": 1, "command": "run.sh", "validator": true}`, "")
+	writePlugin(t, dir, "e-event", `{"protocol_version": 1, "command": "run.sh", "post_save": ["issue.deleted"]}`, "")
+	writePlugin(t, dir, "f-version", `{"protocol_version": 2, "command": "run.sh", "validate": true}`, "")
+	if err := os.WriteFile(filepath.Join(dir, "README.txt"), []byte("not a plugin"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	registry, warnings := Discover(dir)
+	plugins := registry.Plugins()
+	if len(plugins) != 2 || plugins[0].Name != "a-xlsx" || plugins[1].Name != "b-rules" {
+		t.Fatalf("unexpected plugins: %+v", plugins)
+	}
+	if len(warnings) != 4 {
+		t.Fatalf("expected 4 warnings, got %v", warnings)
+	}
+	for i, want := range []string{"already provided by plugin a-xlsx", "unknown field", "unknown post_save event", "unsupported protocol_version"} {
+		if !strings.Contains(warnings[i].Error(), want) {
+			t.Fatalf("warning %d: expected %q, got %v", i, want, warnings[i])
+		}
+	}
+	formats := registry.ExportFormats()
+	if len(formats) != 1 || formats[0].Format != "xlsx" || formats[0].Plugin != "a-xlsx" {
+		t.Fatalf("unexpected formats: %+v", formats)
+	}
+	if plugins[0].timeout() != 5*time.Second || plugins[1].timeout() != defaultTimeout {
+		t.Fatalf("unexpected timeouts: %v %v", plugins[0].timeout(), plugins[1].timeout())
+	}
+	if plugins[0].command() != filepath.Join(dir, "a-xlsx", "run.sh") {
+		t.Fatalf("unexpected command: %s", plugins[0].command())
+	}
+}
+
+func TestDiscover_MissingDirIsEmpty(t *testing.T) {
+	// plugins/ が無い場合は警告の無い空の登録簿とし、nil の登録簿もプラグインが無いものとして扱うことを確認する。
+	registry, warnings := Discover(filepath.Join(t.TempDir(), DirName))
+	if len(registry.Plugins()) != 0 || len(warnings) != 0 {
+		t.Fatalf("unexpected result: %+v %v", registry.Plugins(), warnings)
+	}
+	var missing *Registry
+	if _, ok := missing.Exporter("xlsx"); ok || len(missing.ExportFormats()) != 0 {
+		t.Fatal("expected nil registry to have no exporters")
+	}
+	if got := AppDir(filepath.Join("opt", "ratta", "ratta.exe")); got != filepath.Join("opt", "ratta", DirName) {
+		t.Fatalf("unexpected plugins dir: %s", got)
+	}
+}
+
+func TestManifest_ValidateRejectsInvalidExporters(t *testing.T) {
+	// 出力形式の名前・拡張子の規則、何も追加しない定義、値域外の上限時間を不正とすることを確認する。
+	cases := map[string]Manifest{
+		"invalid export format": {ProtocolVersion: 1, Command: "x", Exporters: []ExportFormat{{Format: "Bad Name", Extension: ".x"}}},
+		"extension must start":  {ProtocolVersion: 1, Command: "x", Exporters: []ExportFormat{{Format: "x", Extension: "x"}}},
+		"duplicate export":      {ProtocolVersion: 1, Command: "x", Exporters: []ExportFormat{{Format: "x", Extension: ".x"}, {Format: "x", Extension: ".y"}}},
+		"provides no":           {ProtocolVersion: 1, Command: "x"},
+		"timeout_seconds":       {ProtocolVersion: 1, Command: "x", Validate: true, TimeoutSeconds: 601},
+		"command is required":   {ProtocolVersion: 1, Validate: true},
+	}
+	for want, manifest := range cases {
+		if err := manifest.validate(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q, got %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+// Package pluginhost は DD-PLUGIN-001 の実行ファイル隣の plugins/ に置いた外部の実行ファイルを標準入出力の JSON で呼び出すことを担い、
+// 出力したファイルの書き込みや保存を拒むかの判断は扱わず、呼び出し側が結果に従う。
+// Go の plugin パッケージは Windows で使えないため、プラグインは言語を問わない独立した実行ファイルとする。
+package pluginhost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/limitbuf"
+)
+
+const (
+	// MethodExport などは DD-PLUGIN-001 のプラグインへ依頼する処理の種類を表す。
+	MethodExport   = "export"
+	MethodValidate = "validate"
+	MethodPostSave = "post_save"
+
+	// maxResponseBytes は DD-PLUGIN-001 の標準出力から読む応答の上限を表す。出力する課題一覧を含むため大きめとする。
+	maxResponseBytes = 256 << 20
+	// maxStderrBytes は DD-PLUGIN-001 の失敗の調査のためにエラーへ含める標準エラーの上限を表す。
+	maxStderrBytes = 4 << 10
+	// waitDelay は DD-PLUGIN-001 の上限を超えて停止した後、子プロセスが出力を閉じるのを待つ上限を表す。
+	waitDelay = 2 * time.Second
+)
+
+// Registry は DD-PLUGIN-001 の発見したプラグインの登録簿を表す。nil の場合はプラグインが無いものとして扱う。
+type Registry struct {
+	plugins []*Plugin
+}
+
+// Problem は DD-PLUGIN-003 のプラグインが返す検証の問題1件を表す。Field が空の場合は課題全体の問題とする。
+type Problem struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// PostSaveEvent は DD-PLUGIN-004 の保存後の処理へ渡す変更の内容を表す。Event は DD-HOOK-003 と同じ変更の種類を表す。
+type PostSaveEvent struct {
+	Event       string      `json:"event"`
+	ProjectRoot string      `json:"project_root"`
+	Category    string      `json:"category"`
+	IssueID     string      `json:"issue_id"`
+	Mode        string      `json:"mode"`
+	Actor       string      `json:"actor,omitempty"`
+	Issue       issue.Issue `json:"issue"`
+}
+
+// Exporter は DD-PLUGIN-002 のプラグインが追加した出力形式1件を表す。
+type Exporter struct {
+	ExportFormat
+	plugin *Plugin
+}
+
+// header は DD-PLUGIN-001 の依頼に共通する項目を表す。
+type header struct {
+	ProtocolVersion int    `json:"protocol_version"`
+	Method          string `json:"method"`
+}
+
+// exportRequest と exportResponse は DD-PLUGIN-002 の出力の依頼と応答を表す。Content は JSON では base64 とする。
+type exportRequest struct {
+	header
+	Format  string        `json:"format"`
+	Project string        `json:"project"`
+	Issues  []issue.Issue `json:"issues"`
+}
+
+type exportResponse struct {
+	Error   string `json:"error,omitempty"`
+	Content []byte `json:"content"`
+}
+
+// validateRequest と validateResponse は DD-PLUGIN-003 の検証の依頼と応答を表す。
+type validateRequest struct {
+	header
+	Category string      `json:"category"`
+	Issue    issue.Issue `json:"issue"`
+}
+
+type validateResponse struct {
+	Error    string    `json:"error,omitempty"`
+	Problems []Problem `json:"problems"`
+}
+
+// postSaveRequest と postSaveResponse は DD-PLUGIN-004 の保存後の処理の依頼と応答を表す。
+type postSaveRequest struct {
+	header
+	PostSaveEvent
+}
+
+type postSaveResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+// Plugins は DD-PLUGIN-001 の発見したプラグインをディレクトリ名の順に返す。
+func (r *Registry) Plugins() []*Plugin {
+	if r == nil {
+		return nil
+	}
+	return r.plugins
+}
+
+// ExportFormats は DD-PLUGIN-002 のプラグインが追加した出力形式をプラグインの順に返す。
+func (r *Registry) ExportFormats() []ExportFormat {
+	formats := []ExportFormat{}
+	for _, plugin := range r.Plugins() {
+		formats = append(formats, plugin.Manifest.Exporters...)
+	}
+	return formats
+}
+
+// Exporter は DD-PLUGIN-002 の出力形式 format を追加したプラグインを返す。無い場合は false を返す。
+func (r *Registry) Exporter(format string) (*Exporter, bool) {
+	for _, plugin := range r.Plugins() {
+		for _, exporter := range plugin.Manifest.Exporters {
+			if exporter.Format == format {
+				return &Exporter{ExportFormat: exporter, plugin: plugin}, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Export は DD-PLUGIN-002 のプラグインへ課題一覧の変換を依頼し、出力するファイルの内容を返す。
+// project はプロジェクトフォルダ名、issues は出力する課題を表す。ファイルの書き込みは呼び出し側が行う。
+func (e *Exporter) Export(ctx context.Context, project string, issues []issue.Issue) ([]byte, error) {
+	var response exportResponse
+	request := exportRequest{header: newHeader(MethodExport), Format: e.Format, Project: project, Issues: issues}
+	if err := e.plugin.call(ctx, request, &response); err != nil {
+		return nil, err
+	}
+	return response.Content, nil
+}
+
+// Validate は DD-PLUGIN-003 の検証を行うプラグインへ保存前の課題の検証を依頼する。
+// 目的: 拠点ごとの入力規則 (必須の記載や担当者の書式など) を、コードを変えずに保存時へ加える。
+// 入力: ctx は中断用、category はカテゴリ名、value は保存しようとする課題。
+// 出力: 問題が無い場合は nil、問題がある場合は issue.ValidationErrors。
+// エラー: プラグインの起動の失敗、0 以外の終了コード、上限時間の超過、不正な応答の場合に返す。
+// 検証できない場合に規則に反する課題を保存しないよう、保存を拒む側に倒す。
+// 副作用: 検証を行うプラグインを順に実行する。
+// 並行性: 複数のゴルーチンから呼び出してよい。
+// 不変条件: 問題の Message の先頭にプラグインの名前を付け、どの規則による問題かを示す。
+// 関連DD: DD-PLUGIN-003
+func (r *Registry) Validate(ctx context.Context, category string, value issue.Issue) error {
+	var problems issue.ValidationErrors
+	for _, plugin := range r.Plugins() {
+		if !plugin.Manifest.Validate {
+			continue
+		}
+		var response validateResponse
+		request := validateRequest{header: newHeader(MethodValidate), Category: category, Issue: value}
+		if err := plugin.call(ctx, request, &response); err != nil {
+			return err
+		}
+		for _, problem := range response.Problems {
+			field := problem.Field
+			if field == "" {
+				field = "issue"
+			}
+			problems = append(problems, issue.ValidationError{Field: field, Message: plugin.Name + ": " + problem.Message})
+		}
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+// PostSave は DD-PLUGIN-004 の変更の種類 event.Event の保存後の処理を行うプラグインを順に実行する。
+// 保存は済んでいるため、失敗したプラグインのエラーを返すのみで他のプラグインの実行は続ける。
+func (r *Registry) PostSave(ctx context.Context, event PostSaveEvent) []error {
+	errs := []error{}
+	for _, plugin := range r.Plugins() {
+		if !plugin.accepts(event.Event) {
+			continue
+		}
+		var response postSaveResponse
+		request := postSaveRequest{header: newHeader(MethodPostSave), PostSaveEvent: event}
+		if err := plugin.call(ctx, request, &response); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// newHeader は DD-PLUGIN-001 の本版の形式バージョンの依頼の共通項目を返す。
+func newHeader(method string) header {
+	return header{ProtocolVersion: ProtocolVersion, Method: method}
+}
+
+// failer は DD-PLUGIN-001 の応答を表し、failure は応答の error を返す。
+type failer interface {
+	failure() string
+}
+
+func (r *exportResponse) failure() string   { return r.Error }
+func (r *validateResponse) failure() string { return r.Error }
+func (r *postSaveResponse) failure() string { return r.Error }
+
+// call は DD-PLUGIN-001 のプラグインの1回の呼び出しを行う。
+// 目的: 依頼を JSON で標準入力へ書き、標準出力の JSON を応答として読む。
+// 入力: ctx は中断用、request は依頼、reply は応答の読み込み先。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: 起動の失敗、0 以外の終了コード、上限時間の超過や ctx の中断、応答の上限超過・不正な JSON、応答の error がある場合に返す。
+// 副作用: シェルを介さずにプラグインを起動する。作業ディレクトリはプラグインのディレクトリとする。
+// 並行性: 呼び出しごとに別のプロセスを起動するため、複数のゴルーチンから呼び出してよい。
+// 不変条件: エラーにはプラグインの名前と標準エラーの先頭を含め、標準出力の内容は含めない。
+// 関連DD: DD-PLUGIN-001
+func (p *Plugin) call(ctx context.Context, request any, reply failer) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("marshal plugin request: %w", err)
+	}
+	runCtx, cancel := context.WithTimeout(ctx, p.timeout())
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, p.command())
+	cmd.Dir = p.Dir
+	cmd.Stdin = bytes.NewReader(body)
+	stdout := limitbuf.New(maxResponseBytes)
+	stderr := limitbuf.New(maxStderrBytes)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = waitDelay
+
+	runErr := cmd.Run()
+	if ctxErr := runCtx.Err(); ctxErr != nil && runErr != nil {
+		return fmt.Errorf("plugin %s stopped after %s: %w", p.Name, p.timeout(), ctxErr)
+	}
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			return fmt.Errorf("plugin %s exited with code %d: %s", p.Name, exitErr.ExitCode(), stderr.String())
+		}
+		return fmt.Errorf("run plugin %s: %w", p.Name, runErr)
+	}
+	if stdout.Truncated() {
+		return fmt.Errorf("plugin %s response exceeds %d bytes", p.Name, maxResponseBytes)
+	}
+	if err := json.Unmarshal(stdout.Bytes(), reply); err != nil {
+		return fmt.Errorf("plugin %s returned an invalid response: %w", p.Name, err)
+	}
+	if message := reply.failure(); message != "" {
+		return fmt.Errorf("plugin %s: %s", p.Name, message)
+	}
+	return nil
+}
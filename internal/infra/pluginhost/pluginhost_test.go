@@ -0,0 +1,98 @@
+// pluginhost_test.go はプラグインへの出力・検証・保存後の処理の依頼と、失敗の扱いのテストを行う。
+package pluginhost
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/configrepo"
+)
+
+func TestExporter_SendsIssuesAndReturnsContent(t *testing.T) {
+	// 依頼を JSON で標準入力へ書き、応答の base64 の内容を出力するファイルの内容として返すことを確認する。
+	dir := t.TempDir()
+	writePlugin(t, dir, "xlsx", `{"protocol_version": 1, "command": "run.sh", "exporters": [{"format": "xlsx", "extension": ".xlsx"}]}`,
+		"cat > request.json\nprintf '{\"content\": \"aGVsbG8=\"}'\n")
+	registry, _ := Discover(dir)
+	exporter, ok := registry.Exporter("xlsx")
+	if !ok {
+		t.Fatal("expected xlsx exporter")
+	}
+	content, err := exporter.Export(context.Background(), "proj", []issue.Issue{{IssueID: "abc", Title: "t"}})
+	if err != nil || string(content) != "hello" {
+		t.Fatalf("unexpected export: %q %v", content, err)
+	}
+	request, _ := os.ReadFile(filepath.Join(dir, "xlsx", "request.json"))
+	for _, want := range []string{`"protocol_version":1`, `"method":"export"`, `"format":"xlsx"`, `"project":"proj"`, `"issue_id":"abc"`} {
+		if !strings.Contains(string(request), want) {
+			t.Fatalf("expected %s in request: %s", want, request)
+		}
+	}
+	if _, ok := registry.Exporter("pdf"); ok {
+		t.Fatal("expected no exporter for unknown format")
+	}
+}
+
+func TestValidate_ReturnsProblemsAndFailsClosed(t *testing.T) {
+	// 問題はプラグインの名前を付けた検証エラーとして返し、検証できないプラグインがある場合は保存を拒むエラーを返すことを確認する。
+	dir := t.TempDir()
+	writePlugin(t, dir, "rules", `{"protocol_version": 1, "command": "run.sh", "validate": true}`,
+		"cat > /dev/null\nprintf '{\"problems\": [{\"field\": \"assignee\", \"message\": \"required for High\"}, {\"message\": \"too vague\"}]}'\n")
+	registry, _ := Discover(dir)
+	err := registry.Validate(context.Background(), "cat", issue.Issue{IssueID: "abc"})
+	var problems issue.ValidationErrors
+	if !errors.As(err, &problems) || len(problems) != 2 {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+	if problems[0] != (issue.ValidationError{Field: "assignee", Message: "rules: required for High"}) || problems[1].Field != "issue" {
+		t.Fatalf("unexpected problems: %+v", problems)
+	}
+
+	writePlugin(t, dir, "broken", `{"protocol_version": 1, "command": "run.sh", "validate": true}`, "echo 'missing rule file' >&2\nexit 2\n")
+	registry, _ = Discover(dir)
+	err = registry.Validate(context.Background(), "cat", issue.Issue{IssueID: "abc"})
+	if err == nil || errors.As(err, &problems) || !strings.Contains(err.Error(), "plugin broken exited with code 2: missing rule file") {
+		t.Fatalf("expected plugin failure, got %v", err)
+	}
+}
+
+func TestPostSave_RunsAcceptingPluginsAndCollectsErrors(t *testing.T) {
+	// 変更の種類を受け付けるプラグインのみを実行し、応答の error や不正な応答は他のプラグインを止めずにエラーとして返すことを確認する。
+	dir := t.TempDir()
+	writePlugin(t, dir, "a-notify", `{"protocol_version": 1, "command": "run.sh", "post_save": ["issue.closed"]}`,
+		"cat > request.json\nprintf '{}'\n")
+	writePlugin(t, dir, "b-error", `{"protocol_version": 1, "command": "run.sh", "post_save": ["issue.closed"]}`,
+		"cat > /dev/null\nprintf '{\"error\": \"queue is full\"}'\n")
+	writePlugin(t, dir, "c-garbage", `{"protocol_version": 1, "command": "run.sh", "post_save": ["issue.closed"]}`,
+		"cat > /dev/null\necho not json\n")
+	writePlugin(t, dir, "d-created", `{"protocol_version": 1, "command": "run.sh", "post_save": ["issue.created"]}`,
+		"touch called\n")
+	registry, _ := Discover(dir)
+
+	errs := registry.PostSave(context.Background(), PostSaveEvent{Event: configrepo.HookEventIssueClosed, Category: "cat", IssueID: "abc"})
+	if len(errs) != 2 || !strings.Contains(errs[0].Error(), "plugin b-error: queue is full") || !strings.Contains(errs[1].Error(), "plugin c-garbage returned an invalid response") {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	request, _ := os.ReadFile(filepath.Join(dir, "a-notify", "request.json"))
+	if !strings.Contains(string(request), `"method":"post_save"`) || !strings.Contains(string(request), `"event":"issue.closed"`) {
+		t.Fatalf("unexpected request: %s", request)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "d-created", "called")); !os.IsNotExist(err) {
+		t.Fatalf("expected plugin for other events not to run: %v", err)
+	}
+}
+
+func TestCall_StopsPluginAfterTimeout(t *testing.T) {
+	// 上限時間を超えたプラグインは停止し、DeadlineExceeded を返すことを確認する。
+	dir := t.TempDir()
+	writePlugin(t, dir, "slow", `{"protocol_version": 1, "command": "run.sh", "validate": true, "timeout_seconds": 1}`, "exec sleep 10\n")
+	registry, _ := Discover(dir)
+	if err := registry.Validate(context.Background(), "cat", issue.Issue{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+}
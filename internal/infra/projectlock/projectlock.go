@@ -0,0 +1,273 @@
+// Package projectlock はプロジェクトルートの書き込み用ロックファイル (.ratta.lock) の取得・更新・解放を担う。
+// ロックは協調的なもので、ファイルシステムによる排他は行わない。読み取り専用で開くかどうかの判断は扱わない。
+// 共有フォルダ上では OS のファイルロックが効かない場合があるため、保持者が定期的に更新時刻を書き込み、
+// 更新の途絶えたロックを放棄されたものとして引き継げるようにする。
+package projectlock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/infra/atomicwrite"
+)
+
+const (
+	// FileName は DD-LOCK-002 のロックファイル名を表す。
+	FileName = ".ratta.lock"
+	// HeartbeatInterval は DD-LOCK-002 の保持者が更新時刻を書き込む間隔を表す。
+	HeartbeatInterval = 30 * time.Second
+	// staleAfter は DD-LOCK-002 の更新が途絶えたロックを放棄されたとみなすまでの時間を表す。
+	// スリープ復帰や共有フォルダの一時的な遅延で誤って引き継がれないよう、更新間隔に十分な余裕を持たせる。
+	staleAfter = 3 * HeartbeatInterval
+)
+
+var (
+	// ErrLocked は DD-LOCK-002 の他のインスタンスがプロジェクトを書き込み用に開いていることを表す。
+	ErrLocked = apperr.New(apperr.ErrConflict, "lock conflict: project is opened for writing by another instance")
+	// ErrNotStale は DD-LOCK-002 のロックが更新され続けており引き継げないことを表す。
+	ErrNotStale = apperr.New(apperr.ErrConflict, "lock conflict: lock is still in use")
+)
+
+// now は DD-LOCK-002 の時刻をテストで固定するための差し替え点。
+var now = time.Now
+
+// Holder は DD-LOCK-002 のロックの保持者を表す。Instance はホスト名と PID が同じ別プロセスを区別する。
+type Holder struct {
+	Hostname   string `json:"hostname"`
+	PID        int    `json:"pid"`
+	Instance   string `json:"instance"`
+	AcquiredAt string `json:"acquired_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+// Stale は DD-LOCK-002 のロックの更新が途絶えているかを判定する。更新時刻が読めない場合も途絶えたものとみなす。
+func (h Holder) Stale() bool {
+	updatedAt, err := time.Parse(time.RFC3339, h.UpdatedAt)
+	if err != nil {
+		return true
+	}
+	return now().Sub(updatedAt) > staleAfter
+}
+
+// self は DD-LOCK-002 のこのプロセスを表す保持者。取得・更新時刻は書き込み時に設定する。
+var self = func() Holder {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return Holder{Hostname: host, PID: os.Getpid(), Instance: fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano())}
+}()
+
+// Lock は DD-LOCK-002 の取得済みのロックを表す。
+type Lock struct {
+	path   string
+	holder Holder
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Acquire は DD-LOCK-002 のロックの取得を行う。
+// 目的: プロジェクトを書き込み用に開くインスタンスを1つに限り、共有フォルダ上での上書きを防ぐ。
+// 入力: root はプロジェクトルート。
+// 出力: 取得した Lock、他のインスタンスが保持している場合はその保持者、エラー。
+// エラー: 他のインスタンスが保持している場合は ErrLocked、ロックファイルの読み書きに失敗した場合に返す。
+// 副作用: ロックファイルを作成する。
+// 並行性: 同時に取得した場合は排他作成 (O_EXCL) により一方のみが成功する。
+// 不変条件: このプロセスが保持しているロックは再取得でき、更新時刻を書き直す。
+// 関連DD: DD-LOCK-002
+func Acquire(root string) (*Lock, Holder, error) {
+	path := filepath.Join(root, FileName)
+	holder := self
+	holder.AcquiredAt = now().UTC().Format(time.RFC3339)
+	holder.UpdatedAt = holder.AcquiredAt
+	data, err := json.MarshalIndent(holder, "", "  ")
+	if err != nil {
+		return nil, Holder{}, fmt.Errorf("marshal lock: %w", err)
+	}
+
+	// 排他作成に失敗した直後に保持者が解放した場合に備え、作成をもう1回だけ試みる。
+	for attempt := 0; ; attempt++ {
+		created, createErr := create(path, append(data, '\n'))
+		if createErr != nil {
+			return nil, Holder{}, createErr
+		}
+		if created {
+			return &Lock{path: path, holder: holder}, Holder{}, nil
+		}
+		current, readErr := read(path)
+		if errors.Is(readErr, os.ErrNotExist) && attempt == 0 {
+			continue
+		}
+		if readErr != nil {
+			return nil, Holder{}, readErr
+		}
+		if current.Instance != self.Instance {
+			return nil, current, ErrLocked
+		}
+		holder.AcquiredAt = current.AcquiredAt
+		lock := &Lock{path: path, holder: holder}
+		if writeErr := lock.write(); writeErr != nil {
+			return nil, Holder{}, writeErr
+		}
+		return lock, Holder{}, nil
+	}
+}
+
+// create は DD-LOCK-002 のロックファイルの排他作成を行う。既に存在する場合は created=false を返す。
+func create(path string, data []byte) (bool, error) {
+	// #nosec G304 -- プロジェクトルート直下の固定名のロックファイルのみを扱うため安全。
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("create lock: %w", err)
+	}
+	_, writeErr := file.Write(data)
+	closeErr := file.Close()
+	if writeErr != nil || closeErr != nil {
+		_ = os.Remove(path)
+		return false, fmt.Errorf("write lock: %w", errors.Join(writeErr, closeErr))
+	}
+	return true, nil
+}
+
+// TakeOver は DD-LOCK-002 の更新が途絶えたロックの引き継ぎを行う。
+// 目的: 異常終了したインスタンスのロックが残っていても、利用者の判断で書き込み用に開けるようにする。
+// 入力: root はプロジェクトルート、expected は Acquire で確認した保持者。
+// 出力: 取得した Lock とエラー。
+// エラー: 確認後に保持者が変わった場合は ErrLocked、更新が途絶えていない場合は ErrNotStale、
+// ロックファイルの読み書きに失敗した場合に返す。
+// 副作用: ロックファイルを置き換える。
+// 並行性: 同時に引き継いだ場合は後から書き込んだ側が保持し、先の側は更新時に喪失を検知する。
+// 不変条件: 更新の途絶えていないロックは引き継がない。
+// 関連DD: DD-LOCK-002
+func TakeOver(root string, expected Holder) (*Lock, error) {
+	path := filepath.Join(root, FileName)
+	current, err := read(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if current.Instance != expected.Instance || current.UpdatedAt != expected.UpdatedAt {
+			return nil, ErrLocked
+		}
+		if !current.Stale() {
+			return nil, ErrNotStale
+		}
+	}
+	holder := self
+	holder.AcquiredAt = now().UTC().Format(time.RFC3339)
+	holder.UpdatedAt = holder.AcquiredAt
+	lock := &Lock{path: path, holder: holder}
+	if writeErr := lock.write(); writeErr != nil {
+		return nil, writeErr
+	}
+	return lock, nil
+}
+
+// Heartbeat は DD-LOCK-002 の更新時刻の定期的な書き込みを開始する。
+// 他のインスタンスに引き継がれたことを検知した場合は onLost に新しい保持者を渡し、書き込みを止める。
+// Release で停止する。
+func (l *Lock) Heartbeat(interval time.Duration, onLost func(Holder)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stop != nil {
+		return
+	}
+	l.stop = make(chan struct{})
+	l.done = make(chan struct{})
+	go func(stop <-chan struct{}, done chan<- struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if holder, lost := l.refresh(); lost {
+					if onLost != nil {
+						onLost(holder)
+					}
+					return
+				}
+			}
+		}
+	}(l.stop, l.done)
+}
+
+// refresh は DD-LOCK-002 の更新時刻の書き込みを行う。他のインスタンスに引き継がれていた場合は lost=true と新しい保持者を返す。
+// 書き込みの失敗は共有フォルダの一時的な切断として扱い、次回の更新で再試行する。
+func (l *Lock) refresh() (Holder, bool) {
+	current, err := read(l.path)
+	if err == nil && current.Instance != l.holder.Instance {
+		return current, true
+	}
+	l.holder.UpdatedAt = now().UTC().Format(time.RFC3339)
+	_ = l.write()
+	return Holder{}, false
+}
+
+// Release は DD-LOCK-002 の更新を止め、このインスタンスが保持している場合はロックファイルを削除する。
+func (l *Lock) Release() error {
+	l.mu.Lock()
+	stop, done := l.stop, l.done
+	l.stop, l.done = nil, nil
+	l.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	current, err := read(l.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if current.Instance != l.holder.Instance {
+		return nil
+	}
+	if removeErr := os.Remove(l.path); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+		return fmt.Errorf("remove lock: %w", removeErr)
+	}
+	return nil
+}
+
+// write は DD-LOCK-002 のロックファイルへ保持者を書き込む。
+func (l *Lock) write() error {
+	data, err := json.MarshalIndent(l.holder, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal lock: %w", err)
+	}
+	if writeErr := atomicwrite.WriteFile(l.path, append(data, '\n')); writeErr != nil {
+		return fmt.Errorf("write lock: %w", writeErr)
+	}
+	return nil
+}
+
+// read は DD-LOCK-002 のロックファイルを読む。解析できない場合は更新時刻の無い保持者として返し、引き継ぎの対象とする。
+func read(path string) (Holder, error) {
+	// #nosec G304 -- プロジェクトルート直下の固定名のロックファイルのみを扱うため安全。
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Holder{}, err
+		}
+		return Holder{}, fmt.Errorf("read lock: %w", err)
+	}
+	var holder Holder
+	if json.Unmarshal(data, &holder) != nil {
+		return Holder{}, nil
+	}
+	return holder, nil
+}
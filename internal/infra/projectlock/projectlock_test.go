@@ -0,0 +1,134 @@
+// projectlock_test.go はロックファイルの取得・引き継ぎ・解放のテストを行う。
+package projectlock
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ratta/internal/infra/atomicwrite"
+)
+
+// writeHolder はテスト用に他のインスタンスのロックファイルを書き込む。
+func writeHolder(t *testing.T, root string, holder Holder) {
+	t.Helper()
+	data, err := json.Marshal(holder)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, FileName), data, 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func TestAcquire_CreatesReacquiresAndReleases(t *testing.T) {
+	// 未取得なら作成し、同じインスタンスは再取得でき、解放でロックファイルが削除されることを確認する。
+	root := t.TempDir()
+	lock, _, err := Acquire(root)
+	if err != nil {
+		t.Fatalf("Acquire error: %v", err)
+	}
+	again, _, err := Acquire(root)
+	if err != nil {
+		t.Fatalf("reacquire error: %v", err)
+	}
+	if again.holder.AcquiredAt != lock.holder.AcquiredAt {
+		t.Fatalf("expected acquired_at to be kept, got %+v", again.holder)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, FileName)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected lock file to be removed, got %v", err)
+	}
+}
+
+func TestAcquire_ReportsOtherHolderAndTakesOverOnlyWhenStale(t *testing.T) {
+	// 他のインスタンスのロックは保持者を返して取得せず、更新が途絶えた場合のみ引き継げることを確認する。
+	root := t.TempDir()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	original := now
+	t.Cleanup(func() { now = original })
+	now = func() time.Time { return base }
+	other := Holder{Hostname: "other-host", PID: 42, Instance: "other", UpdatedAt: base.Add(-time.Minute).Format(time.RFC3339)}
+	writeHolder(t, root, other)
+
+	_, holder, err := Acquire(root)
+	if !errors.Is(err, ErrLocked) || holder.Hostname != "other-host" || holder.Stale() {
+		t.Fatalf("expected fresh lock held by other, got %+v %v", holder, err)
+	}
+	if _, err := TakeOver(root, holder); !errors.Is(err, ErrNotStale) {
+		t.Fatalf("expected not stale, got %v", err)
+	}
+
+	now = func() time.Time { return base.Add(staleAfter) }
+	if !holder.Stale() {
+		t.Fatalf("expected holder to be stale")
+	}
+	lock, err := TakeOver(root, holder)
+	if err != nil {
+		t.Fatalf("TakeOver error: %v", err)
+	}
+	current, err := read(filepath.Join(root, FileName))
+	if err != nil || current.Instance != self.Instance {
+		t.Fatalf("expected lock to be taken over, got %+v %v", current, err)
+	}
+
+	// 引き継がれた側は更新時に喪失を検知し、ロックファイルを削除しない。
+	writeHolder(t, root, other)
+	if lost, isLost := lock.refresh(); !isLost || lost.Instance != "other" {
+		t.Fatalf("expected lost lock, got %+v %v", lost, isLost)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, FileName)); err != nil {
+		t.Fatalf("expected other holder's lock to remain: %v", err)
+	}
+}
+
+func TestTakeOver_TreatsCorruptLockAsStale(t *testing.T) {
+	// 解析できないロックファイルは更新の途絶えたロックとして引き継げることを確認する。
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, FileName), []byte("{"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	_, holder, err := Acquire(root)
+	if !errors.Is(err, ErrLocked) || !holder.Stale() {
+		t.Fatalf("expected stale lock, got %+v %v", holder, err)
+	}
+	if _, err := TakeOver(root, holder); err != nil {
+		t.Fatalf("TakeOver error: %v", err)
+	}
+}
+
+func TestHeartbeat_LeavesNoBackup(t *testing.T) {
+	// バックアップの世代数を設定していても、頻繁に書き換える更新時刻の書き込みでは .bak を残さないことを確認する。
+	atomicwrite.SetBackupGenerations(2)
+	t.Cleanup(func() { atomicwrite.SetBackupGenerations(0) })
+	root := t.TempDir()
+	lock, _, err := Acquire(root)
+	if err != nil {
+		t.Fatalf("Acquire error: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, lost := lock.refresh(); lost {
+			t.Fatal("expected lock to be kept")
+		}
+	}
+	lock.Heartbeat(time.Millisecond, nil)
+	time.Sleep(20 * time.Millisecond)
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release error: %v", err)
+	}
+	matches, err := filepath.Glob(filepath.Join(root, FileName+".bak*"))
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	if len(matches) != 0 {
+		t.Fatalf("expected no backup, got %v", matches)
+	}
+}
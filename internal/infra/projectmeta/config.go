@@ -0,0 +1,175 @@
+package projectmeta
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/id"
+)
+
+const (
+	configFileName = "project-config.json"
+	// MaxPageSize は DD-PROJCONF-001 のプロジェクト単位で指定できる課題一覧の表示件数の上限を表す。
+	MaxPageSize = 200
+)
+
+// ProjectConfig は DD-PROJCONF-001 の共有フォルダとともに配布するプロジェクト単位の設定を表す。
+// 各項目は 0 または未設定の場合に上書きせず、実行ファイル隣の config.json や組み込みの既定値を用いる。
+type ProjectConfig struct {
+	FormatVersion int               `json:"format_version"`
+	PageSize      int               `json:"page_size,omitempty"`
+	Attachments   *AttachmentLimits `json:"attachments,omitempty"`
+	IDs           *IDFormat         `json:"ids,omitempty"`
+	Git           *GitSettings      `json:"git,omitempty"`
+	Webhooks      []Webhook         `json:"webhooks,omitempty"`
+}
+
+// WebhookEventIssueCreated・WebhookEventIssueUpdated・WebhookEventIssueCommented は DD-HOOK-001 の通知する変更の種類を表す。
+const (
+	WebhookEventIssueCreated   = "issue.created"
+	WebhookEventIssueUpdated   = "issue.updated"
+	WebhookEventIssueCommented = "issue.commented"
+)
+
+// webhookEvents は DD-HOOK-001 の events に指定できる変更の種類を表す。
+var webhookEvents = []string{WebhookEventIssueCreated, WebhookEventIssueUpdated, WebhookEventIssueCommented}
+
+// Webhook は DD-HOOK-001 の課題の変更を JSON で POST する通知先を表す。
+// Secret は空でない場合に本文の HMAC-SHA256 の署名に用いる。Events が空の場合はすべての種類の変更を通知する。
+type Webhook struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events,omitempty"`
+}
+
+// Accepts は DD-HOOK-001 の変更の種類 event を通知するかを返す。
+func (w Webhook) Accepts(event string) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, event)
+}
+
+// GitSettings は DD-PROJCONF-001 のプロジェクトルートが git リポジトリの場合の自動コミットの設定を表す。
+// AutoCommit が false の場合は自動コミットを行わない。AuthorName・AuthorEmail は空の場合にリポジトリの
+// user.name・user.email を、それも無い場合は操作者名と既定のアドレスを用いる。
+type GitSettings struct {
+	AutoCommit  bool   `json:"auto_commit"`
+	AuthorName  string `json:"author_name,omitempty"`
+	AuthorEmail string `json:"author_email,omitempty"`
+}
+
+// IDFormat は DD-PROJCONF-001 のプロジェクト単位の課題ID・添付IDの採番方式と文字種と長さを表す。
+// 未設定の項目は組み込みの既定 (nanoid・64 文字種・9 文字) を用い、既存の ID は変更しない。
+type IDFormat struct {
+	Scheme   string `json:"scheme,omitempty"`
+	Alphabet string `json:"alphabet,omitempty"`
+	Length   int    `json:"length,omitempty"`
+}
+
+// AttachmentLimits は DD-PROJCONF-001 のプロジェクト単位の添付の制限を表す。
+// 組み込みの上限より緩くはできず、大きい値を指定した場合は組み込みの上限を用いる。
+type AttachmentLimits struct {
+	MaxBytes      int64 `json:"max_bytes,omitempty"`
+	MaxPerComment int   `json:"max_per_comment,omitempty"`
+}
+
+// ConfigPath は DD-PROJCONF-001 のプロジェクト単位の設定ファイルのパスを返す。
+func ConfigPath(root string) string {
+	return filepath.Join(Dir(root), configFileName)
+}
+
+// LoadConfig は DD-PROJCONF-001 のプロジェクト単位の設定を読み込む。
+// 目的: 共有フォルダに置いたプロジェクト固有の方針を、各端末の設定より優先して取得する。
+// 入力: root はプロジェクトルートパス。
+// 出力: ProjectConfig とエラー。未定義の場合は上書きの無い設定。
+// エラー: 読み取り・パース失敗時、形式バージョンや値が扱えない場合に返す。ファイルが無い場合はエラーにしない。
+// 副作用: ファイルを読み取る。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: 返却値の各項目は 0 (上書きなし) か扱える範囲の値とする。
+// 関連DD: DD-PROJCONF-001
+func LoadConfig(root string) (ProjectConfig, error) {
+	// #nosec G304 -- プロジェクトルート配下の固定ファイル名のみを読む。
+	data, err := os.ReadFile(ConfigPath(root))
+	if errors.Is(err, os.ErrNotExist) {
+		return ProjectConfig{FormatVersion: formatVersion}, nil
+	}
+	if err != nil {
+		return ProjectConfig{}, apperr.WithPath(fmt.Errorf("read project config: %w", err), ConfigPath(root), "")
+	}
+	var cfg ProjectConfig
+	if unmarshalErr := json.Unmarshal(data, &cfg); unmarshalErr != nil {
+		return ProjectConfig{}, apperr.WithPath(fmt.Errorf("parse project config: %w", unmarshalErr), ConfigPath(root), apperr.HintCheckJSON)
+	}
+	if validateErr := cfg.validate(); validateErr != nil {
+		return ProjectConfig{}, fmt.Errorf("invalid project config: %w", validateErr)
+	}
+	return cfg, nil
+}
+
+// AttachmentLimit は DD-PROJCONF-001 の添付の制限を返す。未設定の場合は上書きの無い制限を返す。
+func (c ProjectConfig) AttachmentLimit() AttachmentLimits {
+	if c.Attachments == nil {
+		return AttachmentLimits{}
+	}
+	return *c.Attachments
+}
+
+// IDFormat は DD-PROJCONF-001 の ID の採番方式と文字種と長さを返す。未設定の場合は既定の形式を返す。
+func (c ProjectConfig) IDFormat() id.Format {
+	if c.IDs == nil {
+		return id.Format{}
+	}
+	return id.Format{Scheme: c.IDs.Scheme, Alphabet: c.IDs.Alphabet, Length: c.IDs.Length}
+}
+
+// GitSettings は DD-PROJCONF-001 の git の自動コミットの設定を返す。未設定の場合は自動コミットを行わない設定を返す。
+func (c ProjectConfig) GitSettings() GitSettings {
+	if c.Git == nil {
+		return GitSettings{}
+	}
+	return *c.Git
+}
+
+// validate は DD-PROJCONF-001 の形式バージョンと各項目の値域を確認する。
+func (c ProjectConfig) validate() error {
+	if c.FormatVersion != formatVersion {
+		return fmt.Errorf("unsupported format_version: %d", c.FormatVersion)
+	}
+	if c.PageSize < 0 || c.PageSize > MaxPageSize {
+		return fmt.Errorf("page_size must be between 0 and %d", MaxPageSize)
+	}
+	limits := c.AttachmentLimit()
+	if limits.MaxBytes < 0 {
+		return errors.New("attachments.max_bytes must not be negative")
+	}
+	if limits.MaxPerComment < 0 {
+		return errors.New("attachments.max_per_comment must not be negative")
+	}
+	if err := c.IDFormat().Validate(); err != nil {
+		return fmt.Errorf("ids: %w", err)
+	}
+	for i, hook := range c.Webhooks {
+		if err := hook.validate(); err != nil {
+			return fmt.Errorf("webhooks[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validate は DD-HOOK-001 の通知先の URL が http または https の絶対 URL であり、変更の種類が既知であることを確認する。
+func (w Webhook) validate() error {
+	parsed, err := url.Parse(w.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	for _, event := range w.Events {
+		if !slices.Contains(webhookEvents, event) {
+			return fmt.Errorf("unknown event: %s", event)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+// config_test.go はプロジェクト単位の設定ファイルの読み取りのテストを行い、設定の適用は扱わない。
+package projectmeta
+
+import (
+	"os"
+	"testing"
+
+	"ratta/internal/domain/id"
+)
+
+// writeProjectConfig はテスト用にプロジェクト単位の設定ファイルを書き込む。
+func writeProjectConfig(t *testing.T, root, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(Dir(root), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(ConfigPath(root), []byte(contents), 0o600); err != nil {
+		t.Fatalf("write project config: %v", err)
+	}
+}
+
+func TestLoadConfig_DefaultsAndOverrides(t *testing.T) {
+	// 設定ファイルが無い場合は上書きなしを、ある場合は表示件数と添付の制限と ID の形式を返すことを確認する。
+	root := t.TempDir()
+	cfg, err := LoadConfig(root)
+	if err != nil || cfg.PageSize != 0 || cfg.AttachmentLimit() != (AttachmentLimits{}) || cfg.IDFormat() != (id.Format{}) || cfg.GitSettings().AutoCommit {
+		t.Fatalf("expected no overrides, got %+v err=%v", cfg, err)
+	}
+
+	writeProjectConfig(t, root, `{"format_version": 1, "page_size": 50, "attachments": {"max_bytes": 1024, "max_per_comment": 2}, "ids": {"length": 16}, "git": {"auto_commit": true}}`)
+	cfg, err = LoadConfig(root)
+	if err != nil {
+		t.Fatalf("LoadConfig error: %v", err)
+	}
+	if cfg.PageSize != 50 || cfg.AttachmentLimit() != (AttachmentLimits{MaxBytes: 1024, MaxPerComment: 2}) || cfg.IDFormat() != (id.Format{Length: 16}) || !cfg.GitSettings().AutoCommit {
+		t.Fatalf("unexpected project config: %+v", cfg)
+	}
+}
+
+func TestWebhook_Accepts(t *testing.T) {
+	// 変更の種類を指定しない通知先はすべてを、指定した通知先は指定した種類のみを通知することを確認する。
+	all := Webhook{URL: "https://example.com/hook"}
+	filtered := Webhook{URL: "https://example.com/hook", Events: []string{WebhookEventIssueCommented}}
+	if !all.Accepts(WebhookEventIssueCreated) || !filtered.Accepts(WebhookEventIssueCommented) || filtered.Accepts(WebhookEventIssueUpdated) {
+		t.Fatalf("unexpected filter: %+v %+v", all, filtered)
+	}
+}
+
+func TestLoadConfig_RejectsInvalidConfig(t *testing.T) {
+	// 扱えない形式バージョン・値域外の値・壊れた設定ファイルをエラーとすることを確認する。
+	for _, contents := range []string{
+		`{"format_version": 2}`,
+		`{"format_version": 1, "page_size": 1000}`,
+		`{"format_version": 1, "attachments": {"max_bytes": -1}}`,
+		`{"format_version": 1, "ids": {"alphabet": "0123456789"}}`,
+		`{"format_version": 1, "ids": {"scheme": "ulid", "length": 26}}`,
+		`{"format_version": 1, "webhooks": [{"url": "ftp://example.com/hook"}]}`,
+		`{"format_version": 1, "webhooks": [{"url": "https://example.com/hook", "events": ["issue.deleted"]}]}`,
+		"{broken",
+	} {
+		root := t.TempDir()
+		writeProjectConfig(t, root, contents)
+		if _, err := LoadConfig(root); err == nil {
+			t.Fatalf("expected error for %s", contents)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package projectmeta
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/infra/jsonfmt"
+)
+
+const formatFileName = "format.json"
+
+// FormatSettings は DD-DATA-006 のプロジェクト単位の整形の設定を表す。
+// line_ending は "lf" または "crlf"、indent_width は 0 の場合に既定値 (2) を用いる。
+// preserve_unknown_key_order は DD-DATA-007 の書き換えの際に未知のキーの元の並びを保つかを表す。
+type FormatSettings struct {
+	FormatVersion           int    `json:"format_version"`
+	LineEnding              string `json:"line_ending"`
+	IndentWidth             int    `json:"indent_width"`
+	PreserveUnknownKeyOrder bool   `json:"preserve_unknown_key_order,omitempty"`
+}
+
+// FormatPath は DD-DATA-006 の整形の設定ファイルのパスを返す。
+func FormatPath(root string) string {
+	return filepath.Join(Dir(root), formatFileName)
+}
+
+// LoadFormat は DD-DATA-006 のプロジェクト単位の整形の設定を読み込む。
+// 目的: 課題 JSON などを保存する際の改行コードとインデント幅を取得する。
+// 入力: root はプロジェクトルートパス。
+// 出力: jsonfmt.Format とエラー。未定義の場合は既定の整形。
+// エラー: 読み取り・パース失敗時、改行コードやインデント幅が扱えない値の場合に返す。
+// ファイルが無い場合はエラーにしない。
+// 副作用: ファイルを読み取る。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: 返却値は jsonfmt.Format.Validate を満たす。
+// 関連DD: DD-DATA-006, DD-DATA-007
+func LoadFormat(root string) (jsonfmt.Format, error) {
+	// #nosec G304 -- プロジェクトルート配下の固定ファイル名のみを読む。
+	data, err := os.ReadFile(FormatPath(root))
+	if errors.Is(err, os.ErrNotExist) {
+		return jsonfmt.DefaultFormat(), nil
+	}
+	if err != nil {
+		return jsonfmt.Format{}, apperr.WithPath(fmt.Errorf("read format settings: %w", err), FormatPath(root), "")
+	}
+	var settings FormatSettings
+	if unmarshalErr := json.Unmarshal(data, &settings); unmarshalErr != nil {
+		return jsonfmt.Format{}, apperr.WithPath(fmt.Errorf("parse format settings: %w", unmarshalErr), FormatPath(root), apperr.HintCheckJSON)
+	}
+	format := jsonfmt.Format{
+		LineEnding:              settings.LineEnding,
+		IndentWidth:             settings.IndentWidth,
+		PreserveUnknownKeyOrder: settings.PreserveUnknownKeyOrder,
+	}
+	if validateErr := format.Validate(); validateErr != nil {
+		return jsonfmt.Format{}, fmt.Errorf("invalid format settings: %w", validateErr)
+	}
+	return format, nil
+}
@@ -0,0 +1,46 @@
+// format_test.go は整形の設定ファイルの読み取りのテストを行い、整形そのものは扱わない。
+package projectmeta
+
+import (
+	"os"
+	"testing"
+
+	"ratta/internal/infra/jsonfmt"
+)
+
+// writeFormatSettings はテスト用に整形の設定ファイルを書き込む。
+func writeFormatSettings(t *testing.T, root, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(Dir(root), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(FormatPath(root), []byte(contents), 0o600); err != nil {
+		t.Fatalf("write format settings: %v", err)
+	}
+}
+
+func TestLoadFormat_DefaultsAndSettings(t *testing.T) {
+	// 設定ファイルが無い場合は既定の整形を、ある場合はその改行コードとインデント幅を返すことを確認する。
+	root := t.TempDir()
+	format, err := LoadFormat(root)
+	if err != nil || format != jsonfmt.DefaultFormat() {
+		t.Fatalf("expected default format, got %+v err=%v", format, err)
+	}
+
+	writeFormatSettings(t, root, `{"format_version": 1, "line_ending": "crlf", "indent_width": 4}`)
+	format, err = LoadFormat(root)
+	if err != nil || format != (jsonfmt.Format{LineEnding: jsonfmt.LineEndingCRLF, IndentWidth: 4}) {
+		t.Fatalf("unexpected format: %+v err=%v", format, err)
+	}
+}
+
+func TestLoadFormat_RejectsInvalidSettings(t *testing.T) {
+	// 扱えない改行コードや壊れた設定ファイルをエラーとすることを確認する。
+	for _, contents := range []string{`{"format_version": 1, "line_ending": "cr", "indent_width": 2}`, "{broken"} {
+		root := t.TempDir()
+		writeFormatSettings(t, root, contents)
+		if _, err := LoadFormat(root); err == nil {
+			t.Fatalf("expected error for %s", contents)
+		}
+	}
+}
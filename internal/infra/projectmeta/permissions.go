@@ -0,0 +1,104 @@
+package projectmeta
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/domain/mode"
+	"ratta/internal/infra/jsonfmt"
+)
+
+const permissionsFileName = "permissions.json"
+
+// Permissions は DD-PERM-001 のカテゴリごとの書き込み権限を表す。
+// Categories にないカテゴリは従来どおり閲覧専用モード以外で変更できる。
+type Permissions struct {
+	FormatVersion int                           `json:"format_version"`
+	Categories    map[string]CategoryPermission `json:"categories"`
+}
+
+// CategoryPermission は DD-PERM-001 のカテゴリを変更できるモードを表す。
+type CategoryPermission struct {
+	Writers []mode.Mode `json:"writers"`
+}
+
+// PermissionsPath は DD-PERM-001 のカテゴリ権限ファイルのパスを返す。
+func PermissionsPath(root string) string {
+	return filepath.Join(Dir(root), permissionsFileName)
+}
+
+// Writers は DD-PERM-001 のカテゴリを変更できるモードを返す。制限が無い場合は ok=false を返す。
+func (p Permissions) Writers(category string) ([]mode.Mode, bool) {
+	permission, ok := p.Categories[category]
+	return permission.Writers, ok
+}
+
+// LoadPermissions は DD-PERM-001 のカテゴリ権限を読み込む。
+// 目的: 利用者が定義したカテゴリごとの書き込み権限を取得する。
+// 入力: root はプロジェクトルートパス。
+// 出力: Permissions とエラー。未定義の場合はカテゴリの無い Permissions。
+// エラー: 読み取り・パース失敗時、Vendor・Contractor 以外のモードや空の writers を含む場合に返す。
+// ファイルが無い場合はエラーにしない。
+// 副作用: ファイルを読み取る。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: 返却値の Categories は nil にしない。
+// 関連DD: DD-PERM-001
+func LoadPermissions(root string) (Permissions, error) {
+	// #nosec G304 -- プロジェクトルート配下の固定ファイル名のみを読む。
+	data, err := os.ReadFile(PermissionsPath(root))
+	if errors.Is(err, os.ErrNotExist) {
+		return Permissions{FormatVersion: formatVersion, Categories: map[string]CategoryPermission{}}, nil
+	}
+	if err != nil {
+		return Permissions{}, apperr.WithPath(fmt.Errorf("read permissions: %w", err), PermissionsPath(root), "")
+	}
+	var permissions Permissions
+	if unmarshalErr := json.Unmarshal(data, &permissions); unmarshalErr != nil {
+		return Permissions{}, apperr.WithPath(fmt.Errorf("parse permissions: %w", unmarshalErr), PermissionsPath(root), apperr.HintCheckJSON)
+	}
+	if permissions.Categories == nil {
+		permissions.Categories = map[string]CategoryPermission{}
+	}
+	for category, permission := range permissions.Categories {
+		if len(permission.Writers) == 0 {
+			return Permissions{}, fmt.Errorf("invalid permissions: category %q has no writers", category)
+		}
+		for _, writer := range permission.Writers {
+			if writer != mode.ModeVendor && writer != mode.ModeContractor {
+				return Permissions{}, fmt.Errorf("invalid permissions: category %q has unknown writer %q", category, writer)
+			}
+		}
+	}
+	return permissions, nil
+}
+
+// SavePermissions は DD-PERM-001 のカテゴリ権限を atomic write で保存する。
+// 目的: カテゴリ名変更などに伴うカテゴリ権限の更新を永続化する。
+// 入力: root はプロジェクトルートパス、permissions は保存内容。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: ディレクトリ作成・整形・書き込み失敗時に返す。
+// 副作用: .ratta ディレクトリを必要に応じて作成し、permissions.json を置換する。
+// 並行性: 同時保存は想定しない。
+// 不変条件: format_version は現在の値で保存する。
+// 関連DD: DD-PERM-001, DD-PERSIST-002
+func SavePermissions(root string, permissions Permissions) error {
+	if err := os.MkdirAll(Dir(root), 0o750); err != nil {
+		return fmt.Errorf("create project meta dir: %w", err)
+	}
+	permissions.FormatVersion = formatVersion
+	if permissions.Categories == nil {
+		permissions.Categories = map[string]CategoryPermission{}
+	}
+	data, err := jsonfmt.MarshalPermissions(permissions)
+	if err != nil {
+		return fmt.Errorf("marshal permissions: %w", err)
+	}
+	if writeErr := writeFile(PermissionsPath(root), data); writeErr != nil {
+		return fmt.Errorf("write permissions: %w", writeErr)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// permissions_test.go はカテゴリ権限ファイルの読み書きのテストを行い、権限判定の適用は扱わない。
+package projectmeta
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"ratta/internal/domain/mode"
+)
+
+func TestLoadPermissions_MissingReturnsUnrestricted(t *testing.T) {
+	// 権限ファイルが無い場合はどのカテゴリにも制限が無いものとして扱うことを確認する。
+	permissions, err := LoadPermissions(t.TempDir())
+	if err != nil {
+		t.Fatalf("LoadPermissions error: %v", err)
+	}
+	if permissions.Categories == nil {
+		t.Fatal("expected non-nil categories")
+	}
+	if _, ok := permissions.Writers("any"); ok {
+		t.Fatal("expected no restriction")
+	}
+}
+
+func TestSavePermissions_RoundTrip(t *testing.T) {
+	// 保存したカテゴリ権限がキー順を固定した形で書かれ、そのまま読み戻せることを確認する。
+	root := t.TempDir()
+	input := Permissions{Categories: map[string]CategoryPermission{
+		"internal": {Writers: []mode.Mode{mode.ModeContractor}},
+	}}
+	if err := SavePermissions(root, input); err != nil {
+		t.Fatalf("SavePermissions error: %v", err)
+	}
+	data, err := os.ReadFile(PermissionsPath(root))
+	if err != nil {
+		t.Fatalf("read permissions: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "{\n  \"format_version\": 1,\n  \"categories\": {") {
+		t.Fatalf("unexpected permissions JSON:\n%s", data)
+	}
+	loaded, err := LoadPermissions(root)
+	if err != nil {
+		t.Fatalf("LoadPermissions error: %v", err)
+	}
+	writers, ok := loaded.Writers("internal")
+	if !ok || len(writers) != 1 || writers[0] != mode.ModeContractor {
+		t.Fatalf("unexpected writers: %v ok=%v", writers, ok)
+	}
+}
+
+func TestLoadPermissions_RejectsUnknownOrEmptyWriters(t *testing.T) {
+	// 未知のモードや空の writers は設定ミスとしてエラーにすることを確認する。
+	for _, body := range []string{
+		`{"format_version":1,"categories":{"internal":{"writers":["contractor"]}}}`,
+		`{"format_version":1,"categories":{"internal":{"writers":["Observer"]}}}`,
+		`{"format_version":1,"categories":{"internal":{"writers":[]}}}`,
+		`{broken`,
+	} {
+		root := t.TempDir()
+		if err := os.MkdirAll(Dir(root), 0o750); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(PermissionsPath(root), []byte(body), 0o600); err != nil {
+			t.Fatalf("write permissions: %v", err)
+		}
+		if _, err := LoadPermissions(root); err == nil {
+			t.Fatalf("expected error for %s", body)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Package projectmeta はプロジェクトルート直下の .ratta ディレクトリに置くプロジェクト単位のメタデータの読み書きを担う。
+// 権限判定やカテゴリの存在確認は上位層に委ねる。
+package projectmeta
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/infra/atomicwrite"
+	"ratta/internal/infra/jsonfmt"
+)
+
+// DirName は DD-PROJMETA-001 のプロジェクトメタデータ用ディレクトリ名を表す。
+// ドット始まりとすることでカテゴリ走査や一時ファイル検出の対象外となる。
+const DirName = ".ratta"
+
+const (
+	categoryOrderFileName = "category_order.json"
+	formatVersion         = 1
+)
+
+var writeFile = atomicwrite.WriteFile
+
+// CategoryOrder は DD-PROJMETA-001 のカテゴリ表示順を表す。
+type CategoryOrder struct {
+	FormatVersion int      `json:"format_version"`
+	Categories    []string `json:"categories"`
+}
+
+// Dir は DD-PROJMETA-001 のメタデータディレクトリのパスを返す。
+func Dir(root string) string {
+	return filepath.Join(root, DirName)
+}
+
+// CategoryOrderPath は DD-PROJMETA-001 のカテゴリ表示順ファイルのパスを返す。
+func CategoryOrderPath(root string) string {
+	return filepath.Join(Dir(root), categoryOrderFileName)
+}
+
+// LoadCategoryOrder は DD-PROJMETA-001 のカテゴリ表示順を読み込む。
+// 目的: 利用者が定義した明示的なカテゴリ順を取得する。
+// 入力: root はプロジェクトルートパス。
+// 出力: カテゴリ名の並びとエラー。未定義の場合は空配列。
+// エラー: 読み取り・パース失敗時に返す。ファイルが無い場合はエラーにしない。
+// 副作用: ファイルを読み取る。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: 返却値は保存時の順序を維持する。
+// 関連DD: DD-PROJMETA-001
+func LoadCategoryOrder(root string) ([]string, error) {
+	// #nosec G304 -- プロジェクトルート配下の固定ファイル名のみを読む。
+	data, err := os.ReadFile(filepath.Join(Dir(root), categoryOrderFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, apperr.WithPath(fmt.Errorf("read category order: %w", err), filepath.Join(Dir(root), categoryOrderFileName), "")
+	}
+	var order CategoryOrder
+	if unmarshalErr := json.Unmarshal(data, &order); unmarshalErr != nil {
+		return nil, apperr.WithPath(fmt.Errorf("parse category order: %w", unmarshalErr), filepath.Join(Dir(root), categoryOrderFileName), apperr.HintCheckJSON)
+	}
+	if order.Categories == nil {
+		return []string{}, nil
+	}
+	return order.Categories, nil
+}
+
+// SaveCategoryOrder は DD-PROJMETA-001 のカテゴリ表示順を保存する。
+// 目的: 明示的なカテゴリ順を atomic write で永続化する。
+// 入力: root はプロジェクトルートパス、names は表示順に並べたカテゴリ名。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: ディレクトリ作成・整形・書き込み失敗時に返す。
+// 副作用: .ratta ディレクトリを必要に応じて作成し、category_order.json を置換する。
+// 並行性: 同時保存は想定しない。
+// 不変条件: names の検証 (重複・存在確認) は呼び出し側で済ませる。
+// 関連DD: DD-PROJMETA-001, DD-PERSIST-002
+func SaveCategoryOrder(root string, names []string) error {
+	if err := os.MkdirAll(Dir(root), 0o750); err != nil {
+		return fmt.Errorf("create project meta dir: %w", err)
+	}
+	if names == nil {
+		names = []string{}
+	}
+	data, err := jsonfmt.MarshalCategoryOrder(CategoryOrder{FormatVersion: formatVersion, Categories: names})
+	if err != nil {
+		return fmt.Errorf("marshal category order: %w", err)
+	}
+	if writeErr := writeFile(filepath.Join(Dir(root), categoryOrderFileName), data); writeErr != nil {
+		return fmt.Errorf("write category order: %w", writeErr)
+	}
+	return nil
+}
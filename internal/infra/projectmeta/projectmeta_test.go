@@ -0,0 +1,32 @@
+// projectmeta_test.go はプロジェクトメタデータの読み書きのテストを行い、カテゴリ操作の統合は扱わない。
+package projectmeta
+
+import (
+	"testing"
+)
+
+func TestLoadCategoryOrder_MissingReturnsEmpty(t *testing.T) {
+	// 表示順が未定義の場合は空配列を返しエラーにしないことを確認する。
+	names, err := LoadCategoryOrder(t.TempDir())
+	if err != nil {
+		t.Fatalf("LoadCategoryOrder error: %v", err)
+	}
+	if names == nil || len(names) != 0 {
+		t.Fatalf("unexpected names: %v", names)
+	}
+}
+
+func TestSaveCategoryOrder_RoundTrip(t *testing.T) {
+	// 保存した順序がそのまま読み戻せることを確認する。
+	root := t.TempDir()
+	if err := SaveCategoryOrder(root, []string{"z", "a"}); err != nil {
+		t.Fatalf("SaveCategoryOrder error: %v", err)
+	}
+	names, err := LoadCategoryOrder(root)
+	if err != nil {
+		t.Fatalf("LoadCategoryOrder error: %v", err)
+	}
+	if len(names) != 2 || names[0] != "z" || names[1] != "a" {
+		t.Fatalf("unexpected names: %v", names)
+	}
+}
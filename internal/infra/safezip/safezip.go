@@ -0,0 +1,88 @@
+// Package safezip は DD-BUNDLE-002 の受け取った zip の通常ファイルを、安全なパスと上限の大きさに限ってメモリへ読み込むことを担い、
+// 読み込んだ内容と manifest の照合や署名の検証は扱わない。照合は課題バンドル・パッチの各パッケージが担う。
+package safezip
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"ratta/internal/domain/apperr"
+)
+
+// Limits は DD-BUNDLE-002 の読み込む内容の上限 (バイト) を表す。
+// MaxEntryBytes はエントリ1件の展開後の大きさ、MaxTotalBytes は全エントリの展開後の大きさの合計の上限を表す。
+type Limits struct {
+	MaxEntryBytes int64
+	MaxTotalBytes int64
+}
+
+// ReadFiles は DD-BUNDLE-002 の zip の通常ファイルを読み込む。
+// 目的: 小さな圧縮ファイルが展開時に大きく膨らむ zip や、展開先の外を指す名前のエントリで、アプリのメモリや取り込み先を損なわないようにする。
+// 入力: files は zip のエントリ一覧、limits は展開後の大きさの上限。
+// 出力: zip 内のパスをキーとする内容のマップとエラー。
+// エラー: 危険なパス (絶対パス・親参照・バックスラッシュ・正規化されていない名前) の場合、エントリ1件または合計が上限を超える場合は
+// apperr.ErrValidation を、エントリを開けない・読み取れない場合はその他のエラーを返す。
+// 副作用: zip のエントリを読み取る。
+// 並行性: 読み取りのみで、呼び出しごとに独立しているためスレッドセーフ。
+// 不変条件: ディレクトリエントリは含めない。上限を超えるエントリは開く前に、ヘッダーを偽ったエントリも上限を1バイト超えた時点で読み込みを打ち切る。
+// 関連DD: DD-BUNDLE-002, DD-PATCH-002
+func ReadFiles(files []*zip.File, limits Limits) (map[string][]byte, error) {
+	entries := make(map[string][]byte, len(files))
+	var total int64
+	for _, file := range files {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		name, err := CleanName(file.Name)
+		if err != nil {
+			return nil, err
+		}
+		// 展開後の大きさはヘッダーの値で先に判定し、上限を超えるエントリは展開を始めない。
+		if file.UncompressedSize64 > uint64(limits.MaxEntryBytes) {
+			return nil, apperr.Errorf(apperr.ErrValidation, "zip entry exceeds %d bytes: %s", limits.MaxEntryBytes, name)
+		}
+		if total+int64(file.UncompressedSize64) > limits.MaxTotalBytes {
+			return nil, apperr.Errorf(apperr.ErrValidation, "zip entries exceed %d bytes in total", limits.MaxTotalBytes)
+		}
+		data, err := readEntry(file, name, limits.MaxEntryBytes)
+		if err != nil {
+			return nil, err
+		}
+		total += int64(len(data))
+		entries[name] = data
+	}
+	return entries, nil
+}
+
+// CleanName は DD-BUNDLE-002 の zip のエントリ名を検査し、展開先の中を指す名前のみを返す。
+// zip slip 対策として、正規化で変わる名前、絶対パス、親参照、バックスラッシュを含む名前は一律に拒否する。
+func CleanName(name string) (string, error) {
+	cleaned := path.Clean(name)
+	if cleaned != name || path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") || strings.Contains(cleaned, "\\") {
+		return "", apperr.Errorf(apperr.ErrValidation, "unsafe zip entry: %s", name)
+	}
+	return cleaned, nil
+}
+
+// readEntry は DD-BUNDLE-002 のエントリ1件を maxBytes まで読み込む。
+// ヘッダーの大きさを偽ったエントリに備え、maxBytes を1バイトでも超えて読めた場合は上限超過とする。
+func readEntry(file *zip.File, name string, maxBytes int64) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open zip entry %s: %w", name, err)
+	}
+	data, readErr := io.ReadAll(io.LimitReader(rc, maxBytes+1))
+	if closeErr := rc.Close(); closeErr != nil && readErr == nil {
+		readErr = closeErr
+	}
+	if readErr != nil {
+		return nil, fmt.Errorf("read zip entry %s: %w", name, readErr)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, apperr.Errorf(apperr.ErrValidation, "zip entry exceeds %d bytes: %s", maxBytes, name)
+	}
+	return data, nil
+}
@@ -0,0 +1,77 @@
+// safezip_test.go は zip エントリの安全な読み込みのテストを行う。
+package safezip
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"testing"
+
+	"ratta/internal/domain/apperr"
+)
+
+// openZip はテスト用に名前→内容の zip を作成し、そのエントリ一覧を返す。
+func openZip(t *testing.T, entries map[string][]byte) []*zip.File {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, data := range entries {
+		w, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("create entry: %v", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatalf("write entry: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	return reader.File
+}
+
+func TestReadFiles_ReadsEntriesWithinLimits(t *testing.T) {
+	// 上限内のエントリはパスをキーとして内容を読み込むことを確認する。
+	files := openZip(t, map[string][]byte{"a.json": []byte("{}"), "a.files/x.txt": []byte("x")})
+	entries, err := ReadFiles(files, Limits{MaxEntryBytes: 2, MaxTotalBytes: 3})
+	if err != nil {
+		t.Fatalf("ReadFiles error: %v", err)
+	}
+	if string(entries["a.json"]) != "{}" || string(entries["a.files/x.txt"]) != "x" {
+		t.Fatalf("unexpected entries: %v", entries)
+	}
+}
+
+func TestReadFiles_RejectsUnsafeOrOversizedEntries(t *testing.T) {
+	// 危険なパスと、エントリ1件または合計が上限を超える zip を入力エラーとして拒否することを確認する。
+	cases := map[string]struct {
+		entries map[string][]byte
+		limits  Limits
+	}{
+		"parent reference": {map[string][]byte{"../evil.txt": []byte("x")}, Limits{MaxEntryBytes: 10, MaxTotalBytes: 10}},
+		"absolute path":    {map[string][]byte{"/evil.txt": []byte("x")}, Limits{MaxEntryBytes: 10, MaxTotalBytes: 10}},
+		"backslash":        {map[string][]byte{`a\evil.txt`: []byte("x")}, Limits{MaxEntryBytes: 10, MaxTotalBytes: 10}},
+		"entry too large":  {map[string][]byte{"a.bin": make([]byte, 11)}, Limits{MaxEntryBytes: 10, MaxTotalBytes: 100}},
+		"total too large":  {map[string][]byte{"a.bin": make([]byte, 6), "b.bin": make([]byte, 6)}, Limits{MaxEntryBytes: 10, MaxTotalBytes: 10}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ReadFiles(openZip(t, tc.entries), tc.limits); !errors.Is(err, apperr.ErrValidation) {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReadFiles_StopsReadingWhenHeaderUnderstatesSize(t *testing.T) {
+	// ヘッダーの展開後の大きさを偽ったエントリも、上限を超えた時点で読み込みを打ち切ることを確認する。
+	files := openZip(t, map[string][]byte{"a.bin": make([]byte, 100)})
+	files[0].UncompressedSize64 = 1
+	if _, err := ReadFiles(files, Limits{MaxEntryBytes: 10, MaxTotalBytes: 100}); err == nil {
+		t.Fatal("expected read to be cut off")
+	}
+}
@@ -0,0 +1,86 @@
+// cache.go は課題JSONのスキーマ検証結果をファイル状態 (mtime/サイズ) 単位で再利用するキャッシュを担う。
+// キャッシュはプロセス内のみで保持し、永続化はしない。
+package schema
+
+import (
+	"os"
+	"sync"
+)
+
+// maxCachedResults は DD-VALCACHE-001 の保持件数の上限を表す。
+// 上限に達した場合は全件を破棄し、長時間の利用でメモリが増え続けないようにする。
+const maxCachedResults = 20000
+
+// FileStamp は DD-VALCACHE-001 の検証結果を再利用してよいかの判定に用いるファイル状態を表す。
+type FileStamp struct {
+	ModTime   int64
+	SizeBytes int64
+}
+
+// StampOf は DD-VALCACHE-001 のファイル情報から FileStamp を生成する。
+func StampOf(info os.FileInfo) FileStamp {
+	return FileStamp{ModTime: info.ModTime().UnixNano(), SizeBytes: info.Size()}
+}
+
+// cachedResult は DD-VALCACHE-001 のパス単位の検証結果を表す。
+type cachedResult struct {
+	stamp  FileStamp
+	result ValidationResult
+}
+
+// resultCache は DD-VALCACHE-001 の検証結果キャッシュを表す。
+type resultCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedResult
+}
+
+// newResultCache は DD-VALCACHE-001 の空のキャッシュを生成する。
+func newResultCache() *resultCache {
+	return &resultCache{entries: make(map[string]cachedResult)}
+}
+
+// get は DD-VALCACHE-001 のファイル状態が一致する検証結果を返す。
+func (c *resultCache) get(path string, stamp FileStamp) (ValidationResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.entries[path]
+	if !ok || cached.stamp != stamp {
+		return ValidationResult{}, false
+	}
+	return cached.result, true
+}
+
+// put は DD-VALCACHE-001 の検証結果を保存する。
+func (c *resultCache) put(path string, stamp FileStamp, result ValidationResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[path]; !ok && len(c.entries) >= maxCachedResults {
+		c.entries = make(map[string]cachedResult)
+	}
+	c.entries[path] = cachedResult{stamp: stamp, result: result}
+}
+
+// ValidateIssueFile は DD-VALCACHE-001 のキャッシュ付き issue スキーマ検証を行う。
+// 目的: 変更の無い課題JSONに対する再検証を省き、繰り返しの走査を高速化する。
+// 入力: path は課題JSONパス、stamp は読み込み前に取得したファイル状態、data は読み込んだ内容。
+// 出力: ValidationResult とエラー。
+// エラー: パース・検証失敗時に返す。失敗結果はキャッシュしない。
+// 副作用: 検証結果をプロセス内に保持する。
+// 並行性: スレッドセーフ。
+// 不変条件: path と stamp が一致する場合のみ過去の結果を返す。
+// 読み込み前の状態をキーにするため、読み込み中に更新されても次回は状態の不一致で再検証される。
+// 関連DD: DD-VALCACHE-001, DD-BE-002
+func (v *Validator) ValidateIssueFile(path string, stamp FileStamp, data []byte) (ValidationResult, error) {
+	if v.cache == nil {
+		return v.ValidateIssue(data)
+	}
+	if result, ok := v.cache.get(path, stamp); ok {
+		return result, nil
+	}
+	result, err := v.ValidateIssue(data)
+	if err != nil {
+		return ValidationResult{}, err
+	}
+	v.cache.put(path, stamp, result)
+	return result, nil
+}
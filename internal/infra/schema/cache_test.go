@@ -0,0 +1,60 @@
+// cache_test.go は検証結果キャッシュの再利用条件のテストを行い、スキーマ内容の妥当性は扱わない。
+package schema
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateIssueFile_ReusesResultWhileStampMatches(t *testing.T) {
+	// ファイル状態が一致する間は再検証せず前回結果を返し、状態が変わると再検証することを確認する。
+	validator, err := NewValidatorFromDir(filepath.Join("..", "..", "..", "schemas"))
+	if err != nil {
+		t.Fatalf("NewValidatorFromDir error: %v", err)
+	}
+	stamp := FileStamp{ModTime: 100, SizeBytes: 10}
+
+	first, err := validator.ValidateIssueFile("cat/a.json", stamp, []byte(`{"issue_id":"abc"}`))
+	if err != nil {
+		t.Fatalf("ValidateIssueFile error: %v", err)
+	}
+	if len(first.Issues) == 0 {
+		t.Fatal("expected validation issues")
+	}
+
+	// 内容を差し替えても状態が同じであれば、検証をスキップして前回結果を返す。
+	cached, err := validator.ValidateIssueFile("cat/a.json", stamp, []byte(`{`))
+	if err != nil {
+		t.Fatalf("expected cached result, got error: %v", err)
+	}
+	if len(cached.Issues) != len(first.Issues) {
+		t.Fatalf("expected cached issues, got %+v", cached)
+	}
+
+	if _, err := validator.ValidateIssueFile("cat/a.json", FileStamp{ModTime: 200, SizeBytes: 10}, []byte(`{`)); err == nil {
+		t.Fatal("expected revalidation error after stamp change")
+	}
+	if _, err := validator.ValidateIssueFile("cat/b.json", stamp, []byte(`{`)); err == nil {
+		t.Fatal("expected validation error for another path")
+	}
+}
+
+func TestResultCache_ResetsWhenFull(t *testing.T) {
+	// 上限件数に達した状態で新しいパスを追加すると、古い結果が破棄されることを確認する。
+	cache := newResultCache()
+	for i := 0; i < maxCachedResults; i++ {
+		cache.put(fmt.Sprintf("cat/%d.json", i), FileStamp{}, ValidationResult{})
+	}
+	cache.put("cat/0.json", FileStamp{ModTime: 1}, ValidationResult{})
+	if len(cache.entries) != maxCachedResults {
+		t.Fatalf("expected update in place, got %d entries", len(cache.entries))
+	}
+	cache.put("cat/overflow.json", FileStamp{}, ValidationResult{})
+	if len(cache.entries) != 1 {
+		t.Fatalf("expected cache reset, got %d entries", len(cache.entries))
+	}
+	if _, ok := cache.get("cat/overflow.json", FileStamp{}); !ok {
+		t.Fatal("expected new entry to be kept")
+	}
+}
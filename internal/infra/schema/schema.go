@@ -2,10 +2,14 @@
 package schema
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -28,6 +32,30 @@ func LoadSchemasFromDir(dir string) (map[string]*jsonschema.Schema, error) {
 		return nil, fmt.Errorf("resolve schema dir: %w", err)
 	}
 
+	compiler := newDirCompiler(absDir)
+	entries, err := os.ReadDir(absDir)
+	if err != nil {
+		return nil, fmt.Errorf("read schema dir: %w", err)
+	}
+
+	compiled := make(map[string]*jsonschema.Schema)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(absDir, entry.Name())
+		compiledSchema, compileErr := compiler.Compile(path)
+		if compileErr != nil {
+			return nil, fmt.Errorf("compile schema %s: %w", entry.Name(), compileErr)
+		}
+		compiled[entry.Name()] = compiledSchema
+	}
+
+	return compiled, nil
+}
+
+// newDirCompiler は DD-BE-002 の absDir 配下のファイルのみを参照できるコンパイラを生成する。
+func newDirCompiler(absDir string) *jsonschema.Compiler {
 	compiler := jsonschema.NewCompiler()
 	compiler.LoadURL = func(ref string) (io.ReadCloser, error) {
 		parsed, parseErr := url.Parse(ref)
@@ -45,26 +73,58 @@ func LoadSchemasFromDir(dir string) (map[string]*jsonschema.Schema, error) {
 			return nil, fmt.Errorf("unsupported schema ref: %s", ref)
 		}
 	}
+	return compiler
+}
 
-	entries, err := os.ReadDir(absDir)
+// Extension は DD-BE-002 の課題の custom_fields に適用する追加スキーマを表す。Name はファイル名。
+type Extension struct {
+	Name   string
+	Schema *jsonschema.Schema
+}
+
+// ExtensionProblem は DD-BE-002 の読み込めなかった追加スキーマを表す。
+type ExtensionProblem struct {
+	Name string
+	Err  error
+}
+
+// LoadExtensionsFromDir は DD-BE-002 の dir/extensions 配下の追加スキーマをコンパイルする。
+// 目的: プロジェクト独自の custom_fields の規則を、同梱のスキーマを書き換えずに追加できるようにする。
+// 入力: dir はスキーマディレクトリ (組み込みのスキーマを置くディレクトリ)。
+// 出力: ファイル名順の Extension の配列と、読み込めなかった追加スキーマの配列。
+// エラー: 返却値で表現しない。読み込めない追加スキーマは ExtensionProblem に含め、他の追加スキーマの読み込みを続ける。
+// 副作用: スキーマファイルを読み取る。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: extensions が無い場合は空を返す。参照は dir 配下に限り、組み込みのスキーマの $defs を参照できる。
+// 関連DD: DD-BE-002
+func LoadExtensionsFromDir(dir string) ([]Extension, []ExtensionProblem) {
+	absDir, err := filepath.Abs(dir)
 	if err != nil {
-		return nil, fmt.Errorf("read schema dir: %w", err)
+		return nil, []ExtensionProblem{{Name: ExtensionsDirName, Err: fmt.Errorf("resolve schema dir: %w", err)}}
+	}
+	entries, err := os.ReadDir(filepath.Join(absDir, ExtensionsDirName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []ExtensionProblem{{Name: ExtensionsDirName, Err: fmt.Errorf("read extensions dir: %w", err)}}
 	}
 
-	compiled := make(map[string]*jsonschema.Schema)
+	compiler := newDirCompiler(absDir)
+	var extensions []Extension
+	var problems []ExtensionProblem
 	for _, entry := range entries {
 		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
 			continue
 		}
-		path := filepath.Join(absDir, entry.Name())
-		compiledSchema, compileErr := compiler.Compile(path)
+		compiledSchema, compileErr := compiler.Compile(filepath.Join(absDir, ExtensionsDirName, entry.Name()))
 		if compileErr != nil {
-			return nil, fmt.Errorf("compile schema %s: %w", entry.Name(), compileErr)
+			problems = append(problems, ExtensionProblem{Name: entry.Name(), Err: fmt.Errorf("compile extension: %w", compileErr)})
+			continue
 		}
-		compiled[entry.Name()] = compiledSchema
+		extensions = append(extensions, Extension{Name: entry.Name(), Schema: compiledSchema})
 	}
-
-	return compiled, nil
+	return extensions, problems
 }
 
 // openSchemaFile は DD-BE-002 のローカル限定ルールを満たすファイルを開く。
@@ -90,3 +150,43 @@ func openSchemaFile(baseDir, path string) (io.ReadCloser, error) {
 	}
 	return file, nil
 }
+
+// LoadSchemasFromFS は DD-BE-002 に従い fsys 直下の JSON Schema をコンパイルし、外部参照は拒否する。
+// 目的: 実行ファイルへ同梱したスキーマを、ディレクトリから読み込む場合と同じ規則で読み込む。
+// 入力: fsys はスキーマを含むファイルシステム。
+// 出力: スキーマ名とコンパイル済みスキーマのマップ、エラー。
+// エラー: 読み込み・コンパイル失敗時に返す。
+// 副作用: なし。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: スキーマ内の参照は同じスキーマ内に限り、他のファイル・外部への参照は拒否する。
+// 関連DD: DD-BE-002
+func LoadSchemasFromFS(fsys fs.FS) (map[string]*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.LoadURL = func(ref string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("schema refs outside the schema are not allowed: %s", ref)
+	}
+
+	names, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, fmt.Errorf("read schema fs: %w", err)
+	}
+	for _, name := range names {
+		data, readErr := fs.ReadFile(fsys, name)
+		if readErr != nil {
+			return nil, fmt.Errorf("read schema %s: %w", name, readErr)
+		}
+		if addErr := compiler.AddResource(path.Base(name), bytes.NewReader(data)); addErr != nil {
+			return nil, fmt.Errorf("add schema %s: %w", name, addErr)
+		}
+	}
+
+	compiled := make(map[string]*jsonschema.Schema)
+	for _, name := range names {
+		compiledSchema, compileErr := compiler.Compile(path.Base(name))
+		if compileErr != nil {
+			return nil, fmt.Errorf("compile schema %s: %w", name, compileErr)
+		}
+		compiled[path.Base(name)] = compiledSchema
+	}
+	return compiled, nil
+}
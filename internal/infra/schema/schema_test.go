@@ -18,6 +18,7 @@ func TestLoadSchemasFromDir_AllowsLocalSchemas(t *testing.T) {
 		"issue.schema.json",
 		"config.schema.json",
 		"contractor.schema.json",
+		"users.schema.json",
 	} {
 		if compiled[name] == nil {
 			t.Fatalf("expected schema %s to be loaded", name)
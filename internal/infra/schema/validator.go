@@ -6,8 +6,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"strings"
 
+	"ratta/schemas"
+
 	"github.com/santhosh-tekuri/jsonschema/v5"
 )
 
@@ -15,11 +18,28 @@ const (
 	IssueSchemaName      = "issue.schema.json"
 	ConfigSchemaName     = "config.schema.json"
 	ContractorSchemaName = "contractor.schema.json"
+	UsersSchemaName      = "users.schema.json"
+
+	// EmbeddedSource は DD-BE-002 の同梱したスキーマを読み込んだことを表す Source の値。
+	EmbeddedSource = "embedded"
+	// ExtensionsDirName は DD-BE-002 の custom_fields に適用する追加スキーマを置くディレクトリ名 (スキーマディレクトリ配下) を表す。
+	ExtensionsDirName = "extensions"
+	// customFieldsKey は DD-DATA-003 の追加スキーマを適用する課題JSONの項目名を表す。
+	customFieldsKey = "custom_fields"
 )
 
+// requiredSchemas は DD-BE-002 のディレクトリから読み込む場合に揃っている必要のあるスキーマを表す。
+var requiredSchemas = []string{IssueSchemaName, ConfigSchemaName, ContractorSchemaName, UsersSchemaName}
+
 // Validator は DD-BE-002 のスキーマ検証方針に従い検証を行う。
+// source は読み込んだスキーマのディレクトリ、同梱したスキーマの場合は EmbeddedSource を表す。
+// extensions は custom_fields に適用する追加スキーマ、extensionProblems は読み込めなかった追加スキーマを表す。
 type Validator struct {
-	schemas map[string]*jsonschema.Schema
+	schemas           map[string]*jsonschema.Schema
+	extensions        []Extension
+	extensionProblems []ExtensionProblem
+	cache             *resultCache
+	source            string
 }
 
 // ValidationIssue はスキーマ不整合の詳細を表す。
@@ -52,22 +72,117 @@ func (r ValidationResult) Detail() string {
 // 目的: 検証に必要なスキーマ群を読み込み Validator を生成する。
 // 入力: dir はスキーマディレクトリ。
 // 出力: Validator とエラー。
-// エラー: スキーマ読み込み失敗時に返す。
+// エラー: スキーマ読み込み失敗時に返す。追加スキーマの読み込み失敗は返さず ExtensionProblems で返す。
 // 副作用: スキーマファイルを読み取る。
 // 並行性: 読み取りのみでスレッドセーフ。
-// 不変条件: 必須スキーマが揃っていること。
+// 不変条件: 必須スキーマが揃っていること。dir/extensions の追加スキーマも読み込む。
 // 関連DD: DD-BE-002
 func NewValidatorFromDir(dir string) (*Validator, error) {
 	compiled, err := LoadSchemasFromDir(dir)
 	if err != nil {
 		return nil, fmt.Errorf("load schemas: %w", err)
 	}
-	return &Validator{schemas: compiled}, nil
+	extensions, problems := LoadExtensionsFromDir(dir)
+	return &Validator{
+		schemas:           compiled,
+		extensions:        extensions,
+		extensionProblems: problems,
+		cache:             newResultCache(),
+		source:            dir,
+	}, nil
+}
+
+// NewValidator は DD-BE-002 のスキーマを dirs の順に探して読み込み、いずれも読み込めない場合は同梱のスキーマを用いる。
+// 目的: 実行ファイルの隣に schemas/ が無い配布でも、検証を無効にせずに起動できるようにする。
+// 入力: dirs は優先順のスキーマディレクトリ。空文字は無視する。
+// 出力: Validator とエラー。
+// エラー: 同梱のスキーマの読み込みに失敗した場合に返す (ビルドの不備を表す)。
+// 副作用: スキーマファイルを読み取る。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: 必須スキーマが欠けたディレクトリは採用しない。採用した読み込み元は Source で返す。
+// 関連DD: DD-BE-002
+func NewValidator(dirs ...string) (*Validator, error) {
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		validator, err := NewValidatorFromDir(dir)
+		if err == nil && validator.hasRequired() {
+			return validator, nil
+		}
+	}
+	return NewValidatorFromFS(schemas.FS, EmbeddedSource)
+}
+
+// NewValidatorFromFS は DD-BE-002 の fsys 直下のスキーマを読み込む。source は読み込み元として Source で返す値。
+func NewValidatorFromFS(fsys fs.FS, source string) (*Validator, error) {
+	compiled, err := LoadSchemasFromFS(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("load schemas: %w", err)
+	}
+	return &Validator{schemas: compiled, cache: newResultCache(), source: source}, nil
+}
+
+// Source は DD-BE-002 の読み込んだスキーマのディレクトリ、同梱したスキーマの場合は EmbeddedSource を返す。
+func (v *Validator) Source() string {
+	return v.source
 }
 
-// ValidateIssue は DD-DATA-003 の issue スキーマを検証する。
+// Extensions は DD-BE-002 の読み込んだ追加スキーマのファイル名を返す。
+func (v *Validator) Extensions() []string {
+	names := make([]string, 0, len(v.extensions))
+	for _, extension := range v.extensions {
+		names = append(names, extension.Name)
+	}
+	return names
+}
+
+// ExtensionProblems は DD-BE-002 の読み込めなかった追加スキーマを返す。読み込めなかった追加スキーマは検証に用いない。
+func (v *Validator) ExtensionProblems() []ExtensionProblem {
+	return v.extensionProblems
+}
+
+// hasRequired は DD-BE-002 の必須スキーマがすべて読み込まれているかを返す。
+func (v *Validator) hasRequired() bool {
+	for _, name := range requiredSchemas {
+		if _, ok := v.schemas[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateIssue は DD-DATA-003 の issue スキーマを検証し、custom_fields があれば追加スキーマでも検証する。
+// 追加スキーマの不整合は /custom_fields 配下の位置と追加スキーマのファイル名を付けて報告する。
 func (v *Validator) ValidateIssue(data []byte) (ValidationResult, error) {
-	return v.validateBytes(IssueSchemaName, data)
+	result, value, err := v.validateBytesValue(IssueSchemaName, data)
+	if err != nil || len(v.extensions) == 0 {
+		return result, err
+	}
+	doc, _ := value.(map[string]any)
+	fields, ok := doc[customFieldsKey]
+	if !ok {
+		return result, nil
+	}
+	for _, extension := range v.extensions {
+		if validateErr := extension.Schema.Validate(fields); validateErr != nil {
+			issues := collectIssues(validateErr)
+			if len(issues) == 0 {
+				return ValidationResult{}, fmt.Errorf("validate extension %s: %w", extension.Name, validateErr)
+			}
+			for _, found := range issues {
+				location := "/" + customFieldsKey
+				if found.InstanceLocation != "/" {
+					location += found.InstanceLocation
+				}
+				result.Issues = append(result.Issues, ValidationIssue{
+					InstanceLocation: location,
+					Message:          extension.Name + ": " + found.Message,
+				})
+			}
+		}
+	}
+	return result, nil
 }
 
 // ValidateConfig は DD-DATA-001 の config スキーマを検証する。
@@ -80,6 +195,11 @@ func (v *Validator) ValidateContractor(data []byte) (ValidationResult, error) {
 	return v.validateBytes(ContractorSchemaName, data)
 }
 
+// ValidateUsers は DD-CLI-007 の users スキーマを検証する。
+func (v *Validator) ValidateUsers(data []byte) (ValidationResult, error) {
+	return v.validateBytes(UsersSchemaName, data)
+}
+
 // validateBytes は DD-BE-002 の共通検証処理を行う。
 // 目的: 指定スキーマで JSON データを検証する。
 // 入力: schemaName はスキーマ名、data は JSON バイト列。
@@ -90,25 +210,31 @@ func (v *Validator) ValidateContractor(data []byte) (ValidationResult, error) {
 // 不変条件: スキーマ不整合は ValidationResult に格納する。
 // 関連DD: DD-BE-002
 func (v *Validator) validateBytes(schemaName string, data []byte) (ValidationResult, error) {
+	result, _, err := v.validateBytesValue(schemaName, data)
+	return result, err
+}
+
+// validateBytesValue は DD-BE-002 の共通検証処理を行い、解析した値も返す。
+func (v *Validator) validateBytesValue(schemaName string, data []byte) (ValidationResult, any, error) {
 	schema, ok := v.schemas[schemaName]
 	if !ok {
-		return ValidationResult{}, fmt.Errorf("schema not loaded: %s", schemaName)
+		return ValidationResult{}, nil, fmt.Errorf("schema not loaded: %s", schemaName)
 	}
 
 	var value any
 	if unmarshalErr := json.Unmarshal(data, &value); unmarshalErr != nil {
-		return ValidationResult{}, fmt.Errorf("parse json: %w", unmarshalErr)
+		return ValidationResult{}, nil, fmt.Errorf("parse json: %w", unmarshalErr)
 	}
 
 	if err := schema.Validate(value); err != nil {
 		issues := collectIssues(err)
 		if len(issues) > 0 {
-			return ValidationResult{Issues: issues}, nil
+			return ValidationResult{Issues: issues}, value, nil
 		}
-		return ValidationResult{}, fmt.Errorf("validate schema: %w", err)
+		return ValidationResult{}, nil, fmt.Errorf("validate schema: %w", err)
 	}
 
-	return ValidationResult{}, nil
+	return ValidationResult{}, value, nil
 }
 
 // collectIssues は DD-BE-002 の詳細表示向けに検証エラーを収集する。
@@ -2,9 +2,14 @@
 package schema
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"ratta/schemas"
+
 	"github.com/santhosh-tekuri/jsonschema/v5"
 )
 
@@ -40,6 +45,87 @@ func TestValidateContractor_ReturnsIssues(t *testing.T) {
 	}
 }
 
+func TestValidateContractor_KDFIterationRanges(t *testing.T) {
+	// 鍵導出方式ごとに kdf_iterations の許容範囲を検査することを確認する。
+	validator, err := NewValidatorFromDir(filepath.Join("..", "..", "..", "schemas"))
+	if err != nil {
+		t.Fatalf("NewValidatorFromDir error: %v", err)
+	}
+	cases := []struct {
+		kdf        string
+		iterations int
+		valid      bool
+	}{
+		{"pbkdf2-hmac-sha256", 200000, true},
+		{"pbkdf2-hmac-sha256", 3, false},
+		{"argon2id", 3, true},
+		{"argon2id", 200000, false},
+		{"scrypt", 3, false},
+	}
+	for _, tc := range cases {
+		data := fmt.Sprintf(`{"format_version":1,"kdf":%q,"kdf_iterations":%d,"salt_b64":"AA==","nonce_b64":"AA==","ciphertext_b64":"AA==","mode":"contractor"}`,
+			tc.kdf, tc.iterations)
+		result, validateErr := validator.ValidateContractor([]byte(data))
+		if validateErr != nil {
+			t.Fatalf("ValidateContractor error: %v", validateErr)
+		}
+		if got := len(result.Issues) == 0; got != tc.valid {
+			t.Fatalf("%s/%d: expected valid=%v, got issues %+v", tc.kdf, tc.iterations, tc.valid, result.Issues)
+		}
+	}
+}
+
+func TestValidateContractor_TOTPFieldsTogether(t *testing.T) {
+	// TOTP の nonce と暗号文は揃っている場合のみ受け付けることを確認する。
+	validator, err := NewValidatorFromDir(filepath.Join("..", "..", "..", "schemas"))
+	if err != nil {
+		t.Fatalf("NewValidatorFromDir error: %v", err)
+	}
+	base := `"format_version":1,"kdf":"argon2id","kdf_iterations":3,"salt_b64":"AA==","nonce_b64":"AA==","ciphertext_b64":"AA==","mode":"contractor"`
+	cases := map[string]bool{
+		`{` + base + `,"totp_nonce_b64":"AA==","totp_ciphertext_b64":"AA=="}`: true,
+		`{` + base + `,"totp_nonce_b64":"AA=="}`:                              false,
+		`{` + base + `,"totp_ciphertext_b64":"AA=="}`:                         false,
+	}
+	for data, valid := range cases {
+		result, validateErr := validator.ValidateContractor([]byte(data))
+		if validateErr != nil {
+			t.Fatalf("ValidateContractor error: %v", validateErr)
+		}
+		if got := len(result.Issues) == 0; got != valid {
+			t.Fatalf("%s: expected valid=%v, got issues %+v", data, valid, result.Issues)
+		}
+	}
+}
+
+func TestValidateUsers_ChecksAccounts(t *testing.T) {
+	// users.json のアカウントの必須項目と鍵導出方式ごとの反復回数を検査することを確認する。
+	validator, err := NewValidatorFromDir(filepath.Join("..", "..", "..", "schemas"))
+	if err != nil {
+		t.Fatalf("NewValidatorFromDir error: %v", err)
+	}
+	account := `{"username":%q,"kdf":"argon2id","kdf_iterations":%d,"salt_b64":"AA==","nonce_b64":"AA==","ciphertext_b64":"AA=="}`
+	cases := []struct {
+		data  string
+		valid bool
+	}{
+		{`{"format_version":1,"users":[]}`, true},
+		{`{"format_version":1,"users":[` + fmt.Sprintf(account, "alice", 3) + `]}`, true},
+		{`{"format_version":1,"users":[` + fmt.Sprintf(account, "", 3) + `]}`, false},
+		{`{"format_version":1,"users":[` + fmt.Sprintf(account, "alice", 200000) + `]}`, false},
+		{`{"format_version":1}`, false},
+	}
+	for _, tc := range cases {
+		result, validateErr := validator.ValidateUsers([]byte(tc.data))
+		if validateErr != nil {
+			t.Fatalf("ValidateUsers error: %v", validateErr)
+		}
+		if got := len(result.Issues) == 0; got != tc.valid {
+			t.Fatalf("%s: expected valid=%v, got issues %+v", tc.data, tc.valid, result.Issues)
+		}
+	}
+}
+
 func TestValidateIssue_SchemaMissing(t *testing.T) {
 	// スキーマが未ロードの場合にエラーになることを確認する。
 	validator := &Validator{schemas: map[string]*jsonschema.Schema{}}
@@ -64,6 +150,53 @@ func TestValidateConfig_ReturnsIssues(t *testing.T) {
 	}
 }
 
+func TestValidateConfig_ContractorIdleTimeoutRange(t *testing.T) {
+	// auth.contractor_idle_timeout_minutes は 0〜1440 の整数のみを受け付けることを確認する。
+	validator, err := NewValidatorFromDir(filepath.Join("..", "..", "..", "schemas"))
+	if err != nil {
+		t.Fatalf("NewValidatorFromDir error: %v", err)
+	}
+	base := `{"format_version":1,"last_project_root_path":"","log":{"level":"info"},"ui":{"page_size":20},"auth":{"contractor_idle_timeout_minutes":%d}}`
+	for minutes, valid := range map[int]bool{0: true, 30: true, 1440: true, -1: false, 1441: false} {
+		result, validateErr := validator.ValidateConfig([]byte(fmt.Sprintf(base, minutes)))
+		if validateErr != nil {
+			t.Fatalf("ValidateConfig error: %v", validateErr)
+		}
+		if (len(result.Issues) == 0) != valid {
+			t.Fatalf("minutes %d: expected valid=%v, got %v", minutes, valid, result.Issues)
+		}
+	}
+}
+
+func TestValidateConfig_PasswordPolicyRange(t *testing.T) {
+	// auth.password_policy は文字数 0〜128・文字種 0〜4 のみを受け付けることを確認する。
+	validator, err := NewValidatorFromDir(filepath.Join("..", "..", "..", "schemas"))
+	if err != nil {
+		t.Fatalf("NewValidatorFromDir error: %v", err)
+	}
+	base := `{"format_version":1,"last_project_root_path":"","log":{"level":"info"},"ui":{"page_size":20},` +
+		`"auth":{"contractor_idle_timeout_minutes":0,"password_policy":{"min_length":%d,"min_char_classes":%d,"allow_common":false}}}`
+	cases := []struct {
+		length, classes int
+		valid           bool
+	}{
+		{0, 0, true},
+		{16, 4, true},
+		{129, 2, false},
+		{12, 5, false},
+		{-1, 2, false},
+	}
+	for _, tc := range cases {
+		result, validateErr := validator.ValidateConfig([]byte(fmt.Sprintf(base, tc.length, tc.classes)))
+		if validateErr != nil {
+			t.Fatalf("ValidateConfig error: %v", validateErr)
+		}
+		if (len(result.Issues) == 0) != tc.valid {
+			t.Fatalf("%d/%d: expected valid=%v, got %v", tc.length, tc.classes, tc.valid, result.Issues)
+		}
+	}
+}
+
 func TestValidationResult_Detail(t *testing.T) {
 	// Detail が空と複数エラーの整形を行うことを確認する。
 	if detail := (ValidationResult{}).Detail(); detail != "" {
@@ -87,3 +220,81 @@ func TestNewValidatorFromDir_MissingDir(t *testing.T) {
 		t.Fatal("expected load error")
 	}
 }
+
+func TestNewValidator_PrefersDirAndFallsBackToEmbedded(t *testing.T) {
+	// 必須スキーマの揃ったディレクトリを優先し、無い場合は同梱のスキーマで検証できることを確認する。
+	dir := filepath.Join("..", "..", "..", "schemas")
+	validator, err := NewValidator("", filepath.Join(t.TempDir(), "missing"), dir)
+	if err != nil {
+		t.Fatalf("NewValidator error: %v", err)
+	}
+	if validator.Source() != dir {
+		t.Fatalf("unexpected source: %s", validator.Source())
+	}
+
+	validator, err = NewValidator(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewValidator error: %v", err)
+	}
+	if validator.Source() != EmbeddedSource {
+		t.Fatalf("expected embedded schemas, got %s", validator.Source())
+	}
+	result, err := validator.ValidateIssue([]byte(`{"issue_id":"abc"}`))
+	if err != nil {
+		t.Fatalf("ValidateIssue error: %v", err)
+	}
+	if len(result.Issues) == 0 {
+		t.Fatal("expected validation issues")
+	}
+}
+
+func TestValidateIssue_AppliesCustomFieldExtensions(t *testing.T) {
+	// extensions 配下の追加スキーマを custom_fields に適用し、読み込めない追加スキーマは検証に用いず報告することを確認する。
+	dir := t.TempDir()
+	if err := os.CopyFS(dir, schemas.FS); err != nil {
+		t.Fatalf("copy schemas: %v", err)
+	}
+	extDir := filepath.Join(dir, ExtensionsDirName)
+	if err := os.MkdirAll(extDir, 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	build := `{"type":"object","required":["build"],"properties":{"build":{"type":"string"}}}`
+	if err := os.WriteFile(filepath.Join(extDir, "build.json"), []byte(build), 0o600); err != nil {
+		t.Fatalf("write extension: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(extDir, "broken.json"), []byte(`{"type":5}`), 0o600); err != nil {
+		t.Fatalf("write extension: %v", err)
+	}
+
+	validator, err := NewValidatorFromDir(dir)
+	if err != nil {
+		t.Fatalf("NewValidatorFromDir error: %v", err)
+	}
+	if names := validator.Extensions(); len(names) != 1 || names[0] != "build.json" {
+		t.Fatalf("unexpected extensions: %v", names)
+	}
+	if problems := validator.ExtensionProblems(); len(problems) != 1 || problems[0].Name != "broken.json" {
+		t.Fatalf("unexpected extension problems: %+v", problems)
+	}
+
+	issueJSON := func(customFields string) []byte {
+		return []byte(`{"version":2,"issue_id":"abc123DEF","category":"cat","title":"t","description":"d",` +
+			`"status":"Open","priority":"High","origin_company":"Vendor",` +
+			`"created_at":"2024-01-01T00:00:00Z","updated_at":"2024-01-01T00:00:00Z","due_date":"2024-01-01",` +
+			customFields + `"comments":[]}`)
+	}
+	result, err := validator.ValidateIssue(issueJSON(`"custom_fields":{"build":1},`))
+	if err != nil {
+		t.Fatalf("ValidateIssue error: %v", err)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].InstanceLocation != "/custom_fields/build" ||
+		!strings.HasPrefix(result.Issues[0].Message, "build.json: ") {
+		t.Fatalf("unexpected issues: %+v", result.Issues)
+	}
+	for _, fields := range []string{`"custom_fields":{"build":"1.0"},`, ``} {
+		result, err = validator.ValidateIssue(issueJSON(fields))
+		if err != nil || len(result.Issues) != 0 {
+			t.Fatalf("expected valid issue for %q: %+v err=%v", fields, result.Issues, err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Package scripthook は DD-HOOK-003 の課題の変更の際に端末ごとの config.json の hooks に設定したスクリプトを実行することを担い、
+// 実行する変更の判断やスクリプトの出力の解釈は扱わず、呼び出し側が変更の種類を決めて呼び出す。
+package scripthook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/configrepo"
+	"ratta/internal/infra/jsonfmt"
+	"ratta/internal/infra/limitbuf"
+)
+
+const (
+	// EnvEvent などは DD-HOOK-003 のスクリプトへ変更の内容を渡す環境変数の名前を表す。
+	EnvEvent       = "RATTA_EVENT"
+	EnvProjectRoot = "RATTA_PROJECT_ROOT"
+	EnvCategory    = "RATTA_CATEGORY"
+	EnvIssueID     = "RATTA_ISSUE_ID"
+	EnvMode        = "RATTA_MODE"
+	EnvActor       = "RATTA_ACTOR"
+
+	// maxOutputBytes は DD-HOOK-003 の失敗の調査のためにエラーへ含める標準出力・標準エラーの上限を表す。
+	maxOutputBytes = 4 << 10
+	// waitDelay は DD-HOOK-003 の上限を超えて停止した後、子プロセスが出力を閉じるのを待つ上限を表す。
+	waitDelay = 2 * time.Second
+)
+
+// Invocation は DD-HOOK-003 のスクリプト1回の実行で渡す変更の内容を表す。
+// Event は configrepo.HookEventIssueCreated などの変更の種類、Issue は変更後の課題を表す。
+type Invocation struct {
+	Event       string
+	ProjectRoot string
+	Category    string
+	IssueID     string
+	Mode        string
+	Actor       string
+	Issue       issue.Issue
+}
+
+// Run は DD-HOOK-003 の変更の種類に設定したスクリプトを実行する。
+// 目的: コードを変えずに、拠点ごとの通知や外部システムへの出力を課題の変更へ結び付けられるようにする。
+// 入力: ctx は中断用、hooks は config.json の hooks、baseDir は相対パスの基準とする config.json のあるディレクトリ、
+// invocation は変更の内容。
+// 出力: 成功時、または変更の種類にスクリプトを設定していない場合は nil、失敗時はエラー。
+// エラー: 起動の失敗、0 以外の終了コード、上限時間の超過や ctx の中断で停止した場合に返す。終了コードによるエラーは出力の先頭を含む。
+// 副作用: シェルを介さずにスクリプトを起動し、課題JSONを標準入力へ書く。作業ディレクトリはプロジェクトルートとする。
+// 並行性: スクリプトの終了 (または停止) まで待つ。複数のゴルーチンから呼び出してよい。
+// 不変条件: 課題JSONは保存する課題ファイルと同じ形式とし、変更の内容は RATTA_* の環境変数でも渡す。
+// 関連DD: DD-HOOK-003
+func Run(ctx context.Context, hooks *configrepo.Hooks, baseDir string, invocation Invocation) error {
+	command := hooks.Command(invocation.Event, baseDir)
+	if command == "" {
+		return nil
+	}
+	stdin, err := jsonfmt.MarshalIssue(invocation.Issue)
+	if err != nil {
+		return fmt.Errorf("marshal issue for hook %s: %w", invocation.Event, err)
+	}
+	runCtx, cancel := context.WithTimeout(ctx, hooks.Timeout())
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, command)
+	cmd.Dir = invocation.ProjectRoot
+	cmd.Env = append(os.Environ(),
+		EnvEvent+"="+invocation.Event,
+		EnvProjectRoot+"="+invocation.ProjectRoot,
+		EnvCategory+"="+invocation.Category,
+		EnvIssueID+"="+invocation.IssueID,
+		EnvMode+"="+invocation.Mode,
+		EnvActor+"="+invocation.Actor,
+	)
+	cmd.Stdin = bytes.NewReader(stdin)
+	output := limitbuf.New(maxOutputBytes)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.WaitDelay = waitDelay
+
+	err = cmd.Run()
+	if err == nil {
+		return nil
+	}
+	if ctxErr := runCtx.Err(); ctxErr != nil {
+		return fmt.Errorf("hook %s stopped after %s: %w", invocation.Event, hooks.Timeout(), ctxErr)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("hook %s exited with code %d: %s", invocation.Event, exitErr.ExitCode(), output.String())
+	}
+	return fmt.Errorf("run hook %s: %w", invocation.Event, err)
+}
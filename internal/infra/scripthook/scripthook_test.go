@@ -0,0 +1,83 @@
+// scripthook_test.go はフックのスクリプトへの課題JSONと環境変数の受け渡し、失敗と上限時間の扱いのテストを行う。
+package scripthook
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/configrepo"
+)
+
+// writeScript はテスト用に dir へ実行可能なシェルスクリプトを作成し、ファイル名を返す。
+func writeScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on windows")
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	return name
+}
+
+func TestRun_PassesIssueJSONAndEnvironment(t *testing.T) {
+	// 相対パスのスクリプトを config.json のディレクトリから探し、プロジェクトルートで課題JSONを標準入力、変更の内容を環境変数として渡すことを確認する。
+	configDir, root := t.TempDir(), t.TempDir()
+	script := writeScript(t, configDir, "closed.sh", "cat > stdin.json\necho \"$RATTA_EVENT $RATTA_CATEGORY $RATTA_ISSUE_ID $RATTA_MODE $RATTA_ACTOR\" > env.txt\n")
+	hooks := &configrepo.Hooks{OnIssueClosed: script}
+
+	err := Run(context.Background(), hooks, configDir, Invocation{
+		Event:       configrepo.HookEventIssueClosed,
+		ProjectRoot: root,
+		Category:    "cat",
+		IssueID:     "abc",
+		Mode:        "Vendor",
+		Actor:       "sato",
+		Issue:       issue.Issue{IssueID: "abc", Title: "done", Status: issue.StatusClosed},
+	})
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	stdin, _ := os.ReadFile(filepath.Join(root, "stdin.json"))
+	if !strings.Contains(string(stdin), `"title": "done"`) || !strings.Contains(string(stdin), `"status": "Closed"`) {
+		t.Fatalf("unexpected issue JSON: %s", stdin)
+	}
+	env, _ := os.ReadFile(filepath.Join(root, "env.txt"))
+	if got := strings.TrimSpace(string(env)); got != "issue.closed cat abc Vendor sato" {
+		t.Fatalf("unexpected environment: %q", got)
+	}
+}
+
+func TestRun_SkipsUnsetEvents(t *testing.T) {
+	// hooks が無い場合や変更の種類にスクリプトを設定していない場合は何も実行しないことを確認する。
+	if err := Run(context.Background(), nil, t.TempDir(), Invocation{Event: configrepo.HookEventIssueCreated}); err != nil {
+		t.Fatalf("expected nil for missing hooks: %v", err)
+	}
+	hooks := &configrepo.Hooks{OnIssueClosed: "missing.sh"}
+	if err := Run(context.Background(), hooks, t.TempDir(), Invocation{Event: configrepo.HookEventIssueUpdated}); err != nil {
+		t.Fatalf("expected nil for unset event: %v", err)
+	}
+}
+
+func TestRun_ReportsFailureAndTimeout(t *testing.T) {
+	// 0 以外の終了コードは出力を含めて返し、上限時間を超えたスクリプトは停止して DeadlineExceeded を返すことを確認する。
+	dir := t.TempDir()
+	failing := writeScript(t, dir, "fail.sh", "echo 'cannot reach chat' >&2\nexit 3\n")
+	slow := writeScript(t, dir, "slow.sh", "exec sleep 10\n")
+	hooks := &configrepo.Hooks{OnIssueCreated: failing, OnIssueUpdated: slow, TimeoutSeconds: 1}
+
+	err := Run(context.Background(), hooks, dir, Invocation{Event: configrepo.HookEventIssueCreated, ProjectRoot: dir})
+	if err == nil || !strings.Contains(err.Error(), "exited with code 3") || !strings.Contains(err.Error(), "cannot reach chat") {
+		t.Fatalf("unexpected failure error: %v", err)
+	}
+	err = Run(context.Background(), hooks, dir, Invocation{Event: configrepo.HookEventIssueUpdated, ProjectRoot: dir})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+}
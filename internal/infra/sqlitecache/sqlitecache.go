@@ -0,0 +1,393 @@
+// Package sqlitecache は大規模プロジェクト向けに .ratta/cache.db (SQLite) へ課題一覧の要約を保持するキャッシュを担う。
+// 課題JSONが常に正本であり、本パッケージは一覧の絞り込み・並び替え・ページングの高速化のみを扱う。
+package sqlitecache
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ratta/internal/domain/issue"
+	"ratta/internal/infra/issueindex"
+	"ratta/internal/infra/projectmeta"
+	"ratta/internal/infra/workerpool"
+
+	// cgo を必要としない純 Go 実装の SQLite ドライバを利用する。
+	_ "modernc.org/sqlite"
+)
+
+// FileName は DD-CACHE-001 のキャッシュDBファイル名を表す。
+const FileName = "cache.db"
+
+// schemaVersion は DD-CACHE-001 のテーブル定義バージョンを表す。
+// 定義を変更した場合は値を上げ、既存テーブルを作り直させる。
+const schemaVersion = 1
+
+// Query は DD-CACHE-001 の一覧条件を表す。
+// AfterIssueID を指定した場合は、並び順で (AfterKey, AfterIssueID) より後ろの行のみを返す。
+type Query struct {
+	Status       string
+	Priority     string
+	SortBy       string
+	SortOrder    string
+	Limit        int
+	Offset       int
+	AfterKey     string
+	AfterIssueID string
+}
+
+// Cache は DD-CACHE-001 の SQLite キャッシュを表す。
+type Cache struct {
+	db          *sql.DB
+	concurrency int
+}
+
+// Path は DD-CACHE-001 のキャッシュDBのパスを返す。
+func Path(root string) string {
+	return filepath.Join(projectmeta.Dir(root), FileName)
+}
+
+// Enabled は DD-CACHE-001 のキャッシュ有効判定を行う。
+// キャッシュDBが存在することを有効化の印とし、設定ファイルを別に持たない。
+func Enabled(root string) bool {
+	info, err := os.Stat(Path(root))
+	if err != nil {
+		return false
+	}
+	return info.Mode().IsRegular()
+}
+
+// Enable は DD-CACHE-001 のキャッシュ有効化を行う。
+// 目的: キャッシュDBとテーブルを作成し、以後の一覧取得で利用されるようにする。
+// 入力: root はプロジェクトルートパス。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: ディレクトリ作成・DB作成失敗時に返す。
+// 副作用: .ratta/cache.db を作成する。
+// 並行性: 同時実行は想定しない。
+// 不変条件: 既に有効な場合もテーブル定義を保証して成功する。
+// 関連DD: DD-CACHE-001
+func Enable(root string) error {
+	if err := os.MkdirAll(projectmeta.Dir(root), 0o750); err != nil {
+		return fmt.Errorf("create project meta dir: %w", err)
+	}
+	cache, err := open(Path(root))
+	if err != nil {
+		return err
+	}
+	return cache.Close()
+}
+
+// Disable は DD-CACHE-001 のキャッシュ無効化を行う。
+// キャッシュDBと SQLite の付随ファイルを削除する。課題JSONには影響しない。
+func Disable(root string) error {
+	path := Path(root)
+	for _, target := range []string{path, path + "-wal", path + "-shm", path + "-journal"} {
+		if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove cache: %w", err)
+		}
+	}
+	return nil
+}
+
+// Open は DD-CACHE-001 の有効化済みキャッシュを開く。
+// 目的: 一覧取得のためにキャッシュDBへ接続する。
+// 入力: root はプロジェクトルートパス。
+// 出力: Cache とエラー。
+// エラー: 未有効化、または接続・テーブル準備失敗時に返す。
+// 副作用: 必要に応じてテーブルを作成・再作成する。
+// 並行性: 返却した Cache は呼び出し側で Close する。
+// 不変条件: 未有効化のプロジェクトにDBファイルを作成しない。
+// 関連DD: DD-CACHE-001
+func Open(root string) (*Cache, error) {
+	if !Enabled(root) {
+		return nil, errors.New("sqlite cache is not enabled")
+	}
+	return open(Path(root))
+}
+
+// open はDBへ接続しテーブル定義を保証する。
+func open(path string) (*Cache, error) {
+	db, err := sql.Open("sqlite", "file:"+filepath.ToSlash(path)+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("open cache: %w", err)
+	}
+	cache := &Cache{db: db}
+	if migrateErr := cache.migrate(); migrateErr != nil {
+		if closeErr := db.Close(); closeErr != nil {
+			return nil, fmt.Errorf("prepare cache failed: %w; close error: %s", migrateErr, closeErr.Error())
+		}
+		return nil, migrateErr
+	}
+	return cache, nil
+}
+
+// Close は DD-CACHE-001 のDB接続を閉じる。
+func (c *Cache) Close() error {
+	if err := c.db.Close(); err != nil {
+		return fmt.Errorf("close cache: %w", err)
+	}
+	return nil
+}
+
+// WithConcurrency は DD-SCAN-001 の再読込の並列度を設定する。0 以下は既定値を用いる。
+func (c *Cache) WithConcurrency(concurrency int) *Cache {
+	c.concurrency = concurrency
+	return c
+}
+
+// migrate は DD-CACHE-001 のテーブル定義を保証する。
+// キャッシュは再構築可能なため、定義が古い場合は移行せず作り直す。
+func (c *Cache) migrate() error {
+	var version int
+	if err := c.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("read cache version: %w", err)
+	}
+	if version == schemaVersion {
+		return nil
+	}
+	statements := []string{
+		"DROP TABLE IF EXISTS issues",
+		`CREATE TABLE issues (
+			category TEXT NOT NULL,
+			file_name TEXT NOT NULL,
+			issue_id TEXT NOT NULL,
+			title TEXT NOT NULL,
+			status TEXT NOT NULL,
+			priority TEXT NOT NULL,
+			origin_company TEXT NOT NULL,
+			updated_at TEXT NOT NULL,
+			due_date TEXT NOT NULL,
+			is_schema_invalid INTEGER NOT NULL,
+			mtime INTEGER NOT NULL,
+			size_bytes INTEGER NOT NULL,
+			PRIMARY KEY (category, file_name)
+		)`,
+		"CREATE INDEX issues_category_status ON issues (category, status)",
+		"CREATE INDEX issues_category_priority ON issues (category, priority)",
+		fmt.Sprintf("PRAGMA user_version = %d", schemaVersion),
+	}
+	for _, statement := range statements {
+		if _, err := c.db.Exec(statement); err != nil {
+			return fmt.Errorf("prepare cache schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// SyncCategory は DD-CACHE-001 のカテゴリ単位の同期を行う。
+// 目的: カテゴリ配下の課題JSONとキャッシュを突き合わせ、変更分だけを反映する。
+// 入力: categoryPath はカテゴリパス、category はカテゴリ名、load は再読込関数。
+// 出力: 成功時は nil、失敗時はエラー。
+// エラー: カテゴリ読み取り、キャッシュの読み書き失敗時に返す。
+// 副作用: issues テーブルを更新する。
+// 並行性: 1 トランザクションで反映するため、途中状態は他の参照から見えない。
+// 課題JSONの再読込はトランザクション開始前に上限付きで並列に行う。
+// 不変条件: 同期後のカテゴリ行は、読み込みに成功した現存の課題JSONと一致する。
+// 関連DD: DD-CACHE-001, DD-INDEX-001
+func (c *Cache) SyncCategory(categoryPath, category string, load issueindex.LoadFunc) error {
+	dirEntries, err := os.ReadDir(categoryPath)
+	if err != nil {
+		return fmt.Errorf("read category: %w", err)
+	}
+	known, err := c.fileStamps(category)
+	if err != nil {
+		return err
+	}
+
+	stale := staleFiles(dirEntries, known)
+	loaded := make([]issueindex.Entry, len(stale))
+	loadErrs := make([]error, len(stale))
+	workerpool.Run(c.concurrency, len(stale), func(i int) {
+		loaded[i], loadErrs[i] = load(filepath.Join(categoryPath, stale[i].name))
+	})
+
+	tx, err := c.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin cache sync: %w", err)
+	}
+	if syncErr := syncEntries(tx, category, stale, loaded, loadErrs, known); syncErr != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("cache sync failed: %w; rollback error: %s", syncErr, rollbackErr.Error())
+		}
+		return syncErr
+	}
+	if commitErr := tx.Commit(); commitErr != nil {
+		return fmt.Errorf("commit cache sync: %w", commitErr)
+	}
+	return nil
+}
+
+// fileStamp は鮮度判定に用いる mtime とサイズの組を表す。
+type fileStamp struct {
+	modTime   int64
+	sizeBytes int64
+}
+
+// staleFile は再読込が必要な課題JSONを表す。
+type staleFile struct {
+	name string
+	info os.FileInfo
+}
+
+// fileStamps は DD-CACHE-001 のカテゴリ内の既知ファイルの鮮度情報を取得する。
+func (c *Cache) fileStamps(category string) (map[string]fileStamp, error) {
+	rows, err := c.db.Query("SELECT file_name, mtime, size_bytes FROM issues WHERE category = ?", category)
+	if err != nil {
+		return nil, fmt.Errorf("query cache stamps: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	stamps := make(map[string]fileStamp)
+	for rows.Next() {
+		var name string
+		var stamp fileStamp
+		if scanErr := rows.Scan(&name, &stamp.modTime, &stamp.sizeBytes); scanErr != nil {
+			return nil, fmt.Errorf("scan cache stamps: %w", scanErr)
+		}
+		stamps[name] = stamp
+	}
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, fmt.Errorf("iterate cache stamps: %w", rowsErr)
+	}
+	return stamps, nil
+}
+
+// staleFiles は DD-CACHE-001 の再読込が必要な課題JSONを抽出する。
+// 現存するファイルは known から取り除くため、残ったものが削除済みの課題となる。
+func staleFiles(dirEntries []os.DirEntry, known map[string]fileStamp) []staleFile {
+	var stale []staleFile
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if dirEntry.IsDir() || !issue.IsIssueFileName(name) {
+			continue
+		}
+		info, infoErr := dirEntry.Info()
+		if infoErr != nil {
+			continue
+		}
+		stamp, ok := known[name]
+		delete(known, name)
+		if ok && stamp.modTime == info.ModTime().UnixNano() && stamp.sizeBytes == info.Size() {
+			continue
+		}
+		stale = append(stale, staleFile{name: name, info: info})
+	}
+	return stale
+}
+
+// syncEntries は DD-CACHE-001 の差分をトランザクション内で反映する。
+func syncEntries(tx *sql.Tx, category string, stale []staleFile, loaded []issueindex.Entry, loadErrs []error, removed map[string]fileStamp) error {
+	for i, file := range stale {
+		if loadErrs[i] != nil {
+			// 読めない課題は一覧に出さないため、古い行が残っていれば取り除く。
+			if _, err := tx.Exec("DELETE FROM issues WHERE category = ? AND file_name = ?", category, file.name); err != nil {
+				return fmt.Errorf("delete cache row: %w", err)
+			}
+			continue
+		}
+		entry := loaded[i]
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO issues
+			(category, file_name, issue_id, title, status, priority, origin_company, updated_at, due_date, is_schema_invalid, mtime, size_bytes)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			category, file.name, entry.IssueID, entry.Title, entry.Status, entry.Priority, entry.OriginCompany,
+			entry.UpdatedAt, entry.DueDate, entry.IsSchemaInvalid, file.info.ModTime().UnixNano(), file.info.Size()); err != nil {
+			return fmt.Errorf("upsert cache row: %w", err)
+		}
+	}
+	for name := range removed {
+		if _, err := tx.Exec("DELETE FROM issues WHERE category = ? AND file_name = ?", category, name); err != nil {
+			return fmt.Errorf("delete cache row: %w", err)
+		}
+	}
+	return nil
+}
+
+// List は DD-CACHE-001 の一覧取得を行う。
+// 目的: 絞り込み・並び替え・ページングをDB側で行い、必要な行だけを返す。
+// 入力: category はカテゴリ名、query は一覧条件。
+// 出力: 該当ページのエントリ、絞り込み後の総件数、エラー。
+// エラー: クエリ失敗時に返す。
+// 副作用: なし。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: 並び順は issueops の一覧と同じ規則 (同値は issue_id 昇順) に従う。
+// 関連DD: DD-CACHE-001, DD-BE-003
+func (c *Cache) List(category string, query Query) ([]issueindex.Entry, int, error) {
+	where := "category = ?"
+	args := []any{category}
+	if query.Status != "" {
+		where += " AND status = ?"
+		args = append(args, query.Status)
+	}
+	if query.Priority != "" {
+		where += " AND priority = ?"
+		args = append(args, query.Priority)
+	}
+
+	var total int
+	if err := c.db.QueryRow("SELECT COUNT(*) FROM issues WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count cache rows: %w", err)
+	}
+
+	direction := "ASC"
+	comparison := ">"
+	if query.SortOrder == "desc" {
+		direction = "DESC"
+		comparison = "<"
+	}
+	if query.AfterIssueID != "" {
+		// 総件数はカーソル位置に関わらず絞り込み後の件数とするため、位置の条件は件数取得の後に加える。
+		// 同値の場合は sort_order に関わらず issue_id 昇順で並ぶため、issue_id は常に > で比較する。
+		column := sortExpression(query.SortBy)
+		key := sortKeyExpression(query.SortBy, "?")
+		where += " AND (" + column + " " + comparison + " " + key + " OR (" + column + " = " + key + " AND issue_id > ?))"
+		args = append(args, query.AfterKey, query.AfterKey, query.AfterIssueID)
+	}
+	// ORDER BY 句は固定の候補からのみ組み立て、利用者入力を SQL に直接埋め込まない。
+	statement := "SELECT file_name, issue_id, title, status, priority, origin_company, updated_at, due_date, is_schema_invalid, mtime, size_bytes FROM issues WHERE " +
+		where + " ORDER BY " + sortExpression(query.SortBy) + " " + direction + ", issue_id ASC LIMIT ? OFFSET ?"
+	args = append(args, query.Limit, query.Offset)
+	rows, err := c.db.Query(statement, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query cache rows: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	entries := make([]issueindex.Entry, 0, query.Limit)
+	for rows.Next() {
+		var entry issueindex.Entry
+		if scanErr := rows.Scan(&entry.FileName, &entry.IssueID, &entry.Title, &entry.Status, &entry.Priority,
+			&entry.OriginCompany, &entry.UpdatedAt, &entry.DueDate, &entry.IsSchemaInvalid, &entry.ModTime, &entry.SizeBytes); scanErr != nil {
+			return nil, 0, fmt.Errorf("scan cache row: %w", scanErr)
+		}
+		entries = append(entries, entry)
+	}
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, 0, fmt.Errorf("iterate cache rows: %w", rowsErr)
+	}
+	return entries, total, nil
+}
+
+// sortExpression は DD-BE-003 の sort_by に対応する並び替え式を返す。
+func sortExpression(sortBy string) string {
+	switch sortBy {
+	case "updated_at", "due_date", "title", "priority", "status":
+		return sortKeyExpression(sortBy, sortBy)
+	default:
+		return sortKeyExpression(sortBy, "issue_id")
+	}
+}
+
+// sortKeyExpression は DD-BE-003 の sort_by に従い、operand (列名またはプレースホルダ) を並び替えの値へ変換する式を返す。
+// 優先度とステータスは定義順で並べるため、値を順位へ変換する。
+func sortKeyExpression(sortBy, operand string) string {
+	switch sortBy {
+	case "priority":
+		return "CASE " + operand + " WHEN 'High' THEN 0 WHEN 'Medium' THEN 1 WHEN 'Low' THEN 2 ELSE 3 END"
+	case "status":
+		return "CASE " + operand + " WHEN 'Open' THEN 0 WHEN 'Working' THEN 1 WHEN 'Inquiry' THEN 2 WHEN 'Hold' THEN 3 " +
+			"WHEN 'Feedback' THEN 4 WHEN 'Resolved' THEN 5 WHEN 'Closed' THEN 6 WHEN 'Rejected' THEN 7 ELSE 8 END"
+	default:
+		return operand
+	}
+}
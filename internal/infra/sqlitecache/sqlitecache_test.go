@@ -0,0 +1,111 @@
+// sqlitecache_test.go は SQLite キャッシュの同期と一覧取得のテストを行い、課題JSONの検証は扱わない。
+package sqlitecache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ratta/internal/infra/issueindex"
+)
+
+// writeCacheIssue はテスト用の課題ファイルを作成し、mtime を指定時刻に揃える。
+func writeCacheIssue(t *testing.T, path, body string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+}
+
+func TestOpen_RequiresEnable(t *testing.T) {
+	// 有効化前は Open が失敗し、DBファイルも作成されないことを確認する。
+	root := t.TempDir()
+	if _, err := Open(root); err == nil {
+		t.Fatal("expected not enabled error")
+	}
+	if Enabled(root) {
+		t.Fatal("expected cache to stay disabled")
+	}
+	if err := Enable(root); err != nil {
+		t.Fatalf("Enable error: %v", err)
+	}
+	if !Enabled(root) {
+		t.Fatal("expected cache to be enabled")
+	}
+	if err := Disable(root); err != nil {
+		t.Fatalf("Disable error: %v", err)
+	}
+	if Enabled(root) {
+		t.Fatal("expected cache to be disabled")
+	}
+}
+
+func TestSyncCategoryAndList_FiltersSortsAndPages(t *testing.T) {
+	// 同期後に優先度での絞り込み・並び替え・ページングがDB側で行われ、削除済み課題が消えることを確認する。
+	root := t.TempDir()
+	categoryPath := filepath.Join(root, "cat")
+	if err := os.MkdirAll(categoryPath, 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	summaries := map[string]issueindex.Entry{
+		"a.json": {IssueID: "a", Title: "A", Status: "Open", Priority: "Low"},
+		"b.json": {IssueID: "b", Title: "B", Status: "Open", Priority: "High"},
+		"c.json": {IssueID: "c", Title: "C", Status: "Closed", Priority: "High"},
+	}
+	for name := range summaries {
+		writeCacheIssue(t, filepath.Join(categoryPath, name), name, base)
+	}
+	loads := 0
+	load := func(path string) (issueindex.Entry, error) {
+		loads++
+		return summaries[filepath.Base(path)], nil
+	}
+	if err := Enable(root); err != nil {
+		t.Fatalf("Enable error: %v", err)
+	}
+	cache, err := Open(root)
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	defer func() { _ = cache.Close() }()
+
+	if syncErr := cache.SyncCategory(categoryPath, "cat", load); syncErr != nil {
+		t.Fatalf("SyncCategory error: %v", syncErr)
+	}
+	entries, total, err := cache.List("cat", Query{SortBy: "priority", Limit: 2})
+	if err != nil {
+		t.Fatalf("List error: %v", err)
+	}
+	if total != 3 || len(entries) != 2 || entries[0].IssueID != "b" || entries[1].IssueID != "c" {
+		t.Fatalf("unexpected page: total=%d %+v", total, entries)
+	}
+	high, total, err := cache.List("cat", Query{Priority: "High", Status: "Open", Limit: 10})
+	if err != nil {
+		t.Fatalf("List error: %v", err)
+	}
+	if total != 1 || high[0].IssueID != "b" {
+		t.Fatalf("unexpected filter result: total=%d %+v", total, high)
+	}
+
+	if removeErr := os.Remove(filepath.Join(categoryPath, "c.json")); removeErr != nil {
+		t.Fatalf("remove: %v", removeErr)
+	}
+	if syncErr := cache.SyncCategory(categoryPath, "cat", load); syncErr != nil {
+		t.Fatalf("SyncCategory error: %v", syncErr)
+	}
+	if loads != 3 {
+		t.Fatalf("expected unchanged files not to be reloaded, loads=%d", loads)
+	}
+	_, total, err = cache.List("cat", Query{Limit: 10})
+	if err != nil {
+		t.Fatalf("List error: %v", err)
+	}
+	if total != 2 {
+		t.Fatalf("expected deleted issue to be removed, total=%d", total)
+	}
+}
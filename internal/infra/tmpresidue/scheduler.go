@@ -0,0 +1,48 @@
+// scheduler.go は DD-PERSIST-004 の一時ファイル残骸の定期的な検出を担い、検出結果の通知先は扱わない。
+package tmpresidue
+
+import (
+	"sync"
+	"time"
+)
+
+// Scheduler は DD-PERSIST-004 のプロジェクトを開いている間の一時ファイル残骸の定期的な検出を表す。
+type Scheduler struct {
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// Schedule は DD-PERSIST-004 の一時ファイル残骸の定期的な検出を開始する。
+// 目的: 長時間開いたままのプロジェクトでも、中断した書き込みの残骸を手動の操作なしに片付け、残り続けるものを通知する。
+// 入力: root は走査対象のルートパス、interval は検出の間隔、report は検出結果と走査のエラーの通知先。
+// 出力: Stop で停止できる Scheduler。
+// エラー: 返却値で表現しない。走査のエラーは report に渡し、次回の検出を続ける。
+// 副作用: 監視ゴルーチンを起動し、interval ごとに ScanAndHandle と同じ規則で一時ファイルを削除する。
+// 並行性: report は監視ゴルーチンから呼ばれ、同時に複数回呼ばれることはない。
+// 不変条件: 最終更新から 10 分未満の一時ファイルは書き込み中とみなして削除も通知もしない。開いた直後の検出は呼び出し側が ScanAndHandle で行う。
+// 関連DD: DD-PERSIST-004
+func Schedule(root string, interval time.Duration, report func([]ScanResult, error)) *Scheduler {
+	s := &Scheduler{stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				results, err := scanAndHandle(root, inProgressGrace)
+				report(results, err)
+			}
+		}
+	}()
+	return s
+}
+
+// Stop は DD-PERSIST-004 の定期的な検出を停止し、実行中の検出の終了を待つ。複数回呼び出してもよい。
+func (s *Scheduler) Stop() {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+}
@@ -0,0 +1,50 @@
+// scheduler_test.go は一時ファイル残骸の定期的な検出のテストを行い、UI統合は扱わない。
+package tmpresidue
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSchedule_HandlesResidueAndSkipsInProgress(t *testing.T) {
+	// 定期的な検出で中断された書き込みの残骸を削除し、書き込み中とみなせる直後の一時ファイルは残すことを確認する。
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "issue.json.tmp.123.456")
+	freshPath := filepath.Join(dir, "issue.json.tmp.123.789")
+	for _, path := range []string{oldPath, freshPath} {
+		if err := os.WriteFile(path, []byte("tmp"), 0o600); err != nil {
+			t.Fatalf("write tmp: %v", err)
+		}
+	}
+	modTime := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(oldPath, modTime, modTime); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	reported := make(chan error, 10)
+	scheduler := Schedule(dir, 10*time.Millisecond, func(results []ScanResult, err error) {
+		if len(results) != 0 {
+			t.Errorf("unexpected results: %+v", results)
+		}
+		reported <- err
+	})
+	select {
+	case err := <-reported:
+		if err != nil {
+			t.Fatalf("scan error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected scheduled scan")
+	}
+	scheduler.Stop()
+	scheduler.Stop()
+
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Fatalf("expected residue to be deleted, err=%v", err)
+	}
+	if _, err := os.Stat(freshPath); err != nil {
+		t.Fatalf("expected in-progress temp file to remain, err=%v", err)
+	}
+}
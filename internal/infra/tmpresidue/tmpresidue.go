@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,7 +16,13 @@ const (
 	ErrCodeTmpRemaining = "E_TMP_REMAINING"
 )
 
-const staleThreshold = 24 * time.Hour
+const (
+	// defaultStaleThreshold は DD-PERSIST-004 の削除せず警告とする経過時間の既定値を表す。
+	defaultStaleThreshold = 24 * time.Hour
+	// inProgressGrace は DD-PERSIST-004 の定期的な検出で書き込み中とみなして扱わない経過時間を表す。
+	// プロジェクトを開いている間は自身のアトミック更新の一時ファイルが存在しうるため、直後のものは削除しない。
+	inProgressGrace = 10 * time.Minute
+)
 
 var (
 	now        = time.Now
@@ -23,6 +30,25 @@ var (
 	walkDir    = filepath.WalkDir
 )
 
+var staleThreshold atomic.Int64
+
+// SetStaleThreshold は DD-PERSIST-004 の削除せず警告とする経過時間を設定する。起動時に config.json の設定から呼び出す。
+// 0 以下は既定値 (24時間) とする。
+func SetStaleThreshold(threshold time.Duration) {
+	if threshold <= 0 {
+		threshold = 0
+	}
+	staleThreshold.Store(int64(threshold))
+}
+
+// StaleThreshold は DD-PERSIST-004 の削除せず警告とする経過時間を返す。
+func StaleThreshold() time.Duration {
+	if threshold := time.Duration(staleThreshold.Load()); threshold > 0 {
+		return threshold
+	}
+	return defaultStaleThreshold
+}
+
 // ScanResult は DD-PERSIST-004 の一時ファイル残骸検出結果を表す。
 type ScanResult struct {
 	ErrorCode string
@@ -31,6 +57,12 @@ type ScanResult struct {
 	Hint      string
 }
 
+// Residue は DD-PERSIST-004 の検出した一時ファイル残骸を表す。
+type Residue struct {
+	Path    string
+	ModTime time.Time
+}
+
 // ScanAndHandle は DD-PERSIST-004 に従い *.tmp.* を検出し、削除または警告を記録する。
 // 目的: 一時ファイル残骸を削除し、削除できない場合は警告結果を返す。
 // 入力: root は走査対象のルートパス。
@@ -38,11 +70,67 @@ type ScanResult struct {
 // エラー: 走査中のI/Oエラーが発生した場合に返す。
 // 副作用: 条件に応じて一時ファイルを削除する。
 // 並行性: 同時削除は想定しない。
-// 不変条件: 24時間未満は削除、24時間超過は警告として返す。
+// 不変条件: StaleThreshold (既定24時間) 未満は削除、以上は警告として返す。
 // 関連DD: DD-PERSIST-004
 func ScanAndHandle(root string) ([]ScanResult, error) {
+	return scanAndHandle(root, 0)
+}
+
+// scanAndHandle は DD-PERSIST-004 の検出と削除を行う。経過時間が grace 未満の一時ファイルは書き込み中とみなして扱わない。
+func scanAndHandle(root string, grace time.Duration) ([]ScanResult, error) {
+	residues, err := Find(root)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := StaleThreshold()
 	var results []ScanResult
+	for _, residue := range residues {
+		age := now().Sub(residue.ModTime)
+		if age < grace {
+			continue
+		}
+		if age < threshold {
+			if removeErr := removeFile(residue.Path); removeErr != nil {
+				results = append(results, ScanResult{
+					ErrorCode: ErrCodeIOWrite,
+					Message:   "一時ファイルの削除に失敗しました。",
+					Target:    residue.Path,
+					Hint:      "対象ファイルの権限や利用状況を確認してください。",
+				})
+			}
+			continue
+		}
 
+		results = append(results, ScanResult{
+			ErrorCode: ErrCodeTmpRemaining,
+			Message:   fmt.Sprintf("%s以上残っている一時ファイルがあります。", formatThreshold(threshold)),
+			Target:    residue.Path,
+			Hint:      "不要な場合は手動で削除してください。",
+		})
+	}
+	return results, nil
+}
+
+// formatThreshold は DD-PERSIST-004 の警告に含める経過時間を、時間単位で割り切れる場合は時間、それ以外は分で表す。
+func formatThreshold(threshold time.Duration) string {
+	if threshold%time.Hour == 0 {
+		return fmt.Sprintf("%d時間", int64(threshold/time.Hour))
+	}
+	return fmt.Sprintf("%d分", int64(threshold/time.Minute))
+}
+
+// Find は DD-PERSIST-004 の *.tmp.* を削除せずに列挙する。
+// 目的: 整合性検査などで、残骸の有無を変更を伴わずに確認できるようにする。
+// 入力: root は走査対象のルートパス。
+// 出力: 走査順の Residue の配列とエラー。
+// エラー: 走査中のI/Oエラーが発生した場合に返す。
+// 副作用: なし。
+// 並行性: 読み取りのみでスレッドセーフ。
+// 不変条件: 検出対象と除外ルールは ScanAndHandle と同じ。
+// 関連DD: DD-PERSIST-004
+func Find(root string) ([]Residue, error) {
+	var residues []Residue
 	err := walkDir(root, func(path string, entry os.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -61,33 +149,18 @@ func ScanAndHandle(root string) ([]ScanResult, error) {
 		if infoErr != nil {
 			return fmt.Errorf("stat temp file: %w", infoErr)
 		}
-
-		age := now().Sub(info.ModTime())
-		if age < staleThreshold {
-			if removeErr := removeFile(path); removeErr != nil {
-				results = append(results, ScanResult{
-					ErrorCode: ErrCodeIOWrite,
-					Message:   "一時ファイルの削除に失敗しました。",
-					Target:    path,
-					Hint:      "対象ファイルの権限や利用状況を確認してください。",
-				})
-			}
-			return nil
-		}
-
-		results = append(results, ScanResult{
-			ErrorCode: ErrCodeTmpRemaining,
-			Message:   "24時間以上残っている一時ファイルがあります。",
-			Target:    path,
-			Hint:      "不要な場合は手動で削除してください。",
-		})
+		residues = append(residues, Residue{Path: path, ModTime: info.ModTime()})
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
+	return residues, nil
+}
 
-	return results, nil
+// IsArtifact は DD-PERSIST-004 の一時ファイル名の判定を行う。
+func IsArtifact(name string) bool {
+	return isTmpArtifact(name)
 }
 
 // isTmpArtifact は DD-PERSIST-004 の *.tmp.* 判定を行う。
@@ -132,3 +132,62 @@ func TestShouldSkipDir_Rules(t *testing.T) {
 		t.Fatal("expected .git to be excluded")
 	}
 }
+
+func TestFind_ListsWithoutDeleting(t *testing.T) {
+	// 経過時間に関わらず残骸を列挙し、削除しないことを確認する。
+	dir := t.TempDir()
+	tmpPath := filepath.Join(dir, "issue.json.tmp.123.456")
+	if err := os.WriteFile(tmpPath, []byte("tmp"), 0o600); err != nil {
+		t.Fatalf("write tmp: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "issue.json"), []byte("{}"), 0o600); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+
+	residues, err := Find(dir)
+	if err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	if len(residues) != 1 || residues[0].Path != tmpPath {
+		t.Fatalf("unexpected residues: %+v", residues)
+	}
+	if _, statErr := os.Stat(tmpPath); statErr != nil {
+		t.Fatalf("expected temp file to remain, err=%v", statErr)
+	}
+}
+
+func TestScanAndHandle_FollowsConfiguredThreshold(t *testing.T) {
+	// 設定した経過時間以上の一時ファイルは削除せず、警告に経過時間を含めることを確認する。
+	dir := t.TempDir()
+	tmpPath := filepath.Join(dir, "issue.json.tmp.123.111")
+	if err := os.WriteFile(tmpPath, []byte("tmp"), 0o600); err != nil {
+		t.Fatalf("write tmp: %v", err)
+	}
+
+	fixedNow := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	previousNow := now
+	now = func() time.Time { return fixedNow }
+	SetStaleThreshold(2 * time.Hour)
+	t.Cleanup(func() {
+		now = previousNow
+		SetStaleThreshold(0)
+	})
+
+	if err := os.Chtimes(tmpPath, fixedNow.Add(-3*time.Hour), fixedNow.Add(-3*time.Hour)); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	results, err := ScanAndHandle(dir)
+	if err != nil {
+		t.Fatalf("ScanAndHandle error: %v", err)
+	}
+	if len(results) != 1 || results[0].ErrorCode != ErrCodeTmpRemaining {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if results[0].Message != "2時間以上残っている一時ファイルがあります。" {
+		t.Fatalf("unexpected message: %s", results[0].Message)
+	}
+	if StaleThreshold() != 2*time.Hour {
+		t.Fatalf("unexpected threshold: %s", StaleThreshold())
+	}
+}
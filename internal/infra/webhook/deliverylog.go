@@ -0,0 +1,92 @@
+// deliverylog.go は DD-HOOK-002 の通知の配信の結果を1行1JSONで追記する記録を担い、記録の閲覧やローテーションは扱わない。
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DeliveryLogFileName は DD-HOOK-002 の配信の記録のファイル名を表す。
+const DeliveryLogFileName = "webhooks.jsonl"
+
+// now は DD-HOOK-002 の記録時刻をテストで固定するための差し替え点。
+var now = time.Now
+
+// Delivery は DD-HOOK-002 の通知先1件への配信の結果を表す。
+// Target は認証情報を含み得るパスを除いた通知先のスキームとホスト、StatusCode は最後の応答 (接続の失敗は 0) を表す。
+type Delivery struct {
+	Timestamp  string `json:"timestamp"`
+	DeliveryID string `json:"delivery_id"`
+	Event      string `json:"event"`
+	Target     string `json:"target"`
+	Category   string `json:"category"`
+	IssueID    string `json:"issue_id"`
+	Attempts   int    `json:"attempts"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Delivered  bool   `json:"delivered"`
+	Error      string `json:"error,omitempty"`
+}
+
+// DeliveryLog は DD-HOOK-002 の配信の記録のファイルを表す。
+type DeliveryLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// OpenDeliveryLog は DD-HOOK-002 の path へ追記する配信の記録を返す。ファイルは最初の追記で作成する。
+func OpenDeliveryLog(path string) *DeliveryLog {
+	return &DeliveryLog{path: path}
+}
+
+// AppDeliveryLogPath は DD-HOOK-002 の実行ファイルと同じディレクトリの logs/webhooks.jsonl のパスを返す。
+// 通知は操作した端末から送るため、共有フォルダではなく端末ごとに記録する。
+func AppDeliveryLogPath(exePath string) string {
+	return filepath.Join(filepath.Dir(exePath), "logs", DeliveryLogFileName)
+}
+
+// Append は DD-HOOK-002 の配信の結果を追記する。
+// 目的: 通知が届かない場合に、いつどの通知先へ何回送り、どの応答だったかを後から確認できるようにする。
+// 入力: delivery は配信の結果。Timestamp が空の場合は現在時刻を補う。
+// 出力: 成功時は nil、失敗時はエラー。l が nil の場合は何もせず nil を返す。
+// エラー: 記録先のディレクトリの作成、ファイルのオープン・書き込みに失敗した場合に返す。
+// 副作用: 記録先のファイルへ1行を追記する。
+// 並行性: 同じ DeliveryLog への追記は mutex で排他する。他のインスタンスとの追記は O_APPEND による1回の書き込みに委ねる。
+// 不変条件: 既存の行を書き換えず、末尾にのみ追記する。
+// 関連DD: DD-HOOK-002
+func (l *DeliveryLog) Append(delivery Delivery) error {
+	if l == nil {
+		return nil
+	}
+	if delivery.Timestamp == "" {
+		delivery.Timestamp = now().Format(time.RFC3339)
+	}
+	line, err := json.Marshal(delivery)
+	if err != nil {
+		return fmt.Errorf("marshal webhook delivery: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o750); err != nil {
+		return fmt.Errorf("create webhook log dir: %w", err)
+	}
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open webhook log: %w", err)
+	}
+	if _, err := file.Write(line); err != nil {
+		if closeErr := file.Close(); closeErr != nil {
+			return fmt.Errorf("write webhook log failed: %w; close error: %s", err, closeErr.Error())
+		}
+		return fmt.Errorf("write webhook log: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close webhook log: %w", err)
+	}
+	return nil
+}
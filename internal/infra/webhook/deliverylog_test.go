@@ -0,0 +1,56 @@
+// deliverylog_test.go は配信の記録の追記のテストを行う。
+package webhook
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readDeliveries はテスト用に配信の記録を1行ずつ読み込む。
+func readDeliveries(t *testing.T, path string) []Delivery {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open log: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+	records := []Delivery{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record Delivery
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("unmarshal record: %v", err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestDeliveryLog_AppendsRecords(t *testing.T) {
+	// 記録先のディレクトリを作成して1件1行で追記し、時刻を補うこと、nil の記録は何もしないことを確認する。
+	previous := now
+	now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+	t.Cleanup(func() { now = previous })
+	path := filepath.Join(t.TempDir(), "logs", DeliveryLogFileName)
+	log := OpenDeliveryLog(path)
+	for _, id := range []string{"a", "b"} {
+		if err := log.Append(Delivery{DeliveryID: id, Delivered: true}); err != nil {
+			t.Fatalf("Append error: %v", err)
+		}
+	}
+	records := readDeliveries(t, path)
+	if len(records) != 2 || records[1].DeliveryID != "b" || records[0].Timestamp != "2024-01-02T03:04:05Z" {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+	var missing *DeliveryLog
+	if err := missing.Append(Delivery{}); err != nil {
+		t.Fatalf("expected nil log to ignore records: %v", err)
+	}
+	if got := AppDeliveryLogPath(filepath.Join("opt", "ratta", "ratta.exe")); got != filepath.Join("opt", "ratta", "logs", DeliveryLogFileName) {
+		t.Fatalf("unexpected log path: %s", got)
+	}
+}
@@ -0,0 +1,200 @@
+// Package webhook は DD-HOOK-001 の課題の変更を外部のサービス (チャットツールや CI) へ JSON で POST する通知を担い、
+// 通知する変更の判断や本文に含める課題の表現は扱わず、呼び出し側が DD-PROJCONF-001 の設定に従って呼び出す。
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"ratta/internal/infra/projectmeta"
+)
+
+const (
+	// DefaultMaxAttempts は DD-HOOK-001 の通知1件あたりの既定の最大試行回数を表す。
+	DefaultMaxAttempts = 3
+	// requestTimeout は DD-HOOK-001 の1回の送信で応答を待つ上限を表す。
+	requestTimeout = 10 * time.Second
+	// maxResponseBytes は DD-HOOK-001 の接続を再利用するために読み捨てる応答本文の上限を表す。
+	maxResponseBytes = 64 << 10
+
+	// SignatureHeader は DD-HOOK-001 の本文の HMAC-SHA256 の署名を `sha256=<hex>` で送るヘッダーを表す。
+	SignatureHeader = "X-Ratta-Signature-256"
+	// EventHeader と DeliveryHeader は DD-HOOK-001 の変更の種類と配信IDを送るヘッダーを表す。
+	EventHeader    = "X-Ratta-Event"
+	DeliveryHeader = "X-Ratta-Delivery"
+	userAgent      = "ratta-webhook"
+)
+
+// Payload は DD-HOOK-001 の通知の本文を表す。Issue は変更後の課題を UI と同じ形式で表す。
+// DeliveryID は送信時に通知先ごとに採番する。
+type Payload struct {
+	Event      string `json:"event"`
+	DeliveryID string `json:"delivery_id"`
+	OccurredAt string `json:"occurred_at"`
+	Project    string `json:"project"`
+	Category   string `json:"category"`
+	IssueID    string `json:"issue_id"`
+	Actor      string `json:"actor,omitempty"`
+	Mode       string `json:"mode"`
+	Issue      any    `json:"issue"`
+}
+
+// Sender は DD-HOOK-001 の再試行と配信の記録を伴う通知の送信を表す。
+type Sender struct {
+	client      *http.Client
+	maxAttempts int
+	backoff     func(attempt int) time.Duration
+	log         *DeliveryLog
+}
+
+// NewSender は DD-HOOK-001 の配信の結果を log へ記録する送信を返す。log が nil の場合は記録しない。
+func NewSender(log *DeliveryLog) *Sender {
+	return &Sender{
+		client:      &http.Client{Timeout: requestTimeout},
+		maxAttempts: DefaultMaxAttempts,
+		backoff:     defaultBackoff,
+		log:         log,
+	}
+}
+
+// defaultBackoff は DD-HOOK-001 の attempt 回目の失敗の後に待つ時間を 1 秒から倍々に返す。
+func defaultBackoff(attempt int) time.Duration {
+	return time.Duration(1<<(attempt-1)) * time.Second
+}
+
+// Send は DD-HOOK-001 の変更を通知先へ送る。
+// 目的: 課題の作成・更新・コメントの追加をチャットツールや CI へ知らせる。
+// 入力: ctx は中断用、hooks は DD-PROJCONF-001 の通知先、payload は DeliveryID を除く通知の本文。
+// 出力: payload.Event を通知する通知先ごとの配信の結果。通知先が無い場合は空。
+// エラー: 返さない。送信の失敗は結果の Error に残す。
+// 副作用: HTTP POST を行い、配信の結果を配信の記録へ追記する。
+// 並行性: 通知先へ順に送る。同じ Sender を複数のゴルーチンから呼び出してよい。
+// 不変条件: 接続の失敗と 5xx・429 の応答のみ maxAttempts 回まで再試行し、それ以外の応答は再試行しない。
+// 記録とエラーには URL のパスや Secret を含めない。
+// 関連DD: DD-HOOK-001, DD-HOOK-002
+func (s *Sender) Send(ctx context.Context, hooks []projectmeta.Webhook, payload Payload) []Delivery {
+	deliveries := []Delivery{}
+	for _, hook := range hooks {
+		if !hook.Accepts(payload.Event) {
+			continue
+		}
+		payload.DeliveryID = newDeliveryID()
+		delivery := s.deliver(ctx, hook, payload)
+		// 記録は調査用のため、書き込めない場合も通知の結果は変えない。
+		_ = s.log.Append(delivery)
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries
+}
+
+// deliver は DD-HOOK-001 の通知先1件へ再試行を伴って送り、配信の結果を返す。
+func (s *Sender) deliver(ctx context.Context, hook projectmeta.Webhook, payload Payload) Delivery {
+	delivery := Delivery{
+		DeliveryID: payload.DeliveryID,
+		Event:      payload.Event,
+		Target:     target(hook.URL),
+		Category:   payload.Category,
+		IssueID:    payload.IssueID,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		delivery.Error = fmt.Sprintf("marshal payload: %v", err)
+		return delivery
+	}
+	for attempt := 1; ; attempt++ {
+		delivery.Attempts = attempt
+		status, postErr := s.post(ctx, hook, payload, body)
+		delivery.StatusCode = status
+		if postErr == nil {
+			delivery.Delivered = true
+			delivery.Error = ""
+			return delivery
+		}
+		delivery.Error = postErr.Error()
+		if attempt >= s.maxAttempts || !retryable(status) {
+			return delivery
+		}
+		timer := time.NewTimer(s.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			delivery.Error = ctx.Err().Error()
+			return delivery
+		case <-timer.C:
+		}
+	}
+}
+
+// post は DD-HOOK-001 の1回の送信を行い、応答のステータスコードを返す。2xx 以外の応答はエラーとする。
+// 接続の失敗では URL を含めないよう、net/url のエラーから元のエラーを取り出して返す。
+func (s *Sender) post(ctx context.Context, hook projectmeta.Webhook, payload Payload, body []byte) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, errors.New("invalid webhook request")
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", userAgent)
+	request.Header.Set(EventHeader, payload.Event)
+	request.Header.Set(DeliveryHeader, payload.DeliveryID)
+	if hook.Secret != "" {
+		request.Header.Set(SignatureHeader, Sign(hook.Secret, body))
+	}
+	response, err := s.client.Do(request)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return 0, fmt.Errorf("post webhook: %w", err)
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, maxResponseBytes))
+	if closeErr := response.Body.Close(); closeErr != nil {
+		return response.StatusCode, fmt.Errorf("close webhook response: %w", closeErr)
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("unexpected webhook status: %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+// retryable は DD-HOOK-001 の応答のステータスコード status (接続の失敗は 0) が再試行の対象かを返す。
+func retryable(status int) bool {
+	return status == 0 || status == http.StatusTooManyRequests || status >= 500
+}
+
+// Sign は DD-HOOK-001 の本文 body の secret による HMAC-SHA256 の署名を `sha256=<hex>` で返す。
+// 受信側は同じ計算結果と SignatureHeader の値を比べて送信元と本文を確かめる。
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// target は DD-HOOK-002 の記録に残す通知先を、パスやクエリに含まれる認証情報を除いたスキームとホストで返す。
+func target(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+// newDeliveryID は DD-HOOK-001 の配信IDを乱数から採番する。乱数を得られない場合は時刻から作る。
+func newDeliveryID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,99 @@
+// webhook_test.go は通知の送信・署名・変更の種類による絞り込みと、再試行の規則のテストを行う。
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ratta/internal/infra/projectmeta"
+)
+
+// newTestSender はテスト用に再試行の間隔を待たない送信を返す。
+func newTestSender(log *DeliveryLog) *Sender {
+	sender := NewSender(log)
+	sender.backoff = func(int) time.Duration { return 0 }
+	return sender
+}
+
+func TestSend_PostsSignedPayloadToAcceptingHooks(t *testing.T) {
+	// 変更の種類を通知する通知先のみへ、署名と種類・配信IDのヘッダーを付けた JSON を送ることを確認する。
+	var received Payload
+	var signature, event, delivery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Errorf("unmarshal payload: %v", err)
+		}
+		signature, event, delivery = r.Header.Get(SignatureHeader), r.Header.Get(EventHeader), r.Header.Get(DeliveryHeader)
+		if want := Sign("secret", body); signature != want {
+			t.Errorf("unexpected signature: %q want %q", signature, want)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	logPath := filepath.Join(t.TempDir(), DeliveryLogFileName)
+
+	hooks := []projectmeta.Webhook{
+		{URL: server.URL + "/hook", Secret: "secret"},
+		{URL: server.URL + "/other", Events: []string{projectmeta.WebhookEventIssueUpdated}},
+	}
+	deliveries := newTestSender(OpenDeliveryLog(logPath)).Send(context.Background(), hooks, Payload{
+		Event:    projectmeta.WebhookEventIssueCommented,
+		Category: "cat",
+		IssueID:  "abc",
+		Issue:    map[string]string{"title": "t"},
+	})
+	if len(deliveries) != 1 || !deliveries[0].Delivered || deliveries[0].Attempts != 1 || deliveries[0].StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected deliveries: %+v", deliveries)
+	}
+	if received.Event != projectmeta.WebhookEventIssueCommented || received.IssueID != "abc" || received.DeliveryID == "" || delivery != received.DeliveryID || event != received.Event {
+		t.Fatalf("unexpected payload: %+v %q %q", received, event, delivery)
+	}
+	if deliveries[0].Target != server.URL {
+		t.Fatalf("expected target without path, got %q", deliveries[0].Target)
+	}
+	if records := readDeliveries(t, logPath); len(records) != 1 || records[0].DeliveryID != received.DeliveryID {
+		t.Fatalf("unexpected log: %+v", records)
+	}
+}
+
+func TestSend_RetriesOnlyTransientFailures(t *testing.T) {
+	// 5xx の応答は再試行して届け、4xx の応答は再試行せず、接続の失敗は最大試行回数まで再試行して URL を残さないことを確認する。
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/flaky" && calls.Add(1) == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/bad":
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	defer server.Close()
+
+	sender := newTestSender(nil)
+	payload := Payload{Event: projectmeta.WebhookEventIssueCreated}
+	flaky := sender.Send(context.Background(), []projectmeta.Webhook{{URL: server.URL + "/flaky"}}, payload)
+	if !flaky[0].Delivered || flaky[0].Attempts != 2 || flaky[0].Error != "" {
+		t.Fatalf("expected delivery on retry: %+v", flaky)
+	}
+	bad := sender.Send(context.Background(), []projectmeta.Webhook{{URL: server.URL + "/bad"}}, payload)
+	if bad[0].Delivered || bad[0].Attempts != 1 || bad[0].StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected no retry for client errors: %+v", bad)
+	}
+	unreachable := sender.Send(context.Background(), []projectmeta.Webhook{{URL: closed.URL + "/token"}}, payload)
+	if unreachable[0].Delivered || unreachable[0].Attempts != DefaultMaxAttempts || strings.Contains(unreachable[0].Error, "/token") {
+		t.Fatalf("unexpected result for unreachable hook: %+v", unreachable)
+	}
+}
@@ -0,0 +1,83 @@
+// Package workerpool は件数の決まった独立した処理を上限付きの並列度で実行し、結果の集約や順序付けは扱わない。
+// 共有フォルダ上の大量の課題JSONの読み込み・検証を並列化するために用いる。
+package workerpool
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+const (
+	// MaxSize は DD-SCAN-001 の並列度の上限を表す。
+	// ネットワーク共有上では同時オープン数が増えすぎると逆に遅くなるため上限を設ける。
+	MaxSize = 64
+	// defaultMaxSize は DD-SCAN-001 の既定の並列度の上限を表す。
+	defaultMaxSize = 8
+)
+
+// Size は DD-SCAN-001 の並列度を正規化する。
+// 0 以下は CPU 数 (最大 8) を既定値とし、MaxSize を超える値は MaxSize に丸める。
+func Size(requested int) int {
+	if requested <= 0 {
+		return min(runtime.NumCPU(), defaultMaxSize)
+	}
+	return min(requested, MaxSize)
+}
+
+// Run は DD-SCAN-001 の上限付き並列実行を行う。
+// 目的: 0 から count-1 までの各インデックスに対して task を並列に実行する。
+// 入力: size は並列度 (Size で正規化する)、count は処理件数、task は処理本体。
+// 出力: なし。全ての task の完了後に戻る。
+// エラー: 返却しない。task は自身の結果をインデックスに対応する領域へ書き込む。
+// 副作用: task の副作用に従う。
+// 並行性: task は複数ゴルーチンから同時に呼ばれるため、異なるインデックス間で状態を共有しない。
+// 不変条件: 同時に実行される task は size 件を超えない。
+// 関連DD: DD-SCAN-001
+func Run(size, count int, task func(i int)) {
+	_ = RunContext(context.Background(), size, count, task)
+}
+
+// RunContext は DD-SCAN-001/DD-CANCEL-001 の中断可能な上限付き並列実行を行う。
+// ctx が中断された場合は未着手の task を実行せず、実行中の task の完了を待って ctx.Err() を返す。
+// 中断時は一部のインデックスの結果が未設定のままとなるため、呼び出し側は結果を破棄する。
+func RunContext(ctx context.Context, size, count int, task func(i int)) error {
+	if count <= 0 {
+		return ctx.Err()
+	}
+	workers := min(Size(size), count)
+	if workers == 1 {
+		for i := 0; i < count; i++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			task(i)
+		}
+		return nil
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				task(i)
+			}
+		}()
+	}
+	var err error
+dispatch:
+	for i := 0; i < count; i++ {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break dispatch
+		case indexes <- i:
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	return err
+}
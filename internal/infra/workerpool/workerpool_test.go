@@ -0,0 +1,72 @@
+// workerpool_test.go は上限付き並列実行のテストを行う。
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRun_VisitsEveryIndexWithinLimit(t *testing.T) {
+	// 全インデックスが1回ずつ処理され、同時実行数が指定した並列度を超えないことを確認する。
+	const count = 50
+	visited := make([]int, count)
+	var running, peak int32
+	var mu sync.Mutex
+	Run(4, count, func(i int) {
+		current := atomic.AddInt32(&running, 1)
+		mu.Lock()
+		if current > peak {
+			peak = current
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		visited[i]++
+		atomic.AddInt32(&running, -1)
+	})
+	for i, n := range visited {
+		if n != 1 {
+			t.Fatalf("index %d visited %d times", i, n)
+		}
+	}
+	if peak > 4 {
+		t.Fatalf("expected at most 4 concurrent tasks, got %d", peak)
+	}
+}
+
+func TestSize_Normalizes(t *testing.T) {
+	// 0 以下は既定値、上限超過は MaxSize に丸められることを確認する。
+	if got := Size(0); got < 1 || got > defaultMaxSize {
+		t.Fatalf("unexpected default size: %d", got)
+	}
+	if got := Size(MaxSize + 10); got != MaxSize {
+		t.Fatalf("expected %d, got %d", MaxSize, got)
+	}
+	if got := Size(3); got != 3 {
+		t.Fatalf("expected 3, got %d", got)
+	}
+}
+
+func TestRunContext_StopsDispatchingAfterCancel(t *testing.T) {
+	// 中断後は未着手の処理を行わず、実行中の処理の完了を待って中断理由を返すことを確認する。
+	for _, size := range []int{1, 4} {
+		ctx, cancel := context.WithCancel(context.Background())
+		var started int32
+		err := RunContext(ctx, size, 100, func(i int) {
+			if atomic.AddInt32(&started, 1) == 2 {
+				cancel()
+			}
+			time.Sleep(time.Millisecond)
+		})
+		cancel()
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("size %d: expected canceled, got %v", size, err)
+		}
+		if n := atomic.LoadInt32(&started); n >= 100 {
+			t.Fatalf("size %d: expected dispatch to stop, started %d", size, n)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// catalog.go は DD-BE-004 のエラーコードごとの利用者向けメッセージと復旧の指針 (日本語・英語) を担い、エラーコードの判定は扱わない。
+package present
+
+import (
+	"sync/atomic"
+
+	"ratta/internal/domain/apperr"
+)
+
+const (
+	// LanguageJa・LanguageEn は DD-CONF-005 の表示言語を表す。
+	LanguageJa = "ja"
+	LanguageEn = "en"
+)
+
+// currentLanguage は DD-CONF-005 のメッセージに用いる表示言語を表す。未設定の場合は日本語とする。
+var currentLanguage atomic.Value
+
+// catalog は DD-BE-004 のエラーコードごとの表示言語別のメッセージを表す。
+var catalog = map[string]map[string]string{
+	ErrorValidation: {
+		LanguageJa: "入力内容に誤りがあります。",
+		LanguageEn: "The input is invalid.",
+	},
+	ErrorPermission: {
+		LanguageJa: "この操作を行う権限がありません。",
+		LanguageEn: "You do not have permission to perform this operation.",
+	},
+	ErrorNotFound: {
+		LanguageJa: "対象が見つかりません。",
+		LanguageEn: "The target was not found.",
+	},
+	ErrorConflict: {
+		LanguageJa: "現在の状態では実行できません。",
+		LanguageEn: "The operation conflicts with the current state.",
+	},
+	ErrorCrypto: {
+		LanguageJa: "パスワードの照合に失敗しました。",
+		LanguageEn: "Password verification failed.",
+	},
+	ErrorInternal: {
+		LanguageJa: "処理中にエラーが発生しました。",
+		LanguageEn: "An unexpected error occurred.",
+	},
+	ErrorCanceled: {
+		LanguageJa: "処理を中断しました。",
+		LanguageEn: "The operation was canceled.",
+	},
+	ErrorSchemaInvalid: {
+		LanguageJa: "ファイルの形式が不正です。",
+		LanguageEn: "The file does not match its schema.",
+	},
+	ErrorMimeMismatch: {
+		LanguageJa: "添付ファイルの種類が拡張子と一致しません。",
+		LanguageEn: "The attachment type does not match its file extension.",
+	},
+}
+
+// hints は DD-BE-004 の復旧の指針の種類ごとの表示言語別の文言を表す。
+var hints = map[apperr.Hint]map[string]string{
+	apperr.HintCheckPermission: {
+		LanguageJa: "ファイルやフォルダのアクセス権限を確認してください。",
+		LanguageEn: "Check the access permissions of the file or folder.",
+	},
+	apperr.HintCheckExists: {
+		LanguageJa: "ファイルが移動・削除されていないか確認し、一覧を再読み込みしてください。",
+		LanguageEn: "Check that the file has not been moved or deleted, then reload the list.",
+	},
+	apperr.HintCheckDiskSpace: {
+		LanguageJa: "ディスクの空き容量を確認してください。",
+		LanguageEn: "Check the free disk space.",
+	},
+	apperr.HintRetryWrite: {
+		LanguageJa: "他のプログラムがファイルを開いていないか確認し、再度実行してください。",
+		LanguageEn: "Check that no other program has the file open, then try again.",
+	},
+	apperr.HintCheckJSON: {
+		LanguageJa: "ファイルの内容が JSON として正しいか（git のマージ結果など）を確認してください。",
+		LanguageEn: "Check that the file is valid JSON (for example, after a git merge).",
+	},
+	apperr.HintMergeChanges: {
+		LanguageJa: "他の利用者が課題を更新しています。最新の内容と比べて変更を統合してから保存してください。",
+		LanguageEn: "Another user has updated the issue. Compare it with the latest content and merge your changes before saving.",
+	},
+}
+
+// SetLanguage は DD-CONF-005 のメッセージに用いる表示言語を切り替える。対応しない言語は日本語とする。
+func SetLanguage(language string) {
+	if language != LanguageEn {
+		language = LanguageJa
+	}
+	currentLanguage.Store(language)
+}
+
+// Language は DD-CONF-005 の現在の表示言語を返す。
+func Language() string {
+	if language, ok := currentLanguage.Load().(string); ok {
+		return language
+	}
+	return LanguageJa
+}
+
+// HintMessage は DD-BE-004 の復旧の指針の種類に対応する現在の表示言語の文言を返す。未知の種類は空文字を返す。
+func HintMessage(hint apperr.Hint) string {
+	return hints[hint][Language()]
+}
+
+// Message は DD-BE-004 のエラーコードに対応する現在の表示言語のメッセージを返す。
+// 未知のエラーコードは E_INTERNAL のメッセージを返す。
+func Message(code string) string {
+	messages, ok := catalog[code]
+	if !ok {
+		messages = catalog[ErrorInternal]
+	}
+	return messages[Language()]
+}
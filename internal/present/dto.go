@@ -2,28 +2,97 @@
 package present
 
 // Response は DD-BE-003 の標準レスポンス形式を表す。
+// Warnings は成功した操作が報告する、失敗には当たらない問題を表す。
 type Response struct {
-	Ok    bool         `json:"ok"`
-	Data  any          `json:"data,omitempty"`
-	Error *APIErrorDTO `json:"error,omitempty"`
+	Ok       bool          `json:"ok"`
+	Data     any           `json:"data,omitempty"`
+	Error    *APIErrorDTO  `json:"error,omitempty"`
+	Warnings []APIErrorDTO `json:"warnings,omitempty"`
 }
 
 // APIErrorDTO は DD-BE-003 の共通エラーを表す。
+// conflict は DD-PERSIST-006 の外部で変更された課題の保存を拒んだ場合のみ設定する。
 type APIErrorDTO struct {
-	ErrorCode  string `json:"error_code"`
-	Message    string `json:"message"`
-	Detail     string `json:"detail,omitempty"`
-	TargetPath string `json:"target_path,omitempty"`
-	Hint       string `json:"hint,omitempty"`
+	ErrorCode  string            `json:"error_code"`
+	Message    string            `json:"message"`
+	Detail     string            `json:"detail,omitempty"`
+	TargetPath string            `json:"target_path,omitempty"`
+	Hint       string            `json:"hint,omitempty"`
+	Conflict   *IssueConflictDTO `json:"conflict,omitempty"`
+}
+
+// IssueConflictDTO は DD-PERSIST-006 の外部での変更による保存の競合を表す。
+// current は現在のファイルの内容、attempted は利用者の変更を現在の内容へ適用した課題を表す。
+type IssueConflictDTO struct {
+	Current   IssueDetailDTO `json:"current"`
+	Attempted IssueDetailDTO `json:"attempted"`
 }
 
 // BootstrapDTO は DD-BE-003 の起動時情報を表す。
+// recent_project_roots は最近開いたプロジェクトルートを新しい順に表し、
+// warnings は起動時に開いたプロジェクトで検出した DD-PERSIST-004 の一時ファイル残骸の警告と --root で開けなかった理由を表し、
+// locked_by は ProjectOpenDTO と同じく DD-LOCK-002 の読み取り専用で開いた場合のロックの保持者を表す。
+// mode は起動時の操作モード (--observer 指定時は Observer) を表す。
+// has_contractor_auth_file は contractor.json または users.json があることを、
+// contractor_username_required は DD-CLI-007 の users.json のアカウント名での認証を要することを、
+// contractor_totp_required は DD-CLI-008 のワンタイムコードの入力を要することを、
+// contractor_remember_supported は DD-MODE-003 のこの端末で Contractor 認証を記憶できることを表す。
+// startup_project_root は DD-BE-002 の起動引数 --root で開いたプロジェクトルートを表し、指定がない場合は null とする。
+// settings は DD-CONF-005 の既定値を補ったアプリ設定を表す。
 type BootstrapDTO struct {
-	HasConfig             bool    `json:"has_config"`
-	LastProjectRootPath   *string `json:"last_project_root_path"`
-	UIPageSize            int     `json:"ui_page_size"`
-	LogLevel              string  `json:"log_level"`
-	HasContractorAuthFile bool    `json:"has_contractor_auth_file"`
+	HasConfig                   bool            `json:"has_config"`
+	LastProjectRootPath         *string         `json:"last_project_root_path"`
+	StartupProjectRoot          *string         `json:"startup_project_root"`
+	UIPageSize                  int             `json:"ui_page_size"`
+	LogLevel                    string          `json:"log_level"`
+	Mode                        string          `json:"mode"`
+	HasContractorAuthFile       bool            `json:"has_contractor_auth_file"`
+	ContractorUsernameRequired  bool            `json:"contractor_username_required"`
+	ContractorTOTPRequired      bool            `json:"contractor_totp_required"`
+	ContractorRememberSupported bool            `json:"contractor_remember_supported"`
+	RecentProjectRoots          []string        `json:"recent_project_roots"`
+	Warnings                    []APIErrorDTO   `json:"warnings"`
+	LockedBy                    *ProjectLockDTO `json:"locked_by"`
+	Settings                    SettingsDTO     `json:"settings"`
+}
+
+// SettingsDTO は DD-CONF-005 のアプリ設定を表し、起動時情報と SaveSettings の入力で共用する。
+// confirm_delete_category は空のカテゴリの削除前に、confirm_delete_category_with_issues は課題を含むカテゴリのゴミ箱への退避前に確認することを表す。
+type SettingsDTO struct {
+	DefaultSortBy                   string `json:"default_sort_by"`
+	DefaultSortOrder                string `json:"default_sort_order"`
+	DefaultAuthorName               string `json:"default_author_name"`
+	DateFormat                      string `json:"date_format"`
+	Language                        string `json:"language"`
+	TimeZone                        string `json:"time_zone"`
+	ConfirmDeleteCategory           bool   `json:"confirm_delete_category"`
+	ConfirmDeleteCategoryWithIssues bool   `json:"confirm_delete_category_with_issues"`
+}
+
+// ConfigChangedDTO は DD-CONF-007 の外部で書き換えられた config.json から再読込した設定を表す。
+// ui_page_size は開いているプロジェクトの設定を反映した課題一覧の表示件数を表す。
+type ConfigChangedDTO struct {
+	LogLevel   string      `json:"log_level"`
+	UIPageSize int         `json:"ui_page_size"`
+	Settings   SettingsDTO `json:"settings"`
+}
+
+// ProjectOpenDTO は DD-PERSIST-004 のプロジェクトを開いた結果を表す。warnings は一時ファイル残骸の警告を表す。
+// locked_by は DD-LOCK-002 の他のインスタンスが書き込み用に開いているため読み取り専用で開いた場合の保持者を表し、書き込み可能な場合は null とする。
+// ui_page_size は DD-PROJCONF-001 のプロジェクト単位の設定を反映した課題一覧の表示件数を表し、TakeOverProjectLock など開き直さない場合は省く。
+type ProjectOpenDTO struct {
+	Root       string          `json:"root"`
+	Warnings   []APIErrorDTO   `json:"warnings"`
+	LockedBy   *ProjectLockDTO `json:"locked_by"`
+	UIPageSize int             `json:"ui_page_size,omitempty"`
+}
+
+// ProjectLockDTO は DD-LOCK-002 の書き込み用ロックの保持者を表す。stale は更新が途絶え、引き継げることを表す。
+type ProjectLockDTO struct {
+	Hostname  string `json:"hostname"`
+	PID       int    `json:"pid"`
+	UpdatedAt string `json:"updated_at"`
+	Stale     bool   `json:"stale"`
 }
 
 // ValidationResultDTO は DD-BE-003 の検証結果を表す。
@@ -35,26 +104,230 @@ type ValidationResultDTO struct {
 }
 
 // ModeDTO は DD-BE-003 のモード情報を表す。
+// requires_username は DD-CLI-007 の users.json のアカウント名での認証を要することを、
+// requires_totp は DD-CLI-008 のワンタイムコードの入力を要することを表し、
+// username はパスワード検証で照合したアカウント名を表す (共有パスワードの場合は空)。
 type ModeDTO struct {
 	Mode             string `json:"mode"`
 	RequiresPassword bool   `json:"requires_password"`
+	RequiresUsername bool   `json:"requires_username"`
+	RequiresTOTP     bool   `json:"requires_totp"`
+	Username         string `json:"username"`
+}
+
+// ModeLockedDTO は DD-MODE-001 の Contractor モードの解除通知を表す。
+// reason は解除の理由 ("idle" は無操作、"manual" は LockMode) を、mode は解除後のモード情報を表す。
+type ModeLockedDTO struct {
+	Reason string  `json:"reason"`
+	Mode   ModeDTO `json:"mode"`
 }
 
 // CategoryDTO は DD-BE-003 のカテゴリ情報を表す。
 type CategoryDTO struct {
-	Name       string `json:"name"`
-	IsReadOnly bool   `json:"is_read_only"`
+	Name                string `json:"name"`
+	IsReadOnly          bool   `json:"is_read_only"`
+	IsArchived          bool   `json:"is_archived"`
+	Path                string `json:"path"`
+	IssueCount          int    `json:"issue_count"`
+	Description         string `json:"description"`
+	Color               string `json:"color"`
+	SortWeight          int    `json:"sort_weight"`
+	DefaultAssignee     string `json:"default_assignee"`
+	DefaultPriority     string `json:"default_priority"`
+	DescriptionTemplate string `json:"description_template"`
+}
+
+// CategoryMetaDTO は DD-CATMETA-001 のカテゴリメタデータ更新入力を表す。
+type CategoryMetaDTO struct {
+	Description         string `json:"description"`
+	Color               string `json:"color"`
+	SortWeight          int    `json:"sort_weight"`
+	DefaultAssignee     string `json:"default_assignee"`
+	DefaultPriority     string `json:"default_priority"`
+	DescriptionTemplate string `json:"description_template"`
+}
+
+// CategoryTrashDTO は DD-TRASH-001 のカテゴリ強制削除結果を表す。
+type CategoryTrashDTO struct {
+	TrashID    string `json:"trash_id"`
+	Category   string `json:"category"`
 	Path       string `json:"path"`
 	IssueCount int    `json:"issue_count"`
 }
 
+// CategoryRenamedDTO は DD-EVENT-001 のカテゴリ名変更の通知を表す。category は変更後のカテゴリを表す。
+type CategoryRenamedDTO struct {
+	OldName  string      `json:"old_name"`
+	Category CategoryDTO `json:"category"`
+}
+
+// RenameResidueDTO は DD-BE-003 の中断されたカテゴリ名変更の残骸を表す。name は変更後の名前を表す。
+// original_name は変更前の名前を表し、分からない場合は空とする。updated_issue_count は category を name に更新済みの課題の件数を表す。
+// can_complete・can_rollback は同名のカテゴリが無く、完了・取り消しを行えることを表す。
+type RenameResidueDTO struct {
+	Name              string `json:"name"`
+	OriginalName      string `json:"original_name"`
+	IssueCount        int    `json:"issue_count"`
+	UpdatedIssueCount int    `json:"updated_issue_count"`
+	CanComplete       bool   `json:"can_complete"`
+	CanRollback       bool   `json:"can_rollback"`
+}
+
+// CategoryDeletedDTO は DD-EVENT-001 のカテゴリ削除の通知を表す。trash_id はゴミ箱へ退避した場合のみ設定する。
+type CategoryDeletedDTO struct {
+	Name    string `json:"name"`
+	TrashID string `json:"trash_id,omitempty"`
+}
+
+// CategoryOrderDTO は DD-EVENT-001 のカテゴリ表示順の変更の通知を表す。
+type CategoryOrderDTO struct {
+	Names []string `json:"names"`
+}
+
 // CategoryListDTO は DD-BE-003 のカテゴリ一覧を表す。
 type CategoryListDTO struct {
 	Categories []CategoryDTO `json:"categories"`
 	Errors     int           `json:"errors"`
 }
 
+// CategoryStatsDTO は DD-STATS-001 のカテゴリ集計結果を表す。
+type CategoryStatsDTO struct {
+	Category     string         `json:"category"`
+	Total        int            `json:"total"`
+	ByStatus     map[string]int `json:"by_status"`
+	ByPriority   map[string]int `json:"by_priority"`
+	OpenCount    int            `json:"open_count"`
+	OverdueCount int            `json:"overdue_count"`
+	Errors       int            `json:"errors"`
+}
+
+// ProjectStatsDTO は DD-STATS-001 のプロジェクト全体の集計結果を表す。
+type ProjectStatsDTO struct {
+	Categories   []CategoryStatsDTO `json:"categories"`
+	Total        int                `json:"total"`
+	ByStatus     map[string]int     `json:"by_status"`
+	ByPriority   map[string]int     `json:"by_priority"`
+	OpenCount    int                `json:"open_count"`
+	OverdueCount int                `json:"overdue_count"`
+	Errors       int                `json:"errors"`
+	OldestOpen   []OpenIssueDTO     `json:"oldest_open"`
+	Overdue      []OpenIssueDTO     `json:"overdue"`
+}
+
+// OpenIssueDTO は DD-STATS-001 の未完了課題の要約を表す。
+type OpenIssueDTO struct {
+	Category  string `json:"category"`
+	IssueID   string `json:"issue_id"`
+	Title     string `json:"title"`
+	Status    string `json:"status"`
+	Priority  string `json:"priority"`
+	DueDate   string `json:"due_date"`
+	CreatedAt string `json:"created_at"`
+}
+
+// SearchResultDTO は DD-SEARCH-001 の全文検索結果を表す。
+type SearchResultDTO struct {
+	Query string         `json:"query"`
+	Scope string         `json:"scope"`
+	Hits  []SearchHitDTO `json:"hits"`
+}
+
+// SearchHitDTO は DD-SEARCH-001 の検索結果1件を表す。
+type SearchHitDTO struct {
+	Category string           `json:"category"`
+	Score    float64          `json:"score"`
+	Issue    IssueSummaryDTO  `json:"issue"`
+	Matches  []SearchMatchDTO `json:"matches"`
+}
+
+// SearchMatchDTO は DD-SEARCH-001 の一致箇所を表す。start/end はフィールド値の UTF-8 バイト位置。
+type SearchMatchDTO struct {
+	Field string `json:"field"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// OperationDTO は DD-CANCEL-001 の実行中の処理を表す。op_id は CancelOperation に渡す識別子とする。
+// result は DD-OP-001 の非同期処理の終了通知にのみ含め、バインディングの戻り値と同じ形式で結果を表す。
+type OperationDTO struct {
+	OpID   string    `json:"op_id"`
+	Kind   string    `json:"kind"`
+	Result *Response `json:"result,omitempty"`
+}
+
+// OperationProgressDTO は DD-OP-001 の非同期処理の進捗を表す。total が 0 の場合は総量が分からないことを表す。
+type OperationProgressDTO struct {
+	OpID    string `json:"op_id"`
+	Kind    string `json:"kind"`
+	Done    int    `json:"done"`
+	Total   int    `json:"total"`
+	Message string `json:"message"`
+}
+
+// WarmupProgressDTO は DD-WARMUP-001 の事前読み込みの進捗を表す。
+// 完了時は finished のみを true とした通知を1回送る。root は読み込み中のプロジェクトルートを表す。
+type WarmupProgressDTO struct {
+	Root     string `json:"root"`
+	Done     int    `json:"done"`
+	Total    int    `json:"total"`
+	Category string `json:"category"`
+	Finished bool   `json:"finished"`
+}
+
+// ProjectChangeDTO は DD-WATCH-001 の外部変更通知1件を表す。
+// kind は category/issue/attachment、op は created/modified/deleted のいずれか。
+type ProjectChangeDTO struct {
+	Kind     string `json:"kind"`
+	Op       string `json:"op"`
+	Category string `json:"category"`
+	IssueID  string `json:"issue_id"`
+	Path     string `json:"path"`
+}
+
+// LogEntryDTO は DD-LOG-001 のログ1件を表す。fields は timestamp/level/message 以外の付加情報を表す。
+type LogEntryDTO struct {
+	Timestamp string         `json:"timestamp"`
+	Level     string         `json:"level"`
+	Message   string         `json:"message"`
+	Fields    map[string]any `json:"fields"`
+}
+
+// LogListDTO は DD-LOG-001 のログの取得結果を古い順に表す。truncated は条件に合う記録が limit を超えたことを表す。
+type LogListDTO struct {
+	Entries   []LogEntryDTO `json:"entries"`
+	Truncated bool          `json:"truncated"`
+}
+
+// ChangesDTO は DD-CHANGES-001 の変更差分を表す。until は次回の取得で渡す時刻とする。
+type ChangesDTO struct {
+	Since      string              `json:"since"`
+	Until      string              `json:"until"`
+	Categories []CategoryChangeDTO `json:"categories"`
+	Issues     []IssueChangeDTO    `json:"issues"`
+}
+
+// RefreshDTO は DD-REFRESH-001 の前回の確認以降に外部で行われた変更を表す。
+type RefreshDTO struct {
+	Categories []CategoryChangeDTO `json:"categories"`
+	Issues     []IssueChangeDTO    `json:"issues"`
+}
+
+// CategoryChangeDTO は DD-CHANGES-001 のカテゴリの変更1件を表す。op は created/updated/removed のいずれか。
+type CategoryChangeDTO struct {
+	Name string `json:"name"`
+	Op   string `json:"op"`
+}
+
+// IssueChangeDTO は DD-CHANGES-001 の課題の変更1件を表す。削除の場合 issue は含めない。
+type IssueChangeDTO struct {
+	Op       string           `json:"op"`
+	Category string           `json:"category"`
+	IssueID  string           `json:"issue_id"`
+	Issue    *IssueSummaryDTO `json:"issue,omitempty"`
+}
+
 // IssueSummaryDTO は DD-LOAD-004 の課題一覧項目を表す。
+// *_local は DD-DATA-002 の表示用のタイムゾーンへ変換した日時を表し、解析できない場合は空文字とする。
 type IssueSummaryDTO struct {
 	IssueID         string `json:"issue_id"`
 	Title           string `json:"title"`
@@ -62,25 +335,108 @@ type IssueSummaryDTO struct {
 	Priority        string `json:"priority"`
 	OriginCompany   string `json:"origin_company"`
 	UpdatedAt       string `json:"updated_at"`
+	UpdatedAtLocal  string `json:"updated_at_local"`
 	DueDate         string `json:"due_date"`
 	IsSchemaInvalid bool   `json:"is_schema_invalid"`
 }
 
 // IssueListDTO は DD-BE-003 の課題一覧結果を表す。
+// next_cursor は続きがある場合のみ設定し、次回の IssueListQueryDTO.cursor に渡す。
 type IssueListDTO struct {
-	Category string            `json:"category"`
-	Total    int               `json:"total"`
-	Page     int               `json:"page"`
-	PageSize int               `json:"page_size"`
-	Issues   []IssueSummaryDTO `json:"issues"`
+	Category   string            `json:"category"`
+	Total      int               `json:"total"`
+	Page       int               `json:"page"`
+	PageSize   int               `json:"page_size"`
+	Issues     []IssueSummaryDTO `json:"issues"`
+	NextCursor string            `json:"next_cursor,omitempty"`
 }
 
 // IssueListQueryDTO は DD-BE-003 の一覧条件を表す。
+// cursor を指定した場合は page を用いず、前回の一覧の続きを返す。
 type IssueListQueryDTO struct {
 	Page      int    `json:"page"`
 	PageSize  int    `json:"page_size"`
 	SortBy    string `json:"sort_by"`
 	SortOrder string `json:"sort_order"`
+	Status    string `json:"status,omitempty"`
+	Priority  string `json:"priority,omitempty"`
+	Cursor    string `json:"cursor,omitempty"`
+}
+
+// IssueExportQueryDTO は DD-EXPORT-001 の課題一覧の出力条件を表す。
+// format は csv・json・jsonl のいずれかとし、categories/statuses が空の場合は絞り込まない。
+type IssueExportQueryDTO struct {
+	Format     string   `json:"format"`
+	Categories []string `json:"categories,omitempty"`
+	Statuses   []string `json:"statuses,omitempty"`
+}
+
+// IssueImportQueryDTO は DD-IMPORT-001 の JSON Lines 形式の課題の取り込み条件を表す。
+// category が空の場合は各行の category へ取り込み、overwrite は同じ課題IDの既存の課題を上書きすることを表す。
+type IssueImportQueryDTO struct {
+	Path      string `json:"path"`
+	Category  string `json:"category,omitempty"`
+	Overwrite bool   `json:"overwrite"`
+	DryRun    bool   `json:"dry_run"`
+}
+
+// SyncQueryDTO は DD-SYNC-001 の同期の条件を表す。other_root は開いているプロジェクトと同期する相手のプロジェクトルートを表す。
+type SyncQueryDTO struct {
+	OtherRoot string `json:"other_root"`
+	DryRun    bool   `json:"dry_run"`
+}
+
+// PatchExportQueryDTO は DD-PATCH-001 のパッチの出力条件を表す。since は基準時刻 (RFC 3339 または YYYY-MM-DD) で、空の場合は全件を出力する。
+// passphrase は相手と共有する署名用のパスフレーズを表す。
+type PatchExportQueryDTO struct {
+	DestPath   string `json:"dest_path"`
+	Since      string `json:"since,omitempty"`
+	Passphrase string `json:"passphrase"`
+}
+
+// PatchApplyQueryDTO は DD-PATCH-002 のパッチの取り込み条件を表す。passphrase は相手と共有する署名用のパスフレーズを表す。
+type PatchApplyQueryDTO struct {
+	Path       string `json:"path"`
+	Passphrase string `json:"passphrase"`
+	DryRun     bool   `json:"dry_run"`
+}
+
+// WeeklyReportQueryDTO は DD-REPORT-002 の期間の報告書の出力条件を表す。
+// from/to は YYYY-MM-DD とし、to が空の場合は今日、from が空の場合は to の6日前とする。format は md または html とする。
+type WeeklyReportQueryDTO struct {
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+	Format string `json:"format"`
+}
+
+// RedmineExportQueryDTO は DD-REDMINE-001 の Redmine 向けの CSV の出力条件を表す。categories/statuses が空の場合は絞り込まない。
+type RedmineExportQueryDTO struct {
+	Categories []string `json:"categories,omitempty"`
+	Statuses   []string `json:"statuses,omitempty"`
+}
+
+// RedmineImportQueryDTO は DD-REDMINE-002 の Redmine の CSV の取り込み条件を表す。
+// journals_path は注記の CSV で、空の場合は注記を取り込まない。company は Redmine 側の会社種別で、空の場合は Contractor とする。
+// default_due_date は期日の無い課題に用いる期日 (YYYY-MM-DD) を表す。
+type RedmineImportQueryDTO struct {
+	Category       string `json:"category"`
+	IssuesPath     string `json:"issues_path"`
+	JournalsPath   string `json:"journals_path,omitempty"`
+	Company        string `json:"company,omitempty"`
+	DefaultDueDate string `json:"default_due_date,omitempty"`
+	DryRun         bool   `json:"dry_run"`
+}
+
+// LogQueryDTO は DD-LOG-001 のログの絞り込み条件を表す。
+// level は debug/info/error のいずれかで、指定したレベル以上を返す。since/until は RFC3339 とし、空の場合は絞り込まない。
+// category は DD-LOG-005 の監査ログ (audit) などの記録の種別を表し、空の場合は絞り込まない。
+// limit は新しい方から返す件数とし、0 の場合は既定値を用いる。
+type LogQueryDTO struct {
+	Level    string `json:"level,omitempty"`
+	Since    string `json:"since,omitempty"`
+	Until    string `json:"until,omitempty"`
+	Category string `json:"category,omitempty"`
+	Limit    int    `json:"limit,omitempty"`
 }
 
 // IssueCreateDTO は DD-BE-003 の課題作成入力を表す。
@@ -100,6 +456,8 @@ type IssueUpdateDTO struct {
 	Priority    string `json:"priority"`
 	Status      string `json:"status"`
 	Assignee    string `json:"assignee"`
+	// ExpectedRevision は DD-PERSIST-006 の編集を始めた時点の課題の revision を表す。
+	ExpectedRevision string `json:"expected_revision,omitempty"`
 }
 
 // AttachmentUploadDTO は DD-DATA-005 の添付入力を表す。
@@ -109,11 +467,28 @@ type AttachmentUploadDTO struct {
 	MimeType         string `json:"mime_type"`
 }
 
+// FileDropDTO は DD-DATA-005 のドラッグ&ドロップされたファイルの通知を表す。
+// x, y はドロップ位置を表し、UI はその位置のコメント入力へ attachments を追加する。
+type FileDropDTO struct {
+	X           int                   `json:"x"`
+	Y           int                   `json:"y"`
+	Attachments []AttachmentUploadDTO `json:"attachments"`
+	Rejected    []RejectedFileDTO     `json:"rejected"`
+}
+
+// RejectedFileDTO は DD-DATA-005 の添付できなかったファイルと理由を表す。
+type RejectedFileDTO struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
 // CommentCreateDTO は DD-DATA-004 のコメント作成入力を表す。
 type CommentCreateDTO struct {
 	Body        string                `json:"body"`
 	AuthorName  string                `json:"author_name"`
 	Attachments []AttachmentUploadDTO `json:"attachments"`
+	// ExpectedRevision は DD-PERSIST-006 のコメントを書き始めた時点の課題の revision を表す。
+	ExpectedRevision string `json:"expected_revision,omitempty"`
 }
 
 // AttachmentRefDTO は DD-DATA-005 の添付参照を表す。
@@ -126,30 +501,281 @@ type AttachmentRefDTO struct {
 	SizeBytes    int64  `json:"size_bytes,omitempty"`
 }
 
-// CommentDTO は DD-DATA-004 のコメント情報を表す。
+// CommentDTO は DD-DATA-004 のコメント情報を表す。created_at_local は表示用のタイムゾーンへ変換した日時を表す。
 type CommentDTO struct {
-	CommentID     string             `json:"comment_id"`
-	Body          string             `json:"body"`
-	AuthorName    string             `json:"author_name"`
-	AuthorCompany string             `json:"author_company"`
-	CreatedAt     string             `json:"created_at"`
-	Attachments   []AttachmentRefDTO `json:"attachments"`
+	CommentID      string             `json:"comment_id"`
+	Body           string             `json:"body"`
+	AuthorName     string             `json:"author_name"`
+	AuthorCompany  string             `json:"author_company"`
+	CreatedAt      string             `json:"created_at"`
+	CreatedAtLocal string             `json:"created_at_local"`
+	Attachments    []AttachmentRefDTO `json:"attachments"`
 }
 
-// IssueDetailDTO は DD-DATA-003/004 の課題詳細を表す。
+// IssueDetailDTO は DD-DATA-003/004 の課題詳細を表す。tags・custom_fields は版 2 以降の任意項目で、無い場合は省略する。
+// *_local は DD-DATA-002 の表示用のタイムゾーンへ変換した日時を表し、解析できない場合は空文字とする。
 type IssueDetailDTO struct {
-	IsSchemaInvalid bool         `json:"is_schema_invalid"`
-	Version         int          `json:"version"`
-	IssueID         string       `json:"issue_id"`
-	Category        string       `json:"category"`
-	Title           string       `json:"title"`
-	Description     string       `json:"description"`
-	Status          string       `json:"status"`
-	Priority        string       `json:"priority"`
-	OriginCompany   string       `json:"origin_company"`
-	Assignee        string       `json:"assignee"`
-	CreatedAt       string       `json:"created_at"`
-	UpdatedAt       string       `json:"updated_at"`
-	DueDate         string       `json:"due_date"`
-	Comments        []CommentDTO `json:"comments"`
+	IsSchemaInvalid bool           `json:"is_schema_invalid"`
+	Version         int            `json:"version"`
+	IssueID         string         `json:"issue_id"`
+	Category        string         `json:"category"`
+	Title           string         `json:"title"`
+	Description     string         `json:"description"`
+	Status          string         `json:"status"`
+	Priority        string         `json:"priority"`
+	OriginCompany   string         `json:"origin_company"`
+	Assignee        string         `json:"assignee"`
+	CreatedAt       string         `json:"created_at"`
+	CreatedAtLocal  string         `json:"created_at_local"`
+	UpdatedAt       string         `json:"updated_at"`
+	UpdatedAtLocal  string         `json:"updated_at_local"`
+	DueDate         string         `json:"due_date"`
+	Tags            []string       `json:"tags,omitempty"`
+	CustomFields    map[string]any `json:"custom_fields,omitempty"`
+	Comments        []CommentDTO   `json:"comments"`
+	Revision        string         `json:"revision,omitempty"`
+}
+
+// DiagnosticsExportDTO は DD-DIAG-001 の診断情報出力結果を表す。
+type DiagnosticsExportDTO struct {
+	Path      string `json:"path"`
+	FileCount int    `json:"file_count"`
+}
+
+// UndoDTO は DD-JOURNAL-001 の取り消した操作を表す。operation は issue_created などの操作名を表す。
+type UndoDTO struct {
+	Operation string `json:"operation"`
+	Category  string `json:"category"`
+	IssueID   string `json:"issue_id"`
+}
+
+// BundleExportDTO は DD-BUNDLE-001 の課題バンドル出力結果を表す。
+type BundleExportDTO struct {
+	Path      string `json:"path"`
+	IssueID   string `json:"issue_id"`
+	FileCount int    `json:"file_count"`
+}
+
+// IssueExportDTO は DD-EXPORT-001 の課題一覧の出力結果を表す。skipped は解析できず出力しなかった課題JSONの数を表す。
+type IssueExportDTO struct {
+	Path    string `json:"path"`
+	Format  string `json:"format"`
+	Count   int    `json:"count"`
+	Skipped int    `json:"skipped"`
+}
+
+// ExportFormatDTO は DD-PLUGIN-002 の課題一覧の出力形式を表す。plugin は形式を追加したプラグインの名前を表し、本体の形式では空とする。
+type ExportFormatDTO struct {
+	Format      string `json:"format"`
+	Extension   string `json:"extension"`
+	Description string `json:"description,omitempty"`
+	Plugin      string `json:"plugin,omitempty"`
+}
+
+// IssueImportRowDTO は DD-IMPORT-001 の1行分の取り込み結果を表す。status は created・updated・failed のいずれか。
+type IssueImportRowDTO struct {
+	Line     int    `json:"line"`
+	Status   string `json:"status"`
+	Category string `json:"category,omitempty"`
+	IssueID  string `json:"issue_id,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// IssueImportDTO は DD-IMPORT-001 の取り込み結果を表す。dry_run の場合、件数は保存した場合の件数を表す。
+type IssueImportDTO struct {
+	DryRun  bool                `json:"dry_run"`
+	Created int                 `json:"created"`
+	Updated int                 `json:"updated"`
+	Failed  int                 `json:"failed"`
+	Rows    []IssueImportRowDTO `json:"rows"`
+}
+
+// SyncConflictDTO は DD-SYNC-002 の両方のプロジェクトで異なる値に変更された項目を表す。base は前回の同期時点の値で、記録が無い場合は null とする。
+type SyncConflictDTO struct {
+	Field  string `json:"field"`
+	Base   any    `json:"base"`
+	Local  any    `json:"local"`
+	Remote any    `json:"remote"`
+}
+
+// SyncIssueDTO は DD-SYNC-001 の1つの課題の同期の結果を表す。
+// action は copied_to_local・copied_to_remote・merged・conflict・failed のいずれかとする。
+type SyncIssueDTO struct {
+	Category         string            `json:"category"`
+	IssueID          string            `json:"issue_id"`
+	Action           string            `json:"action"`
+	LocalUpdated     bool              `json:"local_updated"`
+	RemoteUpdated    bool              `json:"remote_updated"`
+	CommentsToLocal  int               `json:"comments_to_local"`
+	CommentsToRemote int               `json:"comments_to_remote"`
+	Attachments      int               `json:"attachments"`
+	Conflicts        []SyncConflictDTO `json:"conflicts,omitempty"`
+	Message          string            `json:"message,omitempty"`
+}
+
+// SyncDTO は DD-SYNC-001 の同期の結果と、手作業で解決する競合の報告を表す。issues には変更・競合・失敗のあった課題のみを含める。
+type SyncDTO struct {
+	DryRun     bool           `json:"dry_run"`
+	LocalRoot  string         `json:"local_root"`
+	RemoteRoot string         `json:"remote_root"`
+	Copied     int            `json:"copied"`
+	Merged     int            `json:"merged"`
+	Conflicted int            `json:"conflicted"`
+	Failed     int            `json:"failed"`
+	Unchanged  int            `json:"unchanged"`
+	Issues     []SyncIssueDTO `json:"issues"`
+}
+
+// PatchExportDTO は DD-PATCH-001 のパッチの出力結果を表す。exported_at は次回のパッチの基準時刻に用いる。
+type PatchExportDTO struct {
+	Path        string `json:"path"`
+	ExportedAt  string `json:"exported_at"`
+	Issues      int    `json:"issues"`
+	Comments    int    `json:"comments"`
+	Attachments int    `json:"attachments"`
+}
+
+// PatchIssueDTO は DD-PATCH-002 のパッチの課題1件の取り込みの結果を表す。
+// action は created・renamed・updated・conflict・failed のいずれかとし、local_issue_id はこのプロジェクトの課題IDを表す。
+// conflicts は両側で変更されたためこのプロジェクトの値のまま残した項目名を表す。
+type PatchIssueDTO struct {
+	Category     string   `json:"category"`
+	IssueID      string   `json:"issue_id"`
+	LocalIssueID string   `json:"local_issue_id"`
+	Action       string   `json:"action"`
+	Comments     int      `json:"comments"`
+	Attachments  int      `json:"attachments"`
+	Conflicts    []string `json:"conflicts,omitempty"`
+	Message      string   `json:"message,omitempty"`
+}
+
+// PatchApplyDTO は DD-PATCH-002 のパッチの取り込みの結果を表す。issues には変更・競合・失敗のあった課題のみを含める。
+// dry_run の場合、件数は保存した場合の件数を表す。
+type PatchApplyDTO struct {
+	DryRun     bool            `json:"dry_run"`
+	ExportedAt string          `json:"exported_at"`
+	Since      string          `json:"since,omitempty"`
+	Created    int             `json:"created"`
+	Updated    int             `json:"updated"`
+	Conflicted int             `json:"conflicted"`
+	Failed     int             `json:"failed"`
+	Unchanged  int             `json:"unchanged"`
+	Issues     []PatchIssueDTO `json:"issues"`
+}
+
+// IssueRevisionDTO は DD-GIT-002 の課題JSONを変更した1つのコミットと、その時点の課題を表す。
+// operation・actor・mode は自動コミットのメッセージから復元した値で、手作業のコミットでは空とする。
+// snapshot は削除されたコミット (deleted) と解析できなかった版 (parse_error) では省略する。
+type IssueRevisionDTO struct {
+	Commit           string          `json:"commit"`
+	AuthorName       string          `json:"author_name"`
+	AuthorEmail      string          `json:"author_email"`
+	CommittedAt      string          `json:"committed_at"`
+	CommittedAtLocal string          `json:"committed_at_local"`
+	Subject          string          `json:"subject"`
+	Operation        string          `json:"operation"`
+	Actor            string          `json:"actor"`
+	Mode             string          `json:"mode"`
+	Deleted          bool            `json:"deleted"`
+	ParseError       string          `json:"parse_error,omitempty"`
+	Snapshot         *IssueDetailDTO `json:"snapshot,omitempty"`
+}
+
+// IssueHistoryDTO は DD-GIT-002 の課題のコミット履歴を表す。revisions は新しい順に並べる。
+type IssueHistoryDTO struct {
+	Category  string             `json:"category"`
+	IssueID   string             `json:"issue_id"`
+	Revisions []IssueRevisionDTO `json:"revisions"`
+}
+
+// ReportDTO は DD-REPORT-001 の PDF の出力結果を表す。
+type ReportDTO struct {
+	Path  string `json:"path"`
+	Pages int    `json:"pages"`
+}
+
+// RedmineExportDTO は DD-REDMINE-001 の Redmine 向けの CSV の出力結果を表す。
+// journals_path は注記を出力しなかった場合は空とし、skipped は解析できず出力しなかった課題JSONの数を表す。
+type RedmineExportDTO struct {
+	IssuesPath   string `json:"issues_path"`
+	JournalsPath string `json:"journals_path,omitempty"`
+	Issues       int    `json:"issues"`
+	Journals     int    `json:"journals"`
+	Skipped      int    `json:"skipped"`
+}
+
+// RedmineImportRowDTO は DD-REDMINE-002 の1行分の取り込み結果を表す。status は created・updated・unchanged・failed のいずれか。
+type RedmineImportRowDTO struct {
+	Line      int    `json:"line"`
+	RedmineID string `json:"redmine_id,omitempty"`
+	Status    string `json:"status"`
+	IssueID   string `json:"issue_id,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+// RedmineImportDTO は DD-REDMINE-002 の取り込み結果を表す。dry_run の場合、件数は保存した場合の件数を表す。
+type RedmineImportDTO struct {
+	DryRun    bool                  `json:"dry_run"`
+	Created   int                   `json:"created"`
+	Updated   int                   `json:"updated"`
+	Unchanged int                   `json:"unchanged"`
+	Failed    int                   `json:"failed"`
+	Rows      []RedmineImportRowDTO `json:"rows"`
+}
+
+// EmlDraftDTO は DD-EML-001 の課題のメールの下書きの出力結果を表す。
+// attachments は埋め込んだ添付の数、missing_attachments は見つからず埋め込まなかった添付の数を表す。
+type EmlDraftDTO struct {
+	Path               string `json:"path"`
+	Attachments        int    `json:"attachments"`
+	MissingAttachments int    `json:"missing_attachments"`
+}
+
+// WeeklyReportDTO は DD-REPORT-002 の期間の報告書の出力結果を表す。
+// opened/resolved/closed/overdue は全カテゴリの件数、skipped は解析できず数えなかった課題JSONの数を表す。
+type WeeklyReportDTO struct {
+	Path     string `json:"path"`
+	Format   string `json:"format"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Opened   int    `json:"opened"`
+	Resolved int    `json:"resolved"`
+	Closed   int    `json:"closed"`
+	Overdue  int    `json:"overdue"`
+	Skipped  int    `json:"skipped"`
+}
+
+// SitePublishDTO は DD-PUBLISH-001 の静的な HTML サイトの出力結果を表す。
+// skipped は解析できず出力しなかった課題JSONの数、missing_attachments は見つからず複写しなかった添付の数を表す。
+type SitePublishDTO struct {
+	Path               string `json:"path"`
+	Categories         int    `json:"categories"`
+	Issues             int    `json:"issues"`
+	Attachments        int    `json:"attachments"`
+	MissingAttachments int    `json:"missing_attachments"`
+	Skipped            int    `json:"skipped"`
+}
+
+// MigrationChangeDTO は DD-MIGRATE-001 の移行が必要 (dry-run 以外では移行済み) なファイル1件を表す。
+type MigrationChangeDTO struct {
+	Kind string `json:"kind"`
+	Path string `json:"path"`
+	From int    `json:"from"`
+	To   int    `json:"to"`
+}
+
+// MigrationProblemDTO は DD-MIGRATE-001 の移行できないファイル1件を表す。
+type MigrationProblemDTO struct {
+	Kind    string `json:"kind"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// MigrationResultDTO は DD-MIGRATE-001 のプロジェクトの形式移行の結果を表す。backup_dir は書き換え前の内容を残した場合のみ設定する。
+type MigrationResultDTO struct {
+	DryRun    bool                  `json:"dry_run"`
+	Checked   int                   `json:"checked"`
+	Changes   []MigrationChangeDTO  `json:"changes"`
+	Problems  []MigrationProblemDTO `json:"problems"`
+	BackupDir string                `json:"backup_dir,omitempty"`
 }
@@ -3,19 +3,26 @@
 package present
 
 import (
+	"context"
 	"errors"
-	"strings"
+	"io/fs"
 
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/apperr"
 	"ratta/internal/domain/issue"
 )
 
 const (
-	ErrorValidation = "E_VALIDATION"
-	ErrorPermission = "E_PERMISSION"
-	ErrorNotFound   = "E_NOT_FOUND"
-	ErrorConflict   = "E_CONFLICT"
-	ErrorCrypto     = "E_CRYPTO"
-	ErrorInternal   = "E_INTERNAL"
+	ErrorValidation    = "E_VALIDATION"
+	ErrorPermission    = "E_PERMISSION"
+	ErrorNotFound      = "E_NOT_FOUND"
+	ErrorConflict      = "E_CONFLICT"
+	ErrorCrypto        = "E_CRYPTO"
+	ErrorInternal      = "E_INTERNAL"
+	ErrorCanceled      = "E_CANCELED"
+	ErrorSchemaInvalid = "E_SCHEMA_INVALID"
+	// ErrorMimeMismatch は DD-DATA-005 の添付の申告された種類と拡張子から判定した種類が異なることを表す。警告としてのみ用いる。
+	ErrorMimeMismatch = "E_MIME_MISMATCH"
 )
 
 // Ok は DD-BE-003 の成功レスポンスを作る。
@@ -23,6 +30,14 @@ func Ok(data any) Response {
 	return Response{Ok: true, Data: data}
 }
 
+// OkWithWarnings は DD-BE-003 の警告を伴う成功レスポンスを作る。warnings が空の場合は Ok と同じとする。
+func OkWithWarnings(data any, warnings []APIErrorDTO) Response {
+	if len(warnings) == 0 {
+		return Ok(data)
+	}
+	return Response{Ok: true, Data: data, Warnings: warnings}
+}
+
 // Fail は DD-BE-003 の失敗レスポンスを作る。
 func Fail(err error) Response {
 	return Response{Ok: false, Error: MapError(err)}
@@ -31,66 +46,90 @@ func Fail(err error) Response {
 // MapError は DD-BE-003 の APIErrorDTO へ変換する。
 // 目的: 内部エラーをUI向けの共通エラー形式に正規化する。
 // 入力: err は内部エラー。
-// 出力: APIErrorDTO へのポインタ。
+// 出力: APIErrorDTO へのポインタ。message はエラーコードに対応する表示言語のメッセージ、detail は内部エラーのメッセージ。
+// 対象のパスと復旧の指針が分かる場合は target_path と hint を、外部で変更された課題の保存を拒んだ場合は conflict を設定する。
 // エラー: なし。
 // 副作用: なし。
 // 並行性: スレッドセーフ。
-// 不変条件: err が nil の場合は nil を返す。
-// 関連DD: DD-BE-003
+// 不変条件: err が nil の場合は nil を返す。中断は detail を持たない。
+// 関連DD: DD-BE-003, DD-BE-004, DD-PERSIST-006
 func MapError(err error) *APIErrorDTO {
 	if err == nil {
 		return nil
 	}
 
 	var validationErrors issue.ValidationErrors
-	if errors.As(err, &validationErrors) {
+	var validationError *issue.ValidationError
+	if errors.As(err, &validationErrors) || errors.As(err, &validationError) {
 		return &APIErrorDTO{
 			ErrorCode: ErrorValidation,
-			Message:   "Validation failed.",
+			Message:   Message(ErrorValidation),
 			Detail:    err.Error(),
 		}
 	}
-	var validationError *issue.ValidationError
-	if errors.As(err, &validationError) {
+
+	// 利用者による中断は失敗ではないため、内部エラーと区別して返す。
+	if errors.Is(err, context.Canceled) {
 		return &APIErrorDTO{
-			ErrorCode: ErrorValidation,
-			Message:   "Validation failed.",
-			Detail:    err.Error(),
+			ErrorCode: ErrorCanceled,
+			Message:   Message(ErrorCanceled),
 		}
 	}
 
-	message := err.Error()
-	code := classifyError(message)
-	return &APIErrorDTO{
+	code := classifyError(err)
+	dto := &APIErrorDTO{
 		ErrorCode: code,
-		Message:   message,
+		Message:   Message(code),
+		Detail:    err.Error(),
 	}
+	dto.TargetPath, dto.Hint = targetOf(err)
+	var conflict *issueops.ExternalChangeError
+	if errors.As(err, &conflict) {
+		dto.Conflict = toIssueConflictDTO(conflict)
+	}
+	return dto
+}
+
+// targetOf は DD-BE-004 の err が持つ対象のパスと復旧の指針の文言を返す。
+// apperr.PathError を優先し、無い場合は fs.PathError のパスと IOHint の指針を用いる。いずれも無い場合は空文字を返す。
+func targetOf(err error) (string, string) {
+	var pathErr *apperr.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Path, HintMessage(pathErr.Hint)
+	}
+	var fsErr *fs.PathError
+	if errors.As(err, &fsErr) {
+		return fsErr.Path, HintMessage(apperr.IOHint(err, ""))
+	}
+	return "", ""
 }
 
 // classifyError は DD-BE-003 のエラーコード判定を行う。
-// 目的: メッセージ内容から ApiErrorDTO.error_code を決定する。
-// 入力: message はエラーメッセージ文字列。
+// 目的: エラーの種別 (apperr) から ApiErrorDTO.error_code を決定する。
+// 入力: err は内部エラー。
 // 出力: エラーコード文字列。
 // エラー: なし。
 // 副作用: なし。
 // 並行性: スレッドセーフ。
-// 不変条件: 既知の判定に一致しない場合は E_INTERNAL を返す。
+// 不変条件: 種別を持たない場合は E_INTERNAL を返す。メッセージの文言では判定しない。
 // 関連DD: DD-BE-003
-func classifyError(message string) string {
-	switch {
-	case strings.Contains(message, "project root is not set"):
+func classifyError(err error) string {
+	kind := apperr.Kind(err)
+	// OS のアクセス権限の不足は種別を持たないため、fs.ErrPermission で判定する。
+	if kind == nil && errors.Is(err, fs.ErrPermission) {
+		return ErrorPermission
+	}
+	switch kind {
+	case apperr.ErrValidation:
 		return ErrorValidation
-	case strings.Contains(message, "permission"):
+	case apperr.ErrPermission:
 		return ErrorPermission
-	case strings.Contains(message, "not found"):
+	case apperr.ErrNotFound:
 		return ErrorNotFound
-	case strings.Contains(message, "conflict"),
-		strings.Contains(message, "read-only"),
-		strings.Contains(message, "schema invalid"),
-		strings.Contains(message, "not empty"):
+	// 読み取り専用・スキーマ不整合の対象への変更は、現在の状態と衝突する操作として扱う。
+	case apperr.ErrConflict, apperr.ErrReadOnly, apperr.ErrSchemaInvalid:
 		return ErrorConflict
-	case strings.Contains(message, "password verification failed"),
-		strings.Contains(message, "crypto"):
+	case apperr.ErrCrypto:
 		return ErrorCrypto
 	default:
 		return ErrorInternal
@@ -2,9 +2,14 @@
 package present
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io/fs"
 	"testing"
 
+	"ratta/internal/app/issueops"
+	"ratta/internal/domain/apperr"
 	"ratta/internal/domain/issue"
 )
 
@@ -24,38 +29,63 @@ func TestMapError_ValidationErrors(t *testing.T) {
 
 func TestMapError_Permission(t *testing.T) {
 	// 権限エラーが E_PERMISSION に変換されることを確認する。
-	dto := MapError(errors.New("permission denied"))
+	dto := MapError(fmt.Errorf("save: %w", apperr.New(apperr.ErrPermission, "observer mode")))
 	if dto.ErrorCode != ErrorPermission {
 		t.Fatalf("unexpected code: %s", dto.ErrorCode)
 	}
+	if dto.Detail != "save: observer mode" {
+		t.Fatalf("unexpected detail: %s", dto.Detail)
+	}
+	osErr := &fs.PathError{Op: "open", Path: "issue.json", Err: fs.ErrPermission}
+	if dto := MapError(osErr); dto.ErrorCode != ErrorPermission {
+		t.Fatalf("unexpected code for os error: %s", dto.ErrorCode)
+	}
 }
 
 func TestMapError_NotFound(t *testing.T) {
-	// not found が E_NOT_FOUND になることを確認する。
-	dto := MapError(errors.New("category not found"))
+	// 対象が存在しないことを表す種別が E_NOT_FOUND になることを確認する。
+	dto := MapError(apperr.New(apperr.ErrNotFound, "category not found"))
 	if dto.ErrorCode != ErrorNotFound {
 		t.Fatalf("unexpected code: %s", dto.ErrorCode)
 	}
 }
 
 func TestMapError_Conflict(t *testing.T) {
-	// conflict が E_CONFLICT になることを確認する。
-	dto := MapError(errors.New("category not empty"))
-	if dto.ErrorCode != ErrorConflict {
+	// 衝突・読み取り専用・スキーマ不整合の種別が E_CONFLICT になることを確認する。
+	for _, kind := range []error{apperr.ErrConflict, apperr.ErrReadOnly, apperr.ErrSchemaInvalid} {
+		dto := MapError(apperr.New(kind, "category not empty"))
+		if dto.ErrorCode != ErrorConflict {
+			t.Fatalf("unexpected code for %v: %s", kind, dto.ErrorCode)
+		}
+	}
+}
+
+func TestMapError_Crypto(t *testing.T) {
+	// パスワードの照合失敗の種別が E_CRYPTO になることを確認する。
+	dto := MapError(apperr.New(apperr.ErrCrypto, "password verification failed"))
+	if dto.ErrorCode != ErrorCrypto {
+		t.Fatalf("unexpected code: %s", dto.ErrorCode)
+	}
+}
+
+func TestMapError_Canceled(t *testing.T) {
+	// 中断された処理のエラーが E_CANCELED になることを確認する。
+	dto := MapError(fmt.Errorf("scan: %w", context.Canceled))
+	if dto.ErrorCode != ErrorCanceled {
 		t.Fatalf("unexpected code: %s", dto.ErrorCode)
 	}
 }
 
 func TestMapError_Internal(t *testing.T) {
-	// 未分類エラーが E_INTERNAL になることを確認する。
-	dto := MapError(errors.New("unexpected"))
+	// 種別を持たないエラーはメッセージの文言によらず E_INTERNAL になることを確認する。
+	dto := MapError(errors.New("permission denied: category not found"))
 	if dto.ErrorCode != ErrorInternal {
 		t.Fatalf("unexpected code: %s", dto.ErrorCode)
 	}
 }
 
 // TestMapError_ProjectRootNotSet はプロジェクトルート未設定の分類を確認する。
-// 目的: project root is not set が E_VALIDATION になることを確認する。
+// 目的: 入力の不備の種別を持つ project root is not set が E_VALIDATION になることを確認する。
 // 入力: 未設定メッセージのエラー。
 // 出力: ErrorValidation のAPIErrorDTO。
 // エラー: なし。
@@ -65,7 +95,7 @@ func TestMapError_Internal(t *testing.T) {
 // 関連DD: DD-BE-003
 func TestMapError_ProjectRootNotSet(t *testing.T) {
 	// プロジェクトルート未設定はバリデーション扱いとなることを確認する。
-	dto := MapError(errors.New("project root is not set"))
+	dto := MapError(apperr.New(apperr.ErrValidation, "project root is not set"))
 	if dto.ErrorCode != ErrorValidation {
 		t.Fatalf("unexpected code: %s", dto.ErrorCode)
 	}
@@ -84,7 +114,7 @@ func TestOkAndFail_ResponseEnvelope(t *testing.T) {
 		t.Fatal("expected error to be nil")
 	}
 
-	fail := Fail(errors.New("permission denied"))
+	fail := Fail(apperr.New(apperr.ErrPermission, "permission denied"))
 	if fail.Ok {
 		t.Fatal("expected Ok to be false")
 	}
@@ -95,3 +125,84 @@ func TestOkAndFail_ResponseEnvelope(t *testing.T) {
 		t.Fatalf("unexpected error code: %s", fail.Error.ErrorCode)
 	}
 }
+
+func TestMapError_MessageFollowsLanguage(t *testing.T) {
+	// message は表示言語に応じたエラーコードのメッセージとなり、内部エラーのメッセージは detail に入ることを確認する。
+	t.Cleanup(func() { SetLanguage(LanguageJa) })
+	err := apperr.New(apperr.ErrNotFound, "category not found")
+
+	SetLanguage(LanguageJa)
+	if dto := MapError(err); dto.Message != "対象が見つかりません。" || dto.Detail != "category not found" {
+		t.Fatalf("unexpected ja dto: %+v", dto)
+	}
+	SetLanguage(LanguageEn)
+	if dto := MapError(err); dto.Message != "The target was not found." {
+		t.Fatalf("unexpected en dto: %+v", dto)
+	}
+	SetLanguage("fr")
+	if Language() != LanguageJa {
+		t.Fatalf("unsupported language must fall back to ja: %s", Language())
+	}
+}
+
+func TestCatalog_CoversAllErrorCodes(t *testing.T) {
+	// すべてのエラーコードに日本語と英語のメッセージがあることを確認する。
+	codes := []string{ErrorValidation, ErrorPermission, ErrorNotFound, ErrorConflict, ErrorCrypto, ErrorInternal, ErrorCanceled, ErrorSchemaInvalid}
+	for _, code := range codes {
+		for _, language := range []string{LanguageJa, LanguageEn} {
+			if catalog[code][language] == "" {
+				t.Fatalf("missing %s message for %s", language, code)
+			}
+		}
+	}
+}
+
+func TestOkWithWarnings_SetsWarningsOnlyWhenPresent(t *testing.T) {
+	// 警告がある場合のみ warnings を設定し、無い場合は Ok と同じ形式になることを確認する。
+	if resp := OkWithWarnings("data", nil); !resp.Ok || resp.Warnings != nil {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	warning := ToMimeMismatchWarningDTO("a.png", "image/png", "application/pdf")
+	resp := OkWithWarnings("data", []APIErrorDTO{warning})
+	if !resp.Ok || resp.Data != "data" || len(resp.Warnings) != 1 || resp.Warnings[0].ErrorCode != ErrorMimeMismatch || resp.Warnings[0].TargetPath != "a.png" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestMapError_TargetPathAndHint(t *testing.T) {
+	// 対象のパスと復旧の指針を持つエラーは target_path と表示言語の hint を設定し、fs のエラーもパスを補うことを確認する。
+	t.Cleanup(func() { SetLanguage(LanguageJa) })
+	SetLanguage(LanguageEn)
+	err := fmt.Errorf("save: %w", apperr.WithPath(errors.New("rename failed"), "cat/a.json", apperr.HintRetryWrite))
+	dto := MapError(err)
+	if dto.TargetPath != "cat/a.json" || dto.Hint != HintMessage(apperr.HintRetryWrite) || dto.Hint == "" {
+		t.Fatalf("unexpected dto: %+v", dto)
+	}
+
+	dto = MapError(&fs.PathError{Op: "open", Path: "cat/b.json", Err: fs.ErrNotExist})
+	if dto.TargetPath != "cat/b.json" || dto.Hint != HintMessage(apperr.HintCheckExists) {
+		t.Fatalf("unexpected dto for fs error: %+v", dto)
+	}
+	if dto := MapError(errors.New("unexpected")); dto.TargetPath != "" || dto.Hint != "" {
+		t.Fatalf("unexpected target: %+v", dto)
+	}
+}
+
+func TestMapError_ExternalChangeSetsConflict(t *testing.T) {
+	// 外部で変更された課題の保存の拒否は E_CONFLICT とし、現在の内容と利用者の変更を conflict に設定することを確認する。
+	err := apperr.WithPath(&issueops.ExternalChangeError{
+		Path:      "cat/a.json",
+		Current:   issueops.IssueDetail{Issue: issue.Issue{IssueID: "a", Title: "theirs"}, Path: "cat/a.json", Revision: "r2"},
+		Attempted: issue.Issue{IssueID: "a", Title: "mine"},
+	}, "cat/a.json", apperr.HintMergeChanges)
+	dto := MapError(err)
+	if dto.ErrorCode != ErrorConflict || dto.Conflict == nil || dto.Hint != HintMessage(apperr.HintMergeChanges) || dto.Hint == "" {
+		t.Fatalf("unexpected dto: %+v", dto)
+	}
+	if dto.Conflict.Current.Title != "theirs" || dto.Conflict.Current.Revision != "r2" || dto.Conflict.Attempted.Title != "mine" || dto.Conflict.Attempted.Revision != "" {
+		t.Fatalf("unexpected conflict: %+v", dto.Conflict)
+	}
+	if dto := MapError(apperr.New(apperr.ErrConflict, "category not empty")); dto.Conflict != nil {
+		t.Fatalf("expected no conflict for other errors: %+v", dto.Conflict)
+	}
+}
@@ -1,18 +1,62 @@
 package present
 
 import (
+	"fmt"
+
+	"ratta/internal/app/categoryops"
 	"ratta/internal/app/categoryscan"
+	"ratta/internal/app/emldraft"
+	"ratta/internal/app/issueexport"
+	"ratta/internal/app/issueimport"
 	"ratta/internal/app/issueops"
+	"ratta/internal/app/issuescan"
+	"ratta/internal/app/migrate"
+	"ratta/internal/app/patchbundle"
+	"ratta/internal/app/projectsync"
+	"ratta/internal/app/redmine"
+	"ratta/internal/app/sitepublish"
+	"ratta/internal/app/weeklyreport"
 	"ratta/internal/domain/issue"
+	"ratta/internal/domain/timeutil"
+	"ratta/internal/infra/configrepo"
+	"ratta/internal/infra/fswatch"
+	"ratta/internal/infra/logging"
+	"ratta/internal/infra/pluginhost"
+	"ratta/internal/infra/projectlock"
+	"ratta/internal/infra/tmpresidue"
 )
 
 // ToCategoryDTO は DD-BE-003 のカテゴリ DTO に変換する。
 func ToCategoryDTO(category categoryscan.Category) CategoryDTO {
 	return CategoryDTO{
-		Name:       category.Name,
-		IsReadOnly: category.IsReadOnly,
-		Path:       category.Path,
-		IssueCount: 0,
+		Name:                category.Name,
+		IsReadOnly:          category.IsReadOnly,
+		IsArchived:          category.IsArchived,
+		Path:                category.Path,
+		IssueCount:          0,
+		Description:         category.Meta.Description,
+		Color:               category.Meta.Color,
+		SortWeight:          category.Meta.SortWeight,
+		DefaultAssignee:     category.Meta.DefaultAssignee,
+		DefaultPriority:     category.Meta.DefaultPriority,
+		DescriptionTemplate: category.Meta.DescriptionTemplate,
+	}
+}
+
+// ToManagedCategoryDTO は DD-BE-003 のカテゴリ操作結果をカテゴリ DTO に変換する。
+func ToManagedCategoryDTO(category categoryops.Category) CategoryDTO {
+	return CategoryDTO{
+		Name:                category.Name,
+		IsReadOnly:          category.IsReadOnly,
+		IsArchived:          category.IsArchived,
+		Path:                category.Path,
+		IssueCount:          0,
+		Description:         category.Meta.Description,
+		Color:               category.Meta.Color,
+		SortWeight:          category.Meta.SortWeight,
+		DefaultAssignee:     category.Meta.DefaultAssignee,
+		DefaultPriority:     category.Meta.DefaultPriority,
+		DescriptionTemplate: category.Meta.DescriptionTemplate,
 	}
 }
 
@@ -31,9 +75,22 @@ func ToIssueDetailDTO(detail issueops.IssueDetail) IssueDetailDTO {
 		OriginCompany:   string(issueValue.OriginCompany),
 		Assignee:        issueValue.Assignee,
 		CreatedAt:       issueValue.CreatedAt,
+		CreatedAtLocal:  timeutil.ToDisplay(issueValue.CreatedAt),
 		UpdatedAt:       issueValue.UpdatedAt,
+		UpdatedAtLocal:  timeutil.ToDisplay(issueValue.UpdatedAt),
 		DueDate:         issueValue.DueDate,
+		Tags:            issueValue.Tags,
+		CustomFields:    issueValue.CustomFields,
 		Comments:        toCommentDTOs(issueValue.Comments),
+		Revision:        detail.Revision,
+	}
+}
+
+// toIssueConflictDTO は DD-PERSIST-006 の外部での変更による保存の競合を DTO に変換する。
+func toIssueConflictDTO(conflict *issueops.ExternalChangeError) *IssueConflictDTO {
+	return &IssueConflictDTO{
+		Current:   ToIssueDetailDTO(conflict.Current),
+		Attempted: ToIssueDetailDTO(issueops.IssueDetail{Issue: conflict.Attempted, Path: conflict.Path}),
 	}
 }
 
@@ -46,11 +103,190 @@ func ToIssueSummaryDTO(summary issueops.IssueSummary) IssueSummaryDTO {
 		Priority:        summary.Priority,
 		OriginCompany:   summary.OriginCompany,
 		UpdatedAt:       summary.UpdatedAt,
+		UpdatedAtLocal:  timeutil.ToDisplay(summary.UpdatedAt),
 		DueDate:         summary.DueDate,
 		IsSchemaInvalid: summary.IsSchemaInvalid,
 	}
 }
 
+// ToIssueListDTO は DD-BE-003 の課題一覧結果を DTO に変換する。
+func ToIssueListDTO(list issueops.IssueList) IssueListDTO {
+	items := make([]IssueSummaryDTO, 0, len(list.Issues))
+	for _, item := range list.Issues {
+		items = append(items, ToIssueSummaryDTO(item))
+	}
+	return IssueListDTO{
+		Category:   list.Category,
+		Total:      list.Total,
+		Page:       list.Page,
+		PageSize:   list.PageSize,
+		Issues:     items,
+		NextCursor: list.NextCursor,
+	}
+}
+
+// ToSearchHitDTO は DD-SEARCH-001 の検索結果1件を DTO に変換する。
+func ToSearchHitDTO(hit issueops.SearchHit) SearchHitDTO {
+	matches := make([]SearchMatchDTO, 0, len(hit.Locations))
+	for _, location := range hit.Locations {
+		matches = append(matches, SearchMatchDTO{Field: location.Field, Start: location.Start, End: location.End})
+	}
+	return SearchHitDTO{
+		Category: hit.Summary.Category,
+		Score:    hit.Score,
+		Issue:    ToIssueSummaryDTO(hit.Summary),
+		Matches:  matches,
+	}
+}
+
+// ToProjectChangeDTO は DD-WATCH-001 の変更通知を DTO に変換する。
+func ToProjectChangeDTO(event fswatch.Event) ProjectChangeDTO {
+	return ProjectChangeDTO{
+		Kind:     string(event.Kind),
+		Op:       string(event.Op),
+		Category: event.Category,
+		IssueID:  event.IssueID,
+		Path:     event.Path,
+	}
+}
+
+// ToChangesDTO は DD-CHANGES-001 の変更差分を DTO に変換する。
+func ToChangesDTO(changes issueops.Changes) ChangesDTO {
+	dto := ChangesDTO{
+		Until:      timeutil.FormatISO8601(changes.Until),
+		Categories: make([]CategoryChangeDTO, 0, len(changes.Categories)),
+		Issues:     make([]IssueChangeDTO, 0, len(changes.Issues)),
+	}
+	if !changes.Since.IsZero() {
+		dto.Since = timeutil.FormatISO8601(changes.Since)
+	}
+	for _, change := range changes.Categories {
+		dto.Categories = append(dto.Categories, CategoryChangeDTO{Name: change.Name, Op: string(change.Op)})
+	}
+	for _, change := range changes.Issues {
+		item := IssueChangeDTO{Op: string(change.Op), Category: change.Category, IssueID: change.IssueID}
+		if change.Summary != nil {
+			summary := ToIssueSummaryDTO(*change.Summary)
+			item.Issue = &summary
+		}
+		dto.Issues = append(dto.Issues, item)
+	}
+	return dto
+}
+
+// ToRefreshDTO は DD-REFRESH-001 の外部変更の確認結果を DTO に変換する。
+func ToRefreshDTO(changes issueops.Changes) RefreshDTO {
+	dto := ToChangesDTO(changes)
+	return RefreshDTO{Categories: dto.Categories, Issues: dto.Issues}
+}
+
+// ToProjectLockDTO は DD-LOCK-002 のロックの保持者を DTO に変換する。
+func ToProjectLockDTO(holder projectlock.Holder) ProjectLockDTO {
+	return ProjectLockDTO{
+		Hostname:  holder.Hostname,
+		PID:       holder.PID,
+		UpdatedAt: holder.UpdatedAt,
+		Stale:     holder.Stale(),
+	}
+}
+
+// ToRenameResidueDTO は DD-BE-003 の中断されたカテゴリ名変更の残骸を DTO に変換する。
+func ToRenameResidueDTO(residue categoryops.RenameResidue) RenameResidueDTO {
+	return RenameResidueDTO{
+		Name:              residue.Name,
+		OriginalName:      residue.OriginalName,
+		IssueCount:        residue.Issues,
+		UpdatedIssueCount: residue.UpdatedIssues,
+		CanComplete:       residue.CanComplete,
+		CanRollback:       residue.CanRollback,
+	}
+}
+
+// ToSettingsDTO は DD-CONF-005 のアプリ設定を DTO に変換する。
+func ToSettingsDTO(settings configrepo.Settings) SettingsDTO {
+	return SettingsDTO{
+		DefaultSortBy:                   settings.DefaultSort.By,
+		DefaultSortOrder:                settings.DefaultSort.Order,
+		DefaultAuthorName:               settings.DefaultAuthorName,
+		DateFormat:                      settings.DateFormat,
+		Language:                        settings.Language,
+		TimeZone:                        settings.TimeZone,
+		ConfirmDeleteCategory:           settings.ConfirmOnDelete.Category,
+		ConfirmDeleteCategoryWithIssues: settings.ConfirmOnDelete.CategoryWithIssues,
+	}
+}
+
+// ToConfigRepairWarningDTO は DD-CONF-006 の config.json を修復した結果を警告に変換する。
+func ToConfigRepairWarningDTO(repair configrepo.Repair) APIErrorDTO {
+	return APIErrorDTO{
+		ErrorCode:  ErrorSchemaInvalid,
+		Message:    "config.json が不正なため、読み取れた設定を既定値に重ねて修復しました。",
+		Detail:     repair.Detail,
+		TargetPath: repair.Path,
+		Hint:       "修復前の内容は " + repair.BackupPath + " に保存しています。失われた設定を確認してください。",
+	}
+}
+
+// ToMimeMismatchWarningDTO は DD-DATA-005 の添付の種類の不一致を警告に変換する。
+func ToMimeMismatchWarningDTO(path, declared, detected string) APIErrorDTO {
+	return APIErrorDTO{
+		ErrorCode:  ErrorMimeMismatch,
+		Message:    Message(ErrorMimeMismatch),
+		Detail:     fmt.Sprintf("declared %s, detected %s from the extension", declared, detected),
+		TargetPath: path,
+	}
+}
+
+// ToExportSkippedWarningDTO は DD-EXPORT-001 の解析できず出力しなかった課題JSONの件数を警告に変換する。
+func ToExportSkippedWarningDTO(skipped int) APIErrorDTO {
+	return APIErrorDTO{
+		ErrorCode: ErrorSchemaInvalid,
+		Message:   Message(ErrorSchemaInvalid),
+		Detail:    fmt.Sprintf("%d unreadable issue files were skipped", skipped),
+	}
+}
+
+// ToMissingAttachmentsWarningDTO は DD-EML-001 の見つからず埋め込まなかった添付の件数を警告に変換する。
+func ToMissingAttachmentsWarningDTO(missing int) APIErrorDTO {
+	return APIErrorDTO{
+		ErrorCode: ErrorNotFound,
+		Message:   Message(ErrorNotFound),
+		Detail:    fmt.Sprintf("%d missing attachments were not embedded", missing),
+	}
+}
+
+// ToLogListDTO は DD-LOG-001 のログの取得結果を DTO に変換する。
+func ToLogListDTO(result logging.Result) LogListDTO {
+	entries := make([]LogEntryDTO, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		fields := entry.Fields
+		if fields == nil {
+			fields = map[string]any{}
+		}
+		entries = append(entries, LogEntryDTO{
+			Timestamp: timeutil.FormatISO8601(entry.Timestamp),
+			Level:     entry.Level,
+			Message:   entry.Message,
+			Fields:    fields,
+		})
+	}
+	return LogListDTO{Entries: entries, Truncated: result.Truncated}
+}
+
+// ToResidueWarningDTOs は DD-PERSIST-004 の一時ファイル残骸の検出結果をエラー一覧の形式に変換する。
+func ToResidueWarningDTOs(results []tmpresidue.ScanResult) []APIErrorDTO {
+	warnings := make([]APIErrorDTO, 0, len(results))
+	for _, result := range results {
+		warnings = append(warnings, APIErrorDTO{
+			ErrorCode:  result.ErrorCode,
+			Message:    result.Message,
+			TargetPath: result.Target,
+			Hint:       result.Hint,
+		})
+	}
+	return warnings
+}
+
 func toCommentDTOs(comments []issue.Comment) []CommentDTO {
 	if len(comments) == 0 {
 		return []CommentDTO{}
@@ -58,12 +294,13 @@ func toCommentDTOs(comments []issue.Comment) []CommentDTO {
 	dtos := make([]CommentDTO, 0, len(comments))
 	for _, comment := range comments {
 		dtos = append(dtos, CommentDTO{
-			CommentID:     comment.CommentID,
-			Body:          comment.Body,
-			AuthorName:    comment.AuthorName,
-			AuthorCompany: string(comment.AuthorCompany),
-			CreatedAt:     comment.CreatedAt,
-			Attachments:   toAttachmentDTOs(comment.Attachments),
+			CommentID:      comment.CommentID,
+			Body:           comment.Body,
+			AuthorName:     comment.AuthorName,
+			AuthorCompany:  string(comment.AuthorCompany),
+			CreatedAt:      comment.CreatedAt,
+			CreatedAtLocal: timeutil.ToDisplay(comment.CreatedAt),
+			Attachments:    toAttachmentDTOs(comment.Attachments),
 		})
 	}
 	return dtos
@@ -86,3 +323,286 @@ func toAttachmentDTOs(attachments []issue.AttachmentRef) []AttachmentRefDTO {
 	}
 	return dtos
 }
+
+// ToIssueExportDTO は DD-EXPORT-001 の課題一覧の出力結果を DTO に変換する。
+func ToIssueExportDTO(result issueexport.Result) IssueExportDTO {
+	return IssueExportDTO{
+		Path:    result.Path,
+		Format:  string(result.Format),
+		Count:   result.Count,
+		Skipped: result.Skipped,
+	}
+}
+
+// ToExportFormatDTOs は DD-PLUGIN-002 の本体の出力形式とプラグインが追加した出力形式 plugins を DTO に変換する。
+// 本体と同じ名前のプラグインの形式は本体の形式を用いるため含めない。
+func ToExportFormatDTOs(plugins []pluginhost.ExportFormat) []ExportFormatDTO {
+	formats := make([]ExportFormatDTO, 0, len(issueexport.BuiltinFormats)+len(plugins))
+	builtin := map[string]bool{}
+	for _, format := range issueexport.BuiltinFormats {
+		builtin[string(format)] = true
+		formats = append(formats, ExportFormatDTO{Format: string(format), Extension: "." + string(format)})
+	}
+	for _, format := range plugins {
+		if builtin[format.Format] {
+			continue
+		}
+		formats = append(formats, ExportFormatDTO{
+			Format:      format.Format,
+			Extension:   format.Extension,
+			Description: format.Description,
+			Plugin:      format.Plugin,
+		})
+	}
+	return formats
+}
+
+// ToSitePublishDTO は DD-PUBLISH-001 の静的な HTML サイトの出力結果を DTO に変換する。
+func ToSitePublishDTO(result sitepublish.Result) SitePublishDTO {
+	return SitePublishDTO{
+		Path:               result.Path,
+		Categories:         result.Categories,
+		Issues:             result.Issues,
+		Attachments:        result.Attachments,
+		MissingAttachments: result.MissingAttachments,
+		Skipped:            result.Skipped,
+	}
+}
+
+// ToIssueImportDTO は DD-IMPORT-001 の JSON Lines 形式の課題の取り込み結果を DTO に変換する。
+func ToIssueImportDTO(result issueimport.Result) IssueImportDTO {
+	rows := make([]IssueImportRowDTO, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		rows = append(rows, IssueImportRowDTO{
+			Line:     row.Line,
+			Status:   row.Status,
+			Category: row.Category,
+			IssueID:  row.IssueID,
+			Message:  row.Message,
+		})
+	}
+	return IssueImportDTO{
+		DryRun:  result.DryRun,
+		Created: result.Created,
+		Updated: result.Updated,
+		Failed:  result.Failed,
+		Rows:    rows,
+	}
+}
+
+// ToIssueHistoryDTO は DD-GIT-002 の課題のコミット履歴を DTO に変換する。
+func ToIssueHistoryDTO(category, issueID string, history []issueops.IssueRevision) IssueHistoryDTO {
+	revisions := make([]IssueRevisionDTO, 0, len(history))
+	for _, revision := range history {
+		committedAt := timeutil.FormatISO8601(revision.CommittedAt)
+		item := IssueRevisionDTO{
+			Commit:           revision.Commit,
+			AuthorName:       revision.AuthorName,
+			AuthorEmail:      revision.AuthorEmail,
+			CommittedAt:      committedAt,
+			CommittedAtLocal: timeutil.ToDisplay(committedAt),
+			Subject:          revision.Subject,
+			Operation:        revision.Operation,
+			Actor:            revision.Actor,
+			Mode:             revision.Mode,
+			Deleted:          revision.Deleted,
+			ParseError:       revision.ParseError,
+		}
+		if revision.Snapshot != nil {
+			snapshot := ToIssueDetailDTO(*revision.Snapshot)
+			item.Snapshot = &snapshot
+		}
+		revisions = append(revisions, item)
+	}
+	return IssueHistoryDTO{Category: category, IssueID: issueID, Revisions: revisions}
+}
+
+// ToSyncDTO は DD-SYNC-001 の同期の結果を DTO に変換する。
+func ToSyncDTO(result projectsync.Result) SyncDTO {
+	issues := make([]SyncIssueDTO, 0, len(result.Issues))
+	for _, item := range result.Issues {
+		conflicts := make([]SyncConflictDTO, 0, len(item.Conflicts))
+		for _, conflict := range item.Conflicts {
+			conflicts = append(conflicts, SyncConflictDTO{Field: conflict.Field, Base: conflict.Base, Local: conflict.Local, Remote: conflict.Remote})
+		}
+		issues = append(issues, SyncIssueDTO{
+			Category:         item.Category,
+			IssueID:          item.IssueID,
+			Action:           item.Action,
+			LocalUpdated:     item.LocalUpdated,
+			RemoteUpdated:    item.RemoteUpdated,
+			CommentsToLocal:  item.CommentsToLocal,
+			CommentsToRemote: item.CommentsToRemote,
+			Attachments:      item.Attachments,
+			Conflicts:        conflicts,
+			Message:          item.Message,
+		})
+	}
+	return SyncDTO{
+		DryRun:     result.DryRun,
+		LocalRoot:  result.LocalRoot,
+		RemoteRoot: result.RemoteRoot,
+		Copied:     result.Copied,
+		Merged:     result.Merged,
+		Conflicted: result.Conflicted,
+		Failed:     result.Failed,
+		Unchanged:  result.Unchanged,
+		Issues:     issues,
+	}
+}
+
+// ToPatchExportDTO は DD-PATCH-001 のパッチの出力結果を DTO に変換する。
+func ToPatchExportDTO(result patchbundle.ExportResult) PatchExportDTO {
+	return PatchExportDTO{
+		Path:        result.Path,
+		ExportedAt:  result.ExportedAt,
+		Issues:      result.Issues,
+		Comments:    result.Comments,
+		Attachments: result.Attachments,
+	}
+}
+
+// ToPatchApplyDTO は DD-PATCH-002 のパッチの取り込みの結果を DTO に変換する。
+func ToPatchApplyDTO(result patchbundle.ApplyResult) PatchApplyDTO {
+	issues := make([]PatchIssueDTO, 0, len(result.Issues))
+	for _, item := range result.Issues {
+		issues = append(issues, PatchIssueDTO{
+			Category:     item.Category,
+			IssueID:      item.IssueID,
+			LocalIssueID: item.LocalIssueID,
+			Action:       item.Action,
+			Comments:     item.Comments,
+			Attachments:  item.Attachments,
+			Conflicts:    item.Conflicts,
+			Message:      item.Message,
+		})
+	}
+	return PatchApplyDTO{
+		DryRun:     result.DryRun,
+		ExportedAt: result.ExportedAt,
+		Since:      result.Since,
+		Created:    result.Created,
+		Updated:    result.Updated,
+		Conflicted: result.Conflicted,
+		Failed:     result.Failed,
+		Unchanged:  result.Unchanged,
+		Issues:     issues,
+	}
+}
+
+// ToRedmineExportDTO は DD-REDMINE-001 の Redmine 向けの CSV の出力結果を DTO に変換する。
+func ToRedmineExportDTO(result redmine.ExportResult) RedmineExportDTO {
+	return RedmineExportDTO{
+		IssuesPath:   result.IssuesPath,
+		JournalsPath: result.JournalsPath,
+		Issues:       result.Issues,
+		Journals:     result.Journals,
+		Skipped:      result.Skipped,
+	}
+}
+
+// ToRedmineImportDTO は DD-REDMINE-002 の取り込み結果を DTO に変換する。
+func ToRedmineImportDTO(result redmine.ImportResult) RedmineImportDTO {
+	rows := make([]RedmineImportRowDTO, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		rows = append(rows, RedmineImportRowDTO{
+			Line:      row.Line,
+			RedmineID: row.RedmineID,
+			Status:    row.Status,
+			IssueID:   row.IssueID,
+			Message:   row.Message,
+		})
+	}
+	return RedmineImportDTO{
+		DryRun:    result.DryRun,
+		Created:   result.Created,
+		Updated:   result.Updated,
+		Unchanged: result.Unchanged,
+		Failed:    result.Failed,
+		Rows:      rows,
+	}
+}
+
+// ToEmlDraftDTO は DD-EML-001 の課題のメールの下書きの出力結果を DTO に変換する。
+func ToEmlDraftDTO(result emldraft.Result) EmlDraftDTO {
+	return EmlDraftDTO{
+		Path:               result.Path,
+		Attachments:        result.Attachments,
+		MissingAttachments: result.MissingAttachments,
+	}
+}
+
+// ToWeeklyReportDTO は DD-REPORT-002 の期間の報告書の出力結果を DTO に変換する。
+func ToWeeklyReportDTO(result weeklyreport.Result) WeeklyReportDTO {
+	return WeeklyReportDTO{
+		Path:     result.Path,
+		Format:   string(result.Format),
+		From:     result.Range.From,
+		To:       result.Range.To,
+		Opened:   result.Totals.Opened,
+		Resolved: result.Totals.Resolved,
+		Closed:   result.Totals.Closed,
+		Overdue:  result.Totals.Overdue,
+		Skipped:  result.Skipped,
+	}
+}
+
+// ToCategoryStatsDTO は DD-STATS-001 のカテゴリ集計結果を DTO に変換する。
+func ToCategoryStatsDTO(stats issuescan.CategoryStats) CategoryStatsDTO {
+	return CategoryStatsDTO{
+		Category:     stats.Category,
+		Total:        stats.Total,
+		ByStatus:     stats.ByStatus,
+		ByPriority:   stats.ByPriority,
+		OpenCount:    stats.OpenCount,
+		OverdueCount: stats.OverdueCount,
+		Errors:       stats.ErrorCount,
+	}
+}
+
+// ToProjectStatsDTO は DD-STATS-001 のプロジェクト全体の集計結果を DTO に変換する。
+func ToProjectStatsDTO(stats issuescan.ProjectStats) ProjectStatsDTO {
+	categories := make([]CategoryStatsDTO, 0, len(stats.Categories))
+	for _, category := range stats.Categories {
+		categories = append(categories, ToCategoryStatsDTO(category))
+	}
+	oldest := make([]OpenIssueDTO, 0, len(stats.OldestOpen))
+	for _, item := range stats.OldestOpen {
+		oldest = append(oldest, OpenIssueDTO(item))
+	}
+	overdue := make([]OpenIssueDTO, 0, len(stats.Overdue))
+	for _, item := range stats.Overdue {
+		overdue = append(overdue, OpenIssueDTO(item))
+	}
+	return ProjectStatsDTO{
+		Categories:   categories,
+		Total:        stats.Total,
+		ByStatus:     stats.ByStatus,
+		ByPriority:   stats.ByPriority,
+		OpenCount:    stats.OpenCount,
+		OverdueCount: stats.OverdueCount,
+		Errors:       stats.ErrorCount,
+		OldestOpen:   oldest,
+		Overdue:      overdue,
+	}
+}
+
+// ToMigrationResultDTO は DD-MIGRATE-001 の移行結果を DTO に変換する。バックアップ先は書き換えたファイルがある場合のみ設定する。
+func ToMigrationResultDTO(result migrate.Result, opts migrate.Options) MigrationResultDTO {
+	dto := MigrationResultDTO{
+		DryRun:   opts.DryRun,
+		Checked:  result.Checked,
+		Changes:  make([]MigrationChangeDTO, 0, len(result.Changes)),
+		Problems: make([]MigrationProblemDTO, 0, len(result.Problems)),
+	}
+	for _, change := range result.Changes {
+		dto.Changes = append(dto.Changes, MigrationChangeDTO{Kind: string(change.Kind), Path: change.Path, From: change.From, To: change.To})
+	}
+	for _, problem := range result.Problems {
+		dto.Problems = append(dto.Problems, MigrationProblemDTO{Kind: string(problem.Kind), Path: problem.Path, Message: problem.Message})
+	}
+	if len(result.Changes) > 0 && !opts.DryRun {
+		dto.BackupDir = opts.BackupDir
+	}
+	return dto
+}
@@ -3,10 +3,13 @@ package present
 
 import (
 	"testing"
+	"time"
 
 	"ratta/internal/app/categoryscan"
 	"ratta/internal/app/issueops"
 	"ratta/internal/domain/issue"
+	"ratta/internal/infra/pluginhost"
+	"ratta/internal/infra/tmpresidue"
 )
 
 func TestToCategoryDTO_MapsFields(t *testing.T) {
@@ -88,6 +91,29 @@ func TestToIssueDetailDTO_MapsNested(t *testing.T) {
 	}
 }
 
+func TestToIssueHistoryDTO_OmitsMissingSnapshots(t *testing.T) {
+	// 各版の課題を詳細DTOへ変換し、削除や解析できなかった版では snapshot を省略することを確認する。
+	when := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	history := []issueops.IssueRevision{
+		{Commit: "c2", CommittedAt: when, Operation: "issue_deleted", Deleted: true},
+		{Commit: "c1", CommittedAt: when, ParseError: "parse issue: unexpected end"},
+		{Commit: "c0", CommittedAt: when, Actor: "suzuki", Snapshot: &issueops.IssueDetail{Issue: issue.Issue{IssueID: "abc", Title: "first"}}},
+	}
+
+	dto := ToIssueHistoryDTO("cat", "abc", history)
+
+	if dto.Category != "cat" || dto.IssueID != "abc" || len(dto.Revisions) != 3 {
+		t.Fatalf("unexpected history: %+v", dto)
+	}
+	if dto.Revisions[0].Snapshot != nil || !dto.Revisions[0].Deleted || dto.Revisions[1].Snapshot != nil || dto.Revisions[1].ParseError == "" {
+		t.Fatalf("unexpected revisions without snapshot: %+v", dto.Revisions[:2])
+	}
+	last := dto.Revisions[2]
+	if last.Snapshot == nil || last.Snapshot.Title != "first" || last.Actor != "suzuki" || last.CommittedAt == "" || last.CommittedAtLocal == "" {
+		t.Fatalf("unexpected revision: %+v", last)
+	}
+}
+
 func TestToIssueSummaryDTO_MapsFields(t *testing.T) {
 	// 一覧要約が DTO へ正しく写像されることを確認する。
 	summary := issueops.IssueSummary{
@@ -110,3 +136,34 @@ func TestToIssueSummaryDTO_MapsFields(t *testing.T) {
 		t.Fatal("expected schema invalid to be true")
 	}
 }
+
+func TestToResidueWarningDTOs_MapsFields(t *testing.T) {
+	// 一時ファイル残骸の検出結果が target_path と hint を含むエラー一覧の形式へ変換されることを確認する。
+	warnings := ToResidueWarningDTOs([]tmpresidue.ScanResult{{
+		ErrorCode: tmpresidue.ErrCodeTmpRemaining,
+		Message:   "msg",
+		Target:    "cat/a.json.tmp.1.2",
+		Hint:      "hint",
+	}})
+	if len(warnings) != 1 || warnings[0].ErrorCode != tmpresidue.ErrCodeTmpRemaining ||
+		warnings[0].TargetPath != "cat/a.json.tmp.1.2" || warnings[0].Hint != "hint" {
+		t.Fatalf("unexpected warnings: %+v", warnings)
+	}
+	if empty := ToResidueWarningDTOs(nil); empty == nil || len(empty) != 0 {
+		t.Fatalf("expected empty non-nil slice, got %#v", empty)
+	}
+}
+
+func TestToExportFormatDTOs_ListsBuiltinThenPluginFormats(t *testing.T) {
+	// 本体の形式を先に並べ、本体と同じ名前のプラグインの形式は含めないことを確認する。
+	dtos := ToExportFormatDTOs([]pluginhost.ExportFormat{
+		{Format: "csv", Extension: ".csv", Plugin: "shadow"},
+		{Format: "xlsx", Extension: ".xlsx", Description: "Excel", Plugin: "office"},
+	})
+	if len(dtos) != 4 || dtos[0] != (ExportFormatDTO{Format: "csv", Extension: ".csv"}) || dtos[2].Format != "jsonl" {
+		t.Fatalf("unexpected builtin formats: %+v", dtos)
+	}
+	if dtos[3] != (ExportFormatDTO{Format: "xlsx", Extension: ".xlsx", Description: "Excel", Plugin: "office"}) {
+		t.Fatalf("unexpected plugin format: %+v", dtos[3])
+	}
+}
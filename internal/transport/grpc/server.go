@@ -0,0 +1,280 @@
+// Package grpc は DD-GRPC-001 の他ツールからの gRPC の呼び出しを、GUI のバインド (App) の呼び出しへ変換することを担い、
+// 課題・カテゴリの操作そのものや、書き込み用ロック・操作モード・カテゴリ権限の判定は扱わない。
+// 判定は GUI と同じ規則を適用するため、すべて Backend (App) に委ねる。
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/present"
+	"ratta/internal/transport/grpc/rattav1"
+)
+
+const (
+	// maxRequestBytes は DD-GRPC-001 の受け付ける要求の上限を表す。
+	// DD-DATA-004 の添付の上限数 (5件) と DD-DATA-005 の1件あたりの上限 (20 MiB) に、本文などの余裕 1 MiB を加えた値とする。
+	maxRequestBytes = 5*20<<20 + 1<<20
+	// ErrorCodeTrailer は DD-GRPC-001 の失敗時に DD-BE-003 のエラーコード (E_PERMISSION など) を返すトレーラーのキーを表す。
+	ErrorCodeTrailer = "ratta-error-code"
+	// WarningTrailer は DD-GRPC-001 の成功時の警告 (添付の種類の不一致など) のメッセージを返すトレーラーのキーを表す。
+	WarningTrailer = "ratta-warning"
+)
+
+// Backend は DD-GRPC-001 の gRPC の呼び出しを処理する GUI のバインドを表す。App の gRPC 用のアダプターが実装する。
+// 各メソッドは GUI から呼び出した場合と同じく、書き込み用ロック・操作モード・カテゴリ権限を判定したうえで操作する。
+// ただし認証を持たない API のため、GUI が Contractor モードでも Vendor として判定し、DD-MODE-001 の無操作時間の計測もやり直さない。
+type Backend interface {
+	ListCategories() present.Response
+	CreateCategory(name string) present.Response
+	RenameCategory(oldName, newName string) present.Response
+	DeleteCategory(name string) present.Response
+	ListIssues(category string, query present.IssueListQueryDTO) present.Response
+	GetIssue(category, issueID string) present.Response
+	CreateIssue(category string, dto present.IssueCreateDTO) present.Response
+	UpdateIssue(category, issueID string, dto present.IssueUpdateDTO) present.Response
+	AddComment(category, issueID string, dto present.CommentCreateDTO) present.Response
+}
+
+// Server は DD-GRPC-001 の RattaService の実装を表す。
+type Server struct {
+	rattav1.UnimplementedRattaServiceServer
+	backend Backend
+	server  *grpcgo.Server
+}
+
+// New は DD-GRPC-001 の backend へ委ねる gRPC サーバーを返す。
+func New(backend Backend) *Server {
+	s := &Server{backend: backend}
+	s.server = grpcgo.NewServer(grpcgo.MaxRecvMsgSize(maxRequestBytes))
+	rattav1.RegisterRattaServiceServer(s.server, s)
+	return s
+}
+
+// Listen は DD-GRPC-001 の address で待ち受けを開始する。
+// 目的: 認証を持たない API を、同じ端末の他ツールからのみ呼び出せるようにする。
+// 入力: address は host:port 形式の待ち受けアドレス。
+// 出力: 待ち受け中の net.Listener とエラー。
+// エラー: host がループバックアドレス (localhost を含む) でない場合は E_VALIDATION、待ち受けに失敗した場合はラップしたエラーを返す。
+// 副作用: TCP ポートを開く。
+// 並行性: スレッドセーフ。
+// 不変条件: ループバック以外のアドレスでは待ち受けない。
+// 関連DD: DD-GRPC-001
+func Listen(address string) (net.Listener, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, apperr.Errorf(apperr.ErrValidation, "invalid grpc address %q: %v", address, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, apperr.Errorf(apperr.ErrValidation, "grpc address must be a loopback address: %s", address)
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("listen grpc: %w", err)
+	}
+	return listener, nil
+}
+
+// Serve は DD-GRPC-001 の listener で要求の受け付けを行い、Stop まで戻らない。
+func (s *Server) Serve(listener net.Listener) error {
+	if err := s.server.Serve(listener); err != nil && !errors.Is(err, grpcgo.ErrServerStopped) {
+		return fmt.Errorf("serve grpc: %w", err)
+	}
+	return nil
+}
+
+// Stop は DD-GRPC-001 の新しい要求の受け付けを止め、処理中の要求の完了を待つ。
+func (s *Server) Stop() {
+	s.server.GracefulStop()
+}
+
+// ListCategories は DD-GRPC-001 のカテゴリ一覧を返す。
+func (s *Server) ListCategories(ctx context.Context, _ *rattav1.ListCategoriesRequest) (*rattav1.ListCategoriesResponse, error) {
+	out := &rattav1.ListCategoriesResponse{}
+	return out, reply(ctx, s.backend.ListCategories(), out)
+}
+
+// CreateCategory は DD-GRPC-001 のカテゴリ作成を行う。
+func (s *Server) CreateCategory(ctx context.Context, req *rattav1.CreateCategoryRequest) (*rattav1.Category, error) {
+	out := &rattav1.Category{}
+	return out, reply(ctx, s.backend.CreateCategory(req.GetName()), out)
+}
+
+// RenameCategory は DD-GRPC-001 のカテゴリ名変更を行う。
+func (s *Server) RenameCategory(ctx context.Context, req *rattav1.RenameCategoryRequest) (*rattav1.Category, error) {
+	out := &rattav1.Category{}
+	return out, reply(ctx, s.backend.RenameCategory(req.GetOldName(), req.GetNewName()), out)
+}
+
+// DeleteCategory は DD-GRPC-001 の空のカテゴリの削除を行う。削除したカテゴリ名を返す。
+func (s *Server) DeleteCategory(ctx context.Context, req *rattav1.DeleteCategoryRequest) (*rattav1.DeleteCategoryResponse, error) {
+	out := &rattav1.DeleteCategoryResponse{}
+	if err := reply(ctx, s.backend.DeleteCategory(req.GetName()), out); err != nil {
+		return nil, err
+	}
+	if out.Name == "" {
+		out.Name = req.GetName()
+	}
+	return out, nil
+}
+
+// ListIssues は DD-GRPC-001 の課題一覧を返す。
+func (s *Server) ListIssues(ctx context.Context, req *rattav1.ListIssuesRequest) (*rattav1.ListIssuesResponse, error) {
+	out := &rattav1.ListIssuesResponse{}
+	return out, reply(ctx, s.backend.ListIssues(req.GetCategory(), present.IssueListQueryDTO{
+		Page:      int(req.GetPage()),
+		PageSize:  int(req.GetPageSize()),
+		SortBy:    req.GetSortBy(),
+		SortOrder: req.GetSortOrder(),
+		Status:    req.GetStatus(),
+		Priority:  req.GetPriority(),
+		Cursor:    req.GetCursor(),
+	}), out)
+}
+
+// GetIssue は DD-GRPC-001 の課題詳細を返す。
+func (s *Server) GetIssue(ctx context.Context, req *rattav1.GetIssueRequest) (*rattav1.IssueDetail, error) {
+	out := &rattav1.IssueDetail{}
+	return out, reply(ctx, s.backend.GetIssue(req.GetCategory(), req.GetIssueId()), out)
+}
+
+// CreateIssue は DD-GRPC-001 の課題作成を行う。
+func (s *Server) CreateIssue(ctx context.Context, req *rattav1.CreateIssueRequest) (*rattav1.IssueDetail, error) {
+	out := &rattav1.IssueDetail{}
+	return out, reply(ctx, s.backend.CreateIssue(req.GetCategory(), present.IssueCreateDTO{
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+		DueDate:     req.GetDueDate(),
+		Priority:    req.GetPriority(),
+		Assignee:    req.GetAssignee(),
+	}), out)
+}
+
+// UpdateIssue は DD-GRPC-001 の課題更新を行う。
+func (s *Server) UpdateIssue(ctx context.Context, req *rattav1.UpdateIssueRequest) (*rattav1.IssueDetail, error) {
+	out := &rattav1.IssueDetail{}
+	return out, reply(ctx, s.backend.UpdateIssue(req.GetCategory(), req.GetIssueId(), present.IssueUpdateDTO{
+		Title:            req.GetTitle(),
+		Description:      req.GetDescription(),
+		DueDate:          req.GetDueDate(),
+		Priority:         req.GetPriority(),
+		Status:           req.GetStatus(),
+		Assignee:         req.GetAssignee(),
+		ExpectedRevision: req.GetExpectedRevision(),
+	}), out)
+}
+
+// AddComment は DD-GRPC-001 のコメント追加を行う。
+// 目的: 内容で受け取った添付を、GUI と同じサイズ・種類の検査を経て保存する。
+// 入力: req はカテゴリ・課題ID・本文・作成者名・添付の内容。
+// 出力: 更新後の課題詳細とエラー。
+// エラー: 添付の一時ファイルを書き込めない場合は Internal、Backend が失敗した場合はそのエラーコードに対応するコードを返す。
+// 副作用: 添付ごとに一時ファイルを作成し、Backend の呼び出し後に削除する。
+// 並行性: 呼び出しごとに別の一時ディレクトリを用いるためスレッドセーフ。
+// 不変条件: 一時ファイルは元のファイル名 (ディレクトリ部分を除く) で作成し、拡張子による種類の判定を GUI と揃える。
+// 関連DD: DD-GRPC-001, DD-DATA-004, DD-DATA-005
+func (s *Server) AddComment(ctx context.Context, req *rattav1.AddCommentRequest) (*rattav1.IssueDetail, error) {
+	dto := present.CommentCreateDTO{Body: req.GetBody(), AuthorName: req.GetAuthorName(), ExpectedRevision: req.GetExpectedRevision()}
+	if len(req.GetAttachments()) > 0 {
+		dir, err := os.MkdirTemp("", "ratta-grpc-")
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "create attachment dir: %v", err)
+		}
+		defer func() { _ = os.RemoveAll(dir) }()
+		for i, upload := range req.GetAttachments() {
+			path, err := writeUpload(dir, i, upload)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "write attachment: %v", err)
+			}
+			dto.Attachments = append(dto.Attachments, present.AttachmentUploadDTO{
+				SourcePath:       path,
+				OriginalFileName: upload.GetOriginalFileName(),
+				MimeType:         upload.GetMimeType(),
+			})
+		}
+	}
+	out := &rattav1.IssueDetail{}
+	return out, reply(ctx, s.backend.AddComment(req.GetCategory(), req.GetIssueId(), dto), out)
+}
+
+// writeUpload は DD-GRPC-001 の添付1件を dir 配下の i 番目のディレクトリへ、元のファイル名で書き込みパスを返す。
+func writeUpload(dir string, i int, upload *rattav1.AttachmentUpload) (string, error) {
+	name := filepath.Base(filepath.Clean("/" + upload.GetOriginalFileName()))
+	if name == "/" || name == "." || name == `\` {
+		name = "attachment"
+	}
+	sub := filepath.Join(dir, fmt.Sprint(i))
+	if err := os.Mkdir(sub, 0o700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(sub, name)
+	if err := os.WriteFile(path, upload.GetData(), 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// reply は DD-GRPC-001 の Backend の応答を gRPC の応答へ変換する。
+// 目的: DTO の JSON のキー名と proto のフィールド名を揃えた定義に従い、DTO を out へ写す。
+// 入力: ctx は呼び出しの context、resp は Backend の応答、out は写し先のメッセージ。
+// 出力: 成功時は nil、失敗時は gRPC のステータスエラー。
+// エラー: resp が失敗の場合はエラーコードに対応するステータス、DTO を写せない場合は Internal を返す。
+// 副作用: 失敗時はエラーコードを、成功時は警告をトレーラーへ設定する。
+// 並行性: スレッドセーフ。
+// 不変条件: proto に無い DTO の項目 (custom_fields など) は読み捨てる。
+// 関連DD: DD-GRPC-001, DD-BE-003
+func reply(ctx context.Context, resp present.Response, out proto.Message) error {
+	if !resp.Ok {
+		return statusError(ctx, resp.Error)
+	}
+	for _, warning := range resp.Warnings {
+		_ = grpcgo.SetTrailer(ctx, metadata.Pairs(WarningTrailer, warning.Message))
+	}
+	if resp.Data == nil {
+		return nil
+	}
+	data, err := json.Marshal(resp.Data)
+	if err != nil {
+		return status.Errorf(codes.Internal, "marshal response: %v", err)
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, out); err != nil {
+		return status.Errorf(codes.Internal, "convert response: %v", err)
+	}
+	return nil
+}
+
+// statusError は DD-GRPC-001 の DD-BE-003 のエラーを gRPC のステータスへ変換し、エラーコードをトレーラーへ設定する。
+// 書き込み用ロックを他のインスタンスが保持している場合 (E_CONFLICT) は、状態が変われば成功し得るため FailedPrecondition とする。
+func statusError(ctx context.Context, apiErr *present.APIErrorDTO) error {
+	if apiErr == nil {
+		return status.Error(codes.Internal, "request failed")
+	}
+	_ = grpcgo.SetTrailer(ctx, metadata.Pairs(ErrorCodeTrailer, apiErr.ErrorCode))
+	code := codes.Internal
+	switch apiErr.ErrorCode {
+	case present.ErrorValidation, present.ErrorSchemaInvalid:
+		code = codes.InvalidArgument
+	case present.ErrorPermission:
+		code = codes.PermissionDenied
+	case present.ErrorNotFound:
+		code = codes.NotFound
+	case present.ErrorConflict:
+		code = codes.FailedPrecondition
+	case present.ErrorCanceled:
+		code = codes.Canceled
+	}
+	return status.Error(code, apiErr.Message)
+}
@@ -0,0 +1,260 @@
+// server_test.go は gRPC の呼び出しを GUI のバインドの呼び出しへ変換するサーバーのテストを行う。
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"ratta/internal/domain/apperr"
+	"ratta/internal/present"
+	"ratta/internal/transport/grpc/rattav1"
+)
+
+// fakeBackend は呼び出しの引数を記録し、設定した応答を返す Backend を表す。
+type fakeBackend struct {
+	resp        present.Response
+	category    string
+	issueID     string
+	query       present.IssueListQueryDTO
+	update      present.IssueUpdateDTO
+	comment     present.CommentCreateDTO
+	attachments [][]byte
+}
+
+func (f *fakeBackend) ListCategories() present.Response { return f.resp }
+
+func (f *fakeBackend) CreateCategory(name string) present.Response {
+	f.category = name
+	return f.resp
+}
+
+func (f *fakeBackend) RenameCategory(_, newName string) present.Response {
+	f.category = newName
+	return f.resp
+}
+
+func (f *fakeBackend) DeleteCategory(name string) present.Response {
+	f.category = name
+	return f.resp
+}
+
+func (f *fakeBackend) ListIssues(category string, query present.IssueListQueryDTO) present.Response {
+	f.category, f.query = category, query
+	return f.resp
+}
+
+func (f *fakeBackend) GetIssue(category, issueID string) present.Response {
+	f.category, f.issueID = category, issueID
+	return f.resp
+}
+
+func (f *fakeBackend) CreateIssue(category string, _ present.IssueCreateDTO) present.Response {
+	f.category = category
+	return f.resp
+}
+
+func (f *fakeBackend) UpdateIssue(category, issueID string, dto present.IssueUpdateDTO) present.Response {
+	f.category, f.issueID, f.update = category, issueID, dto
+	return f.resp
+}
+
+func (f *fakeBackend) AddComment(category, issueID string, dto present.CommentCreateDTO) present.Response {
+	f.category, f.issueID, f.comment = category, issueID, dto
+	// 一時ファイルは呼び出しの後に削除されるため、呼び出し中に内容を読み取って残す。
+	for _, upload := range dto.Attachments {
+		data, err := os.ReadFile(upload.SourcePath)
+		if err != nil {
+			data = nil
+		}
+		f.attachments = append(f.attachments, data)
+	}
+	return f.resp
+}
+
+// dialServer は backend へ委ねるサーバーをメモリ上の接続で起動し、クライアントを返す。
+func dialServer(t *testing.T, backend Backend) rattav1.RattaServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := New(backend)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	conn, err := grpcgo.NewClient("passthrough:///bufnet",
+		grpcgo.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpcgo.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return rattav1.NewRattaServiceClient(conn)
+}
+
+func TestServer_ConvertsIssueDetail(t *testing.T) {
+	// DTO の JSON のキー名に対応するフィールドへ写すことを確認する。
+	backend := &fakeBackend{resp: present.Ok(present.IssueDetailDTO{
+		Version:  1,
+		IssueID:  "abc123def",
+		Category: "A",
+		Title:    "title",
+		Status:   "Open",
+		Revision: "rev",
+		Comments: []present.CommentDTO{{CommentID: "c1", Body: "body"}},
+	})}
+	client := dialServer(t, backend)
+	got, err := client.GetIssue(context.Background(), &rattav1.GetIssueRequest{Category: "A", IssueId: "abc123def"})
+	if err != nil {
+		t.Fatalf("GetIssue error: %v", err)
+	}
+	if backend.category != "A" || backend.issueID != "abc123def" {
+		t.Fatalf("unexpected backend args: %q %q", backend.category, backend.issueID)
+	}
+	if got.GetIssueId() != "abc123def" || got.GetTitle() != "title" || got.GetVersion() != 1 || got.GetRevision() != "rev" || len(got.GetComments()) != 1 || got.GetComments()[0].GetBody() != "body" {
+		t.Fatalf("unexpected issue: %v", got)
+	}
+}
+
+func TestServer_PassesExpectedRevision(t *testing.T) {
+	// 課題更新とコメント追加の expected_revision を DTO へ写し、GUI と同じく他の編集との競合を検出できることを確認する。
+	backend := &fakeBackend{resp: present.Ok(present.IssueDetailDTO{IssueID: "abc123def"})}
+	client := dialServer(t, backend)
+	if _, err := client.UpdateIssue(context.Background(), &rattav1.UpdateIssueRequest{Category: "A", IssueId: "abc123def", Title: "t", ExpectedRevision: "rev-1"}); err != nil {
+		t.Fatalf("UpdateIssue error: %v", err)
+	}
+	if backend.update.ExpectedRevision != "rev-1" || backend.update.Title != "t" {
+		t.Fatalf("unexpected update: %+v", backend.update)
+	}
+	if _, err := client.AddComment(context.Background(), &rattav1.AddCommentRequest{Category: "A", IssueId: "abc123def", Body: "body", ExpectedRevision: "rev-2"}); err != nil {
+		t.Fatalf("AddComment error: %v", err)
+	}
+	if backend.comment.ExpectedRevision != "rev-2" {
+		t.Fatalf("unexpected comment: %+v", backend.comment)
+	}
+}
+
+func TestServer_PassesListQuery(t *testing.T) {
+	// 一覧の条件を IssueListQueryDTO へ写し、一覧の件数と課題を返すことを確認する。
+	backend := &fakeBackend{resp: present.Ok(present.IssueListDTO{
+		Category: "A",
+		Total:    1,
+		Page:     2,
+		PageSize: 10,
+		Issues:   []present.IssueSummaryDTO{{IssueID: "abc123def", Title: "t"}},
+	})}
+	client := dialServer(t, backend)
+	got, err := client.ListIssues(context.Background(), &rattav1.ListIssuesRequest{Category: "A", Page: 2, PageSize: 10, Status: "Open", SortBy: "updated_at"})
+	if err != nil {
+		t.Fatalf("ListIssues error: %v", err)
+	}
+	want := present.IssueListQueryDTO{Page: 2, PageSize: 10, SortBy: "updated_at", Status: "Open"}
+	if backend.query != want {
+		t.Fatalf("unexpected query: %+v", backend.query)
+	}
+	if got.GetTotal() != 1 || len(got.GetIssues()) != 1 || got.GetIssues()[0].GetIssueId() != "abc123def" {
+		t.Fatalf("unexpected list: %v", got)
+	}
+}
+
+func TestServer_MapsErrorCodes(t *testing.T) {
+	// DD-BE-003 のエラーコードを gRPC のステータスへ対応させ、元のコードをトレーラーで返すことを確認する。
+	cases := []struct {
+		err  error
+		code codes.Code
+	}{
+		{apperr.New(apperr.ErrValidation, "bad"), codes.InvalidArgument},
+		{apperr.New(apperr.ErrPermission, "observer"), codes.PermissionDenied},
+		{apperr.New(apperr.ErrNotFound, "missing"), codes.NotFound},
+		{apperr.New(apperr.ErrReadOnly, "locked"), codes.FailedPrecondition},
+		{context.Canceled, codes.Canceled},
+		{errors.New("boom"), codes.Internal},
+	}
+	for _, tc := range cases {
+		backend := &fakeBackend{resp: present.Fail(tc.err)}
+		client := dialServer(t, backend)
+		var trailer metadata.MD
+		_, err := client.UpdateIssue(context.Background(), &rattav1.UpdateIssueRequest{Category: "A", IssueId: "abc123def", Title: "t"}, grpcgo.Trailer(&trailer))
+		if status.Code(err) != tc.code {
+			t.Fatalf("%v: unexpected code: %v", tc.err, err)
+		}
+		apiErr := present.MapError(tc.err)
+		if got := trailer.Get(ErrorCodeTrailer); len(got) != 1 || got[0] != apiErr.ErrorCode {
+			t.Fatalf("%v: unexpected trailer: %v", tc.err, got)
+		}
+	}
+}
+
+func TestServer_AddCommentPassesAttachmentFiles(t *testing.T) {
+	// 内容で受け取った添付を元のファイル名の一時ファイルとして渡し、呼び出しの後に削除することを確認する。
+	backend := &fakeBackend{resp: present.Ok(present.IssueDetailDTO{IssueID: "abc123def"})}
+	client := dialServer(t, backend)
+	_, err := client.AddComment(context.Background(), &rattav1.AddCommentRequest{
+		Category: "A",
+		IssueId:  "abc123def",
+		Body:     "body",
+		Attachments: []*rattav1.AttachmentUpload{
+			{OriginalFileName: "../../report.txt", MimeType: "text/plain", Data: []byte("one")},
+			{OriginalFileName: "report.txt", Data: []byte("two")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddComment error: %v", err)
+	}
+	uploads := backend.comment.Attachments
+	if len(uploads) != 2 || backend.comment.Body != "body" {
+		t.Fatalf("unexpected comment: %+v", backend.comment)
+	}
+	if filepath.Base(uploads[0].SourcePath) != "report.txt" || uploads[0].OriginalFileName != "../../report.txt" || uploads[0].MimeType != "text/plain" {
+		t.Fatalf("unexpected upload: %+v", uploads[0])
+	}
+	if uploads[0].SourcePath == uploads[1].SourcePath {
+		t.Fatalf("uploads with the same name must not share a file: %s", uploads[0].SourcePath)
+	}
+	if string(backend.attachments[0]) != "one" || string(backend.attachments[1]) != "two" {
+		t.Fatalf("unexpected attachment contents: %q", backend.attachments)
+	}
+	if _, statErr := os.Stat(filepath.Dir(filepath.Dir(uploads[0].SourcePath))); !errors.Is(statErr, os.ErrNotExist) {
+		t.Fatalf("temporary directory should be removed: %v", statErr)
+	}
+}
+
+func TestServer_DeleteCategoryReturnsName(t *testing.T) {
+	// 削除の応答にデータが無い場合も、削除したカテゴリ名を返すことを確認する。
+	backend := &fakeBackend{resp: present.Ok(nil)}
+	client := dialServer(t, backend)
+	got, err := client.DeleteCategory(context.Background(), &rattav1.DeleteCategoryRequest{Name: "A"})
+	if err != nil {
+		t.Fatalf("DeleteCategory error: %v", err)
+	}
+	if got.GetName() != "A" || backend.category != "A" {
+		t.Fatalf("unexpected response: %v %q", got, backend.category)
+	}
+}
+
+func TestListen_RejectsNonLoopbackAddress(t *testing.T) {
+	// 認証を持たない API のため、ループバック以外のアドレスでは待ち受けないことを確認する。
+	for _, address := range []string{"0.0.0.0:0", ":0", "192.0.2.1:0", "example.com:0", "localhost"} {
+		listener, err := Listen(address)
+		if err == nil {
+			_ = listener.Close()
+			t.Fatalf("%s: expected error", address)
+		}
+		if !errors.Is(err, apperr.ErrValidation) {
+			t.Fatalf("%s: unexpected error: %v", address, err)
+		}
+	}
+	listener, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen error: %v", err)
+	}
+	_ = listener.Close()
+}
@@ -4,9 +4,14 @@ package main
 import (
 	"embed"
 	"flag"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"ratta/internal/app/cli"
 	"ratta/internal/app/contractorinit"
+	"ratta/internal/infra/crypto"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -16,6 +21,18 @@ import (
 //go:embed all:frontend/dist
 var assets embed.FS
 
+// appVersion は DD-DIAG-001 の診断情報に記録するアプリのバージョンを表す。
+// リリースビルドでは -ldflags "-X main.appVersion=<version>" で埋め込む。
+var appVersion = "dev"
+
+// appCommit と appBuildDate は DD-CLI-006 の version サブコマンドで表示するコミットとビルド日時を表す。
+// リリースビルドでは -ldflags "-X main.appCommit=<hash> -X main.appBuildDate=<RFC3339>" で埋め込む。
+// 空の場合は Go が記録した VCS 情報で補う。
+var (
+	appCommit    = ""
+	appBuildDate = ""
+)
+
 // main は Wails アプリとCLIモードの起動を行う。
 // 目的: CLI 初期化とGUI起動を切り替える。
 // 入力: コマンドライン引数。
@@ -23,26 +40,39 @@ var assets embed.FS
 // エラー: CLI 処理の失敗時は終了コードで示す。
 // 副作用: プロセス終了やアプリ起動を行う。
 // 並行性: 単一ゴルーチンで実行する。
-// 不変条件: CLI が処理された場合は GUI を起動しない。
+// 不変条件: CLI が処理された場合や起動引数が不正な場合は GUI を起動しない。
 // 関連DD: DD-BE-002, DD-CLI-002
 func main() {
 	if handled, code := runCLI(); handled {
 		os.Exit(code)
 	}
+	startup, err := parseStartupOptions(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
 	// Create an instance of the app structure
-	app := NewApp()
+	app := NewApp(startup)
+	width, height := app.windowSize()
 
 	// Create application with options
-	err := wails.Run(&options.App{
+	err = wails.Run(&options.App{
 		Title:  "ratta",
-		Width:  1280,
-		Height: 768,
+		Width:  width,
+		Height: height,
 		AssetServer: &assetserver.Options{
 			Assets: assets,
 		},
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
-		OnStartup:        app.startup,
+		// 添付のドラッグ&ドロップを受け付け、WebView がファイルを開いて画面遷移することを防ぐ。
+		DragAndDrop: &options.DragAndDrop{
+			EnableFileDrop:     true,
+			DisableWebViewDrop: true,
+		},
+		OnStartup:     app.startup,
+		OnBeforeClose: app.beforeClose,
+		OnShutdown:    app.shutdown,
 		Bind: []interface{}{
 			app,
 		},
@@ -52,35 +82,115 @@ func main() {
 	}
 }
 
-// runCLI は CLI モードの初期化コマンドを処理する。
-// 目的: init contractor を検出し認証ファイル生成を実行する。
+// runCLI は CLI モードのコマンドを処理する。
+// 目的: init contractor と各サブコマンドを検出して実行する。
 // 入力: os.Args の内容。
 // 出力: handled は CLI を処理したか、code は終了コード。
-// エラー: 失敗時は handled=true と code=1 を返す。
-// 副作用: contractor.json 生成やプロセス終了コードに影響する。
+// エラー: 失敗時は handled=true と 0 以外の code を返す。
+// 副作用: contractor.json 生成、標準出力への書き込みやプロセス終了コードに影響する。
 // 並行性: 単一ゴルーチンで実行する。
 // 不変条件: 対象外の引数は handled=false を返す。
-// 関連DD: DD-CLI-002, DD-CLI-003, DD-CLI-004
+// 関連DD: DD-CLI-002, DD-CLI-003, DD-CLI-004, DD-CLI-006
 func runCLI() (bool, int) {
 	if len(os.Args) < 2 {
 		return false, 0
 	}
-	if os.Args[1] != "init" || len(os.Args) < 3 || os.Args[2] != "contractor" {
-		return false, 0
+	if os.Args[1] == "init" && len(os.Args) >= 3 && os.Args[2] == "contractor" {
+		return true, runInitContractor(os.Args[3:])
 	}
 
+	exePath, err := os.Executable()
+	if err != nil {
+		exePath = ""
+	}
+	return cli.Run(os.Args[1:], cli.Env{
+		ExePath:  exePath,
+		Stdin:    os.Stdin,
+		Stdout:   os.Stdout,
+		Stderr:   os.Stderr,
+		Prompter: contractorinit.ConsolePrompter{},
+		Build:    cli.BuildInfo{Version: appVersion, Commit: appCommit, BuildDate: appBuildDate},
+	})
+}
+
+// parseStartupOptions は DD-BE-002 の GUI 起動時の起動引数 --root・--config・--observer を解析する。
+// パスは絶対パスにし、--config の配置先のディレクトリが存在しない場合は保存できないためエラーとする。
+// --root の検証は GUI で理由を示せるよう NewApp で行う。
+func parseStartupOptions(args []string) (startupOptions, error) {
+	fs := flag.NewFlagSet("ratta", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	root := fs.String("root", "", "project root to open on startup")
+	configPath := fs.String("config", "", "path of config.json (default: next to the executable)")
+	observer := fs.Bool("observer", false, "start in read-only observer mode")
+	if err := fs.Parse(args); err != nil {
+		return startupOptions{}, err
+	}
+	if fs.NArg() != 0 {
+		return startupOptions{}, fmt.Errorf("unknown command: %s", fs.Arg(0))
+	}
+	options := startupOptions{Observer: *observer}
+	if *root != "" {
+		abs, err := filepath.Abs(*root)
+		if err != nil {
+			return startupOptions{}, fmt.Errorf("--root: %w", err)
+		}
+		options.Root = abs
+	}
+	if *configPath != "" {
+		abs, err := filepath.Abs(*configPath)
+		if err != nil {
+			return startupOptions{}, fmt.Errorf("--config: %w", err)
+		}
+		if info, statErr := os.Stat(filepath.Dir(abs)); statErr != nil || !info.IsDir() {
+			return startupOptions{}, fmt.Errorf("--config: directory does not exist: %s", filepath.Dir(abs))
+		}
+		options.ConfigPath = abs
+	}
+	return options, nil
+}
+
+// runInitContractor は DD-CLI-002/003/004/005/007/008 の init contractor を実行し終了コードを返す。
+// --user 指定時は共有パスワードの contractor.json ではなく users.json へ名前付きアカウントを追加する。
+// --totp 指定時は contractor.json (--user 指定時はそのアカウント) に TOTP の秘密鍵を埋め込み、認証アプリへの登録情報を標準出力へ書く。
+// 鍵導出設定はパスワード入力の前に検証し、未対応の値では入力を求めずに終了する。
+func runInitContractor(args []string) int {
 	fs := flag.NewFlagSet("init contractor", flag.ContinueOnError)
-	force := fs.Bool("force", false, "overwrite existing contractor.json")
-	if err := fs.Parse(os.Args[3:]); err != nil {
-		return true, 1
+	force := fs.Bool("force", false, "overwrite existing contractor.json (or replace the --user account)")
+	user := fs.String("user", "", "add a named contractor account to auth/users.json instead of the shared contractor.json")
+	kdfName := fs.String("kdf", crypto.KDFPBKDF2SHA256, "key derivation function: "+strings.Join(crypto.SupportedKDFs(), " or "))
+	iterations := fs.Int("iterations", 0, "key derivation iterations (0 uses the default for the kdf)")
+	totp := fs.Bool("totp", false, "also require a one-time code from an authenticator app")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	kdf, err := crypto.ResolveKDFParams(*kdfName, *iterations)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "init contractor: %v\n", err)
+		return 1
 	}
 
 	exePath, err := os.Executable()
 	if err != nil {
-		return true, 1
+		return 1
+	}
+	var runErr error
+	var enrollment contractorinit.TOTPEnrollment
+	switch {
+	case *user != "":
+		enrollment, runErr = contractorinit.AddUser(exePath, *user, *force, *totp, kdf, contractorinit.ConsolePrompter{})
+	case *totp:
+		enrollment, runErr = contractorinit.RunWithTOTP(exePath, *force, kdf, contractorinit.ConsolePrompter{})
+	default:
+		runErr = contractorinit.RunWithKDF(exePath, *force, kdf, contractorinit.ConsolePrompter{})
+	}
+	if runErr != nil {
+		fmt.Fprintf(os.Stderr, "init contractor: %v\n", runErr)
+		return 1
 	}
-	if runErr := contractorinit.Run(exePath, *force, contractorinit.ConsolePrompter{}); runErr != nil {
-		return true, 1
+	if *totp {
+		// 秘密鍵は contractor.json に暗号化して保存するため、平文で確認できるのはこの時だけとなる。
+		fmt.Printf("TOTP secret: %s\notpauth URI: %s\n", enrollment.Secret, enrollment.URI)
+		fmt.Fprintln(os.Stderr, "register the secret in an authenticator app now; it is not shown again")
 	}
-	return true, 0
+	return 0
 }
@@ -0,0 +1,10 @@
+// Package schemas は実行ファイルへ同梱する JSON Schema を担い、スキーマの読み込みや検証は扱わない。
+// 実行ファイルの隣に schemas/ が無い場合のフォールバックとして用いる。
+package schemas
+
+import "embed"
+
+// FS は DD-BE-002 の同梱する JSON Schema (*.json) を表す。
+//
+//go:embed *.json
+var FS embed.FS